@@ -151,7 +151,9 @@ echo -e "${YELLOW}Compiling for $PLATFORM...${NC}"
 # Set build variables
 BUILD_TIME=$(date -u '+%Y-%m-%dT%H:%M:%SZ')
 GIT_COMMIT=$(git rev-parse --short HEAD 2>/dev/null || echo "unknown")
-VERSION="2.0.0-native"
+VERSION="${VERSION:-2.0.0-native}"
+# Base64 Ed25519 key that `hyper self-update` verifies releases against
+UPDATE_PUBLIC_KEY="${UPDATE_PUBLIC_KEY:-}"
 
 # Go build with tags and optimizations (cross-platform)
 # CGO is ENABLED for llama.cpp GPU acceleration
@@ -177,7 +179,7 @@ elif [ "$GOOS" = "linux" ]; then
 fi
 
 GOOS=$GOOS GOARCH=$GOARCH go build \
-    -ldflags="-s -w -X main.Version=$VERSION -X main.BuildTime=$BUILD_TIME -X main.GitCommit=$GIT_COMMIT" \
+    -ldflags="-s -w -X main.Version=$VERSION -X main.BuildTime=$BUILD_TIME -X main.GitCommit=$GIT_COMMIT -X hyper/internal/update.PublicKey=$UPDATE_PUBLIC_KEY" \
     -o "$OUTPUT_BINARY" \
     .
 
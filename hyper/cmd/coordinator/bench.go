@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"hyper/internal/embedbench"
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/setup"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// runBenchEmbeddings implements `hyper bench-embeddings`: a retrieval
+// benchmark of every configured embedding provider
+func runBenchEmbeddings(args []string) {
+	fs := flag.NewFlagSet("bench-embeddings", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to config file (default: ./.env.hyper[.<profile>])")
+	profile := fs.String("profile", os.Getenv("HYPER_PROFILE"), "Config profile")
+	workspace := fs.String("workspace", os.Getenv("HYPER_WORKSPACE"), "Workspace from WORKSPACE_DATABASES")
+	datasetPath := fs.String("dataset", "", "JSON file with documents and labelled queries (default: generate from the indexed code)")
+	samples := fs.Int("samples", 300, "Chunks to sample from the index when generating a dataset")
+	queries := fs.Int("queries", 50, "Maximum queries to generate")
+	k := fs.Int("k", 10, "Cutoff for recall@k")
+	providers := fs.String("providers", "", "Comma-separated providers to test: ollama, local, openai, voyage (default: all configured)")
+	fs.Parse(args)
+
+	path := *configPath
+	if path == "" {
+		path = setup.EnvFileNameFor(*profile)
+	}
+	// Keep explicitly exported variables; the file only fills the gaps
+	if files, err := setup.LoadEnvFiles(path, false); err == nil {
+		fmt.Printf("✓ Loaded configuration from: %s\n", strings.Join(files, ", "))
+	}
+
+	dataset, err := loadBenchDataset(*datasetPath, *profile, *workspace, *samples, *queries)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Benchmark: %d documents, %d queries, k=%d\n\n", len(dataset.Documents), len(dataset.Cases), *k)
+
+	clients := benchProviders(*providers)
+	if len(clients) == 0 {
+		fmt.Fprintln(os.Stderr, "✗ No embedding provider configured (set OLLAMA_URL, TEI_URL, OPENAI_API_KEY or VOYAGE_API_KEY)")
+		os.Exit(1)
+	}
+
+	results := make([]embedbench.Result, 0, len(clients))
+	for _, provider := range clients {
+		fmt.Printf("Running %s...\n", provider.Name)
+		results = append(results, embedbench.Run(provider, dataset, *k))
+	}
+	fmt.Println()
+	embedbench.WriteReport(os.Stdout, results)
+}
+
+// loadBenchDataset reads the dataset file, or generates one from chunks in the
+// code index of the profile and workspace
+func loadBenchDataset(path, profile, workspace string, samples, queries int) (*embedbench.Dataset, error) {
+	if path != "" {
+		return embedbench.LoadDataset(path)
+	}
+
+	mongoURI := os.Getenv("MONGODB_URI")
+	if mongoURI == "" {
+		return nil, fmt.Errorf("MONGODB_URI is required to generate a dataset from the index; pass -dataset instead")
+	}
+	mongoDatabase := os.Getenv("MONGODB_DATABASE")
+	if mongoDatabase == "" {
+		mongoDatabase = "coordinator_db1"
+	}
+
+	storage.InitCollectionPrefix(profile)
+	mongoDatabase, err := storage.SelectWorkspace(workspace, mongoDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	codeIndexStorage, err := storage.NewCodeIndexStorage(client.Database(mongoDatabase))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open code index: %w", err)
+	}
+	chunks, err := codeIndexStorage.SampleChunks(samples)
+	if err != nil {
+		return nil, err
+	}
+	return embedbench.GenerateDataset(chunks, queries)
+}
+
+// benchProviders builds a client for each requested provider, or for every
+// provider with configuration when none are named
+func benchProviders(names string) []embedbench.Provider {
+	explicit := names != ""
+	if !explicit {
+		names = "ollama,local,openai,voyage"
+	}
+
+	var providers []embedbench.Provider
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "ollama":
+			url, model := os.Getenv("OLLAMA_URL"), os.Getenv("OLLAMA_MODEL")
+			client, err := embeddings.NewOllamaClient(url, model)
+			if err != nil {
+				// Ollama is probed by default, so only report it when asked for
+				if explicit {
+					fmt.Fprintf(os.Stderr, "⚠ Skipping ollama: %v\n", err)
+				}
+				continue
+			}
+			providers = append(providers, embedbench.Provider{Name: name, Client: client})
+		case "local":
+			url := os.Getenv("TEI_URL")
+			if url == "" {
+				if explicit {
+					fmt.Fprintln(os.Stderr, "⚠ Skipping local: TEI_URL is not set")
+				}
+				continue
+			}
+			providers = append(providers, embedbench.Provider{Name: name, Client: embeddings.NewTEIClient(url)})
+		case "openai":
+			key := os.Getenv("OPENAI_API_KEY")
+			if key == "" {
+				if explicit {
+					fmt.Fprintln(os.Stderr, "⚠ Skipping openai: OPENAI_API_KEY is not set")
+				}
+				continue
+			}
+			providers = append(providers, embedbench.Provider{Name: name, Client: embeddings.NewOpenAIClient(key)})
+		case "voyage":
+			key := os.Getenv("VOYAGE_API_KEY")
+			if key == "" {
+				if explicit {
+					fmt.Fprintln(os.Stderr, "⚠ Skipping voyage: VOYAGE_API_KEY is not set")
+				}
+				continue
+			}
+			client := embeddings.NewVoyageClient(key)
+			if model := os.Getenv("VOYAGE_MODEL"); model != "" {
+				client = embeddings.NewVoyageClientWithModel(key, model)
+			}
+			providers = append(providers, embedbench.Provider{Name: name, Client: client})
+		default:
+			fmt.Fprintf(os.Stderr, "⚠ Unknown provider %q (use ollama, local, openai or voyage)\n", name)
+		}
+	}
+	return providers
+}
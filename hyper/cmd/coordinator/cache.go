@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/handlers"
+	mcphandlers "hyper/internal/mcp/handlers"
+	"hyper/internal/middleware"
+	"hyper/internal/replica"
+	"hyper/internal/vectortier"
+
+	"github.com/gin-gonic/gin"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// cacheSearchRequest is the body of POST /api/v1/code-index/search on a cache
+type cacheSearchRequest struct {
+	Query      string `json:"query" binding:"required"`
+	Limit      int    `json:"limit,omitempty"`
+	FolderPath string `json:"folderPath,omitempty"`
+}
+
+// runCache runs a read-only cache instance (--mode cache): it syncs the
+// CACHE_COLLECTIONS knowledge collections and CACHE_CODE_FOLDERS code folders
+// from CACHE_PRIMARY_URL into memory and answers knowledge queries and code
+// searches from that copy, without MongoDB or Qdrant
+func runCache(logger *zap.Logger) {
+	cfg, err := replica.LoadConfig()
+	if err != nil {
+		logger.Fatal("Invalid cache configuration", zap.Error(err))
+	}
+
+	httpPort := os.Getenv("HTTP_PORT")
+	if httpPort == "" {
+		httpPort = "7095"
+	}
+
+	ctx, stop := setupSignalHandler()
+	defer stop()
+
+	// Queries must be embedded with the primary's models to match its vectors
+	embeddingClient, knowledgeEmbeddingClient, _ := newEmbeddingClients(logger)
+	store := replica.NewStore(knowledgeEmbeddingClient, embeddingClient)
+	store.SetMemoryBudget(cfg.MemoryBudget)
+	archiveCfg, err := vectortier.LoadConfig()
+	if err != nil {
+		logger.Warn("Invalid vector archive configuration; snapshot disk use is not measured", zap.Error(err))
+	} else {
+		store.SetDiskBudget(archiveCfg.Dir, archiveCfg.MaxDiskBytes)
+	}
+	syncer := replica.NewSyncer(cfg, store, logger)
+	syncer.Start(ctx)
+
+	toolHandler := mcphandlers.NewToolHandler(nil, store, nil)
+	toolHandler.SetMetadataRegistry(mcphandlers.NewToolMetadataRegistry())
+	mcpServer := mcp.NewServer(&mcp.Implementation{
+		Name:    "hyperion-coordinator-cache",
+		Version: "2.0.0",
+	}, nil)
+	if err := toolHandler.RegisterCacheTools(mcpServer, store); err != nil {
+		logger.Fatal("Failed to register cache tools", zap.Error(err))
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "healthy",
+			"service": "hyperion-coordinator-cache",
+			"version": "2.0.0",
+		})
+	})
+
+	authorized := r.Group("/", cacheAuth(cfg.APIToken))
+	authorized.GET("/api/v1/cache/status", func(c *gin.Context) {
+		envelope.OK(c, syncer.Status())
+	})
+
+	knowledgeHandler := handlers.NewKnowledgeHandler(store, logger)
+	knowledgeGroup := authorized.Group("/api/v1/knowledge")
+	knowledgeGroup.POST("/query", knowledgeHandler.QueryKnowledge)
+	knowledgeGroup.GET("/collections", knowledgeHandler.GetAllCollections)
+	knowledgeGroup.GET("/browse", knowledgeHandler.BrowseKnowledge)
+	knowledgeGroup.GET("/popular-collections", knowledgeHandler.GetPopularCollections)
+
+	authorized.POST("/api/v1/code-index/search", func(c *gin.Context) {
+		var req cacheSearchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			errcode.RespondCode(c, errcode.Validation, "Invalid request: "+err.Error())
+			return
+		}
+		limit := req.Limit
+		if limit <= 0 {
+			limit = 10
+		}
+		if limit > 50 {
+			limit = 50
+		}
+		results, err := store.SearchCode(req.Query, req.FolderPath, limit)
+		if err != nil {
+			errcode.Respond(c, err, "Failed to search: "+err.Error())
+			return
+		}
+		envelope.OK(c, gin.H{
+			"success":      true,
+			"query":        req.Query,
+			"retrieveMode": "chunk",
+			"results":      results,
+			"count":        len(results),
+			"cached":       true,
+		})
+	})
+
+	mcpHandler := mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server {
+		return mcpServer
+	}, &mcp.StreamableHTTPOptions{})
+	authorized.Any("/mcp", middleware.MCPProtocolVersionMiddleware(logger), gin.WrapH(mcpHandler))
+
+	srv := &http.Server{Addr: ":" + httpPort, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Cache server error", zap.Error(err))
+		}
+	}()
+
+	logger.Info("Cache instance started",
+		zap.String("port", httpPort),
+		zap.String("primary", cfg.PrimaryURL),
+		zap.Strings("collections", cfg.Collections),
+		zap.Strings("codeFolders", cfg.CodeFolders),
+		zap.Duration("syncInterval", cfg.SyncInterval),
+		zap.Duration("fullSyncInterval", cfg.FullSyncInterval),
+		zap.Int64("memoryBudgetBytes", cfg.MemoryBudget))
+
+	<-ctx.Done()
+	logger.Info("Shutdown signal received, stopping cache server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Cache server shutdown error", zap.Error(err))
+	}
+	logger.Info("Cache shutdown complete")
+}
+
+// cacheAuth requires "Authorization: Bearer <token>" when token is set
+func cacheAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			errcode.RespondCode(c, errcode.Unauthenticated, "A valid cache API token is required: Authorization: Bearer <token>")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"hyper/internal/compose"
+	"hyper/internal/setup"
+)
+
+const composeUsage = "usage: hyper compose generate [flags]"
+
+// runCompose implements `hyper compose generate`: writes a docker-compose.yml
+// for the configuration, with bind mounts for the indexed folders
+func runCompose(args []string) {
+	if len(args) == 0 || args[0] != "generate" {
+		fmt.Fprintln(os.Stderr, composeUsage)
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("compose generate", flag.ExitOnError)
+	configPath := fs.String("config", "", "Config file to read (default: ./.env.hyper[.<profile>])")
+	profile := fs.String("profile", os.Getenv("HYPER_PROFILE"), "Config profile to read")
+	output := fs.String("o", "docker-compose.yml", "File to write, or - for stdout")
+	force := fs.Bool("force", false, "Overwrite an existing file")
+	image := fs.String("image", compose.DefaultImage, "Coordinator image")
+	mongo := fs.String("mongo", compose.ModeAuto, "Run MongoDB in the compose file: auto (when MONGODB_URI is local), yes or no")
+	qdrant := fs.String("qdrant", compose.ModeAuto, "Run Qdrant in the compose file: auto (when QDRANT_URL is local), yes or no")
+	ollama := fs.String("ollama", compose.ModeAuto, "Run Ollama in the compose file: auto (when embedding with a local Ollama), yes or no")
+	fs.Parse(args[1:])
+	if *configPath == "" {
+		*configPath = "./" + setup.EnvFileNameFor(*profile)
+	}
+
+	env, files, err := setup.ReadEnvFiles(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Failed to read %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	configAbs, err := filepath.Abs(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+	outputDir := "."
+	if *output != "-" {
+		outputDir = filepath.Dir(*output)
+	}
+	outputAbs, err := filepath.Abs(outputDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+	// The overrides file is passed too, after the base file so it wins
+	envFiles := make([]string, 0, len(files))
+	for _, file := range files {
+		fileAbs, err := filepath.Abs(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			os.Exit(1)
+		}
+		envFile, err := filepath.Rel(outputAbs, fileAbs)
+		if err != nil {
+			envFile = fileAbs
+		}
+		envFiles = append(envFiles, filepath.ToSlash(envFile))
+	}
+
+	result, err := compose.Generate(compose.Options{
+		Env:      env,
+		EnvFiles: envFiles,
+		BaseDir:  filepath.Dir(configAbs),
+		Image:    *image,
+		Mongo:    *mongo,
+		Qdrant:   *qdrant,
+		Ollama:   *ollama,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "-" {
+		os.Stdout.Write(result.YAML)
+		return
+	}
+	if _, err := os.Stat(*output); err == nil && !*force {
+		fmt.Fprintf(os.Stderr, "✗ %s already exists; pass -force to overwrite it\n", *output)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, result.YAML, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ Wrote %s\n", *output)
+	for _, service := range result.Services {
+		fmt.Printf("  service: %s\n", service)
+	}
+	for _, mount := range result.Mounts {
+		fmt.Printf("  mount:   %s → %s\n", mount.Host, mount.Container)
+	}
+	for _, note := range result.Notes {
+		fmt.Printf("  note:    %s\n", note)
+	}
+	fmt.Println("  Start it with `docker compose up -d`.")
+}
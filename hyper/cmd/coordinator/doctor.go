@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hyper/internal/doctor"
+)
+
+const doctorUsage = "usage: hyper doctor claude [flags]"
+
+// runDoctor implements `hyper doctor`: diagnoses how an MCP client is
+// connected to the coordinator and prints what to fix
+func runDoctor(args []string) {
+	if len(args) == 0 || args[0] != "claude" {
+		fmt.Fprintln(os.Stderr, doctorUsage)
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("doctor claude", flag.ExitOnError)
+	server := fs.String("server", "", "Name of the MCP server entry to check (default: every entry that looks like hyper)")
+	dir := fs.String("dir", "", "Project directory Claude Code runs in (default: current directory)")
+	timeout := fs.Duration("timeout", time.Minute, "Bound of each initialize/tools/call round trip")
+	configOnly := fs.Bool("config-only", false, "Only check the configuration, without starting or calling the server")
+	fs.Parse(args[1:])
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Failed to locate the home directory: %v\n", err)
+		os.Exit(1)
+	}
+	if *dir == "" {
+		if *dir, err = os.Getwd(); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *dir, err = filepath.Abs(*dir); err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+	executable, err := os.Executable()
+	if err == nil {
+		executable, _ = filepath.EvalSymlinks(executable)
+	}
+
+	fmt.Printf("Checking the Claude Code connection for %s\n\n", *dir)
+	report := doctor.DiagnoseClaude(context.Background(), doctor.ClaudeOptions{
+		Home:          home,
+		Dir:           *dir,
+		Server:        *server,
+		Executable:    executable,
+		Timeout:       *timeout,
+		SkipRoundTrip: *configOnly,
+	})
+	report.Write(os.Stdout)
+	if report.Failed() {
+		os.Exit(1)
+	}
+}
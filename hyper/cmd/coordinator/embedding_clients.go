@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+
+	"go.uber.org/zap"
+)
+
+// newEmbeddingClients creates the embedding client selected by EMBEDDING and
+// the one used for knowledge, which routes non-English text to the
+// multilingual model when MULTILINGUAL_EMBEDDING_MODEL is set. Invalid
+// configuration is fatal.
+func newEmbeddingClients(logger *zap.Logger) (embeddingClient, knowledgeEmbeddingClient embeddings.EmbeddingClient, embeddingMode string) {
+	embeddingMode = os.Getenv("EMBEDDING")
+	if embeddingMode == "" {
+		embeddingMode = "ollama" // Default to Ollama (GPU-accelerated llama.cpp as a service)
+	}
+
+	logger.Info("Initializing embedding client", zap.String("mode", embeddingMode))
+
+	opts, err := embeddingOptions(embeddingMode)
+	if err != nil {
+		logger.Fatal("Invalid embedding client configuration", zap.Error(err))
+	}
+
+	switch embeddingMode {
+	case "ollama":
+		// Use Ollama (default - GPU-accelerated llama.cpp as a service)
+		// Requires: brew install ollama && ollama pull nomic-embed-text
+		ollamaURL := os.Getenv("OLLAMA_URL")
+		if ollamaURL == "" {
+			ollamaURL = "http://localhost:11434"
+		}
+		ollamaModel := os.Getenv("OLLAMA_MODEL")
+		if ollamaModel == "" {
+			ollamaModel = "nomic-embed-text"
+		}
+
+		embeddingClient, err = embeddings.NewOllamaClient(ollamaURL, ollamaModel, opts...)
+		if err != nil {
+			logger.Fatal("Failed to initialize Ollama embedding client",
+				zap.Error(err),
+				zap.String("url", ollamaURL),
+				zap.String("model", ollamaModel),
+				zap.String("hint", "Install: brew install ollama && ollama pull <model> && brew services start ollama"))
+		}
+		logger.Info("Using Ollama embeddings (GPU-accelerated via llama.cpp)",
+			zap.String("url", ollamaURL),
+			zap.String("model", ollamaModel),
+			zap.Int("dimensions", embeddingClient.GetDimensions()),
+			zap.String("backend", "Metal/CUDA/Vulkan (auto-detected by Ollama)"))
+
+	case "local":
+		// Use local TEI service (Hugging Face Text Embeddings Inference)
+		teiURL := os.Getenv("TEI_URL")
+		if teiURL == "" {
+			teiURL = "http://embedding-service:8080" // Default TEI URL
+		}
+		embeddingClient = embeddings.NewTEIClient(teiURL, opts...)
+		logger.Info("Using local TEI embedding service",
+			zap.String("url", teiURL),
+			zap.String("model", "nomic-ai/nomic-embed-text-v1.5"),
+			zap.Int("dimensions", 768))
+
+	case "openai":
+		// Use OpenAI embeddings
+		openAIKey := os.Getenv("OPENAI_API_KEY")
+		if openAIKey == "" {
+			logger.Fatal("OPENAI_API_KEY is required when EMBEDDING=openai")
+		}
+		embeddingClient = embeddings.NewOpenAIClient(openAIKey, opts...)
+		logger.Info("Using OpenAI embedding service",
+			zap.String("model", "text-embedding-3-small"),
+			zap.Int("dimensions", embeddingClient.GetDimensions()))
+
+	case "voyage":
+		// Use Voyage AI embeddings (Anthropic's recommended provider)
+		voyageKey := os.Getenv("VOYAGE_API_KEY")
+		if voyageKey == "" {
+			logger.Fatal("VOYAGE_API_KEY is required when EMBEDDING=voyage")
+		}
+
+		// Allow optional model override via VOYAGE_MODEL env var
+		voyageModel := os.Getenv("VOYAGE_MODEL")
+		if voyageModel != "" {
+			embeddingClient = embeddings.NewVoyageClientWithModel(voyageKey, voyageModel, opts...)
+			logger.Info("Using Voyage AI embedding service",
+				zap.String("model", voyageModel),
+				zap.Int("dimensions", embeddingClient.GetDimensions()))
+		} else {
+			embeddingClient = embeddings.NewVoyageClient(voyageKey, opts...)
+			logger.Info("Using Voyage AI embedding service",
+				zap.String("model", "voyage-3"),
+				zap.Int("dimensions", embeddingClient.GetDimensions()),
+				zap.String("pricing", "$0.06/1M tokens"))
+		}
+
+	default:
+		logger.Fatal("Invalid EMBEDDING mode. Use 'ollama' (default), 'llama', 'local', 'openai', or 'voyage'",
+			zap.String("mode", embeddingMode))
+	}
+
+	// Optionally embed non-English knowledge with a multilingual model. Code
+	// chunks keep the primary model, so only knowledge is routed.
+	knowledgeEmbeddingClient = embeddingClient
+	multilingualClient, err := multilingualEmbeddingClient(embeddingMode, opts...)
+	if err != nil {
+		logger.Fatal("Failed to initialize multilingual embedding client", zap.Error(err))
+	}
+	if multilingualClient != nil {
+		router, err := embeddings.NewLanguageRouter(embeddingClient, multilingualClient)
+		if err != nil {
+			logger.Fatal("Multilingual embedding model cannot share collections with the primary model",
+				zap.Error(err),
+				zap.String("model", os.Getenv("MULTILINGUAL_EMBEDDING_MODEL")))
+		}
+		knowledgeEmbeddingClient = router
+		logger.Info("Routing non-English knowledge to multilingual embedding model",
+			zap.String("model", os.Getenv("MULTILINGUAL_EMBEDDING_MODEL")))
+	}
+
+	return embeddingClient, knowledgeEmbeddingClient, embeddingMode
+}
+
+// embeddingOptions reads the settings for reaching the embedding provider
+// through a proxy or gateway: EMBEDDING_BASE_URL (OpenAI and Voyage, whose
+// endpoints are otherwise fixed), EMBEDDING_TIMEOUT, EMBEDDING_DIMENSIONS and
+// EMBEDDING_HEADERS ("Name: value" pairs separated by newlines or ";").
+// HTTPS_PROXY is honored without any of them.
+func embeddingOptions(embeddingMode string) ([]embeddings.Option, error) {
+	var opts []embeddings.Option
+	if baseURL := os.Getenv("EMBEDDING_BASE_URL"); baseURL != "" {
+		if embeddingMode != "openai" && embeddingMode != "voyage" {
+			return nil, fmt.Errorf("EMBEDDING_BASE_URL is only used with EMBEDDING=openai or voyage; set OLLAMA_URL or TEI_URL instead")
+		}
+		opts = append(opts, embeddings.WithBaseURL(baseURL))
+	}
+	if value := os.Getenv("EMBEDDING_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("EMBEDDING_TIMEOUT must be a positive duration such as 45s, got %q", value)
+		}
+		opts = append(opts, embeddings.WithTimeout(timeout))
+	}
+	if value := os.Getenv("EMBEDDING_DIMENSIONS"); value != "" {
+		var dimensions int
+		if _, err := fmt.Sscan(value, &dimensions); err != nil || dimensions <= 0 {
+			return nil, fmt.Errorf("EMBEDDING_DIMENSIONS must be a positive number, got %q", value)
+		}
+		opts = append(opts, embeddings.WithDimensions(dimensions))
+	}
+	for _, header := range strings.FieldsFunc(os.Getenv("EMBEDDING_HEADERS"), func(r rune) bool { return r == '\n' || r == ';' }) {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("EMBEDDING_HEADERS entries must look like \"Name: value\", got %q", strings.TrimSpace(header))
+		}
+		opts = append(opts, embeddings.WithHeader(strings.TrimSpace(name), strings.TrimSpace(value)))
+	}
+	return opts, nil
+}
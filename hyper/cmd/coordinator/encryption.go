@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/setup"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// runRotateEncryptionKey implements `hyper rotate-encryption-key`: it
+// re-seals encrypted task and knowledge fields with the active key, and seals
+// fields stored before encryption was enabled. Run it after adding a new key
+// as FIELD_ENCRYPTION_ACTIVE_KEY; the old key can be removed once it reports
+// nothing skipped.
+func runRotateEncryptionKey(args []string) {
+	fs := flag.NewFlagSet("rotate-encryption-key", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to config file (default: ./.env.hyper[.<profile>])")
+	profile := fs.String("profile", os.Getenv("HYPER_PROFILE"), "Config profile")
+	workspace := fs.String("workspace", os.Getenv("HYPER_WORKSPACE"), "Workspace from WORKSPACE_DATABASES")
+	fs.Parse(args)
+
+	path := *configPath
+	if path == "" {
+		path = setup.EnvFileNameFor(*profile)
+	}
+	if files, err := setup.LoadEnvFiles(path, false); err == nil {
+		fmt.Printf("✓ Loaded configuration from: %s\n", strings.Join(files, ", "))
+	}
+
+	if err := rotateEncryptionKey(*profile, *workspace); err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func rotateEncryptionKey(profile, workspace string) error {
+	cipher, err := storage.LoadFieldCipher()
+	if err != nil {
+		return err
+	}
+	if cipher == nil {
+		return fmt.Errorf("FIELD_ENCRYPTION_KEYS is not set")
+	}
+
+	mongoURI := os.Getenv("MONGODB_URI")
+	if mongoURI == "" {
+		return fmt.Errorf("MONGODB_URI is required")
+	}
+	mongoDatabase := os.Getenv("MONGODB_DATABASE")
+	if mongoDatabase == "" {
+		mongoDatabase = "coordinator_db1"
+	}
+
+	storage.InitCollectionPrefix(profile)
+	mongoDatabase, err = storage.SelectWorkspace(workspace, mongoDatabase)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	defer client.Disconnect(context.Background())
+	db := client.Database(mongoDatabase)
+
+	taskStorage, err := storage.NewMongoTaskStorage(db)
+	if err != nil {
+		return fmt.Errorf("failed to open task storage: %w", err)
+	}
+	taskStorage.SetFieldCipher(cipher)
+	knowledgeStorage, err := storage.NewMongoKnowledgeStorage(db, nil)
+	if err != nil {
+		return fmt.Errorf("failed to open knowledge storage: %w", err)
+	}
+	knowledgeStorage.SetFieldCipher(cipher)
+
+	fmt.Printf("Rotating encrypted fields to key %q\n", cipher.ActiveKey())
+	tasks, err := taskStorage.RotateFieldEncryption(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  tasks:     %d scanned, %d rotated, %d skipped\n", tasks.Scanned, tasks.Rotated, tasks.Skipped)
+	knowledge, err := knowledgeStorage.RotateFieldEncryption(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  knowledge: %d scanned, %d rotated, %d skipped\n", knowledge.Scanned, knowledge.Rotated, knowledge.Skipped)
+
+	if tasks.Skipped+knowledge.Skipped > 0 {
+		fmt.Println("Some documents changed during rotation; run again before removing old keys")
+	}
+	return nil
+}
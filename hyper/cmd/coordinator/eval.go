@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/middleware"
+)
+
+// runEvalKnowledge implements `hyper eval-knowledge`: runs the knowledge
+// retrieval evaluation suites on a running coordinator, prints recall and
+// precision with their drift since the previous run, and exits 1 when a
+// suite falls below its thresholds, for CI
+func runEvalKnowledge(args []string) {
+	defaultURL := os.Getenv("HYPER_URL")
+	if defaultURL == "" {
+		defaultURL = "http://localhost:7095"
+	}
+
+	fs := flag.NewFlagSet("eval-knowledge", flag.ExitOnError)
+	url := fs.String("url", defaultURL, "Coordinator HTTP address (HYPER_URL)")
+	apiKey := fs.String("api-key", os.Getenv("HYPER_API_KEY"), "API key sent as "+middleware.APIKeyHeader+" (HYPER_API_KEY)")
+	suiteFile := fs.String("suite", "", "JSON file with a suite, or an array of suites, to save before running")
+	collection := fs.String("collection", "", "Only run the suite of this collection (default: every suite)")
+	minRecall := fs.Float64("min-recall", 0, "Fail when mean recall@k is below this, instead of the suite's threshold")
+	minPrecision := fs.Float64("min-precision", 0, "Fail when mean precision@k is below this, instead of the suite's threshold")
+	timeout := fs.Duration("timeout", 5*time.Minute, "Bound of each request")
+	fs.Parse(args)
+
+	overrides := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { overrides[f.Name] = true })
+
+	client := &evalClient{url: strings.TrimRight(*url, "/"), apiKey: *apiKey, http: &http.Client{Timeout: *timeout}}
+
+	if *suiteFile != "" {
+		suites, err := readEvalSuites(*suiteFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			os.Exit(1)
+		}
+		for _, suite := range suites {
+			request := map[string]interface{}{
+				"collection":   suite.Collection,
+				"cases":        suite.Cases,
+				"minRecall":    suite.MinRecall,
+				"minPrecision": suite.MinPrecision,
+			}
+			if suite.K > 0 {
+				request["k"] = suite.K
+			}
+			if err := client.call("coordinator_set_knowledge_eval", request, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "✗ Failed to save the suite of %s: %v\n", suite.Collection, err)
+				os.Exit(1)
+			}
+			fmt.Printf("✓ Saved the suite of %s (%d questions)\n", suite.Collection, len(suite.Cases))
+		}
+	}
+
+	var result struct {
+		Runs []*storage.KnowledgeEvalRun `json:"runs"`
+	}
+	request := map[string]interface{}{}
+	if *collection != "" {
+		request["collection"] = *collection
+	}
+	if err := client.call("coordinator_run_knowledge_eval", request, &result); err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Knowledge evaluation failed: %v\n", err)
+		os.Exit(1)
+	}
+	if len(result.Runs) == 0 {
+		fmt.Println("No evaluation suites defined: save one with -suite or coordinator_set_knowledge_eval")
+		return
+	}
+
+	failed := false
+	for _, run := range result.Runs {
+		failures := run.Failures
+		if overrides["min-recall"] || overrides["min-precision"] {
+			failures = nil
+			if overrides["min-recall"] && run.Recall < *minRecall {
+				failures = append(failures, fmt.Sprintf("recall@%d %.2f is below the minimum %.2f", run.K, run.Recall, *minRecall))
+			}
+			if overrides["min-precision"] && run.Precision < *minPrecision {
+				failures = append(failures, fmt.Sprintf("precision@%d %.2f is below the minimum %.2f", run.K, run.Precision, *minPrecision))
+			}
+		}
+		printEvalRun(run, failures)
+		failed = failed || len(failures) > 0
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// printEvalRun prints the scores of a run, their drift and the questions
+// that missed expected entries
+func printEvalRun(run *storage.KnowledgeEvalRun, failures []string) {
+	mark := "✓"
+	if len(failures) > 0 {
+		mark = "✗"
+	}
+	fmt.Printf("%s %s  recall@%d %.2f%s  precision@%d %.2f%s  MRR %.2f  (%d questions)\n",
+		mark, run.Collection,
+		run.K, run.Recall, formatDrift(run.RecallDelta),
+		run.K, run.Precision, formatDrift(run.PrecisionDelta),
+		run.MRR, len(run.Cases))
+	for _, c := range run.Cases {
+		switch {
+		case c.Error != "":
+			fmt.Printf("    ✗ %q: %s\n", c.Question, c.Error)
+		case len(c.Missing) > 0:
+			fmt.Printf("    ✗ %q: missing %s\n", c.Question, strings.Join(c.Missing, ", "))
+		}
+	}
+	for _, failure := range failures {
+		fmt.Printf("    %s\n", failure)
+	}
+}
+
+// formatDrift formats the change since the previous run, if there was one
+func formatDrift(delta *float64) string {
+	if delta == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.2f)", *delta)
+}
+
+// readEvalSuites reads one suite or an array of suites from a JSON file
+func readEvalSuites(path string) ([]storage.KnowledgeEvalSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite file: %w", err)
+	}
+	var suites []storage.KnowledgeEvalSuite
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		suites = make([]storage.KnowledgeEvalSuite, 1)
+		err = json.Unmarshal(trimmed, &suites[0])
+	} else {
+		err = json.Unmarshal(data, &suites)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid suite file %s: %w", path, err)
+	}
+	return suites, nil
+}
+
+// evalClient calls coordinator tools through the REST tool proxy
+type evalClient struct {
+	url    string
+	apiKey string
+	http   *http.Client
+}
+
+// call posts args to /api/tools/<tool> and decodes the tool result into
+// result, when not nil
+func (c *evalClient) call(tool string, args interface{}, result interface{}) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url+"/api/tools/"+tool, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set(middleware.APIKeyHeader, c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Data *struct {
+			Result json.RawMessage `json:"result"`
+		} `json:"data"`
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("unexpected response (HTTP %d) from %s", resp.StatusCode, c.url)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%s: %s", response.Error.Code, response.Error.Message)
+	}
+	if response.Data == nil {
+		return fmt.Errorf("unexpected response (HTTP %d) from %s", resp.StatusCode, c.url)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Data.Result, result)
+}
@@ -8,19 +8,40 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"hyper/embed"
+	aiservice "hyper/internal/ai-service"
 	"hyper/internal/ai-service/tools"
+	"hyper/internal/automation"
+	"hyper/internal/bulktasks"
+	"hyper/internal/console"
+	"hyper/internal/digest"
+	"hyper/internal/escalation"
+	"hyper/internal/federation"
+	"hyper/internal/integrity"
+	"hyper/internal/k8s"
+	"hyper/internal/knowledgeeval"
+	"hyper/internal/jira"
+	"hyper/internal/linear"
+	"hyper/internal/reembed"
+	"hyper/internal/schedule"
 	"hyper/internal/server"
 	"hyper/internal/mcp/embeddings"
 	"hyper/internal/mcp/handlers"
 	"hyper/internal/mcp/storage"
 	"hyper/internal/mcp/watcher"
+	"hyper/internal/middleware"
+	"hyper/internal/notify"
+	"hyper/internal/setup"
+	"hyper/internal/taskevents"
+	"hyper/internal/undo"
+	"hyper/internal/update"
+	"hyper/internal/vectortier"
 
-	"github.com/joho/godotenv"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -28,139 +49,243 @@ import (
 )
 
 // ensureCodeIndexCollectionWithDimensions ensures the code index collection exists with the correct dimensions
-// If a dimension mismatch is detected, prompts the user to recreate the collection
-func ensureCodeIndexCollectionWithDimensions(qdrantClient *storage.QdrantClient, expectedDimensions int, logger *zap.Logger) error {
+// On a dimension mismatch it reports that the code index must be re-embedded
+// into a new collection version (see package reembed), unless
+// CODE_INDEX_AUTO_RECREATE=true asks for an empty collection instead
+func ensureCodeIndexCollectionWithDimensions(qdrantClient *storage.QdrantClient, expectedDimensions int, logger *zap.Logger) (reembed bool, err error) {
 	// Try to create the collection with dimension check
-	err := qdrantClient.EnsureCodeIndexCollection(expectedDimensions)
+	err = qdrantClient.EnsureCodeIndexCollection(expectedDimensions)
 	if err == nil {
 		logger.Info("Code index collection ready",
 			zap.String("collection", storage.CodeIndexCollection),
 			zap.Int("dimensions", expectedDimensions))
-		return nil
+		return false, nil
 	}
 
 	// Check if it's a dimension mismatch error
 	var dimErr *storage.DimensionMismatchError
 	if !errors.As(err, &dimErr) {
 		// Not a dimension mismatch, return the error
-		return err
+		return false, err
 	}
 
-	// Dimension mismatch detected - prompt user or auto-recreate
 	logger.Warn("Vector dimension mismatch detected",
 		zap.String("collection", dimErr.Collection),
 		zap.Int("expected", dimErr.ExpectedDim),
 		zap.Int("got", expectedDimensions))
 
-	// Check if auto-recreate is enabled via environment variable
-	autoRecreate := os.Getenv("CODE_INDEX_AUTO_RECREATE")
-	if autoRecreate == "true" {
-		logger.Info("CODE_INDEX_AUTO_RECREATE=true, automatically recreating collection")
-	} else {
-		// Prompt user for confirmation
-		fmt.Printf("\n")
-		fmt.Printf("⚠️  Vector Dimension Mismatch Detected\n")
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("Collection:      %s\n", dimErr.Collection)
-		fmt.Printf("Current dims:    %d (in Qdrant)\n", dimErr.ExpectedDim)
-		fmt.Printf("Expected dims:   %d (from %s)\n", expectedDimensions, os.Getenv("OLLAMA_MODEL"))
-		fmt.Printf("\n")
-		fmt.Printf("This usually happens when you switch embedding models.\n")
-		fmt.Printf("\n")
-		fmt.Printf("⚠️  WARNING: Recreating will DELETE ALL indexed code!\n")
-		fmt.Printf("You will need to re-scan your folders after recreation.\n")
-		fmt.Printf("\n")
-		fmt.Printf("Do you want to recreate the collection? (yes/no): ")
-
-		// Read user input
-		var response string
-		fmt.Scanln(&response)
-
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "yes" && response != "y" {
-			return fmt.Errorf("user declined to recreate collection - cannot proceed with dimension mismatch")
-		}
+	if os.Getenv("CODE_INDEX_AUTO_RECREATE") != "true" {
+		// Keep the current collection for searches while the chunks stored in
+		// MongoDB are re-embedded into the next version
+		return true, nil
 	}
 
-	// User agreed - recreate the collection
-	logger.Info("Recreating code index collection", zap.Int("newDimensions", expectedDimensions))
-	if err := qdrantClient.RecreateCodeIndexCollection(expectedDimensions); err != nil {
-		return fmt.Errorf("failed to recreate collection: %w", err)
+	logger.Info("CODE_INDEX_AUTO_RECREATE=true, recreating collection empty", zap.Int("newDimensions", expectedDimensions))
+	migration, err := qdrantClient.RecreateCodeIndexCollection(expectedDimensions)
+	if err != nil {
+		return false, fmt.Errorf("failed to recreate collection: %w", err)
 	}
 
-	fmt.Printf("\n")
-	fmt.Printf("✅ Collection recreated successfully with %d dimensions\n", expectedDimensions)
-	fmt.Printf("🔄 You can now re-scan your code folders\n")
-	fmt.Printf("\n")
+	console.Printf("\n")
+	console.Printf("✅ Collection recreated successfully with %d dimensions\n", expectedDimensions)
+	console.Printf("🔄 You can now re-scan your code folders\n")
+	console.Printf("\n")
 
 	logger.Info("Code index collection recreated",
 		zap.String("collection", storage.CodeIndexCollection),
+		zap.String("version", migration.Collection),
+		zap.String("previous", migration.Previous),
+		zap.Bool("replacedPlainCollection", migration.ReplacedPlain),
 		zap.Int("dimensions", expectedDimensions))
 
-	return nil
+	return false, nil
 }
 
 func main() {
+	// Connect to the Windows service control manager when started by it
+	startServiceMode()
+	defer finishServiceMode()
+
 	// Initialize project root detection
 	if err := tools.InitProjectRoot(); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to detect project root: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Printf("Project root: %s\n", tools.GetProjectRoot())
+
+	// Subcommands: `hyper init` writes .env.hyper interactively,
+	// `hyper bench-embeddings` compares embedding providers, `hyper service`
+	// installs the coordinator as a background service, `hyper self-update`
+	// installs the latest release, `hyper rotate-encryption-key` re-seals
+	// encrypted fields with the active key, `hyper doctor claude` diagnoses
+	// the Claude Code connection, `hyper compose generate` writes a
+	// docker-compose.yml for the configuration, `hyper eval-knowledge` runs
+	// the knowledge retrieval evaluation suites of a running coordinator
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "init":
+			runInit(os.Args[2:])
+			return
+		case "bench-embeddings":
+			runBenchEmbeddings(os.Args[2:])
+			return
+		case "service":
+			runServiceCommand(os.Args[2:])
+			return
+		case "self-update":
+			runSelfUpdate(os.Args[2:])
+			return
+		case "rotate-encryption-key":
+			runRotateEncryptionKey(os.Args[2:])
+			return
+		case "doctor":
+			runDoctor(os.Args[2:])
+			return
+		case "compose":
+			runCompose(os.Args[2:])
+			return
+		case "eval-knowledge":
+			runEvalKnowledge(os.Args[2:])
+			return
+		}
+	}
 
 	// Parse command-line flags
-	mode := flag.String("mode", "both", "Server mode: http, mcp, or both")
+	mode := flag.String("mode", "both", "Server mode: http, mcp, both, or cache (read-only replica of a primary)")
 	configPath := flag.String("config", "", "Path to config file (default: .env.hyper in executable or current dir)")
+	profile := flag.String("profile", os.Getenv("HYPER_PROFILE"), "Config profile: loads .env.hyper.<profile> and prefixes collection names with <profile>_")
+	workspace := flag.String("workspace", os.Getenv("HYPER_WORKSPACE"), "Workspace to serve: uses its database and collection prefix from WORKSPACE_DATABASES")
+	logFile := flag.String("log-file", "", "Append all output to this file instead of the console (used by the Windows service)")
+	k8sMode := flag.Bool("k8s", false, "Run as a Kubernetes workload: HTTP only, secrets from mounted files, JSON logs, /readyz and a drain on shutdown")
 	flag.Parse()
 
+	if *k8sMode {
+		if *mode == "both" {
+			*mode = "http"
+		}
+		if *mode != "http" {
+			fmt.Fprintln(os.Stderr, "--k8s requires --mode=http")
+			os.Exit(1)
+		}
+	}
+
+	if *logFile != "" {
+		if *mode != "http" {
+			fmt.Fprintln(os.Stderr, "--log-file requires --mode=http: over stdio, stdout carries the MCP protocol")
+			os.Exit(1)
+		}
+		f, err := os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open log file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		os.Stdout, os.Stderr = f, f
+	}
+
+	// Over stdio, stdout carries only MCP frames: human-facing output goes to
+	// stderr, and anything else printed to stdout is diverted there too
+	if *mode == "mcp" || *mode == "both" {
+		console.UseStdioTransport()
+		restoreStdout, err := console.GuardStdout()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to guard stdout: %v\n", err)
+			os.Exit(1)
+		}
+		defer restoreStdout()
+	}
+	console.Printf("Project root: %s\n", tools.GetProjectRoot())
+
 	// Load .env.hyper file if it exists (prefer over system env vars)
-	// This allows native binary to have its own configuration without affecting system
+	// This allows native binary to have its own configuration without affecting system.
+	// Gitignored overrides in .env.hyper.local are layered on top.
+	envFileName := setup.EnvFileNameFor(*profile)
 
 	// If custom config path provided, use it exclusively
+	configLoaded := false
 	if *configPath != "" {
-		if err := godotenv.Overload(*configPath); err != nil {
+		files, err := setup.LoadEnvFiles(*configPath, true)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "✗ Failed to load config from custom path: %s\n", *configPath)
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("✓ Loaded configuration from custom path: %s\n", *configPath)
+		console.Printf("✓ Loaded configuration from custom path: %s\n", strings.Join(files, ", "))
+		configLoaded = true
 	} else {
 		// Default behavior: try executable dir, then current dir
 		executable, err := os.Executable()
 		if err == nil {
 			execDir := filepath.Dir(executable)
-			envFile := filepath.Join(execDir, ".env.hyper")
+			envFile := filepath.Join(execDir, envFileName)
 
-			// Try to load .env.hyper from executable directory
-			if err := godotenv.Overload(envFile); err == nil {
-				fmt.Printf("✓ Loaded configuration from: %s\n", envFile)
+			// Try to load the env file from executable directory
+			if files, err := setup.LoadEnvFiles(envFile, true); err == nil {
+				console.Printf("✓ Loaded configuration from: %s\n", strings.Join(files, ", "))
+				configLoaded = true
 			} else {
-				fmt.Printf("Debug: Failed to load %s: %v\n", envFile, err)
+				console.Printf("Debug: Failed to load %s: %v\n", envFile, err)
 				// Also try current working directory
-				if err := godotenv.Overload(".env.hyper"); err == nil {
-					fmt.Println("✓ Loaded configuration from: ./.env.hyper")
+				if files, err := setup.LoadEnvFiles(envFileName, true); err == nil {
+					console.Printf("✓ Loaded configuration from: ./%s\n", strings.Join(files, ", ./"))
+					configLoaded = true
 				} else {
-					fmt.Printf("Debug: Failed to load ./.env.hyper: %v\n", err)
+					console.Printf("Debug: Failed to load ./%s: %v\n", envFileName, err)
 					// Debug: Show why loading failed
-					fmt.Printf("Warning: No .env.hyper found (checked: %s and ./.env.hyper)\n", envFile)
+					console.Printf("Warning: No %s found (checked: %s and ./%s)\n", envFileName, envFile, envFileName)
 				}
 			}
 		}
 	}
 
-	// Initialize logger
+	// Initialize logger: JSON for log collectors in Kubernetes
 	logger, err := zap.NewDevelopment()
+	if *k8sMode {
+		logger, err = k8s.NewLogger()
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create logger: %v\n", err)
 		os.Exit(1)
 	}
 	defer logger.Sync()
 
+	// In Kubernetes, credentials come from mounted Secret files rather than
+	// the environment
+	var k8sConfig k8s.Config
+	if *k8sMode {
+		if k8sConfig, err = k8s.LoadConfig(); err != nil {
+			logger.Fatal("Invalid Kubernetes configuration", zap.Error(err))
+		}
+		loaded, err := k8s.LoadSecretFiles(k8sConfig.SecretsDir)
+		if err != nil {
+			logger.Fatal("Failed to load secret files", zap.Error(err))
+		}
+		logger.Info("Loaded secrets from files",
+			zap.String("dir", k8sConfig.SecretsDir),
+			zap.Strings("variables", loaded))
+	}
+
 	logger.Info("Starting Unified Hyperion Coordinator",
-		zap.String("mode", *mode))
+		zap.String("version", Version),
+		zap.String("mode", *mode),
+		zap.String("profile", *profile),
+		zap.String("workspace", *workspace))
+
+	// Opt-in (HYPER_UPDATE_CHECK=true) notice when a newer release exists
+	notifyUpdateAvailable(context.Background(), update.LoadConfig(), logger)
+
+	// A cache instance serves a synced copy of a primary and needs no database
+	if *mode == "cache" {
+		runCache(logger)
+		return
+	}
 
 	// Get MongoDB configuration from environment
 	mongoURI := os.Getenv("MONGODB_URI")
+	if mongoURI == "" && !configLoaded && *mode != "mcp" && !*k8sMode {
+		// First run: serve the setup endpoints instead of failing
+		runSetupServer("./"+envFileName, logger)
+		return
+	}
 	if mongoURI == "" {
 		logger.Fatal("MONGODB_URI environment variable is required")
 	}
@@ -170,6 +295,14 @@ func main() {
 		mongoDatabase = "coordinator_db1"
 	}
 
+	// A workspace can have its own database and collection prefix
+	// (WORKSPACE_DATABASES), so its data grows and is backed up on its own
+	storage.InitCollectionPrefix(*profile)
+	mongoDatabase, err = storage.SelectWorkspace(*workspace, mongoDatabase)
+	if err != nil {
+		logger.Fatal("Invalid workspace configuration", zap.Error(err))
+	}
+
 	logger.Info("Connecting to MongoDB Atlas",
 		zap.String("database", mongoDatabase))
 
@@ -200,6 +333,9 @@ func main() {
 
 	// Initialize Qdrant collection name from environment (must be done before creating qdrant client)
 	storage.InitCodeIndexCollection()
+	if storage.CollectionPrefix != "" {
+		logger.Info("Using collection prefix", zap.String("prefix", storage.CollectionPrefix))
+	}
 
 	// Get Qdrant configuration
 	qdrantURL := os.Getenv("QDRANT_URL")
@@ -213,112 +349,114 @@ func main() {
 		qdrantKnowledgeCollection = "dev_squad_knowledge"
 	}
 
-	// Initialize embedding client based on EMBEDDING environment variable
+	// Initialize embedding clients based on EMBEDDING environment variable
 	// IMPORTANT: This must be created BEFORE qdrantClient to ensure correct embeddings are used
-	var embeddingClient embeddings.EmbeddingClient
-	embeddingMode := os.Getenv("EMBEDDING")
-	if embeddingMode == "" {
-		embeddingMode = "ollama" // Default to Ollama (GPU-accelerated llama.cpp as a service)
-	}
-
-	logger.Info("Initializing embedding client", zap.String("mode", embeddingMode))
-
-	switch embeddingMode {
-	case "ollama":
-		// Use Ollama (default - GPU-accelerated llama.cpp as a service)
-		// Requires: brew install ollama && ollama pull nomic-embed-text
-		ollamaURL := os.Getenv("OLLAMA_URL")
-		if ollamaURL == "" {
-			ollamaURL = "http://localhost:11434"
-		}
-		ollamaModel := os.Getenv("OLLAMA_MODEL")
-		if ollamaModel == "" {
-			ollamaModel = "nomic-embed-text"
-		}
-
-		var err error
-		embeddingClient, err = embeddings.NewOllamaClient(ollamaURL, ollamaModel)
-		if err != nil {
-			logger.Fatal("Failed to initialize Ollama embedding client",
-				zap.Error(err),
-				zap.String("url", ollamaURL),
-				zap.String("model", ollamaModel),
-				zap.String("hint", "Install: brew install ollama && ollama pull <model> && brew services start ollama"))
-		}
-		logger.Info("Using Ollama embeddings (GPU-accelerated via llama.cpp)",
-			zap.String("url", ollamaURL),
-			zap.String("model", ollamaModel),
-			zap.Int("dimensions", embeddingClient.GetDimensions()),
-			zap.String("backend", "Metal/CUDA/Vulkan (auto-detected by Ollama)"))
-
-	case "local":
-		// Use local TEI service (Hugging Face Text Embeddings Inference)
-		teiURL := os.Getenv("TEI_URL")
-		if teiURL == "" {
-			teiURL = "http://embedding-service:8080" // Default TEI URL
-		}
-		embeddingClient = embeddings.NewTEIClient(teiURL)
-		logger.Info("Using local TEI embedding service",
-			zap.String("url", teiURL),
-			zap.String("model", "nomic-ai/nomic-embed-text-v1.5"),
-			zap.Int("dimensions", 768))
-
-	case "openai":
-		// Use OpenAI embeddings
-		openAIKey := os.Getenv("OPENAI_API_KEY")
-		if openAIKey == "" {
-			logger.Fatal("OPENAI_API_KEY is required when EMBEDDING=openai")
-		}
-		embeddingClient = embeddings.NewOpenAIClient(openAIKey)
-		logger.Info("Using OpenAI embedding service",
-			zap.String("model", "text-embedding-3-small"),
-			zap.Int("dimensions", 1536))
-
-	case "voyage":
-		// Use Voyage AI embeddings (Anthropic's recommended provider)
-		voyageKey := os.Getenv("VOYAGE_API_KEY")
-		if voyageKey == "" {
-			logger.Fatal("VOYAGE_API_KEY is required when EMBEDDING=voyage")
-		}
-
-		// Allow optional model override via VOYAGE_MODEL env var
-		voyageModel := os.Getenv("VOYAGE_MODEL")
-		if voyageModel != "" {
-			embeddingClient = embeddings.NewVoyageClientWithModel(voyageKey, voyageModel)
-			logger.Info("Using Voyage AI embedding service",
-				zap.String("model", voyageModel),
-				zap.Int("dimensions", embeddingClient.GetDimensions()))
-		} else {
-			embeddingClient = embeddings.NewVoyageClient(voyageKey)
-			logger.Info("Using Voyage AI embedding service",
-				zap.String("model", "voyage-3"),
-				zap.Int("dimensions", 1024),
-				zap.String("pricing", "$0.06/1M tokens"))
-		}
-
-	default:
-		logger.Fatal("Invalid EMBEDDING mode. Use 'ollama' (default), 'llama', 'local', 'openai', or 'voyage'",
-			zap.String("mode", embeddingMode))
-	}
+	embeddingClient, knowledgeEmbeddingClient, embeddingMode := newEmbeddingClients(logger)
 
 	// Now create Qdrant client with the correct embedding client
-	qdrantClient := storage.NewQdrantClientWithEmbeddingClient(qdrantURL, qdrantKnowledgeCollection, embeddingClient)
+	qdrantClient := storage.NewQdrantClientWithEmbeddingClient(qdrantURL, qdrantKnowledgeCollection, knowledgeEmbeddingClient)
 	logger.Info("Qdrant client initialized with embedding client",
 		zap.String("url", qdrantURL),
 		zap.String("knowledgeCollection", qdrantKnowledgeCollection),
 		zap.Int("vectorDimensions", embeddingClient.GetDimensions()))
 
+	// Optional encryption at rest of prompts, notes and knowledge text (FIELD_ENCRYPTION_* settings)
+	fieldCipher, err := storage.LoadFieldCipher()
+	if err != nil {
+		logger.Fatal("Invalid field encryption configuration", zap.Error(err))
+	}
+	if fieldCipher != nil {
+		logger.Info("Field encryption enabled", zap.String("activeKey", fieldCipher.ActiveKey()))
+	}
+
 	// Initialize storage layers (NOW that qdrantClient is created with correct embeddings)
-	taskStorage, err := storage.NewMongoTaskStorage(db)
+	mongoTaskStorage, err := storage.NewMongoTaskStorage(db)
 	if err != nil {
 		logger.Fatal("Failed to initialize task storage", zap.Error(err))
 	}
+	mongoTaskStorage.SetFieldCipher(fieldCipher)
 	logger.Info("Task storage initialized with MongoDB")
 
+	// Task changes stream to dashboards and agents over /api/ws
+	taskEvents := taskevents.NewHub(logger)
+	mongoTaskStorage.SetEventPublisher(taskEvents)
+
+	// Optional Jira sync (JIRA_* settings): one issue per human task, statuses
+	// mirrored both ways
+	var taskStorage storage.TaskStorage = mongoTaskStorage
+	var jiraSync *jira.Sync
+	if jiraConfig, err := jira.LoadConfig(); err != nil {
+		logger.Warn("Jira sync disabled", zap.Error(err))
+	} else if jiraConfig.Enabled() {
+		if jiraSync, err = jira.NewSync(jiraConfig, mongoTaskStorage, logger); err != nil {
+			logger.Warn("Jira sync disabled", zap.Error(err))
+		} else {
+			taskStorage = jiraSync.Mirror(taskStorage)
+			logger.Info("Jira sync enabled",
+				zap.String("baseUrl", jiraConfig.BaseURL),
+				zap.String("project", jiraConfig.ProjectKey),
+				zap.String("issueType", jiraConfig.IssueType))
+		}
+	}
+
+	// Optional Linear sync (LINEAR_* settings): issues for the human tasks of
+	// mapped projects, completions mirrored both ways
+	var linearSync *linear.Sync
+	if linearConfig, err := linear.LoadConfig(); err != nil {
+		logger.Warn("Linear sync disabled", zap.Error(err))
+	} else if linearConfig.Enabled() {
+		if linearSync, err = linear.NewSync(linearConfig, mongoTaskStorage, logger); err != nil {
+			logger.Warn("Linear sync disabled", zap.Error(err))
+		} else {
+			taskStorage = linearSync.Mirror(taskStorage)
+			logger.Info("Linear sync enabled",
+				zap.Any("teams", linearConfig.Teams),
+				zap.String("defaultTeam", linearConfig.DefaultTeam))
+		}
+	}
+
+	// Email notifications (SMTP_* settings): digests, and blocked tasks to NOTIFY_EMAIL_TO
+	mailer := notify.NewMailer(notify.LoadSMTPConfig())
+	taskStorage = notify.WatchBlockedTasks(taskStorage, mailer, logger)
+
+	// Operator hook scripts run on task lifecycle events (auto-tagging, assignment)
+	automationHookStorage := storage.NewAutomationHookStorage(db, logger)
+	automationEngine, err := automation.NewEngine(automationHookStorage, mongoTaskStorage, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize automation hooks", zap.Error(err))
+	}
+	taskStorage = automationEngine.Watch(taskStorage)
+
 	knowledgeStorage, err := storage.NewMongoKnowledgeStorage(db, qdrantClient)
 	if err != nil {
 		logger.Fatal("Failed to initialize knowledge storage", zap.Error(err))
 	}
+	knowledgeStorage.SetFieldCipher(fieldCipher)
+
+	// Archive the vectors of idle knowledge collections and code folders and
+	// restore them on use
+	vectorTierConfig, err := vectortier.LoadConfig()
+	if err != nil {
+		logger.Warn("Vector archiving disabled", zap.Error(err))
+		vectorTierConfig.ArchiveAfter = 0
+	}
+	vectorTier := vectortier.NewTier(vectorTierConfig, knowledgeStorage, qdrantClient, storage.NewVectorArchiveStorage(db), knowledgeEmbeddingClient.GetDimensions(), logger)
+	knowledgeStorage.SetUseObserver(vectorTier)
+
+	// Retrieval evaluation suites run again when the embedding model or
+	// knowledge settings they were last run under change
+	knowledgePricing := embeddings.PricingFor(knowledgeEmbeddingClient)
+	knowledgeEvaluator := knowledgeeval.NewEvaluator(storage.NewKnowledgeEvalStorage(db), knowledgeStorage, knowledgeeval.Fingerprint(
+		knowledgePricing.Provider,
+		knowledgePricing.Model,
+		strconv.Itoa(knowledgeEmbeddingClient.GetDimensions()),
+		os.Getenv("MULTILINGUAL_EMBEDDING_MODEL"),
+		os.Getenv(handlers.KnowledgeMaxTextBytesEnv),
+		os.Getenv(handlers.KnowledgeAutoChunkEnv),
+	), logger)
+
+	// Right-to-erasure purges across tasks, knowledge, vectors and chat history
+	dataSubjectEraser := storage.NewDataSubjectEraser(db, mongoTaskStorage, knowledgeStorage, logger)
 	logger.Info("Knowledge storage initialized with MongoDB + Qdrant")
 
 	// Initialize code indexing components
@@ -327,6 +465,7 @@ func main() {
 		logger.Fatal("Failed to initialize code index storage", zap.Error(err))
 	}
 	logger.Info("Code index storage initialized")
+	vectorTier.SetCodeFolders(codeIndexStorage, qdrantClient, storage.NewFolderVectorArchiveStorage(db))
 
 	// Initialize tools storage for tools discovery (with correct embedding client!)
 	toolsStorage, err := storage.NewToolsStorage(db, qdrantClient)
@@ -337,13 +476,59 @@ func main() {
 		zap.String("embeddingMode", embeddingMode),
 		zap.Int("vectorDimensions", embeddingClient.GetDimensions()))
 
+	// Scheduled digests of new knowledge, decisions and completed tasks
+	digestStorage := storage.NewDigestSubscriptionStorage(db, logger)
+	digestScheduler := digest.NewScheduler(digestStorage, digest.NewBuilder(knowledgeStorage, taskStorage), digest.NewNotifier(mailer), logger)
+
+	// Recurring agent tasks created from cron schedules, through the wrapped
+	// task storage so hooks and trackers see them
+	scheduledTaskStorage := storage.NewScheduledTaskStorage(db, logger)
+	taskScheduler := schedule.NewRunner(scheduledTaskStorage, taskStorage, logger)
+
+	// Scheduled re-embedding of sampled code chunks to catch corrupt or
+	// missing vectors (INDEX_INTEGRITY_* settings)
+	var integrityVerifier *integrity.Verifier
+	if integrityConfig, err := integrity.LoadConfig(); err != nil {
+		logger.Warn("Index integrity checks disabled", zap.Error(err))
+	} else {
+		integrityVerifier = integrity.NewVerifier(integrityConfig, codeIndexStorage, qdrantClient, embeddingClient, mailer, logger)
+	}
+
+	// Task priority inheritance and escalation of blocked work (PRIORITY_*
+	// settings); invalid settings keep priorities but disable the rules
+	priorityConfig, err := escalation.LoadConfig()
+	if err != nil {
+		logger.Warn("Task priority rules disabled", zap.Error(err))
+		priorityConfig.Interval = 0
+	}
+	priorityRules := escalation.NewEngine(priorityConfig, mongoTaskStorage, mongoTaskStorage, mailer, logger)
+
+	// Bulk agent task edits; status changes go through the wrapped task
+	// storage so sync, notification and automation hooks see them
+	bulkEditor := bulktasks.NewEditor(taskStorage, mongoTaskStorage, priorityRules, logger)
+
+	// Stage destructive MCP tools and REST routes so they can be undone within the window
+	undoManager := undo.NewManager(undo.WindowFromEnv(), logger)
+
+	// Peer coordinators (other squads' deployments) tasks can be delegated to
+	federationStorage := storage.NewFederationStorage(db, logger)
+	federationStorage.SetFieldCipher(fieldCipher)
+	var federationSync *federation.Sync
+	if federationConfig, err := federation.LoadConfig(); err != nil {
+		logger.Warn("Federation disabled", zap.Error(err))
+	} else {
+		federationSync = federation.NewSync(federationConfig, federationStorage, taskStorage, logger)
+	}
+
 	logger.Info("Code index collection configured", zap.String("collection", storage.CodeIndexCollection))
 
 	// Ensure Qdrant code index collection exists with correct dimensions
 	expectedDimensions := embeddingClient.GetDimensions()
-	if err := ensureCodeIndexCollectionWithDimensions(qdrantClient, expectedDimensions, logger); err != nil {
+	reembedCodeIndex, err := ensureCodeIndexCollectionWithDimensions(qdrantClient, expectedDimensions, logger)
+	if err != nil {
 		logger.Fatal("Failed to ensure code index collection", zap.Error(err))
 	}
+	reembedMigrator := reembed.NewMigrator(codeIndexStorage, qdrantClient, embeddingClient, logger)
 
 	// Auto-index project root at startup
 	projectRoot := tools.GetProjectRoot()
@@ -391,6 +576,12 @@ func main() {
 		logger.Fatal("Failed to create file watcher", zap.Error(err))
 	}
 
+	// Correlate file events with in-progress agent tasks that declared filesModified
+	fileWatcher.SetChangeTracker(watcher.NewTaskChangeTracker(taskStorage, logger))
+
+	// Embed added/removed lines of modifications for code_index_recent_changes
+	fileWatcher.SetRecentChangeIndexer(watcher.NewRecentChangeIndexer(qdrantClient, embeddingClient, logger))
+
 	// Load existing folders into file watcher
 	folders, err := codeIndexStorage.ListFolders()
 	if err != nil {
@@ -477,7 +668,7 @@ func main() {
 	}
 
 	// Create MCP server instance (used by both HTTP and stdio modes)
-	mcpServer := createMCPServer(taskStorage, knowledgeStorage, codeIndexStorage, qdrantClient, embeddingClient, fileWatcher, mongoClient, toolsStorage, logger)
+	mcpServer, toolRegistry := createMCPServer(taskStorage, knowledgeStorage, codeIndexStorage, qdrantClient, embeddingClient, fileWatcher, db, toolsStorage, digestStorage, digestScheduler, automationHookStorage, automationEngine, dataSubjectEraser, federationStorage, federationSync, priorityRules, bulkEditor, undoManager, reembedMigrator, vectorTier, knowledgeEvaluator, scheduledTaskStorage, logger)
 
 	// Check for embedded UI (production single-binary mode)
 	hasEmbedded := embed.HasUI()
@@ -501,6 +692,35 @@ func main() {
 
 	var wg sync.WaitGroup
 
+	// Deliver scheduled digests from the long-running server only; stdio-only
+	// processes are spawned per client and would send duplicates
+	if *mode != "mcp" {
+		digestScheduler.Start(ctx)
+		if jiraSync != nil {
+			jiraSync.Start(ctx)
+		}
+		if linearSync != nil {
+			linearSync.Start(ctx)
+		}
+		if federationSync != nil {
+			federationSync.Start(ctx)
+		}
+		priorityRules.Start(ctx)
+		vectorTier.Start(ctx)
+		knowledgeEvaluator.Start(ctx)
+		taskScheduler.Start(ctx)
+		if integrityVerifier != nil {
+			integrityVerifier.Start(ctx)
+		}
+		// Move the code index to the embedding model's dimensions; searches
+		// use the previous collection until every chunk is re-embedded
+		if reembedCodeIndex {
+			go reembedMigrator.Run(ctx, storage.CodeIndexCollection)
+		}
+	} else if reembedCodeIndex {
+		logger.Warn("Code index needs re-embedding for the new embedding model: start the coordinator with -mode=http or -mode=both to run it")
+	}
+
 	// Start servers based on mode
 	switch *mode {
 	case "http":
@@ -511,10 +731,20 @@ func main() {
 		}
 		logger.Info("Starting in HTTP-only mode", zap.String("port", httpPort))
 
+		var lifecycle server.Lifecycle
+		if *k8sMode {
+			lifecycle = server.Lifecycle{
+				ProbePort:       k8sConfig.ProbePort,
+				DrainDelay:      k8sConfig.DrainDelay,
+				ShutdownTimeout: k8sConfig.ShutdownTimeout,
+				JSONLogs:        true,
+			}
+		}
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := server.StartHTTPServer(ctx, httpPort, taskStorage, knowledgeStorage, codeIndexStorage, qdrantClient, embeddingClient, fileWatcher, mcpServer, embeddedFS, hasEmbedded, logger, db); err != nil {
+			if err := server.StartHTTPServer(ctx, httpPort, taskStorage, knowledgeStorage, codeIndexStorage, qdrantClient, embeddingClient, fileWatcher, mcpServer, toolRegistry, embeddedFS, hasEmbedded, logger, db, jiraSync, taskEvents, bulkEditor, undoManager, lifecycle); err != nil {
 				logger.Fatal("HTTP server error", zap.Error(err))
 			}
 		}()
@@ -541,7 +771,7 @@ func main() {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := server.StartHTTPServer(ctx, httpPort, taskStorage, knowledgeStorage, codeIndexStorage, qdrantClient, embeddingClient, fileWatcher, mcpServer, embeddedFS, hasEmbedded, logger, db); err != nil {
+			if err := server.StartHTTPServer(ctx, httpPort, taskStorage, knowledgeStorage, codeIndexStorage, qdrantClient, embeddingClient, fileWatcher, mcpServer, toolRegistry, embeddedFS, hasEmbedded, logger, db, jiraSync, taskEvents, bulkEditor, undoManager, server.Lifecycle{}); err != nil {
 				logger.Error("HTTP server error", zap.Error(err))
 			}
 		}()
@@ -557,7 +787,7 @@ func main() {
 		}()
 
 	default:
-		logger.Fatal("Invalid mode. Use: http, mcp, or both", zap.String("mode", *mode))
+		logger.Fatal("Invalid mode. Use: http, mcp, both, or cache", zap.String("mode", *mode))
 	}
 
 	// Wait for interrupt signal
@@ -569,7 +799,8 @@ func main() {
 	logger.Info("Server shutdown complete")
 }
 
-// createMCPServer creates and configures the MCP server with all handlers
+// createMCPServer creates and configures the MCP server with all handlers. The
+// returned registry holds every tool handler for in-process REST invocation.
 func createMCPServer(
 	taskStorage storage.TaskStorage,
 	knowledgeStorage storage.KnowledgeStorage,
@@ -577,38 +808,76 @@ func createMCPServer(
 	qdrantClient *storage.QdrantClient,
 	embeddingClient embeddings.EmbeddingClient,
 	fileWatcher *watcher.FileWatcher,
-	mongoClient *mongo.Client,
+	mongoDB *mongo.Database,
 	toolsStorage *storage.ToolsStorage,
+	digestStorage *storage.DigestSubscriptionStorage,
+	digestScheduler *digest.Scheduler,
+	automationHookStorage *storage.AutomationHookStorage,
+	automationEngine *automation.Engine,
+	dataSubjectEraser *storage.DataSubjectEraser,
+	federationStorage *storage.FederationStorage,
+	federationSync *federation.Sync,
+	priorityRules *escalation.Engine,
+	bulkEditor *bulktasks.Editor,
+	undoManager *undo.Manager,
+	reembedMigrator *reembed.Migrator,
+	vectorTier *vectortier.Tier,
+	knowledgeEvaluator *knowledgeeval.Evaluator,
+	scheduledTaskStorage *storage.ScheduledTaskStorage,
 	logger *zap.Logger,
-) *mcp.Server {
+) (*mcp.Server, *handlers.ToolMetadataRegistry) {
 	impl := &mcp.Implementation{
 		Name:    "hyperion-coordinator-unified",
 		Version: "2.0.0",
 	}
 
+	// Offer client workspace roots for indexing (CODE_INDEX_ROOTS)
+	workspaceRoots := handlers.NewWorkspaceRoots(codeIndexStorage, fileWatcher, logger)
+
+	// Complete task IDs, agent names, collections and folders in prompt and
+	// resource template arguments
+	completer := handlers.NewCompleter(taskStorage, knowledgeStorage, codeIndexStorage)
+
 	opts := &mcp.ServerOptions{
-		HasResources: true,
-		HasTools:     true,
-		HasPrompts:   true,
+		HasResources:            true,
+		HasTools:                true,
+		HasPrompts:              true,
+		InitializedHandler:      workspaceRoots.HandleInitialized,
+		RootsListChangedHandler: workspaceRoots.HandleRootsListChanged,
+		CompletionHandler:       completer.Complete,
 	}
 
 	server := mcp.NewServer(impl, opts)
 
-	// Get the database from mongoClient
-	mongoDB := mongoClient.Database(os.Getenv("MONGODB_DATABASE"))
-	if mongoDB == nil {
-		mongoDB = mongoClient.Database("coordinator_db1")
+	// Enforce RBAC on tool calls (HTTP role forwarded by middleware, stdio uses
+	// MCP_STDIO_ROLE), or the OPA policy at POLICY_OPA_URL when configured
+	policy, err := middleware.LoadPolicy(logger)
+	if err != nil {
+		logger.Fatal("Invalid authorization policy configuration", zap.Error(err))
 	}
+	server.AddReceivingMiddleware(handlers.NewRBACMiddleware(handlers.StdioRole(), policy, logger))
+
+	// Render human-readable tool messages in the caller's locale (headers or HYPER_LOCALE)
+	server.AddReceivingMiddleware(handlers.NewLocaleMiddleware())
+
+	// Bound tool calls by their deadline hint and let them take precedence
+	// over bulk indexing (_meta.priority/timeoutMs or X-Hyper-* headers)
+	server.AddReceivingMiddleware(handlers.NewPriorityMiddleware())
 
 	// Create tool metadata registry for automatic tool indexing
 	toolMetadataRegistry := handlers.NewToolMetadataRegistry()
 
+	// Advertise only the tools each session may call: hide tools above the
+	// caller's role and exec tools from clients not in MCP_TRUSTED_CLIENTS
+	server.AddReceivingMiddleware(handlers.NewToolVisibilityMiddleware(server, toolMetadataRegistry, handlers.StdioRole(), policy, handlers.LoadClientTrust(), logger))
+
 	// Initialize and register all handlers
 	resourceHandler := handlers.NewResourceHandler(taskStorage, knowledgeStorage)
 	docResourceHandler := handlers.NewDocResourceHandler()
 	workflowResourceHandler := handlers.NewWorkflowResourceHandler(taskStorage)
 	knowledgeResourceHandler := handlers.NewKnowledgeResourceHandler(knowledgeStorage)
 	metricsResourceHandler := handlers.NewMetricsResourceHandler(taskStorage)
+	metricsResourceHandler.SetIntegrityChecks(codeIndexStorage)
 	toolHandler := handlers.NewToolHandler(taskStorage, knowledgeStorage, mongoDB)
 	qdrantToolHandler := handlers.NewQdrantToolHandler(qdrantClient)
 	codeToolsHandler := handlers.NewCodeToolsHandler(codeIndexStorage, qdrantClient, embeddingClient, fileWatcher, logger)
@@ -618,6 +887,7 @@ func createMCPServer(
 	documentationPromptHandler := handlers.NewDocumentationPromptHandler()
 	filesystemToolHandler := handlers.NewFilesystemToolHandler(logger)
 	toolsDiscoveryHandler := handlers.NewToolsDiscoveryHandler(toolsStorage, server)
+	diagnosticsHandler := handlers.NewDiagnosticsHandler(mongoDB, qdrantClient, embeddingClient, fileWatcher, logger)
 
 	// Set metadata registry on all tool handlers for automatic indexing
 	toolHandler.SetMetadataRegistry(toolMetadataRegistry)
@@ -625,6 +895,85 @@ func createMCPServer(
 	filesystemToolHandler.SetMetadataRegistry(toolMetadataRegistry)
 	codeToolsHandler.SetMetadataRegistry(toolMetadataRegistry)
 	toolsDiscoveryHandler.SetMetadataRegistry(toolMetadataRegistry)
+	diagnosticsHandler.SetMetadataRegistry(toolMetadataRegistry)
+
+	// Interpolate environment-scoped variables into knowledge query results
+	knowledgeEnvironmentStorage := storage.NewKnowledgeEnvironmentStorage(mongoDB, logger)
+	toolHandler.SetKnowledgeEnvironments(knowledgeEnvironmentStorage)
+	qdrantToolHandler.SetKnowledgeEnvironments(knowledgeEnvironmentStorage)
+
+	// Link code search hits and agent tasks through their declared filesModified
+	codeToolsHandler.SetTaskStorage(taskStorage)
+
+	// List and register client workspace roots through code_index_workspace_roots
+	codeToolsHandler.SetWorkspaceRoots(workspaceRoots)
+
+	// Report code index re-embedding in code_index_status
+	codeToolsHandler.SetReembedMigrator(reembedMigrator)
+
+	// Keep searched code folders hot and restore archived ones on search
+	codeToolsHandler.SetFolderTier(vectorTier)
+
+	// Compare new human task prompts against open tasks semantically
+	toolHandler.SetEmbeddingClient(embeddingClient)
+
+	// Synthesize cited answers and ADR drafts from knowledge with the configured LLM
+	if aiConfig, err := aiservice.LoadAIConfig(""); err != nil {
+		logger.Info("coordinator_answer and coordinator_draft_adr disabled: no LLM configured", zap.Error(err))
+	} else if answerService, err := aiservice.NewChatService(aiConfig); err != nil {
+		logger.Warn("coordinator_answer and coordinator_draft_adr disabled: failed to create LLM client", zap.Error(err))
+	} else {
+		toolHandler.SetAnswerGenerator(answerService)
+	}
+
+	// Manage digest subscriptions and send digests on demand
+	toolHandler.SetDigests(digestStorage, digestScheduler)
+
+	// Manage task lifecycle automation hooks and dry-run their scripts
+	toolHandler.SetAutomation(automationHookStorage, automationEngine)
+
+	// Set task priorities and apply their inheritance and escalation rules
+	toolHandler.SetPriorityRules(priorityRules)
+
+	// Change many agent tasks at once
+	toolHandler.SetBulkEditor(bulkEditor)
+
+	// Tell callers when a queried collection's vectors are archived
+	toolHandler.SetVectorTier(vectorTier)
+	toolHandler.SetKnowledgeEvaluator(knowledgeEvaluator)
+	toolHandler.SetScheduledTasks(scheduledTaskStorage)
+
+	// Purge a data subject's data on right-to-erasure requests
+	toolHandler.SetDataSubjectEraser(dataSubjectEraser)
+
+	// Attach build logs, reports and screenshots to agent tasks
+	if artifactStorage, err := storage.NewTaskArtifactStorage(mongoDB, logger); err != nil {
+		logger.Warn("coordinator_attach_artifact disabled", zap.Error(err))
+	} else {
+		toolHandler.SetArtifactStorage(artifactStorage)
+	}
+
+	// Delegate tasks to peer coordinators and list the delegations
+	toolHandler.SetFederation(federationStorage, federationSync)
+
+	// Migrate Qdrant collections behind aliases and roll them back
+	toolHandler.SetCollectionAliases(qdrantClient)
+
+	// Fan coordinator_search out to the code index and the tool registry
+	toolHandler.SetUnifiedSearch(codeToolsHandler, toolsStorage)
+
+	// Serve coordinator_read_resources from the server's own resources
+	toolHandler.SetResourceReader(handlers.NewResourceReader(server, resourceHandler))
+
+	// Serve and edit registered subagents' system prompts and personas
+	subagentStorage := storage.NewSubchatStorage(mongoDB, logger)
+	toolHandler.SetSubagentStorage(subagentStorage)
+	completer.SetSubagents(subagentStorage)
+
+	// Stage destructive operations so they can be undone within the window
+	toolHandler.SetUndoManager(undoManager)
+	codeToolsHandler.SetUndoManager(undoManager)
+	toolsDiscoveryHandler.SetUndoManager(undoManager)
 
 	// Register all handlers (panic on error)
 	must := func(err error) {
@@ -641,8 +990,11 @@ func createMCPServer(
 	must(toolHandler.RegisterToolHandlers(server))
 	must(qdrantToolHandler.RegisterQdrantTools(server))
 	must(codeToolsHandler.RegisterCodeIndexTools(server))
+	must(codeToolsHandler.RegisterTaskCodeResources(server))
+	must(toolHandler.RegisterAgentPersonaResources(server))
 	must(filesystemToolHandler.RegisterFilesystemTools(server))
 	must(toolsDiscoveryHandler.RegisterToolsDiscoveryTools(server))
+	must(diagnosticsHandler.RegisterDiagnosticsTools(server))
 	must(planningPromptHandler.RegisterPlanningPrompts(server))
 	must(knowledgePromptHandler.RegisterKnowledgePrompts(server))
 	must(coordinationPromptHandler.RegisterCoordinationPrompts(server))
@@ -660,5 +1012,5 @@ func createMCPServer(
 			zap.String("collection", "mcp-tools"))
 	}
 
-	return server
+	return server, toolMetadataRegistry
 }
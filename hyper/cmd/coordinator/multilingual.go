@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"hyper/internal/mcp/embeddings"
+)
+
+// multilingualEmbeddingClient returns the client embedding non-English
+// knowledge when MULTILINGUAL_EMBEDDING_MODEL is set, or nil. The model is
+// served by the same provider as the primary one; for EMBEDDING=local it is
+// the URL of a second TEI server. opts are those of the primary client.
+func multilingualEmbeddingClient(embeddingMode string, opts ...embeddings.Option) (embeddings.EmbeddingClient, error) {
+	model := os.Getenv("MULTILINGUAL_EMBEDDING_MODEL")
+	if model == "" {
+		return nil, nil
+	}
+
+	switch embeddingMode {
+	case "ollama":
+		ollamaURL := os.Getenv("OLLAMA_URL")
+		if ollamaURL == "" {
+			ollamaURL = "http://localhost:11434"
+		}
+		return embeddings.NewOllamaClient(ollamaURL, model, opts...)
+	case "local":
+		return embeddings.NewTEIClient(model, opts...), nil
+	case "voyage":
+		return embeddings.NewVoyageClientWithModel(os.Getenv("VOYAGE_API_KEY"), model, opts...), nil
+	default:
+		return nil, fmt.Errorf("MULTILINGUAL_EMBEDDING_MODEL is not supported with EMBEDDING=%s", embeddingMode)
+	}
+}
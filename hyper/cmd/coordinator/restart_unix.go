@@ -0,0 +1,18 @@
+//go:build unix || darwin
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// restartProcess replaces the current process with a fresh copy of itself
+func restartProcess() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	// Unix/macOS: exec in place so the PID and terminal are kept
+	return syscall.Exec(executable, os.Args, os.Environ())
+}
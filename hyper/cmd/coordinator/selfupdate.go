@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hyper/internal/console"
+	"hyper/internal/update"
+
+	"go.uber.org/zap"
+)
+
+// runSelfUpdate implements `hyper self-update`: downloads the latest release,
+// verifies its signature and replaces the running binary
+func runSelfUpdate(args []string) {
+	fs := flag.NewFlagSet("self-update", flag.ExitOnError)
+	checkOnly := fs.Bool("check", false, "Only report whether a new version is available")
+	force := fs.Bool("force", false, "Install the latest release on a development build")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+
+	updater := update.NewUpdater(update.LoadConfig())
+	release, newer, err := updater.Check(ctx, Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+	if !newer && !*force {
+		fmt.Printf("✓ hyper %s is up to date (latest release: %s)\n", Version, release.Version)
+		return
+	}
+	if *checkOnly {
+		fmt.Printf("A new version is available: %s (running %s). Run `hyper self-update` to install it.\n", release.Version, Version)
+		if release.Notes != "" {
+			fmt.Printf("\n%s\n", release.Notes)
+		}
+		return
+	}
+
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Failed to locate the hyper binary: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Downloading hyper %s for %s...\n", release.Version, update.Platform())
+	binary, err := updater.Download(ctx, release, Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+	if err := update.Apply(executable, binary); err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Updated %s from %s to %s (signature verified)\n", executable, Version, release.Version)
+	fmt.Println("  Restart the coordinator (or `hyper service stop` and `hyper service start`) to run the new version.")
+}
+
+// notifyUpdateAvailable looks for a newer release in the background and
+// reports it. Enabled by HYPER_UPDATE_CHECK=true; failures are only logged.
+func notifyUpdateAvailable(ctx context.Context, cfg update.Config, logger *zap.Logger) {
+	if !cfg.CheckOnStartup || Version == "dev" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		release, newer, err := update.NewUpdater(cfg).Check(ctx, Version)
+		if err != nil {
+			logger.Debug("Update check failed", zap.Error(err))
+			return
+		}
+		if !newer {
+			return
+		}
+		logger.Info("A new hyper version is available",
+			zap.String("current", Version),
+			zap.String("latest", release.Version))
+		console.Printf("\n🔔 hyper %s is available (running %s). Run `hyper self-update` to install it.\n\n", release.Version, Version)
+	}()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"hyper/internal/service"
+)
+
+const serviceUsage = "usage: hyper service install|uninstall|start|stop [flags]"
+
+// runServiceCommand implements `hyper service`: installs and controls the
+// coordinator as a launchd agent (macOS), systemd user unit (Linux) or
+// Windows service, running in HTTP mode
+func runServiceCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, serviceUsage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch args[0] {
+	case "install":
+		err = installService(args[1:])
+	case "uninstall":
+		if err = service.Uninstall(); err == nil {
+			fmt.Println("✓ Service uninstalled")
+		}
+	case "start":
+		if err = service.Start(); err == nil {
+			fmt.Println("✓ Service started")
+		}
+	case "stop":
+		if err = service.Stop(); err == nil {
+			fmt.Println("✓ Service stopped")
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown service command %q\n%s\n", args[0], serviceUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// installService installs the service for the running binary. The config
+// path, profile and workspace are baked into its arguments, since services do not
+// inherit the shell environment.
+func installService(args []string) error {
+	fs := flag.NewFlagSet("service install", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to config file (default: .env.hyper next to the binary)")
+	profile := fs.String("profile", os.Getenv("HYPER_PROFILE"), "Config profile the service runs with")
+	workspace := fs.String("workspace", os.Getenv("HYPER_WORKSPACE"), "Workspace the service serves")
+	logFile := fs.String("log-file", "", "File the service's output is appended to (default: per-platform log directory)")
+	fs.Parse(args)
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the hyper binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	cfg := service.Config{
+		Executable: executable,
+		Args:       []string{"--mode=http"},
+		WorkingDir: filepath.Dir(executable),
+		LogFile:    *logFile,
+	}
+	if *configPath != "" {
+		path, err := filepath.Abs(*configPath)
+		if err != nil {
+			return err
+		}
+		cfg.Args = append(cfg.Args, "--config="+path)
+	}
+	if *profile != "" {
+		cfg.Args = append(cfg.Args, "--profile="+*profile)
+	}
+	if *workspace != "" {
+		cfg.Args = append(cfg.Args, "--workspace="+*workspace)
+	}
+	if cfg.LogFile == "" {
+		if cfg.LogFile, err = service.DefaultLogFile(); err != nil {
+			return err
+		}
+	} else if cfg.LogFile, err = filepath.Abs(cfg.LogFile); err != nil {
+		return err
+	}
+
+	if err := service.Install(cfg); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Service installed: %s %v\n", cfg.Executable, cfg.Args)
+	fmt.Printf("  Logs: %s\n", cfg.LogFile)
+	if hint := service.Hint(); hint != "" {
+		fmt.Printf("  %s\n", hint)
+	}
+	fmt.Println("  Start it now with `hyper service start`.")
+	return nil
+}
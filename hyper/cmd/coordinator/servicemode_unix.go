@@ -0,0 +1,10 @@
+//go:build unix || darwin
+
+package main
+
+// startServiceMode is a no-op: launchd and systemd run the coordinator as a
+// plain process and stop it with SIGTERM
+func startServiceMode() {}
+
+// finishServiceMode is a no-op outside Windows services
+func finishServiceMode() {}
@@ -0,0 +1,95 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hyper/internal/service"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// shutdownTimeout bounds how long a stop request waits for the coordinator
+const shutdownTimeout = 25 * time.Second
+
+var (
+	// serviceCtx is cancelled when the service control manager stops the
+	// service; setupSignalHandler derives from it
+	serviceCtx, cancelService = context.WithCancel(context.Background())
+
+	runningAsService bool
+	mainDone         = make(chan struct{}) // Closed when main returns
+	dispatcherDone   = make(chan struct{}) // Closed once the service reported stopped
+)
+
+// startServiceMode connects to the service control manager when the
+// coordinator was started as a Windows service. It must run first thing in
+// main: the manager gives a service 30 seconds to connect.
+func startServiceMode() {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return
+	}
+	runningAsService = true
+
+	// Services start in System32; config and relative paths are resolved
+	// next to the binary
+	if executable, err := os.Executable(); err == nil {
+		os.Chdir(filepath.Dir(executable))
+	}
+
+	go func() {
+		defer close(dispatcherDone)
+		if err := svc.Run(service.Name, serviceHandler{}); err != nil {
+			fmt.Fprintf(os.Stderr, "Windows service dispatcher failed: %v\n", err)
+			cancelService()
+		}
+	}()
+}
+
+// finishServiceMode lets the service control manager see the service stop
+// before the process exits, so a requested stop is not taken for a crash
+func finishServiceMode() {
+	if !runningAsService {
+		return
+	}
+	close(mainDone)
+	select {
+	case <-dispatcherDone:
+	case <-time.After(5 * time.Second):
+	}
+}
+
+// serviceHandler reports the coordinator running and turns stop requests
+// into a cancelled serviceCtx
+type serviceHandler struct{}
+
+func (serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case <-mainDone:
+			// Stopped on its own: a non-zero exit code triggers the
+			// restart policy
+			return false, 1
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending, WaitHint: uint32(shutdownTimeout.Milliseconds())}
+				cancelService()
+				select {
+				case <-mainDone:
+				case <-time.After(shutdownTimeout):
+				}
+				return false, 0
+			}
+		}
+	}
+}
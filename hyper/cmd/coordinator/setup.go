@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"hyper/internal/console"
+	"hyper/internal/setup"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// runInit implements `hyper init`: an interactive prompt that writes .env.hyper
+func runInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path of the config file to write (default: ./.env.hyper[.<profile>])")
+	profile := fs.String("profile", os.Getenv("HYPER_PROFILE"), "Config profile to write")
+	fs.Parse(args)
+	if *configPath == "" {
+		*configPath = "./" + setup.EnvFileNameFor(*profile)
+	}
+
+	if err := setup.RunInit(context.Background(), os.Stdin, os.Stdout, *configPath, setup.NewChecker()); err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runSetupServer serves only the setup endpoints until a configuration is
+// written, then restarts the coordinator so it loads the new file. It binds
+// to localhost, and its POST endpoints require the setup token printed to the
+// console, because they accept credentials and rewrite the configuration.
+func runSetupServer(envPath string, logger *zap.Logger) {
+	httpPort := os.Getenv("HTTP_PORT")
+	if httpPort == "" {
+		httpPort = "7095"
+	}
+
+	ctx, stop := setupSignalHandler()
+	defer stop()
+
+	applied := make(chan struct{})
+	origin := "http://127.0.0.1:" + httpPort
+	handler := setup.NewHandler(envPath, origin, setup.NewChecker(), func() { close(applied) }, logger)
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(gin.Recovery())
+	handler.RegisterRoutes(r)
+
+	srv := &http.Server{Addr: "127.0.0.1:" + httpPort, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Setup server error", zap.Error(err))
+		}
+	}()
+
+	logger.Warn("No configuration found, running first-run setup",
+		zap.String("url", origin+"/api/v1/setup/status"),
+		zap.String("envPath", envPath))
+	console.Printf("\nNo configuration found. Run `hyper init`, or POST the settings to %s/api/v1/setup/apply\nwith the header %s: %s\n\n", origin, setup.TokenHeader, handler.Token())
+
+	restart := false
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutdown signal received, stopping setup server...")
+	case <-applied:
+		restart = true
+	}
+
+	// Let the apply response reach the client before the listener closes
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Setup server shutdown error", zap.Error(err))
+	}
+
+	if restart {
+		logger.Info("Configuration written, restarting coordinator")
+		logger.Sync()
+		if err := restartProcess(); err != nil {
+			logger.Fatal("Failed to restart coordinator; start it again manually", zap.Error(err))
+		}
+	}
+}
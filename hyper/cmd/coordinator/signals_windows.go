@@ -10,7 +10,8 @@ import (
 
 // setupSignalHandler creates a context that cancels on interrupt signals
 func setupSignalHandler() (context.Context, func()) {
-	// Windows: only handle os.Interrupt (Ctrl+C)
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	// Windows: only handle os.Interrupt (Ctrl+C), and stop requests when
+	// running as a service
+	ctx, stop := signal.NotifyContext(serviceCtx, os.Interrupt)
 	return ctx, stop
 }
@@ -0,0 +1,8 @@
+package main
+
+// Build information, set by build-native.sh with -ldflags "-X main.Version=..."
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+	GitCommit = "unknown"
+)
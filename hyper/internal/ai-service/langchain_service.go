@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 )
 
 // ContextKey type for context keys
@@ -164,6 +165,34 @@ func (s *ChatService) StreamChat(ctx context.Context, messages []Message) (<-cha
 	return wrappedChan, nil
 }
 
+// Complete sends a system prompt and a user prompt and returns the whole
+// response. Providers report failures as a final "ERROR: " chunk, which is
+// returned as an error.
+func (s *ChatService) Complete(ctx context.Context, systemPrompt, prompt string) (string, error) {
+	messages := []Message{{Role: "user", Content: prompt}}
+	if systemPrompt != "" {
+		messages = append([]Message{{Role: "system", Content: systemPrompt}}, messages...)
+	}
+
+	outputChan, err := s.StreamChat(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+
+	var response strings.Builder
+	for chunk := range outputChan {
+		if strings.HasPrefix(chunk, "ERROR: ") {
+			return "", fmt.Errorf("LLM request failed: %s", strings.TrimPrefix(chunk, "ERROR: "))
+		}
+		response.WriteString(chunk)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return response.String(), nil
+}
+
 // StreamChatWithTools sends messages to AI provider with tool support and streams events
 // Handles tool calls automatically: when AI requests a tool, executes it and returns result
 // Returns channel of StreamEvent which can be tokens, tool calls, or tool results
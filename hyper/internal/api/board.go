@@ -0,0 +1,291 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// boardStatuses are the board columns, in order. Tasks in any other status
+// get a column of their own after these.
+var boardStatuses = []storage.TaskStatus{
+	storage.TaskStatusPending,
+	storage.TaskStatusInProgress,
+	storage.TaskStatusBlocked,
+	storage.TaskStatusCompleted,
+}
+
+// boardTitleRunes bounds card titles; the full prompt is on the task
+const boardTitleRunes = 120
+
+// BoardCardDTO is a task as a board card shows it
+type BoardCardDTO struct {
+	ID             string   `json:"id"`
+	Kind           string   `json:"kind"` // human or agent
+	Title          string   `json:"title"`
+	Status         string   `json:"status"`
+	Project        string   `json:"project,omitempty"`
+	HumanTaskID    string   `json:"humanTaskId,omitempty"`
+	AgentName      string   `json:"agentName,omitempty"`
+	TodosTotal     int      `json:"todosTotal,omitempty"`
+	TodosCompleted int      `json:"todosCompleted,omitempty"`
+	DueAt          *string  `json:"dueAt,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	Priority       string   `json:"priority,omitempty"` // Agent cards: own or inherited, not the human task's
+	UpdatedAt      string   `json:"updatedAt"`
+}
+
+// BoardColumnDTO is the cards in one status, most recently updated first
+type BoardColumnDTO struct {
+	Status string         `json:"status"`
+	Count  int            `json:"count"` // All cards in the column, even when limited
+	Cards  []BoardCardDTO `json:"cards"`
+}
+
+// BoardResponse is the whole task board
+type BoardResponse struct {
+	Columns []BoardColumnDTO `json:"columns"`
+	Total   int              `json:"total"`
+	AsOf    string           `json:"asOf"` // Pass as since to /api/board/delta
+}
+
+// BoardDeltaResponse is what changed on the board since a point in time
+type BoardDeltaResponse struct {
+	Changed []BoardCardDTO `json:"changed"`
+	Removed []string       `json:"removed"`
+	Reset   bool           `json:"reset"` // The board must be reloaded: it was cleared, or since is too old
+	AsOf    string         `json:"asOf"`
+}
+
+// GetBoard returns every task grouped into status columns
+// GET /api/board?kind=human|agent&limit=N (limit caps the cards per column)
+func (h *RESTAPIHandler) GetBoard(c *gin.Context) {
+	kind, limit, ok := boardParams(c)
+	if !ok {
+		return
+	}
+
+	asOf := time.Now().UTC()
+	humans, agents, err := h.boardTasks(c.Request.Context(), time.Time{})
+	if err != nil {
+		errcode.Respond(c, err, "Failed to load task board: "+err.Error())
+		return
+	}
+	cards := boardCards(humans, agents, kind)
+
+	byStatus := map[string][]BoardCardDTO{}
+	for _, card := range cards {
+		byStatus[card.Status] = append(byStatus[card.Status], card)
+	}
+	statuses := make([]string, 0, len(boardStatuses)+len(byStatus))
+	for _, status := range boardStatuses {
+		statuses = append(statuses, string(status))
+	}
+	var others []string
+	for status := range byStatus {
+		if !isBoardStatus(status) {
+			others = append(others, status)
+		}
+	}
+	sort.Strings(others)
+	statuses = append(statuses, others...)
+
+	response := BoardResponse{Columns: make([]BoardColumnDTO, 0, len(statuses)), Total: len(cards), AsOf: formatBoardTime(asOf)}
+	for _, status := range statuses {
+		column := byStatus[status]
+		count := len(column)
+		if limit > 0 && len(column) > limit {
+			column = column[:limit]
+		}
+		if column == nil {
+			column = []BoardCardDTO{}
+		}
+		response.Columns = append(response.Columns, BoardColumnDTO{Status: status, Count: count, Cards: column})
+	}
+	envelope.OK(c, response)
+}
+
+// GetBoardDelta returns the cards changed and the tasks removed since a
+// previous asOf, so clients can keep a board current without reloading it
+// GET /api/board/delta?since=<asOf>&kind=human|agent
+func (h *RESTAPIHandler) GetBoardDelta(c *gin.Context) {
+	kind, _, ok := boardParams(c)
+	if !ok {
+		return
+	}
+	since, err := parseBoardSince(c.Query("since"))
+	if err != nil {
+		errcode.RespondCode(c, errcode.Validation, err.Error())
+		return
+	}
+
+	asOf := time.Now().UTC()
+	response := BoardDeltaResponse{Changed: []BoardCardDTO{}, Removed: []string{}, AsOf: formatBoardTime(asOf)}
+
+	board, ok := storage.BoardStorageOf(h.taskStorage)
+	if !ok || asOf.Sub(since) > storage.TaskDeletionRetention {
+		// Removals can't be reported
+		response.Reset = true
+		envelope.OK(c, response)
+		return
+	}
+
+	humans, agents, err := board.BoardTasks(c.Request.Context(), since)
+	if err != nil {
+		errcode.Respond(c, err, "Failed to load task board changes: "+err.Error())
+		return
+	}
+	deletions, err := board.TaskDeletionsSince(c.Request.Context(), since)
+	if err != nil {
+		errcode.Respond(c, err, "Failed to load task board changes: "+err.Error())
+		return
+	}
+
+	response.Changed = boardCards(humans, agents, kind)
+	for _, deletion := range deletions {
+		switch {
+		case deletion.TaskID == storage.BoardClearedID:
+			response.Reset = true
+		case kind == "" || deletion.Kind == kind:
+			response.Removed = append(response.Removed, deletion.TaskID)
+		}
+	}
+	envelope.OK(c, response)
+}
+
+// boardTasks loads the tasks updated at or after since, trimmed to card
+// fields when the storage supports it
+func (h *RESTAPIHandler) boardTasks(ctx context.Context, since time.Time) ([]*storage.HumanTask, []*storage.AgentTask, error) {
+	if board, ok := storage.BoardStorageOf(h.taskStorage); ok {
+		return board.BoardTasks(ctx, since)
+	}
+	return h.taskStorage.ListAllHumanTasks(), h.taskStorage.ListAllAgentTasks(), nil
+}
+
+// boardParams reads the kind and limit query parameters, responding with a
+// validation error when they are invalid
+func boardParams(c *gin.Context) (kind string, limit int, ok bool) {
+	kind = c.Query("kind")
+	if kind != "" && kind != "human" && kind != "agent" {
+		errcode.RespondCode(c, errcode.Validation, "kind must be human or agent")
+		return "", 0, false
+	}
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			errcode.RespondCode(c, errcode.Validation, "limit must be a non-negative integer")
+			return "", 0, false
+		}
+		limit = n
+	}
+	return kind, limit, true
+}
+
+// parseBoardSince reads an asOf timestamp (RFC 3339) or Unix milliseconds
+func parseBoardSince(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, errcode.New(errcode.Validation, "since is required: pass the asOf of the previous board or delta")
+	}
+	if ms, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	since, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, errcode.New(errcode.Validation, "since must be an RFC 3339 timestamp or Unix milliseconds")
+	}
+	return since.UTC(), nil
+}
+
+// boardCards converts tasks to cards of the given kind ("" for both), most
+// recently updated first
+func boardCards(humans []*storage.HumanTask, agents []*storage.AgentTask, kind string) []BoardCardDTO {
+	cards := make([]BoardCardDTO, 0, len(humans)+len(agents))
+	updated := make(map[string]time.Time, len(humans)+len(agents))
+	if kind != "agent" {
+		for _, task := range humans {
+			cards = append(cards, BoardCardDTO{
+				ID:        task.ID,
+				Kind:      "human",
+				Title:     boardTitle(task.Prompt),
+				Status:    string(task.Status),
+				Project:   task.Project,
+				DueAt:     formatBoardTimePtr(task.DueAt),
+				Tags:      task.Tags,
+				Priority:  string(storage.HumanTaskPriority(task)),
+				UpdatedAt: formatBoardTime(task.UpdatedAt),
+			})
+			updated[task.ID] = task.UpdatedAt
+		}
+	}
+	if kind != "human" {
+		for _, task := range agents {
+			card := BoardCardDTO{
+				ID:          task.ID,
+				Kind:        "agent",
+				Title:       boardTitle(task.Role),
+				Status:      string(task.Status),
+				HumanTaskID: task.HumanTaskID,
+				AgentName:   task.AgentName,
+				TodosTotal:  len(task.Todos),
+				DueAt:       formatBoardTimePtr(task.DueAt),
+				Tags:        task.Tags,
+				Priority:    string(storage.AgentTaskPriority(task, nil)),
+				UpdatedAt:   formatBoardTime(task.UpdatedAt),
+			}
+			for _, todo := range task.Todos {
+				if todo.Status == storage.TodoStatusCompleted {
+					card.TodosCompleted++
+				}
+			}
+			cards = append(cards, card)
+			updated[task.ID] = task.UpdatedAt
+		}
+	}
+
+	sort.SliceStable(cards, func(i, j int) bool {
+		a, b := updated[cards[i].ID], updated[cards[j].ID]
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return cards[i].ID < cards[j].ID
+	})
+	return cards
+}
+
+func isBoardStatus(status string) bool {
+	for _, known := range boardStatuses {
+		if string(known) == status {
+			return true
+		}
+	}
+	return false
+}
+
+// boardTitle shortens text to a card title
+func boardTitle(text string) string {
+	if utf8.RuneCountInString(text) <= boardTitleRunes {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:boardTitleRunes-1]) + "…"
+}
+
+func formatBoardTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func formatBoardTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := formatBoardTime(*t)
+	return &formatted
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/automation"
+	"hyper/internal/jira"
+	"hyper/internal/linear"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/notify"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// boardStorage serves the board from fixed tasks; other TaskStorage methods
+// are not called
+type boardStorage struct {
+	storage.TaskStorage
+	humans    []*storage.HumanTask
+	agents    []*storage.AgentTask
+	deletions []storage.TaskDeletion
+}
+
+func (s *boardStorage) BoardTasks(ctx context.Context, since time.Time) ([]*storage.HumanTask, []*storage.AgentTask, error) {
+	var humans []*storage.HumanTask
+	for _, task := range s.humans {
+		if !task.UpdatedAt.Before(since) {
+			humans = append(humans, task)
+		}
+	}
+	var agents []*storage.AgentTask
+	for _, task := range s.agents {
+		if !task.UpdatedAt.Before(since) {
+			agents = append(agents, task)
+		}
+	}
+	return humans, agents, nil
+}
+
+func (s *boardStorage) TaskDeletionsSince(ctx context.Context, since time.Time) ([]storage.TaskDeletion, error) {
+	var deletions []storage.TaskDeletion
+	for _, deletion := range s.deletions {
+		if !deletion.DeletedAt.Before(since) {
+			deletions = append(deletions, deletion)
+		}
+	}
+	return deletions, nil
+}
+
+func getBoard(t *testing.T, tasks storage.TaskStorage, path string, out interface{}) int {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewRESTAPIHandler(tasks, nil, nil, nil, nil, nil, zap.NewNop()).RegisterRESTRoutes(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if out != nil && w.Code == http.StatusOK {
+		var resp struct {
+			Data json.RawMessage `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.NoError(t, json.Unmarshal(resp.Data, out))
+	}
+	return w.Code
+}
+
+func TestGetBoard(t *testing.T) {
+	now := time.Now().UTC()
+	due := now.Add(24 * time.Hour)
+	tasks := &boardStorage{
+		humans: []*storage.HumanTask{
+			{ID: "h1", Prompt: strings.Repeat("Ship the release notes ", 10), Status: storage.TaskStatusInProgress, Project: "web", UpdatedAt: now.Add(-time.Hour)},
+			{ID: "h2", Prompt: "Archive old builds", Status: "archived", UpdatedAt: now},
+		},
+		agents: []*storage.AgentTask{{
+			ID: "a1", HumanTaskID: "h1", AgentName: "go-dev", Role: "Write the changelog", Status: storage.TaskStatusInProgress,
+			Todos:     []storage.TodoItem{{Status: storage.TodoStatusCompleted}, {Status: storage.TodoStatusPending}},
+			UpdatedAt: now, DueAt: &due,
+		}},
+	}
+
+	var board BoardResponse
+	require.Equal(t, http.StatusOK, getBoard(t, tasks, "/api/board", &board))
+	assert.Equal(t, 3, board.Total)
+	statuses := make([]string, len(board.Columns))
+	for i, column := range board.Columns {
+		statuses[i] = column.Status
+	}
+	assert.Equal(t, []string{"pending", "in_progress", "blocked", "completed", "archived"}, statuses, "empty columns are kept and unknown statuses follow")
+
+	inProgress := board.Columns[1]
+	assert.Equal(t, 2, inProgress.Count)
+	require.Len(t, inProgress.Cards, 2)
+	agent, human := inProgress.Cards[0], inProgress.Cards[1]
+	assert.Equal(t, "a1", agent.ID, "most recently updated first")
+	assert.Equal(t, "agent", agent.Kind)
+	assert.Equal(t, 2, agent.TodosTotal)
+	assert.Equal(t, 1, agent.TodosCompleted)
+	assert.NotNil(t, agent.DueAt)
+	assert.Equal(t, "human", human.Kind)
+	assert.Equal(t, "web", human.Project)
+	assert.Len(t, []rune(human.Title), boardTitleRunes)
+	assert.True(t, strings.HasSuffix(human.Title, "…"))
+
+	require.Equal(t, http.StatusOK, getBoard(t, tasks, "/api/board?limit=1", &board))
+	assert.Equal(t, 2, board.Columns[1].Count, "counts cover the cards left out")
+	assert.Len(t, board.Columns[1].Cards, 1)
+
+	require.Equal(t, http.StatusOK, getBoard(t, tasks, "/api/board?kind=human", &board))
+	assert.Equal(t, 2, board.Total)
+	assert.Equal(t, "h1", board.Columns[1].Cards[0].ID)
+
+	assert.Equal(t, http.StatusBadRequest, getBoard(t, tasks, "/api/board?kind=robot", nil))
+}
+
+func TestGetBoardDelta(t *testing.T) {
+	now := time.Now().UTC()
+	since := now.Add(-time.Minute)
+	tasks := &boardStorage{
+		humans: []*storage.HumanTask{
+			{ID: "old", Status: storage.TaskStatusPending, UpdatedAt: now.Add(-time.Hour)},
+			{ID: "new", Status: storage.TaskStatusCompleted, UpdatedAt: now},
+		},
+		deletions: []storage.TaskDeletion{
+			{TaskID: "gone", Kind: "agent", DeletedAt: now},
+			{TaskID: "long-gone", Kind: "human", DeletedAt: now.Add(-time.Hour)},
+		},
+	}
+
+	var delta BoardDeltaResponse
+	require.Equal(t, http.StatusOK, getBoard(t, tasks, "/api/board/delta?since="+since.Format(time.RFC3339Nano), &delta))
+	require.Len(t, delta.Changed, 1)
+	assert.Equal(t, "new", delta.Changed[0].ID)
+	assert.Equal(t, []string{"gone"}, delta.Removed)
+	assert.False(t, delta.Reset)
+	assert.NotEmpty(t, delta.AsOf)
+
+	require.Equal(t, http.StatusOK, getBoard(t, tasks, "/api/board/delta?kind=human&since="+since.Format(time.RFC3339Nano), &delta))
+	assert.Empty(t, delta.Removed, "removals are filtered by kind")
+
+	tasks.deletions = append(tasks.deletions, storage.TaskDeletion{TaskID: storage.BoardClearedID, Kind: "board", DeletedAt: now})
+	require.Equal(t, http.StatusOK, getBoard(t, tasks, "/api/board/delta?since="+since.Format(time.RFC3339Nano), &delta))
+	assert.True(t, delta.Reset, "a cleared board is reloaded")
+
+	old := now.Add(-storage.TaskDeletionRetention - time.Hour).UnixMilli()
+	require.Equal(t, http.StatusOK, getBoard(t, tasks, "/api/board/delta?since="+strconv.FormatInt(old, 10), &delta))
+	assert.True(t, delta.Reset, "deletions older than the retention are unknown")
+
+	assert.Equal(t, http.StatusBadRequest, getBoard(t, tasks, "/api/board/delta", nil))
+	assert.Equal(t, http.StatusBadRequest, getBoard(t, tasks, "/api/board/delta?since=yesterday", nil))
+}
+
+// linkedBoardStorage adds the link and automation support the production
+// wrappers require of the storage they wrap
+type linkedBoardStorage struct {
+	*boardStorage
+	storage.JiraLinkStorage
+	storage.LinearLinkStorage
+	storage.TaskAutomationStorage
+}
+
+func TestGetBoardDelta_WrappedStorage(t *testing.T) {
+	t.Setenv(notify.RecipientsEnv, "ops@example.com")
+	now := time.Now().UTC()
+	base := &linkedBoardStorage{boardStorage: &boardStorage{
+		humans:    []*storage.HumanTask{{ID: "new", Status: storage.TaskStatusPending, UpdatedAt: now}},
+		deletions: []storage.TaskDeletion{{TaskID: "gone", Kind: "human", DeletedAt: now}},
+	}}
+
+	// Wrap the storage as cmd/coordinator does
+	var tasks storage.TaskStorage = base
+	jiraSync, err := jira.NewSync(jira.Config{}, base, zap.NewNop())
+	require.NoError(t, err)
+	tasks = jiraSync.Mirror(tasks)
+	linearSync, err := linear.NewSync(linear.Config{}, base, zap.NewNop())
+	require.NoError(t, err)
+	tasks = linearSync.Mirror(tasks)
+	tasks = notify.WatchBlockedTasks(tasks, notify.NewMailer(notify.SMTPConfig{Host: "smtp.example.com", From: "hyper@example.com"}), zap.NewNop())
+	engine, err := automation.NewEngine(nil, base, zap.NewNop())
+	require.NoError(t, err)
+	tasks = engine.Watch(tasks)
+	_, direct := tasks.(storage.TaskBoardStorage)
+	require.False(t, direct, "the wrappers hide the board methods")
+
+	var delta BoardDeltaResponse
+	since := now.Add(-time.Minute).Format(time.RFC3339Nano)
+	require.Equal(t, http.StatusOK, getBoard(t, tasks, "/api/board/delta?since="+since, &delta))
+	assert.False(t, delta.Reset)
+	require.Len(t, delta.Changed, 1)
+	assert.Equal(t, "new", delta.Changed[0].ID)
+	assert.Equal(t, []string{"gone"}, delta.Removed)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"hyper/internal/bulktasks"
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
+	"hyper/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetBulkEditor enables the bulk agent task edit route
+func (h *RESTAPIHandler) SetBulkEditor(editor *bulktasks.Editor) {
+	h.bulkEditor = editor
+}
+
+// BulkUpdateAgentTasks changes the status, tags, priority or assignee of the
+// agent tasks listed in taskIds or matching filter. With preview=true it only
+// reports what would change.
+// POST /api/v1/agent-tasks/bulk
+func (h *RESTAPIHandler) BulkUpdateAgentTasks(c *gin.Context) {
+	if h.bulkEditor == nil {
+		errcode.RespondCode(c, errcode.DependencyUnavailable, "Bulk task edits are not configured")
+		return
+	}
+
+	var req bulktasks.Request
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
+		return
+	}
+
+	result, err := h.bulkEditor.Apply(req)
+	if err != nil {
+		errcode.RespondCode(c, errcode.Validation, err.Error())
+		return
+	}
+	envelope.OK(c, result)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hyper/internal/bulktasks"
+	"hyper/internal/mcp/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// bulkStorage serves agent tasks and applies status changes and reassignments
+type bulkStorage struct {
+	storage.TaskStorage
+	bulktasks.Changes
+	agents []*storage.AgentTask
+}
+
+func (s *bulkStorage) ListAllAgentTasks() []*storage.AgentTask {
+	return s.agents
+}
+
+func (s *bulkStorage) UpdateTaskStatus(taskID string, status storage.TaskStatus, notes string) error {
+	for _, task := range s.agents {
+		if task.ID == taskID {
+			task.Status = status
+		}
+	}
+	return nil
+}
+
+func postBulk(t *testing.T, editor *bulktasks.Editor, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewRESTAPIHandler(nil, nil, nil, nil, nil, nil, zap.NewNop())
+	if editor != nil {
+		h.SetBulkEditor(editor)
+	}
+	h.RegisterRESTRoutes(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/agent-tasks/bulk", strings.NewReader(body)))
+	return w
+}
+
+func TestBulkUpdateAgentTasks(t *testing.T) {
+	tasks := &bulkStorage{agents: []*storage.AgentTask{
+		{ID: "a-1", HumanTaskID: "h-1", Status: storage.TaskStatusBlocked},
+		{ID: "a-2", HumanTaskID: "h-1", Status: storage.TaskStatusCompleted},
+	}}
+	editor := bulktasks.NewEditor(tasks, tasks, nil, zap.NewNop())
+
+	w := postBulk(t, editor, `{"filter": {"humanTaskId": "h-1"}, "status": "pending", "preview": true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data bulktasks.Result `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Data.Changed)
+	assert.Equal(t, storage.TaskStatusBlocked, tasks.agents[0].Status, "previews change nothing")
+
+	w = postBulk(t, editor, `{"taskIds": ["a-1"], "status": "pending"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, storage.TaskStatusPending, tasks.agents[0].Status)
+
+	assert.Equal(t, http.StatusBadRequest, postBulk(t, editor, `{"taskIds": ["a-1"]}`).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, postBulk(t, nil, `{"taskIds": ["a-1"], "status": "pending"}`).Code)
+}
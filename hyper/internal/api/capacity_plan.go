@@ -0,0 +1,52 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"hyper/internal/capacity"
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCapacityPlan estimates when each project's open agent tasks complete and
+// which agents hold them back. concurrency and agents try other capacity.
+// GET /api/board/plan?concurrency=N&agents=name:N,name:N&historyDays=N&project=name
+func (h *RESTAPIHandler) GetCapacityPlan(c *gin.Context) {
+	opts := capacity.Options{Project: c.Query("project")}
+
+	if raw := c.Query("concurrency"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			errcode.RespondCode(c, errcode.Validation, "concurrency must be a positive integer")
+			return
+		}
+		opts.Concurrency = n
+	}
+	if raw := c.Query("agents"); raw != "" {
+		opts.AgentConcurrency = map[string]int{}
+		for _, pair := range strings.Split(raw, ",") {
+			name, value, found := strings.Cut(pair, ":")
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if !found || strings.TrimSpace(name) == "" || err != nil || n < 1 {
+				errcode.RespondCode(c, errcode.Validation, "agents must list agentName:concurrency pairs, e.g. backend-services:2,ui-dev:3")
+				return
+			}
+			opts.AgentConcurrency[strings.TrimSpace(name)] = n
+		}
+	}
+	if raw := c.Query("historyDays"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			errcode.RespondCode(c, errcode.Validation, "historyDays must be a positive integer")
+			return
+		}
+		opts.History = time.Duration(n) * 24 * time.Hour
+	}
+
+	plan := capacity.Build(h.taskStorage.ListAllHumanTasks(), h.taskStorage.ListAllAgentTasks(), opts)
+	envelope.OK(c, plan)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"hyper/internal/capacity"
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCapacityPlan(t *testing.T) {
+	tasks := &graphStorage{
+		humans: []*storage.HumanTask{{ID: "h1", Project: "web", Status: storage.TaskStatusPending}},
+		agents: []*storage.AgentTask{
+			{ID: "a1", HumanTaskID: "h1", AgentName: "backend", Status: storage.TaskStatusPending},
+			{ID: "a2", HumanTaskID: "h1", AgentName: "backend", Status: storage.TaskStatusPending},
+		},
+	}
+
+	var plan capacity.Plan
+	require.Equal(t, http.StatusOK, getBoard(t, tasks, "/api/board/plan?agents=backend:2&historyDays=7", &plan))
+	assert.Equal(t, 7, plan.HistoryDays)
+	require.Len(t, plan.Agents, 1)
+	assert.Equal(t, 2, plan.Agents[0].Concurrency)
+	assert.Equal(t, []string{"backend"}, plan.Bottlenecks)
+	require.Len(t, plan.Projects, 1)
+	assert.NotNil(t, plan.Projects[0].CompletesAt)
+
+	for _, query := range []string{"concurrency=0", "agents=backend", "agents=backend:x", "historyDays=-1"} {
+		assert.Equal(t, http.StatusBadRequest, getBoard(t, tasks, "/api/board/plan?"+query, nil), query)
+	}
+}
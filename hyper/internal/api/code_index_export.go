@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ExportCodeIndex streams indexed code chunks as NDJSON, one
+// storage.ExportedCodeChunk per line, for cache instances (--mode cache).
+// folder limits the export to one indexed folder, since (RFC 3339) to files
+// updated at or after that time. Chunks carry their embeddings unless
+// vectors=false.
+// GET /api/v1/code-index/export?folder=/repo&since=2025-01-02T15:04:05Z
+func (h *RESTAPIHandler) ExportCodeIndex(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			errcode.RespondCode(c, errcode.Validation, fmt.Sprintf("invalid since %q: must be an RFC 3339 time", raw))
+			return
+		}
+		since = parsed
+	}
+
+	folders, err := h.codeIndexStorage.ListFolders()
+	if err != nil {
+		errcode.Respond(c, err, "Failed to list indexed folders: "+err.Error())
+		return
+	}
+	if path := c.Query("folder"); path != "" {
+		var selected []*storage.IndexedFolder
+		for _, folder := range folders {
+			if folder.Path == path {
+				selected = append(selected, folder)
+			}
+		}
+		if len(selected) == 0 {
+			errcode.RespondCode(c, errcode.NotFound, fmt.Sprintf("Folder is not indexed: %s", path))
+			return
+		}
+		folders = selected
+	}
+	withVectors := c.Query("vectors") != "false" && h.qdrantClient != nil
+
+	// The response is streamed, so the status is committed before the first chunk
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	exported := 0
+	encoder := json.NewEncoder(c.Writer)
+	for _, folder := range folders {
+		var vectors func(ids []string) (map[string][]float64, error)
+		if withVectors {
+			collection := storage.CodeIndexCollection
+			if mapping, _ := h.codeIndexStorage.GetPathMapping(folder.Path); mapping != nil {
+				collection = mapping.QdrantCollection
+			}
+			vectors = func(ids []string) (map[string][]float64, error) {
+				return h.qdrantClient.PointVectors(collection, ids)
+			}
+		}
+
+		err = h.codeIndexStorage.ExportCodeChunks(c.Request.Context(), folder, since, vectors, func(chunk *storage.ExportedCodeChunk) error {
+			if err := encoder.Encode(chunk); err != nil {
+				return fmt.Errorf("failed to write export: %w", err)
+			}
+			exported++
+			if exported%100 == 0 {
+				c.Writer.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			break
+		}
+	}
+
+	h.logger.Info("Code index export finished",
+		zap.Int("folders", len(folders)),
+		zap.Time("since", since),
+		zap.Int("exported", exported),
+		zap.Error(err))
+
+	if err != nil {
+		// End the stream with an error envelope so a truncated export is detectable
+		_ = encoder.Encode(envelope.Response{Error: &envelope.Error{
+			Code:    string(errcode.Of(err)),
+			Message: err.Error(),
+		}})
+	}
+}
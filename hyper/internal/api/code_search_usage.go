@@ -0,0 +1,43 @@
+package api
+
+import (
+	"strconv"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCodeSearchUsage reports which areas of each indexed folder code searches
+// return results from, and which are indexed but never returned
+// GET /api/v1/code-index/usage?folder=/repo&depth=2&limit=20
+func (h *RESTAPIHandler) GetCodeSearchUsage(c *gin.Context) {
+	depth := 0
+	if raw := c.Query("depth"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			errcode.RespondCode(c, errcode.Validation, "depth must be a positive integer")
+			return
+		}
+		depth = n
+	}
+
+	// Parse limit of listed areas per folder (default 20, max 100)
+	limit := 20
+	if raw := c.Query("limit"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			limit = n
+			if limit > 100 {
+				limit = 100
+			}
+		}
+	}
+
+	report, err := h.codeIndexStorage.CodeSearchUsage(c.Query("folder"), depth, limit)
+	if err != nil {
+		errcode.Respond(c, err, "Failed to build code search usage: "+err.Error())
+		return
+	}
+	envelope.OK(c, report)
+}
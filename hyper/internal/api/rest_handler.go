@@ -1,17 +1,27 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"hyper/internal/bulktasks"
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
 	"hyper/internal/mcp/embeddings"
 	"hyper/internal/mcp/scanner"
 	"hyper/internal/mcp/storage"
+	"hyper/internal/mcp/summarizer"
 	"hyper/internal/mcp/watcher"
+	"hyper/internal/priority"
+	"hyper/internal/undo"
+	"hyper/internal/validation"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,46 +30,70 @@ import (
 
 // REST API Data Transfer Objects (DTOs)
 type TaskDTO struct {
-	ID        string `json:"id"`
-	Prompt    string `json:"prompt"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
-	Status    string `json:"status"`
-	Notes     string `json:"notes,omitempty"`
+	ID         string `json:"id"`
+	Prompt     string `json:"prompt"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+	Status     string `json:"status"`
+	Notes      string `json:"notes,omitempty"`
+	Project    string `json:"project,omitempty"`
+	ClonedFrom string `json:"clonedFrom,omitempty"`
 }
 
 type TodoItemDTO struct {
-	ID                        string  `json:"id"`
-	Description               string  `json:"description"`
-	Status                    string  `json:"status"`
-	CreatedAt                 string  `json:"createdAt"`
-	CompletedAt               *string `json:"completedAt,omitempty"`
-	Notes                     string  `json:"notes,omitempty"`
-	FilePath                  string  `json:"filePath,omitempty"`
-	FunctionName              string  `json:"functionName,omitempty"`
-	ContextHint               string  `json:"contextHint,omitempty"`
-	HumanPromptNotes          string  `json:"humanPromptNotes,omitempty"`
-	HumanPromptNotesAddedAt   *string `json:"humanPromptNotesAddedAt,omitempty"`
-	HumanPromptNotesUpdatedAt *string `json:"humanPromptNotesUpdatedAt,omitempty"`
+	ID                        string             `json:"id"`
+	Description               string             `json:"description"`
+	Status                    string             `json:"status"`
+	CreatedAt                 string             `json:"createdAt"`
+	CompletedAt               *string            `json:"completedAt,omitempty"`
+	Notes                     string             `json:"notes,omitempty"`
+	FilePath                  string             `json:"filePath,omitempty"`
+	FunctionName              string             `json:"functionName,omitempty"`
+	ContextHint               string             `json:"contextHint,omitempty"`
+	HumanPromptNotes          string             `json:"humanPromptNotes,omitempty"`
+	HumanPromptNotesAddedAt   *string            `json:"humanPromptNotesAddedAt,omitempty"`
+	HumanPromptNotesUpdatedAt *string            `json:"humanPromptNotesUpdatedAt,omitempty"`
+	Checklist                 []ChecklistItemDTO `json:"checklist,omitempty"`
+	EstimatedMinutes          int                `json:"estimatedMinutes,omitempty"`
+	ActualMinutes             int                `json:"actualMinutes,omitempty"`
+}
+
+type ChecklistItemDTO struct {
+	ID          string  `json:"id"`
+	Description string  `json:"description"`
+	Status      string  `json:"status"`
+	CompletedAt *string `json:"completedAt,omitempty"`
+	Notes       string  `json:"notes,omitempty"`
 }
 
 type AgentTaskDTO struct {
-	ID                        string        `json:"id"`
-	HumanTaskID               string        `json:"humanTaskId"`
-	AgentName                 string        `json:"agentName"`
-	Role                      string        `json:"role"`
-	Todos                     []TodoItemDTO `json:"todos"`
-	CreatedAt                 string        `json:"createdAt"`
-	UpdatedAt                 string        `json:"updatedAt"`
-	Status                    string        `json:"status"`
-	Notes                     string        `json:"notes,omitempty"`
-	ContextSummary            string        `json:"contextSummary,omitempty"`
-	FilesModified             []string      `json:"filesModified,omitempty"`
-	QdrantCollections         []string      `json:"qdrantCollections,omitempty"`
-	PriorWorkSummary          string        `json:"priorWorkSummary,omitempty"`
-	HumanPromptNotes          string        `json:"humanPromptNotes,omitempty"`
-	HumanPromptNotesAddedAt   *string       `json:"humanPromptNotesAddedAt,omitempty"`
-	HumanPromptNotesUpdatedAt *string       `json:"humanPromptNotesUpdatedAt,omitempty"`
+	ID                        string          `json:"id"`
+	HumanTaskID               string          `json:"humanTaskId"`
+	AgentName                 string          `json:"agentName"`
+	Role                      string          `json:"role"`
+	Todos                     []TodoItemDTO   `json:"todos"`
+	CreatedAt                 string          `json:"createdAt"`
+	UpdatedAt                 string          `json:"updatedAt"`
+	Status                    string          `json:"status"`
+	Notes                     string          `json:"notes,omitempty"`
+	ContextSummary            string          `json:"contextSummary,omitempty"`
+	FilesModified             []string        `json:"filesModified,omitempty"`
+	QdrantCollections         []string        `json:"qdrantCollections,omitempty"`
+	PriorWorkSummary          string          `json:"priorWorkSummary,omitempty"`
+	HumanPromptNotes          string          `json:"humanPromptNotes,omitempty"`
+	HumanPromptNotesAddedAt   *string         `json:"humanPromptNotesAddedAt,omitempty"`
+	HumanPromptNotesUpdatedAt *string         `json:"humanPromptNotesUpdatedAt,omitempty"`
+	ChangeLog                 []FileChangeDTO `json:"changeLog,omitempty"`
+}
+
+type FileChangeDTO struct {
+	Path          string `json:"path"`
+	Operation     string `json:"operation"`
+	ObservedAt    string `json:"observedAt"`
+	ChunksIndexed int    `json:"chunksIndexed"`
+	ChunksRemoved int    `json:"chunksRemoved"`
+	LinesAdded    int    `json:"linesAdded"`
+	LinesRemoved  int    `json:"linesRemoved"`
 }
 
 type CreateHumanTaskRequest struct {
@@ -70,6 +104,16 @@ type CreateHumanTaskResponse struct {
 	Task TaskDTO `json:"task"`
 }
 
+type CloneHumanTaskRequest struct {
+	Project string `json:"project,omitempty"`
+	Prompt  string `json:"prompt,omitempty"`
+}
+
+type CloneHumanTaskResponse struct {
+	Task       TaskDTO        `json:"task"`
+	AgentTasks []AgentTaskDTO `json:"agentTasks"`
+}
+
 type ListHumanTasksResponse struct {
 	Tasks []TaskDTO `json:"tasks"`
 	Count int       `json:"count"`
@@ -86,14 +130,14 @@ type UpdateTaskStatusResponse struct {
 }
 
 type CreateAgentTaskRequest struct {
-	HumanTaskID       string                    `json:"humanTaskId" binding:"required"`
-	AgentName         string                    `json:"agentName" binding:"required"`
-	Role              string                    `json:"role" binding:"required"`
-	Todos             []storage.TodoItemInput   `json:"todos" binding:"required"`
-	ContextSummary    string                    `json:"contextSummary,omitempty"`
-	FilesModified     []string                  `json:"filesModified,omitempty"`
-	QdrantCollections []string                  `json:"qdrantCollections,omitempty"`
-	PriorWorkSummary  string                    `json:"priorWorkSummary,omitempty"`
+	HumanTaskID       string                  `json:"humanTaskId" binding:"required"`
+	AgentName         string                  `json:"agentName" binding:"required"`
+	Role              string                  `json:"role" binding:"required"`
+	Todos             []storage.TodoItemInput `json:"todos" binding:"required"`
+	ContextSummary    string                  `json:"contextSummary,omitempty"`
+	FilesModified     []string                `json:"filesModified,omitempty"`
+	QdrantCollections []string                `json:"qdrantCollections,omitempty"`
+	PriorWorkSummary  string                  `json:"priorWorkSummary,omitempty"`
 }
 
 type CreateAgentTaskResponse struct {
@@ -101,20 +145,25 @@ type CreateAgentTaskResponse struct {
 }
 
 type ListAgentTasksResponse struct {
-	Tasks      []AgentTaskDTO `json:"tasks"`
-	Count      int            `json:"count"`
-	TotalCount int            `json:"totalCount"`
-	Offset     int            `json:"offset"`
-	Limit      int            `json:"limit"`
+	Tasks []AgentTaskDTO `json:"tasks"`
+	Count int            `json:"count"`
 }
 
 type GetAgentTaskResponse struct {
 	Task AgentTaskDTO `json:"task"`
 }
 
+type GetAgentTaskActivityResponse struct {
+	AgentTaskID string                 `json:"agentTaskId"`
+	Activity    []storage.TaskActivity `json:"activity"`
+	Count       int                    `json:"count"`
+}
+
 type UpdateTodoStatusRequest struct {
-	Status string `json:"status" binding:"required"`
-	Notes  string `json:"notes,omitempty"`
+	Status           string `json:"status" binding:"required"`
+	Notes            string `json:"notes,omitempty"`
+	EstimatedMinutes *int   `json:"estimatedMinutes,omitempty"`
+	ActualMinutes    *int   `json:"actualMinutes,omitempty"`
 }
 
 type UpdateTodoStatusResponse struct {
@@ -154,7 +203,6 @@ type PopularCollectionsResponse struct {
 type BrowseKnowledgeResponse struct {
 	Entries []KnowledgeEntryDTO `json:"entries"`
 	Count   int                 `json:"count"`
-	Limit   int                 `json:"limit"`
 }
 
 type QueryKnowledgeRequest struct {
@@ -180,17 +228,35 @@ type AddFolderResponse struct {
 }
 
 type RemoveFolderResponse struct {
-	Success      bool   `json:"success"`
-	Message      string `json:"message"`
-	FilesRemoved int    `json:"filesRemoved,omitempty"`
+	Success      bool                  `json:"success"`
+	Message      string                `json:"message"`
+	FilesRemoved int                   `json:"filesRemoved,omitempty"`
+	Operation    *undo.StagedOperation `json:"operation,omitempty"` // Set when the removal is staged
+}
+
+type ScanFolderRequest struct {
+	FolderPath string `json:"folderPath" binding:"required"`
+	DryRun     bool   `json:"dryRun,omitempty"` // Estimate only; nothing is embedded or stored
+
+	// ScanConcurrency replaces the folder's concurrency bounds for this and
+	// later scans; zero bounds restore the defaults
+	ScanConcurrency *storage.ScanConcurrency `json:"scanConcurrency,omitempty"`
+}
+
+type ScanDryRunResponse struct {
+	Success  bool                  `json:"success"`
+	DryRun   bool                  `json:"dryRun"`
+	Estimate *scanner.ScanEstimate `json:"estimate"`
 }
 
 type ScanResponse struct {
-	Success      bool `json:"success"`
-	FilesIndexed int  `json:"filesIndexed"`
-	FilesUpdated int  `json:"filesUpdated"`
-	FilesSkipped int  `json:"filesSkipped"`
-	TotalFiles   int  `json:"totalFiles"`
+	Success       bool                     `json:"success"`
+	FilesIndexed  int                      `json:"filesIndexed"`
+	FilesUpdated  int                      `json:"filesUpdated"`
+	FilesSkipped  int                      `json:"filesSkipped"`
+	TotalFiles    int                      `json:"totalFiles"`
+	EstimatedCost embeddings.CostEstimate  `json:"estimatedCost"`
+	Concurrency   scanner.ConcurrencyStats `json:"concurrency"`
 }
 
 type SearchRequest struct {
@@ -211,10 +277,13 @@ type SearchResultDTO struct {
 	StartLine         int     `json:"startLine,omitempty"`
 	EndLine           int     `json:"endLine,omitempty"`
 	Content           string  `json:"content"`
+	Summary           string  `json:"summary,omitempty"`
 	Score             float32 `json:"score"`
 	FolderID          string  `json:"folderId"`
 	FolderPath        string  `json:"folderPath"`
 	FullFileRetrieved bool    `json:"fullFileRetrieved"`
+
+	RecentTasks []storage.TaskReference `json:"recentTasks,omitempty"` // Agent tasks that declared this file in filesModified
 }
 
 type SearchResponse struct {
@@ -249,6 +318,10 @@ type RESTAPIHandler struct {
 	embeddingClient  embeddings.EmbeddingClient
 	fileScanner      *scanner.FileScanner
 	fileWatcher      *watcher.FileWatcher
+	summarizer       summarizer.Summarizer
+	artifacts        *storage.TaskArtifactStorage // Optional: files attached to agent tasks
+	bulkEditor       *bulktasks.Editor            // Optional: bulk agent task edits
+	undoManager      *undo.Manager                // Optional: stages folder removals for an undo window
 	logger           *zap.Logger
 }
 
@@ -270,6 +343,7 @@ func NewRESTAPIHandler(
 		embeddingClient:  embeddingClient,
 		fileScanner:      scanner.NewFileScanner(),
 		fileWatcher:      fileWatcher,
+		summarizer:       summarizer.FromEnv(),
 		logger:           logger,
 	}
 }
@@ -278,12 +352,14 @@ func NewRESTAPIHandler(
 
 func convertTaskToDTO(task *storage.HumanTask) TaskDTO {
 	return TaskDTO{
-		ID:        task.ID,
-		Prompt:    task.Prompt,
-		CreatedAt: task.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
-		UpdatedAt: task.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
-		Status:    string(task.Status),
-		Notes:     task.Notes,
+		ID:         task.ID,
+		Prompt:     task.Prompt,
+		CreatedAt:  task.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+		UpdatedAt:  task.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
+		Status:     string(task.Status),
+		Notes:      task.Notes,
+		Project:    task.Project,
+		ClonedFrom: task.ClonedFrom,
 	}
 }
 
@@ -298,6 +374,8 @@ func convertTodoItemToDTO(todo *storage.TodoItem) TodoItemDTO {
 		FunctionName:     todo.FunctionName,
 		ContextHint:      todo.ContextHint,
 		HumanPromptNotes: todo.HumanPromptNotes,
+		EstimatedMinutes: todo.EstimatedMinutes,
+		ActualMinutes:    todo.ActualMinutes,
 	}
 
 	if todo.CompletedAt != nil {
@@ -315,6 +393,20 @@ func convertTodoItemToDTO(todo *storage.TodoItem) TodoItemDTO {
 		dto.HumanPromptNotesUpdatedAt = &updatedStr
 	}
 
+	for _, item := range todo.Checklist {
+		itemDTO := ChecklistItemDTO{
+			ID:          item.ID,
+			Description: item.Description,
+			Status:      string(item.Status),
+			Notes:       item.Notes,
+		}
+		if item.CompletedAt != nil {
+			completedStr := item.CompletedAt.Format("2006-01-02T15:04:05.000Z")
+			itemDTO.CompletedAt = &completedStr
+		}
+		dto.Checklist = append(dto.Checklist, itemDTO)
+	}
+
 	return dto
 }
 
@@ -351,6 +443,18 @@ func convertAgentTaskToDTO(task *storage.AgentTask) AgentTaskDTO {
 		dto.HumanPromptNotesUpdatedAt = &updatedStr
 	}
 
+	for _, change := range task.ChangeLog {
+		dto.ChangeLog = append(dto.ChangeLog, FileChangeDTO{
+			Path:          change.Path,
+			Operation:     change.Operation,
+			ObservedAt:    change.ObservedAt.Format("2006-01-02T15:04:05.000Z"),
+			ChunksIndexed: change.ChunksIndexed,
+			ChunksRemoved: change.ChunksRemoved,
+			LinesAdded:    change.LinesAdded,
+			LinesRemoved:  change.LinesRemoved,
+		})
+	}
+
 	return dto
 }
 
@@ -360,18 +464,18 @@ func convertAgentTaskToDTO(task *storage.AgentTask) AgentTaskDTO {
 // POST /api/v1/tasks
 func (h *RESTAPIHandler) CreateHumanTask(c *gin.Context) {
 	var req CreateHumanTaskRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
 	task, err := h.taskStorage.CreateHumanTask(req.Prompt)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task: " + err.Error()})
+		errcode.Respond(c, err, "Failed to create task: "+err.Error())
 		return
 	}
 
-	c.JSON(http.StatusCreated, CreateHumanTaskResponse{
+	envelope.JSON(c, http.StatusCreated, CreateHumanTaskResponse{
 		Task: convertTaskToDTO(task),
 	})
 }
@@ -386,10 +490,10 @@ func (h *RESTAPIHandler) ListHumanTasks(c *gin.Context) {
 		dtos[i] = convertTaskToDTO(task)
 	}
 
-	c.JSON(http.StatusOK, ListHumanTasksResponse{
+	envelope.List(c, ListHumanTasksResponse{
 		Tasks: dtos,
 		Count: len(dtos),
-	})
+	}, envelope.Complete(len(dtos)))
 }
 
 // GetHumanTask returns a single human task by ID
@@ -399,11 +503,11 @@ func (h *RESTAPIHandler) GetHumanTask(c *gin.Context) {
 
 	task, err := h.taskStorage.GetHumanTask(taskID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		errcode.RespondCode(c, errcode.NotFound, "Task not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"task": convertTaskToDTO(task)})
+	envelope.OK(c, gin.H{"task": convertTaskToDTO(task)})
 }
 
 // UpdateTaskStatus updates the status of a task (human or agent)
@@ -412,29 +516,59 @@ func (h *RESTAPIHandler) UpdateTaskStatus(c *gin.Context) {
 	taskID := c.Param("id")
 
 	var req UpdateTaskStatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
 	err := h.taskStorage.UpdateTaskStatus(taskID, storage.TaskStatus(req.Status), req.Notes)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status: " + err.Error()})
+		errcode.Respond(c, err, "Failed to update status: "+err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, UpdateTaskStatusResponse{
+	envelope.OK(c, UpdateTaskStatusResponse{
 		Success: true,
 		Message: fmt.Sprintf("Task status updated to %s", req.Status),
 	})
 }
 
+// CloneHumanTask deep-copies a human task with its agent tasks and TODOs
+// POST /api/v1/tasks/:id/clone
+func (h *RESTAPIHandler) CloneHumanTask(c *gin.Context) {
+	taskID := c.Param("id")
+
+	var req CloneHumanTaskRequest
+	if c.Request.ContentLength > 0 {
+		if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+			validation.RespondBinding(c, err, &req)
+			return
+		}
+	}
+
+	clone, agentTasks, err := h.taskStorage.CloneHumanTask(taskID, req.Project, req.Prompt)
+	if err != nil {
+		errcode.Respond(c, err, "Failed to clone task: "+err.Error())
+		return
+	}
+
+	agentDTOs := make([]AgentTaskDTO, len(agentTasks))
+	for i, task := range agentTasks {
+		agentDTOs[i] = convertAgentTaskToDTO(task)
+	}
+
+	envelope.JSON(c, http.StatusCreated, CloneHumanTaskResponse{
+		Task:       convertTaskToDTO(clone),
+		AgentTasks: agentDTOs,
+	})
+}
+
 // CreateAgentTask creates a new agent task
 // POST /api/v1/agent-tasks
 func (h *RESTAPIHandler) CreateAgentTask(c *gin.Context) {
 	var req CreateAgentTaskRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
@@ -449,11 +583,11 @@ func (h *RESTAPIHandler) CreateAgentTask(c *gin.Context) {
 		req.PriorWorkSummary,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create agent task: " + err.Error()})
+		errcode.Respond(c, err, "Failed to create agent task: "+err.Error())
 		return
 	}
 
-	c.JSON(http.StatusCreated, CreateAgentTaskResponse{
+	envelope.JSON(c, http.StatusCreated, CreateAgentTaskResponse{
 		Task: convertAgentTaskToDTO(task),
 	})
 }
@@ -514,13 +648,10 @@ func (h *RESTAPIHandler) ListAgentTasks(c *gin.Context) {
 		dtos[i] = convertAgentTaskToDTO(task)
 	}
 
-	c.JSON(http.StatusOK, ListAgentTasksResponse{
-		Tasks:      dtos,
-		Count:      len(dtos),
-		TotalCount: totalCount,
-		Offset:     offset,
-		Limit:      limit,
-	})
+	envelope.List(c, ListAgentTasksResponse{
+		Tasks: dtos,
+		Count: len(dtos),
+	}, envelope.NewPagination(totalCount, limit, offset))
 }
 
 // GetAgentTask returns a single agent task by ID
@@ -530,15 +661,33 @@ func (h *RESTAPIHandler) GetAgentTask(c *gin.Context) {
 
 	task, err := h.taskStorage.GetAgentTask(taskID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Agent task not found"})
+		errcode.RespondCode(c, errcode.NotFound, "Agent task not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, GetAgentTaskResponse{
+	envelope.OK(c, GetAgentTaskResponse{
 		Task: convertAgentTaskToDTO(task),
 	})
 }
 
+// GetAgentTaskActivity returns the activity log of an agent task
+// GET /api/v1/agent-tasks/:id/activity
+func (h *RESTAPIHandler) GetAgentTaskActivity(c *gin.Context) {
+	taskID := c.Param("id")
+
+	activity, err := h.taskStorage.GetAgentTaskActivity(taskID)
+	if err != nil {
+		errcode.Respond(c, err, "Failed to get agent task activity: "+err.Error())
+		return
+	}
+
+	envelope.List(c, GetAgentTaskActivityResponse{
+		AgentTaskID: taskID,
+		Activity:    activity,
+		Count:       len(activity),
+	}, envelope.Complete(len(activity)))
+}
+
 // UpdateTodoStatus updates the status of a TODO item
 // PUT /api/v1/agent-tasks/:agentTaskId/todos/:todoId/status
 func (h *RESTAPIHandler) UpdateTodoStatus(c *gin.Context) {
@@ -546,23 +695,60 @@ func (h *RESTAPIHandler) UpdateTodoStatus(c *gin.Context) {
 	todoID := c.Param("todoId")
 
 	var req UpdateTodoStatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
+		return
+	}
+
+	if err := h.taskStorage.UpdateTodoTime(agentTaskID, todoID, req.EstimatedMinutes, req.ActualMinutes); err != nil {
+		errcode.Respond(c, err, "Failed to update TODO time: "+err.Error())
 		return
 	}
 
 	err := h.taskStorage.UpdateTodoStatus(agentTaskID, todoID, storage.TodoStatus(req.Status), req.Notes)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update TODO status: " + err.Error()})
+		errcode.Respond(c, err, "Failed to update TODO status: "+err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, UpdateTodoStatusResponse{
+	envelope.OK(c, UpdateTodoStatusResponse{
 		Success: true,
 		Message: fmt.Sprintf("TODO status updated to %s", req.Status),
 	})
 }
 
+// UpdateChecklistItemStatus updates the status of a checklist item within a TODO.
+// The TODO is completed automatically once all of its checklist items are.
+// PUT /api/v1/agent-tasks/:agentTaskId/todos/:todoId/checklist/:itemId/status
+func (h *RESTAPIHandler) UpdateChecklistItemStatus(c *gin.Context) {
+	agentTaskID := c.Param("agentTaskId")
+	todoID := c.Param("todoId")
+	itemID := c.Param("itemId")
+
+	var req UpdateTodoStatusRequest
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
+		return
+	}
+
+	// Time tracking applies to the parent TODO
+	if err := h.taskStorage.UpdateTodoTime(agentTaskID, todoID, req.EstimatedMinutes, req.ActualMinutes); err != nil {
+		errcode.Respond(c, err, "Failed to update TODO time: "+err.Error())
+		return
+	}
+
+	err := h.taskStorage.UpdateChecklistItemStatus(agentTaskID, todoID, itemID, storage.TodoStatus(req.Status), req.Notes)
+	if err != nil {
+		errcode.Respond(c, err, "Failed to update checklist item status: "+err.Error())
+		return
+	}
+
+	envelope.OK(c, UpdateTodoStatusResponse{
+		Success: true,
+		Message: fmt.Sprintf("Checklist item status updated to %s", req.Status),
+	})
+}
+
 // Knowledge Handlers
 
 // ListCollections returns all knowledge collections with metadata
@@ -571,7 +757,7 @@ func (h *RESTAPIHandler) ListCollections(c *gin.Context) {
 	collections, err := h.knowledgeStorage.GetCollectionStatsWithMetadata()
 	if err != nil {
 		h.logger.Error("Failed to get collection stats", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve collections: " + err.Error()})
+		errcode.Respond(c, err, "Failed to retrieve collections: "+err.Error())
 		return
 	}
 
@@ -585,9 +771,9 @@ func (h *RESTAPIHandler) ListCollections(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, ListCollectionsResponse{
+	envelope.List(c, ListCollectionsResponse{
 		Collections: dtos,
-	})
+	}, envelope.Complete(len(dtos)))
 }
 
 // GetPopularCollections returns popular collections in frontend-compatible format
@@ -608,7 +794,7 @@ func (h *RESTAPIHandler) GetPopularCollections(c *gin.Context) {
 	collections, err := h.knowledgeStorage.GetPopularCollections(limit)
 	if err != nil {
 		h.logger.Error("Failed to get popular collections", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve popular collections"})
+		errcode.Respond(c, err, "Failed to retrieve popular collections")
 		return
 	}
 
@@ -621,9 +807,9 @@ func (h *RESTAPIHandler) GetPopularCollections(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, PopularCollectionsResponse{
+	envelope.List(c, PopularCollectionsResponse{
 		Collections: dtos,
-	})
+	}, envelope.NewPagination(len(dtos), limit, 0))
 }
 
 // BrowseKnowledge retrieves knowledge entries without search (browse mode)
@@ -690,19 +876,18 @@ func (h *RESTAPIHandler) BrowseKnowledge(c *gin.Context) {
 		zap.Int("limit", limit),
 		zap.Int("results", len(allEntries)))
 
-	c.JSON(http.StatusOK, BrowseKnowledgeResponse{
+	envelope.List(c, BrowseKnowledgeResponse{
 		Entries: allEntries,
 		Count:   len(allEntries),
-		Limit:   limit,
-	})
+	}, envelope.NewPagination(len(allEntries), limit, 0))
 }
 
 // QueryKnowledge searches the knowledge base with semantic search
 // POST /api/v1/knowledge/query
 func (h *RESTAPIHandler) QueryKnowledge(c *gin.Context) {
 	var req QueryKnowledgeRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
@@ -716,13 +901,13 @@ func (h *RESTAPIHandler) QueryKnowledge(c *gin.Context) {
 	}
 
 	// Query knowledge storage
-	results, err := h.knowledgeStorage.Query(req.Collection, req.Query, limit)
+	results, err := storage.QueryKnowledgeContext(c.Request.Context(), h.knowledgeStorage, req.Collection, req.Query, limit)
 	if err != nil {
 		h.logger.Error("Failed to query knowledge",
 			zap.String("collection", req.Collection),
 			zap.String("query", req.Query),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query knowledge base"})
+		errcode.Respond(c, err, "Failed to query knowledge base")
 		return
 	}
 
@@ -747,7 +932,7 @@ func (h *RESTAPIHandler) QueryKnowledge(c *gin.Context) {
 		zap.Int("limit", limit),
 		zap.Int("results", len(entries)))
 
-	c.JSON(http.StatusOK, QueryKnowledgeResponse{
+	envelope.OK(c, QueryKnowledgeResponse{
 		Entries: entries,
 	})
 }
@@ -758,26 +943,26 @@ func (h *RESTAPIHandler) QueryKnowledge(c *gin.Context) {
 // POST /api/v1/code-index/add-folder
 func (h *RESTAPIHandler) AddFolder(c *gin.Context) {
 	var req AddFolderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
 	// Convert to absolute path
 	absPath, err := filepath.Abs(req.FolderPath)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path: " + err.Error()})
+		errcode.RespondCode(c, errcode.Validation, "Invalid folder path: "+err.Error())
 		return
 	}
 
 	// Check if folder already exists
 	existing, err := h.codeIndexStorage.GetFolderByPath(absPath)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing folder: " + err.Error()})
+		errcode.Respond(c, err, "Failed to check existing folder: "+err.Error())
 		return
 	}
 	if existing != nil {
-		c.JSON(http.StatusOK, AddFolderResponse{
+		envelope.OK(c, AddFolderResponse{
 			Success: true,
 			Message: "Folder already indexed. File watcher is monitoring changes.",
 			Folder:  existing,
@@ -788,7 +973,7 @@ func (h *RESTAPIHandler) AddFolder(c *gin.Context) {
 	// Add folder to storage
 	folder, err := h.codeIndexStorage.AddFolder(absPath, req.Description)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add folder: " + err.Error()})
+		errcode.Respond(c, err, "Failed to add folder: "+err.Error())
 		return
 	}
 
@@ -805,14 +990,15 @@ func (h *RESTAPIHandler) AddFolder(c *gin.Context) {
 		zap.String("folderID", folder.ID),
 		zap.String("path", absPath))
 
-	c.JSON(http.StatusCreated, AddFolderResponse{
+	envelope.JSON(c, http.StatusCreated, AddFolderResponse{
 		Success: true,
 		Message: "Folder added successfully. File watcher is now monitoring changes. Use /api/code-index/scan to index existing files.",
 		Folder:  folder,
 	})
 }
 
-// RemoveFolder removes a folder from the code index
+// RemoveFolder removes a folder from the code index. With an undo window the
+// removal is staged and the response is 202 with the undo token.
 // DELETE /api/v1/code-index/remove-folder/:configId
 func (h *RESTAPIHandler) RemoveFolder(c *gin.Context) {
 	configID := c.Param("configId")
@@ -820,22 +1006,53 @@ func (h *RESTAPIHandler) RemoveFolder(c *gin.Context) {
 	// Get folder
 	folder, err := h.codeIndexStorage.GetFolder(configID)
 	if err != nil || folder == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found: " + configID})
+		errcode.RespondCode(c, errcode.NotFound, "Folder not found: "+configID)
 		return
 	}
 
 	// Get all files to delete their vectors
 	files, err := h.codeIndexStorage.ListFiles(folder.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list files: " + err.Error()})
+		errcode.Respond(c, err, "Failed to list files: "+err.Error())
 		return
 	}
 
+	if h.undoManager.Enabled() {
+		op := h.undoManager.Stage("code_index_remove_folder", fmt.Sprintf("Remove folder %s and its %d indexed files", folder.Path, len(files)), func(ctx context.Context) (interface{}, error) {
+			if err := h.removeFolder(folder, len(files)); err != nil {
+				return nil, err
+			}
+			return RemoveFolderResponse{Success: true, Message: "Folder removed successfully", FilesRemoved: len(files)}, nil
+		})
+		envelope.JSON(c, http.StatusAccepted, RemoveFolderResponse{
+			Success:      true,
+			Message:      fmt.Sprintf("Folder removal staged until %s: undo it with POST /api/v1/staged-operations/%s/undo", op.ExpiresAt.Format(time.RFC3339), op.UndoToken),
+			FilesRemoved: len(files),
+			Operation:    &op,
+		})
+		return
+	}
+
+	if err := h.removeFolder(folder, len(files)); err != nil {
+		errcode.Respond(c, err, "Failed to remove folder: "+err.Error())
+		return
+	}
+
+	envelope.OK(c, RemoveFolderResponse{
+		Success:      true,
+		Message:      "Folder removed successfully",
+		FilesRemoved: len(files),
+	})
+}
+
+// removeFolder deletes a folder's vectors, stops watching it and removes it
+// with its files and chunks
+func (h *RESTAPIHandler) removeFolder(folder *storage.IndexedFolder, fileCount int) error {
 	// Delete vectors from Qdrant - lookup collection from path mapping
-	if len(files) > 0 {
+	if fileCount > 0 {
 		mapping, _ := h.codeIndexStorage.GetPathMapping(folder.Path)
 		if mapping != nil {
-			err = h.qdrantClient.DeleteCodeIndexByFilter(mapping.QdrantCollection, map[string]interface{}{
+			err := h.qdrantClient.DeleteCodeIndexByFilter(mapping.QdrantCollection, map[string]interface{}{
 				"must": []map[string]interface{}{
 					{"key": "folderId", "match": map[string]interface{}{"value": folder.ID}},
 				},
@@ -857,48 +1074,70 @@ func (h *RESTAPIHandler) RemoveFolder(c *gin.Context) {
 
 	// Remove folder from MongoDB (cascades to files and chunks)
 	if err := h.codeIndexStorage.RemoveFolder(folder.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove folder: " + err.Error()})
-		return
+		return err
 	}
 
 	h.logger.Info("Removed folder from code index",
 		zap.String("folderID", folder.ID),
 		zap.String("path", folder.Path),
-		zap.Int("filesRemoved", len(files)))
-
-	c.JSON(http.StatusOK, RemoveFolderResponse{
-		Success:      true,
-		Message:      "Folder removed successfully",
-		FilesRemoved: len(files),
-	})
+		zap.Int("filesRemoved", fileCount))
+	return nil
 }
 
 // ScanFolder triggers a scan of a folder
 // POST /api/v1/code-index/scan
 func (h *RESTAPIHandler) ScanFolder(c *gin.Context) {
-	var req AddFolderRequest // Reuse same structure (only folderPath needed)
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	var req ScanFolderRequest
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
 	// Convert to absolute path
 	absPath, err := filepath.Abs(req.FolderPath)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder path: " + err.Error()})
+		errcode.RespondCode(c, errcode.Validation, "Invalid folder path: "+err.Error())
+		return
+	}
+
+	// Dry run: estimate the work without requiring the folder to be indexed
+	if req.DryRun {
+		estimate, err := h.estimateScan(absPath)
+		if err != nil {
+			errcode.Respond(c, err, "Failed to scan directory: "+err.Error())
+			return
+		}
+		envelope.OK(c, ScanDryRunResponse{Success: true, DryRun: true, Estimate: estimate})
 		return
 	}
 
 	// Get folder
 	folder, err := h.codeIndexStorage.GetFolderByPath(absPath)
 	if err != nil || folder == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found. Use /api/code-index/add-folder first: " + absPath})
+		errcode.RespondCode(c, errcode.NotFound, "Folder not found. Use /api/code-index/add-folder first: "+absPath)
 		return
 	}
 
+	// Save new concurrency bounds before scanning with them
+	if req.ScanConcurrency != nil {
+		if err := req.ScanConcurrency.Validate(); err != nil {
+			errcode.RespondCode(c, errcode.Validation, err.Error())
+			return
+		}
+		bounds := req.ScanConcurrency
+		if *bounds == (storage.ScanConcurrency{}) {
+			bounds = nil
+		}
+		if err := h.codeIndexStorage.SetFolderScanConcurrency(folder.ID, bounds); err != nil {
+			errcode.Respond(c, err, "Failed to save scan concurrency: "+err.Error())
+			return
+		}
+		folder.ScanConcurrency = bounds
+	}
+
 	// Update folder status to scanning
 	if err := h.codeIndexStorage.UpdateFolderStatus(folder.ID, "scanning", ""); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update folder status: " + err.Error()})
+		errcode.Respond(c, err, "Failed to update folder status: "+err.Error())
 		return
 	}
 
@@ -906,26 +1145,55 @@ func (h *RESTAPIHandler) ScanFolder(c *gin.Context) {
 	scannedFiles, err := h.fileScanner.ScanDirectory(absPath)
 	if err != nil {
 		h.codeIndexStorage.UpdateFolderStatus(folder.ID, "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan directory: " + err.Error()})
+		errcode.Respond(c, err, "Failed to scan directory: "+err.Error())
 		return
 	}
 
+	var mu sync.Mutex
 	filesIndexed := 0
 	filesUpdated := 0
 	filesSkipped := 0
+	var embeddedTokens int64
+
+	// Indexing is bulk work: it yields to interactive searches, and still
+	// finishes if the client goes away
+	bulkCtx := priority.WithLevel(context.WithoutCancel(c.Request.Context()), priority.Bulk)
+
+	// Points go to the collection mapped to the folder, embedded for its vector layout
+	collectionName := storage.CodeIndexCollection // fallback to default
+	if mapping, _ := h.codeIndexStorage.GetPathMapping(folder.Path); mapping != nil {
+		collectionName = mapping.QdrantCollection
+	}
+	namedVectors, err := h.qdrantClient.UsesNamedVectors(bulkCtx, collectionName)
+	if err != nil {
+		h.codeIndexStorage.UpdateFolderStatus(folder.ID, "error", err.Error())
+		errcode.Respond(c, err, "Failed to read collection: "+err.Error())
+		return
+	}
 
-	// Process each file
-	for _, scannedFile := range scannedFiles {
+	// Index files concurrently, as many at once as system load allows
+	limiter := scanner.NewFolderLimiter(folder)
+	embedder := limiter.Embedder(h.embeddingClient)
+	limiter.Run(bulkCtx, len(scannedFiles), func(i int) {
+		scannedFile := scannedFiles[i]
 		scannedFile.FolderID = folder.ID
 
 		// Check if file already exists
 		existingFile, _ := h.codeIndexStorage.GetFileByPath(scannedFile.Path)
 
+		mu.Lock()
 		if existingFile != nil {
 			// Check if file has changed
 			if existingFile.SHA256 == scannedFile.SHA256 {
 				filesSkipped++
-				continue
+				mu.Unlock()
+				// Files indexed before symbols were extracted get them now
+				if existingFile.SymbolsAt.IsZero() {
+					if err := scanner.IndexFileSymbols(h.codeIndexStorage, existingFile); err != nil {
+						h.logger.Warn("Failed to index symbols", zap.String("file", existingFile.Path), zap.Error(err))
+					}
+				}
+				return
 			}
 			filesUpdated++
 			scannedFile.ID = existingFile.ID
@@ -933,19 +1201,27 @@ func (h *RESTAPIHandler) ScanFolder(c *gin.Context) {
 			filesIndexed++
 			scannedFile.ID = uuid.New().String()
 		}
+		mu.Unlock()
 
 		// Create chunks
 		chunks, err := h.fileScanner.CreateFileChunks(scannedFile.ID, scannedFile.Path)
 		if err != nil {
 			h.logger.Warn("Failed to create chunks", zap.String("file", scannedFile.Path), zap.Error(err))
-			continue
+			return
 		}
 
 		// Generate embeddings for chunks
 		var qdrantPoints []storage.CodeIndexPoint
 		for _, chunk := range chunks {
-			// Generate embedding
-			embedding, err := h.embeddingClient.CreateEmbedding(chunk.Content)
+			// Optionally summarize, then embed code and summary for the collection's vector layout
+			summary, _, err := summarizer.Enrich(bulkCtx, h.summarizer, scannedFile.Language, chunk.Content)
+			if err != nil {
+				h.logger.Warn("Failed to summarize chunk", zap.String("file", scannedFile.Path), zap.Error(err))
+			}
+			chunk.Summary = summary
+
+			// Generate embeddings
+			embedding, summaryEmbedding, tokens, err := summarizer.EmbedChunk(bulkCtx, embedder, summary, chunk.Content, namedVectors)
 			if err != nil {
 				h.logger.Warn("Failed to create embedding",
 					zap.String("file", scannedFile.Path),
@@ -953,6 +1229,9 @@ func (h *RESTAPIHandler) ScanFolder(c *gin.Context) {
 					zap.Error(err))
 				continue
 			}
+			mu.Lock()
+			embeddedTokens += tokens
+			mu.Unlock()
 
 			// Create Qdrant point with deterministic UUID (not concatenated string)
 			// Generate a deterministic UUID by hashing fileID + chunkNum
@@ -960,8 +1239,9 @@ func (h *RESTAPIHandler) ScanFolder(c *gin.Context) {
 			chunk.VectorID = pointID
 
 			point := storage.CodeIndexPoint{
-				ID:     pointID,
-				Vector: embedding,
+				ID:            pointID,
+				Vector:        embedding,
+				SummaryVector: summaryEmbedding,
 				Payload: map[string]interface{}{
 					"fileId":       scannedFile.ID,
 					"folderId":     folder.ID,
@@ -975,6 +1255,10 @@ func (h *RESTAPIHandler) ScanFolder(c *gin.Context) {
 					"content":      chunk.Content,
 				},
 			}
+			if chunk.Summary != "" {
+				point.Payload["summary"] = chunk.Summary
+			}
+			storage.TagCommentLanguage(point.Payload, chunk.Content)
 			qdrantPoints = append(qdrantPoints, point)
 
 			// Save chunk to MongoDB
@@ -983,23 +1267,20 @@ func (h *RESTAPIHandler) ScanFolder(c *gin.Context) {
 			}
 		}
 
-		// Upload vectors to Qdrant - lookup collection from path mapping
+		// Upload vectors to Qdrant
 		if len(qdrantPoints) > 0 {
-			mapping, _ := h.codeIndexStorage.GetPathMapping(folder.Path)
-			collectionName := storage.CodeIndexCollection // fallback to default
-			if mapping != nil {
-				collectionName = mapping.QdrantCollection
-			}
-			if err := h.qdrantClient.UpsertCodeIndexPoints(collectionName, qdrantPoints); err != nil {
+			if err := h.qdrantClient.UpsertCodeIndexPointsContext(bulkCtx, collectionName, qdrantPoints); err != nil {
 				h.logger.Warn("Failed to upsert vectors", zap.String("file", scannedFile.Path), zap.Error(err))
 			}
 		}
 
-		// Save file metadata to MongoDB
+		// Save file metadata to MongoDB, then the symbols it declares
 		if err := h.codeIndexStorage.UpsertFile(scannedFile); err != nil {
 			h.logger.Warn("Failed to save file", zap.Error(err))
+		} else if err := scanner.IndexFileSymbols(h.codeIndexStorage, scannedFile); err != nil {
+			h.logger.Warn("Failed to index symbols", zap.String("file", scannedFile.Path), zap.Error(err))
 		}
-	}
+	})
 
 	// Update folder status and scan time
 	if err := h.codeIndexStorage.UpdateFolderStatus(folder.ID, "active", ""); err != nil {
@@ -1014,23 +1295,65 @@ func (h *RESTAPIHandler) ScanFolder(c *gin.Context) {
 		zap.String("folderID", folder.ID),
 		zap.Int("filesIndexed", filesIndexed),
 		zap.Int("filesUpdated", filesUpdated),
-		zap.Int("filesSkipped", filesSkipped))
+		zap.Int("filesSkipped", filesSkipped),
+		zap.Int64("estimatedTokens", embeddedTokens),
+		zap.Int("peakConcurrency", limiter.Stats().Peak))
+
+	envelope.OK(c, ScanResponse{
+		Success:       true,
+		FilesIndexed:  filesIndexed,
+		FilesUpdated:  filesUpdated,
+		FilesSkipped:  filesSkipped,
+		TotalFiles:    len(scannedFiles),
+		EstimatedCost: embeddings.PricingFor(h.embeddingClient).Estimate(embeddedTokens),
+		Concurrency:   limiter.Stats(),
+	})
+}
 
-	c.JSON(http.StatusOK, ScanResponse{
-		Success:      true,
-		FilesIndexed: filesIndexed,
-		FilesUpdated: filesUpdated,
-		FilesSkipped: filesSkipped,
-		TotalFiles:   len(scannedFiles),
+// EstimateScan reports what scanning a folder would index and what embedding it would cost
+// GET /api/v1/code-index/estimate?folderPath=...
+func (h *RESTAPIHandler) EstimateScan(c *gin.Context) {
+	folderPath := c.Query("folderPath")
+	if folderPath == "" {
+		errcode.RespondCode(c, errcode.Validation, "folderPath query parameter is required")
+		return
+	}
+
+	absPath, err := filepath.Abs(folderPath)
+	if err != nil {
+		errcode.RespondCode(c, errcode.Validation, "Invalid folder path: "+err.Error())
+		return
+	}
+
+	estimate, err := h.estimateScan(absPath)
+	if err != nil {
+		errcode.Respond(c, err, "Failed to scan directory: "+err.Error())
+		return
+	}
+	envelope.OK(c, estimate)
+}
+
+// estimateScan walks a folder without embedding anything, skipping files whose
+// indexed content is unchanged, and prices the result with every provider
+func (h *RESTAPIHandler) estimateScan(absPath string) (*scanner.ScanEstimate, error) {
+	estimate, err := h.fileScanner.EstimateScan(absPath, func(file *storage.IndexedFile) bool {
+		existing, _ := h.codeIndexStorage.GetFileByPath(file.Path)
+		return existing != nil && existing.SHA256 == file.SHA256
 	})
+	if err != nil {
+		return nil, err
+	}
+	activeCost := embeddings.PricingFor(h.embeddingClient).Estimate(estimate.EstimatedTokens)
+	estimate.ActiveCost = &activeCost
+	return estimate, nil
 }
 
 // SearchCode searches the code index
 // POST /api/v1/code-index/search
 func (h *RESTAPIHandler) SearchCode(c *gin.Context) {
 	var req SearchRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
@@ -1048,14 +1371,14 @@ func (h *RESTAPIHandler) SearchCode(c *gin.Context) {
 		retrieveMode = "chunk"
 	}
 	if retrieveMode != "chunk" && retrieveMode != "full" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "retrieve must be 'chunk' or 'full'"})
+		errcode.RespondCode(c, errcode.Validation, "retrieve must be 'chunk' or 'full'")
 		return
 	}
 
 	// Generate embedding for query
-	queryEmbedding, err := h.embeddingClient.CreateEmbedding(req.Query)
+	queryEmbedding, err := embeddings.CreateEmbeddingContext(c.Request.Context(), h.embeddingClient, req.Query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create query embedding: " + err.Error()})
+		errcode.Respond(c, err, "Failed to create query embedding: "+err.Error())
 		return
 	}
 
@@ -1069,9 +1392,9 @@ func (h *RESTAPIHandler) SearchCode(c *gin.Context) {
 	}
 
 	// Search in Qdrant
-	searchResp, err := h.qdrantClient.SearchCodeIndex(collectionName, queryEmbedding, limit)
+	searchResp, err := h.qdrantClient.SearchCodeIndexModeContext(c.Request.Context(), collectionName, storage.VectorModeFused, queryEmbedding, limit, nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search: " + err.Error()})
+		errcode.Respond(c, err, "Failed to search: "+err.Error())
 		return
 	}
 
@@ -1109,6 +1432,9 @@ func (h *RESTAPIHandler) SearchCode(c *gin.Context) {
 		if endLine, ok := hit.Payload["endLine"].(float64); ok {
 			result.EndLine = int(endLine)
 		}
+		if summary, ok := hit.Payload["summary"].(string); ok {
+			result.Summary = summary
+		}
 
 		// Handle content based on retrieve mode
 		if retrieveMode == "chunk" {
@@ -1143,12 +1469,24 @@ func (h *RESTAPIHandler) SearchCode(c *gin.Context) {
 		results = append(results, result)
 	}
 
+	// Link hits to the agent tasks that touched the same files
+	agentTasks := h.taskStorage.ListAllAgentTasks()
+	for i := range results {
+		results[i].RecentTasks = storage.RecentTasksForFile(agentTasks, results[i].FilePath, results[i].RelativePath, storage.RecentTasksPerFile)
+	}
+
+	hits := make([]storage.SearchResult, len(results))
+	for i, result := range results {
+		hits[i] = storage.SearchResult{FolderPath: result.FolderPath, FilePath: result.FilePath, RelativePath: result.RelativePath}
+	}
+	h.codeIndexStorage.RecordSearchHits(hits)
+
 	h.logger.Info("Code search completed",
 		zap.String("query", req.Query),
 		zap.String("retrieveMode", retrieveMode),
 		zap.Int("results", len(results)))
 
-	c.JSON(http.StatusOK, SearchResponse{
+	envelope.OK(c, SearchResponse{
 		Success:      true,
 		Query:        req.Query,
 		RetrieveMode: retrieveMode,
@@ -1163,14 +1501,14 @@ func (h *RESTAPIHandler) GetIndexStatus(c *gin.Context) {
 	// Get index status
 	status, err := h.codeIndexStorage.GetIndexStatus()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get index status: " + err.Error()})
+		errcode.Respond(c, err, "Failed to get index status: "+err.Error())
 		return
 	}
 
 	// Get folder details
 	folders, err := h.codeIndexStorage.ListFolders()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list folders: " + err.Error()})
+		errcode.Respond(c, err, "Failed to list folders: "+err.Error())
 		return
 	}
 
@@ -1200,7 +1538,7 @@ func (h *RESTAPIHandler) GetIndexStatus(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, IndexStatusResponse{
+	envelope.OK(c, IndexStatusResponse{
 		TotalFolders:  status.TotalFolders,
 		TotalFiles:    status.TotalFiles,
 		TotalSize:     totalSize,
@@ -1218,6 +1556,8 @@ func (h *RESTAPIHandler) RegisterRESTRoutes(r *gin.Engine) {
 		tasks.POST("", h.CreateHumanTask)
 		tasks.GET("/:id", h.GetHumanTask)
 		tasks.PUT("/:id/status", h.UpdateTaskStatus)
+		tasks.POST("/:id/clone", h.CloneHumanTask)
+		tasks.GET("/:id/graph", h.GetTaskGraph)
 	}
 
 	// Agent Tasks
@@ -1225,10 +1565,30 @@ func (h *RESTAPIHandler) RegisterRESTRoutes(r *gin.Engine) {
 	{
 		agentTasks.GET("", h.ListAgentTasks)
 		agentTasks.POST("", h.CreateAgentTask)
+		agentTasks.POST("/bulk", h.BulkUpdateAgentTasks)
 		agentTasks.GET("/:id", h.GetAgentTask)
+		agentTasks.GET("/:id/activity", h.GetAgentTaskActivity)
+		agentTasks.POST("/:id/artifacts", h.UploadTaskArtifact)
+		agentTasks.GET("/:id/artifacts", h.ListTaskArtifacts)
+		agentTasks.GET("/:id/artifacts/:artifactId", h.DownloadTaskArtifact)
 		agentTasks.PUT("/:agentTaskId/todos/:todoId/status", h.UpdateTodoStatus)
+		agentTasks.PUT("/:agentTaskId/todos/:todoId/checklist/:itemId/status", h.UpdateChecklistItemStatus)
 	}
 
+	// Destructive operations staged for the undo window
+	stagedOperations := r.Group("/api/v1/staged-operations")
+	{
+		stagedOperations.GET("", h.ListStagedOperations)
+		stagedOperations.POST("/:token/undo", h.UndoStagedOperation)
+		stagedOperations.POST("/:token/confirm", h.ConfirmStagedOperation)
+	}
+
+	// Task board, grouped by status for kanban rendering
+	r.GET("/api/board", h.GetBoard)
+	r.GET("/api/board/delta", h.GetBoardDelta)
+	r.GET("/api/board/graph", h.GetBoardGraph)
+	r.GET("/api/board/plan", h.GetCapacityPlan)
+
 	// Knowledge routes are registered separately in http_server.go
 	// to avoid duplication - see http_server.go line 344
 
@@ -1238,7 +1598,10 @@ func (h *RESTAPIHandler) RegisterRESTRoutes(r *gin.Engine) {
 		codeIndex.POST("/add-folder", h.AddFolder)
 		codeIndex.DELETE("/remove-folder/:configId", h.RemoveFolder)
 		codeIndex.POST("/scan", h.ScanFolder)
+		codeIndex.GET("/estimate", h.EstimateScan)
 		codeIndex.POST("/search", h.SearchCode)
 		codeIndex.GET("/status", h.GetIndexStatus)
+		codeIndex.GET("/export", h.ExportCodeIndex)
+		codeIndex.GET("/usage", h.GetCodeSearchUsage)
 	}
 }
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"hyper/internal/envelope"
 	"hyper/internal/mcp/scanner"
 	"hyper/internal/mcp/storage"
 
@@ -60,6 +62,16 @@ func (m *MockTaskStorage) ListAllAgentTasks() []*storage.AgentTask {
 	return args.Get(0).([]*storage.AgentTask)
 }
 
+func (m *MockTaskStorage) ListAgentTasksByStatus(status storage.TaskStatus) ([]*storage.AgentTask, error) {
+	args := m.Called(status)
+	return args.Get(0).([]*storage.AgentTask), args.Error(1)
+}
+
+func (m *MockTaskStorage) ListAgentTasksByHumanTask(humanTaskID string) ([]*storage.AgentTask, error) {
+	args := m.Called(humanTaskID)
+	return args.Get(0).([]*storage.AgentTask), args.Error(1)
+}
+
 func (m *MockTaskStorage) GetAgentTask(id string) (*storage.AgentTask, error) {
 	args := m.Called(id)
 	if args.Get(0) != nil {
@@ -73,6 +85,24 @@ func (m *MockTaskStorage) UpdateTodoStatus(agentTaskID, todoID string, status st
 	return args.Error(0)
 }
 
+func (m *MockTaskStorage) UpdateChecklistItemStatus(agentTaskID, todoID, itemID string, status storage.TodoStatus, notes string) error {
+	args := m.Called(agentTaskID, todoID, itemID, status, notes)
+	return args.Error(0)
+}
+
+func (m *MockTaskStorage) UpdateTodoTime(agentTaskID, todoID string, estimatedMinutes, actualMinutes *int) error {
+	args := m.Called(agentTaskID, todoID, estimatedMinutes, actualMinutes)
+	return args.Error(0)
+}
+
+func (m *MockTaskStorage) GetAgentTaskActivity(agentTaskID string) ([]storage.TaskActivity, error) {
+	args := m.Called(agentTaskID)
+	if args.Get(0) != nil {
+		return args.Get(0).([]storage.TaskActivity), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockTaskStorage) ClearAllTasks() (*storage.ClearResult, error) {
 	args := m.Called()
 	if args.Get(0) != nil {
@@ -81,6 +111,24 @@ func (m *MockTaskStorage) ClearAllTasks() (*storage.ClearResult, error) {
 	return nil, args.Error(1)
 }
 
+func (m *MockTaskStorage) AppendTaskChangeLog(agentTaskID string, entry storage.FileChangeEntry) error {
+	args := m.Called(agentTaskID, entry)
+	return args.Error(0)
+}
+
+func (m *MockTaskStorage) CloneHumanTask(sourceTaskID, project, prompt string) (*storage.HumanTask, []*storage.AgentTask, error) {
+	args := m.Called(sourceTaskID, project, prompt)
+	if args.Get(0) != nil {
+		return args.Get(0).(*storage.HumanTask), args.Get(1).([]*storage.AgentTask), args.Error(2)
+	}
+	return nil, nil, args.Error(2)
+}
+
+func (m *MockTaskStorage) SetTaskDueDate(taskID string, dueAt *time.Time) error {
+	args := m.Called(taskID, dueAt)
+	return args.Error(0)
+}
+
 func (m *MockTaskStorage) AddTaskPromptNotes(agentTaskID, notes string) error {
 	args := m.Called(agentTaskID, notes)
 	return args.Error(0)
@@ -234,9 +282,11 @@ func TestCreateHumanTask(t *testing.T) {
 			assert.Equal(t, tt.expectedStatus, w.Code)
 
 			if tt.expectedError != "" {
-				var response map[string]string
+				var response envelope.Response
 				json.Unmarshal(w.Body.Bytes(), &response)
-				assert.Contains(t, response["error"], tt.expectedError)
+				if assert.NotNil(t, response.Error) {
+					assert.Contains(t, response.Error.Message, tt.expectedError)
+				}
 			}
 
 			mockTaskStorage.AssertExpectations(t)
@@ -264,10 +314,16 @@ func TestListHumanTasks(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response ListHumanTasksResponse
+	var response struct {
+		Data ListHumanTasksResponse `json:"data"`
+		Meta envelope.Meta          `json:"meta"`
+	}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(t, 2, response.Count)
-	assert.Len(t, response.Tasks, 2)
+	assert.Equal(t, 2, response.Data.Count)
+	assert.Len(t, response.Data.Tasks, 2)
+	if assert.NotNil(t, response.Meta.Pagination) {
+		assert.Equal(t, 2, response.Meta.Pagination.Total)
+	}
 
 	mockTaskStorage.AssertExpectations(t)
 }
@@ -295,9 +351,11 @@ func TestUpdateTaskStatus(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response UpdateTaskStatusResponse
+	var response struct {
+		Data UpdateTaskStatusResponse `json:"data"`
+	}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.True(t, response.Success)
+	assert.True(t, response.Data.Success)
 
 	mockTaskStorage.AssertExpectations(t)
 }
@@ -322,10 +380,12 @@ func TestListCollections(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response ListCollectionsResponse
+	var response struct {
+		Data ListCollectionsResponse `json:"data"`
+	}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Len(t, response.Collections, 2)
-	assert.Equal(t, "technical-knowledge", response.Collections[0].Name)
+	assert.Len(t, response.Data.Collections, 2)
+	assert.Equal(t, "technical-knowledge", response.Data.Collections[0].Name)
 
 	mockKnowledgeStorage.AssertExpectations(t)
 }
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/undo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetUndoManager stages folder removals for the manager's undo window and
+// enables the staged operation routes
+func (h *RESTAPIHandler) SetUndoManager(manager *undo.Manager) {
+	h.undoManager = manager
+}
+
+// ListStagedOperations lists destructive operations waiting to be committed,
+// oldest first
+// GET /api/v1/staged-operations
+func (h *RESTAPIHandler) ListStagedOperations(c *gin.Context) {
+	if !h.undoManager.Enabled() {
+		envelope.List(c, []undo.StagedOperation{}, envelope.Complete(0))
+		return
+	}
+	ops := h.undoManager.List()
+	envelope.List(c, ops, envelope.Complete(len(ops)))
+}
+
+// UndoStagedOperation discards a staged operation before it is committed
+// POST /api/v1/staged-operations/:token/undo
+func (h *RESTAPIHandler) UndoStagedOperation(c *gin.Context) {
+	if !h.undoManager.Enabled() {
+		errcode.RespondCode(c, errcode.DependencyUnavailable, "Undo is disabled: destructive operations run immediately when "+undo.WindowEnv+"=0")
+		return
+	}
+	op, err := h.undoManager.Undo(c.Param("token"))
+	if err != nil {
+		errcode.Respond(c, err, err.Error())
+		return
+	}
+	envelope.OK(c, op)
+}
+
+// ConfirmStagedOperation commits a staged operation now instead of waiting
+// for its undo window to expire
+// POST /api/v1/staged-operations/:token/confirm
+func (h *RESTAPIHandler) ConfirmStagedOperation(c *gin.Context) {
+	if !h.undoManager.Enabled() {
+		errcode.RespondCode(c, errcode.DependencyUnavailable, "Undo is disabled: destructive operations run immediately when "+undo.WindowEnv+"=0")
+		return
+	}
+	result, err := h.undoManager.Confirm(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		errcode.Respond(c, err, "Failed to commit staged operation: "+err.Error())
+		return
+	}
+	envelope.OK(c, result)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hyper/internal/undo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStagedOperationRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := undo.NewManager(time.Minute, nil)
+	r := gin.New()
+	h := NewRESTAPIHandler(nil, nil, nil, nil, nil, nil, zap.NewNop())
+	h.SetUndoManager(manager)
+	h.RegisterRESTRoutes(r)
+
+	post := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		return w
+	}
+
+	committed := false
+	commit := func(ctx context.Context) (interface{}, error) {
+		committed = true
+		return "removed", nil
+	}
+
+	undone := manager.Stage("code_index_remove_folder", "Remove folder /repo", commit)
+	confirmed := manager.Stage("code_index_remove_folder", "Remove folder /other", commit)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/staged-operations", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []undo.StagedOperation `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Data, 2)
+
+	require.Equal(t, http.StatusOK, post("/api/v1/staged-operations/"+undone.UndoToken+"/undo").Code)
+	assert.False(t, committed)
+	assert.Equal(t, http.StatusNotFound, post("/api/v1/staged-operations/"+undone.UndoToken+"/undo").Code)
+
+	require.Equal(t, http.StatusOK, post("/api/v1/staged-operations/"+confirmed.UndoToken+"/confirm").Code)
+	assert.True(t, committed)
+	assert.Empty(t, manager.List())
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// multipartOverheadBytes allows for the form fields and part headers sent
+// alongside an artifact upload
+const multipartOverheadBytes = 1 << 20
+
+// TaskArtifactDTO is an artifact with the path serving its content
+type TaskArtifactDTO struct {
+	*storage.TaskArtifact
+	DownloadPath string `json:"downloadPath"`
+}
+
+type UploadTaskArtifactResponse struct {
+	Artifact TaskArtifactDTO `json:"artifact"`
+}
+
+type ListTaskArtifactsResponse struct {
+	AgentTaskID string            `json:"agentTaskId"`
+	Artifacts   []TaskArtifactDTO `json:"artifacts"`
+	Count       int               `json:"count"`
+}
+
+// SetArtifactStorage enables the agent task artifact routes
+func (h *RESTAPIHandler) SetArtifactStorage(artifacts *storage.TaskArtifactStorage) {
+	h.artifacts = artifacts
+}
+
+func convertArtifactToDTO(artifact *storage.TaskArtifact) TaskArtifactDTO {
+	return TaskArtifactDTO{TaskArtifact: artifact, DownloadPath: artifact.DownloadPath()}
+}
+
+// UploadTaskArtifact attaches an uploaded file to an agent task. The form
+// carries the file in "file" and optionally "name", "contentType" and
+// "description"; the name defaults to the uploaded file name.
+// POST /api/v1/agent-tasks/:id/artifacts
+func (h *RESTAPIHandler) UploadTaskArtifact(c *gin.Context) {
+	if h.artifacts == nil {
+		errcode.RespondCode(c, errcode.DependencyUnavailable, "Artifact storage is not configured")
+		return
+	}
+	taskID := c.Param("id")
+
+	if _, err := h.taskStorage.GetAgentTask(taskID); err != nil {
+		errcode.RespondCode(c, errcode.NotFound, "Agent task not found")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, storage.ArtifactMaxBytes()+multipartOverheadBytes)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			errcode.RespondCode(c, errcode.Validation, fmt.Sprintf("Artifact exceeds the %d byte limit (%s)", storage.ArtifactMaxBytes(), storage.ArtifactMaxBytesEnv))
+			return
+		}
+		errcode.RespondCode(c, errcode.Validation, "Invalid upload: a multipart form with a \"file\" field is required")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		errcode.RespondCode(c, errcode.Internal, "Failed to read upload: "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	artifact := &storage.TaskArtifact{
+		AgentTaskID: taskID,
+		Name:        c.DefaultPostForm("name", filepath.Base(fileHeader.Filename)),
+		ContentType: c.PostForm("contentType"),
+		Description: c.PostForm("description"),
+	}
+	if artifact.ContentType == "" && fileHeader.Header.Get("Content-Type") != "application/octet-stream" {
+		artifact.ContentType = fileHeader.Header.Get("Content-Type")
+	}
+	if err := h.artifacts.Save(c.Request.Context(), artifact, file); err != nil {
+		errcode.Respond(c, err, "Failed to store artifact: "+err.Error())
+		return
+	}
+
+	envelope.JSON(c, http.StatusCreated, UploadTaskArtifactResponse{
+		Artifact: convertArtifactToDTO(artifact),
+	})
+}
+
+// ListTaskArtifacts returns the artifacts of an agent task, oldest first
+// GET /api/v1/agent-tasks/:id/artifacts
+func (h *RESTAPIHandler) ListTaskArtifacts(c *gin.Context) {
+	if h.artifacts == nil {
+		errcode.RespondCode(c, errcode.DependencyUnavailable, "Artifact storage is not configured")
+		return
+	}
+	taskID := c.Param("id")
+
+	artifacts, err := h.artifacts.List(c.Request.Context(), taskID)
+	if err != nil {
+		errcode.Respond(c, err, "Failed to list artifacts: "+err.Error())
+		return
+	}
+
+	dtos := make([]TaskArtifactDTO, len(artifacts))
+	for i, artifact := range artifacts {
+		dtos[i] = convertArtifactToDTO(artifact)
+	}
+	envelope.List(c, ListTaskArtifactsResponse{
+		AgentTaskID: taskID,
+		Artifacts:   dtos,
+		Count:       len(dtos),
+	}, envelope.Complete(len(dtos)))
+}
+
+// DownloadTaskArtifact streams the content of an artifact. Pass inline=true
+// to display it in the browser instead of saving it.
+// GET /api/v1/agent-tasks/:id/artifacts/:artifactId
+func (h *RESTAPIHandler) DownloadTaskArtifact(c *gin.Context) {
+	if h.artifacts == nil {
+		errcode.RespondCode(c, errcode.DependencyUnavailable, "Artifact storage is not configured")
+		return
+	}
+
+	artifact, content, err := h.artifacts.Open(c.Request.Context(), c.Param("id"), c.Param("artifactId"))
+	if err != nil {
+		errcode.Respond(c, err, "Failed to get artifact: "+err.Error())
+		return
+	}
+	defer content.Close()
+
+	disposition := "attachment"
+	if c.Query("inline") == "true" {
+		disposition = "inline"
+	}
+	c.Header("Content-Type", artifact.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(artifact.Size, 10))
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, artifact.Name))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("ETag", strconv.Quote(artifact.SHA256))
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		h.logger.Warn("Failed to stream artifact",
+			zap.String("artifactId", artifact.ID),
+			zap.Error(err))
+	}
+}
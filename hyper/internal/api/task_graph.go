@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+
+	"hyper/internal/errcode"
+	"hyper/internal/taskgraph"
+
+	"github.com/gin-gonic/gin"
+)
+
+// graphContentTypes are the media types of rendered task graphs
+var graphContentTypes = map[taskgraph.Format]string{
+	taskgraph.Mermaid: "text/vnd.mermaid; charset=utf-8",
+	taskgraph.DOT:     "text/vnd.graphviz; charset=utf-8",
+}
+
+// GetTaskGraph renders a human task, its agent tasks and their dependencies
+// as diagram text
+// GET /api/v1/tasks/:id/graph?format=mermaid|dot&todos=true
+func (h *RESTAPIHandler) GetTaskGraph(c *gin.Context) {
+	h.renderTaskGraph(c, c.Param("id"))
+}
+
+// GetBoardGraph renders every human task as GetTaskGraph does
+// GET /api/board/graph?format=mermaid|dot&todos=true
+func (h *RESTAPIHandler) GetBoardGraph(c *gin.Context) {
+	h.renderTaskGraph(c, "")
+}
+
+func (h *RESTAPIHandler) renderTaskGraph(c *gin.Context, humanTaskID string) {
+	format, err := taskgraph.ParseFormat(c.Query("format"))
+	if err != nil {
+		errcode.RespondCode(c, errcode.Validation, err.Error())
+		return
+	}
+
+	graph, err := taskgraph.Build(h.taskStorage.ListAllHumanTasks(), h.taskStorage.ListAllAgentTasks(), taskgraph.Options{
+		HumanTaskID:  humanTaskID,
+		IncludeTodos: c.Query("todos") == "true",
+	})
+	if err != nil {
+		errcode.RespondCode(c, errcode.NotFound, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, graphContentTypes[format], []byte(graph.Render(format)))
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// graphStorage lists fixed tasks; other TaskStorage methods are not called
+type graphStorage struct {
+	storage.TaskStorage
+	humans []*storage.HumanTask
+	agents []*storage.AgentTask
+}
+
+func (s *graphStorage) ListAllHumanTasks() []*storage.HumanTask { return s.humans }
+func (s *graphStorage) ListAllAgentTasks() []*storage.AgentTask { return s.agents }
+
+func TestGetTaskGraph(t *testing.T) {
+	tasks := &graphStorage{
+		humans: []*storage.HumanTask{{ID: "h1", Prompt: "Ship it", Status: storage.TaskStatusPending}},
+		agents: []*storage.AgentTask{{ID: "a1", HumanTaskID: "h1", Role: "Build", Status: storage.TaskStatusPending}},
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewRESTAPIHandler(tasks, nil, nil, nil, nil, nil, zap.NewNop()).RegisterRESTRoutes(r)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/v1/tasks/h1/graph")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/vnd.mermaid; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "h1 --> a1")
+
+	w = get("/api/board/graph?format=dot")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "h1 -> a1;")
+
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/tasks/missing/graph").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/board/graph?format=svg").Code)
+}
@@ -0,0 +1,345 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
+	"hyper/internal/i18n"
+	"hyper/internal/middleware"
+	"hyper/internal/toolprogress"
+	"hyper/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// eventStreamType is the Accept media type that streams a tool call as
+// server-sent events
+const eventStreamType = "text/event-stream"
+
+// ToolInvoker calls registered MCP tool handlers in-process
+type ToolInvoker interface {
+	Tools() []*mcp.Tool
+	Tool(name string) (*mcp.Tool, bool)
+	Invoke(ctx context.Context, name string, args map[string]interface{}) (*mcp.CallToolResult, error)
+}
+
+// ToolProxy exposes every registered MCP tool over REST at
+// POST /api/tools/:toolName, so new tools need no hand-written gin handler.
+// Handlers are invoked in-process; the request body is validated against the
+// tool's input schema and the caller's role is checked against
+// middleware.RequiredRoleForTool (or the authorization policy, when one is
+// configured) before the tool runs. Calls accepting text/event-stream are
+// answered with server-sent events as the tool reports progress.
+type ToolProxy struct {
+	invoker ToolInvoker
+	policy  middleware.Policy // Optional: decides tool calls in place of roles
+	logger  *zap.Logger
+}
+
+// ToolSummaryDTO describes a tool callable through the proxy
+type ToolSummaryDTO struct {
+	Name          string      `json:"name"`
+	Description   string      `json:"description"`
+	InputSchema   interface{} `json:"inputSchema"`
+	RequiredRole  string      `json:"requiredRole"`
+	RequiredScope string      `json:"requiredScope"`
+	Path          string      `json:"path"`
+}
+
+// ToolCallResponse is the REST result of a proxied tool call
+type ToolCallResponse struct {
+	Tool    string        `json:"tool"`
+	Result  interface{}   `json:"result"`
+	Content []mcp.Content `json:"content,omitempty"`
+}
+
+// NewToolProxy creates a REST proxy for in-process tool handlers
+func NewToolProxy(invoker ToolInvoker, logger *zap.Logger) *ToolProxy {
+	return &ToolProxy{
+		invoker: invoker,
+		logger:  logger,
+	}
+}
+
+// SetPolicy delegates tool call authorization to a policy engine
+func (p *ToolProxy) SetPolicy(policy middleware.Policy) {
+	p.policy = policy
+}
+
+// RegisterRoutes registers the tool proxy routes
+func (p *ToolProxy) RegisterRoutes(r *gin.Engine) {
+	tools := r.Group("/api/tools")
+	{
+		tools.GET("", p.ListTools)
+		tools.POST("/:toolName", p.CallTool)
+	}
+}
+
+// ListTools lists the tools callable through the proxy
+// GET /api/tools
+func (p *ToolProxy) ListTools(c *gin.Context) {
+	tools := []ToolSummaryDTO{}
+	for _, tool := range p.invoker.Tools() {
+		tools = append(tools, ToolSummaryDTO{
+			Name:          tool.Name,
+			Description:   tool.Description,
+			InputSchema:   tool.InputSchema,
+			RequiredRole:  string(middleware.RequiredRoleForTool(tool.Name)),
+			RequiredScope: string(middleware.RequiredScopeForTool(tool.Name)),
+			Path:          "/api/tools/" + tool.Name,
+		})
+	}
+
+	envelope.List(c, gin.H{
+		"tools": tools,
+		"count": len(tools),
+	}, envelope.Complete(len(tools)))
+}
+
+// CallTool validates the JSON body against the tool's input schema and calls it
+// POST /api/tools/:toolName
+func (p *ToolProxy) CallTool(c *gin.Context) {
+	name := c.Param("toolName")
+
+	if scope := middleware.RequiredScopeForTool(name); !middleware.GetScopes(c).Allows(scope) {
+		errcode.RespondCode(c, errcode.PermissionDenied, fmt.Sprintf("permission denied: tool %s requires scope %s", name, scope))
+		return
+	}
+
+	role := middleware.GetRole(c)
+	required := middleware.RequiredRoleForTool(name)
+	if p.policy == nil && !role.Allows(required) {
+		errcode.RespondCode(c, errcode.PermissionDenied, fmt.Sprintf("permission denied: tool %s requires role %s (current role: %s)", name, required, role))
+		return
+	}
+
+	args, err := decodeToolArguments(c.Request.Body)
+	if err != nil {
+		validation.Respond(c, "Invalid tool arguments", validation.FromBinding(err, nil))
+		return
+	}
+
+	// Arguments sent under deprecated names are rewritten before policies
+	// and validation see them
+	if tool, ok := p.invoker.Tool(name); ok {
+		if declared := fieldnames.SchemaDeclares(tool.InputSchema); declared != nil {
+			fieldnames.Deprecate(c, fieldnames.Normalize(args, declared))
+		}
+	}
+
+	// Policies see the arguments, so they are decided after decoding
+	if p.policy != nil {
+		decision := middleware.Authorize(c.Request.Context(), p.policy, middleware.PolicyInput{
+			Kind:         middleware.PolicyKindTool,
+			Principal:    middleware.Principal{UserID: c.GetString("userId"), Role: role},
+			Tool:         name,
+			Arguments:    args,
+			RequiredRole: required,
+		})
+		if !decision.Allow {
+			errcode.RespondCode(c, errcode.PermissionDenied, middleware.ToolDeniedMessage(name, decision))
+			return
+		}
+	}
+
+	tool, ok := p.invoker.Tool(name)
+	if !ok {
+		errcode.RespondCode(c, errcode.NotFound, fmt.Sprintf("tool not found: %s", name))
+		return
+	}
+
+	if errs := validation.FromSchema(tool.InputSchema, args); len(errs) > 0 {
+		errcode.RespondDetails(c, errcode.Validation,
+			fmt.Sprintf("invalid arguments for %s: %s", name, validation.Summary(errs)),
+			gin.H{"tool": name, "errors": errs})
+		return
+	}
+
+	// Handlers render messages in the caller's locale, as the MCP locale middleware does
+	locale := i18n.DefaultFromEnv()
+	if headerLocale, ok := i18n.FromHeader(c.Request.Header); ok {
+		locale = headerLocale
+	}
+
+	ctx := i18n.WithLocale(c.Request.Context(), locale)
+
+	// Clients accepting text/event-stream see the tool's progress while it runs
+	if acceptsEventStream(c.Request) {
+		p.streamToolCall(c, ctx, name, args)
+		return
+	}
+
+	result, err := p.invoker.Invoke(ctx, name, args)
+	c.JSON(p.toolCallResponse(name, result, err))
+}
+
+// streamToolCall calls a tool and answers with server-sent events: a
+// "progress" event per toolprogress.Update the tool reports, then a "result"
+// (or "error") event carrying the envelope a buffered call would return
+func (p *ToolProxy) streamToolCall(c *gin.Context, ctx context.Context, name string, args map[string]interface{}) {
+	c.Header("Content-Type", eventStreamType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // Keep reverse proxies from buffering events
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	var mu sync.Mutex
+	finished := false
+	ctx = toolprogress.WithReporter(ctx, func(update toolprogress.Update) {
+		mu.Lock()
+		defer mu.Unlock()
+		// Goroutines the tool left running may report after the result
+		if finished {
+			return
+		}
+		c.SSEvent("progress", update)
+		c.Writer.Flush()
+	})
+
+	result, err := p.invoker.Invoke(ctx, name, args)
+	_, response := p.toolCallResponse(name, result, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	finished = true
+	event := "result"
+	if response.Error != nil {
+		event = "error"
+	}
+	c.SSEvent(event, response)
+	c.Writer.Flush()
+}
+
+// toolCallResponse returns the status and envelope answering a tool call
+func (p *ToolProxy) toolCallResponse(name string, result *mcp.CallToolResult, err error) (int, envelope.Response) {
+	if err != nil {
+		code := errcode.Of(err)
+		return code.HTTPStatus(), envelope.Response{Error: &envelope.Error{
+			Code:    string(code),
+			Message: fmt.Sprintf("failed to call tool %s: %s", name, err.Error()),
+		}}
+	}
+
+	if result.IsError {
+		code, message := toolErrorCode(result)
+		p.logger.Debug("Proxied tool call failed",
+			zap.String("tool", name),
+			zap.String("code", string(code)),
+			zap.String("error", message))
+		details := gin.H{"tool": name}
+		if errs := toolFieldErrors(result); errs != nil {
+			details["errors"] = errs
+		}
+		return code.HTTPStatus(), envelope.Response{Error: &envelope.Error{Code: string(code), Message: message, Details: details}}
+	}
+
+	// Structured results are returned as-is; only text-only tools fall back to
+	// decoding their text output
+	if result.StructuredContent != nil {
+		return http.StatusOK, envelope.Response{Data: ToolCallResponse{Tool: name, Result: result.StructuredContent}}
+	}
+	return http.StatusOK, envelope.Response{Data: ToolCallResponse{
+		Tool:    name,
+		Result:  toolResultValue(result.Content),
+		Content: result.Content,
+	}}
+}
+
+// acceptsEventStream reports whether the client asked for server-sent events
+func acceptsEventStream(req *http.Request) bool {
+	for _, accept := range req.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			if strings.HasPrefix(strings.TrimSpace(mediaType), eventStreamType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeToolArguments reads the request body as a JSON object; an empty body
+// means no arguments
+func decodeToolArguments(body io.Reader) (map[string]interface{}, error) {
+	args := map[string]interface{}{}
+	if body == nil {
+		return args, nil
+	}
+
+	decoder := json.NewDecoder(body)
+	if err := decoder.Decode(&args); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("request body must be a JSON object of tool arguments: %w", err)
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	return args, nil
+}
+
+// toolResultValue returns the tool's text output, decoded when it is JSON, so
+// REST callers get the same shape a dedicated handler would return
+func toolResultValue(content []mcp.Content) interface{} {
+	var texts []string
+	for _, item := range content {
+		if text, ok := item.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	joined := strings.Join(texts, "\n")
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(joined), &decoded); err == nil {
+		return decoded
+	}
+	return joined
+}
+
+// toolErrorCode extracts the error code and message from a failed tool result,
+// preferring the structured errcode set by coded tool errors
+func toolErrorCode(result *mcp.CallToolResult) (errcode.Code, string) {
+	message, _ := toolResultValue(result.Content).(string)
+	message = strings.TrimSpace(strings.TrimPrefix(message, "❌ Error:"))
+
+	if structured, ok := result.StructuredContent.(map[string]interface{}); ok {
+		if errObj, ok := structured["error"].(map[string]interface{}); ok {
+			if msg, ok := errObj["message"].(string); ok && msg != "" {
+				message = msg
+			}
+			// In-process results carry errcode.Code; decoded ones carry strings
+			switch code := errObj["code"].(type) {
+			case errcode.Code:
+				if code != "" {
+					return code, message
+				}
+			case string:
+				if code != "" {
+					return errcode.Code(code), message
+				}
+			}
+		}
+	}
+	return errcode.Classify(message), message
+}
+
+// toolFieldErrors returns the fields a failed tool result lists as invalid
+// (error.errors in its structured content), or nil
+func toolFieldErrors(result *mcp.CallToolResult) interface{} {
+	if structured, ok := result.StructuredContent.(map[string]interface{}); ok {
+		if errObj, ok := structured["error"].(map[string]interface{}); ok {
+			return errObj["errors"]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	mcphandlers "hyper/internal/mcp/handlers"
+	"hyper/internal/middleware"
+	"hyper/internal/toolprogress"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupToolProxyRouter(role middleware.Role) *gin.Engine {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	registry := mcphandlers.NewToolMetadataRegistry()
+	registry.RegisterToolWithServer(server, &mcp.Tool{
+		Name:        "echo_task",
+		Description: "Echo a task title",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"title": {Type: "string"},
+			},
+			Required: []string{"title"},
+		},
+	}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args map[string]interface{}
+		if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+			return nil, err
+		}
+		if args["title"] == "missing" {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "❌ Error: task missing not found"}},
+				IsError: true,
+			}, nil
+		}
+		if args["title"] == "slow" {
+			for i := 1; i <= 2; i++ {
+				toolprogress.Report(ctx, toolprogress.Update{Progress: float64(i), Total: 2, Message: fmt.Sprintf("step %d", i)})
+			}
+		}
+		if args["title"] == "plain" {
+			data, _ := json.Marshal(map[string]interface{}{"success": true, "title": args["title"]})
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(data)}}}, nil
+		}
+		return &mcp.CallToolResult{
+			Content:           []mcp.Content{&mcp.TextContent{Text: "done"}},
+			StructuredContent: map[string]interface{}{"success": true, "title": args["title"]},
+		}, nil
+	})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("role", role)
+		c.Next()
+	})
+	NewToolProxy(registry, zap.NewNop()).RegisterRoutes(r)
+	return r
+}
+
+func callProxy(r *gin.Engine, path, body string) (*httptest.ResponseRecorder, envelope.Response) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var resp envelope.Response
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestToolProxy_CallTool(t *testing.T) {
+	r := setupToolProxyRouter(middleware.RoleContributor)
+
+	w, resp := callProxy(r, "/api/tools/echo_task", `{"title":"ship it"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Nil(t, resp.Error)
+	data := resp.Data.(map[string]interface{})
+	assert.Equal(t, "echo_task", data["tool"])
+	result, ok := data["result"].(map[string]interface{})
+	require.True(t, ok, "structured tool output should be returned as the result")
+	assert.Equal(t, "ship it", result["title"])
+	assert.Nil(t, data["content"])
+
+	w, resp = callProxy(r, "/api/tools/echo_task", `{"title":"plain"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	result, ok = resp.Data.(map[string]interface{})["result"].(map[string]interface{})
+	require.True(t, ok, "JSON text output should be decoded")
+	assert.Equal(t, "plain", result["title"])
+}
+
+func TestToolProxy_StreamsProgress(t *testing.T) {
+	r := setupToolProxyRouter(middleware.RoleContributor)
+
+	stream := func(body string) []string {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/tools/echo_task", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		return strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	}
+
+	events := stream(`{"title":"slow"}`)
+	require.Len(t, events, 3, events)
+	assert.Equal(t, "event:progress\ndata:{\"progress\":1,\"total\":2,\"message\":\"step 1\"}", events[0])
+	assert.Equal(t, "event:progress\ndata:{\"progress\":2,\"total\":2,\"message\":\"step 2\"}", events[1])
+	require.True(t, strings.HasPrefix(events[2], "event:result\ndata:"), events[2])
+	var resp envelope.Response
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(events[2], "event:result\ndata:")), &resp))
+	assert.Equal(t, "slow", resp.Data.(map[string]interface{})["result"].(map[string]interface{})["title"])
+
+	events = stream(`{"title":"missing"}`)
+	require.Len(t, events, 1)
+	require.True(t, strings.HasPrefix(events[0], "event:error\ndata:"), events[0])
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(events[0], "event:error\ndata:")), &resp))
+	assert.Equal(t, string(errcode.NotFound), resp.Error.Code)
+}
+
+func TestToolProxy_ValidatesSchema(t *testing.T) {
+	r := setupToolProxyRouter(middleware.RoleContributor)
+
+	w, resp := callProxy(r, "/api/tools/echo_task", `{"title":42}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, string(errcode.Validation), resp.Error.Code)
+	assert.Equal(t, "invalid arguments for echo_task: /title must be string, not integer", resp.Error.Message)
+
+	w, resp = callProxy(r, "/api/tools/echo_task", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"pointer": "/title", "constraint": "required", "message": "is required", "example": "example",
+	}}, resp.Error.Details.(map[string]interface{})["errors"])
+
+	w, _ = callProxy(r, "/api/tools/echo_task", `not json`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestToolProxy_Errors(t *testing.T) {
+	r := setupToolProxyRouter(middleware.RoleContributor)
+
+	w, resp := callProxy(r, "/api/tools/no_such_tool", `{}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, string(errcode.NotFound), resp.Error.Code)
+
+	w, resp = callProxy(r, "/api/tools/echo_task", `{"title":"missing"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, "task missing not found", resp.Error.Message)
+	assert.Equal(t, "echo_task", resp.Error.Details.(map[string]interface{})["tool"])
+}
+
+func TestToolProxy_EnforcesToolRole(t *testing.T) {
+	r := setupToolProxyRouter(middleware.RoleViewer)
+
+	w, resp := callProxy(r, "/api/tools/echo_task", `{"title":"ship it"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, string(errcode.PermissionDenied), resp.Error.Code)
+}
+
+func TestToolProxy_ListTools(t *testing.T) {
+	r := setupToolProxyRouter(middleware.RoleViewer)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tools", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data struct {
+			Tools []ToolSummaryDTO `json:"tools"`
+			Count int              `json:"count"`
+		} `json:"data"`
+		Meta envelope.Meta `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 1, resp.Data.Count)
+	assert.Equal(t, "/api/tools/echo_task", resp.Data.Tools[0].Path)
+	assert.Equal(t, string(middleware.RoleContributor), resp.Data.Tools[0].RequiredRole)
+	require.NotNil(t, resp.Meta.Pagination)
+	assert.Equal(t, 1, resp.Meta.Pagination.Total)
+}
@@ -0,0 +1,284 @@
+package automation
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// HookStore is the part of storage.AutomationHookStorage the engine needs
+type HookStore interface {
+	ListEnabledHooks(event string) ([]*storage.AutomationHook, error)
+	RecordRun(name string, at time.Time, runErr error) error
+}
+
+// Engine runs operator hook scripts on task lifecycle events and applies
+// their effects
+type Engine struct {
+	hooks   HookStore
+	tasks   storage.TaskStorage
+	changes storage.TaskAutomationStorage
+	logger  *zap.Logger
+	async   func(func())
+	now     func() time.Time
+
+	mu       sync.Mutex
+	compiled map[string]compiledHook // By hook name
+}
+
+// compiledHook caches a hook's script until the hook is saved again
+type compiledHook struct {
+	updatedAt time.Time
+	script    *Script
+}
+
+// NewEngine creates an engine whose hooks change tasks, which must support
+// the changes automation makes
+func NewEngine(hooks HookStore, tasks storage.TaskStorage, logger *zap.Logger) (*Engine, error) {
+	changes, ok := tasks.(storage.TaskAutomationStorage)
+	if !ok {
+		return nil, fmt.Errorf("task storage does not support automation changes")
+	}
+	return &Engine{
+		hooks:    hooks,
+		tasks:    tasks,
+		changes:  changes,
+		logger:   logger,
+		async:    func(f func()) { go f() },
+		now:      time.Now,
+		compiled: map[string]compiledHook{},
+	}, nil
+}
+
+// Watch wraps a task storage so that hooks run after task creation, TODO
+// completion and status changes. Hooks run in the background and never fail
+// the operation that triggered them.
+func (e *Engine) Watch(tasks storage.TaskStorage) storage.TaskStorage {
+	return &watchedTaskStorage{TaskStorage: tasks, engine: e}
+}
+
+// watchedTaskStorage fires hook events for task changes
+type watchedTaskStorage struct {
+	storage.TaskStorage
+	engine *Engine
+}
+
+// Unwrap returns the wrapped storage, for reads that bypass the wrapper
+func (w *watchedTaskStorage) Unwrap() storage.TaskStorage {
+	return w.TaskStorage
+}
+
+func (w *watchedTaskStorage) CreateHumanTask(prompt string) (*storage.HumanTask, error) {
+	task, err := w.TaskStorage.CreateHumanTask(prompt)
+	if err != nil {
+		return nil, err
+	}
+	w.engine.async(func() { w.engine.Fire(storage.HookEventTaskCreated, task.ID, "", "") })
+	return task, nil
+}
+
+func (w *watchedTaskStorage) CloneHumanTask(sourceTaskID, project, prompt string) (*storage.HumanTask, []*storage.AgentTask, error) {
+	task, agentTasks, err := w.TaskStorage.CloneHumanTask(sourceTaskID, project, prompt)
+	if err != nil {
+		return nil, nil, err
+	}
+	w.engine.async(func() { w.engine.Fire(storage.HookEventTaskCreated, task.ID, "", "") })
+	return task, agentTasks, nil
+}
+
+func (w *watchedTaskStorage) CreateAgentTask(humanTaskID, agentName, role string, todos []storage.TodoItemInput, contextSummary string, filesModified []string, qdrantCollections []string, priorWorkSummary string) (*storage.AgentTask, error) {
+	task, err := w.TaskStorage.CreateAgentTask(humanTaskID, agentName, role, todos, contextSummary, filesModified, qdrantCollections, priorWorkSummary)
+	if err != nil {
+		return nil, err
+	}
+	w.engine.async(func() { w.engine.Fire(storage.HookEventAgentTaskCreated, task.ID, "", "") })
+	return task, nil
+}
+
+func (w *watchedTaskStorage) UpdateTodoStatus(agentTaskID, todoID string, status storage.TodoStatus, notes string) error {
+	if err := w.TaskStorage.UpdateTodoStatus(agentTaskID, todoID, status, notes); err != nil {
+		return err
+	}
+	if status == storage.TodoStatusCompleted {
+		w.engine.async(func() { w.engine.Fire(storage.HookEventTodoCompleted, agentTaskID, todoID, "") })
+	}
+	return nil
+}
+
+// UpdateTaskStatus updates the status and fires task_status_changed with the
+// previous status when it actually changed
+func (w *watchedTaskStorage) UpdateTaskStatus(taskID string, status storage.TaskStatus, notes string) error {
+	previous := w.engine.taskStatus(w.TaskStorage, taskID)
+	if err := w.TaskStorage.UpdateTaskStatus(taskID, status, notes); err != nil {
+		return err
+	}
+	if previous != status {
+		w.engine.async(func() { w.engine.Fire(storage.HookEventTaskStatusChanged, taskID, "", previous) })
+	}
+	return nil
+}
+
+func (e *Engine) taskStatus(tasks storage.TaskStorage, taskID string) storage.TaskStatus {
+	if human, err := tasks.GetHumanTask(taskID); err == nil {
+		return human.Status
+	}
+	if agent, err := tasks.GetAgentTask(taskID); err == nil {
+		return agent.Status
+	}
+	return ""
+}
+
+// Fire runs the enabled hooks of an event for a task, in name order. Each
+// hook sees the task as left by the hooks before it.
+func (e *Engine) Fire(event, taskID, todoID string, previousStatus storage.TaskStatus) {
+	hooks, err := e.hooks.ListEnabledHooks(event)
+	if err != nil {
+		e.logger.Warn("Failed to load automation hooks", zap.String("event", event), zap.Error(err))
+		return
+	}
+
+	for _, hook := range hooks {
+		runErr := e.runHook(hook, event, taskID, todoID, previousStatus)
+		if runErr != nil {
+			e.logger.Warn("Automation hook failed",
+				zap.String("hook", hook.Name),
+				zap.String("event", event),
+				zap.String("taskId", taskID),
+				zap.Error(runErr))
+		}
+		if err := e.hooks.RecordRun(hook.Name, e.now().UTC(), runErr); err != nil {
+			e.logger.Warn("Failed to record automation hook run", zap.String("hook", hook.Name), zap.Error(err))
+		}
+	}
+}
+
+func (e *Engine) runHook(hook *storage.AutomationHook, event, taskID, todoID string, previousStatus storage.TaskStatus) error {
+	script, err := e.compile(hook)
+	if err != nil {
+		return err
+	}
+	vars, isAgent, err := e.Variables(event, taskID, todoID, previousStatus)
+	if err != nil {
+		return err
+	}
+	effects, err := script.Run(vars)
+	if err != nil {
+		return err
+	}
+	return e.apply(hook.Name, taskID, isAgent, effects)
+}
+
+// compile returns the hook's compiled script, reusing it until the hook changes
+func (e *Engine) compile(hook *storage.AutomationHook) (*Script, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if cached, ok := e.compiled[hook.Name]; ok && cached.updatedAt.Equal(hook.UpdatedAt) {
+		return cached.script, nil
+	}
+	script, err := Compile(hook.Script)
+	if err != nil {
+		return nil, err
+	}
+	e.compiled[hook.Name] = compiledHook{updatedAt: hook.UpdatedAt, script: script}
+	return script, nil
+}
+
+// Variables builds the variables a script sees for an event: event, task and,
+// for todo_completed, todo. It also reports whether the task is an agent task.
+func (e *Engine) Variables(event, taskID, todoID string, previousStatus storage.TaskStatus) (map[string]interface{}, bool, error) {
+	vars := map[string]interface{}{"event": event}
+
+	if human, err := e.tasks.GetHumanTask(taskID); err == nil {
+		vars["task"] = map[string]interface{}{
+			"id":              human.ID,
+			"kind":            "human",
+			"prompt":          human.Prompt,
+			"project":         human.Project,
+			"status":          string(human.Status),
+			"previous_status": string(previousStatus),
+			"notes":           human.Notes,
+			"tags":            stringList(human.Tags),
+		}
+		return vars, false, nil
+	}
+
+	agent, err := e.tasks.GetAgentTask(taskID)
+	if err != nil {
+		return nil, false, fmt.Errorf("task with ID %s not found", taskID)
+	}
+	task := map[string]interface{}{
+		"id":              agent.ID,
+		"kind":            "agent",
+		"human_task_id":   agent.HumanTaskID,
+		"agent":           agent.AgentName,
+		"role":            agent.Role,
+		"status":          string(agent.Status),
+		"previous_status": string(previousStatus),
+		"notes":           agent.Notes,
+		"context_summary": agent.ContextSummary,
+		"files_modified":  stringList(agent.FilesModified),
+		"tags":            stringList(agent.Tags),
+		"todo_count":      float64(len(agent.Todos)),
+	}
+	// Scripts route on the project of the parent human task
+	if parent, err := e.tasks.GetHumanTask(agent.HumanTaskID); err == nil {
+		task["project"] = parent.Project
+		task["prompt"] = parent.Prompt
+	}
+	vars["task"] = task
+
+	if todoID != "" {
+		for _, todo := range agent.Todos {
+			if todo.ID == todoID {
+				vars["todo"] = map[string]interface{}{
+					"id":            todo.ID,
+					"description":   todo.Description,
+					"file_path":     todo.FilePath,
+					"function_name": todo.FunctionName,
+					"notes":         todo.Notes,
+				}
+			}
+		}
+	}
+	return vars, true, nil
+}
+
+// apply makes the changes a hook asked for
+func (e *Engine) apply(hookName, taskID string, isAgent bool, effects *Effects) error {
+	for _, msg := range effects.Logs {
+		e.logger.Info("Automation hook log", zap.String("hook", hookName), zap.String("taskId", taskID), zap.String("message", msg))
+	}
+	if len(effects.Tags) > 0 {
+		if err := e.changes.AddTaskTags(taskID, effects.Tags); err != nil {
+			return err
+		}
+	}
+	if effects.DueInDays != nil {
+		due := e.now().UTC().Add(time.Duration(*effects.DueInDays * float64(24*time.Hour)))
+		if err := e.tasks.SetTaskDueDate(taskID, &due); err != nil {
+			return err
+		}
+	}
+	if effects.Assign != "" {
+		if !isAgent {
+			return fmt.Errorf("assign only applies to agent tasks")
+		}
+		if err := e.changes.AssignAgentTask(taskID, effects.Assign); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stringList(items []string) []interface{} {
+	list := make([]interface{}, len(items))
+	for i, item := range items {
+		list[i] = item
+	}
+	return list
+}
@@ -0,0 +1,203 @@
+package automation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryTasks keeps tasks in memory and supports automation changes
+type memoryTasks struct {
+	storage.TaskStorage
+	human map[string]*storage.HumanTask
+	agent map[string]*storage.AgentTask
+}
+
+func (m *memoryTasks) CreateHumanTask(prompt string) (*storage.HumanTask, error) {
+	task := &storage.HumanTask{ID: fmt.Sprintf("h-%d", len(m.human)+1), Prompt: prompt, Status: storage.TaskStatusPending}
+	m.human[task.ID] = task
+	return task, nil
+}
+
+func (m *memoryTasks) CreateAgentTask(humanTaskID, agentName, role string, todos []storage.TodoItemInput, contextSummary string, filesModified []string, qdrantCollections []string, priorWorkSummary string) (*storage.AgentTask, error) {
+	task := &storage.AgentTask{ID: fmt.Sprintf("a-%d", len(m.agent)+1), HumanTaskID: humanTaskID, AgentName: agentName, Role: role, FilesModified: filesModified, Status: storage.TaskStatusPending}
+	for i, todo := range todos {
+		task.Todos = append(task.Todos, storage.TodoItem{ID: fmt.Sprintf("todo-%d", i+1), Description: todo.Description})
+	}
+	m.agent[task.ID] = task
+	return task, nil
+}
+
+func (m *memoryTasks) GetHumanTask(id string) (*storage.HumanTask, error) {
+	if task, ok := m.human[id]; ok {
+		return task, nil
+	}
+	return nil, fmt.Errorf("human task with ID %s not found", id)
+}
+
+func (m *memoryTasks) GetAgentTask(id string) (*storage.AgentTask, error) {
+	if task, ok := m.agent[id]; ok {
+		return task, nil
+	}
+	return nil, fmt.Errorf("agent task with ID %s not found", id)
+}
+
+func (m *memoryTasks) UpdateTaskStatus(id string, status storage.TaskStatus, notes string) error {
+	if task, ok := m.human[id]; ok {
+		task.Status = status
+		return nil
+	}
+	if task, ok := m.agent[id]; ok {
+		task.Status = status
+		return nil
+	}
+	return fmt.Errorf("task with ID %s not found", id)
+}
+
+func (m *memoryTasks) UpdateTodoStatus(agentTaskID, todoID string, status storage.TodoStatus, notes string) error {
+	task, err := m.GetAgentTask(agentTaskID)
+	if err != nil {
+		return err
+	}
+	for i := range task.Todos {
+		if task.Todos[i].ID == todoID {
+			task.Todos[i].Status = status
+			return nil
+		}
+	}
+	return fmt.Errorf("todo item with ID %s not found", todoID)
+}
+
+func (m *memoryTasks) SetTaskDueDate(id string, dueAt *time.Time) error {
+	if task, ok := m.human[id]; ok {
+		task.DueAt = dueAt
+		return nil
+	}
+	if task, ok := m.agent[id]; ok {
+		task.DueAt = dueAt
+		return nil
+	}
+	return fmt.Errorf("task with ID %s not found", id)
+}
+
+func (m *memoryTasks) AddTaskTags(id string, tags []string) error {
+	if task, ok := m.human[id]; ok {
+		task.Tags = append(task.Tags, tags...)
+		return nil
+	}
+	if task, ok := m.agent[id]; ok {
+		task.Tags = append(task.Tags, tags...)
+		return nil
+	}
+	return fmt.Errorf("task with ID %s not found", id)
+}
+
+func (m *memoryTasks) AssignAgentTask(id, agentName string) error {
+	task, err := m.GetAgentTask(id)
+	if err != nil {
+		return err
+	}
+	task.AgentName = agentName
+	return nil
+}
+
+// memoryHooks keeps hooks and their last run errors in memory
+type memoryHooks struct {
+	hooks  []*storage.AutomationHook
+	errors map[string]error
+}
+
+func (m *memoryHooks) ListEnabledHooks(event string) ([]*storage.AutomationHook, error) {
+	var hooks []*storage.AutomationHook
+	for _, hook := range m.hooks {
+		if hook.Event == event && hook.Enabled {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (m *memoryHooks) RecordRun(name string, at time.Time, runErr error) error {
+	m.errors[name] = runErr
+	return nil
+}
+
+func newTestEngine(t *testing.T, hooks ...*storage.AutomationHook) (*Engine, *memoryTasks, *memoryHooks) {
+	tasks := &memoryTasks{human: map[string]*storage.HumanTask{}, agent: map[string]*storage.AgentTask{}}
+	store := &memoryHooks{hooks: hooks, errors: map[string]error{}}
+	engine, err := NewEngine(store, tasks, zap.NewNop())
+	require.NoError(t, err)
+	engine.async = func(f func()) { f() }
+	engine.now = func() time.Time { return time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC) }
+	return engine, tasks, store
+}
+
+func TestEngineTaskCreated(t *testing.T) {
+	engine, tasks, store := newTestEngine(t,
+		&storage.AutomationHook{Name: "a-tag", Event: storage.HookEventTaskCreated, Enabled: true,
+			Script: `if contains(lower(task.prompt), "security") { tag("security") due_in_days(1) }`},
+		&storage.AutomationHook{Name: "b-disabled", Event: storage.HookEventTaskCreated, Enabled: false,
+			Script: `tag("never")`},
+		&storage.AutomationHook{Name: "c-broken", Event: storage.HookEventTaskCreated, Enabled: true,
+			Script: `assign("go-dev")`},
+	)
+	watched := engine.Watch(tasks)
+
+	task, err := watched.CreateHumanTask("Fix Security headers")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"security"}, tasks.human[task.ID].Tags)
+	require.NotNil(t, tasks.human[task.ID].DueAt)
+	assert.Equal(t, time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC), *tasks.human[task.ID].DueAt)
+
+	assert.NoError(t, store.errors["a-tag"])
+	assert.NotContains(t, store.errors, "b-disabled")
+	require.Error(t, store.errors["c-broken"])
+	assert.Contains(t, store.errors["c-broken"].Error(), "only applies to agent tasks")
+}
+
+func TestEngineAgentTaskEvents(t *testing.T) {
+	engine, tasks, _ := newTestEngine(t,
+		&storage.AutomationHook{Name: "route", Event: storage.HookEventAgentTaskCreated, Enabled: true,
+			Script: `if task.project == "api" && contains(task.files_modified, "go.mod") { assign("go-dev") }`},
+		&storage.AutomationHook{Name: "todo", Event: storage.HookEventTodoCompleted, Enabled: true,
+			Script: `tag("done:" + todo.description)`},
+		&storage.AutomationHook{Name: "status", Event: storage.HookEventTaskStatusChanged, Enabled: true,
+			Script: `tag(task.previous_status + "->" + task.status)`},
+	)
+	watched := engine.Watch(tasks)
+
+	human, _ := tasks.CreateHumanTask("Upgrade deps")
+	human.Project = "api"
+	agent, err := watched.CreateAgentTask(human.ID, "generalist", "Upgrade", []storage.TodoItemInput{{Description: "bump"}}, "", []string{"go.mod"}, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, "go-dev", tasks.agent[agent.ID].AgentName)
+
+	require.NoError(t, watched.UpdateTodoStatus(agent.ID, "todo-1", storage.TodoStatusInProgress, ""))
+	assert.Empty(t, tasks.agent[agent.ID].Tags)
+	require.NoError(t, watched.UpdateTodoStatus(agent.ID, "todo-1", storage.TodoStatusCompleted, ""))
+	assert.Equal(t, []string{"done:bump"}, tasks.agent[agent.ID].Tags)
+
+	require.NoError(t, watched.UpdateTaskStatus(agent.ID, storage.TaskStatusBlocked, ""))
+	require.NoError(t, watched.UpdateTaskStatus(agent.ID, storage.TaskStatusBlocked, ""))
+	assert.Equal(t, []string{"done:bump", "pending->blocked"}, tasks.agent[agent.ID].Tags)
+}
+
+func TestEngineRecompilesChangedHooks(t *testing.T) {
+	hook := &storage.AutomationHook{Name: "tag", Event: storage.HookEventTaskCreated, Enabled: true, Script: `tag("v1")`, UpdatedAt: time.Unix(1, 0)}
+	engine, tasks, _ := newTestEngine(t, hook)
+	watched := engine.Watch(tasks)
+
+	first, _ := watched.CreateHumanTask("one")
+	hook.Script, hook.UpdatedAt = `tag("v2")`, time.Unix(2, 0)
+	second, _ := watched.CreateHumanTask("two")
+
+	assert.Equal(t, []string{"v1"}, tasks.human[first.ID].Tags)
+	assert.Equal(t, []string{"v2"}, tasks.human[second.ID].Tags)
+}
@@ -0,0 +1,429 @@
+package automation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxSteps bounds the work of one script run
+const maxSteps = 10000
+
+// Effects are the changes a script asked for. Scripts never touch storage
+// themselves; the engine applies the effects after the run.
+type Effects struct {
+	Tags      []string `json:"tags,omitempty"`      // tag(...): tags added to the task
+	Assign    string   `json:"assign,omitempty"`    // assign(agent): agent the agent task is reassigned to
+	DueInDays *float64 `json:"dueInDays,omitempty"` // due_in_days(n): due date n days from now
+	Logs      []string `json:"logs,omitempty"`      // log(...): messages written to the coordinator log
+}
+
+// Empty reports whether the script asked for nothing
+func (e *Effects) Empty() bool {
+	return len(e.Tags) == 0 && e.Assign == "" && e.DueInDays == nil && len(e.Logs) == 0
+}
+
+// run is the state of one script execution
+type run struct {
+	vars    map[string]interface{}
+	effects *Effects
+	steps   int
+}
+
+// Run executes the script with the given variables and returns its effects
+func (s *Script) Run(vars map[string]interface{}) (*Effects, error) {
+	r := &run{vars: map[string]interface{}{}, effects: &Effects{}}
+	for name, value := range vars {
+		r.vars[name] = value
+	}
+	if err := r.block(s.body); err != nil {
+		return nil, err
+	}
+	return r.effects, nil
+}
+
+func (r *run) block(body []node) error {
+	for _, stmt := range body {
+		if err := r.statement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *run) statement(stmt node) error {
+	switch s := stmt.(type) {
+	case *ifStmt:
+		cond, err := r.eval(s.cond)
+		if err != nil {
+			return err
+		}
+		if truthy(cond) {
+			return r.block(s.then)
+		}
+		return r.block(s.els)
+	case *letStmt:
+		value, err := r.eval(s.value)
+		if err != nil {
+			return err
+		}
+		r.vars[s.name] = value
+		return nil
+	default:
+		_, err := r.eval(stmt)
+		return err
+	}
+}
+
+func (r *run) eval(n node) (interface{}, error) {
+	r.steps++
+	if r.steps > maxSteps {
+		return nil, fmt.Errorf("script exceeded %d steps", maxSteps)
+	}
+
+	switch e := n.(type) {
+	case *literal:
+		return e.value, nil
+	case *variable:
+		return r.vars[e.name], nil
+	case *member:
+		object, err := r.eval(e.object)
+		if err != nil {
+			return nil, err
+		}
+		if fields, ok := object.(map[string]interface{}); ok {
+			return fields[e.name], nil
+		}
+		return nil, nil
+	case *listExpr:
+		items := make([]interface{}, len(e.items))
+		for i, item := range e.items {
+			value, err := r.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = value
+		}
+		return items, nil
+	case *unary:
+		operand, err := r.eval(e.operand)
+		if err != nil {
+			return nil, err
+		}
+		if e.op == "!" {
+			return !truthy(operand), nil
+		}
+		n, ok := operand.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %s", typeName(operand))
+		}
+		return -n, nil
+	case *binary:
+		return r.binary(e)
+	case *call:
+		args := make([]interface{}, len(e.args))
+		for i, arg := range e.args {
+			value, err := r.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+		value, err := functions[e.name](r, args)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", e.line, e.name, err)
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("unsupported expression %T", n)
+}
+
+func (r *run) binary(e *binary) (interface{}, error) {
+	left, err := r.eval(e.left)
+	if err != nil {
+		return nil, err
+	}
+	// Short-circuit logic
+	switch e.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := r.eval(e.right)
+		return truthy(right), err
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := r.eval(e.right)
+		return truthy(right), err
+	}
+
+	right, err := r.eval(e.right)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "+":
+		if a, ok := left.(float64); ok {
+			if b, ok := right.(float64); ok {
+				return a + b, nil
+			}
+		}
+		return toString(left) + toString(right), nil
+	}
+
+	a, aOK := left.(float64)
+	b, bOK := right.(float64)
+	if !aOK || !bOK {
+		as, aStr := left.(string)
+		bs, bStr := right.(string)
+		if e.op == "-" || !aStr || !bStr {
+			return nil, fmt.Errorf("line %d: cannot apply %s to %s and %s", e.line, e.op, typeName(left), typeName(right))
+		}
+		// Strings compare lexically
+		return compare(e.op, strings.Compare(as, bs)), nil
+	}
+	if e.op == "-" {
+		return a - b, nil
+	}
+	switch {
+	case a < b:
+		return compare(e.op, -1), nil
+	case a > b:
+		return compare(e.op, 1), nil
+	}
+	return compare(e.op, 0), nil
+}
+
+func compare(op string, c int) bool {
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+func truthy(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case string:
+		return x != ""
+	case float64:
+		return x != 0
+	case []interface{}:
+		return len(x) > 0
+	}
+	return true
+}
+
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case []interface{}, map[string]interface{}:
+		return false
+	case nil:
+		return b == nil
+	default:
+		switch b.(type) {
+		case []interface{}, map[string]interface{}:
+			return false
+		}
+		return x == b
+	}
+}
+
+func toString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		if x == float64(int64(x)) {
+			return fmt.Sprintf("%d", int64(x))
+		}
+		return fmt.Sprintf("%g", x)
+	}
+	return fmt.Sprint(v)
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "list"
+	}
+	return "object"
+}
+
+// function is a built-in; actions record effects on the run
+type function func(r *run, args []interface{}) (interface{}, error)
+
+// functions are the built-ins scripts may call
+var functions = map[string]function{
+	"contains":    builtinContains,
+	"lower":       stringFunc(strings.ToLower),
+	"upper":       stringFunc(strings.ToUpper),
+	"trim":        stringFunc(strings.TrimSpace),
+	"starts_with": stringPredicate(strings.HasPrefix),
+	"ends_with":   stringPredicate(strings.HasSuffix),
+	"matches":     builtinMatches,
+	"len":         builtinLen,
+
+	"tag":         actionTag,
+	"assign":      actionAssign,
+	"due_in_days": actionDueInDays,
+	"log":         actionLog,
+}
+
+func arity(args []interface{}, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("takes %d argument(s), got %d", n, len(args))
+	}
+	return nil
+}
+
+func stringFunc(f func(string) string) function {
+	return func(_ *run, args []interface{}) (interface{}, error) {
+		if err := arity(args, 1); err != nil {
+			return nil, err
+		}
+		return f(toString(args[0])), nil
+	}
+}
+
+func stringPredicate(f func(s, affix string) bool) function {
+	return func(_ *run, args []interface{}) (interface{}, error) {
+		if err := arity(args, 2); err != nil {
+			return nil, err
+		}
+		return f(toString(args[0]), toString(args[1])), nil
+	}
+}
+
+// builtinContains checks for a substring, or for an item of a list
+func builtinContains(_ *run, args []interface{}) (interface{}, error) {
+	if err := arity(args, 2); err != nil {
+		return nil, err
+	}
+	if list, ok := args[0].([]interface{}); ok {
+		for _, item := range list {
+			if equal(item, args[1]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return strings.Contains(toString(args[0]), toString(args[1])), nil
+}
+
+func builtinLen(_ *run, args []interface{}) (interface{}, error) {
+	if err := arity(args, 1); err != nil {
+		return nil, err
+	}
+	switch x := args[0].(type) {
+	case []interface{}:
+		return float64(len(x)), nil
+	case nil:
+		return float64(0), nil
+	}
+	return float64(len([]rune(toString(args[0])))), nil
+}
+
+var (
+	regexMu    sync.Mutex
+	regexCache = map[string]*regexp.Regexp{}
+)
+
+// builtinMatches reports whether a string matches a regular expression
+func builtinMatches(_ *run, args []interface{}) (interface{}, error) {
+	if err := arity(args, 2); err != nil {
+		return nil, err
+	}
+	pattern := toString(args[1])
+
+	regexMu.Lock()
+	re, ok := regexCache[pattern]
+	if !ok {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			regexMu.Unlock()
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		regexCache[pattern] = re
+	}
+	regexMu.Unlock()
+
+	return re.MatchString(toString(args[0])), nil
+}
+
+// actionTag adds tags to the task; lists are flattened
+func actionTag(r *run, args []interface{}) (interface{}, error) {
+	for _, arg := range args {
+		items, ok := arg.([]interface{})
+		if !ok {
+			items = []interface{}{arg}
+		}
+		for _, item := range items {
+			if tag := strings.TrimSpace(toString(item)); tag != "" && !contains(r.effects.Tags, tag) {
+				r.effects.Tags = append(r.effects.Tags, tag)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// actionAssign reassigns the agent task; the last call wins
+func actionAssign(r *run, args []interface{}) (interface{}, error) {
+	if err := arity(args, 1); err != nil {
+		return nil, err
+	}
+	agent := strings.TrimSpace(toString(args[0]))
+	if agent == "" {
+		return nil, fmt.Errorf("agent name cannot be empty")
+	}
+	r.effects.Assign = agent
+	return nil, nil
+}
+
+// actionDueInDays sets the task's due date relative to now
+func actionDueInDays(r *run, args []interface{}) (interface{}, error) {
+	if err := arity(args, 1); err != nil {
+		return nil, err
+	}
+	days, ok := args[0].(float64)
+	if !ok || days < 0 {
+		return nil, fmt.Errorf("days must be a non-negative number")
+	}
+	r.effects.DueInDays = &days
+	return nil, nil
+}
+
+func actionLog(r *run, args []interface{}) (interface{}, error) {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = toString(arg)
+	}
+	r.effects.Logs = append(r.effects.Logs, strings.Join(parts, " "))
+	return nil, nil
+}
@@ -0,0 +1,446 @@
+package automation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxScriptBytes caps the size of a hook script
+const MaxScriptBytes = 16 << 10
+
+// Hook scripts are small programs of if/else blocks, let bindings and calls:
+//
+//	# Tag crash reports of the mobile project and make them due in two days
+//	if task.project == "mobile" && contains(lower(task.prompt), "crash") {
+//	    tag("bug", "mobile")
+//	    due_in_days(2)
+//	}
+//
+// Expressions support string, number, boolean and null literals, lists,
+// member access (task.prompt) and the operators || && ! == != < <= > >= + -.
+// Built-ins are contains, lower, upper, trim, starts_with, ends_with, matches
+// (regular expression) and len; the actions tag, assign, due_in_days and log
+// record effects (see eval.go). There are no loops, so every script finishes
+// in time proportional to its length.
+
+// tokenKind classifies a token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// lex splits a script into tokens. Comments run from # to the end of a line.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[start:i]), line})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[start:i]), line})
+		case r == '"' || r == '\'':
+			quote := r
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(runes) || runes[i] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				if runes[i] == quote {
+					i++
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						b.WriteRune('\n')
+					case 't':
+						b.WriteRune('\t')
+					default:
+						b.WriteRune(runes[i])
+					}
+					i++
+					continue
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{tokString, b.String(), line})
+		default:
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				switch two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					tokens = append(tokens, token{tokOp, two, line})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("(){}[].,;=<>!+-", r) {
+				return nil, fmt.Errorf("line %d: unexpected character %q", line, r)
+			}
+			tokens = append(tokens, token{tokOp, string(r), line})
+			i++
+		}
+	}
+	return append(tokens, token{tokEOF, "", line}), nil
+}
+
+// node is a parsed statement or expression
+type node interface{}
+
+type (
+	literal  struct{ value interface{} }
+	variable struct{ name string }
+	member   struct {
+		object node
+		name   string
+	}
+	listExpr struct{ items []node }
+	call     struct {
+		name string
+		args []node
+		line int
+	}
+	unary struct {
+		op      string
+		operand node
+	}
+	binary struct {
+		op          string
+		left, right node
+		line        int
+	}
+	ifStmt struct {
+		cond      node
+		then, els []node
+	}
+	letStmt struct {
+		name  string
+		value node
+	}
+)
+
+// Script is a compiled hook script
+type Script struct {
+	body []node
+}
+
+// Compile parses a script and checks that it only calls known functions
+func Compile(src string) (*Script, error) {
+	if len(src) > MaxScriptBytes {
+		return nil, fmt.Errorf("script must be at most %d bytes", MaxScriptBytes)
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	var body []node
+	for p.peek().kind != tokEOF {
+		stmt, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, stmt)
+	}
+	return &Script{body: body}, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the given operator or keyword
+func (p *parser) accept(text string) bool {
+	t := p.peek()
+	if (t.kind == tokOp || t.kind == tokIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf("expected %q", text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	found := t.text
+	if t.kind == tokEOF {
+		found = "end of script"
+	}
+	return fmt.Errorf("line %d: %s, found %q", t.line, fmt.Sprintf(format, args...), found)
+}
+
+func (p *parser) statement() (node, error) {
+	defer p.accept(";")
+	switch {
+	case p.accept("if"):
+		return p.ifStatement()
+	case p.accept("let"):
+		name := p.next()
+		if name.kind != tokIdent || isKeyword(name.text) {
+			p.pos--
+			return nil, p.errorf("expected a name after let")
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		return &letStmt{name: name.text, value: value}, nil
+	}
+
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := expr.(*call); !ok {
+		return nil, fmt.Errorf("line %d: a statement must be a function call, if or let", p.tokens[max(p.pos-1, 0)].line)
+	}
+	return expr, nil
+}
+
+func (p *parser) ifStatement() (node, error) {
+	cond, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	then, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &ifStmt{cond: cond, then: then}
+	if p.accept("else") {
+		if p.accept("if") {
+			nested, err := p.ifStatement()
+			if err != nil {
+				return nil, err
+			}
+			stmt.els = []node{nested}
+		} else if stmt.els, err = p.block(); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+func (p *parser) block() ([]node, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var body []node
+	for !p.accept("}") {
+		if p.peek().kind == tokEOF {
+			return nil, p.errorf("expected %q", "}")
+		}
+		stmt, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, stmt)
+	}
+	return body, nil
+}
+
+func (p *parser) expression() (node, error) { return p.binaryLevel(0) }
+
+// precedence lists binary operators from loosest to tightest binding
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+}
+
+func (p *parser) binaryLevel(level int) (node, error) {
+	if level == len(precedence) {
+		return p.unaryExpr()
+	}
+	left, err := p.binaryLevel(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || !contains(precedence[level], t.text) {
+			return left, nil
+		}
+		p.next()
+		right, err := p.binaryLevel(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: t.text, left: left, right: right, line: t.line}
+	}
+}
+
+func (p *parser) unaryExpr() (node, error) {
+	if p.accept("!") {
+		operand, err := p.unaryExpr()
+		return &unary{op: "!", operand: operand}, err
+	}
+	if p.accept("-") {
+		operand, err := p.unaryExpr()
+		return &unary{op: "-", operand: operand}, err
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (node, error) {
+	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(".") {
+		name := p.next()
+		if name.kind != tokIdent {
+			p.pos--
+			return nil, p.errorf("expected a field name after '.'")
+		}
+		expr = &member{object: expr, name: name.text}
+	}
+	return expr, nil
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid number %q", t.line, t.text)
+		}
+		return &literal{n}, nil
+	case tokString:
+		return &literal{t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literal{true}, nil
+		case "false":
+			return &literal{false}, nil
+		case "null":
+			return &literal{nil}, nil
+		}
+		if isKeyword(t.text) {
+			p.pos--
+			return nil, p.errorf("unexpected keyword")
+		}
+		if p.accept("(") {
+			args, err := p.list(")")
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := functions[t.text]; !ok {
+				return nil, fmt.Errorf("line %d: unknown function %s", t.line, t.text)
+			}
+			return &call{name: t.text, args: args, line: t.line}, nil
+		}
+		return &variable{t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			expr, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			return expr, p.expect(")")
+		case "[":
+			items, err := p.list("]")
+			return &listExpr{items}, err
+		}
+	}
+	p.pos--
+	return nil, p.errorf("expected an expression")
+}
+
+// list parses comma-separated expressions up to the closing token
+func (p *parser) list(closing string) ([]node, error) {
+	var items []node
+	if p.accept(closing) {
+		return items, nil
+	}
+	for {
+		item, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.accept(closing) {
+			return items, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func isKeyword(s string) bool {
+	switch s {
+	case "if", "else", "let":
+		return true
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
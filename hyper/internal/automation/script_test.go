@@ -0,0 +1,90 @@
+package automation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runScript(t *testing.T, src string, vars map[string]interface{}) *Effects {
+	t.Helper()
+	script, err := Compile(src)
+	require.NoError(t, err)
+	effects, err := script.Run(vars)
+	require.NoError(t, err)
+	return effects
+}
+
+func TestScriptTagsAndDueDate(t *testing.T) {
+	src := `
+# Tag crash reports of the mobile project
+if task.project == "mobile" && contains(lower(task.prompt), "crash") {
+    tag("bug", ["mobile", "bug"])
+    due_in_days(2)
+} else {
+    log("skipped", task.id)
+}`
+	task := map[string]interface{}{"id": "t1", "project": "mobile", "prompt": "App CRASHES on start"}
+	effects := runScript(t, src, map[string]interface{}{"task": task})
+	assert.Equal(t, []string{"bug", "mobile"}, effects.Tags)
+	require.NotNil(t, effects.DueInDays)
+	assert.Equal(t, 2.0, *effects.DueInDays)
+	assert.Empty(t, effects.Logs)
+
+	task["project"] = "web"
+	effects = runScript(t, src, map[string]interface{}{"task": task})
+	assert.Empty(t, effects.Tags)
+	assert.Equal(t, []string{"skipped t1"}, effects.Logs)
+}
+
+func TestScriptExpressions(t *testing.T) {
+	vars := map[string]interface{}{
+		"task": map[string]interface{}{"files_modified": []interface{}{"go.mod", "main.go"}, "todo_count": 3.0},
+	}
+	effects := runScript(t, `
+let n = task.todo_count + 1
+if n >= 4 && !(n > 10) { tag("big") }
+if contains(task.files_modified, "go.mod") { assign("go-dev") }
+if matches("ENG-42", "^[A-Z]+-[0-9]+$") { tag("ticket") }
+if task.missing.field == null { tag("safe-" + len(task.files_modified)) }
+else if true { tag("never") }`, vars)
+	assert.Equal(t, []string{"big", "ticket", "safe-2"}, effects.Tags)
+	assert.Equal(t, "go-dev", effects.Assign)
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := map[string]string{
+		`tag("a"`:                             "line 1",
+		`explode()`:                           "unknown function explode",
+		`"just a string"`:                     "must be a function call",
+		"if x {\n tag('a')":                   "expected \"}\"",
+		`let if = 1`:                          "expected a name after let",
+		`tag("unterminated)`:                  "unterminated string",
+		`tag(1 @ 2)`:                          "unexpected character",
+		strings.Repeat("#", MaxScriptBytes+1): "at most",
+	}
+	for src, want := range tests {
+		_, err := Compile(src)
+		require.Error(t, err, src)
+		assert.Contains(t, err.Error(), want, src)
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	tests := map[string]string{
+		`due_in_days("soon")`: "non-negative number",
+		`assign("")`:          "cannot be empty",
+		`lower()`:             "takes 1 argument",
+		`if 1 - "a" { }`:      "cannot apply -",
+		`matches("a", "(")`:   "invalid pattern",
+	}
+	for src, want := range tests {
+		script, err := Compile(src)
+		require.NoError(t, err, src)
+		_, err = script.Run(nil)
+		require.Error(t, err, src)
+		assert.Contains(t, err.Error(), want, src)
+	}
+}
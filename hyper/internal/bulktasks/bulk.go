@@ -0,0 +1,325 @@
+// Package bulktasks applies one set of changes (status, tags, priority,
+// assignee) to many agent tasks at once, with a preview of what would change.
+package bulktasks
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"hyper/internal/escalation"
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// MaxTasks caps how many agent tasks one request may change
+const MaxTasks = 500
+
+// Changes are the task edits beyond status changes, implemented by
+// storage.MongoTaskStorage
+type Changes interface {
+	AddTaskTags(taskID string, tags []string) error
+	RemoveTaskTags(taskID string, tags []string) error
+	AssignAgentTask(taskID, agentName string) error
+	SetTaskPriority(taskID string, priority storage.TaskPriority) error
+}
+
+// PriorityRules re-applies priority inheritance after priorities change
+// (implemented by escalation.Engine)
+type PriorityRules interface {
+	Enabled() bool
+	Run() *escalation.Report
+}
+
+// Filter selects agent tasks by their fields; set fields must all match
+type Filter struct {
+	HumanTaskID string             `json:"humanTaskId,omitempty"`
+	AgentName   string             `json:"agentName,omitempty"`
+	Status      storage.TaskStatus `json:"status,omitempty"`
+	Tag         string             `json:"tag,omitempty"`
+}
+
+func (f *Filter) empty() bool {
+	return f == nil || (f.HumanTaskID == "" && f.AgentName == "" && f.Status == "" && f.Tag == "")
+}
+
+func (f *Filter) matches(task *storage.AgentTask) bool {
+	return (f.HumanTaskID == "" || task.HumanTaskID == f.HumanTaskID) &&
+		(f.AgentName == "" || task.AgentName == f.AgentName) &&
+		(f.Status == "" || task.Status == f.Status) &&
+		(f.Tag == "" || slices.Contains(task.Tags, f.Tag))
+}
+
+// Request selects agent tasks, by ID or by filter, and the changes to apply
+// to each of them
+type Request struct {
+	TaskIDs    []string           `json:"taskIds,omitempty"`
+	Filter     *Filter            `json:"filter,omitempty"`
+	Status     storage.TaskStatus `json:"status,omitempty"`
+	Notes      string             `json:"notes,omitempty"` // Recorded with a status change
+	AddTags    []string           `json:"addTags,omitempty"`
+	RemoveTags []string           `json:"removeTags,omitempty"`
+	Priority   *string            `json:"priority,omitempty"` // Empty clears the task's own priority
+	AssignTo   string             `json:"assignTo,omitempty"`
+	Preview    bool               `json:"preview,omitempty"` // Report what would change without changing it
+}
+
+// Change is one field of one task that changes
+type Change struct {
+	Field string      `json:"field"` // status, agentName, priority, tags
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// TaskResult reports the changes to one agent task
+type TaskResult struct {
+	TaskID      string   `json:"taskId"`
+	HumanTaskID string   `json:"humanTaskId,omitempty"`
+	AgentName   string   `json:"agentName,omitempty"`
+	Changes     []Change `json:"changes"` // Empty when the task already matches
+	Error       string   `json:"error,omitempty"`
+}
+
+// Result reports a bulk edit, or its preview
+type Result struct {
+	Preview       bool               `json:"preview"`
+	Matched       int                `json:"matched"`
+	Changed       int                `json:"changed"` // Tasks with at least one change
+	Unchanged     int                `json:"unchanged"`
+	Failed        int                `json:"failed"`
+	Tasks         []TaskResult       `json:"tasks"`
+	PriorityRules *escalation.Report `json:"priorityRules,omitempty"` // Inheritance applied after priorities changed
+}
+
+// Editor applies bulk edits to agent tasks
+type Editor struct {
+	tasks   storage.TaskStorage // Status changes go through it so sync and notification wrappers see them
+	changes Changes
+	rules   PriorityRules
+	logger  *zap.Logger
+}
+
+// NewEditor creates an editor. rules may be nil.
+func NewEditor(tasks storage.TaskStorage, changes Changes, rules PriorityRules, logger *zap.Logger) *Editor {
+	return &Editor{tasks: tasks, changes: changes, rules: rules, logger: logger}
+}
+
+// plan is a validated request
+type plan struct {
+	status     storage.TaskStatus
+	priority   *storage.TaskPriority
+	addTags    []string
+	removeTags []string
+}
+
+// Apply validates req and applies it to every selected task, or only reports
+// what would change when req.Preview is set. Validation problems are returned
+// as errors; failures of single tasks are reported in their TaskResult.
+func (e *Editor) Apply(req Request) (*Result, error) {
+	p, err := validate(req)
+	if err != nil {
+		return nil, err
+	}
+	tasks, missing, err := e.selectTasks(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Preview: req.Preview, Tasks: []TaskResult{}}
+	for _, taskID := range missing {
+		result.Failed++
+		result.Tasks = append(result.Tasks, TaskResult{TaskID: taskID, Changes: []Change{}, Error: "agent task not found"})
+	}
+
+	prioritiesChanged := false
+	for _, task := range tasks {
+		result.Matched++
+		item := TaskResult{TaskID: task.ID, HumanTaskID: task.HumanTaskID, AgentName: task.AgentName, Changes: diff(task, req, p)}
+		if len(item.Changes) == 0 {
+			result.Unchanged++
+			result.Tasks = append(result.Tasks, item)
+			continue
+		}
+		if !req.Preview {
+			if err := e.apply(task, item.Changes, req, p); err != nil {
+				item.Error = err.Error()
+				result.Failed++
+				result.Tasks = append(result.Tasks, item)
+				continue
+			}
+			prioritiesChanged = prioritiesChanged || slices.ContainsFunc(item.Changes, func(c Change) bool { return c.Field == "priority" })
+		}
+		result.Changed++
+		result.Tasks = append(result.Tasks, item)
+	}
+
+	if prioritiesChanged && e.rules != nil && e.rules.Enabled() {
+		result.PriorityRules = e.rules.Run()
+	}
+
+	if !req.Preview {
+		e.logger.Info("Bulk agent task edit applied",
+			zap.Int("matched", result.Matched),
+			zap.Int("changed", result.Changed),
+			zap.Int("failed", result.Failed))
+	}
+	return result, nil
+}
+
+// validate checks that req selects tasks and changes something
+func validate(req Request) (*plan, error) {
+	if len(req.TaskIDs) == 0 && req.Filter.empty() {
+		return nil, fmt.Errorf("select tasks with taskIds or a filter (humanTaskId, agentName, status or tag)")
+	}
+	if len(req.TaskIDs) > 0 && req.Filter != nil {
+		return nil, fmt.Errorf("taskIds and filter cannot be combined")
+	}
+	if len(req.TaskIDs) > MaxTasks {
+		return nil, fmt.Errorf("taskIds must not list more than %d tasks", MaxTasks)
+	}
+	if req.Filter != nil && req.Filter.Status != "" && !validStatus(req.Filter.Status) {
+		return nil, fmt.Errorf("invalid filter status %q: must be pending, in_progress, completed or blocked", req.Filter.Status)
+	}
+
+	p := &plan{status: req.Status, addTags: cleanTags(req.AddTags), removeTags: cleanTags(req.RemoveTags)}
+	if p.status != "" && !validStatus(p.status) {
+		return nil, fmt.Errorf("invalid status %q: must be pending, in_progress, completed or blocked", p.status)
+	}
+	if req.Priority != nil {
+		priority, err := storage.ParseTaskPriority(*req.Priority)
+		if err != nil {
+			return nil, err
+		}
+		p.priority = &priority
+	}
+	for _, tag := range p.addTags {
+		if slices.Contains(p.removeTags, tag) {
+			return nil, fmt.Errorf("tag %q cannot be both added and removed", tag)
+		}
+	}
+	if p.status == "" && p.priority == nil && len(p.addTags) == 0 && len(p.removeTags) == 0 && strings.TrimSpace(req.AssignTo) == "" {
+		return nil, fmt.Errorf("no changes requested: set status, addTags, removeTags, priority or assignTo")
+	}
+	return p, nil
+}
+
+func validStatus(status storage.TaskStatus) bool {
+	switch status {
+	case storage.TaskStatusPending, storage.TaskStatusInProgress, storage.TaskStatusCompleted, storage.TaskStatusBlocked:
+		return true
+	}
+	return false
+}
+
+// cleanTags trims tags and drops empty and repeated ones
+func cleanTags(tags []string) []string {
+	var cleaned []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(cleaned, tag) {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	return cleaned
+}
+
+// selectTasks returns the selected tasks, and the requested IDs that do not exist
+func (e *Editor) selectTasks(req Request) ([]*storage.AgentTask, []string, error) {
+	all := e.tasks.ListAllAgentTasks()
+
+	if len(req.TaskIDs) == 0 {
+		var selected []*storage.AgentTask
+		for _, task := range all {
+			if req.Filter.matches(task) {
+				selected = append(selected, task)
+			}
+		}
+		if len(selected) > MaxTasks {
+			return nil, nil, fmt.Errorf("filter matches %d tasks, more than the %d one request may change; narrow it down", len(selected), MaxTasks)
+		}
+		return selected, nil, nil
+	}
+
+	byID := make(map[string]*storage.AgentTask, len(all))
+	for _, task := range all {
+		byID[task.ID] = task
+	}
+	var selected []*storage.AgentTask
+	var missing []string
+	seen := make(map[string]bool)
+	for _, taskID := range req.TaskIDs {
+		taskID = strings.TrimSpace(taskID)
+		if taskID == "" || seen[taskID] {
+			continue
+		}
+		seen[taskID] = true
+		if task, ok := byID[taskID]; ok {
+			selected = append(selected, task)
+		} else {
+			missing = append(missing, taskID)
+		}
+	}
+	return selected, missing, nil
+}
+
+// diff lists the fields of task that the request changes
+func diff(task *storage.AgentTask, req Request, p *plan) []Change {
+	changes := []Change{}
+	if assignTo := strings.TrimSpace(req.AssignTo); assignTo != "" && assignTo != task.AgentName {
+		changes = append(changes, Change{Field: "agentName", From: task.AgentName, To: assignTo})
+	}
+
+	var added, removed []string
+	for _, tag := range p.addTags {
+		if !slices.Contains(task.Tags, tag) {
+			added = append(added, tag)
+		}
+	}
+	for _, tag := range p.removeTags {
+		if slices.Contains(task.Tags, tag) {
+			removed = append(removed, tag)
+		}
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		tags := []string{}
+		for _, tag := range task.Tags {
+			if !slices.Contains(removed, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		changes = append(changes, Change{Field: "tags", From: task.Tags, To: append(tags, added...)})
+	}
+
+	if p.priority != nil && *p.priority != task.Priority {
+		changes = append(changes, Change{Field: "priority", From: task.Priority, To: *p.priority})
+	}
+	if p.status != "" && p.status != task.Status {
+		changes = append(changes, Change{Field: "status", From: task.Status, To: p.status})
+	}
+	return changes
+}
+
+// apply makes the changes to one task. Status goes last, so hooks that run on
+// status changes see the new assignee, tags and priority.
+func (e *Editor) apply(task *storage.AgentTask, changes []Change, req Request, p *plan) error {
+	for _, change := range changes {
+		var err error
+		switch change.Field {
+		case "agentName":
+			err = e.changes.AssignAgentTask(task.ID, strings.TrimSpace(req.AssignTo))
+		case "tags":
+			if err = e.changes.AddTaskTags(task.ID, p.addTags); err == nil {
+				err = e.changes.RemoveTaskTags(task.ID, p.removeTags)
+			}
+		case "priority":
+			err = e.changes.SetTaskPriority(task.ID, *p.priority)
+		case "status":
+			err = e.tasks.UpdateTaskStatus(task.ID, p.status, req.Notes)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to change %s: %w", change.Field, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package bulktasks
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"hyper/internal/escalation"
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryTasks keeps agent tasks in memory and records the order of edits
+type memoryTasks struct {
+	storage.TaskStorage
+	agents []*storage.AgentTask
+	edits  []string
+}
+
+func (m *memoryTasks) agent(id string) *storage.AgentTask {
+	for _, task := range m.agents {
+		if task.ID == id {
+			return task
+		}
+	}
+	return nil
+}
+
+func (m *memoryTasks) ListAllAgentTasks() []*storage.AgentTask {
+	return m.agents
+}
+
+func (m *memoryTasks) UpdateTaskStatus(taskID string, status storage.TaskStatus, notes string) error {
+	if taskID == "a-broken" {
+		return errors.New("mongo unavailable")
+	}
+	m.agent(taskID).Status = status
+	m.edits = append(m.edits, "status:"+taskID)
+	return nil
+}
+
+func (m *memoryTasks) AddTaskTags(taskID string, tags []string) error {
+	task := m.agent(taskID)
+	for _, tag := range tags {
+		if !slices.Contains(task.Tags, tag) {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+	m.edits = append(m.edits, "tags:"+taskID)
+	return nil
+}
+
+func (m *memoryTasks) RemoveTaskTags(taskID string, tags []string) error {
+	task := m.agent(taskID)
+	task.Tags = slices.DeleteFunc(task.Tags, func(tag string) bool { return slices.Contains(tags, tag) })
+	return nil
+}
+
+func (m *memoryTasks) AssignAgentTask(taskID, agentName string) error {
+	m.agent(taskID).AgentName = agentName
+	m.edits = append(m.edits, "assign:"+taskID)
+	return nil
+}
+
+func (m *memoryTasks) SetTaskPriority(taskID string, priority storage.TaskPriority) error {
+	m.agent(taskID).Priority = priority
+	m.edits = append(m.edits, "priority:"+taskID)
+	return nil
+}
+
+// countingRules counts how often the priority rules run
+type countingRules struct {
+	runs int
+}
+
+func (r *countingRules) Enabled() bool { return true }
+
+func (r *countingRules) Run() *escalation.Report {
+	r.runs++
+	return &escalation.Report{}
+}
+
+func newTestEditor() (*Editor, *memoryTasks, *countingRules) {
+	tasks := &memoryTasks{agents: []*storage.AgentTask{
+		{ID: "a-1", HumanTaskID: "h-1", AgentName: "backend", Status: storage.TaskStatusBlocked, Tags: []string{"flaky"}},
+		{ID: "a-2", HumanTaskID: "h-1", AgentName: "backend", Status: storage.TaskStatusInProgress},
+		{ID: "a-3", HumanTaskID: "h-1", AgentName: "frontend", Status: storage.TaskStatusBlocked},
+		{ID: "a-4", HumanTaskID: "h-2", AgentName: "backend", Status: storage.TaskStatusBlocked},
+	}}
+	rules := &countingRules{}
+	return NewEditor(tasks, tasks, rules, zap.NewNop()), tasks, rules
+}
+
+func priority(p string) *string { return &p }
+
+func TestApply_Preview(t *testing.T) {
+	editor, tasks, _ := newTestEditor()
+
+	result, err := editor.Apply(Request{
+		Filter:     &Filter{HumanTaskID: "h-1", Status: storage.TaskStatusBlocked},
+		Status:     storage.TaskStatusPending,
+		AddTags:    []string{"retry"},
+		RemoveTags: []string{"flaky"},
+		Preview:    true,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Preview)
+	assert.Equal(t, 2, result.Matched)
+	assert.Equal(t, 2, result.Changed)
+	assert.Equal(t, []Change{
+		{Field: "tags", From: []string{"flaky"}, To: []string{"retry"}},
+		{Field: "status", From: storage.TaskStatusBlocked, To: storage.TaskStatusPending},
+	}, result.Tasks[0].Changes)
+
+	assert.Empty(t, tasks.edits, "previews change nothing")
+	assert.Equal(t, storage.TaskStatusBlocked, tasks.agent("a-1").Status)
+}
+
+func TestApply(t *testing.T) {
+	editor, tasks, rules := newTestEditor()
+
+	result, err := editor.Apply(Request{
+		TaskIDs:  []string{"a-1", "a-2", "missing", "a-1"},
+		Status:   storage.TaskStatusPending,
+		AssignTo: "backend",
+		Priority: priority("high"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Matched)
+	assert.Equal(t, 2, result.Changed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, "missing", result.Tasks[0].TaskID)
+
+	assert.Equal(t, storage.TaskStatusPending, tasks.agent("a-2").Status)
+	assert.Equal(t, storage.PriorityHigh, tasks.agent("a-1").Priority)
+	assert.Equal(t, []string{"priority:a-1", "status:a-1", "priority:a-2", "status:a-2"}, tasks.edits, "status changes go last")
+	assert.Equal(t, 1, rules.runs, "priority rules run once")
+	assert.NotNil(t, result.PriorityRules)
+
+	// Applying again changes nothing
+	result, err = editor.Apply(Request{TaskIDs: []string{"a-1", "a-2"}, Status: storage.TaskStatusPending})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Unchanged)
+	assert.Equal(t, 0, result.Changed)
+}
+
+func TestApply_Reassign(t *testing.T) {
+	editor, tasks, rules := newTestEditor()
+
+	result, err := editor.Apply(Request{Filter: &Filter{AgentName: "backend", Status: storage.TaskStatusBlocked}, AssignTo: "backend-v2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Changed)
+	assert.Equal(t, "backend-v2", tasks.agent("a-4").AgentName)
+	assert.Equal(t, "backend", tasks.agent("a-2").AgentName)
+	assert.Zero(t, rules.runs)
+}
+
+func TestApply_TaskFailure(t *testing.T) {
+	editor, tasks, _ := newTestEditor()
+	tasks.agents = append(tasks.agents, &storage.AgentTask{ID: "a-broken", Status: storage.TaskStatusBlocked})
+
+	result, err := editor.Apply(Request{TaskIDs: []string{"a-broken", "a-3"}, Status: storage.TaskStatusPending})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.Changed)
+	assert.Contains(t, result.Tasks[0].Error, "failed to change status")
+	assert.Equal(t, storage.TaskStatusPending, tasks.agent("a-3").Status, "other tasks are still changed")
+}
+
+func TestApply_Validation(t *testing.T) {
+	editor, _, _ := newTestEditor()
+
+	for name, req := range map[string]Request{
+		"no selection":     {Status: storage.TaskStatusPending},
+		"empty filter":     {Filter: &Filter{}, Status: storage.TaskStatusPending},
+		"ids and filter":   {TaskIDs: []string{"a-1"}, Filter: &Filter{AgentName: "backend"}, Status: storage.TaskStatusPending},
+		"no changes":       {TaskIDs: []string{"a-1"}},
+		"bad status":       {TaskIDs: []string{"a-1"}, Status: "done"},
+		"bad priority":     {TaskIDs: []string{"a-1"}, Priority: priority("urgent")},
+		"add and remove":   {TaskIDs: []string{"a-1"}, AddTags: []string{"x"}, RemoveTags: []string{"x"}},
+		"bad filter state": {Filter: &Filter{Status: "done"}, Status: storage.TaskStatusPending},
+	} {
+		_, err := editor.Apply(req)
+		assert.Error(t, err, name)
+	}
+}
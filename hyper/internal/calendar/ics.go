@@ -0,0 +1,191 @@
+// Package calendar renders task due dates as an iCalendar (RFC 5545) feed
+// that Google Calendar, Outlook and other calendar apps can subscribe to.
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/storage"
+)
+
+const (
+	// prodID identifies the feed's producer
+	prodID = "-//Hyperion//Coordinator//EN"
+	// eventDuration is the length of the event shown for a due date
+	eventDuration = 30 * time.Minute
+	// maxLineOctets is the longest content line before folding
+	maxLineOctets = 75
+	// maxDescriptionChars keeps event descriptions readable
+	maxDescriptionChars = 2000
+)
+
+// Event is one calendar entry
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	Categories  []string
+	Modified    time.Time
+}
+
+// Calendar is a named list of events
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// TaskEvents builds an event for every human and agent task with a due date.
+// A non-empty project limits the feed to that project's tasks.
+func TaskEvents(tasks storage.TaskStorage, project string) []Event {
+	humanTasks := make(map[string]*storage.HumanTask)
+	var events []Event
+
+	for _, task := range tasks.ListAllHumanTasks() {
+		humanTasks[task.ID] = task
+		if task.DueAt == nil || (project != "" && task.Project != project) {
+			continue
+		}
+		events = append(events, dueEvent(task.ID, task.Status, firstLine(task.Prompt), task.Prompt, *task.DueAt, task.UpdatedAt, task.Project, ""))
+	}
+
+	for _, task := range tasks.ListAllAgentTasks() {
+		if task.DueAt == nil {
+			continue
+		}
+		parent := humanTasks[task.HumanTaskID]
+		taskProject := ""
+		if parent != nil {
+			taskProject = parent.Project
+		}
+		if project != "" && taskProject != project {
+			continue
+		}
+		summary := fmt.Sprintf("%s: %s", task.AgentName, firstLine(task.Role))
+		events = append(events, dueEvent(task.ID, task.Status, summary, task.Role, *task.DueAt, task.UpdatedAt, taskProject, task.AgentName))
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events
+}
+
+// dueEvent builds the event of a task due date
+func dueEvent(taskID string, status storage.TaskStatus, summary, description string, dueAt, updatedAt time.Time, project, agent string) Event {
+	prefix := "Due"
+	if status == storage.TaskStatusCompleted {
+		prefix = "Done"
+	}
+
+	details := []string{fmt.Sprintf("Task: %s", taskID), fmt.Sprintf("Status: %s", status)}
+	if agent != "" {
+		details = append(details, fmt.Sprintf("Agent: %s", agent))
+	}
+	if project != "" {
+		details = append(details, fmt.Sprintf("Project: %s", project))
+	}
+	runes := []rune(strings.TrimSpace(description))
+	if len(runes) > maxDescriptionChars {
+		runes = append(runes[:maxDescriptionChars], []rune("...")...)
+	}
+
+	var categories []string
+	if project != "" {
+		categories = []string{project}
+	}
+
+	return Event{
+		UID:         taskID + "-due@hyperion",
+		Summary:     fmt.Sprintf("%s: %s", prefix, summary),
+		Description: strings.Join(details, "\n") + "\n\n" + string(runes),
+		Start:       dueAt,
+		End:         dueAt.Add(eventDuration),
+		Categories:  categories,
+		Modified:    updatedAt,
+	}
+}
+
+// ICS renders the calendar; now is used as the DTSTAMP of every event
+func (c *Calendar) ICS(now time.Time) string {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+prodID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	writeLine(&b, "X-WR-CALNAME:"+escapeText(c.Name))
+
+	for _, event := range c.Events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+event.UID)
+		writeLine(&b, "DTSTAMP:"+formatTime(now))
+		writeLine(&b, "DTSTART:"+formatTime(event.Start))
+		writeLine(&b, "DTEND:"+formatTime(event.End))
+		if !event.Modified.IsZero() {
+			writeLine(&b, "LAST-MODIFIED:"+formatTime(event.Modified))
+		}
+		writeLine(&b, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if len(event.Categories) > 0 {
+			escaped := make([]string, len(event.Categories))
+			for i, category := range event.Categories {
+				escaped[i] = escapeText(category)
+			}
+			writeLine(&b, "CATEGORIES:"+strings.Join(escaped, ","))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText escapes a TEXT value (RFC 5545 section 3.3.11)
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// writeLine writes a content line, folded at 75 octets without splitting a
+// UTF-8 character (RFC 5545 section 3.1)
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1 // The leading space counts
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+// firstLine returns the first non-empty line of s
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	runes := []rune(s)
+	if len(runes) > 120 {
+		return string(runes[:120]) + "..."
+	}
+	return s
+}
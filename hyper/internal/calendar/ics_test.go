@@ -0,0 +1,75 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTasks lists fixed tasks
+type memoryTasks struct {
+	storage.TaskStorage
+	human []*storage.HumanTask
+	agent []*storage.AgentTask
+}
+
+func (m *memoryTasks) ListAllHumanTasks() []*storage.HumanTask { return m.human }
+func (m *memoryTasks) ListAllAgentTasks() []*storage.AgentTask { return m.agent }
+
+func at(s string) *time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return &t
+}
+
+func TestTaskEvents(t *testing.T) {
+	tasks := &memoryTasks{
+		human: []*storage.HumanTask{
+			{ID: "h-1", Prompt: "Launch billing\nwith invoices", Project: "platform", Status: storage.TaskStatusInProgress, DueAt: at("2026-03-02T17:00:00Z")},
+			{ID: "h-2", Prompt: "No deadline", Project: "platform"},
+			{ID: "h-3", Prompt: "Mobile release", Project: "mobile", Status: storage.TaskStatusCompleted, DueAt: at("2026-03-01T09:00:00Z")},
+		},
+		agent: []*storage.AgentTask{
+			{ID: "a-1", HumanTaskID: "h-1", AgentName: "go-dev", Role: "Invoice API", DueAt: at("2026-03-01T12:00:00Z")},
+		},
+	}
+
+	events := TaskEvents(tasks, "")
+	require.Len(t, events, 3)
+	assert.Equal(t, "Done: Mobile release", events[0].Summary, "sorted by due date")
+	assert.Equal(t, "Due: go-dev: Invoice API", events[1].Summary)
+	assert.Equal(t, []string{"platform"}, events[1].Categories, "agent tasks take their human task's project")
+	assert.Equal(t, "Due: Launch billing", events[2].Summary)
+	assert.Equal(t, 30*time.Minute, events[2].End.Sub(events[2].Start))
+
+	events = TaskEvents(tasks, "platform")
+	require.Len(t, events, 2)
+	assert.Equal(t, "a-1-due@hyperion", events[0].UID)
+}
+
+func TestICS(t *testing.T) {
+	now := time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC)
+	cal := &Calendar{Name: "Hyperion tasks", Events: []Event{{
+		UID:         "h-1-due@hyperion",
+		Summary:     "Due: Ship v2; then, celebrate",
+		Description: strings.Repeat("é", 60) + "\nnext line",
+		Start:       *at("2026-03-02T17:00:00Z"),
+		End:         *at("2026-03-02T17:30:00Z"),
+	}}}
+
+	ics := cal.ICS(now)
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	assert.Contains(t, ics, "DTSTAMP:20260201T080000Z\r\nDTSTART:20260302T170000Z\r\nDTEND:20260302T173000Z\r\n")
+	assert.Contains(t, ics, `SUMMARY:Due: Ship v2\; then\, celebrate`)
+
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets, "line %q is not folded", line)
+	}
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	assert.Contains(t, unfolded, "DESCRIPTION:"+strings.Repeat("é", 60)+`\nnext line`)
+}
@@ -0,0 +1,369 @@
+// Package capacity estimates when projects complete given the open agent
+// tasks, how many tasks each agent works on at once and how long agents have
+// taken per task, and points out the agents that hold projects back.
+package capacity
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/taskgraph"
+)
+
+// DefaultCycleTime is assumed per task when no agent completed a task within
+// the history window
+const DefaultCycleTime = 4 * time.Hour
+
+// DefaultHistory is how far back completed tasks give cycle times
+const DefaultHistory = 30 * 24 * time.Hour
+
+// Options are the what-if inputs of a plan
+type Options struct {
+	Concurrency      int            // Tasks each agent works on at once; 0 means 1
+	AgentConcurrency map[string]int // Per-agent overrides of Concurrency
+	History          time.Duration  // 0 means DefaultHistory
+	Project          string         // Only report this project; other tasks still occupy agents
+	Now              time.Time      // Zero means time.Now
+}
+
+// AgentPlan is one agent's share of the plan
+type AgentPlan struct {
+	AgentName      string    `json:"agentName"`
+	Concurrency    int       `json:"concurrency"`
+	OpenTasks      int       `json:"openTasks"` // Pending, in progress and blocked
+	InProgress     int       `json:"inProgress"`
+	Blocked        int       `json:"blocked"`
+	CycleTimeHours float64   `json:"cycleTimeHours"` // Median start-to-completion time
+	Samples        int       `json:"samples"`        // Completed tasks behind it; 0 when assumed
+	BacklogHours   float64   `json:"backlogHours"`   // Queued work divided by concurrency
+	FreeAt         time.Time `json:"freeAt"`         // When its last open task is done
+	Bottleneck     bool      `json:"bottleneck"`
+	BottleneckFor  []string  `json:"bottleneckFor,omitempty"` // Projects whose completion it sets
+}
+
+// ProjectPlan is the estimated completion of one project. Tasks without a
+// project are planned under an empty name.
+type ProjectPlan struct {
+	Project        string     `json:"project"`
+	HumanTasks     int        `json:"humanTasks"` // Open human tasks
+	OpenAgentTasks int        `json:"openAgentTasks"`
+	Unplanned      int        `json:"unplannedHumanTasks"`   // Open human tasks without open agent tasks
+	CompletesAt    *time.Time `json:"completesAt,omitempty"` // Nil when no agent task is open
+	DueAt          *time.Time `json:"dueAt,omitempty"`       // Latest due date of its open human tasks
+	Late           bool       `json:"late"`                  // Completes after DueAt
+	FinishingAgent string     `json:"finishingAgent,omitempty"`
+}
+
+// Plan is the estimate for the whole backlog
+type Plan struct {
+	GeneratedAt           time.Time     `json:"generatedAt"`
+	HistoryDays           int           `json:"historyDays"`
+	DefaultCycleTimeHours float64       `json:"defaultCycleTimeHours"` // For agents without completed tasks
+	Projects              []ProjectPlan `json:"projects"`
+	Agents                []AgentPlan   `json:"agents"`
+	Bottlenecks           []string      `json:"bottlenecks"` // Agents finishing a project last, latest first
+	Warnings              []string      `json:"warnings,omitempty"`
+}
+
+// job is an open agent task being scheduled
+type job struct {
+	task     *storage.AgentTask
+	duration time.Duration
+	deps     []*job
+	end      time.Time
+	planned  bool
+}
+
+// Build plans the open agent tasks. Each agent works on its tasks in order:
+// in-progress tasks first, then by priority and age. A task starts once its
+// agent has a free slot and the tasks it depends on are done. In-progress
+// tasks take what is left of a cycle time.
+func Build(humans []*storage.HumanTask, agents []*storage.AgentTask, opts Options) *Plan {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.UTC()
+	history := opts.History
+	if history <= 0 {
+		history = DefaultHistory
+	}
+
+	humanByID := make(map[string]*storage.HumanTask, len(humans))
+	for _, human := range humans {
+		humanByID[human.ID] = human
+	}
+
+	cycleTimes, overall := cycleTimes(agents, now.Add(-history))
+	plan := &Plan{
+		GeneratedAt:           now,
+		HistoryDays:           int(history / (24 * time.Hour)),
+		DefaultCycleTimeHours: overall.Hours(),
+		Projects:              []ProjectPlan{},
+		Agents:                []AgentPlan{},
+		Bottlenecks:           []string{},
+	}
+	if overall == 0 {
+		overall = DefaultCycleTime
+		plan.DefaultCycleTimeHours = overall.Hours()
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("No agent task was completed in the last %d days; assuming %s per task", plan.HistoryDays, DefaultCycleTime))
+	}
+
+	// Open tasks, in the order agents pick them up
+	var jobs []*job
+	agentPlans := make(map[string]*AgentPlan)
+	for _, task := range agents {
+		if task.Status == storage.TaskStatusCompleted {
+			continue
+		}
+		agent := agentPlans[task.AgentName]
+		if agent == nil {
+			agent = &AgentPlan{AgentName: task.AgentName, Concurrency: concurrency(opts, task.AgentName)}
+			if samples := cycleTimes[task.AgentName]; len(samples) > 0 {
+				agent.CycleTimeHours = median(samples).Hours()
+				agent.Samples = len(samples)
+			} else {
+				agent.CycleTimeHours = overall.Hours()
+			}
+			agentPlans[task.AgentName] = agent
+		}
+		agent.OpenTasks++
+
+		cycle := time.Duration(agent.CycleTimeHours * float64(time.Hour))
+		duration := cycle
+		switch task.Status {
+		case storage.TaskStatusInProgress:
+			agent.InProgress++
+			duration = max(cycle-now.Sub(startedAt(task)), 0)
+		case storage.TaskStatusBlocked:
+			agent.Blocked++
+		}
+		agent.BacklogHours += duration.Hours() / float64(agent.Concurrency)
+		jobs = append(jobs, &job{task: task, duration: duration})
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		a, b := jobs[i].task, jobs[j].task
+		if started := a.Status == storage.TaskStatusInProgress; started != (b.Status == storage.TaskStatusInProgress) {
+			return started
+		}
+		pa, pb := storage.AgentTaskPriority(a, humanByID[a.HumanTaskID]), storage.AgentTaskPriority(b, humanByID[b.HumanTaskID])
+		if pa.Rank() != pb.Rank() {
+			return pa.Rank() > pb.Rank()
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	for _, dependent := range jobs {
+		for _, dependency := range jobs {
+			if dependent != dependency && taskgraph.DependsOn(dependent.task, dependency.task) {
+				dependent.deps = append(dependent.deps, dependency)
+			}
+		}
+	}
+
+	schedule(jobs, agentPlans, now)
+
+	// Projects complete when their last open agent task does
+	projects := make(map[string]*ProjectPlan)
+	project := func(name string) *ProjectPlan {
+		if projects[name] == nil {
+			projects[name] = &ProjectPlan{Project: name}
+		}
+		return projects[name]
+	}
+	openAgentTasks := make(map[string]int)
+	for _, j := range jobs {
+		openAgentTasks[j.task.HumanTaskID]++
+		human := humanByID[j.task.HumanTaskID]
+		if human == nil {
+			continue
+		}
+		p := project(human.Project)
+		p.OpenAgentTasks++
+		if p.CompletesAt == nil || j.end.After(*p.CompletesAt) {
+			end := j.end
+			p.CompletesAt = &end
+			p.FinishingAgent = j.task.AgentName
+		}
+		if agent := agentPlans[j.task.AgentName]; j.end.After(agent.FreeAt) {
+			agent.FreeAt = j.end
+		}
+	}
+	for _, agent := range agentPlans {
+		if agent.FreeAt.IsZero() {
+			agent.FreeAt = now
+		}
+	}
+	for _, human := range humans {
+		if human.Status == storage.TaskStatusCompleted {
+			continue
+		}
+		p := project(human.Project)
+		p.HumanTasks++
+		if openAgentTasks[human.ID] == 0 {
+			p.Unplanned++
+		}
+		if human.DueAt != nil && (p.DueAt == nil || human.DueAt.After(*p.DueAt)) {
+			due := human.DueAt.UTC()
+			p.DueAt = &due
+		}
+	}
+
+	for _, p := range projects {
+		if opts.Project != "" && p.Project != opts.Project {
+			continue
+		}
+		p.Late = p.CompletesAt != nil && p.DueAt != nil && p.CompletesAt.After(*p.DueAt)
+		if p.FinishingAgent != "" {
+			agent := agentPlans[p.FinishingAgent]
+			agent.Bottleneck = true
+			agent.BottleneckFor = append(agent.BottleneckFor, p.Project)
+		}
+		plan.Projects = append(plan.Projects, *p)
+	}
+	sort.Slice(plan.Projects, func(i, j int) bool { return plan.Projects[i].Project < plan.Projects[j].Project })
+
+	for _, agent := range agentPlans {
+		sort.Strings(agent.BottleneckFor)
+		plan.Agents = append(plan.Agents, *agent)
+	}
+	// Latest to be free first, so the agents holding work back lead
+	sort.Slice(plan.Agents, func(i, j int) bool {
+		if !plan.Agents[i].FreeAt.Equal(plan.Agents[j].FreeAt) {
+			return plan.Agents[i].FreeAt.After(plan.Agents[j].FreeAt)
+		}
+		return plan.Agents[i].AgentName < plan.Agents[j].AgentName
+	})
+	for _, agent := range plan.Agents {
+		if agent.Bottleneck {
+			plan.Bottlenecks = append(plan.Bottlenecks, agent.AgentName)
+		}
+	}
+	return plan
+}
+
+// schedule sets the end of every job. It repeatedly takes the first job whose
+// dependencies are planned, or the first one left when dependencies form a
+// cycle, and starts it on its agent's earliest free slot.
+func schedule(jobs []*job, agents map[string]*AgentPlan, now time.Time) {
+	slots := make(map[string][]time.Time)
+	for name, agent := range agents {
+		slots[name] = make([]time.Time, agent.Concurrency)
+		for i := range slots[name] {
+			slots[name][i] = now
+		}
+	}
+
+	for range jobs {
+		var next *job
+		for _, j := range jobs {
+			if j.planned {
+				continue
+			}
+			if next == nil {
+				next = j
+			}
+			if ready(j) {
+				next = j
+				break
+			}
+		}
+
+		start := now
+		for _, dep := range next.deps {
+			if dep.planned && dep.end.After(start) {
+				start = dep.end
+			}
+		}
+		agentSlots := slots[next.task.AgentName]
+		slot := 0
+		for i := range agentSlots {
+			if agentSlots[i].Before(agentSlots[slot]) {
+				slot = i
+			}
+		}
+		if agentSlots[slot].After(start) {
+			start = agentSlots[slot]
+		}
+		next.end = start.Add(next.duration)
+		next.planned = true
+		agentSlots[slot] = next.end
+	}
+}
+
+// ready reports whether every dependency of j is planned
+func ready(j *job) bool {
+	for _, dep := range j.deps {
+		if !dep.planned {
+			return false
+		}
+	}
+	return true
+}
+
+// concurrency is how many tasks an agent works on at once
+func concurrency(opts Options, agentName string) int {
+	if n := opts.AgentConcurrency[agentName]; n > 0 {
+		return n
+	}
+	if opts.Concurrency > 0 {
+		return opts.Concurrency
+	}
+	return 1
+}
+
+// cycleTimes returns the start-to-completion times of tasks completed since
+// from, per agent, and their median across all agents (0 without any)
+func cycleTimes(agents []*storage.AgentTask, from time.Time) (map[string][]time.Duration, time.Duration) {
+	byAgent := make(map[string][]time.Duration)
+	var all []time.Duration
+	for _, task := range agents {
+		if task.Status != storage.TaskStatusCompleted {
+			continue
+		}
+		completed := completedAt(task)
+		if completed.Before(from) {
+			continue
+		}
+		cycle := max(completed.Sub(startedAt(task)), 0)
+		byAgent[task.AgentName] = append(byAgent[task.AgentName], cycle)
+		all = append(all, cycle)
+	}
+	if len(all) == 0 {
+		return byAgent, 0
+	}
+	return byAgent, median(all)
+}
+
+// startedAt is when the task first moved to in_progress, or its creation when
+// its activity log does not say
+func startedAt(task *storage.AgentTask) time.Time {
+	for _, entry := range task.Activity {
+		if entry.Action == storage.ActivityStatusChanged && entry.Status == string(storage.TaskStatusInProgress) {
+			return entry.At
+		}
+	}
+	return task.CreatedAt
+}
+
+// completedAt is when the task last moved to completed, or its last update
+func completedAt(task *storage.AgentTask) time.Time {
+	for i := len(task.Activity) - 1; i >= 0; i-- {
+		entry := task.Activity[i]
+		if entry.Action == storage.ActivityStatusChanged && entry.Status == string(storage.TaskStatusCompleted) {
+			return entry.At
+		}
+	}
+	return task.UpdatedAt
+}
+
+// median of durations; samples is reordered
+func median(samples []time.Duration) time.Duration {
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	mid := len(samples) / 2
+	if len(samples)%2 == 1 {
+		return samples[mid]
+	}
+	return (samples[mid-1] + samples[mid]) / 2
+}
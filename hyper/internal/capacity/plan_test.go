@@ -0,0 +1,144 @@
+package capacity
+
+import (
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+// completed is an agent task that took cycle from starting to completing
+func completed(id, agent string, cycle time.Duration) *storage.AgentTask {
+	done := now.Add(-24 * time.Hour)
+	return &storage.AgentTask{
+		ID: id, AgentName: agent, HumanTaskID: "h-done", Status: storage.TaskStatusCompleted,
+		CreatedAt: done.Add(-cycle - 48*time.Hour), UpdatedAt: done,
+		Activity: []storage.TaskActivity{
+			{At: done.Add(-cycle), Action: storage.ActivityStatusChanged, Status: string(storage.TaskStatusInProgress)},
+			{At: done, Action: storage.ActivityStatusChanged, Status: string(storage.TaskStatusCompleted)},
+		},
+	}
+}
+
+func open(id, agent, human string, status storage.TaskStatus, age time.Duration) *storage.AgentTask {
+	return &storage.AgentTask{ID: id, AgentName: agent, HumanTaskID: human, Status: status, CreatedAt: now.Add(-age)}
+}
+
+func backlog() ([]*storage.HumanTask, []*storage.AgentTask) {
+	due := now.Add(6 * time.Hour)
+	humans := []*storage.HumanTask{
+		{ID: "h-done", Project: "web", Status: storage.TaskStatusCompleted},
+		{ID: "h-web", Project: "web", Status: storage.TaskStatusInProgress, DueAt: &due},
+		{ID: "h-api", Project: "api", Status: storage.TaskStatusPending},
+		{ID: "h-idle", Project: "api", Status: storage.TaskStatusPending},
+	}
+	agents := []*storage.AgentTask{
+		completed("c-1", "backend", 2*time.Hour),
+		completed("c-2", "backend", 4*time.Hour),
+		completed("c-3", "backend", 3*time.Hour),
+		completed("c-4", "frontend", time.Hour),
+		open("b-1", "backend", "h-api", storage.TaskStatusPending, 3*time.Hour),
+		open("b-2", "backend", "h-web", storage.TaskStatusPending, 2*time.Hour),
+		open("b-3", "backend", "h-api", storage.TaskStatusPending, time.Hour),
+		open("f-1", "frontend", "h-web", storage.TaskStatusBlocked, time.Hour),
+		open("d-1", "docs", "h-web", storage.TaskStatusPending, time.Hour),
+	}
+	// The frontend task waits for the backend's web task
+	agents[7].Notes = "Blocked on b-2 for the new endpoint"
+	return humans, agents
+}
+
+func projectPlan(t *testing.T, plan *Plan, name string) ProjectPlan {
+	t.Helper()
+	for _, p := range plan.Projects {
+		if p.Project == name {
+			return p
+		}
+	}
+	require.Failf(t, "project not planned", name)
+	return ProjectPlan{}
+}
+
+func TestBuild(t *testing.T) {
+	humans, agents := backlog()
+	plan := Build(humans, agents, Options{Now: now})
+
+	assert.Empty(t, plan.Warnings)
+	assert.Equal(t, 30, plan.HistoryDays)
+	assert.Equal(t, 2.5, plan.DefaultCycleTimeHours, "median of all completed tasks")
+
+	require.Len(t, plan.Agents, 3)
+	backend := plan.Agents[0]
+	assert.Equal(t, "backend", backend.AgentName, "latest to be free first")
+	assert.Equal(t, 3.0, backend.CycleTimeHours)
+	assert.Equal(t, 3, backend.Samples)
+	assert.Equal(t, 3, backend.OpenTasks)
+	assert.Equal(t, 9.0, backend.BacklogHours)
+	assert.Equal(t, now.Add(9*time.Hour), backend.FreeAt)
+	assert.True(t, backend.Bottleneck)
+
+	docs := plan.Agents[2]
+	assert.Equal(t, "docs", docs.AgentName)
+	assert.Equal(t, 0, docs.Samples)
+	assert.Equal(t, 2.5, docs.CycleTimeHours, "agents without history take the overall median")
+
+	// Backend works oldest first: b-1 (0-3h), b-2 (3-6h), b-3 (6-9h). The
+	// frontend task waits for b-2 and then takes 1h.
+	web := projectPlan(t, plan, "web")
+	require.NotNil(t, web.CompletesAt)
+	assert.Equal(t, now.Add(7*time.Hour), *web.CompletesAt)
+	assert.Equal(t, "frontend", web.FinishingAgent)
+	assert.True(t, web.Late, "due in 6h")
+	assert.Equal(t, 1, web.HumanTasks)
+	assert.Equal(t, 3, web.OpenAgentTasks)
+
+	api := projectPlan(t, plan, "api")
+	assert.Equal(t, now.Add(9*time.Hour), *api.CompletesAt)
+	assert.Equal(t, "backend", api.FinishingAgent)
+	assert.Equal(t, 1, api.Unplanned)
+	assert.False(t, api.Late)
+
+	assert.Equal(t, []string{"backend", "frontend"}, plan.Bottlenecks)
+	assert.Equal(t, []string{"api"}, backend.BottleneckFor)
+}
+
+func TestBuild_WhatIf(t *testing.T) {
+	humans, agents := backlog()
+
+	// A second backend slot runs b-1 and b-2 side by side
+	plan := Build(humans, agents, Options{Now: now, AgentConcurrency: map[string]int{"backend": 2}})
+	assert.Equal(t, 2, plan.Agents[0].Concurrency)
+	assert.Equal(t, now.Add(4*time.Hour), *projectPlan(t, plan, "web").CompletesAt)
+	assert.False(t, projectPlan(t, plan, "web").Late)
+	assert.Equal(t, now.Add(6*time.Hour), *projectPlan(t, plan, "api").CompletesAt)
+
+	// Priority jumps the queue
+	humans[1].Priority = storage.PriorityHigh
+	plan = Build(humans, agents, Options{Now: now})
+	assert.Equal(t, now.Add(4*time.Hour), *projectPlan(t, plan, "web").CompletesAt)
+
+	plan = Build(humans, agents, Options{Now: now, Project: "api"})
+	require.Len(t, plan.Projects, 1)
+	assert.Equal(t, "api", plan.Projects[0].Project)
+}
+
+func TestBuild_InProgressAndNoHistory(t *testing.T) {
+	humans := []*storage.HumanTask{{ID: "h-1", Status: storage.TaskStatusInProgress}}
+	started := open("a-1", "backend", "h-1", storage.TaskStatusInProgress, 5*time.Hour)
+	started.Activity = []storage.TaskActivity{{At: now.Add(-time.Hour), Action: storage.ActivityStatusChanged, Status: string(storage.TaskStatusInProgress)}}
+	agents := []*storage.AgentTask{open("a-2", "backend", "h-1", storage.TaskStatusPending, 6*time.Hour), started}
+
+	plan := Build(humans, agents, Options{Now: now})
+	require.Len(t, plan.Warnings, 1)
+	assert.Equal(t, DefaultCycleTime.Hours(), plan.DefaultCycleTimeHours)
+
+	// The started task has 3h of 4h left, then the older pending task runs
+	p := projectPlan(t, plan, "")
+	assert.Equal(t, now.Add(7*time.Hour), *p.CompletesAt)
+	assert.Equal(t, 1, plan.Agents[0].InProgress)
+}
@@ -0,0 +1,401 @@
+// Package compose generates a docker-compose.yml running the coordinator and
+// the services its configuration points at, for `hyper compose generate`.
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultImage is the published coordinator image
+const DefaultImage = "us-central1-docker.pkg.dev/production-471918/hyperion-public/hyperion-coordinator:latest"
+
+// WorkspaceDir is where folders without a path mapping are mounted
+const WorkspaceDir = "/workspace"
+
+// Whether to run a dependency in the compose file
+const (
+	ModeAuto = "auto" // Run it when the configuration points at this machine
+	ModeYes  = "yes"
+	ModeNo   = "no"
+)
+
+// Service names, which are also their hosts on the compose network
+const (
+	serviceCoordinator = "hyper"
+	serviceMongo       = "mongodb"
+	serviceQdrant      = "qdrant"
+	serviceOllama      = "ollama"
+)
+
+// dockerHost reaches the machine running Docker from a container
+const dockerHost = "host.docker.internal"
+
+// Options configures Generate
+type Options struct {
+	Env      map[string]string // Contents of .env.hyper and its local overrides
+	EnvFiles []string          // .env.hyper and its overrides relative to the compose file, passed as env_file
+	BaseDir  string            // Directory relative CODE_INDEX_FOLDERS are resolved against
+	Image    string            // Coordinator image; default DefaultImage
+	Mongo    string            // ModeAuto, ModeYes or ModeNo; default ModeAuto
+	Qdrant   string
+	Ollama   string
+}
+
+// Mount is a host folder bind-mounted into the coordinator
+type Mount struct {
+	Host      string
+	Container string
+}
+
+// Result is a generated compose file with what went into it
+type Result struct {
+	YAML     []byte
+	Services []string // Services besides the coordinator
+	Mounts   []Mount
+	Notes    []string // Steps left to the user
+}
+
+// Generate builds the compose file for a configuration. Only variables it
+// changes are written to the file; the rest, credentials included, are read
+// from the env file when the containers start.
+func Generate(opts Options) (*Result, error) {
+	if opts.Image == "" {
+		opts.Image = DefaultImage
+	}
+	result := &Result{}
+	environment := map[string]string{}
+
+	mounts, folders, err := volumeMounts(opts.Env, opts.BaseDir)
+	if err != nil {
+		return nil, err
+	}
+	result.Mounts = mounts
+	if len(mounts) > 0 {
+		pairs := make([]string, len(mounts))
+		for i, mount := range mounts {
+			pairs[i] = mount.Host + ":" + mount.Container
+		}
+		environment["CODE_INDEX_PATH_MAPPINGS"] = strings.Join(pairs, ",")
+	}
+	if len(folders) > 0 {
+		environment["CODE_INDEX_FOLDERS"] = strings.Join(folders, ",")
+	}
+
+	file := composeFile{Services: map[string]*service{}, Volumes: map[string]struct{}{}}
+	coordinator := &service{
+		Image:   opts.Image,
+		Command: []string{"--mode=http"},
+		Restart: "unless-stopped",
+		Healthcheck: &healthcheck{
+			Test:     []string{"CMD", "wget", "--no-verbose", "--tries=1", "-O", "/dev/null", "http://localhost:" + httpPort(opts.Env) + "/health"},
+			Interval: "10s",
+			Timeout:  "5s",
+			Retries:  3,
+		},
+	}
+	coordinator.EnvFile = opts.EnvFiles
+	coordinator.Ports = []string{httpPort(opts.Env) + ":" + httpPort(opts.Env)}
+	for _, mount := range mounts {
+		coordinator.Volumes = append(coordinator.Volumes, mount.Host+":"+mount.Container)
+	}
+	file.Services[serviceCoordinator] = coordinator
+
+	include := func(name, mode string, local bool) (bool, error) {
+		switch mode {
+		case "", ModeAuto:
+			return local, nil
+		case ModeYes:
+			return true, nil
+		case ModeNo:
+			return false, nil
+		}
+		return false, fmt.Errorf("%s must be %s, %s or %s, not %q", name, ModeAuto, ModeYes, ModeNo, mode)
+	}
+
+	mongoURI := opts.Env["MONGODB_URI"]
+	withMongo, err := include("mongo", opts.Mongo, mongoURI == "" || isLocalURL(mongoURI))
+	if err != nil {
+		return nil, err
+	}
+	if withMongo {
+		environment["MONGODB_URI"] = composeMongoURI(mongoURI)
+		file.Services[serviceMongo] = &service{
+			Image:   "mongo:7.0",
+			Volumes: []string{"mongodb_data:/data/db"},
+			Restart: "unless-stopped",
+			Healthcheck: &healthcheck{
+				Test:     []string{"CMD", "mongosh", "--quiet", "--eval", "db.adminCommand('ping')"},
+				Interval: "10s",
+				Timeout:  "5s",
+				Retries:  5,
+			},
+		}
+		file.Volumes["mongodb_data"] = struct{}{}
+		coordinator.dependOn(serviceMongo, "service_healthy")
+	}
+
+	qdrantURL := opts.Env["QDRANT_URL"]
+	withQdrant, err := include("qdrant", opts.Qdrant, qdrantURL == "" || isLocalURL(qdrantURL))
+	if err != nil {
+		return nil, err
+	}
+	if withQdrant {
+		environment["QDRANT_URL"] = "http://" + serviceQdrant + ":6333"
+		if opts.Env["QDRANT_API_KEY"] != "" {
+			environment["QDRANT_API_KEY"] = ""
+		}
+		file.Services[serviceQdrant] = &service{
+			Image:   "qdrant/qdrant:latest",
+			Volumes: []string{"qdrant_data:/qdrant/storage"},
+			Restart: "unless-stopped",
+		}
+		file.Volumes["qdrant_data"] = struct{}{}
+		coordinator.dependOn(serviceQdrant, "service_started")
+	}
+
+	embedding := strings.ToLower(opts.Env["EMBEDDING"])
+	ollamaURL := opts.Env["OLLAMA_URL"]
+	usesOllama := embedding == "" || embedding == "ollama"
+	withOllama, err := include("ollama", opts.Ollama, usesOllama && (ollamaURL == "" || isLocalURL(ollamaURL)))
+	if err != nil {
+		return nil, err
+	}
+	if withOllama {
+		environment["OLLAMA_URL"] = "http://" + serviceOllama + ":11434"
+		file.Services[serviceOllama] = &service{
+			Image:   "ollama/ollama:latest",
+			Volumes: []string{"ollama_data:/root/.ollama"},
+			Restart: "unless-stopped",
+		}
+		file.Volumes["ollama_data"] = struct{}{}
+		coordinator.dependOn(serviceOllama, "service_started")
+
+		model := opts.Env["OLLAMA_MODEL"]
+		if model == "" {
+			model = "nomic-embed-text"
+		}
+		result.Notes = append(result.Notes, fmt.Sprintf("Pull the embedding model once Ollama is up: docker compose exec %s ollama pull %s", serviceOllama, model))
+	}
+
+	// Services left on this machine are reached through the Docker host
+	keys := make([]string, 0, len(opts.Env))
+	for key := range opts.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, set := environment[key]; set || !(strings.HasSuffix(key, "_URL") || strings.HasSuffix(key, "_URI")) {
+			continue
+		}
+		if rewritten, ok := toDockerHost(opts.Env[key]); ok {
+			environment[key] = rewritten
+			coordinator.ExtraHosts = []string{dockerHost + ":host-gateway"}
+			result.Notes = append(result.Notes, fmt.Sprintf("%s points at this machine and is reached through %s; the service must listen on an address containers can reach", key, dockerHost))
+		}
+	}
+
+	coordinator.Environment = make(map[string]string, len(environment))
+	for key, value := range environment {
+		// Compose interpolates $ in the file
+		coordinator.Environment[key] = strings.ReplaceAll(value, "$", "$$")
+	}
+	for name := range file.Services {
+		if name != serviceCoordinator {
+			result.Services = append(result.Services, name)
+		}
+	}
+	sort.Strings(result.Services)
+
+	var header strings.Builder
+	header.WriteString("# Generated by `hyper compose generate`")
+	if len(opts.EnvFiles) > 0 {
+		header.WriteString(" from " + strings.Join(opts.EnvFiles, " and "))
+	}
+	header.WriteString(".\n# Regenerate it after changing CODE_INDEX_FOLDERS or CODE_INDEX_PATH_MAPPINGS.\n")
+	for _, note := range result.Notes {
+		header.WriteString("# " + note + "\n")
+	}
+	header.WriteString("\n")
+
+	var out bytes.Buffer
+	out.WriteString(header.String())
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(file); err != nil {
+		return nil, fmt.Errorf("failed to render compose file: %w", err)
+	}
+	encoder.Close()
+	result.YAML = out.Bytes()
+	return result, nil
+}
+
+// volumeMounts derives the coordinator's bind mounts from
+// CODE_INDEX_PATH_MAPPINGS and CODE_INDEX_FOLDERS, and returns the folders
+// as the coordinator sees them. Folders given as host paths outside every
+// mapping are mounted under WorkspaceDir.
+func volumeMounts(env map[string]string, baseDir string) ([]Mount, []string, error) {
+	var mounts []Mount
+	used := map[string]bool{}
+	if mappings := strings.TrimSpace(env["CODE_INDEX_PATH_MAPPINGS"]); mappings != "" {
+		for _, pair := range strings.Split(mappings, ",") {
+			parts := strings.Split(pair, ":")
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+				return nil, nil, fmt.Errorf("CODE_INDEX_PATH_MAPPINGS entry %q is not /host/path:/container/path", pair)
+			}
+			container := path.Clean(strings.TrimSpace(parts[1]))
+			if !path.IsAbs(container) {
+				return nil, nil, fmt.Errorf("CODE_INDEX_PATH_MAPPINGS container path %q must be absolute", container)
+			}
+			mounts = append(mounts, Mount{Host: hostPath(strings.TrimSpace(parts[0]), baseDir), Container: container})
+			used[container] = true
+		}
+	}
+
+	var folders []string
+	for _, folder := range strings.Split(env["CODE_INDEX_FOLDERS"], ",") {
+		folder = strings.TrimSpace(folder)
+		if folder == "" {
+			continue
+		}
+		if container, ok := mappedPath(mounts, folder, false); ok {
+			folders = append(folders, container)
+			continue
+		}
+		host := hostPath(folder, baseDir)
+		if container, ok := mappedPath(mounts, host, true); ok {
+			folders = append(folders, container)
+			continue
+		}
+
+		container := path.Join(WorkspaceDir, filepath.Base(host))
+		for i := 2; used[container]; i++ {
+			container = path.Join(WorkspaceDir, fmt.Sprintf("%s-%d", filepath.Base(host), i))
+		}
+		used[container] = true
+		mounts = append(mounts, Mount{Host: host, Container: container})
+		folders = append(folders, container)
+	}
+	return mounts, folders, nil
+}
+
+// mappedPath translates a folder under a mount's host path, or returns one
+// already under its container path, as PathMapper would see it
+func mappedPath(mounts []Mount, folder string, fromHost bool) (string, bool) {
+	for _, mount := range mounts {
+		prefix := mount.Container
+		if fromHost {
+			prefix = mount.Host
+		}
+		if folder == prefix {
+			return mount.Container, true
+		}
+		if rest, ok := strings.CutPrefix(folder, strings.TrimSuffix(prefix, "/")+"/"); ok {
+			return path.Join(mount.Container, filepath.ToSlash(rest)), true
+		}
+	}
+	return "", false
+}
+
+// hostPath resolves a host folder against baseDir
+func hostPath(folder, baseDir string) string {
+	if !filepath.IsAbs(folder) && baseDir != "" {
+		folder = filepath.Join(baseDir, folder)
+	}
+	return filepath.Clean(folder)
+}
+
+// httpPort is the port the coordinator listens on
+func httpPort(env map[string]string) string {
+	if port := env["HTTP_PORT"]; port != "" {
+		return port
+	}
+	return "7095"
+}
+
+// isLocalURL reports whether a URL points at this machine
+func isLocalURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// toDockerHost replaces a local URL's host with dockerHost
+func toDockerHost(raw string) (string, bool) {
+	if !isLocalURL(raw) {
+		return "", false
+	}
+	u, _ := url.Parse(raw)
+	if port := u.Port(); port != "" {
+		u.Host = net.JoinHostPort(dockerHost, port)
+	} else {
+		u.Host = dockerHost
+	}
+	return u.String(), true
+}
+
+// composeMongoURI points a MongoDB URI at the compose MongoDB, keeping its
+// database and options. Credentials are dropped: the container runs without
+// authentication on the compose network only.
+func composeMongoURI(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" {
+		return "mongodb://" + serviceMongo + ":27017"
+	}
+	u.Scheme = "mongodb"
+	u.User = nil
+	u.Host = serviceMongo + ":27017"
+	return u.String()
+}
+
+// composeFile is the subset of the compose specification Generate writes
+type composeFile struct {
+	Services map[string]*service `yaml:"services"`
+	Volumes  map[string]struct{} `yaml:"volumes,omitempty"`
+}
+
+type service struct {
+	Image       string                `yaml:"image"`
+	Command     []string              `yaml:"command,omitempty"`
+	Ports       []string              `yaml:"ports,omitempty"`
+	EnvFile     []string              `yaml:"env_file,omitempty"`
+	Environment map[string]string     `yaml:"environment,omitempty"`
+	Volumes     []string              `yaml:"volumes,omitempty"`
+	ExtraHosts  []string              `yaml:"extra_hosts,omitempty"`
+	DependsOn   map[string]dependency `yaml:"depends_on,omitempty"`
+	Restart     string                `yaml:"restart,omitempty"`
+	Healthcheck *healthcheck          `yaml:"healthcheck,omitempty"`
+}
+
+type dependency struct {
+	Condition string `yaml:"condition"`
+}
+
+type healthcheck struct {
+	Test     []string `yaml:"test"`
+	Interval string   `yaml:"interval"`
+	Timeout  string   `yaml:"timeout"`
+	Retries  int      `yaml:"retries"`
+}
+
+func (s *service) dependOn(name, condition string) {
+	if s.DependsOn == nil {
+		s.DependsOn = map[string]dependency{}
+	}
+	s.DependsOn[name] = dependency{Condition: condition}
+}
@@ -0,0 +1,106 @@
+// Package confirm asks MCP clients to confirm destructive tool calls.
+//
+// Clients that advertise the elicitation capability are sent a structured
+// confirmation request, so the user approves the action rather than the
+// agent. Clients without elicitation fall back to a confirm: true argument.
+package confirm
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Outcome is the result of asking for confirmation
+type Outcome int
+
+const (
+	// Required means nobody confirmed: the client cannot elicit and the
+	// call did not pass confirm: true
+	Required Outcome = iota
+	// Confirmed means the caller or the user approved the action
+	Confirmed
+	// Declined means the user declined or dismissed the confirmation
+	Declined
+)
+
+// elicitor sends elicitation requests to the client; *mcp.ServerSession
+// satisfies it
+type elicitor interface {
+	Elicit(ctx context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error)
+}
+
+type contextKey struct{}
+
+// WithElicitor returns ctx carrying e, which Ask uses to confirm actions
+func WithElicitor(ctx context.Context, e elicitor) context.Context {
+	return context.WithValue(ctx, contextKey{}, e)
+}
+
+// WithRequest returns ctx carrying the session of req when its client
+// supports elicitation, and ctx unchanged otherwise
+func WithRequest(ctx context.Context, req *mcp.CallToolRequest) context.Context {
+	if req == nil {
+		return ctx
+	}
+	return WithSession(ctx, req.Session)
+}
+
+// WithSession returns ctx carrying session when its client supports
+// elicitation, and ctx unchanged otherwise
+func WithSession(ctx context.Context, session *mcp.ServerSession) context.Context {
+	if session == nil {
+		return ctx
+	}
+	params := session.InitializeParams()
+	if params == nil || params.Capabilities == nil || params.Capabilities.Elicitation == nil {
+		return ctx
+	}
+	return WithElicitor(ctx, session)
+}
+
+// Middleware makes the calling session available to Ask inside handler
+func Middleware(handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handler(WithRequest(ctx, req), req)
+	}
+}
+
+// Ask confirms the action described by message. A confirm: true argument
+// confirms without asking; otherwise the client is asked through
+// elicitation when it supports it.
+func Ask(ctx context.Context, args map[string]interface{}, message string) Outcome {
+	if confirmed, ok := args["confirm"].(bool); ok && confirmed {
+		return Confirmed
+	}
+	e, ok := ctx.Value(contextKey{}).(elicitor)
+	if !ok || e == nil {
+		return Required
+	}
+
+	result, err := e.Elicit(ctx, &mcp.ElicitParams{
+		Message: message,
+		RequestedSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"confirm": map[string]interface{}{
+					"type":        "boolean",
+					"title":       "Confirm",
+					"description": "Set to true to proceed",
+				},
+			},
+			"required": []string{"confirm"},
+		},
+	})
+	if err != nil || result == nil {
+		// The client could not answer; fall back to the confirm argument
+		return Required
+	}
+	if result.Action != "accept" {
+		return Declined
+	}
+	if confirmed, ok := result.Content["confirm"].(bool); ok && confirmed {
+		return Confirmed
+	}
+	return Declined
+}
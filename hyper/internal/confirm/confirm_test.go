@@ -0,0 +1,59 @@
+package confirm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeElicitor answers every elicitation with a fixed result
+type fakeElicitor struct {
+	result *mcp.ElicitResult
+	err    error
+	asked  []*mcp.ElicitParams
+}
+
+func (f *fakeElicitor) Elicit(ctx context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+	f.asked = append(f.asked, params)
+	return f.result, f.err
+}
+
+func TestAsk_FallsBackToConfirmArgument(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, Confirmed, Ask(ctx, map[string]interface{}{"confirm": true}, "clear?"))
+	assert.Equal(t, Required, Ask(ctx, map[string]interface{}{"confirm": false}, "clear?"))
+	assert.Equal(t, Required, Ask(ctx, map[string]interface{}{}, "clear?"))
+}
+
+func TestAsk_Elicits(t *testing.T) {
+	accept := &fakeElicitor{result: &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": true}}}
+	ctx := WithElicitor(context.Background(), accept)
+	assert.Equal(t, Confirmed, Ask(ctx, map[string]interface{}{}, "Clear the task board?"))
+	require.Len(t, accept.asked, 1)
+	assert.Equal(t, "Clear the task board?", accept.asked[0].Message)
+
+	// An explicit confirm argument skips the prompt
+	assert.Equal(t, Confirmed, Ask(ctx, map[string]interface{}{"confirm": true}, "again?"))
+	assert.Len(t, accept.asked, 1)
+
+	unchecked := &fakeElicitor{result: &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": false}}}
+	assert.Equal(t, Declined, Ask(WithElicitor(context.Background(), unchecked), nil, "clear?"))
+
+	for _, action := range []string{"decline", "cancel"} {
+		e := &fakeElicitor{result: &mcp.ElicitResult{Action: action}}
+		assert.Equal(t, Declined, Ask(WithElicitor(context.Background(), e), nil, "clear?"), action)
+	}
+
+	failing := &fakeElicitor{err: errors.New("method not found")}
+	assert.Equal(t, Required, Ask(WithElicitor(context.Background(), failing), nil, "clear?"))
+}
+
+func TestWithRequest_WithoutSession(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithRequest(ctx, nil))
+	assert.Equal(t, ctx, WithRequest(ctx, &mcp.CallToolRequest{}))
+}
@@ -0,0 +1,55 @@
+// Package console carries human-facing output (startup notes, prompts,
+// warnings) and keeps it off stdout when stdout is the MCP stdio transport.
+//
+// In stdio mode stdout carries newline-delimited JSON-RPC frames, so a stray
+// fmt.Printf corrupts the stream. Code prints through this package instead of
+// fmt.Print*, and GuardStdout makes sure nothing else slips through.
+package console
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	mu    sync.RWMutex
+	stdio bool
+)
+
+// UseStdioTransport routes all human-facing output to stderr. Call it before
+// anything is printed when the MCP server runs over stdio.
+func UseStdioTransport() {
+	mu.Lock()
+	defer mu.Unlock()
+	stdio = true
+}
+
+// StdioTransport reports whether stdout is reserved for protocol frames, in
+// which case stdin is too and the user cannot be prompted
+func StdioTransport() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return stdio
+}
+
+// Writer returns the writer for human-facing output: stdout, or stderr in
+// stdio mode. It is looked up on each call, so redirecting os.Stdout or
+// os.Stderr (e.g. to a log file) is honoured.
+func Writer() io.Writer {
+	if StdioTransport() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// Printf writes formatted human-facing output
+func Printf(format string, args ...interface{}) {
+	fmt.Fprintf(Writer(), format, args...)
+}
+
+// Println writes a line of human-facing output
+func Println(args ...interface{}) {
+	fmt.Fprintln(Writer(), args...)
+}
@@ -0,0 +1,88 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIsFrame(t *testing.T) {
+	cases := []struct {
+		line string
+		want bool
+	}{
+		{`{"jsonrpc":"2.0","id":1,"result":{}}` + "\n", true},
+		{`[{"jsonrpc":"2.0","id":1,"result":{}}]` + "\n", true},
+		{`{"jsonrpc":"2.0"}`, false}, // Not terminated
+		{"Project root: /tmp\n", false},
+		{"✓ Loaded configuration from: .env.hyper\n", false},
+		{`"just a string"` + "\n", false},
+		{"{not json\n", false},
+		{"\n", false},
+	}
+	for _, c := range cases {
+		if got := IsFrame([]byte(c.line)); got != c.want {
+			t.Errorf("IsFrame(%q) = %v, want %v", c.line, got, c.want)
+		}
+	}
+}
+
+func TestUseStdioTransportRoutesToStderr(t *testing.T) {
+	defer func() { stdio = false }()
+
+	if StdioTransport() || Writer() != os.Stdout {
+		t.Fatal("expected stdout before stdio mode")
+	}
+	UseStdioTransport()
+	if !StdioTransport() || Writer() != os.Stderr {
+		t.Fatal("expected stderr in stdio mode")
+	}
+}
+
+func TestGuardStdoutPassesOnlyFrames(t *testing.T) {
+	original := os.Stdout
+	var stdout, stderr bytes.Buffer
+
+	restore, err := guardStdout(&stdout, &stderr)
+	if err != nil {
+		t.Fatalf("guardStdout: %v", err)
+	}
+	frame := `{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}` + "\n"
+	fmt.Printf("Project root: %s\n", "/tmp/project")
+	fmt.Fprint(os.Stdout, frame)
+	fmt.Print("Do you want to recreate the collection? (yes/no): ")
+	restore()
+	restore() // Safe to call twice
+
+	if os.Stdout != original {
+		t.Fatal("expected the real stdout to be restored")
+	}
+	if stdout.String() != frame {
+		t.Errorf("stdout = %q, want only the frame", stdout.String())
+	}
+	for _, want := range []string{"Project root: /tmp/project", "(yes/no): "} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("expected %q diverted to stderr, got %q", want, stderr.String())
+		}
+	}
+}
+
+func TestPrintfInStdioModeLeavesStdoutClean(t *testing.T) {
+	defer func() { stdio = false }()
+	var stdout, stderr bytes.Buffer
+
+	restore, err := guardStdout(&stdout, &stderr)
+	if err != nil {
+		t.Fatalf("guardStdout: %v", err)
+	}
+	UseStdioTransport()
+	Printf("✓ Loaded configuration from: %s\n", ".env.hyper")
+	Println("Warning: failed to store in Qdrant")
+	restore()
+
+	if stdout.Len() != 0 || stderr.Len() != 0 {
+		t.Errorf("expected console output to bypass stdout, got stdout %q, diverted %q", stdout.String(), stderr.String())
+	}
+}
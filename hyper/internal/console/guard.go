@@ -0,0 +1,84 @@
+package console
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// GuardStdout replaces os.Stdout with a pipe that passes only protocol frames
+// (one JSON object or array per line) on to the real stdout. Anything else,
+// such as a library printing to stdout, is diverted to stderr with a warning
+// instead of corrupting the stream.
+//
+// Call it before the stdio transport connects, since the transport captures
+// os.Stdout then. The returned function flushes the pipe and puts the real
+// stdout back.
+func GuardStdout() (restore func(), err error) {
+	return guardStdout(os.Stdout, os.Stderr)
+}
+
+func guardStdout(stdout, stderr io.Writer) (func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	original := os.Stdout
+	os.Stdout = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		filterFrames(r, stdout, stderr)
+		r.Close()
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			os.Stdout = original
+			w.Close() // The transport may have closed it already
+			<-done
+		})
+	}, nil
+}
+
+// filterFrames copies protocol frames from r to stdout and diverts every other
+// line to stderr, until r is drained
+func filterFrames(r io.Reader, stdout, stderr io.Writer) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if IsFrame(line) {
+				stdout.Write(line)
+			} else {
+				fmt.Fprintf(stderr, "⚠ Diverted non-protocol output from stdout: %s", line)
+				if line[len(line)-1] != '\n' {
+					fmt.Fprintln(stderr)
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// IsFrame reports whether a line is a stdio protocol frame: a single JSON
+// object or batch array terminated by a newline
+func IsFrame(line []byte) bool {
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		return false
+	}
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	return json.Valid(trimmed)
+}
@@ -0,0 +1,266 @@
+// Package digest builds and delivers scheduled summaries of new knowledge,
+// completed tasks and decisions for a workspace.
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/notify"
+)
+
+const (
+	// maxEntriesScanned is how many of the newest entries per collection are
+	// considered for a digest
+	maxEntriesScanned = 200
+	// maxItemsPerSection caps each digest section; the totals still count everything
+	maxItemsPerSection = 25
+	// maxSummaryChars is the length knowledge text and task prompts are cut to
+	maxSummaryChars = 200
+)
+
+// decisionCollection holds architecture decision records
+const decisionCollection = "adr"
+
+// Item is one line of a digest section
+type Item struct {
+	ID         string    `json:"id"`
+	Collection string    `json:"collection,omitempty"`
+	Project    string    `json:"project,omitempty"`
+	Agent      string    `json:"agent,omitempty"`
+	Summary    string    `json:"summary"`
+	At         time.Time `json:"at"`
+}
+
+// Section is a capped list of digest items with the full count
+type Section struct {
+	Total int     `json:"total"`
+	Items []*Item `json:"items"`
+}
+
+// Digest summarizes a workspace's activity over a period
+type Digest struct {
+	Workspace      string    `json:"workspace"`
+	Project        string    `json:"project,omitempty"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	Knowledge      Section   `json:"knowledge"`
+	Decisions      Section   `json:"decisions"`
+	CompletedTasks Section   `json:"completedTasks"`
+}
+
+// Empty reports whether nothing happened in the period
+func (d *Digest) Empty() bool {
+	return d.Knowledge.Total == 0 && d.Decisions.Total == 0 && d.CompletedTasks.Total == 0
+}
+
+// Builder collects digest contents from knowledge and task storage
+type Builder struct {
+	knowledge storage.KnowledgeStorage
+	tasks     storage.TaskStorage
+}
+
+// NewBuilder creates a digest builder
+func NewBuilder(knowledge storage.KnowledgeStorage, tasks storage.TaskStorage) *Builder {
+	return &Builder{knowledge: knowledge, tasks: tasks}
+}
+
+// Build summarizes what happened between from and to for a subscription
+func (b *Builder) Build(sub *storage.DigestSubscription, from, to time.Time) (*Digest, error) {
+	digest := &Digest{Workspace: sub.Name, Project: sub.Project, From: from, To: to}
+
+	if err := b.collectKnowledge(digest, sub.Collections); err != nil {
+		return nil, err
+	}
+	b.collectCompletedTasks(digest)
+
+	for _, section := range []*Section{&digest.Knowledge, &digest.Decisions, &digest.CompletedTasks} {
+		sort.Slice(section.Items, func(i, j int) bool {
+			return section.Items[i].At.After(section.Items[j].At)
+		})
+		section.Total = len(section.Items)
+		if len(section.Items) > maxItemsPerSection {
+			section.Items = section.Items[:maxItemsPerSection]
+		}
+	}
+	return digest, nil
+}
+
+// collectKnowledge adds entries created in the period, splitting out decisions
+func (b *Builder) collectKnowledge(digest *Digest, collections []string) error {
+	if b.knowledge == nil {
+		return nil
+	}
+	if len(collections) == 0 {
+		collections = b.knowledge.ListCollections()
+	}
+
+	for _, collection := range collections {
+		entries, err := b.knowledge.ListKnowledge(collection, maxEntriesScanned)
+		if err != nil {
+			return fmt.Errorf("failed to list knowledge in %s: %w", collection, err)
+		}
+		for _, entry := range entries {
+			if !inPeriod(entry.CreatedAt, digest.From, digest.To) {
+				continue
+			}
+			// Drafts are not knowledge until a human approves them
+			if approval, _ := entry.Metadata[storage.ApprovalMetadataKey].(string); approval == storage.ApprovalPending {
+				continue
+			}
+			item := &Item{
+				ID:         entry.ID,
+				Collection: entry.Collection,
+				Summary:    summarize(entry.Text),
+				At:         entry.CreatedAt,
+			}
+			if isDecision(entry) {
+				digest.Decisions.Items = append(digest.Decisions.Items, item)
+			} else {
+				digest.Knowledge.Items = append(digest.Knowledge.Items, item)
+			}
+		}
+	}
+	return nil
+}
+
+// collectCompletedTasks adds human and agent tasks completed in the period
+func (b *Builder) collectCompletedTasks(digest *Digest) {
+	if b.tasks == nil {
+		return
+	}
+
+	humanTasks := make(map[string]*storage.HumanTask)
+	for _, task := range b.tasks.ListAllHumanTasks() {
+		humanTasks[task.ID] = task
+		if !completedInPeriod(task.Status, task.UpdatedAt, digest) || !inProject(task, digest.Project) {
+			continue
+		}
+		digest.CompletedTasks.Items = append(digest.CompletedTasks.Items, &Item{
+			ID:      task.ID,
+			Project: task.Project,
+			Summary: summarize(task.Prompt),
+			At:      task.UpdatedAt,
+		})
+	}
+
+	for _, task := range b.tasks.ListAllAgentTasks() {
+		parent := humanTasks[task.HumanTaskID]
+		if !completedInPeriod(task.Status, task.UpdatedAt, digest) || !inProject(parent, digest.Project) {
+			continue
+		}
+		item := &Item{
+			ID:      task.ID,
+			Agent:   task.AgentName,
+			Summary: summarize(task.Role),
+			At:      task.UpdatedAt,
+		}
+		if parent != nil {
+			item.Project = parent.Project
+		}
+		digest.CompletedTasks.Items = append(digest.CompletedTasks.Items, item)
+	}
+}
+
+// isDecision reports whether an entry records a decision: it is stored in the
+// ADR collection or tagged with metadata type "decision" or "adr"
+func isDecision(entry *storage.KnowledgeEntry) bool {
+	if entry.Collection == decisionCollection {
+		return true
+	}
+	kind, _ := entry.Metadata["type"].(string)
+	kind = strings.ToLower(kind)
+	return kind == "decision" || kind == "adr"
+}
+
+func completedInPeriod(status storage.TaskStatus, at time.Time, digest *Digest) bool {
+	return status == storage.TaskStatusCompleted && inPeriod(at, digest.From, digest.To)
+}
+
+// inProject reports whether a human task belongs to the project; an empty
+// project matches every task
+func inProject(task *storage.HumanTask, project string) bool {
+	if project == "" {
+		return true
+	}
+	return task != nil && task.Project == project
+}
+
+func inPeriod(at, from, to time.Time) bool {
+	return !at.Before(from) && at.Before(to)
+}
+
+// summarize keeps the first line of text, cut to maxSummaryChars
+func summarize(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = strings.TrimSpace(text[:i])
+	}
+	runes := []rune(text)
+	if len(runes) > maxSummaryChars {
+		return string(runes[:maxSummaryChars]) + "..."
+	}
+	return text
+}
+
+// Title is the digest headline, e.g. "Daily digest for platform"
+func (d *Digest) Title(frequency string) string {
+	kind := "Daily"
+	if frequency == storage.DigestWeekly {
+		kind = "Weekly"
+	}
+	return fmt.Sprintf("%s digest for %s", kind, d.Workspace)
+}
+
+// Markdown renders the digest for Slack and email
+func (d *Digest) Markdown(frequency string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*\n", d.Title(frequency))
+	fmt.Fprintf(&b, "%s to %s (UTC)\n", d.From.UTC().Format("2006-01-02 15:04"), d.To.UTC().Format("2006-01-02 15:04"))
+	if d.Project != "" {
+		fmt.Fprintf(&b, "Project: %s\n", d.Project)
+	}
+
+	if d.Empty() {
+		b.WriteString("\nNo new knowledge, decisions or completed tasks in this period.\n")
+		return b.String()
+	}
+
+	writeSection(&b, "Notable decisions", d.Decisions, func(item *Item) string {
+		return fmt.Sprintf("%s (%s)", item.Summary, item.Collection)
+	})
+	writeSection(&b, "New knowledge", d.Knowledge, func(item *Item) string {
+		return fmt.Sprintf("%s (%s)", item.Summary, item.Collection)
+	})
+	writeSection(&b, "Completed tasks", d.CompletedTasks, func(item *Item) string {
+		if item.Agent != "" {
+			return fmt.Sprintf("%s: %s", item.Agent, item.Summary)
+		}
+		return item.Summary
+	})
+	return b.String()
+}
+
+// HTML renders the digest as an email body
+func (d *Digest) HTML(frequency string) (string, error) {
+	return notify.RenderHTML("digest.html", struct {
+		Title string
+		*Digest
+	}{d.Title(frequency), d})
+}
+
+func writeSection(b *strings.Builder, title string, section Section, line func(*Item) string) {
+	if section.Total == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n*%s (%d)*\n", title, section.Total)
+	for _, item := range section.Items {
+		fmt.Fprintf(b, "- %s\n", line(item))
+	}
+	if hidden := section.Total - len(section.Items); hidden > 0 {
+		fmt.Fprintf(b, "- ...and %d more\n", hidden)
+	}
+}
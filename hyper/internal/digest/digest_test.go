@@ -0,0 +1,240 @@
+package digest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var (
+	periodStart = time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	periodEnd   = periodStart.Add(24 * time.Hour)
+	during      = periodStart.Add(time.Hour)
+	before      = periodStart.Add(-time.Hour)
+)
+
+// fakeKnowledge serves fixed entries per collection
+type fakeKnowledge struct {
+	storage.KnowledgeStorage
+	entries map[string][]*storage.KnowledgeEntry
+}
+
+func (f *fakeKnowledge) ListCollections() []string {
+	names := make([]string, 0, len(f.entries))
+	for name := range f.entries {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (f *fakeKnowledge) ListKnowledge(collection string, limit int) ([]*storage.KnowledgeEntry, error) {
+	return f.entries[collection], nil
+}
+
+// fakeTasks serves fixed human and agent tasks
+type fakeTasks struct {
+	storage.TaskStorage
+	human []*storage.HumanTask
+	agent []*storage.AgentTask
+}
+
+func (f *fakeTasks) ListAllHumanTasks() []*storage.HumanTask { return f.human }
+func (f *fakeTasks) ListAllAgentTasks() []*storage.AgentTask { return f.agent }
+
+func newTestBuilder() *Builder {
+	knowledge := &fakeKnowledge{entries: map[string][]*storage.KnowledgeEntry{
+		"adr": {
+			{ID: "adr-1", Collection: "adr", Text: "Use MongoDB for tasks\nLong rationale", CreatedAt: during},
+			{ID: "adr-draft", Collection: "adr", Text: "Drafted decision", Metadata: map[string]interface{}{"approval": "pending"}, CreatedAt: during},
+		},
+		"technical-knowledge": {
+			{ID: "tk-1", Collection: "technical-knowledge", Text: "Qdrant runs on port 6333", CreatedAt: during.Add(time.Hour)},
+			{ID: "tk-2", Collection: "technical-knowledge", Text: "Switch to gRPC", Metadata: map[string]interface{}{"type": "Decision"}, CreatedAt: during},
+			{ID: "tk-old", Collection: "technical-knowledge", Text: "Old entry", CreatedAt: before},
+		},
+	}}
+	tasks := &fakeTasks{
+		human: []*storage.HumanTask{
+			{ID: "h-1", Prompt: "Ship digests", Project: "platform", Status: storage.TaskStatusCompleted, UpdatedAt: during},
+			{ID: "h-2", Prompt: "Other project", Project: "mobile", Status: storage.TaskStatusCompleted, UpdatedAt: during},
+			{ID: "h-3", Prompt: "Still running", Project: "platform", Status: storage.TaskStatusInProgress, UpdatedAt: during},
+		},
+		agent: []*storage.AgentTask{
+			{ID: "a-1", HumanTaskID: "h-1", AgentName: "go-dev", Role: "Build scheduler", Status: storage.TaskStatusCompleted, UpdatedAt: during},
+			{ID: "a-2", HumanTaskID: "h-2", AgentName: "ios-dev", Role: "Build app", Status: storage.TaskStatusCompleted, UpdatedAt: during},
+			{ID: "a-3", HumanTaskID: "h-1", AgentName: "go-dev", Role: "Earlier work", Status: storage.TaskStatusCompleted, UpdatedAt: before},
+		},
+	}
+	return NewBuilder(knowledge, tasks)
+}
+
+func TestBuild(t *testing.T) {
+	sub := &storage.DigestSubscription{Name: "platform", Project: "platform"}
+	d, err := newTestBuilder().Build(sub, periodStart, periodEnd)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, d.Knowledge.Total)
+	assert.Equal(t, "tk-1", d.Knowledge.Items[0].ID)
+
+	require.Equal(t, 2, d.Decisions.Total)
+	ids := []string{d.Decisions.Items[0].ID, d.Decisions.Items[1].ID}
+	assert.ElementsMatch(t, []string{"adr-1", "tk-2"}, ids)
+
+	require.Equal(t, 2, d.CompletedTasks.Total)
+	assert.ElementsMatch(t, []string{"h-1", "a-1"}, []string{d.CompletedTasks.Items[0].ID, d.CompletedTasks.Items[1].ID})
+
+	text := d.Markdown(storage.DigestDaily)
+	assert.Contains(t, text, "*Daily digest for platform*")
+	assert.Contains(t, text, "*Notable decisions (2)*")
+	assert.Contains(t, text, "- Use MongoDB for tasks (adr)")
+	assert.NotContains(t, text, "Long rationale")
+	assert.Contains(t, text, "- go-dev: Build scheduler")
+}
+
+func TestBuild_CollectionFilterAndCap(t *testing.T) {
+	entries := make([]*storage.KnowledgeEntry, maxItemsPerSection+5)
+	for i := range entries {
+		entries[i] = &storage.KnowledgeEntry{ID: "e", Collection: "notes", Text: "note", CreatedAt: during}
+	}
+	builder := NewBuilder(&fakeKnowledge{entries: map[string][]*storage.KnowledgeEntry{
+		"notes": entries,
+		"adr":   {{ID: "adr-1", Collection: "adr", Text: "Decision", CreatedAt: during}},
+	}}, nil)
+
+	d, err := builder.Build(&storage.DigestSubscription{Name: "w", Collections: []string{"notes"}}, periodStart, periodEnd)
+	require.NoError(t, err)
+	assert.Equal(t, maxItemsPerSection+5, d.Knowledge.Total)
+	assert.Len(t, d.Knowledge.Items, maxItemsPerSection)
+	assert.Equal(t, 0, d.Decisions.Total)
+	assert.Contains(t, d.Markdown(storage.DigestWeekly), "- ...and 5 more")
+}
+
+func TestNotifier_Webhooks(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+		if r.URL.Path == "/fail" {
+			http.Error(w, "nope", http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	notifier := NewNotifier(nil)
+	d := &Digest{Workspace: "platform", From: periodStart, To: periodEnd}
+
+	require.NoError(t, notifier.Send(context.Background(), &storage.DigestSubscription{Channel: storage.DigestChannelWebhook, Target: srv.URL, Frequency: storage.DigestWeekly}, d))
+	require.NoError(t, notifier.Send(context.Background(), &storage.DigestSubscription{Channel: storage.DigestChannelSlack, Target: srv.URL}, d))
+
+	require.Len(t, bodies, 2)
+	assert.Equal(t, "Weekly digest for platform", bodies[0]["title"])
+	assert.Contains(t, bodies[0], "digest")
+	assert.Len(t, bodies[1], 1)
+	assert.Contains(t, bodies[1]["text"], "No new knowledge")
+
+	err := notifier.Send(context.Background(), &storage.DigestSubscription{Channel: storage.DigestChannelSlack, Target: srv.URL + "/fail"}, d)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 502")
+}
+
+// fakeEmail records sent emails
+type fakeEmail struct{ sent []*notify.Email }
+
+func (f *fakeEmail) SendEmail(msg *notify.Email) error {
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+func TestNotifier_Email(t *testing.T) {
+	email := &fakeEmail{}
+	notifier := NewNotifier(email)
+
+	sub := &storage.DigestSubscription{Channel: storage.DigestChannelEmail, Target: "a@example.com, Bob <b@example.com>"}
+	d, err := newTestBuilder().Build(&storage.DigestSubscription{Name: "platform"}, periodStart, periodEnd)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Send(context.Background(), sub, d))
+
+	require.Len(t, email.sent, 1)
+	msg := email.sent[0]
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, msg.To)
+	assert.Equal(t, "Daily digest for platform", msg.Subject)
+	assert.Contains(t, msg.Text, "*New knowledge (1)*")
+	assert.Contains(t, msg.HTML, "<h1 style=\"margin:0;font-size:20px;\">Daily digest for platform</h1>")
+	assert.Contains(t, msg.HTML, "Notable decisions (2)")
+	assert.Contains(t, msg.HTML, "<strong>go-dev</strong>: Build scheduler")
+}
+
+// fakeStore records deliveries in memory
+type fakeStore struct {
+	subs       []*storage.DigestSubscription
+	deliveries map[string]error
+}
+
+func (f *fakeStore) ListSubscriptions() ([]*storage.DigestSubscription, error) { return f.subs, nil }
+
+func (f *fakeStore) RecordDelivery(name string, at time.Time, deliveryErr error) error {
+	f.deliveries[name] = deliveryErr
+	return nil
+}
+
+// fakeSender records sent digests and fails for the "broken" workspace
+type fakeSender struct{ sent []*Digest }
+
+func (f *fakeSender) Send(ctx context.Context, sub *storage.DigestSubscription, d *Digest) error {
+	if sub.Name == "broken" {
+		return errors.New("webhook down")
+	}
+	f.sent = append(f.sent, d)
+	return nil
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	now := periodEnd.Add(30 * time.Minute)
+	created := periodStart.Add(-48 * time.Hour)
+	lastSent := periodStart
+	recentAttempt := now.Add(-5 * time.Minute)
+
+	store := &fakeStore{
+		deliveries: map[string]error{},
+		subs: []*storage.DigestSubscription{
+			{Name: "platform", Project: "platform", Frequency: storage.DigestDaily, HourUTC: 8, Enabled: true, CreatedAt: created, LastSentAt: &lastSent},
+			{Name: "sent-today", Frequency: storage.DigestDaily, HourUTC: 8, Enabled: true, CreatedAt: created, LastSentAt: &now},
+			{Name: "backing-off", Frequency: storage.DigestDaily, HourUTC: 8, Enabled: true, CreatedAt: created, LastAttemptAt: &recentAttempt, LastError: "boom"},
+			{Name: "broken", Frequency: storage.DigestDaily, HourUTC: 8, Enabled: true, CreatedAt: created},
+		},
+	}
+	sender := &fakeSender{}
+	scheduler := NewScheduler(store, newTestBuilder(), sender, zap.NewNop())
+	scheduler.now = func() time.Time { return now }
+
+	scheduler.RunDue(context.Background())
+
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, "platform", sender.sent[0].Workspace)
+	assert.True(t, sender.sent[0].From.Equal(lastSent))
+	assert.Equal(t, 2, sender.sent[0].CompletedTasks.Total)
+
+	assert.Len(t, store.deliveries, 2)
+	assert.NoError(t, store.deliveries["platform"])
+	assert.EqualError(t, store.deliveries["broken"], "webhook down")
+}
+
+func TestPeriodStart(t *testing.T) {
+	sub := &storage.DigestSubscription{Frequency: storage.DigestWeekly}
+	assert.True(t, PeriodStart(sub, periodEnd).Equal(periodEnd.Add(-7*24*time.Hour)))
+
+	sub.LastSentAt = &periodStart
+	assert.True(t, PeriodStart(sub, periodEnd).Equal(periodStart))
+}
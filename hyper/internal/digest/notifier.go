@@ -0,0 +1,105 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/notify"
+)
+
+// deliveryTimeout bounds a single webhook request
+const deliveryTimeout = 30 * time.Second
+
+// Notifier delivers digests over the subscription's channel
+type Notifier struct {
+	httpClient *http.Client
+	email      notify.EmailSender
+}
+
+// NewNotifier creates a notifier sending email digests through email
+func NewNotifier(email notify.EmailSender) *Notifier {
+	return &Notifier{
+		httpClient: &http.Client{Timeout: deliveryTimeout},
+		email:      email,
+	}
+}
+
+// webhookPayload is the JSON body POSTed to webhook subscribers
+type webhookPayload struct {
+	Title     string  `json:"title"`
+	Frequency string  `json:"frequency"`
+	Text      string  `json:"text"` // Markdown rendering
+	Digest    *Digest `json:"digest"`
+}
+
+// Send delivers a digest to the subscription's target
+func (n *Notifier) Send(ctx context.Context, sub *storage.DigestSubscription, d *Digest) error {
+	switch sub.Channel {
+	case storage.DigestChannelWebhook:
+		return n.postJSON(ctx, sub.Target, webhookPayload{
+			Title:     d.Title(sub.Frequency),
+			Frequency: sub.Frequency,
+			Text:      d.Markdown(sub.Frequency),
+			Digest:    d,
+		})
+	case storage.DigestChannelSlack:
+		return n.postJSON(ctx, sub.Target, map[string]string{"text": d.Markdown(sub.Frequency)})
+	case storage.DigestChannelEmail:
+		return n.sendEmail(sub, d)
+	default:
+		return fmt.Errorf("invalid channel %q", sub.Channel)
+	}
+}
+
+// postJSON POSTs a JSON body and expects a 2xx response
+func (n *Notifier) postJSON(ctx context.Context, target string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode digest: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create digest request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver digest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("digest delivery failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
+
+// sendEmail sends the digest as an HTML email with a plain text alternative
+func (n *Notifier) sendEmail(sub *storage.DigestSubscription, d *Digest) error {
+	recipients, err := storage.ParseEmailRecipients(sub.Target)
+	if err != nil {
+		return err
+	}
+
+	html, err := d.HTML(sub.Frequency)
+	if err != nil {
+		return err
+	}
+
+	return n.email.SendEmail(&notify.Email{
+		To:      recipients,
+		Subject: d.Title(sub.Frequency),
+		Text:    d.Markdown(sub.Frequency),
+		HTML:    html,
+	})
+}
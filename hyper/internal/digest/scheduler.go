@@ -0,0 +1,130 @@
+package digest
+
+import (
+	"context"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// checkInterval is how often subscriptions are checked for due digests
+	checkInterval = time.Minute
+	// retryBackoff is how long a failed delivery waits before it is retried
+	retryBackoff = 15 * time.Minute
+)
+
+// SubscriptionStore is the subscription storage the scheduler needs
+// (implemented by storage.DigestSubscriptionStorage)
+type SubscriptionStore interface {
+	ListSubscriptions() ([]*storage.DigestSubscription, error)
+	RecordDelivery(name string, at time.Time, deliveryErr error) error
+}
+
+// Sender delivers a built digest (implemented by Notifier)
+type Sender interface {
+	Send(ctx context.Context, sub *storage.DigestSubscription, d *Digest) error
+}
+
+// Scheduler sends digests when their daily or weekly slot comes up
+type Scheduler struct {
+	store   SubscriptionStore
+	builder *Builder
+	sender  Sender
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewScheduler creates a digest scheduler
+func NewScheduler(store SubscriptionStore, builder *Builder, sender Sender, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		store:   store,
+		builder: builder,
+		sender:  sender,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Start checks for due digests every minute until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		s.logger.Info("Digest scheduler started")
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RunDue(ctx)
+			}
+		}
+	}()
+}
+
+// RunDue sends every digest that is due and not waiting out a retry backoff
+func (s *Scheduler) RunDue(ctx context.Context) {
+	subs, err := s.store.ListSubscriptions()
+	if err != nil {
+		s.logger.Warn("Failed to list digest subscriptions", zap.Error(err))
+		return
+	}
+
+	now := s.now().UTC()
+	for _, sub := range subs {
+		if !sub.Due(now) || backingOff(sub, now) {
+			continue
+		}
+		if _, err := s.Deliver(ctx, sub, now); err != nil {
+			s.logger.Warn("Digest delivery failed",
+				zap.String("workspace", sub.Name),
+				zap.String("channel", sub.Channel),
+				zap.Error(err))
+			continue
+		}
+		s.logger.Info("Digest delivered", zap.String("workspace", sub.Name), zap.String("channel", sub.Channel))
+	}
+}
+
+// Deliver builds the digest for the period since the last delivery, sends it
+// and records the outcome
+func (s *Scheduler) Deliver(ctx context.Context, sub *storage.DigestSubscription, now time.Time) (*Digest, error) {
+	d, err := s.builder.Build(sub, PeriodStart(sub, now), now)
+	if err != nil {
+		s.record(sub.Name, now, err)
+		return nil, err
+	}
+
+	err = s.sender.Send(ctx, sub, d)
+	s.record(sub.Name, now, err)
+	return d, err
+}
+
+// Preview builds the digest Deliver would send without sending or recording it
+func (s *Scheduler) Preview(sub *storage.DigestSubscription, now time.Time) (*Digest, error) {
+	return s.builder.Build(sub, PeriodStart(sub, now), now)
+}
+
+func (s *Scheduler) record(name string, at time.Time, deliveryErr error) {
+	if err := s.store.RecordDelivery(name, at, deliveryErr); err != nil {
+		s.logger.Warn("Failed to record digest delivery", zap.String("workspace", name), zap.Error(err))
+	}
+}
+
+// PeriodStart is where the next digest of a subscription starts: the last
+// delivery, or one period back for a first digest
+func PeriodStart(sub *storage.DigestSubscription, now time.Time) time.Time {
+	if sub.LastSentAt != nil {
+		return *sub.LastSentAt
+	}
+	return now.Add(-sub.Period())
+}
+
+// backingOff reports whether a failed delivery was attempted too recently to retry
+func backingOff(sub *storage.DigestSubscription, now time.Time) bool {
+	return sub.LastError != "" && sub.LastAttemptAt != nil && now.Sub(*sub.LastAttemptAt) < retryBackoff
+}
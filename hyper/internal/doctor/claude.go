@@ -0,0 +1,377 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"hyper/internal/setup"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// probeTool is called in the round trip: read-only, and it needs MongoDB, so
+// a successful call shows the coordinator is fully up
+const probeTool = "code_index_status"
+
+// ClaudeOptions configures DiagnoseClaude
+type ClaudeOptions struct {
+	Home          string        // Directory holding .claude.json
+	Dir           string        // Project directory Claude Code runs in
+	Server        string        // Entry to check; default: every entry that looks like the coordinator
+	Executable    string        // This binary, suggested in fixes
+	Timeout       time.Duration // Bound of each round trip; default one minute
+	SkipRoundTrip bool          // Only check the configuration
+}
+
+// DiagnoseClaude checks the coordinator's entries in the Claude Code MCP
+// configuration and runs an initialize, tools/list and tools/call round trip
+// against each, the way Claude Code would start or reach it
+func DiagnoseClaude(ctx context.Context, opts ClaudeOptions) *Report {
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Minute
+	}
+	report := &Report{}
+	servers := loadClaudeServers(opts.Home, opts.Dir, report)
+
+	var selected []*ClaudeServer
+	var others []string
+	for _, server := range servers {
+		if (opts.Server != "" && server.Name == opts.Server) || (opts.Server == "" && isHyperServer(server)) {
+			selected = append(selected, server)
+		} else {
+			others = append(others, server.Name)
+		}
+	}
+	if len(selected) == 0 {
+		detail := fmt.Sprintf("no coordinator entry in %s or %s", filepath.Join(opts.Home, ".claude.json"), filepath.Join(opts.Dir, ".mcp.json"))
+		if opts.Server != "" {
+			detail = fmt.Sprintf("no entry named %q", opts.Server)
+		}
+		if len(others) > 0 {
+			detail += fmt.Sprintf(" (found: %s)", strings.Join(others, ", "))
+		}
+		report.fail("Claude Code configuration", detail, addServerFix(opts.Executable))
+		return report
+	}
+
+	seen := make(map[string]*ClaudeServer)
+	for _, server := range selected {
+		name := fmt.Sprintf("Server %q", server.Name)
+		if winner, ok := seen[server.Name]; ok {
+			report.warn(name, fmt.Sprintf("%s entry in %s is shadowed by the %s entry and never used", server.Scope, server.Source, winner.Scope),
+				fmt.Sprintf("Remove it: claude mcp remove %s --scope %s", server.Name, server.Scope))
+			continue
+		}
+		seen[server.Name] = server
+		report.ok(name, fmt.Sprintf("%s (%s)", server.describe(), server.Source))
+
+		var runnable bool
+		if server.Transport() == "stdio" {
+			runnable = checkStdioServer(server, opts, report)
+		} else {
+			runnable = checkHTTPServer(ctx, server, report)
+		}
+		if runnable && !opts.SkipRoundTrip {
+			roundTrip(ctx, server, opts, report)
+		}
+	}
+	return report
+}
+
+// addServerFix is the command registering this binary with Claude Code
+func addServerFix(executable string) string {
+	if executable == "" {
+		executable = "/absolute/path/to/hyper"
+	}
+	return fmt.Sprintf("Register it: claude mcp add hyper --scope user -- %s --mode=mcp", executable)
+}
+
+// argValue returns the value of a -name/--name flag in args, and whether it is set
+func argValue(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		trimmed := strings.TrimLeft(arg, "-")
+		if trimmed == arg {
+			continue
+		}
+		if key, value, ok := strings.Cut(trimmed, "="); ok && key == name {
+			return value, true
+		}
+		if trimmed == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// checkStdioServer checks a stdio entry's command, mode and configuration
+// file, and reports whether it can be started
+func checkStdioServer(server *ClaudeServer, opts ClaudeOptions, report *Report) bool {
+	if server.Command == "" {
+		report.fail("Command", "the entry has no command", addServerFix(opts.Executable))
+		return false
+	}
+
+	command := server.Command
+	if !filepath.IsAbs(command) && !strings.ContainsRune(command, filepath.Separator) {
+		resolved, err := exec.LookPath(command)
+		if err != nil {
+			report.fail("Command", fmt.Sprintf("%s is not on PATH", command), addServerFix(opts.Executable))
+			return false
+		}
+		report.warn("Command", fmt.Sprintf("%s resolves to %s on this shell's PATH", command, resolved),
+			"Claude Code may start with a different PATH; use the absolute path: "+addServerFix(resolved))
+		command = resolved
+	} else if info, err := os.Stat(command); err != nil {
+		report.fail("Command", err.Error(), "Rebuild the binary or point the entry at its new location. "+addServerFix(opts.Executable))
+		return false
+	} else if info.IsDir() || info.Mode()&0o111 == 0 {
+		report.fail("Command", fmt.Sprintf("%s is not an executable file", command), "chmod +x "+command)
+		return false
+	} else {
+		report.ok("Command", command)
+	}
+	server.Command = command
+
+	switch mode, ok := argValue(server.Args, "mode"); {
+	case !ok:
+		report.warn("Mode", "no --mode, so the coordinator also starts its HTTP server and a second Claude Code session collides on HTTP_PORT",
+			"Add --mode=mcp to the entry's args")
+	case mode != "mcp":
+		report.warn("Mode", fmt.Sprintf("--mode=%s; Claude Code needs only stdio", mode), "Use --mode=mcp")
+	default:
+		report.ok("Mode", "stdio only (--mode=mcp)")
+	}
+
+	if path, ok := argValue(server.Args, "config"); ok {
+		if _, err := os.Stat(path); err != nil {
+			report.fail("Configuration", err.Error(), "Point --config at an existing file, or create it with `hyper init`")
+			return false
+		}
+		report.ok("Configuration", path)
+		return true
+	}
+
+	profile, _ := argValue(server.Args, "profile")
+	if profile == "" {
+		profile = server.Env["HYPER_PROFILE"]
+	}
+	envFile := setup.EnvFileNameFor(profile)
+	if resolved, err := filepath.EvalSymlinks(command); err == nil {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(resolved), envFile)); err == nil {
+			report.ok("Configuration", filepath.Join(filepath.Dir(resolved), envFile))
+			return true
+		}
+	}
+	if server.Env["MONGODB_URI"] != "" {
+		report.ok("Configuration", "MONGODB_URI set in the entry's env")
+		return true
+	}
+	report.warn("Configuration", fmt.Sprintf("no %s next to the binary, so it is read from the directory of each project Claude Code opens", envFile),
+		"Pass --config=/absolute/path/"+envFile+" in the entry's args")
+	return true
+}
+
+// checkHTTPServer checks an HTTP entry's URL and that the coordinator
+// answers /health, and reports whether a round trip can be tried
+func checkHTTPServer(ctx context.Context, server *ClaudeServer, report *Report) bool {
+	endpoint, err := url.Parse(server.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		report.fail("URL", fmt.Sprintf("%q is not an http(s) URL", server.URL), "Use the coordinator's MCP endpoint, e.g. http://localhost:7095/mcp")
+		return false
+	}
+	if server.Transport() == "sse" {
+		report.warn("Transport", "the coordinator serves streamable HTTP, not SSE",
+			fmt.Sprintf("Re-add it: claude mcp add --transport http %s %s", server.Name, server.URL))
+	}
+	if !strings.HasSuffix(endpoint.Path, "/mcp") {
+		report.warn("URL", fmt.Sprintf("%s does not end in /mcp", server.URL), "The MCP endpoint is served at /mcp")
+	}
+
+	healthURL := (&url.URL{Scheme: endpoint.Scheme, Host: endpoint.Host, Path: "/health"}).String()
+	healthCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(healthCtx, http.MethodGet, healthURL, nil)
+	if err != nil {
+		report.fail("Health", err.Error(), "")
+		return false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		report.fail("Health", err.Error(), "Start the coordinator (hyper --mode=http) or check the host and port")
+		return false
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		report.warn("Health", fmt.Sprintf("%s answered %s", healthURL, resp.Status), "Check the coordinator's logs")
+		return true
+	}
+	report.ok("Health", healthURL)
+	return true
+}
+
+// headerTransport adds an entry's configured headers to every request
+type headerTransport struct {
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// stderrTail keeps the end of a child process's stderr
+type stderrTail struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+const stderrTailBytes = 2048
+
+func (t *stderrTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > stderrTailBytes {
+		t.buf = t.buf[len(t.buf)-stderrTailBytes:]
+	}
+	return len(p), nil
+}
+
+func (t *stderrTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.TrimSpace(string(t.buf))
+}
+
+// startupHints map messages the coordinator logs when it cannot start to fixes
+var startupHints = []struct {
+	contains string
+	fix      string
+}{
+	{"MONGODB_URI environment variable is required", "Set MONGODB_URI in the configuration file or the entry's env"},
+	{"Failed to connect to MongoDB", "Check MONGODB_URI, and that the cluster accepts connections from this machine"},
+	{"Failed to ping MongoDB", "Check MONGODB_URI, and that the cluster accepts connections from this machine"},
+	{"Failed to load config from custom path", "Point --config at an existing file"},
+	{"Failed to ensure code index collection", "Check QDRANT_URL and that Qdrant is running"},
+}
+
+// roundTrip connects to the server as Claude Code would and calls probeTool
+func roundTrip(ctx context.Context, server *ClaudeServer, opts ClaudeOptions, report *Report) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	var transport mcp.Transport
+	stderr := &stderrTail{}
+	if server.Transport() == "stdio" {
+		cmd := exec.Command(server.Command, server.Args...)
+		cmd.Dir = opts.Dir
+		cmd.Env = os.Environ()
+		for key, value := range server.Env {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+		cmd.Stderr = stderr
+		transport = &mcp.CommandTransport{Command: cmd}
+	} else {
+		transport = &mcp.StreamableClientTransport{
+			Endpoint:   server.URL,
+			HTTPClient: &http.Client{Transport: &headerTransport{headers: server.Headers}},
+			MaxRetries: -1,
+		}
+	}
+
+	// Claude Code's clientInfo name, so MCP_TRUSTED_CLIENTS filters tools as it would
+	client := mcp.NewClient(&mcp.Implementation{Name: "claude-code", Version: "hyper-doctor"}, nil)
+	started := time.Now()
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		report.fail("Initialize", failureDetail(err, stderr), failureFix(ctx, server, stderr, opts))
+		return
+	}
+	defer session.Close()
+
+	info := session.InitializeResult()
+	detail := fmt.Sprintf("protocol %s in %s", info.ProtocolVersion, time.Since(started).Round(time.Millisecond))
+	if info.ServerInfo != nil {
+		detail = fmt.Sprintf("%s %s, %s", info.ServerInfo.Name, info.ServerInfo.Version, detail)
+	}
+	report.ok("Initialize", detail)
+
+	count, probe := 0, false
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			report.fail("List tools", failureDetail(err, stderr), failureFix(ctx, server, stderr, opts))
+			return
+		}
+		count++
+		probe = probe || tool.Name == probeTool
+	}
+	if !probe {
+		report.warn("List tools", fmt.Sprintf("%d tools, without %s", count, probeTool),
+			"Check the entry points at the coordinator, and that MCP_TRUSTED_CLIENTS and MCP_STDIO_ROLE allow claude-code to see its tools")
+		return
+	}
+	report.ok("List tools", fmt.Sprintf("%d tools", count))
+
+	started = time.Now()
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: probeTool, Arguments: map[string]interface{}{}})
+	if err != nil {
+		report.fail("Call "+probeTool, failureDetail(err, stderr), failureFix(ctx, server, stderr, opts))
+		return
+	}
+	if result.IsError {
+		text := ""
+		for _, content := range result.Content {
+			if t, ok := content.(*mcp.TextContent); ok {
+				text = t.Text
+				break
+			}
+		}
+		report.fail("Call "+probeTool, text, "Check the coordinator's MongoDB and Qdrant settings")
+		return
+	}
+	report.ok("Call "+probeTool, fmt.Sprintf("answered in %s", time.Since(started).Round(time.Millisecond)))
+}
+
+// failureDetail is an error with the last line the coordinator logged
+func failureDetail(err error, stderr *stderrTail) string {
+	detail := err.Error()
+	if last := lastLine(stderr.String()); last != "" {
+		detail += "; last output: " + last
+	}
+	return detail
+}
+
+// lastLine returns the last line of output, where a fatal startup error is logged
+func lastLine(output string) string {
+	lines := strings.Split(output, "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// failureFix suggests what to do about a failed round trip
+func failureFix(ctx context.Context, server *ClaudeServer, stderr *stderrTail, opts ClaudeOptions) string {
+	last := lastLine(stderr.String())
+	for _, hint := range startupHints {
+		if strings.Contains(last, hint.contains) {
+			return hint.fix
+		}
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Sprintf("No answer within %s: check MongoDB and Qdrant are reachable, or raise --timeout", opts.Timeout)
+	}
+	if server.Transport() == "stdio" {
+		return fmt.Sprintf("Run it in a terminal to see its full output: %s %s", server.Command, strings.Join(server.Args, " "))
+	}
+	return "Check the URL and any Authorization header the entry sends"
+}
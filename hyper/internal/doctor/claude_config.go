@@ -0,0 +1,155 @@
+package doctor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Claude Code configuration scopes, in order of precedence
+const (
+	ScopeLocal   = "local"   // ~/.claude.json, under the project's directory
+	ScopeProject = "project" // .mcp.json in the project, shared through the repository
+	ScopeUser    = "user"    // ~/.claude.json, for every project
+)
+
+// ClaudeServer is an MCP server entry of the Claude Code configuration
+type ClaudeServer struct {
+	Name    string            `json:"-"`
+	Scope   string            `json:"-"`
+	Source  string            `json:"-"` // File the entry was read from
+	Type    string            `json:"type,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Transport is the entry's transport: stdio unless a URL is given
+func (s *ClaudeServer) Transport() string {
+	if s.Type != "" {
+		return s.Type
+	}
+	if s.URL != "" {
+		return "http"
+	}
+	return "stdio"
+}
+
+// claudeConfigFile is the part of ~/.claude.json and .mcp.json the doctor reads
+type claudeConfigFile struct {
+	MCPServers map[string]*ClaudeServer `json:"mcpServers"`
+	Projects   map[string]struct {
+		MCPServers map[string]*ClaudeServer `json:"mcpServers"`
+	} `json:"projects"`
+}
+
+// loadClaudeServers reads the MCP servers Claude Code sees in dir: local and
+// user entries from home/.claude.json and project entries from dir/.mcp.json.
+// Missing files are skipped; unreadable ones are reported.
+func loadClaudeServers(home, dir string, report *Report) []*ClaudeServer {
+	var local, project, user []*ClaudeServer
+
+	userPath := filepath.Join(home, ".claude.json")
+	if config, ok := readClaudeConfig(userPath, report); ok {
+		if entry, found := config.Projects[dir]; found {
+			local = scopedServers(entry.MCPServers, ScopeLocal, userPath)
+		}
+		user = scopedServers(config.MCPServers, ScopeUser, userPath)
+	}
+
+	projectPath := filepath.Join(dir, ".mcp.json")
+	if config, ok := readClaudeConfig(projectPath, report); ok {
+		project = scopedServers(config.MCPServers, ScopeProject, projectPath)
+	}
+
+	return append(append(local, project...), user...)
+}
+
+// readClaudeConfig parses a Claude Code configuration file
+func readClaudeConfig(path string, report *Report) (*claudeConfigFile, bool) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false
+	}
+	if err != nil {
+		report.fail("Read "+path, err.Error(), "Check the file's permissions")
+		return nil, false
+	}
+
+	var config claudeConfigFile
+	if err := json.Unmarshal(data, &config); err != nil {
+		report.fail("Parse "+path, err.Error(), "Fix the JSON syntax; Claude Code ignores a file it cannot parse")
+		return nil, false
+	}
+	return &config, true
+}
+
+// scopedServers returns the entries of one scope sorted by name, with
+// ${VAR} and ${VAR:-default} expanded as Claude Code does
+func scopedServers(entries map[string]*ClaudeServer, scope, source string) []*ClaudeServer {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	servers := make([]*ClaudeServer, 0, len(names))
+	for _, name := range names {
+		server := entries[name]
+		if server == nil {
+			continue
+		}
+		server.Name, server.Scope, server.Source = name, scope, source
+		server.Command = expandVars(server.Command)
+		server.URL = expandVars(server.URL)
+		for i, arg := range server.Args {
+			server.Args[i] = expandVars(arg)
+		}
+		for key, value := range server.Env {
+			server.Env[key] = expandVars(value)
+		}
+		for key, value := range server.Headers {
+			server.Headers[key] = expandVars(value)
+		}
+		servers = append(servers, server)
+	}
+	return servers
+}
+
+// expandVars expands ${VAR} and ${VAR:-default} from the environment
+func expandVars(s string) string {
+	return os.Expand(s, func(name string) string {
+		if key, fallback, ok := strings.Cut(name, ":-"); ok {
+			if value, set := os.LookupEnv(key); set && value != "" {
+				return value
+			}
+			return fallback
+		}
+		return os.Getenv(name)
+	})
+}
+
+// isHyperServer reports whether an entry looks like the coordinator: named
+// hyper*, or running a hyper binary
+func isHyperServer(server *ClaudeServer) bool {
+	if strings.Contains(strings.ToLower(server.Name), "hyper") {
+		return true
+	}
+	base := strings.ToLower(filepath.Base(server.Command))
+	return strings.HasPrefix(base, "hyper")
+}
+
+// describe is a one-line summary of an entry
+func (s *ClaudeServer) describe() string {
+	if s.Transport() == "stdio" {
+		return fmt.Sprintf("%s scope, stdio: %s", s.Scope, strings.TrimSpace(s.Command+" "+strings.Join(s.Args, " ")))
+	}
+	return fmt.Sprintf("%s scope, %s: %s", s.Scope, s.Transport(), s.URL)
+}
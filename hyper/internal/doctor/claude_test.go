@@ -0,0 +1,211 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func checkNamed(report *Report, name string) *Check {
+	for i := range report.Checks {
+		if report.Checks[i].Name == name {
+			return &report.Checks[i]
+		}
+	}
+	return nil
+}
+
+func TestLoadClaudeServers(t *testing.T) {
+	home, dir := t.TempDir(), t.TempDir()
+	t.Setenv("HYPER_BIN", "/opt/hyper/bin")
+	writeFile(t, filepath.Join(home, ".claude.json"), `{
+		"mcpServers": {
+			"hyper": {"command": "${HYPER_BIN}/hyper", "args": ["--mode=mcp"]},
+			"github": {"type": "http", "url": "https://api.example.com/mcp"}
+		},
+		"projects": {
+			"`+dir+`": {"mcpServers": {"hyper-dev": {"command": "./hyper", "env": {"HYPER_PROFILE": "${HYPER_PROFILE_UNSET:-dev}"}}}}
+		}
+	}`)
+	writeFile(t, filepath.Join(dir, ".mcp.json"), `{"mcpServers": {"hyper": {"type": "http", "url": "http://localhost:7095/mcp"}}}`)
+
+	report := &Report{}
+	servers := loadClaudeServers(home, dir, report)
+	assert.Empty(t, report.Checks)
+	require.Len(t, servers, 4)
+
+	assert.Equal(t, "hyper-dev", servers[0].Name)
+	assert.Equal(t, ScopeLocal, servers[0].Scope)
+	assert.Equal(t, "dev", servers[0].Env["HYPER_PROFILE"], "defaults are expanded")
+
+	assert.Equal(t, "hyper", servers[1].Name)
+	assert.Equal(t, ScopeProject, servers[1].Scope)
+	assert.Equal(t, "http", servers[1].Transport())
+
+	assert.Equal(t, "github", servers[2].Name)
+	assert.Equal(t, ScopeUser, servers[2].Scope)
+	assert.Equal(t, "/opt/hyper/bin/hyper", servers[3].Command, "variables are expanded")
+	assert.Equal(t, "stdio", servers[3].Transport())
+}
+
+func TestLoadClaudeServers_InvalidJSON(t *testing.T) {
+	home, dir := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(dir, ".mcp.json"), `{"mcpServers": {`)
+
+	report := &Report{}
+	assert.Empty(t, loadClaudeServers(home, dir, report), "a missing ~/.claude.json is skipped")
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, StatusFail, report.Checks[0].Status)
+	assert.Contains(t, report.Checks[0].Name, ".mcp.json")
+}
+
+func TestDiagnoseClaude_NoEntry(t *testing.T) {
+	home, dir := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(home, ".claude.json"), `{"mcpServers": {"github": {"type": "http", "url": "https://api.example.com/mcp"}}}`)
+
+	report := DiagnoseClaude(context.Background(), ClaudeOptions{Home: home, Dir: dir, Executable: "/usr/local/bin/hyper"})
+	require.True(t, report.Failed())
+	assert.Contains(t, report.Checks[0].Detail, "found: github")
+	assert.Equal(t, "Register it: claude mcp add hyper --scope user -- /usr/local/bin/hyper --mode=mcp", report.Checks[0].Fix)
+}
+
+func TestDiagnoseClaude_Shadowed(t *testing.T) {
+	home, dir := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(home, ".claude.json"), `{"mcpServers": {"hyper": {"command": "/missing/hyper"}}}`)
+	writeFile(t, filepath.Join(dir, ".mcp.json"), `{"mcpServers": {"hyper": {"type": "sse", "url": "not a url"}}}`)
+
+	report := DiagnoseClaude(context.Background(), ClaudeOptions{Home: home, Dir: dir, SkipRoundTrip: true})
+	require.True(t, report.Failed())
+
+	url := checkNamed(report, "URL")
+	require.NotNil(t, url, "the project entry is checked")
+	assert.Equal(t, StatusFail, url.Status)
+
+	shadowed := report.Checks[len(report.Checks)-1]
+	assert.Equal(t, StatusWarn, shadowed.Status)
+	assert.Contains(t, shadowed.Detail, "user entry")
+	assert.Equal(t, "Remove it: claude mcp remove hyper --scope user", shadowed.Fix)
+	assert.Nil(t, checkNamed(report, "Command"), "shadowed entries are not checked")
+}
+
+func TestCheckStdioServer(t *testing.T) {
+	binDir := t.TempDir()
+	binary := filepath.Join(binDir, "hyper")
+	writeFile(t, binary, "#!/bin/sh\n")
+	require.NoError(t, os.Chmod(binary, 0o755))
+
+	t.Run("missing command", func(t *testing.T) {
+		report := &Report{}
+		ok := checkStdioServer(&ClaudeServer{Command: filepath.Join(binDir, "gone")}, ClaudeOptions{}, report)
+		assert.False(t, ok)
+		assert.Equal(t, StatusFail, checkNamed(report, "Command").Status)
+	})
+
+	t.Run("not executable", func(t *testing.T) {
+		plain := filepath.Join(binDir, "hyper.txt")
+		writeFile(t, plain, "")
+		report := &Report{}
+		assert.False(t, checkStdioServer(&ClaudeServer{Command: plain}, ClaudeOptions{}, report))
+		assert.Equal(t, "chmod +x "+plain, checkNamed(report, "Command").Fix)
+	})
+
+	t.Run("without mode or configuration", func(t *testing.T) {
+		report := &Report{}
+		assert.True(t, checkStdioServer(&ClaudeServer{Command: binary}, ClaudeOptions{}, report))
+		assert.False(t, report.Failed())
+		assert.Equal(t, StatusWarn, checkNamed(report, "Mode").Status)
+		assert.Equal(t, StatusWarn, checkNamed(report, "Configuration").Status)
+	})
+
+	t.Run("env file next to the binary", func(t *testing.T) {
+		writeFile(t, filepath.Join(binDir, ".env.hyper.dev"), "")
+		report := &Report{}
+		server := &ClaudeServer{Command: binary, Args: []string{"--mode", "mcp", "--profile=dev"}}
+		assert.True(t, checkStdioServer(server, ClaudeOptions{}, report))
+		assert.Equal(t, StatusOK, checkNamed(report, "Mode").Status)
+		configuration := checkNamed(report, "Configuration")
+		assert.Equal(t, StatusOK, configuration.Status)
+		assert.Equal(t, filepath.Join(binDir, ".env.hyper.dev"), configuration.Detail)
+	})
+
+	t.Run("missing config file", func(t *testing.T) {
+		report := &Report{}
+		server := &ClaudeServer{Command: binary, Args: []string{"--mode=mcp", "--config=" + filepath.Join(binDir, "missing.env")}}
+		assert.False(t, checkStdioServer(server, ClaudeOptions{}, report))
+		assert.Equal(t, StatusFail, checkNamed(report, "Configuration").Status)
+	})
+}
+
+func TestArgValue(t *testing.T) {
+	args := []string{"--mode=mcp", "-config", "/etc/hyper.env", "profile", "dev"}
+
+	value, ok := argValue(args, "mode")
+	assert.True(t, ok)
+	assert.Equal(t, "mcp", value)
+
+	value, ok = argValue(args, "config")
+	assert.True(t, ok)
+	assert.Equal(t, "/etc/hyper.env", value)
+
+	_, ok = argValue(args, "profile")
+	assert.False(t, ok, "positional arguments are not flags")
+}
+
+func TestDiagnoseClaude_HTTPRoundTrip(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "hyper", Version: "test"}, nil)
+	var client string
+	mcp.AddTool(server, &mcp.Tool{Name: probeTool}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		client = req.Session.InitializeParams().ClientInfo.Name
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "indexed"}}}, nil, nil
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	home, dir := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(dir, ".mcp.json"), `{"mcpServers": {"hyper": {"type": "http", "url": "`+ts.URL+`/mcp"}}}`)
+
+	report := DiagnoseClaude(context.Background(), ClaudeOptions{Home: home, Dir: dir, Timeout: 10 * time.Second})
+	var out bytes.Buffer
+	report.Write(&out)
+	require.False(t, report.Failed(), out.String())
+
+	for _, name := range []string{"Health", "Initialize", "List tools", "Call " + probeTool} {
+		check := checkNamed(report, name)
+		require.NotNil(t, check, name)
+		assert.Equal(t, StatusOK, check.Status, name)
+	}
+	assert.Equal(t, "claude-code", client)
+	assert.Contains(t, out.String(), "No problems found")
+}
+
+func TestDiagnoseClaude_HTTPDown(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	home, dir := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(dir, ".mcp.json"), `{"mcpServers": {"hyper": {"type": "http", "url": "`+ts.URL+`/mcp"}}}`)
+
+	report := DiagnoseClaude(context.Background(), ClaudeOptions{Home: home, Dir: dir, Timeout: time.Second})
+	require.True(t, report.Failed())
+	health := checkNamed(report, "Health")
+	assert.Equal(t, StatusFail, health.Status)
+	assert.Nil(t, checkNamed(report, "Initialize"), "no round trip against a server that is down")
+}
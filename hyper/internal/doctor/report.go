@@ -0,0 +1,84 @@
+// Package doctor diagnoses how MCP clients are connected to the coordinator
+// and prints fixes for what it finds, for `hyper doctor`.
+package doctor
+
+import (
+	"fmt"
+	"io"
+)
+
+// Check outcomes
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn" // Works, but likely to cause trouble
+	StatusFail = "fail" // Broken
+)
+
+// Check is the outcome of one diagnostic step
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Fix    string `json:"fix,omitempty"` // What to do about a warning or failure
+}
+
+// Report collects the checks of a diagnosis, in the order they ran
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+func (r *Report) ok(name, detail string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: StatusOK, Detail: detail})
+}
+
+func (r *Report) warn(name, detail, fix string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: StatusWarn, Detail: detail, Fix: fix})
+}
+
+func (r *Report) fail(name, detail, fix string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: StatusFail, Detail: detail, Fix: fix})
+}
+
+// Failed reports whether any check failed
+func (r *Report) Failed() bool {
+	for _, check := range r.Checks {
+		if check.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Write prints the checks with their fixes, and a summary line
+func (r *Report) Write(w io.Writer) {
+	warnings, failures := 0, 0
+	for _, check := range r.Checks {
+		mark := "✓"
+		switch check.Status {
+		case StatusWarn:
+			mark = "!"
+			warnings++
+		case StatusFail:
+			mark = "✗"
+			failures++
+		}
+		line := fmt.Sprintf("%s %s", mark, check.Name)
+		if check.Detail != "" {
+			line += ": " + check.Detail
+		}
+		fmt.Fprintln(w, line)
+		if check.Fix != "" {
+			fmt.Fprintf(w, "    → %s\n", check.Fix)
+		}
+	}
+
+	fmt.Fprintln(w)
+	switch {
+	case failures > 0:
+		fmt.Fprintf(w, "%d problem(s) and %d warning(s) found\n", failures, warnings)
+	case warnings > 0:
+		fmt.Fprintf(w, "No problems, %d warning(s)\n", warnings)
+	default:
+		fmt.Fprintln(w, "No problems found")
+	}
+}
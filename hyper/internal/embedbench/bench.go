@@ -0,0 +1,169 @@
+package embedbench
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+)
+
+// batchSize is the number of documents embedded per request
+const batchSize = 32
+
+// Provider is an embedding client under test
+type Provider struct {
+	Name   string
+	Client embeddings.EmbeddingClient
+}
+
+// Result holds the scores of one provider
+type Result struct {
+	Provider         string
+	Model            string
+	Dimensions       int
+	K                int
+	Cases            int
+	RecallAtK        float64
+	MRR              float64
+	DocLatency       time.Duration // Average embedding time per document
+	QueryLatencyP50  time.Duration
+	QueryLatencyP95  time.Duration
+	EstimatedCostUSD float64
+	Err              string
+}
+
+// Run embeds the dataset with the provider and scores retrieval at k
+func Run(provider Provider, dataset *Dataset, k int) Result {
+	pricing := embeddings.PricingFor(provider.Client)
+	result := Result{Provider: provider.Name, Model: pricing.Model, K: k, Cases: len(dataset.Cases)}
+
+	// Embed the corpus
+	var tokens int64
+	vectors := make([][]float32, 0, len(dataset.Documents))
+	start := time.Now()
+	for i := 0; i < len(dataset.Documents); i += batchSize {
+		end := min(i+batchSize, len(dataset.Documents))
+		texts := make([]string, 0, end-i)
+		for _, doc := range dataset.Documents[i:end] {
+			texts = append(texts, doc.Text)
+			tokens += embeddings.EstimateTokens(doc.Text)
+		}
+		batch, err := provider.Client.CreateEmbeddings(texts)
+		if err != nil {
+			result.Err = fmt.Sprintf("failed to embed documents: %v", err)
+			return result
+		}
+		vectors = append(vectors, batch...)
+	}
+	result.DocLatency = time.Since(start) / time.Duration(len(dataset.Documents))
+	result.Dimensions = provider.Client.GetDimensions()
+
+	// Query and score
+	latencies := make([]time.Duration, 0, len(dataset.Cases))
+	var recallSum, rrSum float64
+	for _, c := range dataset.Cases {
+		start := time.Now()
+		query, err := provider.Client.CreateEmbedding(c.Query)
+		if err != nil {
+			result.Err = fmt.Sprintf("failed to embed query: %v", err)
+			return result
+		}
+		latencies = append(latencies, time.Since(start))
+		tokens += embeddings.EstimateTokens(c.Query)
+
+		ranked := rank(query, vectors)
+		recall, rr := score(ranked, dataset.Documents, c.Relevant, k)
+		recallSum += recall
+		rrSum += rr
+	}
+
+	result.RecallAtK = recallSum / float64(len(dataset.Cases))
+	result.MRR = rrSum / float64(len(dataset.Cases))
+	result.QueryLatencyP50 = percentile(latencies, 0.50)
+	result.QueryLatencyP95 = percentile(latencies, 0.95)
+	result.EstimatedCostUSD = pricing.Estimate(tokens).EstimatedCostUSD
+	return result
+}
+
+// rank returns document indexes ordered by descending cosine similarity to query
+func rank(query []float32, vectors [][]float32) []int {
+	scores := make([]float64, len(vectors))
+	order := make([]int, len(vectors))
+	for i, v := range vectors {
+		scores[i] = cosine(query, v)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	return order
+}
+
+// score returns recall@k and the reciprocal rank of the first relevant document
+func score(ranked []int, docs []Document, relevant []string, k int) (float64, float64) {
+	want := make(map[string]bool, len(relevant))
+	for _, id := range relevant {
+		want[id] = true
+	}
+
+	found, rr := 0, 0.0
+	for pos, idx := range ranked {
+		if !want[docs[idx].ID] {
+			continue
+		}
+		if rr == 0 {
+			rr = 1 / float64(pos+1)
+		}
+		if pos < k {
+			found++
+		}
+	}
+	return float64(found) / float64(len(want)), rr
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return -1
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func percentile(values []time.Duration, p float64) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(idx, 0)]
+}
+
+// WriteReport prints results as an aligned table
+func WriteReport(out io.Writer, results []Result) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if len(results) > 0 {
+		fmt.Fprintf(tw, "PROVIDER\tMODEL\tDIMS\tRECALL@%d\tMRR\tDOC LATENCY\tQUERY P50\tQUERY P95\tEST. COST\n", results[0].K)
+	}
+	for _, r := range results {
+		if r.Err != "" {
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t-\t-\t-\t%s\n", r.Provider, r.Model, r.Err)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.3f\t%.3f\t%s\t%s\t%s\t$%.4f\n",
+			r.Provider, r.Model, r.Dimensions, r.RecallAtK, r.MRR,
+			r.DocLatency.Round(time.Microsecond), r.QueryLatencyP50.Round(time.Microsecond),
+			r.QueryLatencyP95.Round(time.Microsecond), r.EstimatedCostUSD)
+	}
+	tw.Flush()
+}
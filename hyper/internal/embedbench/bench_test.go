@@ -0,0 +1,86 @@
+package embedbench
+
+import (
+	"strings"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keywordClient embeds text as counts of a fixed vocabulary
+type keywordClient struct{ vocab []string }
+
+func (c *keywordClient) CreateEmbedding(text string) ([]float32, error) {
+	vector := make([]float32, len(c.vocab))
+	for i, word := range c.vocab {
+		vector[i] = float32(strings.Count(strings.ToLower(text), word))
+	}
+	return vector, nil
+}
+
+func (c *keywordClient) CreateEmbeddings(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = c.CreateEmbedding(text)
+	}
+	return vectors, nil
+}
+
+func (c *keywordClient) GetDimensions() int { return len(c.vocab) }
+
+func TestRun(t *testing.T) {
+	dataset := &Dataset{
+		Documents: []Document{
+			{ID: "auth", Text: "func login(user, password) { token := sign(user) }"},
+			{ID: "db", Text: "func connect(uri) { mongo.Connect(uri) }"},
+			{ID: "http", Text: "func serve(port) { http.Listen(port) }"},
+		},
+		Cases: []Case{
+			{Query: "login with password", Relevant: []string{"auth"}},
+			{Query: "connect to mongo", Relevant: []string{"db"}},
+			{Query: "listen on http port", Relevant: []string{"http"}},
+			{Query: "password token", Relevant: []string{"db"}}, // deliberately wrong label
+		},
+	}
+	client := &keywordClient{vocab: []string{"login", "password", "mongo", "connect", "http", "port", "token"}}
+
+	result := Run(Provider{Name: "keyword", Client: client}, dataset, 1)
+
+	require.Empty(t, result.Err)
+	assert.Equal(t, 4, result.Cases)
+	assert.Equal(t, 7, result.Dimensions)
+	assert.InDelta(t, 0.75, result.RecallAtK, 1e-9)
+	assert.Less(t, result.MRR, 1.0)
+	assert.Greater(t, result.MRR, 0.75)
+}
+
+func TestGenerateDataset(t *testing.T) {
+	chunks := []*storage.FileChunk{
+		{ID: "a", Content: "// ValidateToken checks the signature of a session token\nfunc ValidateToken() {}\n"},
+		{ID: "b", Content: "x := 1\n"},
+	}
+
+	dataset, err := GenerateDataset(chunks, 10)
+	require.NoError(t, err)
+
+	assert.Len(t, dataset.Documents, 2, "chunks without comments stay as distractors")
+	require.Len(t, dataset.Cases, 1)
+	assert.Equal(t, "ValidateToken checks the signature of a session token", dataset.Cases[0].Query)
+	assert.Equal(t, []string{"a"}, dataset.Cases[0].Relevant)
+	assert.NotContains(t, dataset.Documents[0].Text, "signature", "the query line is removed from the document")
+	assert.NoError(t, dataset.Validate())
+
+	_, err = GenerateDataset(chunks[1:], 10)
+	assert.Error(t, err)
+}
+
+func TestDatasetValidate(t *testing.T) {
+	dataset := &Dataset{
+		Documents: []Document{{ID: "a", Text: "x"}},
+		Cases:     []Case{{Query: "q", Relevant: []string{"missing"}}},
+	}
+	assert.ErrorContains(t, dataset.Validate(), "unknown document")
+}
@@ -0,0 +1,133 @@
+// Package embedbench measures how well embedding providers retrieve code. It
+// embeds a labelled corpus with each provider, runs every query against it
+// with exact cosine search and reports recall@k, MRR and latency.
+package embedbench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"hyper/internal/mcp/storage"
+)
+
+// minQueryLength is the shortest comment accepted as a generated query
+const minQueryLength = 20
+
+// Document is one searchable text in the benchmark corpus
+type Document struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// Case is a query and the IDs of the documents that should answer it
+type Case struct {
+	Query    string   `json:"query"`
+	Relevant []string `json:"relevant"`
+}
+
+// Dataset is a labelled retrieval benchmark
+type Dataset struct {
+	Documents []Document `json:"documents"`
+	Cases     []Case     `json:"cases"`
+}
+
+// LoadDataset reads a dataset from a JSON file
+func LoadDataset(path string) (*Dataset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+
+	var dataset Dataset
+	if err := json.Unmarshal(data, &dataset); err != nil {
+		return nil, fmt.Errorf("failed to parse dataset: %w", err)
+	}
+	if err := dataset.Validate(); err != nil {
+		return nil, err
+	}
+	return &dataset, nil
+}
+
+// Validate checks that every case has a query and only references known documents
+func (d *Dataset) Validate() error {
+	if len(d.Documents) == 0 || len(d.Cases) == 0 {
+		return fmt.Errorf("dataset must contain documents and cases")
+	}
+
+	ids := make(map[string]bool, len(d.Documents))
+	for _, doc := range d.Documents {
+		if doc.ID == "" {
+			return fmt.Errorf("every document must have an id")
+		}
+		ids[doc.ID] = true
+	}
+	for i, c := range d.Cases {
+		if strings.TrimSpace(c.Query) == "" || len(c.Relevant) == 0 {
+			return fmt.Errorf("case %d must have a query and at least one relevant document", i)
+		}
+		for _, id := range c.Relevant {
+			if !ids[id] {
+				return fmt.Errorf("case %d references unknown document %q", i, id)
+			}
+		}
+	}
+	return nil
+}
+
+// GenerateDataset builds a dataset from indexed chunks. A chunk's longest
+// comment becomes the query and is removed from the chunk text, so the chunk
+// must be found from its code alone. Chunks without a usable comment remain in
+// the corpus as distractors. At most maxCases cases are generated.
+func GenerateDataset(chunks []*storage.FileChunk, maxCases int) (*Dataset, error) {
+	dataset := &Dataset{}
+	for _, chunk := range chunks {
+		id := chunk.ID
+		if id == "" {
+			id = fmt.Sprintf("%s_%d", chunk.FileID, chunk.ChunkNum)
+		}
+
+		query, text := extractQuery(chunk.Content)
+		dataset.Documents = append(dataset.Documents, Document{ID: id, Text: text})
+		if query != "" && len(dataset.Cases) < maxCases {
+			dataset.Cases = append(dataset.Cases, Case{Query: query, Relevant: []string{id}})
+		}
+	}
+
+	if len(dataset.Cases) == 0 {
+		return nil, fmt.Errorf("no commented code found to generate queries from; provide a dataset file")
+	}
+	return dataset, nil
+}
+
+// extractQuery returns the longest comment line of content (without comment
+// markers) and content with that line removed
+func extractQuery(content string) (string, string) {
+	lines := strings.Split(content, "\n")
+	best, bestLine := "", -1
+	for i, line := range lines {
+		comment := commentText(line)
+		if len(comment) >= minQueryLength && len(comment) > len(best) {
+			best, bestLine = comment, i
+		}
+	}
+	if bestLine < 0 {
+		return "", content
+	}
+
+	rest := append(lines[:bestLine:bestLine], lines[bestLine+1:]...)
+	return best, strings.Join(rest, "\n")
+}
+
+// commentText returns the text of a single-line comment, or "" if line is not one
+func commentText(line string) string {
+	trimmed := strings.TrimSpace(line)
+	for _, marker := range []string{"///", "//", "#", "/**", "/*", "*", "--"} {
+		if strings.HasPrefix(trimmed, marker) {
+			text := strings.TrimSpace(strings.TrimPrefix(trimmed, marker))
+			return strings.TrimSpace(strings.TrimSuffix(text, "*/"))
+		}
+	}
+	return ""
+}
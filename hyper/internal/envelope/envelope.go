@@ -0,0 +1,77 @@
+// Package envelope defines the response envelope shared by every REST endpoint.
+//
+// All /api responses have the shape
+//
+//	{"data": ..., "error": {"code", "message", "details"}, "meta": {"pagination": ...}}
+//
+// data is null on failure, error is omitted on success and meta is only set for
+// lists, so clients can decode every endpoint the same way.
+package envelope
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response is the body of every REST response
+type Response struct {
+	Data  interface{} `json:"data"`
+	Error *Error      `json:"error,omitempty"`
+	Meta  *Meta       `json:"meta,omitempty"`
+}
+
+// Error describes a failed request; Code is an errcode.Code
+type Error struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Meta carries response metadata
+type Meta struct {
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes the window of a list returned in data
+type Pagination struct {
+	Total   int  `json:"total"`
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	HasMore bool `json:"hasMore"`
+}
+
+// NewPagination describes a window of limit items starting at offset out of total
+func NewPagination(total, limit, offset int) *Pagination {
+	return &Pagination{
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: offset+limit < total,
+	}
+}
+
+// Complete describes an unpaginated list of total items
+func Complete(total int) *Pagination {
+	return NewPagination(total, total, 0)
+}
+
+// JSON writes data with the given status
+func JSON(c *gin.Context, status int, data interface{}) {
+	c.JSON(status, Response{Data: data})
+}
+
+// OK writes data with status 200
+func OK(c *gin.Context, data interface{}) {
+	JSON(c, http.StatusOK, data)
+}
+
+// List writes a list with its pagination metadata and status 200
+func List(c *gin.Context, data interface{}, pagination *Pagination) {
+	c.JSON(http.StatusOK, Response{Data: data, Meta: &Meta{Pagination: pagination}})
+}
+
+// Fail writes an error; callers normally go through errcode.RespondCode
+func Fail(c *gin.Context, status int, code, message string, details interface{}) {
+	c.JSON(status, Response{Error: &Error{Code: code, Message: message, Details: details}})
+}
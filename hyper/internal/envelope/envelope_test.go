@@ -0,0 +1,68 @@
+package envelope
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON body %s: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+func TestList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	List(c, []string{"a", "b"}, NewPagination(5, 2, 2))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	body := decode(t, w)
+	if _, ok := body["error"]; ok {
+		t.Errorf("successful response has an error: %v", body)
+	}
+	pagination := body["meta"].(map[string]interface{})["pagination"].(map[string]interface{})
+	if pagination["total"] != float64(5) || pagination["hasMore"] != true {
+		t.Errorf("pagination = %v, want total 5 with more", pagination)
+	}
+}
+
+func TestFail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	Fail(c, http.StatusBadRequest, "VALIDATION", "name is required", map[string]string{"field": "name"})
+
+	body := decode(t, w)
+	if body["data"] != nil {
+		t.Errorf("failed response has data: %v", body["data"])
+	}
+	errObj := body["error"].(map[string]interface{})
+	if errObj["code"] != "VALIDATION" || errObj["message"] != "name is required" {
+		t.Errorf("error = %v", errObj)
+	}
+	if errObj["details"].(map[string]interface{})["field"] != "name" {
+		t.Errorf("details = %v", errObj["details"])
+	}
+}
+
+func TestNewPagination(t *testing.T) {
+	if p := NewPagination(10, 5, 5); p.HasMore {
+		t.Errorf("last page reported more results: %+v", p)
+	}
+	if p := Complete(3); p.HasMore || p.Limit != 3 {
+		t.Errorf("Complete(3) = %+v", p)
+	}
+}
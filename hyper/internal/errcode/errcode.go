@@ -0,0 +1,139 @@
+// Package errcode defines the error taxonomy shared by MCP tool results and the REST API.
+//
+// Tool failures carry the code in CallToolResult.StructuredContent and REST
+// errors carry it in the error.code field of the response envelope, so callers
+// can branch on the code instead of parsing free-text messages.
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"hyper/internal/envelope"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Code identifies a class of error
+type Code string
+
+const (
+	NotFound              Code = "NOT_FOUND"
+	Unauthenticated       Code = "UNAUTHENTICATED"
+	Validation            Code = "VALIDATION"
+	Conflict              Code = "CONFLICT"
+	DependencyUnavailable Code = "DEPENDENCY_UNAVAILABLE"
+	RateLimited           Code = "RATE_LIMITED"
+	PermissionDenied      Code = "PERMISSION_DENIED"
+	Internal              Code = "INTERNAL"
+)
+
+// HTTPStatus maps a code to the REST status it is reported with
+func (c Code) HTTPStatus() int {
+	switch c {
+	case NotFound:
+		return http.StatusNotFound
+	case Unauthenticated:
+		return http.StatusUnauthorized
+	case Validation:
+		return http.StatusBadRequest
+	case Conflict:
+		return http.StatusConflict
+	case DependencyUnavailable:
+		return http.StatusServiceUnavailable
+	case RateLimited:
+		return http.StatusTooManyRequests
+	case PermissionDenied:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error is an error tagged with a Code
+type Error struct {
+	Code    Code
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates a coded error
+func New(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap tags an existing error with a code
+func Wrap(code Code, err error, message string) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// Of returns the code of err: the code of the first coded error in its chain,
+// otherwise a code classified from its message. Storage and tool errors should
+// carry their code from the source; classification is a fallback for errors
+// from dependencies that do not.
+func Of(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return Classify(err.Error())
+}
+
+// classifyRules map message fragments used across storage and tool errors to codes.
+// Order matters: the first matching rule wins.
+var classifyRules = []struct {
+	code      Code
+	fragments []string
+}{
+	{RateLimited, []string{"rate limit", "too many requests", "quota exceeded"}},
+	{PermissionDenied, []string{"permission denied", "access denied", "forbidden", "unauthorized"}},
+	{DependencyUnavailable, []string{"connection refused", "dial tcp", "no such host", "unavailable", "timeout", "timed out", "deadline exceeded", "server selection error"}},
+	{NotFound, []string{"not found", "no such file", "does not exist", "no documents in result"}},
+	{Conflict, []string{"already exists", "duplicate key", "conflict", "already in progress"}},
+	{Validation, []string{"is required", "must be", "invalid", "cannot be empty", "must not", "not allowed", "unsupported", "exceeds"}},
+}
+
+// Classify infers a code from a free-text error message. It only guesses, so
+// prefer returning New or Wrap where the failure is known.
+func Classify(message string) Code {
+	lower := strings.ToLower(message)
+	for _, rule := range classifyRules {
+		for _, fragment := range rule.fragments {
+			if strings.Contains(lower, fragment) {
+				return rule.code
+			}
+		}
+	}
+	return Internal
+}
+
+// Respond writes a REST error with the status and code derived from err
+func Respond(c *gin.Context, err error, message string) {
+	RespondCode(c, Of(err), message)
+}
+
+// RespondCode writes a REST error with an explicit code
+func RespondCode(c *gin.Context, code Code, message string) {
+	RespondDetails(c, code, message, nil)
+}
+
+// RespondDetails writes a REST error with an explicit code and structured details
+func RespondDetails(c *gin.Context, code Code, message string, details interface{}) {
+	envelope.Fail(c, code.HTTPStatus(), string(code), message, details)
+}
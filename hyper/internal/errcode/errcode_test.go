@@ -0,0 +1,66 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		message string
+		want    Code
+	}{
+		{"agent task with ID abc not found", NotFound},
+		{"query parameter is required and must be a non-empty string", Validation},
+		{"server already exists: github", Conflict},
+		{"failed to search: dial tcp 127.0.0.1:6333: connect: connection refused", DependencyUnavailable},
+		{"embedding API returned 429: rate limit exceeded", RateLimited},
+		{"permission denied: tool bash requires role operator", PermissionDenied},
+		{"something unexpected happened", Internal},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.message); got != tt.want {
+			t.Errorf("Classify(%q) = %s, want %s", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestOfPrefersExplicitCode(t *testing.T) {
+	err := fmt.Errorf("failed to load: %w", New(Conflict, "revision %d is stale", 3))
+	if got := Of(err); got != Conflict {
+		t.Errorf("Of(wrapped coded error) = %s, want %s", got, Conflict)
+	}
+
+	if got := Of(errors.New("file not found: x.go")); got != NotFound {
+		t.Errorf("Of(plain error) = %s, want %s", got, NotFound)
+	}
+
+	if got := Of(nil); got != "" {
+		t.Errorf("Of(nil) = %q, want empty", got)
+	}
+}
+
+func TestRespond(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	Respond(c, errors.New("human task with ID 1 not found"), "Task not found")
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if !strings.Contains(w.Body.String(), `"code":"NOT_FOUND"`) {
+		t.Errorf("body missing code: %s", w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"data":null`) {
+		t.Errorf("body is not enveloped: %s", w.Body.String())
+	}
+}
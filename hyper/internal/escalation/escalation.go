@@ -0,0 +1,287 @@
+// Package escalation applies task priority rules. Agent tasks that block a
+// high-priority human task's blocked agent tasks inherit that priority until
+// nothing of higher priority waits on them, and agent tasks of such human
+// tasks that stay blocked beyond a threshold are escalated by email.
+package escalation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/notify"
+	"hyper/internal/taskgraph"
+
+	"go.uber.org/zap"
+)
+
+// Config controls the priority rules
+type Config struct {
+	Interval    time.Duration        // Time between sweeps; 0 disables the rules
+	EscalateAt  time.Duration        // How long an agent task may stay blocked before it is escalated
+	MinPriority storage.TaskPriority // Human task priority from which the rules apply
+}
+
+// LoadConfig reads PRIORITY_RULES_INTERVAL (default 5m, 0 disables),
+// PRIORITY_ESCALATE_AFTER (default 4h) and PRIORITY_RULES_MIN (default high)
+func LoadConfig() (Config, error) {
+	cfg := Config{Interval: 5 * time.Minute, EscalateAt: 4 * time.Hour, MinPriority: storage.PriorityHigh}
+
+	if raw := os.Getenv("PRIORITY_RULES_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval < 0 {
+			return cfg, fmt.Errorf("invalid PRIORITY_RULES_INTERVAL %q: must be a duration such as 5m", raw)
+		}
+		cfg.Interval = interval
+	}
+	if raw := os.Getenv("PRIORITY_ESCALATE_AFTER"); raw != "" {
+		after, err := time.ParseDuration(raw)
+		if err != nil || after <= 0 {
+			return cfg, fmt.Errorf("invalid PRIORITY_ESCALATE_AFTER %q: must be a positive duration such as 4h", raw)
+		}
+		cfg.EscalateAt = after
+	}
+	if raw := os.Getenv("PRIORITY_RULES_MIN"); raw != "" {
+		priority, err := storage.ParseTaskPriority(raw)
+		if err != nil || priority == storage.PriorityUnset {
+			return cfg, fmt.Errorf("invalid PRIORITY_RULES_MIN %q: must be low, medium, high or critical", raw)
+		}
+		cfg.MinPriority = priority
+	}
+	return cfg, nil
+}
+
+// taskReader lists tasks and their activity (implemented by storage.TaskStorage)
+type taskReader interface {
+	ListAllHumanTasks() []*storage.HumanTask
+	ListAllAgentTasks() []*storage.AgentTask
+	GetAgentTaskActivity(taskID string) ([]storage.TaskActivity, error)
+}
+
+// Change is a priority an agent task inherited or gave back
+type Change struct {
+	TaskID      string               `json:"taskId"`
+	AgentName   string               `json:"agentName"`
+	Priority    storage.TaskPriority `json:"priority,omitempty"` // Empty when restored
+	HumanTaskID string               `json:"humanTaskId,omitempty"`
+}
+
+// Escalation is a blocked agent task reported as blocked too long
+type Escalation struct {
+	TaskID       string               `json:"taskId"`
+	AgentName    string               `json:"agentName"`
+	Role         string               `json:"role"`
+	HumanTaskID  string               `json:"humanTaskId"`
+	Prompt       string               `json:"prompt"`
+	Priority     storage.TaskPriority `json:"priority"`
+	BlockedSince time.Time            `json:"blockedSince"`
+	BlockedBy    []string             `json:"blockedBy"` // Agent task IDs it depends on
+	Notes        string               `json:"notes,omitempty"`
+}
+
+// Report is what one sweep changed
+type Report struct {
+	At        time.Time    `json:"at"`
+	Raised    []Change     `json:"raised"`
+	Restored  []Change     `json:"restored"`
+	Escalated []Escalation `json:"escalated"`
+	Errors    []string     `json:"errors,omitempty"`
+}
+
+// Engine applies the priority rules
+type Engine struct {
+	cfg        Config
+	tasks      taskReader
+	priorities storage.TaskPriorityStorage
+	email      notify.EmailSender
+	recipients []string
+	logger     *zap.Logger
+	now        func() time.Time
+
+	mu sync.Mutex // One sweep at a time
+}
+
+// NewEngine creates the priority rules engine. Escalations are emailed to
+// NOTIFY_EMAIL_TO when SMTP is configured, and logged either way.
+func NewEngine(cfg Config, tasks taskReader, priorities storage.TaskPriorityStorage, mailer *notify.Mailer, logger *zap.Logger) *Engine {
+	e := &Engine{cfg: cfg, tasks: tasks, priorities: priorities, logger: logger, now: time.Now}
+	if list := os.Getenv(notify.RecipientsEnv); list != "" && mailer.Configured() {
+		recipients, err := storage.ParseEmailRecipients(list)
+		if err != nil {
+			logger.Warn("Escalation emails disabled", zap.String("env", notify.RecipientsEnv), zap.Error(err))
+		} else {
+			e.email = mailer
+			e.recipients = recipients
+		}
+	}
+	return e
+}
+
+// Start sweeps every interval until ctx is cancelled
+func (e *Engine) Start(ctx context.Context) {
+	if e.cfg.Interval <= 0 {
+		e.logger.Info("Task priority rules disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(e.cfg.Interval)
+		defer ticker.Stop()
+
+		e.logger.Info("Task priority rules started",
+			zap.Duration("interval", e.cfg.Interval),
+			zap.Duration("escalateAfter", e.cfg.EscalateAt),
+			zap.String("minPriority", string(e.cfg.MinPriority)))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.Run()
+			}
+		}
+	}()
+}
+
+// SetPriority sets the priority of a task (human or agent) and, unless the
+// rules are disabled, applies them at once so blocking tasks inherit it
+// without waiting for the next sweep. The report is nil when they are disabled.
+func (e *Engine) SetPriority(taskID string, priority storage.TaskPriority) (*Report, error) {
+	if err := e.priorities.SetTaskPriority(taskID, priority); err != nil {
+		return nil, err
+	}
+	if !e.Enabled() {
+		return nil, nil
+	}
+	return e.Run(), nil
+}
+
+// Enabled reports whether the rules are applied, which they are unless
+// PRIORITY_RULES_INTERVAL is zero
+func (e *Engine) Enabled() bool {
+	return e.cfg.Interval > 0
+}
+
+// inheritance is the priority an agent task should inherit, and from where
+type inheritance struct {
+	priority storage.TaskPriority
+	from     string
+}
+
+// Run applies the rules once: blocking tasks inherit priority, tasks that no
+// longer block give it back, and tasks blocked too long are escalated
+func (e *Engine) Run() *Report {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now().UTC()
+	report := &Report{At: now, Raised: []Change{}, Restored: []Change{}, Escalated: []Escalation{}}
+
+	humans := make(map[string]*storage.HumanTask)
+	for _, human := range e.tasks.ListAllHumanTasks() {
+		humans[human.ID] = human
+	}
+	agents := e.tasks.ListAllAgentTasks()
+	sort.SliceStable(agents, func(i, j int) bool { return agents[i].CreatedAt.Before(agents[j].CreatedAt) })
+
+	wanted := make(map[string]inheritance)
+	for _, blocked := range agents {
+		human := humans[blocked.HumanTaskID]
+		if blocked.Status != storage.TaskStatusBlocked || human == nil || human.Status == storage.TaskStatusCompleted {
+			continue
+		}
+		priority := storage.HumanTaskPriority(human)
+		if priority.Rank() < e.cfg.MinPriority.Rank() {
+			continue
+		}
+
+		var blockedBy []string
+		for _, blocker := range agents {
+			if blocker.ID == blocked.ID || !taskgraph.DependsOn(blocked, blocker) {
+				continue
+			}
+			blockedBy = append(blockedBy, blocker.ID)
+			if blocker.Status == storage.TaskStatusCompleted {
+				continue
+			}
+			// Inherit only what raises the blocker above its own priority
+			own := storage.OwnAgentTaskPriority(blocker, humans[blocker.HumanTaskID])
+			if priority.Rank() > own.Rank() && priority.Rank() > wanted[blocker.ID].priority.Rank() {
+				wanted[blocker.ID] = inheritance{priority: priority, from: human.ID}
+			}
+		}
+
+		if blocked.EscalatedAt == nil {
+			since := e.blockedSince(blocked)
+			if now.Sub(since) >= e.cfg.EscalateAt {
+				escalation := Escalation{
+					TaskID:       blocked.ID,
+					AgentName:    blocked.AgentName,
+					Role:         blocked.Role,
+					HumanTaskID:  human.ID,
+					Prompt:       human.Prompt,
+					Priority:     priority,
+					BlockedSince: since,
+					BlockedBy:    blockedBy,
+					Notes:        blocked.Notes,
+				}
+				if err := e.priorities.MarkBlockedEscalated(blocked.ID, &now); err != nil {
+					report.Errors = append(report.Errors, err.Error())
+					continue
+				}
+				e.notify(&escalation)
+				report.Escalated = append(report.Escalated, escalation)
+			}
+		}
+	}
+
+	for _, task := range agents {
+		want := wanted[task.ID]
+		if task.InheritedPriority != want.priority || task.PriorityInheritedFrom != want.from {
+			if err := e.priorities.SetInheritedPriority(task.ID, want.priority, want.from); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+				continue
+			}
+			change := Change{TaskID: task.ID, AgentName: task.AgentName, Priority: want.priority, HumanTaskID: want.from}
+			if want.priority == storage.PriorityUnset {
+				report.Restored = append(report.Restored, change)
+			} else {
+				report.Raised = append(report.Raised, change)
+			}
+		}
+		// A task escalated again only after it was unblocked in between
+		if task.EscalatedAt != nil && task.Status != storage.TaskStatusBlocked {
+			if err := e.priorities.MarkBlockedEscalated(task.ID, nil); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			}
+		}
+	}
+
+	if len(report.Raised)+len(report.Restored)+len(report.Escalated)+len(report.Errors) > 0 {
+		e.logger.Info("Task priority rules applied",
+			zap.Int("raised", len(report.Raised)),
+			zap.Int("restored", len(report.Restored)),
+			zap.Int("escalated", len(report.Escalated)),
+			zap.Strings("errors", report.Errors))
+	}
+	return report
+}
+
+// blockedSince is when the task last became blocked, from its activity log,
+// or its last update when the log does not say
+func (e *Engine) blockedSince(task *storage.AgentTask) time.Time {
+	activity, err := e.tasks.GetAgentTaskActivity(task.ID)
+	if err == nil {
+		for i := len(activity) - 1; i >= 0; i-- {
+			entry := activity[i]
+			if entry.Action == storage.ActivityStatusChanged && entry.Status == string(storage.TaskStatusBlocked) {
+				return entry.At
+			}
+		}
+	}
+	return task.UpdatedAt
+}
@@ -0,0 +1,235 @@
+package escalation
+
+import (
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeTasks keeps tasks in memory and applies priority updates to them
+type fakeTasks struct {
+	humans   []*storage.HumanTask
+	agents   []*storage.AgentTask
+	activity map[string][]storage.TaskActivity
+}
+
+func (f *fakeTasks) ListAllHumanTasks() []*storage.HumanTask { return f.humans }
+
+func (f *fakeTasks) ListAllAgentTasks() []*storage.AgentTask {
+	// Copies, as the storage returns fresh documents
+	agents := make([]*storage.AgentTask, len(f.agents))
+	for i, task := range f.agents {
+		copied := *task
+		agents[i] = &copied
+	}
+	return agents
+}
+
+func (f *fakeTasks) GetAgentTaskActivity(taskID string) ([]storage.TaskActivity, error) {
+	return f.activity[taskID], nil
+}
+
+func (f *fakeTasks) agent(taskID string) *storage.AgentTask {
+	for _, task := range f.agents {
+		if task.ID == taskID {
+			return task
+		}
+	}
+	return nil
+}
+
+func (f *fakeTasks) SetTaskPriority(taskID string, priority storage.TaskPriority) error {
+	for _, task := range f.humans {
+		if task.ID == taskID {
+			task.Priority = priority
+			return nil
+		}
+	}
+	if task := f.agent(taskID); task != nil {
+		task.Priority = priority
+		return nil
+	}
+	return assert.AnError
+}
+
+func (f *fakeTasks) SetInheritedPriority(taskID string, priority storage.TaskPriority, from string) error {
+	task := f.agent(taskID)
+	task.InheritedPriority = priority
+	task.PriorityInheritedFrom = from
+	return nil
+}
+
+func (f *fakeTasks) MarkBlockedEscalated(taskID string, at *time.Time) error {
+	f.agent(taskID).EscalatedAt = at
+	return nil
+}
+
+type recordingSender struct {
+	sent []*notify.Email
+}
+
+func (s *recordingSender) SendEmail(msg *notify.Email) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+var now = time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+// newTestEngine returns an engine over a high-priority human task whose
+// frontend task is blocked on a backend task of a low-priority human task
+func newTestEngine() (*Engine, *fakeTasks, *recordingSender) {
+	tasks := &fakeTasks{
+		humans: []*storage.HumanTask{
+			{ID: "human-urgent", Prompt: "Fix checkout outage", Status: storage.TaskStatusInProgress, Tags: []string{"priority:high"}},
+			{ID: "human-chore", Prompt: "Tidy API", Status: storage.TaskStatusInProgress, Priority: storage.PriorityLow},
+		},
+		agents: []*storage.AgentTask{
+			{ID: "backend-1111", HumanTaskID: "human-chore", AgentName: "backend", Status: storage.TaskStatusInProgress, CreatedAt: now.Add(-6 * time.Hour)},
+			{
+				ID: "frontend-2222", HumanTaskID: "human-urgent", AgentName: "frontend", Role: "Fix the checkout button",
+				Status: storage.TaskStatusBlocked, Notes: "Waiting on backend-1111 for the new endpoint",
+				CreatedAt: now.Add(-5 * time.Hour), UpdatedAt: now.Add(-5 * time.Hour),
+			},
+		},
+		activity: map[string][]storage.TaskActivity{
+			"frontend-2222": {
+				{At: now.Add(-5 * time.Hour), Action: storage.ActivityStatusChanged, Status: string(storage.TaskStatusInProgress)},
+				{At: now.Add(-2 * time.Hour), Action: storage.ActivityStatusChanged, Status: string(storage.TaskStatusBlocked)},
+			},
+		},
+	}
+	sender := &recordingSender{}
+	engine := &Engine{
+		cfg:        Config{Interval: time.Minute, EscalateAt: 4 * time.Hour, MinPriority: storage.PriorityHigh},
+		tasks:      tasks,
+		priorities: tasks,
+		email:      sender,
+		recipients: []string{"leads@example.com"},
+		logger:     zap.NewNop(),
+		now:        func() time.Time { return now },
+	}
+	return engine, tasks, sender
+}
+
+func TestRun_InheritsPriority(t *testing.T) {
+	engine, tasks, sender := newTestEngine()
+
+	report := engine.Run()
+	assert.Empty(t, report.Errors)
+	require.Len(t, report.Raised, 1)
+	assert.Equal(t, Change{TaskID: "backend-1111", AgentName: "backend", Priority: storage.PriorityHigh, HumanTaskID: "human-urgent"}, report.Raised[0])
+	assert.Equal(t, storage.PriorityHigh, tasks.agent("backend-1111").InheritedPriority)
+	assert.Equal(t, storage.PriorityHigh, storage.AgentTaskPriority(tasks.agent("backend-1111"), tasks.humans[1]))
+	assert.Empty(t, report.Escalated, "blocked for 2h of the 4h allowed")
+	assert.Empty(t, sender.sent)
+
+	// Nothing changes until the blocked task is unblocked
+	report = engine.Run()
+	assert.Empty(t, report.Raised)
+	assert.Empty(t, report.Restored)
+
+	tasks.agent("frontend-2222").Status = storage.TaskStatusInProgress
+	report = engine.Run()
+	require.Len(t, report.Restored, 1)
+	assert.Equal(t, "backend-1111", report.Restored[0].TaskID)
+	assert.Equal(t, storage.PriorityUnset, tasks.agent("backend-1111").InheritedPriority)
+	assert.Empty(t, tasks.agent("backend-1111").PriorityInheritedFrom)
+}
+
+func TestRun_SkipsLowerPriorities(t *testing.T) {
+	engine, tasks, _ := newTestEngine()
+
+	// Already as urgent as the blocked work
+	tasks.agent("backend-1111").Priority = storage.PriorityCritical
+	report := engine.Run()
+	assert.Empty(t, report.Raised)
+
+	// Below the rules' minimum
+	tasks.agent("backend-1111").Priority = storage.PriorityUnset
+	tasks.humans[0].Tags = nil
+	tasks.humans[0].Priority = storage.PriorityMedium
+	report = engine.Run()
+	assert.Empty(t, report.Raised)
+
+	// Completed blockers keep their priority
+	tasks.humans[0].Priority = storage.PriorityCritical
+	tasks.agent("backend-1111").Status = storage.TaskStatusCompleted
+	report = engine.Run()
+	assert.Empty(t, report.Raised)
+}
+
+func TestRun_Escalates(t *testing.T) {
+	engine, tasks, sender := newTestEngine()
+	engine.now = func() time.Time { return now.Add(3 * time.Hour) }
+
+	report := engine.Run()
+	require.Len(t, report.Escalated, 1)
+	escalation := report.Escalated[0]
+	assert.Equal(t, "frontend-2222", escalation.TaskID)
+	assert.Equal(t, "human-urgent", escalation.HumanTaskID)
+	assert.Equal(t, storage.PriorityHigh, escalation.Priority)
+	assert.Equal(t, now.Add(-2*time.Hour), escalation.BlockedSince, "from the activity log, not the last update")
+	assert.Equal(t, []string{"backend-1111"}, escalation.BlockedBy)
+	require.NotNil(t, tasks.agent("frontend-2222").EscalatedAt)
+
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, []string{"leads@example.com"}, sender.sent[0].To)
+	assert.Contains(t, sender.sent[0].Subject, "high task blocked for 5h0m0s")
+	assert.Contains(t, sender.sent[0].Text, "Blocked by: backend-1111")
+	assert.Contains(t, sender.sent[0].HTML, "Fix checkout outage")
+
+	// Escalated once per blocked spell
+	report = engine.Run()
+	assert.Empty(t, report.Escalated)
+	assert.Len(t, sender.sent, 1)
+
+	tasks.agent("frontend-2222").Status = storage.TaskStatusInProgress
+	engine.Run()
+	assert.Nil(t, tasks.agent("frontend-2222").EscalatedAt, "cleared once unblocked")
+}
+
+func TestSetPriority(t *testing.T) {
+	engine, tasks, _ := newTestEngine()
+	tasks.humans[0].Tags = nil
+
+	report, err := engine.SetPriority("human-urgent", storage.PriorityCritical)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Len(t, report.Raised, 1, "rules apply at once")
+	assert.Equal(t, storage.PriorityCritical, report.Raised[0].Priority)
+
+	_, err = engine.SetPriority("missing", storage.PriorityLow)
+	assert.Error(t, err)
+
+	engine.cfg.Interval = 0
+	report, err = engine.SetPriority("human-urgent", storage.PriorityUnset)
+	require.NoError(t, err)
+	assert.Nil(t, report, "no rules when disabled")
+	assert.Equal(t, storage.PriorityUnset, tasks.humans[0].Priority)
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("PRIORITY_RULES_INTERVAL", "")
+	t.Setenv("PRIORITY_ESCALATE_AFTER", "")
+	t.Setenv("PRIORITY_RULES_MIN", "")
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, Config{Interval: 5 * time.Minute, EscalateAt: 4 * time.Hour, MinPriority: storage.PriorityHigh}, cfg)
+
+	t.Setenv("PRIORITY_RULES_INTERVAL", "0")
+	t.Setenv("PRIORITY_ESCALATE_AFTER", "30m")
+	t.Setenv("PRIORITY_RULES_MIN", "Critical")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, Config{Interval: 0, EscalateAt: 30 * time.Minute, MinPriority: storage.PriorityCritical}, cfg)
+
+	t.Setenv("PRIORITY_RULES_MIN", "urgent")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package escalation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"hyper/internal/notify"
+
+	"go.uber.org/zap"
+)
+
+// escalationEmail is the data of the task_escalated.html template
+type escalationEmail struct {
+	*Escalation
+	Title      string
+	BlockedFor string
+}
+
+// notify logs an escalation and emails it to NOTIFY_EMAIL_TO
+func (e *Engine) notify(escalation *Escalation) {
+	blockedFor := e.now().Sub(escalation.BlockedSince).Round(time.Minute).String()
+	e.logger.Warn("Blocked task escalated",
+		zap.String("taskId", escalation.TaskID),
+		zap.String("humanTaskId", escalation.HumanTaskID),
+		zap.String("priority", string(escalation.Priority)),
+		zap.String("blockedFor", blockedFor))
+	if e.email == nil {
+		return
+	}
+
+	title := fmt.Sprintf("Escalation: %s task blocked for %s", escalation.Priority, blockedFor)
+	text := fmt.Sprintf("An agent task of a %s priority human task has been blocked for %s.\n\n", escalation.Priority, blockedFor)
+	text += fmt.Sprintf("Task: %s\nAgent: %s\nRole: %s\nHuman task: %s\nPrompt: %s\n",
+		escalation.TaskID, escalation.AgentName, escalation.Role, escalation.HumanTaskID, escalation.Prompt)
+	if len(escalation.BlockedBy) > 0 {
+		text += fmt.Sprintf("Blocked by: %s\n", strings.Join(escalation.BlockedBy, ", "))
+	}
+	if escalation.Notes != "" {
+		text += fmt.Sprintf("Notes: %s\n", escalation.Notes)
+	}
+
+	html, err := notify.RenderHTML("task_escalated.html", &escalationEmail{Escalation: escalation, Title: title, BlockedFor: blockedFor})
+	if err != nil {
+		// Plain text still carries everything
+		e.logger.Warn("Failed to render escalation email", zap.Error(err))
+	}
+	if err := e.email.SendEmail(&notify.Email{To: e.recipients, Subject: title, Text: text, HTML: html}); err != nil {
+		e.logger.Warn("Failed to email escalation", zap.String("taskId", escalation.TaskID), zap.Error(err))
+	}
+}
@@ -0,0 +1,113 @@
+// Package federation delegates tasks to peer coordinators, such as other
+// squads' deployments, and syncs the status of the remote tasks back.
+package federation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/storage"
+)
+
+// requestTimeout bounds a single request to a peer
+const requestTimeout = 30 * time.Second
+
+// RemoteTask is the part of a peer's human task the federation needs
+type RemoteTask struct {
+	ID     string             `json:"id"`
+	Status storage.TaskStatus `json:"status"`
+	Notes  string             `json:"notes,omitempty"`
+}
+
+// Client calls the REST API of peer coordinators
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a peer API client
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: requestTimeout}}
+}
+
+// CreateTask creates a human task on a peer
+func (c *Client) CreateTask(ctx context.Context, peer *storage.FederationPeer, prompt string) (*RemoteTask, error) {
+	task, err := c.do(ctx, peer, http.MethodPost, "/api/v1/tasks", map[string]string{"prompt": prompt})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task on peer %s: %w", peer.Name, err)
+	}
+	return task, nil
+}
+
+// GetTask returns a human task of a peer
+func (c *Client) GetTask(ctx context.Context, peer *storage.FederationPeer, id string) (*RemoteTask, error) {
+	task, err := c.do(ctx, peer, http.MethodGet, "/api/v1/tasks/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task %s from peer %s: %w", id, peer.Name, err)
+	}
+	return task, nil
+}
+
+// do sends a request to a peer and decodes the task in its response envelope
+func (c *Client) do(ctx context.Context, peer *storage.FederationPeer, method, path string, body interface{}) (*RemoteTask, error) {
+	var payload io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, peer.URL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if peer.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+peer.Token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Data *struct {
+			Task *RemoteTask `json:"task"`
+		} `json:"data"`
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		snippet := strings.TrimSpace(string(raw))
+		if len(snippet) > 200 {
+			snippet = snippet[:200]
+		}
+		return nil, fmt.Errorf("peer returned status %d: %s", resp.StatusCode, snippet)
+	}
+	if envelope.Error != nil {
+		return nil, fmt.Errorf("peer returned %s: %s", envelope.Error.Code, envelope.Error.Message)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("peer returned status %d", resp.StatusCode)
+	}
+	if envelope.Data == nil || envelope.Data.Task == nil || envelope.Data.Task.ID == "" {
+		return nil, fmt.Errorf("peer response has no task")
+	}
+	return envelope.Data.Task, nil
+}
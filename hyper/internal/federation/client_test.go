@@ -0,0 +1,57 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCreatesAndGetsTasks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer peer-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"data":null,"error":{"code":"UNAUTHORIZED","message":"missing token"}}`))
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/tasks":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "Rotate keys", body["prompt"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data":{"task":{"id":"t-9","prompt":"Rotate keys","status":"pending"}},"error":null}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/tasks/t-9":
+			w.Write([]byte(`{"data":{"task":{"id":"t-9","status":"completed","notes":"Done"}},"error":null}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"data":null,"error":{"code":"NOT_FOUND","message":"task not found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	peer := &storage.FederationPeer{Name: "payments", URL: server.URL, Token: "peer-token"}
+	ctx := context.Background()
+
+	created, err := client.CreateTask(ctx, peer, "Rotate keys")
+	require.NoError(t, err)
+	assert.Equal(t, &RemoteTask{ID: "t-9", Status: storage.TaskStatusPending}, created)
+
+	task, err := client.GetTask(ctx, peer, "t-9")
+	require.NoError(t, err)
+	assert.Equal(t, storage.TaskStatusCompleted, task.Status)
+	assert.Equal(t, "Done", task.Notes)
+
+	_, err = client.GetTask(ctx, peer, "missing")
+	assert.ErrorContains(t, err, "NOT_FOUND: task not found")
+
+	_, err = client.GetTask(ctx, &storage.FederationPeer{Name: "payments", URL: server.URL}, "t-9")
+	assert.ErrorContains(t, err, "UNAUTHORIZED")
+}
@@ -0,0 +1,230 @@
+package federation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Config configures delegation to peer coordinators
+type Config struct {
+	Name         string        // FEDERATION_NAME: how this coordinator introduces itself to peers (default: host name)
+	PollInterval time.Duration // FEDERATION_POLL_INTERVAL (default 2m; 0 disables status sync)
+}
+
+// LoadConfig reads the FEDERATION_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		Name:         strings.TrimSpace(os.Getenv("FEDERATION_NAME")),
+		PollInterval: 2 * time.Minute,
+	}
+	if cfg.Name == "" {
+		cfg.Name, _ = os.Hostname()
+	}
+
+	if raw := os.Getenv("FEDERATION_POLL_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval < 0 {
+			return cfg, fmt.Errorf("invalid FEDERATION_POLL_INTERVAL %q: must be a duration such as 2m", raw)
+		}
+		cfg.PollInterval = interval
+	}
+	return cfg, nil
+}
+
+// taskAPI is the peer API the sync uses (implemented by Client)
+type taskAPI interface {
+	CreateTask(ctx context.Context, peer *storage.FederationPeer, prompt string) (*RemoteTask, error)
+	GetTask(ctx context.Context, peer *storage.FederationPeer, id string) (*RemoteTask, error)
+}
+
+// delegationStore persists peers and delegations (implemented by
+// storage.FederationStorage)
+type delegationStore interface {
+	GetPeer(name string) (*storage.FederationPeer, error)
+	CreateDelegation(delegation *storage.Delegation) error
+	ListDelegations(peer string, openOnly bool) ([]*storage.Delegation, error)
+	RecordDelegationSync(id string, status storage.TaskStatus, syncErr error) error
+}
+
+// Sync creates tasks on peer coordinators and mirrors the status of the
+// remote tasks to the local human tasks they were delegated from
+type Sync struct {
+	cfg    Config
+	api    taskAPI
+	store  delegationStore
+	tasks  storage.TaskStorage
+	logger *zap.Logger
+}
+
+// NewSync creates a federation sync
+func NewSync(cfg Config, store *storage.FederationStorage, tasks storage.TaskStorage, logger *zap.Logger) *Sync {
+	return &Sync{
+		cfg:    cfg,
+		api:    NewClient(),
+		store:  store,
+		tasks:  tasks,
+		logger: logger,
+	}
+}
+
+// Delegate creates a task on a peer and records the delegation. With a
+// localTaskID, that human task's prompt is forwarded (unless prompt is set)
+// and its status follows the remote task from then on.
+func (s *Sync) Delegate(ctx context.Context, peerName, prompt, localTaskID string) (*storage.Delegation, error) {
+	peer, err := s.store.GetPeer(peerName)
+	if err != nil {
+		return nil, err
+	}
+	if peer == nil {
+		return nil, fmt.Errorf("federation peer not found: %s", peerName)
+	}
+
+	var localTask *storage.HumanTask
+	if localTaskID != "" {
+		if localTask, err = s.tasks.GetHumanTask(localTaskID); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(prompt) == "" {
+			prompt = localTask.Prompt
+		}
+	}
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return nil, fmt.Errorf("prompt is required when no taskId is given")
+	}
+
+	remote, err := s.api.CreateTask(ctx, peer, s.remotePrompt(prompt, localTaskID))
+	if err != nil {
+		return nil, err
+	}
+
+	delegation := &storage.Delegation{
+		ID:           uuid.New().String(),
+		Peer:         peer.Name,
+		LocalTaskID:  localTaskID,
+		RemoteTaskID: remote.ID,
+		Prompt:       prompt,
+		Status:       remote.Status,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.store.CreateDelegation(delegation); err != nil {
+		return nil, fmt.Errorf("task %s was created on peer %s but not recorded: %w", remote.ID, peer.Name, err)
+	}
+
+	if localTask != nil {
+		note := fmt.Sprintf("Delegated to %s as task %s", peer.Name, remote.ID)
+		if err := s.tasks.UpdateTaskStatus(localTask.ID, localTask.Status, note); err != nil {
+			s.logger.Warn("Failed to note delegation on task", zap.String("taskId", localTask.ID), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("Delegated task to peer",
+		zap.String("peer", peer.Name),
+		zap.String("remoteTaskId", remote.ID),
+		zap.String("localTaskId", localTaskID))
+	return delegation, nil
+}
+
+// remotePrompt tells the peer's team where a delegated task came from
+func (s *Sync) remotePrompt(prompt, localTaskID string) string {
+	origin := fmt.Sprintf("Delegated by coordinator %q", s.cfg.Name)
+	if localTaskID != "" {
+		origin += fmt.Sprintf(" (task %s)", localTaskID)
+	}
+	return prompt + "\n\n" + origin
+}
+
+// SyncDelegation fetches the remote task of a delegation and, when its status
+// changed, records it and applies it to the local task. It reports whether
+// the status changed.
+func (s *Sync) SyncDelegation(ctx context.Context, delegation *storage.Delegation) (bool, error) {
+	changed, err := s.syncDelegation(ctx, delegation)
+	if err != nil {
+		if recordErr := s.store.RecordDelegationSync(delegation.ID, delegation.Status, err); recordErr != nil {
+			s.logger.Warn("Failed to record delegation sync error", zap.String("delegationId", delegation.ID), zap.Error(recordErr))
+		}
+		return false, err
+	}
+	return changed, nil
+}
+
+func (s *Sync) syncDelegation(ctx context.Context, delegation *storage.Delegation) (bool, error) {
+	peer, err := s.store.GetPeer(delegation.Peer)
+	if err != nil {
+		return false, err
+	}
+	if peer == nil {
+		return false, fmt.Errorf("federation peer not found: %s", delegation.Peer)
+	}
+
+	remote, err := s.api.GetTask(ctx, peer, delegation.RemoteTaskID)
+	if err != nil {
+		return false, err
+	}
+	changed := remote.Status != delegation.Status
+	if changed && delegation.LocalTaskID != "" {
+		note := fmt.Sprintf("%s task %s is %s", peer.Name, remote.ID, remote.Status)
+		if err := s.tasks.UpdateTaskStatus(delegation.LocalTaskID, remote.Status, note); err != nil {
+			return false, fmt.Errorf("failed to update local task %s: %w", delegation.LocalTaskID, err)
+		}
+	}
+	if err := s.store.RecordDelegationSync(delegation.ID, remote.Status, nil); err != nil {
+		return false, err
+	}
+	if changed {
+		s.logger.Info("Delegated task status changed",
+			zap.String("peer", peer.Name),
+			zap.String("remoteTaskId", remote.ID),
+			zap.String("status", string(remote.Status)))
+	}
+	delegation.Status = remote.Status
+	return changed, nil
+}
+
+// Poll syncs every delegation whose remote task is not completed yet
+func (s *Sync) Poll(ctx context.Context) error {
+	delegations, err := s.store.ListDelegations("", true)
+	if err != nil {
+		return err
+	}
+	for _, delegation := range delegations {
+		if _, err := s.SyncDelegation(ctx, delegation); err != nil {
+			s.logger.Warn("Failed to sync delegated task",
+				zap.String("peer", delegation.Peer),
+				zap.String("remoteTaskId", delegation.RemoteTaskID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Start polls peers for delegated task status until ctx is cancelled
+func (s *Sync) Start(ctx context.Context) {
+	if s.cfg.PollInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.PollInterval)
+		defer ticker.Stop()
+
+		s.logger.Info("Federation status sync started", zap.Duration("interval", s.cfg.PollInterval))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Poll(ctx); err != nil {
+					s.logger.Warn("Federation poll failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
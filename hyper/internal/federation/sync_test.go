@@ -0,0 +1,201 @@
+package federation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryTasks keeps human tasks in memory
+type memoryTasks struct {
+	storage.TaskStorage
+	human map[string]*storage.HumanTask
+	notes map[string][]string
+}
+
+func (m *memoryTasks) GetHumanTask(id string) (*storage.HumanTask, error) {
+	if task, ok := m.human[id]; ok {
+		return task, nil
+	}
+	return nil, fmt.Errorf("human task with ID %s not found", id)
+}
+
+func (m *memoryTasks) UpdateTaskStatus(id string, status storage.TaskStatus, notes string) error {
+	task, err := m.GetHumanTask(id)
+	if err != nil {
+		return err
+	}
+	task.Status = status
+	m.notes[id] = append(m.notes[id], notes)
+	return nil
+}
+
+// memoryStore keeps peers and delegations in memory
+type memoryStore struct {
+	peers       map[string]*storage.FederationPeer
+	delegations []*storage.Delegation
+	syncErrors  map[string]string
+}
+
+func (m *memoryStore) GetPeer(name string) (*storage.FederationPeer, error) {
+	return m.peers[name], nil
+}
+
+func (m *memoryStore) CreateDelegation(delegation *storage.Delegation) error {
+	m.delegations = append(m.delegations, delegation)
+	return nil
+}
+
+func (m *memoryStore) ListDelegations(peer string, openOnly bool) ([]*storage.Delegation, error) {
+	var result []*storage.Delegation
+	for _, delegation := range m.delegations {
+		if openOnly && delegation.Status == storage.TaskStatusCompleted {
+			continue
+		}
+		copied := *delegation
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func (m *memoryStore) RecordDelegationSync(id string, status storage.TaskStatus, syncErr error) error {
+	for _, delegation := range m.delegations {
+		if delegation.ID != id {
+			continue
+		}
+		if syncErr != nil {
+			m.syncErrors[id] = syncErr.Error()
+			return nil
+		}
+		delegation.Status = status
+		delete(m.syncErrors, id)
+	}
+	return nil
+}
+
+// fakePeer is an in-memory peer coordinator
+type fakePeer struct {
+	tasks   map[string]*RemoteTask
+	prompts map[string]string
+	down    bool
+}
+
+func (f *fakePeer) CreateTask(ctx context.Context, peer *storage.FederationPeer, prompt string) (*RemoteTask, error) {
+	task := &RemoteTask{ID: fmt.Sprintf("r-%d", len(f.tasks)+1), Status: storage.TaskStatusPending}
+	f.tasks[task.ID] = task
+	f.prompts[task.ID] = prompt
+	return task, nil
+}
+
+func (f *fakePeer) GetTask(ctx context.Context, peer *storage.FederationPeer, id string) (*RemoteTask, error) {
+	if f.down {
+		return nil, fmt.Errorf("failed to get task %s from peer %s: connection refused", id, peer.Name)
+	}
+	copied := *f.tasks[id]
+	return &copied, nil
+}
+
+func newTestSync() (*Sync, *fakePeer, *memoryStore, *memoryTasks) {
+	peer := &fakePeer{tasks: map[string]*RemoteTask{}, prompts: map[string]string{}}
+	store := &memoryStore{
+		peers:      map[string]*storage.FederationPeer{"payments": {Name: "payments", URL: "http://payments:7095"}},
+		syncErrors: map[string]string{},
+	}
+	tasks := &memoryTasks{
+		human: map[string]*storage.HumanTask{"h-1": {ID: "h-1", Prompt: "Rotate the PSP API keys", Status: storage.TaskStatusPending}},
+		notes: map[string][]string{},
+	}
+	sync := &Sync{cfg: Config{Name: "platform"}, api: peer, store: store, tasks: tasks, logger: zap.NewNop()}
+	return sync, peer, store, tasks
+}
+
+func TestDelegateForwardsLocalTask(t *testing.T) {
+	sync, peer, store, tasks := newTestSync()
+
+	delegation, err := sync.Delegate(context.Background(), "payments", "", "h-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "r-1", delegation.RemoteTaskID)
+	assert.Equal(t, "Rotate the PSP API keys", delegation.Prompt)
+	assert.Equal(t, "Rotate the PSP API keys\n\nDelegated by coordinator \"platform\" (task h-1)", peer.prompts["r-1"])
+	assert.Len(t, store.delegations, 1)
+	assert.Equal(t, []string{"Delegated to payments as task r-1"}, tasks.notes["h-1"])
+}
+
+func TestDelegateValidates(t *testing.T) {
+	sync, _, _, _ := newTestSync()
+
+	_, err := sync.Delegate(context.Background(), "unknown", "Do it", "")
+	assert.ErrorContains(t, err, "federation peer not found")
+
+	_, err = sync.Delegate(context.Background(), "payments", "  ", "")
+	assert.ErrorContains(t, err, "prompt is required")
+}
+
+func TestPollSyncsRemoteStatus(t *testing.T) {
+	sync, peer, store, tasks := newTestSync()
+	ctx := context.Background()
+
+	_, err := sync.Delegate(ctx, "payments", "", "h-1")
+	require.NoError(t, err)
+
+	peer.tasks["r-1"].Status = storage.TaskStatusInProgress
+	require.NoError(t, sync.Poll(ctx))
+	assert.Equal(t, storage.TaskStatusInProgress, tasks.human["h-1"].Status)
+	assert.Equal(t, storage.TaskStatusInProgress, store.delegations[0].Status)
+	assert.Contains(t, tasks.notes["h-1"], "payments task r-1 is in_progress")
+
+	// Unchanged status does not touch the local task again
+	require.NoError(t, sync.Poll(ctx))
+	assert.Len(t, tasks.notes["h-1"], 2)
+
+	peer.tasks["r-1"].Status = storage.TaskStatusCompleted
+	require.NoError(t, sync.Poll(ctx))
+	assert.Equal(t, storage.TaskStatusCompleted, tasks.human["h-1"].Status)
+
+	// Completed delegations are no longer polled
+	peer.down = true
+	require.NoError(t, sync.Poll(ctx))
+	assert.Empty(t, store.syncErrors)
+}
+
+func TestSyncDelegationRecordsErrors(t *testing.T) {
+	sync, peer, store, tasks := newTestSync()
+	ctx := context.Background()
+
+	delegation, err := sync.Delegate(ctx, "payments", "Review the ledger schema", "")
+	require.NoError(t, err)
+
+	peer.down = true
+	changed, err := sync.SyncDelegation(ctx, delegation)
+	assert.Error(t, err)
+	assert.False(t, changed)
+	assert.Contains(t, store.syncErrors[delegation.ID], "connection refused")
+	assert.Equal(t, storage.TaskStatusPending, tasks.human["h-1"].Status)
+
+	peer.down = false
+	peer.tasks[delegation.RemoteTaskID].Status = storage.TaskStatusBlocked
+	changed, err = sync.SyncDelegation(ctx, delegation)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Empty(t, store.syncErrors)
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("FEDERATION_NAME", "platform")
+	t.Setenv("FEDERATION_POLL_INTERVAL", "30s")
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "platform", cfg.Name)
+	assert.Equal(t, "30s", cfg.PollInterval.String())
+
+	t.Setenv("FEDERATION_POLL_INTERVAL", "soon")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
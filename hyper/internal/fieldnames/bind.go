@@ -0,0 +1,92 @@
+package fieldnames
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// ShouldBindJSON binds the request body to obj like gin's ShouldBindJSON,
+// first rewriting fields obj does not declare (see Normalize). Renames are
+// reported to the client with a Deprecation header and a Warning each.
+func ShouldBindJSON(c *gin.Context, obj interface{}) error {
+	var body []byte
+	if c.Request != nil && c.Request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(c.Request.Body); err != nil {
+			return err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // keep large integers exact through the rewrite
+	var fields map[string]interface{}
+	if decoder.Decode(&fields) == nil && fields != nil {
+		if renames := Normalize(fields, jsonFields(obj)); len(renames) > 0 {
+			if rewritten, err := json.Marshal(fields); err == nil {
+				body = rewritten
+				Deprecate(c, renames)
+			}
+		}
+	}
+	return binding.JSON.BindBody(body, obj)
+}
+
+// Deprecate tells the client which fields it sent under deprecated names
+func Deprecate(c *gin.Context, renames []Rename) {
+	if len(renames) == 0 {
+		return
+	}
+	c.Header("Deprecation", "true")
+	for _, rename := range renames {
+		c.Writer.Header().Add("Warning", `299 - "`+strings.ReplaceAll(rename.Warning(), `"`, `'`)+`"`)
+	}
+}
+
+// jsonFields returns whether obj, a pointer to a struct, decodes a field of
+// that name. Anything else declares every name, so nothing is rewritten.
+func jsonFields(obj interface{}) func(name string) bool {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return func(string) bool { return true }
+	}
+	names := map[string]bool{}
+	collectJSONFields(t, names)
+	return func(name string) bool { return names[name] }
+}
+
+func collectJSONFields(t reflect.Type, names map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				collectJSONFields(embedded, names)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+}
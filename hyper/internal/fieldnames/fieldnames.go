@@ -0,0 +1,137 @@
+// Package fieldnames accepts the older spellings of request fields. The REST
+// API, MCP tools and stored documents grew up naming the same things
+// differently (collectionName and collection, information and text, some
+// scripts send snake_case), so each surface rewrites the spellings it does
+// not declare into the one it does and reports the rewrite as deprecated.
+package fieldnames
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// synonyms are the names one field goes by. The first is canonical: the name
+// storage and the REST API use, and the one clients should converge on.
+var synonyms = [][]string{
+	{"collection", "collectionName"},
+	{"text", "information"},
+}
+
+// Rename is an input field rewritten to the name its receiver declares
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Warning describes the rename for a deprecation notice
+func (r Rename) Warning() string {
+	return fmt.Sprintf("%q is deprecated; use %q", r.From, r.To)
+}
+
+// Canonical returns the name clients should use for a field: camelCase, and
+// the first of its synonyms
+func Canonical(name string) string {
+	name = camelCase(name)
+	if group := synonymsOf(name); group != nil {
+		return group[0]
+	}
+	return name
+}
+
+// Normalize rewrites the fields a receiver does not declare to the synonym or
+// camelCase spelling it does, and returns the renames sorted by name. A field
+// is left alone when its declared spelling is also present, so an explicit
+// value wins. Only top-level fields are rewritten: nested objects such as
+// metadata hold keys chosen by users.
+func Normalize(fields map[string]interface{}, declared func(name string) bool) []Rename {
+	var renames []Rename
+	for name := range fields {
+		if declared(name) {
+			continue
+		}
+		to := resolve(name, declared)
+		if to == "" {
+			continue
+		}
+		if _, taken := fields[to]; taken {
+			continue
+		}
+		renames = append(renames, Rename{From: name, To: to})
+	}
+	sort.Slice(renames, func(i, j int) bool { return renames[i].From < renames[j].From })
+
+	// Of two spellings of one field, the first by name is taken
+	applied := renames[:0]
+	for _, rename := range renames {
+		if _, taken := fields[rename.To]; taken {
+			continue
+		}
+		fields[rename.To] = fields[rename.From]
+		delete(fields, rename.From)
+		applied = append(applied, rename)
+	}
+	return applied
+}
+
+// SchemaDeclares returns whether a JSON schema declares a property of that
+// name, or nil when it declares none
+func SchemaDeclares(schema interface{}) func(name string) bool {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	var object struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(raw, &object); err != nil || len(object.Properties) == 0 {
+		return nil
+	}
+	return func(name string) bool {
+		_, ok := object.Properties[name]
+		return ok
+	}
+}
+
+// resolve returns the declared spelling of name, or ""
+func resolve(name string, declared func(name string) bool) string {
+	camel := camelCase(name)
+	if camel != name && declared(camel) {
+		return camel
+	}
+	for _, synonym := range synonymsOf(camel) {
+		if synonym != name && declared(synonym) {
+			return synonym
+		}
+	}
+	return ""
+}
+
+func synonymsOf(name string) []string {
+	for _, group := range synonyms {
+		for _, synonym := range group {
+			if synonym == name {
+				return group
+			}
+		}
+	}
+	return nil
+}
+
+// camelCase converts snake_case to camelCase, keeping other names as they are
+func camelCase(name string) string {
+	if !strings.Contains(name, "_") {
+		return name
+	}
+	parts := strings.Split(name, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package fieldnames
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func declares(names ...string) func(string) bool {
+	return func(name string) bool {
+		for _, declared := range names {
+			if declared == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	fields := map[string]interface{}{
+		"collectionName": "team-docs",
+		"information":    "Deploys run on Fridays",
+		"human_task_id":  "t-1",
+		"metadata":       map[string]interface{}{"source_file": "notes.md"},
+		"unknown_field":  true,
+	}
+	renames := Normalize(fields, declares("collection", "text", "humanTaskId", "metadata"))
+
+	assert.Equal(t, []Rename{
+		{From: "collectionName", To: "collection"},
+		{From: "human_task_id", To: "humanTaskId"},
+		{From: "information", To: "text"},
+	}, renames)
+	assert.Equal(t, map[string]interface{}{
+		"collection":    "team-docs",
+		"text":          "Deploys run on Fridays",
+		"humanTaskId":   "t-1",
+		"metadata":      map[string]interface{}{"source_file": "notes.md"},
+		"unknown_field": true,
+	}, fields, "nested and undeclared fields are kept as sent")
+}
+
+func TestNormalize_DeclaredSpellingWins(t *testing.T) {
+	fields := map[string]interface{}{"collection": "a", "collectionName": "b", "collection_name": "c"}
+	assert.Empty(t, Normalize(fields, declares("collection")))
+	assert.Equal(t, "a", fields["collection"])
+
+	// The MCP tools declare the older name
+	fields = map[string]interface{}{"collection": "a", "collection_name": "b"}
+	renames := Normalize(fields, declares("collectionName"))
+	assert.Equal(t, []Rename{{From: "collection", To: "collectionName"}}, renames, "of two spellings the first by name is taken")
+	assert.Equal(t, "a", fields["collectionName"])
+}
+
+func TestCanonical(t *testing.T) {
+	assert.Equal(t, "collection", Canonical("collection_name"))
+	assert.Equal(t, "text", Canonical("information"))
+	assert.Equal(t, "agentTaskId", Canonical("agent_task_id"))
+	assert.Equal(t, "query", Canonical("query"))
+}
+
+func TestSchemaDeclares(t *testing.T) {
+	declared := SchemaDeclares(map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"collectionName": map[string]interface{}{"type": "string"}},
+	})
+	require.NotNil(t, declared)
+	assert.True(t, declared("collectionName"))
+	assert.False(t, declared("collection"))
+	assert.Nil(t, SchemaDeclares(map[string]interface{}{"type": "object"}))
+}
+
+func TestShouldBindJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	type queryRequest struct {
+		Collection string `json:"collection" binding:"required"`
+		Query      string `json:"query" binding:"required"`
+		Limit      int64  `json:"limit"`
+	}
+
+	bind := func(body string) (*httptest.ResponseRecorder, queryRequest, error) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		var req queryRequest
+		err := ShouldBindJSON(c, &req)
+		return w, req, err
+	}
+
+	w, req, err := bind(`{"collectionName": "team-docs", "query": "deploys", "limit": 9007199254740993}`)
+	require.NoError(t, err)
+	assert.Equal(t, queryRequest{Collection: "team-docs", Query: "deploys", Limit: 9007199254740993}, req)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, []string{`299 - "'collectionName' is deprecated; use 'collection'"`}, w.Header().Values("Warning"))
+
+	w, req, err = bind(`{"collection": "team-docs", "query": "deploys"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "team-docs", req.Collection)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+
+	_, _, err = bind(`{"query": "deploys"}`)
+	assert.Error(t, err, "binding rules still apply")
+}
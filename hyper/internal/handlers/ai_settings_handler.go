@@ -3,8 +3,12 @@ package handlers
 import (
 	"net/http"
 
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
 	"hyper/internal/models"
 	"hyper/internal/services"
+	"hyper/internal/validation"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -72,18 +76,18 @@ func (h *AISettingsHandler) extractUserContext(c *gin.Context) (string, string,
 func (h *AISettingsHandler) GetSystemPrompt(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	prompt, err := h.aiSettingsService.GetSystemPrompt(c.Request.Context(), userID, companyID)
 	if err != nil {
 		h.logger.Error("Failed to get system prompt", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve system prompt"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to retrieve system prompt")
 		return
 	}
 
-	c.JSON(http.StatusOK, models.GetSystemPromptResponse{
+	envelope.OK(c, models.GetSystemPromptResponse{
 		SystemPrompt: prompt,
 	})
 }
@@ -93,24 +97,24 @@ func (h *AISettingsHandler) GetSystemPrompt(c *gin.Context) {
 func (h *AISettingsHandler) UpdateSystemPrompt(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	var req models.UpdateSystemPromptRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
 	err = h.aiSettingsService.UpdateSystemPrompt(c.Request.Context(), userID, companyID, req.SystemPrompt)
 	if err != nil {
 		h.logger.Error("Failed to update system prompt", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update system prompt"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to update system prompt")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	envelope.OK(c, gin.H{
 		"success": true,
 		"message": "System prompt updated successfully",
 	})
@@ -121,21 +125,21 @@ func (h *AISettingsHandler) UpdateSystemPrompt(c *gin.Context) {
 func (h *AISettingsHandler) ListSubagents(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	subagents, err := h.aiSettingsService.ListSubagents(c.Request.Context(), userID, companyID)
 	if err != nil {
 		h.logger.Error("Failed to list subagents", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list subagents"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to list subagents")
 		return
 	}
 
-	c.JSON(http.StatusOK, models.ListSubagentsResponse{
+	envelope.List(c, models.ListSubagentsResponse{
 		Subagents: subagents,
 		Count:     len(subagents),
-	})
+	}, envelope.Complete(len(subagents)))
 }
 
 // GetSubagent retrieves a specific subagent by ID
@@ -143,24 +147,24 @@ func (h *AISettingsHandler) ListSubagents(c *gin.Context) {
 func (h *AISettingsHandler) GetSubagent(c *gin.Context) {
 	_, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	subagentIDStr := c.Param("id")
 	subagentID, err := primitive.ObjectIDFromHex(subagentIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subagent ID"})
+		errcode.RespondCode(c, errcode.Validation, "Invalid subagent ID")
 		return
 	}
 
 	subagent, err := h.aiSettingsService.GetSubagent(c.Request.Context(), subagentID, companyID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		errcode.RespondCode(c, errcode.NotFound, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"subagent": subagent})
+	envelope.OK(c, gin.H{"subagent": subagent})
 }
 
 // CreateSubagent creates a new subagent
@@ -168,13 +172,13 @@ func (h *AISettingsHandler) GetSubagent(c *gin.Context) {
 func (h *AISettingsHandler) CreateSubagent(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	var req models.CreateSubagentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
@@ -188,11 +192,11 @@ func (h *AISettingsHandler) CreateSubagent(c *gin.Context) {
 	)
 	if err != nil {
 		h.logger.Error("Failed to create subagent", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create subagent"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to create subagent")
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"subagent": subagent})
+	envelope.JSON(c, http.StatusCreated, gin.H{"subagent": subagent})
 }
 
 // UpdateSubagent updates an existing subagent
@@ -200,20 +204,20 @@ func (h *AISettingsHandler) CreateSubagent(c *gin.Context) {
 func (h *AISettingsHandler) UpdateSubagent(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	subagentIDStr := c.Param("id")
 	subagentID, err := primitive.ObjectIDFromHex(subagentIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subagent ID"})
+		errcode.RespondCode(c, errcode.Validation, "Invalid subagent ID")
 		return
 	}
 
 	var req models.UpdateSubagentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
@@ -229,16 +233,16 @@ func (h *AISettingsHandler) UpdateSubagent(c *gin.Context) {
 	if err != nil {
 		h.logger.Error("Failed to update subagent", zap.Error(err))
 		if err.Error() == "unauthorized: subagent does not belong to user" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			errcode.RespondCode(c, errcode.PermissionDenied, err.Error())
 		} else if err.Error() == "subagent not found or access denied" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			errcode.RespondCode(c, errcode.NotFound, err.Error())
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update subagent"})
+			errcode.RespondCode(c, errcode.Internal, "Failed to update subagent")
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"subagent": subagent})
+	envelope.OK(c, gin.H{"subagent": subagent})
 }
 
 // DeleteSubagent deletes a subagent
@@ -246,14 +250,14 @@ func (h *AISettingsHandler) UpdateSubagent(c *gin.Context) {
 func (h *AISettingsHandler) DeleteSubagent(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	subagentIDStr := c.Param("id")
 	subagentID, err := primitive.ObjectIDFromHex(subagentIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subagent ID"})
+		errcode.RespondCode(c, errcode.Validation, "Invalid subagent ID")
 		return
 	}
 
@@ -261,16 +265,16 @@ func (h *AISettingsHandler) DeleteSubagent(c *gin.Context) {
 	if err != nil {
 		h.logger.Error("Failed to delete subagent", zap.Error(err))
 		if err.Error() == "unauthorized: subagent does not belong to user" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			errcode.RespondCode(c, errcode.PermissionDenied, err.Error())
 		} else if err.Error() == "subagent not found or access denied" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			errcode.RespondCode(c, errcode.NotFound, err.Error())
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			errcode.RespondCode(c, errcode.Internal, err.Error())
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	envelope.OK(c, gin.H{
 		"success": true,
 		"message": "Subagent deleted successfully",
 	})
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/middleware"
+	"hyper/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// apiKeyUseInterval throttles last-use updates of a key
+const apiKeyUseInterval = time.Minute
+
+// APIKeysHandler handles HTTP REST requests for API key administration and
+// looks keys up for middleware.AuthMiddleware
+type APIKeysHandler struct {
+	keyStorage *storage.APIKeyStorage
+	logger     *zap.Logger
+
+	mu      sync.Mutex
+	lastUse map[string]time.Time // Last recorded use, by key hash
+	hasKeys *bool                // Whether an active key exists; nil until checked
+}
+
+// NewAPIKeysHandler creates a new API keys handler
+func NewAPIKeysHandler(keyStorage *storage.APIKeyStorage, logger *zap.Logger) *APIKeysHandler {
+	return &APIKeysHandler{
+		keyStorage: keyStorage,
+		logger:     logger,
+		lastUse:    map[string]time.Time{},
+	}
+}
+
+// DTOs for API keys API
+type ListAPIKeysResponse struct {
+	Scopes []middleware.Scope      `json:"scopes"`
+	Keys   []*storage.APIKeyRecord `json:"keys"`
+	Count  int                     `json:"count"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+type CreateAPIKeyResponse struct {
+	*storage.APIKeyRecord
+	Key string `json:"key"` // The secret, shown only once
+}
+
+// LookupAPIKey implements middleware.APIKeyStore
+func (h *APIKeysHandler) LookupAPIKey(hash string) (*middleware.APIKey, error) {
+	record, err := h.keyStorage.GetActiveAPIKey(hash)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	scopes, err := middleware.ParseScopeList(record.Scopes)
+	if err != nil {
+		h.logger.Warn("API key has invalid scopes", zap.String("name", record.Name), zap.Error(err))
+		return nil, nil
+	}
+
+	h.noteUse(hash)
+	return &middleware.APIKey{Name: record.Name, Scopes: scopes}, nil
+}
+
+// HasAPIKeys implements middleware.APIKeyStore. The answer is cached until a
+// key is created or revoked.
+func (h *APIKeysHandler) HasAPIKeys() (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.hasKeys == nil {
+		exists, err := h.keyStorage.HasActiveAPIKeys()
+		if err != nil {
+			return false, err
+		}
+		h.hasKeys = &exists
+	}
+	return *h.hasKeys, nil
+}
+
+// forgetHasKeys drops the cached answer of HasAPIKeys
+func (h *APIKeysHandler) forgetHasKeys() {
+	h.mu.Lock()
+	h.hasKeys = nil
+	h.mu.Unlock()
+}
+
+// noteUse records the use of a key in the background, at most once per
+// apiKeyUseInterval
+func (h *APIKeysHandler) noteUse(hash string) {
+	now := time.Now()
+	h.mu.Lock()
+	if last, ok := h.lastUse[hash]; ok && now.Sub(last) < apiKeyUseInterval {
+		h.mu.Unlock()
+		return
+	}
+	h.lastUse[hash] = now
+	h.mu.Unlock()
+
+	go func() {
+		if err := h.keyStorage.RecordAPIKeyUse(hash, now); err != nil {
+			h.logger.Warn("Failed to record API key use", zap.Error(err))
+		}
+	}()
+}
+
+// ListAPIKeys returns the supported scopes and all stored keys
+// GET /api/v1/admin/api-keys
+func (h *APIKeysHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.keyStorage.ListAPIKeys()
+	if err != nil {
+		h.logger.Error("Failed to list API keys", zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to retrieve API keys")
+		return
+	}
+
+	envelope.List(c, ListAPIKeysResponse{
+		Scopes: middleware.AllScopes,
+		Keys:   keys,
+		Count:  len(keys),
+	}, envelope.Complete(len(keys)))
+}
+
+// CreateAPIKey creates a key and returns its secret, which is not stored
+// POST /api/v1/admin/api-keys
+func (h *APIKeysHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || strings.ContainsAny(name, ":,") {
+		errcode.RespondCode(c, errcode.Validation, "Invalid name. Must be non-empty without ':' or ','")
+		return
+	}
+	scopes, err := middleware.ParseScopeList(req.Scopes)
+	if err != nil {
+		errcode.RespondCode(c, errcode.Validation, err.Error())
+		return
+	}
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+
+	secret, record, err := h.keyStorage.CreateAPIKey(name, names, c.GetString("userId"))
+	if err != nil {
+		if errors.Is(err, storage.ErrAPIKeyExists) {
+			errcode.RespondCode(c, errcode.Conflict, err.Error())
+			return
+		}
+		h.logger.Error("Failed to create API key", zap.String("name", name), zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to create API key")
+		return
+	}
+	h.forgetHasKeys()
+
+	envelope.JSON(c, http.StatusCreated, CreateAPIKeyResponse{APIKeyRecord: record, Key: secret})
+}
+
+// RevokeAPIKey stops a key from authenticating
+// DELETE /api/v1/admin/api-keys/:name
+func (h *APIKeysHandler) RevokeAPIKey(c *gin.Context) {
+	name := c.Param("name")
+
+	if err := h.keyStorage.RevokeAPIKey(name); err != nil {
+		h.logger.Error("Failed to revoke API key", zap.String("name", name), zap.Error(err))
+		errcode.RespondCode(c, errcode.NotFound, err.Error())
+		return
+	}
+	h.forgetHasKeys()
+
+	envelope.OK(c, gin.H{
+		"success": true,
+		"message": "API key revoked",
+	})
+}
+
+// RegisterRoutes registers API key administration routes
+func (h *APIKeysHandler) RegisterRoutes(r *gin.Engine) {
+	admin := r.Group("/api/v1/admin/api-keys", middleware.RequireRole(middleware.RoleAdmin))
+	{
+		admin.GET("", h.ListAPIKeys)
+		admin.POST("", h.CreateAPIKey)
+		admin.DELETE("/:name", h.RevokeAPIKey)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"time"
+
+	"hyper/internal/calendar"
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CalendarTokenEnv holds the token calendar apps pass to subscribe to the feed
+const CalendarTokenEnv = "CALENDAR_FEED_TOKEN"
+
+// CalendarHandler serves task due dates as an iCalendar feed
+type CalendarHandler struct {
+	taskStorage storage.TaskStorage
+	token       string
+	logger      *zap.Logger
+}
+
+// NewCalendarHandler creates a calendar feed handler. It returns nil when
+// CALENDAR_FEED_TOKEN is not set, leaving the feed disabled.
+func NewCalendarHandler(taskStorage storage.TaskStorage, logger *zap.Logger) *CalendarHandler {
+	token := os.Getenv(CalendarTokenEnv)
+	if token == "" {
+		return nil
+	}
+	return &CalendarHandler{taskStorage: taskStorage, token: token, logger: logger}
+}
+
+// RegisterRoutes registers the calendar feed route
+func (h *CalendarHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET(middleware.CalendarFeedPath, h.GetFeed)
+}
+
+// GetFeed returns the due dates of all tasks, or of ?project=, as ICS. Calendar
+// apps cannot send headers, so the token is passed as ?token=.
+// GET /api/calendar.ics
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	if subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(h.token)) != 1 {
+		errcode.RespondCode(c, errcode.Unauthenticated, "Invalid calendar feed token")
+		return
+	}
+
+	project := c.Query("project")
+	name := "Hyperion tasks"
+	if project != "" {
+		name += " - " + project
+	}
+	feed := &calendar.Calendar{Name: name, Events: calendar.TaskEvents(h.taskStorage, project)}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(feed.ICS(time.Now())))
+}
@@ -4,8 +4,12 @@ import (
 	"net/http"
 	"strconv"
 
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
 	"hyper/internal/models"
 	"hyper/internal/services"
+	"hyper/internal/validation"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -73,24 +77,24 @@ func (h *ChatHandler) extractUserContext(c *gin.Context) (string, string, error)
 func (h *ChatHandler) CreateSession(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	var req models.CreateSessionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
 	session, err := h.chatService.CreateSession(c.Request.Context(), userID, companyID, req.Title)
 	if err != nil {
 		h.logger.Error("Failed to create session", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to create session")
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"session": session})
+	envelope.JSON(c, http.StatusCreated, gin.H{"session": session})
 }
 
 // ListUserSessions lists all chat sessions for the authenticated user
@@ -98,21 +102,21 @@ func (h *ChatHandler) CreateSession(c *gin.Context) {
 func (h *ChatHandler) ListUserSessions(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	sessions, err := h.chatService.GetUserSessions(c.Request.Context(), userID, companyID)
 	if err != nil {
 		h.logger.Error("Failed to list sessions", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to list sessions")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	envelope.List(c, gin.H{
 		"sessions": sessions,
 		"count":    len(sessions),
-	})
+	}, envelope.Complete(len(sessions)))
 }
 
 // GetSession retrieves a specific chat session
@@ -120,24 +124,24 @@ func (h *ChatHandler) ListUserSessions(c *gin.Context) {
 func (h *ChatHandler) GetSession(c *gin.Context) {
 	_, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	sessionIDStr := c.Param("id")
 	sessionID, err := primitive.ObjectIDFromHex(sessionIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		errcode.RespondCode(c, errcode.Validation, "Invalid session ID")
 		return
 	}
 
 	session, err := h.chatService.GetSession(c.Request.Context(), sessionID, companyID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		errcode.RespondCode(c, errcode.NotFound, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"session": session})
+	envelope.OK(c, gin.H{"session": session})
 }
 
 // DeleteSession deletes a chat session and all its messages
@@ -145,25 +149,25 @@ func (h *ChatHandler) GetSession(c *gin.Context) {
 func (h *ChatHandler) DeleteSession(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	sessionIDStr := c.Param("id")
 	sessionID, err := primitive.ObjectIDFromHex(sessionIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		errcode.RespondCode(c, errcode.Validation, "Invalid session ID")
 		return
 	}
 
 	err = h.chatService.DeleteSession(c.Request.Context(), sessionID, userID, companyID)
 	if err != nil {
 		h.logger.Error("Failed to delete session", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errcode.RespondCode(c, errcode.Internal, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	envelope.OK(c, gin.H{
 		"success": true,
 		"message": "Session deleted successfully",
 	})
@@ -202,31 +206,31 @@ func (h *ChatHandler) getOrCreateDefaultSession(c *gin.Context, userID, companyI
 func (h *ChatHandler) UpdateSession(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	sessionIDStr := c.Param("id")
 	sessionID, err := primitive.ObjectIDFromHex(sessionIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		errcode.RespondCode(c, errcode.Validation, "Invalid session ID")
 		return
 	}
 
 	var req models.UpdateSessionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
 	updatedSession, err := h.chatService.UpdateSession(c.Request.Context(), sessionID, userID, companyID, req.Title)
 	if err != nil {
 		h.logger.Error("Failed to update session", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errcode.RespondCode(c, errcode.Internal, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"session": updatedSession})
+	envelope.OK(c, gin.H{"session": updatedSession})
 }
 
 // GetMessages retrieves messages for a session with pagination
@@ -234,7 +238,7 @@ func (h *ChatHandler) UpdateSession(c *gin.Context) {
 func (h *ChatHandler) GetMessages(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
@@ -246,14 +250,14 @@ func (h *ChatHandler) GetMessages(c *gin.Context) {
 		sessionID, err = h.getOrCreateDefaultSession(c, userID, companyID)
 		if err != nil {
 			h.logger.Error("Failed to get or create default session", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get default session"})
+			errcode.RespondCode(c, errcode.Internal, "Failed to get default session")
 			return
 		}
 	} else {
 		// Parse as MongoDB ObjectID
 		sessionID, err = primitive.ObjectIDFromHex(sessionIDStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID format. Use a valid ObjectID hex string or 'default-session'"})
+			errcode.RespondCode(c, errcode.Validation, "Invalid session ID format. Use a valid ObjectID hex string or 'default-session'")
 			return
 		}
 	}
@@ -279,11 +283,11 @@ func (h *ChatHandler) GetMessages(c *gin.Context) {
 
 	response, err := h.chatService.GetMessages(c.Request.Context(), sessionID, companyID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		errcode.RespondCode(c, errcode.NotFound, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	envelope.List(c, response, envelope.NewPagination(int(response.Total), response.Limit, response.Offset))
 }
 
 // SetSessionSubagent sets or clears the active subagent for a session
@@ -291,33 +295,33 @@ func (h *ChatHandler) GetMessages(c *gin.Context) {
 func (h *ChatHandler) SetSessionSubagent(c *gin.Context) {
 	userID, companyID, err := h.extractUserContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	sessionIDStr := c.Param("id")
 	sessionID, err := primitive.ObjectIDFromHex(sessionIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		errcode.RespondCode(c, errcode.Validation, "Invalid session ID")
 		return
 	}
 
 	var req struct {
 		SubagentID *string `json:"subagentId"` // null to clear, ObjectID hex string to set
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
 	// Verify session belongs to user
 	session, err := h.chatService.GetSession(c.Request.Context(), sessionID, companyID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found or access denied"})
+		errcode.RespondCode(c, errcode.NotFound, "Session not found or access denied")
 		return
 	}
 	if session.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		errcode.RespondCode(c, errcode.PermissionDenied, "Access denied")
 		return
 	}
 
@@ -326,7 +330,7 @@ func (h *ChatHandler) SetSessionSubagent(c *gin.Context) {
 	if req.SubagentID != nil && *req.SubagentID != "" {
 		id, err := primitive.ObjectIDFromHex(*req.SubagentID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subagent ID"})
+			errcode.RespondCode(c, errcode.Validation, "Invalid subagent ID")
 			return
 		}
 		subagentObjID = &id
@@ -336,11 +340,11 @@ func (h *ChatHandler) SetSessionSubagent(c *gin.Context) {
 	err = h.chatService.SetSessionSubagent(c.Request.Context(), sessionID, subagentObjID, companyID)
 	if err != nil {
 		h.logger.Error("Failed to set session subagent", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to update session")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	envelope.OK(c, gin.H{
 		"success": true,
 		"message": "Session subagent updated successfully",
 	})
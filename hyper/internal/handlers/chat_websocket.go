@@ -9,6 +9,7 @@ import (
 	"time"
 
 	aiservice "hyper/internal/ai-service"
+	"hyper/internal/errcode"
 	"hyper/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -99,33 +100,33 @@ func (h *ChatWebSocketHandler) HandleChatWebSocket(c *gin.Context) {
 	// Extract authentication from context (set by middleware)
 	userID, companyID, err := h.extractAuthFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Unauthorized: "+err.Error())
 		return
 	}
 
 	// Get session ID from query
 	sessionIDStr := c.Query("sessionId")
 	if sessionIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing sessionId parameter"})
+		errcode.RespondCode(c, errcode.Validation, "Missing sessionId parameter")
 		return
 	}
 
 	sessionID, err := primitive.ObjectIDFromHex(sessionIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sessionId"})
+		errcode.RespondCode(c, errcode.Validation, "Invalid sessionId")
 		return
 	}
 
 	// Verify session exists and user has access
 	session, err := h.chatService.GetSession(c.Request.Context(), sessionID, companyID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found or access denied"})
+		errcode.RespondCode(c, errcode.NotFound, "Session not found or access denied")
 		return
 	}
 
 	// Verify session belongs to user
 	if session.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: session belongs to different user"})
+		errcode.RespondCode(c, errcode.PermissionDenied, "Access denied: session belongs to different user")
 		return
 	}
 
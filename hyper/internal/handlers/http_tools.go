@@ -3,14 +3,17 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
-	"hyper/internal/models"
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
 	"hyper/internal/mcp/storage"
+	"hyper/internal/models"
+	"hyper/internal/validation"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -34,7 +37,7 @@ func NewHTTPToolsHandler(
 	logger *zap.Logger,
 ) (*HTTPToolsHandler, error) {
 	handler := &HTTPToolsHandler{
-		httpToolsCollection: mongoDatabase.Collection("http_tools"),
+		httpToolsCollection: mongoDatabase.Collection(storage.CollectionName("http_tools")),
 		toolsStorage:        toolsStorage,
 		logger:              logger,
 	}
@@ -87,47 +90,36 @@ func (h *HTTPToolsHandler) CreateHTTPTool(c *gin.Context) {
 	userID, exists := c.Get("userId")
 	if !exists {
 		h.logger.Error("User ID not found in context")
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User identity not found. JWT middleware may not be configured correctly.",
-		})
+		errcode.RespondCode(c, errcode.Unauthenticated, "User identity not found. JWT middleware may not be configured correctly.")
 		return
 	}
 
 	companyID, exists := c.Get("companyId")
 	if !exists {
 		h.logger.Error("Company ID not found in context")
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Company identity not found. JWT middleware may not be configured correctly.",
-		})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Company identity not found. JWT middleware may not be configured correctly.")
 		return
 	}
 
 	userIDStr, ok := userID.(string)
 	if !ok {
 		h.logger.Error("User ID is not a string", zap.Any("userId", userID))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Invalid user ID type in context",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Invalid user ID type in context")
 		return
 	}
 
 	companyIDStr, ok := companyID.(string)
 	if !ok {
 		h.logger.Error("Company ID is not a string", zap.Any("companyId", companyID))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Invalid company ID type in context",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Invalid company ID type in context")
 		return
 	}
 
 	// Parse request body
 	var req models.CreateHTTPToolRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
 		h.logger.Error("Failed to parse request body", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
@@ -140,9 +132,7 @@ func (h *HTTPToolsHandler) CreateHTTPTool(c *gin.Context) {
 		models.HTTPMethodPATCH:  true,
 	}
 	if !validMethods[req.Method] {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Invalid HTTP method '%s'. Allowed: GET, POST, PUT, DELETE, PATCH", req.Method),
-		})
+		errcode.RespondCode(c, errcode.Validation, fmt.Sprintf("Invalid HTTP method '%s'. Allowed: GET, POST, PUT, DELETE, PATCH", req.Method))
 		return
 	}
 
@@ -154,17 +144,13 @@ func (h *HTTPToolsHandler) CreateHTTPTool(c *gin.Context) {
 		models.AuthTypeBasic:  true,
 	}
 	if !validAuthTypes[req.AuthType] {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Invalid auth type '%s'. Allowed: none, bearer, apiKey, basic", req.AuthType),
-		})
+		errcode.RespondCode(c, errcode.Validation, fmt.Sprintf("Invalid auth type '%s'. Allowed: none, bearer, apiKey, basic", req.AuthType))
 		return
 	}
 
 	// Validate endpoint is a valid URL or path
 	if req.Endpoint == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Endpoint cannot be empty",
-		})
+		errcode.RespondCode(c, errcode.Validation, "Endpoint cannot be empty")
 		return
 	}
 
@@ -197,16 +183,12 @@ func (h *HTTPToolsHandler) CreateHTTPTool(c *gin.Context) {
 			h.logger.Warn("HTTP tool already exists",
 				zap.String("toolName", req.ToolName),
 				zap.String("companyId", companyIDStr))
-			c.JSON(http.StatusConflict, gin.H{
-				"error": fmt.Sprintf("HTTP tool with name '%s' already exists for your company", req.ToolName),
-			})
+			errcode.RespondCode(c, errcode.Conflict, fmt.Sprintf("HTTP tool with name '%s' already exists for your company", req.ToolName))
 			return
 		}
 
 		h.logger.Error("Failed to store HTTP tool in MongoDB", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to store HTTP tool. Please try again.",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Failed to store HTTP tool. Please try again.")
 		return
 	}
 
@@ -237,7 +219,7 @@ func (h *HTTPToolsHandler) CreateHTTPTool(c *gin.Context) {
 		zap.String("companyId", companyIDStr),
 		zap.String("createdBy", userIDStr))
 
-	c.JSON(http.StatusCreated, gin.H{
+	envelope.JSON(c, http.StatusCreated, gin.H{
 		"id":      toolID,
 		"message": fmt.Sprintf("HTTP tool '%s' created successfully and is now discoverable via semantic search", tool.ToolName),
 		"tool":    tool,
@@ -249,17 +231,13 @@ func (h *HTTPToolsHandler) ListHTTPTools(c *gin.Context) {
 	// Extract company ID from context
 	companyID, exists := c.Get("companyId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Company identity not found",
-		})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Company identity not found")
 		return
 	}
 
 	companyIDStr, ok := companyID.(string)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Invalid company ID type in context",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Invalid company ID type in context")
 		return
 	}
 
@@ -286,15 +264,12 @@ func (h *HTTPToolsHandler) ListHTTPTools(c *gin.Context) {
 	total, err := h.httpToolsCollection.CountDocuments(ctx, filter)
 	if err != nil {
 		h.logger.Error("Failed to count HTTP tools", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to count HTTP tools",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Failed to count HTTP tools")
 		return
 	}
 
 	// Calculate pagination
 	skip := (page - 1) * pageSize
-	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
 
 	// Query with pagination
 	opts := options.Find().
@@ -305,9 +280,7 @@ func (h *HTTPToolsHandler) ListHTTPTools(c *gin.Context) {
 	cursor, err := h.httpToolsCollection.Find(ctx, filter, opts)
 	if err != nil {
 		h.logger.Error("Failed to query HTTP tools", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve HTTP tools",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Failed to retrieve HTTP tools")
 		return
 	}
 	defer cursor.Close(ctx)
@@ -316,9 +289,7 @@ func (h *HTTPToolsHandler) ListHTTPTools(c *gin.Context) {
 	var tools []models.HTTPToolDefinition
 	if err := cursor.All(ctx, &tools); err != nil {
 		h.logger.Error("Failed to decode HTTP tools", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to decode HTTP tools",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Failed to decode HTTP tools")
 		return
 	}
 
@@ -327,15 +298,9 @@ func (h *HTTPToolsHandler) ListHTTPTools(c *gin.Context) {
 		tools = make([]models.HTTPToolDefinition, 0)
 	}
 
-	response := models.HTTPToolListResponse{
-		Tools:      tools,
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-	}
-
-	c.JSON(http.StatusOK, response)
+	envelope.List(c, models.HTTPToolListResponse{
+		Tools: tools,
+	}, envelope.NewPagination(int(total), pageSize, skip))
 }
 
 // DeleteHTTPTool handles DELETE /api/v1/tools/http/:id
@@ -343,26 +308,20 @@ func (h *HTTPToolsHandler) DeleteHTTPTool(c *gin.Context) {
 	// Extract company ID from context
 	companyID, exists := c.Get("companyId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Company identity not found",
-		})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Company identity not found")
 		return
 	}
 
 	companyIDStr, ok := companyID.(string)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Invalid company ID type in context",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Invalid company ID type in context")
 		return
 	}
 
 	// Get tool ID from URL parameter
 	toolID := c.Param("id")
 	if toolID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Tool ID is required",
-		})
+		errcode.RespondCode(c, errcode.Validation, "Tool ID is required")
 		return
 	}
 
@@ -377,17 +336,13 @@ func (h *HTTPToolsHandler) DeleteHTTPTool(c *gin.Context) {
 	result, err := h.httpToolsCollection.DeleteOne(ctx, filter)
 	if err != nil {
 		h.logger.Error("Failed to delete HTTP tool", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete HTTP tool",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Failed to delete HTTP tool")
 		return
 	}
 
 	// Check if tool was found and deleted
 	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": fmt.Sprintf("HTTP tool with ID '%s' not found or you don't have permission to delete it", toolID),
-		})
+		errcode.RespondCode(c, errcode.NotFound, fmt.Sprintf("HTTP tool with ID '%s' not found or you don't have permission to delete it", toolID))
 		return
 	}
 
@@ -395,7 +350,7 @@ func (h *HTTPToolsHandler) DeleteHTTPTool(c *gin.Context) {
 		zap.String("toolId", toolID),
 		zap.String("companyId", companyIDStr))
 
-	c.JSON(http.StatusOK, gin.H{
+	envelope.OK(c, gin.H{
 		"message": "HTTP tool deleted successfully",
 		"id":      toolID,
 	})
@@ -406,26 +361,20 @@ func (h *HTTPToolsHandler) GetHTTPTool(c *gin.Context) {
 	// Extract company ID from context
 	companyID, exists := c.Get("companyId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Company identity not found",
-		})
+		errcode.RespondCode(c, errcode.Unauthenticated, "Company identity not found")
 		return
 	}
 
 	companyIDStr, ok := companyID.(string)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Invalid company ID type in context",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Invalid company ID type in context")
 		return
 	}
 
 	// Get tool ID from URL parameter
 	toolID := c.Param("id")
 	if toolID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Tool ID is required",
-		})
+		errcode.RespondCode(c, errcode.Validation, "Tool ID is required")
 		return
 	}
 
@@ -441,20 +390,16 @@ func (h *HTTPToolsHandler) GetHTTPTool(c *gin.Context) {
 	err := h.httpToolsCollection.FindOne(ctx, filter).Decode(&tool)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": fmt.Sprintf("HTTP tool with ID '%s' not found or you don't have permission to view it", toolID),
-			})
+			errcode.RespondCode(c, errcode.NotFound, fmt.Sprintf("HTTP tool with ID '%s' not found or you don't have permission to view it", toolID))
 			return
 		}
 
 		h.logger.Error("Failed to retrieve HTTP tool", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve HTTP tool",
-		})
+		errcode.RespondCode(c, errcode.Internal, "Failed to retrieve HTTP tool")
 		return
 	}
 
-	c.JSON(http.StatusOK, tool)
+	envelope.OK(c, tool)
 }
 
 // generateSemanticDescription creates a semantic-friendly description for tool discovery
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"io"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/jira"
+	"hyper/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxWebhookBytes caps inbound webhook payloads
+const maxWebhookBytes = 1 << 20
+
+// JiraWebhookPath receives Jira issue webhooks
+const JiraWebhookPath = middleware.WebhookPathPrefix + "jira"
+
+// JiraWebhookHandler applies Jira issue transitions to linked human tasks
+type JiraWebhookHandler struct {
+	sync   *jira.Sync
+	logger *zap.Logger
+}
+
+// NewJiraWebhookHandler creates a new Jira webhook handler
+func NewJiraWebhookHandler(sync *jira.Sync, logger *zap.Logger) *JiraWebhookHandler {
+	return &JiraWebhookHandler{sync: sync, logger: logger}
+}
+
+// RegisterRoutes registers the Jira webhook route
+func (h *JiraWebhookHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST(JiraWebhookPath, h.HandleWebhook)
+}
+
+// HandleWebhook applies an issue's status to its linked task. The shared
+// secret is passed as the token query parameter (Jira webhook URLs cannot set
+// headers) or the X-Webhook-Token header.
+// POST /api/v1/webhooks/jira
+func (h *JiraWebhookHandler) HandleWebhook(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.GetHeader("X-Webhook-Token")
+	}
+	if !h.sync.VerifyWebhook(token) {
+		errcode.RespondCode(c, errcode.Unauthenticated, "Invalid webhook token")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		errcode.RespondCode(c, errcode.Validation, "Failed to read webhook payload")
+		return
+	}
+
+	issue, err := jira.ParseWebhook(body)
+	if err != nil {
+		errcode.RespondCode(c, errcode.Validation, err.Error())
+		return
+	}
+	if issue == nil {
+		envelope.OK(c, gin.H{"ignored": true})
+		return
+	}
+
+	updated, err := h.sync.ApplyIssue(issue)
+	if err != nil {
+		h.logger.Error("Failed to apply Jira webhook", zap.String("issue", issue.Key), zap.Error(err))
+		errcode.Respond(c, err, "Failed to update task from Jira")
+		return
+	}
+
+	envelope.OK(c, gin.H{"issueKey": issue.Key, "updated": updated})
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/knowledgeio"
+	"hyper/internal/mcp/storage"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Knowledge export formats
+const (
+	ExportFormatNDJSON  = "ndjson"
+	ExportFormatParquet = "parquet"
+)
+
+// ExportKnowledge streams a collection as NDJSON, one storage.ExportedKnowledge
+// per line, in the row format ImportKnowledge accepts. With vectors=true each
+// line carries its embedding so an import into a deployment using the same
+// embedding dimension skips re-embedding. since (RFC 3339) limits the export
+// to entries created at or after that time, for incremental replica syncs.
+// GET /api/v1/knowledge/export?collection=xxx&vectors=true&format=ndjson&since=2025-01-02T15:04:05Z
+func (h *KnowledgeHandler) ExportKnowledge(c *gin.Context) {
+	collection := c.Query("collection")
+	if collection == "" {
+		errcode.RespondCode(c, errcode.Validation, "collection is required")
+		return
+	}
+
+	switch strings.ToLower(c.DefaultQuery("format", ExportFormatNDJSON)) {
+	case ExportFormatNDJSON, "jsonl":
+	case ExportFormatParquet:
+		// Writing Parquet needs a columnar encoding library this build does not include
+		errcode.RespondCode(c, errcode.Validation, "parquet export is not supported; use format=ndjson")
+		return
+	default:
+		errcode.RespondCode(c, errcode.Validation, fmt.Sprintf("unsupported format %q: must be ndjson", c.Query("format")))
+		return
+	}
+
+	withVectors := c.Query("vectors") == "true"
+
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339Nano, raw); err != nil {
+			errcode.RespondCode(c, errcode.Validation, fmt.Sprintf("invalid since %q: must be an RFC 3339 time", raw))
+			return
+		}
+	}
+	export, err := knowledgeio.Exporter(h.knowledgeStorage, since)
+	if err != nil {
+		errcode.Respond(c, err, err.Error())
+		return
+	}
+
+	// Headers are written with the first entry, so failures before it still
+	// get a regular error response
+	exported := 0
+	encoder := json.NewEncoder(c.Writer)
+	err = export(c.Request.Context(), collection, withVectors, func(entry *storage.ExportedKnowledge) error {
+		if exported == 0 {
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", collection+".ndjson"))
+			c.Status(http.StatusOK)
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		exported++
+		if exported%100 == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+
+	h.logger.Info("Knowledge export finished",
+		zap.String("collection", collection),
+		zap.Bool("vectors", withVectors),
+		zap.Int("exported", exported),
+		zap.Error(err))
+
+	if err == nil && exported == 0 {
+		// An empty collection is an empty file, not an error
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		return
+	}
+	if err != nil {
+		if exported == 0 {
+			errcode.Respond(c, err, err.Error())
+			return
+		}
+		// Too late for an error status: end the stream with an error envelope so
+		// a truncated export is detectable (importing it reports that line as a bad row)
+		_ = encoder.Encode(envelope.Response{Error: &envelope.Error{
+			Code:    string(errcode.Of(err)),
+			Message: err.Error(),
+		}})
+	}
+}
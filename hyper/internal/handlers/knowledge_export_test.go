@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// exportTestStorage exports fixed entries, failing after failAfter of them when set
+type exportTestStorage struct {
+	importTestStorage
+	entries   []*storage.KnowledgeEntry
+	failAfter int
+}
+
+func (s *exportTestStorage) ExportKnowledge(ctx context.Context, collection string, withVectors bool, visit func(*storage.ExportedKnowledge) error) error {
+	for i, entry := range s.entries {
+		if s.failAfter > 0 && i == s.failAfter {
+			return errors.New("qdrant unavailable")
+		}
+		exported := &storage.ExportedKnowledge{KnowledgeEntry: entry}
+		if withVectors {
+			exported.Vector = []float64{0.5, 0.25}
+		}
+		if err := visit(exported); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getExport(t *testing.T, store storage.KnowledgeStorage, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewKnowledgeHandler(store, zap.NewNop()).RegisterRoutes(r.Group("/api/v1/knowledge"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/knowledge/export"+query, nil))
+	return w
+}
+
+func TestExportKnowledge_RoundTripsThroughImport(t *testing.T) {
+	store := &exportTestStorage{entries: []*storage.KnowledgeEntry{
+		{ID: "1", Collection: "adr", Text: "Use MongoDB", Metadata: map[string]interface{}{"status": "accepted"}},
+		{ID: "2", Collection: "adr", Text: "Use Qdrant"},
+	}}
+
+	w := getExport(t, store, "?collection=adr&vectors=true")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), `filename="adr.ndjson"`)
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	var first storage.ExportedKnowledge
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "Use MongoDB", first.Text)
+	assert.Equal(t, []float64{0.5, 0.25}, first.Vector)
+
+	// The export is valid import input, and vectors are passed on for reuse
+	target := &importTestStorage{}
+	imported := postImport(t, target, "", "application/x-ndjson", w.Body.String())
+	require.Equal(t, http.StatusOK, imported.Code, imported.Body.String())
+	assert.Equal(t, 2, decodeImportReport(t, imported.Body.Bytes()).Imported)
+	require.Len(t, target.batches, 1)
+	assert.Equal(t, []float64{0.5, 0.25}, target.batches[0][0].Vector)
+	assert.Equal(t, "accepted", target.batches[0][0].Metadata["status"])
+}
+
+func TestExportKnowledge_OmitsVectorsByDefault(t *testing.T) {
+	store := &exportTestStorage{entries: []*storage.KnowledgeEntry{{ID: "1", Collection: "adr", Text: "Use MongoDB"}}}
+
+	w := getExport(t, store, "?collection=adr")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"vector"`)
+}
+
+func TestExportKnowledge_Validation(t *testing.T) {
+	store := &exportTestStorage{}
+
+	w := getExport(t, store, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "collection is required")
+
+	w = getExport(t, store, "?collection=adr&format=parquet")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "parquet export is not supported")
+}
+
+func TestExportKnowledge_FailureMidStream(t *testing.T) {
+	store := &exportTestStorage{
+		entries: []*storage.KnowledgeEntry{
+			{ID: "1", Collection: "adr", Text: "first"},
+			{ID: "2", Collection: "adr", Text: "second"},
+		},
+		failAfter: 1,
+	}
+
+	w := getExport(t, store, "?collection=adr")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"DEPENDENCY_UNAVAILABLE"`)
+}
+
+func TestExportKnowledge_Since(t *testing.T) {
+	store := &exportTestStorage{entries: []*storage.KnowledgeEntry{{ID: "1", Collection: "adr", Text: "Use MongoDB"}}}
+
+	w := getExport(t, store, "?collection=adr&since=yesterday")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "RFC 3339")
+
+	// Storages without incremental export reject since instead of exporting everything
+	w = getExport(t, store, "?collection=adr&since=2025-01-02T15:04:05Z")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "incremental export")
+}
+
+func TestImportKnowledge_Reembed(t *testing.T) {
+	body := `{"collection":"adr","text":"Use MongoDB","vector":[0.5,0.25]}`
+
+	target := &importTestStorage{}
+	w := postImport(t, target, "?reembed=true", "application/x-ndjson", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, decodeImportReport(t, w.Body.Bytes()).Reembedded)
+	require.Len(t, target.batches, 1)
+	assert.Nil(t, target.batches[0][0].Vector, "exported vectors are dropped so the row is embedded again")
+}
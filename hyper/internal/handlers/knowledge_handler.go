@@ -1,10 +1,14 @@
 package handlers
 
 import (
-	"net/http"
 	"strconv"
+	"time"
 
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
 	"hyper/internal/mcp/storage"
+	"hyper/internal/validation"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -42,15 +46,15 @@ func (h *KnowledgeHandler) GetPopularCollections(c *gin.Context) {
 	collections, err := h.knowledgeStorage.GetPopularCollections(limit)
 	if err != nil {
 		h.logger.Error("Failed to get popular collections", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve popular collections"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to retrieve popular collections")
 		return
 	}
 
 	// Return collections (empty array if no results)
-	c.JSON(http.StatusOK, gin.H{
+	envelope.List(c, gin.H{
 		"collections": collections,
 		"count":       len(collections),
-	})
+	}, envelope.NewPagination(len(collections), limit, 0))
 }
 
 // QueryKnowledge searches the knowledge base
@@ -62,8 +66,8 @@ func (h *KnowledgeHandler) QueryKnowledge(c *gin.Context) {
 		Limit      int    `json:"limit"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
@@ -77,13 +81,13 @@ func (h *KnowledgeHandler) QueryKnowledge(c *gin.Context) {
 	}
 
 	// Query knowledge storage
-	results, err := h.knowledgeStorage.Query(req.Collection, req.Query, limit)
+	results, err := storage.QueryKnowledgeContext(c.Request.Context(), h.knowledgeStorage, req.Collection, req.Query, limit)
 	if err != nil {
 		h.logger.Error("Failed to query knowledge",
 			zap.String("collection", req.Collection),
 			zap.String("query", req.Query),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query knowledge base"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to query knowledge base")
 		return
 	}
 
@@ -99,7 +103,7 @@ func (h *KnowledgeHandler) QueryKnowledge(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	envelope.OK(c, gin.H{
 		"entries": entries,
 	})
 }
@@ -128,7 +132,7 @@ func (h *KnowledgeHandler) BrowseKnowledge(c *gin.Context) {
 		popular, err := h.knowledgeStorage.GetPopularCollections(5)
 		if err != nil {
 			h.logger.Error("Failed to get popular collections", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to browse knowledge base"})
+			errcode.RespondCode(c, errcode.Internal, "Failed to browse knowledge base")
 			return
 		}
 
@@ -160,7 +164,7 @@ func (h *KnowledgeHandler) BrowseKnowledge(c *gin.Context) {
 			h.logger.Error("Failed to list knowledge",
 				zap.String("collection", collection),
 				zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to browse knowledge base"})
+			errcode.RespondCode(c, errcode.Internal, "Failed to browse knowledge base")
 			return
 		}
 		allEntries = entries
@@ -177,9 +181,9 @@ func (h *KnowledgeHandler) BrowseKnowledge(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	envelope.List(c, gin.H{
 		"entries": responseEntries,
-	})
+	}, envelope.NewPagination(len(responseEntries), limit, 0))
 }
 
 // GetAllCollections retrieves all collections with metadata
@@ -188,7 +192,7 @@ func (h *KnowledgeHandler) GetAllCollections(c *gin.Context) {
 	collections, err := h.knowledgeStorage.GetCollectionStatsWithMetadata()
 	if err != nil {
 		h.logger.Error("Failed to get all collections", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve collections"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to retrieve collections")
 		return
 	}
 
@@ -202,9 +206,52 @@ func (h *KnowledgeHandler) GetAllCollections(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	envelope.List(c, gin.H{
 		"collections": responseCollections,
-	})
+	}, envelope.Complete(len(responseCollections)))
+}
+
+// GetKnowledgeAnalytics reports query hits and never-hit/stale entries per collection
+// GET /api/v1/knowledge/analytics?staleDays=30&limit=20
+func (h *KnowledgeHandler) GetKnowledgeAnalytics(c *gin.Context) {
+	analytics, ok := h.knowledgeStorage.(storage.KnowledgeAnalytics)
+	if !ok {
+		errcode.RespondCode(c, errcode.Internal, "knowledge storage does not support analytics")
+		return
+	}
+
+	staleAfter := storage.DefaultKnowledgeStaleAfter
+	if daysStr := c.Query("staleDays"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days <= 0 {
+			errcode.RespondCode(c, errcode.Validation, "staleDays must be a positive number of days")
+			return
+		}
+		staleAfter = time.Duration(days) * 24 * time.Hour
+	}
+
+	// Parse limit of listed entries per collection (default 20, max 100)
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if val, err := strconv.Atoi(limitStr); err == nil && val > 0 {
+			limit = val
+			if limit > 100 {
+				limit = 100 // Max limit
+			}
+		}
+	}
+
+	reports, err := analytics.GetUsageReports(staleAfter, limit)
+	if err != nil {
+		h.logger.Error("Failed to build knowledge analytics", zap.Error(err))
+		errcode.Respond(c, err, "Failed to build knowledge analytics")
+		return
+	}
+
+	envelope.List(c, gin.H{
+		"collections":    reports,
+		"staleAfterDays": int(staleAfter.Hours() / 24),
+	}, envelope.Complete(len(reports)))
 }
 
 // RegisterRoutes registers all knowledge-related routes
@@ -213,4 +260,7 @@ func (h *KnowledgeHandler) RegisterRoutes(r *gin.RouterGroup) {
 	r.GET("/collections", h.GetAllCollections)
 	r.GET("/browse", h.BrowseKnowledge)
 	r.POST("/query", h.QueryKnowledge)
+	r.POST("/import", h.ImportKnowledge)
+	r.GET("/export", h.ExportKnowledge)
+	r.GET("/analytics", h.GetKnowledgeAnalytics)
 }
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/knowledgeio"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ImportKnowledge bulk-imports knowledge entries from NDJSON, such as an
+// export, or CSV. With reembed=true exported vectors are ignored and every row
+// is embedded again, e.g. after switching embedding models.
+// POST /api/v1/knowledge/import?format=ndjson|csv&collection=...&batchSize=32&progress=true&reembed=true
+func (h *KnowledgeHandler) ImportKnowledge(c *gin.Context) {
+	format, err := knowledgeio.Format(c.Query("format"), c.ContentType())
+	if err != nil {
+		errcode.RespondCode(c, errcode.Validation, err.Error())
+		return
+	}
+
+	batchSize := knowledgeio.DefaultBatchSize
+	if sizeStr := c.Query("batchSize"); sizeStr != "" {
+		if val, err := strconv.Atoi(sizeStr); err == nil && val > 0 {
+			batchSize = val
+			if batchSize > knowledgeio.MaxBatchSize {
+				batchSize = knowledgeio.MaxBatchSize // Max batch size
+			}
+		}
+	}
+
+	reader, err := knowledgeio.NewRowReader(format, c.Request.Body, c.Query("collection"))
+	if err != nil {
+		errcode.RespondCode(c, errcode.Validation, err.Error())
+		return
+	}
+
+	importer := &knowledgeio.Importer{
+		Storage:   h.knowledgeStorage,
+		BatchSize: batchSize,
+		Reembed:   c.Query("reembed") == "true",
+	}
+
+	// With progress=true the response is NDJSON: one {"progress": ...} line per
+	// batch, then the enveloped report as the last line
+	streamProgress := c.Query("progress") == "true"
+	if streamProgress {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		encoder := json.NewEncoder(c.Writer)
+		importer.OnProgress = func(progress knowledgeio.Progress) {
+			_ = encoder.Encode(gin.H{"progress": progress})
+			c.Writer.Flush()
+		}
+	}
+
+	report, importErr := importer.Run(c.Request.Context(), format, reader)
+
+	h.logger.Info("Knowledge import finished",
+		zap.String("format", format),
+		zap.Int("processed", report.Processed),
+		zap.Int("imported", report.Imported),
+		zap.Int("failed", report.Failed),
+		zap.Int("batches", report.Batches),
+		zap.Error(importErr))
+
+	if streamProgress {
+		final := envelope.Response{Data: report}
+		if importErr != nil {
+			final = envelope.Response{Error: &envelope.Error{
+				Code:    string(errcode.Validation),
+				Message: importErr.Error(),
+				Details: report,
+			}}
+		}
+		_ = json.NewEncoder(c.Writer).Encode(final)
+		return
+	}
+
+	if importErr != nil {
+		// Rows before the failure were imported; the report says how many
+		errcode.RespondDetails(c, errcode.Validation, importErr.Error(), report)
+		return
+	}
+	envelope.OK(c, report)
+}
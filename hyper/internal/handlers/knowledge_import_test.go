@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hyper/internal/knowledgeio"
+	"hyper/internal/mcp/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// importTestStorage records batched upserts and fails collections named "broken"
+type importTestStorage struct {
+	storage.KnowledgeStorage
+	batches [][]storage.KnowledgeInput
+}
+
+func (s *importTestStorage) UpsertBatch(collection string, inputs []storage.KnowledgeInput) ([]*storage.KnowledgeEntry, error) {
+	if collection == "broken" {
+		return nil, errors.New("qdrant unavailable")
+	}
+	s.batches = append(s.batches, inputs)
+	return make([]*storage.KnowledgeEntry, len(inputs)), nil
+}
+
+func postImport(t *testing.T, store storage.KnowledgeStorage, query, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewKnowledgeHandler(store, zap.NewNop()).RegisterRoutes(r.Group("/api/v1/knowledge"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/knowledge/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeImportReport(t *testing.T, body []byte) knowledgeio.Report {
+	t.Helper()
+	var resp struct {
+		Data knowledgeio.Report `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp), string(body))
+	return resp.Data
+}
+
+func TestImportKnowledge_NDJSON(t *testing.T) {
+	store := &importTestStorage{}
+	body := strings.Join([]string{
+		`{"collection":"adr","text":"Use MongoDB","metadata":{"source":"wiki"}}`,
+		``,
+		`{"text":"Defaults to the query collection"}`,
+		`{not json`,
+		`{"collection":"adr","text":"  "}`,
+		`{"collection":"broken","text":"Lost"}`,
+	}, "\n")
+
+	w := postImport(t, store, "?collection=notes&batchSize=2", "application/x-ndjson", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	report := decodeImportReport(t, w.Body.Bytes())
+	assert.Equal(t, 5, report.Processed)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 3, report.Failed)
+	assert.Equal(t, map[string]int{"adr": 1, "notes": 1}, report.Collections)
+
+	require.Len(t, report.Errors, 3)
+	assert.Equal(t, 4, report.Errors[0].Row)
+	assert.Contains(t, report.Errors[0].Error, "invalid JSON")
+	assert.Equal(t, 5, report.Errors[1].Row)
+	assert.Contains(t, report.Errors[1].Error, "text is required")
+	assert.Equal(t, 6, report.Errors[2].Row)
+	assert.Equal(t, "broken", report.Errors[2].Collection)
+	assert.Equal(t, "wiki", store.batches[0][0].Metadata["source"])
+}
+
+func TestImportKnowledge_CSV(t *testing.T) {
+	store := &importTestStorage{}
+	body := "collection,text,metadata,author\n" +
+		"adr,\"Use MongoDB, not Postgres\",\"{\"\"status\"\":\"\"accepted\"\"}\",sam\n" +
+		"adr,Bad metadata,not-json,\n" +
+		",No collection,,\n"
+
+	w := postImport(t, store, "", "text/csv", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	report := decodeImportReport(t, w.Body.Bytes())
+	assert.Equal(t, knowledgeio.FormatCSV, report.Format)
+	assert.Equal(t, 1, report.Imported)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, 3, report.Errors[0].Row)
+	assert.Contains(t, report.Errors[0].Error, "metadata must be a JSON object")
+	assert.Contains(t, report.Errors[1].Error, "collection is required")
+
+	entry := store.batches[0][0]
+	assert.Equal(t, "Use MongoDB, not Postgres", entry.Text)
+	assert.Equal(t, "accepted", entry.Metadata["status"])
+	assert.Equal(t, "sam", entry.Metadata["author"])
+}
+
+func TestImportKnowledge_CSVRequiresTextColumn(t *testing.T) {
+	w := postImport(t, &importTestStorage{}, "?format=csv", "text/plain", "collection,body\nadr,x\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "text column")
+}
+
+func TestImportKnowledge_StreamsProgress(t *testing.T) {
+	body := strings.Repeat(`{"collection":"adr","text":"entry"}`+"\n", 5)
+	w := postImport(t, &importTestStorage{}, "?batchSize=2&progress=true", "application/x-ndjson", body)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	var lines []string
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.Len(t, lines, 4, "three progress lines and the final report")
+	assert.Contains(t, lines[0], `"progress"`)
+	assert.Equal(t, 5, decodeImportReport(t, []byte(lines[3])).Imported)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/middleware"
+	"hyper/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RolesHandler handles HTTP REST requests for RBAC role administration
+type RolesHandler struct {
+	roleStorage *storage.RoleStorage
+	logger      *zap.Logger
+}
+
+// NewRolesHandler creates a new roles handler
+func NewRolesHandler(roleStorage *storage.RoleStorage, logger *zap.Logger) *RolesHandler {
+	return &RolesHandler{
+		roleStorage: roleStorage,
+		logger:      logger,
+	}
+}
+
+// DTOs for roles API
+type ListRolesResponse struct {
+	Roles       []middleware.Role         `json:"roles"`
+	Assignments []*storage.RoleAssignment `json:"assignments"`
+	Count       int                       `json:"count"`
+}
+
+type SetUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ListRoles returns the supported roles and all stored assignments
+// GET /api/v1/admin/roles
+func (h *RolesHandler) ListRoles(c *gin.Context) {
+	assignments, err := h.roleStorage.ListRoleAssignments()
+	if err != nil {
+		h.logger.Error("Failed to list role assignments", zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to retrieve role assignments")
+		return
+	}
+
+	envelope.List(c, ListRolesResponse{
+		Roles:       middleware.AllRoles,
+		Assignments: assignments,
+		Count:       len(assignments),
+	}, envelope.Complete(len(assignments)))
+}
+
+// GetCurrentRole returns the role resolved for the calling user
+// GET /api/v1/roles/me
+func (h *RolesHandler) GetCurrentRole(c *gin.Context) {
+	envelope.OK(c, gin.H{
+		"userId": c.GetString("userId"),
+		"role":   middleware.GetRole(c),
+	})
+}
+
+// SetUserRole assigns a role to a user
+// PUT /api/v1/admin/roles/:userId
+func (h *RolesHandler) SetUserRole(c *gin.Context) {
+	userID := c.Param("userId")
+
+	var req SetUserRoleRequest
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
+		return
+	}
+
+	role, ok := middleware.ParseRole(req.Role)
+	if !ok {
+		errcode.RespondCode(c, errcode.Validation, "Invalid role. Must be: viewer, contributor, operator, or admin")
+		return
+	}
+
+	assignment, err := h.roleStorage.SetUserRole(userID, string(role), c.GetString("userId"))
+	if err != nil {
+		h.logger.Error("Failed to set user role", zap.String("userId", userID), zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to set user role")
+		return
+	}
+
+	envelope.OK(c, assignment)
+}
+
+// DeleteUserRole removes a user's role assignment so defaults and claims apply again
+// DELETE /api/v1/admin/roles/:userId
+func (h *RolesHandler) DeleteUserRole(c *gin.Context) {
+	userID := c.Param("userId")
+
+	if err := h.roleStorage.DeleteUserRole(userID); err != nil {
+		h.logger.Error("Failed to delete user role", zap.String("userId", userID), zap.Error(err))
+		errcode.RespondCode(c, errcode.NotFound, err.Error())
+		return
+	}
+
+	envelope.OK(c, gin.H{
+		"success": true,
+		"message": "Role assignment removed",
+	})
+}
+
+// RegisterRolesRoutes registers role administration routes
+func (h *RolesHandler) RegisterRolesRoutes(r *gin.Engine) {
+	r.GET("/api/v1/roles/me", h.GetCurrentRole)
+
+	admin := r.Group("/api/v1/admin/roles", middleware.RequireRole(middleware.RoleAdmin))
+	{
+		admin.GET("", h.ListRoles)
+		admin.PUT("/:userId", h.SetUserRole)
+		admin.DELETE("/:userId", h.DeleteUserRole)
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/middleware"
+	"hyper/internal/share"
+	"hyper/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Share link environment variables
+const (
+	ShareLinkSecretEnv    = "SHARE_LINK_SECRET"     // Signs share tokens; unset disables share links
+	ShareLinkBaseURLEnv   = "SHARE_LINK_BASE_URL"   // Public origin of share URLs, e.g. https://hyper.example.com; required
+	ShareLinkRateLimitEnv = "SHARE_LINK_RATE_LIMIT" // Views per minute and client IP (default 60)
+)
+
+// Share link lifetimes
+const (
+	DefaultShareLinkHours = 7 * 24
+	MaxShareLinkHours     = 30 * 24
+)
+
+// defaultShareLinkRateLimit caps public views per minute and client IP
+const defaultShareLinkRateLimit = 60
+
+// ShareLinkStore persists share links
+type ShareLinkStore interface {
+	CreateShareLink(humanTaskID, createdBy string, expiresAt time.Time) (*storage.ShareLink, error)
+	GetShareLink(id string) (*storage.ShareLink, error)
+	ListShareLinks(humanTaskID string) ([]*storage.ShareLink, error)
+	RevokeShareLink(id string) (*storage.ShareLink, error)
+	RecordShareLinkView(id string) error
+}
+
+// ShareLinksHandler creates and revokes read-only share links for human tasks
+// and serves them to people without an account
+type ShareLinksHandler struct {
+	links       ShareLinkStore
+	taskStorage storage.TaskStorage
+	signer      *share.Signer
+	limiter     *share.Limiter
+	baseURL     string
+	logger      *zap.Logger
+}
+
+// NewShareLinksHandler creates a share link handler. It returns nil when
+// SHARE_LINK_SECRET is not set, leaving share links disabled. Share URLs are
+// built from SHARE_LINK_BASE_URL only, never from the request's Host or
+// forwarded headers, which the caller controls; without it share links stay
+// disabled too.
+func NewShareLinksHandler(links ShareLinkStore, taskStorage storage.TaskStorage, logger *zap.Logger) *ShareLinksHandler {
+	secret := os.Getenv(ShareLinkSecretEnv)
+	if secret == "" {
+		return nil
+	}
+
+	baseURL := strings.TrimSuffix(os.Getenv(ShareLinkBaseURLEnv), "/")
+	if u, err := url.Parse(baseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		logger.Warn("Share links disabled: SHARE_LINK_BASE_URL must be the public http(s) origin of share URLs",
+			zap.String("value", baseURL))
+		return nil
+	}
+
+	limit := defaultShareLinkRateLimit
+	if raw := os.Getenv(ShareLinkRateLimitEnv); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			limit = n
+		} else {
+			logger.Warn("Ignoring invalid share link rate limit", zap.String("value", raw))
+		}
+	}
+
+	return &ShareLinksHandler{
+		links:       links,
+		taskStorage: taskStorage,
+		signer:      share.NewSigner([]byte(secret)),
+		limiter:     share.NewLimiter(limit, time.Minute),
+		baseURL:     baseURL,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registers the share link routes
+func (h *ShareLinksHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET(middleware.SharePathPrefix+":token", h.GetSharedTask)
+	r.POST("/api/v1/tasks/:id/share-links", h.CreateShareLink)
+	r.GET("/api/v1/tasks/:id/share-links", h.ListShareLinks)
+	r.DELETE("/api/v1/share-links/:id", h.RevokeShareLink)
+}
+
+// CreateShareLinkRequest is the body of CreateShareLink
+type CreateShareLinkRequest struct {
+	ExpiresInHours int `json:"expiresInHours" binding:"omitempty,min=1,max=720"`
+}
+
+// ShareLinkResponse is a share link with the URL to hand out
+type ShareLinkResponse struct {
+	*storage.ShareLink
+	URL string `json:"url,omitempty"` // Only while the link is active
+}
+
+// CreateShareLink creates a link to a human task, valid for expiresInHours
+// (default 7 days, at most 30)
+// POST /api/v1/tasks/:id/share-links
+func (h *ShareLinksHandler) CreateShareLink(c *gin.Context) {
+	var req CreateShareLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+			validation.RespondBinding(c, err, &req)
+			return
+		}
+	}
+	hours := req.ExpiresInHours
+	if hours == 0 {
+		hours = DefaultShareLinkHours
+	}
+
+	taskID := c.Param("id")
+	if _, err := h.taskStorage.GetHumanTask(taskID); err != nil {
+		errcode.RespondCode(c, errcode.NotFound, "Human task not found: "+taskID)
+		return
+	}
+
+	link, err := h.links.CreateShareLink(taskID, c.GetString("userId"), time.Now().Add(time.Duration(hours)*time.Hour))
+	if err != nil {
+		h.logger.Error("Failed to create share link", zap.String("humanTaskId", taskID), zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to create share link")
+		return
+	}
+
+	envelope.JSON(c, http.StatusCreated, h.response(link, time.Now()))
+}
+
+// ListShareLinks lists the share links of a human task, including expired
+// and revoked ones
+// GET /api/v1/tasks/:id/share-links
+func (h *ShareLinksHandler) ListShareLinks(c *gin.Context) {
+	links, err := h.links.ListShareLinks(c.Param("id"))
+	if err != nil {
+		h.logger.Error("Failed to list share links", zap.String("humanTaskId", c.Param("id")), zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to list share links")
+		return
+	}
+
+	now := time.Now()
+	responses := make([]ShareLinkResponse, len(links))
+	for i, link := range links {
+		responses[i] = h.response(link, now)
+	}
+	envelope.OK(c, responses)
+}
+
+// RevokeShareLink stops a share link from opening
+// DELETE /api/v1/share-links/:id
+func (h *ShareLinksHandler) RevokeShareLink(c *gin.Context) {
+	link, err := h.links.RevokeShareLink(c.Param("id"))
+	if err != nil {
+		h.logger.Error("Failed to revoke share link", zap.String("id", c.Param("id")), zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to revoke share link")
+		return
+	}
+	if link == nil {
+		errcode.RespondCode(c, errcode.NotFound, "Share link not found: "+c.Param("id"))
+		return
+	}
+	envelope.OK(c, h.response(link, time.Now()))
+}
+
+// GetSharedTask shows the task of a share link as an HTML page, or as JSON
+// with ?format=json. It needs no account; views are rate limited per IP.
+// GET /api/share/:token
+func (h *ShareLinksHandler) GetSharedTask(c *gin.Context) {
+	now := time.Now()
+	if ok, retryAfter := h.limiter.Allow(c.ClientIP(), now); !ok {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		errcode.RespondCode(c, errcode.RateLimited, "Too many requests, try again later")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Robots-Tag", "noindex")
+
+	linkID, err := h.signer.Verify(c.Param("token"), now)
+	if errors.Is(err, share.ErrExpiredToken) {
+		errcode.RespondCode(c, errcode.NotFound, "This share link has expired")
+		return
+	}
+	if err != nil {
+		errcode.RespondCode(c, errcode.NotFound, "Share link not found")
+		return
+	}
+
+	link, err := h.links.GetShareLink(linkID)
+	if err != nil {
+		h.logger.Error("Failed to get share link", zap.String("id", linkID), zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to load share link")
+		return
+	}
+	if link == nil {
+		errcode.RespondCode(c, errcode.NotFound, "Share link not found")
+		return
+	}
+	if !link.Active(now) {
+		errcode.RespondCode(c, errcode.NotFound, "This share link has been revoked")
+		return
+	}
+
+	human, err := h.taskStorage.GetHumanTask(link.HumanTaskID)
+	if err != nil {
+		errcode.RespondCode(c, errcode.NotFound, "The shared task no longer exists")
+		return
+	}
+
+	if err := h.links.RecordShareLinkView(link.ID); err != nil {
+		h.logger.Warn("Failed to record share link view", zap.String("id", link.ID), zap.Error(err))
+	}
+
+	agents, err := h.taskStorage.ListAgentTasksByHumanTask(human.ID)
+	if err != nil {
+		h.logger.Error("Failed to load shared task's agent tasks", zap.String("id", link.ID), zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to load shared task")
+		return
+	}
+	view := share.NewTaskView(human, agents, link.ExpiresAt, now)
+	if c.Query("format") == "json" {
+		envelope.OK(c, view)
+		return
+	}
+
+	page, err := share.RenderHTML(view)
+	if err != nil {
+		h.logger.Error("Failed to render shared task", zap.String("id", link.ID), zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to render shared task")
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// response adds the share URL, under SHARE_LINK_BASE_URL, to an active link
+func (h *ShareLinksHandler) response(link *storage.ShareLink, now time.Time) ShareLinkResponse {
+	resp := ShareLinkResponse{ShareLink: link}
+	if !link.Active(now) {
+		return resp
+	}
+
+	resp.URL = h.baseURL + middleware.SharePathPrefix + h.signer.Token(link.ID, link.ExpiresAt)
+	return resp
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryShareLinks keeps share links in memory
+type memoryShareLinks struct {
+	links map[string]*storage.ShareLink
+}
+
+func (m *memoryShareLinks) CreateShareLink(humanTaskID, createdBy string, expiresAt time.Time) (*storage.ShareLink, error) {
+	link := &storage.ShareLink{ID: fmt.Sprintf("link-%d", len(m.links)+1), HumanTaskID: humanTaskID, CreatedBy: createdBy, CreatedAt: time.Now(), ExpiresAt: expiresAt}
+	m.links[link.ID] = link
+	return link, nil
+}
+
+func (m *memoryShareLinks) GetShareLink(id string) (*storage.ShareLink, error) {
+	return m.links[id], nil
+}
+
+func (m *memoryShareLinks) ListShareLinks(humanTaskID string) ([]*storage.ShareLink, error) {
+	links := []*storage.ShareLink{}
+	for _, link := range m.links {
+		if link.HumanTaskID == humanTaskID {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+func (m *memoryShareLinks) RevokeShareLink(id string) (*storage.ShareLink, error) {
+	link := m.links[id]
+	if link != nil && link.RevokedAt == nil {
+		now := time.Now()
+		link.RevokedAt = &now
+	}
+	return link, nil
+}
+
+func (m *memoryShareLinks) RecordShareLinkView(id string) error {
+	m.links[id].Views++
+	return nil
+}
+
+// shareTaskStorage serves one human task and its agent tasks. It does not
+// implement ListAllAgentTasks, so a share view listing every agent task panics.
+type shareTaskStorage struct {
+	storage.TaskStorage
+	human  *storage.HumanTask
+	agents []*storage.AgentTask
+}
+
+func (s *shareTaskStorage) GetHumanTask(taskID string) (*storage.HumanTask, error) {
+	if taskID != s.human.ID {
+		return nil, fmt.Errorf("human task not found: %s", taskID)
+	}
+	return s.human, nil
+}
+
+func (s *shareTaskStorage) ListAgentTasksByHumanTask(humanTaskID string) ([]*storage.AgentTask, error) {
+	var tasks []*storage.AgentTask
+	for _, task := range s.agents {
+		if task.HumanTaskID == humanTaskID {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func newShareLinksRouter(t *testing.T) (*memoryShareLinks, *gin.Engine) {
+	t.Setenv(ShareLinkSecretEnv, "secret")
+	t.Setenv(ShareLinkBaseURLEnv, "http://hyper.example.com/")
+	t.Setenv(ShareLinkRateLimitEnv, "3")
+	gin.SetMode(gin.TestMode)
+
+	links := &memoryShareLinks{links: map[string]*storage.ShareLink{}}
+	tasks := &shareTaskStorage{
+		human: &storage.HumanTask{ID: "h-1", Prompt: "Launch checkout", Status: storage.TaskStatusInProgress, Notes: "internal only"},
+		agents: []*storage.AgentTask{{ID: "a-1", HumanTaskID: "h-1", AgentName: "backend", Status: storage.TaskStatusInProgress,
+			Todos: []storage.TodoItem{{Description: "Payment API", Status: storage.TodoStatusCompleted}}}},
+	}
+	r := gin.New()
+	NewShareLinksHandler(links, tasks, zap.NewNop()).RegisterRoutes(r)
+	return links, r
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = "hyper.example.com"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestNewShareLinksHandler_Disabled(t *testing.T) {
+	t.Setenv(ShareLinkSecretEnv, "")
+	assert.Nil(t, NewShareLinksHandler(&memoryShareLinks{}, &shareTaskStorage{}, zap.NewNop()))
+
+	t.Setenv(ShareLinkSecretEnv, "secret")
+	for _, baseURL := range []string{"", "hyper.example.com", "ftp://hyper.example.com"} {
+		t.Setenv(ShareLinkBaseURLEnv, baseURL)
+		assert.Nil(t, NewShareLinksHandler(&memoryShareLinks{}, &shareTaskStorage{}, zap.NewNop()), "share URLs need a base URL, got %q", baseURL)
+	}
+}
+
+func TestShareLinks(t *testing.T) {
+	links, r := newShareLinksRouter(t)
+
+	w := serve(r, http.MethodPost, "/api/v1/tasks/h-1/share-links", `{"expiresInHours": 24}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Data ShareLinkResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), created.Data.ExpiresAt, time.Minute)
+	require.True(t, strings.HasPrefix(created.Data.URL, "http://hyper.example.com/api/share/"), created.Data.URL)
+	sharePath := strings.TrimPrefix(created.Data.URL, "http://hyper.example.com")
+
+	w = serve(r, http.MethodGet, sharePath, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "Launch checkout")
+	assert.Contains(t, w.Body.String(), "Payment API")
+	assert.NotContains(t, w.Body.String(), "internal only")
+
+	w = serve(r, http.MethodGet, sharePath+"?format=json", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"todosCompleted":1`)
+	assert.Equal(t, 2, links.links[created.Data.ID].Views)
+
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodGet, sharePath+"x", "").Code, "tampered token")
+
+	w = serve(r, http.MethodGet, sharePath, "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "rate limited per IP")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestShareLinks_IgnoresRequestHost(t *testing.T) {
+	_, r := newShareLinksRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/h-1/share-links", strings.NewReader(`{}`))
+	req.Host = "evil.example"
+	req.Header.Set("X-Forwarded-Proto", "javascript")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"url":"http://hyper.example.com/api/share/`)
+}
+
+func TestShareLinks_Revoke(t *testing.T) {
+	links, r := newShareLinksRouter(t)
+	link, _ := links.CreateShareLink("h-1", "dev-user", time.Now().Add(time.Hour))
+
+	w := serve(r, http.MethodDelete, "/api/v1/share-links/"+link.ID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"url"`, "revoked links have no URL")
+
+	token := NewShareLinksHandler(links, nil, zap.NewNop()).signer.Token(link.ID, link.ExpiresAt)
+	w = serve(r, http.MethodGet, "/api/share/"+token, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "revoked")
+
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodDelete, "/api/v1/share-links/missing", "").Code)
+}
+
+func TestShareLinks_Validation(t *testing.T) {
+	_, r := newShareLinksRouter(t)
+
+	assert.Equal(t, http.StatusBadRequest, serve(r, http.MethodPost, "/api/v1/tasks/h-1/share-links", `{"expiresInHours": 1000}`).Code)
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodPost, "/api/v1/tasks/h-2/share-links", "").Code)
+
+	w := serve(r, http.MethodPost, "/api/v1/tasks/h-1/share-links", "")
+	require.Equal(t, http.StatusCreated, w.Code, "the body is optional")
+	assert.Contains(t, w.Body.String(), `"url"`)
+}
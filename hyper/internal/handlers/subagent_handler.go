@@ -1,9 +1,13 @@
 package handlers
 
 import (
-	"net/http"
+	"time"
 
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
 	"hyper/internal/mcp/storage"
+	"hyper/internal/validation"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -42,7 +46,7 @@ func (h *SubagentHandler) ListSubagents(c *gin.Context) {
 	subagents, err := h.subchatStorage.ListSubagents()
 	if err != nil {
 		h.logger.Error("Failed to list subagents", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve subagents"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to retrieve subagents")
 		return
 	}
 
@@ -57,10 +61,10 @@ func (h *SubagentHandler) ListSubagents(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, ListSubagentsResponse{
+	envelope.List(c, ListSubagentsResponse{
 		Subagents: responses,
 		Count:     len(responses),
-	})
+	}, envelope.Complete(len(responses)))
 }
 
 // GetSubagent retrieves a single subagent by name
@@ -71,15 +75,79 @@ func (h *SubagentHandler) GetSubagent(c *gin.Context) {
 	subagent, err := h.subchatStorage.GetSubagent(name)
 	if err != nil {
 		h.logger.Error("Failed to get subagent", zap.String("name", name), zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "Subagent not found"})
+		errcode.RespondCode(c, errcode.NotFound, "Subagent not found")
 		return
 	}
 
 	// Return response without systemPrompt
-	c.JSON(http.StatusOK, SubagentResponse{
+	envelope.OK(c, SubagentResponse{
 		Name:        subagent.Name,
 		Description: subagent.Description,
 		Tools:       subagent.Tools,
 		Category:    subagent.Category,
 	})
 }
+
+// PersonaResponse is a subagent's system prompt and persona document
+type PersonaResponse struct {
+	Name             string     `json:"name"`
+	SystemPrompt     string     `json:"systemPrompt"`
+	Persona          string     `json:"persona"`
+	PersonaUpdatedAt *time.Time `json:"personaUpdatedAt,omitempty"`
+}
+
+// UpdatePersonaRequest replaces the given persona fields; omitted fields are kept
+type UpdatePersonaRequest struct {
+	SystemPrompt *string `json:"systemPrompt"`
+	Persona      *string `json:"persona"`
+}
+
+// GetPersona retrieves a subagent's system prompt and persona document
+// GET /api/v1/subagents/:name/persona
+func (h *SubagentHandler) GetPersona(c *gin.Context) {
+	name := c.Param("name")
+
+	subagent, err := h.subchatStorage.GetSubagent(name)
+	if err != nil {
+		h.logger.Error("Failed to get subagent", zap.String("name", name), zap.Error(err))
+		errcode.RespondCode(c, errcode.NotFound, "Subagent not found")
+		return
+	}
+
+	envelope.OK(c, newPersonaResponse(subagent))
+}
+
+// UpdatePersona replaces a subagent's system prompt and/or persona document
+// PUT /api/v1/subagents/:name/persona
+func (h *SubagentHandler) UpdatePersona(c *gin.Context) {
+	name := c.Param("name")
+
+	var req UpdatePersonaRequest
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
+		return
+	}
+	if req.SystemPrompt == nil && req.Persona == nil {
+		errcode.RespondCode(c, errcode.Validation, "Provide systemPrompt and/or persona")
+		return
+	}
+
+	subagent, err := h.subchatStorage.SetAgentPersona(name, req.SystemPrompt, req.Persona)
+	if err != nil {
+		h.logger.Error("Failed to update subagent persona", zap.String("name", name), zap.Error(err))
+		errcode.Respond(c, err, "Failed to update subagent persona")
+		return
+	}
+
+	h.logger.Info("Subagent persona updated", zap.String("name", name))
+	envelope.OK(c, newPersonaResponse(subagent))
+}
+
+func newPersonaResponse(subagent *storage.Subagent) PersonaResponse {
+	return PersonaResponse{
+		Name:             subagent.Name,
+		SystemPrompt:     subagent.SystemPrompt,
+		Persona:          subagent.Persona,
+		PersonaUpdatedAt: subagent.PersonaUpdatedAt,
+	}
+}
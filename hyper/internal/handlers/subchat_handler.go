@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"net/http"
 
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/fieldnames"
 	"hyper/internal/mcp/storage"
+	"hyper/internal/validation"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -54,8 +58,8 @@ type ListSubchatsResponse struct {
 // POST /api/v1/subchats
 func (h *SubchatHandler) CreateSubchat(c *gin.Context) {
 	var req CreateSubchatRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
@@ -63,7 +67,7 @@ func (h *SubchatHandler) CreateSubchat(c *gin.Context) {
 	subchat, err := h.subchatStorage.CreateSubchat(req.ParentChatID, req.SubagentName, req.TaskID, req.TodoID)
 	if err != nil {
 		h.logger.Error("Failed to create subchat", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create subchat: " + err.Error()})
+		errcode.RespondCode(c, errcode.Internal, "Failed to create subchat: "+err.Error())
 		return
 	}
 
@@ -122,7 +126,7 @@ func (h *SubchatHandler) CreateSubchat(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusCreated, h.toSubchatResponse(subchat))
+	envelope.JSON(c, http.StatusCreated, h.toSubchatResponse(subchat))
 }
 
 // GetSubchat retrieves a single subchat by ID
@@ -133,11 +137,11 @@ func (h *SubchatHandler) GetSubchat(c *gin.Context) {
 	subchat, err := h.subchatStorage.GetSubchat(id)
 	if err != nil {
 		h.logger.Error("Failed to get subchat", zap.String("id", id), zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "Subchat not found"})
+		errcode.RespondCode(c, errcode.NotFound, "Subchat not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, h.toSubchatResponse(subchat))
+	envelope.OK(c, h.toSubchatResponse(subchat))
 }
 
 // GetSubchatsByParent retrieves all subchats for a parent chat
@@ -148,7 +152,7 @@ func (h *SubchatHandler) GetSubchatsByParent(c *gin.Context) {
 	subchats, err := h.subchatStorage.GetSubchatsByParent(parentChatID)
 	if err != nil {
 		h.logger.Error("Failed to get subchats by parent", zap.String("parentChatId", parentChatID), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve subchats"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to retrieve subchats")
 		return
 	}
 
@@ -158,10 +162,10 @@ func (h *SubchatHandler) GetSubchatsByParent(c *gin.Context) {
 		responses[i] = h.toSubchatResponse(subchat)
 	}
 
-	c.JSON(http.StatusOK, ListSubchatsResponse{
+	envelope.List(c, ListSubchatsResponse{
 		Subchats: responses,
 		Count:    len(responses),
-	})
+	}, envelope.Complete(len(responses)))
 }
 
 // UpdateSubchatStatus updates the status of a subchat
@@ -172,8 +176,8 @@ func (h *SubchatHandler) UpdateSubchatStatus(c *gin.Context) {
 	var req struct {
 		Status string `json:"status" binding:"required"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+	if err := fieldnames.ShouldBindJSON(c, &req); err != nil {
+		validation.RespondBinding(c, err, &req)
 		return
 	}
 
@@ -182,18 +186,18 @@ func (h *SubchatHandler) UpdateSubchatStatus(c *gin.Context) {
 	if status != storage.SubchatStatusActive &&
 		status != storage.SubchatStatusCompleted &&
 		status != storage.SubchatStatusFailed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Must be: active, completed, or failed"})
+		errcode.RespondCode(c, errcode.Validation, "Invalid status. Must be: active, completed, or failed")
 		return
 	}
 
 	err := h.subchatStorage.UpdateSubchatStatus(id, status)
 	if err != nil {
 		h.logger.Error("Failed to update subchat status", zap.String("id", id), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update subchat status"})
+		errcode.RespondCode(c, errcode.Internal, "Failed to update subchat status")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	envelope.OK(c, gin.H{
 		"success": true,
 		"message": "Subchat status updated successfully",
 	})
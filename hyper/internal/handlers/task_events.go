@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/taskevents"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// TaskEventsPath is where clients open the task event stream
+const TaskEventsPath = "/api/ws"
+
+// Keep-alive timing of the task event stream
+const (
+	taskEventsPingInterval = 30 * time.Second
+	taskEventsPongWait     = 60 * time.Second
+	taskEventsWriteWait    = 10 * time.Second
+)
+
+// taskEventsLagging closes streams whose client fell behind; it should reload
+// the board and reconnect
+const taskEventsLagging = "subscriber fell behind; reload and reconnect"
+
+// TaskEventsHandler streams task changes to WebSocket clients
+type TaskEventsHandler struct {
+	hub            *taskevents.Hub
+	allowedOrigins []string
+	upgrader       websocket.Upgrader
+	logger         *zap.Logger
+}
+
+// NewTaskEventsHandler creates a task event stream handler. Browsers may only
+// open the stream from the server's own origin or from allowedOrigins, the
+// CORS allow-list, so other pages cannot read it with the user's cookies.
+func NewTaskEventsHandler(hub *taskevents.Hub, allowedOrigins []string, logger *zap.Logger) *TaskEventsHandler {
+	h := &TaskEventsHandler{hub: hub, allowedOrigins: allowedOrigins, logger: logger}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     h.checkOrigin,
+	}
+	return h
+}
+
+// checkOrigin accepts requests without an Origin header (non-browser
+// clients), from the server's own host, or from an allowed origin
+func (h *TaskEventsHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return slices.Contains(h.allowedOrigins, origin)
+}
+
+// RegisterRoutes registers the task event stream route
+func (h *TaskEventsHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET(TaskEventsPath, h.Stream)
+}
+
+// Stream upgrades to a WebSocket and sends each matching task event as a JSON
+// text message. Messages from the client are ignored.
+// GET /api/ws?types=a,b&taskId=&humanTaskId=&agentName=
+func (h *TaskEventsHandler) Stream(c *gin.Context) {
+	types, err := taskevents.ParseTypes(c.Query("types"))
+	if err != nil {
+		errcode.RespondCode(c, errcode.Validation, err.Error())
+		return
+	}
+	filter := taskevents.Filter{
+		Types:       types,
+		TaskID:      c.Query("taskId"),
+		HumanTaskID: c.Query("humanTaskId"),
+		AgentName:   c.Query("agentName"),
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.logger.Warn("Failed to upgrade task event stream", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(filter)
+	defer h.hub.Unsubscribe(sub)
+	h.logger.Debug("Task event stream opened", zap.Int("subscribers", h.hub.Subscribers()))
+
+	// Read until the client goes away, answering pings and keeping the
+	// deadline alive with pongs
+	closed := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(taskEventsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(taskEventsPongWait))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(taskEventsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(taskEventsWriteWait)); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, taskEventsLagging)
+				conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(taskEventsWriteWait))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(taskEventsWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
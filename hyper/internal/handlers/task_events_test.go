@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/taskevents"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTaskEventsServer(t *testing.T) (*taskevents.Hub, *httptest.Server) {
+	gin.SetMode(gin.TestMode)
+	hub := taskevents.NewHub(zap.NewNop())
+	r := gin.New()
+	NewTaskEventsHandler(hub, []string{"http://localhost:5173"}, zap.NewNop()).RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return hub, server
+}
+
+func TestTaskEventsStream(t *testing.T) {
+	hub, server := newTaskEventsServer(t)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + TaskEventsPath + "?agentName=backend"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool { return hub.Subscribers() == 1 }, time.Second, 10*time.Millisecond)
+	hub.PublishTaskEvent(storage.TaskEvent{Type: storage.TaskEventAgentTaskCreated, TaskID: "a-0", AgentName: "frontend"})
+	hub.PublishTaskEvent(storage.TaskEvent{Type: storage.TaskEventAgentTaskAssigned, TaskID: "a-1", AgentName: "backend", PreviousAgentName: "frontend"})
+
+	var event storage.TaskEvent
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	require.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, storage.TaskEventAgentTaskAssigned, event.Type, "other agents' events are filtered out")
+	assert.Equal(t, "a-1", event.TaskID)
+
+	conn.Close()
+	assert.Eventually(t, func() bool { return hub.Subscribers() == 0 }, time.Second, 10*time.Millisecond, "closed streams unsubscribe")
+}
+
+func TestTaskEventsStream_InvalidType(t *testing.T) {
+	_, server := newTaskEventsServer(t)
+
+	resp, err := http.Get(server.URL + TaskEventsPath + "?types=task_deleted")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTaskEventsStream_CheckOrigin(t *testing.T) {
+	_, server := newTaskEventsServer(t)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + TaskEventsPath
+
+	for _, origin := range []string{"", server.URL, "http://localhost:5173"} {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, _, err := websocket.DefaultDialer.Dial(url, header)
+		require.NoError(t, err, origin)
+		conn.Close()
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.example"}})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,153 @@
+// Package i18n provides the message catalog for human-readable tool result text.
+//
+// Only prose shown to people is localized; structured output (JSON payloads,
+// field names, error codes) stays locale-independent. Messages are fmt format
+// strings and every translation must keep the same verbs in the same order.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is used when no locale is configured or a message is missing
+const DefaultLocale = "en"
+
+// LocaleHeader lets HTTP clients pick a locale explicitly; Accept-Language is used otherwise
+const LocaleHeader = "X-Hyper-Locale"
+
+// LocaleEnv sets the server-wide default locale
+const LocaleEnv = "HYPER_LOCALE"
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[string]map[string]string{
+		"en": messagesEN,
+		"es": messagesES,
+		"de": messagesDE,
+	}
+)
+
+type contextKey struct{}
+
+// Register adds or extends the catalog for a locale. Keys missing from a
+// catalog fall back to English.
+func Register(locale string, messages map[string]string) {
+	locale = normalize(locale)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	catalog, ok := catalogs[locale]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		catalogs[locale] = catalog
+	}
+	for key, msg := range messages {
+		catalog[key] = msg
+	}
+}
+
+// Supported returns the registered locales, sorted
+func Supported() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// normalize lowercases a language tag and drops its region ("pt_BR" -> "pt")
+func normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_."); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+// Match returns the registered locale for a language tag such as "es-MX"
+func Match(tag string) (string, bool) {
+	locale := normalize(tag)
+	mu.RLock()
+	_, ok := catalogs[locale]
+	mu.RUnlock()
+	return locale, ok
+}
+
+// DefaultFromEnv returns the locale set by HYPER_LOCALE, or DefaultLocale
+func DefaultFromEnv() string {
+	if locale, ok := Match(os.Getenv(LocaleEnv)); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// FromHeader picks a locale from X-Hyper-Locale or Accept-Language.
+// ok is false when neither names a registered locale.
+func FromHeader(h http.Header) (string, bool) {
+	if h == nil {
+		return "", false
+	}
+	if locale, ok := Match(h.Get(LocaleHeader)); ok {
+		return locale, true
+	}
+
+	// Accept-Language entries are tried in order; quality weights are not re-sorted
+	// since clients list preferred languages first
+	for _, part := range strings.Split(h.Get("Accept-Language"), ",") {
+		tag := strings.SplitN(part, ";", 2)[0]
+		if locale, ok := Match(tag); ok {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// WithLocale returns a context carrying locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale carried by ctx, or the server default
+func FromContext(ctx context.Context) string {
+	if ctx != nil {
+		if locale, ok := ctx.Value(contextKey{}).(string); ok && locale != "" {
+			return locale
+		}
+	}
+	return DefaultFromEnv()
+}
+
+// Translate formats the message for key in locale, falling back to English
+// and finally to the key itself
+func Translate(locale, key string, args ...interface{}) string {
+	mu.RLock()
+	msg, ok := catalogs[locale][key]
+	if !ok {
+		msg, ok = catalogs[DefaultLocale][key]
+	}
+	mu.RUnlock()
+
+	if !ok {
+		msg = key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// T formats the message for key in the locale carried by ctx
+func T(ctx context.Context, key string, args ...interface{}) string {
+	return Translate(FromContext(ctx), key, args...)
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCatalogsMatchEnglishVerbs(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for key, msg := range catalog {
+			en, ok := messagesEN[key]
+			if !ok {
+				t.Errorf("%s: key %q missing from English catalog", locale, key)
+				continue
+			}
+			if got, want := strings.Count(msg, "%"), strings.Count(en, "%"); got != want {
+				t.Errorf("%s: key %q has %d format verbs, English has %d", locale, key, got, want)
+			}
+		}
+	}
+}
+
+func TestTranslateFallsBack(t *testing.T) {
+	Register("xx", map[string]string{"common.notes": "\nXX: %s"})
+
+	if got := Translate("xx", "common.notes", "n"); got != "\nXX: n" {
+		t.Errorf("registered message = %q", got)
+	}
+	if got := Translate("xx", "notes.task.added", "t1"); got != "✓ Added prompt notes to task t1" {
+		t.Errorf("missing key should fall back to English, got %q", got)
+	}
+	if got := Translate("en", "no.such.key"); got != "no.such.key" {
+		t.Errorf("unknown key should return the key, got %q", got)
+	}
+}
+
+func TestFromHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("Accept-Language", "fr-FR;q=0.9, es-MX;q=0.8, en;q=0.5")
+	if locale, ok := FromHeader(h); !ok || locale != "es" {
+		t.Errorf("Accept-Language: got (%q, %v), want (es, true)", locale, ok)
+	}
+
+	h.Set(LocaleHeader, "de")
+	if locale, ok := FromHeader(h); !ok || locale != "de" {
+		t.Errorf("explicit header: got (%q, %v), want (de, true)", locale, ok)
+	}
+
+	if _, ok := FromHeader(http.Header{}); ok {
+		t.Error("empty headers should not resolve a locale")
+	}
+}
+
+func TestT(t *testing.T) {
+	t.Setenv(LocaleEnv, "")
+
+	ctx := WithLocale(context.Background(), "es")
+	if got := T(ctx, "notes.task.cleared", "t1"); got != "✓ Notas de prompt eliminadas de la tarea t1" {
+		t.Errorf("T(es) = %q", got)
+	}
+	if got := T(context.Background(), "notes.task.cleared", "t1"); got != "✓ Cleared prompt notes from task t1" {
+		t.Errorf("T(default) = %q", got)
+	}
+}
@@ -0,0 +1,47 @@
+package i18n
+
+// messagesDE is the German catalog
+var messagesDE = map[string]string{
+	"knowledge.stored":              "✓ Wissen erfolgreich gespeichert\n\nID: %s\nSammlung: %s\nErstellt: %s",
+	"knowledge.stored.chunked":      "✓ Wissen als %d verknüpfte Teile gespeichert\n\nTeilgruppe: %s\nSammlung: %s",
+	"knowledge.environment.saved":   "✓ Wissensumgebung '%s' gespeichert\n\nVariablen: %d\nPlatzhalter der Form {{NAME}} in Wissenseinträgen werden bei Abfragen mit environment='%s' ersetzt",
+	"knowledge.environment.deleted": "✓ Wissensumgebung '%s' gelöscht",
+
+	"task.human.created":          "✓ Benutzeraufgabe erfolgreich erstellt\n\nAufgaben-ID: %s\nErstellt: %s\nStatus: %s\n\nPrompt: %s",
+	"task.human.cloned":           "✓ Benutzeraufgabe %s erfolgreich geklont\n\nNeue Aufgaben-ID: %s\nAgentenaufgaben: %d\nTODOs: %d\nAlle Status wurden auf ausstehend zurückgesetzt.",
+	"task.human.project":          "\nProjekt: %s",
+	"task.agent.created":          "✓ Agentenaufgabe erfolgreich erstellt\n\nAufgaben-ID: %s\nAgent: %s\nRolle: %s\nÜbergeordnete Aufgabe: %s\nErstellt: %s\nStatus: %s\n",
+	"task.agent.contextSummary":   "\nKontextzusammenfassung: %s\n",
+	"task.agent.filesToModify":    "\nZu ändernde Dateien: %v\n",
+	"task.agent.suggestedColls":   "\nEmpfohlene Qdrant-Sammlungen: %v\n",
+	"task.agent.todosHeader":      "\nTODOs:\n",
+	"task.agent.todoFile":         " (Datei: %s)",
+	"task.agent.todoFunction":     " (Funktion: %s)",
+	"task.agent.todoHint":         "     Hinweis: %s\n",
+	"task.agent.retrieved":        "✓ Agentenaufgabe abgerufen\n\nAufgabe:\n%s",
+	"task.status.updated":         "✓ Aufgabenstatus erfolgreich aktualisiert\n\nAufgaben-ID: %s\nNeuer Status: %s",
+	"todo.status.updated":         "✓ TODO-Status erfolgreich aktualisiert\n\nAgentenaufgaben-ID: %s\nTODO-ID: %s\nNeuer Status: %s",
+	"todo.checklist.updated":      "✓ Status des Checklistenpunkts erfolgreich aktualisiert\n\nAgentenaufgaben-ID: %s\nTODO-ID: %s\nChecklistenpunkt-ID: %s\nNeuer Status: %s",
+	"todo.time.estimated":         "\nGeschätzt: %d Min.",
+	"todo.time.actual":            "\nTatsächlich: %d Min.",
+	"common.notes":                "\nNotizen: %s",
+	"tasks.human.listed":          "✓ %d Benutzeraufgaben abgerufen\n\nAufgaben:\n%s",
+	"tasks.agent.listed":          "✓ %d Agentenaufgaben abgerufen (Anzeige %d-%d von insgesamt %d)",
+	"tasks.agent.filteredByHuman": "\nGefiltert nach humanTaskId: %s",
+	"tasks.agent.filteredByAgent": "\nGefiltert nach agentName: %s",
+	"tasks.agent.truncationNote":  "\n\nℹ️  Hinweis: Felder über 500 Bytes werden gekürzt. Verwende coordinator_get_agent_task(taskId) für alle Details.",
+	"tasks.agent.body":            "\n\nAufgaben:\n%s",
+	"board.cleared":               "✓ Aufgabenboard erfolgreich geleert\n\n%s",
+	"operation.staged":            "⏳ Vorgemerkt: %s\n\nUndo-Token: %s\nWird automatisch ausgeführt um: %s\n\nRufe coordinator_undo mit diesem Token zum Abbrechen auf, oder coordinator_confirm_operation zum sofortigen Ausführen.",
+	"operation.undone":            "✓ Rückgängig gemacht: %s",
+	"operation.committed":         "✓ Vorgemerkte Operation ausgeführt\n\n%s",
+
+	"notes.task.added":   "✓ Prompt-Notizen zu Aufgabe %s hinzugefügt",
+	"notes.task.updated": "✓ Prompt-Notizen für Aufgabe %s aktualisiert",
+	"notes.task.cleared": "✓ Prompt-Notizen von Aufgabe %s entfernt",
+	"notes.todo.added":   "✓ Prompt-Notizen zu TODO %s in Aufgabe %s hinzugefügt",
+	"notes.todo.updated": "✓ Prompt-Notizen für TODO %s in Aufgabe %s aktualisiert",
+	"notes.todo.cleared": "✓ Prompt-Notizen von TODO %s in Aufgabe %s entfernt",
+
+	"subagent.validated": "✓ Subagent '%s' erfolgreich validiert\n\nHinweis: Die Zuordnung zu Chat-Sitzungen wird über den Subchat-Dienst umgesetzt. Verwende die REST-API von subchat_handler, um Subchats mit zugewiesenen Subagenten zu erstellen.",
+}
@@ -0,0 +1,47 @@
+package i18n
+
+// messagesEN is the reference catalog; every key must exist here
+var messagesEN = map[string]string{
+	"knowledge.stored":              "✓ Knowledge stored successfully\n\nID: %s\nCollection: %s\nCreated: %s",
+	"knowledge.stored.chunked":      "✓ Knowledge stored as %d linked chunks\n\nChunk group: %s\nCollection: %s",
+	"knowledge.environment.saved":   "✓ Knowledge environment '%s' saved\n\nVariables: %d\nPlaceholders written as {{NAME}} in knowledge entries are replaced when queried with environment='%s'",
+	"knowledge.environment.deleted": "✓ Knowledge environment '%s' deleted",
+
+	"task.human.created":          "✓ Human task created successfully\n\nTask ID: %s\nCreated: %s\nStatus: %s\n\nPrompt: %s",
+	"task.human.cloned":           "✓ Human task %s cloned successfully\n\nNew Task ID: %s\nAgent Tasks: %d\nTODOs: %d\nAll statuses reset to pending.",
+	"task.human.project":          "\nProject: %s",
+	"task.agent.created":          "✓ Agent task created successfully\n\nTask ID: %s\nAgent: %s\nRole: %s\nParent Task: %s\nCreated: %s\nStatus: %s\n",
+	"task.agent.contextSummary":   "\nContext Summary: %s\n",
+	"task.agent.filesToModify":    "\nFiles to Modify: %v\n",
+	"task.agent.suggestedColls":   "\nSuggested Qdrant Collections: %v\n",
+	"task.agent.todosHeader":      "\nTODOs:\n",
+	"task.agent.todoFile":         " (File: %s)",
+	"task.agent.todoFunction":     " (Function: %s)",
+	"task.agent.todoHint":         "     Hint: %s\n",
+	"task.agent.retrieved":        "✓ Retrieved agent task\n\nTask:\n%s",
+	"task.status.updated":         "✓ Task status updated successfully\n\nTask ID: %s\nNew Status: %s",
+	"todo.status.updated":         "✓ TODO status updated successfully\n\nAgent Task ID: %s\nTODO ID: %s\nNew Status: %s",
+	"todo.checklist.updated":      "✓ Checklist item status updated successfully\n\nAgent Task ID: %s\nTODO ID: %s\nChecklist Item ID: %s\nNew Status: %s",
+	"todo.time.estimated":         "\nEstimated: %d min",
+	"todo.time.actual":            "\nActual: %d min",
+	"common.notes":                "\nNotes: %s",
+	"tasks.human.listed":          "✓ Retrieved %d human tasks\n\nTasks:\n%s",
+	"tasks.agent.listed":          "✓ Retrieved %d agent tasks (showing %d-%d of %d total)",
+	"tasks.agent.filteredByHuman": "\nFiltered by humanTaskId: %s",
+	"tasks.agent.filteredByAgent": "\nFiltered by agentName: %s",
+	"tasks.agent.truncationNote":  "\n\nℹ️  Note: Fields >500 bytes are truncated. Use coordinator_get_agent_task(taskId) for full details.",
+	"tasks.agent.body":            "\n\nTasks:\n%s",
+	"board.cleared":               "✓ Task board cleared successfully\n\n%s",
+	"operation.staged":            "⏳ Staged: %s\n\nUndo token: %s\nCommits automatically at: %s\n\nCall coordinator_undo with this token to cancel, or coordinator_confirm_operation to commit now.",
+	"operation.undone":            "✓ Undone: %s",
+	"operation.committed":         "✓ Staged operation committed\n\n%s",
+
+	"notes.task.added":   "✓ Added prompt notes to task %s",
+	"notes.task.updated": "✓ Updated prompt notes for task %s",
+	"notes.task.cleared": "✓ Cleared prompt notes from task %s",
+	"notes.todo.added":   "✓ Added prompt notes to TODO %s in task %s",
+	"notes.todo.updated": "✓ Updated prompt notes for TODO %s in task %s",
+	"notes.todo.cleared": "✓ Cleared prompt notes from TODO %s in task %s",
+
+	"subagent.validated": "✓ Subagent '%s' validated successfully\n\nNote: Chat session association will be implemented via subchat service. Use subchat_handler REST API to create subchats with subagent assignments.",
+}
@@ -0,0 +1,47 @@
+package i18n
+
+// messagesES is the Spanish catalog
+var messagesES = map[string]string{
+	"knowledge.stored":              "✓ Conocimiento guardado correctamente\n\nID: %s\nColección: %s\nCreado: %s",
+	"knowledge.stored.chunked":      "✓ Conocimiento guardado en %d fragmentos enlazados\n\nGrupo de fragmentos: %s\nColección: %s",
+	"knowledge.environment.saved":   "✓ Entorno de conocimiento '%s' guardado\n\nVariables: %d\nLos marcadores {{NAME}} en las entradas de conocimiento se reemplazan al consultar con environment='%s'",
+	"knowledge.environment.deleted": "✓ Entorno de conocimiento '%s' eliminado",
+
+	"task.human.created":          "✓ Tarea humana creada correctamente\n\nID de tarea: %s\nCreada: %s\nEstado: %s\n\nPrompt: %s",
+	"task.human.cloned":           "✓ Tarea humana %s clonada correctamente\n\nNuevo ID de tarea: %s\nTareas de agente: %d\nTODOs: %d\nTodos los estados se restablecieron a pendiente.",
+	"task.human.project":          "\nProyecto: %s",
+	"task.agent.created":          "✓ Tarea de agente creada correctamente\n\nID de tarea: %s\nAgente: %s\nRol: %s\nTarea padre: %s\nCreada: %s\nEstado: %s\n",
+	"task.agent.contextSummary":   "\nResumen de contexto: %s\n",
+	"task.agent.filesToModify":    "\nArchivos a modificar: %v\n",
+	"task.agent.suggestedColls":   "\nColecciones de Qdrant sugeridas: %v\n",
+	"task.agent.todosHeader":      "\nTAREAS PENDIENTES:\n",
+	"task.agent.todoFile":         " (Archivo: %s)",
+	"task.agent.todoFunction":     " (Función: %s)",
+	"task.agent.todoHint":         "     Pista: %s\n",
+	"task.agent.retrieved":        "✓ Tarea de agente obtenida\n\nTarea:\n%s",
+	"task.status.updated":         "✓ Estado de la tarea actualizado correctamente\n\nID de tarea: %s\nNuevo estado: %s",
+	"todo.status.updated":         "✓ Estado del TODO actualizado correctamente\n\nID de tarea de agente: %s\nID de TODO: %s\nNuevo estado: %s",
+	"todo.checklist.updated":      "✓ Estado del elemento de checklist actualizado correctamente\n\nID de tarea de agente: %s\nID de TODO: %s\nID de elemento: %s\nNuevo estado: %s",
+	"todo.time.estimated":         "\nEstimado: %d min",
+	"todo.time.actual":            "\nReal: %d min",
+	"common.notes":                "\nNotas: %s",
+	"tasks.human.listed":          "✓ Se obtuvieron %d tareas humanas\n\nTareas:\n%s",
+	"tasks.agent.listed":          "✓ Se obtuvieron %d tareas de agente (mostrando %d-%d de %d en total)",
+	"tasks.agent.filteredByHuman": "\nFiltrado por humanTaskId: %s",
+	"tasks.agent.filteredByAgent": "\nFiltrado por agentName: %s",
+	"tasks.agent.truncationNote":  "\n\nℹ️  Nota: los campos de más de 500 bytes se truncan. Usa coordinator_get_agent_task(taskId) para ver todos los detalles.",
+	"tasks.agent.body":            "\n\nTareas:\n%s",
+	"board.cleared":               "✓ Tablero de tareas vaciado correctamente\n\n%s",
+	"operation.staged":            "⏳ Preparado: %s\n\nToken de deshacer: %s\nSe confirma automáticamente a las: %s\n\nLlama a coordinator_undo con este token para cancelar, o a coordinator_confirm_operation para confirmar ahora.",
+	"operation.undone":            "✓ Deshecho: %s",
+	"operation.committed":         "✓ Operación preparada confirmada\n\n%s",
+
+	"notes.task.added":   "✓ Notas de prompt añadidas a la tarea %s",
+	"notes.task.updated": "✓ Notas de prompt actualizadas en la tarea %s",
+	"notes.task.cleared": "✓ Notas de prompt eliminadas de la tarea %s",
+	"notes.todo.added":   "✓ Notas de prompt añadidas al TODO %s de la tarea %s",
+	"notes.todo.updated": "✓ Notas de prompt actualizadas en el TODO %s de la tarea %s",
+	"notes.todo.cleared": "✓ Notas de prompt eliminadas del TODO %s de la tarea %s",
+
+	"subagent.validated": "✓ Subagente '%s' validado correctamente\n\nNota: la asociación con sesiones de chat se implementará mediante el servicio de subchats. Usa la API REST de subchat_handler para crear subchats con subagentes asignados.",
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"hyper/internal/confirm"
+	"hyper/internal/errcode"
 	"hyper/internal/indexer/embeddings"
 	"hyper/internal/indexer/scanner"
 	"hyper/internal/indexer/storage"
@@ -115,7 +117,7 @@ func (h *ToolHandler) registerAddFolder(server *mcp.Server) error {
 func (h *ToolHandler) registerRemoveFolder(server *mcp.Server) error {
 	tool := &mcp.Tool{
 		Name:        "code_index_remove_folder",
-		Description: "Remove a folder from the code index. This will delete all indexed files and their vectors.",
+		Description: "Remove a folder from the code index. This will delete all indexed files and their vectors. Clients that support elicitation are asked to confirm; others must pass confirm: true.",
 		InputSchema: &jsonschema.Schema{
 			Type: "object",
 			Properties: map[string]*jsonschema.Schema{
@@ -123,18 +125,22 @@ func (h *ToolHandler) registerRemoveFolder(server *mcp.Server) error {
 					Type:        "string",
 					Description: "Absolute path to the folder to remove (must match the path used when adding)",
 				},
+				"confirm": {
+					Type:        "boolean",
+					Description: "Set to true to remove without asking (required when the client does not support elicitation)",
+				},
 			},
 			Required: []string{"folderPath"},
 		},
 	}
 
-	server.AddTool(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	server.AddTool(tool, confirm.Middleware(func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, err := h.extractArguments(req)
 		if err != nil {
 			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
 		}
 		return h.handleRemoveFolder(ctx, args)
-	})
+	}))
 
 	return nil
 }
@@ -308,6 +314,13 @@ func (h *ToolHandler) handleRemoveFolder(ctx context.Context, args map[string]in
 		return createErrorResult(fmt.Sprintf("folder not found: %s", absPath)), nil
 	}
 
+	switch confirm.Ask(ctx, args, fmt.Sprintf("Remove %s from the code index? All of its indexed files and vectors are deleted.", absPath)) {
+	case confirm.Declined:
+		return createErrorResult("Removal cancelled: confirmation was declined"), nil
+	case confirm.Required:
+		return createErrorResult("Confirmation required: set confirm=true to remove the folder"), nil
+	}
+
 	// Get all files to delete their vectors
 	files, err := h.mongoStorage.ListFiles(folder.ID)
 	if err != nil {
@@ -631,12 +644,19 @@ func (h *ToolHandler) extractArguments(req *mcp.CallToolRequest) (map[string]int
 	return result, nil
 }
 
-// createErrorResult creates an error result with the given message
+// createErrorResult creates an error result with the given message and an
+// error code classified from it in the structured content
 func createErrorResult(message string) *mcp.CallToolResult {
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: fmt.Sprintf("❌ Error: %s", message)},
 		},
+		StructuredContent: map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    errcode.Classify(message),
+				"message": message,
+			},
+		},
 		IsError: true,
 	}
 }
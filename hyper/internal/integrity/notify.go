@@ -0,0 +1,43 @@
+package integrity
+
+import (
+	"fmt"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/notify"
+
+	"go.uber.org/zap"
+)
+
+// checkEmail is the data of the integrity_check.html template
+type checkEmail struct {
+	*storage.IntegrityCheck
+	Title string
+}
+
+// notify emails a run with discrepancies to NOTIFY_EMAIL_TO
+func (v *Verifier) notify(check *storage.IntegrityCheck) {
+	if v.email == nil {
+		return
+	}
+
+	title := fmt.Sprintf("Index integrity: %d of %d sampled chunks failed", len(check.Discrepancies), check.Sampled)
+	text := fmt.Sprintf("The index integrity check at %s found discrepancies. Re-scan the affected folders to rewrite their vectors.\n\n",
+		check.StartedAt.Format("2006-01-02 15:04 UTC"))
+	for _, d := range check.Discrepancies {
+		text += fmt.Sprintf("- %s: %s chunk %d in %s", d.Kind, d.FilePath, d.ChunkNum, d.Collection)
+		if d.Vector != "" {
+			text += fmt.Sprintf(" (%s vector)", d.Vector)
+		}
+		text += "\n"
+	}
+
+	html, err := notify.RenderHTML("integrity_check.html", &checkEmail{IntegrityCheck: check, Title: title})
+	if err != nil {
+		// Plain text still carries everything
+		v.logger.Warn("Failed to render integrity check email", zap.Error(err))
+	}
+	if err := v.email.SendEmail(&notify.Email{To: v.recipients, Subject: title, Text: text, HTML: html}); err != nil {
+		v.logger.Warn("Failed to email integrity check", zap.Error(err))
+	}
+}
@@ -0,0 +1,376 @@
+// Package integrity periodically verifies the code index: it samples chunks
+// from MongoDB, re-embeds them and checks that their Qdrant points exist and
+// still match, catching silent corruption and partial upserts.
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/mcp/summarizer"
+	"hyper/internal/notify"
+	"hyper/internal/priority"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// checkInterval is how often the verifier looks whether a run is due
+const checkInterval = time.Minute
+
+// Config controls the integrity verification job
+type Config struct {
+	Interval      time.Duration // Time between runs; 0 disables the job
+	SampleSize    int           // Chunks verified per run
+	MinSimilarity float64       // Cosine similarity a stored vector needs to a fresh embedding
+}
+
+// LoadConfig reads INDEX_INTEGRITY_INTERVAL (default 24h, 0 disables),
+// INDEX_INTEGRITY_SAMPLE (default 50) and INDEX_INTEGRITY_MIN_SIMILARITY
+// (default 0.98)
+func LoadConfig() (Config, error) {
+	cfg := Config{Interval: 24 * time.Hour, SampleSize: 50, MinSimilarity: 0.98}
+
+	if raw := os.Getenv("INDEX_INTEGRITY_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval < 0 {
+			return cfg, fmt.Errorf("invalid INDEX_INTEGRITY_INTERVAL %q: must be a duration such as 24h", raw)
+		}
+		cfg.Interval = interval
+	}
+	if raw := os.Getenv("INDEX_INTEGRITY_SAMPLE"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 {
+			return cfg, fmt.Errorf("invalid INDEX_INTEGRITY_SAMPLE %q: must be a positive number of chunks", raw)
+		}
+		cfg.SampleSize = size
+	}
+	if raw := os.Getenv("INDEX_INTEGRITY_MIN_SIMILARITY"); raw != "" {
+		similarity, err := strconv.ParseFloat(raw, 64)
+		if err != nil || similarity <= 0 || similarity > 1 {
+			return cfg, fmt.Errorf("invalid INDEX_INTEGRITY_MIN_SIMILARITY %q: must be between 0 and 1", raw)
+		}
+		cfg.MinSimilarity = similarity
+	}
+	return cfg, nil
+}
+
+// chunkStore is the code index storage the verifier reads and records runs
+// in (implemented by storage.CodeIndexStorage)
+type chunkStore interface {
+	SampleChunks(n int) ([]*storage.FileChunk, error)
+	GetFile(fileID string) (*storage.IndexedFile, error)
+	ListPathMappings() ([]*storage.CodeIndexMapping, error)
+	RecordIntegrityCheck(check *storage.IntegrityCheck) error
+	ListIntegrityChecks(limit int) ([]*storage.IntegrityCheck, error)
+}
+
+// vectorStore reads stored code index vectors (implemented by storage.QdrantClient)
+type vectorStore interface {
+	UsesNamedVectors(ctx context.Context, collectionName string) (bool, error)
+	CodeIndexPointVectors(ctx context.Context, collectionName string, ids []string) (map[string]storage.StoredVectors, error)
+}
+
+// Verifier runs integrity checks of the code index
+type Verifier struct {
+	cfg        Config
+	chunks     chunkStore
+	vectors    vectorStore
+	embedder   embeddings.EmbeddingClient
+	email      notify.EmailSender
+	recipients []string
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewVerifier creates an integrity verifier. Runs with discrepancies are
+// emailed to NOTIFY_EMAIL_TO when SMTP is configured.
+func NewVerifier(cfg Config, chunks *storage.CodeIndexStorage, vectors *storage.QdrantClient, embedder embeddings.EmbeddingClient, mailer *notify.Mailer, logger *zap.Logger) *Verifier {
+	v := &Verifier{
+		cfg:      cfg,
+		chunks:   chunks,
+		vectors:  vectors,
+		embedder: embedder,
+		logger:   logger,
+		now:      time.Now,
+	}
+	if list := os.Getenv(notify.RecipientsEnv); list != "" && mailer.Configured() {
+		recipients, err := storage.ParseEmailRecipients(list)
+		if err != nil {
+			logger.Warn("Integrity check emails disabled", zap.String("env", notify.RecipientsEnv), zap.Error(err))
+		} else {
+			v.email = mailer
+			v.recipients = recipients
+		}
+	}
+	return v
+}
+
+// Start runs a check whenever the last one is older than the interval,
+// until ctx is cancelled. Runs are recorded in MongoDB, so restarts do not
+// reset the schedule.
+func (v *Verifier) Start(ctx context.Context) {
+	if v.cfg.Interval <= 0 {
+		v.logger.Info("Index integrity checks disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		v.logger.Info("Index integrity checks started",
+			zap.Duration("interval", v.cfg.Interval),
+			zap.Int("sampleSize", v.cfg.SampleSize))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if v.due() {
+					v.Run(ctx)
+				}
+			}
+		}
+	}()
+}
+
+// due reports whether the last recorded run is older than the interval
+func (v *Verifier) due() bool {
+	checks, err := v.chunks.ListIntegrityChecks(1)
+	if err != nil {
+		v.logger.Warn("Failed to load last integrity check", zap.Error(err))
+		return false
+	}
+	return len(checks) == 0 || v.now().Sub(checks[0].StartedAt) >= v.cfg.Interval
+}
+
+// sampledChunk is a sampled chunk with the collection its point lives in
+type sampledChunk struct {
+	chunk      *storage.FileChunk
+	filePath   string
+	collection string
+}
+
+// Run verifies a sample of chunks, records the run and notifies about
+// discrepancies. Embedding runs at bulk priority, behind interactive work.
+func (v *Verifier) Run(ctx context.Context) *storage.IntegrityCheck {
+	ctx = priority.WithLevel(ctx, priority.Bulk)
+	check := &storage.IntegrityCheck{
+		ID:            uuid.New().String(),
+		StartedAt:     v.now().UTC(),
+		MinSimilarity: v.cfg.MinSimilarity,
+	}
+
+	if err := v.verify(ctx, check); err != nil {
+		check.Error = err.Error()
+	}
+	check.FinishedAt = v.now().UTC()
+
+	if err := v.chunks.RecordIntegrityCheck(check); err != nil {
+		v.logger.Warn("Failed to record integrity check", zap.Error(err))
+	}
+
+	fields := []zap.Field{
+		zap.Int("sampled", check.Sampled),
+		zap.Int("verified", check.Verified),
+		zap.Int("skipped", check.Skipped),
+		zap.Int("discrepancies", len(check.Discrepancies)),
+	}
+	switch {
+	case check.Error != "":
+		v.logger.Warn("Index integrity check failed", append(fields, zap.String("error", check.Error))...)
+	case len(check.Discrepancies) > 0:
+		v.logger.Warn("Index integrity check found discrepancies", fields...)
+		v.notify(check)
+	default:
+		v.logger.Info("Index integrity check passed", fields...)
+	}
+	return check
+}
+
+// verify samples chunks and checks them collection by collection
+func (v *Verifier) verify(ctx context.Context, check *storage.IntegrityCheck) error {
+	chunks, err := v.chunks.SampleChunks(v.cfg.SampleSize)
+	if err != nil {
+		return err
+	}
+	mappings, err := v.chunks.ListPathMappings()
+	if err != nil {
+		return err
+	}
+
+	byCollection := make(map[string][]sampledChunk)
+	var order []string
+	files := make(map[string]*storage.IndexedFile)
+	for _, chunk := range chunks {
+		if chunk.VectorID == "" {
+			continue
+		}
+		check.Sampled++
+
+		file, ok := files[chunk.FileID]
+		if !ok {
+			file, _ = v.chunks.GetFile(chunk.FileID)
+			files[chunk.FileID] = file
+		}
+		if file == nil {
+			// Removed while sampling
+			check.Skipped++
+			continue
+		}
+
+		collection := collectionFor(mappings, file.Path)
+		if _, ok := byCollection[collection]; !ok {
+			order = append(order, collection)
+		}
+		byCollection[collection] = append(byCollection[collection], sampledChunk{chunk: chunk, filePath: file.Path, collection: collection})
+	}
+
+	for _, collection := range order {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		v.verifyCollection(ctx, collection, byCollection[collection], check)
+	}
+	return nil
+}
+
+// collectionFor returns the collection of the most specific mapped folder
+// covering path, or the shared code index
+func collectionFor(mappings []*storage.CodeIndexMapping, path string) string {
+	var best *storage.CodeIndexMapping
+	for _, mapping := range mappings {
+		if storage.FolderCovers(mapping.Path, path) && (best == nil || len(mapping.Path) > len(best.Path)) {
+			best = mapping
+		}
+	}
+	if best == nil {
+		return storage.CodeIndexCollection
+	}
+	return best.QdrantCollection
+}
+
+// verifyCollection checks the points of sampled chunks of one collection.
+// Points missing from a folder's collection are looked up in the shared code
+// index as well, where the file watcher writes re-indexed files.
+func (v *Verifier) verifyCollection(ctx context.Context, collection string, sampled []sampledChunk, check *storage.IntegrityCheck) {
+	ids := make([]string, len(sampled))
+	for i, s := range sampled {
+		ids[i] = s.chunk.VectorID
+	}
+	stored, named, err := v.storedVectors(ctx, collection, ids)
+	if err != nil {
+		v.logger.Warn("Failed to read stored vectors", zap.String("collection", collection), zap.Error(err))
+		check.Skipped += len(sampled)
+		return
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if _, ok := stored[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	shared, sharedNamed := map[string]storage.StoredVectors{}, false
+	if len(missing) > 0 && collection != storage.CodeIndexCollection {
+		if found, foundNamed, err := v.storedVectors(ctx, storage.CodeIndexCollection, missing); err == nil {
+			shared, sharedNamed = found, foundNamed
+		}
+	}
+
+	for _, s := range sampled {
+		if vectors, ok := stored[s.chunk.VectorID]; ok {
+			v.verifyChunk(ctx, s, &vectors, named, check)
+		} else if vectors, ok := shared[s.chunk.VectorID]; ok {
+			s.collection = storage.CodeIndexCollection
+			v.verifyChunk(ctx, s, &vectors, sharedNamed, check)
+		} else {
+			v.verifyChunk(ctx, s, nil, named, check)
+		}
+	}
+}
+
+// storedVectors reads the layout of a collection and the vectors of ids in it
+func (v *Verifier) storedVectors(ctx context.Context, collection string, ids []string) (map[string]storage.StoredVectors, bool, error) {
+	named, err := v.vectors.UsesNamedVectors(ctx, collection)
+	if err != nil {
+		return nil, false, err
+	}
+	stored, err := v.vectors.CodeIndexPointVectors(ctx, collection, ids)
+	if err != nil {
+		return nil, false, err
+	}
+	return stored, named, nil
+}
+
+// verifyChunk re-embeds a chunk as it was indexed and compares the result
+// with its stored vectors; nil vectors means its point was not found
+func (v *Verifier) verifyChunk(ctx context.Context, s sampledChunk, vectors *storage.StoredVectors, named bool, check *storage.IntegrityCheck) {
+	discrepancy := storage.IntegrityDiscrepancy{
+		FileID:     s.chunk.FileID,
+		FilePath:   s.filePath,
+		ChunkNum:   s.chunk.ChunkNum,
+		VectorID:   s.chunk.VectorID,
+		Collection: s.collection,
+	}
+
+	if vectors == nil {
+		discrepancy.Kind = storage.IntegrityMissingPoint
+		check.Discrepancies = append(check.Discrepancies, discrepancy)
+		return
+	}
+
+	code, summary, _, err := summarizer.EmbedChunk(ctx, v.embedder, s.chunk.Summary, s.chunk.Content, named)
+	if err != nil {
+		v.logger.Debug("Failed to re-embed chunk", zap.String("vectorId", s.chunk.VectorID), zap.Error(err))
+		check.Skipped++
+		return
+	}
+
+	type comparison struct {
+		name          string
+		stored, fresh []float32
+	}
+	comparisons := []comparison{{name: "", stored: vectors.Code, fresh: code}}
+	if named {
+		comparisons[0].name = storage.CodeVectorName
+		if len(summary) > 0 {
+			comparisons = append(comparisons, comparison{name: storage.SummaryVectorName, stored: vectors.Summary, fresh: summary})
+		}
+	}
+	for _, c := range comparisons {
+		discrepancy.Vector = c.name
+		if len(c.stored) == 0 || len(c.stored) != len(c.fresh) {
+			discrepancy.Kind = storage.IntegrityMissingVector
+			check.Discrepancies = append(check.Discrepancies, discrepancy)
+			return
+		}
+		if similarity := cosine(c.stored, c.fresh); similarity < v.cfg.MinSimilarity {
+			discrepancy.Kind = storage.IntegrityLowSimilarity
+			discrepancy.Similarity = similarity
+			check.Discrepancies = append(check.Discrepancies, discrepancy)
+			return
+		}
+	}
+	check.Verified++
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
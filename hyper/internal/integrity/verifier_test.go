@@ -0,0 +1,210 @@
+package integrity
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+	"hyper/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeChunkStore struct {
+	chunks   []*storage.FileChunk
+	files    map[string]*storage.IndexedFile
+	mappings []*storage.CodeIndexMapping
+	checks   []*storage.IntegrityCheck
+}
+
+func (f *fakeChunkStore) SampleChunks(n int) ([]*storage.FileChunk, error) {
+	return f.chunks, nil
+}
+
+func (f *fakeChunkStore) GetFile(fileID string) (*storage.IndexedFile, error) {
+	return f.files[fileID], nil
+}
+
+func (f *fakeChunkStore) ListPathMappings() ([]*storage.CodeIndexMapping, error) {
+	return f.mappings, nil
+}
+
+func (f *fakeChunkStore) RecordIntegrityCheck(check *storage.IntegrityCheck) error {
+	f.checks = append([]*storage.IntegrityCheck{check}, f.checks...)
+	return nil
+}
+
+func (f *fakeChunkStore) ListIntegrityChecks(limit int) ([]*storage.IntegrityCheck, error) {
+	if len(f.checks) > limit {
+		return f.checks[:limit], nil
+	}
+	return f.checks, nil
+}
+
+// fakeVectorStore holds points per collection
+type fakeVectorStore struct {
+	named  map[string]bool
+	points map[string]map[string]storage.StoredVectors
+}
+
+func (f *fakeVectorStore) UsesNamedVectors(ctx context.Context, collectionName string) (bool, error) {
+	return f.named[collectionName], nil
+}
+
+func (f *fakeVectorStore) CodeIndexPointVectors(ctx context.Context, collectionName string, ids []string) (map[string]storage.StoredVectors, error) {
+	found := make(map[string]storage.StoredVectors)
+	for _, id := range ids {
+		if vectors, ok := f.points[collectionName][id]; ok {
+			found[id] = vectors
+		}
+	}
+	return found, nil
+}
+
+// wordEmbedder embeds text by whether it mentions "retry" and "queue"
+type wordEmbedder struct{}
+
+func (wordEmbedder) CreateEmbedding(text string) ([]float32, error) {
+	vector := []float32{0.1, 0.1}
+	if strings.Contains(text, "retry") {
+		vector[0] = 1
+	}
+	if strings.Contains(text, "queue") {
+		vector[1] = 1
+	}
+	return vector, nil
+}
+
+func (e wordEmbedder) CreateEmbeddings(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = e.CreateEmbedding(text)
+	}
+	return vectors, nil
+}
+
+func (wordEmbedder) GetDimensions() int { return 2 }
+
+type recordingSender struct {
+	sent []*notify.Email
+}
+
+func (s *recordingSender) SendEmail(msg *notify.Email) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func newTestVerifier() (*Verifier, *fakeChunkStore, *recordingSender) {
+	chunks := &fakeChunkStore{
+		chunks: []*storage.FileChunk{
+			{FileID: "f-1", ChunkNum: 0, VectorID: "ok", Content: "func retry() {}", Summary: "Puts a job on the queue."},
+			{FileID: "f-1", ChunkNum: 1, VectorID: "lost", Content: "func retry() {}"},
+			{FileID: "f-1", ChunkNum: 2, VectorID: "stale", Content: "func retry() {}"},
+			{FileID: "f-2", ChunkNum: 0, VectorID: "watched", Content: "func queue() {}"},
+			{FileID: "f-2", ChunkNum: 1, Content: "not embedded yet"},
+			{FileID: "gone", ChunkNum: 0, VectorID: "orphan", Content: "x"},
+		},
+		files: map[string]*storage.IndexedFile{
+			"f-1": {ID: "f-1", Path: "/repo/webhooks/retry.go"},
+			"f-2": {ID: "f-2", Path: "/repo/webhooks/queue.go"},
+		},
+		mappings: []*storage.CodeIndexMapping{{Path: "/repo", QdrantCollection: "code_index_repo"}},
+	}
+	vectors := &fakeVectorStore{
+		named: map[string]bool{"code_index_repo": true},
+		points: map[string]map[string]storage.StoredVectors{
+			"code_index_repo": {
+				"ok":    {Code: []float32{1, 0.1}, Summary: []float32{0.1, 1}},
+				"stale": {Code: []float32{0.1, 1}},
+			},
+			// Legacy layout: summary and code embedded together
+			storage.CodeIndexCollection: {
+				"watched": {Code: []float32{0.1, 1}},
+			},
+		},
+	}
+	sender := &recordingSender{}
+	verifier := &Verifier{
+		cfg:        Config{Interval: time.Hour, SampleSize: 10, MinSimilarity: 0.98},
+		chunks:     chunks,
+		vectors:    vectors,
+		embedder:   wordEmbedder{},
+		email:      sender,
+		recipients: []string{"ops@example.com"},
+		logger:     zap.NewNop(),
+		now:        time.Now,
+	}
+	return verifier, chunks, sender
+}
+
+func TestVerifierRun(t *testing.T) {
+	verifier, chunks, sender := newTestVerifier()
+
+	check := verifier.Run(context.Background())
+	assert.Empty(t, check.Error)
+	assert.Equal(t, 5, check.Sampled, "chunks without a vector are not sampled")
+	assert.Equal(t, 2, check.Verified, "named vectors and points found in the shared index verify")
+	assert.Equal(t, 1, check.Skipped, "chunks of removed files are skipped")
+
+	require.Len(t, check.Discrepancies, 2)
+	kinds := map[string]storage.IntegrityDiscrepancy{}
+	for _, discrepancy := range check.Discrepancies {
+		kinds[discrepancy.VectorID] = discrepancy
+	}
+	assert.Equal(t, storage.IntegrityMissingPoint, kinds["lost"].Kind)
+	assert.Equal(t, "code_index_repo", kinds["lost"].Collection)
+	assert.Equal(t, storage.IntegrityLowSimilarity, kinds["stale"].Kind)
+	assert.Equal(t, storage.CodeVectorName, kinds["stale"].Vector)
+	assert.Less(t, kinds["stale"].Similarity, 0.98)
+
+	require.Len(t, chunks.checks, 1, "runs are recorded")
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, []string{"ops@example.com"}, sender.sent[0].To)
+	assert.Contains(t, sender.sent[0].Text, "/repo/webhooks/retry.go chunk 1")
+	assert.Contains(t, sender.sent[0].HTML, "low_similarity")
+}
+
+func TestVerifierRun_Passing(t *testing.T) {
+	verifier, chunks, sender := newTestVerifier()
+	chunks.chunks = chunks.chunks[:1]
+
+	check := verifier.Run(context.Background())
+	assert.Equal(t, 1, check.Verified)
+	assert.Empty(t, check.Discrepancies)
+	assert.Empty(t, sender.sent, "passing runs are not emailed")
+}
+
+func TestVerifierDue(t *testing.T) {
+	verifier, chunks, _ := newTestVerifier()
+	assert.True(t, verifier.due(), "due without any recorded run")
+
+	chunks.checks = []*storage.IntegrityCheck{{StartedAt: time.Now().Add(-10 * time.Minute)}}
+	assert.False(t, verifier.due())
+
+	chunks.checks[0].StartedAt = time.Now().Add(-2 * time.Hour)
+	assert.True(t, verifier.due())
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("INDEX_INTEGRITY_INTERVAL", "")
+	t.Setenv("INDEX_INTEGRITY_SAMPLE", "")
+	t.Setenv("INDEX_INTEGRITY_MIN_SIMILARITY", "")
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, Config{Interval: 24 * time.Hour, SampleSize: 50, MinSimilarity: 0.98}, cfg)
+
+	t.Setenv("INDEX_INTEGRITY_INTERVAL", "6h")
+	t.Setenv("INDEX_INTEGRITY_SAMPLE", "200")
+	t.Setenv("INDEX_INTEGRITY_MIN_SIMILARITY", "0.9")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, Config{Interval: 6 * time.Hour, SampleSize: 200, MinSimilarity: 0.9}, cfg)
+
+	t.Setenv("INDEX_INTEGRITY_MIN_SIMILARITY", "1.5")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "INDEX_INTEGRITY_MIN_SIMILARITY")
+}
@@ -0,0 +1,249 @@
+// Package jira mirrors human tasks to Jira issues and keeps their statuses in
+// sync in both directions.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// requestTimeout bounds a single Jira API request
+const requestTimeout = 30 * time.Second
+
+// Config configures the Jira integration
+type Config struct {
+	BaseURL       string        // JIRA_BASE_URL, e.g. https://acme.atlassian.net
+	Email         string        // JIRA_EMAIL; with it the token is used for basic auth (Jira Cloud)
+	APIToken      string        // JIRA_API_TOKEN; without JIRA_EMAIL it is sent as a bearer token (Data Center)
+	ProjectKey    string        // JIRA_PROJECT_KEY
+	IssueType     string        // JIRA_ISSUE_TYPE (default Task)
+	BlockedStatus string        // JIRA_BLOCKED_STATUS: Jira status mirrored as blocked (default Blocked)
+	WebhookSecret string        // JIRA_WEBHOOK_SECRET: token expected on inbound webhooks
+	PollInterval  time.Duration // JIRA_POLL_INTERVAL (default 5m; 0 disables polling)
+}
+
+// LoadConfig reads the JIRA_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		BaseURL:       strings.TrimSuffix(os.Getenv("JIRA_BASE_URL"), "/"),
+		Email:         os.Getenv("JIRA_EMAIL"),
+		APIToken:      os.Getenv("JIRA_API_TOKEN"),
+		ProjectKey:    os.Getenv("JIRA_PROJECT_KEY"),
+		IssueType:     os.Getenv("JIRA_ISSUE_TYPE"),
+		BlockedStatus: os.Getenv("JIRA_BLOCKED_STATUS"),
+		WebhookSecret: os.Getenv("JIRA_WEBHOOK_SECRET"),
+		PollInterval:  5 * time.Minute,
+	}
+	if cfg.IssueType == "" {
+		cfg.IssueType = "Task"
+	}
+	if cfg.BlockedStatus == "" {
+		cfg.BlockedStatus = "Blocked"
+	}
+	if raw := os.Getenv("JIRA_POLL_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval < 0 {
+			return cfg, fmt.Errorf("invalid JIRA_POLL_INTERVAL %q: must be a duration such as 5m", raw)
+		}
+		cfg.PollInterval = interval
+	}
+	return cfg, nil
+}
+
+// Enabled reports whether the integration is configured
+func (c Config) Enabled() bool {
+	return c.BaseURL != "" && c.APIToken != "" && c.ProjectKey != ""
+}
+
+// Status is the status of a Jira issue
+type Status struct {
+	Name     string `json:"name"`
+	Category string `json:"category"` // Status category key: new, indeterminate or done
+}
+
+// Issue is the part of a Jira issue the sync needs
+type Issue struct {
+	Key    string
+	Status Status
+}
+
+// Transition is a workflow transition available on an issue
+type Transition struct {
+	ID string
+	To Status
+}
+
+// Client calls the Jira REST API (v2, supported by Jira Cloud and Data Center)
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+// NewClient creates a Jira API client
+func NewClient(cfg Config) *Client {
+	return &Client{cfg: cfg, httpClient: &http.Client{Timeout: requestTimeout}}
+}
+
+// issueFields is the JSON shape of the fields the client reads
+type issueFields struct {
+	Status struct {
+		Name           string `json:"name"`
+		StatusCategory struct {
+			Key string `json:"key"`
+		} `json:"statusCategory"`
+	} `json:"status"`
+}
+
+func (f issueFields) status() Status {
+	return Status{Name: f.Status.Name, Category: f.Status.StatusCategory.Key}
+}
+
+// CreateIssue creates an issue in the configured project and returns its key
+func (c *Client) CreateIssue(ctx context.Context, summary, description string) (string, error) {
+	body := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": c.cfg.ProjectKey},
+			"issuetype":   map[string]string{"name": c.cfg.IssueType},
+			"summary":     summary,
+			"description": description,
+		},
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/rest/api/2/issue", body, &created); err != nil {
+		return "", fmt.Errorf("failed to create Jira issue: %w", err)
+	}
+	return created.Key, nil
+}
+
+// GetIssue returns an issue's current status
+func (c *Client) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	var issue struct {
+		Key    string      `json:"key"`
+		Fields issueFields `json:"fields"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=status", nil, &issue); err != nil {
+		return nil, fmt.Errorf("failed to get Jira issue %s: %w", key, err)
+	}
+	return &Issue{Key: issue.Key, Status: issue.Fields.status()}, nil
+}
+
+// Transitions lists the transitions currently available on an issue
+func (c *Client) Transitions(ctx context.Context, key string) ([]Transition, error) {
+	var response struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				Name           string `json:"name"`
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions", nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list transitions of %s: %w", key, err)
+	}
+
+	transitions := make([]Transition, len(response.Transitions))
+	for i, t := range response.Transitions {
+		transitions[i] = Transition{ID: t.ID, To: Status{Name: t.To.Name, Category: t.To.StatusCategory.Key}}
+	}
+	return transitions, nil
+}
+
+// Transition moves an issue through a workflow transition
+func (c *Client) Transition(ctx context.Context, key, transitionID string) error {
+	body := map[string]interface{}{"transition": map[string]string{"id": transitionID}}
+	if err := c.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions", body, nil); err != nil {
+		return fmt.Errorf("failed to transition %s: %w", key, err)
+	}
+	return nil
+}
+
+// UpdatedSince returns the project's issues updated at or after since
+func (c *Client) UpdatedSince(ctx context.Context, since time.Time) ([]*Issue, error) {
+	// JQL compares in the Jira user's time zone at minute precision; a
+	// relative offset avoids both
+	minutes := int(time.Since(since).Minutes()) + 1
+	jql := fmt.Sprintf(`project = "%s" AND updated >= "-%dm" ORDER BY updated ASC`, c.cfg.ProjectKey, minutes)
+
+	var issues []*Issue
+	for startAt := 0; ; {
+		query := url.Values{
+			"jql":        {jql},
+			"fields":     {"status"},
+			"startAt":    {fmt.Sprint(startAt)},
+			"maxResults": {"100"},
+		}
+		var page struct {
+			Total  int `json:"total"`
+			Issues []struct {
+				Key    string      `json:"key"`
+				Fields issueFields `json:"fields"`
+			} `json:"issues"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/rest/api/2/search?"+query.Encode(), nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to search Jira issues: %w", err)
+		}
+
+		for _, issue := range page.Issues {
+			issues = append(issues, &Issue{Key: issue.Key, Status: issue.Fields.status()})
+		}
+		startAt += len(page.Issues)
+		if len(page.Issues) == 0 || startAt >= page.Total {
+			return issues, nil
+		}
+	}
+}
+
+// do sends a JSON request and decodes a JSON response into out (when non-nil)
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.Email != "" {
+		req.SetBasicAuth(c.cfg.Email, c.cfg.APIToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("jira returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,93 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("JIRA_BASE_URL", "https://acme.atlassian.net/")
+	t.Setenv("JIRA_API_TOKEN", "secret")
+	t.Setenv("JIRA_PROJECT_KEY", "HYP")
+	t.Setenv("JIRA_POLL_INTERVAL", "")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.Enabled())
+	assert.Equal(t, "https://acme.atlassian.net", cfg.BaseURL)
+	assert.Equal(t, "Task", cfg.IssueType)
+	assert.Equal(t, "Blocked", cfg.BlockedStatus)
+	assert.Equal(t, 5*time.Minute, cfg.PollInterval)
+
+	t.Setenv("JIRA_POLL_INTERVAL", "soon")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
+
+func TestClient(t *testing.T) {
+	var created map[string]map[string]interface{}
+	var transitioned string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/2/issue", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "bot@acme.dev", user)
+		assert.Equal(t, "secret", pass)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		w.Write([]byte(`{"key":"HYP-7"}`))
+	})
+	mux.HandleFunc("/rest/api/2/issue/HYP-7/transitions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body struct {
+				Transition struct{ ID string } `json:"transition"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			transitioned = body.Transition.ID
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{"transitions":[{"id":"31","to":{"name":"Done","statusCategory":{"key":"done"}}}]}`))
+	})
+	mux.HandleFunc("/rest/api/2/search", func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Query().Get("jql"), `project = "HYP"`)
+		if r.URL.Query().Get("startAt") == "0" {
+			w.Write([]byte(`{"total":2,"issues":[{"key":"HYP-7","fields":{"status":{"name":"Blocked","statusCategory":{"key":"indeterminate"}}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"total":2,"issues":[{"key":"HYP-8","fields":{"status":{"name":"To Do","statusCategory":{"key":"new"}}}}]}`))
+	})
+	mux.HandleFunc("/rest/api/2/issue/HYP-404", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errorMessages":["Issue does not exist"]}`, http.StatusNotFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := NewClient(Config{BaseURL: srv.URL, Email: "bot@acme.dev", APIToken: "secret", ProjectKey: "HYP", IssueType: "Story"})
+	ctx := context.Background()
+
+	key, err := client.CreateIssue(ctx, "Ship digests", "details")
+	require.NoError(t, err)
+	assert.Equal(t, "HYP-7", key)
+	assert.Equal(t, map[string]interface{}{"name": "Story"}, created["fields"]["issuetype"])
+
+	transitions, err := client.Transitions(ctx, "HYP-7")
+	require.NoError(t, err)
+	assert.Equal(t, []Transition{{ID: "31", To: Status{Name: "Done", Category: "done"}}}, transitions)
+	require.NoError(t, client.Transition(ctx, "HYP-7", "31"))
+	assert.Equal(t, "31", transitioned)
+
+	issues, err := client.UpdatedSince(ctx, time.Now().Add(-10*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, Status{Name: "Blocked", Category: "indeterminate"}, issues[0].Status)
+
+	_, err = client.GetIssue(ctx, "HYP-404")
+	assert.ErrorContains(t, err, "jira returned status 404")
+}
@@ -0,0 +1,283 @@
+package jira
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// maxSummaryChars is Jira's limit for issue summaries
+const maxSummaryChars = 255
+
+// issueAPI is the Jira API the sync uses (implemented by Client)
+type issueAPI interface {
+	CreateIssue(ctx context.Context, summary, description string) (string, error)
+	GetIssue(ctx context.Context, key string) (*Issue, error)
+	Transitions(ctx context.Context, key string) ([]Transition, error)
+	Transition(ctx context.Context, key, transitionID string) error
+	UpdatedSince(ctx context.Context, since time.Time) ([]*Issue, error)
+}
+
+// Sync keeps human tasks and their Jira issues in step: new human tasks get
+// an issue, task status changes transition the issue, and issue transitions
+// (from the webhook or polling) update the task
+type Sync struct {
+	cfg    Config
+	api    issueAPI
+	tasks  storage.TaskStorage // Unwrapped storage, so updates from Jira are not echoed back
+	links  storage.JiraLinkStorage
+	logger *zap.Logger
+
+	async    func(func()) // Runs outbound calls off the request path
+	lastPoll time.Time
+}
+
+// NewSync creates a Jira sync for tasks, which must support Jira links
+func NewSync(cfg Config, tasks storage.TaskStorage, logger *zap.Logger) (*Sync, error) {
+	links, ok := tasks.(storage.JiraLinkStorage)
+	if !ok {
+		return nil, fmt.Errorf("task storage does not support Jira links")
+	}
+	return &Sync{
+		cfg:    cfg,
+		api:    NewClient(cfg),
+		tasks:  tasks,
+		links:  links,
+		logger: logger,
+		async:  func(f func()) { go f() },
+	}, nil
+}
+
+// Mirror wraps a task storage so that task creation and status changes are
+// pushed to Jira
+func (s *Sync) Mirror(tasks storage.TaskStorage) storage.TaskStorage {
+	return &mirroredTaskStorage{TaskStorage: tasks, sync: s}
+}
+
+// mirroredTaskStorage pushes human task changes to Jira
+type mirroredTaskStorage struct {
+	storage.TaskStorage
+	sync *Sync
+}
+
+// Unwrap returns the wrapped storage, for reads that bypass the wrapper
+func (m *mirroredTaskStorage) Unwrap() storage.TaskStorage {
+	return m.TaskStorage
+}
+
+// CreateHumanTask creates the task and, in the background, its Jira issue
+func (m *mirroredTaskStorage) CreateHumanTask(prompt string) (*storage.HumanTask, error) {
+	task, err := m.TaskStorage.CreateHumanTask(prompt)
+	if err != nil {
+		return nil, err
+	}
+	m.sync.async(func() { m.sync.createIssue(task) })
+	return task, nil
+}
+
+// CloneHumanTask clones the task tree and creates a Jira issue for the clone
+func (m *mirroredTaskStorage) CloneHumanTask(sourceTaskID, project, prompt string) (*storage.HumanTask, []*storage.AgentTask, error) {
+	task, agentTasks, err := m.TaskStorage.CloneHumanTask(sourceTaskID, project, prompt)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.sync.async(func() { m.sync.createIssue(task) })
+	return task, agentTasks, nil
+}
+
+// UpdateTaskStatus updates the task and transitions its Jira issue
+func (m *mirroredTaskStorage) UpdateTaskStatus(taskID string, status storage.TaskStatus, notes string) error {
+	if err := m.TaskStorage.UpdateTaskStatus(taskID, status, notes); err != nil {
+		return err
+	}
+	m.sync.async(func() { m.sync.pushStatus(taskID, status) })
+	return nil
+}
+
+// createIssue creates the Jira issue of a human task and links it
+func (s *Sync) createIssue(task *storage.HumanTask) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	description := fmt.Sprintf("%s\n\nHyperion task: %s", task.Prompt, task.ID)
+	key, err := s.api.CreateIssue(ctx, issueSummary(task.Prompt), description)
+	if err != nil {
+		s.logger.Warn("Failed to create Jira issue for task", zap.String("taskId", task.ID), zap.Error(err))
+		return
+	}
+	if err := s.links.SetJiraIssueKey(task.ID, key); err != nil {
+		s.logger.Warn("Failed to link task to Jira issue", zap.String("taskId", task.ID), zap.String("issue", key), zap.Error(err))
+		return
+	}
+	s.logger.Info("Created Jira issue for task", zap.String("taskId", task.ID), zap.String("issue", key))
+}
+
+// pushStatus transitions the Jira issue of a human task to match its status
+func (s *Sync) pushStatus(taskID string, status storage.TaskStatus) {
+	task, err := s.tasks.GetHumanTask(taskID)
+	if err != nil || task.JiraIssueKey == "" {
+		return // Agent task or unlinked human task
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	issue, err := s.api.GetIssue(ctx, task.JiraIssueKey)
+	if err != nil {
+		s.logger.Warn("Failed to read Jira issue", zap.String("issue", task.JiraIssueKey), zap.Error(err))
+		return
+	}
+	if s.TaskStatusFor(issue.Status) == status {
+		return // Already in step, e.g. the change came from Jira
+	}
+
+	transitions, err := s.api.Transitions(ctx, task.JiraIssueKey)
+	if err != nil {
+		s.logger.Warn("Failed to list Jira transitions", zap.String("issue", task.JiraIssueKey), zap.Error(err))
+		return
+	}
+	for _, transition := range transitions {
+		if s.TaskStatusFor(transition.To) != status {
+			continue
+		}
+		if err := s.api.Transition(ctx, task.JiraIssueKey, transition.ID); err != nil {
+			s.logger.Warn("Failed to transition Jira issue", zap.String("issue", task.JiraIssueKey), zap.Error(err))
+		}
+		return
+	}
+	s.logger.Warn("No Jira transition leads to task status",
+		zap.String("issue", task.JiraIssueKey),
+		zap.String("from", issue.Status.Name),
+		zap.String("status", string(status)))
+}
+
+// TaskStatusFor maps a Jira status to a task status: the configured blocked
+// status is blocked, otherwise the status category decides
+func (s *Sync) TaskStatusFor(status Status) storage.TaskStatus {
+	if strings.EqualFold(status.Name, s.cfg.BlockedStatus) {
+		return storage.TaskStatusBlocked
+	}
+	switch status.Category {
+	case "done":
+		return storage.TaskStatusCompleted
+	case "indeterminate":
+		return storage.TaskStatusInProgress
+	default:
+		return storage.TaskStatusPending
+	}
+}
+
+// ApplyIssue updates the human task linked to an issue to the issue's status.
+// It reports whether the task changed; unlinked issues are ignored.
+func (s *Sync) ApplyIssue(issue *Issue) (bool, error) {
+	task, err := s.links.GetHumanTaskByJiraKey(issue.Key)
+	if err != nil || task == nil {
+		return false, err
+	}
+
+	status := s.TaskStatusFor(issue.Status)
+	if task.Status == status {
+		return false, nil
+	}
+	if err := s.tasks.UpdateTaskStatus(task.ID, status, ""); err != nil {
+		return false, err
+	}
+	s.logger.Info("Applied Jira status to task",
+		zap.String("issue", issue.Key),
+		zap.String("taskId", task.ID),
+		zap.String("status", string(status)))
+	return true, nil
+}
+
+// VerifyWebhook checks the token sent with an inbound webhook. Webhooks are
+// rejected when no JIRA_WEBHOOK_SECRET is configured.
+func (s *Sync) VerifyWebhook(token string) bool {
+	return s.cfg.WebhookSecret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.WebhookSecret)) == 1
+}
+
+// ParseWebhook reads the issue from a Jira issue webhook. It returns nil for
+// other events.
+func ParseWebhook(body []byte) (*Issue, error) {
+	var event struct {
+		WebhookEvent string `json:"webhookEvent"`
+		Issue        *struct {
+			Key    string      `json:"key"`
+			Fields issueFields `json:"fields"`
+		} `json:"issue"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	if !strings.HasPrefix(event.WebhookEvent, "jira:issue_") || event.Issue == nil || event.Issue.Key == "" {
+		return nil, nil
+	}
+	return &Issue{Key: event.Issue.Key, Status: event.Issue.Fields.status()}, nil
+}
+
+// Start polls Jira for updated issues until ctx is cancelled, catching
+// transitions whose webhook was missed
+func (s *Sync) Start(ctx context.Context) {
+	if s.cfg.PollInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.PollInterval)
+		defer ticker.Stop()
+
+		s.logger.Info("Jira polling started", zap.Duration("interval", s.cfg.PollInterval))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Poll(ctx); err != nil {
+					s.logger.Warn("Jira poll failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Poll applies the status of every project issue updated since the last poll
+func (s *Sync) Poll(ctx context.Context) error {
+	started := time.Now()
+	since := s.lastPoll
+	if since.IsZero() {
+		since = started.Add(-s.cfg.PollInterval)
+	}
+
+	issues, err := s.api.UpdatedSince(ctx, since)
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		if _, err := s.ApplyIssue(issue); err != nil {
+			s.logger.Warn("Failed to apply Jira issue", zap.String("issue", issue.Key), zap.Error(err))
+		}
+	}
+	s.lastPoll = started
+	return nil
+}
+
+// issueSummary is the first line of the prompt, cut to Jira's summary limit
+func issueSummary(prompt string) string {
+	summary := strings.TrimSpace(prompt)
+	if i := strings.IndexByte(summary, '\n'); i >= 0 {
+		summary = strings.TrimSpace(summary[:i])
+	}
+	runes := []rune(summary)
+	if len(runes) > maxSummaryChars {
+		summary = string(runes[:maxSummaryChars-3]) + "..."
+	}
+	if summary == "" {
+		summary = "Hyperion task"
+	}
+	return summary
+}
@@ -0,0 +1,190 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryTasks keeps human tasks and their Jira links in memory
+type memoryTasks struct {
+	storage.TaskStorage
+	human map[string]*storage.HumanTask
+}
+
+func (m *memoryTasks) CreateHumanTask(prompt string) (*storage.HumanTask, error) {
+	task := &storage.HumanTask{ID: fmt.Sprintf("h-%d", len(m.human)+1), Prompt: prompt, Status: storage.TaskStatusPending}
+	m.human[task.ID] = task
+	return task, nil
+}
+
+func (m *memoryTasks) GetHumanTask(id string) (*storage.HumanTask, error) {
+	if task, ok := m.human[id]; ok {
+		return task, nil
+	}
+	return nil, fmt.Errorf("human task with ID %s not found", id)
+}
+
+func (m *memoryTasks) UpdateTaskStatus(id string, status storage.TaskStatus, notes string) error {
+	task, err := m.GetHumanTask(id)
+	if err != nil {
+		return err
+	}
+	task.Status = status
+	return nil
+}
+
+func (m *memoryTasks) SetJiraIssueKey(id, key string) error {
+	task, err := m.GetHumanTask(id)
+	if err != nil {
+		return err
+	}
+	task.JiraIssueKey = key
+	return nil
+}
+
+func (m *memoryTasks) GetHumanTaskByJiraKey(key string) (*storage.HumanTask, error) {
+	for _, task := range m.human {
+		if task.JiraIssueKey == key {
+			return task, nil
+		}
+	}
+	return nil, nil
+}
+
+// fakeJira is an in-memory Jira project with a simple workflow
+type fakeJira struct {
+	issues  map[string]*Issue
+	summary map[string]string
+	updated []*Issue
+}
+
+var workflow = []Transition{
+	{ID: "11", To: Status{Name: "To Do", Category: "new"}},
+	{ID: "21", To: Status{Name: "In Progress", Category: "indeterminate"}},
+	{ID: "31", To: Status{Name: "Blocked", Category: "indeterminate"}},
+	{ID: "41", To: Status{Name: "Done", Category: "done"}},
+}
+
+func (f *fakeJira) CreateIssue(ctx context.Context, summary, description string) (string, error) {
+	key := fmt.Sprintf("HYP-%d", len(f.issues)+1)
+	f.issues[key] = &Issue{Key: key, Status: workflow[0].To}
+	f.summary[key] = summary
+	return key, nil
+}
+
+func (f *fakeJira) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	issue, ok := f.issues[key]
+	if !ok {
+		return nil, fmt.Errorf("jira returned status 404")
+	}
+	return &Issue{Key: key, Status: issue.Status}, nil
+}
+
+func (f *fakeJira) Transitions(ctx context.Context, key string) ([]Transition, error) {
+	return workflow, nil
+}
+
+func (f *fakeJira) Transition(ctx context.Context, key, transitionID string) error {
+	for _, t := range workflow {
+		if t.ID == transitionID {
+			f.issues[key].Status = t.To
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown transition %s", transitionID)
+}
+
+func (f *fakeJira) UpdatedSince(ctx context.Context, since time.Time) ([]*Issue, error) {
+	return f.updated, nil
+}
+
+func newTestSync() (*Sync, *memoryTasks, *fakeJira) {
+	tasks := &memoryTasks{human: map[string]*storage.HumanTask{}}
+	api := &fakeJira{issues: map[string]*Issue{}, summary: map[string]string{}}
+	sync := &Sync{
+		cfg:    Config{BlockedStatus: "Blocked", PollInterval: time.Minute},
+		api:    api,
+		tasks:  tasks,
+		links:  tasks,
+		logger: zap.NewNop(),
+		async:  func(f func()) { f() },
+	}
+	return sync, tasks, api
+}
+
+func TestMirroredTaskStorage(t *testing.T) {
+	sync, tasks, api := newTestSync()
+	mirrored := sync.Mirror(tasks)
+
+	task, err := mirrored.CreateHumanTask("Ship digests\nwith email and Slack")
+	require.NoError(t, err)
+	assert.Equal(t, "HYP-1", tasks.human[task.ID].JiraIssueKey)
+	assert.Equal(t, "Ship digests", api.summary["HYP-1"])
+
+	for _, status := range []storage.TaskStatus{storage.TaskStatusInProgress, storage.TaskStatusBlocked, storage.TaskStatusCompleted} {
+		require.NoError(t, mirrored.UpdateTaskStatus(task.ID, status, ""))
+		assert.Equal(t, status, sync.TaskStatusFor(api.issues["HYP-1"].Status))
+	}
+	assert.Equal(t, "Done", api.issues["HYP-1"].Status.Name)
+}
+
+func TestApplyIssue(t *testing.T) {
+	sync, tasks, api := newTestSync()
+	tasks.human["h-1"] = &storage.HumanTask{ID: "h-1", Status: storage.TaskStatusInProgress, JiraIssueKey: "HYP-1"}
+
+	updated, err := sync.ApplyIssue(&Issue{Key: "HYP-1", Status: Status{Name: "blocked", Category: "indeterminate"}})
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, storage.TaskStatusBlocked, tasks.human["h-1"].Status)
+
+	// Same status and unlinked issues change nothing
+	updated, err = sync.ApplyIssue(&Issue{Key: "HYP-1", Status: Status{Name: "Blocked"}})
+	require.NoError(t, err)
+	assert.False(t, updated)
+	updated, err = sync.ApplyIssue(&Issue{Key: "OTHER-1", Status: Status{Category: "done"}})
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	api.updated = []*Issue{{Key: "HYP-1", Status: Status{Name: "Done", Category: "done"}}}
+	require.NoError(t, sync.Poll(context.Background()))
+	assert.Equal(t, storage.TaskStatusCompleted, tasks.human["h-1"].Status)
+	assert.False(t, sync.lastPoll.IsZero())
+}
+
+func TestParseWebhook(t *testing.T) {
+	issue, err := ParseWebhook([]byte(`{"webhookEvent":"jira:issue_updated","issue":{"key":"HYP-3","fields":{"status":{"name":"Done","statusCategory":{"key":"done"}}}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Issue{Key: "HYP-3", Status: Status{Name: "Done", Category: "done"}}, issue)
+
+	issue, err = ParseWebhook([]byte(`{"webhookEvent":"comment_created","comment":{}}`))
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+
+	_, err = ParseWebhook([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	sync, _, _ := newTestSync()
+	assert.False(t, sync.VerifyWebhook(""), "no secret configured")
+
+	sync.cfg.WebhookSecret = "s3cret"
+	assert.True(t, sync.VerifyWebhook("s3cret"))
+	assert.False(t, sync.VerifyWebhook("guess"))
+}
+
+func TestIssueSummary(t *testing.T) {
+	assert.Equal(t, "Hyperion task", issueSummary("  "))
+	long := issueSummary(strings.Repeat("x", 300))
+	assert.Len(t, long, maxSummaryChars)
+	assert.True(t, strings.HasSuffix(long, "..."))
+}
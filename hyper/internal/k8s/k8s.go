@@ -0,0 +1,160 @@
+// Package k8s adapts the coordinator to run as a Kubernetes workload
+// (`hyper --k8s`): secrets are read from mounted files, logs are JSON, and
+// the HTTP server drains before it stops.
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultSecretsDir is where the chart mounts the coordinator's Secret
+const DefaultSecretsDir = "/var/run/secrets/hyper"
+
+// Config controls the Kubernetes mode
+type Config struct {
+	SecretsDir      string        // Files named after variables, e.g. a mounted Secret
+	ProbePort       string        // Port serving /healthz, /readyz and /drain; empty disables it
+	DrainDelay      time.Duration // How long /readyz fails before the listener closes
+	ShutdownTimeout time.Duration // Bound for in-flight requests to finish after the drain
+}
+
+// LoadConfig reads K8S_SECRETS_DIR (default DefaultSecretsDir),
+// K8S_PROBE_PORT (default 8081), K8S_DRAIN_DELAY (default 10s) and
+// K8S_SHUTDOWN_TIMEOUT (default 20s)
+func LoadConfig() (Config, error) {
+	cfg := Config{SecretsDir: DefaultSecretsDir, ProbePort: "8081", DrainDelay: 10 * time.Second, ShutdownTimeout: 20 * time.Second}
+
+	if dir, ok := os.LookupEnv("K8S_SECRETS_DIR"); ok {
+		cfg.SecretsDir = dir
+	}
+	if port, ok := os.LookupEnv("K8S_PROBE_PORT"); ok {
+		if _, err := strconv.ParseUint(port, 10, 16); port != "" && err != nil {
+			return cfg, fmt.Errorf("invalid K8S_PROBE_PORT %q: must be a port number", port)
+		}
+		cfg.ProbePort = port
+	}
+	if raw := os.Getenv("K8S_DRAIN_DELAY"); raw != "" {
+		delay, err := time.ParseDuration(raw)
+		if err != nil || delay < 0 {
+			return cfg, fmt.Errorf("invalid K8S_DRAIN_DELAY %q: must be a duration such as 10s", raw)
+		}
+		cfg.DrainDelay = delay
+	}
+	if raw := os.Getenv("K8S_SHUTDOWN_TIMEOUT"); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil || timeout <= 0 {
+			return cfg, fmt.Errorf("invalid K8S_SHUTDOWN_TIMEOUT %q: must be a positive duration such as 20s", raw)
+		}
+		cfg.ShutdownTimeout = timeout
+	}
+	return cfg, nil
+}
+
+// envName matches the file names taken as variables
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// secretVariables are the credentials that can be given as VAR_FILE. The
+// list is explicit since other software uses the suffix for paths it reads
+// itself (SSL_CERT_FILE, for one). FIELD_ENCRYPTION_KEYS_FILE is read by
+// the field cipher.
+var secretVariables = []string{
+	"MONGODB_URI",
+	"QDRANT_API_KEY",
+	"OPENAI_API_KEY",
+	"VOYAGE_API_KEY",
+	"ANTHROPIC_API_KEY",
+	"API_KEY",
+	"JWT_SECRET",
+	"SMTP_PASSWORD",
+	"JIRA_API_TOKEN",
+	"JIRA_WEBHOOK_SECRET",
+	"LINEAR_API_KEY",
+	"POLICY_OPA_TOKEN",
+	"CACHE_API_TOKEN",
+	"CACHE_PRIMARY_TOKEN",
+}
+
+// LoadSecretFiles sets variables from files, overriding the environment:
+// each of secretVariables from the file named by VAR_FILE, and every file in
+// dir as the variable it is named after (how Kubernetes mounts the keys of a
+// Secret). A missing dir is skipped. Trailing newlines are trimmed. Returns
+// the variables set.
+func LoadSecretFiles(dir string) ([]string, error) {
+	values := map[string]string{}
+
+	for _, name := range secretVariables {
+		path := os.Getenv(name + "_FILE")
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s_FILE: %w", name, err)
+		}
+		values[name] = strings.TrimRight(string(data), "\r\n")
+	}
+
+	if dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read secrets directory %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			// Kubernetes keeps the current version in ..data and timestamped
+			// directories, with each key a symlink into it
+			if !envName.MatchString(entry.Name()) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read secret %s: %w", path, err)
+			}
+			values[entry.Name()] = strings.TrimRight(string(data), "\r\n")
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name, value := range values {
+		if err := os.Setenv(name, value); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", name, err)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// NewLogger returns a JSON logger at LOG_LEVEL (default info), with the
+// field names Cloud Logging and most collectors recognise
+func NewLogger() (*zap.Logger, error) {
+	cfg := zap.NewProductionConfig()
+	cfg.EncoderConfig.TimeKey = "time"
+	cfg.EncoderConfig.LevelKey = "severity"
+	cfg.EncoderConfig.MessageKey = "message"
+	cfg.EncoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	cfg.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	if raw := os.Getenv("LOG_LEVEL"); raw != "" {
+		level, err := zap.ParseAtomicLevel(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVEL %q: %w", raw, err)
+		}
+		cfg.Level = level
+	}
+	return cfg.Build()
+}
@@ -0,0 +1,80 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mountSecret lays out files the way Kubernetes mounts a Secret: the keys
+// live in a timestamped directory, reached through ..data and a symlink each
+func mountSecret(t *testing.T, keys map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	version := filepath.Join(dir, "..2026_10_16_12_00_00.000000001")
+	require.NoError(t, os.Mkdir(version, 0o755))
+	for key, value := range keys {
+		require.NoError(t, os.WriteFile(filepath.Join(version, key), []byte(value), 0o600))
+	}
+	require.NoError(t, os.Symlink(filepath.Base(version), filepath.Join(dir, "..data")))
+	for key := range keys {
+		require.NoError(t, os.Symlink(filepath.Join("..data", key), filepath.Join(dir, key)))
+	}
+	return dir
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	dir := mountSecret(t, map[string]string{
+		"MONGODB_URI":    "mongodb://mongo.db.svc:27017\n",
+		"OPENAI_API_KEY": "sk-from-secret",
+	})
+	keyFile := filepath.Join(t.TempDir(), "qdrant-key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("qdrant-secret\r\n"), 0o600))
+
+	t.Setenv("MONGODB_URI", "mongodb://localhost:27017")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("QDRANT_API_KEY", "")
+	t.Setenv("QDRANT_API_KEY_FILE", keyFile)
+
+	loaded, err := LoadSecretFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MONGODB_URI", "OPENAI_API_KEY", "QDRANT_API_KEY"}, loaded, "..data and version directories are skipped")
+	assert.Equal(t, "mongodb://mongo.db.svc:27017", os.Getenv("MONGODB_URI"), "files override the environment")
+	assert.Equal(t, "sk-from-secret", os.Getenv("OPENAI_API_KEY"))
+	assert.Equal(t, "qdrant-secret", os.Getenv("QDRANT_API_KEY"))
+}
+
+func TestLoadSecretFiles_Missing(t *testing.T) {
+	loaded, err := LoadSecretFiles(filepath.Join(t.TempDir(), "absent"))
+	require.NoError(t, err, "a missing secrets directory is skipped")
+	assert.Empty(t, loaded)
+
+	t.Setenv("VOYAGE_API_KEY_FILE", filepath.Join(t.TempDir(), "absent"))
+	_, err = LoadSecretFiles("")
+	assert.ErrorContains(t, err, "VOYAGE_API_KEY_FILE")
+}
+
+func TestLoadConfig(t *testing.T) {
+	for _, key := range []string{"K8S_SECRETS_DIR", "K8S_PROBE_PORT", "K8S_DRAIN_DELAY", "K8S_SHUTDOWN_TIMEOUT"} {
+		t.Setenv(key, "") // restored after the test
+		os.Unsetenv(key)
+	}
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, Config{SecretsDir: DefaultSecretsDir, ProbePort: "8081", DrainDelay: 10 * time.Second, ShutdownTimeout: 20 * time.Second}, cfg)
+
+	t.Setenv("K8S_PROBE_PORT", "")
+	t.Setenv("K8S_DRAIN_DELAY", "0s")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.ProbePort, "an empty port disables the probe server")
+	assert.Zero(t, cfg.DrainDelay)
+
+	t.Setenv("K8S_PROBE_PORT", "http")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "K8S_PROBE_PORT")
+}
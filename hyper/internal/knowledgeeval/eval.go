@@ -0,0 +1,344 @@
+// Package knowledgeeval measures knowledge retrieval quality. A suite lists
+// questions for a collection and the entries a query for each must return;
+// running it through the real query path yields recall, precision and MRR at
+// k, compared with the previous run so changes to embedding models or
+// chunking that hurt retrieval show up as drift instead of going unnoticed.
+package knowledgeeval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultK is the number of results retrieved per question
+const DefaultK = 5
+
+// maxK bounds the results retrieved per question
+const maxK = 50
+
+// Run triggers
+const (
+	TriggerManual       = "manual"        // Requested through the tool or the CLI
+	TriggerConfigChange = "config-change" // The embedding model or knowledge settings changed
+)
+
+// Searcher queries knowledge the way agents do (implemented by
+// storage.KnowledgeStorage)
+type Searcher interface {
+	Query(collection, query string, limit int) ([]*storage.QueryResult, error)
+}
+
+// Store keeps suites and runs (implemented by storage.KnowledgeEvalStorage)
+type Store interface {
+	SaveKnowledgeEvalSuite(suite *storage.KnowledgeEvalSuite) error
+	GetKnowledgeEvalSuite(collection string) (*storage.KnowledgeEvalSuite, error)
+	ListKnowledgeEvalSuites() ([]*storage.KnowledgeEvalSuite, error)
+	SaveKnowledgeEvalRun(run *storage.KnowledgeEvalRun) error
+	LatestKnowledgeEvalRun(collection string) (*storage.KnowledgeEvalRun, error)
+}
+
+// Fingerprint identifies the embedding model and knowledge settings results
+// depend on. Runs with another fingerprint were made under another
+// configuration.
+func Fingerprint(settings ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(settings, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Validate checks a suite and fills in defaults
+func Validate(suite *storage.KnowledgeEvalSuite) error {
+	if strings.TrimSpace(suite.Collection) == "" {
+		return fmt.Errorf("collection is required")
+	}
+	if suite.K == 0 {
+		suite.K = DefaultK
+	}
+	if suite.K < 1 || suite.K > maxK {
+		return fmt.Errorf("k must be between 1 and %d", maxK)
+	}
+	if suite.MinRecall < 0 || suite.MinRecall > 1 || suite.MinPrecision < 0 || suite.MinPrecision > 1 {
+		return fmt.Errorf("minRecall and minPrecision must be between 0 and 1")
+	}
+	if len(suite.Cases) == 0 {
+		return fmt.Errorf("at least one case is required")
+	}
+	for i, c := range suite.Cases {
+		if strings.TrimSpace(c.Question) == "" {
+			return fmt.Errorf("case %d: question is required", i+1)
+		}
+		if len(c.ExpectedIDs)+len(c.ExpectedText) == 0 {
+			return fmt.Errorf("case %d: expectedIds or expectedText is required", i+1)
+		}
+	}
+	return nil
+}
+
+// Evaluate runs the cases of suite against searcher and scores them. The
+// run has no ID, trigger or comparison with earlier runs yet.
+func Evaluate(searcher Searcher, suite *storage.KnowledgeEvalSuite) *storage.KnowledgeEvalRun {
+	run := &storage.KnowledgeEvalRun{Collection: suite.Collection, K: suite.K, Cases: make([]storage.KnowledgeEvalCaseResult, 0, len(suite.Cases))}
+
+	var recallSum, precisionSum, rrSum float64
+	for _, c := range suite.Cases {
+		result := evaluateCase(searcher, suite.Collection, suite.K, c)
+		recallSum += result.Recall
+		precisionSum += result.Precision
+		if result.Rank > 0 {
+			rrSum += 1 / float64(result.Rank)
+		}
+		run.Cases = append(run.Cases, result)
+	}
+
+	if n := float64(len(suite.Cases)); n > 0 {
+		run.Recall = recallSum / n
+		run.Precision = precisionSum / n
+		run.MRR = rrSum / n
+	}
+	if run.Recall < suite.MinRecall {
+		run.Failures = append(run.Failures, fmt.Sprintf("recall@%d %.2f is below the minimum %.2f", suite.K, run.Recall, suite.MinRecall))
+	}
+	if run.Precision < suite.MinPrecision {
+		run.Failures = append(run.Failures, fmt.Sprintf("precision@%d %.2f is below the minimum %.2f", suite.K, run.Precision, suite.MinPrecision))
+	}
+	run.Passed = len(run.Failures) == 0
+	return run
+}
+
+// evaluateCase queries one question and scores the results. A result is
+// relevant when it has an expected ID or contains an expected text.
+func evaluateCase(searcher Searcher, collection string, k int, c storage.KnowledgeEvalCase) storage.KnowledgeEvalCaseResult {
+	result := storage.KnowledgeEvalCaseResult{Question: c.Question}
+
+	results, err := searcher.Query(collection, c.Question, k)
+	if err != nil {
+		result.Error = err.Error()
+		result.Missing = append(append([]string{}, c.ExpectedIDs...), c.ExpectedText...)
+		return result
+	}
+	if len(results) > k {
+		results = results[:k]
+	}
+
+	found := 0
+	for _, id := range c.ExpectedIDs {
+		if !anyResult(results, func(entry *storage.KnowledgeEntry) bool { return entry.ID == id }) {
+			result.Missing = append(result.Missing, id)
+			continue
+		}
+		found++
+	}
+	for _, text := range c.ExpectedText {
+		if !anyResult(results, func(entry *storage.KnowledgeEntry) bool { return containsFold(entry.Text, text) }) {
+			result.Missing = append(result.Missing, text)
+			continue
+		}
+		found++
+	}
+	result.Recall = float64(found) / float64(len(c.ExpectedIDs)+len(c.ExpectedText))
+
+	relevant := 0
+	for i, r := range results {
+		if r.Entry == nil || !expected(r.Entry, c) {
+			continue
+		}
+		relevant++
+		if result.Rank == 0 {
+			result.Rank = i + 1
+		}
+	}
+	if len(results) > 0 {
+		result.Precision = float64(relevant) / float64(len(results))
+	}
+	return result
+}
+
+// expected reports whether entry is one of the entries c expects
+func expected(entry *storage.KnowledgeEntry, c storage.KnowledgeEvalCase) bool {
+	for _, id := range c.ExpectedIDs {
+		if entry.ID == id {
+			return true
+		}
+	}
+	for _, text := range c.ExpectedText {
+		if containsFold(entry.Text, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyResult(results []*storage.QueryResult, match func(*storage.KnowledgeEntry) bool) bool {
+	for _, r := range results {
+		if r.Entry != nil && match(r.Entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(text, snippet string) bool {
+	return strings.Contains(strings.ToLower(text), strings.ToLower(strings.TrimSpace(snippet)))
+}
+
+// Evaluator runs suites, compares each run with the previous one and
+// records it
+type Evaluator struct {
+	store       Store
+	searcher    Searcher
+	fingerprint string
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewEvaluator creates an evaluator. fingerprint identifies the current
+// configuration; see Fingerprint.
+func NewEvaluator(store Store, searcher Searcher, fingerprint string, logger *zap.Logger) *Evaluator {
+	return &Evaluator{
+		store:       store,
+		searcher:    searcher,
+		fingerprint: fingerprint,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// SetSuite validates suite and replaces the suite of its collection
+func (e *Evaluator) SetSuite(suite *storage.KnowledgeEvalSuite) error {
+	if err := Validate(suite); err != nil {
+		return err
+	}
+	suite.UpdatedAt = e.now().UTC()
+	return e.store.SaveKnowledgeEvalSuite(suite)
+}
+
+// SuiteStatus is a suite with its latest run, nil if it never ran
+type SuiteStatus struct {
+	Suite     *storage.KnowledgeEvalSuite `json:"suite"`
+	LatestRun *storage.KnowledgeEvalRun   `json:"latestRun,omitempty"`
+}
+
+// Suites returns every suite with its latest run
+func (e *Evaluator) Suites() ([]SuiteStatus, error) {
+	suites, err := e.store.ListKnowledgeEvalSuites()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]SuiteStatus, 0, len(suites))
+	for _, suite := range suites {
+		latest, err := e.store.LatestKnowledgeEvalRun(suite.Collection)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, SuiteStatus{Suite: suite, LatestRun: latest})
+	}
+	return statuses, nil
+}
+
+// RunAll runs every suite. onRun, when not nil, is called after each run
+// with the number of suites run so far.
+func (e *Evaluator) RunAll(trigger string, onRun func(run *storage.KnowledgeEvalRun, done, total int)) ([]*storage.KnowledgeEvalRun, error) {
+	suites, err := e.store.ListKnowledgeEvalSuites()
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*storage.KnowledgeEvalRun, 0, len(suites))
+	for i, suite := range suites {
+		run, err := e.Run(suite.Collection, trigger)
+		if err != nil {
+			return nil, err
+		}
+		if run == nil {
+			continue
+		}
+		runs = append(runs, run)
+		if onRun != nil {
+			onRun(run, i+1, len(suites))
+		}
+	}
+	return runs, nil
+}
+
+// Run runs the suite of collection and records the run. It returns nil
+// without error when the collection has no suite.
+func (e *Evaluator) Run(collection, trigger string) (*storage.KnowledgeEvalRun, error) {
+	suite, err := e.store.GetKnowledgeEvalSuite(collection)
+	if err != nil || suite == nil {
+		return nil, err
+	}
+	previous, err := e.store.LatestKnowledgeEvalRun(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	run := Evaluate(e.searcher, suite)
+	run.ID = uuid.New().String()
+	run.At = e.now().UTC()
+	run.Trigger = trigger
+	run.Fingerprint = e.fingerprint
+	if previous != nil {
+		recallDelta := run.Recall - previous.Recall
+		precisionDelta := run.Precision - previous.Precision
+		run.RecallDelta = &recallDelta
+		run.PrecisionDelta = &precisionDelta
+	}
+
+	if err := e.store.SaveKnowledgeEvalRun(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Start runs, in the background, the suites whose latest run was made under
+// another configuration, so retrieval drift after a model or settings change
+// is logged without anyone asking
+func (e *Evaluator) Start(ctx context.Context) {
+	go func() {
+		suites, err := e.store.ListKnowledgeEvalSuites()
+		if err != nil {
+			e.logger.Warn("Failed to list knowledge evaluation suites", zap.Error(err))
+			return
+		}
+		for _, suite := range suites {
+			if ctx.Err() != nil {
+				return
+			}
+			latest, err := e.store.LatestKnowledgeEvalRun(suite.Collection)
+			if err != nil {
+				e.logger.Warn("Failed to read knowledge evaluation runs", zap.String("collection", suite.Collection), zap.Error(err))
+				continue
+			}
+			if latest != nil && latest.Fingerprint == e.fingerprint {
+				continue
+			}
+
+			run, err := e.Run(suite.Collection, TriggerConfigChange)
+			if err != nil {
+				e.logger.Warn("Knowledge evaluation failed", zap.String("collection", suite.Collection), zap.Error(err))
+				continue
+			}
+			fields := []zap.Field{
+				zap.String("collection", run.Collection),
+				zap.Float64("recall", run.Recall),
+				zap.Float64("precision", run.Precision),
+			}
+			if run.RecallDelta != nil {
+				fields = append(fields, zap.Float64("recallDelta", *run.RecallDelta), zap.Float64("precisionDelta", *run.PrecisionDelta))
+			}
+			if !run.Passed {
+				e.logger.Warn("Knowledge retrieval below its evaluation thresholds after a configuration change",
+					append(fields, zap.Strings("failures", run.Failures))...)
+				continue
+			}
+			e.logger.Info("Knowledge evaluation passed after a configuration change", fields...)
+		}
+	}()
+}
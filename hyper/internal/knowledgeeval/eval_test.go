@@ -0,0 +1,241 @@
+package knowledgeeval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeSearcher returns fixed results per question
+type fakeSearcher map[string][]*storage.KnowledgeEntry
+
+func (f fakeSearcher) Query(collection, query string, limit int) ([]*storage.QueryResult, error) {
+	entries, ok := f[query]
+	if !ok {
+		return nil, fmt.Errorf("qdrant unavailable")
+	}
+	results := make([]*storage.QueryResult, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, &storage.QueryResult{Entry: entry})
+	}
+	return results, nil
+}
+
+// memoryStore keeps suites and runs in memory
+type memoryStore struct {
+	mu     sync.Mutex
+	suites map[string]*storage.KnowledgeEvalSuite
+	runs   []*storage.KnowledgeEvalRun
+}
+
+func (m *memoryStore) SaveKnowledgeEvalSuite(suite *storage.KnowledgeEvalSuite) error {
+	m.suites[suite.Collection] = suite
+	return nil
+}
+
+func (m *memoryStore) GetKnowledgeEvalSuite(collection string) (*storage.KnowledgeEvalSuite, error) {
+	return m.suites[collection], nil
+}
+
+func (m *memoryStore) ListKnowledgeEvalSuites() ([]*storage.KnowledgeEvalSuite, error) {
+	var suites []*storage.KnowledgeEvalSuite
+	for _, suite := range m.suites {
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+func (m *memoryStore) SaveKnowledgeEvalRun(run *storage.KnowledgeEvalRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs = append(m.runs, run)
+	return nil
+}
+
+func (m *memoryStore) LatestKnowledgeEvalRun(collection string) (*storage.KnowledgeEvalRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.runs) - 1; i >= 0; i-- {
+		if m.runs[i].Collection == collection {
+			return m.runs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryStore) runCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.runs)
+}
+
+func entry(id, text string) *storage.KnowledgeEntry {
+	return &storage.KnowledgeEntry{ID: id, Text: text}
+}
+
+func TestValidate(t *testing.T) {
+	suite := &storage.KnowledgeEvalSuite{
+		Collection: "team-docs",
+		Cases:      []storage.KnowledgeEvalCase{{Question: "How do we deploy?", ExpectedIDs: []string{"a"}}},
+	}
+	require.NoError(t, Validate(suite))
+	assert.Equal(t, DefaultK, suite.K)
+
+	for name, suite := range map[string]*storage.KnowledgeEvalSuite{
+		"no collection":  {Cases: suite.Cases},
+		"no cases":       {Collection: "team-docs"},
+		"no expectation": {Collection: "team-docs", Cases: []storage.KnowledgeEvalCase{{Question: "How?"}}},
+		"no question":    {Collection: "team-docs", Cases: []storage.KnowledgeEvalCase{{ExpectedIDs: []string{"a"}}}},
+		"k too large":    {Collection: "team-docs", K: 500, Cases: suite.Cases},
+		"recall above 1": {Collection: "team-docs", MinRecall: 1.5, Cases: suite.Cases},
+	} {
+		assert.Error(t, Validate(suite), name)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	searcher := fakeSearcher{
+		"How do we deploy?": {entry("x", "Unrelated"), entry("deploy", "Deploy with make release"), entry("y", "Also unrelated")},
+		"Where are logs?":   {entry("logs-1", "Logs are shipped to LOKI"), entry("z", "Other")},
+	}
+	suite := &storage.KnowledgeEvalSuite{
+		Collection: "team-docs",
+		K:          3,
+		MinRecall:  0.5,
+		Cases: []storage.KnowledgeEvalCase{
+			{Question: "How do we deploy?", ExpectedIDs: []string{"deploy", "rollback"}},
+			{Question: "Where are logs?", ExpectedText: []string{"shipped to loki"}},
+		},
+	}
+
+	run := Evaluate(searcher, suite)
+
+	require.Len(t, run.Cases, 2)
+	assert.Equal(t, 0.5, run.Cases[0].Recall)
+	assert.InDelta(t, 1.0/3, run.Cases[0].Precision, 1e-9)
+	assert.Equal(t, 2, run.Cases[0].Rank)
+	assert.Equal(t, []string{"rollback"}, run.Cases[0].Missing)
+	assert.Equal(t, 1.0, run.Cases[1].Recall, "text expectations match case-insensitively")
+	assert.Equal(t, 1, run.Cases[1].Rank)
+
+	assert.Equal(t, 0.75, run.Recall)
+	assert.InDelta(t, (1.0/3+0.5)/2, run.Precision, 1e-9)
+	assert.Equal(t, 0.75, run.MRR)
+	assert.True(t, run.Passed)
+
+	suite.MinPrecision = 0.9
+	run = Evaluate(searcher, suite)
+	assert.False(t, run.Passed)
+	require.Len(t, run.Failures, 1)
+	assert.Contains(t, run.Failures[0], "precision@3")
+}
+
+func TestEvaluate_QueryErrorCountsAsMissed(t *testing.T) {
+	suite := &storage.KnowledgeEvalSuite{
+		Collection: "team-docs",
+		K:          5,
+		MinRecall:  0.1,
+		Cases:      []storage.KnowledgeEvalCase{{Question: "Unknown", ExpectedIDs: []string{"a"}}},
+	}
+
+	run := Evaluate(fakeSearcher{}, suite)
+
+	assert.Equal(t, "qdrant unavailable", run.Cases[0].Error)
+	assert.Equal(t, []string{"a"}, run.Cases[0].Missing)
+	assert.False(t, run.Passed)
+}
+
+func TestEvaluator_RunReportsDrift(t *testing.T) {
+	searcher := fakeSearcher{"How do we deploy?": {entry("deploy", "Deploy with make release")}}
+	store := &memoryStore{suites: map[string]*storage.KnowledgeEvalSuite{
+		"team-docs": {
+			Collection: "team-docs",
+			K:          5,
+			Cases:      []storage.KnowledgeEvalCase{{Question: "How do we deploy?", ExpectedIDs: []string{"deploy"}}},
+		},
+	}}
+	store.runs = []*storage.KnowledgeEvalRun{{ID: "old", Collection: "team-docs", Recall: 0.5, Precision: 0.25}}
+
+	evaluator := NewEvaluator(store, searcher, "fp", zap.NewNop())
+	run, err := evaluator.Run("team-docs", TriggerManual)
+
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.NotEmpty(t, run.ID)
+	assert.Equal(t, TriggerManual, run.Trigger)
+	assert.Equal(t, "fp", run.Fingerprint)
+	require.NotNil(t, run.RecallDelta)
+	assert.Equal(t, 0.5, *run.RecallDelta)
+	assert.Equal(t, 0.75, *run.PrecisionDelta)
+	assert.Equal(t, 2, store.runCount())
+
+	run, err = evaluator.Run("other", TriggerManual)
+	require.NoError(t, err)
+	assert.Nil(t, run, "collections without a suite are not run")
+}
+
+func TestEvaluator_SetSuiteAndRunAll(t *testing.T) {
+	store := &memoryStore{suites: map[string]*storage.KnowledgeEvalSuite{}}
+	evaluator := NewEvaluator(store, fakeSearcher{"How do we deploy?": {entry("deploy", "Deploy")}}, "fp", zap.NewNop())
+
+	assert.Error(t, evaluator.SetSuite(&storage.KnowledgeEvalSuite{Collection: "team-docs"}))
+	require.NoError(t, evaluator.SetSuite(&storage.KnowledgeEvalSuite{
+		Collection: "team-docs",
+		Cases:      []storage.KnowledgeEvalCase{{Question: "How do we deploy?", ExpectedIDs: []string{"deploy"}}},
+	}))
+	assert.Equal(t, DefaultK, store.suites["team-docs"].K)
+
+	var reported []int
+	runs, err := evaluator.RunAll(TriggerManual, func(run *storage.KnowledgeEvalRun, done, total int) {
+		reported = append(reported, done, total)
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, 1.0, runs[0].Recall)
+	assert.Equal(t, []int{1, 1}, reported)
+
+	statuses, err := evaluator.Suites()
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, runs[0], statuses[0].LatestRun)
+}
+
+func TestEvaluator_StartRunsSuitesAfterConfigChange(t *testing.T) {
+	suite := func(collection string) *storage.KnowledgeEvalSuite {
+		return &storage.KnowledgeEvalSuite{
+			Collection: collection,
+			K:          5,
+			Cases:      []storage.KnowledgeEvalCase{{Question: "How do we deploy?", ExpectedIDs: []string{"deploy"}}},
+		}
+	}
+	store := &memoryStore{suites: map[string]*storage.KnowledgeEvalSuite{
+		"current": suite("current"),
+		"stale":   suite("stale"),
+	}}
+	store.runs = []*storage.KnowledgeEvalRun{
+		{ID: "1", Collection: "current", Fingerprint: "new"},
+		{ID: "2", Collection: "stale", Fingerprint: "old"},
+	}
+	searcher := fakeSearcher{"How do we deploy?": {entry("deploy", "Deploy")}}
+
+	NewEvaluator(store, searcher, "new", zap.NewNop()).Start(context.Background())
+
+	require.Eventually(t, func() bool { return store.runCount() == 3 }, time.Second, 10*time.Millisecond)
+	latest, _ := store.LatestKnowledgeEvalRun("stale")
+	assert.Equal(t, TriggerConfigChange, latest.Trigger)
+	assert.Equal(t, "new", latest.Fingerprint)
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, Fingerprint("model", "768"), Fingerprint("model", "768"))
+	assert.NotEqual(t, Fingerprint("model", "768"), Fingerprint("model", "1024"))
+	assert.NotEqual(t, Fingerprint("ab", "c"), Fingerprint("a", "bc"))
+}
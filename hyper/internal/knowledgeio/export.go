@@ -0,0 +1,50 @@
+package knowledgeio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+)
+
+// ExportFunc streams every entry of a collection, oldest first
+type ExportFunc func(ctx context.Context, collection string, withVectors bool, visit func(*storage.ExportedKnowledge) error) error
+
+// Exporter returns how to export from store. A non-zero since limits the
+// export to entries created at or after it, for incremental syncs.
+func Exporter(store storage.KnowledgeStorage, since time.Time) (ExportFunc, error) {
+	exporter, ok := store.(storage.KnowledgeExporter)
+	if !ok {
+		return nil, errcode.New(errcode.Internal, "knowledge storage does not support export")
+	}
+	if since.IsZero() {
+		return exporter.ExportKnowledge, nil
+	}
+
+	incremental, ok := store.(storage.IncrementalKnowledgeExporter)
+	if !ok {
+		return nil, errcode.New(errcode.Validation, "knowledge storage does not support incremental export")
+	}
+	return func(ctx context.Context, collection string, withVectors bool, visit func(*storage.ExportedKnowledge) error) error {
+		return incremental.ExportKnowledgeSince(ctx, collection, since, withVectors, visit)
+	}, nil
+}
+
+// WriteNDJSON writes the export of a collection to w, one
+// storage.ExportedKnowledge per line, and returns how many entries it wrote
+func WriteNDJSON(ctx context.Context, export ExportFunc, collection string, withVectors bool, w io.Writer) (int, error) {
+	exported := 0
+	encoder := json.NewEncoder(w)
+	err := export(ctx, collection, withVectors, func(entry *storage.ExportedKnowledge) error {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		exported++
+		return nil
+	})
+	return exported, err
+}
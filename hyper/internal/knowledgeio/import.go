@@ -0,0 +1,368 @@
+// Package knowledgeio moves whole knowledge collections in and out of storage
+// as NDJSON, shared by the REST endpoints and the MCP import/export tools.
+package knowledgeio
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"hyper/internal/mcp/storage"
+)
+
+// Knowledge import formats
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+const (
+	DefaultBatchSize = 32
+	MaxBatchSize     = 256
+	maxRowBytes      = 1 << 20 // Larger NDJSON lines abort the import
+	maxErrors        = 1000    // Row errors beyond this are counted but not listed
+)
+
+// Row is one entry of a knowledge import
+type Row struct {
+	Collection string                 `json:"collection"`
+	Text       string                 `json:"text"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Vector     []float64              `json:"vector,omitempty"` // From an export; reused when its dimension matches the embedding model
+}
+
+// ImportError reports a row that was not imported
+type ImportError struct {
+	Row        int    `json:"row"` // Line number in the uploaded file
+	Collection string `json:"collection,omitempty"`
+	Error      string `json:"error"`
+}
+
+// Progress counts the rows handled so far
+type Progress struct {
+	Processed int `json:"processed"`
+	Imported  int `json:"imported"`
+	Failed    int `json:"failed"`
+	Batches   int `json:"batches"`
+}
+
+// Report is the result of a knowledge import
+type Report struct {
+	Progress
+	Format          string         `json:"format"`
+	Reembedded      bool           `json:"reembedded,omitempty"` // Exported vectors were ignored
+	Collections     map[string]int `json:"collections"`          // Imported rows per collection
+	Errors          []ImportError  `json:"errors"`
+	ErrorsTruncated bool           `json:"errorsTruncated,omitempty"`
+}
+
+// record is a parsed row, or the reason it could not be parsed
+type record struct {
+	line int
+	row  Row
+	err  error
+}
+
+// RowReader streams rows from an upload. Row-level problems are returned in
+// record.err; a returned error (other than io.EOF) aborts the import.
+type RowReader interface {
+	next() (record, error)
+}
+
+// Format picks the upload format from a format parameter or the content type
+func Format(format, contentType string) (string, error) {
+	switch strings.ToLower(format) {
+	case FormatNDJSON, "jsonl":
+		return FormatNDJSON, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported format %q: must be ndjson or csv", format)
+	}
+
+	if contentType == "text/csv" || contentType == "application/csv" {
+		return FormatCSV, nil
+	}
+	return FormatNDJSON, nil
+}
+
+// NewRowReader creates a row reader; defaultCollection applies to rows without one
+func NewRowReader(format string, body io.Reader, defaultCollection string) (RowReader, error) {
+	if format == FormatCSV {
+		return newCSVReader(body, defaultCollection)
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRowBytes)
+	return &ndjsonReader{scanner: scanner, defaultCollection: defaultCollection}, nil
+}
+
+// ndjsonReader reads one JSON object per line, skipping blank lines
+type ndjsonReader struct {
+	scanner           *bufio.Scanner
+	line              int
+	defaultCollection string
+}
+
+func (r *ndjsonReader) next() (record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		text := strings.TrimSpace(r.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		rec := record{line: r.line}
+		if err := json.Unmarshal([]byte(text), &rec.row); err != nil {
+			rec.err = fmt.Errorf("invalid JSON: %w", err)
+			return rec, nil
+		}
+		if rec.row.Collection == "" {
+			rec.row.Collection = r.defaultCollection
+		}
+		return rec, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return record{}, fmt.Errorf("line %d exceeds %d bytes", r.line+1, maxRowBytes)
+		}
+		return record{}, fmt.Errorf("failed to read upload: %w", err)
+	}
+	return record{}, io.EOF
+}
+
+// csvReader reads CSV with a header row. The header must have a text column
+// and may have collection and metadata (a JSON object) columns; any other
+// column is stored as a string metadata field.
+type csvReader struct {
+	reader            *csv.Reader
+	columns           []string
+	defaultCollection string
+}
+
+func newCSVReader(body io.Reader, defaultCollection string) (*csvReader, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV upload is empty: a header row with a text column is required")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	hasText := false
+	for i, name := range header {
+		columns[i] = strings.TrimSpace(name)
+		if strings.EqualFold(columns[i], "text") {
+			columns[i] = "text"
+			hasText = true
+		}
+	}
+	if !hasText {
+		return nil, fmt.Errorf("CSV header must have a text column")
+	}
+
+	return &csvReader{reader: reader, columns: columns, defaultCollection: defaultCollection}, nil
+}
+
+func (r *csvReader) next() (record, error) {
+	fields, err := r.reader.Read()
+	if err == io.EOF {
+		return record{}, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return record{line: parseErr.StartLine, err: fmt.Errorf("invalid CSV: %w", parseErr.Err)}, nil
+	}
+	if err != nil {
+		return record{}, fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	rec := record{line: line, row: Row{Collection: r.defaultCollection}}
+	for i, value := range fields {
+		if i >= len(r.columns) || r.columns[i] == "" {
+			continue
+		}
+		switch strings.ToLower(r.columns[i]) {
+		case "text":
+			rec.row.Text = value
+		case "collection":
+			if value != "" {
+				rec.row.Collection = value
+			}
+		case "metadata":
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			var metadata map[string]interface{}
+			if err := json.Unmarshal([]byte(value), &metadata); err != nil {
+				rec.err = fmt.Errorf("metadata must be a JSON object: %w", err)
+				return rec, nil
+			}
+			for k, v := range metadata {
+				setMetadata(&rec.row, k, v)
+			}
+		default:
+			if value != "" {
+				setMetadata(&rec.row, r.columns[i], value)
+			}
+		}
+	}
+	return rec, nil
+}
+
+func setMetadata(row *Row, key string, value interface{}) {
+	if row.Metadata == nil {
+		row.Metadata = map[string]interface{}{}
+	}
+	row.Metadata[key] = value
+}
+
+// Importer validates rows and stores them in batches
+type Importer struct {
+	Storage    storage.KnowledgeStorage
+	BatchSize  int            // Rows per batch (default DefaultBatchSize)
+	Reembed    bool           // Ignore exported vectors, e.g. after switching embedding models
+	OnProgress func(Progress) // Called after each batch
+
+	report  *Report
+	pending []record
+}
+
+// Run imports every row of reader, stopping early if ctx is cancelled. The
+// report is returned even on error: rows before the failure were imported.
+func (im *Importer) Run(ctx context.Context, format string, reader RowReader) (*Report, error) {
+	if im.BatchSize <= 0 {
+		im.BatchSize = DefaultBatchSize
+	}
+	im.report = &Report{
+		Format:      format,
+		Reembedded:  im.Reembed,
+		Collections: map[string]int{},
+		Errors:      []ImportError{},
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return im.report, fmt.Errorf("import cancelled: %w", err)
+		}
+
+		rec, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			im.flush()
+			return im.report, err
+		}
+
+		im.report.Processed++
+		if rec.err == nil {
+			rec.err = validateRow(rec.row)
+		}
+		if rec.err != nil {
+			im.fail(rec, rec.err)
+			continue
+		}
+		if im.Reembed {
+			rec.row.Vector = nil
+		}
+
+		im.pending = append(im.pending, rec)
+		if len(im.pending) >= im.BatchSize {
+			im.flush()
+		}
+	}
+
+	im.flush()
+	return im.report, nil
+}
+
+// validateRow applies the same rules as knowledge upserts
+func validateRow(row Row) error {
+	if row.Collection == "" {
+		return fmt.Errorf("collection is required (set it per row or with the collection parameter)")
+	}
+	if strings.TrimSpace(row.Text) == "" {
+		return fmt.Errorf("text is required and cannot be empty")
+	}
+	return nil
+}
+
+// flush stores the pending rows, one batch per collection
+func (im *Importer) flush() {
+	if len(im.pending) == 0 {
+		return
+	}
+
+	var order []string
+	groups := map[string][]record{}
+	for _, rec := range im.pending {
+		collection := rec.row.Collection
+		if _, ok := groups[collection]; !ok {
+			order = append(order, collection)
+		}
+		groups[collection] = append(groups[collection], rec)
+	}
+	im.pending = nil
+
+	batchStorage, canBatch := im.Storage.(storage.BatchKnowledgeStorage)
+	for _, collection := range order {
+		records := groups[collection]
+		if canBatch {
+			inputs := make([]storage.KnowledgeInput, len(records))
+			for i, rec := range records {
+				inputs[i] = storage.KnowledgeInput{Text: rec.row.Text, Metadata: rec.row.Metadata, Vector: rec.row.Vector}
+			}
+			if _, err := batchStorage.UpsertBatch(collection, inputs); err != nil {
+				for _, rec := range records {
+					im.fail(rec, err)
+				}
+				continue
+			}
+			im.report.Imported += len(records)
+			im.report.Collections[collection] += len(records)
+			continue
+		}
+
+		for _, rec := range records {
+			if _, err := im.Storage.Upsert(collection, rec.row.Text, rec.row.Metadata); err != nil {
+				im.fail(rec, err)
+				continue
+			}
+			im.report.Imported++
+			im.report.Collections[collection]++
+		}
+	}
+
+	im.report.Batches++
+	if im.OnProgress != nil {
+		im.OnProgress(im.report.Progress)
+	}
+}
+
+// fail records a row error, listing at most maxErrors of them
+func (im *Importer) fail(rec record, err error) {
+	im.report.Failed++
+	if len(im.report.Errors) >= maxErrors {
+		im.report.ErrorsTruncated = true
+		return
+	}
+	im.report.Errors = append(im.report.Errors, ImportError{
+		Row:        rec.line,
+		Collection: rec.row.Collection,
+		Error:      err.Error(),
+	})
+}
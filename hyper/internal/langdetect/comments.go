@@ -0,0 +1,80 @@
+package langdetect
+
+import "strings"
+
+// lineCommentMarkers start a comment running to the end of the line in the
+// languages the code indexer scans
+var lineCommentMarkers = []string{"//", "#", "--"}
+
+// blockComments pairs the openers and closers of block comments
+var blockComments = [][2]string{{"/*", "*/"}, {"<!--", "-->"}, {`"""`, `"""`}}
+
+// DetectComments returns the language of the comments in source code, or ""
+// when there are too few to tell. Identifiers and keywords are ignored, so a
+// Go file commented in German reports "de".
+func DetectComments(source string) string {
+	return Detect(Comments(source))
+}
+
+// Comments extracts the text of line and block comments from source code,
+// one comment per line. Markers only count at the start of a line or after
+// whitespace, so URLs and operators like "x--" are not mistaken for comments.
+func Comments(source string) string {
+	var comments []string
+	closer := ""
+	for _, line := range strings.Split(source, "\n") {
+		for line != "" {
+			if closer != "" {
+				end := strings.Index(line, closer)
+				if end < 0 {
+					comments = append(comments, strings.TrimLeft(strings.TrimSpace(line), "* "))
+					break
+				}
+				comments = append(comments, strings.TrimLeft(strings.TrimSpace(line[:end]), "* "))
+				line, closer = line[end+len(closer):], ""
+				continue
+			}
+
+			start, marker, blockCloser := commentStart(line)
+			if start < 0 {
+				break
+			}
+			line = line[start+len(marker):]
+			if blockCloser == "" {
+				comments = append(comments, strings.TrimSpace(strings.TrimLeft(line, marker[:1])))
+				break
+			}
+			closer = blockCloser
+		}
+	}
+	return strings.Join(comments, "\n")
+}
+
+// commentStart finds the first comment marker in line, returning its offset,
+// the marker and, for block comments, the matching closer
+func commentStart(line string) (int, string, string) {
+	start, marker, closer := -1, "", ""
+	consider := func(candidate, candidateCloser string) {
+		for offset := 0; offset < len(line); {
+			i := strings.Index(line[offset:], candidate)
+			if i < 0 {
+				return
+			}
+			i += offset
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				if start < 0 || i < start {
+					start, marker, closer = i, candidate, candidateCloser
+				}
+				return
+			}
+			offset = i + len(candidate)
+		}
+	}
+	for _, m := range lineCommentMarkers {
+		consider(m, "")
+	}
+	for _, block := range blockComments {
+		consider(block[0], block[1])
+	}
+	return start, marker, closer
+}
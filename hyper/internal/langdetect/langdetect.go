@@ -0,0 +1,167 @@
+// Package langdetect guesses the natural language of short texts such as
+// knowledge entries and code comments.
+//
+// Detection is deliberately small and dependency-free: non-Latin scripts are
+// recognized by their Unicode ranges, and Latin-script languages by counting
+// common function words. Languages are reported as ISO 639-1 codes; text that
+// is too short or too mixed to call yields "".
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// English is the code of the language the default embedding models are trained on
+const English = "en"
+
+// minStopwords is the number of function words a Latin-script text needs
+// before its language is reported
+const minStopwords = 2
+
+// stopwords lists frequent function words per Latin-script language. Words
+// shared between languages count for each of them.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "as", "was", "on", "are", "this", "be", "by", "not", "have", "from", "or", "an", "which", "you", "we", "should", "when", "if", "must", "use"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "den", "ein", "eine", "zu", "auf", "für", "von", "sich", "dem", "des", "auch", "es", "wir", "ich", "wenn", "oder", "werden", "wird", "bei", "nach", "kann", "muss", "sind"},
+	"fr": {"le", "la", "les", "et", "est", "des", "une", "un", "du", "pour", "pas", "que", "qui", "dans", "sur", "avec", "ce", "il", "nous", "vous", "sont", "au", "aux", "par", "mais", "ou", "cette", "être", "doit", "si"},
+	"es": {"el", "la", "los", "las", "y", "es", "que", "de", "en", "un", "una", "por", "para", "con", "no", "del", "se", "al", "como", "pero", "más", "este", "esta", "son", "está", "lo", "muy", "cuando", "si", "debe"},
+	"it": {"il", "lo", "la", "gli", "le", "e", "è", "di", "che", "un", "una", "per", "con", "non", "del", "della", "sono", "nel", "alla", "come", "ma", "questo", "questa", "anche", "più", "essere", "quando", "se", "deve", "da"},
+	"pt": {"o", "a", "os", "as", "e", "é", "de", "que", "um", "uma", "para", "com", "não", "do", "da", "em", "no", "na", "por", "mais", "como", "mas", "são", "está", "ao", "se", "quando", "isso", "deve", "dos"},
+	"nl": {"de", "het", "een", "en", "is", "van", "dat", "niet", "met", "voor", "op", "te", "zijn", "er", "ook", "maar", "als", "wordt", "bij", "aan", "dit", "deze", "naar", "kan", "wij", "ik", "worden", "heeft", "moet", "geen"},
+}
+
+// markers are letters that only occur in some Latin-script languages; each
+// word containing one adds to that language's score
+var markers = map[rune][]string{
+	'ß': {"de"}, 'ä': {"de"}, 'ö': {"de"}, 'ü': {"de"},
+	'ñ': {"es"}, 'á': {"es", "pt"}, 'ó': {"es", "pt"},
+	'ç': {"fr", "pt"}, 'œ': {"fr"}, 'ê': {"fr", "pt"}, 'è': {"fr", "it"}, 'à': {"fr", "it"},
+	'ã': {"pt"}, 'õ': {"pt"},
+	'ò': {"it"}, 'ì': {"it"},
+}
+
+// wordLanguages maps each stopword to the languages using it
+var wordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], lang)
+		}
+	}
+	return index
+}()
+
+// Detect returns the ISO 639-1 code of the language text is written in, or
+// "" when it cannot tell
+func Detect(text string) string {
+	if lang := detectScript(text); lang != "" {
+		return lang
+	}
+	return detectLatin(text)
+}
+
+// IsEnglish reports whether a detected language is handled like English:
+// English itself and text whose language is unknown
+func IsEnglish(lang string) bool {
+	return lang == "" || lang == English
+}
+
+// detectScript names the language of text mostly written in a non-Latin script
+func detectScript(text string) string {
+	var latin, other int
+	counts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+			continue
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			// Letters of the Ukrainian alphabet missing from Russian
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				counts["uk"]++
+			}
+			counts["ru"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		}
+		other++
+	}
+	if other == 0 || other < latin {
+		return ""
+	}
+
+	// Japanese mixes kana with Han characters, so any kana decides it
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for lang, count := range counts {
+		if lang == "uk" {
+			continue
+		}
+		if count > bestCount || (count == bestCount && lang < best) {
+			best, bestCount = lang, count
+		}
+	}
+	if best == "ru" && counts["uk"] > 0 {
+		return "uk"
+	}
+	return best
+}
+
+// detectLatin scores text against each Latin-script language's stopwords and
+// returns the single best language, if any
+func detectLatin(text string) string {
+	scores := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isWordSeparator) {
+		for _, lang := range wordLanguages[word] {
+			scores[lang]++
+		}
+		seen := make(map[string]bool)
+		for _, r := range word {
+			for _, lang := range markers[r] {
+				if !seen[lang] {
+					seen[lang] = true
+					scores[lang]++
+				}
+			}
+		}
+	}
+
+	best, bestScore, runnerUp := "", 0, 0
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, runnerUp = lang, score, bestScore
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	if bestScore < minStopwords || bestScore == runnerUp {
+		return ""
+	}
+	return best
+}
+
+// isWordSeparator splits words on anything but letters and apostrophes
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && r != '\''
+}
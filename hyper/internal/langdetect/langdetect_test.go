@@ -0,0 +1,70 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The cache is invalidated when the user updates the profile", "en"},
+		{"Der Cache wird ungültig, wenn der Benutzer sein Profil ändert und die Sitzung abläuft", "de"},
+		{"Le cache est invalidé lorsque l'utilisateur met à jour son profil dans la session", "fr"},
+		{"La caché se invalida cuando el usuario actualiza su perfil en la sesión", "es"},
+		{"La cache viene invalidata quando l'utente aggiorna il suo profilo nella sessione", "it"},
+		{"O cache é invalidado quando o usuário atualiza o perfil na sessão", "pt"},
+		{"De cache wordt ongeldig als de gebruiker het profiel bijwerkt", "nl"},
+		{"用户更新个人资料时缓存失效", "zh"},
+		{"ユーザーがプロフィールを更新するとキャッシュが無効になります", "ja"},
+		{"사용자가 프로필을 업데이트하면 캐시가 무효화됩니다", "ko"},
+		{"Кэш сбрасывается, когда пользователь обновляет профиль", "ru"},
+		{"Кеш скидається, коли користувач оновлює свій профіль", "uk"},
+		{"Η προσωρινή μνήμη ακυρώνεται όταν ο χρήστης ενημερώνει το προφίλ", "el"},
+		{"يتم إبطال ذاكرة التخزين المؤقت عندما يقوم المستخدم بتحديث ملفه", "ar"},
+		{"Redis caching", ""},
+		{"", ""},
+		{"1234 !!", ""},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.text); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestIsEnglish(t *testing.T) {
+	if !IsEnglish("") || !IsEnglish("en") || IsEnglish("de") {
+		t.Error("IsEnglish should hold for English and unknown text only")
+	}
+}
+
+func TestComments(t *testing.T) {
+	source := `package cache
+
+// Invalidate entfernt den Eintrag, wenn der Benutzer sich abmeldet
+func Invalidate(key string) {
+	url := "http://example.com" // und die Sitzung ist abgelaufen
+	/* Der Eintrag wird
+	 * nicht sofort gelöscht */
+	i--
+}
+`
+	want := "Invalidate entfernt den Eintrag, wenn der Benutzer sich abmeldet\n" +
+		"und die Sitzung ist abgelaufen\n" +
+		"Der Eintrag wird\n" +
+		"nicht sofort gelöscht"
+	if got := Comments(source); got != want {
+		t.Errorf("Comments() = %q, want %q", got, want)
+	}
+	if got := DetectComments(source); got != "de" {
+		t.Errorf("DetectComments() = %q, want de", got)
+	}
+
+	python := "def f():\n    \"\"\"Return the value of the cache entry.\"\"\"\n    return x  # fall back to the default\n"
+	if got := DetectComments(python); got != "en" {
+		t.Errorf("DetectComments(python) = %q, want en", got)
+	}
+	if got := DetectComments("x := a - b\nreturn x"); got != "" {
+		t.Errorf("code without comments = %q, want empty", got)
+	}
+}
@@ -0,0 +1,301 @@
+// Package linear mirrors human tasks to Linear issues, choosing the Linear
+// team by task project, and brings completions made in Linear back.
+package linear
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultAPIURL is Linear's GraphQL endpoint
+	defaultAPIURL = "https://api.linear.app/graphql"
+	// requestTimeout bounds a single Linear API request
+	requestTimeout = 30 * time.Second
+)
+
+// Config configures the Linear integration
+type Config struct {
+	APIURL       string
+	APIKey       string            // LINEAR_API_KEY (personal API key)
+	Teams        map[string]string // LINEAR_TEAMS: project to team key, e.g. "platform=ENG,mobile=MOB"
+	DefaultTeam  string            // LINEAR_DEFAULT_TEAM: team for tasks of other projects; empty skips them
+	PollInterval time.Duration     // LINEAR_POLL_INTERVAL (default 5m; 0 disables polling)
+}
+
+// LoadConfig reads the LINEAR_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		APIURL:       defaultAPIURL,
+		APIKey:       os.Getenv("LINEAR_API_KEY"),
+		Teams:        map[string]string{},
+		DefaultTeam:  strings.TrimSpace(os.Getenv("LINEAR_DEFAULT_TEAM")),
+		PollInterval: 5 * time.Minute,
+	}
+
+	if raw := os.Getenv("LINEAR_TEAMS"); raw != "" {
+		for _, pair := range strings.Split(raw, ",") {
+			project, team, ok := strings.Cut(pair, "=")
+			project, team = strings.TrimSpace(project), strings.TrimSpace(team)
+			if !ok || project == "" || team == "" {
+				return cfg, fmt.Errorf("invalid LINEAR_TEAMS entry %q: expected project=TEAMKEY", pair)
+			}
+			cfg.Teams[project] = team
+		}
+	}
+
+	if raw := os.Getenv("LINEAR_POLL_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval < 0 {
+			return cfg, fmt.Errorf("invalid LINEAR_POLL_INTERVAL %q: must be a duration such as 5m", raw)
+		}
+		cfg.PollInterval = interval
+	}
+	return cfg, nil
+}
+
+// Enabled reports whether the integration is configured
+func (c Config) Enabled() bool {
+	return c.APIKey != "" && (len(c.Teams) > 0 || c.DefaultTeam != "")
+}
+
+// TeamFor returns the key of the team a project's tasks are mirrored to
+func (c Config) TeamFor(project string) (string, bool) {
+	if team, ok := c.Teams[project]; ok {
+		return team, true
+	}
+	return c.DefaultTeam, c.DefaultTeam != ""
+}
+
+// TeamKeys lists every configured team key
+func (c Config) TeamKeys() []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, team := range append([]string{c.DefaultTeam}, mapValues(c.Teams)...) {
+		if team != "" && !seen[team] {
+			seen[team] = true
+			keys = append(keys, team)
+		}
+	}
+	return keys
+}
+
+func mapValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Workflow state types
+const (
+	StateBacklog   = "backlog"
+	StateUnstarted = "unstarted"
+	StateStarted   = "started"
+	StateCompleted = "completed"
+	StateCanceled  = "canceled"
+)
+
+// State is a workflow state of a team
+type State struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Position float64 `json:"position"`
+}
+
+// Team is a Linear team and its workflow states
+type Team struct {
+	ID     string
+	Key    string
+	States []State
+}
+
+// Issue is the part of a Linear issue the sync needs
+type Issue struct {
+	ID         string `json:"id"`
+	Identifier string `json:"identifier"`
+	State      State  `json:"state"`
+}
+
+// Client calls the Linear GraphQL API
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+// NewClient creates a Linear API client
+func NewClient(cfg Config) *Client {
+	return &Client{cfg: cfg, httpClient: &http.Client{Timeout: requestTimeout}}
+}
+
+// Team looks up a team and its workflow states by key
+func (c *Client) Team(ctx context.Context, key string) (*Team, error) {
+	const query = `query Team($key: String!) {
+  teams(filter: {key: {eq: $key}}) {
+    nodes { id key states { nodes { id name type position } } }
+  }
+}`
+	var data struct {
+		Teams struct {
+			Nodes []struct {
+				ID     string `json:"id"`
+				Key    string `json:"key"`
+				States struct {
+					Nodes []State `json:"nodes"`
+				} `json:"states"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}
+	if err := c.do(ctx, query, map[string]interface{}{"key": key}, &data); err != nil {
+		return nil, fmt.Errorf("failed to look up Linear team %s: %w", key, err)
+	}
+	if len(data.Teams.Nodes) == 0 {
+		return nil, fmt.Errorf("linear team %s not found", key)
+	}
+
+	node := data.Teams.Nodes[0]
+	return &Team{ID: node.ID, Key: node.Key, States: node.States.Nodes}, nil
+}
+
+// CreateIssue creates an issue in a team
+func (c *Client) CreateIssue(ctx context.Context, teamID, title, description string) (*Issue, error) {
+	const mutation = `mutation CreateIssue($input: IssueCreateInput!) {
+  issueCreate(input: $input) { success issue { id identifier state { id name type position } } }
+}`
+	var data struct {
+		IssueCreate struct {
+			Success bool   `json:"success"`
+			Issue   *Issue `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	input := map[string]interface{}{"teamId": teamID, "title": title, "description": description}
+	if err := c.do(ctx, mutation, map[string]interface{}{"input": input}, &data); err != nil {
+		return nil, fmt.Errorf("failed to create Linear issue: %w", err)
+	}
+	if !data.IssueCreate.Success || data.IssueCreate.Issue == nil {
+		return nil, fmt.Errorf("failed to create Linear issue: not created")
+	}
+	return data.IssueCreate.Issue, nil
+}
+
+// GetIssue returns an issue's current state
+func (c *Client) GetIssue(ctx context.Context, id string) (*Issue, error) {
+	const query = `query Issue($id: String!) {
+  issue(id: $id) { id identifier state { id name type position } }
+}`
+	var data struct {
+		Issue *Issue `json:"issue"`
+	}
+	if err := c.do(ctx, query, map[string]interface{}{"id": id}, &data); err != nil {
+		return nil, fmt.Errorf("failed to get Linear issue %s: %w", id, err)
+	}
+	if data.Issue == nil {
+		return nil, fmt.Errorf("linear issue %s not found", id)
+	}
+	return data.Issue, nil
+}
+
+// SetState moves an issue to a workflow state
+func (c *Client) SetState(ctx context.Context, issueID, stateID string) error {
+	const mutation = `mutation SetState($id: String!, $stateId: String!) {
+  issueUpdate(id: $id, input: {stateId: $stateId}) { success }
+}`
+	var data struct {
+		IssueUpdate struct {
+			Success bool `json:"success"`
+		} `json:"issueUpdate"`
+	}
+	if err := c.do(ctx, mutation, map[string]interface{}{"id": issueID, "stateId": stateID}, &data); err != nil {
+		return fmt.Errorf("failed to update Linear issue %s: %w", issueID, err)
+	}
+	if !data.IssueUpdate.Success {
+		return fmt.Errorf("failed to update Linear issue %s: not updated", issueID)
+	}
+	return nil
+}
+
+// UpdatedSince returns the issues of the given teams updated at or after since
+func (c *Client) UpdatedSince(ctx context.Context, teamKeys []string, since time.Time) ([]*Issue, error) {
+	const query = `query Updated($teams: [String!], $since: DateTimeOrDuration!, $after: String) {
+  issues(first: 100, after: $after, filter: {team: {key: {in: $teams}}, updatedAt: {gte: $since}}) {
+    nodes { id identifier state { id name type position } }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+	var issues []*Issue
+	var after interface{}
+	for {
+		var data struct {
+			Issues struct {
+				Nodes    []*Issue `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"issues"`
+		}
+		variables := map[string]interface{}{
+			"teams": teamKeys,
+			"since": since.UTC().Format(time.RFC3339),
+			"after": after,
+		}
+		if err := c.do(ctx, query, variables, &data); err != nil {
+			return nil, fmt.Errorf("failed to list updated Linear issues: %w", err)
+		}
+
+		issues = append(issues, data.Issues.Nodes...)
+		if !data.Issues.PageInfo.HasNextPage || data.Issues.PageInfo.EndCursor == "" {
+			return issues, nil
+		}
+		after = data.Issues.PageInfo.EndCursor
+	}
+}
+
+// do runs a GraphQL operation and decodes its data into out
+func (c *Client) do(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.APIURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.cfg.APIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("linear returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("linear returned error: %s", result.Errors[0].Message)
+	}
+	return json.Unmarshal(result.Data, out)
+}
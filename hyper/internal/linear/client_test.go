@@ -0,0 +1,93 @@
+package linear
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("LINEAR_API_KEY", "lin_api_x")
+	t.Setenv("LINEAR_TEAMS", "platform=ENG, mobile = MOB")
+	t.Setenv("LINEAR_DEFAULT_TEAM", "")
+	t.Setenv("LINEAR_POLL_INTERVAL", "")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.Enabled())
+	assert.Equal(t, map[string]string{"platform": "ENG", "mobile": "MOB"}, cfg.Teams)
+	assert.Equal(t, 5*time.Minute, cfg.PollInterval)
+
+	team, ok := cfg.TeamFor("mobile")
+	assert.True(t, ok)
+	assert.Equal(t, "MOB", team)
+	_, ok = cfg.TeamFor("")
+	assert.False(t, ok, "unmapped projects are skipped without a default team")
+
+	cfg.DefaultTeam = "OPS"
+	team, ok = cfg.TeamFor("")
+	assert.True(t, ok)
+	assert.Equal(t, "OPS", team)
+	assert.ElementsMatch(t, []string{"OPS", "ENG", "MOB"}, cfg.TeamKeys())
+
+	t.Setenv("LINEAR_TEAMS", "platform")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "lin_api_x", r.Header.Get("Authorization"))
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		switch {
+		case strings.HasPrefix(req.Query, "query Team"):
+			assert.Equal(t, "ENG", req.Variables["key"])
+			w.Write([]byte(`{"data":{"teams":{"nodes":[{"id":"team-1","key":"ENG","states":{"nodes":[{"id":"s-done","name":"Done","type":"completed","position":3}]}}]}}}`))
+		case strings.HasPrefix(req.Query, "mutation CreateIssue"):
+			input := req.Variables["input"].(map[string]interface{})
+			assert.Equal(t, "team-1", input["teamId"])
+			w.Write([]byte(`{"data":{"issueCreate":{"success":true,"issue":{"id":"iss-1","identifier":"ENG-1","state":{"type":"unstarted"}}}}}`))
+		case strings.HasPrefix(req.Query, "query Updated"):
+			if req.Variables["after"] == nil {
+				w.Write([]byte(`{"data":{"issues":{"nodes":[{"id":"iss-1","identifier":"ENG-1","state":{"type":"completed"}}],"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}`))
+				return
+			}
+			w.Write([]byte(`{"data":{"issues":{"nodes":[{"id":"iss-2","identifier":"ENG-2","state":{"type":"started"}}],"pageInfo":{"hasNextPage":false}}}}`))
+		default:
+			w.Write([]byte(`{"errors":[{"message":"Entity not found"}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(Config{APIURL: srv.URL, APIKey: "lin_api_x"})
+	ctx := context.Background()
+
+	team, err := client.Team(ctx, "ENG")
+	require.NoError(t, err)
+	assert.Equal(t, "team-1", team.ID)
+	assert.Equal(t, []State{{ID: "s-done", Name: "Done", Type: StateCompleted, Position: 3}}, team.States)
+
+	issue, err := client.CreateIssue(ctx, team.ID, "Ship digests", "details")
+	require.NoError(t, err)
+	assert.Equal(t, "ENG-1", issue.Identifier)
+
+	issues, err := client.UpdatedSince(ctx, []string{"ENG"}, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, StateCompleted, issues[0].State.Type)
+
+	_, err = client.GetIssue(ctx, "missing")
+	assert.ErrorContains(t, err, "Entity not found")
+}
@@ -0,0 +1,302 @@
+package linear
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// maxTitleChars keeps issue titles readable
+const maxTitleChars = 255
+
+// issueAPI is the Linear API the sync uses (implemented by Client)
+type issueAPI interface {
+	Team(ctx context.Context, key string) (*Team, error)
+	CreateIssue(ctx context.Context, teamID, title, description string) (*Issue, error)
+	GetIssue(ctx context.Context, id string) (*Issue, error)
+	SetState(ctx context.Context, issueID, stateID string) error
+	UpdatedSince(ctx context.Context, teamKeys []string, since time.Time) ([]*Issue, error)
+}
+
+// Sync mirrors human tasks of the configured projects to Linear issues,
+// moves the issues along as task statuses change, and completes tasks whose
+// issues were completed in Linear
+type Sync struct {
+	cfg    Config
+	api    issueAPI
+	tasks  storage.TaskStorage // Unwrapped storage, so updates from Linear are not echoed back
+	links  storage.LinearLinkStorage
+	logger *zap.Logger
+
+	async    func(func()) // Runs outbound calls off the request path
+	lastPoll time.Time
+
+	mu    sync.Mutex
+	teams map[string]*Team // By key, looked up on first use
+}
+
+// NewSync creates a Linear sync for tasks, which must support Linear links
+func NewSync(cfg Config, tasks storage.TaskStorage, logger *zap.Logger) (*Sync, error) {
+	links, ok := tasks.(storage.LinearLinkStorage)
+	if !ok {
+		return nil, fmt.Errorf("task storage does not support Linear links")
+	}
+	return &Sync{
+		cfg:    cfg,
+		api:    NewClient(cfg),
+		tasks:  tasks,
+		links:  links,
+		logger: logger,
+		async:  func(f func()) { go f() },
+		teams:  map[string]*Team{},
+	}, nil
+}
+
+// Mirror wraps a task storage so that task creation and status changes are
+// pushed to Linear
+func (s *Sync) Mirror(tasks storage.TaskStorage) storage.TaskStorage {
+	return &mirroredTaskStorage{TaskStorage: tasks, sync: s}
+}
+
+// mirroredTaskStorage pushes human task changes to Linear
+type mirroredTaskStorage struct {
+	storage.TaskStorage
+	sync *Sync
+}
+
+// Unwrap returns the wrapped storage, for reads that bypass the wrapper
+func (m *mirroredTaskStorage) Unwrap() storage.TaskStorage {
+	return m.TaskStorage
+}
+
+// CreateHumanTask creates the task and, in the background, its Linear issue
+func (m *mirroredTaskStorage) CreateHumanTask(prompt string) (*storage.HumanTask, error) {
+	task, err := m.TaskStorage.CreateHumanTask(prompt)
+	if err != nil {
+		return nil, err
+	}
+	m.sync.async(func() { m.sync.createIssue(task) })
+	return task, nil
+}
+
+// CloneHumanTask clones the task tree and creates a Linear issue for the clone
+func (m *mirroredTaskStorage) CloneHumanTask(sourceTaskID, project, prompt string) (*storage.HumanTask, []*storage.AgentTask, error) {
+	task, agentTasks, err := m.TaskStorage.CloneHumanTask(sourceTaskID, project, prompt)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.sync.async(func() { m.sync.createIssue(task) })
+	return task, agentTasks, nil
+}
+
+// UpdateTaskStatus updates the task and moves its Linear issue
+func (m *mirroredTaskStorage) UpdateTaskStatus(taskID string, status storage.TaskStatus, notes string) error {
+	if err := m.TaskStorage.UpdateTaskStatus(taskID, status, notes); err != nil {
+		return err
+	}
+	m.sync.async(func() { m.sync.pushStatus(taskID, status) })
+	return nil
+}
+
+// team returns a team with its workflow states, cached after the first lookup
+func (s *Sync) team(ctx context.Context, key string) (*Team, error) {
+	s.mu.Lock()
+	team, ok := s.teams[key]
+	s.mu.Unlock()
+	if ok {
+		return team, nil
+	}
+
+	team, err := s.api.Team(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.teams[key] = team
+	s.mu.Unlock()
+	return team, nil
+}
+
+// createIssue creates the Linear issue of a human task in its project's team
+// and links it. Tasks of unmapped projects are skipped.
+func (s *Sync) createIssue(task *storage.HumanTask) {
+	teamKey, ok := s.cfg.TeamFor(task.Project)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	team, err := s.team(ctx, teamKey)
+	if err != nil {
+		s.logger.Warn("Failed to look up Linear team", zap.String("team", teamKey), zap.Error(err))
+		return
+	}
+
+	description := fmt.Sprintf("%s\n\nHyperion task: `%s`", task.Prompt, task.ID)
+	issue, err := s.api.CreateIssue(ctx, team.ID, issueTitle(task.Prompt), description)
+	if err != nil {
+		s.logger.Warn("Failed to create Linear issue for task", zap.String("taskId", task.ID), zap.Error(err))
+		return
+	}
+	if err := s.links.SetLinearIssue(task.ID, issue.ID, issue.Identifier); err != nil {
+		s.logger.Warn("Failed to link task to Linear issue", zap.String("taskId", task.ID), zap.String("issue", issue.Identifier), zap.Error(err))
+		return
+	}
+	s.logger.Info("Created Linear issue for task", zap.String("taskId", task.ID), zap.String("issue", issue.Identifier))
+}
+
+// pushStatus moves the Linear issue of a human task to a state matching its
+// status
+func (s *Sync) pushStatus(taskID string, status storage.TaskStatus) {
+	task, err := s.tasks.GetHumanTask(taskID)
+	if err != nil || task.LinearIssueID == "" {
+		return // Agent task or unlinked human task
+	}
+	teamKey, ok := s.cfg.TeamFor(task.Project)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	issue, err := s.api.GetIssue(ctx, task.LinearIssueID)
+	if err != nil {
+		s.logger.Warn("Failed to read Linear issue", zap.String("issue", task.LinearIssueKey), zap.Error(err))
+		return
+	}
+	stateType := StateTypeFor(status)
+	if issue.State.Type == stateType {
+		return // Already in step, e.g. the completion came from Linear
+	}
+
+	team, err := s.team(ctx, teamKey)
+	if err != nil {
+		s.logger.Warn("Failed to look up Linear team", zap.String("team", teamKey), zap.Error(err))
+		return
+	}
+	state, ok := firstState(team.States, stateType)
+	if !ok {
+		s.logger.Warn("Linear team has no state of type",
+			zap.String("team", teamKey),
+			zap.String("type", stateType))
+		return
+	}
+	if err := s.api.SetState(ctx, issue.ID, state.ID); err != nil {
+		s.logger.Warn("Failed to move Linear issue", zap.String("issue", task.LinearIssueKey), zap.Error(err))
+	}
+}
+
+// StateTypeFor maps a task status to a Linear workflow state type. Linear
+// has no blocked type, so blocked tasks stay started.
+func StateTypeFor(status storage.TaskStatus) string {
+	switch status {
+	case storage.TaskStatusCompleted:
+		return StateCompleted
+	case storage.TaskStatusInProgress, storage.TaskStatusBlocked:
+		return StateStarted
+	default:
+		return StateUnstarted
+	}
+}
+
+// firstState returns the first state of a type in workflow order
+func firstState(states []State, stateType string) (State, bool) {
+	var best State
+	found := false
+	for _, state := range states {
+		if state.Type == stateType && (!found || state.Position < best.Position) {
+			best, found = state, true
+		}
+	}
+	return best, found
+}
+
+// ApplyIssue completes the human task linked to an issue completed in Linear.
+// It reports whether the task changed; other states and unlinked issues are
+// ignored, since only completion is mirrored back.
+func (s *Sync) ApplyIssue(issue *Issue) (bool, error) {
+	if issue.State.Type != StateCompleted {
+		return false, nil
+	}
+	task, err := s.links.GetHumanTaskByLinearIssue(issue.ID)
+	if err != nil || task == nil || task.Status == storage.TaskStatusCompleted {
+		return false, err
+	}
+
+	if err := s.tasks.UpdateTaskStatus(task.ID, storage.TaskStatusCompleted, ""); err != nil {
+		return false, err
+	}
+	s.logger.Info("Completed task from Linear",
+		zap.String("issue", issue.Identifier),
+		zap.String("taskId", task.ID))
+	return true, nil
+}
+
+// Start polls Linear for updated issues until ctx is cancelled
+func (s *Sync) Start(ctx context.Context) {
+	if s.cfg.PollInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.PollInterval)
+		defer ticker.Stop()
+
+		s.logger.Info("Linear polling started", zap.Duration("interval", s.cfg.PollInterval))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Poll(ctx); err != nil {
+					s.logger.Warn("Linear poll failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Poll applies every issue of the configured teams updated since the last poll
+func (s *Sync) Poll(ctx context.Context) error {
+	started := time.Now()
+	since := s.lastPoll
+	if since.IsZero() {
+		since = started.Add(-s.cfg.PollInterval)
+	}
+
+	issues, err := s.api.UpdatedSince(ctx, s.cfg.TeamKeys(), since)
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		if _, err := s.ApplyIssue(issue); err != nil {
+			s.logger.Warn("Failed to apply Linear issue", zap.String("issue", issue.Identifier), zap.Error(err))
+		}
+	}
+	s.lastPoll = started
+	return nil
+}
+
+// issueTitle is the first line of the prompt, cut to maxTitleChars
+func issueTitle(prompt string) string {
+	title := strings.TrimSpace(prompt)
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = strings.TrimSpace(title[:i])
+	}
+	runes := []rune(title)
+	if len(runes) > maxTitleChars {
+		title = string(runes[:maxTitleChars-3]) + "..."
+	}
+	if title == "" {
+		title = "Hyperion task"
+	}
+	return title
+}
@@ -0,0 +1,189 @@
+package linear
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryTasks keeps human tasks and their Linear links in memory
+type memoryTasks struct {
+	storage.TaskStorage
+	human map[string]*storage.HumanTask
+}
+
+func (m *memoryTasks) CreateHumanTask(prompt string) (*storage.HumanTask, error) {
+	task := &storage.HumanTask{ID: fmt.Sprintf("h-%d", len(m.human)+1), Prompt: prompt, Status: storage.TaskStatusPending}
+	m.human[task.ID] = task
+	return task, nil
+}
+
+func (m *memoryTasks) CloneHumanTask(sourceTaskID, project, prompt string) (*storage.HumanTask, []*storage.AgentTask, error) {
+	task, err := m.CreateHumanTask(prompt)
+	if err != nil {
+		return nil, nil, err
+	}
+	task.Project = project
+	return task, nil, nil
+}
+
+func (m *memoryTasks) GetHumanTask(id string) (*storage.HumanTask, error) {
+	if task, ok := m.human[id]; ok {
+		return task, nil
+	}
+	return nil, fmt.Errorf("human task with ID %s not found", id)
+}
+
+func (m *memoryTasks) UpdateTaskStatus(id string, status storage.TaskStatus, notes string) error {
+	task, err := m.GetHumanTask(id)
+	if err != nil {
+		return err
+	}
+	task.Status = status
+	return nil
+}
+
+func (m *memoryTasks) SetLinearIssue(id, issueID, issueKey string) error {
+	task, err := m.GetHumanTask(id)
+	if err != nil {
+		return err
+	}
+	task.LinearIssueID, task.LinearIssueKey = issueID, issueKey
+	return nil
+}
+
+func (m *memoryTasks) GetHumanTaskByLinearIssue(issueID string) (*storage.HumanTask, error) {
+	for _, task := range m.human {
+		if task.LinearIssueID == issueID {
+			return task, nil
+		}
+	}
+	return nil, nil
+}
+
+// fakeLinear is an in-memory Linear workspace
+type fakeLinear struct {
+	teams   map[string]*Team
+	issues  map[string]*Issue
+	lookups int
+	updated []*Issue
+}
+
+func (f *fakeLinear) Team(ctx context.Context, key string) (*Team, error) {
+	f.lookups++
+	if team, ok := f.teams[key]; ok {
+		return team, nil
+	}
+	return nil, fmt.Errorf("linear team %s not found", key)
+}
+
+func (f *fakeLinear) CreateIssue(ctx context.Context, teamID, title, description string) (*Issue, error) {
+	n := len(f.issues) + 1
+	issue := &Issue{ID: fmt.Sprintf("iss-%d", n), Identifier: fmt.Sprintf("%s-%d", teamID, n), State: State{ID: "todo", Type: StateUnstarted}}
+	f.issues[issue.ID] = issue
+	return issue, nil
+}
+
+func (f *fakeLinear) GetIssue(ctx context.Context, id string) (*Issue, error) {
+	issue, ok := f.issues[id]
+	if !ok {
+		return nil, fmt.Errorf("linear issue %s not found", id)
+	}
+	copied := *issue
+	return &copied, nil
+}
+
+func (f *fakeLinear) SetState(ctx context.Context, issueID, stateID string) error {
+	for _, team := range f.teams {
+		for _, state := range team.States {
+			if state.ID == stateID {
+				f.issues[issueID].State = state
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("unknown state %s", stateID)
+}
+
+func (f *fakeLinear) UpdatedSince(ctx context.Context, teamKeys []string, since time.Time) ([]*Issue, error) {
+	return f.updated, nil
+}
+
+func newTestSync() (*Sync, *memoryTasks, *fakeLinear) {
+	tasks := &memoryTasks{human: map[string]*storage.HumanTask{}}
+	api := &fakeLinear{
+		teams: map[string]*Team{"ENG": {ID: "ENG", Key: "ENG", States: []State{
+			{ID: "todo", Type: StateUnstarted, Position: 1},
+			{ID: "review", Type: StateStarted, Position: 3},
+			{ID: "doing", Type: StateStarted, Position: 2},
+			{ID: "done", Type: StateCompleted, Position: 4},
+		}}},
+		issues: map[string]*Issue{},
+	}
+	sync := &Sync{
+		cfg:    Config{Teams: map[string]string{"platform": "ENG"}, PollInterval: time.Minute},
+		api:    api,
+		tasks:  tasks,
+		links:  tasks,
+		logger: zap.NewNop(),
+		async:  func(f func()) { f() },
+		teams:  map[string]*Team{},
+	}
+	return sync, tasks, api
+}
+
+func TestMirroredTaskStorage(t *testing.T) {
+	sync, tasks, api := newTestSync()
+	mirrored := sync.Mirror(tasks)
+
+	// Tasks outside mapped projects are not mirrored
+	unmapped, err := mirrored.CreateHumanTask("Local experiment")
+	require.NoError(t, err)
+	assert.Empty(t, tasks.human[unmapped.ID].LinearIssueID)
+
+	task, _, err := mirrored.CloneHumanTask(unmapped.ID, "platform", "Ship digests")
+	require.NoError(t, err)
+	assert.Equal(t, "iss-1", tasks.human[task.ID].LinearIssueID)
+	assert.Equal(t, "ENG-1", tasks.human[task.ID].LinearIssueKey)
+
+	require.NoError(t, mirrored.UpdateTaskStatus(task.ID, storage.TaskStatusInProgress, ""))
+	assert.Equal(t, "doing", api.issues["iss-1"].State.ID, "first started state in workflow order")
+	require.NoError(t, mirrored.UpdateTaskStatus(task.ID, storage.TaskStatusCompleted, ""))
+	assert.Equal(t, "done", api.issues["iss-1"].State.ID)
+	assert.Equal(t, 1, api.lookups, "team states are cached")
+}
+
+func TestApplyIssue(t *testing.T) {
+	sync, tasks, api := newTestSync()
+	tasks.human["h-1"] = &storage.HumanTask{ID: "h-1", Project: "platform", Status: storage.TaskStatusInProgress, LinearIssueID: "iss-1"}
+
+	// Only completion is mirrored back
+	updated, err := sync.ApplyIssue(&Issue{ID: "iss-1", State: State{Type: StateCanceled}})
+	require.NoError(t, err)
+	assert.False(t, updated)
+	assert.Equal(t, storage.TaskStatusInProgress, tasks.human["h-1"].Status)
+
+	api.updated = []*Issue{
+		{ID: "iss-1", Identifier: "ENG-1", State: State{Type: StateCompleted}},
+		{ID: "iss-9", Identifier: "ENG-9", State: State{Type: StateCompleted}},
+	}
+	require.NoError(t, sync.Poll(context.Background()))
+	assert.Equal(t, storage.TaskStatusCompleted, tasks.human["h-1"].Status)
+
+	updated, err = sync.ApplyIssue(api.updated[0])
+	require.NoError(t, err)
+	assert.False(t, updated, "already completed")
+}
+
+func TestStateTypeFor(t *testing.T) {
+	assert.Equal(t, StateUnstarted, StateTypeFor(storage.TaskStatusPending))
+	assert.Equal(t, StateStarted, StateTypeFor(storage.TaskStatusBlocked))
+	assert.Equal(t, StateCompleted, StateTypeFor(storage.TaskStatusCompleted))
+}
@@ -0,0 +1,93 @@
+package embeddings
+
+import "math"
+
+// bytesPerToken approximates how many bytes of source text make up one
+// embedding token. Code tokenizes denser than prose, so estimates err high.
+const bytesPerToken = 4
+
+// Pricing is the embedding price of one provider model
+type Pricing struct {
+	Provider            string  `json:"provider"`
+	Model               string  `json:"model"`
+	USDPerMillionTokens float64 `json:"usdPerMillionTokens"`
+}
+
+// CostEstimate is the estimated price of embedding a number of tokens
+type CostEstimate struct {
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"`
+	EstimatedTokens  int64   `json:"estimatedTokens"`
+	EstimatedCostUSD float64 `json:"estimatedCostUsd"`
+}
+
+// DefaultPricing lists the default model of each supported provider. Local
+// providers cost nothing beyond compute.
+var DefaultPricing = []Pricing{
+	{Provider: "ollama", Model: "nomic-embed-text", USDPerMillionTokens: 0},
+	{Provider: "local", Model: "nomic-embed-text-v1.5", USDPerMillionTokens: 0},
+	{Provider: "openai", Model: "text-embedding-3-small", USDPerMillionTokens: 0.02},
+	{Provider: "voyage", Model: "voyage-3", USDPerMillionTokens: 0.06},
+}
+
+// voyageModelPricing holds the list price of each Voyage model in USD per million tokens
+var voyageModelPricing = map[string]float64{
+	"voyage-3":        0.06,
+	"voyage-3.5":      0.06,
+	"voyage-3.5-lite": 0.02,
+	"voyage-3-large":  0.18,
+	"voyage-code-3":   0.18,
+}
+
+// EstimateTokens approximates the number of embedding tokens in text
+func EstimateTokens(text string) int64 {
+	return BytesToTokens(int64(len(text)))
+}
+
+// BytesToTokens approximates the number of embedding tokens in n bytes of text
+func BytesToTokens(n int64) int64 {
+	return (n + bytesPerToken - 1) / bytesPerToken
+}
+
+// Estimate prices tokens with this model, rounded to a hundredth of a cent
+func (p Pricing) Estimate(tokens int64) CostEstimate {
+	cost := float64(tokens) / 1e6 * p.USDPerMillionTokens
+	return CostEstimate{
+		Provider:         p.Provider,
+		Model:            p.Model,
+		EstimatedTokens:  tokens,
+		EstimatedCostUSD: math.Round(cost*10000) / 10000,
+	}
+}
+
+// EstimateAll prices tokens with the default model of every provider
+func EstimateAll(tokens int64) []CostEstimate {
+	estimates := make([]CostEstimate, 0, len(DefaultPricing))
+	for _, pricing := range DefaultPricing {
+		estimates = append(estimates, pricing.Estimate(tokens))
+	}
+	return estimates
+}
+
+// PricingFor returns the pricing of the model a client embeds with
+func PricingFor(client EmbeddingClient) Pricing {
+	switch c := client.(type) {
+	case *OpenAIClient:
+		return DefaultPricing[2]
+	case *VoyageClient:
+		price, ok := voyageModelPricing[c.model]
+		if !ok {
+			price = DefaultPricing[3].USDPerMillionTokens
+		}
+		return Pricing{Provider: "voyage", Model: c.model, USDPerMillionTokens: price}
+	case *OllamaClient:
+		return Pricing{Provider: "ollama", Model: c.model}
+	case *TEIClient:
+		return DefaultPricing[1]
+	case *LanguageRouter:
+		// Most text is English, so price by the primary model
+		return PricingFor(c.primary)
+	}
+	// Embedded llama.cpp and test clients run locally
+	return Pricing{Provider: "local"}
+}
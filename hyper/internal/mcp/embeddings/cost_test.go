@@ -0,0 +1,36 @@
+package embeddings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, int64(0), EstimateTokens(""))
+	assert.Equal(t, int64(1), EstimateTokens("abc"))
+	assert.Equal(t, int64(2), EstimateTokens("abcdefgh"))
+	assert.Equal(t, int64(250_000), BytesToTokens(1_000_000))
+}
+
+func TestPricingEstimate(t *testing.T) {
+	openai := DefaultPricing[2]
+	estimate := openai.Estimate(50_000_000)
+	assert.Equal(t, "openai", estimate.Provider)
+	assert.Equal(t, int64(50_000_000), estimate.EstimatedTokens)
+	assert.InDelta(t, 1.0, estimate.EstimatedCostUSD, 1e-9)
+
+	assert.Len(t, EstimateAll(1000), len(DefaultPricing))
+}
+
+func TestPricingFor(t *testing.T) {
+	assert.Equal(t, "openai", PricingFor(&OpenAIClient{}).Provider)
+
+	voyage := PricingFor(NewVoyageClientWithModel("key", "voyage-code-3"))
+	assert.Equal(t, "voyage-code-3", voyage.Model)
+	assert.Equal(t, 0.18, voyage.USDPerMillionTokens)
+
+	ollama := PricingFor(&OllamaClient{model: "mxbai-embed-large"})
+	assert.Equal(t, "mxbai-embed-large", ollama.Model)
+	assert.Zero(t, ollama.USDPerMillionTokens)
+}
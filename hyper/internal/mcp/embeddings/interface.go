@@ -1,14 +1,46 @@
 package embeddings
 
-// EmbeddingClient is the interface for embedding generation services
-// Implemented by both OpenAIClient and TEIClient
-type EmbeddingClient interface {
-	// CreateEmbedding generates a single embedding vector for the given text
-	CreateEmbedding(text string) ([]float32, error)
+import (
+	"context"
 
-	// CreateEmbeddings generates embedding vectors for multiple texts
-	CreateEmbeddings(texts []string) ([][]float32, error)
+	"hyper/internal/priority"
+	"hyper/pkg/embedding"
+)
 
-	// GetDimensions returns the number of dimensions for the embedding model
-	GetDimensions() int
+// EmbeddingClient is the interface for embedding generation services,
+// implemented by the clients in this package. It is embedding.Client, so
+// clients built outside the module can be used wherever one is taken.
+type EmbeddingClient = embedding.Client
+
+// CreateEmbeddingContext embeds text at ctx's priority (see package priority):
+// interactive callers hold bulk indexing back, bulk callers first yield to
+// them. It returns ctx's error once its deadline passes, leaving the request
+// to finish within the client's own timeout.
+func CreateEmbeddingContext(ctx context.Context, client EmbeddingClient, text string) ([]float32, error) {
+	done, err := priority.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if ctx.Done() == nil {
+		return client.CreateEmbedding(text)
+	}
+
+	type outcome struct {
+		vector []float32
+		err    error
+	}
+	result := make(chan outcome, 1)
+	go func() {
+		vector, err := client.CreateEmbedding(text)
+		result <- outcome{vector: vector, err: err}
+	}()
+
+	select {
+	case out := <-result:
+		return out.vector, out.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
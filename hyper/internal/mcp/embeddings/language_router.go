@@ -0,0 +1,84 @@
+package embeddings
+
+import (
+	"fmt"
+
+	"hyper/internal/langdetect"
+)
+
+// LanguageRouter embeds English text, and text whose language is unknown,
+// with a primary model and every other language with a multilingual model.
+// Vectors of the two models are not comparable: stores tag each point with
+// its detected language so searches only compare vectors of the same model.
+type LanguageRouter struct {
+	primary      EmbeddingClient
+	multilingual EmbeddingClient
+}
+
+// NewLanguageRouter routes non-English text to multilingual. Both models
+// must produce vectors of the same dimension, since they share collections.
+func NewLanguageRouter(primary, multilingual EmbeddingClient) (*LanguageRouter, error) {
+	if primary.GetDimensions() != multilingual.GetDimensions() {
+		return nil, fmt.Errorf("multilingual model has %d dimensions, primary model has %d", multilingual.GetDimensions(), primary.GetDimensions())
+	}
+	return &LanguageRouter{primary: primary, multilingual: multilingual}, nil
+}
+
+// ClientFor returns the client that embeds text of a language
+func (r *LanguageRouter) ClientFor(language string) EmbeddingClient {
+	if langdetect.IsEnglish(language) {
+		return r.primary
+	}
+	return r.multilingual
+}
+
+// CreateEmbedding embeds text with the model for its detected language
+func (r *LanguageRouter) CreateEmbedding(text string) ([]float32, error) {
+	return r.ClientFor(langdetect.Detect(text)).CreateEmbedding(text)
+}
+
+// CreateEmbeddings embeds texts in at most two batches, one per model,
+// returning vectors in the order of texts
+func (r *LanguageRouter) CreateEmbeddings(texts []string) ([][]float32, error) {
+	var primaryTexts, multilingualTexts []string
+	var primaryIdx, multilingualIdx []int
+	for i, text := range texts {
+		if langdetect.IsEnglish(langdetect.Detect(text)) {
+			primaryTexts = append(primaryTexts, text)
+			primaryIdx = append(primaryIdx, i)
+		} else {
+			multilingualTexts = append(multilingualTexts, text)
+			multilingualIdx = append(multilingualIdx, i)
+		}
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, batch := range []struct {
+		client  EmbeddingClient
+		texts   []string
+		indices []int
+	}{
+		{r.primary, primaryTexts, primaryIdx},
+		{r.multilingual, multilingualTexts, multilingualIdx},
+	} {
+		if len(batch.texts) == 0 {
+			continue
+		}
+		embedded, err := batch.client.CreateEmbeddings(batch.texts)
+		if err != nil {
+			return nil, err
+		}
+		if len(embedded) != len(batch.texts) {
+			return nil, fmt.Errorf("got %d embeddings for %d texts", len(embedded), len(batch.texts))
+		}
+		for j, i := range batch.indices {
+			vectors[i] = embedded[j]
+		}
+	}
+	return vectors, nil
+}
+
+// GetDimensions returns the dimension shared by both models
+func (r *LanguageRouter) GetDimensions() int {
+	return r.primary.GetDimensions()
+}
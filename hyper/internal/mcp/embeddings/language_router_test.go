@@ -0,0 +1,79 @@
+package embeddings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// modelClient embeds every text as a vector holding its model's marker
+type modelClient struct {
+	marker     float32
+	dimensions int
+	batches    [][]string
+}
+
+func (c *modelClient) CreateEmbedding(text string) ([]float32, error) {
+	vectors, err := c.CreateEmbeddings([]string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func (c *modelClient) CreateEmbeddings(texts []string) ([][]float32, error) {
+	c.batches = append(c.batches, texts)
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = make([]float32, c.dimensions)
+		vectors[i][0] = c.marker
+	}
+	return vectors, nil
+}
+
+func (c *modelClient) GetDimensions() int {
+	return c.dimensions
+}
+
+func TestLanguageRouterRejectsDimensionMismatch(t *testing.T) {
+	_, err := NewLanguageRouter(&modelClient{dimensions: 768}, &modelClient{dimensions: 1024})
+	assert.ErrorContains(t, err, "1024 dimensions")
+}
+
+func TestLanguageRouterRoutesByLanguage(t *testing.T) {
+	primary := &modelClient{marker: 1, dimensions: 4}
+	multilingual := &modelClient{marker: 2, dimensions: 4}
+	router, err := NewLanguageRouter(primary, multilingual)
+	require.NoError(t, err)
+
+	vector, err := router.CreateEmbedding("The session expires when the token is revoked")
+	require.NoError(t, err)
+	assert.Equal(t, float32(1), vector[0])
+
+	vector, err = router.CreateEmbedding("Die Sitzung läuft ab, wenn das Token widerrufen wird")
+	require.NoError(t, err)
+	assert.Equal(t, float32(2), vector[0])
+
+	texts := []string{
+		"Die Sitzung läuft ab, wenn das Token widerrufen wird",
+		"The session expires when the token is revoked",
+		"redis",
+		"トークンが取り消されるとセッションが期限切れになります",
+	}
+	vectors, err := router.CreateEmbeddings(texts)
+	require.NoError(t, err)
+	require.Len(t, vectors, len(texts))
+	markers := make([]float32, len(vectors))
+	for i, v := range vectors {
+		markers[i] = v[0]
+	}
+	assert.Equal(t, []float32{2, 1, 1, 2}, markers)
+	assert.Equal(t, []string{texts[1], texts[2]}, primary.batches[len(primary.batches)-1])
+	assert.Equal(t, []string{texts[0], texts[3]}, multilingual.batches[len(multilingual.batches)-1])
+
+	assert.Equal(t, 4, router.GetDimensions())
+	assert.Same(t, multilingual, router.ClientFor("fr"))
+	assert.Same(t, primary, router.ClientFor(""))
+	assert.Equal(t, "ollama", PricingFor(&LanguageRouter{primary: &OllamaClient{model: "m"}}).Provider)
+}
@@ -13,10 +13,8 @@ import (
 // Ollama runs llama.cpp with GPU acceleration (Metal/CUDA/Vulkan) as a local service
 // No CGO required - uses REST API
 type OllamaClient struct {
-	baseURL    string
-	model      string
-	dimensions int
-	httpClient *http.Client
+	model string
+	clientOptions
 }
 
 // OllamaEmbeddingRequest represents the request to Ollama embeddings API
@@ -33,7 +31,8 @@ type OllamaEmbeddingResponse struct {
 // NewOllamaClient creates a new Ollama embedding client
 // baseURL: Ollama API endpoint (default: http://localhost:11434)
 // model: embedding model to use (default: nomic-embed-text)
-func NewOllamaClient(baseURL, model string) (*OllamaClient, error) {
+// WithDimensions skips the test embedding that detects dimensions.
+func NewOllamaClient(baseURL, model string, opts ...Option) (*OllamaClient, error) {
 	if baseURL == "" {
 		baseURL = "http://localhost:11434"
 	}
@@ -42,12 +41,8 @@ func NewOllamaClient(baseURL, model string) (*OllamaClient, error) {
 	}
 
 	client := &OllamaClient{
-		baseURL:    baseURL,
-		model:      model,
-		dimensions: 0, // Will be auto-detected
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		model:         model,
+		clientOptions: newClientOptions(baseURL, 30*time.Second, 0, opts), // Dimensions will be auto-detected
 	}
 
 	// Test connection to Ollama
@@ -56,6 +51,9 @@ func NewOllamaClient(baseURL, model string) (*OllamaClient, error) {
 	}
 
 	// Auto-detect embedding dimensions by making a test embedding call
+	if client.dimensionsSet {
+		return client, nil
+	}
 	if err := client.detectDimensions(); err != nil {
 		return nil, fmt.Errorf("failed to detect embedding dimensions: %w", err)
 	}
@@ -65,7 +63,13 @@ func NewOllamaClient(baseURL, model string) (*OllamaClient, error) {
 
 // testConnection verifies Ollama is running and accessible
 func (c *OllamaClient) testConnection() error {
-	resp, err := c.httpClient.Get(c.baseURL + "/api/tags")
+	req, err := http.NewRequest("GET", c.baseURL+"/api/tags", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("Ollama not reachable at %s: %w", c.baseURL, err)
 	}
@@ -111,6 +115,7 @@ func (c *OllamaClient) CreateEmbedding(text string) ([]float32, error) {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	c.setHeaders(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -11,9 +11,8 @@ import (
 
 // OpenAIClient handles communication with OpenAI API for embeddings
 type OpenAIClient struct {
-	apiKey     string
-	httpClient *http.Client
-	baseURL    string
+	apiKey string
+	clientOptions
 }
 
 // EmbeddingRequest represents the request payload for OpenAI embeddings API
@@ -21,6 +20,7 @@ type EmbeddingRequest struct {
 	Input          interface{} `json:"input"` // Can be string or []string
 	Model          string      `json:"model"`
 	EncodingFormat string      `json:"encoding_format,omitempty"`
+	Dimensions     int         `json:"dimensions,omitempty"` // Shortens text-embedding-3 vectors
 }
 
 // EmbeddingResponse represents the response from OpenAI embeddings API
@@ -38,14 +38,12 @@ type EmbeddingResponse struct {
 	} `json:"usage"`
 }
 
-// NewOpenAIClient creates a new OpenAI client for embeddings
-func NewOpenAIClient(apiKey string) *OpenAIClient {
+// NewOpenAIClient creates a new OpenAI client for embeddings. WithBaseURL
+// points it at an OpenAI-compatible gateway.
+func NewOpenAIClient(apiKey string, opts ...Option) *OpenAIClient {
 	return &OpenAIClient{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		baseURL: "https://api.openai.com/v1",
+		apiKey:        apiKey,
+		clientOptions: newClientOptions("https://api.openai.com/v1", 30*time.Second, 1536, opts), // text-embedding-3-small dimension
 	}
 }
 
@@ -72,6 +70,9 @@ func (c *OpenAIClient) CreateEmbeddings(texts []string) ([][]float32, error) {
 		Model:          "text-embedding-3-small", // 1536 dimensions, cost-effective
 		EncodingFormat: "float",
 	}
+	if c.dimensionsSet {
+		reqBody.Dimensions = c.dimensions
+	}
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
@@ -85,6 +86,7 @@ func (c *OpenAIClient) CreateEmbeddings(texts []string) ([][]float32, error) {
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	c.setHeaders(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -116,5 +118,5 @@ func (c *OpenAIClient) CreateEmbeddings(texts []string) ([][]float32, error) {
 
 // GetDimensions returns the number of dimensions for the embedding model
 func (c *OpenAIClient) GetDimensions() int {
-	return 1536 // text-embedding-3-small dimension
+	return c.dimensions
 }
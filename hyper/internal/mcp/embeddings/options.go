@@ -0,0 +1,98 @@
+package embeddings
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures an HTTP embedding client (Ollama, TEI, OpenAI, Voyage)
+// in place of its defaults, e.g. to go through a proxy or gateway
+type Option func(*clientOptions)
+
+// clientOptions are the settings Options change
+type clientOptions struct {
+	baseURL    string
+	timeout    time.Duration
+	httpClient *http.Client
+	dimensions int
+	headers    http.Header
+
+	timeoutSet    bool // WithTimeout was given
+	dimensionsSet bool // WithDimensions was given
+}
+
+// WithBaseURL sends requests to url instead of the provider's endpoint, such
+// as an OpenAI-compatible gateway. The clients' usual paths (/embeddings,
+// /embed, /api/embeddings) are appended to it.
+func WithBaseURL(url string) Option {
+	return func(o *clientOptions) {
+		o.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithTimeout bounds each request, in place of the client's default
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+		o.timeoutSet = true
+	}
+}
+
+// WithHTTPClient sends requests with client, e.g. one with a proxy or custom
+// TLS configuration. WithTimeout still applies, to a copy of client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithDimensions overrides the vector size the client reports. OpenAI and
+// Voyage also ask the model for vectors of that size; Ollama skips detecting
+// it with a test embedding.
+func WithDimensions(dimensions int) Option {
+	return func(o *clientOptions) {
+		o.dimensions = dimensions
+		o.dimensionsSet = true
+	}
+}
+
+// WithHeader adds a header to every request, such as the credentials of an
+// authenticating proxy
+func WithHeader(name, value string) Option {
+	return func(o *clientOptions) {
+		if o.headers == nil {
+			o.headers = http.Header{}
+		}
+		o.headers.Add(name, value)
+	}
+}
+
+// newClientOptions applies opts over a client's defaults
+func newClientOptions(baseURL string, timeout time.Duration, dimensions int, opts []Option) clientOptions {
+	o := clientOptions{baseURL: baseURL, timeout: timeout, dimensions: dimensions}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// A client passed without WithTimeout keeps its own timeout
+	if o.httpClient != nil && !o.timeoutSet {
+		return o
+	}
+	client := &http.Client{}
+	if o.httpClient != nil {
+		copied := *o.httpClient
+		client = &copied
+	}
+	client.Timeout = o.timeout
+	o.httpClient = client
+	return o
+}
+
+// setHeaders adds the WithHeader headers to req
+func (o *clientOptions) setHeaders(req *http.Request) {
+	for name, values := range o.headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+}
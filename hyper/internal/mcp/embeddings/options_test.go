@@ -0,0 +1,89 @@
+package embeddings
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIClient_Options(t *testing.T) {
+	var request EmbeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/proxy/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		assert.Equal(t, "team-a", r.Header.Get("X-Gateway-Team"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Write([]byte(`{"data":[{"embedding":[0.1,0.2],"index":0}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("key",
+		WithBaseURL(server.URL+"/proxy/v1/"),
+		WithHeader("X-Gateway-Team", "team-a"),
+		WithDimensions(2))
+
+	vector, err := client.CreateEmbedding("hello")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.1, 0.2}, vector)
+	assert.Equal(t, 2, request.Dimensions)
+	assert.Equal(t, 2, client.GetDimensions())
+
+	assert.Equal(t, 1536, NewOpenAIClient("key").GetDimensions())
+}
+
+func TestVoyageClient_Options(t *testing.T) {
+	var request VoyageRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Write([]byte(`{"data":[{"embedding":[1,0],"index":0}]}`))
+	}))
+	defer server.Close()
+
+	client := NewVoyageClientWithModel("key", "voyage-3.5-lite", WithBaseURL(server.URL), WithDimensions(256))
+	_, err := client.CreateEmbedding("hello")
+	require.NoError(t, err)
+	assert.Equal(t, 256, request.OutputDimension)
+	assert.Equal(t, 256, client.GetDimensions())
+
+	assert.Equal(t, 512, NewVoyageClientWithModel("key", "voyage-3.5-lite").GetDimensions())
+}
+
+func TestOllamaClient_DimensionsSkipDetection(t *testing.T) {
+	embedded := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("Proxy-Authorization"))
+		if r.URL.Path == "/api/embeddings" {
+			embedded = true
+		}
+		w.Write([]byte(`{"models":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewOllamaClient(server.URL, "", WithDimensions(768), WithHeader("Proxy-Authorization", "secret"))
+	require.NoError(t, err)
+	assert.Equal(t, 768, client.GetDimensions())
+	assert.False(t, embedded, "no test embedding should be made")
+}
+
+func TestNewClientOptions_HTTPClient(t *testing.T) {
+	custom := &http.Client{Timeout: 5 * time.Second}
+
+	// A client given without WithTimeout is used as-is
+	o := newClientOptions("", 30*time.Second, 0, []Option{WithHTTPClient(custom)})
+	assert.Same(t, custom, o.httpClient)
+
+	// WithTimeout applies to a copy, leaving the caller's client alone
+	o = newClientOptions("", 30*time.Second, 0, []Option{WithHTTPClient(custom), WithTimeout(time.Minute)})
+	assert.NotSame(t, custom, o.httpClient)
+	assert.Equal(t, time.Minute, o.httpClient.Timeout)
+	assert.Equal(t, 5*time.Second, custom.Timeout)
+
+	o = newClientOptions("", 30*time.Second, 0, nil)
+	assert.Equal(t, 30*time.Second, o.httpClient.Timeout)
+}
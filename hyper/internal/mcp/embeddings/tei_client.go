@@ -12,9 +12,7 @@ import (
 // TEIClient handles communication with Hugging Face Text Embeddings Inference (TEI) service
 // Compatible with models like nomic-ai/nomic-embed-text-v1.5
 type TEIClient struct {
-	baseURL    string
-	httpClient *http.Client
-	dimensions int
+	clientOptions
 }
 
 // TEIRequest represents the request payload for TEI embeddings API
@@ -27,13 +25,11 @@ type TEIResponse [][]float32
 
 // NewTEIClient creates a new TEI client for embeddings
 // baseURL should be like "http://embedding-service:8080" (no /embed suffix)
-func NewTEIClient(baseURL string) *TEIClient {
+func NewTEIClient(baseURL string, opts ...Option) *TEIClient {
 	return &TEIClient{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second, // Increased for CPU-based TEI inference (can take 30s+ with queue)
-		},
-		dimensions: 768, // nomic-embed-text-v1.5 dimension
+		// 60s for CPU-based TEI inference (can take 30s+ with queue);
+		// 768 is the nomic-embed-text-v1.5 dimension
+		clientOptions: newClientOptions(baseURL, 60*time.Second, 768, opts),
 	}
 }
 
@@ -70,6 +66,7 @@ func (c *TEIClient) CreateEmbeddings(texts []string) ([][]float32, error) {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	c.setHeaders(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -12,17 +12,17 @@ import (
 // VoyageClient handles communication with Voyage AI embeddings API
 // Anthropic's recommended embedding provider
 type VoyageClient struct {
-	apiKey     string
-	httpClient *http.Client
-	model      string
-	dimensions int
+	apiKey string
+	model  string
+	clientOptions
 }
 
 // VoyageRequest represents the request payload for Voyage AI embeddings API
 type VoyageRequest struct {
-	Input     []string `json:"input"`
-	Model     string   `json:"model"`
-	InputType string   `json:"input_type"` // "document" for code chunks
+	Input           []string `json:"input"`
+	Model           string   `json:"model"`
+	InputType       string   `json:"input_type"`                 // "document" for code chunks
+	OutputDimension int      `json:"output_dimension,omitempty"` // Smaller vectors, for models that support them
 }
 
 // VoyageResponse is the response from Voyage AI
@@ -40,22 +40,22 @@ type VoyageResponse struct {
 
 // NewVoyageClient creates a new Voyage AI client for embeddings
 // Uses voyage-3 model by default (1024 dimensions, best price/performance)
-func NewVoyageClient(apiKey string) *VoyageClient {
+func NewVoyageClient(apiKey string, opts ...Option) *VoyageClient {
 	return &VoyageClient{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		model:      "voyage-3",    // Best price/performance: $0.06/1M tokens
-		dimensions: 1024,           // voyage-3 dimensions
+		apiKey:        apiKey,
+		model:         "voyage-3",                                                                  // Best price/performance: $0.06/1M tokens
+		clientOptions: newClientOptions("https://api.voyageai.com/v1", 30*time.Second, 1024, opts), // voyage-3 dimensions
 	}
 }
 
 // NewVoyageClientWithModel creates a Voyage AI client with a specific model
 // Available models: voyage-3, voyage-3-large, voyage-3.5, voyage-3.5-lite, voyage-code-3
-func NewVoyageClientWithModel(apiKey, model string) *VoyageClient {
-	client := NewVoyageClient(apiKey)
+func NewVoyageClientWithModel(apiKey, model string, opts ...Option) *VoyageClient {
+	client := NewVoyageClient(apiKey, opts...)
 	client.model = model
+	if client.dimensionsSet {
+		return client
+	}
 
 	// Set dimensions based on model
 	switch model {
@@ -97,19 +97,23 @@ func (c *VoyageClient) CreateEmbeddings(texts []string) ([][]float32, error) {
 		Model:     c.model,
 		InputType: "document", // For code chunks (vs "query" for search queries)
 	}
+	if c.dimensionsSet {
+		reqBody.OutputDimension = c.dimensions
+	}
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api.voyageai.com/v1/embeddings", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequest("POST", c.baseURL+"/embeddings", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	c.setHeaders(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	adrCollection      = "adr"
+	maxADRTasks        = 10
+	maxADRKnowledge    = 30   // Knowledge entries offered to the LLM
+	maxADRPassageChars = 1500 // Longer notes and entries are truncated in the prompt
+	maxADRTitleLength  = 120
+	adrDraftTimeout    = 2 * time.Minute
+	adrDraftedByTool   = "coordinator_draft_adr"
+	adrDecisionApprove = "approve"
+	adrDecisionReject  = "reject"
+	adrStatusProposed  = "proposed"
+	adrStatusAccepted  = "accepted"
+	adrStatusRejected  = "rejected"
+)
+
+// adrSections are the sections every drafted ADR must have
+var adrSections = []string{"Context", "Decision", "Consequences"}
+
+// adrSystemPrompt keeps drafted ADRs to what the agents actually recorded
+const adrSystemPrompt = `You write Architecture Decision Records from the notes and knowledge agents recorded while completing tasks.
+Write markdown with exactly this structure:
+# <short title naming the decision>
+## Context
+## Decision
+## Consequences
+Use only the material provided. State the decision that was made, not a recommendation.
+List positive and negative consequences. If the material shows no clear decision, say so in the Decision section.
+Be concise.`
+
+// registerDraftADR registers the coordinator_draft_adr tool
+func (h *ToolHandler) registerDraftADR(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_draft_adr",
+		Description: "Draft an Architecture Decision Record (context, decision, consequences) from completed agent tasks, using their notes and summaries and the knowledge written for them, with the configured LLM. The draft is stored in the adr collection with status 'proposed' and approval 'pending', and stays out of digests until a human approves it with coordinator_review_adr.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"agentTaskIds": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: fmt.Sprintf("Completed agent task UUIDs the decision was made in (max %d)", maxADRTasks),
+				},
+				"title": {
+					Type:        "string",
+					Description: "Optional: title of the decision; drafted by the LLM when omitted",
+				},
+			},
+			Required: []string{"agentTaskIds"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleDraftADR(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleDraftADR handles the coordinator_draft_adr tool call
+func (h *ToolHandler) handleDraftADR(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	taskIDs := adrTaskIDs(args["agentTaskIds"])
+	if len(taskIDs) == 0 {
+		return createCodedErrorResult(errcode.Validation, "agentTaskIds parameter is required and must list at least one agent task"), nil, nil
+	}
+	if len(taskIDs) > maxADRTasks {
+		return createCodedErrorResult(errcode.Validation, fmt.Sprintf("agentTaskIds must not list more than %d tasks", maxADRTasks)), nil, nil
+	}
+	title := strings.TrimSpace(getStringField(args, "title", ""))
+
+	tasks := make([]*storage.AgentTask, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		task, err := h.taskStorage.GetAgentTask(id)
+		if err != nil {
+			return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("agent task not found: %s", id)), nil, nil
+		}
+		if task.Status != storage.TaskStatusCompleted {
+			return createCodedErrorResult(errcode.Validation, fmt.Sprintf("agent task %s must be completed before drafting an ADR (status: %s)", id, task.Status)), nil, nil
+		}
+		tasks = append(tasks, task)
+	}
+
+	if h.answerGenerator == nil {
+		return createCodedErrorResult(errcode.DependencyUnavailable,
+			"ADR drafting unavailable: no LLM configured (set AI_PROVIDER and its API key)"), nil, nil
+	}
+
+	knowledge, warnings := h.adrKnowledge(ctx, tasks)
+
+	llmCtx, cancel := context.WithTimeout(ctx, adrDraftTimeout)
+	defer cancel()
+	draft, err := h.answerGenerator.Complete(llmCtx, adrSystemPrompt, adrPrompt(title, tasks, knowledge))
+	if err != nil {
+		return createCodedErrorResult(errcode.DependencyUnavailable, fmt.Sprintf("failed to draft ADR: %s", err.Error())), nil, nil
+	}
+	draft = strings.TrimSpace(draft)
+	if draft == "" {
+		return createCodedErrorResult(errcode.DependencyUnavailable, "failed to draft ADR: the LLM returned an empty response"), nil, nil
+	}
+	if title == "" {
+		title = adrTitle(draft)
+	}
+	for _, section := range adrSections {
+		if !strings.Contains(draft, "## "+section) {
+			warnings = append(warnings, fmt.Sprintf("the draft has no %q section; edit it before approving", section))
+		}
+	}
+
+	humanTaskIDs := []string{}
+	for _, task := range tasks {
+		if !slices.Contains(humanTaskIDs, task.HumanTaskID) {
+			humanTaskIDs = append(humanTaskIDs, task.HumanTaskID)
+		}
+	}
+	knowledgeIDs := make([]string, len(knowledge))
+	for i, entry := range knowledge {
+		knowledgeIDs[i] = entry.ID
+	}
+	metadata := map[string]interface{}{
+		"knowledgeType":             "adr",
+		"title":                     title,
+		"status":                    adrStatusProposed,
+		storage.ApprovalMetadataKey: storage.ApprovalPending,
+		"draftedBy":                 adrDraftedByTool,
+		"agentTaskIds":              taskIDs,
+		"humanTaskIds":              humanTaskIDs,
+		"sourceKnowledgeIds":        knowledgeIDs,
+		"tags":                      []string{"architecture", "decision", "draft"},
+	}
+
+	entry, err := h.knowledgeStorage.Upsert(adrCollection, draft, metadata)
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to store ADR draft: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"success":    true,
+		"id":         entry.ID,
+		"collection": adrCollection,
+		"title":      title,
+		"status":     adrStatusProposed,
+		"approval":   storage.ApprovalPending,
+		"draft":      draft,
+		"sources": map[string]interface{}{
+			"agentTaskIds": taskIDs,
+			"knowledgeIds": knowledgeIDs,
+		},
+		"message": "ADR drafted and stored pending human approval; review it with coordinator_review_adr",
+	}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// adrKnowledge lists the knowledge written for the tasks, most recent last.
+// Storages that cannot list it leave the draft to the task notes.
+func (h *ToolHandler) adrKnowledge(ctx context.Context, tasks []*storage.AgentTask) ([]*storage.KnowledgeEntry, []string) {
+	finder, ok := h.knowledgeStorage.(storage.RunKnowledgeFinder)
+	if !ok {
+		return []*storage.KnowledgeEntry{}, []string{"knowledge: this knowledge storage cannot list a task's entries; drafted from task notes only"}
+	}
+
+	var taskIDs, agentNames []string
+	from, until := tasks[0].CreatedAt, tasks[0].UpdatedAt
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+		if task.AgentName != "" && !slices.Contains(agentNames, task.AgentName) {
+			agentNames = append(agentNames, task.AgentName)
+		}
+		if task.CreatedAt.Before(from) {
+			from = task.CreatedAt
+		}
+		if task.UpdatedAt.After(until) {
+			until = task.UpdatedAt
+		}
+	}
+
+	entries, err := finder.KnowledgeForRun(ctx, taskIDs, agentNames, from, until, maxADRKnowledge)
+	if err != nil {
+		return []*storage.KnowledgeEntry{}, []string{"knowledge: " + err.Error()}
+	}
+	// Earlier drafts are not evidence for a new one
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.Metadata["draftedBy"] != adrDraftedByTool {
+			kept = append(kept, entry)
+		}
+	}
+	return kept, nil
+}
+
+// adrPrompt lists each task's notes and the knowledge written for them
+func adrPrompt(title string, tasks []*storage.AgentTask, knowledge []*storage.KnowledgeEntry) string {
+	var prompt strings.Builder
+	if title != "" {
+		fmt.Fprintf(&prompt, "Decision title: %s\n\n", title)
+	}
+	prompt.WriteString("Completed tasks:\n\n")
+	for _, task := range tasks {
+		fmt.Fprintf(&prompt, "## Task %s (%s): %s\n", task.ID, task.AgentName, task.Role)
+		for _, part := range []struct{ label, text string }{
+			{"Context", task.ContextSummary},
+			{"Prior work", task.PriorWorkSummary},
+			{"Notes", task.Notes},
+			{"Human guidance", task.HumanPromptNotes},
+		} {
+			if strings.TrimSpace(part.text) != "" {
+				fmt.Fprintf(&prompt, "%s: %s\n", part.label, truncateText(strings.TrimSpace(part.text), maxADRPassageChars))
+			}
+		}
+		for _, todo := range task.Todos {
+			fmt.Fprintf(&prompt, "- TODO %s (%s)", todo.Description, todo.Status)
+			if todo.Notes != "" {
+				fmt.Fprintf(&prompt, ": %s", truncateText(todo.Notes, maxADRPassageChars))
+			}
+			prompt.WriteString("\n")
+		}
+		if len(task.FilesModified) > 0 {
+			fmt.Fprintf(&prompt, "Files modified: %s\n", strings.Join(task.FilesModified, ", "))
+		}
+		prompt.WriteString("\n")
+	}
+
+	if len(knowledge) > 0 {
+		prompt.WriteString("Knowledge written during the tasks:\n\n")
+		for _, entry := range knowledge {
+			fmt.Fprintf(&prompt, "- (collection: %s) %s\n", entry.Collection, truncateText(entry.Text, maxADRPassageChars))
+		}
+	}
+	return prompt.String()
+}
+
+// adrTitle takes the title from the draft's first heading
+func adrTitle(draft string) string {
+	for _, line := range strings.Split(draft, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return truncateText(strings.TrimSpace(strings.TrimPrefix(line, "# ")), maxADRTitleLength)
+		}
+	}
+	return truncateText(strings.TrimSpace(strings.SplitN(draft, "\n", 2)[0]), maxADRTitleLength)
+}
+
+// adrTaskIDs reads the agentTaskIds argument, dropping blanks and duplicates
+func adrTaskIDs(raw interface{}) []string {
+	items, _ := raw.([]interface{})
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		id, _ := item.(string)
+		id = strings.TrimSpace(id)
+		if id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// registerReviewADR registers the coordinator_review_adr tool
+func (h *ToolHandler) registerReviewADR(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_review_adr",
+		Description: "Approve or reject an ADR drafted by coordinator_draft_adr. Approving sets its status to 'accepted'; rejecting sets it to 'rejected'. Only drafts still pending approval can be reviewed.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"id": {
+					Type:        "string",
+					Description: "ID of the ADR draft in the adr collection",
+				},
+				"decision": {
+					Type:        "string",
+					Enum:        []interface{}{adrDecisionApprove, adrDecisionReject},
+					Description: "'approve' or 'reject'",
+				},
+				"reviewer": {
+					Type:        "string",
+					Description: "Optional: who reviewed the draft",
+				},
+				"notes": {
+					Type:        "string",
+					Description: "Optional: review notes, e.g. why it was rejected",
+				},
+			},
+			Required: []string{"id", "decision"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleReviewADR(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleReviewADR handles the coordinator_review_adr tool call
+func (h *ToolHandler) handleReviewADR(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	id := strings.TrimSpace(getStringField(args, "id", ""))
+	if id == "" {
+		return createCodedErrorResult(errcode.Validation, "id parameter is required"), nil, nil
+	}
+
+	set := map[string]interface{}{"reviewedAt": time.Now().UTC().Format(time.RFC3339)}
+	switch getStringField(args, "decision", "") {
+	case adrDecisionApprove:
+		set["status"] = adrStatusAccepted
+		set[storage.ApprovalMetadataKey] = storage.ApprovalApproved
+		set["decidedAt"] = time.Now().UTC().Format("2006-01-02")
+	case adrDecisionReject:
+		set["status"] = adrStatusRejected
+		set[storage.ApprovalMetadataKey] = storage.ApprovalRejected
+	default:
+		return createCodedErrorResult(errcode.Validation, "decision must be 'approve' or 'reject'"), nil, nil
+	}
+	if reviewer := strings.TrimSpace(getStringField(args, "reviewer", "")); reviewer != "" {
+		set["reviewedBy"] = reviewer
+	}
+	if notes := strings.TrimSpace(getStringField(args, "notes", "")); notes != "" {
+		set["reviewNotes"] = notes
+	}
+
+	updater, ok := h.knowledgeStorage.(storage.KnowledgeMetadataUpdater)
+	if !ok {
+		return createCodedErrorResult(errcode.DependencyUnavailable, "ADR review unavailable: this knowledge storage cannot update entries"), nil, nil
+	}
+	entry, err := updater.UpdateKnowledgeMetadata(ctx, adrCollection, id, map[string]interface{}{storage.ApprovalMetadataKey: storage.ApprovalPending}, set)
+	if err != nil {
+		if errcode.Classify(err.Error()) == errcode.NotFound {
+			return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("no ADR draft pending approval: %s", id)), nil, nil
+		}
+		return createErrorResult(fmt.Sprintf("failed to review ADR: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"id":       entry.ID,
+		"title":    entry.Metadata["title"],
+		"status":   set["status"],
+		"approval": set[storage.ApprovalMetadataKey],
+	}
+	return structuredToolResult(response), response, nil
+}
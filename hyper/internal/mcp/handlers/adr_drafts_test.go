@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// adrTaskStorage serves fixed agent tasks; other TaskStorage methods are not called
+type adrTaskStorage struct {
+	storage.TaskStorage
+	tasks map[string]*storage.AgentTask
+}
+
+func (s *adrTaskStorage) GetAgentTask(id string) (*storage.AgentTask, error) {
+	if task, ok := s.tasks[id]; ok {
+		return task, nil
+	}
+	return nil, assert.AnError
+}
+
+// reviewKnowledgeStorage lists every stored entry as a run's knowledge and
+// updates their metadata
+type reviewKnowledgeStorage struct {
+	MockKnowledgeStorage
+}
+
+func (m *reviewKnowledgeStorage) KnowledgeForRun(ctx context.Context, taskIDs, agentNames []string, from, until time.Time, limit int) ([]*storage.KnowledgeEntry, error) {
+	return append([]*storage.KnowledgeEntry{}, m.entries...), nil
+}
+
+func (m *reviewKnowledgeStorage) UpdateKnowledgeMetadata(ctx context.Context, collection, id string, match, set map[string]interface{}) (*storage.KnowledgeEntry, error) {
+	for _, entry := range m.entries {
+		if entry.ID != id || entry.Collection != collection {
+			continue
+		}
+		for key, value := range match {
+			if entry.Metadata[key] != value {
+				return nil, assert.AnError
+			}
+		}
+		for key, value := range set {
+			entry.Metadata[key] = value
+		}
+		return entry, nil
+	}
+	return nil, assert.AnError
+}
+
+func newADRTestHandler(generator AnswerGenerator) (*ToolHandler, *reviewKnowledgeStorage) {
+	knowledge := &reviewKnowledgeStorage{}
+	knowledge.entries = []*storage.KnowledgeEntry{{ID: "tk-1", Collection: "technical-knowledge", Text: "NATS handled 50k msg/s in the benchmark"}}
+	h := NewToolHandler(nil, knowledge, nil)
+	h.taskStorage = &adrTaskStorage{tasks: map[string]*storage.AgentTask{
+		"a-1": {ID: "a-1", HumanTaskID: "h-1", AgentName: "go-dev", Role: "Pick a queue", Status: storage.TaskStatusCompleted, Notes: "Chose NATS over Kafka: no ZooKeeper to run"},
+		"a-2": {ID: "a-2", HumanTaskID: "h-1", AgentName: "go-dev", Role: "Wire consumers", Status: storage.TaskStatusInProgress},
+	}}
+	if generator != nil {
+		h.SetAnswerGenerator(generator)
+	}
+	return h, knowledge
+}
+
+func TestHandleDraftADR(t *testing.T) {
+	generator := &fakeAnswerGenerator{answer: "# Use NATS for events\n\n## Context\nWe need a queue.\n\n## Decision\nNATS.\n\n## Consequences\nNo ZooKeeper."}
+	h, knowledge := newADRTestHandler(generator)
+
+	result, _, err := h.handleDraftADR(context.Background(), map[string]interface{}{"agentTaskIds": []interface{}{"a-1"}})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, generator.prompt, "Chose NATS over Kafka")
+	assert.Contains(t, generator.prompt, "NATS handled 50k msg/s")
+
+	require.Len(t, knowledge.entries, 2)
+	draft := knowledge.entries[1]
+	assert.Equal(t, "adr", draft.Collection)
+	assert.Equal(t, "Use NATS for events", draft.Metadata["title"])
+	assert.Equal(t, "proposed", draft.Metadata["status"])
+	assert.Equal(t, storage.ApprovalPending, draft.Metadata[storage.ApprovalMetadataKey])
+	assert.Equal(t, []string{"a-1"}, draft.Metadata["agentTaskIds"])
+	assert.Equal(t, []string{"tk-1"}, draft.Metadata["sourceKnowledgeIds"])
+	assert.NotContains(t, result.StructuredContent.(map[string]interface{}), "warnings")
+}
+
+func TestHandleDraftADRRejectsUnfinishedTasks(t *testing.T) {
+	h, _ := newADRTestHandler(&fakeAnswerGenerator{})
+
+	result, _, err := h.handleDraftADR(context.Background(), map[string]interface{}{"agentTaskIds": []interface{}{"a-1", "a-2"}})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Equal(t, errcode.Validation, errorCode(t, result))
+
+	result, _, err = h.handleDraftADR(context.Background(), map[string]interface{}{"agentTaskIds": []interface{}{"a-9"}})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.NotFound, errorCode(t, result))
+}
+
+func TestHandleDraftADRWithoutLLM(t *testing.T) {
+	h, knowledge := newADRTestHandler(nil)
+
+	result, _, err := h.handleDraftADR(context.Background(), map[string]interface{}{"agentTaskIds": []interface{}{"a-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.DependencyUnavailable, errorCode(t, result))
+	assert.Len(t, knowledge.entries, 1)
+}
+
+func TestHandleReviewADR(t *testing.T) {
+	h, knowledge := newADRTestHandler(&fakeAnswerGenerator{answer: "# Use NATS\n## Context\n## Decision\n## Consequences"})
+	_, _, err := h.handleDraftADR(context.Background(), map[string]interface{}{"agentTaskIds": []interface{}{"a-1"}})
+	require.NoError(t, err)
+	draft := knowledge.entries[1]
+
+	result, _, err := h.handleReviewADR(context.Background(), map[string]interface{}{"id": draft.ID, "decision": "approve", "reviewer": "dana"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, "accepted", draft.Metadata["status"])
+	assert.Equal(t, storage.ApprovalApproved, draft.Metadata[storage.ApprovalMetadataKey])
+	assert.Equal(t, "dana", draft.Metadata["reviewedBy"])
+
+	// Reviewed drafts are no longer pending
+	result, _, err = h.handleReviewADR(context.Background(), map[string]interface{}{"id": draft.ID, "decision": "reject"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, _, err = h.handleReviewADR(context.Background(), map[string]interface{}{"id": draft.ID, "decision": "maybe"})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.Validation, errorCode(t, result))
+}
+
+// errorCode returns the code of an error result
+func errorCode(t *testing.T, result *mcp.CallToolResult) interface{} {
+	t.Helper()
+	require.True(t, result.IsError)
+	return result.StructuredContent.(map[string]interface{})["error"].(map[string]interface{})["code"]
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxBootstrapEntryChars cuts bootstrap entries shown in the claim response
+const maxBootstrapEntryChars = 800
+
+// bootstrapEntry is one knowledge entry returned when an agent claims a task
+type bootstrapEntry struct {
+	ID         string                 `json:"id"`
+	Collection string                 `json:"collection"`
+	Text       string                 `json:"text"`
+	Score      float64                `json:"score"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// registerSetAgentBootstrap registers the coordinator_set_agent_bootstrap tool
+func (h *ToolHandler) registerSetAgentBootstrap(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_set_agent_bootstrap",
+		Description: "Attach bootstrap knowledge collections to a registered subagent. When the agent claims an agent task (status in_progress), the top entries from these collections for the task's role and context are returned inline, so it does not need to know collection names or wait for a first query. An empty list detaches the pack.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Subagent name (see list_subagents)",
+				},
+				"collections": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: fmt.Sprintf("Knowledge collections to draw from (at most %d)", storage.MaxBootstrapCollection),
+				},
+				"limit": {
+					Type:        "number",
+					Description: fmt.Sprintf("Entries returned per claim, across all collections (default %d, max %d)", storage.DefaultBootstrapLimit, storage.MaxBootstrapLimit),
+				},
+			},
+			Required: []string{"name", "collections"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+
+		result, _, err := h.handleSetAgentBootstrap(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleSetAgentBootstrap replaces a subagent's bootstrap collections
+func (h *ToolHandler) handleSetAgentBootstrap(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.subagents == nil {
+		return createErrorResult("agent bootstrap packs are unavailable: no subagent storage configured"), nil, nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return createErrorResult("name parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	rawCollections, ok := args["collections"].([]interface{})
+	if !ok {
+		return createErrorResult("collections parameter is required and must be an array of collection names"), nil, nil
+	}
+	collections := make([]string, 0, len(rawCollections))
+	for _, raw := range rawCollections {
+		collection, ok := raw.(string)
+		if !ok {
+			return createErrorResult("collections must be an array of strings"), nil, nil
+		}
+		collections = append(collections, strings.TrimSpace(collection))
+	}
+
+	limit := 0
+	if raw, ok := args["limit"].(float64); ok {
+		if raw != float64(int(raw)) {
+			return createErrorResult("limit must be a whole number"), nil, nil
+		}
+		limit = int(raw)
+	}
+
+	subagent, err := h.subagents.SetAgentBootstrap(name, collections, limit)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"name":        subagent.Name,
+		"collections": subagent.BootstrapCollections,
+		"limit":       bootstrapLimit(subagent),
+	}
+	if len(subagent.BootstrapCollections) == 0 {
+		response["collections"] = []string{}
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// bootstrapLimit is the number of entries returned for a subagent's claims
+func bootstrapLimit(subagent *storage.Subagent) int {
+	if subagent.BootstrapLimit > 0 {
+		return subagent.BootstrapLimit
+	}
+	return storage.DefaultBootstrapLimit
+}
+
+// bootstrapKnowledge returns the top entries of the agent's bootstrap
+// collections for a task, best first. Collections that fail to query are
+// skipped so a claim never fails on bootstrap knowledge.
+func (h *ToolHandler) bootstrapKnowledge(ctx context.Context, task *storage.AgentTask) []bootstrapEntry {
+	if h.subagents == nil || h.knowledgeStorage == nil {
+		return nil
+	}
+	subagent, err := h.subagents.GetSubagent(task.AgentName)
+	if err != nil || len(subagent.BootstrapCollections) == 0 {
+		return nil
+	}
+
+	query := strings.TrimSpace(task.Role + "\n" + task.ContextSummary)
+	limit := bootstrapLimit(subagent)
+
+	var entries []bootstrapEntry
+	seen := make(map[string]bool)
+	for _, collection := range subagent.BootstrapCollections {
+		results, err := storage.QueryKnowledgeContext(ctx, h.knowledgeStorage, collection, query, limit)
+		if err != nil {
+			continue
+		}
+		for _, result := range results {
+			if result.Entry == nil || seen[result.Entry.ID] {
+				continue
+			}
+			seen[result.Entry.ID] = true
+			entries = append(entries, bootstrapEntry{
+				ID:         result.Entry.ID,
+				Collection: collection,
+				Text:       truncateText(result.Entry.Text, maxBootstrapEntryChars),
+				Score:      result.Score,
+				Metadata:   result.Entry.Metadata,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Score > entries[j].Score })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// formatBootstrapKnowledge renders bootstrap entries for the claim response
+func formatBootstrapKnowledge(entries []bootstrapEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\nBootstrap knowledge (%d entries):\n", len(entries))
+	for i, entry := range entries {
+		fmt.Fprintf(&b, "\n%d. [%s] (score %.2f)\n%s\n", i+1, entry.Collection, entry.Score, entry.Text)
+	}
+	return b.String()
+}
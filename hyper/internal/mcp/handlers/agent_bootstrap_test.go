@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootstrapLimit(t *testing.T) {
+	assert.Equal(t, storage.DefaultBootstrapLimit, bootstrapLimit(&storage.Subagent{}))
+	assert.Equal(t, 3, bootstrapLimit(&storage.Subagent{BootstrapLimit: 3}))
+}
+
+func TestFormatBootstrapKnowledge(t *testing.T) {
+	text := formatBootstrapKnowledge([]bootstrapEntry{
+		{ID: "k-1", Collection: "adr", Text: "Use Mongo for task storage", Score: 0.91},
+		{ID: "k-2", Collection: "go-patterns", Text: "Wrap errors with %w", Score: 0.8},
+	})
+
+	assert.Contains(t, text, "Bootstrap knowledge (2 entries):")
+	assert.Contains(t, text, "1. [adr] (score 0.91)\nUse Mongo for task storage\n")
+	assert.Contains(t, text, "2. [go-patterns] (score 0.80)\nWrap errors with %w\n")
+}
+
+func TestBootstrapKnowledgeWithoutSubagents(t *testing.T) {
+	h := &ToolHandler{}
+	assert.Nil(t, h.bootstrapKnowledge(context.Background(), &storage.AgentTask{AgentName: "go-dev"}))
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	agentPersonaURIPrefix = "hyperion://agent/"
+	agentPersonaURISuffix = "/persona"
+)
+
+// SetSubagentStorage enables the agent persona tools and resource
+func (h *ToolHandler) SetSubagentStorage(subagents *storage.SubchatStorage) {
+	h.subagents = subagents
+}
+
+// AgentPersonaURI returns the persona resource URI of a subagent
+func AgentPersonaURI(name string) string {
+	return agentPersonaURIPrefix + url.PathEscape(name) + agentPersonaURISuffix
+}
+
+// RegisterAgentPersonaResources registers the hyperion://agent/{name}/persona
+// resource that orchestrators read to inject a subagent's system prompt and
+// persona when spawning it
+func (h *ToolHandler) RegisterAgentPersonaResources(server *mcp.Server) error {
+	if h.subagents == nil {
+		return fmt.Errorf("subagent storage is not set")
+	}
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: agentPersonaURIPrefix + "{name}" + agentPersonaURISuffix,
+		Name:        "Agent Persona",
+		Description: "System prompt and persona document of a registered subagent, to inject when spawning it. Names are URL-escaped.",
+		MIMEType:    "application/json",
+	}, h.handleAgentPersonaResource)
+
+	return nil
+}
+
+// handleAgentPersonaResource serves hyperion://agent/{name}/persona
+func (h *ToolHandler) handleAgentPersonaResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	escaped := strings.TrimSuffix(strings.TrimPrefix(uri, agentPersonaURIPrefix), agentPersonaURISuffix)
+	name, err := url.PathUnescape(escaped)
+	if err != nil || name == "" || strings.Contains(escaped, "/") {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	subagent, err := h.subagents.GetSubagent(name)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	jsonData, err := json.MarshalIndent(subagent, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal agent persona: %w", err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(jsonData),
+			},
+		},
+	}, nil
+}
+
+// registerGetAgentPersona registers the coordinator_get_agent_persona tool
+func (h *ToolHandler) registerGetAgentPersona(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_get_agent_persona",
+		Description: "Get the system prompt and persona document of a registered subagent, to inject when spawning it. Also available as the hyperion://agent/{name}/persona resource.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Subagent name (see list_subagents)",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+
+		result, _, err := h.handleGetAgentPersona(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerSetAgentPersona registers the coordinator_set_agent_persona tool
+func (h *ToolHandler) registerSetAgentPersona(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_set_agent_persona",
+		Description: "Replace the system prompt and/or persona document (Markdown) of a registered subagent. Omitted fields are left unchanged.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Subagent name (see list_subagents)",
+				},
+				"systemPrompt": {
+					Type:        "string",
+					Description: "Optional: new system prompt",
+				},
+				"persona": {
+					Type:        "string",
+					Description: "Optional: new persona document (Markdown)",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+
+		result, _, err := h.handleSetAgentPersona(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleGetAgentPersona returns a subagent's persona
+func (h *ToolHandler) handleGetAgentPersona(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.subagents == nil {
+		return createErrorResult("agent personas are unavailable: no subagent storage configured"), nil, nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return createErrorResult("name parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	subagent, err := h.subagents.GetSubagent(name)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := personaResponse(subagent)
+	return structuredToolResult(response), response, nil
+}
+
+// handleSetAgentPersona updates a subagent's system prompt and/or persona
+func (h *ToolHandler) handleSetAgentPersona(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.subagents == nil {
+		return createErrorResult("agent personas are unavailable: no subagent storage configured"), nil, nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return createErrorResult("name parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	var systemPrompt, persona *string
+	if value, ok := args["systemPrompt"].(string); ok {
+		systemPrompt = &value
+	}
+	if value, ok := args["persona"].(string); ok {
+		persona = &value
+	}
+
+	subagent, err := h.subagents.SetAgentPersona(name, systemPrompt, persona)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := personaResponse(subagent)
+	return structuredToolResult(response), response, nil
+}
+
+// personaResponse is the structured result of the persona tools
+func personaResponse(subagent *storage.Subagent) map[string]interface{} {
+	return map[string]interface{}{
+		"name":             subagent.Name,
+		"description":      subagent.Description,
+		"systemPrompt":     subagent.SystemPrompt,
+		"persona":          subagent.Persona,
+		"tools":            subagent.Tools,
+		"personaUpdatedAt": subagent.PersonaUpdatedAt,
+		"bootstrap":        subagent.BootstrapCollections,
+		"resourceUri":      AgentPersonaURI(subagent.Name),
+	}
+}
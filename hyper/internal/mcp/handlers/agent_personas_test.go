@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentPersonaURI(t *testing.T) {
+	assert.Equal(t, "hyperion://agent/go-dev/persona", AgentPersonaURI("go-dev"))
+	assert.Equal(t, "hyperion://agent/Security%20&%20Auth%20Specialist/persona", AgentPersonaURI("Security & Auth Specialist"))
+}
+
+func TestAgentPersonaToolsWithoutStorage(t *testing.T) {
+	h := &ToolHandler{}
+
+	result, _, err := h.handleGetAgentPersona(context.Background(), map[string]interface{}{"name": "go-dev"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, _, err = h.handleSetAgentPersona(context.Background(), map[string]interface{}{"name": "go-dev", "persona": "# Go dev"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "no subagent storage configured")
+}
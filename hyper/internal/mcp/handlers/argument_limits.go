@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"hyper/internal/errcode"
+	"hyper/internal/i18n"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Environment variables bounding the size of incoming tool arguments
+const (
+	ToolMaxArgumentBytesEnv  = "TOOL_MAX_ARGUMENT_BYTES"  // whole arguments object
+	ToolMaxStringBytesEnv    = "TOOL_MAX_STRING_BYTES"    // any single string argument
+	KnowledgeMaxTextBytesEnv = "KNOWLEDGE_MAX_TEXT_BYTES" // knowledge text stored as one entry
+	KnowledgeAutoChunkEnv    = "KNOWLEDGE_AUTO_CHUNK"     // split oversized knowledge text by default
+)
+
+const (
+	defaultMaxArgumentBytes = 2 << 20   // well below MongoDB's 16 MiB document limit
+	defaultMaxStringBytes   = 512 << 10 // bounds notes, prompts and summaries
+	// defaultMaxKnowledgeBytes keeps an entry within the context window of
+	// the default embedding models (about 8k tokens)
+	defaultMaxKnowledgeBytes = 32 << 10
+)
+
+// autoChunkSchema is the shared "autoChunk" argument of knowledge writes
+var autoChunkSchema = &jsonschema.Schema{
+	Type:        "boolean",
+	Description: "Optional: store text above KNOWLEDGE_MAX_TEXT_BYTES (default 32 KiB) as linked chunks sharing metadata.chunkGroupId instead of rejecting it (default: KNOWLEDGE_AUTO_CHUNK)",
+}
+
+// sizeLimit returns the positive byte limit set in env, or def
+func sizeLimit(env string, def int) int {
+	if raw := os.Getenv(env); raw != "" {
+		if value, err := strconv.Atoi(raw); err == nil && value > 0 {
+			return value
+		}
+	}
+	return def
+}
+
+// maxKnowledgeTextBytes returns the largest knowledge text stored as one entry
+func maxKnowledgeTextBytes() int {
+	return sizeLimit(KnowledgeMaxTextBytesEnv, defaultMaxKnowledgeBytes)
+}
+
+// knowledgeAutoChunk reports whether oversized knowledge text is split: the
+// "autoChunk" argument when given, otherwise KNOWLEDGE_AUTO_CHUNK
+func knowledgeAutoChunk(args map[string]interface{}) bool {
+	if autoChunk, ok := args["autoChunk"].(bool); ok {
+		return autoChunk
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv(KnowledgeAutoChunkEnv))
+	return enabled
+}
+
+// oversizedKnowledgeError explains how to store knowledge text above the limit
+func oversizedKnowledgeError(size, limit int) *mcp.CallToolResult {
+	return createCodedErrorResult(errcode.Validation, fmt.Sprintf(
+		"text is %d bytes, above the %d byte knowledge entry limit (%s); split it into smaller entries or pass autoChunk: true to store it as linked chunks",
+		size, limit, KnowledgeMaxTextBytesEnv))
+}
+
+// guardArgumentSizes rejects calls whose arguments exceed the configured size
+// limits before they reach the tool handler, so oversized writes fail with a
+// clear validation error instead of deep in MongoDB or Qdrant
+func guardArgumentSizes(handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if req == nil || req.Params == nil {
+			return handler(ctx, req)
+		}
+		if message := checkArgumentSizes(req.Params.Arguments); message != "" {
+			return createCodedErrorResult(errcode.Validation, message), nil
+		}
+		return handler(ctx, req)
+	}
+}
+
+// checkArgumentSizes returns why raw arguments are too large, or ""
+func checkArgumentSizes(raw json.RawMessage) string {
+	maxArguments := sizeLimit(ToolMaxArgumentBytesEnv, defaultMaxArgumentBytes)
+	if len(raw) > maxArguments {
+		return fmt.Sprintf("arguments are %d bytes, above the %d byte limit (%s)", len(raw), maxArguments, ToolMaxArgumentBytesEnv)
+	}
+
+	// No string can be longer than the arguments holding it
+	maxString := sizeLimit(ToolMaxStringBytesEnv, defaultMaxStringBytes)
+	if len(raw) <= maxString {
+		return ""
+	}
+	var args interface{}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "" // malformed arguments are reported by the handler
+	}
+	if path, size := largestString("", args); size > maxString {
+		return fmt.Sprintf("argument %s is %d bytes, above the %d byte limit (%s)", path, size, maxString, ToolMaxStringBytesEnv)
+	}
+	return ""
+}
+
+// largestString finds the longest string in a decoded JSON value and its path
+func largestString(path string, value interface{}) (string, int) {
+	switch v := value.(type) {
+	case string:
+		return path, len(v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		bestPath, bestSize := "", -1
+		for _, key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			if p, size := largestString(childPath, v[key]); size > bestSize {
+				bestPath, bestSize = p, size
+			}
+		}
+		return bestPath, bestSize
+	case []interface{}:
+		bestPath, bestSize := "", -1
+		for i, item := range v {
+			if p, size := largestString(fmt.Sprintf("%s[%d]", path, i), item); size > bestSize {
+				bestPath, bestSize = p, size
+			}
+		}
+		return bestPath, bestSize
+	}
+	return "", -1
+}
+
+// upsertKnowledgeChunks stores the chunks of oversized knowledge text as
+// entries linked by chunk metadata, in one batch where the storage allows
+func (h *ToolHandler) upsertKnowledgeChunks(ctx context.Context, collection string, chunks []string, metadata map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	group := uuid.New().String()
+	inputs := make([]storage.KnowledgeInput, len(chunks))
+	for i, chunk := range chunks {
+		inputs[i] = storage.KnowledgeInput{Text: chunk, Metadata: storage.KnowledgeChunkMetadata(metadata, group, i, len(chunks))}
+	}
+
+	var entries []*storage.KnowledgeEntry
+	if batch, ok := h.knowledgeStorage.(storage.BatchKnowledgeStorage); ok {
+		stored, err := batch.UpsertBatch(collection, inputs)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to upsert knowledge: %s", err.Error())), nil, nil
+		}
+		entries = stored
+	} else {
+		for i, input := range inputs {
+			entry, err := h.knowledgeStorage.Upsert(collection, input.Text, input.Metadata)
+			if err != nil {
+				return createErrorResult(fmt.Sprintf("failed to upsert knowledge chunk %d of %d: %s", i+1, len(inputs), err.Error())), nil, nil
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	resultText := i18n.T(ctx, "knowledge.stored.chunked", len(entries), group, collection)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: resultText},
+		},
+	}, map[string]interface{}{
+		"ids":          ids,
+		"chunkGroupId": group,
+		"collection":   collection,
+	}, nil
+}
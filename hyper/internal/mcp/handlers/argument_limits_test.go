@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCheckArgumentSizes(t *testing.T) {
+	t.Setenv(ToolMaxArgumentBytesEnv, "200")
+	t.Setenv(ToolMaxStringBytesEnv, "20")
+
+	small, _ := json.Marshal(map[string]interface{}{"taskId": "t-1", "notes": "short"})
+	if message := checkArgumentSizes(small); message != "" {
+		t.Errorf("small arguments rejected: %s", message)
+	}
+
+	longString, _ := json.Marshal(map[string]interface{}{
+		"taskId":   "t-1",
+		"metadata": map[string]interface{}{"tags": []interface{}{"a", strings.Repeat("x", 30)}},
+	})
+	if message := checkArgumentSizes(longString); !strings.Contains(message, "metadata.tags[1]") {
+		t.Errorf("long nested string message = %q, want its path", message)
+	}
+
+	huge, _ := json.Marshal(map[string]interface{}{"text": strings.Repeat("y", 300)})
+	if message := checkArgumentSizes(huge); !strings.Contains(message, ToolMaxArgumentBytesEnv) {
+		t.Errorf("huge arguments message = %q, want %s", message, ToolMaxArgumentBytesEnv)
+	}
+}
+
+func TestKnowledgeAutoChunk(t *testing.T) {
+	t.Setenv(KnowledgeAutoChunkEnv, "")
+	if knowledgeAutoChunk(map[string]interface{}{}) {
+		t.Error("auto-chunking should be off by default")
+	}
+
+	t.Setenv(KnowledgeAutoChunkEnv, "true")
+	if !knowledgeAutoChunk(map[string]interface{}{}) {
+		t.Errorf("%s=true should enable auto-chunking", KnowledgeAutoChunkEnv)
+	}
+	if knowledgeAutoChunk(map[string]interface{}{"autoChunk": false}) {
+		t.Error("autoChunk argument should override the environment")
+	}
+}
+
+func TestMaxKnowledgeTextBytes(t *testing.T) {
+	t.Setenv(KnowledgeMaxTextBytesEnv, "not-a-number")
+	if got := maxKnowledgeTextBytes(); got != defaultMaxKnowledgeBytes {
+		t.Errorf("invalid limit = %d, want default %d", got, defaultMaxKnowledgeBytes)
+	}
+
+	t.Setenv(KnowledgeMaxTextBytesEnv, "1024")
+	if got := maxKnowledgeTextBytes(); got != 1024 {
+		t.Errorf("limit = %d, want 1024", got)
+	}
+}
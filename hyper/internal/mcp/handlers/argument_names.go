@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"hyper/internal/fieldnames"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DeprecatedArgumentsMetaKey is the result _meta key listing the arguments a
+// call sent under deprecated names
+const DeprecatedArgumentsMetaKey = "hyper/deprecatedArguments"
+
+// normalizeArgumentNames rewrites arguments the tool's input schema does not
+// declare to the spelling it does (collection for collectionName, camelCase
+// for snake_case; see fieldnames.Normalize), so callers written against
+// either surface keep working. The renames are listed in the result's _meta.
+func normalizeArgumentNames(tool *mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	declared := fieldnames.SchemaDeclares(tool.InputSchema)
+	if declared == nil {
+		return handler
+	}
+
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if req == nil || req.Params == nil || len(req.Params.Arguments) == 0 {
+			return handler(ctx, req)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(req.Params.Arguments))
+		decoder.UseNumber() // keep large integers exact through the rewrite
+		var args map[string]interface{}
+		if err := decoder.Decode(&args); err != nil || args == nil {
+			return handler(ctx, req) // malformed arguments are reported by the handler
+		}
+		renames := fieldnames.Normalize(args, declared)
+		if len(renames) == 0 {
+			return handler(ctx, req)
+		}
+		raw, err := json.Marshal(args)
+		if err != nil {
+			return handler(ctx, req)
+		}
+
+		params := *req.Params
+		params.Arguments = raw
+		normalized := *req
+		normalized.Params = &params
+
+		result, err := handler(ctx, &normalized)
+		if result != nil {
+			if result.Meta == nil {
+				result.Meta = mcp.Meta{}
+			}
+			result.Meta[DeprecatedArgumentsMetaKey] = renames
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"hyper/internal/fieldnames"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestNormalizeArgumentNames(t *testing.T) {
+	tool := &mcp.Tool{
+		Name: "knowledge_store",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"collectionName": {Type: "string"},
+				"information":    {Type: "string"},
+			},
+		},
+	}
+	var received map[string]interface{}
+	handler := normalizeArgumentNames(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var err error
+		received, err = extractArguments(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &mcp.CallToolResult{}, nil
+	})
+
+	args, _ := json.Marshal(map[string]interface{}{"collection": "team-docs", "text": "Deploys run on Fridays"})
+	result, err := handler(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Arguments: args}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received["collectionName"] != "team-docs" || received["information"] != "Deploys run on Fridays" {
+		t.Errorf("handler received %v, want the declared names", received)
+	}
+	renames, _ := result.Meta[DeprecatedArgumentsMetaKey].([]fieldnames.Rename)
+	if len(renames) != 2 {
+		t.Errorf("_meta lists %v, want both renames", result.Meta[DeprecatedArgumentsMetaKey])
+	}
+
+	args, _ = json.Marshal(map[string]interface{}{"collectionName": "team-docs"})
+	result, err = handler(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Arguments: args}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result.Meta[DeprecatedArgumentsMetaKey]; ok {
+		t.Error("declared names should not be reported")
+	}
+}
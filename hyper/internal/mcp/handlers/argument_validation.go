@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"hyper/internal/errcode"
+	"hyper/internal/validation"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// describeInvalidArguments adds the arguments failing the tool's input schema
+// to the VALIDATION errors its handler returns: as error.errors in the
+// structured content, and as a list after the message for agents reading the
+// text. Handlers keep their own checks and messages; the schema only adds
+// which fields to fix and how.
+func describeInvalidArguments(tool *mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, req)
+		if err != nil || result == nil || !result.IsError || req == nil || req.Params == nil {
+			return result, err
+		}
+		structured, ok := result.StructuredContent.(map[string]interface{})
+		if !ok {
+			return result, err
+		}
+		errObj, ok := structured["error"].(map[string]interface{})
+		if !ok || fmt.Sprint(errObj["code"]) != string(errcode.Validation) {
+			return result, err
+		}
+
+		var errs []validation.FieldError
+		if args, argsErr := extractArguments(req); argsErr != nil {
+			errs = []validation.FieldError{{Constraint: "type", Message: "arguments must be a JSON object", Example: map[string]interface{}{}}}
+		} else {
+			errs = validation.FromSchema(tool.InputSchema, args)
+		}
+		if len(errs) == 0 {
+			return result, err
+		}
+
+		errObj["errors"] = errs
+		lines := make([]string, 0, len(errs))
+		for _, fieldErr := range errs {
+			line := "- " + fieldErr.String()
+			if fieldErr.Example != nil {
+				line += fmt.Sprintf(" (e.g. %s)", formatExample(fieldErr.Example))
+			}
+			lines = append(lines, line)
+		}
+		for _, content := range result.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				text.Text += "\nInvalid arguments:\n" + strings.Join(lines, "\n")
+				break
+			}
+		}
+		return result, err
+	}
+}
+
+// formatExample renders an example value as JSON
+func formatExample(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
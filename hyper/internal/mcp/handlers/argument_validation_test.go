@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"hyper/internal/errcode"
+	"hyper/internal/validation"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestDescribeInvalidArguments(t *testing.T) {
+	limit := 1.0
+	tool := &mcp.Tool{
+		Name: "knowledge_find",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"collectionName": {Type: "string"},
+				"limit":          {Type: "integer", Minimum: &limit},
+			},
+			Required: []string{"collectionName"},
+		},
+	}
+	handler := describeInvalidArguments(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return createCodedErrorResult(errcode.Validation, "collectionName parameter is required"), nil
+	})
+
+	args, _ := json.Marshal(map[string]interface{}{"limit": 0})
+	result, err := handler(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Arguments: args}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, _ := result.StructuredContent.(map[string]interface{})["error"].(map[string]interface{})["errors"].([]validation.FieldError)
+	if len(errs) != 2 || errs[0].Pointer != "/collectionName" || errs[1].Pointer != "/limit" || errs[1].Constraint != "minimum" {
+		t.Errorf("errors = %+v, want /collectionName required and /limit minimum", errs)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.HasPrefix(text, "❌ Error: collectionName parameter is required") || !strings.Contains(text, `- /collectionName is required (e.g. "example")`) {
+		t.Errorf("text = %q, want the handler message followed by the invalid fields", text)
+	}
+
+	notFound := describeInvalidArguments(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return createCodedErrorResult(errcode.NotFound, "collection not found"), nil
+	})
+	result, _ = notFound(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Arguments: args}})
+	if _, listed := result.StructuredContent.(map[string]interface{})["error"].(map[string]interface{})["errors"]; listed {
+		t.Error("only validation errors should list invalid fields")
+	}
+}
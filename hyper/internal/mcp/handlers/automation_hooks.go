@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hyper/internal/automation"
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetAutomation enables the automation hook tools. The engine builds the
+// variables coordinator_test_automation_hook runs scripts with.
+func (h *ToolHandler) SetAutomation(store *storage.AutomationHookStorage, engine *automation.Engine) {
+	h.automationHooks = store
+	h.automationEngine = engine
+}
+
+// hookEventEnum lists the hook events for tool schemas
+func hookEventEnum() []interface{} {
+	events := make([]interface{}, len(storage.HookEvents))
+	for i, event := range storage.HookEvents {
+		events[i] = event
+	}
+	return events
+}
+
+// registerSetAutomationHook registers the coordinator_set_automation_hook tool
+func (h *ToolHandler) registerSetAutomationHook(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_set_automation_hook",
+		Description: "Create or replace an automation hook: a small script run when a task is created, an agent task is created, a TODO is completed or a task changes status. Scripts use if/else, let, comparisons and string helpers over `task` (and `todo`), and call tag(...), assign(agent), due_in_days(n) or log(...) to change the task. Scripts are checked before they are saved.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Hook name; hooks of an event run in name order",
+				},
+				"event": {
+					Type:        "string",
+					Enum:        hookEventEnum(),
+					Description: "Event the hook runs on",
+				},
+				"script": {
+					Type:        "string",
+					Description: "Hook script, e.g. if contains(lower(task.prompt), \"crash\") { tag(\"bug\") }",
+				},
+				"enabled": {
+					Type:        "boolean",
+					Description: "Optional: false keeps the hook without running it (default: true)",
+				},
+				"delete": {
+					Type:        "boolean",
+					Description: "Optional: delete the hook instead of saving it",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleSetAutomationHook(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerListAutomationHooks registers the coordinator_list_automation_hooks tool
+func (h *ToolHandler) registerListAutomationHooks(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_list_automation_hooks",
+		Description: "List automation hooks with their event, script, and the time and error of their last run.",
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, _, err := h.handleListAutomationHooks(ctx)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerTestAutomationHook registers the coordinator_test_automation_hook tool
+func (h *ToolHandler) registerTestAutomationHook(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_test_automation_hook",
+		Description: "Run a hook script against an existing task without applying anything, returning the tags, assignment, due date and log lines it would produce. Pass a script, or the name of a saved hook.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"taskId": {
+					Type:        "string",
+					Description: "Human or agent task the script runs against",
+				},
+				"script": {
+					Type:        "string",
+					Description: "Optional: script to test",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Optional: saved hook to test when no script is given",
+				},
+				"event": {
+					Type:        "string",
+					Enum:        hookEventEnum(),
+					Description: "Optional: event to simulate (default: the saved hook's event, or task_created)",
+				},
+				"todoId": {
+					Type:        "string",
+					Description: "Optional: TODO exposed as `todo` for todo_completed",
+				},
+			},
+			Required: []string{"taskId"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleTestAutomationHook(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleSetAutomationHook handles the coordinator_set_automation_hook tool call
+func (h *ToolHandler) handleSetAutomationHook(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.automationHooks == nil {
+		return createErrorResult("automation hooks are unavailable: no hook storage configured"), nil, nil
+	}
+
+	name, _ := args["name"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return createErrorResult("name parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	if del, _ := args["delete"].(bool); del {
+		if err := h.automationHooks.DeleteHook(name); err != nil {
+			return createErrorResult(err.Error()), nil, nil
+		}
+		response := map[string]interface{}{"name": name, "deleted": true}
+		return structuredToolResult(response), response, nil
+	}
+
+	hook := &storage.AutomationHook{Name: name, Enabled: true}
+	hook.Event, _ = args["event"].(string)
+	hook.Script, _ = args["script"].(string)
+	if enabled, ok := args["enabled"].(bool); ok {
+		hook.Enabled = enabled
+	}
+	if err := storage.ValidateAutomationHook(hook); err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+	if _, err := automation.Compile(hook.Script); err != nil {
+		return createCodedErrorResult(errcode.Validation, fmt.Sprintf("invalid script: %s", err.Error())), nil, nil
+	}
+	if err := h.automationHooks.SetHook(hook); err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{"hook": hook}
+	return structuredToolResult(response), response, nil
+}
+
+// handleListAutomationHooks handles the coordinator_list_automation_hooks tool call
+func (h *ToolHandler) handleListAutomationHooks(ctx context.Context) (*mcp.CallToolResult, interface{}, error) {
+	if h.automationHooks == nil {
+		return createErrorResult("automation hooks are unavailable: no hook storage configured"), nil, nil
+	}
+
+	hooks, err := h.automationHooks.ListHooks()
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"hooks": hooks,
+		"count": len(hooks),
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// handleTestAutomationHook handles the coordinator_test_automation_hook tool call
+func (h *ToolHandler) handleTestAutomationHook(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.automationHooks == nil || h.automationEngine == nil {
+		return createErrorResult("automation hooks are unavailable: no hook storage configured"), nil, nil
+	}
+
+	taskID, _ := args["taskId"].(string)
+	if strings.TrimSpace(taskID) == "" {
+		return createErrorResult("taskId parameter is required and must be a non-empty string"), nil, nil
+	}
+	source, _ := args["script"].(string)
+	event, _ := args["event"].(string)
+	todoID, _ := args["todoId"].(string)
+
+	if strings.TrimSpace(source) == "" {
+		name, _ := args["name"].(string)
+		if strings.TrimSpace(name) == "" {
+			return createErrorResult("either script or name is required"), nil, nil
+		}
+		hook, err := h.automationHooks.GetHook(strings.TrimSpace(name))
+		if err != nil {
+			return createErrorResult(err.Error()), nil, nil
+		}
+		if hook == nil {
+			return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("automation hook not found: %s", name)), nil, nil
+		}
+		source = hook.Script
+		if event == "" {
+			event = hook.Event
+		}
+	}
+	if event == "" {
+		event = storage.HookEventTaskCreated
+	}
+
+	script, err := automation.Compile(source)
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, fmt.Sprintf("invalid script: %s", err.Error())), nil, nil
+	}
+	vars, _, err := h.automationEngine.Variables(event, strings.TrimSpace(taskID), todoID, "")
+	if err != nil {
+		return createCodedErrorResult(errcode.NotFound, err.Error()), nil, nil
+	}
+	effects, err := script.Run(vars)
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("script failed: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"taskId":    taskID,
+		"event":     event,
+		"variables": vars,
+		"effects":   effects,
+		"noop":      effects.Empty(),
+	}
+	return structuredToolResult(response), response, nil
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutomationHookToolsWithoutStorage(t *testing.T) {
+	h := &ToolHandler{}
+
+	result, _, err := h.handleSetAutomationHook(context.Background(), map[string]interface{}{"name": "tagger", "event": "task_created", "script": `tag("a")`})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "no hook storage configured")
+
+	result, _, err = h.handleTestAutomationHook(context.Background(), map[string]interface{}{"taskId": "t1", "script": `tag("a")`})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
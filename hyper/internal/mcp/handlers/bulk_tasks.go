@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"hyper/internal/bulktasks"
+	"hyper/internal/errcode"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetBulkEditor enables coordinator_bulk_update_agent_tasks
+func (h *ToolHandler) SetBulkEditor(editor *bulktasks.Editor) {
+	h.bulkEditor = editor
+}
+
+// registerBulkUpdateAgentTasks registers the coordinator_bulk_update_agent_tasks tool
+func (h *ToolHandler) registerBulkUpdateAgentTasks(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_bulk_update_agent_tasks",
+		Description: fmt.Sprintf("Change the status, tags, priority or assignee of many agent tasks at once, selected by taskIds or by a filter (humanTaskId, agentName, status, tag), up to %d tasks. Use preview=true first to see every task that would change and how, without changing anything. Status changes fire the same sync and notification hooks as coordinator_update_task_status. Returns matched, changed, unchanged and failed counts with per-task changes and errors.", bulktasks.MaxTasks),
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"taskIds": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: "Agent task UUIDs to change (cannot be combined with filter)",
+				},
+				"filter": {
+					Type:        "object",
+					Description: "Select agent tasks whose fields all match",
+					Properties: map[string]*jsonschema.Schema{
+						"humanTaskId": {Type: "string", Description: "Parent human task UUID"},
+						"agentName":   {Type: "string", Description: "Assigned agent"},
+						"status":      {Type: "string", Enum: []interface{}{"pending", "in_progress", "completed", "blocked"}},
+						"tag":         {Type: "string", Description: "Tag the task carries"},
+					},
+				},
+				"status": {
+					Type:        "string",
+					Enum:        []interface{}{"pending", "in_progress", "completed", "blocked"},
+					Description: "Optional: new status",
+				},
+				"notes": {
+					Type:        "string",
+					Description: "Optional: notes recorded with the status change",
+				},
+				"addTags": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: "Optional: tags to add",
+				},
+				"removeTags": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: "Optional: tags to remove",
+				},
+				"priority": {
+					Type:        "string",
+					Enum:        []interface{}{"low", "medium", "high", "critical", ""},
+					Description: "Optional: new priority. Empty clears the tasks' own priority so they inherit their human task's.",
+				},
+				"assignTo": {
+					Type:        "string",
+					Description: "Optional: agent to reassign the tasks to",
+				},
+				"preview": {
+					Type:        "boolean",
+					Description: "Report what would change without changing it (default: false)",
+				},
+			},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+
+		result, _, err := h.handleBulkUpdateAgentTasks(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleBulkUpdateAgentTasks applies, or previews, one bulk agent task edit
+func (h *ToolHandler) handleBulkUpdateAgentTasks(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.bulkEditor == nil {
+		return createCodedErrorResult(errcode.DependencyUnavailable, "bulk task edits are unavailable: no bulk editor configured"), nil, nil
+	}
+
+	// The arguments mirror the REST request body, so decode them the same way
+	var req bulktasks.Request
+	raw, err := json.Marshal(args)
+	if err == nil {
+		err = json.Unmarshal(raw, &req)
+	}
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, fmt.Sprintf("invalid arguments: %s", err.Error())), nil, nil
+	}
+
+	result, err := h.bulkEditor.Apply(req)
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{"result": result}
+	return structuredToolResult(response), response, nil
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"hyper/internal/bulktasks"
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// bulkTaskStorage serves agent tasks and applies status changes
+type bulkTaskStorage struct {
+	storage.TaskStorage
+	bulktasks.Changes
+	agents []*storage.AgentTask
+}
+
+func (s *bulkTaskStorage) ListAllAgentTasks() []*storage.AgentTask {
+	return s.agents
+}
+
+func (s *bulkTaskStorage) UpdateTaskStatus(taskID string, status storage.TaskStatus, notes string) error {
+	for _, task := range s.agents {
+		if task.ID == taskID {
+			task.Status = status
+		}
+	}
+	return nil
+}
+
+func TestBulkUpdateAgentTasks(t *testing.T) {
+	tasks := &bulkTaskStorage{agents: []*storage.AgentTask{
+		{ID: "a-1", HumanTaskID: "h-1", Status: storage.TaskStatusBlocked},
+		{ID: "a-2", HumanTaskID: "h-1", Status: storage.TaskStatusInProgress},
+		{ID: "a-3", HumanTaskID: "h-2", Status: storage.TaskStatusBlocked},
+	}}
+	h := NewToolHandler(nil, nil, nil)
+	ctx := context.Background()
+
+	result, _, err := h.handleBulkUpdateAgentTasks(ctx, map[string]interface{}{"taskIds": []interface{}{"a-1"}, "status": "pending"})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.DependencyUnavailable, errorCode(t, result))
+
+	h.SetBulkEditor(bulktasks.NewEditor(tasks, tasks, nil, zap.NewNop()))
+	args := map[string]interface{}{
+		"filter":  map[string]interface{}{"humanTaskId": "h-1", "status": "blocked"},
+		"status":  "pending",
+		"preview": true,
+	}
+	result, _, err = h.handleBulkUpdateAgentTasks(ctx, args)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	preview := result.StructuredContent.(map[string]interface{})["result"].(*bulktasks.Result)
+	assert.Equal(t, 1, preview.Changed)
+	assert.Equal(t, storage.TaskStatusBlocked, tasks.agents[0].Status, "previews change nothing")
+
+	delete(args, "preview")
+	result, _, _ = h.handleBulkUpdateAgentTasks(ctx, args)
+	require.False(t, result.IsError)
+	assert.Equal(t, storage.TaskStatusPending, tasks.agents[0].Status)
+	assert.Equal(t, storage.TaskStatusBlocked, tasks.agents[2].Status)
+
+	result, _, _ = h.handleBulkUpdateAgentTasks(ctx, map[string]interface{}{"taskIds": []interface{}{"a-1"}, "status": "done"})
+	assert.Equal(t, errcode.Validation, errorCode(t, result))
+}
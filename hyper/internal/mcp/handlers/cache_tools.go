@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CodeSearcher searches a code index held outside MongoDB and Qdrant, such as
+// the in-memory copy of a cache instance
+type CodeSearcher interface {
+	SearchCode(query, folderPath string, limit int) ([]*storage.SearchResult, error)
+}
+
+// RegisterCacheTools registers the read-only tools a cache instance
+// (--mode cache) answers from its synced copy: knowledge queries, and
+// code_index_search over the cached code folders
+func (h *ToolHandler) RegisterCacheTools(server *mcp.Server, code CodeSearcher) error {
+	if err := h.registerQueryKnowledge(server); err != nil {
+		return fmt.Errorf("failed to register query_knowledge tool: %w", err)
+	}
+	if err := h.registerGetPopularCollections(server); err != nil {
+		return fmt.Errorf("failed to register get_popular_collections tool: %w", err)
+	}
+
+	tool := &mcp.Tool{
+		Name:        "code_index_search",
+		Description: "Search the cached code index using natural language queries. Returns relevant code chunks with file paths, line numbers and the folder they came from.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"query": {
+					Type:        "string",
+					Description: "Natural language search query (e.g., 'authentication logic', 'error handling for API calls')",
+				},
+				"limit": {
+					Type:        "number",
+					Description: "Maximum number of results to return (default: 10, max: 50)",
+				},
+				"folderPath": {
+					Type:        "string",
+					Description: "Optional: only return results from this indexed folder path",
+				},
+			},
+			Required: []string{"query"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleCachedCodeSearch(ctx, code, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleCachedCodeSearch handles code_index_search on a cache instance
+func (h *ToolHandler) handleCachedCodeSearch(ctx context.Context, code CodeSearcher, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	query := strings.TrimSpace(getStringField(args, "query", ""))
+	if query == "" {
+		return createErrorResult("query is required and must be a string"), nil, nil
+	}
+
+	limit := 10
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	folderPath := getStringField(args, "folderPath", "")
+
+	results, err := code.SearchCode(query, folderPath, limit)
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to search code: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"success":      true,
+		"query":        query,
+		"retrieveMode": "chunk",
+		"results":      results,
+		"count":        len(results),
+		"cached":       true,
+	}
+	if folderPath != "" {
+		response["folders"] = []string{folderPath}
+	}
+	return structuredToolResult(response), response, nil
+}
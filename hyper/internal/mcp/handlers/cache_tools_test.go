@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCodeSearcher records the search it was asked for
+type fakeCodeSearcher struct {
+	folderPath string
+	limit      int
+}
+
+func (f *fakeCodeSearcher) SearchCode(query, folderPath string, limit int) ([]*storage.SearchResult, error) {
+	f.folderPath, f.limit = folderPath, limit
+	return []*storage.SearchResult{{FilePath: "/repo/auth.go", Content: "func auth()", Score: 0.9}}, nil
+}
+
+func TestCachedCodeSearch(t *testing.T) {
+	h := &ToolHandler{}
+	code := &fakeCodeSearcher{}
+
+	result, response, err := h.handleCachedCodeSearch(context.Background(), code, map[string]interface{}{"query": "auth", "limit": float64(500), "folderPath": "/repo"})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, 1, response.(map[string]interface{})["count"])
+	assert.Equal(t, 50, code.limit)
+	assert.Equal(t, "/repo", code.folderPath)
+
+	result, _, err = h.handleCachedCodeSearch(context.Background(), code, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
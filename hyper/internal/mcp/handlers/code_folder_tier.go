@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"hyper/internal/mcp/storage"
+)
+
+// FolderTier records code index folder searches and reports whether a
+// folder's vectors are archived; implemented by *vectortier.Tier
+type FolderTier interface {
+	CodeFolderUsed(folderPath string)
+	FolderStatus(folderPath string) *storage.VectorArchive
+}
+
+// SetFolderTier makes code searches keep their folders hot, restore archived
+// ones and report them
+func (h *CodeToolsHandler) SetFolderTier(tier FolderTier) {
+	h.folderTier = tier
+}
+
+// useFolders records a search of the folders of targets when the search was
+// scoped to folderPath, and of the folders of results otherwise, so
+// searches across every folder do not keep all of them hot. It returns the
+// tier record of the first archived target folder, or nil.
+func (h *CodeToolsHandler) useFolders(folderPath string, targets []searchTarget, results []storage.SearchResult) *storage.VectorArchive {
+	if h.folderTier == nil {
+		return nil
+	}
+	if folderPath == "" {
+		used := map[string]bool{}
+		for _, result := range results {
+			if result.FolderPath != "" && !used[result.FolderPath] {
+				used[result.FolderPath] = true
+				h.folderTier.CodeFolderUsed(result.FolderPath)
+			}
+		}
+		return nil
+	}
+
+	var archived *storage.VectorArchive
+	for _, target := range targets {
+		h.folderTier.CodeFolderUsed(target.FolderPath)
+		if archive := h.folderTier.FolderStatus(target.FolderPath); archive != nil && archived == nil {
+			archived = archive
+		}
+	}
+	return archived
+}
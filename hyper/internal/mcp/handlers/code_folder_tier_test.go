@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingFolderTier records folder uses and reports fixed tier records
+type recordingFolderTier struct {
+	used     []string
+	archived map[string]*storage.VectorArchive
+}
+
+func (r *recordingFolderTier) CodeFolderUsed(folderPath string) {
+	r.used = append(r.used, folderPath)
+}
+
+func (r *recordingFolderTier) FolderStatus(folderPath string) *storage.VectorArchive {
+	return r.archived[folderPath]
+}
+
+func TestUseFolders(t *testing.T) {
+	tier := &recordingFolderTier{archived: map[string]*storage.VectorArchive{
+		"/repos/api": {Collection: "/repos/api", Tier: storage.VectorTierArchived},
+	}}
+	handler := &CodeToolsHandler{}
+	handler.SetFolderTier(tier)
+	targets := []searchTarget{{FolderPath: "/repos/api", Collection: "api_index"}, {FolderPath: "/repos/web", Collection: "web_index"}}
+
+	// Searches across every folder keep only the folders with hits hot
+	archive := handler.useFolders("", targets, []storage.SearchResult{{FolderPath: "/repos/web"}, {FolderPath: "/repos/web"}})
+	assert.Nil(t, archive)
+	assert.Equal(t, []string{"/repos/web"}, tier.used)
+
+	// Scoped searches restore and report an archived folder
+	tier.used = nil
+	archive = handler.useFolders("/repos/api", targets[:1], nil)
+	assert.Equal(t, []string{"/repos/api"}, tier.used)
+	if assert.NotNil(t, archive) {
+		assert.Equal(t, storage.VectorTierArchived, archive.Tier)
+	}
+
+	assert.Nil(t, (&CodeToolsHandler{}).useFolders("/repos/api", targets, nil), "no tier, no archive")
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// defaultRecentChangesWindow is searched when code_index_recent_changes has no since
+const defaultRecentChangesWindow = "7d"
+
+// RecentChange is one embedded hunk of a file modification
+type RecentChange struct {
+	FilePath     string    `json:"filePath"`
+	RelativePath string    `json:"relativePath"`
+	FolderPath   string    `json:"folderPath"`
+	Language     string    `json:"language"`
+	ChangedAt    time.Time `json:"changedAt"`
+	StartLine    int       `json:"startLine"`
+	Added        string    `json:"added,omitempty"`
+	Removed      string    `json:"removed,omitempty"`
+	LinesAdded   int       `json:"linesAdded"`
+	LinesRemoved int       `json:"linesRemoved"`
+	Score        float32   `json:"score"`
+}
+
+// registerRecentChanges registers the code_index_recent_changes tool
+func (h *CodeToolsHandler) registerRecentChanges(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "code_index_recent_changes",
+		Description: "Search recent code changes: lines added and removed by file modifications the watcher observed, embedded with timestamps. Answers questions like 'what changed around auth last week'. Changes are kept for RECENT_CHANGES_RETENTION_DAYS (default 14).",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"query": {
+					Type:        "string",
+					Description: "Natural language description of the change to look for (e.g., 'authentication', 'retry logic')",
+				},
+				"since": {
+					Type:        "string",
+					Description: "Start of the time window: a duration back from now ('7d', '24h', '90m') or a date/time ('2025-01-31', RFC3339). Default: 7d",
+				},
+				"until": {
+					Type:        "string",
+					Description: "Optional: end of the time window, in the same formats as since. Default: now",
+				},
+				"folderPath": {
+					Type:        "string",
+					Description: "Optional: only return changes to files under this folder",
+				},
+				"limit": {
+					Type:        "number",
+					Description: "Maximum number of results to return (default: 10, max: 50)",
+				},
+			},
+			Required: []string{"query"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		return h.handleRecentChanges(ctx, args)
+	})
+
+	return nil
+}
+
+// handleRecentChanges handles the code_index_recent_changes tool
+func (h *CodeToolsHandler) handleRecentChanges(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	query, _ := args["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return createCodeIndexErrorResult("query is required and must be a non-empty string"), nil
+	}
+
+	limit := 10
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	now := time.Now().UTC()
+	sinceArg, _ := args["since"].(string)
+	if sinceArg == "" {
+		sinceArg = defaultRecentChangesWindow
+	}
+	since, err := parseChangeTime(sinceArg, now)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("invalid since: %s", err.Error())), nil
+	}
+	until := now
+	if untilArg, _ := args["until"].(string); untilArg != "" {
+		if until, err = parseChangeTime(untilArg, now); err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("invalid until: %s", err.Error())), nil
+		}
+	}
+	if !since.Before(until) {
+		return createCodeIndexErrorResult("since must be before until"), nil
+	}
+	folderPath, _ := args["folderPath"].(string)
+
+	response := map[string]interface{}{
+		"success": true,
+		"query":   query,
+		"since":   since,
+		"until":   until,
+	}
+
+	// Nothing has been recorded until the watcher sees its first modification
+	_, exists, err := h.qdrantClient.CollectionVectorSize(ctx, storage.RecentChangesCollection)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to check recent changes collection: %s", err.Error())), nil
+	}
+	if !exists {
+		response["results"] = []RecentChange{}
+		response["count"] = 0
+		response["message"] = "no changes recorded yet - the file watcher records modifications of indexed files"
+		return structuredToolResult(response), nil
+	}
+
+	queryEmbedding, err := embeddings.CreateEmbeddingContext(ctx, h.embeddingClient, query)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to create query embedding: %s", err.Error())), nil
+	}
+
+	// Over-fetch when filtering by folder, which happens after the search
+	fetchLimit := limit
+	if folderPath != "" {
+		fetchLimit = limit * 3
+	}
+	resp, err := h.qdrantClient.SearchCodeIndexFilteredContext(ctx, storage.RecentChangesCollection, queryEmbedding, fetchLimit, changeWindowFilter(since, until))
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to search recent changes: %s", err.Error())), nil
+	}
+
+	results := make([]RecentChange, 0, len(resp.Result))
+	for _, hit := range resp.Result {
+		change := recentChangeFromHit(hit.Score, hit.Payload)
+		if folderPath != "" && !storage.FolderCovers(folderPath, change.FilePath) {
+			continue
+		}
+		results = append(results, change)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	h.logger.Info("Recent changes search completed",
+		zap.String("query", query),
+		zap.Time("since", since),
+		zap.Time("until", until),
+		zap.Int("results", len(results)))
+
+	response["results"] = results
+	response["count"] = len(results)
+	return structuredToolResult(response), nil
+}
+
+// changeWindowFilter restricts a recent changes search to [since, until]
+func changeWindowFilter(since, until time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"must": []map[string]interface{}{
+			{"key": "changedAtUnix", "range": map[string]interface{}{"gte": since.Unix(), "lte": until.Unix()}},
+		},
+	}
+}
+
+// parseChangeTime parses a time window bound: a duration back from now with
+// d/h/m units ("7d", "24h"), a date ("2006-01-02") or an RFC3339 timestamp
+func parseChangeTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("'%s' is not a valid number of days", value)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("'%s' must not be negative", value)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("'%s' is not a duration (7d, 24h) or date (2006-01-02, RFC3339)", value)
+}
+
+// recentChangeFromHit builds a recent change from a Qdrant hit payload
+func recentChangeFromHit(score float32, payload map[string]interface{}) RecentChange {
+	change := RecentChange{Score: score}
+
+	change.FilePath, _ = payload["filePath"].(string)
+	change.RelativePath, _ = payload["relativePath"].(string)
+	change.FolderPath, _ = payload["folderPath"].(string)
+	change.Language, _ = payload["language"].(string)
+	change.Added, _ = payload["added"].(string)
+	change.Removed, _ = payload["removed"].(string)
+	if changedAt, ok := payload["changedAt"].(string); ok {
+		change.ChangedAt, _ = time.Parse(time.RFC3339, changedAt)
+	}
+	if startLine, ok := payload["startLine"].(float64); ok {
+		change.StartLine = int(startLine)
+	}
+	if linesAdded, ok := payload["linesAdded"].(float64); ok {
+		change.LinesAdded = int(linesAdded)
+	}
+	if linesRemoved, ok := payload["linesRemoved"].(float64); ok {
+		change.LinesRemoved = int(linesRemoved)
+	}
+
+	return change
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseChangeTime(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"7d", time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)},
+		{"24h", time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)},
+		{"90m", time.Date(2025, 3, 10, 10, 30, 0, 0, time.UTC)},
+		{"2025-03-01", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"2025-03-01T08:00:00+02:00", time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseChangeTime(tt.value, now)
+		if err != nil {
+			t.Errorf("parseChangeTime(%q) error: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseChangeTime(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "last week", "-3d", "-2h"} {
+		if _, err := parseChangeTime(value, now); err == nil {
+			t.Errorf("parseChangeTime(%q) expected error", value)
+		}
+	}
+}
+
+func TestRecentChangeFromHit(t *testing.T) {
+	change := recentChangeFromHit(0.8, map[string]interface{}{
+		"filePath":     "/repo/auth/login.go",
+		"relativePath": "auth/login.go",
+		"changedAt":    "2025-03-10T12:00:00Z",
+		"startLine":    float64(42),
+		"added":        "\tcheckToken()",
+		"linesAdded":   float64(1),
+	})
+
+	if change.RelativePath != "auth/login.go" || change.StartLine != 42 || change.LinesAdded != 1 {
+		t.Errorf("unexpected change: %+v", change)
+	}
+	if !change.ChangedAt.Equal(time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("changedAt = %v", change.ChangedAt)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"hyper/internal/confirm"
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/undo"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// SetUndoManager stages code_index_remove_folder for the manager's undo window
+func (h *CodeToolsHandler) SetUndoManager(manager *undo.Manager) {
+	h.undoManager = manager
+}
+
+// registerRemoveFolder registers the code_index_remove_folder tool
+func (h *CodeToolsHandler) registerRemoveFolder(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "code_index_remove_folder",
+		Description: "Remove a folder from the code index: stops watching it and deletes its indexed files, chunks and vectors. ⚠️ DESTRUCTIVE - the removal is staged for an undo window (UNDO_WINDOW_SECONDS): cancel it with coordinator_undo or commit it early with coordinator_confirm_operation. Clients that support elicitation are asked to confirm; others must pass confirm: true.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"folderPath": {
+					Type:        "string",
+					Description: "Absolute path of the indexed folder to remove",
+				},
+				"confirm": {
+					Type:        "boolean",
+					Description: "Set to true to confirm removal without asking (required when the client does not support elicitation)",
+				},
+			},
+			Required: []string{"folderPath"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		return h.handleRemoveFolder(ctx, args)
+	})
+
+	return nil
+}
+
+// handleRemoveFolder handles the code_index_remove_folder tool
+func (h *CodeToolsHandler) handleRemoveFolder(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	folderPath, ok := args["folderPath"].(string)
+	if !ok || folderPath == "" {
+		return createCodeIndexErrorResult("folderPath parameter is required and must be a non-empty string"), nil
+	}
+
+	folder, err := h.codeIndexStorage.GetFolderByPath(folderPath)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to get folder: %s", err.Error())), nil
+	}
+	if folder == nil {
+		return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("folder not indexed: %s", folderPath)), nil
+	}
+
+	files, err := h.codeIndexStorage.ListFiles(folder.ID)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to list files: %s", err.Error())), nil
+	}
+
+	switch confirm.Ask(ctx, args, fmt.Sprintf("Remove %s and its %d indexed files from the code index?", folder.Path, len(files))) {
+	case confirm.Declined:
+		return createCodeIndexErrorResult("Removal cancelled: confirmation was declined"), nil
+	case confirm.Required:
+		return createCodeIndexErrorResult("Confirmation required: set confirm=true to remove the folder"), nil
+	}
+
+	result := map[string]interface{}{
+		"folderPath":   folder.Path,
+		"filesRemoved": len(files),
+	}
+
+	if h.undoManager.Enabled() {
+		op := h.undoManager.Stage("code_index_remove_folder", fmt.Sprintf("Remove folder %s and its %d indexed files", folder.Path, len(files)), func(ctx context.Context) (interface{}, error) {
+			if err := h.removeFolder(folder, len(files)); err != nil {
+				return nil, err
+			}
+			return result, nil
+		})
+		return stagedOperationResult(ctx, op), nil
+	}
+
+	if err := h.removeFolder(folder, len(files)); err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to remove folder: %s", err.Error())), nil
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(resultJSON)},
+		},
+		StructuredContent: result,
+	}, nil
+}
+
+// removeFolder deletes a folder's vectors, stops watching it and removes it
+// with its files and chunks
+func (h *CodeToolsHandler) removeFolder(folder *storage.IndexedFolder, fileCount int) error {
+	if fileCount > 0 {
+		mapping, _ := h.codeIndexStorage.GetPathMapping(folder.Path)
+		if mapping != nil {
+			err := h.qdrantClient.DeleteCodeIndexByFilter(mapping.QdrantCollection, map[string]interface{}{
+				"must": []map[string]interface{}{
+					{"key": "folderId", "match": map[string]interface{}{"value": folder.ID}},
+				},
+			})
+			if err != nil {
+				h.logger.Warn("Failed to delete vectors from Qdrant", zap.Error(err))
+			}
+		}
+	}
+
+	if h.fileWatcher != nil {
+		if err := h.fileWatcher.RemoveFolder(folder.Path); err != nil {
+			h.logger.Warn("Failed to remove folder from file watcher", zap.Error(err))
+		}
+	}
+
+	if err := h.codeIndexStorage.RemoveFolder(folder.ID); err != nil {
+		return err
+	}
+
+	h.logger.Info("Removed folder from code index",
+		zap.String("folderID", folder.ID),
+		zap.String("path", folder.Path),
+		zap.Int("filesRemoved", fileCount))
+	return nil
+}
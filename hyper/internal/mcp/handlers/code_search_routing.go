@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"hyper/internal/mcp/storage"
+)
+
+// searchTarget is a Qdrant collection queried on behalf of an indexed folder
+type searchTarget struct {
+	FolderPath string
+	Collection string
+}
+
+// resolveSearchTargets selects the collections a code search should query.
+// With folderPath, only the most specific mapping covering that folder is used.
+// Without it, every mapped folder allowed by the profile (and not weighted to 0)
+// is searched, so monorepo and dependency-repo indexes are mixed in one result set.
+func resolveSearchTargets(mappings []*storage.CodeIndexMapping, folderPath string, profile *storage.SearchProfile) []searchTarget {
+	if folderPath != "" {
+		var best *storage.CodeIndexMapping
+		for _, mapping := range mappings {
+			if storage.FolderCovers(mapping.Path, folderPath) && (best == nil || len(mapping.Path) > len(best.Path)) {
+				best = mapping
+			}
+		}
+		if best == nil {
+			return nil
+		}
+		return []searchTarget{{FolderPath: best.Path, Collection: best.QdrantCollection}}
+	}
+
+	var targets []searchTarget
+	seen := make(map[string]bool)
+	for _, mapping := range mappings {
+		if seen[mapping.QdrantCollection] || !profile.Allows(mapping.Path) || profile.WeightFor(mapping.Path) <= 0 {
+			continue
+		}
+		seen[mapping.QdrantCollection] = true
+		targets = append(targets, searchTarget{FolderPath: mapping.Path, Collection: mapping.QdrantCollection})
+	}
+	return targets
+}
+
+// codeSearchResultFromHit builds a search result from a Qdrant hit payload.
+// Hits without a folderPath payload are attributed to the searched folder.
+func codeSearchResultFromHit(target searchTarget, score float32, payload map[string]interface{}) storage.SearchResult {
+	result := storage.SearchResult{
+		Score:      score,
+		FolderPath: target.FolderPath,
+	}
+
+	if fileID, ok := payload["fileId"].(string); ok {
+		result.FileID = fileID
+	}
+	if folderID, ok := payload["folderId"].(string); ok {
+		result.FolderID = folderID
+	}
+	if folderPath, ok := payload["folderPath"].(string); ok && folderPath != "" {
+		result.FolderPath = folderPath
+	}
+	if filePath, ok := payload["filePath"].(string); ok {
+		result.FilePath = filePath
+	}
+	if relativePath, ok := payload["relativePath"].(string); ok {
+		result.RelativePath = relativePath
+	}
+	if language, ok := payload["language"].(string); ok {
+		result.Language = language
+	}
+	if commentLanguage, ok := payload[storage.CommentLanguageKey].(string); ok {
+		result.CommentLanguage = commentLanguage
+	}
+	if chunkNum, ok := payload["chunkNum"].(float64); ok {
+		result.ChunkNum = int(chunkNum)
+	}
+	if startLine, ok := payload["startLine"].(float64); ok {
+		result.StartLine = int(startLine)
+	}
+	if endLine, ok := payload["endLine"].(float64); ok {
+		result.EndLine = int(endLine)
+	}
+	if content, ok := payload["content"].(string); ok {
+		result.Content = content
+	}
+	if summary, ok := payload["summary"].(string); ok {
+		result.Summary = summary
+	}
+
+	return result
+}
+
+// mergeSearchResults applies the profile's allow-list and folder weights to hits
+// gathered from several collections, drops duplicate chunks, and returns the
+// top results by weighted score. With folderPath, hits outside it are dropped.
+func mergeSearchResults(results []storage.SearchResult, folderPath string, profile *storage.SearchProfile, limit int) []storage.SearchResult {
+	merged := make([]storage.SearchResult, 0, len(results))
+	indexByChunk := make(map[string]int)
+
+	for _, result := range results {
+		if folderPath != "" && !storage.FolderCovers(folderPath, result.FilePath) {
+			continue
+		}
+		if !profile.Allows(result.FolderPath) {
+			continue
+		}
+
+		weight := profile.WeightFor(result.FolderPath)
+		if weight <= 0 {
+			continue
+		}
+
+		result.RawScore = result.Score
+		result.FolderWeight = weight
+		result.Score = float32(float64(result.Score) * weight)
+
+		key := fmt.Sprintf("%s#%d", result.FilePath, result.ChunkNum)
+		if i, ok := indexByChunk[key]; ok {
+			if result.Score > merged[i].Score {
+				merged[i] = result
+			}
+			continue
+		}
+		indexByChunk[key] = len(merged)
+		merged = append(merged, result)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Score > merged[j].Score
+	})
+
+	if limit > 0 && len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged
+}
+
+// matchIndexedFolder finds the indexed folder a hit belongs to, by ID or else by
+// the most specific folder path covering it
+func matchIndexedFolder(folders []*storage.IndexedFolder, folderID, folderPath string) *storage.IndexedFolder {
+	var best *storage.IndexedFolder
+	for _, folder := range folders {
+		if folderID != "" && folder.ID == folderID {
+			return folder
+		}
+		if folderPath != "" && storage.FolderCovers(folder.Path, folderPath) && (best == nil || len(folder.Path) > len(best.Path)) {
+			best = folder
+		}
+	}
+	return best
+}
+
+// searchProfileOverrides builds the effective profile for one search call by
+// layering per-call folder weights and allow-list over the stored profile
+func searchProfileOverrides(base *storage.SearchProfile, name string, args map[string]interface{}) (*storage.SearchProfile, error) {
+	profile := &storage.SearchProfile{Name: name}
+	if base != nil {
+		profile.FolderWeights = append(profile.FolderWeights, base.FolderWeights...)
+		profile.AllowedFolders = append(profile.AllowedFolders, base.AllowedFolders...)
+	}
+
+	weights, err := parseFolderWeights(args["folderWeights"])
+	if err != nil {
+		return nil, err
+	}
+	profile.FolderWeights = append(profile.FolderWeights, weights...)
+
+	if raw, ok := args["folders"]; ok {
+		folders, err := parseStringList(raw, "folders")
+		if err != nil {
+			return nil, err
+		}
+		profile.AllowedFolders = folders
+	}
+
+	return profile, nil
+}
+
+// parseFolderWeights converts a {"/path": weight} argument into folder weights,
+// sorted by path so the result is deterministic
+func parseFolderWeights(raw interface{}) ([]storage.FolderWeight, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("folderWeights must be an object mapping folder paths to weights")
+	}
+
+	weights := make([]storage.FolderWeight, 0, len(obj))
+	for path, value := range obj {
+		weight, ok := value.(float64)
+		if !ok || weight < 0 {
+			return nil, fmt.Errorf("folderWeights[%s] must be a non-negative number", path)
+		}
+		weights = append(weights, storage.FolderWeight{Path: path, Weight: weight})
+	}
+
+	sort.Slice(weights, func(i, j int) bool {
+		return weights[i].Path < weights[j].Path
+	})
+	return weights, nil
+}
+
+// parseStringList converts a JSON array argument into a string slice
+func parseStringList(raw interface{}, name string) ([]string, error) {
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", name)
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array of strings", name)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// snippetLanguageFilter builds the Qdrant payload filter restricting a search
+// to chunks in the given languages; nil when no language is requested
+func snippetLanguageFilter(languages []string) map[string]interface{} {
+	if len(languages) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"must": []map[string]interface{}{
+			{"key": "language", "match": map[string]interface{}{"any": languages}},
+		},
+	}
+}
+
+// parseSnippetLanguages reads the language filter of a snippet search, accepting
+// either a single "language" or a "languages" list, normalized to lower case
+func parseSnippetLanguages(args map[string]interface{}) ([]string, error) {
+	var languages []string
+	if language, ok := args["language"].(string); ok && strings.TrimSpace(language) != "" {
+		languages = append(languages, language)
+	}
+	if raw, ok := args["languages"]; ok {
+		list, err := parseStringList(raw, "languages")
+		if err != nil {
+			return nil, err
+		}
+		languages = append(languages, list...)
+	}
+
+	seen := make(map[string]bool, len(languages))
+	normalized := make([]string, 0, len(languages))
+	for _, language := range languages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if language == "" || seen[language] {
+			continue
+		}
+		seen[language] = true
+		normalized = append(normalized, language)
+	}
+	return normalized, nil
+}
+
+// excludeSnippetSource drops hits from the file the snippet was copied from,
+// matched on absolute or folder-relative path
+func excludeSnippetSource(results []storage.SearchResult, excludeFile string) []storage.SearchResult {
+	if excludeFile == "" {
+		return results
+	}
+	kept := results[:0]
+	for _, result := range results {
+		if result.FilePath == excludeFile || (result.RelativePath != "" && result.RelativePath == excludeFile) {
+			continue
+		}
+		kept = append(kept, result)
+	}
+	return kept
+}
+
+// hybridSearchArgs layers a search's mode, fusion and keywordWeight
+// arguments over the CODE_INDEX_SEARCH_* defaults
+func hybridSearchArgs(args map[string]interface{}) (storage.HybridSearch, error) {
+	search, err := storage.HybridSearchFromEnv()
+	if err != nil {
+		return search, err
+	}
+	if mode, ok := args["mode"].(string); ok && mode != "" {
+		if search.Mode, err = storage.ParseSearchMode(mode); err != nil {
+			return search, err
+		}
+	}
+	if fusion, ok := args["fusion"].(string); ok && fusion != "" {
+		if search.Fusion, err = storage.ParseFusion(fusion); err != nil {
+			return search, err
+		}
+	}
+	if weight, ok := args["keywordWeight"].(float64); ok {
+		search.KeywordWeight = weight
+	}
+	return search, search.Validate()
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+)
+
+func TestParseSnippetLanguages(t *testing.T) {
+	languages, err := parseSnippetLanguages(map[string]interface{}{
+		"language":  "Go",
+		"languages": []interface{}{"go", " TypeScript ", ""},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"go", "typescript"}; !reflect.DeepEqual(languages, want) {
+		t.Errorf("languages = %v, want %v", languages, want)
+	}
+
+	if _, err := parseSnippetLanguages(map[string]interface{}{"languages": "go"}); err == nil {
+		t.Error("expected error for non-array languages")
+	}
+}
+
+func TestSnippetLanguageFilter(t *testing.T) {
+	if filter := snippetLanguageFilter(nil); filter != nil {
+		t.Errorf("expected no filter without languages, got %v", filter)
+	}
+
+	filter := snippetLanguageFilter([]string{"go"})
+	must, ok := filter["must"].([]map[string]interface{})
+	if !ok || len(must) != 1 || must[0]["key"] != "language" {
+		t.Fatalf("unexpected filter: %v", filter)
+	}
+}
+
+func TestExcludeSnippetSource(t *testing.T) {
+	results := []storage.SearchResult{
+		{FilePath: "/repo/a.go", RelativePath: "a.go"},
+		{FilePath: "/repo/b.go", RelativePath: "b.go"},
+		{FilePath: "/repo/c.go", RelativePath: "c.go"},
+	}
+
+	kept := excludeSnippetSource(append([]storage.SearchResult(nil), results...), "/repo/a.go")
+	if len(kept) != 2 || kept[0].FilePath != "/repo/b.go" {
+		t.Errorf("absolute path not excluded: %v", kept)
+	}
+
+	kept = excludeSnippetSource(append([]storage.SearchResult(nil), results...), "c.go")
+	if len(kept) != 2 || kept[1].FilePath != "/repo/b.go" {
+		t.Errorf("relative path not excluded: %v", kept)
+	}
+
+	if kept = excludeSnippetSource(results, ""); len(kept) != 3 {
+		t.Errorf("empty excludeFile should keep all results, got %d", len(kept))
+	}
+}
+
+func TestHybridSearchArgs(t *testing.T) {
+	t.Setenv(storage.SearchModeEnv, "hybrid")
+
+	search, err := hybridSearchArgs(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if search.Mode != storage.SearchModeHybrid || search.Fusion != storage.FusionRRF {
+		t.Errorf("environment defaults not applied: %+v", search)
+	}
+
+	search, err = hybridSearchArgs(map[string]interface{}{"mode": "Keyword", "fusion": "weighted", "keywordWeight": 0.6})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if search.Mode != storage.SearchModeKeyword || search.Fusion != storage.FusionWeighted || search.KeywordWeight != 0.6 {
+		t.Errorf("arguments not applied: %+v", search)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"mode": "sparse"},
+		{"fusion": "max"},
+		{"keywordWeight": 1.5},
+	} {
+		if _, err := hybridSearchArgs(args); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// codeUsageAreaLimit caps the hot and never-queried areas listed per folder
+const codeUsageAreaLimit = 20
+
+// registerUsage registers the code_index_usage tool
+func (h *CodeToolsHandler) registerUsage(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "code_index_usage",
+		Description: "Heatmap of code search usage: which directories of each indexed folder code searches return results from most, and which indexed directories no search has ever returned. Use it to decide what to index and what to exclude: never-queried areas with many chunks cost indexing time and storage without being used.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"folderPath": {
+					Type:        "string",
+					Description: "Optional: only report this indexed folder",
+				},
+				"depth": {
+					Type:        "number",
+					Description: "Directory levels below each folder to group areas by (default: 2)",
+				},
+				"limit": {
+					Type:        "number",
+					Description: "Maximum hot and never-queried areas listed per folder (default: 20, max: 100)",
+				},
+			},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		return h.handleUsage(ctx, args)
+	})
+
+	return nil
+}
+
+// handleUsage handles the code_index_usage tool
+func (h *CodeToolsHandler) handleUsage(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	folderPath, _ := args["folderPath"].(string)
+
+	depth := 0
+	if d, ok := args["depth"].(float64); ok {
+		if d < 1 {
+			return createCodeIndexErrorResult("depth must be at least 1"), nil
+		}
+		depth = int(d)
+	}
+	limit := codeUsageAreaLimit
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	report, err := h.codeIndexStorage.CodeSearchUsage(folderPath, depth, limit)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to build code search usage: %s", err.Error())), nil
+	}
+
+	return structuredToolResult(map[string]interface{}{
+		"success":      true,
+		"depth":        report.Depth,
+		"trackedSince": report.TrackedSince,
+		"folders":      report.Folders,
+	}), nil
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleUsageRejectsDepth(t *testing.T) {
+	h := &CodeToolsHandler{}
+	result, err := h.handleUsage(context.Background(), map[string]interface{}{"depth": float64(0)})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// symbolLimit is the default number of symbols code_index_find_symbol returns
+	symbolLimit = 20
+
+	// definitionSourceLines caps the source returned with a definition
+	definitionSourceLines = 200
+
+	// definitionAlternatives caps the other candidates listed for an ambiguous name
+	definitionAlternatives = 10
+)
+
+// symbolQueryProperties are the filters both symbol tools accept
+func symbolQueryProperties() map[string]*jsonschema.Schema {
+	return map[string]*jsonschema.Schema{
+		"kind": {
+			Type:        "string",
+			Description: "Optional: only symbols of this kind: function, method, struct, class, interface, type, enum, trait, const, var, ...",
+		},
+		"file": {
+			Type:        "string",
+			Description: "Optional: only symbols in this file or directory (absolute, relative, or a trailing part such as storage/tasks.go)",
+		},
+	}
+}
+
+// registerFindSymbol registers the code_index_find_symbol tool
+func (h *CodeToolsHandler) registerFindSymbol(server *mcp.Server) error {
+	properties := symbolQueryProperties()
+	properties["name"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Symbol name, optionally qualified by its package, directory, module or container: NewClient, storage.NewClient, CodeIndexStorage.UpsertFile",
+	}
+	properties["prefix"] = &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "Match names starting with name, ignoring case (default: false, exact name)",
+	}
+	properties["limit"] = &jsonschema.Schema{
+		Type:        "number",
+		Description: "Maximum symbols returned (default: 20, max: 100)",
+	}
+
+	tool := &mcp.Tool{
+		Name:        "code_index_find_symbol",
+		Description: "Find where functions, methods, types and constants are declared in the indexed code, by name rather than by meaning. Returns each declaration's file, line range, kind, container and signature. Deterministic and exact, unlike code_index_search: use it when you know the identifier. Symbols come from Go, Python, JavaScript, TypeScript, Java, C#, Kotlin, Scala, Rust, PHP and Swift files.",
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: properties,
+			Required:   []string{"name"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		return h.handleFindSymbol(ctx, args)
+	})
+
+	return nil
+}
+
+// registerGetDefinition registers the code_index_get_definition tool
+func (h *CodeToolsHandler) registerGetDefinition(server *mcp.Server) error {
+	properties := symbolQueryProperties()
+	properties["symbol"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Symbol to resolve, optionally qualified by its package, directory, module or container: storage.NewClient, CodeIndexStorage.UpsertFile",
+	}
+	properties["includeSource"] = &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "Return the definition's source from the index (default: true; up to 200 lines)",
+	}
+
+	tool := &mcp.Tool{
+		Name:        "code_index_get_definition",
+		Description: "Go to definition: resolve a symbol name to the file and line range that declare it, with its source. When several declarations match, the best one is returned (declarations outside tests first) with the others as alternatives; qualify the name or pass file to narrow it down.",
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: properties,
+			Required:   []string{"symbol"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		return h.handleGetDefinition(ctx, args)
+	})
+
+	return nil
+}
+
+// handleFindSymbol handles the code_index_find_symbol tool
+func (h *CodeToolsHandler) handleFindSymbol(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	name, _ := args["name"].(string)
+	if strings.TrimSpace(name) == "" {
+		return createCodedErrorResult(errcode.Validation, "name parameter is required and must be a non-empty string"), nil
+	}
+	query := symbolQueryArgs(args)
+	query.Name = name
+	query.Prefix, _ = args["prefix"].(bool)
+	query.Limit = symbolLimit
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		query.Limit = int(l)
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	symbols, err := h.codeIndexStorage.FindSymbols(query)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to find symbols: %s", err.Error())), nil
+	}
+	if symbols == nil {
+		symbols = []*storage.CodeSymbol{}
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"symbols": symbols,
+		"count":   len(symbols),
+	}
+	if len(symbols) == 0 {
+		response["hint"] = h.noSymbolsHint()
+	}
+	return structuredToolResult(response), nil
+}
+
+// handleGetDefinition handles the code_index_get_definition tool
+func (h *CodeToolsHandler) handleGetDefinition(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	symbol, _ := args["symbol"].(string)
+	if strings.TrimSpace(symbol) == "" {
+		return createCodedErrorResult(errcode.Validation, "symbol parameter is required and must be a non-empty string"), nil
+	}
+	query := symbolQueryArgs(args)
+	query.Name = symbol
+	query.Limit = definitionAlternatives + 1
+
+	candidates, err := h.codeIndexStorage.FindSymbols(query)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to find symbols: %s", err.Error())), nil
+	}
+	if len(candidates) == 0 {
+		return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("no definition found for %s. %s", symbol, h.noSymbolsHint())), nil
+	}
+
+	definition := candidates[0]
+	response := map[string]interface{}{
+		"success":    true,
+		"definition": definition,
+		"ambiguous":  len(candidates) > 1,
+	}
+	if len(candidates) > 1 {
+		response["alternatives"] = candidates[1:]
+	}
+
+	if includeSource, ok := args["includeSource"].(bool); !ok || includeSource {
+		source, truncated, err := h.definitionSource(definition)
+		if err != nil {
+			response["sourceError"] = err.Error()
+		} else {
+			response["source"] = source
+			if truncated {
+				response["sourceTruncated"] = true
+			}
+		}
+	}
+	return structuredToolResult(response), nil
+}
+
+// symbolQueryArgs reads the kind and file filters
+func symbolQueryArgs(args map[string]interface{}) storage.SymbolQuery {
+	var query storage.SymbolQuery
+	query.Kind, _ = args["kind"].(string)
+	query.File, _ = args["file"].(string)
+	query.Kind = strings.TrimSpace(query.Kind)
+	return query
+}
+
+// noSymbolsHint explains an empty result: nothing by that name, or no
+// symbols indexed yet
+func (h *CodeToolsHandler) noSymbolsHint() string {
+	if count, err := h.codeIndexStorage.CountSymbols(); err == nil && count == 0 {
+		return "No symbols are indexed yet: run code_index_scan to extract them from the indexed files."
+	}
+	return "Check the spelling, drop the qualifier or file filter, or use prefix matching with code_index_find_symbol."
+}
+
+// definitionSource returns a symbol's lines from the file's indexed chunks
+func (h *CodeToolsHandler) definitionSource(symbol *storage.CodeSymbol) (string, bool, error) {
+	chunks, err := h.codeIndexStorage.ListChunks(symbol.FileID)
+	if err != nil {
+		return "", false, err
+	}
+	return sourceLines(chunks, symbol.StartLine, symbol.EndLine, definitionSourceLines)
+}
+
+// sourceLines assembles lines start..end (1-based, inclusive) from chunks,
+// keeping at most limit lines
+func sourceLines(chunks []*storage.FileChunk, start, end, limit int) (string, bool, error) {
+	truncated := false
+	if end-start+1 > limit {
+		end = start + limit - 1
+		truncated = true
+	}
+
+	lines := make(map[int]string)
+	for _, chunk := range chunks {
+		if chunk.EndLine < start || chunk.StartLine > end {
+			continue
+		}
+		for i, line := range strings.Split(strings.TrimSuffix(chunk.Content, "\n"), "\n") {
+			lines[chunk.StartLine+i] = line
+		}
+	}
+
+	var b strings.Builder
+	for n := start; n <= end; n++ {
+		line, ok := lines[n]
+		if !ok {
+			return "", false, fmt.Errorf("line %d is not in the index; the file may have changed since it was indexed", n)
+		}
+		b.WriteString(line)
+		if n < end {
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), truncated, nil
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceLines(t *testing.T) {
+	chunks := []*storage.FileChunk{
+		{Content: "package store\n\nfunc A() {\n", StartLine: 1, EndLine: 3},
+		{Content: "\treturn\n}\n", StartLine: 4, EndLine: 5},
+	}
+
+	source, truncated, err := sourceLines(chunks, 3, 5, 200)
+	require.NoError(t, err)
+	assert.Equal(t, "func A() {\n\treturn\n}", source)
+	assert.False(t, truncated)
+
+	source, truncated, err = sourceLines(chunks, 3, 5, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "func A() {\n\treturn", source)
+	assert.True(t, truncated)
+
+	// The file grew since it was indexed
+	_, _, err = sourceLines(chunks, 4, 7, 200)
+	assert.Error(t, err)
+}
+
+func TestSymbolToolsRequireName(t *testing.T) {
+	h := &CodeToolsHandler{}
+
+	result, err := h.handleFindSymbol(context.Background(), map[string]interface{}{"name": "  "})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.Validation, errorCode(t, result))
+
+	result, err = h.handleGetDefinition(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.Validation, errorCode(t, result))
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+const (
+	taskCodeURIPrefix = "hyperion://task/agent/"
+	taskCodeURISuffix = "/code"
+)
+
+// SetTaskStorage links code search to agent tasks: hits list the tasks that
+// declared their file in filesModified, and tasks expose the code they touch
+func (h *CodeToolsHandler) SetTaskStorage(taskStorage storage.TaskStorage) {
+	h.taskStorage = taskStorage
+}
+
+// attachRecentTasks lists on each result the agent tasks that declared its file
+func (h *CodeToolsHandler) attachRecentTasks(results []storage.SearchResult) {
+	if h.taskStorage == nil || len(results) == 0 {
+		return
+	}
+	agentTasks := h.taskStorage.ListAllAgentTasks()
+	for i := range results {
+		results[i].RecentTasks = storage.RecentTasksForFile(agentTasks, results[i].FilePath, results[i].RelativePath, storage.RecentTasksPerFile)
+	}
+}
+
+// RegisterTaskCodeResources registers the hyperion://task/agent/{id}/code
+// resource, serving the indexed code an agent task declared in filesModified
+func (h *CodeToolsHandler) RegisterTaskCodeResources(server *mcp.Server) error {
+	if h.taskStorage == nil {
+		return fmt.Errorf("task storage is not set")
+	}
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: taskCodeURIPrefix + "{id}" + taskCodeURISuffix,
+		Name:        "Agent Task Code",
+		Description: "Indexed chunks of the files an agent task declared in filesModified, so the code a task touches can be read alongside the task",
+		MIMEType:    "application/json",
+	}, h.handleTaskCode)
+
+	return nil
+}
+
+// taskCodeFile is one indexed file an agent task declared, with its chunks
+type taskCodeFile struct {
+	Path         string               `json:"path"`
+	RelativePath string               `json:"relativePath"`
+	Language     string               `json:"language"`
+	LineCount    int                  `json:"lineCount"`
+	Chunks       []*storage.FileChunk `json:"chunks"`
+}
+
+// handleTaskCode serves hyperion://task/agent/{id}/code
+func (h *CodeToolsHandler) handleTaskCode(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	taskID := strings.TrimSuffix(strings.TrimPrefix(uri, taskCodeURIPrefix), taskCodeURISuffix)
+	if taskID == "" || strings.Contains(taskID, "/") {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	task, err := h.taskStorage.GetAgentTask(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve agent task: %w", err)
+	}
+
+	indexedFiles, err := h.codeIndexStorage.FindFilesDeclaredBy(task.FilesModified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find indexed files for task: %w", err)
+	}
+
+	files := make([]taskCodeFile, 0, len(indexedFiles))
+	for _, file := range indexedFiles {
+		chunks, err := h.codeIndexStorage.ListChunks(file.ID)
+		if err != nil {
+			h.logger.Warn("Failed to load chunks for task file",
+				zap.String("agentTaskId", taskID),
+				zap.String("path", file.Path),
+				zap.Error(err))
+			continue
+		}
+		files = append(files, taskCodeFile{
+			Path:         file.Path,
+			RelativePath: file.RelativePath,
+			Language:     file.Language,
+			LineCount:    file.LineCount,
+			Chunks:       chunks,
+		})
+	}
+
+	jsonData, err := json.MarshalIndent(map[string]interface{}{
+		"agentTaskId":      task.ID,
+		"agentName":        task.AgentName,
+		"status":           task.Status,
+		"filesModified":    task.FilesModified,
+		"unindexedEntries": unindexedEntries(task.FilesModified, indexedFiles),
+		"files":            files,
+		"count":            len(files),
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task code: %w", err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(jsonData),
+			},
+		},
+	}, nil
+}
+
+// unindexedEntries returns the declared entries that match no indexed file,
+// e.g. files not created yet or outside every indexed folder
+func unindexedEntries(declared []string, files []*storage.IndexedFile) []string {
+	missing := []string{}
+	for _, entry := range declared {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		found := false
+		for _, file := range files {
+			if storage.DeclaresPath([]string{entry}, file.Path, file.RelativePath) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, entry)
+		}
+	}
+	return missing
+}
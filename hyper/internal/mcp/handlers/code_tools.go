@@ -4,14 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"hyper/internal/ai-service/tools"
+	"hyper/internal/errcode"
 	"hyper/internal/mcp/embeddings"
 	"hyper/internal/mcp/scanner"
 	"hyper/internal/mcp/storage"
+	"hyper/internal/mcp/summarizer"
 	"hyper/internal/mcp/watcher"
+	"hyper/internal/priority"
+	"hyper/internal/reembed"
+	"hyper/internal/toolprogress"
+	"hyper/internal/undo"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/google/uuid"
@@ -26,8 +34,14 @@ type CodeToolsHandler struct {
 	embeddingClient  embeddings.EmbeddingClient
 	fileScanner      *scanner.FileScanner
 	fileWatcher      *watcher.FileWatcher
+	summarizer       summarizer.Summarizer
+	taskStorage      storage.TaskStorage
 	logger           *zap.Logger
 	metadataRegistry *ToolMetadataRegistry
+	workspaceRoots   *WorkspaceRoots
+	reembedMigrator  *reembed.Migrator // Optional: re-embedding after an embedding model change
+	undoManager      *undo.Manager     // Optional: stages folder removals for an undo window
+	folderTier       FolderTier        // Optional: archived code index folders
 }
 
 // NewCodeToolsHandler creates a new code tools handler
@@ -44,6 +58,7 @@ func NewCodeToolsHandler(
 		embeddingClient:  embeddingClient,
 		fileScanner:      scanner.NewFileScanner(),
 		fileWatcher:      fileWatcher,
+		summarizer:       summarizer.FromEnv(),
 		logger:           logger,
 	}
 }
@@ -53,21 +68,14 @@ func (h *CodeToolsHandler) SetMetadataRegistry(registry *ToolMetadataRegistry) {
 	h.metadataRegistry = registry
 }
 
+// SetReembedMigrator reports the migration's progress in code_index_status
+func (h *CodeToolsHandler) SetReembedMigrator(migrator *reembed.Migrator) {
+	h.reembedMigrator = migrator
+}
+
 // addToolWithMetadata adds a tool to the server and registers it for indexing
 func (h *CodeToolsHandler) addToolWithMetadata(server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandler) {
-	server.AddTool(tool, handler)
-	if h.metadataRegistry != nil {
-		h.metadataRegistry.RegisterTool(
-			tool.Name,
-			tool.Description,
-			map[string]interface{}{
-				"type":        "mcp-tool",
-				"name":        tool.Name,
-				"description": tool.Description,
-				"inputSchema": tool.InputSchema,
-			},
-		)
-	}
+	h.metadataRegistry.RegisterToolWithServer(server, tool, handler)
 }
 
 // RegisterCodeIndexTools registers all code indexing MCP tools
@@ -80,11 +88,47 @@ func (h *CodeToolsHandler) RegisterCodeIndexTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register code_index_search tool: %w", err)
 	}
 
+	if err := h.registerSearchBySnippet(server); err != nil {
+		return fmt.Errorf("failed to register code_index_search_by_snippet tool: %w", err)
+	}
+
+	if err := h.registerRecentChanges(server); err != nil {
+		return fmt.Errorf("failed to register code_index_recent_changes tool: %w", err)
+	}
+
+	if err := h.registerFindSymbol(server); err != nil {
+		return fmt.Errorf("failed to register code_index_find_symbol tool: %w", err)
+	}
+
+	if err := h.registerGetDefinition(server); err != nil {
+		return fmt.Errorf("failed to register code_index_get_definition tool: %w", err)
+	}
+
 	if err := h.registerStatus(server); err != nil {
 		return fmt.Errorf("failed to register code_index_status tool: %w", err)
 	}
 
-	h.logger.Info("Registered code indexing MCP tools", zap.Int("count", 3))
+	if err := h.registerUsage(server); err != nil {
+		return fmt.Errorf("failed to register code_index_usage tool: %w", err)
+	}
+
+	if err := h.registerConfigureSearch(server); err != nil {
+		return fmt.Errorf("failed to register code_index_configure_search tool: %w", err)
+	}
+
+	if err := h.registerExplain(server); err != nil {
+		return fmt.Errorf("failed to register code_index_explain tool: %w", err)
+	}
+
+	if err := h.registerWorkspaceRoots(server); err != nil {
+		return fmt.Errorf("failed to register code_index_workspace_roots tool: %w", err)
+	}
+
+	if err := h.registerRemoveFolder(server); err != nil {
+		return fmt.Errorf("failed to register code_index_remove_folder tool: %w", err)
+	}
+
+	h.logger.Info("Registered code indexing MCP tools", zap.Int("count", 12))
 	return nil
 }
 
@@ -100,6 +144,18 @@ func (h *CodeToolsHandler) registerScan(server *mcp.Server) error {
 					Type:        "string",
 					Description: "Absolute path to the folder to scan (optional: defaults to INDEX_SOURCE_PATH env var or current directory)",
 				},
+				"dryRun": {
+					Type:        "boolean",
+					Description: "Report what would be indexed (files, chunks, estimated tokens and cost per embedding provider) without embedding or storing anything. The folder does not need to be indexed yet.",
+				},
+				"minConcurrency": {
+					Type:        "integer",
+					Description: "Lower bound on files indexed at once; saved on the folder for later scans (0 restores the SCAN_MIN_CONCURRENCY default)",
+				},
+				"maxConcurrency": {
+					Type:        "integer",
+					Description: "Upper bound on files indexed at once; the scan scales between the bounds with CPU/IO load and embedding latency. Saved on the folder for later scans (0 restores the SCAN_MAX_CONCURRENCY default)",
+				},
 			},
 			Required: []string{},
 		},
@@ -120,7 +176,7 @@ func (h *CodeToolsHandler) registerScan(server *mcp.Server) error {
 func (h *CodeToolsHandler) registerSearch(server *mcp.Server) error {
 	tool := &mcp.Tool{
 		Name:        "code_index_search",
-		Description: "Search for code using natural language queries. Returns relevant code snippets with file paths and line numbers. Content can be retrieved as chunks (default) or full files.",
+		Description: "Search for code using natural language queries. Returns relevant code snippets with file paths and line numbers. Content can be retrieved as chunks (default) or full files. Without folderPath, results from all indexed folders are mixed using the search profile's per-folder weights, and each hit reports the folder it came from. Use mode 'hybrid' or 'keyword' when the query names exact identifiers.",
 		InputSchema: &jsonschema.Schema{
 			Type: "object",
 			Properties: map[string]*jsonschema.Schema{
@@ -134,13 +190,53 @@ func (h *CodeToolsHandler) registerSearch(server *mcp.Server) error {
 				},
 				"folderPath": {
 					Type:        "string",
-					Description: "Optional: filter results to a specific folder path",
+					Description: "Optional: filter results to a specific folder path. When omitted, all indexed folders allowed by the search profile are searched and merged",
+				},
+				"profile": {
+					Type:        "string",
+					Description: "Optional: workspace or agent search profile that sets folder weights and allow-list (default: 'default')",
+				},
+				"folders": {
+					Type:        "array",
+					Description: "Optional: folder allow-list for this search, overriding the profile's allow-list",
+					Items:       &jsonschema.Schema{Type: "string"},
+				},
+				"folderWeights": {
+					Type:        "object",
+					Description: "Optional: per-folder relevance weights for this search, e.g. {\"/repo/app\": 1.5, \"/repo/vendor-lib\": 0.5}. Layered over the profile's weights; 0 excludes a folder",
 				},
 				"retrieve": {
 					Type:        "string",
 					Description: "Content retrieval mode: 'chunk' (default - return matching chunk only) or 'full' (return entire file content)",
 					Enum:        []interface{}{"chunk", "full"},
 				},
+				"timeoutMs": {
+					Type:        "number",
+					Description: "Optional: time budget in milliseconds (max 60000). Returns the best results gathered within the budget with truncated=true instead of waiting for slow folders",
+				},
+				"commentLanguage": {
+					Type:        "string",
+					Description: "Optional: only return chunks whose comments are written in this language, as an ISO 639-1 code (e.g. 'de'). Each hit reports its detected commentLanguage",
+				},
+				"vector": {
+					Type:        "string",
+					Description: "Optional: embedding to match the query against: 'code', 'summary' (the chunk's natural-language summary, best for intent queries) or 'fused' (default - both, keeping each chunk's better score). Folders indexed before named vectors ignore it",
+					Enum:        []interface{}{storage.VectorModeCode, storage.VectorModeSummary, storage.VectorModeFused},
+				},
+				"mode": {
+					Type:        "string",
+					Description: "Optional: 'vector' (embedding similarity), 'keyword' (BM25 over the query's words; finds exact identifiers such as NewHTTPBridge) or 'hybrid' (both rankings fused). Default: CODE_INDEX_SEARCH_MODE, else 'vector'",
+					Enum:        []interface{}{storage.SearchModeVector, storage.SearchModeKeyword, storage.SearchModeHybrid},
+				},
+				"fusion": {
+					Type:        "string",
+					Description: "Optional: how hybrid mode combines the rankings: 'rrf' (reciprocal rank fusion, default) or 'weighted' (scores scaled to each ranking's best hit and mixed by keywordWeight)",
+					Enum:        []interface{}{storage.FusionRRF, storage.FusionWeighted},
+				},
+				"keywordWeight": {
+					Type:        "number",
+					Description: "Optional: share of the keyword ranking in weighted fusion, from 0 to 1 (default: CODE_INDEX_KEYWORD_WEIGHT, else 0.3)",
+				},
 			},
 			Required: []string{"query"},
 		},
@@ -157,11 +253,64 @@ func (h *CodeToolsHandler) registerSearch(server *mcp.Server) error {
 	return nil
 }
 
+// registerSearchBySnippet registers the code_index_search_by_snippet tool
+func (h *CodeToolsHandler) registerSearchBySnippet(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "code_index_search_by_snippet",
+		Description: "Find code similar to a given code snippet (query by example) across indexed folders. Use it for 'where else do we do this pattern' questions: paste the snippet instead of describing it. Results can be restricted to one or more languages and exclude the file the snippet came from.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"snippet": {
+					Type:        "string",
+					Description: "Code snippet to find similar code for",
+				},
+				"language": {
+					Type:        "string",
+					Description: "Optional: only return code in this language (e.g., 'go', 'typescript', 'python')",
+				},
+				"languages": {
+					Type:        "array",
+					Description: "Optional: only return code in any of these languages",
+					Items:       &jsonschema.Schema{Type: "string"},
+				},
+				"excludeFile": {
+					Type:        "string",
+					Description: "Optional: absolute or folder-relative path of the file the snippet was taken from, so it does not match itself",
+				},
+				"limit": {
+					Type:        "number",
+					Description: "Maximum number of results to return (default: 10, max: 50)",
+				},
+				"folderPath": {
+					Type:        "string",
+					Description: "Optional: filter results to a specific folder path. When omitted, all indexed folders allowed by the search profile are searched and merged",
+				},
+				"profile": {
+					Type:        "string",
+					Description: "Optional: workspace or agent search profile that sets folder weights and allow-list (default: 'default')",
+				},
+			},
+			Required: []string{"snippet"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		return h.handleSearchBySnippet(ctx, args)
+	})
+
+	return nil
+}
+
 // registerStatus registers the code_index_status tool
 func (h *CodeToolsHandler) registerStatus(server *mcp.Server) error {
 	tool := &mcp.Tool{
 		Name:        "code_index_status",
-		Description: "Get the current status of the code index, including indexed folders, file counts, and last scan times.",
+		Description: "Get the current status of the code index, including indexed folders, file counts, and last scan times. After an embedding model change, reembedding reports the progress of re-embedding the index into a collection with the new dimensions.",
 		InputSchema: &jsonschema.Schema{
 			Type:       "object",
 			Properties: map[string]*jsonschema.Schema{},
@@ -175,8 +324,49 @@ func (h *CodeToolsHandler) registerStatus(server *mcp.Server) error {
 	return nil
 }
 
+// registerConfigureSearch registers the code_index_configure_search tool
+func (h *CodeToolsHandler) registerConfigureSearch(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "code_index_configure_search",
+		Description: "Configure multi-folder search routing for a workspace or agent: per-folder relevance weights and a folder allow-list applied by code_index_search when no folderPath is given. Replaces the existing profile with the same name.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"profile": {
+					Type:        "string",
+					Description: "Workspace or agent name the profile applies to ('default' applies when code_index_search names no profile)",
+				},
+				"folderWeights": {
+					Type:        "object",
+					Description: "Per-folder relevance weights, e.g. {\"/repo/app\": 1.5, \"/repo/vendor-lib\": 0.5}. Weights multiply similarity scores; 0 excludes a folder; unlisted folders use 1",
+				},
+				"allowedFolders": {
+					Type:        "array",
+					Description: "Folders that may appear in results (subfolders included). Empty allows all indexed folders",
+					Items:       &jsonschema.Schema{Type: "string"},
+				},
+			},
+			Required: []string{"profile"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		return h.handleConfigureSearch(ctx, args)
+	})
+
+	return nil
+}
+
 // handleScan handles the code_index_scan tool
 func (h *CodeToolsHandler) handleScan(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	if dryRun, _ := args["dryRun"].(bool); dryRun {
+		return h.handleScanDryRun(args)
+	}
+
 	// Always use project root (no manual folderPath parameter)
 	projectRoot := tools.GetProjectRoot()
 
@@ -190,6 +380,10 @@ func (h *CodeToolsHandler) handleScan(ctx context.Context, args map[string]inter
 	}
 
 	collectionName := mapping.QdrantCollection
+	namedVectors, err := h.qdrantClient.UsesNamedVectors(ctx, collectionName)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to read collection '%s': %s", collectionName, err.Error())), nil
+	}
 
 	// Get folder (for legacy compatibility with MongoDB storage)
 	folder, err := h.codeIndexStorage.GetFolderByPath(projectRoot)
@@ -197,6 +391,16 @@ func (h *CodeToolsHandler) handleScan(ctx context.Context, args map[string]inter
 		return createCodeIndexErrorResult(fmt.Sprintf("folder metadata not found for: %s", projectRoot)), nil
 	}
 
+	// Save new concurrency bounds before scanning with them
+	if bounds, changed, err := scanConcurrencyArgs(args, folder.ScanConcurrency); err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil
+	} else if changed {
+		if err := h.codeIndexStorage.SetFolderScanConcurrency(folder.ID, bounds); err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to save scan concurrency: %s", err.Error())), nil
+		}
+		folder.ScanConcurrency = bounds
+	}
+
 	// Update folder status to scanning
 	if err := h.codeIndexStorage.UpdateFolderStatus(folder.ID, "scanning", ""); err != nil {
 		return createCodeIndexErrorResult(fmt.Sprintf("failed to update folder status: %s", err.Error())), nil
@@ -209,22 +413,41 @@ func (h *CodeToolsHandler) handleScan(ctx context.Context, args map[string]inter
 		return createCodeIndexErrorResult(fmt.Sprintf("failed to scan directory: %s", err.Error())), nil
 	}
 
+	var mu sync.Mutex
 	filesIndexed := 0
 	filesUpdated := 0
 	filesSkipped := 0
-
-	// Process each file
-	for _, scannedFile := range scannedFiles {
+	var embeddedTokens int64
+
+	// Indexing is bulk work: it yields to interactive searches, and still
+	// finishes if the caller goes away
+	bulkCtx := priority.WithLevel(context.WithoutCancel(ctx), priority.Bulk)
+
+	// Index files concurrently, as many at once as system load allows
+	limiter := scanner.NewFolderLimiter(folder)
+	embedder := limiter.Embedder(h.embeddingClient)
+	scanProgress := toolprogress.NewCounter(ctx, len(scannedFiles))
+	limiter.Run(bulkCtx, len(scannedFiles), func(i int) {
+		scannedFile := scannedFiles[i]
 		scannedFile.FolderID = folder.ID
+		defer scanProgress.Done(scannedFile.RelativePath)
 
 		// Check if file already exists
 		existingFile, _ := h.codeIndexStorage.GetFileByPath(scannedFile.Path)
 
+		mu.Lock()
 		if existingFile != nil {
 			// Check if file has changed
 			if existingFile.SHA256 == scannedFile.SHA256 {
 				filesSkipped++
-				continue
+				mu.Unlock()
+				// Files indexed before symbols were extracted get them now
+				if existingFile.SymbolsAt.IsZero() {
+					if err := scanner.IndexFileSymbols(h.codeIndexStorage, existingFile); err != nil {
+						h.logger.Warn("Failed to index symbols", zap.String("file", existingFile.Path), zap.Error(err))
+					}
+				}
+				return
 			}
 			filesUpdated++
 			scannedFile.ID = existingFile.ID
@@ -232,19 +455,27 @@ func (h *CodeToolsHandler) handleScan(ctx context.Context, args map[string]inter
 			filesIndexed++
 			scannedFile.ID = uuid.New().String()
 		}
+		mu.Unlock()
 
 		// Create chunks
 		chunks, err := h.fileScanner.CreateFileChunks(scannedFile.ID, scannedFile.Path)
 		if err != nil {
 			h.logger.Warn("Failed to create chunks", zap.String("file", scannedFile.Path), zap.Error(err))
-			continue
+			return
 		}
 
 		// Generate embeddings for chunks
 		var qdrantPoints []storage.CodeIndexPoint
 		for _, chunk := range chunks {
-			// Generate embedding
-			embedding, err := h.embeddingClient.CreateEmbedding(chunk.Content)
+			// Optionally summarize, then embed code and summary for the collection's vector layout
+			summary, _, err := summarizer.Enrich(bulkCtx, h.summarizer, scannedFile.Language, chunk.Content)
+			if err != nil {
+				h.logger.Warn("Failed to summarize chunk", zap.String("file", scannedFile.Path), zap.Error(err))
+			}
+			chunk.Summary = summary
+
+			// Generate embeddings
+			embedding, summaryEmbedding, tokens, err := summarizer.EmbedChunk(bulkCtx, embedder, summary, chunk.Content, namedVectors)
 			if err != nil {
 				h.logger.Warn("Failed to create embedding",
 					zap.String("file", scannedFile.Path),
@@ -252,14 +483,18 @@ func (h *CodeToolsHandler) handleScan(ctx context.Context, args map[string]inter
 					zap.Error(err))
 				continue
 			}
+			mu.Lock()
+			embeddedTokens += tokens
+			mu.Unlock()
 
 			// Create Qdrant point
 			pointID := fmt.Sprintf("%s_%d", scannedFile.ID, chunk.ChunkNum)
 			chunk.VectorID = pointID
 
 			point := storage.CodeIndexPoint{
-				ID:     pointID,
-				Vector: embedding,
+				ID:            pointID,
+				Vector:        embedding,
+				SummaryVector: summaryEmbedding,
 				Payload: map[string]interface{}{
 					"fileId":       scannedFile.ID,
 					"folderId":     folder.ID,
@@ -273,6 +508,10 @@ func (h *CodeToolsHandler) handleScan(ctx context.Context, args map[string]inter
 					"content":      chunk.Content,
 				},
 			}
+			if chunk.Summary != "" {
+				point.Payload["summary"] = chunk.Summary
+			}
+			storage.TagCommentLanguage(point.Payload, chunk.Content)
 			qdrantPoints = append(qdrantPoints, point)
 
 			// Save chunk to MongoDB
@@ -283,16 +522,18 @@ func (h *CodeToolsHandler) handleScan(ctx context.Context, args map[string]inter
 
 		// Upload vectors to Qdrant (using the correct collection for this path)
 		if len(qdrantPoints) > 0 {
-			if err := h.qdrantClient.UpsertCodeIndexPoints(collectionName, qdrantPoints); err != nil {
+			if err := h.qdrantClient.UpsertCodeIndexPointsContext(bulkCtx, collectionName, qdrantPoints); err != nil {
 				h.logger.Warn("Failed to upsert vectors", zap.String("file", scannedFile.Path), zap.Error(err))
 			}
 		}
 
-		// Save file metadata to MongoDB
+		// Save file metadata to MongoDB, then the symbols it declares
 		if err := h.codeIndexStorage.UpsertFile(scannedFile); err != nil {
 			h.logger.Warn("Failed to save file", zap.Error(err))
+		} else if err := scanner.IndexFileSymbols(h.codeIndexStorage, scannedFile); err != nil {
+			h.logger.Warn("Failed to index symbols", zap.String("file", scannedFile.Path), zap.Error(err))
 		}
-	}
+	})
 
 	// Update folder status and scan time
 	if err := h.codeIndexStorage.UpdateFolderStatus(folder.ID, "active", ""); err != nil {
@@ -307,14 +548,18 @@ func (h *CodeToolsHandler) handleScan(ctx context.Context, args map[string]inter
 		zap.String("folderID", folder.ID),
 		zap.Int("filesIndexed", filesIndexed),
 		zap.Int("filesUpdated", filesUpdated),
-		zap.Int("filesSkipped", filesSkipped))
+		zap.Int("filesSkipped", filesSkipped),
+		zap.Int64("estimatedTokens", embeddedTokens),
+		zap.Int("peakConcurrency", limiter.Stats().Peak))
 
 	jsonData, _ := json.Marshal(map[string]interface{}{
-		"success":      true,
-		"filesIndexed": filesIndexed,
-		"filesUpdated": filesUpdated,
-		"filesSkipped": filesSkipped,
-		"totalFiles":   len(scannedFiles),
+		"success":       true,
+		"filesIndexed":  filesIndexed,
+		"filesUpdated":  filesUpdated,
+		"filesSkipped":  filesSkipped,
+		"totalFiles":    len(scannedFiles),
+		"estimatedCost": embeddings.PricingFor(h.embeddingClient).Estimate(embeddedTokens),
+		"concurrency":   limiter.Stats(),
 	})
 
 	return &mcp.CallToolResult{
@@ -324,6 +569,75 @@ func (h *CodeToolsHandler) handleScan(ctx context.Context, args map[string]inter
 	}, nil
 }
 
+// scanConcurrencyArgs applies the minConcurrency and maxConcurrency arguments
+// to a folder's current bounds. changed is false when neither was given; nil
+// bounds mean both fall back to the defaults.
+func scanConcurrencyArgs(args map[string]interface{}, current *storage.ScanConcurrency) (bounds *storage.ScanConcurrency, changed bool, err error) {
+	next := storage.ScanConcurrency{}
+	if current != nil {
+		next = *current
+	}
+	for key, field := range map[string]*int{"minConcurrency": &next.Min, "maxConcurrency": &next.Max} {
+		raw, ok := args[key]
+		if !ok {
+			continue
+		}
+		n, isNumber := raw.(float64)
+		if !isNumber || n != float64(int(n)) {
+			return nil, false, fmt.Errorf("%s must be an integer", key)
+		}
+		*field = int(n)
+		changed = true
+	}
+	if !changed {
+		return current, false, nil
+	}
+	if err := next.Validate(); err != nil {
+		return nil, false, err
+	}
+	if next == (storage.ScanConcurrency{}) {
+		return nil, true, nil
+	}
+	return &next, true, nil
+}
+
+// handleScanDryRun estimates a scan of folderPath (or the project root) without
+// embedding anything or touching the index
+func (h *CodeToolsHandler) handleScanDryRun(args map[string]interface{}) (*mcp.CallToolResult, error) {
+	folderPath := tools.GetProjectRoot()
+	if path, ok := args["folderPath"].(string); ok && path != "" {
+		if !filepath.IsAbs(path) {
+			return createCodeIndexErrorResult("folderPath must be an absolute path"), nil
+		}
+		folderPath = filepath.Clean(path)
+	}
+
+	estimate, err := h.fileScanner.EstimateScan(folderPath, h.unchangedFile)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to scan directory: %s", err.Error())), nil
+	}
+	activeCost := embeddings.PricingFor(h.embeddingClient).Estimate(estimate.EstimatedTokens)
+	estimate.ActiveCost = &activeCost
+
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"success":  true,
+		"dryRun":   true,
+		"estimate": estimate,
+	})
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(jsonData)},
+		},
+	}, nil
+}
+
+// unchangedFile reports whether a scanned file is already indexed with the same content
+func (h *CodeToolsHandler) unchangedFile(file *storage.IndexedFile) bool {
+	existing, _ := h.codeIndexStorage.GetFileByPath(file.Path)
+	return existing != nil && existing.SHA256 == file.SHA256
+}
+
 // handleSearch handles the code_index_search tool
 func (h *CodeToolsHandler) handleSearch(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
 	query, ok := args["query"].(string)
@@ -347,111 +661,426 @@ func (h *CodeToolsHandler) handleSearch(ctx context.Context, args map[string]int
 		}
 	}
 
-	// Get current project root
-	projectRoot := tools.GetProjectRoot()
+	commentLanguage, err := parseLanguageArg(args, "commentLanguage")
+	if err != nil {
+		return createCodeIndexErrorResult(err.Error()), nil
+	}
+	filter := commentLanguageFilter(commentLanguage)
 
-	// Lookup collection name from code_index_map
-	mapping, err := h.codeIndexStorage.GetPathMapping(projectRoot)
+	vectorMode, err := storage.ParseVectorMode(getStringField(args, "vector", ""))
 	if err != nil {
-		return createCodeIndexErrorResult(fmt.Sprintf("failed to lookup collection mapping: %s", err.Error())), nil
+		return createCodeIndexErrorResult(err.Error()), nil
 	}
-	if mapping == nil {
-		return createCodeIndexErrorResult(fmt.Sprintf("no code index found for project root '%s' - please restart coordinator to auto-index, or the path has not been indexed yet", projectRoot)), nil
+
+	search, err := hybridSearchArgs(args)
+	if err != nil {
+		return createCodeIndexErrorResult(err.Error()), nil
 	}
 
-	collectionName := mapping.QdrantCollection
+	budget, err := newSearchBudget(args)
+	if err != nil {
+		return createCodeIndexErrorResult(err.Error()), nil
+	}
+
+	folderPath, _ := args["folderPath"].(string)
 
-	// Generate embedding for query
-	queryEmbedding, err := h.embeddingClient.CreateEmbedding(query)
+	// Resolve the workspace/agent search profile, with per-call overrides
+	profileName := storage.DefaultSearchProfile
+	if name, ok := args["profile"].(string); ok && name != "" {
+		profileName = name
+	}
+	storedProfile, err := h.codeIndexStorage.GetSearchProfile(profileName)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to load search profile: %s", err.Error())), nil
+	}
+	profile, err := searchProfileOverrides(storedProfile, profileName, args)
 	if err != nil {
-		return createCodeIndexErrorResult(fmt.Sprintf("failed to create query embedding: %s", err.Error())), nil
+		return createCodeIndexErrorResult(err.Error()), nil
 	}
 
-	// Search in Qdrant using the correct collection
-	searchResp, err := h.qdrantClient.SearchCodeIndex(collectionName, queryEmbedding, limit)
+	// Route the search to the collections of every eligible indexed folder
+	mappings, err := h.codeIndexStorage.ListPathMappings()
 	if err != nil {
-		return createCodeIndexErrorResult(fmt.Sprintf("failed to search in collection '%s': %s", collectionName, err.Error())), nil
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to lookup collection mapping: %s", err.Error())), nil
+	}
+	if len(mappings) == 0 {
+		projectRoot := tools.GetProjectRoot()
+		return createCodeIndexErrorResult(fmt.Sprintf("no code index found for project root '%s' - please restart coordinator to auto-index, or the path has not been indexed yet", projectRoot)), nil
 	}
 
-	// Build results
-	var results []storage.SearchResult
-	for _, hit := range searchResp.Result {
-		result := storage.SearchResult{
-			Score: hit.Score,
+	targets := resolveSearchTargets(mappings, folderPath, profile)
+	if len(targets) == 0 {
+		if folderPath != "" {
+			return createCodeIndexErrorResult(fmt.Sprintf("no code index found covering folder '%s'", folderPath)), nil
 		}
+		return createCodeIndexErrorResult(fmt.Sprintf("no indexed folders are allowed by search profile '%s'", profileName)), nil
+	}
 
-		if fileID, ok := hit.Payload["fileId"].(string); ok {
-			result.FileID = fileID
-		}
-		if folderID, ok := hit.Payload["folderId"].(string); ok {
-			result.FolderID = folderID
-		}
-		if folderPath, ok := hit.Payload["folderPath"].(string); ok {
-			result.FolderPath = folderPath
-		}
-		if filePath, ok := hit.Payload["filePath"].(string); ok {
-			result.FilePath = filePath
-		}
-		if relativePath, ok := hit.Payload["relativePath"].(string); ok {
-			result.RelativePath = relativePath
-		}
-		if language, ok := hit.Payload["language"].(string); ok {
-			result.Language = language
+	searchCtx, cancel := budget.context(ctx)
+	defer cancel()
+
+	// Generate embedding for query; keyword searches need none
+	var queryEmbedding []float32
+	completed := true
+	if search.Mode != storage.SearchModeKeyword {
+		queryEmbedding, completed, err = runWithinBudget(searchCtx, budget, func() ([]float32, error) {
+			return embeddings.CreateEmbeddingContext(searchCtx, h.embeddingClient, query)
+		})
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to create query embedding: %s", err.Error())), nil
 		}
-		if chunkNum, ok := hit.Payload["chunkNum"].(float64); ok {
-			result.ChunkNum = int(chunkNum)
+	}
+	truncated := !completed
+
+	// Search all target collections concurrently, keeping whatever arrives within the budget
+	type targetResponse struct {
+		target searchTarget
+		resp   *storage.CodeIndexSearchResponse
+		err    error
+	}
+	responses := make(chan targetResponse, len(targets))
+	if completed {
+		for _, target := range targets {
+			go func(target searchTarget) {
+				resp, err := h.qdrantClient.SearchCodeIndexHybridContext(searchCtx, target.Collection, search, vectorMode, query, queryEmbedding, limit, filter)
+				responses <- targetResponse{target: target, resp: resp, err: err}
+			}(target)
 		}
-		if startLine, ok := hit.Payload["startLine"].(float64); ok {
-			result.StartLine = int(startLine)
+	}
+
+	var results []storage.SearchResult
+	searchedFolders := make([]string, 0, len(targets))
+	pending := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		pending[target.FolderPath] = true
+	}
+
+	deadline := budget.timer()
+collect:
+	for completed && len(pending) > 0 {
+		select {
+		case r := <-responses:
+			delete(pending, r.target.FolderPath)
+			if r.err != nil {
+				if len(targets) == 1 {
+					return createCodeIndexErrorResult(fmt.Sprintf("failed to search in collection '%s': %s", r.target.Collection, r.err.Error())), nil
+				}
+				h.logger.Warn("Failed to search folder collection",
+					zap.String("folder", r.target.FolderPath),
+					zap.String("collection", r.target.Collection),
+					zap.Error(r.err))
+				continue
+			}
+			searchedFolders = append(searchedFolders, r.target.FolderPath)
+			for _, hit := range r.resp.Result {
+				results = append(results, codeSearchResultFromHit(r.target, hit.Score, hit.Payload))
+			}
+		case <-deadline:
+			truncated = true
+			break collect
+		case <-ctx.Done():
+			truncated = true
+			break collect
 		}
-		if endLine, ok := hit.Payload["endLine"].(float64); ok {
-			result.EndLine = int(endLine)
+	}
+
+	pendingFolders := make([]string, 0, len(pending))
+	for _, target := range targets {
+		if pending[target.FolderPath] {
+			pendingFolders = append(pendingFolders, target.FolderPath)
 		}
+	}
+
+	// Apply folder weights and allow-list, then keep the overall top hits
+	results = mergeSearchResults(results, folderPath, profile, limit)
+	archive := h.useFolders(folderPath, targets, results)
+
+	// Attach folder metadata so callers can tell which repo each hit came from
+	folders, err := h.codeIndexStorage.ListFolders()
+	if err != nil {
+		h.logger.Warn("Failed to load folder metadata for search results", zap.Error(err))
+	}
+	for i := range results {
+		results[i].Folder = matchIndexedFolder(folders, results[i].FolderID, results[i].FolderPath)
+	}
+
+	h.attachRecentTasks(results)
+	h.codeIndexStorage.RecordSearchHits(results)
 
-		// Handle content based on retrieve mode
-		if retrieveMode == "chunk" {
-			// Default: return just the matching chunk content from Qdrant
-			if content, ok := hit.Payload["content"].(string); ok {
-				result.Content = content
+	if retrieveMode == "full" {
+		// Fetch entire file content from MongoDB; chunk content is kept as fallback
+		for i := range results {
+			if results[i].FileID == "" {
+				continue
 			}
-		} else if retrieveMode == "full" {
-			// Fetch entire file content from MongoDB
-			if result.FileID != "" {
-				allChunks, err := h.codeIndexStorage.GetChunksByFileID(result.FileID)
-				if err != nil {
-					h.logger.Warn("Failed to fetch full file content",
-						zap.String("fileID", result.FileID),
-						zap.Error(err))
-					// Fallback to chunk content
-					if content, ok := hit.Payload["content"].(string); ok {
-						result.Content = content
-					}
-				} else {
-					// Concatenate all chunks to build full file content
-					var fullContent strings.Builder
-					for _, chunk := range allChunks {
-						fullContent.WriteString(chunk.Content)
-					}
-					result.Content = fullContent.String()
-					result.FullFileRetrieved = true
-				}
+			if budget.expired() {
+				// Out of time: remaining hits keep their chunk content
+				truncated = true
+				break
+			}
+			allChunks, err := h.codeIndexStorage.GetChunksByFileID(results[i].FileID)
+			if err != nil {
+				h.logger.Warn("Failed to fetch full file content",
+					zap.String("fileID", results[i].FileID),
+					zap.Error(err))
+				continue
+			}
+			// Concatenate all chunks to build full file content
+			var fullContent strings.Builder
+			for _, chunk := range allChunks {
+				fullContent.WriteString(chunk.Content)
 			}
+			results[i].Content = fullContent.String()
+			results[i].FullFileRetrieved = true
 		}
-
-		results = append(results, result)
 	}
 
 	h.logger.Info("Code search completed",
 		zap.String("query", query),
 		zap.String("retrieveMode", retrieveMode),
+		zap.String("mode", search.Mode),
+		zap.String("profile", profileName),
+		zap.Strings("folders", searchedFolders),
+		zap.Bool("truncated", truncated),
 		zap.Int("results", len(results)))
 
-	jsonData, _ := json.Marshal(map[string]interface{}{
+	response := map[string]interface{}{
 		"success":      true,
 		"query":        query,
 		"retrieveMode": retrieveMode,
+		"vector":       vectorMode,
+		"mode":         search.Mode,
+		"profile":      profileName,
+		"folders":      searchedFolders,
 		"results":      results,
 		"count":        len(results),
+		"truncated":    truncated,
+	}
+	if search.Mode == storage.SearchModeHybrid {
+		response["fusion"] = search.Fusion
+	}
+	if archive != nil {
+		response["archive"] = archive
+		response["message"] = fmt.Sprintf("Folder %s is %s: its vectors are being restored, search again shortly", archive.Collection, archive.Tier)
+	}
+	if budget.limited() {
+		response["elapsedMs"] = budget.elapsedMs()
+		if len(pendingFolders) > 0 {
+			response["pendingFolders"] = pendingFolders
+		}
+	}
+	return structuredToolResult(response), nil
+}
+
+// SearchCode searches folderPath, or every indexed folder allowed by the
+// default search profile, in the CODE_INDEX_SEARCH_MODE mode, and returns the
+// top hits by weighted score. Folders that fail are skipped unless every one
+// does.
+func (h *CodeToolsHandler) SearchCode(query, folderPath string, limit int) ([]*storage.SearchResult, error) {
+	storedProfile, err := h.codeIndexStorage.GetSearchProfile(storage.DefaultSearchProfile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load search profile: %w", err)
+	}
+	profile, err := searchProfileOverrides(storedProfile, storage.DefaultSearchProfile, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := h.codeIndexStorage.ListPathMappings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup collection mapping: %w", err)
+	}
+	targets := resolveSearchTargets(mappings, folderPath, profile)
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	search, err := storage.HybridSearchFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	var queryEmbedding []float32
+	if search.Mode != storage.SearchModeKeyword {
+		queryEmbedding, err = embeddings.CreateEmbeddingContext(ctx, h.embeddingClient, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create query embedding: %w", err)
+		}
+	}
+
+	var results []storage.SearchResult
+	var lastErr error
+	for _, target := range targets {
+		resp, err := h.qdrantClient.SearchCodeIndexHybridContext(ctx, target.Collection, search, storage.VectorModeFused, query, queryEmbedding, limit, nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, hit := range resp.Result {
+			results = append(results, codeSearchResultFromHit(target, hit.Score, hit.Payload))
+		}
+	}
+	if len(results) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	merged := mergeSearchResults(results, folderPath, profile, limit)
+	h.useFolders(folderPath, targets, merged)
+	h.codeIndexStorage.RecordSearchHits(merged)
+	hits := make([]*storage.SearchResult, len(merged))
+	for i := range merged {
+		hits[i] = &merged[i]
+	}
+	return hits, nil
+}
+
+// handleSearchBySnippet handles the code_index_search_by_snippet tool
+func (h *CodeToolsHandler) handleSearchBySnippet(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	snippet, _ := args["snippet"].(string)
+	if strings.TrimSpace(snippet) == "" {
+		return createCodeIndexErrorResult("snippet is required and must be a non-empty string"), nil
+	}
+
+	limit := 10
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	languages, err := parseSnippetLanguages(args)
+	if err != nil {
+		return createCodeIndexErrorResult(err.Error()), nil
+	}
+	excludeFile, _ := args["excludeFile"].(string)
+	folderPath, _ := args["folderPath"].(string)
+
+	profileName := storage.DefaultSearchProfile
+	if name, ok := args["profile"].(string); ok && name != "" {
+		profileName = name
+	}
+	storedProfile, err := h.codeIndexStorage.GetSearchProfile(profileName)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to load search profile: %s", err.Error())), nil
+	}
+	profile, err := searchProfileOverrides(storedProfile, profileName, args)
+	if err != nil {
+		return createCodeIndexErrorResult(err.Error()), nil
+	}
+
+	mappings, err := h.codeIndexStorage.ListPathMappings()
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to lookup collection mapping: %s", err.Error())), nil
+	}
+	targets := resolveSearchTargets(mappings, folderPath, profile)
+	if len(targets) == 0 {
+		if folderPath != "" {
+			return createCodeIndexErrorResult(fmt.Sprintf("no code index found covering folder '%s'", folderPath)), nil
+		}
+		return createCodeIndexErrorResult(fmt.Sprintf("no indexed folders are allowed by search profile '%s'", profileName)), nil
+	}
+
+	// The snippet is embedded as-is, so it lands near chunks with the same shape
+	snippetEmbedding, err := embeddings.CreateEmbeddingContext(ctx, h.embeddingClient, snippet)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to create snippet embedding: %s", err.Error())), nil
+	}
+
+	// Over-fetch when excluding the source file, since its own chunks rank first
+	fetchLimit := limit
+	if excludeFile != "" {
+		fetchLimit = limit * 2
+	}
+	filter := snippetLanguageFilter(languages)
+
+	var results []storage.SearchResult
+	searchedFolders := make([]string, 0, len(targets))
+	for _, target := range targets {
+		resp, err := h.qdrantClient.SearchCodeIndexFilteredContext(ctx, target.Collection, snippetEmbedding, fetchLimit, filter)
+		if err != nil {
+			if len(targets) == 1 {
+				return createCodeIndexErrorResult(fmt.Sprintf("failed to search in collection '%s': %s", target.Collection, err.Error())), nil
+			}
+			h.logger.Warn("Failed to search folder collection",
+				zap.String("folder", target.FolderPath),
+				zap.String("collection", target.Collection),
+				zap.Error(err))
+			continue
+		}
+		searchedFolders = append(searchedFolders, target.FolderPath)
+		for _, hit := range resp.Result {
+			results = append(results, codeSearchResultFromHit(target, hit.Score, hit.Payload))
+		}
+	}
+
+	results = excludeSnippetSource(results, excludeFile)
+	results = mergeSearchResults(results, folderPath, profile, limit)
+
+	folders, err := h.codeIndexStorage.ListFolders()
+	if err != nil {
+		h.logger.Warn("Failed to load folder metadata for search results", zap.Error(err))
+	}
+	for i := range results {
+		results[i].Folder = matchIndexedFolder(folders, results[i].FolderID, results[i].FolderPath)
+	}
+
+	h.attachRecentTasks(results)
+	h.codeIndexStorage.RecordSearchHits(results)
+
+	h.logger.Info("Snippet search completed",
+		zap.Int("snippetLength", len(snippet)),
+		zap.Strings("languages", languages),
+		zap.String("profile", profileName),
+		zap.Strings("folders", searchedFolders),
+		zap.Int("results", len(results)))
+
+	response := map[string]interface{}{
+		"success":   true,
+		"languages": languages,
+		"profile":   profileName,
+		"folders":   searchedFolders,
+		"results":   results,
+		"count":     len(results),
+	}
+	return structuredToolResult(response), nil
+}
+
+// handleConfigureSearch handles the code_index_configure_search tool
+func (h *CodeToolsHandler) handleConfigureSearch(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	name, ok := args["profile"].(string)
+	if !ok || name == "" {
+		return createCodeIndexErrorResult("profile is required and must be a string"), nil
+	}
+
+	weights, err := parseFolderWeights(args["folderWeights"])
+	if err != nil {
+		return createCodeIndexErrorResult(err.Error()), nil
+	}
+
+	profile := &storage.SearchProfile{
+		Name:          name,
+		FolderWeights: weights,
+	}
+	if raw, ok := args["allowedFolders"]; ok {
+		profile.AllowedFolders, err = parseStringList(raw, "allowedFolders")
+		if err != nil {
+			return createCodeIndexErrorResult(err.Error()), nil
+		}
+	}
+
+	if err := h.codeIndexStorage.UpsertSearchProfile(profile); err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to save search profile: %s", err.Error())), nil
+	}
+
+	h.logger.Info("Code search profile configured",
+		zap.String("profile", name),
+		zap.Int("folderWeights", len(profile.FolderWeights)),
+		zap.Int("allowedFolders", len(profile.AllowedFolders)))
+
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"success": true,
+		"profile": profile,
 	})
 
 	return &mcp.CallToolResult{
@@ -501,13 +1130,20 @@ func (h *CodeToolsHandler) handleStatus(ctx context.Context) (*mcp.CallToolResul
 	}
 
 	// Return in UI-expected format
-	jsonData, _ := json.Marshal(map[string]interface{}{
+	response := map[string]interface{}{
 		"totalFolders":  status.TotalFolders,
 		"totalFiles":    status.TotalFiles,
 		"totalSize":     totalSize,
 		"watcherStatus": watcherStatus,
 		"folders":       uiFolders,
-	})
+		"chunkStorage":  chunkStorageReport(status, h.codeIndexStorage.ChunkCompression()),
+	}
+	if h.reembedMigrator != nil {
+		if progress := h.reembedMigrator.Progress(); progress != nil {
+			response["reembedding"] = progress
+		}
+	}
+	jsonData, _ := json.Marshal(response)
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -516,6 +1152,23 @@ func (h *CodeToolsHandler) handleStatus(ctx context.Context) (*mcp.CallToolResul
 	}, nil
 }
 
+// chunkStorageReport describes how much storage chunk compression saves
+func chunkStorageReport(status *storage.IndexStatus, compression string) map[string]interface{} {
+	saved := status.ChunkContentBytes - status.ChunkStoredBytes
+	savedPercent := 0.0
+	if status.ChunkContentBytes > 0 {
+		savedPercent = math.Round(float64(saved)/float64(status.ChunkContentBytes)*1000) / 10
+	}
+	return map[string]interface{}{
+		"compression":      compression,
+		"compressedChunks": status.CompressedChunks,
+		"contentBytes":     status.ChunkContentBytes,
+		"storedBytes":      status.ChunkStoredBytes,
+		"savedBytes":       saved,
+		"savedPercent":     savedPercent,
+	}
+}
+
 // extractArguments safely extracts arguments from CallToolRequest
 func (h *CodeToolsHandler) extractArguments(req *mcp.CallToolRequest) (map[string]interface{}, error) {
 	if req.Params.Arguments == nil || len(req.Params.Arguments) == 0 {
@@ -591,10 +1244,5 @@ func validateSafeIndexPath(path string) error {
 
 // createCodeIndexErrorResult creates an error result with the given message
 func createCodeIndexErrorResult(message string) *mcp.CallToolResult {
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("❌ Error: %s", message)},
-		},
-		IsError: true,
-	}
+	return createErrorResult(message)
 }
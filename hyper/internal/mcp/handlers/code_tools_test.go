@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanConcurrencyArgs(t *testing.T) {
+	current := &storage.ScanConcurrency{Min: 2, Max: 6}
+
+	bounds, changed, err := scanConcurrencyArgs(map[string]interface{}{}, current)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Same(t, current, bounds)
+
+	bounds, changed, err = scanConcurrencyArgs(map[string]interface{}{"maxConcurrency": float64(10)}, current)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, &storage.ScanConcurrency{Min: 2, Max: 10}, bounds)
+
+	bounds, changed, err = scanConcurrencyArgs(map[string]interface{}{"minConcurrency": float64(0), "maxConcurrency": float64(0)}, current)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Nil(t, bounds, "zero bounds restore the defaults")
+
+	for _, args := range []map[string]interface{}{
+		{"minConcurrency": float64(8), "maxConcurrency": float64(4)},
+		{"maxConcurrency": float64(-1)},
+		{"maxConcurrency": float64(storage.MaxScanConcurrency + 1)},
+		{"minConcurrency": 1.5},
+		{"minConcurrency": "2"},
+	} {
+		_, _, err := scanConcurrencyArgs(args, nil)
+		assert.Error(t, err, "%v", args)
+	}
+}
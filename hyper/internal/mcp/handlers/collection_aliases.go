@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hyper/internal/confirm"
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CollectionAliasManager creates versioned Qdrant collections and switches
+// the aliases readers use between them; implemented by *storage.QdrantClient
+type CollectionAliasManager interface {
+	ListCollectionAliases(ctx context.Context) ([]storage.CollectionAlias, error)
+	CollectionVersions(ctx context.Context, alias string) ([]string, error)
+	CollectionVectorSize(ctx context.Context, collectionName string) (size int, exists bool, err error)
+	MigrateCollection(ctx context.Context, alias string, vectorSize int) (*storage.AliasMigration, error)
+	SwitchCollectionAlias(ctx context.Context, alias, collection string) (string, error)
+	RollbackCollectionAlias(ctx context.Context, alias string) (*storage.AliasMigration, error)
+}
+
+// SetCollectionAliases enables the collection migration tools
+func (h *ToolHandler) SetCollectionAliases(manager CollectionAliasManager) {
+	h.collectionAliases = manager
+}
+
+// registerListCollectionAliases registers the coordinator_list_collection_aliases tool
+func (h *ToolHandler) registerListCollectionAliases(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_list_collection_aliases",
+		Description: "List Qdrant collection aliases, the versioned collection each points to, and the versions available to switch to. Searches and indexing use the alias, so migrations and rollbacks are alias switches.",
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, _, err := h.handleListCollectionAliases(ctx)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerMigrateCollection registers the coordinator_migrate_collection tool
+func (h *ToolHandler) registerMigrateCollection(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_migrate_collection",
+		Description: "Create the next version of a Qdrant collection (code_index_v3 after code_index_v2) and switch its alias to it in one atomic step, e.g. after changing the embedding model. The new version starts empty; the previous one is kept so coordinator_switch_collection_alias can roll back. A plain collection created before aliases were used is deleted. Clients that support elicitation are asked to confirm; others must pass confirm: true.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"alias": {
+					Type:        "string",
+					Description: "Optional: alias to migrate (default: the code index collection)",
+				},
+				"vectorSize": {
+					Type:        "integer",
+					Description: "Optional: vector dimensions of the new version (default: those of the current version)",
+				},
+				"confirm": {
+					Type:        "boolean",
+					Description: "Set to true to migrate without asking (required when the client does not support elicitation)",
+				},
+			},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleMigrateCollection(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerSwitchCollectionAlias registers the coordinator_switch_collection_alias tool
+func (h *ToolHandler) registerSwitchCollectionAlias(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_switch_collection_alias",
+		Description: "Point a Qdrant collection alias at another collection atomically, so searches keep working throughout. Without collection, rolls back to the newest version older than the current one.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"alias": {
+					Type:        "string",
+					Description: "Optional: alias to switch (default: the code index collection)",
+				},
+				"collection": {
+					Type:        "string",
+					Description: "Optional: collection to point the alias at, e.g. code_index_v2 (default: roll back one version)",
+				},
+			},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleSwitchCollectionAlias(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleListCollectionAliases handles the coordinator_list_collection_aliases tool call
+func (h *ToolHandler) handleListCollectionAliases(ctx context.Context) (*mcp.CallToolResult, interface{}, error) {
+	if h.collectionAliases == nil {
+		return createErrorResult("collection aliases are unavailable: Qdrant is not configured"), nil, nil
+	}
+
+	aliases, err := h.collectionAliases.ListCollectionAliases(ctx)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	listed := make([]map[string]interface{}, 0, len(aliases))
+	for _, alias := range aliases {
+		versions, err := h.collectionAliases.CollectionVersions(ctx, alias.Alias)
+		if err != nil {
+			return createErrorResult(err.Error()), nil, nil
+		}
+		listed = append(listed, map[string]interface{}{
+			"alias":      alias.Alias,
+			"collection": alias.Collection,
+			"versions":   versions,
+		})
+	}
+
+	response := map[string]interface{}{
+		"aliases":             listed,
+		"count":               len(listed),
+		"codeIndexCollection": storage.CodeIndexCollection,
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// handleMigrateCollection handles the coordinator_migrate_collection tool call
+func (h *ToolHandler) handleMigrateCollection(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.collectionAliases == nil {
+		return createErrorResult("collection aliases are unavailable: Qdrant is not configured"), nil, nil
+	}
+
+	alias := aliasArg(args)
+	vectorSize := 0
+	if raw, ok := args["vectorSize"]; ok {
+		size, isNumber := raw.(float64)
+		if !isNumber || size < 1 || size != float64(int(size)) {
+			return createCodedErrorResult(errcode.Validation, "vectorSize must be a positive integer"), nil, nil
+		}
+		vectorSize = int(size)
+	}
+	if vectorSize == 0 {
+		size, exists, err := h.collectionAliases.CollectionVectorSize(ctx, alias)
+		if err != nil {
+			return createErrorResult(err.Error()), nil, nil
+		}
+		if !exists {
+			return createCodedErrorResult(errcode.Validation, fmt.Sprintf("collection %s does not exist: vectorSize is required", alias)), nil, nil
+		}
+		vectorSize = size
+	}
+
+	switch confirm.Ask(ctx, args, fmt.Sprintf("Switch %s to a new, empty collection version? Searches return nothing until it is re-indexed.", alias)) {
+	case confirm.Declined:
+		return createErrorResult("Migration cancelled: confirmation was declined"), nil, nil
+	case confirm.Required:
+		return createErrorResult("Confirmation required: set confirm=true to migrate the collection"), nil, nil
+	}
+
+	migration, err := h.collectionAliases.MigrateCollection(ctx, alias, vectorSize)
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to migrate collection: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{"migration": migration, "vectorSize": vectorSize}
+	return structuredToolResult(response), response, nil
+}
+
+// handleSwitchCollectionAlias handles the coordinator_switch_collection_alias tool call
+func (h *ToolHandler) handleSwitchCollectionAlias(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.collectionAliases == nil {
+		return createErrorResult("collection aliases are unavailable: Qdrant is not configured"), nil, nil
+	}
+
+	alias := aliasArg(args)
+	collection := strings.TrimSpace(getStringField(args, "collection", ""))
+	if collection == alias {
+		return createCodedErrorResult(errcode.Validation, "an alias cannot point to itself"), nil, nil
+	}
+
+	var migration *storage.AliasMigration
+	if collection == "" {
+		var err error
+		if migration, err = h.collectionAliases.RollbackCollectionAlias(ctx, alias); err != nil {
+			return createErrorResult(fmt.Sprintf("failed to roll back: %s", err.Error())), nil, nil
+		}
+	} else {
+		previous, err := h.collectionAliases.SwitchCollectionAlias(ctx, alias, collection)
+		if err != nil {
+			return createErrorResult(err.Error()), nil, nil
+		}
+		migration = &storage.AliasMigration{Alias: alias, Collection: collection, Previous: previous}
+	}
+
+	response := map[string]interface{}{"migration": migration}
+	return structuredToolResult(response), response, nil
+}
+
+// aliasArg returns the alias argument, defaulting to the code index collection
+func aliasArg(args map[string]interface{}) string {
+	if alias := strings.TrimSpace(getStringField(args, "alias", "")); alias != "" {
+		return alias
+	}
+	return storage.CodeIndexCollection
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAliasManager records migrations of a single alias
+type fakeAliasManager struct {
+	size     int
+	migrated int
+	switched string
+}
+
+func (f *fakeAliasManager) ListCollectionAliases(ctx context.Context) ([]storage.CollectionAlias, error) {
+	return []storage.CollectionAlias{{Alias: "code_index", Collection: "code_index_v2"}}, nil
+}
+
+func (f *fakeAliasManager) CollectionVersions(ctx context.Context, alias string) ([]string, error) {
+	return []string{"code_index_v1", "code_index_v2"}, nil
+}
+
+func (f *fakeAliasManager) CollectionVectorSize(ctx context.Context, name string) (int, bool, error) {
+	return f.size, f.size > 0, nil
+}
+
+func (f *fakeAliasManager) MigrateCollection(ctx context.Context, alias string, vectorSize int) (*storage.AliasMigration, error) {
+	f.migrated = vectorSize
+	return &storage.AliasMigration{Alias: alias, Collection: alias + "_v3", Previous: alias + "_v2"}, nil
+}
+
+func (f *fakeAliasManager) SwitchCollectionAlias(ctx context.Context, alias, collection string) (string, error) {
+	f.switched = collection
+	return alias + "_v2", nil
+}
+
+func (f *fakeAliasManager) RollbackCollectionAlias(ctx context.Context, alias string) (*storage.AliasMigration, error) {
+	f.switched = alias + "_v1"
+	return &storage.AliasMigration{Alias: alias, Collection: alias + "_v1", Previous: alias + "_v2"}, nil
+}
+
+func TestCollectionAliasTools(t *testing.T) {
+	ctx := context.Background()
+	manager := &fakeAliasManager{size: 768}
+	h := &ToolHandler{}
+
+	result, _, err := h.handleMigrateCollection(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Qdrant is not configured")
+
+	h.SetCollectionAliases(manager)
+
+	// Without elicitation the migration must be confirmed explicitly
+	result, _, err = h.handleMigrateCollection(ctx, map[string]interface{}{"alias": "code_index"})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "set confirm=true")
+	assert.Zero(t, manager.migrated)
+
+	// The new version keeps the current dimensions unless vectorSize is given
+	result, _, err = h.handleMigrateCollection(ctx, map[string]interface{}{"alias": "code_index", "confirm": true})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, 768, manager.migrated)
+
+	result, _, err = h.handleMigrateCollection(ctx, map[string]interface{}{"vectorSize": float64(1024), "confirm": true})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, 1024, manager.migrated)
+
+	result, _, err = h.handleMigrateCollection(ctx, map[string]interface{}{"vectorSize": float64(-1)})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	manager.size = 0
+	result, _, err = h.handleMigrateCollection(ctx, map[string]interface{}{"alias": "fresh"})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "vectorSize is required")
+
+	// Without a collection the alias rolls back one version
+	_, response, err := h.handleSwitchCollectionAlias(ctx, map[string]interface{}{"alias": "code_index"})
+	require.NoError(t, err)
+	assert.Equal(t, "code_index_v1", manager.switched)
+	assert.Equal(t, "code_index_v2", response.(map[string]interface{})["migration"].(*storage.AliasMigration).Previous)
+
+	_, _, err = h.handleSwitchCollectionAlias(ctx, map[string]interface{}{"alias": "code_index", "collection": "code_index_v2"})
+	require.NoError(t, err)
+	assert.Equal(t, "code_index_v2", manager.switched)
+
+	_, response, err = h.handleListCollectionAliases(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.(map[string]interface{})["count"])
+}
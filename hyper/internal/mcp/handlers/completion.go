@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCompletionValues is the most values a completion/complete response may
+// carry under the MCP specification
+const maxCompletionValues = 100
+
+// Completion kinds, chosen by argument name
+const (
+	completeTaskIDs     = "task"
+	completeAgentTaskID = "agentTask"
+	completeAgentNames  = "agent"
+	completeCollections = "collection"
+	completeFolders     = "folder"
+)
+
+// completionArguments maps lower-cased prompt and resource template argument
+// names to the values they are completed with
+var completionArguments = map[string]string{
+	"taskid":               completeTaskIDs,
+	"humantaskid":          completeTaskIDs,
+	"parenttaskid":         completeTaskIDs,
+	"agenttaskid":          completeAgentTaskID,
+	"agentname":            completeAgentNames,
+	"name":                 completeAgentNames,
+	"targetsquad":          completeAgentNames,
+	"collection":           completeCollections,
+	"collectionname":       completeCollections,
+	"availablecollections": completeCollections,
+	"folderpath":           completeFolders,
+	"projectpath":          completeFolders,
+}
+
+// IndexedFolderLister lists the folders in the code index; implemented by
+// *storage.CodeIndexStorage
+type IndexedFolderLister interface {
+	ListFolders() ([]*storage.IndexedFolder, error)
+}
+
+// subagentLister lists registered subagents; implemented by
+// *storage.SubchatStorage
+type subagentLister interface {
+	ListSubagents() ([]*storage.Subagent, error)
+}
+
+// Completer answers MCP completion/complete requests for task IDs, agent
+// names, knowledge collections and indexed folders from live storage, so
+// clients can offer them instead of having users type UUIDs and names
+type Completer struct {
+	taskStorage      storage.TaskStorage
+	knowledgeStorage storage.KnowledgeStorage
+	folders          IndexedFolderLister
+	subagents        subagentLister
+}
+
+// NewCompleter creates a completer; any storage may be nil, leaving its
+// arguments without suggestions
+func NewCompleter(taskStorage storage.TaskStorage, knowledgeStorage storage.KnowledgeStorage, folders IndexedFolderLister) *Completer {
+	return &Completer{
+		taskStorage:      taskStorage,
+		knowledgeStorage: knowledgeStorage,
+		folders:          folders,
+	}
+}
+
+// SetSubagents adds registered subagent names to agent name completions
+func (c *Completer) SetSubagents(subagents *storage.SubchatStorage) {
+	c.subagents = subagents
+}
+
+// Complete handles completion/complete; use it as the server's CompletionHandler.
+// The {id} of hyperion://task/agent/ templates completes agent task IDs; other
+// arguments are completed by name (taskId, agentName, collection, folderPath...).
+func (c *Completer) Complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	result := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{}}}
+	if req == nil || req.Params == nil {
+		return result, nil
+	}
+
+	kind := completionArguments[strings.ToLower(req.Params.Argument.Name)]
+	if ref := req.Params.Ref; ref != nil && ref.Type == "ref/resource" && req.Params.Argument.Name == "id" &&
+		strings.HasPrefix(ref.URI, taskCodeURIPrefix) {
+		kind = completeAgentTaskID
+	}
+
+	var candidates []string
+	switch kind {
+	case completeTaskIDs:
+		candidates = append(c.humanTaskIDs(), c.agentTaskIDs()...)
+	case completeAgentTaskID:
+		candidates = c.agentTaskIDs()
+	case completeAgentNames:
+		candidates = c.agentNames()
+	case completeCollections:
+		candidates = c.collections()
+	case completeFolders:
+		candidates = c.folderPaths()
+	}
+
+	values := matchCompletions(candidates, req.Params.Argument.Value)
+	result.Completion.Total = len(values)
+	if len(values) > maxCompletionValues {
+		values = values[:maxCompletionValues]
+		result.Completion.HasMore = true
+	}
+	if values != nil {
+		result.Completion.Values = values
+	}
+	return result, nil
+}
+
+// humanTaskIDs returns the IDs of all human tasks
+func (c *Completer) humanTaskIDs() []string {
+	if c.taskStorage == nil {
+		return nil
+	}
+	var ids []string
+	for _, task := range c.taskStorage.ListAllHumanTasks() {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+// agentTaskIDs returns the IDs of all agent tasks
+func (c *Completer) agentTaskIDs() []string {
+	if c.taskStorage == nil {
+		return nil
+	}
+	var ids []string
+	for _, task := range c.taskStorage.ListAllAgentTasks() {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+// agentNames returns registered subagents and agents that were assigned tasks
+func (c *Completer) agentNames() []string {
+	var names []string
+	if c.subagents != nil {
+		if subagents, err := c.subagents.ListSubagents(); err == nil {
+			for _, subagent := range subagents {
+				names = append(names, subagent.Name)
+			}
+		}
+	}
+	if c.taskStorage != nil {
+		for _, task := range c.taskStorage.ListAllAgentTasks() {
+			names = append(names, task.AgentName)
+		}
+	}
+	return names
+}
+
+// collections returns the knowledge collections in use
+func (c *Completer) collections() []string {
+	if c.knowledgeStorage == nil {
+		return nil
+	}
+	return c.knowledgeStorage.ListCollections()
+}
+
+// folderPaths returns the paths of indexed folders
+func (c *Completer) folderPaths() []string {
+	if c.folders == nil {
+		return nil
+	}
+	folders, err := c.folders.ListFolders()
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, folder := range folders {
+		paths = append(paths, folder.Path)
+	}
+	return paths
+}
+
+// matchCompletions returns the distinct candidates matching the typed value
+// case-insensitively: prefix matches first, then those containing it, each
+// sorted. Hyphens are ignored, so a UUID typed without them still matches.
+func matchCompletions(candidates []string, value string) []string {
+	typed := completionKey(value)
+	seen := make(map[string]bool)
+	var prefixed, contained []string
+	for _, candidate := range candidates {
+		if candidate == "" || seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		key := completionKey(candidate)
+		switch {
+		case strings.HasPrefix(key, typed):
+			prefixed = append(prefixed, candidate)
+		case strings.Contains(key, typed):
+			contained = append(contained, candidate)
+		}
+	}
+	sort.Strings(prefixed)
+	sort.Strings(contained)
+	return append(prefixed, contained...)
+}
+
+// completionKey normalizes a value for matching
+func completionKey(value string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(value)), "-", "")
+}
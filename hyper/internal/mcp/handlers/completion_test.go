@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// completionKnowledgeStorage lists fixed collections
+type completionKnowledgeStorage struct {
+	storage.KnowledgeStorage
+	collections []string
+}
+
+func (s *completionKnowledgeStorage) ListCollections() []string { return s.collections }
+
+// fakeFolderLister lists fixed indexed folders
+type fakeFolderLister []*storage.IndexedFolder
+
+func (f fakeFolderLister) ListFolders() ([]*storage.IndexedFolder, error) { return f, nil }
+
+func newTestCompleter() *Completer {
+	tasks := &searchTaskStorage{
+		human: []*storage.HumanTask{{ID: "3fa85f64-5717-4562-b3fc-2c963f66afa6"}},
+		agent: []*storage.AgentTask{
+			{ID: "3fb1c2d4-0000-4000-8000-000000000001", AgentName: "go-dev"},
+			{ID: "9e2a0000-0000-4000-8000-000000000002", AgentName: "ui-dev"},
+			{ID: "9e2a0000-0000-4000-8000-000000000003", AgentName: "go-dev"},
+		},
+	}
+	knowledge := &completionKnowledgeStorage{collections: []string{"team-docs", "adr", "docs-archive"}}
+	folders := fakeFolderLister{{Path: "/repo/api"}, {Path: "/repo/web"}}
+	return NewCompleter(tasks, knowledge, folders)
+}
+
+func complete(t *testing.T, c *Completer, ref *mcp.CompleteReference, name, value string) mcp.CompletionResultDetails {
+	result, err := c.Complete(context.Background(), &mcp.CompleteRequest{Params: &mcp.CompleteParams{
+		Ref:      ref,
+		Argument: mcp.CompleteParamsArgument{Name: name, Value: value},
+	}})
+	require.NoError(t, err)
+	return result.Completion
+}
+
+func TestCompleter_Complete(t *testing.T) {
+	c := newTestCompleter()
+	prompt := &mcp.CompleteReference{Type: "ref/prompt", Name: "plan_task_breakdown"}
+
+	assert.Equal(t, []string{"3fa85f64-5717-4562-b3fc-2c963f66afa6", "3fb1c2d4-0000-4000-8000-000000000001"},
+		complete(t, c, prompt, "taskId", "3f").Values)
+	assert.Equal(t, []string{"3fa85f64-5717-4562-b3fc-2c963f66afa6"},
+		complete(t, c, prompt, "taskId", "3fa85f645717").Values, "hyphens are optional")
+
+	// Only agent tasks have code and activity resources
+	taskCode := &mcp.CompleteReference{Type: "ref/resource", URI: "hyperion://task/agent/{id}/code"}
+	assert.Equal(t, []string{"3fb1c2d4-0000-4000-8000-000000000001"}, complete(t, c, taskCode, "id", "3f").Values)
+
+	assert.Equal(t, []string{"go-dev", "ui-dev"}, complete(t, c, prompt, "targetSquad", "").Values)
+	assert.Equal(t, []string{"docs-archive", "team-docs"}, complete(t, c, prompt, "collectionName", "DOCS").Values,
+		"prefix matches come before substring matches")
+	assert.Equal(t, []string{"/repo/web"}, complete(t, c, prompt, "folderPath", "/repo/w").Values)
+
+	unknown := complete(t, c, prompt, "taskDescription", "")
+	assert.Empty(t, unknown.Values)
+	assert.NotNil(t, unknown.Values, "values is always present")
+}
+
+func TestCompleter_CapsValues(t *testing.T) {
+	var collections []string
+	for i := 0; i < maxCompletionValues+5; i++ {
+		collections = append(collections, fmt.Sprintf("c%03d", i))
+	}
+	c := NewCompleter(nil, &completionKnowledgeStorage{collections: collections}, nil)
+
+	completion := complete(t, c, nil, "collection", "c")
+	assert.Len(t, completion.Values, maxCompletionValues)
+	assert.Equal(t, maxCompletionValues+5, completion.Total)
+	assert.True(t, completion.HasMore)
+
+	assert.Empty(t, complete(t, c, nil, "folderPath", "").Values, "missing storage completes nothing")
+}
@@ -0,0 +1,444 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultBundleTokens  = 4000
+	minBundleTokens      = 256
+	maxBundleTokens      = 32000
+	bundleKnowledgeLimit = 5  // Knowledge entries queried per suggested collection
+	bundleCodeFiles      = 10 // Modified files searched for code
+	bundleCodeLimit      = 5  // Code results searched per modified file
+	minBundleItemTokens  = 32 // Items that would be cut below this are left out instead
+	bundleFormatMarkdown = "markdown"
+	bundleFormatJSON     = "json"
+)
+
+// Sections of a context bundle, in the order they are filled from the budget
+const (
+	bundleSectionTask      = "task"
+	bundleSectionNotes     = "promptNotes"
+	bundleSectionSummary   = "contextSummary"
+	bundleSectionTodos     = "todos"
+	bundleSectionKnowledge = "knowledge"
+	bundleSectionCode      = "code"
+)
+
+// bundleSectionTitles are the markdown headings of the bundle sections
+var bundleSectionTitles = map[string]string{
+	bundleSectionTask:      "Task",
+	bundleSectionNotes:     "Prompt Notes",
+	bundleSectionSummary:   "Context Summary",
+	bundleSectionTodos:     "TODOs",
+	bundleSectionKnowledge: "Knowledge",
+	bundleSectionCode:      "Code",
+}
+
+// bundleItem is one entry of a context bundle section
+type bundleItem struct {
+	Title     string  `json:"title"`
+	Text      string  `json:"text,omitempty"`
+	Source    string  `json:"source,omitempty"` // Collection or file
+	Score     float64 `json:"score,omitempty"`
+	Truncated bool    `json:"truncated,omitempty"`
+}
+
+// bundleSection is one section of a context bundle
+type bundleSection struct {
+	Name  string       `json:"name"`
+	Items []bundleItem `json:"items"`
+}
+
+// registerBuildContextBundle registers the coordinator_build_context_bundle tool
+func (h *ToolHandler) registerBuildContextBundle(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_build_context_bundle",
+		Description: "Assemble everything a sub-agent needs for an agent task into one bundle sized to a token budget: the task and its human prompt, prompt notes, context summary, TODOs, top knowledge entries from the task's suggested collections, and code search results for its modified files. Sections are filled in that order; what does not fit is cut or left out and counted under 'omitted'. Returns markdown ready to paste into a prompt, or JSON.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"agentTaskId": {
+					Type:        "string",
+					Description: "Agent task UUID",
+				},
+				"tokenBudget": {
+					Type:        "number",
+					Description: fmt.Sprintf("Optional: approximate maximum size of the bundle in tokens (default: %d, min: %d, max: %d)", defaultBundleTokens, minBundleTokens, maxBundleTokens),
+				},
+				"format": {
+					Type:        "string",
+					Enum:        []interface{}{bundleFormatMarkdown, bundleFormatJSON},
+					Description: "Optional: 'markdown' (default) or 'json'",
+				},
+			},
+			Required: []string{"agentTaskId"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleBuildContextBundle(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleBuildContextBundle handles the coordinator_build_context_bundle tool call
+func (h *ToolHandler) handleBuildContextBundle(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	taskID := strings.TrimSpace(getStringField(args, "agentTaskId", ""))
+	if taskID == "" {
+		return createCodedErrorResult(errcode.Validation, "agentTaskId parameter is required"), nil, nil
+	}
+
+	format := strings.ToLower(getStringField(args, "format", bundleFormatMarkdown))
+	if format != bundleFormatMarkdown && format != bundleFormatJSON {
+		return createCodedErrorResult(errcode.Validation, fmt.Sprintf("unknown format '%s': use markdown or json", format)), nil, nil
+	}
+
+	budget := defaultBundleTokens
+	if raw, ok := args["tokenBudget"]; ok && raw != nil {
+		value, ok := raw.(float64)
+		if !ok || value <= 0 {
+			return createCodedErrorResult(errcode.Validation, "tokenBudget must be a positive number"), nil, nil
+		}
+		budget = int(value)
+		if budget < minBundleTokens {
+			budget = minBundleTokens
+		}
+		if budget > maxBundleTokens {
+			budget = maxBundleTokens
+		}
+	}
+
+	task, err := h.taskStorage.GetAgentTask(taskID)
+	if err != nil {
+		return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("agent task not found: %s", taskID)), nil, nil
+	}
+
+	// Knowledge and code are searched in parallel; a failing search leaves its section empty
+	failures := make(map[string]string)
+	var knowledge, code []bundleItem
+	var knowledgeErr, codeErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		knowledge, knowledgeErr = h.bundleKnowledge(ctx, task)
+	}()
+	go func() {
+		defer wg.Done()
+		code, codeErr = h.bundleCode(task)
+	}()
+	wg.Wait()
+	if knowledgeErr != nil {
+		failures[bundleSectionKnowledge] = knowledgeErr.Error()
+	}
+	if codeErr != nil {
+		failures[bundleSectionCode] = codeErr.Error()
+	}
+
+	candidates := []bundleSection{
+		{Name: bundleSectionTask, Items: h.bundleTask(task)},
+		{Name: bundleSectionNotes, Items: bundlePromptNotes(task)},
+		{Name: bundleSectionSummary, Items: bundleContextSummary(task)},
+		{Name: bundleSectionTodos, Items: bundleTodos(task)},
+		{Name: bundleSectionKnowledge, Items: knowledge},
+		{Name: bundleSectionCode, Items: code},
+	}
+	sections, used, omitted := fitBundle(candidates, budget)
+	markdown := renderBundleMarkdown(task, sections)
+
+	response := map[string]interface{}{
+		"agentTaskId":     task.ID,
+		"format":          format,
+		"tokenBudget":     budget,
+		"estimatedTokens": used,
+		"sections":        sections,
+		"omitted":         omitted,
+	}
+	if len(failures) > 0 {
+		response["errors"] = failures
+	}
+
+	if format == bundleFormatJSON {
+		return structuredToolResult(response), response, nil
+	}
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: markdown}},
+		StructuredContent: response,
+	}, response, nil
+}
+
+// bundleTask describes the agent task and the human prompt it belongs to
+func (h *ToolHandler) bundleTask(task *storage.AgentTask) []bundleItem {
+	lines := []string{
+		fmt.Sprintf("Agent: %s", task.AgentName),
+		fmt.Sprintf("Status: %s", task.Status),
+		fmt.Sprintf("Agent task: %s", task.ID),
+	}
+	if human, err := h.taskStorage.GetHumanTask(task.HumanTaskID); err == nil {
+		lines = append(lines, fmt.Sprintf("Human task: %s", human.ID), "", human.Prompt)
+	}
+	return []bundleItem{{Title: task.Role, Text: strings.Join(lines, "\n")}}
+}
+
+// bundlePromptNotes returns the human guidance on the task and its TODOs
+func bundlePromptNotes(task *storage.AgentTask) []bundleItem {
+	var items []bundleItem
+	if notes := strings.TrimSpace(task.HumanPromptNotes); notes != "" {
+		items = append(items, bundleItem{Title: "Task", Text: notes})
+	}
+	for _, todo := range task.Todos {
+		if notes := strings.TrimSpace(todo.HumanPromptNotes); notes != "" {
+			items = append(items, bundleItem{Title: "TODO: " + firstLine(todo.Description), Text: notes})
+		}
+	}
+	return items
+}
+
+// bundleContextSummary returns the task's context and prior work summaries
+func bundleContextSummary(task *storage.AgentTask) []bundleItem {
+	var items []bundleItem
+	if summary := strings.TrimSpace(task.ContextSummary); summary != "" {
+		items = append(items, bundleItem{Title: "Context", Text: summary})
+	}
+	if prior := strings.TrimSpace(task.PriorWorkSummary); prior != "" {
+		items = append(items, bundleItem{Title: "Prior work", Text: prior})
+	}
+	return items
+}
+
+// bundleTodos returns the task's TODOs with their hints; open TODOs come first
+// so completed ones are the first to go when the budget runs out
+func bundleTodos(task *storage.AgentTask) []bundleItem {
+	todos := make([]storage.TodoItem, len(task.Todos))
+	copy(todos, task.Todos)
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].Status != storage.TodoStatusCompleted && todos[j].Status == storage.TodoStatusCompleted
+	})
+
+	items := make([]bundleItem, 0, len(todos))
+	for _, todo := range todos {
+		var lines []string
+		if todo.FilePath != "" {
+			location := todo.FilePath
+			if todo.FunctionName != "" {
+				location += " (" + todo.FunctionName + ")"
+			}
+			lines = append(lines, "File: "+location)
+		}
+		if todo.ContextHint != "" {
+			lines = append(lines, "Hint: "+todo.ContextHint)
+		}
+		if todo.Notes != "" {
+			lines = append(lines, "Notes: "+todo.Notes)
+		}
+		for _, check := range todo.Checklist {
+			lines = append(lines, fmt.Sprintf("- [%s] %s", check.Status, check.Description))
+		}
+		items = append(items, bundleItem{
+			Title: fmt.Sprintf("[%s] %s", todo.Status, todo.Description),
+			Text:  strings.Join(lines, "\n"),
+		})
+	}
+	return items
+}
+
+// bundleKnowledge queries the task's suggested collections with its role and
+// context, best entries first
+func (h *ToolHandler) bundleKnowledge(ctx context.Context, task *storage.AgentTask) ([]bundleItem, error) {
+	if len(task.QdrantCollections) == 0 || h.knowledgeStorage == nil {
+		return nil, nil
+	}
+
+	query := strings.TrimSpace(task.Role + "\n" + task.ContextSummary)
+	var items []bundleItem
+	var lastErr error
+	seen := make(map[string]bool)
+	for _, collection := range task.QdrantCollections {
+		results, err := storage.QueryKnowledgeContext(ctx, h.knowledgeStorage, collection, query, bundleKnowledgeLimit)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, result := range results {
+			if result.Entry == nil || seen[result.Entry.ID] {
+				continue
+			}
+			seen[result.Entry.ID] = true
+			items = append(items, bundleItem{
+				Title:  firstLine(result.Entry.Text),
+				Text:   result.Entry.Text,
+				Source: collection,
+				Score:  result.Score,
+			})
+		}
+	}
+	if len(items) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Score > items[j].Score })
+	return items, nil
+}
+
+// bundleCode searches the code index for each modified file with the task's
+// role and keeps the chunks of that file, best first
+func (h *ToolHandler) bundleCode(task *storage.AgentTask) ([]bundleItem, error) {
+	if len(task.FilesModified) == 0 {
+		return nil, nil
+	}
+	if h.codeSearcher == nil {
+		return nil, fmt.Errorf("code search is not configured")
+	}
+
+	files := task.FilesModified
+	if len(files) > bundleCodeFiles {
+		files = files[:bundleCodeFiles]
+	}
+
+	var items []bundleItem
+	var lastErr error
+	seen := make(map[string]bool)
+	for _, file := range files {
+		results, err := h.codeSearcher.SearchCode(strings.TrimSpace(task.Role+" "+file), "", bundleCodeLimit)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, result := range results {
+			key := fmt.Sprintf("%s#%d", result.FilePath, result.ChunkNum)
+			if seen[key] || !sameCodeFile(result, file) {
+				continue
+			}
+			seen[key] = true
+			title := result.RelativePath
+			if title == "" {
+				title = result.FilePath
+			}
+			if result.StartLine > 0 {
+				title = fmt.Sprintf("%s:%d-%d", title, result.StartLine, result.EndLine)
+			}
+			items = append(items, bundleItem{
+				Title:  title,
+				Text:   result.Content,
+				Source: file,
+				Score:  float64(result.Score),
+			})
+		}
+	}
+	if len(items) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Score > items[j].Score })
+	return items, nil
+}
+
+// sameCodeFile reports whether a search result is from file, which may be
+// absolute or relative to its indexed folder
+func sameCodeFile(result *storage.SearchResult, file string) bool {
+	file = filepath.ToSlash(filepath.Clean(file))
+	for _, path := range []string{result.FilePath, result.RelativePath} {
+		if path == "" {
+			continue
+		}
+		path = filepath.ToSlash(filepath.Clean(path))
+		if path == file || strings.HasSuffix(path, "/"+file) || strings.HasSuffix(file, "/"+path) {
+			return true
+		}
+	}
+	return false
+}
+
+// fitBundle keeps items section by section while they fit in budget tokens.
+// The first item that does not fit is cut to the tokens left, unless that
+// leaves too little of it; the items after it are counted as omitted.
+func fitBundle(candidates []bundleSection, budget int) ([]bundleSection, int, map[string]int) {
+	used := 0
+	omitted := make(map[string]int)
+	sections := make([]bundleSection, 0, len(candidates))
+	for _, candidate := range candidates {
+		section := bundleSection{Name: candidate.Name, Items: []bundleItem{}}
+		heading := int(embeddings.EstimateTokens(renderBundleHeading(candidate.Name)))
+		for i, item := range candidate.Items {
+			cost := int(embeddings.EstimateTokens(renderBundleItem(item)))
+			if len(section.Items) == 0 {
+				cost += heading
+			}
+			if used+cost <= budget {
+				section.Items = append(section.Items, item)
+				used += cost
+				continue
+			}
+
+			skipped := len(candidate.Items) - i
+			left := budget - used - (cost - int(embeddings.EstimateTokens(item.Text)))
+			if left >= minBundleItemTokens && item.Text != "" {
+				item.Text = truncateText(item.Text, left*4-3)
+				item.Truncated = true
+				section.Items = append(section.Items, item)
+				used = budget
+				skipped--
+			}
+			if skipped > 0 {
+				omitted[candidate.Name] += skipped
+			}
+			break
+		}
+		if len(section.Items) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections, used, omitted
+}
+
+// renderBundleMarkdown renders the fitted sections as one markdown document
+func renderBundleMarkdown(task *storage.AgentTask, sections []bundleSection) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Context: %s (%s)\n", task.Role, task.AgentName)
+	for _, section := range sections {
+		b.WriteString(renderBundleHeading(section.Name))
+		for _, item := range section.Items {
+			b.WriteString(renderBundleItem(item))
+		}
+	}
+	return b.String()
+}
+
+// renderBundleHeading renders the heading of a section
+func renderBundleHeading(name string) string {
+	return fmt.Sprintf("\n## %s\n", bundleSectionTitles[name])
+}
+
+// renderBundleItem renders one item with its source and score
+func renderBundleItem(item bundleItem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n### %s", item.Title)
+	if item.Source != "" {
+		fmt.Fprintf(&b, " (%s, score %.2f)", item.Source, item.Score)
+	}
+	b.WriteString("\n")
+	if item.Text != "" {
+		b.WriteString("\n" + item.Text + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newContextBundleHandler() *ToolHandler {
+	tasks := &batchTaskStorage{
+		human: []*storage.HumanTask{{ID: "h-1", Prompt: "Add webhook retries"}},
+		agent: []*storage.AgentTask{{
+			ID:                "a-1",
+			HumanTaskID:       "h-1",
+			AgentName:         "go-dev",
+			Role:              "Implement the retry queue",
+			ContextSummary:    "Retries use exponential backoff.",
+			HumanPromptNotes:  "Keep the queue in MongoDB.",
+			QdrantCollections: []string{"adr"},
+			FilesModified:     []string{"webhooks/retry.go"},
+			Todos: []storage.TodoItem{
+				{Description: "Write tests", Status: storage.TodoStatusCompleted},
+				{Description: "Add the queue", Status: storage.TodoStatusPending, FilePath: "webhooks/retry.go", ContextHint: "see ADR-7"},
+			},
+		}},
+	}
+	knowledge := &searchKnowledgeStorage{results: map[string][]*storage.QueryResult{
+		"adr": {{Entry: &storage.KnowledgeEntry{ID: "adr-7", Text: "ADR-7\nFailed webhooks are retried three times."}, Score: 0.8}},
+	}}
+	handler := NewToolHandler(tasks, knowledge, nil)
+	handler.SetUnifiedSearch(&stubCodeSearcher{results: []*storage.SearchResult{
+		{FilePath: "/repo/webhooks/retry.go", RelativePath: "webhooks/retry.go", Content: "func retry() {}", StartLine: 1, EndLine: 3, Score: 0.9},
+		{FilePath: "/repo/webhooks/send.go", RelativePath: "webhooks/send.go", Content: "func send() {}", Score: 0.95},
+	}}, nil)
+	return handler
+}
+
+func TestHandleBuildContextBundle(t *testing.T) {
+	handler := newContextBundleHandler()
+
+	result, payload, err := handler.handleBuildContextBundle(context.Background(), map[string]interface{}{"agentTaskId": "a-1"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	markdown := result.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{"Add webhook retries", "Keep the queue in MongoDB.", "exponential backoff", "see ADR-7", "retried three times", "func retry() {}"} {
+		assert.Contains(t, markdown, want)
+	}
+	assert.NotContains(t, markdown, "func send() {}", "only chunks of modified files are bundled")
+	assert.Less(t, strings.Index(markdown, "Add the queue"), strings.Index(markdown, "Write tests"), "open TODOs come first")
+
+	response := payload.(map[string]interface{})
+	assert.Empty(t, response["omitted"])
+	assert.LessOrEqual(t, response["estimatedTokens"], defaultBundleTokens)
+}
+
+func TestHandleBuildContextBundle_Budget(t *testing.T) {
+	handler := newContextBundleHandler()
+	tasks := handler.taskStorage.(*batchTaskStorage)
+	tasks.agent[0].ContextSummary = strings.Repeat("Retries use exponential backoff. ", 200)
+
+	result, payload, err := handler.handleBuildContextBundle(context.Background(), map[string]interface{}{
+		"agentTaskId": "a-1",
+		"tokenBudget": float64(minBundleTokens),
+		"format":      "json",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	response := payload.(map[string]interface{})
+	assert.LessOrEqual(t, response["estimatedTokens"], minBundleTokens)
+	omitted := response["omitted"].(map[string]int)
+	assert.Equal(t, 2, omitted[bundleSectionTodos])
+	assert.Equal(t, 1, omitted[bundleSectionKnowledge])
+
+	sections := response["sections"].([]bundleSection)
+	last := sections[len(sections)-1]
+	assert.Equal(t, bundleSectionSummary, last.Name)
+	assert.True(t, last.Items[0].Truncated)
+}
+
+func TestHandleBuildContextBundle_Validation(t *testing.T) {
+	handler := newContextBundleHandler()
+
+	for _, args := range []map[string]interface{}{
+		{},
+		{"agentTaskId": "a-404"},
+		{"agentTaskId": "a-1", "format": "yaml"},
+		{"agentTaskId": "a-1", "tokenBudget": float64(-1)},
+	} {
+		result, _, err := handler.handleBuildContextBundle(context.Background(), args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, args)
+	}
+}
+
+func TestSameCodeFile(t *testing.T) {
+	result := &storage.SearchResult{FilePath: "/repo/webhooks/retry.go", RelativePath: "webhooks/retry.go"}
+	assert.True(t, sameCodeFile(result, "webhooks/retry.go"))
+	assert.True(t, sameCodeFile(result, "/repo/webhooks/retry.go"))
+	assert.False(t, sameCodeFile(result, "retry.go.bak"))
+	assert.False(t, sameCodeFile(result, "other/retry.go"))
+}
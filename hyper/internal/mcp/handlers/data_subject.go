@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetDataSubjectEraser enables coordinator_erase_data_subject
+func (h *ToolHandler) SetDataSubjectEraser(eraser *storage.DataSubjectEraser) {
+	h.dataSubjectEraser = eraser
+}
+
+// registerEraseDataSubject registers the coordinator_erase_data_subject tool
+func (h *ToolHandler) registerEraseDataSubject(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_erase_data_subject",
+		Description: "Right-to-erasure request: find every task, task activity entry, knowledge entry, Qdrant vector, chat session and message, role assignment and digest subscription containing the given identifiers (email address, user ID) and return a deletion report. Without confirm the report only lists what would be purged. ⚠️ DESTRUCTIVE with confirm=true - the purge is staged for an undo window (UNDO_WINDOW_SECONDS) like coordinator_clear_task_board.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"identifiers": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: "Identifiers of the data subject, e.g. [\"jane@example.com\", \"jdoe\"]. Matching is case-insensitive and also finds identifiers inside longer text.",
+				},
+				"confirm": {
+					Type:        "boolean",
+					Description: "Optional: true purges the matches; otherwise only the report is returned (default: false)",
+				},
+			},
+			Required: []string{"identifiers"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleEraseDataSubject(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleEraseDataSubject handles the coordinator_erase_data_subject tool call
+func (h *ToolHandler) handleEraseDataSubject(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.dataSubjectEraser == nil {
+		return createErrorResult("data subject erasure is unavailable: no storage configured"), nil, nil
+	}
+
+	var identifiers []string
+	switch raw := args["identifiers"].(type) {
+	case []interface{}:
+		for _, item := range raw {
+			if identifier, ok := item.(string); ok {
+				identifiers = append(identifiers, identifier)
+			}
+		}
+	case string:
+		identifiers = strings.Split(raw, ",")
+	}
+	subject, err := storage.NewDataSubject(identifiers)
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+
+	confirm, _ := args["confirm"].(bool)
+	if confirm && h.undoManager.Enabled() {
+		description := fmt.Sprintf("Erase all data of data subject (%d identifiers)", len(subject.Identifiers))
+		op := h.undoManager.Stage("coordinator_erase_data_subject", description, func(ctx context.Context) (interface{}, error) {
+			report := h.dataSubjectEraser.Erase(ctx, subject, false)
+			if !report.Complete {
+				return report, fmt.Errorf("data subject erasure incomplete: run it again")
+			}
+			return report, nil
+		})
+		return stagedOperationResult(ctx, op), map[string]interface{}{"staged": true, "operation": op}, nil
+	}
+
+	report := h.dataSubjectEraser.Erase(ctx, subject, !confirm)
+	response := map[string]interface{}{"report": report}
+	return structuredToolResult(response), response, nil
+}
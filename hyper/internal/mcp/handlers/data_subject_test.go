@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEraseDataSubjectWithoutStorage(t *testing.T) {
+	h := &ToolHandler{}
+
+	result, _, err := h.handleEraseDataSubject(context.Background(), map[string]interface{}{"identifiers": []interface{}{"jane@example.com"}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "no storage configured")
+}
+
+func TestEraseDataSubjectValidatesIdentifiers(t *testing.T) {
+	h := &ToolHandler{}
+	h.SetDataSubjectEraser(storage.NewDataSubjectEraser(nil, nil, nil, zap.NewNop()))
+
+	for _, identifiers := range []interface{}{nil, []interface{}{" "}, []interface{}{"jd"}} {
+		result, _, err := h.handleEraseDataSubject(context.Background(), map[string]interface{}{"identifiers": identifiers})
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "identifiers %v", identifiers)
+	}
+}
@@ -0,0 +1,494 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/mcp/watcher"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// Diagnostic check outcomes, from best to worst
+const (
+	DiagnosticPass = "pass"
+	DiagnosticSkip = "skip"
+	DiagnosticWarn = "warn"
+	DiagnosticFail = "fail"
+)
+
+// Thresholds used by the self-diagnostics checks
+const (
+	diagnosticTimeout       = 10 * time.Second
+	clockSkewWarn           = 2 * time.Second
+	clockSkewFail           = 30 * time.Second
+	minFreeDiskBytes        = 1 << 30 // 1 GiB
+	minFreeDiskRatio        = 0.05
+	embeddingProbeText      = "hyperion coordinator diagnostics probe"
+	watcherHeartbeatMaxSkip = 3 // heartbeats missed before the watcher counts as stalled
+)
+
+// expectedMongoIndexes lists the indexes created at startup, by collection
+var expectedMongoIndexes = map[string][]string{
+	"human_tasks":       {"taskId_1"},
+	"agent_tasks":       {"taskId_1", "agentName_1", "humanTaskId_1"},
+	"knowledge_entries": {"entryId_1", "collection_1", "text_text"},
+	"tools":             {"toolId_1", "toolName_1", "serverName_1", "description_text"},
+	"mcp_servers":       {"serverName_1"},
+	"indexed_folders":   {"path_1", "status_1"},
+	"code_index_map":    {"path_1", "qdrantCollection_1"},
+	"indexed_files":     {"folderId_1", "path_1", "sha256_1", "language_1"},
+	"file_chunks":       {"fileId_1_chunkNum_1", "vectorId_1"},
+}
+
+// DiagnosticCheck is the outcome of one self-diagnostics check
+type DiagnosticCheck struct {
+	Name        string                 `json:"name"`
+	Status      string                 `json:"status"`
+	Message     string                 `json:"message"`
+	Remediation string                 `json:"remediation,omitempty"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+	DurationMs  int64                  `json:"durationMs"`
+}
+
+// DiagnosticReport is the result of coordinator_diagnose
+type DiagnosticReport struct {
+	Status      string            `json:"status"`
+	GeneratedAt time.Time         `json:"generatedAt"`
+	Checks      []DiagnosticCheck `json:"checks"`
+}
+
+// DiagnosticsHandler runs environment self-checks for the coordinator
+type DiagnosticsHandler struct {
+	mongoDatabase    *mongo.Database
+	qdrantClient     *storage.QdrantClient
+	embeddingClient  embeddings.EmbeddingClient
+	fileWatcher      *watcher.FileWatcher
+	logger           *zap.Logger
+	metadataRegistry *ToolMetadataRegistry
+}
+
+// NewDiagnosticsHandler creates a new diagnostics handler. Any dependency may
+// be nil, in which case its checks are skipped.
+func NewDiagnosticsHandler(
+	mongoDatabase *mongo.Database,
+	qdrantClient *storage.QdrantClient,
+	embeddingClient embeddings.EmbeddingClient,
+	fileWatcher *watcher.FileWatcher,
+	logger *zap.Logger,
+) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		mongoDatabase:   mongoDatabase,
+		qdrantClient:    qdrantClient,
+		embeddingClient: embeddingClient,
+		fileWatcher:     fileWatcher,
+		logger:          logger,
+	}
+}
+
+// SetMetadataRegistry sets the metadata registry for tool indexing
+func (h *DiagnosticsHandler) SetMetadataRegistry(registry *ToolMetadataRegistry) {
+	h.metadataRegistry = registry
+}
+
+// addToolWithMetadata adds a tool to the server and registers it for indexing
+func (h *DiagnosticsHandler) addToolWithMetadata(server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandler) {
+	h.metadataRegistry.RegisterToolWithServer(server, tool, handler)
+}
+
+// RegisterDiagnosticsTools registers the coordinator_diagnose tool
+func (h *DiagnosticsHandler) RegisterDiagnosticsTools(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_diagnose",
+		Description: "Run coordinator self-diagnostics: MongoDB indexes, Qdrant collection dimensions, embedding round-trip, file watcher heartbeat, disk space for cache directories and clock skew. Returns a structured report with remediation hints for each failed check.",
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, _, err := h.handleDiagnose(ctx)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleDiagnose handles the coordinator_diagnose tool call
+func (h *DiagnosticsHandler) handleDiagnose(ctx context.Context) (*mcp.CallToolResult, interface{}, error) {
+	report := h.Run(ctx)
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to serialize diagnostics report: %s", err.Error())), nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(jsonData)},
+		},
+	}, report, nil
+}
+
+// Run executes every check and summarizes the outcome
+func (h *DiagnosticsHandler) Run(ctx context.Context) *DiagnosticReport {
+	checks := []struct {
+		name string
+		run  func(ctx context.Context) DiagnosticCheck
+	}{
+		{"mongo_indexes", h.checkMongoIndexes},
+		{"qdrant_collections", h.checkQdrantCollections},
+		{"embedding_round_trip", h.checkEmbeddingRoundTrip},
+		{"watcher_heartbeat", h.checkWatcherHeartbeat},
+		{"disk_space", h.checkDiskSpace},
+		{"clock_skew", h.checkClockSkew},
+	}
+
+	report := &DiagnosticReport{GeneratedAt: time.Now().UTC()}
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, diagnosticTimeout)
+		start := time.Now()
+		check := c.run(checkCtx)
+		cancel()
+		check.Name = c.name
+		check.DurationMs = time.Since(start).Milliseconds()
+		report.Checks = append(report.Checks, check)
+	}
+	report.Status = worstStatus(report.Checks)
+
+	if h.logger != nil {
+		h.logger.Info("Coordinator diagnostics completed", zap.String("status", report.Status))
+	}
+	return report
+}
+
+// worstStatus returns the most severe status among checks
+func worstStatus(checks []DiagnosticCheck) string {
+	rank := map[string]int{DiagnosticPass: 0, DiagnosticSkip: 1, DiagnosticWarn: 2, DiagnosticFail: 3}
+	worst := DiagnosticPass
+	for _, check := range checks {
+		if rank[check.Status] > rank[worst] {
+			worst = check.Status
+		}
+	}
+	if worst == DiagnosticSkip {
+		return DiagnosticPass
+	}
+	return worst
+}
+
+// checkMongoIndexes verifies that the indexes created at startup exist
+func (h *DiagnosticsHandler) checkMongoIndexes(ctx context.Context) DiagnosticCheck {
+	if h.mongoDatabase == nil {
+		return DiagnosticCheck{Status: DiagnosticSkip, Message: "MongoDB is not configured"}
+	}
+
+	present := make(map[string][]string, len(expectedMongoIndexes))
+	for collection := range expectedMongoIndexes {
+		specs, err := h.mongoDatabase.Collection(storage.CollectionName(collection)).Indexes().ListSpecifications(ctx)
+		if err != nil {
+			return DiagnosticCheck{
+				Status:      DiagnosticFail,
+				Message:     fmt.Sprintf("failed to list indexes of %s: %v", collection, err),
+				Remediation: "Check MONGODB_URI and that the database user has the listIndexes privilege",
+			}
+		}
+		for _, spec := range specs {
+			present[collection] = append(present[collection], spec.Name)
+		}
+	}
+
+	missing := missingIndexes(expectedMongoIndexes, present)
+	if len(missing) > 0 {
+		return DiagnosticCheck{
+			Status:      DiagnosticFail,
+			Message:     fmt.Sprintf("%d index(es) missing: %s", len(missing), strings.Join(missing, ", ")),
+			Remediation: "Indexes are created at startup: restart the coordinator and check its log for index creation errors (duplicate keys or missing createIndex privilege)",
+			Details:     map[string]interface{}{"missing": missing},
+		}
+	}
+
+	return DiagnosticCheck{Status: DiagnosticPass, Message: fmt.Sprintf("all indexes present in %d collections", len(expectedMongoIndexes))}
+}
+
+// missingIndexes returns "collection.index" for every expected index not present, sorted
+func missingIndexes(expected, present map[string][]string) []string {
+	var missing []string
+	for collection, names := range expected {
+		have := make(map[string]bool, len(present[collection]))
+		for _, name := range present[collection] {
+			have[name] = true
+		}
+		for _, name := range names {
+			if !have[name] {
+				missing = append(missing, collection+"."+name)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// checkQdrantCollections verifies that Qdrant collections match the embedding dimensions
+func (h *DiagnosticsHandler) checkQdrantCollections(ctx context.Context) DiagnosticCheck {
+	if h.qdrantClient == nil {
+		return DiagnosticCheck{Status: DiagnosticSkip, Message: "Qdrant is not configured"}
+	}
+	if h.embeddingClient == nil {
+		return DiagnosticCheck{Status: DiagnosticSkip, Message: "no embedding client configured to compare dimensions against"}
+	}
+
+	expected := h.embeddingClient.GetDimensions()
+	sizes := map[string]interface{}{}
+	var mismatched []string
+	for _, collection := range []string{storage.CodeIndexCollection, h.qdrantClient.KnowledgeCollectionName()} {
+		size, exists, err := h.qdrantClient.CollectionVectorSize(ctx, collection)
+		if err != nil {
+			return DiagnosticCheck{
+				Status:      DiagnosticFail,
+				Message:     fmt.Sprintf("failed to read collection %s: %v", collection, err),
+				Remediation: "Check QDRANT_URL and QDRANT_API_KEY and that Qdrant is reachable from the coordinator",
+			}
+		}
+		if !exists {
+			sizes[collection] = "missing"
+			continue
+		}
+		sizes[collection] = size
+		if size != expected {
+			mismatched = append(mismatched, fmt.Sprintf("%s has %d dimensions", collection, size))
+		}
+	}
+
+	details := map[string]interface{}{"expectedDimensions": expected, "collections": sizes}
+	if len(mismatched) > 0 {
+		return DiagnosticCheck{
+			Status:      DiagnosticFail,
+			Message:     fmt.Sprintf("embedding model produces %d dimensions but %s", expected, strings.Join(mismatched, ", ")),
+			Remediation: "The EMBEDDING model changed since the collections were created: switch back to the original model or delete the collections and re-index (code_index_scan, knowledge re-import)",
+			Details:     details,
+		}
+	}
+	return DiagnosticCheck{Status: DiagnosticPass, Message: fmt.Sprintf("collections match the %d-dimension embedding model", expected), Details: details}
+}
+
+// checkEmbeddingRoundTrip embeds a probe text and validates the vector
+func (h *DiagnosticsHandler) checkEmbeddingRoundTrip(ctx context.Context) DiagnosticCheck {
+	if h.embeddingClient == nil {
+		return DiagnosticCheck{Status: DiagnosticSkip, Message: "no embedding client configured"}
+	}
+
+	type embeddingResult struct {
+		vector []float32
+		err    error
+	}
+	done := make(chan embeddingResult, 1)
+	go func() {
+		vector, err := h.embeddingClient.CreateEmbedding(embeddingProbeText)
+		done <- embeddingResult{vector, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return DiagnosticCheck{
+			Status:      DiagnosticFail,
+			Message:     fmt.Sprintf("embedding request did not complete within %s", diagnosticTimeout),
+			Remediation: "The embedding service is slow or unreachable: check EMBEDDING and the service URL (OLLAMA_URL, TEI_URL) and that the model is loaded",
+		}
+	case res := <-done:
+		return embeddingCheck(res.vector, h.embeddingClient.GetDimensions(), res.err)
+	}
+}
+
+// embeddingCheck validates an embedding produced for the probe text
+func embeddingCheck(vector []float32, dimensions int, err error) DiagnosticCheck {
+	if err != nil {
+		return DiagnosticCheck{
+			Status:      DiagnosticFail,
+			Message:     fmt.Sprintf("embedding request failed: %v", err),
+			Remediation: "Check EMBEDDING and the matching service URL or API key (OLLAMA_URL, TEI_URL, OPENAI_API_KEY, VOYAGE_API_KEY)",
+		}
+	}
+	if len(vector) != dimensions {
+		return DiagnosticCheck{
+			Status:      DiagnosticFail,
+			Message:     fmt.Sprintf("embedding has %d dimensions, client reports %d", len(vector), dimensions),
+			Remediation: "The configured model does not match the expected dimensions: check OLLAMA_MODEL or the provider model setting",
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 || math.IsNaN(norm) || math.IsInf(norm, 0) {
+		return DiagnosticCheck{
+			Status:      DiagnosticFail,
+			Message:     "embedding vector is zero or not finite",
+			Remediation: "The embedding model returned an unusable vector: reload or re-download the model",
+		}
+	}
+
+	return DiagnosticCheck{Status: DiagnosticPass, Message: fmt.Sprintf("embedded probe text into %d dimensions", dimensions)}
+}
+
+// checkWatcherHeartbeat verifies that the file watcher event loop is alive
+func (h *DiagnosticsHandler) checkWatcherHeartbeat(ctx context.Context) DiagnosticCheck {
+	if h.fileWatcher == nil || os.Getenv("ENABLE_FILE_WATCHER") == "false" {
+		return DiagnosticCheck{Status: DiagnosticSkip, Message: "file watcher is disabled"}
+	}
+	check := watcherHeartbeatCheck(h.fileWatcher.Heartbeat(), time.Now())
+	check.Details = map[string]interface{}{"watchedFolders": h.fileWatcher.WatchedFolderCount()}
+	return check
+}
+
+// watcherHeartbeatCheck classifies the age of the watcher heartbeat
+func watcherHeartbeatCheck(last, now time.Time) DiagnosticCheck {
+	if last.IsZero() {
+		return DiagnosticCheck{
+			Status:      DiagnosticFail,
+			Message:     "file watcher is not running",
+			Remediation: "The watcher failed to start: check the startup log for 'Failed to start file watcher' and the OS limit on watched files (fs.inotify.max_user_watches on Linux)",
+		}
+	}
+
+	age := now.Sub(last)
+	if age > watcherHeartbeatMaxSkip*watcher.HeartbeatInterval {
+		return DiagnosticCheck{
+			Status:      DiagnosticFail,
+			Message:     fmt.Sprintf("file watcher heartbeat is %s old", age.Round(time.Second)),
+			Remediation: "The watcher event loop is stalled, usually on a slow embedding or Qdrant call: check those services and restart the coordinator",
+		}
+	}
+	return DiagnosticCheck{Status: DiagnosticPass, Message: fmt.Sprintf("last heartbeat %s ago", age.Round(time.Second))}
+}
+
+// cacheDirectories returns the existing directories the coordinator writes caches to
+func cacheDirectories() []string {
+	candidates := []string{os.TempDir(), "models"}
+	if dir, err := os.UserCacheDir(); err == nil {
+		candidates = append(candidates, dir)
+	}
+
+	var dirs []string
+	seen := map[string]bool{}
+	for _, dir := range candidates {
+		abs, err := filepath.Abs(dir)
+		if err != nil || seen[abs] {
+			continue
+		}
+		if info, err := os.Stat(abs); err == nil && info.IsDir() {
+			seen[abs] = true
+			dirs = append(dirs, abs)
+		}
+	}
+	return dirs
+}
+
+// checkDiskSpace verifies free space on the volumes holding cache directories
+func (h *DiagnosticsHandler) checkDiskSpace(ctx context.Context) DiagnosticCheck {
+	dirs := cacheDirectories()
+	if len(dirs) == 0 {
+		return DiagnosticCheck{Status: DiagnosticSkip, Message: "no cache directories found"}
+	}
+
+	var checks []DiagnosticCheck
+	details := map[string]interface{}{}
+	for _, dir := range dirs {
+		free, total, err := diskUsage(dir)
+		if err != nil {
+			details[dir] = err.Error()
+			checks = append(checks, DiagnosticCheck{Status: DiagnosticSkip, Message: err.Error()})
+			continue
+		}
+		details[dir] = map[string]uint64{"freeBytes": free, "totalBytes": total}
+		checks = append(checks, diskSpaceCheck(dir, free, total))
+	}
+
+	check := DiagnosticCheck{Status: worstStatus(checks), Details: details}
+	var problems []string
+	for _, c := range checks {
+		if c.Status == DiagnosticWarn || c.Status == DiagnosticFail {
+			problems = append(problems, c.Message)
+			check.Remediation = c.Remediation
+		}
+	}
+	if len(problems) > 0 {
+		check.Message = strings.Join(problems, "; ")
+	} else {
+		check.Message = fmt.Sprintf("enough free space for %d cache director(ies)", len(dirs))
+	}
+	return check
+}
+
+// diskSpaceCheck classifies the free space of the volume holding dir
+func diskSpaceCheck(dir string, free, total uint64) DiagnosticCheck {
+	remediation := "Free disk space or point TMPDIR / the model directory at a larger volume; indexing and embedding caches fail when the disk fills"
+	if free < minFreeDiskBytes/4 {
+		return DiagnosticCheck{Status: DiagnosticFail, Message: fmt.Sprintf("%s has only %d MiB free", dir, free>>20), Remediation: remediation}
+	}
+	if free < minFreeDiskBytes || (total > 0 && float64(free)/float64(total) < minFreeDiskRatio) {
+		return DiagnosticCheck{Status: DiagnosticWarn, Message: fmt.Sprintf("%s is low on space (%d MiB free)", dir, free>>20), Remediation: remediation}
+	}
+	return DiagnosticCheck{Status: DiagnosticPass, Message: fmt.Sprintf("%s has %d MiB free", dir, free>>20)}
+}
+
+// checkClockSkew compares the local clock with the MongoDB server clock
+func (h *DiagnosticsHandler) checkClockSkew(ctx context.Context) DiagnosticCheck {
+	if h.mongoDatabase == nil {
+		return DiagnosticCheck{Status: DiagnosticSkip, Message: "MongoDB is not configured"}
+	}
+
+	var hello struct {
+		LocalTime time.Time `bson:"localTime"`
+	}
+	start := time.Now()
+	err := h.mongoDatabase.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	roundTrip := time.Since(start)
+	if err != nil {
+		return DiagnosticCheck{
+			Status:      DiagnosticFail,
+			Message:     fmt.Sprintf("failed to read MongoDB server time: %v", err),
+			Remediation: "Check MONGODB_URI and network access to the MongoDB cluster",
+		}
+	}
+	if hello.LocalTime.IsZero() {
+		return DiagnosticCheck{Status: DiagnosticSkip, Message: "MongoDB server did not report its time"}
+	}
+
+	// Assume the server read its clock halfway through the round trip
+	skew := hello.LocalTime.Sub(start.Add(roundTrip / 2))
+	return clockSkewCheck(skew)
+}
+
+// clockSkewCheck classifies the offset between local and server clocks
+func clockSkewCheck(skew time.Duration) DiagnosticCheck {
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	details := map[string]interface{}{"skewMs": skew.Milliseconds()}
+	remediation := "Enable time synchronization (NTP / chrony / Windows Time) on the coordinator host; task timestamps, TTLs and undo windows rely on the clock"
+
+	switch {
+	case abs > clockSkewFail:
+		return DiagnosticCheck{Status: DiagnosticFail, Message: fmt.Sprintf("local clock is %s off from MongoDB", abs.Round(time.Millisecond)), Remediation: remediation, Details: details}
+	case abs > clockSkewWarn:
+		return DiagnosticCheck{Status: DiagnosticWarn, Message: fmt.Sprintf("local clock is %s off from MongoDB", abs.Round(time.Millisecond)), Remediation: remediation, Details: details}
+	}
+	return DiagnosticCheck{Status: DiagnosticPass, Message: fmt.Sprintf("clock within %s of MongoDB", clockSkewWarn), Details: details}
+}
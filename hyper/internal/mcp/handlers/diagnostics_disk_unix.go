@@ -0,0 +1,14 @@
+//go:build unix || darwin
+
+package handlers
+
+import "syscall"
+
+// diskUsage returns the free and total bytes of the volume holding path
+func diskUsage(path string) (free, total uint64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), stat.Blocks * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package handlers
+
+import "golang.org/x/sys/windows"
+
+// diskUsage returns the free and total bytes of the volume holding path
+func diskUsage(path string) (free, total uint64, err error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &free, &total, nil); err != nil {
+		return 0, 0, err
+	}
+	return free, total, nil
+}
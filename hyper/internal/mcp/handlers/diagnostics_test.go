@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/watcher"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingIndexes(t *testing.T) {
+	expected := map[string][]string{
+		"agent_tasks": {"taskId_1", "agentName_1"},
+		"tools":       {"toolId_1"},
+	}
+	present := map[string][]string{
+		"agent_tasks": {"_id_", "taskId_1"},
+	}
+
+	assert.Equal(t, []string{"agent_tasks.agentName_1", "tools.toolId_1"}, missingIndexes(expected, present))
+
+	present["agent_tasks"] = append(present["agent_tasks"], "agentName_1")
+	present["tools"] = []string{"toolId_1"}
+	assert.Empty(t, missingIndexes(expected, present))
+}
+
+func TestWorstStatus(t *testing.T) {
+	assert.Equal(t, DiagnosticPass, worstStatus(nil))
+	assert.Equal(t, DiagnosticPass, worstStatus([]DiagnosticCheck{{Status: DiagnosticPass}, {Status: DiagnosticSkip}}))
+	assert.Equal(t, DiagnosticWarn, worstStatus([]DiagnosticCheck{{Status: DiagnosticWarn}, {Status: DiagnosticPass}}))
+	assert.Equal(t, DiagnosticFail, worstStatus([]DiagnosticCheck{{Status: DiagnosticWarn}, {Status: DiagnosticFail}}))
+}
+
+func TestEmbeddingCheck(t *testing.T) {
+	assert.Equal(t, DiagnosticPass, embeddingCheck([]float32{0.6, 0.8}, 2, nil).Status)
+	assert.Equal(t, DiagnosticFail, embeddingCheck(nil, 2, errors.New("connection refused")).Status)
+	assert.Equal(t, DiagnosticFail, embeddingCheck([]float32{0.6, 0.8}, 768, nil).Status)
+	assert.Equal(t, DiagnosticFail, embeddingCheck([]float32{0, 0}, 2, nil).Status)
+}
+
+func TestWatcherHeartbeatCheck(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, DiagnosticFail, watcherHeartbeatCheck(time.Time{}, now).Status)
+	assert.Equal(t, DiagnosticPass, watcherHeartbeatCheck(now.Add(-watcher.HeartbeatInterval), now).Status)
+
+	stalled := watcherHeartbeatCheck(now.Add(-4*watcher.HeartbeatInterval), now)
+	assert.Equal(t, DiagnosticFail, stalled.Status)
+	assert.NotEmpty(t, stalled.Remediation)
+}
+
+func TestDiskSpaceCheck(t *testing.T) {
+	const gib = 1 << 30
+
+	assert.Equal(t, DiagnosticPass, diskSpaceCheck("/tmp", 50*gib, 100*gib).Status)
+	assert.Equal(t, DiagnosticWarn, diskSpaceCheck("/tmp", gib/2, 100*gib).Status)
+	assert.Equal(t, DiagnosticWarn, diskSpaceCheck("/tmp", 2*gib, 1000*gib).Status, "below 5% free")
+	assert.Equal(t, DiagnosticFail, diskSpaceCheck("/tmp", gib/8, 100*gib).Status)
+}
+
+func TestClockSkewCheck(t *testing.T) {
+	assert.Equal(t, DiagnosticPass, clockSkewCheck(300*time.Millisecond).Status)
+	assert.Equal(t, DiagnosticWarn, clockSkewCheck(-5*time.Second).Status)
+
+	check := clockSkewCheck(2 * time.Minute)
+	assert.Equal(t, DiagnosticFail, check.Status)
+	assert.Equal(t, int64(120000), check.Details["skewMs"])
+}
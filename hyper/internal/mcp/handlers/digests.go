@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"hyper/internal/digest"
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetDigests enables the digest subscription tools. The scheduler builds and
+// delivers digests sent on demand with coordinator_send_digest.
+func (h *ToolHandler) SetDigests(store *storage.DigestSubscriptionStorage, scheduler *digest.Scheduler) {
+	h.digestSubscriptions = store
+	h.digestScheduler = scheduler
+}
+
+// registerSetDigestSubscription registers the coordinator_set_digest_subscription tool
+func (h *ToolHandler) registerSetDigestSubscription(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_set_digest_subscription",
+		Description: "Create or replace a workspace's scheduled digest of new knowledge entries, notable decisions (the 'adr' collection or metadata type 'decision') and completed tasks. Digests are sent daily or weekly at the given UTC hour to a webhook (JSON POST), a Slack incoming webhook or email (SMTP_* settings).",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Workspace name identifying the subscription",
+				},
+				"channel": {
+					Type:        "string",
+					Enum:        []interface{}{storage.DigestChannelWebhook, storage.DigestChannelSlack, storage.DigestChannelEmail},
+					Description: "Delivery channel",
+				},
+				"target": {
+					Type:        "string",
+					Description: "Webhook URL for webhook/slack, or comma-separated addresses for email",
+				},
+				"frequency": {
+					Type:        "string",
+					Enum:        []interface{}{storage.DigestDaily, storage.DigestWeekly},
+					Description: "How often the digest is sent (default: daily)",
+				},
+				"hourUtc": {
+					Type:        "number",
+					Description: "Hour of day (0-23, UTC) the digest is sent (default: 8)",
+				},
+				"weekday": {
+					Type:        "string",
+					Description: "Day weekly digests are sent (default: monday)",
+				},
+				"project": {
+					Type:        "string",
+					Description: "Optional: only include tasks of this project",
+				},
+				"collections": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: "Optional: only include knowledge from these collections (default: all)",
+				},
+				"enabled": {
+					Type:        "boolean",
+					Description: "Optional: false pauses the subscription (default: true)",
+				},
+				"delete": {
+					Type:        "boolean",
+					Description: "Optional: delete the subscription instead of saving it",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleSetDigestSubscription(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerListDigestSubscriptions registers the coordinator_list_digest_subscriptions tool
+func (h *ToolHandler) registerListDigestSubscriptions(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_list_digest_subscriptions",
+		Description: "List digest subscriptions with their schedule and last delivery. Webhook URLs are masked.",
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, _, err := h.handleListDigestSubscriptions(ctx)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerSendDigest registers the coordinator_send_digest tool
+func (h *ToolHandler) registerSendDigest(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_send_digest",
+		Description: "Build a workspace's digest for the period since its last delivery and send it now. With dryRun the digest is returned without being sent.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Workspace name of the subscription",
+				},
+				"dryRun": {
+					Type:        "boolean",
+					Description: "Optional: preview the digest without sending it",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleSendDigest(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleSetDigestSubscription handles the coordinator_set_digest_subscription tool call
+func (h *ToolHandler) handleSetDigestSubscription(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.digestSubscriptions == nil {
+		return createErrorResult("digests are unavailable: no digest storage configured"), nil, nil
+	}
+
+	name, _ := args["name"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return createErrorResult("name parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	if del, _ := args["delete"].(bool); del {
+		if err := h.digestSubscriptions.DeleteSubscription(name); err != nil {
+			return createErrorResult(err.Error()), nil, nil
+		}
+		response := map[string]interface{}{"name": name, "deleted": true}
+		return structuredToolResult(response), response, nil
+	}
+
+	sub, err := digestSubscriptionFromArgs(name, args)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+	if err := h.digestSubscriptions.SetSubscription(sub); err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"subscription": maskDigestSubscription(sub),
+		"nextSendAt":   nextDigestSlot(sub, time.Now()),
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// digestSubscriptionFromArgs reads and validates a subscription from tool arguments
+func digestSubscriptionFromArgs(name string, args map[string]interface{}) (*storage.DigestSubscription, error) {
+	sub := &storage.DigestSubscription{Name: name, HourUTC: 8, Enabled: true}
+	sub.Channel, _ = args["channel"].(string)
+	sub.Target, _ = args["target"].(string)
+	sub.Frequency, _ = args["frequency"].(string)
+	sub.Weekday, _ = args["weekday"].(string)
+	sub.Project, _ = args["project"].(string)
+
+	if raw, ok := args["hourUtc"]; ok {
+		hour, ok := raw.(float64)
+		if !ok || hour != float64(int(hour)) {
+			return nil, fmt.Errorf("hourUtc must be a whole number between 0 and 23")
+		}
+		sub.HourUTC = int(hour)
+	}
+	if enabled, ok := args["enabled"].(bool); ok {
+		sub.Enabled = enabled
+	}
+	if items, ok := args["collections"].([]interface{}); ok {
+		for _, item := range items {
+			if collection, ok := item.(string); ok && strings.TrimSpace(collection) != "" {
+				sub.Collections = append(sub.Collections, strings.TrimSpace(collection))
+			}
+		}
+	}
+
+	if err := storage.ValidateDigestSubscription(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// handleListDigestSubscriptions handles the coordinator_list_digest_subscriptions tool call
+func (h *ToolHandler) handleListDigestSubscriptions(ctx context.Context) (*mcp.CallToolResult, interface{}, error) {
+	if h.digestSubscriptions == nil {
+		return createErrorResult("digests are unavailable: no digest storage configured"), nil, nil
+	}
+
+	subs, err := h.digestSubscriptions.ListSubscriptions()
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	masked := make([]*storage.DigestSubscription, len(subs))
+	for i, sub := range subs {
+		masked[i] = maskDigestSubscription(sub)
+	}
+
+	response := map[string]interface{}{
+		"subscriptions": masked,
+		"count":         len(masked),
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// handleSendDigest handles the coordinator_send_digest tool call
+func (h *ToolHandler) handleSendDigest(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.digestSubscriptions == nil || h.digestScheduler == nil {
+		return createErrorResult("digests are unavailable: no digest storage configured"), nil, nil
+	}
+
+	name, _ := args["name"].(string)
+	if strings.TrimSpace(name) == "" {
+		return createErrorResult("name parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	sub, err := h.digestSubscriptions.GetSubscription(strings.TrimSpace(name))
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+	if sub == nil {
+		return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("digest subscription not found: %s", name)), nil, nil
+	}
+
+	now := time.Now().UTC()
+	response := map[string]interface{}{"name": sub.Name, "channel": sub.Channel}
+
+	var d *digest.Digest
+	if dryRun, _ := args["dryRun"].(bool); dryRun {
+		d, err = h.digestScheduler.Preview(sub, now)
+		if err != nil {
+			return createErrorResult(err.Error()), nil, nil
+		}
+		response["sent"] = false
+	} else {
+		d, err = h.digestScheduler.Deliver(ctx, sub, now)
+		if err != nil {
+			return createCodedErrorResult(errcode.DependencyUnavailable, fmt.Sprintf("failed to send digest: %s", err.Error())), nil, nil
+		}
+		response["sent"] = true
+	}
+
+	response["digest"] = d
+	response["text"] = d.Markdown(sub.Frequency)
+	return structuredToolResult(response), response, nil
+}
+
+// maskDigestSubscription hides webhook URLs, which usually embed a secret
+func maskDigestSubscription(sub *storage.DigestSubscription) *storage.DigestSubscription {
+	masked := *sub
+	if sub.Channel != storage.DigestChannelEmail {
+		if parsed, err := url.Parse(sub.Target); err == nil && parsed.Host != "" {
+			masked.Target = parsed.Scheme + "://" + parsed.Host + "/***"
+		} else {
+			masked.Target = "***"
+		}
+	}
+	return &masked
+}
+
+// nextDigestSlot returns the first scheduled send time after now
+func nextDigestSlot(sub *storage.DigestSubscription, now time.Time) *time.Time {
+	if !sub.Enabled {
+		return nil
+	}
+	next := sub.LatestSlot(now).Add(sub.Period())
+	return &next
+}
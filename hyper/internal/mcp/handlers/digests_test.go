@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestSubscriptionFromArgs(t *testing.T) {
+	sub, err := digestSubscriptionFromArgs("platform", map[string]interface{}{
+		"channel":     "slack",
+		"target":      "https://hooks.slack.com/services/T/B/secret",
+		"frequency":   "weekly",
+		"hourUtc":     float64(17),
+		"collections": []interface{}{"adr", " ", "notes"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 17, sub.HourUTC)
+	assert.Equal(t, "monday", sub.Weekday)
+	assert.True(t, sub.Enabled)
+	assert.Equal(t, []string{"adr", "notes"}, sub.Collections)
+
+	sub, err = digestSubscriptionFromArgs("platform", map[string]interface{}{"channel": "email", "target": "a@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, 8, sub.HourUTC)
+	assert.Equal(t, storage.DigestDaily, sub.Frequency)
+
+	_, err = digestSubscriptionFromArgs("platform", map[string]interface{}{"channel": "slack", "target": "https://x", "hourUtc": 7.5})
+	assert.Error(t, err)
+	_, err = digestSubscriptionFromArgs("platform", map[string]interface{}{"channel": "pager", "target": "https://x"})
+	assert.Error(t, err)
+}
+
+func TestMaskDigestSubscription(t *testing.T) {
+	slack := &storage.DigestSubscription{Channel: storage.DigestChannelSlack, Target: "https://hooks.slack.com/services/T/B/secret"}
+	assert.Equal(t, "https://hooks.slack.com/***", maskDigestSubscription(slack).Target)
+	assert.Equal(t, "https://hooks.slack.com/services/T/B/secret", slack.Target, "original must not change")
+
+	email := &storage.DigestSubscription{Channel: storage.DigestChannelEmail, Target: "a@example.com"}
+	assert.Equal(t, "a@example.com", maskDigestSubscription(email).Target)
+}
+
+func TestNextDigestSlot(t *testing.T) {
+	sub := &storage.DigestSubscription{Frequency: storage.DigestDaily, HourUTC: 8, Enabled: true}
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 3, 3, 8, 0, 0, 0, time.UTC), *nextDigestSlot(sub, now))
+
+	sub.Enabled = false
+	assert.Nil(t, nextDigestSlot(sub, now))
+}
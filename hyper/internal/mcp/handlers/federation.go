@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hyper/internal/errcode"
+	"hyper/internal/federation"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetFederation enables the federation tools. The sync creates tasks on
+// peers and mirrors their status back to local tasks.
+func (h *ToolHandler) SetFederation(peers *storage.FederationStorage, sync *federation.Sync) {
+	h.federationPeers = peers
+	h.federationSync = sync
+}
+
+// registerSetFederationPeer registers the coordinator_set_federation_peer tool
+func (h *ToolHandler) registerSetFederationPeer(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_set_federation_peer",
+		Description: "Register or update a peer coordinator, such as another squad's deployment, that tasks can be delegated to with coordinator_delegate_task. The token is sent to the peer as a Bearer token; it is stored encrypted and never returned.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Peer name, e.g. payments-squad (lowercase letters, digits, - and _)",
+				},
+				"url": {
+					Type:        "string",
+					Description: "Base URL of the peer's HTTP server, e.g. https://coordinator.payments.example.com",
+				},
+				"token": {
+					Type:        "string",
+					Description: "Optional: API token for the peer (default: keep the stored token)",
+				},
+				"description": {
+					Type:        "string",
+					Description: "Optional: who runs the peer and what work it takes",
+				},
+				"delete": {
+					Type:        "boolean",
+					Description: "Optional: remove the peer instead of saving it; its delegations are kept but no longer synced",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleSetFederationPeer(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerListFederationPeers registers the coordinator_list_federation_peers tool
+func (h *ToolHandler) registerListFederationPeers(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_list_federation_peers",
+		Description: "List the peer coordinators tasks can be delegated to, with their URLs and descriptions.",
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, _, err := h.handleListFederationPeers(ctx)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerDelegateTask registers the coordinator_delegate_task tool
+func (h *ToolHandler) registerDelegateTask(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_delegate_task",
+		Description: "Create a task on a peer coordinator, e.g. to hand work to the squad that owns a service. With taskId, the local human task's prompt is forwarded and the task's status follows the remote task as the peer's team works on it.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"peer": {
+					Type:        "string",
+					Description: "Name of the peer coordinator (see coordinator_list_federation_peers)",
+				},
+				"prompt": {
+					Type:        "string",
+					Description: "Optional: task prompt for the peer (default: the prompt of taskId)",
+				},
+				"taskId": {
+					Type:        "string",
+					Description: "Optional: local human task to delegate; its status is synced from the remote task",
+				},
+			},
+			Required: []string{"peer"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleDelegateTask(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerListDelegations registers the coordinator_list_delegations tool
+func (h *ToolHandler) registerListDelegations(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_list_delegations",
+		Description: "List tasks delegated to peer coordinators, newest first, with the remote task ID, the remote status last synced and the error of the last failed sync.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"peer": {
+					Type:        "string",
+					Description: "Optional: only delegations to this peer",
+				},
+				"openOnly": {
+					Type:        "boolean",
+					Description: "Optional: only delegations whose remote task is not completed (default: false)",
+				},
+			},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleListDelegations(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleSetFederationPeer handles the coordinator_set_federation_peer tool call
+func (h *ToolHandler) handleSetFederationPeer(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.federationPeers == nil {
+		return createErrorResult("federation is unavailable: no federation storage configured"), nil, nil
+	}
+
+	name := strings.TrimSpace(getStringField(args, "name", ""))
+	if name == "" {
+		return createCodedErrorResult(errcode.Validation, "name is required"), nil, nil
+	}
+
+	if del, _ := args["delete"].(bool); del {
+		if err := h.federationPeers.DeletePeer(name); err != nil {
+			return createErrorResult(err.Error()), nil, nil
+		}
+		response := map[string]interface{}{"name": name, "deleted": true}
+		return structuredToolResult(response), response, nil
+	}
+
+	peer := &storage.FederationPeer{
+		Name:        name,
+		URL:         getStringField(args, "url", ""),
+		Token:       strings.TrimSpace(getStringField(args, "token", "")),
+		Description: strings.TrimSpace(getStringField(args, "description", "")),
+	}
+	if err := storage.ValidateFederationPeer(peer); err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+	if err := h.federationPeers.SetPeer(peer); err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{"peer": peer, "hasToken": peer.Token != ""}
+	return structuredToolResult(response), response, nil
+}
+
+// handleListFederationPeers handles the coordinator_list_federation_peers tool call
+func (h *ToolHandler) handleListFederationPeers(ctx context.Context) (*mcp.CallToolResult, interface{}, error) {
+	if h.federationPeers == nil {
+		return createErrorResult("federation is unavailable: no federation storage configured"), nil, nil
+	}
+
+	peers, err := h.federationPeers.ListPeers()
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"peers": peers,
+		"count": len(peers),
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// handleDelegateTask handles the coordinator_delegate_task tool call
+func (h *ToolHandler) handleDelegateTask(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.federationSync == nil {
+		return createErrorResult("federation is unavailable: no federation storage configured"), nil, nil
+	}
+
+	peer := strings.TrimSpace(getStringField(args, "peer", ""))
+	if peer == "" {
+		return createCodedErrorResult(errcode.Validation, "peer is required"), nil, nil
+	}
+	prompt := getStringField(args, "prompt", "")
+	taskID := strings.TrimSpace(getStringField(args, "taskId", ""))
+	if strings.TrimSpace(prompt) == "" && taskID == "" {
+		return createCodedErrorResult(errcode.Validation, "prompt or taskId is required"), nil, nil
+	}
+
+	delegation, err := h.federationSync.Delegate(ctx, peer, prompt, taskID)
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to delegate task: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{"delegation": delegation}
+	return structuredToolResult(response), response, nil
+}
+
+// handleListDelegations handles the coordinator_list_delegations tool call
+func (h *ToolHandler) handleListDelegations(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.federationPeers == nil {
+		return createErrorResult("federation is unavailable: no federation storage configured"), nil, nil
+	}
+
+	openOnly, _ := args["openOnly"].(bool)
+	delegations, err := h.federationPeers.ListDelegations(getStringField(args, "peer", ""), openOnly)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"delegations": delegations,
+		"count":       len(delegations),
+	}
+	return structuredToolResult(response), response, nil
+}
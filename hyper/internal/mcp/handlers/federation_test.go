@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFederationToolsWithoutStorage(t *testing.T) {
+	h := &ToolHandler{}
+
+	result, _, err := h.handleDelegateTask(context.Background(), map[string]interface{}{"peer": "payments", "prompt": "Rotate keys"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "no federation storage configured")
+
+	result, _, err = h.handleSetFederationPeer(context.Background(), map[string]interface{}{"name": "payments", "url": "https://payments.example.com"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -45,19 +45,7 @@ func (h *FilesystemToolHandler) SetMetadataRegistry(registry *ToolMetadataRegist
 
 // addToolWithMetadata adds a tool to the server and registers it for indexing
 func (h *FilesystemToolHandler) addToolWithMetadata(server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandler) {
-	server.AddTool(tool, handler)
-	if h.metadataRegistry != nil {
-		h.metadataRegistry.RegisterTool(
-			tool.Name,
-			tool.Description,
-			map[string]interface{}{
-				"type":        "mcp-tool",
-				"name":        tool.Name,
-				"description": tool.Description,
-				"inputSchema": tool.InputSchema,
-			},
-		)
-	}
+	h.metadataRegistry.RegisterToolWithServer(server, tool, handler)
 }
 
 // RegisterFilesystemTools registers all filesystem MCP tools
@@ -685,10 +673,5 @@ func (h *FilesystemToolHandler) extractArguments(req *mcp.CallToolRequest) (map[
 
 // createFilesystemErrorResult creates an error result with the given message
 func createFilesystemErrorResult(message string) *mcp.CallToolResult {
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("❌ Error: %s", message)},
-		},
-		IsError: true,
-	}
+	return createErrorResult(message)
 }
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultAnswerTopK     = 5
+	maxAnswerTopK         = 20
+	maxAnswerCollections  = 10
+	maxAnswerPassageChars = 2000 // Longer passages are truncated in the prompt
+	answerTimeout         = 2 * time.Minute
+)
+
+// answerSystemPrompt keeps synthesized answers grounded in the retrieved passages
+const answerSystemPrompt = `You answer questions using only the numbered knowledge passages provided.
+Cite every claim with the passage numbers it comes from, like [1] or [2][3].
+If the passages do not contain the answer, say so plainly instead of guessing.
+Be concise.`
+
+// citationPattern matches passage citations such as [3] in a synthesized answer
+var citationPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+// AnswerGenerator synthesizes text with the configured LLM
+// (implemented by aiservice.ChatService)
+type AnswerGenerator interface {
+	Complete(ctx context.Context, systemPrompt, prompt string) (string, error)
+}
+
+// answerSource is a retrieved passage offered to the LLM
+type answerSource struct {
+	Ref        int     `json:"ref"` // Passage number used in citations
+	ID         string  `json:"id"`
+	Collection string  `json:"collection"`
+	Score      float64 `json:"score"`
+	Cited      bool    `json:"cited"`
+	Text       string  `json:"text"`
+}
+
+// SetAnswerGenerator enables coordinator_answer and coordinator_draft_adr.
+// Without it the tools report that no LLM is configured.
+func (h *ToolHandler) SetAnswerGenerator(generator AnswerGenerator) {
+	h.answerGenerator = generator
+}
+
+// registerAnswer registers the coordinator_answer tool
+func (h *ToolHandler) registerAnswer(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_answer",
+		Description: "Answer a question from the knowledge base: retrieves the top-k passages across the given collections and synthesizes a cited answer with the configured LLM. Returns the answer plus the source entry IDs, collections and scores; cited=true marks sources the answer references as [n].",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"question": {
+					Type:        "string",
+					Description: "Question to answer",
+				},
+				"collections": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: fmt.Sprintf("Collections to retrieve passages from (max %d)", maxAnswerCollections),
+				},
+				"topK": {
+					Type:        "number",
+					Description: fmt.Sprintf("Number of passages to answer from, best first across all collections (default: %d, max: %d)", defaultAnswerTopK, maxAnswerTopK),
+				},
+				"minScore": {
+					Type:        "number",
+					Description: "Optional: ignore passages scoring below this similarity (0-1)",
+				},
+				"environment": environmentSchema,
+			},
+			Required: []string{"question", "collections"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleAnswer(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleAnswer handles the coordinator_answer tool call
+func (h *ToolHandler) handleAnswer(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	question, _ := args["question"].(string)
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return createErrorResult("question parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	collections, err := answerCollections(args["collections"])
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	topK := defaultAnswerTopK
+	if k, ok := args["topK"].(float64); ok && k > 0 {
+		topK = int(k)
+		if topK > maxAnswerTopK {
+			topK = maxAnswerTopK
+		}
+	}
+	minScore, _ := args["minScore"].(float64)
+
+	if h.answerGenerator == nil {
+		return createCodedErrorResult(errcode.DependencyUnavailable,
+			"answer synthesis unavailable: no LLM configured (set AI_PROVIDER and its API key); use coordinator_query_knowledge to retrieve passages"), nil, nil
+	}
+
+	env, err := resolveKnowledgeEnvironment(h.knowledgeEnvironments, args)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	sources, err := h.retrieveAnswerSources(ctx, question, collections, topK, minScore, environmentVariables(env))
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to query knowledge: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"question":    question,
+		"collections": collections,
+		"sources":     sources,
+	}
+	if len(sources) == 0 {
+		response["answer"] = ""
+		response["message"] = "No relevant knowledge found in the given collections; nothing to synthesize an answer from."
+		return structuredToolResult(response), response, nil
+	}
+
+	llmCtx, cancel := context.WithTimeout(ctx, answerTimeout)
+	defer cancel()
+	answer, err := h.answerGenerator.Complete(llmCtx, answerSystemPrompt, answerPrompt(question, sources))
+	if err != nil {
+		return createCodedErrorResult(errcode.DependencyUnavailable, fmt.Sprintf("failed to synthesize answer: %s", err.Error())), nil, nil
+	}
+
+	answer = strings.TrimSpace(answer)
+	markCitedSources(answer, sources)
+	response["answer"] = answer
+	return structuredToolResult(response), response, nil
+}
+
+// answerCollections reads the collections argument
+func answerCollections(raw interface{}) ([]string, error) {
+	items, _ := raw.([]interface{})
+	seen := make(map[string]bool)
+	collections := make([]string, 0, len(items))
+	for _, item := range items {
+		name, ok := item.(string)
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		collections = append(collections, name)
+	}
+
+	if len(collections) == 0 {
+		return nil, fmt.Errorf("collections parameter is required and must list at least one collection")
+	}
+	if len(collections) > maxAnswerCollections {
+		return nil, fmt.Errorf("collections must not list more than %d collections", maxAnswerCollections)
+	}
+	return collections, nil
+}
+
+// retrieveAnswerSources queries every collection and keeps the topK best
+// distinct passages, numbered from 1 in score order
+func (h *ToolHandler) retrieveAnswerSources(ctx context.Context, question string, collections []string, topK int, minScore float64, variables map[string]string) ([]*answerSource, error) {
+	var results []*storage.QueryResult
+	var lastErr error
+	for _, collection := range collections {
+		found, err := storage.QueryKnowledgeContext(ctx, h.knowledgeStorage, collection, question, topK)
+		if err != nil {
+			// One unavailable collection should not sink the answer
+			lastErr = err
+			continue
+		}
+		results = append(results, found...)
+	}
+	if len(results) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	sources := make([]*answerSource, 0, topK)
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if len(sources) >= topK {
+			break
+		}
+		if result.Entry == nil || result.Score < minScore || seen[result.Entry.ID] {
+			continue
+		}
+		seen[result.Entry.ID] = true
+		sources = append(sources, &answerSource{
+			Ref:        len(sources) + 1,
+			ID:         result.Entry.ID,
+			Collection: result.Entry.Collection,
+			Score:      result.Score,
+			Text:       storage.InterpolateEnvironment(result.Entry.Text, variables),
+		})
+	}
+	return sources, nil
+}
+
+// answerPrompt lists the numbered passages followed by the question
+func answerPrompt(question string, sources []*answerSource) string {
+	var prompt strings.Builder
+	prompt.WriteString("Knowledge passages:\n\n")
+	for _, source := range sources {
+		fmt.Fprintf(&prompt, "[%d] (collection: %s)\n%s\n\n", source.Ref, source.Collection, truncateText(source.Text, maxAnswerPassageChars))
+	}
+	fmt.Fprintf(&prompt, "Question: %s\n", question)
+	return prompt.String()
+}
+
+// markCitedSources flags the sources the answer cites by passage number
+func markCitedSources(answer string, sources []*answerSource) {
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		ref, err := strconv.Atoi(match[1])
+		if err != nil || ref < 1 || ref > len(sources) {
+			continue
+		}
+		sources[ref-1].Cited = true
+	}
+}
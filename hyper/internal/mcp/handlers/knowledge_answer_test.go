@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAnswerGenerator records the prompt and returns a canned answer
+type fakeAnswerGenerator struct {
+	answer string
+	err    error
+	prompt string
+	calls  int
+}
+
+func (g *fakeAnswerGenerator) Complete(ctx context.Context, systemPrompt, prompt string) (string, error) {
+	g.calls++
+	g.prompt = prompt
+	return g.answer, g.err
+}
+
+// scoredKnowledgeStorage returns fixed query results per collection
+type scoredKnowledgeStorage struct {
+	MockKnowledgeStorage
+	results map[string][]*storage.QueryResult
+}
+
+func (m *scoredKnowledgeStorage) Query(collection, query string, limit int) ([]*storage.QueryResult, error) {
+	if collection == "broken" {
+		return nil, errors.New("qdrant unavailable")
+	}
+	return m.results[collection], nil
+}
+
+func newAnswerTestHandler(generator AnswerGenerator) *ToolHandler {
+	entry := func(id, collection, text string) *storage.KnowledgeEntry {
+		return &storage.KnowledgeEntry{ID: id, Collection: collection, Text: text}
+	}
+	store := &scoredKnowledgeStorage{results: map[string][]*storage.QueryResult{
+		"adr": {
+			{Entry: entry("adr-1", "adr", "We use MongoDB for task storage."), Score: 0.9},
+			{Entry: entry("adr-2", "adr", "Qdrant stores embeddings."), Score: 0.4},
+		},
+		"technical-knowledge": {
+			{Entry: entry("tk-1", "technical-knowledge", "Tasks are stored in MongoDB collections."), Score: 0.8},
+			{Entry: entry("adr-1", "adr", "We use MongoDB for task storage."), Score: 0.9},
+		},
+	}}
+	handler := NewToolHandler(nil, store, nil)
+	if generator != nil {
+		handler.SetAnswerGenerator(generator)
+	}
+	return handler
+}
+
+func TestHandleAnswer_SynthesizesCitedAnswer(t *testing.T) {
+	generator := &fakeAnswerGenerator{answer: "Tasks live in MongoDB [1][2]. See also [9]."}
+	handler := newAnswerTestHandler(generator)
+
+	result, payload, err := handler.handleAnswer(context.Background(), map[string]interface{}{
+		"question":    "Where are tasks stored?",
+		"collections": []interface{}{"adr", "technical-knowledge", "broken"},
+		"topK":        float64(3),
+		"minScore":    0.5,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	response := payload.(map[string]interface{})
+	assert.Equal(t, "Tasks live in MongoDB [1][2]. See also [9].", response["answer"])
+
+	// Sources are deduplicated, filtered by minScore and numbered by score
+	sources := response["sources"].([]*answerSource)
+	require.Len(t, sources, 2)
+	assert.Equal(t, "adr-1", sources[0].ID)
+	assert.Equal(t, 1, sources[0].Ref)
+	assert.Equal(t, "tk-1", sources[1].ID)
+	assert.True(t, sources[0].Cited)
+	assert.True(t, sources[1].Cited)
+
+	assert.Contains(t, generator.prompt, "[1] (collection: adr)\nWe use MongoDB for task storage.")
+	assert.Contains(t, generator.prompt, "Question: Where are tasks stored?")
+}
+
+func TestHandleAnswer_NoPassagesSkipsLLM(t *testing.T) {
+	generator := &fakeAnswerGenerator{answer: "unused"}
+	handler := newAnswerTestHandler(generator)
+
+	result, payload, err := handler.handleAnswer(context.Background(), map[string]interface{}{
+		"question":    "Anything?",
+		"collections": []interface{}{"empty"},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, 0, generator.calls)
+	assert.Equal(t, "", payload.(map[string]interface{})["answer"])
+}
+
+func TestHandleAnswer_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator AnswerGenerator
+		args      map[string]interface{}
+		code      errcode.Code
+	}{
+		{
+			name:      "missing question",
+			generator: &fakeAnswerGenerator{},
+			args:      map[string]interface{}{"collections": []interface{}{"adr"}},
+			code:      errcode.Validation,
+		},
+		{
+			name:      "missing collections",
+			generator: &fakeAnswerGenerator{},
+			args:      map[string]interface{}{"question": "Where?"},
+			code:      errcode.Validation,
+		},
+		{
+			name: "no LLM configured",
+			args: map[string]interface{}{"question": "Where?", "collections": []interface{}{"adr"}},
+			code: errcode.DependencyUnavailable,
+		},
+		{
+			name:      "LLM failure",
+			generator: &fakeAnswerGenerator{err: errors.New("rate limited")},
+			args:      map[string]interface{}{"question": "Where?", "collections": []interface{}{"adr"}},
+			code:      errcode.DependencyUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := newAnswerTestHandler(tt.generator).handleAnswer(context.Background(), tt.args)
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			errObj := result.StructuredContent.(map[string]interface{})["error"].(map[string]interface{})
+			assert.Equal(t, tt.code, errObj["code"])
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"hyper/internal/errcode"
+	"hyper/internal/i18n"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KnowledgeEnvironmentEnv names the environment applied to knowledge queries
+// that do not pass one explicitly
+const KnowledgeEnvironmentEnv = "KNOWLEDGE_ENVIRONMENT"
+
+// environmentSchema is the shared "environment" argument of knowledge query tools
+var environmentSchema = &jsonschema.Schema{
+	Type:        "string",
+	Description: "Optional: environment (e.g. 'dev', 'staging', 'prod') whose variables replace {{NAME}} placeholders in returned entry text. Defaults to KNOWLEDGE_ENVIRONMENT",
+}
+
+// resolveKnowledgeEnvironment returns the environment requested by the
+// "environment" argument, falling back to KNOWLEDGE_ENVIRONMENT. It returns nil
+// when no environment applies. An explicitly requested environment that is not
+// defined is an error; an undefined server default is ignored.
+func resolveKnowledgeEnvironment(store *storage.KnowledgeEnvironmentStorage, args map[string]interface{}) (*storage.KnowledgeEnvironment, error) {
+	name, explicit := args["environment"].(string)
+	if name == "" {
+		explicit = false
+		name = os.Getenv(KnowledgeEnvironmentEnv)
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	if store == nil {
+		if explicit {
+			return nil, fmt.Errorf("knowledge environments are unavailable: no environment storage configured")
+		}
+		return nil, nil
+	}
+
+	env, err := store.GetEnvironment(name)
+	if err != nil {
+		return nil, err
+	}
+	if env == nil && explicit {
+		return nil, fmt.Errorf("knowledge environment not found: %s", name)
+	}
+	return env, nil
+}
+
+// environmentVariables returns the variables of env, or nil when no environment applies
+func environmentVariables(env *storage.KnowledgeEnvironment) map[string]string {
+	if env == nil {
+		return nil
+	}
+	return env.Variables
+}
+
+// SetKnowledgeEnvironments enables environment interpolation and the environment management tools
+func (h *ToolHandler) SetKnowledgeEnvironments(store *storage.KnowledgeEnvironmentStorage) {
+	h.knowledgeEnvironments = store
+}
+
+// registerSetKnowledgeEnvironment registers the coordinator_set_knowledge_environment tool
+func (h *ToolHandler) registerSetKnowledgeEnvironment(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_set_knowledge_environment",
+		Description: "Define or replace an environment's template variables (e.g. API endpoints, collection names). Knowledge entries containing {{NAME}} placeholders are rendered with these values when queried with that environment.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Environment name (e.g. 'dev', 'staging', 'prod')",
+				},
+				"variables": {
+					Type:        "object",
+					Description: "Variable values keyed by name (letters, digits and underscores), e.g. {\"API_URL\": \"https://staging.example.com\"}. Replaces all existing variables",
+				},
+				"delete": {
+					Type:        "boolean",
+					Description: "Optional: delete the environment instead of saving it. The deletion is staged for the undo window (UNDO_WINDOW_SECONDS) like coordinator_clear_task_board",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleSetKnowledgeEnvironment(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerListKnowledgeEnvironments registers the coordinator_list_knowledge_environments tool
+func (h *ToolHandler) registerListKnowledgeEnvironments(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_list_knowledge_environments",
+		Description: "List the environments available for knowledge queries and their template variables.",
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, _, err := h.handleListKnowledgeEnvironments(ctx)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleSetKnowledgeEnvironment handles the coordinator_set_knowledge_environment tool call
+func (h *ToolHandler) handleSetKnowledgeEnvironment(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.knowledgeEnvironments == nil {
+		return createErrorResult("knowledge environments are unavailable: no environment storage configured"), nil, nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return createErrorResult("name parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	if del, _ := args["delete"].(bool); del {
+		if h.undoManager.Enabled() {
+			env, err := h.knowledgeEnvironments.GetEnvironment(name)
+			if err != nil {
+				return createErrorResult(err.Error()), nil, nil
+			}
+			if env == nil {
+				return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("knowledge environment not found: %s", name)), nil, nil
+			}
+			op := h.undoManager.Stage("coordinator_set_knowledge_environment", fmt.Sprintf("Delete knowledge environment '%s'", name), func(ctx context.Context) (interface{}, error) {
+				if err := h.knowledgeEnvironments.DeleteEnvironment(name); err != nil {
+					return nil, err
+				}
+				return map[string]interface{}{"deleted": name}, nil
+			})
+			return stagedOperationResult(ctx, op), map[string]interface{}{"staged": true, "operation": op}, nil
+		}
+		if err := h.knowledgeEnvironments.DeleteEnvironment(name); err != nil {
+			return createErrorResult(err.Error()), nil, nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: i18n.T(ctx, "knowledge.environment.deleted", name)},
+			},
+		}, nil, nil
+	}
+
+	variables := map[string]string{}
+	if raw, ok := args["variables"]; ok && raw != nil {
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			return createErrorResult("variables must be an object mapping variable names to string values"), nil, nil
+		}
+		for key, value := range obj {
+			str, ok := value.(string)
+			if !ok {
+				return createErrorResult(fmt.Sprintf("variables[%s] must be a string", key)), nil, nil
+			}
+			variables[key] = str
+		}
+	}
+
+	env := &storage.KnowledgeEnvironment{Name: name, Variables: variables}
+	if err := h.knowledgeEnvironments.SetEnvironment(env); err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: i18n.T(ctx, "knowledge.environment.saved", env.Name, len(env.Variables), env.Name)},
+		},
+	}, env, nil
+}
+
+// handleListKnowledgeEnvironments handles the coordinator_list_knowledge_environments tool call
+func (h *ToolHandler) handleListKnowledgeEnvironments(ctx context.Context) (*mcp.CallToolResult, interface{}, error) {
+	if h.knowledgeEnvironments == nil {
+		return createErrorResult("knowledge environments are unavailable: no environment storage configured"), nil, nil
+	}
+
+	envs, err := h.knowledgeEnvironments.ListEnvironments()
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"environments": envs,
+		"count":        len(envs),
+		"default":      os.Getenv(KnowledgeEnvironmentEnv),
+	}
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to serialize environments: %s", err.Error())), nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(jsonData)},
+		},
+	}, envs, nil
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"hyper/internal/errcode"
+	"hyper/internal/knowledgeeval"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/toolprogress"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetKnowledgeEvaluator enables the knowledge evaluation tools
+func (h *ToolHandler) SetKnowledgeEvaluator(evaluator *knowledgeeval.Evaluator) {
+	h.knowledgeEvaluator = evaluator
+}
+
+// registerSetKnowledgeEval registers the coordinator_set_knowledge_eval tool
+func (h *ToolHandler) registerSetKnowledgeEval(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_set_knowledge_eval",
+		Description: "Define the retrieval evaluation suite of a knowledge collection: questions and the entries a query for each must return within the top k, by ID or by a snippet of their text. Replaces the collection's previous suite. The coordinator runs suites again after embedding model or knowledge setting changes; run one now with coordinator_run_knowledge_eval.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"collection": {
+					Type:        "string",
+					Description: "Collection the questions are asked against",
+				},
+				"cases": {
+					Type:        "array",
+					Description: "Questions and their expected entries",
+					Items: &jsonschema.Schema{
+						Type: "object",
+						Properties: map[string]*jsonschema.Schema{
+							"question":     {Type: "string", Description: "Query text"},
+							"expectedIds":  {Type: "array", Items: &jsonschema.Schema{Type: "string"}, Description: "Entry IDs that must be returned"},
+							"expectedText": {Type: "array", Items: &jsonschema.Schema{Type: "string"}, Description: "Snippets returned entries must contain (case-insensitive); unlike IDs they survive re-imports and re-chunking"},
+						},
+						Required: []string{"question"},
+					},
+				},
+				"k": {
+					Type:        "number",
+					Description: fmt.Sprintf("Results retrieved per question (default: %d)", knowledgeeval.DefaultK),
+				},
+				"minRecall": {
+					Type:        "number",
+					Description: "Mean recall@k, between 0 and 1, below which a run fails (default: 0)",
+				},
+				"minPrecision": {
+					Type:        "number",
+					Description: "Mean precision@k, between 0 and 1, below which a run fails (default: 0)",
+				},
+				"updatedBy": {
+					Type:        "string",
+					Description: "Optional: who maintains the suite",
+				},
+			},
+			Required: []string{"collection", "cases"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleSetKnowledgeEval(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerRunKnowledgeEval registers the coordinator_run_knowledge_eval tool
+func (h *ToolHandler) registerRunKnowledgeEval(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_run_knowledge_eval",
+		Description: "Run the retrieval evaluation suite of a collection, or of every collection, through the same query path agents use. Returns recall@k, precision@k and MRR per suite and per question, the change since the previous run, and passed=false with the failures when a suite falls below its thresholds. The hyper eval-knowledge command calls this tool and exits non-zero on failure, for CI.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"collection": {
+					Type:        "string",
+					Description: "Optional: collection whose suite to run (default: all suites)",
+				},
+			},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleRunKnowledgeEval(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerListKnowledgeEvals registers the coordinator_list_knowledge_evals tool
+func (h *ToolHandler) registerListKnowledgeEvals(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_list_knowledge_evals",
+		Description: "List the retrieval evaluation suites of knowledge collections with the scores of their latest run.",
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleListKnowledgeEvals(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// knowledgeEvalUnavailable is returned when no evaluator is configured
+func knowledgeEvalUnavailable() *mcp.CallToolResult {
+	return createCodedErrorResult(errcode.DependencyUnavailable, "knowledge evaluation is unavailable: no evaluator configured")
+}
+
+// handleSetKnowledgeEval handles the coordinator_set_knowledge_eval tool call
+func (h *ToolHandler) handleSetKnowledgeEval(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.knowledgeEvaluator == nil {
+		return knowledgeEvalUnavailable(), nil, nil
+	}
+
+	// The arguments mirror the stored suite, so decode them the same way
+	var suite storage.KnowledgeEvalSuite
+	raw, err := json.Marshal(args)
+	if err == nil {
+		err = json.Unmarshal(raw, &suite)
+	}
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, fmt.Sprintf("invalid arguments: %s", err.Error())), nil, nil
+	}
+	suite.Collection = strings.TrimSpace(suite.Collection)
+	suite.UpdatedBy = strings.TrimSpace(suite.UpdatedBy)
+
+	if err := knowledgeeval.Validate(&suite); err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+	if err := h.knowledgeEvaluator.SetSuite(&suite); err != nil {
+		return createErrorResult(fmt.Sprintf("failed to save evaluation suite: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{"suite": suite}
+	return structuredToolResult(response), response, nil
+}
+
+// handleRunKnowledgeEval handles the coordinator_run_knowledge_eval tool call
+func (h *ToolHandler) handleRunKnowledgeEval(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.knowledgeEvaluator == nil {
+		return knowledgeEvalUnavailable(), nil, nil
+	}
+
+	var runs []*storage.KnowledgeEvalRun
+	if collection := strings.TrimSpace(getStringField(args, "collection", "")); collection != "" {
+		run, err := h.knowledgeEvaluator.Run(collection, knowledgeeval.TriggerManual)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("knowledge evaluation failed: %s", err.Error())), nil, nil
+		}
+		if run == nil {
+			return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("collection %s has no evaluation suite: define one with coordinator_set_knowledge_eval", collection)), nil, nil
+		}
+		runs = append(runs, run)
+	} else {
+		var err error
+		// Each suite's run is reported as it finishes, for callers streaming the call
+		runs, err = h.knowledgeEvaluator.RunAll(knowledgeeval.TriggerManual, func(run *storage.KnowledgeEvalRun, done, total int) {
+			if !toolprogress.Enabled(ctx) {
+				return
+			}
+			content, _ := json.Marshal(run)
+			toolprogress.Report(ctx, toolprogress.Update{
+				Progress: float64(done),
+				Total:    float64(total),
+				Message:  fmt.Sprintf("evaluated %s", run.Collection),
+				Content:  []mcp.Content{&mcp.TextContent{Text: string(content)}},
+			})
+		})
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("knowledge evaluation failed: %s", err.Error())), nil, nil
+		}
+	}
+
+	passed := true
+	for _, run := range runs {
+		passed = passed && run.Passed
+	}
+	response := map[string]interface{}{
+		"runs":   runs,
+		"count":  len(runs),
+		"passed": passed,
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// handleListKnowledgeEvals handles the coordinator_list_knowledge_evals tool call
+func (h *ToolHandler) handleListKnowledgeEvals(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.knowledgeEvaluator == nil {
+		return knowledgeEvalUnavailable(), nil, nil
+	}
+
+	suites, err := h.knowledgeEvaluator.Suites()
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to list evaluation suites: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"suites": suites,
+		"count":  len(suites),
+	}
+	return structuredToolResult(response), response, nil
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"hyper/internal/errcode"
+	"hyper/internal/knowledgeeval"
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// evalStore keeps evaluation suites and runs in memory
+type evalStore struct {
+	suites map[string]*storage.KnowledgeEvalSuite
+	runs   []*storage.KnowledgeEvalRun
+}
+
+func (s *evalStore) SaveKnowledgeEvalSuite(suite *storage.KnowledgeEvalSuite) error {
+	s.suites[suite.Collection] = suite
+	return nil
+}
+
+func (s *evalStore) GetKnowledgeEvalSuite(collection string) (*storage.KnowledgeEvalSuite, error) {
+	return s.suites[collection], nil
+}
+
+func (s *evalStore) ListKnowledgeEvalSuites() ([]*storage.KnowledgeEvalSuite, error) {
+	var suites []*storage.KnowledgeEvalSuite
+	for _, suite := range s.suites {
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+func (s *evalStore) SaveKnowledgeEvalRun(run *storage.KnowledgeEvalRun) error {
+	s.runs = append(s.runs, run)
+	return nil
+}
+
+func (s *evalStore) LatestKnowledgeEvalRun(collection string) (*storage.KnowledgeEvalRun, error) {
+	for i := len(s.runs) - 1; i >= 0; i-- {
+		if s.runs[i].Collection == collection {
+			return s.runs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// evalSearcher returns one entry for every query
+type evalSearcher struct{}
+
+func (evalSearcher) Query(collection, query string, limit int) ([]*storage.QueryResult, error) {
+	return []*storage.QueryResult{{Entry: &storage.KnowledgeEntry{ID: "deploy", Text: "Deploy with make release"}}}, nil
+}
+
+func TestKnowledgeEvalTools(t *testing.T) {
+	h := NewToolHandler(nil, nil, nil)
+	ctx := context.Background()
+
+	result, _, err := h.handleRunKnowledgeEval(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.DependencyUnavailable, errorCode(t, result))
+
+	h.SetKnowledgeEvaluator(knowledgeeval.NewEvaluator(&evalStore{suites: map[string]*storage.KnowledgeEvalSuite{}}, evalSearcher{}, "fp", zap.NewNop()))
+
+	result, _, err = h.handleSetKnowledgeEval(ctx, map[string]interface{}{
+		"collection": "team-docs",
+		"cases":      []interface{}{map[string]interface{}{"question": "How do we deploy?"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.Validation, errorCode(t, result), "cases need an expectation")
+
+	result, _, err = h.handleSetKnowledgeEval(ctx, map[string]interface{}{
+		"collection": "team-docs",
+		"minRecall":  0.9,
+		"cases": []interface{}{
+			map[string]interface{}{"question": "How do we deploy?", "expectedText": []interface{}{"make release"}},
+			map[string]interface{}{"question": "How do we roll back?", "expectedIds": []interface{}{"rollback"}},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	result, _, err = h.handleRunKnowledgeEval(ctx, map[string]interface{}{"collection": "other"})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.NotFound, errorCode(t, result))
+
+	result, _, err = h.handleRunKnowledgeEval(ctx, map[string]interface{}{"collection": "team-docs"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	content := result.StructuredContent.(map[string]interface{})
+	assert.Equal(t, false, content["passed"])
+	runs := content["runs"].([]*storage.KnowledgeEvalRun)
+	require.Len(t, runs, 1)
+	assert.Equal(t, 0.5, runs[0].Recall)
+	assert.Equal(t, knowledgeeval.DefaultK, runs[0].K)
+
+	result, _, err = h.handleListKnowledgeEvals(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	suites := result.StructuredContent.(map[string]interface{})["suites"].([]knowledgeeval.SuiteStatus)
+	require.Len(t, suites, 1)
+	assert.Equal(t, runs[0], suites[0].LatestRun)
+}
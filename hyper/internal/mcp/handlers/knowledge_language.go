@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// languageSchema is the shared "language" argument of knowledge searches
+var languageSchema = &jsonschema.Schema{
+	Type:        "string",
+	Description: "Optional: only return entries written in this language, as an ISO 639-1 code (e.g. 'de', 'ja'). Entries are tagged with their detected language in metadata.lang when stored",
+}
+
+// parseLanguageArg returns the normalized language code argument name, or ""
+// when absent
+func parseLanguageArg(args map[string]interface{}, name string) (string, error) {
+	raw, _ := args[name].(string)
+	language := strings.ToLower(strings.TrimSpace(raw))
+	if language == "" {
+		return "", nil
+	}
+	if len(language) != 2 || language[0] < 'a' || language[0] > 'z' || language[1] < 'a' || language[1] > 'z' {
+		return "", fmt.Errorf("%s must be a two-letter ISO 639-1 code, got %q", name, raw)
+	}
+	return language, nil
+}
+
+// commentLanguageFilter builds the Qdrant payload filter restricting a code
+// search to chunks whose comments are in language; nil when none is requested
+func commentLanguageFilter(language string) map[string]interface{} {
+	if language == "" {
+		return nil
+	}
+	return map[string]interface{}{
+		"must": []map[string]interface{}{
+			{"key": storage.CommentLanguageKey, "match": map[string]interface{}{"value": language}},
+		},
+	}
+}
+
+// queryKnowledge queries a knowledge storage, restricted to language when
+// set, bounded by ctx when the storage supports it
+func queryKnowledge(ctx context.Context, store storage.KnowledgeStorage, collection, query, language string, limit int) ([]*storage.QueryResult, error) {
+	if contextStore, ok := store.(storage.ContextKnowledgeStorage); ok {
+		return contextStore.QueryContext(ctx, collection, query, language, limit)
+	}
+	if language == "" {
+		return store.Query(collection, query, limit)
+	}
+	languageStore, ok := store.(storage.LanguageKnowledgeStorage)
+	if !ok {
+		return nil, fmt.Errorf("language filtering is not supported by this knowledge storage")
+	}
+	return languageStore.QueryLanguage(collection, query, language, limit)
+}
+
+// languageSearcher is implemented by Qdrant clients that can restrict a
+// similarity search to points of one language
+type languageSearcher interface {
+	SearchSimilarInLanguage(collectionName, query, language string, limit int) ([]*storage.QdrantQueryResult, error)
+}
+
+// contextSearcher is implemented by Qdrant clients whose similarity searches
+// honor the caller's deadline and priority
+type contextSearcher interface {
+	SearchSimilarContext(ctx context.Context, collectionName, query, language string, limit int) ([]*storage.QdrantQueryResult, error)
+}
+
+// searchSimilar runs a Qdrant similarity search, restricted to language when
+// set, bounded by ctx when the client supports it
+func searchSimilar(ctx context.Context, client storage.QdrantClientInterface, collection, query, language string, limit int) ([]*storage.QdrantQueryResult, error) {
+	if searcher, ok := client.(contextSearcher); ok {
+		return searcher.SearchSimilarContext(ctx, collection, query, language, limit)
+	}
+	if language == "" {
+		return client.SearchSimilar(collection, query, limit)
+	}
+	searcher, ok := client.(languageSearcher)
+	if !ok {
+		return nil, fmt.Errorf("language filtering is not supported by this Qdrant client")
+	}
+	return searcher.SearchSimilarInLanguage(collection, query, language, limit)
+}
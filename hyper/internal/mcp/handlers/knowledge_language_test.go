@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"testing"
+
+	"hyper/internal/mcp/storage"
+)
+
+func TestParseLanguageArg(t *testing.T) {
+	language, err := parseLanguageArg(map[string]interface{}{"language": " DE "}, "language")
+	if err != nil || language != "de" {
+		t.Errorf("parseLanguageArg = %q, %v; want de", language, err)
+	}
+
+	language, err = parseLanguageArg(map[string]interface{}{}, "language")
+	if err != nil || language != "" {
+		t.Errorf("absent language = %q, %v; want empty", language, err)
+	}
+
+	for _, invalid := range []string{"german", "d", "d3"} {
+		if _, err := parseLanguageArg(map[string]interface{}{"commentLanguage": invalid}, "commentLanguage"); err == nil {
+			t.Errorf("parseLanguageArg(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestCommentLanguageFilter(t *testing.T) {
+	if filter := commentLanguageFilter(""); filter != nil {
+		t.Errorf("no language should not filter, got %v", filter)
+	}
+
+	filter := commentLanguageFilter("de")
+	must := filter["must"].([]map[string]interface{})
+	if len(must) != 1 || must[0]["key"] != storage.CommentLanguageKey {
+		t.Errorf("filter = %v, want a match on %s", filter, storage.CommentLanguageKey)
+	}
+}
+
+func TestCodeSearchResultCommentLanguage(t *testing.T) {
+	result := codeSearchResultFromHit(searchTarget{FolderPath: "/repo"}, 0.9, map[string]interface{}{
+		"language":                 "go",
+		storage.CommentLanguageKey: "ja",
+	})
+	if result.Language != "go" || result.CommentLanguage != "ja" {
+		t.Errorf("result languages = %q/%q, want go/ja", result.Language, result.CommentLanguage)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"hyper/internal/mcp/storage"
@@ -52,7 +53,7 @@ func (h *KnowledgeResourceHandler) RegisterKnowledgeResources(server *mcp.Server
 		Description: "Complete list of all Qdrant knowledge collections with metadata, purpose, and example queries",
 		MIMEType:    "application/json",
 	}
-	server.AddResource(collectionsResource, h.handleCollectionsResource)
+	addPaginatedResource(server, collectionsResource, h.handleCollectionsResource)
 
 	// Register recent learnings resource
 	recentLearningsResource := &mcp.Resource{
@@ -61,7 +62,16 @@ func (h *KnowledgeResourceHandler) RegisterKnowledgeResources(server *mcp.Server
 		Description: "Knowledge entries stored in the last 24 hours, grouped by collection and source",
 		MIMEType:    "application/json",
 	}
-	server.AddResource(recentLearningsResource, h.handleRecentLearningsResource)
+	addPaginatedResource(server, recentLearningsResource, h.handleRecentLearningsResource)
+
+	// Register knowledge analytics resource
+	analyticsResource := &mcp.Resource{
+		URI:         "hyperion://knowledge/analytics",
+		Name:        "Knowledge Usage Analytics",
+		Description: "Per-collection query hit counts with never-hit and stale entries, for knowledge base housekeeping",
+		MIMEType:    "application/json",
+	}
+	addPaginatedResource(server, analyticsResource, h.handleAnalyticsResource)
 
 	return nil
 }
@@ -233,6 +243,11 @@ func (h *KnowledgeResourceHandler) handleCollectionsResource(ctx context.Context
 		},
 	}
 
+	page, err := parseResourcePage(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get actual collections from storage and merge with metadata
 	actualCollections := h.knowledgeStorage.ListCollections()
 	collectionMap := make(map[string]bool)
@@ -260,11 +275,14 @@ func (h *KnowledgeResourceHandler) handleCollectionsResource(ctx context.Context
 		})
 	}
 
+	collectionsPage, pagination := paginate(collectionsWithStatus, page)
+
 	jsonData, err := json.MarshalIndent(map[string]interface{}{
-		"collections": collectionsWithStatus,
+		"collections": collectionsPage,
 		"totalDefined": len(collections),
 		"totalWithData": len(actualCollections),
 		"lastUpdated": time.Now().UTC(),
+		"pagination": pagination,
 	}, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal collections data: %w", err)
@@ -283,6 +301,11 @@ func (h *KnowledgeResourceHandler) handleCollectionsResource(ctx context.Context
 
 // handleRecentLearningsResource returns knowledge entries from the last 24 hours
 func (h *KnowledgeResourceHandler) handleRecentLearningsResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	page, err := parseResourcePage(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
 	// Query for entries created in last 24 hours
 	twentyFourHoursAgo := time.Now().UTC().Add(-24 * time.Hour)
 
@@ -291,52 +314,58 @@ func (h *KnowledgeResourceHandler) handleRecentLearningsResource(ctx context.Con
 	var recentLearnings []RecentLearning
 
 	for _, collection := range collections {
-		// Query this collection with a broad search to get recent entries
-		// We'll filter by time after retrieval
-		results, err := h.knowledgeStorage.Query(collection, "recent", 50) // Get up to 50 per collection
+		// List the newest entries of this collection and filter by time after
+		// retrieval; browsing rather than querying keeps resource reads out of
+		// the hit analytics
+		entries, err := h.knowledgeStorage.ListKnowledge(collection, 50) // Get up to 50 per collection
 		if err != nil {
-			// If search fails, try empty query
-			results, err = h.knowledgeStorage.Query(collection, "", 50)
-			if err != nil {
-				continue // Skip collections with errors
-			}
+			continue // Skip collections with errors
 		}
 
-		for _, result := range results {
+		for _, entry := range entries {
 			// Filter by creation time
-			if result.Entry.CreatedAt.After(twentyFourHoursAgo) {
+			if entry.CreatedAt.After(twentyFourHoursAgo) {
 				// Extract topic from metadata or first 100 chars of text
 				topic := ""
-				if title, ok := result.Entry.Metadata["title"].(string); ok {
+				if title, ok := entry.Metadata["title"].(string); ok {
 					topic = title
-				} else if len(result.Entry.Text) > 100 {
-					topic = result.Entry.Text[:100] + "..."
+				} else if len(entry.Text) > 100 {
+					topic = entry.Text[:100] + "..."
 				} else {
-					topic = result.Entry.Text
+					topic = entry.Text
 				}
 
 				// Extract agent name from metadata
 				agentName := ""
-				if agent, ok := result.Entry.Metadata["agentName"].(string); ok {
+				if agent, ok := entry.Metadata["agentName"].(string); ok {
 					agentName = agent
 				}
 
 				recentLearnings = append(recentLearnings, RecentLearning{
-					ID:         result.Entry.ID,
-					Collection: result.Entry.Collection,
+					ID:         entry.ID,
+					Collection: entry.Collection,
 					Topic:      topic,
-					Text:       result.Entry.Text,
-					Metadata:   result.Entry.Metadata,
-					CreatedAt:  result.Entry.CreatedAt,
+					Text:       entry.Text,
+					Metadata:   entry.Metadata,
+					CreatedAt:  entry.CreatedAt,
 					AgentName:  agentName,
 				})
 			}
 		}
 	}
 
+	// Newest first, in a stable order so pages do not overlap
+	sort.Slice(recentLearnings, func(i, j int) bool {
+		if !recentLearnings[i].CreatedAt.Equal(recentLearnings[j].CreatedAt) {
+			return recentLearnings[i].CreatedAt.After(recentLearnings[j].CreatedAt)
+		}
+		return recentLearnings[i].ID < recentLearnings[j].ID
+	})
+	learningsPage, pagination := paginate(recentLearnings, page)
+
 	// Group by collection
 	byCollection := make(map[string][]RecentLearning)
-	for _, learning := range recentLearnings {
+	for _, learning := range learningsPage {
 		byCollection[learning.Collection] = append(byCollection[learning.Collection], learning)
 	}
 
@@ -347,8 +376,9 @@ func (h *KnowledgeResourceHandler) handleRecentLearningsResource(ctx context.Con
 		},
 		"totalEntries":    len(recentLearnings),
 		"byCollection":    byCollection,
-		"allEntries":      recentLearnings,
+		"allEntries":      learningsPage,
 		"collectionsWithActivity": len(byCollection),
+		"pagination":      pagination,
 	}, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal recent learnings data: %w", err)
@@ -364,3 +394,65 @@ func (h *KnowledgeResourceHandler) handleRecentLearningsResource(ctx context.Con
 		},
 	}, nil
 }
+
+// knowledgeAnalyticsEntryLimit caps the never-hit and stale entries listed per collection
+const knowledgeAnalyticsEntryLimit = 20
+
+// handleAnalyticsResource returns hit statistics and housekeeping candidates per collection
+func (h *KnowledgeResourceHandler) handleAnalyticsResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	analytics, ok := h.knowledgeStorage.(storage.KnowledgeAnalytics)
+	if !ok {
+		return nil, fmt.Errorf("knowledge analytics are not supported by this storage")
+	}
+
+	page, err := parseResourcePage(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	staleAfter := storage.DefaultKnowledgeStaleAfter
+	reports, err := analytics.GetUsageReports(staleAfter, knowledgeAnalyticsEntryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build knowledge analytics: %w", err)
+	}
+
+	totalEntries, neverHit, stale := 0, 0, 0
+	for _, report := range reports {
+		totalEntries += report.TotalEntries
+		neverHit += report.NeverHitCount
+		stale += report.StaleCount
+	}
+	reportsPage, pagination := paginate(reports, page)
+	for _, report := range reportsPage {
+		for _, entry := range report.NeverHitEntries {
+			entry.Text = truncateText(entry.Text, 200)
+		}
+		for _, entry := range report.StaleEntries {
+			entry.Text = truncateText(entry.Text, 200)
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(map[string]interface{}{
+		"staleAfterDays": int(staleAfter.Hours() / 24),
+		"totalEntries":   totalEntries,
+		"neverHitCount":  neverHit,
+		"staleCount":     stale,
+		"collections":    reportsPage,
+		"generatedAt":    time.Now().UTC(),
+		"pagination":     pagination,
+		"hint":           "Never-hit entries are older than the staleness window and were never returned by a query; stale entries have not been returned within it. Review them for deletion or rewording.",
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal knowledge analytics: %w", err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      "hyperion://knowledge/analytics",
+				MIMEType: "application/json",
+				Text:     string(jsonData),
+			},
+		},
+	}, nil
+}
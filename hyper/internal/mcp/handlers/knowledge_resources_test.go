@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -292,3 +293,71 @@ func TestKnowledgeResourceHandler_CollectionCategories(t *testing.T) {
 		assert.True(t, collectionNames[expected], "Should have collection: %s", expected)
 	}
 }
+
+// analyticsKnowledgeStorage adds usage reports to MockKnowledgeStorage
+type analyticsKnowledgeStorage struct {
+	MockKnowledgeStorage
+	reports    []*storage.KnowledgeUsageReport
+	staleAfter time.Duration
+}
+
+func (m *analyticsKnowledgeStorage) GetUsageReports(staleAfter time.Duration, limit int) ([]*storage.KnowledgeUsageReport, error) {
+	m.staleAfter = staleAfter
+	return m.reports, nil
+}
+
+func TestKnowledgeResourceHandler_AnalyticsResource(t *testing.T) {
+	lastHit := time.Now().UTC().Add(-60 * 24 * time.Hour)
+	mockStorage := &analyticsKnowledgeStorage{
+		reports: []*storage.KnowledgeUsageReport{
+			{
+				Collection:    "technical-knowledge",
+				TotalEntries:  10,
+				TotalHits:     4,
+				NeverHitCount: 2,
+				StaleCount:    1,
+				NeverHitEntries: []*storage.KnowledgeUsageEntry{
+					{ID: "never-1", Text: strings.Repeat("x", 500)},
+				},
+				StaleEntries: []*storage.KnowledgeUsageEntry{
+					{ID: "stale-1", Text: "Old pattern", HitCount: 4, LastHitAt: &lastHit},
+				},
+			},
+			{Collection: "adr", TotalEntries: 3, TotalHits: 9},
+		},
+	}
+
+	handler := NewKnowledgeResourceHandler(mockStorage)
+	result, err := handler.handleAnalyticsResource(context.Background(), &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: "hyperion://knowledge/analytics"},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Contents, 1)
+	assert.Equal(t, "hyperion://knowledge/analytics", result.Contents[0].URI)
+	assert.Equal(t, storage.DefaultKnowledgeStaleAfter, mockStorage.staleAfter)
+
+	var response struct {
+		StaleAfterDays int                             `json:"staleAfterDays"`
+		TotalEntries   int                             `json:"totalEntries"`
+		NeverHitCount  int                             `json:"neverHitCount"`
+		StaleCount     int                             `json:"staleCount"`
+		Collections    []*storage.KnowledgeUsageReport `json:"collections"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &response))
+
+	assert.Equal(t, 30, response.StaleAfterDays)
+	assert.Equal(t, 13, response.TotalEntries)
+	assert.Equal(t, 2, response.NeverHitCount)
+	assert.Equal(t, 1, response.StaleCount)
+	require.Len(t, response.Collections, 2)
+	assert.Equal(t, "stale-1", response.Collections[0].StaleEntries[0].ID)
+	assert.Len(t, response.Collections[0].NeverHitEntries[0].Text, 203, "long texts are truncated")
+}
+
+func TestKnowledgeResourceHandler_AnalyticsUnsupported(t *testing.T) {
+	handler := NewKnowledgeResourceHandler(&MockKnowledgeStorage{})
+	_, err := handler.handleAnalyticsResource(context.Background(), &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: "hyperion://knowledge/analytics"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/knowledgeio"
+	"hyper/internal/toolprogress"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerExportKnowledge registers the coordinator_export_knowledge tool
+func (h *ToolHandler) registerExportKnowledge(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_export_knowledge",
+		Description: "Dump a whole knowledge collection to a JSONL file on the server, oldest entry first, with each entry's embedding by default. Use to back up a collection before switching embedding models or to move it to another environment with coordinator_import_knowledge. Same format as GET /api/v1/knowledge/export.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"collection": {
+					Type:        "string",
+					Description: "Collection to export",
+				},
+				"path": {
+					Type:        "string",
+					Description: "File to write, e.g. backups/adr.jsonl (relative paths are resolved against the server's working directory)",
+				},
+				"vectors": {
+					Type:        "boolean",
+					Description: "Include stored embeddings so an import with the same embedding model skips re-embedding (default: true)",
+				},
+				"since": {
+					Type:        "string",
+					Description: "Optional: only entries created at or after this RFC 3339 time",
+				},
+				"overwrite": {
+					Type:        "boolean",
+					Description: "Replace path if it already exists (default: false)",
+				},
+			},
+			Required: []string{"collection", "path"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleExportKnowledge(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerImportKnowledge registers the coordinator_import_knowledge tool
+func (h *ToolHandler) registerImportKnowledge(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_import_knowledge",
+		Description: "Restore knowledge from a JSONL file on the server, such as one written by coordinator_export_knowledge. Stored vectors are reused when they match the embedding dimension; reembed=true ignores them and embeds every entry again, e.g. after switching embedding models. Returns imported and failed counts per collection and per-line errors.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"path": {
+					Type:        "string",
+					Description: "JSONL file to import, one {\"collection\", \"text\", \"metadata\", \"vector\"} object per line",
+				},
+				"collection": {
+					Type:        "string",
+					Description: "Optional: collection for lines that do not name one",
+				},
+				"reembed": {
+					Type:        "boolean",
+					Description: "Embed every entry again instead of reusing exported vectors (default: false)",
+				},
+				"batchSize": {
+					Type:        "number",
+					Description: fmt.Sprintf("Entries embedded per batch (default: %d, max: %d)", knowledgeio.DefaultBatchSize, knowledgeio.MaxBatchSize),
+				},
+			},
+			Required: []string{"path"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleImportKnowledge(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleExportKnowledge handles the coordinator_export_knowledge tool call
+func (h *ToolHandler) handleExportKnowledge(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	collection := strings.TrimSpace(getStringField(args, "collection", ""))
+	if collection == "" {
+		return createCodedErrorResult(errcode.Validation, "collection parameter is required"), nil, nil
+	}
+	path, err := transferPath(args)
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+	withVectors := true
+	if v, ok := args["vectors"].(bool); ok {
+		withVectors = v
+	}
+	overwrite, _ := args["overwrite"].(bool)
+
+	var since time.Time
+	if raw := getStringField(args, "since", ""); raw != "" {
+		if since, err = time.Parse(time.RFC3339Nano, raw); err != nil {
+			return createCodedErrorResult(errcode.Validation, fmt.Sprintf("invalid since %q: must be an RFC 3339 time", raw)), nil, nil
+		}
+	}
+
+	export, err := knowledgeio.Exporter(h.knowledgeStorage, since)
+	if err != nil {
+		return createCodedErrorResult(errcode.Of(err), err.Error()), nil, nil
+	}
+
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return createCodedErrorResult(errcode.Conflict, fmt.Sprintf("%s already exists: set overwrite=true to replace it", path)), nil, nil
+	}
+
+	// Write next to the target and rename, so a failed export never leaves a
+	// truncated file that looks like a complete backup
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to create export file: %s", err.Error())), nil, nil
+	}
+	defer os.Remove(file.Name())
+
+	exported, err := knowledgeio.WriteNDJSON(ctx, export, collection, withVectors, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write export: %w", closeErr)
+	}
+	if err != nil {
+		return createCodedErrorResult(errcode.Of(err), fmt.Sprintf("export failed after %d entries: %s", exported, err.Error())), nil, nil
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return createErrorResult(fmt.Sprintf("failed to write export file: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"collection": collection,
+		"path":       path,
+		"exported":   exported,
+		"vectors":    withVectors,
+	}
+	if !since.IsZero() {
+		response["since"] = since
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// handleImportKnowledge handles the coordinator_import_knowledge tool call
+func (h *ToolHandler) handleImportKnowledge(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	path, err := transferPath(args)
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+
+	batchSize := knowledgeio.DefaultBatchSize
+	if size, ok := args["batchSize"].(float64); ok && size > 0 {
+		batchSize = int(size)
+		if batchSize > knowledgeio.MaxBatchSize {
+			batchSize = knowledgeio.MaxBatchSize
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("failed to open import file: %s", err.Error())), nil, nil
+	}
+	defer file.Close()
+
+	reader, err := knowledgeio.NewRowReader(knowledgeio.FormatNDJSON, file, strings.TrimSpace(getStringField(args, "collection", "")))
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+
+	reembed, _ := args["reembed"].(bool)
+	importer := &knowledgeio.Importer{Storage: h.knowledgeStorage, BatchSize: batchSize, Reembed: reembed}
+	if toolprogress.Enabled(ctx) {
+		importer.OnProgress = func(progress knowledgeio.Progress) {
+			toolprogress.Report(ctx, toolprogress.Update{
+				Progress: float64(progress.Processed),
+				Message:  fmt.Sprintf("%d entries imported, %d failed", progress.Imported, progress.Failed),
+			})
+		}
+	}
+	report, err := importer.Run(ctx, knowledgeio.FormatNDJSON, reader)
+
+	if err != nil {
+		// Lines before the failure were imported; the report says how many
+		result := createCodedErrorResult(errcode.Validation, fmt.Sprintf("import stopped after %d lines: %s", report.Processed, err.Error()))
+		result.StructuredContent.(map[string]interface{})["report"] = report
+		return result, nil, nil
+	}
+
+	response := map[string]interface{}{"path": path, "report": report}
+	return structuredToolResult(response), response, nil
+}
+
+// transferPath reads the path argument as an absolute file path
+func transferPath(args map[string]interface{}) (string, error) {
+	path := strings.TrimSpace(getStringField(args, "path", ""))
+	if path == "" {
+		return "", fmt.Errorf("path parameter is required")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	return abs, nil
+}
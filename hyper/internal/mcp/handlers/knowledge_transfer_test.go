@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hyper/internal/errcode"
+	"hyper/internal/knowledgeio"
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transferKnowledgeStorage exports fixed entries and records batched upserts
+type transferKnowledgeStorage struct {
+	MockKnowledgeStorage
+	entries []*storage.KnowledgeEntry
+	batches map[string][]storage.KnowledgeInput
+}
+
+func (s *transferKnowledgeStorage) ExportKnowledge(ctx context.Context, collection string, withVectors bool, visit func(*storage.ExportedKnowledge) error) error {
+	for _, entry := range s.entries {
+		if entry.Collection != collection {
+			continue
+		}
+		exported := &storage.ExportedKnowledge{KnowledgeEntry: entry}
+		if withVectors {
+			exported.Vector = []float64{0.5, 0.25}
+		}
+		if err := visit(exported); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *transferKnowledgeStorage) UpsertBatch(collection string, inputs []storage.KnowledgeInput) ([]*storage.KnowledgeEntry, error) {
+	s.batches[collection] = append(s.batches[collection], inputs...)
+	return make([]*storage.KnowledgeEntry, len(inputs)), nil
+}
+
+func TestExportImportKnowledge_RoundTrip(t *testing.T) {
+	source := &transferKnowledgeStorage{entries: []*storage.KnowledgeEntry{
+		{ID: "1", Collection: "adr", Text: "Use MongoDB", Metadata: map[string]interface{}{"status": "accepted"}},
+		{ID: "2", Collection: "adr", Text: "Use Qdrant"},
+		{ID: "3", Collection: "other", Text: "Not exported"},
+	}}
+	path := filepath.Join(t.TempDir(), "adr.jsonl")
+
+	result, _, err := NewToolHandler(nil, source, nil).handleExportKnowledge(context.Background(), map[string]interface{}{"collection": "adr", "path": path})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, 2, result.StructuredContent.(map[string]interface{})["exported"])
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 2)
+	assert.Contains(t, string(data), `"vector":[0.5,0.25]`, "vectors are exported by default")
+
+	// An existing file is only replaced on request
+	result, _, _ = NewToolHandler(nil, source, nil).handleExportKnowledge(context.Background(), map[string]interface{}{"collection": "adr", "path": path})
+	assert.Equal(t, errcode.Conflict, errorCode(t, result))
+
+	target := &transferKnowledgeStorage{batches: map[string][]storage.KnowledgeInput{}}
+	handler := NewToolHandler(nil, target, nil)
+	result, _, err = handler.handleImportKnowledge(context.Background(), map[string]interface{}{"path": path})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	report := result.StructuredContent.(map[string]interface{})["report"].(*knowledgeio.Report)
+	assert.Equal(t, 2, report.Imported)
+	require.Len(t, target.batches["adr"], 2)
+	assert.Equal(t, []float64{0.5, 0.25}, target.batches["adr"][0].Vector)
+	assert.Equal(t, "accepted", target.batches["adr"][0].Metadata["status"])
+
+	// Re-embedding drops the exported vectors
+	target.batches = map[string][]storage.KnowledgeInput{}
+	result, _, _ = handler.handleImportKnowledge(context.Background(), map[string]interface{}{"path": path, "reembed": true})
+	require.False(t, result.IsError)
+	assert.Nil(t, target.batches["adr"][0].Vector)
+}
+
+func TestExportImportKnowledge_Validation(t *testing.T) {
+	handler := NewToolHandler(nil, &transferKnowledgeStorage{}, nil)
+	ctx := context.Background()
+
+	result, _, _ := handler.handleExportKnowledge(ctx, map[string]interface{}{"path": "adr.jsonl"})
+	assert.Equal(t, errcode.Validation, errorCode(t, result))
+
+	result, _, _ = handler.handleExportKnowledge(ctx, map[string]interface{}{"collection": "adr", "path": "adr.jsonl", "since": "yesterday"})
+	assert.Equal(t, errcode.Validation, errorCode(t, result))
+
+	result, _, _ = NewToolHandler(nil, &MockKnowledgeStorage{}, nil).handleExportKnowledge(ctx, map[string]interface{}{"collection": "adr", "path": "adr.jsonl"})
+	assert.Equal(t, errcode.Internal, errorCode(t, result), "storage without export support")
+
+	result, _, _ = handler.handleImportKnowledge(ctx, map[string]interface{}{"path": filepath.Join(t.TempDir(), "missing.jsonl")})
+	assert.Equal(t, errcode.NotFound, errorCode(t, result))
+}
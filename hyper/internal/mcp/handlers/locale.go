@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+
+	"hyper/internal/i18n"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// NewLocaleMiddleware returns MCP receiving middleware that attaches the caller's
+// locale to tools/call contexts, so handlers can render messages with i18n.T.
+// HTTP sessions choose a locale with X-Hyper-Locale or Accept-Language; other
+// requests use HYPER_LOCALE.
+func NewLocaleMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if !ok || method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			locale := i18n.DefaultFromEnv()
+			if extra := callReq.GetExtra(); extra != nil {
+				if headerLocale, ok := i18n.FromHeader(extra.Header); ok {
+					locale = headerLocale
+				}
+			}
+
+			return next(i18n.WithLocale(ctx, locale), method, req)
+		}
+	}
+}
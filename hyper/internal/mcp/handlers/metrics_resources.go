@@ -14,9 +14,20 @@ import (
 
 // MetricsResourceHandler manages performance metrics resources
 type MetricsResourceHandler struct {
-	taskStorage storage.TaskStorage
+	taskStorage     storage.TaskStorage
+	integrityChecks integrityCheckLister
 }
 
+// integrityCheckLister lists index integrity check runs (implemented by
+// storage.CodeIndexStorage)
+type integrityCheckLister interface {
+	ListIntegrityChecks(limit int) ([]*storage.IntegrityCheck, error)
+}
+
+// integrityRunsReported is how many recent integrity check runs the
+// index-integrity metrics cover
+const integrityRunsReported = 30
+
 // NewMetricsResourceHandler creates a new metrics resource handler
 func NewMetricsResourceHandler(taskStorage storage.TaskStorage) *MetricsResourceHandler {
 	return &MetricsResourceHandler{
@@ -24,6 +35,24 @@ func NewMetricsResourceHandler(taskStorage storage.TaskStorage) *MetricsResource
 	}
 }
 
+// SetIntegrityChecks enables hyperion://metrics/index-integrity, reporting
+// the runs of the index integrity verification job
+func (h *MetricsResourceHandler) SetIntegrityChecks(checks integrityCheckLister) {
+	h.integrityChecks = checks
+}
+
+// IntegrityMetrics summarizes recent index integrity check runs
+type IntegrityMetrics struct {
+	Runs          int            `json:"runs"`
+	Sampled       int            `json:"sampled"`
+	Verified      int            `json:"verified"`
+	Skipped       int            `json:"skipped"`
+	Discrepancies int            `json:"discrepancies"`
+	ByKind        map[string]int `json:"byKind"`              // Discrepancies per kind
+	FailedRuns    int            `json:"failedRuns"`          // Runs that stopped with an error
+	LastRunAt     *time.Time     `json:"lastRunAt,omitempty"` // Start of the latest run
+}
+
 // SquadVelocityMetrics represents task completion rates by squad
 type SquadVelocityMetrics struct {
 	SquadName         string    `json:"squadName"`
@@ -94,6 +123,75 @@ type TaskComplexityMetrics struct {
 	SimpleTasksPercent  float64            `json:"simpleTasksPercent"`  // tasks with ≤3 TODOs
 }
 
+// EstimationStats compares estimated and actual TODO effort
+type EstimationStats struct {
+	TodoCount             int     `json:"todoCount"`
+	EstimatedTodos        int     `json:"estimatedTodos"`  // TODOs with estimatedMinutes
+	ReportedTodos         int     `json:"reportedTodos"`   // TODOs with actualMinutes
+	ComparableTodos       int     `json:"comparableTodos"` // TODOs with both
+	TotalEstimatedMinutes int     `json:"totalEstimatedMinutes"`
+	TotalActualMinutes    int     `json:"totalActualMinutes"`
+	AccuracyRatio         float64 `json:"accuracyRatio"`            // actual/estimated over comparable TODOs; >1 means underestimated
+	MeanAbsoluteErrorPct  float64 `json:"meanAbsoluteErrorPercent"` // mean |actual-estimated|/estimated over comparable TODOs
+}
+
+// TaskEstimationMetrics holds estimation stats for one agent task
+type TaskEstimationMetrics struct {
+	AgentTaskID string             `json:"agentTaskId"`
+	HumanTaskID string             `json:"humanTaskId"`
+	AgentName   string             `json:"agentName"`
+	Status      storage.TaskStatus `json:"status"`
+	EstimationStats
+}
+
+// AgentEstimationMetrics holds estimation stats aggregated over an agent's tasks
+type AgentEstimationMetrics struct {
+	AgentName string `json:"agentName"`
+	TaskCount int    `json:"taskCount"`
+	EstimationStats
+}
+
+// estimationAccumulator sums TODO estimates and actuals
+type estimationAccumulator struct {
+	stats               EstimationStats
+	comparableEstimated int
+	comparableActual    int
+	absErrorPctSum      float64
+}
+
+func (a *estimationAccumulator) add(todos []storage.TodoItem) {
+	for _, todo := range todos {
+		a.stats.TodoCount++
+		if todo.EstimatedMinutes > 0 {
+			a.stats.EstimatedTodos++
+			a.stats.TotalEstimatedMinutes += todo.EstimatedMinutes
+		}
+		if todo.ActualMinutes > 0 {
+			a.stats.ReportedTodos++
+			a.stats.TotalActualMinutes += todo.ActualMinutes
+		}
+		if todo.EstimatedMinutes > 0 && todo.ActualMinutes > 0 {
+			a.stats.ComparableTodos++
+			a.comparableEstimated += todo.EstimatedMinutes
+			a.comparableActual += todo.ActualMinutes
+			diff := float64(todo.ActualMinutes - todo.EstimatedMinutes)
+			if diff < 0 {
+				diff = -diff
+			}
+			a.absErrorPctSum += diff / float64(todo.EstimatedMinutes) * 100.0
+		}
+	}
+}
+
+func (a *estimationAccumulator) result() EstimationStats {
+	stats := a.stats
+	if stats.ComparableTodos > 0 {
+		stats.AccuracyRatio = float64(a.comparableActual) / float64(a.comparableEstimated)
+		stats.MeanAbsoluteErrorPct = a.absErrorPctSum / float64(stats.ComparableTodos)
+	}
+	return stats
+}
+
 // RegisterMetricsResources registers all metrics resources with the MCP server
 func (h *MetricsResourceHandler) RegisterMetricsResources(server *mcp.Server) error {
 	// Register squad-velocity resource
@@ -114,6 +212,26 @@ func (h *MetricsResourceHandler) RegisterMetricsResources(server *mcp.Server) er
 	}
 	server.AddResource(contextEfficiencyResource, h.handleContextEfficiency)
 
+	// Register estimation-accuracy resource
+	estimationAccuracyResource := &mcp.Resource{
+		URI:         "hyperion://metrics/estimation-accuracy",
+		Name:        "Estimation Accuracy Metrics",
+		Description: "Estimated vs actual TODO minutes per agent task and per agent",
+		MIMEType:    "application/json",
+	}
+	server.AddResource(estimationAccuracyResource, h.handleEstimationAccuracy)
+
+	// Register index-integrity resource
+	if h.integrityChecks != nil {
+		indexIntegrityResource := &mcp.Resource{
+			URI:         "hyperion://metrics/index-integrity",
+			Name:        "Index Integrity Metrics",
+			Description: "Results of the scheduled code index integrity checks: sampled chunks whose vectors are missing or no longer match",
+			MIMEType:    "application/json",
+		}
+		server.AddResource(indexIntegrityResource, h.handleIndexIntegrity)
+	}
+
 	return nil
 }
 
@@ -275,6 +393,88 @@ func (h *MetricsResourceHandler) handleContextEfficiency(ctx context.Context, re
 	}, nil
 }
 
+// handleEstimationAccuracy computes and returns estimate vs actual metrics
+func (h *MetricsResourceHandler) handleEstimationAccuracy(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	allAgentTasks := h.taskStorage.ListAllAgentTasks()
+
+	overall, perAgent, perTask := h.calculateEstimationMetrics(allAgentTasks)
+
+	jsonData, err := json.MarshalIndent(map[string]interface{}{
+		"overall":   overall,
+		"agents":    perAgent,
+		"tasks":     perTask,
+		"timestamp": time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal estimation accuracy metrics: %w", err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      "hyperion://metrics/estimation-accuracy",
+				MIMEType: "application/json",
+				Text:     string(jsonData),
+			},
+		},
+	}, nil
+}
+
+// calculateEstimationMetrics aggregates TODO estimates and actuals overall, per
+// agent and per task. Tasks without any estimate or actual are left out of the
+// per-task list.
+func (h *MetricsResourceHandler) calculateEstimationMetrics(tasks []*storage.AgentTask) (EstimationStats, []AgentEstimationMetrics, []TaskEstimationMetrics) {
+	var overall estimationAccumulator
+	agentAcc := make(map[string]*estimationAccumulator)
+	agentTaskCount := make(map[string]int)
+	perTask := make([]TaskEstimationMetrics, 0)
+
+	for _, task := range tasks {
+		var taskAcc estimationAccumulator
+		taskAcc.add(task.Todos)
+		overall.add(task.Todos)
+
+		acc, ok := agentAcc[task.AgentName]
+		if !ok {
+			acc = &estimationAccumulator{}
+			agentAcc[task.AgentName] = acc
+		}
+		acc.add(task.Todos)
+		agentTaskCount[task.AgentName]++
+
+		stats := taskAcc.result()
+		if stats.EstimatedTodos == 0 && stats.ReportedTodos == 0 {
+			continue
+		}
+		perTask = append(perTask, TaskEstimationMetrics{
+			AgentTaskID:     task.ID,
+			HumanTaskID:     task.HumanTaskID,
+			AgentName:       task.AgentName,
+			Status:          task.Status,
+			EstimationStats: stats,
+		})
+	}
+
+	perAgent := make([]AgentEstimationMetrics, 0, len(agentAcc))
+	for agentName, acc := range agentAcc {
+		perAgent = append(perAgent, AgentEstimationMetrics{
+			AgentName:       agentName,
+			TaskCount:       agentTaskCount[agentName],
+			EstimationStats: acc.result(),
+		})
+	}
+
+	// Agents with the most comparable TODOs first, since their ratios are most meaningful
+	sort.Slice(perAgent, func(i, j int) bool {
+		if perAgent[i].ComparableTodos != perAgent[j].ComparableTodos {
+			return perAgent[i].ComparableTodos > perAgent[j].ComparableTodos
+		}
+		return perAgent[i].AgentName < perAgent[j].AgentName
+	})
+
+	return overall.result(), perAgent, perTask
+}
+
 // calculateOverallStats computes platform-wide efficiency statistics
 func (h *MetricsResourceHandler) calculateOverallStats(tasks []*storage.AgentTask) OverallEfficiencyStats {
 	stats := OverallEfficiencyStats{
@@ -574,3 +774,57 @@ func min(a, b float64) float64 {
 	}
 	return b
 }
+
+// handleIndexIntegrity reports the latest index integrity check with its
+// discrepancies, and totals over recent runs
+func (h *MetricsResourceHandler) handleIndexIntegrity(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	checks, err := h.integrityChecks.ListIntegrityChecks(integrityRunsReported)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *storage.IntegrityCheck
+	if len(checks) > 0 {
+		latest = checks[0]
+	}
+	jsonData, err := json.MarshalIndent(map[string]interface{}{
+		"latest":    latest,
+		"recent":    calculateIntegrityMetrics(checks),
+		"timestamp": time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal index integrity metrics: %w", err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      "hyperion://metrics/index-integrity",
+				MIMEType: "application/json",
+				Text:     string(jsonData),
+			},
+		},
+	}, nil
+}
+
+// calculateIntegrityMetrics totals integrity check runs, newest first
+func calculateIntegrityMetrics(checks []*storage.IntegrityCheck) IntegrityMetrics {
+	metrics := IntegrityMetrics{Runs: len(checks), ByKind: make(map[string]int)}
+	for _, check := range checks {
+		metrics.Sampled += check.Sampled
+		metrics.Verified += check.Verified
+		metrics.Skipped += check.Skipped
+		metrics.Discrepancies += len(check.Discrepancies)
+		for _, discrepancy := range check.Discrepancies {
+			metrics.ByKind[discrepancy.Kind]++
+		}
+		if check.Error != "" {
+			metrics.FailedRuns++
+		}
+	}
+	if len(checks) > 0 {
+		lastRunAt := checks[0].StartedAt
+		metrics.LastRunAt = &lastRunAt
+	}
+	return metrics
+}
@@ -52,6 +52,26 @@ func (m *MockMetricsTaskStorage) ListAllAgentTasks() []*storage.AgentTask {
 	return m.tasks
 }
 
+func (m *MockMetricsTaskStorage) ListAgentTasksByStatus(status storage.TaskStatus) ([]*storage.AgentTask, error) {
+	var tasks []*storage.AgentTask
+	for _, task := range m.tasks {
+		if task.Status == status {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (m *MockMetricsTaskStorage) ListAgentTasksByHumanTask(humanTaskID string) ([]*storage.AgentTask, error) {
+	var tasks []*storage.AgentTask
+	for _, task := range m.tasks {
+		if task.HumanTaskID == humanTaskID {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
 func (m *MockMetricsTaskStorage) UpdateTaskStatus(taskID string, status storage.TaskStatus, notes string) error {
 	return nil
 }
@@ -60,6 +80,18 @@ func (m *MockMetricsTaskStorage) UpdateTodoStatus(agentTaskID, todoID string, st
 	return nil
 }
 
+func (m *MockMetricsTaskStorage) UpdateChecklistItemStatus(agentTaskID, todoID, itemID string, status storage.TodoStatus, notes string) error {
+	return nil
+}
+
+func (m *MockMetricsTaskStorage) UpdateTodoTime(agentTaskID, todoID string, estimatedMinutes, actualMinutes *int) error {
+	return nil
+}
+
+func (m *MockMetricsTaskStorage) GetAgentTaskActivity(agentTaskID string) ([]storage.TaskActivity, error) {
+	return nil, nil
+}
+
 func (m *MockMetricsTaskStorage) AddTaskPromptNotes(agentTaskID string, notes string) error {
 	return nil
 }
@@ -88,6 +120,18 @@ func (m *MockMetricsTaskStorage) ClearAllTasks() (*storage.ClearResult, error) {
 	return nil, nil
 }
 
+func (m *MockMetricsTaskStorage) AppendTaskChangeLog(agentTaskID string, entry storage.FileChangeEntry) error {
+	return nil
+}
+
+func (m *MockMetricsTaskStorage) CloneHumanTask(sourceTaskID, project, prompt string) (*storage.HumanTask, []*storage.AgentTask, error) {
+	return nil, nil, nil
+}
+
+func (m *MockMetricsTaskStorage) SetTaskDueDate(taskID string, dueAt *time.Time) error {
+	return nil
+}
+
 func TestMetricsResourceHandler_SquadVelocity(t *testing.T) {
 	now := time.Now().UTC()
 	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
@@ -337,3 +381,75 @@ func TestEfficiencyAnalysis(t *testing.T) {
 		assert.Contains(t, result, tt.expected)
 	}
 }
+
+func TestCalculateEstimationMetrics(t *testing.T) {
+	tasks := []*storage.AgentTask{
+		{
+			ID:        "task-1",
+			AgentName: "go-dev",
+			Todos: []storage.TodoItem{
+				{EstimatedMinutes: 30, ActualMinutes: 60}, // 100% over
+				{EstimatedMinutes: 60, ActualMinutes: 30}, // 50% under
+				{EstimatedMinutes: 20},                    // not reported yet
+			},
+		},
+		{
+			ID:        "task-2",
+			AgentName: "go-dev",
+			Todos:     []storage.TodoItem{{}, {}}, // no time tracking
+		},
+		{
+			ID:        "task-3",
+			AgentName: "ui-dev",
+			Todos:     []storage.TodoItem{{EstimatedMinutes: 10, ActualMinutes: 10}},
+		},
+	}
+
+	handler := &MetricsResourceHandler{}
+	overall, perAgent, perTask := handler.calculateEstimationMetrics(tasks)
+
+	assert.Equal(t, 6, overall.TodoCount)
+	assert.Equal(t, 4, overall.EstimatedTodos)
+	assert.Equal(t, 3, overall.ComparableTodos)
+	assert.Equal(t, 120, overall.TotalEstimatedMinutes)
+	assert.Equal(t, 100, overall.TotalActualMinutes)
+	// Comparable: estimated 100, actual 100
+	assert.InDelta(t, 1.0, overall.AccuracyRatio, 0.001)
+	// (100 + 50 + 0) / 3
+	assert.InDelta(t, 50.0, overall.MeanAbsoluteErrorPct, 0.001)
+
+	require.Len(t, perAgent, 2)
+	assert.Equal(t, "go-dev", perAgent[0].AgentName)
+	assert.Equal(t, 2, perAgent[0].TaskCount)
+	assert.InDelta(t, 1.0, perAgent[0].AccuracyRatio, 0.001)
+	assert.Equal(t, "ui-dev", perAgent[1].AgentName)
+
+	// Tasks without any time tracking are omitted
+	require.Len(t, perTask, 2)
+	assert.Equal(t, "task-1", perTask[0].AgentTaskID)
+	assert.Equal(t, 2, perTask[0].ComparableTodos)
+	assert.Equal(t, "task-3", perTask[1].AgentTaskID)
+}
+
+func TestCalculateIntegrityMetrics(t *testing.T) {
+	latest := time.Date(2026, 3, 2, 4, 0, 0, 0, time.UTC)
+	metrics := calculateIntegrityMetrics([]*storage.IntegrityCheck{
+		{StartedAt: latest, Sampled: 50, Verified: 47, Skipped: 1, Discrepancies: []storage.IntegrityDiscrepancy{
+			{Kind: storage.IntegrityMissingPoint},
+			{Kind: storage.IntegrityLowSimilarity},
+		}},
+		{StartedAt: latest.Add(-24 * time.Hour), Sampled: 50, Verified: 49, Discrepancies: []storage.IntegrityDiscrepancy{
+			{Kind: storage.IntegrityMissingPoint},
+		}},
+		{StartedAt: latest.Add(-48 * time.Hour), Error: "failed to sample chunks"},
+	})
+
+	assert.Equal(t, 3, metrics.Runs)
+	assert.Equal(t, 100, metrics.Sampled)
+	assert.Equal(t, 96, metrics.Verified)
+	assert.Equal(t, 1, metrics.Skipped)
+	assert.Equal(t, 3, metrics.Discrepancies)
+	assert.Equal(t, map[string]int{storage.IntegrityMissingPoint: 2, storage.IntegrityLowSimilarity: 1}, metrics.ByKind)
+	assert.Equal(t, 1, metrics.FailedRuns)
+	assert.Equal(t, latest, *metrics.LastRunAt)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/priority"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Request _meta keys carrying priority and deadline hints on tools/call
+const (
+	priorityMetaKey  = "priority"
+	timeoutMsMetaKey = "timeoutMs"
+)
+
+// NewPriorityMiddleware returns MCP receiving middleware that attaches the
+// caller's priority and deadline to tools/call contexts, so storage and
+// vector operations stop at the deadline and bulk indexing yields to agent
+// queries. Calls are interactive unless _meta.priority (or the
+// X-Hyper-Priority header of HTTP sessions) says "bulk"; _meta.timeoutMs or
+// X-Hyper-Timeout-Ms set a deadline.
+func NewPriorityMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if !ok || method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			var header http.Header
+			if extra := callReq.GetExtra(); extra != nil {
+				header = extra.Header
+			}
+			var meta mcp.Meta
+			if callReq.Params != nil {
+				meta = callReq.Params.Meta
+			}
+			level, timeout, err := callPriority(header, meta)
+			if err != nil {
+				return createCodedErrorResult(errcode.Validation, err.Error()), nil
+			}
+
+			ctx = priority.WithLevel(ctx, level)
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// callPriority reads the hints of a tool call; _meta takes precedence over headers
+func callPriority(header http.Header, meta mcp.Meta) (priority.Level, time.Duration, error) {
+	level, timeout, err := priority.FromHeader(header)
+	if err != nil {
+		return priority.Unset, 0, err
+	}
+
+	if raw, ok := meta[priorityMetaKey]; ok {
+		name, _ := raw.(string)
+		if level, err = priority.Parse(name); err != nil {
+			return priority.Unset, 0, fmt.Errorf("_meta.%s: %w", priorityMetaKey, err)
+		}
+	}
+	if raw, ok := meta[timeoutMsMetaKey]; ok {
+		ms, isNumber := raw.(float64)
+		if !isNumber {
+			return priority.Unset, 0, fmt.Errorf("_meta.%s must be a number of milliseconds", timeoutMsMetaKey)
+		}
+		if timeout, err = priority.ParseTimeoutMs(fmt.Sprintf("%.0f", ms)); err != nil {
+			return priority.Unset, 0, fmt.Errorf("_meta.%s: %w", timeoutMsMetaKey, err)
+		}
+	}
+
+	if level == priority.Unset {
+		level = priority.Interactive
+	}
+	return level, timeout, nil
+}
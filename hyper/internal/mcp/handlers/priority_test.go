@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"hyper/internal/priority"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallPriority(t *testing.T) {
+	level, timeout, err := callPriority(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, priority.Interactive, level)
+	assert.Zero(t, timeout)
+
+	header := http.Header{}
+	header.Set(priority.PriorityHeader, "bulk")
+	header.Set(priority.TimeoutHeader, "3000")
+	level, timeout, err = callPriority(header, nil)
+	require.NoError(t, err)
+	assert.Equal(t, priority.Bulk, level)
+	assert.Equal(t, 3*time.Second, timeout)
+
+	// _meta overrides the session headers
+	level, timeout, err = callPriority(header, mcp.Meta{"priority": "interactive", "timeoutMs": float64(250)})
+	require.NoError(t, err)
+	assert.Equal(t, priority.Interactive, level)
+	assert.Equal(t, 250*time.Millisecond, timeout)
+
+	_, _, err = callPriority(nil, mcp.Meta{"priority": "asap"})
+	assert.Error(t, err)
+	_, _, err = callPriority(nil, mcp.Meta{"timeoutMs": "soon"})
+	assert.Error(t, err)
+}
@@ -16,6 +16,8 @@ import (
 type QdrantToolHandler struct {
 	qdrantClient     storage.QdrantClientInterface
 	metadataRegistry *ToolMetadataRegistry
+
+	knowledgeEnvironments *storage.KnowledgeEnvironmentStorage // Optional: template variables for knowledge_find
 }
 
 // NewQdrantToolHandler creates a new Qdrant tool handler
@@ -30,6 +32,11 @@ func (h *QdrantToolHandler) SetMetadataRegistry(registry *ToolMetadataRegistry)
 	h.metadataRegistry = registry
 }
 
+// SetKnowledgeEnvironments enables environment interpolation of knowledge_find results
+func (h *QdrantToolHandler) SetKnowledgeEnvironments(store *storage.KnowledgeEnvironmentStorage) {
+	h.knowledgeEnvironments = store
+}
+
 // RegisterQdrantTools registers Qdrant tools with the MCP server
 func (h *QdrantToolHandler) RegisterQdrantTools(server *mcp.Server) error {
 	// Register knowledge_find tool
@@ -42,6 +49,11 @@ func (h *QdrantToolHandler) RegisterQdrantTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register knowledge_store tool: %w", err)
 	}
 
+	// Register knowledge_explain tool
+	if err := h.registerKnowledgeExplain(server); err != nil {
+		return fmt.Errorf("failed to register knowledge_explain tool: %w", err)
+	}
+
 	return nil
 }
 
@@ -74,34 +86,26 @@ func (h *QdrantToolHandler) registerKnowledgeFind(server *mcp.Server) error {
 					Type:        "number",
 					Description: "Maximum characters to return per result when retrieveMode is 'chunk' (default: 500, min: 100, max: 2000)",
 				},
+				"timeoutMs": {
+					Type:        "number",
+					Description: "Optional: time budget in milliseconds (max 60000). If the search does not finish in time, returns what was gathered and marks the response as truncated instead of hanging",
+				},
+				"environment": environmentSchema,
+				"language":    languageSchema,
 			},
 			Required: []string{"collectionName", "query"},
 		},
 	}
 
-	server.AddTool(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.metadataRegistry.RegisterToolWithServer(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, err := extractArguments(req)
 		if err != nil {
 			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
 		}
-		result, _, err := h.handleQdrantFind(args)
+		result, _, err := h.handleQdrantFind(ctx, args)
 		return result, err
 	})
 
-	// Report tool to metadata registry for indexing
-	if h.metadataRegistry != nil {
-		h.metadataRegistry.RegisterTool(
-			tool.Name,
-			tool.Description,
-			map[string]interface{}{
-				"type":        "mcp-tool",
-				"name":        tool.Name,
-				"description": tool.Description,
-				"inputSchema": tool.InputSchema,
-			},
-		)
-	}
-
 	return nil
 }
 
@@ -125,12 +129,13 @@ func (h *QdrantToolHandler) registerKnowledgeStore(server *mcp.Server) error {
 					Type:        "object",
 					Description: "Optional metadata to attach (e.g., tags, source, author)",
 				},
+				"autoChunk": autoChunkSchema,
 			},
 			Required: []string{"collectionName", "information"},
 		},
 	}
 
-	server.AddTool(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.metadataRegistry.RegisterToolWithServer(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, err := extractArguments(req)
 		if err != nil {
 			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
@@ -139,25 +144,11 @@ func (h *QdrantToolHandler) registerKnowledgeStore(server *mcp.Server) error {
 		return result, err
 	})
 
-	// Report tool to metadata registry for indexing
-	if h.metadataRegistry != nil {
-		h.metadataRegistry.RegisterTool(
-			tool.Name,
-			tool.Description,
-			map[string]interface{}{
-				"type":        "mcp-tool",
-				"name":        tool.Name,
-				"description": tool.Description,
-				"inputSchema": tool.InputSchema,
-			},
-		)
-	}
-
 	return nil
 }
 
 // handleQdrantFind handles the qdrant_find tool call
-func (h *QdrantToolHandler) handleQdrantFind(args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+func (h *QdrantToolHandler) handleQdrantFind(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
 	// Extract collectionName (required)
 	collectionName, ok := args["collectionName"].(string)
 	if !ok || collectionName == "" {
@@ -202,8 +193,33 @@ func (h *QdrantToolHandler) handleQdrantFind(args map[string]interface{}) (*mcp.
 		}
 	}
 
+	language, err := parseLanguageArg(args, "language")
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	budget, err := newSearchBudget(args)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	env, err := resolveKnowledgeEnvironment(h.knowledgeEnvironments, args)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+	variables := environmentVariables(env)
+
+	searchCtx, cancel := budget.context(ctx)
+	defer cancel()
+
 	// Ensure collection exists (with 768 dimensions for TEI embeddings)
-	if err := h.qdrantClient.EnsureCollection(collectionName, 768); err != nil {
+	_, completed, err := runWithinBudget(searchCtx, budget, func() (struct{}, error) {
+		return struct{}{}, h.qdrantClient.EnsureCollection(collectionName, 768)
+	})
+	if !completed {
+		return truncatedKnowledgeFindResult(collectionName, budget), nil, nil
+	}
+	if err != nil {
 		// Provide helpful recovery guidance based on error type
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "connection") || strings.Contains(errMsg, "dial") || strings.Contains(errMsg, "lookup") {
@@ -213,7 +229,12 @@ func (h *QdrantToolHandler) handleQdrantFind(args map[string]interface{}) (*mcp.
 	}
 
 	// Search for similar entries
-	results, err := h.qdrantClient.SearchSimilar(collectionName, query, limit)
+	results, completed, err := runWithinBudget(searchCtx, budget, func() ([]*storage.QdrantQueryResult, error) {
+		return searchSimilar(searchCtx, h.qdrantClient, collectionName, query, language, limit)
+	})
+	if !completed {
+		return truncatedKnowledgeFindResult(collectionName, budget), nil, nil
+	}
 	if err != nil {
 		// Provide helpful recovery guidance based on error type
 		errMsg := err.Error()
@@ -235,11 +256,14 @@ func (h *QdrantToolHandler) handleQdrantFind(args map[string]interface{}) (*mcp.
 
 	// Format results with chunking if requested
 	resultText := fmt.Sprintf("Found %d results (retrieveMode: %s):\n\n", len(results), retrieveMode)
+	if env != nil {
+		resultText = fmt.Sprintf("Found %d results (retrieveMode: %s, environment: %s):\n\n", len(results), retrieveMode, env.Name)
+	}
 	for i, result := range results {
 		resultText += fmt.Sprintf("Result %d (Score: %.2f)\n", i+1, result.Score)
 
-		// Apply chunking logic based on retrieveMode
-		text := result.Entry.Text
+		// Apply chunking logic based on retrieveMode (after interpolation, so chunks hold real values)
+		text := storage.InterpolateEnvironment(result.Entry.Text, variables)
 		if retrieveMode == "chunk" && len(text) > chunkSize {
 			text = text[:chunkSize] + "..."
 			resultText += fmt.Sprintf("Text (truncated to %d chars): %s\n", chunkSize, text)
@@ -266,6 +290,16 @@ func (h *QdrantToolHandler) handleQdrantFind(args map[string]interface{}) (*mcp.
 	}, results, nil
 }
 
+// truncatedKnowledgeFindResult reports a knowledge_find search that ran out of time
+// before any results were gathered
+func truncatedKnowledgeFindResult(collectionName string, budget searchBudget) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Found 0 results (truncated: search of '%s' did not finish within timeoutMs, %dms elapsed). Retry with a larger timeoutMs or a narrower query.", collectionName, budget.elapsedMs())},
+		},
+	}
+}
+
 // handleQdrantStore handles the qdrant_store tool call
 func (h *QdrantToolHandler) handleQdrantStore(args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
 	// Extract collectionName (required)
@@ -291,8 +325,28 @@ func (h *QdrantToolHandler) handleQdrantStore(args map[string]interface{}) (*mcp
 		metadata = m
 	}
 
+	// Oversized text is rejected, or split into linked chunks when asked
+	chunks := []string{information}
+	if limit := maxKnowledgeTextBytes(); len(information) > limit {
+		if !knowledgeAutoChunk(args) {
+			return oversizedKnowledgeError(len(information), limit), nil, nil
+		}
+		chunks = storage.SplitKnowledgeText(information, limit)
+	}
+
+	budget, err := newSearchBudget(args)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
 	// Ensure collection exists (with 768 dimensions for TEI embeddings)
-	if err := h.qdrantClient.EnsureCollection(collectionName, 768); err != nil {
+	_, completed, err := runWithinBudget(context.Background(), budget, func() (struct{}, error) {
+		return struct{}{}, h.qdrantClient.EnsureCollection(collectionName, 768)
+	})
+	if !completed {
+		return truncatedKnowledgeFindResult(collectionName, budget), nil, nil
+	}
+	if err != nil {
 		// Provide helpful recovery guidance based on error type
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "connection") || strings.Contains(errMsg, "dial") || strings.Contains(errMsg, "lookup") {
@@ -301,6 +355,10 @@ func (h *QdrantToolHandler) handleQdrantStore(args map[string]interface{}) (*mcp
 		return createErrorResult(fmt.Sprintf("Failed to ensure collection exists: %s. Try coordinator_upsert_knowledge as fallback.", errMsg)), nil, nil
 	}
 
+	if len(chunks) > 1 {
+		return h.storeQdrantChunks(collectionName, chunks, metadata)
+	}
+
 	// Generate ID
 	id := storage.GenerateID()
 
@@ -327,6 +385,32 @@ func (h *QdrantToolHandler) handleQdrantStore(args map[string]interface{}) (*mcp
 	}, nil
 }
 
+// storeQdrantChunks stores the chunks of oversized knowledge text as points
+// linked by chunk metadata
+func (h *QdrantToolHandler) storeQdrantChunks(collectionName string, chunks []string, metadata map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	group := storage.GenerateID()
+	ids := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		ids[i] = storage.GenerateID()
+		if err := h.qdrantClient.StorePoint(collectionName, ids[i], chunk, storage.KnowledgeChunkMetadata(metadata, group, i, len(chunks))); err != nil {
+			return createErrorResult(fmt.Sprintf("Failed to store knowledge chunk %d of %d: %s. Chunks stored so far share %s=%s.", i+1, len(chunks), err.Error(), storage.ChunkGroupKey, group)), nil, nil
+		}
+	}
+
+	resultText := fmt.Sprintf("✓ Knowledge stored in Qdrant as %d linked chunks\n\nChunk group: %s\nCollection: %s\nIDs: %s",
+		len(chunks), group, collectionName, strings.Join(ids, ", "))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: resultText},
+		},
+	}, map[string]interface{}{
+		"ids":          ids,
+		"chunkGroupId": group,
+		"collection":   collectionName,
+	}, nil
+}
+
 // extractArguments safely extracts arguments from CallToolRequest
 func extractArguments(req *mcp.CallToolRequest) (map[string]interface{}, error) {
 	if req.Params.Arguments == nil || len(req.Params.Arguments) == 0 {
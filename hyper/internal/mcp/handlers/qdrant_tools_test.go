@@ -121,7 +121,7 @@ func TestKnowledgeFind_ValidParams(t *testing.T) {
 		"limit":          float64(5),
 	}
 
-	result, data, err := handler.handleQdrantFind(args)
+	result, data, err := handler.handleQdrantFind(context.Background(), args)
 
 	require.NoError(t, err)
 	assert.NotNil(t, result)
@@ -144,7 +144,7 @@ func TestKnowledgeFind_MissingCollectionName(t *testing.T) {
 		"query": "test query",
 	}
 
-	result, _, err := handler.handleQdrantFind(args)
+	result, _, err := handler.handleQdrantFind(context.Background(), args)
 
 	require.NoError(t, err)
 	assert.NotNil(t, result)
@@ -164,7 +164,7 @@ func TestKnowledgeFind_MissingQuery(t *testing.T) {
 		"collectionName": "test-collection",
 	}
 
-	result, _, err := handler.handleQdrantFind(args)
+	result, _, err := handler.handleQdrantFind(context.Background(), args)
 
 	require.NoError(t, err)
 	assert.NotNil(t, result)
@@ -192,7 +192,7 @@ func TestKnowledgeFind_LimitExceedsMax(t *testing.T) {
 		"limit":          float64(30), // Request 30, should be capped at 20
 	}
 
-	result, data, err := handler.handleQdrantFind(args)
+	result, data, err := handler.handleQdrantFind(context.Background(), args)
 
 	require.NoError(t, err)
 	assert.NotNil(t, result)
@@ -218,7 +218,7 @@ func TestKnowledgeFind_NoResults(t *testing.T) {
 		"limit":          float64(5),
 	}
 
-	result, _, err := handler.handleQdrantFind(args)
+	result, _, err := handler.handleQdrantFind(context.Background(), args)
 
 	require.NoError(t, err)
 	assert.NotNil(t, result)
@@ -362,7 +362,7 @@ func TestKnowledgeFind_CollectionFailure(t *testing.T) {
 		"query":          "test",
 	}
 
-	result, _, err := handler.handleQdrantFind(args)
+	result, _, err := handler.handleQdrantFind(context.Background(), args)
 
 	require.NoError(t, err)
 	assert.NotNil(t, result)
@@ -455,7 +455,7 @@ func TestKnowledgeFind_ResponseFormat(t *testing.T) {
 		"limit":          float64(5),
 	}
 
-	result, _, err := handler.handleQdrantFind(args)
+	result, _, err := handler.handleQdrantFind(context.Background(), args)
 
 	require.NoError(t, err)
 	assert.NotNil(t, result)
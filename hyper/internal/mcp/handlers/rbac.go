@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"hyper/internal/errcode"
+	"hyper/internal/middleware"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// StdioRole returns the role granted to stdio MCP clients.
+// Stdio clients are local processes launched by the user, so they default to admin;
+// override with MCP_STDIO_ROLE.
+func StdioRole() middleware.Role {
+	if role, ok := middleware.ParseRole(os.Getenv("MCP_STDIO_ROLE")); ok {
+		return role
+	}
+	return middleware.RoleAdmin
+}
+
+// NewRBACMiddleware returns MCP receiving middleware that authorizes tools/call
+// requests against middleware.RequiredRoleForTool, or against policy with the
+// tool arguments when one is configured. Calls must also be within the scopes
+// of the caller's API key or token.
+// HTTP sessions use the role forwarded by middleware.RBACMiddleware; requests
+// without HTTP headers (stdio) use stdioRole.
+func NewRBACMiddleware(stdioRole middleware.Role, policy middleware.Policy, logger *zap.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if !ok || method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			role, userID := callerRole(callReq, stdioRole)
+
+			// Scopes limit the credential whatever its role or the policy allows
+			if scope := middleware.RequiredScopeForTool(callReq.Params.Name); !callerScopes(callReq).Allows(scope) {
+				logger.Warn("Tool call denied by credential scopes",
+					zap.String("tool", callReq.Params.Name),
+					zap.String("requiredScope", string(scope)))
+				return createCodedErrorResult(errcode.PermissionDenied, fmt.Sprintf("permission denied: tool %s requires scope %s",
+					callReq.Params.Name, scope)), nil
+			}
+
+			required := middleware.RequiredRoleForTool(callReq.Params.Name)
+			if policy != nil {
+				var args map[string]interface{}
+				if len(callReq.Params.Arguments) > 0 {
+					// Undecodable arguments are rejected by the tool itself
+					_ = json.Unmarshal(callReq.Params.Arguments, &args)
+				}
+				decision := middleware.Authorize(ctx, policy, middleware.PolicyInput{
+					Kind:         middleware.PolicyKindTool,
+					Principal:    middleware.Principal{UserID: userID, Role: role},
+					Tool:         callReq.Params.Name,
+					Arguments:    args,
+					RequiredRole: required,
+				})
+				if !decision.Allow {
+					logger.Warn("Tool call denied by policy",
+						zap.String("tool", callReq.Params.Name),
+						zap.String("role", string(role)),
+						zap.String("reason", decision.Reason))
+					return createCodedErrorResult(errcode.PermissionDenied, middleware.ToolDeniedMessage(callReq.Params.Name, decision)), nil
+				}
+				return next(ctx, method, req)
+			}
+
+			if !role.Allows(required) {
+				logger.Warn("Tool call denied by RBAC",
+					zap.String("tool", callReq.Params.Name),
+					zap.String("role", string(role)),
+					zap.String("requiredRole", string(required)))
+				return createCodedErrorResult(errcode.PermissionDenied, fmt.Sprintf("permission denied: tool %s requires role %s (current role: %s)",
+					callReq.Params.Name, required, role)), nil
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// callerRole returns the role and user ID of the caller of req: the role
+// forwarded by middleware.RBACMiddleware for HTTP requests, stdioRole otherwise
+func callerRole(req mcp.Request, stdioRole middleware.Role) (middleware.Role, string) {
+	extra := req.GetExtra()
+	if extra == nil || extra.Header == nil {
+		return stdioRole, ""
+	}
+	userID := extra.Header.Get(middleware.UserHeader)
+	if role, ok := middleware.RoleFromHeader(extra.Header); ok {
+		return role, userID
+	}
+	// HTTP requests that bypassed RBACMiddleware carry no role and are denied
+	return "", userID
+}
+
+// callerScopes returns the scopes forwarded by middleware.AuthMiddleware for
+// HTTP requests; stdio requests are unrestricted
+func callerScopes(req mcp.Request) middleware.Scopes {
+	extra := req.GetExtra()
+	if extra == nil {
+		return nil
+	}
+	return middleware.ScopesFromHeader(extra.Header)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"hyper/internal/errcode"
+	"hyper/internal/middleware"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// scopedHeader is the header of an HTTP request authenticated with a
+// credential limited to scopes
+func scopedHeader(role middleware.Role, scopes string) *mcp.RequestExtra {
+	return &mcp.RequestExtra{Header: http.Header{
+		middleware.RoleHeader:   []string{string(role)},
+		middleware.ScopesHeader: []string{scopes},
+	}}
+}
+
+func TestRBACMiddleware_EnforcesScopes(t *testing.T) {
+	handler := NewRBACMiddleware(middleware.RoleAdmin, nil, zap.NewNop())(listAllTools)
+	call := func(extra *mcp.RequestExtra, tool string) *mcp.CallToolResult {
+		result, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{Extra: extra, Params: &mcp.CallToolParamsRaw{Name: tool}})
+		require.NoError(t, err)
+		return result.(*mcp.CallToolResult)
+	}
+
+	// The admin role does not lift a key's scopes
+	readOnly := scopedHeader(middleware.RoleAdmin, "read")
+	assert.False(t, call(readOnly, "coordinator_list_human_tasks").IsError)
+	result := call(readOnly, "coordinator_update_task_status")
+	assert.True(t, result.IsError)
+	assert.Equal(t, errcode.PermissionDenied, errorCode(t, result))
+
+	knowledge := scopedHeader(middleware.RoleContributor, "knowledge-write")
+	assert.False(t, call(knowledge, "coordinator_upsert_knowledge").IsError)
+	assert.True(t, call(knowledge, "coordinator_update_task_status").IsError)
+
+	// Stdio and unscoped HTTP callers are limited by their role only
+	assert.False(t, call(nil, "coordinator_clear_task_board").IsError)
+	assert.False(t, call(roleHeader(middleware.RoleContributor), "coordinator_update_task_status").IsError)
+}
+
+func TestToolVisibility_HidesToolsOutsideScopes(t *testing.T) {
+	visibility := newToolVisibility(middleware.RoleAdmin, nil, ParseClientTrust(""), nil, nil, zap.NewNop())
+	handler := visibility.middleware()(listAllTools)
+
+	result, err := handler(context.Background(), "tools/list", &mcp.ListToolsRequest{Extra: scopedHeader(middleware.RoleAdmin, "read task-write")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"coordinator_list_human_tasks"}, listedToolNames(result))
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"hyper/internal/errcode"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxBatchResources bounds the resources coordinator_read_resources reads in one call
+const maxBatchResources = 25
+
+const (
+	humanTaskURIPrefix = "hyperion://task/human/"
+	agentTaskURIPrefix = "hyperion://task/agent/"
+)
+
+// BatchResourceReader reads a resource by URI; implemented by *ResourceReader
+type BatchResourceReader interface {
+	ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error)
+}
+
+// ResourceReader reads the resources of an MCP server in-process through an
+// in-memory client session, so every resource, template and middleware is
+// served exactly as it is to clients. Task resources are read from storage,
+// because only tasks that existed at startup are registered with the server.
+type ResourceReader struct {
+	server *mcp.Server
+	tasks  *ResourceHandler
+
+	mu      sync.Mutex
+	session *mcp.ClientSession
+}
+
+// NewResourceReader creates a reader for the resources of server; tasks may be nil
+func NewResourceReader(server *mcp.Server, tasks *ResourceHandler) *ResourceReader {
+	return &ResourceReader{server: server, tasks: tasks}
+}
+
+// ReadResource reads the resource at uri
+func (r *ResourceReader) ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	if taskID, taskType, agentName, ok := parseTaskResourceURI(uri); ok && r.tasks != nil {
+		return r.tasks.createResourceHandler(taskID, taskType, agentName)(ctx, &mcp.ReadResourceRequest{
+			Params: &mcp.ReadResourceParams{URI: uri},
+		})
+	}
+
+	session, err := r.clientSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if errors.Is(err, mcp.ErrConnectionClosed) {
+		r.mu.Lock()
+		if r.session == session {
+			r.session = nil
+		}
+		r.mu.Unlock()
+	}
+	return result, err
+}
+
+// clientSession connects the in-memory client on first use
+func (r *ResourceReader) clientSession(ctx context.Context) (*mcp.ClientSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.session != nil {
+		return r.session, nil
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	// The sessions outlive the request that opened them
+	connectCtx := context.WithoutCancel(ctx)
+	if _, err := r.server.Connect(connectCtx, serverTransport, nil); err != nil {
+		return nil, fmt.Errorf("failed to connect resource reader: %w", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "hyper-resource-reader", Version: "1.0.0"}, nil)
+	session, err := client.Connect(connectCtx, clientTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect resource reader: %w", err)
+	}
+	r.session = session
+	return session, nil
+}
+
+// parseTaskResourceURI parses hyperion://task/human/{id} and
+// hyperion://task/agent/{agent}/{id}; the /code and /activity resources of
+// agent tasks are not task resources
+func parseTaskResourceURI(uri string) (taskID, taskType, agentName string, ok bool) {
+	if id, found := strings.CutPrefix(uri, humanTaskURIPrefix); found && id != "" && !strings.ContainsAny(id, "/?") {
+		return id, "human", "", true
+	}
+	rest, found := strings.CutPrefix(uri, agentTaskURIPrefix)
+	if !found || strings.Contains(rest, "?") {
+		return "", "", "", false
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || parts[1] == "code" || parts[1] == "activity" {
+		return "", "", "", false
+	}
+	return parts[1], "agent", parts[0], true
+}
+
+// SetResourceReader enables the coordinator_read_resources tool
+func (h *ToolHandler) SetResourceReader(reader BatchResourceReader) {
+	h.resourceReader = reader
+}
+
+// batchResource is one resource read by coordinator_read_resources
+type batchResource struct {
+	URI      string `json:"uri"`
+	MIMEType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     []byte `json:"blob,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// registerReadResources registers the coordinator_read_resources tool
+func (h *ToolHandler) registerReadResources(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_read_resources",
+		Description: fmt.Sprintf("Read several MCP resources in one call instead of one resources/read per URI, e.g. a human task, its agent tasks and knowledge resources when assembling context. With taskId, the human task and all of its agent tasks are added. Resources are read in parallel (at most %d per call); one that fails reports an error without failing the others.", maxBatchResources),
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"uris": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: "Resource URIs to read, e.g. hyperion://task/agent/{id}/activity or hyperion://knowledge/collections",
+				},
+				"taskId": {
+					Type:        "string",
+					Description: "Optional: human task ID whose task and agent task resources are added",
+				},
+			},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleReadResources(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleReadResources handles the coordinator_read_resources tool call
+func (h *ToolHandler) handleReadResources(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.resourceReader == nil {
+		return createErrorResult("batch resource reads are unavailable: no resource reader configured"), nil, nil
+	}
+
+	var uris []string
+	if raw, ok := args["uris"]; ok {
+		parsed, err := parseStringList(raw, "uris")
+		if err != nil {
+			return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+		}
+		uris = parsed
+	}
+
+	if taskID := getStringField(args, "taskId", ""); taskID != "" {
+		task, err := h.taskStorage.GetHumanTask(taskID)
+		if err != nil {
+			return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("human task not found: %s", taskID)), nil, nil
+		}
+		uris = append(uris, humanTaskURIPrefix+task.ID)
+		for _, agentTask := range h.taskStorage.ListAllAgentTasks() {
+			if agentTask.HumanTaskID == task.ID {
+				uris = append(uris, agentTaskURIPrefix+agentTask.AgentName+"/"+agentTask.ID)
+			}
+		}
+	}
+
+	// Drop duplicates, keeping the requested order
+	seen := make(map[string]bool)
+	unique := uris[:0]
+	for _, uri := range uris {
+		if uri = strings.TrimSpace(uri); uri != "" && !seen[uri] {
+			seen[uri] = true
+			unique = append(unique, uri)
+		}
+	}
+	uris = unique
+
+	if len(uris) == 0 {
+		return createCodedErrorResult(errcode.Validation, "uris or taskId is required"), nil, nil
+	}
+	if len(uris) > maxBatchResources {
+		return createCodedErrorResult(errcode.Validation, fmt.Sprintf("%d resources requested, above the limit of %d per call", len(uris), maxBatchResources)), nil, nil
+	}
+
+	results := make([][]batchResource, len(uris))
+	var wg sync.WaitGroup
+	for i, uri := range uris {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.readBatchResource(ctx, uri)
+		}()
+	}
+	wg.Wait()
+
+	resources := make([]batchResource, 0, len(uris))
+	failed := 0
+	for _, read := range results {
+		for _, resource := range read {
+			if resource.Error != "" {
+				failed++
+			}
+			resources = append(resources, resource)
+		}
+	}
+
+	response := map[string]interface{}{
+		"resources": resources,
+		"count":     len(resources),
+		"failed":    failed,
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// readBatchResource reads one URI into its contents, or an error entry
+func (h *ToolHandler) readBatchResource(ctx context.Context, uri string) []batchResource {
+	result, err := h.resourceReader.ReadResource(ctx, uri)
+	if err != nil {
+		return []batchResource{{URI: uri, Error: err.Error()}}
+	}
+	if result == nil || len(result.Contents) == 0 {
+		return []batchResource{{URI: uri, Error: "resource has no contents"}}
+	}
+
+	contents := make([]batchResource, 0, len(result.Contents))
+	for _, content := range result.Contents {
+		resource := batchResource{URI: content.URI, MIMEType: content.MIMEType, Text: content.Text, Blob: content.Blob}
+		if resource.URI == "" {
+			resource.URI = uri
+		}
+		contents = append(contents, resource)
+	}
+	return contents
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchTaskStorage serves tasks by ID
+type batchTaskStorage struct {
+	storage.TaskStorage
+	human []*storage.HumanTask
+	agent []*storage.AgentTask
+}
+
+func (s *batchTaskStorage) GetHumanTask(taskID string) (*storage.HumanTask, error) {
+	for _, task := range s.human {
+		if task.ID == taskID {
+			return task, nil
+		}
+	}
+	return nil, fmt.Errorf("task not found: %s", taskID)
+}
+
+func (s *batchTaskStorage) GetAgentTask(taskID string) (*storage.AgentTask, error) {
+	for _, task := range s.agent {
+		if task.ID == taskID {
+			return task, nil
+		}
+	}
+	return nil, fmt.Errorf("task not found: %s", taskID)
+}
+
+func (s *batchTaskStorage) ListAllAgentTasks() []*storage.AgentTask { return s.agent }
+
+func newBatchReadHandler(t *testing.T) *ToolHandler {
+	tasks := &batchTaskStorage{
+		human: []*storage.HumanTask{{ID: "h-1", Prompt: "Add webhooks"}},
+		agent: []*storage.AgentTask{
+			{ID: "a-1", HumanTaskID: "h-1", AgentName: "go-dev", Role: "API"},
+			{ID: "a-2", HumanTaskID: "h-1", AgentName: "ui-dev", Role: "UI"},
+			{ID: "a-3", HumanTaskID: "h-2", AgentName: "go-dev", Role: "Other"},
+		},
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	server.AddResource(&mcp.Resource{URI: "hyperion://docs/standards", Name: "standards"},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+				{URI: req.Params.URI, MIMEType: "text/markdown", Text: "# Standards"},
+			}}, nil
+		})
+
+	handler := NewToolHandler(tasks, nil, nil)
+	handler.SetResourceReader(NewResourceReader(server, NewResourceHandler(tasks, nil)))
+	return handler
+}
+
+func TestHandleReadResources(t *testing.T) {
+	handler := newBatchReadHandler(t)
+
+	result, payload, err := handler.handleReadResources(context.Background(), map[string]interface{}{
+		"uris":   []interface{}{"hyperion://docs/standards", "hyperion://task/human/h-1", "hyperion://docs/missing"},
+		"taskId": "h-1",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	response := payload.(map[string]interface{})
+	resources := response["resources"].([]batchResource)
+	var uris []string
+	for _, resource := range resources {
+		uris = append(uris, resource.URI)
+	}
+	assert.Equal(t, []string{
+		"hyperion://docs/standards",
+		"hyperion://task/human/h-1",
+		"hyperion://docs/missing",
+		"hyperion://task/agent/go-dev/a-1",
+		"hyperion://task/agent/ui-dev/a-2",
+	}, uris, "requested order, with the task's agent tasks added once")
+
+	assert.Equal(t, "# Standards", resources[0].Text)
+	assert.Contains(t, resources[1].Text, "Add webhooks")
+	assert.NotEmpty(t, resources[2].Error)
+	assert.Contains(t, resources[4].Text, "ui-dev")
+	assert.Equal(t, 1, response["failed"])
+}
+
+func TestHandleReadResources_Validation(t *testing.T) {
+	handler := newBatchReadHandler(t)
+
+	result, _, err := handler.handleReadResources(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, _, err = handler.handleReadResources(context.Background(), map[string]interface{}{"taskId": "h-404"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	uris := make([]interface{}, maxBatchResources+1)
+	for i := range uris {
+		uris[i] = fmt.Sprintf("hyperion://docs/%d", i)
+	}
+	result, _, err = handler.handleReadResources(context.Background(), map[string]interface{}{"uris": uris})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestParseTaskResourceURI(t *testing.T) {
+	id, taskType, agent, ok := parseTaskResourceURI("hyperion://task/agent/go-dev/a-1")
+	require.True(t, ok)
+	assert.Equal(t, []string{"a-1", "agent", "go-dev"}, []string{id, taskType, agent})
+
+	id, taskType, _, ok = parseTaskResourceURI("hyperion://task/human/h-1")
+	require.True(t, ok)
+	assert.Equal(t, []string{"h-1", "human"}, []string{id, taskType})
+
+	for _, uri := range []string{"hyperion://task/agent/a-1/code", "hyperion://task/agent/a-1/activity?limit=5", "hyperion://docs/standards"} {
+		_, _, _, ok = parseTaskResourceURI(uri)
+		assert.False(t, ok, uri)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resourcePageQuery is the URI template suffix accepted by list resources
+const resourcePageQuery = "{?cursor,limit}"
+
+// maxResourcePageSize caps the limit of one page of a list resource
+const maxResourcePageSize = 500
+
+// resourcePage is the slice of a list resource requested by a read: the
+// items from offset on, at most limit of them (0 returns all)
+type resourcePage struct {
+	offset int
+	limit  int
+}
+
+// addPaginatedResource registers a list resource under its plain URI and as a
+// template accepting ?cursor=...&limit=..., so clients can read it in pages
+// and follow nextCursor until it is absent
+func addPaginatedResource(server *mcp.Server, resource *mcp.Resource, handler mcp.ResourceHandler) {
+	server.AddResource(resource, handler)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: resource.URI + resourcePageQuery,
+		Name:        resource.Name + " (paginated)",
+		Description: resource.Description + ". Pass limit (max 500) and the returned nextCursor to read it page by page",
+		MIMEType:    resource.MIMEType,
+	}, handler)
+}
+
+// parseResourcePage reads the cursor and limit query parameters of a
+// resource URI. Without them the whole list is returned.
+func parseResourcePage(uri string) (resourcePage, error) {
+	var page resourcePage
+	i := strings.Index(uri, "?")
+	if i < 0 {
+		return page, nil
+	}
+	query, err := url.ParseQuery(uri[i+1:])
+	if err != nil {
+		return page, fmt.Errorf("invalid resource query: %w", err)
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return page, fmt.Errorf("limit must be a positive integer, got %q", raw)
+		}
+		if limit > maxResourcePageSize {
+			limit = maxResourcePageSize
+		}
+		page.limit = limit
+	}
+	if raw := query.Get("cursor"); raw != "" {
+		offset, err := decodeResourceCursor(raw)
+		if err != nil {
+			return page, err
+		}
+		page.offset = offset
+	}
+	return page, nil
+}
+
+// paginate returns the page of items and the pagination fields of the
+// response: total, offset, limit and, when more items follow, nextCursor
+func paginate[T any](items []T, page resourcePage) ([]T, map[string]interface{}) {
+	total := len(items)
+	start := page.offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if page.limit > 0 && start+page.limit < total {
+		end = start + page.limit
+	}
+
+	info := map[string]interface{}{
+		"total":  total,
+		"offset": start,
+		"count":  end - start,
+	}
+	if page.limit > 0 {
+		info["limit"] = page.limit
+	}
+	if end < total {
+		info["nextCursor"] = encodeResourceCursor(end)
+	}
+	return items[start:end], info
+}
+
+// encodeResourceCursor makes an opaque cursor for the item at offset
+func encodeResourceCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// decodeResourceCursor returns the offset a cursor points at
+func decodeResourceCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if value, ok := strings.CutPrefix(string(raw), "offset:"); ok {
+			if offset, err := strconv.Atoi(value); err == nil && offset >= 0 {
+				return offset, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q: pass the nextCursor of a previous page", cursor)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseResourcePage(t *testing.T) {
+	page, err := parseResourcePage("hyperion://workflow/task-queue")
+	if err != nil || page != (resourcePage{}) {
+		t.Errorf("plain URI = %+v, %v; want whole list", page, err)
+	}
+
+	page, err = parseResourcePage("hyperion://workflow/task-queue?limit=5000&cursor=" + encodeResourceCursor(20))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (resourcePage{offset: 20, limit: maxResourcePageSize}); page != want {
+		t.Errorf("page = %+v, want %+v", page, want)
+	}
+
+	for _, uri := range []string{
+		"hyperion://workflow/task-queue?limit=0",
+		"hyperion://workflow/task-queue?limit=ten",
+		"hyperion://workflow/task-queue?cursor=not-a-cursor",
+	} {
+		if _, err := parseResourcePage(uri); err == nil {
+			t.Errorf("parseResourcePage(%q) should fail", uri)
+		}
+	}
+}
+
+func TestPaginateFollowsCursors(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	var seen []int
+	uri := "hyperion://workflow/task-queue?limit=2"
+	for pages := 0; pages < 5; pages++ {
+		page, err := parseResourcePage(uri)
+		if err != nil {
+			t.Fatalf("parseResourcePage(%q): %v", uri, err)
+		}
+		got, info := paginate(items, page)
+		seen = append(seen, got...)
+		if info["total"] != 5 {
+			t.Errorf("total = %v, want 5", info["total"])
+		}
+		next, ok := info["nextCursor"].(string)
+		if !ok {
+			break
+		}
+		uri = "hyperion://workflow/task-queue?limit=2&cursor=" + next
+	}
+	if !reflect.DeepEqual(seen, items) {
+		t.Errorf("pages returned %v, want %v", seen, items)
+	}
+
+	all, info := paginate(items, resourcePage{})
+	if len(all) != 5 || info["nextCursor"] != nil {
+		t.Errorf("unpaginated read = %v %v, want every item and no cursor", all, info)
+	}
+
+	past, info := paginate(items, resourcePage{offset: 10, limit: 2})
+	if len(past) != 0 || info["count"] != 0 {
+		t.Errorf("page past the end = %v %v, want empty", past, info)
+	}
+}
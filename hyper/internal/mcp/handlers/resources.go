@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"hyper/internal/mcp/storage"
 
@@ -52,9 +53,63 @@ func (h *ResourceHandler) RegisterResourceHandlers(server *mcp.Server) error {
 		server.AddResource(resource, h.createResourceHandler(task.ID, "agent", task.AgentName))
 	}
 
+	// Activity logs are served for any agent task, including ones created after startup
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: agentTaskActivityURIPrefix + "{id}" + agentTaskActivityURISuffix + resourcePageQuery,
+		Name:        "Agent Task Activity",
+		Description: "Chronological log of status changes, TODO updates and prompt note edits for an agent task. Long logs can be read in pages with ?limit= and the returned nextCursor",
+		MIMEType:    "application/json",
+	}, h.handleAgentTaskActivity)
+
 	return nil
 }
 
+const (
+	agentTaskActivityURIPrefix = "hyperion://task/agent/"
+	agentTaskActivityURISuffix = "/activity"
+)
+
+// handleAgentTaskActivity serves hyperion://task/agent/{id}/activity
+func (h *ResourceHandler) handleAgentTaskActivity(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	page, err := parseResourcePage(uri)
+	if err != nil {
+		return nil, err
+	}
+	path, _, _ := strings.Cut(uri, "?")
+	taskID := strings.TrimSuffix(strings.TrimPrefix(path, agentTaskActivityURIPrefix), agentTaskActivityURISuffix)
+	if taskID == "" || strings.Contains(taskID, "/") {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	activity, err := h.taskStorage.GetAgentTaskActivity(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve agent task activity: %w", err)
+	}
+
+	activityPage, pagination := paginate(activity, page)
+
+	jsonData, err := json.MarshalIndent(map[string]interface{}{
+		"agentTaskId": taskID,
+		"activity":    activityPage,
+		"count":       len(activity),
+		"pagination":  pagination,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task activity: %w", err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(jsonData),
+			},
+		},
+	}, nil
+}
+
 // createResourceHandler creates a handler function for a specific task resource
 func (h *ResourceHandler) createResourceHandler(taskID, taskType, agentName string) mcp.ResourceHandler {
 	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/taskgraph"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	runKnowledgeLimit    = 100 // Knowledge entries listed in a run report
+	runKnowledgeTextLen  = 300 // Characters of each entry's text shown
+	runExportFormatMD    = "markdown"
+	runExportFormatJSON  = "json"
+	runReportTimeLayout  = "2006-01-02 15:04 MST"
+	runReportTitleLength = 80
+)
+
+// runActivityTools name the tool call behind each activity log entry
+var runActivityTools = map[storage.ActivityAction]string{
+	storage.ActivityCreated:                "coordinator_create_agent_task",
+	storage.ActivityStatusChanged:          "coordinator_update_task_status",
+	storage.ActivityTodoStatusChanged:      "coordinator_update_todo_status",
+	storage.ActivityChecklistStatusChanged: "coordinator_update_todo_status",
+	storage.ActivityTodoTimeUpdated:        "coordinator_update_todo_status",
+	storage.ActivityPromptNotesAdded:       "coordinator_add_task_prompt_notes",
+	storage.ActivityPromptNotesUpdated:     "coordinator_update_task_prompt_notes",
+	storage.ActivityPromptNotesCleared:     "coordinator_clear_task_prompt_notes",
+	storage.ActivityTodoPromptNotesAdded:   "coordinator_add_todo_prompt_notes",
+	storage.ActivityTodoPromptNotesUpdated: "coordinator_update_todo_prompt_notes",
+	storage.ActivityTodoPromptNotesCleared: "coordinator_clear_todo_prompt_notes",
+}
+
+// runTranscript is everything recorded about one orchestrated run: a human
+// task and the agent tasks it was split into
+type runTranscript struct {
+	HumanTask    *storage.HumanTask        `json:"humanTask"`
+	Finished     bool                      `json:"finished"`
+	StartedAt    time.Time                 `json:"startedAt"`
+	EndedAt      *time.Time                `json:"endedAt,omitempty"` // Last task update, once finished
+	AgentTasks   []*storage.AgentTask      `json:"agentTasks"`
+	ToolCalls    []runToolCall             `json:"toolCalls"`
+	Knowledge    []*storage.KnowledgeEntry `json:"knowledge"`
+	FilesChanged []runFileChange           `json:"filesChanged"`
+	Graph        string                    `json:"graph"` // Mermaid flowchart of the plan
+	Warnings     []string                  `json:"warnings,omitempty"`
+}
+
+// runToolCall is an activity log entry attributed to the tool call that made it
+type runToolCall struct {
+	At          time.Time `json:"at"`
+	AgentTaskID string    `json:"agentTaskId"`
+	AgentName   string    `json:"agentName"`
+	Tool        string    `json:"tool"`
+	Change      string    `json:"change"`
+}
+
+// runFileChange is a file an agent declared or the watcher saw change
+type runFileChange struct {
+	Path       string   `json:"path"`
+	Agents     []string `json:"agents"`
+	Operations []string `json:"operations,omitempty"` // Observed by the watcher, e.g. modified
+}
+
+// registerExportRun registers the coordinator_export_run tool
+func (h *ToolHandler) registerExportRun(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_export_run",
+		Description: "Export the transcript of an orchestrated run as a markdown report to attach to PRs and retrospectives: the human task and its agent tasks with their TODOs, a plan diagram, every recorded tool call that changed a task (from the task activity logs), the knowledge written for the run, and the files changed. Best called once the run finishes; unfinished runs are exported as they stand.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"humanTaskId": {
+					Type:        "string",
+					Description: "Human task UUID of the run",
+				},
+				"format": {
+					Type:        "string",
+					Enum:        []interface{}{runExportFormatMD, runExportFormatJSON},
+					Description: "Optional: 'markdown' (default) or 'json'",
+				},
+			},
+			Required: []string{"humanTaskId"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleExportRun(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleExportRun handles the coordinator_export_run tool call
+func (h *ToolHandler) handleExportRun(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	humanTaskID := strings.TrimSpace(getStringField(args, "humanTaskId", ""))
+	if humanTaskID == "" {
+		return createCodedErrorResult(errcode.Validation, "humanTaskId parameter is required"), nil, nil
+	}
+	format := getStringField(args, "format", runExportFormatMD)
+	if format != runExportFormatMD && format != runExportFormatJSON {
+		return createCodedErrorResult(errcode.Validation, "format must be 'markdown' or 'json'"), nil, nil
+	}
+
+	human, err := h.taskStorage.GetHumanTask(humanTaskID)
+	if err != nil {
+		return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("human task not found: %s", humanTaskID)), nil, nil
+	}
+
+	run := h.buildRunTranscript(ctx, human)
+	if format == runExportFormatJSON {
+		response := map[string]interface{}{"run": run}
+		return structuredToolResult(response), response, nil
+	}
+	markdown := renderRunMarkdown(run)
+	response := map[string]interface{}{"run": run, "markdown": markdown}
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: markdown}},
+		StructuredContent: response,
+	}, response, nil
+}
+
+// buildRunTranscript collects a run's tasks, tool calls, knowledge and files.
+// Parts that fail to load are left out and reported as warnings.
+func (h *ToolHandler) buildRunTranscript(ctx context.Context, human *storage.HumanTask) *runTranscript {
+	run := &runTranscript{HumanTask: human, StartedAt: human.CreatedAt, AgentTasks: []*storage.AgentTask{}, ToolCalls: []runToolCall{}, Knowledge: []*storage.KnowledgeEntry{}}
+	for _, task := range h.taskStorage.ListAllAgentTasks() {
+		if task.HumanTaskID == human.ID {
+			run.AgentTasks = append(run.AgentTasks, task)
+		}
+	}
+	sort.SliceStable(run.AgentTasks, func(i, j int) bool { return run.AgentTasks[i].CreatedAt.Before(run.AgentTasks[j].CreatedAt) })
+
+	run.Finished = human.Status == storage.TaskStatusCompleted
+	if !run.Finished && len(run.AgentTasks) > 0 {
+		run.Finished = true
+		for _, task := range run.AgentTasks {
+			if task.Status != storage.TaskStatusCompleted {
+				run.Finished = false
+			}
+		}
+	}
+	ended := human.UpdatedAt
+	for _, task := range run.AgentTasks {
+		if task.UpdatedAt.After(ended) {
+			ended = task.UpdatedAt
+		}
+	}
+	if run.Finished {
+		run.EndedAt = &ended
+	}
+
+	taskIDs := []string{human.ID}
+	var agentNames []string
+	for _, task := range run.AgentTasks {
+		taskIDs = append(taskIDs, task.ID)
+		if task.AgentName != "" && !slices.Contains(agentNames, task.AgentName) {
+			agentNames = append(agentNames, task.AgentName)
+		}
+		activity, err := h.taskStorage.GetAgentTaskActivity(task.ID)
+		if err != nil {
+			run.Warnings = append(run.Warnings, fmt.Sprintf("activity of agent task %s: %s", task.ID, err.Error()))
+			continue
+		}
+		for _, entry := range activity {
+			run.ToolCalls = append(run.ToolCalls, runToolCallFrom(task, entry))
+		}
+	}
+	sort.SliceStable(run.ToolCalls, func(i, j int) bool { return run.ToolCalls[i].At.Before(run.ToolCalls[j].At) })
+
+	if finder, ok := h.knowledgeStorage.(storage.RunKnowledgeFinder); ok {
+		var until time.Time
+		if run.EndedAt != nil {
+			until = *run.EndedAt
+		}
+		entries, err := finder.KnowledgeForRun(ctx, taskIDs, agentNames, run.StartedAt, until, runKnowledgeLimit)
+		if err != nil {
+			run.Warnings = append(run.Warnings, "knowledge: "+err.Error())
+		} else {
+			run.Knowledge = entries
+		}
+	} else {
+		run.Warnings = append(run.Warnings, "knowledge: this knowledge storage cannot list a run's entries")
+	}
+
+	run.FilesChanged = runFilesChanged(run.AgentTasks)
+
+	if graph, err := taskgraph.Build([]*storage.HumanTask{human}, run.AgentTasks, taskgraph.Options{HumanTaskID: human.ID}); err == nil {
+		run.Graph = graph.Mermaid()
+	}
+	return run
+}
+
+// runToolCallFrom describes an activity log entry as a tool call
+func runToolCallFrom(task *storage.AgentTask, entry storage.TaskActivity) runToolCall {
+	tool := runActivityTools[entry.Action]
+	if tool == "" {
+		tool = string(entry.Action)
+	}
+
+	var change string
+	switch entry.Action {
+	case storage.ActivityCreated:
+		change = "created " + task.Role
+	case storage.ActivityTodoTimeUpdated:
+		change = "TODO " + runTodoName(task, entry.TodoID) + " time"
+		if entry.EstimatedMinutes != nil {
+			change += fmt.Sprintf(", estimated %d min", *entry.EstimatedMinutes)
+		}
+		if entry.ActualMinutes != nil {
+			change += fmt.Sprintf(", actual %d min", *entry.ActualMinutes)
+		}
+	default:
+		change = strings.ReplaceAll(string(entry.Action), "_", " ")
+		if entry.TodoID != "" {
+			change = "TODO " + runTodoName(task, entry.TodoID) + ": " + change
+		}
+		if entry.Status != "" {
+			change += ": "
+			if entry.PreviousStatus != "" {
+				change += entry.PreviousStatus + " → "
+			}
+			change += entry.Status
+		}
+	}
+	if entry.Notes != "" {
+		change += " — " + entry.Notes
+	}
+	return runToolCall{At: entry.At, AgentTaskID: task.ID, AgentName: task.AgentName, Tool: tool, Change: change}
+}
+
+// runTodoName returns a TODO's description, or its ID if it is gone
+func runTodoName(task *storage.AgentTask, todoID string) string {
+	for _, todo := range task.Todos {
+		if todo.ID == todoID {
+			return fmt.Sprintf("%q", todo.Description)
+		}
+	}
+	return todoID
+}
+
+// runFilesChanged merges the files agent tasks declared with the changes the
+// watcher recorded for them, sorted by path
+func runFilesChanged(tasks []*storage.AgentTask) []runFileChange {
+	byPath := map[string]*runFileChange{}
+	file := func(path string) *runFileChange {
+		if byPath[path] == nil {
+			byPath[path] = &runFileChange{Path: path, Agents: []string{}}
+		}
+		return byPath[path]
+	}
+	for _, task := range tasks {
+		for _, path := range task.FilesModified {
+			change := file(path)
+			if !slices.Contains(change.Agents, task.AgentName) {
+				change.Agents = append(change.Agents, task.AgentName)
+			}
+		}
+		for _, entry := range task.ChangeLog {
+			change := file(entry.Path)
+			if !slices.Contains(change.Agents, task.AgentName) {
+				change.Agents = append(change.Agents, task.AgentName)
+			}
+			if !slices.Contains(change.Operations, entry.Operation) {
+				change.Operations = append(change.Operations, entry.Operation)
+			}
+		}
+	}
+
+	files := make([]runFileChange, 0, len(byPath))
+	for _, change := range byPath {
+		files = append(files, *change)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// renderRunMarkdown renders a run transcript as a markdown report
+func renderRunMarkdown(run *runTranscript) string {
+	var b strings.Builder
+	human := run.HumanTask
+	fmt.Fprintf(&b, "# Run: %s\n\n", runTitle(human.Prompt))
+
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Human task | `%s` |\n", human.ID)
+	fmt.Fprintf(&b, "| Status | %s |\n", human.Status)
+	if human.Project != "" {
+		fmt.Fprintf(&b, "| Project | %s |\n", runCell(human.Project))
+	}
+	fmt.Fprintf(&b, "| Started | %s |\n", run.StartedAt.UTC().Format(runReportTimeLayout))
+	if run.EndedAt != nil {
+		fmt.Fprintf(&b, "| Finished | %s |\n", run.EndedAt.UTC().Format(runReportTimeLayout))
+		fmt.Fprintf(&b, "| Duration | %s |\n", run.EndedAt.Sub(run.StartedAt).Round(time.Minute))
+	} else {
+		b.WriteString("| Finished | not yet |\n")
+	}
+	completed, todos, todosDone := 0, 0, 0
+	for _, task := range run.AgentTasks {
+		if task.Status == storage.TaskStatusCompleted {
+			completed++
+		}
+		for _, todo := range task.Todos {
+			todos++
+			if todo.Status == storage.TodoStatusCompleted {
+				todosDone++
+			}
+		}
+	}
+	fmt.Fprintf(&b, "| Agent tasks | %d/%d completed |\n", completed, len(run.AgentTasks))
+	fmt.Fprintf(&b, "| TODOs | %d/%d completed |\n", todosDone, todos)
+	fmt.Fprintf(&b, "| Tool calls | %d |\n", len(run.ToolCalls))
+	fmt.Fprintf(&b, "| Knowledge written | %d |\n", len(run.Knowledge))
+	fmt.Fprintf(&b, "| Files changed | %d |\n", len(run.FilesChanged))
+
+	b.WriteString("\n## Prompt\n\n")
+	for _, line := range strings.Split(strings.TrimSpace(human.Prompt), "\n") {
+		b.WriteString("> " + line + "\n")
+	}
+
+	if run.Graph != "" {
+		b.WriteString("\n## Plan\n\n```mermaid\n" + run.Graph + "```\n")
+	}
+
+	b.WriteString("\n## Agent tasks\n")
+	if len(run.AgentTasks) == 0 {
+		b.WriteString("\nNo agent tasks.\n")
+	}
+	for _, task := range run.AgentTasks {
+		fmt.Fprintf(&b, "\n### %s: %s (%s)\n\n", task.AgentName, task.Role, task.Status)
+		fmt.Fprintf(&b, "Agent task `%s`\n", task.ID)
+		if task.ContextSummary != "" {
+			b.WriteString("\n" + strings.TrimSpace(task.ContextSummary) + "\n")
+		}
+		if len(task.Todos) > 0 {
+			b.WriteString("\n")
+		}
+		for _, todo := range task.Todos {
+			fmt.Fprintf(&b, "- [%s] %s", runCheck(todo.Status), todo.Description)
+			if todo.ActualMinutes > 0 {
+				fmt.Fprintf(&b, " (%d min)", todo.ActualMinutes)
+			}
+			b.WriteString("\n")
+			for _, item := range todo.Checklist {
+				fmt.Fprintf(&b, "  - [%s] %s\n", runCheck(item.Status), item.Description)
+			}
+		}
+		if task.Notes != "" {
+			b.WriteString("\nNotes: " + strings.TrimSpace(task.Notes) + "\n")
+		}
+	}
+
+	b.WriteString("\n## Tool calls\n\n")
+	if len(run.ToolCalls) == 0 {
+		b.WriteString("No tool calls recorded.\n")
+	} else {
+		b.WriteString("| Time | Agent | Tool | Change |\n|---|---|---|---|\n")
+		for _, call := range run.ToolCalls {
+			fmt.Fprintf(&b, "| %s | %s | `%s` | %s |\n", call.At.UTC().Format(runReportTimeLayout), runCell(call.AgentName), call.Tool, runCell(call.Change))
+		}
+	}
+
+	b.WriteString("\n## Knowledge written\n\n")
+	if len(run.Knowledge) == 0 {
+		b.WriteString("No knowledge written.\n")
+	}
+	for _, entry := range run.Knowledge {
+		fmt.Fprintf(&b, "- **%s** (%s", entry.Collection, entry.CreatedAt.UTC().Format(runReportTimeLayout))
+		if agent, ok := entry.Metadata["agentName"].(string); ok && agent != "" {
+			b.WriteString(", " + agent)
+		}
+		fmt.Fprintf(&b, "): %s\n", strings.Join(strings.Fields(truncateText(entry.Text, runKnowledgeTextLen)), " "))
+	}
+
+	b.WriteString("\n## Files changed\n\n")
+	if len(run.FilesChanged) == 0 {
+		b.WriteString("No files changed.\n")
+	}
+	for _, file := range run.FilesChanged {
+		fmt.Fprintf(&b, "- `%s` (%s", file.Path, strings.Join(file.Agents, ", "))
+		if len(file.Operations) > 0 {
+			b.WriteString("; " + strings.Join(file.Operations, ", "))
+		}
+		b.WriteString(")\n")
+	}
+
+	if len(run.Warnings) > 0 {
+		b.WriteString("\n## Incomplete\n\n")
+		for _, warning := range run.Warnings {
+			b.WriteString("- " + warning + "\n")
+		}
+	}
+	return b.String()
+}
+
+// runTitle is the first line of the prompt, shortened
+func runTitle(prompt string) string {
+	title := strings.TrimSpace(strings.SplitN(strings.TrimSpace(prompt), "\n", 2)[0])
+	if len([]rune(title)) > runReportTitleLength {
+		title = string([]rune(title)[:runReportTitleLength-1]) + "…"
+	}
+	return title
+}
+
+// runCell makes text safe for a markdown table cell
+func runCell(text string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(text), " "), "|", `\|`)
+}
+
+func runCheck(status storage.TodoStatus) string {
+	if status == storage.TodoStatusCompleted {
+		return "x"
+	}
+	return " "
+}
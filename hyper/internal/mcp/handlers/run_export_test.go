@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runTaskStorage serves one run's tasks and activity; other TaskStorage
+// methods are not called
+type runTaskStorage struct {
+	graphTaskStorage
+	activity map[string][]storage.TaskActivity
+}
+
+func (s *runTaskStorage) GetHumanTask(id string) (*storage.HumanTask, error) {
+	for _, task := range s.human {
+		if task.ID == id {
+			return task, nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func (s *runTaskStorage) GetAgentTaskActivity(id string) ([]storage.TaskActivity, error) {
+	return s.activity[id], nil
+}
+
+func TestHandleExportRun(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	h := &ToolHandler{taskStorage: &runTaskStorage{
+		graphTaskStorage: graphTaskStorage{
+			human: []*storage.HumanTask{{ID: "h-1", Prompt: "Add webhooks", Status: storage.TaskStatusCompleted, CreatedAt: start, UpdatedAt: start.Add(90 * time.Minute)}},
+			agent: []*storage.AgentTask{{
+				ID: "a-1", HumanTaskID: "h-1", AgentName: "go-dev", Role: "API", Status: storage.TaskStatusCompleted,
+				CreatedAt: start, UpdatedAt: start.Add(time.Hour),
+				Todos:         []storage.TodoItem{{ID: "t-1", Description: "Add handler", Status: storage.TodoStatusCompleted}},
+				FilesModified: []string{"api/webhooks.go"},
+			}},
+		},
+		activity: map[string][]storage.TaskActivity{"a-1": {
+			{At: start.Add(time.Minute), Action: storage.ActivityTodoStatusChanged, TodoID: "t-1", PreviousStatus: "pending", Status: "completed"},
+			{At: start, Action: storage.ActivityCreated, Status: "pending"},
+		}},
+	}}
+
+	result, _, err := h.handleExportRun(context.Background(), map[string]interface{}{"humanTaskId": "h-1"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "# Run: Add webhooks")
+	assert.Contains(t, text, "| Duration | 1h30m0s |")
+	assert.Contains(t, text, "- [x] Add handler")
+	assert.Contains(t, text, "`coordinator_update_todo_status` | TODO \"Add handler\": todo status changed: pending → completed |")
+	assert.Contains(t, text, "- `api/webhooks.go` (go-dev)")
+	assert.Less(t, strings.Index(text, "coordinator_create_agent_task"), strings.Index(text, "coordinator_update_todo_status"), "tool calls are in time order")
+
+	result, _, err = h.handleExportRun(context.Background(), map[string]interface{}{"humanTaskId": "h-9"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, _, err = h.handleExportRun(context.Background(), map[string]interface{}{"humanTaskId": "h-1", "format": "pdf"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
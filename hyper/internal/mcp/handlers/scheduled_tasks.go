@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/schedule"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetScheduledTasks enables the scheduled task tools
+func (h *ToolHandler) SetScheduledTasks(store *storage.ScheduledTaskStorage) {
+	h.scheduledTasks = store
+}
+
+// registerCreateScheduledTask registers the coordinator_create_scheduled_task tool
+func (h *ToolHandler) registerCreateScheduledTask(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_create_scheduled_task",
+		Description: "Create or replace a recurring agent task: whenever the cron expression matches, the coordinator creates an agent task from the template, such as a nightly re-index or a weekly knowledge cleanup. Without a humanTaskId, each run also creates a human task with the template's prompt. A run missed while the coordinator was down happens once when it is back.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Schedule name; saving an existing name replaces its schedule",
+				},
+				"cron": {
+					Type:        "string",
+					Description: "Five-field cron expression (minute hour day-of-month month day-of-week), e.g. \"0 2 * * *\" for 02:00 daily or \"30 9 * * mon\", or @hourly, @daily, @weekly, @monthly",
+				},
+				"timezone": {
+					Type:        "string",
+					Description: "Optional: IANA timezone the expression is read in, e.g. Europe/Paris (default: UTC)",
+				},
+				"template": {
+					Type:        "object",
+					Description: "Agent task created on each run",
+					Properties: map[string]*jsonschema.Schema{
+						"humanTaskId": {
+							Type:        "string",
+							Description: "Optional: human task every run's agent task belongs to",
+						},
+						"prompt": {
+							Type:        "string",
+							Description: "Optional: prompt of the human task created per run when there is no humanTaskId (default: \"Scheduled task: <name>\")",
+						},
+						"agentName": {
+							Type:        "string",
+							Description: "Agent the task is assigned to",
+						},
+						"role": {
+							Type:        "string",
+							Description: "What the agent does",
+						},
+						"todos": {
+							Type:        "array",
+							Description: "TODOs of every run: strings, or objects with description and optional filePath, functionName, contextHint, notes, estimatedMinutes and checklist",
+							Items: &jsonschema.Schema{
+								OneOf: []*jsonschema.Schema{
+									{Type: "string"},
+									{Type: "object", Required: []string{"description"}},
+								},
+							},
+						},
+						"contextSummary": {
+							Type:        "string",
+							Description: "Optional: context given to the agent",
+						},
+						"qdrantCollections": {
+							Type:        "array",
+							Description: "Optional: knowledge collections to consult",
+							Items:       &jsonschema.Schema{Type: "string"},
+						},
+						"priorWorkSummary": {
+							Type:        "string",
+							Description: "Optional: work the agent builds on",
+						},
+					},
+					Required: []string{"agentName", "role", "todos"},
+				},
+				"enabled": {
+					Type:        "boolean",
+					Description: "Optional: false keeps the schedule without running it (default: true)",
+				},
+			},
+			Required: []string{"name", "cron", "template"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleCreateScheduledTask(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerListScheduledTasks registers the coordinator_list_scheduled_tasks tool
+func (h *ToolHandler) registerListScheduledTasks(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_list_scheduled_tasks",
+		Description: "List scheduled tasks with their cron expression, template, next run, and the task and error of their last run.",
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, _, err := h.handleListScheduledTasks(ctx)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerDeleteScheduledTask registers the coordinator_delete_scheduled_task tool
+func (h *ToolHandler) registerDeleteScheduledTask(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_delete_scheduled_task",
+		Description: "Delete a scheduled task. Tasks its runs already created are kept.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Schedule to delete",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleDeleteScheduledTask(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleCreateScheduledTask handles the coordinator_create_scheduled_task tool call
+func (h *ToolHandler) handleCreateScheduledTask(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.scheduledTasks == nil {
+		return createErrorResult("scheduled tasks are unavailable: no schedule storage configured"), nil, nil
+	}
+
+	task := &storage.ScheduledTask{Enabled: true}
+	task.Name, _ = args["name"].(string)
+	task.Cron, _ = args["cron"].(string)
+	task.Timezone, _ = args["timezone"].(string)
+	if enabled, ok := args["enabled"].(bool); ok {
+		task.Enabled = enabled
+	}
+	template, err := parseScheduledTaskTemplate(args["template"])
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+	task.Template = *template
+
+	if err := storage.ValidateScheduledTask(task); err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+	if task.NextRunAt, err = schedule.NextRun(task, time.Now()); err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+	if task.Template.HumanTaskID != "" {
+		if _, err := h.taskStorage.GetHumanTask(task.Template.HumanTaskID); err != nil {
+			return createCodedErrorResult(errcode.NotFound, fmt.Sprintf("human task not found: %s", task.Template.HumanTaskID)), nil, nil
+		}
+	}
+	if err := h.scheduledTasks.SetScheduledTask(task); err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{"scheduledTask": task}
+	return structuredToolResult(response), response, nil
+}
+
+// parseScheduledTaskTemplate decodes the template argument. TODOs may be
+// plain strings, as for coordinator_create_agent_task.
+func parseScheduledTaskTemplate(raw interface{}) (*storage.ScheduledTaskTemplate, error) {
+	fields, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("template parameter is required and must be an object")
+	}
+	if todos, ok := fields["todos"].([]interface{}); ok {
+		normalized := make([]interface{}, len(todos))
+		for i, todo := range todos {
+			if description, ok := todo.(string); ok {
+				todo = map[string]interface{}{"description": description}
+			}
+			normalized[i] = todo
+		}
+		fields["todos"] = normalized
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	var template storage.ScheduledTaskTemplate
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	template.HumanTaskID = strings.TrimSpace(template.HumanTaskID)
+	return &template, nil
+}
+
+// handleListScheduledTasks handles the coordinator_list_scheduled_tasks tool call
+func (h *ToolHandler) handleListScheduledTasks(ctx context.Context) (*mcp.CallToolResult, interface{}, error) {
+	if h.scheduledTasks == nil {
+		return createErrorResult("scheduled tasks are unavailable: no schedule storage configured"), nil, nil
+	}
+
+	tasks, err := h.scheduledTasks.ListScheduledTasks()
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"scheduledTasks": tasks,
+		"count":          len(tasks),
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// handleDeleteScheduledTask handles the coordinator_delete_scheduled_task tool call
+func (h *ToolHandler) handleDeleteScheduledTask(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.scheduledTasks == nil {
+		return createErrorResult("scheduled tasks are unavailable: no schedule storage configured"), nil, nil
+	}
+
+	name, _ := args["name"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return createErrorResult("name parameter is required and must be a non-empty string"), nil, nil
+	}
+	if err := h.scheduledTasks.DeleteScheduledTask(name); err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	response := map[string]interface{}{"name": name, "deleted": true}
+	return structuredToolResult(response), response, nil
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledTaskToolsWithoutStorage(t *testing.T) {
+	h := &ToolHandler{}
+
+	result, _, err := h.handleCreateScheduledTask(context.Background(), map[string]interface{}{"name": "reindex", "cron": "@daily"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "no schedule storage configured")
+
+	result, _, err = h.handleListScheduledTasks(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestCreateScheduledTask_Validation(t *testing.T) {
+	// Validation fails before the storage is used
+	h := NewToolHandler(nil, nil, nil)
+	h.SetScheduledTasks(&storage.ScheduledTaskStorage{})
+
+	template := map[string]interface{}{"agentName": "indexer", "role": "Re-index", "todos": []interface{}{"Scan folders"}}
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{"invalid cron", map[string]interface{}{"name": "reindex", "cron": "0 25 * * *", "template": template}, "invalid hour"},
+		{"invalid timezone", map[string]interface{}{"name": "reindex", "cron": "@daily", "timezone": "Nowhere/City", "template": template}, "invalid timezone"},
+		{"missing template", map[string]interface{}{"name": "reindex", "cron": "@daily"}, "template parameter is required"},
+		{"missing todos", map[string]interface{}{"name": "reindex", "cron": "@daily", "template": map[string]interface{}{"agentName": "indexer", "role": "Re-index"}}, "template.todos"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := h.handleCreateScheduledTask(context.Background(), tt.args)
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Equal(t, errcode.Validation, errorCode(t, result))
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tt.want)
+		})
+	}
+}
+
+func TestParseScheduledTaskTemplate(t *testing.T) {
+	template, err := parseScheduledTaskTemplate(map[string]interface{}{
+		"humanTaskId": " h1 ",
+		"agentName":   "librarian",
+		"role":        "Clean up stale knowledge",
+		"todos": []interface{}{
+			"Find entries older than a year",
+			map[string]interface{}{"description": "Archive them", "checklist": []interface{}{"runbooks", "adrs"}},
+		},
+		"qdrantCollections": []interface{}{"team-knowledge"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "h1", template.HumanTaskID)
+	assert.Equal(t, []storage.TodoItemInput{
+		{Description: "Find entries older than a year"},
+		{Description: "Archive them", Checklist: []string{"runbooks", "adrs"}},
+	}, template.Todos)
+	assert.Equal(t, []string{"team-knowledge"}, template.QdrantCollections)
+}
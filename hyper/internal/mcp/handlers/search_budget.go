@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// maxSearchTimeout caps the timeoutMs argument accepted by search tools
+const maxSearchTimeout = 60 * time.Second
+
+// searchBudget tracks the time left for a time-boxed search.
+// A zero-value budget never expires.
+type searchBudget struct {
+	deadline time.Time
+	started  time.Time
+}
+
+// newSearchBudget reads the optional timeoutMs argument
+func newSearchBudget(args map[string]interface{}) (searchBudget, error) {
+	budget := searchBudget{started: time.Now()}
+
+	raw, ok := args["timeoutMs"]
+	if !ok || raw == nil {
+		return budget, nil
+	}
+
+	ms, ok := raw.(float64)
+	if !ok || ms <= 0 {
+		return budget, fmt.Errorf("timeoutMs must be a positive number")
+	}
+
+	timeout := time.Duration(ms) * time.Millisecond
+	if timeout > maxSearchTimeout {
+		timeout = maxSearchTimeout
+	}
+	budget.deadline = budget.started.Add(timeout)
+	return budget, nil
+}
+
+// limited reports whether the caller set a time budget
+func (b searchBudget) limited() bool {
+	return !b.deadline.IsZero()
+}
+
+// expired reports whether the budget has been used up
+func (b searchBudget) expired() bool {
+	return b.limited() && !time.Now().Before(b.deadline)
+}
+
+// elapsedMs returns the time spent since the search started
+func (b searchBudget) elapsedMs() int64 {
+	return time.Since(b.started).Milliseconds()
+}
+
+// timer returns a channel that fires when the budget expires, or nil when unlimited
+func (b searchBudget) timer() <-chan time.Time {
+	if !b.limited() {
+		return nil
+	}
+	return time.After(time.Until(b.deadline))
+}
+
+// context returns ctx bounded by the budget's deadline, so storage and vector
+// operations given it stop once the budget is used up
+func (b searchBudget) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if !b.limited() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, b.deadline)
+}
+
+// runWithinBudget runs fn and waits for it until the budget expires or ctx is done.
+// fn should use a context from budget.context so the operation stops too; a
+// result arriving after the deadline is discarded. completed is false when the
+// budget ran out first, including when fn failed because of it.
+func runWithinBudget[T any](ctx context.Context, budget searchBudget, fn func() (T, error)) (result T, completed bool, err error) {
+	if !budget.limited() {
+		result, err = fn()
+		return result, true, err
+	}
+
+	type outcome struct {
+		result T
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		r, e := fn()
+		done <- outcome{result: r, err: e}
+	}()
+
+	select {
+	case out := <-done:
+		if out.err != nil && (budget.expired() || ctx.Err() != nil) {
+			return result, false, nil
+		}
+		return out.result, true, out.err
+	case <-budget.timer():
+		return result, false, nil
+	case <-ctx.Done():
+		return result, false, nil
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewSearchBudget(t *testing.T) {
+	budget, err := newSearchBudget(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if budget.limited() || budget.expired() {
+		t.Error("budget without timeoutMs should be unlimited")
+	}
+
+	budget, err = newSearchBudget(map[string]interface{}{"timeoutMs": float64(3600000)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := time.Until(budget.deadline); got > maxSearchTimeout {
+		t.Errorf("timeout not capped: %v", got)
+	}
+
+	if _, err := newSearchBudget(map[string]interface{}{"timeoutMs": float64(-5)}); err == nil {
+		t.Error("expected error for negative timeoutMs")
+	}
+	if _, err := newSearchBudget(map[string]interface{}{"timeoutMs": "100"}); err == nil {
+		t.Error("expected error for non-numeric timeoutMs")
+	}
+}
+
+func TestRunWithinBudget(t *testing.T) {
+	budget, _ := newSearchBudget(map[string]interface{}{"timeoutMs": float64(20)})
+
+	result, completed, err := runWithinBudget(context.Background(), budget, func() (int, error) {
+		return 42, nil
+	})
+	if err != nil || !completed || result != 42 {
+		t.Errorf("fast operation: got (%d, %v, %v), want (42, true, nil)", result, completed, err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	result, completed, err = runWithinBudget(context.Background(), budget, func() (int, error) {
+		<-release
+		return 7, nil
+	})
+	if err != nil || completed || result != 0 {
+		t.Errorf("slow operation: got (%d, %v, %v), want (0, false, nil)", result, completed, err)
+	}
+	if !budget.expired() {
+		t.Error("budget should be expired after waiting for a slow operation")
+	}
+}
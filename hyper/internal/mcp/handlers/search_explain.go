@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// noReranker describes the ranking applied after vector similarity; no
+// re-ranking model is used
+const noReranker = "none: hits are ordered by similarity × folder weight"
+
+// knowledgeExplainer is implemented by Qdrant clients that can explain how a
+// knowledge point scores for a query
+type knowledgeExplainer interface {
+	ExplainKnowledgePoint(ctx context.Context, collectionName, query, language, id string, limit int) (*storage.PointExplanation, error)
+}
+
+// searchVerdict lists why a search returning limit hits leaves out the
+// explained point; empty when it is returned
+func searchVerdict(explanation *storage.PointExplanation, limit int) []string {
+	switch {
+	case !explanation.Found:
+		return []string{"the point is not stored in the vector index: re-index it"}
+	case !explanation.PassesFilter:
+		return []string{fmt.Sprintf("excluded by the search filter %v", explanation.Filter)}
+	case explanation.Rank == 0:
+		return []string{fmt.Sprintf("ranked below the top %d hits (similarity %.4f)", explanation.RankDepth, explanation.Similarity)}
+	case explanation.Rank > limit:
+		return []string{fmt.Sprintf("ranked %d, below the top %d: similarity %.4f is under the cutoff %.4f", explanation.Rank, limit, explanation.Similarity, explanation.CutoffScore)}
+	}
+	return []string{}
+}
+
+// codeSearchVerdict adds the reasons the folder routing and weights of a
+// code search leave out a chunk to searchVerdict's
+func codeSearchVerdict(explanation *storage.PointExplanation, limit int, folderPath, filePath string, profile *storage.SearchProfile, chunkFolder string) []string {
+	reasons := []string{}
+	if folderPath != "" && !storage.FolderCovers(folderPath, filePath) {
+		reasons = append(reasons, fmt.Sprintf("outside the searched folder %s", folderPath))
+	}
+	if !profile.Allows(chunkFolder) {
+		reasons = append(reasons, fmt.Sprintf("folder %s is not in the allow-list of search profile '%s'", chunkFolder, profile.Name))
+	} else if profile.WeightFor(chunkFolder) <= 0 {
+		reasons = append(reasons, fmt.Sprintf("folder %s has weight 0 in search profile '%s'", chunkFolder, profile.Name))
+	}
+	return append(reasons, searchVerdict(explanation, limit)...)
+}
+
+// registerExplain registers the code_index_explain tool
+func (h *CodeToolsHandler) registerExplain(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "code_index_explain",
+		Description: "Explain why a file does or does not appear in code_index_search results for a query: the chunk's embedding similarity, the filters and search profile applied, its rank and the cutoff score, the folder weight applied after ranking, and the chunk's stored metadata. Takes the same search arguments as code_index_search.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"query": {
+					Type:        "string",
+					Description: "The search query to explain",
+				},
+				"filePath": {
+					Type:        "string",
+					Description: "Absolute path of the indexed file expected in the results",
+				},
+				"chunkNum": {
+					Type:        "number",
+					Description: "Optional: chunk of the file to explain (default: the chunk most similar to the query)",
+				},
+				"limit": {
+					Type:        "number",
+					Description: "Optional: the search's result limit (default: 10, max: 50)",
+				},
+				"folderPath": {
+					Type:        "string",
+					Description: "Optional: the search's folderPath",
+				},
+				"profile": {
+					Type:        "string",
+					Description: "Optional: the search's profile (default: 'default')",
+				},
+				"folders": {
+					Type:        "array",
+					Description: "Optional: the search's folder allow-list",
+					Items:       &jsonschema.Schema{Type: "string"},
+				},
+				"folderWeights": {
+					Type:        "object",
+					Description: "Optional: the search's per-folder weights",
+				},
+				"commentLanguage": {
+					Type:        "string",
+					Description: "Optional: the search's commentLanguage filter",
+				},
+				"vector": {
+					Type:        "string",
+					Description: "Optional: the search's vector mode (code, summary or fused; default fused)",
+					Enum:        []interface{}{storage.VectorModeCode, storage.VectorModeSummary, storage.VectorModeFused},
+				},
+			},
+			Required: []string{"query", "filePath"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		return h.handleExplain(ctx, args)
+	})
+
+	return nil
+}
+
+// handleExplain handles the code_index_explain tool
+func (h *CodeToolsHandler) handleExplain(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	query, _ := args["query"].(string)
+	if query == "" {
+		return createCodeIndexErrorResult("query is required and must be a string"), nil
+	}
+	filePath, _ := args["filePath"].(string)
+	if filePath == "" {
+		return createCodeIndexErrorResult("filePath is required and must be a string"), nil
+	}
+
+	limit := 10
+	if l, ok := args["limit"].(float64); ok {
+		limit = int(l)
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	if limit < 1 {
+		limit = 1
+	}
+
+	commentLanguage, err := parseLanguageArg(args, "commentLanguage")
+	if err != nil {
+		return createCodeIndexErrorResult(err.Error()), nil
+	}
+	filter := commentLanguageFilter(commentLanguage)
+	folderPath, _ := args["folderPath"].(string)
+
+	vectorMode, err := storage.ParseVectorMode(getStringField(args, "vector", ""))
+	if err != nil {
+		return createCodeIndexErrorResult(err.Error()), nil
+	}
+
+	profileName := storage.DefaultSearchProfile
+	if name, ok := args["profile"].(string); ok && name != "" {
+		profileName = name
+	}
+	storedProfile, err := h.codeIndexStorage.GetSearchProfile(profileName)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to load search profile: %s", err.Error())), nil
+	}
+	profile, err := searchProfileOverrides(storedProfile, profileName, args)
+	if err != nil {
+		return createCodeIndexErrorResult(err.Error()), nil
+	}
+
+	file, err := h.codeIndexStorage.GetFileByPath(filePath)
+	if err != nil {
+		return createCodeIndexErrorResult(err.Error()), nil
+	}
+	if file == nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("'%s' is not indexed: scan its folder with code_index_scan", filePath)), nil
+	}
+	chunks, err := h.codeIndexStorage.GetChunksByFileID(file.ID)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to load chunks: %s", err.Error())), nil
+	}
+	if len(chunks) == 0 {
+		return createCodeIndexErrorResult(fmt.Sprintf("'%s' has no indexed chunks: re-scan its folder", filePath)), nil
+	}
+
+	// The file's chunks live in the collection of the most specific folder covering it
+	mappings, err := h.codeIndexStorage.ListPathMappings()
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to lookup collection mapping: %s", err.Error())), nil
+	}
+	targets := resolveSearchTargets(mappings, filePath, profile)
+	if len(targets) == 0 {
+		return createCodeIndexErrorResult(fmt.Sprintf("no code index collection covers '%s'", filePath)), nil
+	}
+	target := targets[0]
+
+	queryEmbedding, err := embeddings.CreateEmbeddingContext(ctx, h.embeddingClient, query)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("failed to create query embedding: %s", err.Error())), nil
+	}
+
+	// Score every chunk of the file, to pick the best one when none is named
+	vectorIDs := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.VectorID != "" {
+			vectorIDs = append(vectorIDs, chunk.VectorID)
+		}
+	}
+	similarities := make(map[string]float32, len(vectorIDs))
+	if len(vectorIDs) > 0 {
+		byFile := map[string]interface{}{"must": []map[string]interface{}{{"has_id": vectorIDs}}}
+		resp, err := h.qdrantClient.SearchCodeIndexModeContext(ctx, target.Collection, vectorMode, queryEmbedding, len(vectorIDs), byFile)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to search in collection '%s': %s", target.Collection, err.Error())), nil
+		}
+		for _, hit := range resp.Result {
+			similarities[hit.ID] = hit.Score
+		}
+	}
+
+	var chunk *storage.FileChunk
+	if n, ok := args["chunkNum"].(float64); ok {
+		for _, candidate := range chunks {
+			if candidate.ChunkNum == int(n) {
+				chunk = candidate
+			}
+		}
+		if chunk == nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("'%s' has no chunk %d (it has %d)", filePath, int(n), len(chunks))), nil
+		}
+	} else {
+		chunk = chunks[0]
+		for _, candidate := range chunks {
+			if similarities[candidate.VectorID] > similarities[chunk.VectorID] {
+				chunk = candidate
+			}
+		}
+	}
+
+	chunkScores := make([]map[string]interface{}, 0, len(chunks))
+	for _, candidate := range chunks {
+		score := map[string]interface{}{
+			"chunkNum":  candidate.ChunkNum,
+			"startLine": candidate.StartLine,
+			"endLine":   candidate.EndLine,
+		}
+		if similarity, ok := similarities[candidate.VectorID]; ok {
+			score["similarity"] = similarity
+		}
+		chunkScores = append(chunkScores, score)
+	}
+
+	explanation := &storage.PointExplanation{ID: chunk.VectorID}
+	if chunk.VectorID != "" {
+		explanation, err = h.qdrantClient.ExplainCodeIndexPoint(ctx, target.Collection, vectorMode, queryEmbedding, filter, chunk.VectorID, limit)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to explain chunk: %s", err.Error())), nil
+		}
+	}
+
+	chunkFolder := target.FolderPath
+	if payloadFolder, ok := explanation.Payload["folderPath"].(string); ok && payloadFolder != "" {
+		chunkFolder = payloadFolder
+	}
+	weight := profile.WeightFor(chunkFolder)
+	reasons := codeSearchVerdict(explanation, limit, folderPath, filePath, profile, chunkFolder)
+
+	h.logger.Info("Code search explained",
+		zap.String("query", query),
+		zap.String("filePath", filePath),
+		zap.Int("chunkNum", chunk.ChunkNum),
+		zap.Bool("returned", len(reasons) == 0))
+
+	response := map[string]interface{}{
+		"query":      query,
+		"filePath":   filePath,
+		"chunkNum":   chunk.ChunkNum,
+		"startLine":  chunk.StartLine,
+		"endLine":    chunk.EndLine,
+		"collection": target.Collection,
+		"folder":     chunkFolder,
+		"profile":    profileName,
+		"vector":     vectorMode,
+		"limit":      limit,
+		"returned":   len(reasons) == 0,
+		"reasons":    reasons,
+		"similarity": explanation.Similarity,
+		"filter":     explanation.Filter,
+		"rank":       explanation.Rank,
+		"ranking": map[string]interface{}{
+			"reranker":      noReranker,
+			"folderWeight":  weight,
+			"weightedScore": explanation.Similarity * weight,
+			"cutoffScore":   explanation.CutoffScore,
+			"scoredVector":  explanation.Vector,
+		},
+		"metadata": explanation.Payload,
+		"chunks":   chunkScores,
+	}
+	return structuredToolResult(response), nil
+}
+
+// registerKnowledgeExplain registers the knowledge_explain tool
+func (h *QdrantToolHandler) registerKnowledgeExplain(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "knowledge_explain",
+		Description: "Explain why a knowledge entry does or does not appear in knowledge_find results for a query: its embedding similarity, the language filter applied, its rank and the cutoff score, and its stored metadata.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"collectionName": {
+					Type:        "string",
+					Description: "Collection the search ran against",
+				},
+				"query": {
+					Type:        "string",
+					Description: "The search query to explain",
+				},
+				"id": {
+					Type:        "string",
+					Description: "ID of the knowledge entry expected in the results",
+				},
+				"limit": {
+					Type:        "number",
+					Description: "Optional: the search's result limit (default: 5, max: 20)",
+				},
+				"language": languageSchema,
+			},
+			Required: []string{"collectionName", "query", "id"},
+		},
+	}
+
+	h.metadataRegistry.RegisterToolWithServer(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleKnowledgeExplain(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleKnowledgeExplain handles the knowledge_explain tool call
+func (h *QdrantToolHandler) handleKnowledgeExplain(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	collectionName := getStringField(args, "collectionName", "")
+	query := getStringField(args, "query", "")
+	id := getStringField(args, "id", "")
+	if collectionName == "" || query == "" || id == "" {
+		return createErrorResult("collectionName, query and id are required and must be non-empty strings"), nil, nil
+	}
+
+	limit := 5
+	if l, ok := args["limit"].(float64); ok {
+		limit = int(l)
+		if limit > 20 {
+			limit = 20
+		}
+		if limit < 1 {
+			limit = 1
+		}
+	}
+
+	language, err := parseLanguageArg(args, "language")
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	explainer, ok := h.qdrantClient.(knowledgeExplainer)
+	if !ok {
+		return createErrorResult("search explanations are not supported by this Qdrant client"), nil, nil
+	}
+	explanation, err := explainer.ExplainKnowledgePoint(ctx, collectionName, query, language, id, limit)
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to explain entry: %s", err.Error())), nil, nil
+	}
+
+	reasons := searchVerdict(explanation, limit)
+	response := map[string]interface{}{
+		"collectionName": collectionName,
+		"query":          query,
+		"id":             id,
+		"limit":          limit,
+		"returned":       len(reasons) == 0,
+		"reasons":        reasons,
+		"similarity":     explanation.Similarity,
+		"filter":         explanation.Filter,
+		"rank":           explanation.Rank,
+		"cutoffScore":    explanation.CutoffScore,
+		"reranker":       "none: hits are ordered by similarity",
+		"metadata":       explanation.Payload,
+	}
+	return structuredToolResult(response), explanation, nil
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// explainingQdrant explains every knowledge point with a fixed explanation
+type explainingQdrant struct {
+	storage.QdrantClientInterface
+	explanation storage.PointExplanation
+	limit       int
+}
+
+func (q *explainingQdrant) ExplainKnowledgePoint(ctx context.Context, collectionName, query, language, id string, limit int) (*storage.PointExplanation, error) {
+	q.limit = limit
+	explanation := q.explanation
+	explanation.ID = id
+	return &explanation, nil
+}
+
+func TestSearchVerdict(t *testing.T) {
+	returned := &storage.PointExplanation{Found: true, PassesFilter: true, Similarity: 0.8, Rank: 2, RankDepth: 100}
+	assert.Empty(t, searchVerdict(returned, 5))
+	assert.Contains(t, searchVerdict(returned, 1)[0], "ranked 2, below the top 1")
+
+	assert.Contains(t, searchVerdict(&storage.PointExplanation{}, 5)[0], "not stored")
+	assert.Contains(t, searchVerdict(&storage.PointExplanation{Found: true}, 5)[0], "excluded by the search filter")
+	assert.Contains(t, searchVerdict(&storage.PointExplanation{Found: true, PassesFilter: true, RankDepth: 100}, 5)[0], "below the top 100")
+}
+
+func TestCodeSearchVerdict(t *testing.T) {
+	explanation := &storage.PointExplanation{Found: true, PassesFilter: true, Rank: 1, RankDepth: 100}
+	profile := &storage.SearchProfile{Name: "agent"}
+	assert.Empty(t, codeSearchVerdict(explanation, 10, "", "/repo/app/main.go", profile, "/repo/app"))
+
+	reasons := codeSearchVerdict(explanation, 10, "/repo/lib", "/repo/app/main.go", profile, "/repo/app")
+	require.Len(t, reasons, 1)
+	assert.Contains(t, reasons[0], "outside the searched folder")
+
+	profile.AllowedFolders = []string{"/repo/lib"}
+	assert.Contains(t, codeSearchVerdict(explanation, 10, "", "/repo/app/main.go", profile, "/repo/app")[0], "allow-list")
+
+	profile.AllowedFolders = nil
+	profile.FolderWeights = []storage.FolderWeight{{Path: "/repo/app", Weight: 0}}
+	assert.Contains(t, codeSearchVerdict(explanation, 10, "", "/repo/app/main.go", profile, "/repo/app")[0], "weight 0")
+}
+
+func TestHandleKnowledgeExplain(t *testing.T) {
+	client := &explainingQdrant{explanation: storage.PointExplanation{
+		Found: true, PassesFilter: true, Similarity: 0.42, Rank: 7, RankDepth: 100, CutoffScore: 0.5,
+		Payload: map[string]interface{}{"text": "retry policy"},
+	}}
+	handler := NewQdrantToolHandler(client)
+
+	result, explanation, err := handler.handleKnowledgeExplain(context.Background(), map[string]interface{}{
+		"collectionName": "runbooks",
+		"query":          "how do retries work",
+		"id":             "entry-1",
+		"limit":          float64(50),
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, 20, client.limit, "limit is capped like knowledge_find")
+	assert.Equal(t, "entry-1", explanation.(*storage.PointExplanation).ID)
+
+	result, _, err = handler.handleKnowledgeExplain(context.Background(), map[string]interface{}{
+		"collectionName": "runbooks",
+		"query":          "how do retries work",
+		"id":             "entry-1",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, 5, client.limit)
+
+	result, _, err = handler.handleKnowledgeExplain(context.Background(), map[string]interface{}{"collectionName": "runbooks"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
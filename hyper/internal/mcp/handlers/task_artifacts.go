@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetArtifactStorage enables coordinator_attach_artifact and coordinator_list_artifacts
+func (h *ToolHandler) SetArtifactStorage(artifacts *storage.TaskArtifactStorage) {
+	h.artifacts = artifacts
+}
+
+// registerAttachArtifact registers the coordinator_attach_artifact tool
+func (h *ToolHandler) registerAttachArtifact(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_attach_artifact",
+		Description: "Attach a file such as a build log, coverage report or rendered screenshot to an agent task instead of pasting it into notes. Pass text as content or binary data as contentBase64; the result includes the artifact ID and its download path. Larger files can be uploaded with POST /api/v1/agent-tasks/{id}/artifacts (multipart field \"file\").",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"agentTaskId": {
+					Type:        "string",
+					Description: "Agent task ID to attach the artifact to",
+				},
+				"name": {
+					Type:        "string",
+					Description: "File name of the artifact, e.g. build.log or coverage.html",
+				},
+				"content": {
+					Type:        "string",
+					Description: "Text content of the artifact (use contentBase64 for binary files)",
+				},
+				"contentBase64": {
+					Type:        "string",
+					Description: "Base64-encoded content of a binary artifact such as a PNG screenshot",
+				},
+				"contentType": {
+					Type:        "string",
+					Description: "Optional: MIME type (default: detected from the name and content)",
+				},
+				"description": {
+					Type:        "string",
+					Description: "Optional: what the artifact shows",
+				},
+			},
+			Required: []string{"agentTaskId", "name"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleAttachArtifact(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleAttachArtifact handles the coordinator_attach_artifact tool call
+func (h *ToolHandler) handleAttachArtifact(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.artifacts == nil {
+		return createErrorResult("artifacts are unavailable: no artifact storage configured"), nil, nil
+	}
+
+	agentTaskID, _ := args["agentTaskId"].(string)
+	if agentTaskID == "" {
+		return createCodedErrorResult(errcode.Validation, "agentTaskId is required"), nil, nil
+	}
+	name, err := storage.ValidateArtifactName(getStringField(args, "name", ""))
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+	content, err := artifactContent(args)
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+
+	if _, err := h.taskStorage.GetAgentTask(agentTaskID); err != nil {
+		return createErrorResult(fmt.Sprintf("failed to get agent task: %s", err.Error())), nil, nil
+	}
+
+	artifact := &storage.TaskArtifact{
+		AgentTaskID: agentTaskID,
+		Name:        name,
+		ContentType: getStringField(args, "contentType", ""),
+		Description: strings.TrimSpace(getStringField(args, "description", "")),
+	}
+	if err := h.artifacts.Save(ctx, artifact, content); err != nil {
+		return createErrorResult(fmt.Sprintf("failed to attach artifact: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{
+		"artifact":     artifact,
+		"downloadPath": artifact.DownloadPath(),
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// artifactContent returns the content given as exactly one of content and
+// contentBase64
+func artifactContent(args map[string]interface{}) (io.Reader, error) {
+	text, hasText := args["content"].(string)
+	encoded, hasEncoded := args["contentBase64"].(string)
+	switch {
+	case hasText && hasEncoded:
+		return nil, fmt.Errorf("pass either content or contentBase64, not both")
+	case hasText:
+		return strings.NewReader(text), nil
+	case hasEncoded:
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("contentBase64 is invalid base64: %w", err)
+		}
+		return bytes.NewReader(data), nil
+	default:
+		return nil, fmt.Errorf("content or contentBase64 is required")
+	}
+}
+
+// registerListArtifacts registers the coordinator_list_artifacts tool
+func (h *ToolHandler) registerListArtifacts(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_list_artifacts",
+		Description: "List the artifacts attached to an agent task with their names, types, sizes, SHA-256 digests and download paths (GET /api/v1/agent-tasks/{id}/artifacts/{artifactId}).",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"agentTaskId": {
+					Type:        "string",
+					Description: "Agent task ID",
+				},
+			},
+			Required: []string{"agentTaskId"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleListArtifacts(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleListArtifacts handles the coordinator_list_artifacts tool call
+func (h *ToolHandler) handleListArtifacts(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.artifacts == nil {
+		return createErrorResult("artifacts are unavailable: no artifact storage configured"), nil, nil
+	}
+
+	agentTaskID, _ := args["agentTaskId"].(string)
+	if agentTaskID == "" {
+		return createCodedErrorResult(errcode.Validation, "agentTaskId is required"), nil, nil
+	}
+
+	artifacts, err := h.artifacts.List(ctx, agentTaskID)
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to list artifacts: %s", err.Error())), nil, nil
+	}
+
+	listed := make([]map[string]interface{}, len(artifacts))
+	for i, artifact := range artifacts {
+		listed[i] = map[string]interface{}{
+			"artifact":     artifact,
+			"downloadPath": artifact.DownloadPath(),
+		}
+	}
+	response := map[string]interface{}{
+		"agentTaskId": agentTaskID,
+		"artifacts":   listed,
+		"count":       len(listed),
+	}
+	return structuredToolResult(response), response, nil
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachArtifactWithoutStorage(t *testing.T) {
+	h := &ToolHandler{}
+
+	result, _, err := h.handleAttachArtifact(context.Background(), map[string]interface{}{"agentTaskId": "t-1", "name": "build.log", "content": "ok"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "no artifact storage configured")
+}
+
+func TestArtifactContent(t *testing.T) {
+	content, err := artifactContent(map[string]interface{}{"content": "PASS"})
+	require.NoError(t, err)
+	data, _ := io.ReadAll(content)
+	assert.Equal(t, "PASS", string(data))
+
+	content, err = artifactContent(map[string]interface{}{"contentBase64": "iVBORw0KGgo="})
+	require.NoError(t, err)
+	data, _ = io.ReadAll(content)
+	assert.Equal(t, "\x89PNG\r\n\x1a\n", string(data))
+
+	for _, args := range []map[string]interface{}{
+		{},
+		{"content": "a", "contentBase64": "YQ=="},
+		{"contentBase64": "not base64!"},
+	} {
+		_, err := artifactContent(args)
+		assert.Error(t, err, "args %v", args)
+	}
+}
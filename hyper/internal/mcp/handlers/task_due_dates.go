@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerSetTaskDueDate registers the coordinator_set_task_due_date tool
+func (h *ToolHandler) registerSetTaskDueDate(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_set_task_due_date",
+		Description: "Set or clear the due date of a human or agent task. Due dates appear in the calendar feed (/api/calendar.ics) that humans can subscribe to.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"taskId": {
+					Type:        "string",
+					Description: "Human or agent task UUID",
+				},
+				"dueAt": {
+					Type:        "string",
+					Description: "Due date as RFC 3339 (2026-03-01T17:00:00Z) or a date (2026-03-01, end of day UTC). Empty clears the due date.",
+				},
+			},
+			Required: []string{"taskId", "dueAt"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+
+		result, _, err := h.handleSetTaskDueDate(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleSetTaskDueDate sets or clears a task's due date
+func (h *ToolHandler) handleSetTaskDueDate(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	taskID, ok := args["taskId"].(string)
+	if !ok || taskID == "" {
+		return createErrorResult("taskId parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	raw, ok := args["dueAt"].(string)
+	if !ok {
+		return createErrorResult("dueAt parameter is required: a date, an RFC 3339 time, or empty to clear"), nil, nil
+	}
+	dueAt, err := parseDueDate(raw)
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+
+	if err := h.taskStorage.SetTaskDueDate(taskID, dueAt); err != nil {
+		return createErrorResult(fmt.Sprintf("failed to set due date: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{"taskId": taskID, "dueAt": nil}
+	if dueAt != nil {
+		response["dueAt"] = dueAt.Format(time.RFC3339)
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// parseDueDate reads an RFC 3339 time or a plain date, which is due at the
+// end of that day (UTC). An empty value means no due date.
+func parseDueDate(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+	if day, err := time.Parse("2006-01-02", raw); err == nil {
+		t := day.Add(24*time.Hour - time.Second)
+		return &t, nil
+	}
+	return nil, fmt.Errorf("invalid dueAt %q: use a date (2026-03-01) or an RFC 3339 time (2026-03-01T17:00:00Z)", raw)
+}
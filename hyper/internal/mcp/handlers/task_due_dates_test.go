@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDueDate(t *testing.T) {
+	due, err := parseDueDate("2026-03-01T17:00:00+02:00")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC), *due)
+
+	due, err = parseDueDate("2026-03-01")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 1, 23, 59, 59, 0, time.UTC), *due, "dates are due at the end of the day")
+
+	due, err = parseDueDate("")
+	require.NoError(t, err)
+	assert.Nil(t, due)
+
+	_, err = parseDueDate("next friday")
+	assert.Error(t, err)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TaskDuplicateThresholdEnv overrides the similarity above which a new human
+// task prompt is reported as a likely duplicate of an open task
+const TaskDuplicateThresholdEnv = "TASK_DUPLICATE_THRESHOLD"
+
+// defaultDuplicateThreshold is the cosine similarity treated as "same request"
+const defaultDuplicateThreshold = 0.9
+
+// maxDuplicateCandidates caps the candidates reported back to the caller
+const maxDuplicateCandidates = 5
+
+// duplicateCandidate is an open human task whose prompt resembles a new one
+type duplicateCandidate struct {
+	TaskID     string             `json:"taskId"`
+	Prompt     string             `json:"prompt"`
+	Status     storage.TaskStatus `json:"status"`
+	CreatedAt  string             `json:"createdAt"`
+	Similarity float64            `json:"similarity"`
+}
+
+// SetEmbeddingClient enables embedding-based duplicate detection for new human
+// tasks. Without it, prompts are compared by term-frequency cosine similarity.
+func (h *ToolHandler) SetEmbeddingClient(client embeddings.EmbeddingClient) {
+	h.embeddingClient = client
+}
+
+// duplicateThreshold returns the configured similarity threshold
+func duplicateThreshold() float64 {
+	if raw := os.Getenv(TaskDuplicateThresholdEnv); raw != "" {
+		if value, err := strconv.ParseFloat(raw, 64); err == nil && value > 0 && value <= 1 {
+			return value
+		}
+	}
+	return defaultDuplicateThreshold
+}
+
+// findDuplicateHumanTasks returns open (not completed) human tasks whose prompt
+// similarity to prompt is at least threshold, most similar first. Embeddings
+// are used when a client is available; if embedding fails, detection falls back
+// to term vectors rather than blocking task creation.
+func findDuplicateHumanTasks(prompt string, tasks []*storage.HumanTask, threshold float64, client embeddings.EmbeddingClient) []duplicateCandidate {
+	open := make([]*storage.HumanTask, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Status != storage.TaskStatusCompleted && strings.TrimSpace(task.Prompt) != "" {
+			open = append(open, task)
+		}
+	}
+	if len(open) == 0 {
+		return nil
+	}
+
+	similarities := embeddingSimilarities(prompt, open, client)
+	if similarities == nil {
+		similarities = make([]float64, len(open))
+		promptTerms := termVector(prompt)
+		for i, task := range open {
+			similarities[i] = termCosine(promptTerms, termVector(task.Prompt))
+		}
+	}
+
+	var candidates []duplicateCandidate
+	for i, task := range open {
+		if similarities[i] < threshold {
+			continue
+		}
+		candidates = append(candidates, duplicateCandidate{
+			TaskID:     task.ID,
+			Prompt:     task.Prompt,
+			Status:     task.Status,
+			CreatedAt:  task.CreatedAt.Format("2006-01-02 15:04:05 UTC"),
+			Similarity: math.Round(similarities[i]*1000) / 1000,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+	if len(candidates) > maxDuplicateCandidates {
+		candidates = candidates[:maxDuplicateCandidates]
+	}
+	return candidates
+}
+
+// embeddingSimilarities embeds prompt and the task prompts in one batch and
+// returns their cosine similarities, or nil when embeddings are unavailable
+func embeddingSimilarities(prompt string, tasks []*storage.HumanTask, client embeddings.EmbeddingClient) []float64 {
+	texts := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		texts = append(texts, task.Prompt)
+	}
+	return textSimilarities(prompt, texts, client)
+}
+
+// textSimilarities embeds query and texts in one batch and returns the cosine
+// similarity of each text to query, or nil when embeddings are unavailable
+func textSimilarities(query string, texts []string, client embeddings.EmbeddingClient) []float64 {
+	if client == nil || len(texts) == 0 {
+		return nil
+	}
+
+	batch := make([]string, 0, len(texts)+1)
+	batch = append(batch, query)
+	batch = append(batch, texts...)
+
+	vectors, err := client.CreateEmbeddings(batch)
+	if err != nil || len(vectors) != len(batch) {
+		return nil
+	}
+
+	similarities := make([]float64, len(texts))
+	for i := range texts {
+		similarities[i] = cosineSimilarity(vectors[0], vectors[i+1])
+	}
+	return similarities
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors, or 0
+// when either is empty or they differ in length
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// termVector counts the lowercased words of text
+func termVector(text string) map[string]float64 {
+	terms := make(map[string]float64)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		terms[word]++
+	}
+	return terms
+}
+
+// termCosine returns the cosine similarity of two term vectors
+func termCosine(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, count := range a {
+		dot += count * b[term]
+		normA += count * count
+	}
+	for _, count := range b {
+		normB += count * count
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// duplicateTaskResult reports likely duplicates instead of creating the task
+func duplicateTaskResult(candidates []duplicateCandidate, threshold float64) *mcp.CallToolResult {
+	result := createCodedErrorResult(errcode.Conflict, fmt.Sprintf(
+		"prompt is highly similar to %d open human task(s) (similarity >= %.2f); pass force=true to create it anyway",
+		len(candidates), threshold))
+
+	var b strings.Builder
+	b.WriteString("⚠️ Possible duplicate human task — not created.\n\nSimilar open tasks:\n")
+	for i, c := range candidates {
+		fmt.Fprintf(&b, "%d. %s (similarity %.3f, status %s, created %s)\n   %s\n", i+1, c.TaskID, c.Similarity, c.Status, c.CreatedAt, truncateText(c.Prompt, 200))
+	}
+	b.WriteString("\nReuse one of these tasks, or call coordinator_create_human_task again with force=true.")
+
+	result.Content = append(result.Content, &mcp.TextContent{Text: b.String()})
+	result.StructuredContent.(map[string]interface{})["duplicates"] = candidates
+	return result
+}
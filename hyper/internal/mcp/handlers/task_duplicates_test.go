@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEmbeddingClient maps each text to a fixed vector
+type fakeEmbeddingClient struct {
+	vectors map[string][]float32
+	err     error
+}
+
+func (f *fakeEmbeddingClient) CreateEmbedding(text string) ([]float32, error) {
+	return f.vectors[text], f.err
+}
+
+func (f *fakeEmbeddingClient) CreateEmbeddings(texts []string) ([][]float32, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i] = f.vectors[text]
+	}
+	return out, nil
+}
+
+func (f *fakeEmbeddingClient) GetDimensions() int {
+	return 2
+}
+
+func TestFindDuplicateHumanTasks_TermFallback(t *testing.T) {
+	now := time.Now()
+	tasks := []*storage.HumanTask{
+		{ID: "open", Prompt: "Add dark mode to the settings page", Status: storage.TaskStatusInProgress, CreatedAt: now},
+		{ID: "done", Prompt: "Add dark mode to the settings page", Status: storage.TaskStatusCompleted, CreatedAt: now},
+		{ID: "other", Prompt: "Fix login timeout on mobile", Status: storage.TaskStatusPending, CreatedAt: now},
+	}
+
+	candidates := findDuplicateHumanTasks("add dark mode to the Settings page!", tasks, 0.9, nil)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, "open", candidates[0].TaskID)
+	assert.Equal(t, 1.0, candidates[0].Similarity)
+
+	assert.Empty(t, findDuplicateHumanTasks("Rewrite the billing export", tasks, 0.9, nil))
+}
+
+func TestFindDuplicateHumanTasks_Embeddings(t *testing.T) {
+	tasks := []*storage.HumanTask{
+		{ID: "close", Prompt: "Support SSO login", Status: storage.TaskStatusPending},
+		{ID: "closer", Prompt: "Allow single sign-on", Status: storage.TaskStatusBlocked},
+		{ID: "far", Prompt: "Update README", Status: storage.TaskStatusPending},
+	}
+	client := &fakeEmbeddingClient{vectors: map[string][]float32{
+		"Add single sign-on":   {1, 0},
+		"Support SSO login":    {0.95, 0.2},
+		"Allow single sign-on": {0.99, 0.05},
+		"Update README":        {0, 1},
+	}}
+
+	candidates := findDuplicateHumanTasks("Add single sign-on", tasks, 0.9, client)
+	require.Len(t, candidates, 2)
+	assert.Equal(t, "closer", candidates[0].TaskID)
+	assert.Equal(t, "close", candidates[1].TaskID)
+
+	// Embedding failures fall back to term similarity instead of blocking creation
+	client.err = errors.New("embedding service unavailable")
+	assert.Empty(t, findDuplicateHumanTasks("Add single sign-on", tasks, 0.9, client))
+}
+
+func TestDuplicateTaskResult(t *testing.T) {
+	candidates := []duplicateCandidate{{TaskID: "t1", Prompt: "Add dark mode", Status: storage.TaskStatusPending, Similarity: 0.97}}
+
+	result := duplicateTaskResult(candidates, 0.9)
+
+	assert.True(t, result.IsError)
+	structured, ok := result.StructuredContent.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, candidates, structured["duplicates"])
+	assert.Equal(t, errcode.Conflict, structured["error"].(map[string]interface{})["code"])
+}
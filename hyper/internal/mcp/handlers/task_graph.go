@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"hyper/internal/errcode"
+	"hyper/internal/taskgraph"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerGetTaskGraph registers the coordinator_get_task_graph tool
+func (h *ToolHandler) registerGetTaskGraph(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_get_task_graph",
+		Description: "Render the human task → agent tasks → dependencies graph as Mermaid or Graphviz DOT text, to embed in docs and PR descriptions. An agent task depends on another when its notes or prior work summary mention the other's ID. The text content is the diagram itself.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"humanTaskId": {
+					Type:        "string",
+					Description: "Human task UUID to graph. Omit to graph every human task.",
+				},
+				"format": {
+					Type:        "string",
+					Enum:        []interface{}{"mermaid", "dot"},
+					Description: "Diagram language (default mermaid)",
+				},
+				"includeTodos": {
+					Type:        "boolean",
+					Description: "Add each agent task's TODO items to the graph (default false)",
+				},
+			},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+
+		result, _, err := h.handleGetTaskGraph(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleGetTaskGraph renders the task graph as a diagram
+func (h *ToolHandler) handleGetTaskGraph(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	humanTaskID, _ := args["humanTaskId"].(string)
+	rawFormat, _ := args["format"].(string)
+	format, err := taskgraph.ParseFormat(rawFormat)
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+	includeTodos, _ := args["includeTodos"].(bool)
+
+	graph, err := taskgraph.Build(h.taskStorage.ListAllHumanTasks(), h.taskStorage.ListAllAgentTasks(), taskgraph.Options{
+		HumanTaskID:  humanTaskID,
+		IncludeTodos: includeTodos,
+	})
+	if err != nil {
+		return createCodedErrorResult(errcode.NotFound, err.Error()), nil, nil
+	}
+	diagram := graph.Render(format)
+
+	response := map[string]interface{}{
+		"format":  string(format),
+		"diagram": diagram,
+		"nodes":   graph.Nodes,
+		"edges":   graph.Edges,
+	}
+	if humanTaskID != "" {
+		response["humanTaskId"] = humanTaskID
+	}
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: diagram}},
+		StructuredContent: response,
+	}, response, nil
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphTaskStorage lists fixed tasks; other TaskStorage methods are not called
+type graphTaskStorage struct {
+	storage.TaskStorage
+	human []*storage.HumanTask
+	agent []*storage.AgentTask
+}
+
+func (s *graphTaskStorage) ListAllHumanTasks() []*storage.HumanTask { return s.human }
+func (s *graphTaskStorage) ListAllAgentTasks() []*storage.AgentTask { return s.agent }
+
+func TestHandleGetTaskGraph(t *testing.T) {
+	h := &ToolHandler{taskStorage: &graphTaskStorage{
+		human: []*storage.HumanTask{{ID: "h-1", Prompt: "Add webhooks", Status: storage.TaskStatusPending}},
+		agent: []*storage.AgentTask{
+			{ID: "a-1", HumanTaskID: "h-1", Role: "API", Status: storage.TaskStatusCompleted},
+			{ID: "a-2", HumanTaskID: "h-1", Role: "UI", Status: storage.TaskStatusPending, Notes: "Needs a-1"},
+		},
+	}}
+
+	result, _, err := h.handleGetTaskGraph(context.Background(), map[string]interface{}{"humanTaskId": "h-1", "format": "dot"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.True(t, strings.HasPrefix(text, "digraph tasks {"), "the text content is the diagram")
+	assert.Contains(t, text, `a1 -> a2 [style=dashed, label="blocks"];`)
+	assert.Equal(t, "dot", result.StructuredContent.(map[string]interface{})["format"])
+
+	result, _, err = h.handleGetTaskGraph(context.Background(), map[string]interface{}{"humanTaskId": "h-9"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, _, err = h.handleGetTaskGraph(context.Background(), map[string]interface{}{"format": "png"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hyper/internal/errcode"
+	"hyper/internal/escalation"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetPriorityRules enables coordinator_set_task_priority. The engine stores
+// priorities and applies the inheritance and escalation rules.
+func (h *ToolHandler) SetPriorityRules(rules *escalation.Engine) {
+	h.priorityRules = rules
+}
+
+// registerSetTaskPriority registers the coordinator_set_task_priority tool
+func (h *ToolHandler) registerSetTaskPriority(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_set_task_priority",
+		Description: "Set or clear the priority of a human or agent task: low, medium, high or critical. Agent tasks without a priority take their human task's. Pending agent tasks are queued by priority first. When a high-priority human task has blocked agent tasks, the agent tasks they depend on (by ID in their notes or prior work summary) inherit its priority until the work waiting on them is unblocked, and agent tasks blocked too long are escalated by email.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"taskId": {
+					Type:        "string",
+					Description: "Human or agent task UUID",
+				},
+				"priority": {
+					Type:        "string",
+					Enum:        []interface{}{"low", "medium", "high", "critical", ""},
+					Description: "low, medium, high or critical. Empty clears the priority.",
+				},
+			},
+			Required: []string{"taskId", "priority"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+
+		result, _, err := h.handleSetTaskPriority(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleSetTaskPriority sets or clears a task's priority
+func (h *ToolHandler) handleSetTaskPriority(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.priorityRules == nil {
+		return createCodedErrorResult(errcode.DependencyUnavailable, "task priorities are unavailable: no priority rules configured"), nil, nil
+	}
+
+	taskID, ok := args["taskId"].(string)
+	taskID = strings.TrimSpace(taskID)
+	if !ok || taskID == "" {
+		return createErrorResult("taskId parameter is required and must be a non-empty string"), nil, nil
+	}
+	raw, ok := args["priority"].(string)
+	if !ok {
+		return createErrorResult("priority parameter is required: low, medium, high, critical, or empty to clear"), nil, nil
+	}
+	priority, err := storage.ParseTaskPriority(raw)
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+
+	report, err := h.priorityRules.SetPriority(taskID, priority)
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to set priority: %s", err.Error())), nil, nil
+	}
+
+	response := map[string]interface{}{"taskId": taskID, "priority": nil}
+	if priority != storage.PriorityUnset {
+		response["priority"] = string(priority)
+	}
+	if report != nil {
+		response["inherited"] = report.Raised
+		response["restored"] = report.Restored
+		response["escalated"] = report.Escalated
+	}
+	return structuredToolResult(response), response, nil
+}
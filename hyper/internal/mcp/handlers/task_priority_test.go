@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/escalation"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// priorityTaskStorage serves human tasks whose agent tasks wait on each
+// other; other TaskStorage methods are not called
+type priorityTaskStorage struct {
+	storage.TaskStorage
+	humans []*storage.HumanTask
+	agents []*storage.AgentTask
+}
+
+func (s *priorityTaskStorage) ListAllHumanTasks() []*storage.HumanTask {
+	return s.humans
+}
+
+func (s *priorityTaskStorage) ListAllAgentTasks() []*storage.AgentTask {
+	return s.agents
+}
+
+func (s *priorityTaskStorage) GetAgentTaskActivity(taskID string) ([]storage.TaskActivity, error) {
+	return nil, nil
+}
+
+func (s *priorityTaskStorage) SetTaskPriority(taskID string, priority storage.TaskPriority) error {
+	for _, task := range s.humans {
+		if task.ID == taskID {
+			task.Priority = priority
+			return nil
+		}
+	}
+	return assert.AnError
+}
+
+func (s *priorityTaskStorage) SetInheritedPriority(taskID string, priority storage.TaskPriority, from string) error {
+	for _, task := range s.agents {
+		if task.ID == taskID {
+			task.InheritedPriority = priority
+			task.PriorityInheritedFrom = from
+		}
+	}
+	return nil
+}
+
+func (s *priorityTaskStorage) MarkBlockedEscalated(taskID string, at *time.Time) error {
+	return nil
+}
+
+func TestSetTaskPriority(t *testing.T) {
+	h := NewToolHandler(nil, nil, nil)
+	result, _, err := h.handleSetTaskPriority(context.Background(), map[string]interface{}{"taskId": "h-1", "priority": "high"})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.DependencyUnavailable, errorCode(t, result))
+
+	tasks := &priorityTaskStorage{
+		humans: []*storage.HumanTask{
+			{ID: "h-1", Status: storage.TaskStatusInProgress},
+			{ID: "h-2", Status: storage.TaskStatusInProgress},
+		},
+		agents: []*storage.AgentTask{
+			{ID: "a-1", HumanTaskID: "h-2", AgentName: "backend", Status: storage.TaskStatusInProgress, UpdatedAt: time.Now()},
+			{ID: "a-2", HumanTaskID: "h-1", AgentName: "frontend", Status: storage.TaskStatusBlocked, Notes: "Needs a-1", UpdatedAt: time.Now()},
+		},
+	}
+	cfg := escalation.Config{Interval: time.Minute, EscalateAt: time.Hour, MinPriority: storage.PriorityHigh}
+	h.SetPriorityRules(escalation.NewEngine(cfg, tasks, tasks, notify.NewMailer(notify.SMTPConfig{}), zap.NewNop()))
+
+	result, _, err = h.handleSetTaskPriority(context.Background(), map[string]interface{}{"taskId": "h-1", "priority": "urgent"})
+	require.NoError(t, err)
+	assert.Equal(t, errcode.Validation, errorCode(t, result))
+
+	result, response, err := h.handleSetTaskPriority(context.Background(), map[string]interface{}{"taskId": "h-1", "priority": "Critical"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	body := response.(map[string]interface{})
+	assert.Equal(t, "critical", body["priority"])
+	assert.Len(t, body["inherited"], 1)
+	assert.Equal(t, storage.PriorityCritical, tasks.agents[0].InheritedPriority)
+
+	result, response, err = h.handleSetTaskPriority(context.Background(), map[string]interface{}{"taskId": "h-1", "priority": ""})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Nil(t, response.(map[string]interface{})["priority"])
+	assert.Len(t, response.(map[string]interface{})["restored"], 1, "cleared priorities give inherited ones back")
+}
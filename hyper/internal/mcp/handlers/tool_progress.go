@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+
+	"hyper/internal/toolprogress"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// forwardProgress sends the toolprogress reports of a call as
+// notifications/progress when the client asked for them with a progress
+// token. Notifications carry no content, so partial results only reach REST
+// clients streaming the call.
+func forwardProgress(handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if req == nil || req.Session == nil || req.Params == nil {
+			return handler(ctx, req)
+		}
+		token := req.Params.GetProgressToken()
+		if token == nil {
+			return handler(ctx, req)
+		}
+
+		session := req.Session
+		ctx = toolprogress.WithReporter(ctx, func(update toolprogress.Update) {
+			// A client that went away just misses the notification
+			_ = session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: token,
+				Progress:      update.Progress,
+				Total:         update.Total,
+				Message:       update.Message,
+			})
+		})
+		return handler(ctx, req)
+	}
+}
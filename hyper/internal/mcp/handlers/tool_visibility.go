@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"os"
+	"strings"
+	"sync"
+
+	"hyper/internal/errcode"
+	"hyper/internal/middleware"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// TrustedClientsEnv lists the MCP clients, by the clientInfo name they send
+// in initialize, that may see and call local filesystem and exec tools.
+// Unset or "*" trusts every client.
+const TrustedClientsEnv = "MCP_TRUSTED_CLIENTS"
+
+// execTools read or change the coordinator host directly or run arbitrary
+// tools, so they are offered to trusted clients only
+var execTools = map[string]bool{
+	"bash":         true,
+	"file_read":    true,
+	"file_write":   true,
+	"apply_patch":  true,
+	"execute_tool": true,
+}
+
+// maxTrackedSessions bounds the roles remembered per session before sessions
+// that have ended are pruned
+const maxTrackedSessions = 256
+
+// ClientTrust decides which MCP clients may use exec tools
+type ClientTrust struct {
+	all     bool
+	trusted map[string]bool
+}
+
+// LoadClientTrust reads the trusted clients from MCP_TRUSTED_CLIENTS
+func LoadClientTrust() ClientTrust {
+	return ParseClientTrust(os.Getenv(TrustedClientsEnv))
+}
+
+// ParseClientTrust parses a comma-separated list of trusted client names
+// (case-insensitive); an empty list or "*" trusts every client
+func ParseClientTrust(value string) ClientTrust {
+	trust := ClientTrust{trusted: make(map[string]bool)}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "*" {
+			trust.all = true
+		} else if name != "" {
+			trust.trusted[name] = true
+		}
+	}
+	if len(trust.trusted) == 0 {
+		trust.all = true
+	}
+	return trust
+}
+
+// Trusts reports whether the client that sent params may use exec tools.
+// Clients that did not identify themselves are untrusted unless every client is.
+func (t ClientTrust) Trusts(params *mcp.InitializeParams) bool {
+	if t.all {
+		return true
+	}
+	if params == nil || params.ClientInfo == nil {
+		return false
+	}
+	return t.trusted[strings.ToLower(params.ClientInfo.Name)]
+}
+
+// toolVisibility tailors the tool list to each session
+type toolVisibility struct {
+	stdioRole middleware.Role
+	policy    middleware.Policy
+	trust     ClientTrust
+	sessions  func() iter.Seq[*mcp.ServerSession]
+	notify    func()
+	logger    *zap.Logger
+
+	mu     sync.Mutex
+	listed map[*mcp.ServerSession]middleware.Role
+}
+
+// NewToolVisibilityMiddleware returns MCP receiving middleware that tailors
+// tools/list to the caller: tools the caller's role cannot call are hidden
+// (unless an authorization policy decides per call), and exec tools are
+// hidden from, and refused to, clients not trusted by trust.
+// When a session's role changes after it listed tools, every session of
+// server is sent tools/list_changed (see NotifyToolListChanged) so the client
+// lists them again.
+func NewToolVisibilityMiddleware(server *mcp.Server, registry *ToolMetadataRegistry, stdioRole middleware.Role, policy middleware.Policy, trust ClientTrust, logger *zap.Logger) mcp.Middleware {
+	return newToolVisibility(stdioRole, policy, trust, server.Sessions, func() { registry.NotifyToolListChanged(server) }, logger).middleware()
+}
+
+// newToolVisibility creates the per-session tool list state
+func newToolVisibility(stdioRole middleware.Role, policy middleware.Policy, trust ClientTrust, sessions func() iter.Seq[*mcp.ServerSession], notify func(), logger *zap.Logger) *toolVisibility {
+	return &toolVisibility{
+		stdioRole: stdioRole,
+		policy:    policy,
+		trust:     trust,
+		sessions:  sessions,
+		notify:    notify,
+		logger:    logger,
+		listed:    make(map[*mcp.ServerSession]middleware.Role),
+	}
+}
+
+// middleware filters tools/list, refuses exec tool calls from untrusted
+// clients and watches session roles
+func (v *toolVisibility) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			session, _ := req.GetSession().(*mcp.ServerSession)
+			role, _ := callerRole(req, v.stdioRole)
+
+			switch method {
+			case "tools/list":
+				result, err := next(ctx, method, req)
+				if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+					list.Tools = v.visibleTools(list.Tools, role, callerScopes(req), sessionParams(session))
+					v.remember(session, role)
+				}
+				return result, err
+
+			case "tools/call":
+				callReq, ok := req.(*mcp.CallToolRequest)
+				if ok && execTools[callReq.Params.Name] && !v.trust.Trusts(sessionParams(session)) {
+					v.logger.Warn("Exec tool call denied for untrusted client",
+						zap.String("tool", callReq.Params.Name),
+						zap.String("client", clientName(sessionParams(session))))
+					return createCodedErrorResult(errcode.PermissionDenied, fmt.Sprintf(
+						"permission denied: tool %s is not available to this client (add it to %s)", callReq.Params.Name, TrustedClientsEnv)), nil
+				}
+			}
+
+			v.checkRole(session, role)
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// visibleTools returns the tools a caller with role and a credential with
+// scopes, using the client that sent params, may call
+func (v *toolVisibility) visibleTools(tools []*mcp.Tool, role middleware.Role, scopes middleware.Scopes, params *mcp.InitializeParams) []*mcp.Tool {
+	trusted := v.trust.Trusts(params)
+	visible := make([]*mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if execTools[tool.Name] && !trusted {
+			continue
+		}
+		if !scopes.Allows(middleware.RequiredScopeForTool(tool.Name)) {
+			continue
+		}
+		// A policy may allow calls the role alone would not, so it is left to decide per call
+		if v.policy == nil && !role.Allows(middleware.RequiredRoleForTool(tool.Name)) {
+			continue
+		}
+		visible = append(visible, tool)
+	}
+	return visible
+}
+
+// remember records the role a session listed tools with
+func (v *toolVisibility) remember(session *mcp.ServerSession, role middleware.Role) {
+	if session == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, ok := v.listed[session]; !ok && len(v.listed) >= maxTrackedSessions {
+		v.pruneEnded()
+	}
+	v.listed[session] = role
+}
+
+// pruneEnded forgets sessions the server no longer has. Called with mu held.
+func (v *toolVisibility) pruneEnded() {
+	live := make(map[*mcp.ServerSession]bool)
+	for session := range v.sessions() {
+		live[session] = true
+	}
+	for session := range v.listed {
+		if !live[session] {
+			delete(v.listed, session)
+		}
+	}
+}
+
+// checkRole notifies the client when its role changed since it listed tools,
+// because its tool list changed with it
+func (v *toolVisibility) checkRole(session *mcp.ServerSession, role middleware.Role) {
+	if session == nil || v.notify == nil || v.policy != nil {
+		return
+	}
+	v.mu.Lock()
+	listedRole, listed := v.listed[session]
+	changed := listed && listedRole != role
+	if changed {
+		v.listed[session] = role
+	}
+	v.mu.Unlock()
+
+	if changed {
+		v.logger.Info("Session role changed, notifying tool list change",
+			zap.String("session", session.ID()),
+			zap.String("previousRole", string(listedRole)),
+			zap.String("role", string(role)))
+		v.notify()
+	}
+}
+
+// sessionParams returns the initialize parameters of session, or nil
+func sessionParams(session *mcp.ServerSession) *mcp.InitializeParams {
+	if session == nil {
+		return nil
+	}
+	return session.InitializeParams()
+}
+
+// clientName returns the clientInfo name sent with params, or "unknown"
+func clientName(params *mcp.InitializeParams) string {
+	if params == nil || params.ClientInfo == nil || params.ClientInfo.Name == "" {
+		return "unknown"
+	}
+	return params.ClientInfo.Name
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"slices"
+	"testing"
+
+	"hyper/internal/middleware"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func listedToolNames(result mcp.Result) []string {
+	var names []string
+	for _, tool := range result.(*mcp.ListToolsResult).Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+// listAllTools is a method handler listing a fixed set of tools
+func listAllTools(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+	if method != "tools/list" {
+		return &mcp.CallToolResult{}, nil
+	}
+	return &mcp.ListToolsResult{Tools: []*mcp.Tool{
+		{Name: "coordinator_list_human_tasks"},
+		{Name: "coordinator_clear_task_board"},
+		{Name: "code_index_scan"},
+		{Name: "bash"},
+	}}, nil
+}
+
+func roleHeader(role middleware.Role) *mcp.RequestExtra {
+	return &mcp.RequestExtra{Header: http.Header{middleware.RoleHeader: []string{string(role)}}}
+}
+
+func TestParseClientTrust(t *testing.T) {
+	claude := &mcp.InitializeParams{ClientInfo: &mcp.Implementation{Name: "claude-code"}}
+	other := &mcp.InitializeParams{ClientInfo: &mcp.Implementation{Name: "web-agent"}}
+
+	all := ParseClientTrust("")
+	assert.True(t, all.Trusts(other))
+	assert.True(t, all.Trusts(nil))
+
+	trust := ParseClientTrust(" Claude-Code , cursor")
+	assert.True(t, trust.Trusts(claude))
+	assert.False(t, trust.Trusts(other))
+	assert.False(t, trust.Trusts(nil), "anonymous clients are untrusted")
+
+	assert.True(t, ParseClientTrust("cursor,*").Trusts(other))
+}
+
+func TestToolVisibility_FiltersToolList(t *testing.T) {
+	visibility := newToolVisibility(middleware.RoleAdmin, nil, ParseClientTrust("claude-code"), nil, nil, zap.NewNop())
+	handler := visibility.middleware()(listAllTools)
+
+	// Stdio callers keep the admin role but an anonymous client loses exec tools
+	result, err := handler(context.Background(), "tools/list", &mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"coordinator_list_human_tasks", "coordinator_clear_task_board", "code_index_scan"}, listedToolNames(result))
+
+	result, err = handler(context.Background(), "tools/list", &mcp.ListToolsRequest{Extra: roleHeader(middleware.RoleContributor)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"coordinator_list_human_tasks"}, listedToolNames(result))
+
+	// Untrusted clients cannot call exec tools they were not offered
+	callResult, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "bash"}})
+	require.NoError(t, err)
+	assert.True(t, callResult.(*mcp.CallToolResult).IsError)
+
+	callResult, err = handler(context.Background(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "code_index_scan"}})
+	require.NoError(t, err)
+	assert.False(t, callResult.(*mcp.CallToolResult).IsError)
+}
+
+func TestToolVisibility_NotifiesRoleChange(t *testing.T) {
+	session := &mcp.ServerSession{}
+	notified := 0
+	sessions := func() iter.Seq[*mcp.ServerSession] { return slices.Values([]*mcp.ServerSession{session}) }
+	visibility := newToolVisibility(middleware.RoleAdmin, nil, ParseClientTrust(""), sessions, func() { notified++ }, zap.NewNop())
+	handler := visibility.middleware()(listAllTools)
+
+	_, err := handler(context.Background(), "tools/list", &mcp.ListToolsRequest{Session: session, Extra: roleHeader(middleware.RoleOperator)})
+	require.NoError(t, err)
+
+	_, err = handler(context.Background(), "tools/call", &mcp.CallToolRequest{Session: session, Extra: roleHeader(middleware.RoleOperator), Params: &mcp.CallToolParamsRaw{Name: "code_index_scan"}})
+	require.NoError(t, err)
+	assert.Zero(t, notified)
+
+	_, err = handler(context.Background(), "tools/call", &mcp.CallToolRequest{Session: session, Extra: roleHeader(middleware.RoleViewer), Params: &mcp.CallToolParamsRaw{Name: "code_index_search"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, notified)
+
+	// Ended sessions are forgotten once the limit is reached
+	for i := 0; i < maxTrackedSessions; i++ {
+		visibility.listed[&mcp.ServerSession{}] = middleware.RoleViewer
+	}
+	visibility.remember(&mcp.ServerSession{}, middleware.RoleViewer)
+	assert.Len(t, visibility.listed, 2)
+}
@@ -6,7 +6,18 @@ import (
 	"fmt"
 	"time"
 
+	"hyper/internal/automation"
+	"hyper/internal/bulktasks"
+	"hyper/internal/confirm"
+	"hyper/internal/digest"
+	"hyper/internal/errcode"
+	"hyper/internal/escalation"
+	"hyper/internal/federation"
+	"hyper/internal/i18n"
+	"hyper/internal/knowledgeeval"
+	"hyper/internal/mcp/embeddings"
 	"hyper/internal/mcp/storage"
+	"hyper/internal/undo"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -19,6 +30,29 @@ type ToolHandler struct {
 	knowledgeStorage storage.KnowledgeStorage
 	mongoDatabase    *mongo.Database // For querying subagents
 	metadataRegistry *ToolMetadataRegistry
+
+	knowledgeEnvironments *storage.KnowledgeEnvironmentStorage // Optional: template variables for knowledge queries
+	embeddingClient       embeddings.EmbeddingClient           // Optional: semantic duplicate detection for human tasks
+	undoManager           *undo.Manager                        // Optional: stages destructive operations for an undo window
+	answerGenerator       AnswerGenerator                      // Optional: LLM used by coordinator_answer and coordinator_draft_adr
+	digestSubscriptions   *storage.DigestSubscriptionStorage   // Optional: scheduled digest configuration
+	digestScheduler       *digest.Scheduler                    // Optional: builds and delivers digests on demand
+	subagents             *storage.SubchatStorage              // Optional: registered subagents and their personas
+	automationHooks       *storage.AutomationHookStorage       // Optional: task lifecycle hook scripts
+	automationEngine      *automation.Engine                   // Optional: runs hook scripts for coordinator_test_automation_hook
+	dataSubjectEraser     *storage.DataSubjectEraser           // Optional: right-to-erasure purges
+	artifacts             *storage.TaskArtifactStorage         // Optional: files attached to agent tasks
+	federationPeers       *storage.FederationStorage           // Optional: peer coordinators and delegated tasks
+	federationSync        *federation.Sync                     // Optional: delegates tasks to peers and syncs their status
+	collectionAliases     CollectionAliasManager               // Optional: Qdrant collection migrations and rollbacks
+	codeSearcher          CodeSearcher                         // Optional: code scope of coordinator_search
+	toolSearcher          ToolSearcher                         // Optional: tools scope of coordinator_search
+	resourceReader        BatchResourceReader                  // Optional: reads resources for coordinator_read_resources
+	priorityRules         *escalation.Engine                   // Optional: task priorities and their inheritance and escalation rules
+	bulkEditor            *bulktasks.Editor                    // Optional: bulk agent task edits
+	vectorTier            VectorTierStatus                     // Optional: archived knowledge collections
+	knowledgeEvaluator    *knowledgeeval.Evaluator             // Optional: knowledge retrieval evaluation suites
+	scheduledTasks        *storage.ScheduledTaskStorage        // Optional: recurring agent tasks
 }
 
 // NewToolHandler creates a new tool handler
@@ -37,19 +71,7 @@ func (h *ToolHandler) SetMetadataRegistry(registry *ToolMetadataRegistry) {
 
 // addToolWithMetadata adds a tool to the server and registers it for indexing
 func (h *ToolHandler) addToolWithMetadata(server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandler) {
-	server.AddTool(tool, handler)
-	if h.metadataRegistry != nil {
-		h.metadataRegistry.RegisterTool(
-			tool.Name,
-			tool.Description,
-			map[string]interface{}{
-				"type":        "mcp-tool",
-				"name":        tool.Name,
-				"description": tool.Description,
-				"inputSchema": tool.InputSchema,
-			},
-		)
-	}
+	h.metadataRegistry.RegisterToolWithServer(server, tool, handler)
 }
 
 // RegisterToolHandlers registers all tool handlers with the MCP server
@@ -64,6 +86,36 @@ func (h *ToolHandler) RegisterToolHandlers(server *mcp.Server) error {
 		return fmt.Errorf("failed to register query_knowledge tool: %w", err)
 	}
 
+	// Register coordinator_answer
+	if err := h.registerAnswer(server); err != nil {
+		return fmt.Errorf("failed to register answer tool: %w", err)
+	}
+
+	// Register coordinator_search
+	if err := h.registerUnifiedSearch(server); err != nil {
+		return fmt.Errorf("failed to register search tool: %w", err)
+	}
+
+	// Register coordinator_read_resources
+	if err := h.registerReadResources(server); err != nil {
+		return fmt.Errorf("failed to register read_resources tool: %w", err)
+	}
+
+	// Register coordinator_build_context_bundle
+	if err := h.registerBuildContextBundle(server); err != nil {
+		return fmt.Errorf("failed to register build_context_bundle tool: %w", err)
+	}
+
+	// Register coordinator_set_knowledge_environment
+	if err := h.registerSetKnowledgeEnvironment(server); err != nil {
+		return fmt.Errorf("failed to register set_knowledge_environment tool: %w", err)
+	}
+
+	// Register coordinator_list_knowledge_environments
+	if err := h.registerListKnowledgeEnvironments(server); err != nil {
+		return fmt.Errorf("failed to register list_knowledge_environments tool: %w", err)
+	}
+
 	// Register coordinator_get_popular_collections
 	if err := h.registerGetPopularCollections(server); err != nil {
 		return fmt.Errorf("failed to register get_popular_collections tool: %w", err)
@@ -104,11 +156,59 @@ func (h *ToolHandler) RegisterToolHandlers(server *mcp.Server) error {
 		return fmt.Errorf("failed to register get_agent_task tool: %w", err)
 	}
 
+	// Register coordinator_clone_human_task
+	if err := h.registerCloneHumanTask(server); err != nil {
+		return fmt.Errorf("failed to register clone_human_task tool: %w", err)
+	}
+
+	// Register coordinator_set_task_due_date
+	if err := h.registerSetTaskDueDate(server); err != nil {
+		return fmt.Errorf("failed to register set_task_due_date tool: %w", err)
+	}
+
+	// Register coordinator_set_task_priority
+	if err := h.registerSetTaskPriority(server); err != nil {
+		return fmt.Errorf("failed to register set_task_priority tool: %w", err)
+	}
+
+	// Register coordinator_bulk_update_agent_tasks
+	if err := h.registerBulkUpdateAgentTasks(server); err != nil {
+		return fmt.Errorf("failed to register bulk_update_agent_tasks tool: %w", err)
+	}
+
+	// Register coordinator_get_task_graph
+	if err := h.registerGetTaskGraph(server); err != nil {
+		return fmt.Errorf("failed to register get_task_graph tool: %w", err)
+	}
+
+	// Register coordinator_export_run
+	if err := h.registerExportRun(server); err != nil {
+		return fmt.Errorf("failed to register export_run tool: %w", err)
+	}
+
+	// Register coordinator_draft_adr
+	if err := h.registerDraftADR(server); err != nil {
+		return fmt.Errorf("failed to register draft_adr tool: %w", err)
+	}
+
+	// Register coordinator_review_adr
+	if err := h.registerReviewADR(server); err != nil {
+		return fmt.Errorf("failed to register review_adr tool: %w", err)
+	}
+
 	// Register coordinator_clear_task_board
 	if err := h.registerClearTaskBoard(server); err != nil {
 		return fmt.Errorf("failed to register clear_task_board tool: %w", err)
 	}
 
+	if err := h.registerUndo(server); err != nil {
+		return fmt.Errorf("failed to register undo tool: %w", err)
+	}
+
+	if err := h.registerConfirmOperation(server); err != nil {
+		return fmt.Errorf("failed to register confirm_operation tool: %w", err)
+	}
+
 	// Register coordinator_add_task_prompt_notes
 	if err := h.registerAddTaskPromptNotes(server); err != nil {
 		return fmt.Errorf("failed to register add_task_prompt_notes tool: %w", err)
@@ -139,6 +239,141 @@ func (h *ToolHandler) RegisterToolHandlers(server *mcp.Server) error {
 		return fmt.Errorf("failed to register clear_todo_prompt_notes tool: %w", err)
 	}
 
+	// Register coordinator_set_digest_subscription
+	if err := h.registerSetDigestSubscription(server); err != nil {
+		return fmt.Errorf("failed to register set_digest_subscription tool: %w", err)
+	}
+
+	// Register coordinator_list_digest_subscriptions
+	if err := h.registerListDigestSubscriptions(server); err != nil {
+		return fmt.Errorf("failed to register list_digest_subscriptions tool: %w", err)
+	}
+
+	// Register coordinator_send_digest
+	if err := h.registerSendDigest(server); err != nil {
+		return fmt.Errorf("failed to register send_digest tool: %w", err)
+	}
+
+	// Register coordinator_get_agent_persona
+	if err := h.registerGetAgentPersona(server); err != nil {
+		return fmt.Errorf("failed to register get_agent_persona tool: %w", err)
+	}
+
+	// Register coordinator_set_agent_persona
+	if err := h.registerSetAgentPersona(server); err != nil {
+		return fmt.Errorf("failed to register set_agent_persona tool: %w", err)
+	}
+
+	// Register coordinator_set_agent_bootstrap
+	if err := h.registerSetAgentBootstrap(server); err != nil {
+		return fmt.Errorf("failed to register set_agent_bootstrap tool: %w", err)
+	}
+
+	// Register coordinator_set_automation_hook
+	if err := h.registerSetAutomationHook(server); err != nil {
+		return fmt.Errorf("failed to register set_automation_hook tool: %w", err)
+	}
+
+	// Register coordinator_list_automation_hooks
+	if err := h.registerListAutomationHooks(server); err != nil {
+		return fmt.Errorf("failed to register list_automation_hooks tool: %w", err)
+	}
+
+	// Register coordinator_test_automation_hook
+	if err := h.registerTestAutomationHook(server); err != nil {
+		return fmt.Errorf("failed to register test_automation_hook tool: %w", err)
+	}
+
+	// Register coordinator_erase_data_subject
+	if err := h.registerEraseDataSubject(server); err != nil {
+		return fmt.Errorf("failed to register erase_data_subject tool: %w", err)
+	}
+
+	// Register coordinator_attach_artifact
+	if err := h.registerAttachArtifact(server); err != nil {
+		return fmt.Errorf("failed to register attach_artifact tool: %w", err)
+	}
+
+	// Register coordinator_list_artifacts
+	if err := h.registerListArtifacts(server); err != nil {
+		return fmt.Errorf("failed to register list_artifacts tool: %w", err)
+	}
+
+	// Register coordinator_set_federation_peer
+	if err := h.registerSetFederationPeer(server); err != nil {
+		return fmt.Errorf("failed to register set_federation_peer tool: %w", err)
+	}
+
+	// Register coordinator_list_federation_peers
+	if err := h.registerListFederationPeers(server); err != nil {
+		return fmt.Errorf("failed to register list_federation_peers tool: %w", err)
+	}
+
+	// Register coordinator_delegate_task
+	if err := h.registerDelegateTask(server); err != nil {
+		return fmt.Errorf("failed to register delegate_task tool: %w", err)
+	}
+
+	// Register coordinator_list_delegations
+	if err := h.registerListDelegations(server); err != nil {
+		return fmt.Errorf("failed to register list_delegations tool: %w", err)
+	}
+
+	// Register coordinator_list_collection_aliases
+	if err := h.registerListCollectionAliases(server); err != nil {
+		return fmt.Errorf("failed to register list_collection_aliases tool: %w", err)
+	}
+
+	// Register coordinator_migrate_collection
+	if err := h.registerMigrateCollection(server); err != nil {
+		return fmt.Errorf("failed to register migrate_collection tool: %w", err)
+	}
+
+	// Register coordinator_switch_collection_alias
+	if err := h.registerSwitchCollectionAlias(server); err != nil {
+		return fmt.Errorf("failed to register switch_collection_alias tool: %w", err)
+	}
+
+	// Register coordinator_export_knowledge
+	if err := h.registerExportKnowledge(server); err != nil {
+		return fmt.Errorf("failed to register export_knowledge tool: %w", err)
+	}
+
+	// Register coordinator_import_knowledge
+	if err := h.registerImportKnowledge(server); err != nil {
+		return fmt.Errorf("failed to register import_knowledge tool: %w", err)
+	}
+
+	// Register coordinator_set_knowledge_eval
+	if err := h.registerSetKnowledgeEval(server); err != nil {
+		return fmt.Errorf("failed to register set_knowledge_eval tool: %w", err)
+	}
+
+	// Register coordinator_run_knowledge_eval
+	if err := h.registerRunKnowledgeEval(server); err != nil {
+		return fmt.Errorf("failed to register run_knowledge_eval tool: %w", err)
+	}
+
+	// Register coordinator_list_knowledge_evals
+	if err := h.registerListKnowledgeEvals(server); err != nil {
+		return fmt.Errorf("failed to register list_knowledge_evals tool: %w", err)
+	}
+
+	// Register coordinator_create_scheduled_task
+	if err := h.registerCreateScheduledTask(server); err != nil {
+		return fmt.Errorf("failed to register create_scheduled_task tool: %w", err)
+	}
+
+	// Register coordinator_list_scheduled_tasks
+	if err := h.registerListScheduledTasks(server); err != nil {
+		return fmt.Errorf("failed to register list_scheduled_tasks tool: %w", err)
+	}
+
+	// Register coordinator_delete_scheduled_task
+	if err := h.registerDeleteScheduledTask(server); err != nil {
+		return fmt.Errorf("failed to register delete_scheduled_task tool: %w", err)
+	}
+
 	// Register list_subagents
 	if err := h.registerListSubagents(server); err != nil {
 		return fmt.Errorf("failed to register list_subagents tool: %w", err)
@@ -172,6 +407,7 @@ func (h *ToolHandler) registerUpsertKnowledge(server *mcp.Server) error {
 					Type:        "object",
 					Description: "Optional metadata (taskId, agentName, timestamp, etc.)",
 				},
+				"autoChunk": autoChunkSchema,
 			},
 			Required: []string{"collection", "text"},
 		},
@@ -193,7 +429,7 @@ func (h *ToolHandler) registerUpsertKnowledge(server *mcp.Server) error {
 func (h *ToolHandler) registerQueryKnowledge(server *mcp.Server) error {
 	tool := &mcp.Tool{
 		Name:        "coordinator_query_knowledge",
-		Description: "Query the coordinator knowledge base. Returns most relevant knowledge entries with similarity scores.",
+		Description: "Query the coordinator knowledge base. Returns most relevant knowledge entries with similarity scores. When the collection's vectors are archived, the response is an object {results, count, archive, message}: results come from text search and the vectors are restored in the background.",
 		InputSchema: &jsonschema.Schema{
 			Type: "object",
 			Properties: map[string]*jsonschema.Schema{
@@ -209,6 +445,12 @@ func (h *ToolHandler) registerQueryKnowledge(server *mcp.Server) error {
 					Type:        "number",
 					Description: "Maximum number of results (default: 5)",
 				},
+				"timeoutMs": {
+					Type:        "number",
+					Description: "Optional: time budget in milliseconds (max 60000). When set, the response is an object {results, count, truncated, elapsedMs} and truncated=true marks a query that did not finish in time",
+				},
+				"environment": environmentSchema,
+				"language":    languageSchema,
 			},
 			Required: []string{"collection", "query"},
 		},
@@ -230,7 +472,7 @@ func (h *ToolHandler) registerQueryKnowledge(server *mcp.Server) error {
 func (h *ToolHandler) registerCreateHumanTask(server *mcp.Server) error {
 	tool := &mcp.Tool{
 		Name:        "coordinator_create_human_task",
-		Description: "Create a new human task with the original user prompt. Returns a unique taskId (UUID format).",
+		Description: "Create a new human task with the original user prompt. Returns a unique taskId (UUID format). If the prompt closely matches an open task, returns the likely duplicates instead; pass force=true to create it anyway.",
 		InputSchema: &jsonschema.Schema{
 			Type: "object",
 			Properties: map[string]*jsonschema.Schema{
@@ -238,6 +480,10 @@ func (h *ToolHandler) registerCreateHumanTask(server *mcp.Server) error {
 					Type:        "string",
 					Description: "Original human request/prompt",
 				},
+				"force": {
+					Type:        "boolean",
+					Description: "Optional: create the task even if it looks like a duplicate of an open task (default: false)",
+				},
 			},
 			Required: []string{"prompt"},
 		},
@@ -326,6 +572,15 @@ func (h *ToolHandler) registerCreateAgentTask(server *mcp.Server) error {
 										Type:        "string",
 										Description: "Additional context for this TODO (optional)",
 									},
+									"estimatedMinutes": {
+										Type:        "number",
+										Description: "Estimated effort in minutes (optional)",
+									},
+									"checklist": {
+										Type:        "array",
+										Description: "Nested checklist items for complex TODOs (optional). Each gets its own ID and status; the TODO auto-completes when all items are completed",
+										Items:       &jsonschema.Schema{Type: "string"},
+									},
 								},
 								Required: []string{"description"},
 							},
@@ -404,12 +659,19 @@ func (h *ToolHandler) handleUpsertKnowledge(ctx context.Context, args map[string
 		metadata = m
 	}
 
+	if limit := maxKnowledgeTextBytes(); len(text) > limit {
+		if !knowledgeAutoChunk(args) {
+			return oversizedKnowledgeError(len(text), limit), nil, nil
+		}
+		return h.upsertKnowledgeChunks(ctx, collection, storage.SplitKnowledgeText(text, limit), metadata)
+	}
+
 	entry, err := h.knowledgeStorage.Upsert(collection, text, metadata)
 	if err != nil {
 		return createErrorResult(fmt.Sprintf("failed to upsert knowledge: %s", err.Error())), nil, nil
 	}
 
-	resultText := fmt.Sprintf("✓ Knowledge stored successfully\n\nID: %s\nCollection: %s\nCreated: %s",
+	resultText := i18n.T(ctx, "knowledge.stored",
 		entry.ID, entry.Collection, entry.CreatedAt.Format("2006-01-02 15:04:05 UTC"))
 
 	return &mcp.CallToolResult{
@@ -436,7 +698,28 @@ func (h *ToolHandler) handleQueryKnowledge(ctx context.Context, args map[string]
 		limit = int(l)
 	}
 
-	results, err := h.knowledgeStorage.Query(collection, query, limit)
+	language, err := parseLanguageArg(args, "language")
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+
+	budget, err := newSearchBudget(args)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	env, err := resolveKnowledgeEnvironment(h.knowledgeEnvironments, args)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+	variables := environmentVariables(env)
+
+	searchCtx, cancel := budget.context(ctx)
+	defer cancel()
+
+	results, completed, err := runWithinBudget(searchCtx, budget, func() ([]*storage.QueryResult, error) {
+		return queryKnowledge(searchCtx, h.knowledgeStorage, collection, query, language, limit)
+	})
 	if err != nil {
 		return createErrorResult(fmt.Sprintf("failed to query knowledge: %s", err.Error())), nil, nil
 	}
@@ -457,15 +740,35 @@ func (h *ToolHandler) handleQueryKnowledge(ctx context.Context, args map[string]
 		entries[i] = KnowledgeEntryResponse{
 			ID:         result.Entry.ID,
 			Collection: result.Entry.Collection,
-			Text:       result.Entry.Text,
+			Text:       storage.InterpolateEnvironment(result.Entry.Text, variables),
 			Metadata:   result.Entry.Metadata,
 			CreatedAt:  result.Entry.CreatedAt.Format(time.RFC3339),
 			Score:      result.Score,
 		}
 	}
 
+	// Time-boxed queries report whether the budget ran out and queries of
+	// archived collections report the archive; plain queries keep the array format
+	var payload interface{} = entries
+	archive := h.archivedCollection(collection)
+	if budget.limited() || archive != nil {
+		object := map[string]interface{}{
+			"results": entries,
+			"count":   len(entries),
+		}
+		if budget.limited() {
+			object["truncated"] = !completed
+			object["elapsedMs"] = budget.elapsedMs()
+		}
+		if archive != nil {
+			object["archive"] = archive
+			object["message"] = fmt.Sprintf("Collection %s is %s: results come from MongoDB text search until its vectors are restored", collection, archive.Tier)
+		}
+		payload = object
+	}
+
 	// Marshal to JSON
-	jsonData, err := json.Marshal(entries)
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return createErrorResult(fmt.Sprintf("failed to serialize results: %s", err.Error())), nil, nil
 	}
@@ -484,12 +787,20 @@ func (h *ToolHandler) handleCreateHumanTask(ctx context.Context, args map[string
 		return createErrorResult("prompt parameter is required and must be a non-empty string"), nil, nil
 	}
 
+	// Catch the same request pasted twice before it becomes a second workstream
+	if force, _ := args["force"].(bool); !force {
+		threshold := duplicateThreshold()
+		if candidates := findDuplicateHumanTasks(prompt, h.taskStorage.ListAllHumanTasks(), threshold, h.embeddingClient); len(candidates) > 0 {
+			return duplicateTaskResult(candidates, threshold), candidates, nil
+		}
+	}
+
 	task, err := h.taskStorage.CreateHumanTask(prompt)
 	if err != nil {
 		return createErrorResult(fmt.Sprintf("failed to create human task: %s", err.Error())), nil, nil
 	}
 
-	resultText := fmt.Sprintf("✓ Human task created successfully\n\nTask ID: %s\nCreated: %s\nStatus: %s\n\nPrompt: %s",
+	resultText := i18n.T(ctx, "task.human.created",
 		task.ID, task.CreatedAt.Format("2006-01-02 15:04:05 UTC"), task.Status, task.Prompt)
 
 	return &mcp.CallToolResult{
@@ -551,6 +862,18 @@ func (h *ToolHandler) handleCreateAgentTask(ctx context.Context, args map[string
 			if notes, ok := todoMap["notes"].(string); ok {
 				todos[i].Notes = notes
 			}
+			if minutes, err := optionalMinutes(todoMap, "estimatedMinutes"); err != nil {
+				return createErrorResult(fmt.Sprintf("todos[%d]: %s", i, err.Error())), nil, nil
+			} else if minutes != nil {
+				todos[i].EstimatedMinutes = *minutes
+			}
+			if raw, ok := todoMap["checklist"]; ok {
+				checklist, err := parseStringList(raw, fmt.Sprintf("todos[%d].checklist", i))
+				if err != nil {
+					return createErrorResult(err.Error()), nil, nil
+				}
+				todos[i].Checklist = checklist
+			}
 		} else {
 			return createErrorResult(fmt.Sprintf("todos[%d] must be a string or an object with description field", i)), nil, nil
 		}
@@ -592,31 +915,34 @@ func (h *ToolHandler) handleCreateAgentTask(ctx context.Context, args map[string
 		return createErrorResult(fmt.Sprintf("failed to create agent task: %s", err.Error())), nil, nil
 	}
 
-	resultText := fmt.Sprintf("✓ Agent task created successfully\n\nTask ID: %s\nAgent: %s\nRole: %s\nParent Task: %s\nCreated: %s\nStatus: %s\n",
+	resultText := i18n.T(ctx, "task.agent.created",
 		task.ID, task.AgentName, task.Role, task.HumanTaskID, task.CreatedAt.Format("2006-01-02 15:04:05 UTC"), task.Status)
 
 	if task.ContextSummary != "" {
-		resultText += fmt.Sprintf("\nContext Summary: %s\n", task.ContextSummary)
+		resultText += i18n.T(ctx, "task.agent.contextSummary", task.ContextSummary)
 	}
 	if len(task.FilesModified) > 0 {
-		resultText += fmt.Sprintf("\nFiles to Modify: %v\n", task.FilesModified)
+		resultText += i18n.T(ctx, "task.agent.filesToModify", task.FilesModified)
 	}
 	if len(task.QdrantCollections) > 0 {
-		resultText += fmt.Sprintf("\nSuggested Qdrant Collections: %v\n", task.QdrantCollections)
+		resultText += i18n.T(ctx, "task.agent.suggestedColls", task.QdrantCollections)
 	}
 
-	resultText += "\nTODOs:\n"
+	resultText += i18n.T(ctx, "task.agent.todosHeader")
 	for i, todo := range task.Todos {
 		resultText += fmt.Sprintf("  %d. %s", i+1, todo.Description)
 		if todo.FilePath != "" {
-			resultText += fmt.Sprintf(" (File: %s)", todo.FilePath)
+			resultText += i18n.T(ctx, "task.agent.todoFile", todo.FilePath)
 		}
 		if todo.FunctionName != "" {
-			resultText += fmt.Sprintf(" (Function: %s)", todo.FunctionName)
+			resultText += i18n.T(ctx, "task.agent.todoFunction", todo.FunctionName)
 		}
 		resultText += "\n"
 		if todo.ContextHint != "" {
-			resultText += fmt.Sprintf("     Hint: %s\n", todo.ContextHint)
+			resultText += i18n.T(ctx, "task.agent.todoHint", todo.ContextHint)
+		}
+		for _, item := range todo.Checklist {
+			resultText += fmt.Sprintf("     - [ ] %s (ID: %s)\n", item.Description, item.ID)
 		}
 	}
 
@@ -646,14 +972,30 @@ func (h *ToolHandler) handleUpdateTaskStatus(ctx context.Context, args map[strin
 		notes = n
 	}
 
+	// An agent moving its task to in_progress claims it
+	var claimed *storage.AgentTask
+	if status == storage.TaskStatusInProgress {
+		if task, err := h.taskStorage.GetAgentTask(taskID); err == nil && task.Status != storage.TaskStatusInProgress {
+			claimed = task
+		}
+	}
+
 	err := h.taskStorage.UpdateTaskStatus(taskID, status, notes)
 	if err != nil {
 		return createErrorResult(fmt.Sprintf("failed to update task status: %s", err.Error())), nil, nil
 	}
 
-	resultText := fmt.Sprintf("✓ Task status updated successfully\n\nTask ID: %s\nNew Status: %s", taskID, status)
+	resultText := i18n.T(ctx, "task.status.updated", taskID, status)
 	if notes != "" {
-		resultText += fmt.Sprintf("\nNotes: %s", notes)
+		resultText += i18n.T(ctx, "common.notes", notes)
+	}
+
+	var bootstrap []bootstrapEntry
+	if claimed != nil {
+		bootstrap = h.bootstrapKnowledge(ctx, claimed)
+		if len(bootstrap) > 0 {
+			resultText += formatBootstrapKnowledge(bootstrap)
+		}
 	}
 
 	return &mcp.CallToolResult{
@@ -661,9 +1003,10 @@ func (h *ToolHandler) handleUpdateTaskStatus(ctx context.Context, args map[strin
 			&mcp.TextContent{Text: resultText},
 		},
 	}, map[string]interface{}{
-		"taskId": taskID,
-		"status": status,
-		"notes":  notes,
+		"taskId":    taskID,
+		"status":    status,
+		"notes":     notes,
+		"bootstrap": bootstrap,
 	}, nil
 }
 
@@ -671,7 +1014,7 @@ func (h *ToolHandler) handleUpdateTaskStatus(ctx context.Context, args map[strin
 func (h *ToolHandler) registerUpdateTodoStatus(server *mcp.Server) error {
 	tool := &mcp.Tool{
 		Name:        "coordinator_update_todo_status",
-		Description: "Update the status of a specific TODO item within an agent task. Status values: pending, in_progress, completed. When all TODOs are completed, the agent task is automatically marked as completed.",
+		Description: "Update the status of a specific TODO item within an agent task, or of one of its checklist items (pass checklistItemId). Status values: pending, in_progress, completed. A TODO with a checklist is completed when all its items are; when all TODOs are completed, the agent task is automatically marked as completed.",
 		InputSchema: &jsonschema.Schema{
 			Type: "object",
 			Properties: map[string]*jsonschema.Schema{
@@ -683,6 +1026,18 @@ func (h *ToolHandler) registerUpdateTodoStatus(server *mcp.Server) error {
 					Type:        "string",
 					Description: "TODO item ID (UUID)",
 				},
+				"checklistItemId": {
+					Type:        "string",
+					Description: "Optional: checklist item ID within the TODO; updates that item instead of the TODO itself",
+				},
+				"estimatedMinutes": {
+					Type:        "number",
+					Description: "Optional: estimated effort for the TODO in minutes",
+				},
+				"actualMinutes": {
+					Type:        "number",
+					Description: "Optional: actual effort spent on the TODO in minutes (report it when completing)",
+				},
 				"status": {
 					Type:        "string",
 					Description: "New status (pending, in_progress, completed)",
@@ -733,14 +1088,56 @@ func (h *ToolHandler) handleUpdateTodoStatus(ctx context.Context, args map[strin
 		notes = n
 	}
 
-	err := h.taskStorage.UpdateTodoStatus(agentTaskID, todoID, status, notes)
+	// Time tracking applies to the TODO itself, also when a checklist item is updated
+	estimatedMinutes, err := optionalMinutes(args, "estimatedMinutes")
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+	actualMinutes, err := optionalMinutes(args, "actualMinutes")
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+	if err := h.taskStorage.UpdateTodoTime(agentTaskID, todoID, estimatedMinutes, actualMinutes); err != nil {
+		return createErrorResult(fmt.Sprintf("failed to update TODO time: %s", err.Error())), nil, nil
+	}
+
+	if itemID, ok := args["checklistItemId"].(string); ok && itemID != "" {
+		if err := h.taskStorage.UpdateChecklistItemStatus(agentTaskID, todoID, itemID, status, notes); err != nil {
+			return createErrorResult(fmt.Sprintf("failed to update checklist item status: %s", err.Error())), nil, nil
+		}
+
+		resultText := i18n.T(ctx, "todo.checklist.updated", agentTaskID, todoID, itemID, status)
+		if notes != "" {
+			resultText += i18n.T(ctx, "common.notes", notes)
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: resultText},
+			},
+		}, map[string]interface{}{
+			"agentTaskId":     agentTaskID,
+			"todoId":          todoID,
+			"checklistItemId": itemID,
+			"status":          status,
+			"notes":           notes,
+		}, nil
+	}
+
+	err = h.taskStorage.UpdateTodoStatus(agentTaskID, todoID, status, notes)
 	if err != nil {
 		return createErrorResult(fmt.Sprintf("failed to update TODO status: %s", err.Error())), nil, nil
 	}
 
-	resultText := fmt.Sprintf("✓ TODO status updated successfully\n\nAgent Task ID: %s\nTODO ID: %s\nNew Status: %s", agentTaskID, todoID, status)
+	resultText := i18n.T(ctx, "todo.status.updated", agentTaskID, todoID, status)
 	if notes != "" {
-		resultText += fmt.Sprintf("\nNotes: %s", notes)
+		resultText += i18n.T(ctx, "common.notes", notes)
+	}
+	if estimatedMinutes != nil {
+		resultText += i18n.T(ctx, "todo.time.estimated", *estimatedMinutes)
+	}
+	if actualMinutes != nil {
+		resultText += i18n.T(ctx, "todo.time.actual", *actualMinutes)
 	}
 
 	return &mcp.CallToolResult{
@@ -823,7 +1220,7 @@ func (h *ToolHandler) handleListHumanTasks(ctx context.Context) (*mcp.CallToolRe
 		return createErrorResult(fmt.Sprintf("failed to marshal tasks: %s", err.Error())), nil, nil
 	}
 
-	resultText := fmt.Sprintf("✓ Retrieved %d human tasks\n\nTasks:\n%s", len(tasks), string(tasksJSON))
+	resultText := i18n.T(ctx, "tasks.human.listed", len(tasks), string(tasksJSON))
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -951,6 +1348,9 @@ func (h *ToolHandler) handleListAgentTasks(ctx context.Context, args map[string]
 			}
 
 			todoMap["humanPromptNotesAddedAt"] = todo.HumanPromptNotesAddedAt
+			if len(todo.Checklist) > 0 {
+				todoMap["checklist"] = todo.Checklist
+			}
 			truncatedTodos[j] = todoMap
 		}
 		taskMap["todos"] = truncatedTodos
@@ -963,16 +1363,16 @@ func (h *ToolHandler) handleListAgentTasks(ctx context.Context, args map[string]
 		return createErrorResult(fmt.Sprintf("failed to marshal tasks: %s", err.Error())), nil, nil
 	}
 
-	resultText := fmt.Sprintf("✓ Retrieved %d agent tasks (showing %d-%d of %d total)",
+	resultText := i18n.T(ctx, "tasks.agent.listed",
 		len(paginatedTasks), offset+1, offset+len(paginatedTasks), totalCount)
 	if humanTaskID != "" {
-		resultText += fmt.Sprintf("\nFiltered by humanTaskId: %s", humanTaskID)
+		resultText += i18n.T(ctx, "tasks.agent.filteredByHuman", humanTaskID)
 	}
 	if agentName != "" {
-		resultText += fmt.Sprintf("\nFiltered by agentName: %s", agentName)
+		resultText += i18n.T(ctx, "tasks.agent.filteredByAgent", agentName)
 	}
-	resultText += fmt.Sprintf("\n\nℹ️  Note: Fields >500 bytes are truncated. Use coordinator_get_agent_task(taskId) for full details.")
-	resultText += fmt.Sprintf("\n\nTasks:\n%s", string(tasksJSON))
+	resultText += i18n.T(ctx, "tasks.agent.truncationNote")
+	resultText += i18n.T(ctx, "tasks.agent.body", string(tasksJSON))
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -1033,7 +1433,7 @@ func (h *ToolHandler) handleGetAgentTask(ctx context.Context, args map[string]in
 		return createErrorResult(fmt.Sprintf("failed to marshal task: %s", err.Error())), nil, nil
 	}
 
-	resultText := fmt.Sprintf("✓ Retrieved agent task\n\nTask:\n%s", string(taskJSON))
+	resultText := i18n.T(ctx, "task.agent.retrieved", string(taskJSON))
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -1059,30 +1459,146 @@ func (h *ToolHandler) extractArguments(req *mcp.CallToolRequest) (map[string]int
 	return result, nil
 }
 
-// createErrorResult creates an error result with the given message
+// createErrorResult creates an error result with the given message.
+// The error code is classified from the message; use createCodedErrorResult
+// when the caller knows the code.
 func createErrorResult(message string) *mcp.CallToolResult {
+	return createCodedErrorResult(errcode.Classify(message), message)
+}
+
+// createCodedErrorResult creates an error result whose structured content
+// carries the error code, so clients need not parse the message text
+func createCodedErrorResult(code errcode.Code, message string) *mcp.CallToolResult {
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: fmt.Sprintf("❌ Error: %s", message)},
 		},
+		StructuredContent: map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    code,
+				"message": message,
+			},
+		},
 		IsError: true,
 	}
 }
 
+// structuredToolResult returns a JSON response both as text, for MCP clients,
+// and as structured content, which in-process REST callers use without
+// re-parsing the text
+func structuredToolResult(response map[string]interface{}) *mcp.CallToolResult {
+	jsonData, _ := json.Marshal(response)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(jsonData)},
+		},
+		StructuredContent: response,
+	}
+}
+
+// optionalMinutes reads an optional non-negative whole-minute argument
+func optionalMinutes(args map[string]interface{}, name string) (*int, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	value, ok := raw.(float64)
+	if !ok || value < 0 || value != float64(int(value)) {
+		return nil, fmt.Errorf("%s must be a non-negative whole number of minutes", name)
+	}
+	minutes := int(value)
+	return &minutes, nil
+}
+
+// registerCloneHumanTask registers the coordinator_clone_human_task tool
+func (h *ToolHandler) registerCloneHumanTask(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_clone_human_task",
+		Description: "Clone a human task with all its agent tasks and TODOs into a new task tree (new IDs, statuses reset to pending). Use it to try an alternative breakdown without touching the live task.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"taskId": {
+					Type:        "string",
+					Description: "Human task UUID to clone",
+				},
+				"project": {
+					Type:        "string",
+					Description: "Optional: project for the clone (defaults to the source task's project)",
+				},
+				"prompt": {
+					Type:        "string",
+					Description: "Optional: prompt for the clone (defaults to the source task's prompt)",
+				},
+			},
+			Required: []string{"taskId"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+
+		result, _, err := h.handleCloneHumanTask(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleCloneHumanTask deep-copies a human task tree
+func (h *ToolHandler) handleCloneHumanTask(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	taskID, ok := args["taskId"].(string)
+	if !ok || taskID == "" {
+		return createErrorResult("taskId parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	project, _ := args["project"].(string)
+	prompt, _ := args["prompt"].(string)
+
+	clone, agentTasks, err := h.taskStorage.CloneHumanTask(taskID, project, prompt)
+	if err != nil {
+		return createErrorResult(fmt.Sprintf("failed to clone task: %s", err.Error())), nil, nil
+	}
+
+	todoCount := 0
+	agentTaskIDs := make(map[string]string, len(agentTasks))
+	for _, task := range agentTasks {
+		todoCount += len(task.Todos)
+		agentTaskIDs[task.ClonedFrom] = task.ID
+	}
+
+	resultText := i18n.T(ctx, "task.human.cloned", taskID, clone.ID, len(agentTasks), todoCount)
+	if clone.Project != "" {
+		resultText += i18n.T(ctx, "task.human.project", clone.Project)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: resultText},
+		},
+	}, map[string]interface{}{
+		"task":         clone,
+		"agentTasks":   agentTasks,
+		"agentTaskIds": agentTaskIDs,
+	}, nil
+}
+
 // registerClearTaskBoard registers the coordinator_clear_task_board tool
 func (h *ToolHandler) registerClearTaskBoard(server *mcp.Server) error {
 	tool := &mcp.Tool{
 		Name:        "coordinator_clear_task_board",
-		Description: "Clear all tasks from the coordinator. ⚠️ DESTRUCTIVE OPERATION - Cannot be undone. Removes all human tasks and agent tasks.",
+		Description: "Clear all tasks from the coordinator. ⚠️ DESTRUCTIVE OPERATION - Removes all human tasks and agent tasks. The clear is staged for an undo window (UNDO_WINDOW_SECONDS): cancel it with coordinator_undo or commit it early with coordinator_confirm_operation. Clients that support elicitation are asked to confirm; others must pass confirm: true.",
 		InputSchema: &jsonschema.Schema{
 			Type: "object",
 			Properties: map[string]*jsonschema.Schema{
 				"confirm": {
 					Type:        "boolean",
-					Description: "Must be true to confirm deletion",
+					Description: "Set to true to confirm deletion without asking (required when the client does not support elicitation)",
 				},
 			},
-			Required: []string{"confirm"},
 		},
 	}
 
@@ -1101,12 +1617,24 @@ func (h *ToolHandler) registerClearTaskBoard(server *mcp.Server) error {
 
 // handleClearTaskBoard clears all tasks from the database
 func (h *ToolHandler) handleClearTaskBoard(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, map[string]interface{}, error) {
-	// Check confirmation
-	confirm, ok := args["confirm"].(bool)
-	if !ok || !confirm {
+	switch confirm.Ask(ctx, args, "Clear all human and agent tasks from the coordinator task board?") {
+	case confirm.Declined:
+		return createErrorResult("Clear cancelled: confirmation was declined"), nil, nil
+	case confirm.Required:
 		return createErrorResult("Confirmation required: set confirm=true to clear all tasks"), nil, nil
 	}
 
+	if h.undoManager.Enabled() {
+		op := h.undoManager.Stage("coordinator_clear_task_board", "Clear all human and agent tasks", func(ctx context.Context) (interface{}, error) {
+			result, err := h.taskStorage.ClearAllTasks()
+			if err != nil {
+				return nil, fmt.Errorf("failed to clear tasks: %w", err)
+			}
+			return result, nil
+		})
+		return stagedOperationResult(ctx, op), map[string]interface{}{"staged": true, "operation": op}, nil
+	}
+
 	// Clear all tasks
 	result, err := h.taskStorage.ClearAllTasks()
 	if err != nil {
@@ -1119,7 +1647,7 @@ func (h *ToolHandler) handleClearTaskBoard(ctx context.Context, args map[string]
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("✓ Task board cleared successfully\n\n%s", string(resultJSON)),
+				Text: i18n.T(ctx, "board.cleared", string(resultJSON)),
 			},
 		},
 	}, map[string]interface{}{
@@ -1358,7 +1886,7 @@ func (h *ToolHandler) handleAddTaskPromptNotes(ctx context.Context, args map[str
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("✓ Added prompt notes to task %s", agentTaskId),
+				Text: i18n.T(ctx, "notes.task.added", agentTaskId),
 			},
 		},
 	}, nil, nil
@@ -1391,7 +1919,7 @@ func (h *ToolHandler) handleUpdateTaskPromptNotes(ctx context.Context, args map[
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("✓ Updated prompt notes for task %s", agentTaskId),
+				Text: i18n.T(ctx, "notes.task.updated", agentTaskId),
 			},
 		},
 	}, nil, nil
@@ -1413,7 +1941,7 @@ func (h *ToolHandler) handleClearTaskPromptNotes(ctx context.Context, args map[s
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("✓ Cleared prompt notes from task %s", agentTaskId),
+				Text: i18n.T(ctx, "notes.task.cleared", agentTaskId),
 			},
 		},
 	}, nil, nil
@@ -1451,7 +1979,7 @@ func (h *ToolHandler) handleAddTodoPromptNotes(ctx context.Context, args map[str
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("✓ Added prompt notes to TODO %s in task %s", todoId, agentTaskId),
+				Text: i18n.T(ctx, "notes.todo.added", todoId, agentTaskId),
 			},
 		},
 	}, nil, nil
@@ -1489,7 +2017,7 @@ func (h *ToolHandler) handleUpdateTodoPromptNotes(ctx context.Context, args map[
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("✓ Updated prompt notes for TODO %s in task %s", todoId, agentTaskId),
+				Text: i18n.T(ctx, "notes.todo.updated", todoId, agentTaskId),
 			},
 		},
 	}, nil, nil
@@ -1516,7 +2044,7 @@ func (h *ToolHandler) handleClearTodoPromptNotes(ctx context.Context, args map[s
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("✓ Cleared prompt notes from TODO %s in task %s", todoId, agentTaskId),
+				Text: i18n.T(ctx, "notes.todo.cleared", todoId, agentTaskId),
 			},
 		},
 	}, nil, nil
@@ -1624,7 +2152,7 @@ func (h *ToolHandler) handleListSubagents(ctx context.Context) (*mcp.CallToolRes
 		Description string `json:"description"`
 	}
 
-	collection := h.mongoDatabase.Collection("subagents")
+	collection := h.mongoDatabase.Collection(storage.CollectionName("subagents"))
 
 	cursor, err := collection.Find(ctx, map[string]interface{}{})
 	if err != nil {
@@ -1727,7 +2255,7 @@ func (h *ToolHandler) handleSetCurrentSubagent(ctx context.Context, args map[str
 
 	// Note: Actual MongoDB update will be implemented in subchat service
 	// For now, return success with note that this requires chat context
-	resultText := fmt.Sprintf("✓ Subagent '%s' validated successfully\n\nNote: Chat session association will be implemented via subchat service. Use subchat_handler REST API to create subchats with subagent assignments.", subagentName)
+	resultText := i18n.T(ctx, "subagent.validated", subagentName)
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -9,7 +9,10 @@ import (
 	"net/http"
 	"time"
 
+	"hyper/internal/console"
+	"hyper/internal/errcode"
 	"hyper/internal/mcp/storage"
+	"hyper/internal/undo"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -20,7 +23,8 @@ type ToolsDiscoveryHandler struct {
 	toolsStorage     storage.ToolsStorageInterface
 	metadataRegistry *ToolMetadataRegistry
 	mcpServer        *mcp.Server
-	httpClient       *http.Client // For discovering tools from external MCP servers
+	httpClient       *http.Client  // For discovering tools from external MCP servers
+	undoManager      *undo.Manager // Optional: stages server removals for an undo window
 }
 
 // NewToolsDiscoveryHandler creates a new tools discovery handler
@@ -39,21 +43,14 @@ func (h *ToolsDiscoveryHandler) SetMetadataRegistry(registry *ToolMetadataRegist
 	h.metadataRegistry = registry
 }
 
+// SetUndoManager stages mcp_remove_server for the manager's undo window
+func (h *ToolsDiscoveryHandler) SetUndoManager(manager *undo.Manager) {
+	h.undoManager = manager
+}
+
 // addToolWithMetadata adds a tool to the server and registers it for indexing
 func (h *ToolsDiscoveryHandler) addToolWithMetadata(server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandler) {
-	server.AddTool(tool, handler)
-	if h.metadataRegistry != nil {
-		h.metadataRegistry.RegisterTool(
-			tool.Name,
-			tool.Description,
-			map[string]interface{}{
-				"type":        "mcp-tool",
-				"name":        tool.Name,
-				"description": tool.Description,
-				"inputSchema": tool.InputSchema,
-			},
-		)
-	}
+	h.metadataRegistry.RegisterToolWithServer(server, tool, handler)
 }
 
 // RegisterToolsDiscoveryTools registers tools discovery tools with the MCP server
@@ -413,6 +410,12 @@ func (h *ToolsDiscoveryHandler) executeToolOnServer(ctx context.Context, serverU
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: fmt.Sprintf("Tool '%s' execution failed: %s", toolName, errorMsg)},
 			},
+			StructuredContent: map[string]interface{}{
+				"error": map[string]interface{}{
+					"code":    errcode.Classify(errorMsg),
+					"message": errorMsg,
+				},
+			},
 			IsError: true,
 		}, nil
 	}
@@ -506,7 +509,7 @@ func (h *ToolsDiscoveryHandler) registerMCPRediscoverServer(server *mcp.Server)
 func (h *ToolsDiscoveryHandler) registerMCPRemoveServer(server *mcp.Server) error {
 	tool := &mcp.Tool{
 		Name:        "mcp_remove_server",
-		Description: "Remove an MCP server and all its tools from the registry. This deletes the server metadata and all associated tool data from MongoDB and Qdrant.",
+		Description: "Remove an MCP server and all its tools from the registry. This deletes the server metadata and all associated tool data from MongoDB and Qdrant. The removal is staged for an undo window (UNDO_WINDOW_SECONDS): cancel it with coordinator_undo or commit it early with coordinator_confirm_operation.",
 		InputSchema: &jsonschema.Schema{
 			Type: "object",
 			Properties: map[string]*jsonschema.Schema{
@@ -573,7 +576,7 @@ func (h *ToolsDiscoveryHandler) HandleMCPAddServer(ctx context.Context, args map
 		schema, _ := tool["inputSchema"].(map[string]interface{})
 
 		if err := h.toolsStorage.StoreToolMetadata(ctx, toolName, desc, schema, serverName); err != nil {
-			fmt.Printf("Warning: failed to store tool %s: %v\n", toolName, err)
+			console.Printf("Warning: failed to store tool %s: %v\n", toolName, err)
 			continue
 		}
 		successCount++
@@ -626,7 +629,7 @@ func (h *ToolsDiscoveryHandler) HandleMCPRediscoverServer(ctx context.Context, a
 		schema, _ := tool["inputSchema"].(map[string]interface{})
 
 		if err := h.toolsStorage.StoreToolMetadata(ctx, toolName, desc, schema, serverName); err != nil {
-			fmt.Printf("Warning: failed to store tool %s: %v\n", toolName, err)
+			console.Printf("Warning: failed to store tool %s: %v\n", toolName, err)
 			continue
 		}
 		successCount++
@@ -660,14 +663,18 @@ func (h *ToolsDiscoveryHandler) HandleMCPRemoveServer(ctx context.Context, args
 		return createErrorResult(fmt.Sprintf("failed to get server: %s", err.Error())), nil, nil
 	}
 
-	// Remove all tools for this server
-	if err := h.toolsStorage.RemoveServerTools(ctx, serverName); err != nil {
-		return createErrorResult(fmt.Sprintf("failed to remove server tools: %s", err.Error())), nil, nil
+	if h.undoManager.Enabled() {
+		op := h.undoManager.Stage("mcp_remove_server", fmt.Sprintf("Remove MCP server '%s' (%s) and its tools", serverName, server.ServerURL), func(ctx context.Context) (interface{}, error) {
+			if err := h.removeServer(ctx, serverName); err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"serverName": serverName, "removed": true}, nil
+		})
+		return stagedOperationResult(ctx, op), map[string]interface{}{"staged": true, "operation": op}, nil
 	}
 
-	// Remove server from registry
-	if err := h.toolsStorage.RemoveServer(ctx, serverName); err != nil {
-		return createErrorResult(fmt.Sprintf("failed to remove server: %s", err.Error())), nil, nil
+	if err := h.removeServer(ctx, serverName); err != nil {
+		return createErrorResult(err.Error()), nil, nil
 	}
 
 	resultText := fmt.Sprintf("Server '%s' removed successfully!\n\nServer URL: %s\nAll tools and metadata deleted from MongoDB and Qdrant.",
@@ -683,6 +690,20 @@ func (h *ToolsDiscoveryHandler) HandleMCPRemoveServer(ctx context.Context, args
 	}, nil
 }
 
+// removeServer deletes a server's tools and then the server itself
+func (h *ToolsDiscoveryHandler) removeServer(ctx context.Context, serverName string) error {
+	// Remove all tools for this server
+	if err := h.toolsStorage.RemoveServerTools(ctx, serverName); err != nil {
+		return fmt.Errorf("failed to remove server tools: %w", err)
+	}
+
+	// Remove server from registry
+	if err := h.toolsStorage.RemoveServer(ctx, serverName); err != nil {
+		return fmt.Errorf("failed to remove server: %w", err)
+	}
+	return nil
+}
+
 // discoverServerTools connects to an MCP server and lists its tools
 func (h *ToolsDiscoveryHandler) discoverServerTools(ctx context.Context, serverURL string, headers map[string]interface{}) ([]map[string]interface{}, error) {
 	// Create MCP tools/list request
@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
+	"hyper/internal/confirm"
 	"hyper/internal/mcp/storage"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -18,15 +22,27 @@ type ToolMetadataForIndexing struct {
 	Schema      map[string]interface{}
 }
 
-// ToolMetadataRegistry collects tool metadata during registration for later indexing
+// ToolMetadataRegistry collects tool metadata during registration for later indexing.
+// Tools registered through RegisterToolWithServer also keep their handler, so the
+// REST layer can invoke them in-process (see Invoke).
 type ToolMetadataRegistry struct {
 	tools []ToolMetadataForIndexing
+
+	handlersMu sync.RWMutex
+	handlers   map[string]registeredTool
+}
+
+// registeredTool is a tool definition with the handler serving it
+type registeredTool struct {
+	tool    *mcp.Tool
+	handler mcp.ToolHandler
 }
 
 // NewToolMetadataRegistry creates a new tool metadata registry
 func NewToolMetadataRegistry() *ToolMetadataRegistry {
 	return &ToolMetadataRegistry{
-		tools: make([]ToolMetadataForIndexing, 0),
+		tools:    make([]ToolMetadataForIndexing, 0),
+		handlers: make(map[string]registeredTool),
 	}
 }
 
@@ -51,11 +67,17 @@ func (r *ToolMetadataRegistry) RegisterToolWithServer(
 	tool *mcp.Tool,
 	handler mcp.ToolHandler,
 ) {
+	handler = forwardProgress(confirm.Middleware(guardArgumentSizes(normalizeArgumentNames(tool, describeInvalidArguments(tool, handler)))))
+
 	// Register with MCP server
 	server.AddTool(tool, handler)
 
-	// Report to metadata registry for indexing
+	// Report to metadata registry for indexing and in-process invocation
 	if r != nil {
+		r.handlersMu.Lock()
+		r.handlers[tool.Name] = registeredTool{tool: tool, handler: handler}
+		r.handlersMu.Unlock()
+
 		r.RegisterTool(
 			tool.Name,
 			tool.Description,
@@ -69,6 +91,69 @@ func (r *ToolMetadataRegistry) RegisterToolWithServer(
 	}
 }
 
+// NotifyToolListChanged sends tools/list_changed to every session of server.
+// The SDK only notifies when tools change, so a registered tool is re-added
+// unchanged; sessions then list tools again through the receiving middleware.
+func (r *ToolMetadataRegistry) NotifyToolListChanged(server *mcp.Server) {
+	if r == nil {
+		return
+	}
+	r.handlersMu.RLock()
+	var first *registeredTool
+	for name, registered := range r.handlers {
+		if first == nil || name < first.tool.Name {
+			registered := registered
+			first = &registered
+		}
+	}
+	r.handlersMu.RUnlock()
+	if first != nil {
+		server.AddTool(first.tool, first.handler)
+	}
+}
+
+// Tool returns the definition of a tool registered with its handler
+func (r *ToolMetadataRegistry) Tool(name string) (*mcp.Tool, bool) {
+	r.handlersMu.RLock()
+	defer r.handlersMu.RUnlock()
+	registered, ok := r.handlers[name]
+	return registered.tool, ok
+}
+
+// Tools returns the definitions of all tools registered with their handler, by name
+func (r *ToolMetadataRegistry) Tools() []*mcp.Tool {
+	r.handlersMu.RLock()
+	defer r.handlersMu.RUnlock()
+	tools := make([]*mcp.Tool, 0, len(r.handlers))
+	for _, registered := range r.handlers {
+		tools = append(tools, registered.tool)
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+	return tools
+}
+
+// Invoke calls a registered tool handler in-process, without an MCP session
+// or the server's receiving middleware; callers are responsible for RBAC
+func (r *ToolMetadataRegistry) Invoke(ctx context.Context, name string, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	r.handlersMu.RLock()
+	registered, ok := r.handlers[name]
+	r.handlersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("tool not found: %s", name)
+	}
+
+	rawArgs, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tool arguments: %w", err)
+	}
+
+	return registered.handler(ctx, &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: name, Arguments: rawArgs},
+	})
+}
+
 // IndexRegisteredTools indexes all tools from the registry into ToolsStorage
 // This makes the tools discoverable via the discover_tools MCP tool
 func IndexRegisteredTools(registry *ToolMetadataRegistry, toolsStorage *storage.ToolsStorage, logger *zap.Logger) (int, error) {
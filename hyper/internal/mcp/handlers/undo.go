@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/i18n"
+	"hyper/internal/undo"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// stagedOperationResult reports a staged operation to the caller
+func stagedOperationResult(ctx context.Context, op undo.StagedOperation) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: i18n.T(ctx, "operation.staged",
+				op.Description, op.UndoToken, op.ExpiresAt.Format(time.RFC3339))},
+		},
+		StructuredContent: map[string]interface{}{
+			"staged":    true,
+			"operation": op,
+		},
+	}
+}
+
+// SetUndoManager stages destructive tools (coordinator_clear_task_board, knowledge
+// environment deletion) for the manager's undo window and enables coordinator_undo
+// and coordinator_confirm_operation
+func (h *ToolHandler) SetUndoManager(manager *undo.Manager) {
+	h.undoManager = manager
+}
+
+// registerUndo registers the coordinator_undo tool
+func (h *ToolHandler) registerUndo(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_undo",
+		Description: "Cancel a staged destructive operation (e.g. coordinator_clear_task_board, mcp_remove_server) before its undo window expires. Call without undoToken to list staged operations.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"undoToken": {
+					Type:        "string",
+					Description: "Undo token returned when the operation was staged",
+				},
+			},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleUndo(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// registerConfirmOperation registers the coordinator_confirm_operation tool
+func (h *ToolHandler) registerConfirmOperation(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_confirm_operation",
+		Description: "Commit a staged destructive operation now instead of waiting for its undo window to expire. ⚠️ Cannot be undone afterwards.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"undoToken": {
+					Type:        "string",
+					Description: "Undo token returned when the operation was staged",
+				},
+			},
+			Required: []string{"undoToken"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleConfirmOperation(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleUndo handles the coordinator_undo tool call
+func (h *ToolHandler) handleUndo(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if !h.undoManager.Enabled() {
+		return createErrorResult(fmt.Sprintf("undo is disabled: destructive operations run immediately when %s=0", undo.WindowEnv)), nil, nil
+	}
+
+	token, _ := args["undoToken"].(string)
+	if token == "" {
+		ops := h.undoManager.List()
+		jsonData, err := json.Marshal(map[string]interface{}{
+			"operations": ops,
+			"count":      len(ops),
+		})
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to serialize staged operations: %s", err.Error())), nil, nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: string(jsonData)},
+			},
+		}, ops, nil
+	}
+
+	op, err := h.undoManager.Undo(token)
+	if err != nil {
+		return createCodedErrorResult(errcode.Of(err), err.Error()), nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: i18n.T(ctx, "operation.undone", op.Description)},
+		},
+	}, op, nil
+}
+
+// handleConfirmOperation handles the coordinator_confirm_operation tool call
+func (h *ToolHandler) handleConfirmOperation(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if !h.undoManager.Enabled() {
+		return createErrorResult(fmt.Sprintf("undo is disabled: destructive operations run immediately when %s=0", undo.WindowEnv)), nil, nil
+	}
+
+	token, ok := args["undoToken"].(string)
+	if !ok || token == "" {
+		return createErrorResult("undoToken parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	result, err := h.undoManager.Confirm(ctx, token)
+	if err != nil {
+		return createErrorResult(err.Error()), nil, nil
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: i18n.T(ctx, "operation.committed", string(resultJSON))},
+		},
+	}, result, nil
+}
@@ -0,0 +1,395 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultSearchLimit      = 10
+	maxSearchLimit          = 50
+	maxSearchCollections    = 10  // Knowledge collections searched without a collections argument
+	maxTaskSearchCandidates = 50  // Tasks re-scored with embeddings after term matching
+	maxSearchSnippetChars   = 300 // Longer snippets are truncated
+)
+
+// Search scopes of coordinator_search
+const (
+	scopeKnowledge = "knowledge"
+	scopeCode      = "code"
+	scopeTasks     = "tasks"
+	scopeTools     = "tools"
+)
+
+// searchScopes lists every scope, in the order results are reported
+var searchScopes = []string{scopeKnowledge, scopeCode, scopeTasks, scopeTools}
+
+// ToolSearcher searches the registered MCP tools (implemented by *storage.ToolsStorage)
+type ToolSearcher interface {
+	SearchTools(ctx context.Context, query string, limit int) ([]*storage.ToolMatch, error)
+}
+
+// searchHit is one typed result of coordinator_search
+type searchHit struct {
+	Type    string  `json:"type"` // knowledge, code, task or tool
+	ID      string  `json:"id"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet,omitempty"`
+	Score   float64 `json:"score"`
+	Source  string  `json:"source,omitempty"` // Collection, folder, agent or MCP server
+	URI     string  `json:"uri,omitempty"`    // Resource to read for the full item
+}
+
+// SetUnifiedSearch enables the code and tools scopes of coordinator_search
+func (h *ToolHandler) SetUnifiedSearch(code CodeSearcher, tools ToolSearcher) {
+	h.codeSearcher = code
+	h.toolSearcher = tools
+}
+
+// registerUnifiedSearch registers the coordinator_search tool
+func (h *ToolHandler) registerUnifiedSearch(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "coordinator_search",
+		Description: "Search knowledge, code, tasks and tools with one query when you don't know where the answer lives. Returns one list of typed hits (knowledge, code, task, tool) ordered by similarity score, each with an ID, title, snippet, source and, where available, a resource URI for the full item.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"query": {
+					Type:        "string",
+					Description: "Natural language search query",
+				},
+				"scope": {
+					Type:        "string",
+					Description: "Optional: indexes to search, as a mask such as 'knowledge|code' (default: 'knowledge|code|tasks|tools')",
+				},
+				"collections": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: fmt.Sprintf("Optional: knowledge collections to search (default: the %d most used)", maxSearchCollections),
+				},
+				"limit": {
+					Type:        "number",
+					Description: fmt.Sprintf("Maximum number of merged results (default: %d, max: %d)", defaultSearchLimit, maxSearchLimit),
+				},
+			},
+			Required: []string{"query"},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		result, _, err := h.handleUnifiedSearch(ctx, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleUnifiedSearch handles the coordinator_search tool call
+func (h *ToolHandler) handleUnifiedSearch(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	query := strings.TrimSpace(getStringField(args, "query", ""))
+	if query == "" {
+		return createCodedErrorResult(errcode.Validation, "query parameter is required and must be a non-empty string"), nil, nil
+	}
+
+	scopes, err := parseSearchScope(getStringField(args, "scope", ""))
+	if err != nil {
+		return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+	}
+
+	limit := defaultSearchLimit
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+		if limit > maxSearchLimit {
+			limit = maxSearchLimit
+		}
+	}
+
+	var collections []string
+	if raw, ok := args["collections"]; ok {
+		if collections, err = parseStringList(raw, "collections"); err != nil {
+			return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+		}
+	}
+
+	searches := map[string]func() ([]searchHit, error){
+		scopeKnowledge: func() ([]searchHit, error) { return h.searchKnowledgeScope(ctx, query, collections, limit) },
+		scopeCode:      func() ([]searchHit, error) { return h.searchCodeScope(query, limit) },
+		scopeTasks:     func() ([]searchHit, error) { return h.searchTaskScope(query, limit), nil },
+		scopeTools:     func() ([]searchHit, error) { return h.searchToolScope(ctx, query, limit) },
+	}
+
+	// Fan out to every requested index; one failing index does not sink the others
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var hits []searchHit
+	counts := make(map[string]int, len(scopes))
+	failures := make(map[string]string)
+	for _, scope := range scopes {
+		wg.Add(1)
+		go func(scope string) {
+			defer wg.Done()
+			found, err := searches[scope]()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures[scope] = err.Error()
+				return
+			}
+			counts[scope] = len(found)
+			hits = append(hits, found...)
+		}(scope)
+	}
+	wg.Wait()
+
+	if len(hits) == 0 && len(failures) == len(scopes) {
+		return createErrorResult(fmt.Sprintf("search failed in every scope: %v", failures)), nil, nil
+	}
+
+	hits = mergeSearchHits(hits, limit)
+	response := map[string]interface{}{
+		"query":   query,
+		"scopes":  scopes,
+		"results": hits,
+		"count":   len(hits),
+		"matched": counts,
+	}
+	if len(failures) > 0 {
+		response["errors"] = failures
+	}
+	return structuredToolResult(response), response, nil
+}
+
+// parseSearchScope reads a scope mask such as "knowledge|code"; empty selects
+// every scope
+func parseSearchScope(mask string) ([]string, error) {
+	if strings.TrimSpace(mask) == "" {
+		return searchScopes, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, part := range strings.FieldsFunc(mask, func(r rune) bool { return r == '|' || r == ',' || r == ' ' }) {
+		scope := strings.ToLower(part)
+		if scope == "task" || scope == "tool" {
+			scope += "s"
+		}
+		if !slices.Contains(searchScopes, scope) {
+			return nil, fmt.Errorf("unknown search scope '%s': use %s", part, strings.Join(searchScopes, "|"))
+		}
+		selected[scope] = true
+	}
+
+	scopes := make([]string, 0, len(selected))
+	for _, scope := range searchScopes {
+		if selected[scope] {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// mergeSearchHits orders hits from every scope by score and keeps the top limit
+func mergeSearchHits(hits []searchHit, limit int) []searchHit {
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	if hits == nil {
+		hits = []searchHit{}
+	}
+	return hits
+}
+
+// searchKnowledgeScope queries the given knowledge collections, or the most
+// used ones, keeping each entry once
+func (h *ToolHandler) searchKnowledgeScope(ctx context.Context, query string, collections []string, limit int) ([]searchHit, error) {
+	if len(collections) == 0 {
+		popular, err := h.knowledgeStorage.GetPopularCollections(maxSearchCollections)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list knowledge collections: %w", err)
+		}
+		for _, stats := range popular {
+			collections = append(collections, stats.Collection)
+		}
+	}
+
+	var hits []searchHit
+	var lastErr error
+	seen := make(map[string]bool)
+	for _, collection := range collections {
+		results, err := storage.QueryKnowledgeContext(ctx, h.knowledgeStorage, collection, query, limit)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, result := range results {
+			if result.Entry == nil || seen[result.Entry.ID] {
+				continue
+			}
+			seen[result.Entry.ID] = true
+			hits = append(hits, searchHit{
+				Type:    "knowledge",
+				ID:      result.Entry.ID,
+				Title:   firstLine(result.Entry.Text),
+				Snippet: truncateText(result.Entry.Text, maxSearchSnippetChars),
+				Score:   result.Score,
+				Source:  collection,
+			})
+		}
+	}
+	if len(hits) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return hits, nil
+}
+
+// searchCodeScope searches the code index of every indexed folder
+func (h *ToolHandler) searchCodeScope(query string, limit int) ([]searchHit, error) {
+	if h.codeSearcher == nil {
+		return nil, fmt.Errorf("code search is not configured")
+	}
+	results, err := h.codeSearcher.SearchCode(query, "", limit)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]searchHit, 0, len(results))
+	for _, result := range results {
+		title := result.RelativePath
+		if title == "" {
+			title = result.FilePath
+		}
+		if result.StartLine > 0 {
+			title = fmt.Sprintf("%s:%d-%d", title, result.StartLine, result.EndLine)
+		}
+		hits = append(hits, searchHit{
+			Type:    "code",
+			ID:      fmt.Sprintf("%s#%d", result.FilePath, result.ChunkNum),
+			Title:   title,
+			Snippet: truncateText(result.Content, maxSearchSnippetChars),
+			Score:   float64(result.Score),
+			Source:  result.FolderPath,
+		})
+	}
+	return hits, nil
+}
+
+// searchTaskScope matches human and agent tasks by their terms, then re-scores
+// the best candidates with embeddings when a client is available
+func (h *ToolHandler) searchTaskScope(query string, limit int) []searchHit {
+	var candidates []searchHit
+	var texts []string
+	queryTerms := termVector(query)
+	for _, task := range h.taskStorage.ListAllHumanTasks() {
+		text := strings.TrimSpace(task.Prompt + "\n" + task.Notes)
+		candidates = append(candidates, searchHit{
+			Type:    "task",
+			ID:      task.ID,
+			Title:   firstLine(task.Prompt),
+			Snippet: truncateText(task.Prompt, maxSearchSnippetChars),
+			Score:   termCosine(queryTerms, termVector(text)),
+			Source:  string(task.Status),
+			URI:     fmt.Sprintf("hyperion://task/human/%s", task.ID),
+		})
+		texts = append(texts, text)
+	}
+	for _, task := range h.taskStorage.ListAllAgentTasks() {
+		text := agentTaskSearchText(task)
+		candidates = append(candidates, searchHit{
+			Type:    "task",
+			ID:      task.ID,
+			Title:   fmt.Sprintf("%s: %s", task.AgentName, task.Role),
+			Snippet: truncateText(text, maxSearchSnippetChars),
+			Score:   termCosine(queryTerms, termVector(text)),
+			Source:  task.AgentName,
+			URI:     fmt.Sprintf("hyperion://task/agent/%s/%s", task.AgentName, task.ID),
+		})
+		texts = append(texts, text)
+	}
+
+	// Keep the tasks sharing terms with the query, best first
+	order := make([]int, 0, len(candidates))
+	for i := range candidates {
+		if candidates[i].Score > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return candidates[order[a]].Score > candidates[order[b]].Score
+	})
+	if len(order) > maxTaskSearchCandidates {
+		order = order[:maxTaskSearchCandidates]
+	}
+
+	hits := make([]searchHit, 0, len(order))
+	selectedTexts := make([]string, 0, len(order))
+	for _, i := range order {
+		hits = append(hits, candidates[i])
+		selectedTexts = append(selectedTexts, texts[i])
+	}
+	if similarities := textSimilarities(query, selectedTexts, h.embeddingClient); similarities != nil {
+		for i := range hits {
+			hits[i].Score = similarities[i]
+		}
+	}
+
+	return mergeSearchHits(hits, limit)
+}
+
+// agentTaskSearchText is the text of an agent task matched by coordinator_search
+func agentTaskSearchText(task *storage.AgentTask) string {
+	parts := []string{task.Role, task.ContextSummary, task.Notes}
+	for _, todo := range task.Todos {
+		parts = append(parts, todo.Description)
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// searchToolScope searches the registered MCP tools
+func (h *ToolHandler) searchToolScope(ctx context.Context, query string, limit int) ([]searchHit, error) {
+	if h.toolSearcher == nil {
+		return nil, fmt.Errorf("tool search is not configured")
+	}
+	matches, err := h.toolSearcher.SearchTools(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]searchHit, 0, len(matches))
+	for _, match := range matches {
+		hits = append(hits, searchHit{
+			Type:    "tool",
+			ID:      match.ToolName,
+			Title:   match.ToolName,
+			Snippet: truncateText(match.Description, maxSearchSnippetChars),
+			Score:   match.Score,
+			Source:  match.ServerName,
+		})
+	}
+	return hits, nil
+}
+
+// firstLine returns the first non-empty line of text, shortened for a title
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return truncateText(line, 120)
+		}
+	}
+	return ""
+}
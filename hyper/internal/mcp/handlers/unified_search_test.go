@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// searchKnowledgeStorage returns fixed query results per collection
+type searchKnowledgeStorage struct {
+	storage.KnowledgeStorage
+	results map[string][]*storage.QueryResult
+}
+
+func (s *searchKnowledgeStorage) Query(collection, query string, limit int) ([]*storage.QueryResult, error) {
+	return s.results[collection], nil
+}
+
+func (s *searchKnowledgeStorage) GetPopularCollections(limit int) ([]*storage.CollectionStats, error) {
+	return []*storage.CollectionStats{{Collection: "adr", Count: 2}}, nil
+}
+
+// searchTaskStorage serves a fixed set of tasks
+type searchTaskStorage struct {
+	storage.TaskStorage
+	human []*storage.HumanTask
+	agent []*storage.AgentTask
+}
+
+func (s *searchTaskStorage) ListAllHumanTasks() []*storage.HumanTask { return s.human }
+func (s *searchTaskStorage) ListAllAgentTasks() []*storage.AgentTask { return s.agent }
+
+// stubCodeSearcher returns fixed code hits
+type stubCodeSearcher struct {
+	results []*storage.SearchResult
+	err     error
+}
+
+func (f *stubCodeSearcher) SearchCode(query, folderPath string, limit int) ([]*storage.SearchResult, error) {
+	return f.results, f.err
+}
+
+// fakeToolSearcher returns fixed tool matches
+type fakeToolSearcher struct {
+	matches []*storage.ToolMatch
+}
+
+func (f *fakeToolSearcher) SearchTools(ctx context.Context, query string, limit int) ([]*storage.ToolMatch, error) {
+	return f.matches, nil
+}
+
+func newUnifiedSearchHandler(code CodeSearcher) *ToolHandler {
+	knowledge := &searchKnowledgeStorage{results: map[string][]*storage.QueryResult{
+		"adr": {{Entry: &storage.KnowledgeEntry{ID: "adr-1", Collection: "adr", Text: "Retries\nWe retry failed webhooks three times."}, Score: 0.7}},
+	}}
+	tasks := &searchTaskStorage{
+		human: []*storage.HumanTask{
+			{ID: "h-1", Prompt: "Fix webhook retry backoff", Status: storage.TaskStatusCompleted},
+			{ID: "h-2", Prompt: "Redesign the login page"},
+		},
+		agent: []*storage.AgentTask{
+			{ID: "a-1", AgentName: "backend", Role: "Implement webhook retry queue"},
+		},
+	}
+	handler := NewToolHandler(tasks, knowledge, nil)
+	handler.SetUnifiedSearch(code, &fakeToolSearcher{matches: []*storage.ToolMatch{
+		{ToolName: "webhook_replay", Description: "Replay a failed webhook", ServerName: "ops", Score: 0.5},
+	}})
+	return handler
+}
+
+func TestHandleUnifiedSearch_MergesScopes(t *testing.T) {
+	code := &stubCodeSearcher{results: []*storage.SearchResult{
+		{FilePath: "/repo/webhooks/retry.go", RelativePath: "webhooks/retry.go", FolderPath: "/repo", StartLine: 10, EndLine: 40, Score: 0.9},
+	}}
+	handler := newUnifiedSearchHandler(code)
+
+	result, payload, err := handler.handleUnifiedSearch(context.Background(), map[string]interface{}{
+		"query": "webhook retry",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	response := payload.(map[string]interface{})
+	hits := response["results"].([]searchHit)
+	require.NotEmpty(t, hits)
+	assert.Equal(t, "code", hits[0].Type)
+	assert.Equal(t, "webhooks/retry.go:10-40", hits[0].Title)
+
+	types := map[string]bool{}
+	for i, hit := range hits {
+		types[hit.Type] = true
+		if hit.Type == "knowledge" {
+			assert.Equal(t, "Retries", hit.Title)
+			assert.Equal(t, "adr", hit.Source)
+		}
+		if i > 0 {
+			assert.GreaterOrEqual(t, hits[i-1].Score, hit.Score, "hits are ordered by score")
+		}
+		assert.NotEqual(t, "h-2", hit.ID, "tasks sharing no terms are left out")
+	}
+	assert.Equal(t, map[string]bool{"knowledge": true, "code": true, "task": true, "tool": true}, types)
+}
+
+func TestHandleUnifiedSearch_ScopeMask(t *testing.T) {
+	handler := newUnifiedSearchHandler(&stubCodeSearcher{err: errors.New("qdrant down")})
+
+	_, payload, err := handler.handleUnifiedSearch(context.Background(), map[string]interface{}{
+		"query": "webhook retry",
+		"scope": "tasks|code",
+		"limit": float64(1),
+	})
+	require.NoError(t, err)
+	response := payload.(map[string]interface{})
+	assert.Equal(t, []string{"code", "tasks"}, response["scopes"])
+	hits := response["results"].([]searchHit)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "task", hits[0].Type)
+	assert.Equal(t, map[string]string{"code": "qdrant down"}, response["errors"])
+
+	result, _, err := handler.handleUnifiedSearch(context.Background(), map[string]interface{}{
+		"query": "webhook retry",
+		"scope": "knowledge|email",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestParseSearchScope(t *testing.T) {
+	scopes, err := parseSearchScope("")
+	require.NoError(t, err)
+	assert.Equal(t, searchScopes, scopes)
+
+	scopes, err = parseSearchScope("Tools | task,knowledge")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"knowledge", "tasks", "tools"}, scopes)
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"hyper/internal/mcp/storage"
+)
+
+// VectorTierStatus reports whether a knowledge collection's vectors are
+// archived; implemented by *vectortier.Tier
+type VectorTierStatus interface {
+	Status(collection string) *storage.VectorArchive
+}
+
+// SetVectorTier makes knowledge queries report archived collections
+func (h *ToolHandler) SetVectorTier(tier VectorTierStatus) {
+	h.vectorTier = tier
+}
+
+// archivedCollection returns the tier record of collection when its vectors
+// are archived or being restored, or nil
+func (h *ToolHandler) archivedCollection(collection string) *storage.VectorArchive {
+	if h.vectorTier == nil {
+		return nil
+	}
+	return h.vectorTier.Status(collection)
+}
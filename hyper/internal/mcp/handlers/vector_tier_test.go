@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedVectorTier reports fixed tier records
+type fixedVectorTier map[string]*storage.VectorArchive
+
+func (f fixedVectorTier) Status(collection string) *storage.VectorArchive {
+	return f[collection]
+}
+
+func TestHandleQueryKnowledge_ReportsArchivedCollection(t *testing.T) {
+	handler := newAnswerTestHandler(nil)
+	handler.SetVectorTier(fixedVectorTier{
+		"adr": {Collection: "adr", Tier: storage.VectorTierRehydrating, Points: 2},
+	})
+
+	result, _, err := handler.handleQueryKnowledge(context.Background(), map[string]interface{}{
+		"collection": "adr",
+		"query":      "task storage",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var response struct {
+		Results []map[string]interface{} `json:"results"`
+		Count   int                      `json:"count"`
+		Archive *storage.VectorArchive   `json:"archive"`
+		Message string                   `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, storage.VectorTierRehydrating, response.Archive.Tier)
+	assert.Contains(t, response.Message, "until its vectors are restored")
+
+	// Hot collections keep the array format
+	result, _, err = handler.handleQueryKnowledge(context.Background(), map[string]interface{}{
+		"collection": "technical-knowledge",
+		"query":      "task storage",
+	})
+	require.NoError(t, err)
+	var entries []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &entries))
+	assert.Len(t, entries, 2)
+}
@@ -66,7 +66,7 @@ func (h *WorkflowResourceHandler) RegisterWorkflowResources(server *mcp.Server)
 		Description: "Real-time status of all agents (working, blocked, idle)",
 		MIMEType:    "application/json",
 	}
-	server.AddResource(activeAgentsResource, h.handleActiveAgents)
+	addPaginatedResource(server, activeAgentsResource, h.handleActiveAgents)
 
 	// Register task-queue resource
 	taskQueueResource := &mcp.Resource{
@@ -75,7 +75,7 @@ func (h *WorkflowResourceHandler) RegisterWorkflowResources(server *mcp.Server)
 		Description: "Pending tasks ordered by priority and creation time",
 		MIMEType:    "application/json",
 	}
-	server.AddResource(taskQueueResource, h.handleTaskQueue)
+	addPaginatedResource(server, taskQueueResource, h.handleTaskQueue)
 
 	// Register dependencies resource
 	dependenciesResource := &mcp.Resource{
@@ -84,13 +84,18 @@ func (h *WorkflowResourceHandler) RegisterWorkflowResources(server *mcp.Server)
 		Description: "Task dependency graph showing blocking relationships",
 		MIMEType:    "application/json",
 	}
-	server.AddResource(dependenciesResource, h.handleDependencies)
+	addPaginatedResource(server, dependenciesResource, h.handleDependencies)
 
 	return nil
 }
 
 // handleActiveAgents computes and returns active agent status
 func (h *WorkflowResourceHandler) handleActiveAgents(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	page, err := parseResourcePage(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
 	allAgentTasks := h.taskStorage.ListAllAgentTasks()
 
 	// Group tasks by agent name
@@ -167,10 +172,13 @@ func (h *WorkflowResourceHandler) handleActiveAgents(ctx context.Context, req *m
 		return agentStatuses[i].AgentName < agentStatuses[j].AgentName
 	})
 
+	agentStatusesPage, pagination := paginate(agentStatuses, page)
+
 	jsonData, err := json.MarshalIndent(map[string]interface{}{
-		"agents":     agentStatuses,
+		"agents":     agentStatusesPage,
 		"totalCount": len(agentStatuses),
 		"timestamp":  time.Now().UTC(),
+		"pagination": pagination,
 	}, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal agent status: %w", err)
@@ -189,7 +197,16 @@ func (h *WorkflowResourceHandler) handleActiveAgents(ctx context.Context, req *m
 
 // handleTaskQueue returns pending tasks ordered by priority
 func (h *WorkflowResourceHandler) handleTaskQueue(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	page, err := parseResourcePage(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
 	allAgentTasks := h.taskStorage.ListAllAgentTasks()
+	humanTasks := make(map[string]*storage.HumanTask)
+	for _, human := range h.taskStorage.ListAllHumanTasks() {
+		humanTasks[human.ID] = human
+	}
 
 	// Filter pending tasks
 	pendingTasks := make([]TaskQueueItem, 0)
@@ -199,7 +216,7 @@ func (h *WorkflowResourceHandler) handleTaskQueue(ctx context.Context, req *mcp.
 				TaskID:      task.ID,
 				AgentName:   task.AgentName,
 				Role:        task.Role,
-				Priority:    calculatePriority(task),
+				Priority:    calculatePriority(task, humanTasks[task.HumanTaskID]),
 				CreatedAt:   task.CreatedAt,
 				TodoCount:   len(task.Todos),
 				HumanTaskID: task.HumanTaskID,
@@ -213,13 +230,19 @@ func (h *WorkflowResourceHandler) handleTaskQueue(ctx context.Context, req *mcp.
 		if pendingTasks[i].Priority != pendingTasks[j].Priority {
 			return pendingTasks[i].Priority > pendingTasks[j].Priority
 		}
-		return pendingTasks[i].CreatedAt.Before(pendingTasks[j].CreatedAt)
+		if !pendingTasks[i].CreatedAt.Equal(pendingTasks[j].CreatedAt) {
+			return pendingTasks[i].CreatedAt.Before(pendingTasks[j].CreatedAt)
+		}
+		return pendingTasks[i].TaskID < pendingTasks[j].TaskID
 	})
 
+	pendingTasksPage, pagination := paginate(pendingTasks, page)
+
 	jsonData, err := json.MarshalIndent(map[string]interface{}{
-		"queue":      pendingTasks,
+		"queue":      pendingTasksPage,
 		"totalCount": len(pendingTasks),
 		"timestamp":  time.Now().UTC(),
+		"pagination": pagination,
 	}, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal task queue: %w", err)
@@ -238,6 +261,11 @@ func (h *WorkflowResourceHandler) handleTaskQueue(ctx context.Context, req *mcp.
 
 // handleDependencies analyzes and returns task dependency graph
 func (h *WorkflowResourceHandler) handleDependencies(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	page, err := parseResourcePage(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
 	allAgentTasks := h.taskStorage.ListAllAgentTasks()
 
 	// Build dependency graph
@@ -285,10 +313,13 @@ func (h *WorkflowResourceHandler) handleDependencies(ctx context.Context, req *m
 		dependencies = append(dependencies, dep)
 	}
 
+	dependenciesPage, pagination := paginate(dependencies, page)
+
 	jsonData, err := json.MarshalIndent(map[string]interface{}{
-		"dependencies": dependencies,
+		"dependencies": dependenciesPage,
 		"totalCount":   len(dependencies),
 		"timestamp":    time.Now().UTC(),
+		"pagination":   pagination,
 	}, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal dependencies: %w", err)
@@ -305,10 +336,15 @@ func (h *WorkflowResourceHandler) handleDependencies(ctx context.Context, req *m
 	}, nil
 }
 
-// calculatePriority determines task priority based on various factors
-func calculatePriority(task *storage.AgentTask) int {
+// calculatePriority determines task priority based on various factors. parent
+// may be nil.
+func calculatePriority(task *storage.AgentTask, parent *storage.HumanTask) int {
 	priority := 0
 
+	// The task's priority (own, its human task's, or inherited) outweighs the
+	// other factors
+	priority += storage.AgentTaskPriority(task, parent).Rank() * 1000
+
 	// Base priority on TODO count (more TODOs = higher priority)
 	priority += len(task.Todos) * 10
 
@@ -46,6 +46,26 @@ func (m *MockWorkflowTaskStorage) ListAllAgentTasks() []*storage.AgentTask {
 	return m.agentTasks
 }
 
+func (m *MockWorkflowTaskStorage) ListAgentTasksByStatus(status storage.TaskStatus) ([]*storage.AgentTask, error) {
+	var tasks []*storage.AgentTask
+	for _, task := range m.agentTasks {
+		if task.Status == status {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (m *MockWorkflowTaskStorage) ListAgentTasksByHumanTask(humanTaskID string) ([]*storage.AgentTask, error) {
+	var tasks []*storage.AgentTask
+	for _, task := range m.agentTasks {
+		if task.HumanTaskID == humanTaskID {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
 func (m *MockWorkflowTaskStorage) UpdateTaskStatus(taskID string, status storage.TaskStatus, notes string) error {
 	return nil
 }
@@ -54,6 +74,18 @@ func (m *MockWorkflowTaskStorage) UpdateTodoStatus(agentTaskID, todoID string, s
 	return nil
 }
 
+func (m *MockWorkflowTaskStorage) UpdateChecklistItemStatus(agentTaskID, todoID, itemID string, status storage.TodoStatus, notes string) error {
+	return nil
+}
+
+func (m *MockWorkflowTaskStorage) UpdateTodoTime(agentTaskID, todoID string, estimatedMinutes, actualMinutes *int) error {
+	return nil
+}
+
+func (m *MockWorkflowTaskStorage) GetAgentTaskActivity(agentTaskID string) ([]storage.TaskActivity, error) {
+	return nil, nil
+}
+
 func (m *MockWorkflowTaskStorage) AddTaskPromptNotes(agentTaskID string, notes string) error {
 	return nil
 }
@@ -82,6 +114,18 @@ func (m *MockWorkflowTaskStorage) ClearAllTasks() (*storage.ClearResult, error)
 	return nil, nil
 }
 
+func (m *MockWorkflowTaskStorage) AppendTaskChangeLog(agentTaskID string, entry storage.FileChangeEntry) error {
+	return nil
+}
+
+func (m *MockWorkflowTaskStorage) CloneHumanTask(sourceTaskID, project, prompt string) (*storage.HumanTask, []*storage.AgentTask, error) {
+	return nil, nil, nil
+}
+
+func (m *MockWorkflowTaskStorage) SetTaskDueDate(taskID string, dueAt *time.Time) error {
+	return nil
+}
+
 func TestWorkflowResourceHandler_ActiveAgents(t *testing.T) {
 	now := time.Now().UTC()
 
@@ -248,6 +292,44 @@ func TestWorkflowResourceHandler_TaskQueue(t *testing.T) {
 	assert.Equal(t, "High priority task", firstTask["role"])
 }
 
+func TestWorkflowResourceHandler_TaskQueuePriority(t *testing.T) {
+	now := time.Now().UTC()
+
+	mockStorage := &MockWorkflowTaskStorage{
+		agentTasks: []*storage.AgentTask{
+			{
+				ID:             "task-1",
+				AgentName:      "go-mcp-dev",
+				Role:           "Well-prepared task",
+				Status:         storage.TaskStatusPending,
+				CreatedAt:      now.Add(-2 * time.Hour),
+				ContextSummary: "Complete context provided",
+				Todos:          []storage.TodoItem{{ID: "1"}, {ID: "2"}, {ID: "3"}},
+			},
+			{
+				ID:                "task-2",
+				AgentName:         "ui-dev",
+				Role:              "Task blocking urgent work",
+				Status:            storage.TaskStatusPending,
+				CreatedAt:         now.Add(-30 * time.Minute),
+				InheritedPriority: storage.PriorityHigh,
+			},
+		},
+	}
+
+	handler := NewWorkflowResourceHandler(mockStorage)
+	result, err := handler.handleTaskQueue(context.Background(), &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: "hyperion://workflow/task-queue"},
+	})
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &response))
+	queue := response["queue"].([]interface{})
+	require.Len(t, queue, 2)
+	assert.Equal(t, "task-2", queue[0].(map[string]interface{})["taskId"], "priority outweighs the other factors")
+}
+
 func TestWorkflowResourceHandler_Dependencies(t *testing.T) {
 	now := time.Now().UTC()
 
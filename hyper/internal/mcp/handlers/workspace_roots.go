@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"hyper/internal/confirm"
+	"hyper/internal/errcode"
+	"hyper/internal/mcp/storage"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// WorkspaceRootsEnv controls what happens to the workspace roots a client
+// exposes: "ask" (default) registers them for indexing once the user accepts
+// an elicitation prompt, "auto" registers them without asking and "off" only
+// lists them through code_index_workspace_roots
+const WorkspaceRootsEnv = "CODE_INDEX_ROOTS"
+
+// Workspace root modes
+const (
+	WorkspaceRootsAsk  = "ask"
+	WorkspaceRootsAuto = "auto"
+	WorkspaceRootsOff  = "off"
+)
+
+// Workspace root statuses reported by code_index_workspace_roots
+const (
+	rootIndexed     = "indexed"
+	rootRegistered  = "registered"
+	rootUnindexed   = "unindexed"
+	rootDeclined    = "declined"
+	rootUnavailable = "unavailable"
+)
+
+// rootsListTimeout bounds asking a client for its roots and for consent
+const rootsListTimeout = 2 * time.Minute
+
+// WorkspaceFolderStore registers indexed folders; implemented by
+// *storage.CodeIndexStorage
+type WorkspaceFolderStore interface {
+	GetFolderByPath(path string) (*storage.IndexedFolder, error)
+	AddFolder(path, description string) (*storage.IndexedFolder, error)
+}
+
+// WorkspaceFolderWatcher watches and scans registered folders; implemented by
+// *watcher.FileWatcher
+type WorkspaceFolderWatcher interface {
+	AddFolder(folder *storage.IndexedFolder) error
+	ScanFolder(folder *storage.IndexedFolder) error
+}
+
+// rootsLister lists a client's workspace roots; *mcp.ServerSession
+// satisfies it
+type rootsLister interface {
+	ListRoots(ctx context.Context, params *mcp.ListRootsParams) (*mcp.ListRootsResult, error)
+}
+
+// WorkspaceRoot is a client workspace root and its code index status
+type WorkspaceRoot struct {
+	URI    string `json:"uri"`
+	Name   string `json:"name,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// WorkspaceRoots registers the workspace roots of MCP clients as indexed
+// folders, so agents do not have to add them by absolute path
+type WorkspaceRoots struct {
+	folders  WorkspaceFolderStore
+	watcher  WorkspaceFolderWatcher
+	mode     string
+	autoScan bool
+	logger   *zap.Logger
+
+	mu       sync.Mutex
+	declined map[string]bool // paths the user declined, not asked again
+}
+
+// NewWorkspaceRoots creates a workspace root registrar configured from
+// CODE_INDEX_ROOTS and CODE_INDEX_AUTO_SCAN
+func NewWorkspaceRoots(folders WorkspaceFolderStore, watcher WorkspaceFolderWatcher, logger *zap.Logger) *WorkspaceRoots {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv(WorkspaceRootsEnv)))
+	switch mode {
+	case WorkspaceRootsAsk, WorkspaceRootsAuto, WorkspaceRootsOff:
+	default:
+		if mode != "" {
+			logger.Warn("Invalid workspace roots mode, asking before registering roots",
+				zap.String("env", WorkspaceRootsEnv), zap.String("value", mode))
+		}
+		mode = WorkspaceRootsAsk
+	}
+
+	return &WorkspaceRoots{
+		folders:  folders,
+		watcher:  watcher,
+		mode:     mode,
+		autoScan: os.Getenv("CODE_INDEX_AUTO_SCAN") != "false",
+		logger:   logger,
+		declined: make(map[string]bool),
+	}
+}
+
+// HandleInitialized offers the roots of a newly initialized client for
+// indexing; use it as the server's InitializedHandler
+func (w *WorkspaceRoots) HandleInitialized(ctx context.Context, req *mcp.InitializedRequest) {
+	w.offer(ctx, req.Session)
+}
+
+// HandleRootsListChanged offers roots the client added since it initialized;
+// use it as the server's RootsListChangedHandler
+func (w *WorkspaceRoots) HandleRootsListChanged(ctx context.Context, req *mcp.RootsListChangedRequest) {
+	w.offer(ctx, req.Session)
+}
+
+// offer lists the roots of session in the background, because the client
+// answers roots/list and elicitation requests only after this notification
+// has been handled
+func (w *WorkspaceRoots) offer(ctx context.Context, session *mcp.ServerSession) {
+	if w == nil || session == nil || w.mode == WorkspaceRootsOff {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(confirm.WithSession(context.WithoutCancel(ctx), session), rootsListTimeout)
+		defer cancel()
+		w.Sync(ctx, session)
+	}()
+}
+
+// Sync lists the roots of a client and registers the unindexed ones the mode
+// allows: all of them with "auto", those the user confirms with "ask"
+func (w *WorkspaceRoots) Sync(ctx context.Context, client rootsLister) []WorkspaceRoot {
+	roots, err := w.List(ctx, client)
+	if err != nil {
+		// Clients without the roots capability reject roots/list
+		w.logger.Debug("Client workspace roots unavailable", zap.Error(err))
+		return nil
+	}
+
+	var offered []int
+	for i, root := range roots {
+		if root.Status == rootUnindexed {
+			offered = append(offered, i)
+		}
+	}
+	if len(offered) == 0 {
+		return roots
+	}
+
+	if w.mode == WorkspaceRootsAsk {
+		paths := make([]string, len(offered))
+		for i, index := range offered {
+			paths[i] = roots[index].Path
+		}
+		message := fmt.Sprintf("Index these workspace folders for code search?\n%s", strings.Join(paths, "\n"))
+		switch confirm.Ask(ctx, nil, message) {
+		case confirm.Required:
+			// Without elicitation the roots stay offered through code_index_workspace_roots
+			w.logger.Info("Client workspace roots not indexed; register them with code_index_workspace_roots",
+				zap.Strings("paths", paths))
+			return roots
+		case confirm.Declined:
+			w.mu.Lock()
+			for _, index := range offered {
+				w.declined[roots[index].Path] = true
+				roots[index].Status = rootDeclined
+			}
+			w.mu.Unlock()
+			return roots
+		}
+	}
+
+	for _, index := range offered {
+		w.register(&roots[index])
+	}
+	return roots
+}
+
+// List returns the roots of a client with their code index status
+func (w *WorkspaceRoots) List(ctx context.Context, client rootsLister) ([]WorkspaceRoot, error) {
+	result, err := client.ListRoots(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list client roots: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	roots := make([]WorkspaceRoot, 0, len(result.Roots))
+	for _, root := range result.Roots {
+		if root == nil {
+			continue
+		}
+		entry := WorkspaceRoot{URI: root.URI, Name: root.Name, Status: rootUnindexed}
+		path, err := rootPath(root.URI)
+		if err != nil {
+			entry.Status, entry.Error = rootUnavailable, err.Error()
+			roots = append(roots, entry)
+			continue
+		}
+		entry.Path = path
+
+		// A remote coordinator cannot index folders it cannot see
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			entry.Status, entry.Error = rootUnavailable, "folder is not accessible to the coordinator"
+		} else if folder, err := w.folders.GetFolderByPath(path); err != nil {
+			entry.Status, entry.Error = rootUnavailable, err.Error()
+		} else if folder != nil {
+			entry.Status = rootIndexed
+		} else if w.declined[path] {
+			entry.Status = rootDeclined
+		}
+		roots = append(roots, entry)
+	}
+	return roots, nil
+}
+
+// register adds an unindexed root as an indexed folder, watches it and
+// starts its initial scan
+func (w *WorkspaceRoots) register(root *WorkspaceRoot) {
+	folder, err := w.folders.AddFolder(root.Path, "Client workspace root")
+	if err != nil {
+		root.Status, root.Error = rootUnavailable, err.Error()
+		return
+	}
+	root.Status = rootRegistered
+
+	w.mu.Lock()
+	delete(w.declined, root.Path)
+	w.mu.Unlock()
+
+	if w.watcher == nil {
+		return
+	}
+	if err := w.watcher.AddFolder(folder); err != nil {
+		w.logger.Warn("Failed to watch workspace root", zap.String("path", root.Path), zap.Error(err))
+	}
+	if w.autoScan {
+		go func() {
+			if err := w.watcher.ScanFolder(folder); err != nil {
+				w.logger.Warn("Failed to scan workspace root", zap.String("path", root.Path), zap.Error(err))
+			}
+		}()
+	}
+	w.logger.Info("Registered client workspace root for indexing", zap.String("path", root.Path))
+}
+
+// rootPath converts a file:// root URI to a clean absolute path
+func rootPath(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid root URI: %w", err)
+	}
+	if parsed.Scheme != "file" {
+		return "", fmt.Errorf("unsupported root URI scheme %q: only file:// roots can be indexed", parsed.Scheme)
+	}
+	path := filepath.FromSlash(parsed.Path)
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("root URI %s is not an absolute path", uri)
+	}
+	return filepath.Clean(path), nil
+}
+
+// SetWorkspaceRoots enables the code_index_workspace_roots tool
+func (h *CodeToolsHandler) SetWorkspaceRoots(roots *WorkspaceRoots) {
+	h.workspaceRoots = roots
+}
+
+// registerWorkspaceRoots registers the code_index_workspace_roots tool
+func (h *CodeToolsHandler) registerWorkspaceRoots(server *mcp.Server) error {
+	tool := &mcp.Tool{
+		Name:        "code_index_workspace_roots",
+		Description: "List the workspace roots your MCP client exposes and whether each is in the code index. With register: true, registers the unindexed roots (or only those in paths) for indexing and starts their initial scan, so folders never need to be added by absolute path. Calling with register: true is the consent for clients that cannot answer elicitation prompts.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"register": {
+					Type:        "boolean",
+					Description: "Register the unindexed roots for indexing (default: false, list only)",
+				},
+				"paths": {
+					Type:        "array",
+					Items:       &jsonschema.Schema{Type: "string"},
+					Description: "Optional: register only these root paths",
+				},
+			},
+		},
+	}
+
+	h.addToolWithMetadata(server, tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := h.extractArguments(req)
+		if err != nil {
+			return createCodeIndexErrorResult(fmt.Sprintf("failed to extract arguments: %s", err.Error())), nil
+		}
+		if req.Session == nil {
+			return createCodeIndexErrorResult("workspace roots are only available to connected MCP clients"), nil
+		}
+		result, _, err := h.handleWorkspaceRoots(ctx, req.Session, args)
+		return result, err
+	})
+
+	return nil
+}
+
+// handleWorkspaceRoots handles the code_index_workspace_roots tool call
+func (h *CodeToolsHandler) handleWorkspaceRoots(ctx context.Context, client rootsLister, args map[string]interface{}) (*mcp.CallToolResult, interface{}, error) {
+	if h.workspaceRoots == nil {
+		return createCodeIndexErrorResult("workspace roots are unavailable: the code index is not configured"), nil, nil
+	}
+
+	var only []string
+	if raw, ok := args["paths"]; ok {
+		paths, err := parseStringList(raw, "paths")
+		if err != nil {
+			return createCodedErrorResult(errcode.Validation, err.Error()), nil, nil
+		}
+		for _, path := range paths {
+			only = append(only, filepath.Clean(path))
+		}
+	}
+
+	roots, err := h.workspaceRoots.List(ctx, client)
+	if err != nil {
+		return createCodeIndexErrorResult(fmt.Sprintf("%s (the client may not support the MCP roots capability)", err.Error())), nil, nil
+	}
+
+	if register, _ := args["register"].(bool); register {
+		for i := range roots {
+			if roots[i].Status != rootUnindexed && roots[i].Status != rootDeclined {
+				continue
+			}
+			if len(only) > 0 && !slices.Contains(only, roots[i].Path) {
+				continue
+			}
+			h.workspaceRoots.register(&roots[i])
+		}
+	}
+
+	response := map[string]interface{}{"roots": roots, "mode": h.workspaceRoots.mode}
+	return structuredToolResult(response), response, nil
+}
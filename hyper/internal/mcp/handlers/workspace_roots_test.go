@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"hyper/internal/confirm"
+	"hyper/internal/mcp/storage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeRootsClient exposes fixed workspace roots
+type fakeRootsClient struct {
+	roots []*mcp.Root
+	err   error
+}
+
+func (c *fakeRootsClient) ListRoots(ctx context.Context, params *mcp.ListRootsParams) (*mcp.ListRootsResult, error) {
+	return &mcp.ListRootsResult{Roots: c.roots}, c.err
+}
+
+// fakeFolderStore keeps registered folders in memory
+type fakeFolderStore struct {
+	folders map[string]*storage.IndexedFolder
+}
+
+func (s *fakeFolderStore) GetFolderByPath(path string) (*storage.IndexedFolder, error) {
+	return s.folders[path], nil
+}
+
+func (s *fakeFolderStore) AddFolder(path, description string) (*storage.IndexedFolder, error) {
+	folder := &storage.IndexedFolder{ID: path, Path: path, Description: description}
+	s.folders[path] = folder
+	return folder, nil
+}
+
+// rootsElicitor answers consent prompts with a fixed action
+type rootsElicitor struct {
+	action string
+}
+
+func (e *rootsElicitor) Elicit(ctx context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+	return &mcp.ElicitResult{Action: e.action, Content: map[string]any{"confirm": true}}, nil
+}
+
+func newTestWorkspaceRoots(t *testing.T, mode string) (*WorkspaceRoots, *fakeFolderStore, *fakeRootsClient, string) {
+	t.Setenv(WorkspaceRootsEnv, mode)
+	indexed, fresh := t.TempDir(), t.TempDir()
+	store := &fakeFolderStore{folders: map[string]*storage.IndexedFolder{indexed: {ID: "1", Path: indexed}}}
+	client := &fakeRootsClient{roots: []*mcp.Root{
+		{URI: "file://" + indexed, Name: "api"},
+		{URI: "file://" + fresh, Name: "web"},
+		{URI: "file:///does/not/exist"},
+		{URI: "https://example.com/repo"},
+	}}
+	return NewWorkspaceRoots(store, nil, zap.NewNop()), store, client, fresh
+}
+
+func rootStatuses(roots []WorkspaceRoot) []string {
+	statuses := make([]string, len(roots))
+	for i, root := range roots {
+		statuses[i] = root.Status
+	}
+	return statuses
+}
+
+func TestWorkspaceRoots_SyncAsk(t *testing.T) {
+	roots, store, client, fresh := newTestWorkspaceRoots(t, "")
+	assert.Equal(t, WorkspaceRootsAsk, roots.mode)
+
+	// Without elicitation nothing is registered
+	synced := roots.Sync(context.Background(), client)
+	assert.Equal(t, []string{rootIndexed, rootUnindexed, rootUnavailable, rootUnavailable}, rootStatuses(synced))
+	assert.Nil(t, store.folders[fresh])
+
+	// A declined prompt is remembered and not asked again
+	declined := confirm.WithElicitor(context.Background(), &rootsElicitor{action: "decline"})
+	synced = roots.Sync(declined, client)
+	assert.Equal(t, rootDeclined, synced[1].Status)
+	assert.Nil(t, store.folders[fresh])
+
+	accepted := confirm.WithElicitor(context.Background(), &rootsElicitor{action: "accept"})
+	synced = roots.Sync(accepted, client)
+	assert.Equal(t, rootDeclined, synced[1].Status)
+
+	roots.declined = map[string]bool{}
+	synced = roots.Sync(accepted, client)
+	assert.Equal(t, rootRegistered, synced[1].Status)
+	require.NotNil(t, store.folders[fresh])
+	assert.Equal(t, "Client workspace root", store.folders[fresh].Description)
+}
+
+func TestWorkspaceRoots_SyncAuto(t *testing.T) {
+	roots, store, client, fresh := newTestWorkspaceRoots(t, "auto")
+
+	synced := roots.Sync(context.Background(), client)
+	assert.Equal(t, rootRegistered, synced[1].Status)
+	assert.NotNil(t, store.folders[fresh])
+
+	client.err = errors.New("method not found")
+	assert.Nil(t, roots.Sync(context.Background(), client))
+}
+
+func TestHandleWorkspaceRoots(t *testing.T) {
+	roots, store, client, fresh := newTestWorkspaceRoots(t, "off")
+	handler := &CodeToolsHandler{}
+
+	result, _, err := handler.handleWorkspaceRoots(context.Background(), client, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	handler.SetWorkspaceRoots(roots)
+	_, payload, err := handler.handleWorkspaceRoots(context.Background(), client, map[string]interface{}{})
+	require.NoError(t, err)
+	listed := payload.(map[string]interface{})["roots"].([]WorkspaceRoot)
+	assert.Equal(t, rootUnindexed, listed[1].Status)
+	assert.Nil(t, store.folders[fresh], "listing does not register")
+
+	_, payload, err = handler.handleWorkspaceRoots(context.Background(), client, map[string]interface{}{
+		"register": true,
+		"paths":    []interface{}{"/elsewhere"},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, store.folders[fresh], "only the given paths are registered")
+
+	_, payload, err = handler.handleWorkspaceRoots(context.Background(), client, map[string]interface{}{"register": true})
+	require.NoError(t, err)
+	listed = payload.(map[string]interface{})["roots"].([]WorkspaceRoot)
+	assert.Equal(t, rootRegistered, listed[1].Status)
+	assert.NotNil(t, store.folders[fresh])
+}
+
+func TestRootPath(t *testing.T) {
+	path, err := rootPath("file:///home/dev/my%20repo/")
+	require.NoError(t, err)
+	assert.Equal(t, "/home/dev/my repo", path)
+
+	_, err = rootPath("https://example.com/repo")
+	assert.Error(t, err)
+}
@@ -0,0 +1,336 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+)
+
+// Environment variables setting the default scan concurrency bounds
+const (
+	MinConcurrencyEnv = "SCAN_MIN_CONCURRENCY"
+	MaxConcurrencyEnv = "SCAN_MAX_CONCURRENCY"
+)
+
+// Load thresholds of the concurrency controller. Above a high mark the limit
+// is halved; below all low marks it grows by one file.
+const (
+	cpuHigh        = 0.85
+	cpuLow         = 0.60
+	ioWaitHigh     = 0.20
+	ioWaitLow      = 0.05
+	latencyHigh    = 2.0 // Embedding latency relative to the scan's baseline
+	latencyLow     = 1.3
+	latencyWeight  = 0.2 // EWMA weight of each new latency observation
+	adjustInterval = 2 * time.Second
+)
+
+// LoadSample is one reading of system load, as fractions of total CPU time
+// since the previous reading
+type LoadSample struct {
+	CPU    float64 // Busy (non-idle, non-iowait) share
+	IOWait float64 // Share spent waiting on IO
+}
+
+// LoadProbe reads system load. ok is false when load cannot be measured, in
+// which case only embedding latency steers concurrency.
+type LoadProbe interface {
+	Sample() (sample LoadSample, ok bool)
+}
+
+// ConcurrencyStats reports how a scan's concurrency evolved
+type ConcurrencyStats struct {
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+	Peak  int `json:"peak"`  // Most files indexed at once
+	Final int `json:"final"` // Limit when the scan finished
+}
+
+// DefaultConcurrency returns the bounds from SCAN_MIN_CONCURRENCY and
+// SCAN_MAX_CONCURRENCY: 1 and half the CPUs unless set
+func DefaultConcurrency() storage.ScanConcurrency {
+	bounds := storage.ScanConcurrency{Min: 1, Max: max(1, runtime.NumCPU()/2)}
+	if n, err := strconv.Atoi(os.Getenv(MinConcurrencyEnv)); err == nil && n > 0 {
+		bounds.Min = min(n, storage.MaxScanConcurrency)
+	}
+	if n, err := strconv.Atoi(os.Getenv(MaxConcurrencyEnv)); err == nil && n > 0 {
+		bounds.Max = min(n, storage.MaxScanConcurrency)
+	}
+	bounds.Max = max(bounds.Max, bounds.Min)
+	return bounds
+}
+
+// ConcurrencyFor returns the effective bounds for scans of folder: its own
+// bounds where set, the defaults otherwise
+func ConcurrencyFor(folder *storage.IndexedFolder) storage.ScanConcurrency {
+	bounds := DefaultConcurrency()
+	if folder == nil || folder.ScanConcurrency == nil {
+		return bounds
+	}
+	if folder.ScanConcurrency.Min > 0 {
+		bounds.Min = folder.ScanConcurrency.Min
+	}
+	if folder.ScanConcurrency.Max > 0 {
+		bounds.Max = folder.ScanConcurrency.Max
+	}
+	bounds.Max = max(bounds.Max, bounds.Min)
+	return bounds
+}
+
+// AdaptiveLimiter runs scan work concurrently, starting at the minimum bound
+// and adjusting the number of files in flight to CPU and IO load and to the
+// latency of the embedding backend: it backs off multiplicatively under load
+// and grows one file at a time while there is headroom.
+type AdaptiveLimiter struct {
+	bounds   storage.ScanConcurrency
+	probe    LoadProbe
+	interval time.Duration
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int
+	inflight int
+	peak     int
+	latency  float64 // EWMA of embedding latency, in seconds
+	baseline float64 // Lowest latency EWMA seen during the scan
+}
+
+// NewAdaptiveLimiter creates a limiter within bounds; probe may be nil to
+// steer by embedding latency alone
+func NewAdaptiveLimiter(bounds storage.ScanConcurrency, probe LoadProbe) *AdaptiveLimiter {
+	bounds.Min = max(1, bounds.Min)
+	bounds.Max = max(bounds.Max, bounds.Min)
+	l := &AdaptiveLimiter{
+		bounds:   bounds,
+		probe:    probe,
+		interval: adjustInterval,
+		limit:    bounds.Min,
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// NewFolderLimiter creates a limiter for scans of folder, probing the load of
+// this machine
+func NewFolderLimiter(folder *storage.IndexedFolder) *AdaptiveLimiter {
+	return NewAdaptiveLimiter(ConcurrencyFor(folder), NewSystemLoadProbe())
+}
+
+// Run calls fn for items 0..count-1 with at most Limit calls in flight and
+// returns once all calls have returned. Items not yet started when ctx ends
+// are skipped.
+func (l *AdaptiveLimiter) Run(ctx context.Context, count int, fn func(i int)) {
+	stop := make(chan struct{})
+	defer close(stop)
+	go l.control(stop)
+
+	var wg sync.WaitGroup
+	for i := 0; i < count && ctx.Err() == nil; i++ {
+		l.mu.Lock()
+		for l.inflight >= l.limit {
+			l.cond.Wait()
+		}
+		l.inflight++
+		l.peak = max(l.peak, l.inflight)
+		l.mu.Unlock()
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer l.release()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// release frees the slot of a finished call
+func (l *AdaptiveLimiter) release() {
+	l.mu.Lock()
+	l.inflight--
+	l.mu.Unlock()
+	l.cond.Broadcast()
+}
+
+// control adjusts the limit every interval until stop is closed
+func (l *AdaptiveLimiter) control(stop <-chan struct{}) {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			l.adjust()
+		}
+	}
+}
+
+// adjust applies one step of the controller
+func (l *AdaptiveLimiter) adjust() {
+	var sample LoadSample
+	measured := false
+	if l.probe != nil {
+		sample, measured = l.probe.Sample()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slowBackend := l.baseline > 0 && l.latency > latencyHigh*l.baseline
+	fastBackend := l.baseline == 0 || l.latency < latencyLow*l.baseline
+	busy := measured && (sample.CPU > cpuHigh || sample.IOWait > ioWaitHigh)
+	idle := !measured || (sample.CPU < cpuLow && sample.IOWait < ioWaitLow)
+
+	switch {
+	case busy || slowBackend:
+		l.limit = max(l.bounds.Min, l.limit/2)
+	case idle && fastBackend:
+		l.limit = min(l.bounds.Max, l.limit+1)
+	}
+	l.cond.Broadcast()
+}
+
+// ObserveLatency records how long one embedding request took
+func (l *AdaptiveLimiter) ObserveLatency(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	seconds := d.Seconds()
+	if l.latency == 0 {
+		l.latency = seconds
+	} else {
+		l.latency = (1-latencyWeight)*l.latency + latencyWeight*seconds
+	}
+	if l.baseline == 0 || l.latency < l.baseline {
+		l.baseline = l.latency
+	}
+}
+
+// Limit returns the current number of calls allowed in flight
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// Stats returns the bounds, peak and current limit
+func (l *AdaptiveLimiter) Stats() ConcurrencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return ConcurrencyStats{Min: l.bounds.Min, Max: l.bounds.Max, Peak: l.peak, Final: l.limit}
+}
+
+// Embedder wraps client so that each embedding request's latency is observed
+func (l *AdaptiveLimiter) Embedder(client embeddings.EmbeddingClient) embeddings.EmbeddingClient {
+	return &timedEmbedder{EmbeddingClient: client, limiter: l}
+}
+
+// timedEmbedder reports embedding latency to its limiter
+type timedEmbedder struct {
+	embeddings.EmbeddingClient
+	limiter *AdaptiveLimiter
+}
+
+func (e *timedEmbedder) CreateEmbedding(text string) ([]float32, error) {
+	start := time.Now()
+	vector, err := e.EmbeddingClient.CreateEmbedding(text)
+	if err == nil {
+		e.limiter.ObserveLatency(time.Since(start))
+	}
+	return vector, err
+}
+
+func (e *timedEmbedder) CreateEmbeddings(texts []string) ([][]float32, error) {
+	start := time.Now()
+	vectors, err := e.EmbeddingClient.CreateEmbeddings(texts)
+	if err == nil && len(texts) > 0 {
+		e.limiter.ObserveLatency(time.Since(start) / time.Duration(len(texts)))
+	}
+	return vectors, err
+}
+
+// systemLoadProbe reads CPU and IO wait shares from /proc/stat
+type systemLoadProbe struct {
+	path string
+
+	mu          sync.Mutex
+	prevTotal   uint64
+	prevIdle    uint64
+	prevIOWait  uint64
+	initialized bool
+}
+
+// NewSystemLoadProbe returns a probe of this machine's load. On systems
+// without /proc/stat it never reports a sample.
+func NewSystemLoadProbe() LoadProbe {
+	p := &systemLoadProbe{path: "/proc/stat"}
+	p.Sample() // Prime the counters so the first tick has a delta
+	return p
+}
+
+// Sample returns the load since the previous call
+func (p *systemLoadProbe) Sample() (LoadSample, bool) {
+	total, idle, iowait, err := readCPUTimes(p.path)
+	if err != nil {
+		return LoadSample{}, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prevTotal, prevIdle, prevIOWait, initialized := p.prevTotal, p.prevIdle, p.prevIOWait, p.initialized
+	p.prevTotal, p.prevIdle, p.prevIOWait, p.initialized = total, idle, iowait, true
+	if !initialized || total <= prevTotal {
+		return LoadSample{}, false
+	}
+
+	elapsed := float64(total - prevTotal)
+	idleShare := float64(idle-prevIdle) / elapsed
+	ioWaitShare := float64(iowait-prevIOWait) / elapsed
+	return LoadSample{CPU: 1 - idleShare - ioWaitShare, IOWait: ioWaitShare}, true
+}
+
+// readCPUTimes parses the aggregate "cpu" line of a /proc/stat file
+func readCPUTimes(path string) (total, idle, iowait uint64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 6 || fields[0] != "cpu" {
+			continue
+		}
+		// user nice system idle iowait irq softirq steal ...; guest time is
+		// already included in user and nice
+		for i, field := range fields[1:min(len(fields), 9)] {
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, 0, fmt.Errorf("invalid %s: %w", path, err)
+			}
+			total += value
+			switch i {
+			case 3:
+				idle = value
+			case 4:
+				iowait = value
+			}
+		}
+		return total, idle, iowait, nil
+	}
+	if err := sc.Err(); err != nil {
+		return 0, 0, 0, err
+	}
+	return 0, 0, 0, fmt.Errorf("no cpu line in %s", path)
+}
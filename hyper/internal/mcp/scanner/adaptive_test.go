@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProbe struct {
+	sample LoadSample
+	ok     bool
+}
+
+func (p *fakeProbe) Sample() (LoadSample, bool) { return p.sample, p.ok }
+
+func TestAdaptiveLimiterScalesWithLoad(t *testing.T) {
+	probe := &fakeProbe{sample: LoadSample{CPU: 0.2, IOWait: 0.01}, ok: true}
+	l := NewAdaptiveLimiter(storage.ScanConcurrency{Min: 2, Max: 5}, probe)
+	assert.Equal(t, 2, l.Limit())
+
+	for range 10 {
+		l.adjust()
+	}
+	assert.Equal(t, 5, l.Limit(), "grows to the upper bound while idle")
+
+	probe.sample = LoadSample{CPU: 0.95}
+	l.adjust()
+	assert.Equal(t, 2, l.Limit(), "halves under CPU load, not below the minimum")
+
+	probe.sample = LoadSample{CPU: 0.7, IOWait: 0.03}
+	l.adjust()
+	assert.Equal(t, 2, l.Limit(), "holds between the marks")
+
+	probe.sample = LoadSample{CPU: 0.1, IOWait: 0.3}
+	l.adjust()
+	assert.Equal(t, 2, l.Limit(), "IO wait counts as load")
+}
+
+func TestAdaptiveLimiterBacksOffOnSlowEmbeddings(t *testing.T) {
+	l := NewAdaptiveLimiter(storage.ScanConcurrency{Min: 1, Max: 8}, nil)
+	l.ObserveLatency(100 * time.Millisecond)
+	for range 4 {
+		l.adjust()
+	}
+	require.Equal(t, 5, l.Limit())
+
+	for range 10 {
+		l.ObserveLatency(time.Second)
+	}
+	l.adjust()
+	assert.Equal(t, 2, l.Limit())
+}
+
+func TestAdaptiveLimiterRun(t *testing.T) {
+	l := NewAdaptiveLimiter(storage.ScanConcurrency{Min: 3, Max: 3}, nil)
+
+	var inflight, peak, calls atomic.Int32
+	l.Run(context.Background(), 20, func(i int) {
+		n := inflight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inflight.Add(-1)
+		calls.Add(1)
+	})
+
+	assert.Equal(t, int32(20), calls.Load())
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	stats := l.Stats()
+	assert.Equal(t, 3, stats.Peak)
+	assert.Equal(t, 3, stats.Final)
+}
+
+func TestConcurrencyFor(t *testing.T) {
+	t.Setenv(MinConcurrencyEnv, "2")
+	t.Setenv(MaxConcurrencyEnv, "6")
+	assert.Equal(t, storage.ScanConcurrency{Min: 2, Max: 6}, ConcurrencyFor(nil))
+
+	folder := &storage.IndexedFolder{ScanConcurrency: &storage.ScanConcurrency{Max: 12}}
+	assert.Equal(t, storage.ScanConcurrency{Min: 2, Max: 12}, ConcurrencyFor(folder))
+
+	folder.ScanConcurrency = &storage.ScanConcurrency{Min: 8}
+	assert.Equal(t, storage.ScanConcurrency{Min: 8, Max: 8}, ConcurrencyFor(folder))
+}
+
+func TestSystemLoadProbe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stat")
+	write := func(line string) {
+		require.NoError(t, os.WriteFile(path, []byte(line+"\ncpu0 1 2 3 4 5\n"), 0o644))
+	}
+	probe := &systemLoadProbe{path: path}
+
+	write("cpu  100 0 100 700 100 0 0 0 0 0")
+	_, ok := probe.Sample()
+	assert.False(t, ok, "the first reading has no delta")
+
+	// +1000 jiffies: 600 busy, 300 idle, 100 iowait
+	write("cpu  400 0 400 1000 200 0 0 0 0 0")
+	sample, ok := probe.Sample()
+	require.True(t, ok)
+	assert.InDelta(t, 0.6, sample.CPU, 1e-9)
+	assert.InDelta(t, 0.1, sample.IOWait, 1e-9)
+
+	probe.path = filepath.Join(t.TempDir(), "missing")
+	_, ok = probe.Sample()
+	assert.False(t, ok)
+}
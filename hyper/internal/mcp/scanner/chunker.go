@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Chunker splits the content of a file into the chunks that are embedded and
+// searched. Chunks are returned in file order with 1-based line ranges.
+// chunkSize is the CODE_INDEX_CHUNK_SIZE line budget; chunkers that split on
+// syntax, such as one SQL statement or Terraform block per chunk, may treat
+// it as a soft limit.
+type Chunker interface {
+	Chunk(content string, chunkSize int) []ChunkContent
+}
+
+// ChunkerFunc adapts a function to Chunker
+type ChunkerFunc func(content string, chunkSize int) []ChunkContent
+
+// Chunk calls f
+func (f ChunkerFunc) Chunk(content string, chunkSize int) []ChunkContent {
+	return f(content, chunkSize)
+}
+
+// LineChunker splits content into windows of chunkSize lines. It chunks
+// every language without a registered chunker.
+var LineChunker Chunker = ChunkerFunc(chunkLines)
+
+var (
+	registryMu sync.RWMutex
+	languages  = map[string]string{ // extension -> language
+		".go":    "go",
+		".js":    "javascript",
+		".ts":    "typescript",
+		".jsx":   "javascript",
+		".tsx":   "typescript",
+		".py":    "python",
+		".java":  "java",
+		".c":     "c",
+		".cpp":   "cpp",
+		".h":     "c",
+		".hpp":   "cpp",
+		".cs":    "csharp",
+		".rb":    "ruby",
+		".php":   "php",
+		".rs":    "rust",
+		".swift": "swift",
+		".kt":    "kotlin",
+		".m":     "objective-c",
+		".scala": "scala",
+		".r":     "r",
+		".sql":   "sql",
+		".sh":    "shell",
+		".bash":  "shell",
+		".yaml":  "yaml",
+		".yml":   "yaml",
+		".json":  "json",
+		".xml":   "xml",
+		".html":  "html",
+		".css":   "css",
+		".scss":  "scss",
+		".less":  "less",
+		".vue":   "vue",
+		".md":    "markdown",
+	}
+	chunkers = map[string]Chunker{} // language -> chunker
+)
+
+// RegisterLanguage makes files with extension (".tf" or "tf") indexable as
+// language, replacing the language the extension had
+func RegisterLanguage(extension, language string) {
+	if !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	languages[extension] = language
+}
+
+// RegisterChunker chunks files of language with chunker instead of
+// LineChunker. Register the language's extensions with RegisterLanguage if
+// it is not indexed yet.
+func RegisterChunker(language string, chunker Chunker) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	chunkers[language] = chunker
+}
+
+// LanguageOf returns the language of a file from its extension; ok is false
+// for files that are not indexed
+func LanguageOf(path string) (language string, ok bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	language, ok = languages[filepath.Ext(path)]
+	return language, ok
+}
+
+// ChunkerFor returns the chunker of language: the registered one, or
+// LineChunker
+func ChunkerFor(language string) Chunker {
+	if chunker, ok := registeredChunker(language); ok {
+		return chunker
+	}
+	return LineChunker
+}
+
+// registeredChunker returns the chunker registered for language, if any
+func registeredChunker(language string) (Chunker, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	chunker, ok := chunkers[language]
+	return chunker, ok
+}
+
+// chunkContent chunks content with the chunker of language. A file always
+// has at least one chunk, empty for empty files.
+func chunkContent(language, content string, chunkSize int) []ChunkContent {
+	chunks := ChunkerFor(language).Chunk(content, chunkSize)
+	if len(chunks) == 0 {
+		chunks = []ChunkContent{{Content: "", StartLine: 1, EndLine: 0}}
+	}
+	return chunks
+}
+
+// chunkLines implements LineChunker. Lines keep their "\n" terminator and
+// lose a trailing "\r".
+func chunkLines(content string, chunkSize int) []ChunkContent {
+	if content == "" {
+		return nil
+	}
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	chunks := make([]ChunkContent, 0, (len(lines)+chunkSize-1)/chunkSize)
+	for start := 0; start < len(lines); start += chunkSize {
+		end := min(start+chunkSize, len(lines))
+		var b strings.Builder
+		for _, line := range lines[start:end] {
+			b.WriteString(strings.TrimSuffix(line, "\r"))
+			b.WriteString("\n")
+		}
+		chunks = append(chunks, ChunkContent{Content: b.String(), StartLine: start + 1, EndLine: end})
+	}
+	return chunks
+}
@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineChunker(t *testing.T) {
+	chunks := LineChunker.Chunk("a\r\nb\nc", 2)
+	assert.Equal(t, []ChunkContent{
+		{Content: "a\nb\n", StartLine: 1, EndLine: 2},
+		{Content: "c\n", StartLine: 3, EndLine: 3},
+	}, chunks)
+
+	assert.Empty(t, LineChunker.Chunk("", 2))
+	assert.Equal(t, []ChunkContent{{Content: "\n", StartLine: 1, EndLine: 1}}, LineChunker.Chunk("\n", 2))
+}
+
+func TestRegisterChunker(t *testing.T) {
+	// One chunk per blank-line separated block
+	RegisterLanguage("tftest", "terraform-test")
+	RegisterChunker("terraform-test", ChunkerFunc(func(content string, _ int) []ChunkContent {
+		var chunks []ChunkContent
+		line := 1
+		for _, block := range strings.Split(content, "\n\n") {
+			lines := strings.Count(block, "\n") + 1
+			chunks = append(chunks, ChunkContent{Content: block, StartLine: line, EndLine: line + lines - 1})
+			line += lines + 1
+		}
+		return chunks
+	}))
+
+	dir := t.TempDir()
+	content := "resource \"a\" {\n}\n\nresource \"b\" {\n}"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tftest"), []byte(content), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+
+	assert.True(t, IsCodeFile("infra/main.tftest"))
+	_, custom := registeredChunker("go")
+	assert.False(t, custom)
+
+	fs := NewFileScanner()
+	files, err := fs.ScanDirectory(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	for _, file := range files {
+		if file.Language == "terraform-test" {
+			assert.Equal(t, 2, file.ChunkCount)
+		} else {
+			assert.Equal(t, 1, file.ChunkCount)
+		}
+	}
+
+	chunks, err := fs.CreateFileChunks("file-1", filepath.Join(dir, "main.tftest"))
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "resource \"b\" {\n}", chunks[1].Content)
+	assert.Equal(t, 4, chunks[1].StartLine)
+	assert.Equal(t, 5, chunks[1].EndLine)
+
+	info, err := ScanFile(filepath.Join(dir, "main.tftest"), dir)
+	require.NoError(t, err)
+	assert.Len(t, info.Chunks, 2)
+}
@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+)
+
+// ScanEstimate reports what a scan would index without embedding anything
+type ScanEstimate struct {
+	FolderPath        string                    `json:"folderPath"`
+	TotalFiles        int                       `json:"totalFiles"`
+	FilesToIndex      int                       `json:"filesToIndex"`
+	FilesUnchanged    int                       `json:"filesUnchanged"`
+	ChunkCount        int                       `json:"chunkCount"`
+	TotalBytes        int64                     `json:"totalBytes"`
+	EstimatedTokens   int64                     `json:"estimatedTokens"`
+	AvgTokensPerChunk int64                     `json:"avgTokensPerChunk"`
+	Languages         map[string]int            `json:"languages"`
+	Costs             []embeddings.CostEstimate `json:"costs"`                // Every provider's default model
+	ActiveCost        *embeddings.CostEstimate  `json:"activeCost,omitempty"` // The configured provider, when known
+}
+
+// EstimateScan walks folderPath with the same exclusions and chunking as a real
+// scan and totals the work an index run would do. Files for which unchanged
+// returns true are counted but not estimated, since a scan would skip them.
+func (fs *FileScanner) EstimateScan(folderPath string, unchanged func(file *storage.IndexedFile) bool) (*ScanEstimate, error) {
+	files, err := fs.ScanDirectory(folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &ScanEstimate{
+		FolderPath: folderPath,
+		TotalFiles: len(files),
+		Languages:  make(map[string]int),
+	}
+	for _, file := range files {
+		if unchanged != nil && unchanged(file) {
+			estimate.FilesUnchanged++
+			continue
+		}
+		estimate.FilesToIndex++
+		estimate.ChunkCount += file.ChunkCount
+		estimate.TotalBytes += file.Size
+		estimate.Languages[file.Language]++
+	}
+
+	estimate.EstimatedTokens = embeddings.BytesToTokens(estimate.TotalBytes)
+	if estimate.ChunkCount > 0 {
+		estimate.AvgTokensPerChunk = estimate.EstimatedTokens / int64(estimate.ChunkCount)
+	}
+	estimate.Costs = embeddings.EstimateAll(estimate.EstimatedTokens)
+
+	return estimate, nil
+}
@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateScan(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write("main.go", strings.Repeat("x\n", 250))     // 500 bytes, 2 chunks
+	write("app.py", "print('hi')\n")                 // 12 bytes, 1 chunk
+	write("notes.txt", "unsupported extension")      // ignored
+	write("node_modules/lib.js", "module.exports=1") // excluded directory
+	write("cached.go", "package cached\n")           // reported unchanged
+
+	fs := NewFileScanner()
+	fs.chunkSize = 200
+
+	estimate, err := fs.EstimateScan(dir, func(file *storage.IndexedFile) bool {
+		return filepath.Base(file.Path) == "cached.go"
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, estimate.TotalFiles)
+	assert.Equal(t, 2, estimate.FilesToIndex)
+	assert.Equal(t, 1, estimate.FilesUnchanged)
+	assert.Equal(t, 3, estimate.ChunkCount)
+	assert.Equal(t, int64(512), estimate.TotalBytes)
+	assert.Equal(t, int64(128), estimate.EstimatedTokens)
+	assert.Equal(t, int64(42), estimate.AvgTokensPerChunk)
+	assert.Equal(t, map[string]int{"go": 1, "python": 1}, estimate.Languages)
+
+	require.Len(t, estimate.Costs, len(embeddings.DefaultPricing))
+	for _, cost := range estimate.Costs {
+		if cost.Provider == "ollama" {
+			assert.Zero(t, cost.EstimatedCostUSD)
+		}
+	}
+}
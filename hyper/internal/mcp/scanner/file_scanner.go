@@ -8,22 +8,25 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 
 	"hyper/internal/mcp/storage"
 )
 
-// FileScanner scans directories for code files
+// defaultChunkSize is the number of lines per chunk unless
+// CODE_INDEX_CHUNK_SIZE is set
+const defaultChunkSize = 200
+
+// FileScanner scans directories for code files. Files are indexed by the
+// languages registered with RegisterLanguage and chunked by their Chunker.
 type FileScanner struct {
-	supportedExtensions map[string]string // extension -> language
-	maxFileSize         int64             // max file size in bytes
-	chunkSize           int               // lines per chunk
+	maxFileSize int64 // max file size in bytes
+	chunkSize   int   // lines per chunk
 }
 
 // NewFileScanner creates a new file scanner
 func NewFileScanner() *FileScanner {
 	// Get chunk size from ENV var (default: 200 lines)
-	chunkSize := 200
+	chunkSize := defaultChunkSize
 	if envChunkSize := os.Getenv("CODE_INDEX_CHUNK_SIZE"); envChunkSize != "" {
 		if parsedSize, err := strconv.Atoi(envChunkSize); err == nil && parsedSize > 0 {
 			chunkSize = parsedSize
@@ -31,43 +34,8 @@ func NewFileScanner() *FileScanner {
 	}
 
 	return &FileScanner{
-		supportedExtensions: map[string]string{
-			".go":   "go",
-			".js":   "javascript",
-			".ts":   "typescript",
-			".jsx":  "javascript",
-			".tsx":  "typescript",
-			".py":   "python",
-			".java": "java",
-			".c":    "c",
-			".cpp":  "cpp",
-			".h":    "c",
-			".hpp":  "cpp",
-			".cs":   "csharp",
-			".rb":   "ruby",
-			".php":  "php",
-			".rs":   "rust",
-			".swift": "swift",
-			".kt":   "kotlin",
-			".m":    "objective-c",
-			".scala": "scala",
-			".r":    "r",
-			".sql":  "sql",
-			".sh":   "shell",
-			".bash": "shell",
-			".yaml": "yaml",
-			".yml":  "yaml",
-			".json": "json",
-			".xml":  "xml",
-			".html": "html",
-			".css":  "css",
-			".scss": "scss",
-			".less": "less",
-			".vue":  "vue",
-			".md":   "markdown",
-		},
 		maxFileSize: 10 * 1024 * 1024, // 10 MB
-		chunkSize:   chunkSize,         // Configurable via CODE_INDEX_CHUNK_SIZE env var
+		chunkSize:   chunkSize,        // Configurable via CODE_INDEX_CHUNK_SIZE env var
 	}
 }
 
@@ -94,8 +62,7 @@ func (fs *FileScanner) ScanDirectory(folderPath string) ([]*storage.IndexedFile,
 		}
 
 		// Check if file extension is supported
-		ext := filepath.Ext(path)
-		language, supported := fs.supportedExtensions[ext]
+		language, supported := LanguageOf(path)
 		if !supported {
 			return nil
 		}
@@ -124,9 +91,9 @@ func (fs *FileScanner) ScanDirectory(folderPath string) ([]*storage.IndexedFile,
 		}
 
 		// Calculate chunk count
-		chunkCount := (lineCount + fs.chunkSize - 1) / fs.chunkSize
-		if chunkCount == 0 {
-			chunkCount = 1
+		chunkCount, err := fs.chunkCount(path, language, lineCount)
+		if err != nil {
+			return fmt.Errorf("failed to chunk %s: %w", path, err)
 		}
 
 		file := &storage.IndexedFile{
@@ -150,80 +117,60 @@ func (fs *FileScanner) ScanDirectory(folderPath string) ([]*storage.IndexedFile,
 	return files, nil
 }
 
-// ReadFileChunks reads a file and returns it in chunks
-func (fs *FileScanner) ReadFileChunks(filePath string) ([]string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+// chunkCount returns the number of chunks of a file. Only files chunked by a
+// registered Chunker are read; line windows are counted from lineCount.
+func (fs *FileScanner) chunkCount(path, language string, lineCount int) (int, error) {
+	if _, custom := registeredChunker(language); !custom {
+		return max(1, (lineCount+fs.chunkSize-1)/fs.chunkSize), nil
 	}
-	defer file.Close()
-
-	var chunks []string
-	var currentChunk strings.Builder
-	var lineCount int
-
-	scanner := bufio.NewScanner(file)
-
-	// Increase buffer size to 1MB to handle minified files with very long lines
-	const maxCapacity = 1024 * 1024 // 1 MB
-	buf := make([]byte, maxCapacity)
-	scanner.Buffer(buf, maxCapacity)
-
-	for scanner.Scan() {
-		currentChunk.WriteString(scanner.Text())
-		currentChunk.WriteString("\n")
-		lineCount++
-
-		if lineCount >= fs.chunkSize {
-			chunks = append(chunks, currentChunk.String())
-			currentChunk.Reset()
-			lineCount = 0
-		}
+	chunks, err := fs.readChunks(path, language)
+	if err != nil {
+		return 0, err
 	}
+	return len(chunks), nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+// readChunks reads a file and chunks it with the chunker of language
+func (fs *FileScanner) readChunks(filePath, language string) ([]ChunkContent, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
+	return chunkContent(language, string(content), fs.chunkSize), nil
+}
 
-	// Add remaining content as last chunk
-	if currentChunk.Len() > 0 {
-		chunks = append(chunks, currentChunk.String())
+// ReadFileChunks reads a file and returns it in chunks
+func (fs *FileScanner) ReadFileChunks(filePath string) ([]string, error) {
+	language, _ := LanguageOf(filePath)
+	chunks, err := fs.readChunks(filePath, language)
+	if err != nil {
+		return nil, err
 	}
 
-	// If no chunks, add empty chunk
-	if len(chunks) == 0 {
-		chunks = append(chunks, "")
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Content
 	}
-
-	return chunks, nil
+	return texts, nil
 }
 
 // CreateFileChunks creates FileChunk objects for a file
 func (fs *FileScanner) CreateFileChunks(fileID, filePath string) ([]*storage.FileChunk, error) {
-	chunks, err := fs.ReadFileChunks(filePath)
+	language, _ := LanguageOf(filePath)
+	chunks, err := fs.readChunks(filePath, language)
 	if err != nil {
 		return nil, err
 	}
 
-	var fileChunks []*storage.FileChunk
-	currentLine := 1
-
-	for i, content := range chunks {
-		lines := strings.Count(content, "\n")
-		if lines == 0 && content != "" {
-			lines = 1
-		}
-
-		chunk := &storage.FileChunk{
+	fileChunks := make([]*storage.FileChunk, 0, len(chunks))
+	for i, chunk := range chunks {
+		fileChunks = append(fileChunks, &storage.FileChunk{
 			FileID:    fileID,
 			ChunkNum:  i,
-			Content:   content,
-			StartLine: currentLine,
-			EndLine:   currentLine + lines - 1,
-		}
-
-		fileChunks = append(fileChunks, chunk)
-		currentLine += lines
+			Content:   chunk.Content,
+			StartLine: chunk.StartLine,
+			EndLine:   chunk.EndLine,
+		})
 	}
 
 	return fileChunks, nil
@@ -313,10 +260,9 @@ func ScanFile(filePath string, basePath string) (*FileInfo, error) {
 	}
 
 	// Check if file extension is supported
-	ext := filepath.Ext(filePath)
-	language, supported := fs.supportedExtensions[ext]
+	language, supported := LanguageOf(filePath)
 	if !supported {
-		return nil, fmt.Errorf("unsupported file type: %s", ext)
+		return nil, fmt.Errorf("unsupported file type: %s", filepath.Ext(filePath))
 	}
 
 	// Check file size
@@ -343,30 +289,11 @@ func ScanFile(filePath string, basePath string) (*FileInfo, error) {
 	}
 
 	// Read file chunks
-	chunkTexts, err := fs.ReadFileChunks(filePath)
+	chunks, err := fs.readChunks(filePath, language)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read chunks: %w", err)
 	}
 
-	// Create chunk content with line numbers
-	var chunks []ChunkContent
-	currentLine := 1
-
-	for _, chunkText := range chunkTexts {
-		lines := strings.Count(chunkText, "\n")
-		if lines == 0 && chunkText != "" {
-			lines = 1
-		}
-
-		chunks = append(chunks, ChunkContent{
-			Content:   chunkText,
-			StartLine: currentLine,
-			EndLine:   currentLine + lines - 1,
-		})
-
-		currentLine += lines
-	}
-
 	return &FileInfo{
 		Path:         filePath,
 		RelativePath: relativePath,
@@ -380,8 +307,6 @@ func ScanFile(filePath string, basePath string) (*FileInfo, error) {
 
 // IsCodeFile checks if a file is a supported code file
 func IsCodeFile(filePath string) bool {
-	fs := NewFileScanner()
-	ext := filepath.Ext(filePath)
-	_, supported := fs.supportedExtensions[ext]
+	_, supported := LanguageOf(filePath)
 	return supported
 }
@@ -0,0 +1,521 @@
+package scanner
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"hyper/internal/mcp/storage"
+)
+
+// Symbol kinds that enclose others: methods found inside one get it as their
+// container
+var containerKinds = map[string]bool{
+	"class": true, "interface": true, "struct": true, "enum": true, "trait": true,
+	"record": true, "object": true, "impl": true, "protocol": true, "extension": true,
+}
+
+// symbolRule finds one kind of declaration on a line; the declared name is
+// the pattern's "name" group
+type symbolRule struct {
+	pattern *regexp.Regexp
+	kind    string
+	member  bool // Only a declaration inside a container, e.g. a method without a keyword
+	hidden  bool // Only a container for others, not reported itself (Rust impl blocks)
+}
+
+func rule(kind, pattern string) symbolRule {
+	return symbolRule{pattern: regexp.MustCompile(pattern), kind: kind}
+}
+
+func memberRule(kind, pattern string) symbolRule {
+	return symbolRule{pattern: regexp.MustCompile(pattern), kind: kind, member: true}
+}
+
+const (
+	jvmModifiers = `^\s*(?:@\w+(?:\([^)]*\))?\s+)*(?:(?:public|private|protected|internal|static|final|abstract|sealed|partial|open|data|inner|override|virtual|async|readonly|unsafe|extern|synchronized|native|default|new|suspend|inline|operator|export)\s+)*`
+	jsName       = `(?P<name>[A-Za-z_$][\w$]*)`
+)
+
+// symbolRules are the declarations recognized in brace-delimited languages
+var symbolRules = map[string][]symbolRule{
+	"javascript": jsRules(),
+	"typescript": jsRules(),
+	"java": {
+		rule("class", jvmModifiers+`(?:class|record)\s+(?P<name>\w+)`),
+		rule("interface", jvmModifiers+`@?interface\s+(?P<name>\w+)`),
+		rule("enum", jvmModifiers+`enum\s+(?P<name>\w+)`),
+		memberRule("method", jvmModifiers+`(?:<[^>]+>\s+)?(?P<type>[\w<>\[\]?,.]+)(?:\s*<[^>]*>)?\s+(?P<name>\w+)\s*\([^;]*$`),
+	},
+	"csharp": {
+		rule("class", jvmModifiers+`(?:class|record)\s+(?P<name>\w+)`),
+		rule("struct", jvmModifiers+`struct\s+(?P<name>\w+)`),
+		rule("interface", jvmModifiers+`interface\s+(?P<name>\w+)`),
+		rule("enum", jvmModifiers+`enum\s+(?P<name>\w+)`),
+		memberRule("method", jvmModifiers+`(?P<type>[\w<>\[\]?,.]+)(?:\s*<[^>]*>)?\s+(?P<name>\w+)\s*(?:<[^>]*>)?\s*\([^;]*$`),
+	},
+	"kotlin": {
+		rule("class", jvmModifiers+`(?:enum\s+|annotation\s+)?class\s+(?P<name>\w+)`),
+		rule("interface", jvmModifiers+`(?:fun\s+)?interface\s+(?P<name>\w+)`),
+		rule("object", jvmModifiers+`object\s+(?P<name>\w+)`),
+		rule("function", jvmModifiers+`fun\s+(?:<[^>]+>\s*)?(?:[\w.]+\.)?(?P<name>\w+)\s*\(`),
+	},
+	"scala": {
+		rule("class", jvmModifiers+`(?:case\s+)?class\s+(?P<name>\w+)`),
+		rule("trait", jvmModifiers+`trait\s+(?P<name>\w+)`),
+		rule("object", jvmModifiers+`(?:case\s+)?object\s+(?P<name>\w+)`),
+		rule("function", jvmModifiers+`def\s+(?P<name>\w+)`),
+	},
+	"rust": {
+		rule("function", `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"[^"]*"\s+)?fn\s+(?P<name>\w+)`),
+		rule("struct", `^\s*(?:pub(?:\([^)]*\))?\s+)?struct\s+(?P<name>\w+)`),
+		rule("enum", `^\s*(?:pub(?:\([^)]*\))?\s+)?enum\s+(?P<name>\w+)`),
+		rule("trait", `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:unsafe\s+)?trait\s+(?P<name>\w+)`),
+		rule("type", `^\s*(?:pub(?:\([^)]*\))?\s+)?type\s+(?P<name>\w+)`),
+		rule("const", `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const|static)\s+(?:mut\s+)?(?P<name>[A-Z_][A-Z0-9_]*)\s*:`),
+		rule("module", `^\s*(?:pub(?:\([^)]*\))?\s+)?mod\s+(?P<name>\w+)\s*\{`),
+		{pattern: regexp.MustCompile(`^\s*(?:unsafe\s+)?impl\b(?:\s*<[^{]*?>)?\s+(?:[\w:<>, ]+\s+for\s+)?(?:[\w:]+::)?(?P<name>\w+)`), kind: "impl", hidden: true},
+	},
+	"php": {
+		rule("class", `^\s*(?:(?:abstract|final|readonly)\s+)*class\s+(?P<name>\w+)`),
+		rule("interface", `^\s*interface\s+(?P<name>\w+)`),
+		rule("trait", `^\s*trait\s+(?P<name>\w+)`),
+		rule("enum", `^\s*enum\s+(?P<name>\w+)`),
+		rule("function", `^\s*(?:(?:public|private|protected|static|abstract|final)\s+)*function\s+&?(?P<name>\w+)\s*\(`),
+	},
+	"swift": {
+		rule("class", `^\s*(?:(?:public|private|fileprivate|internal|open|final)\s+)*class\s+(?P<name>\w+)`),
+		rule("struct", `^\s*(?:(?:public|private|fileprivate|internal)\s+)*struct\s+(?P<name>\w+)`),
+		rule("enum", `^\s*(?:(?:public|private|fileprivate|internal|indirect)\s+)*enum\s+(?P<name>\w+)`),
+		rule("protocol", `^\s*(?:(?:public|private|fileprivate|internal)\s+)*protocol\s+(?P<name>\w+)`),
+		{pattern: regexp.MustCompile(`^\s*(?:(?:public|private|fileprivate|internal)\s+)*extension\s+(?P<name>\w+)`), kind: "extension", hidden: true},
+		rule("function", `^\s*(?:@\w+\s+)*(?:(?:public|private|fileprivate|internal|open|static|class|final|override|mutating)\s+)*func\s+(?P<name>\w+)`),
+	},
+}
+
+func jsRules() []symbolRule {
+	return []symbolRule{
+		rule("function", `^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:async\s+)?function\s*\*?\s*`+jsName),
+		rule("class", `^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?class\s+`+jsName),
+		rule("interface", `^\s*(?:export\s+)?(?:declare\s+)?interface\s+`+jsName),
+		rule("type", `^\s*(?:export\s+)?(?:declare\s+)?type\s+`+jsName+`\s*(?:<[^=]*>)?\s*=`),
+		rule("enum", `^\s*(?:export\s+)?(?:declare\s+)?(?:const\s+)?enum\s+`+jsName),
+		rule("function", `^\s*(?:export\s+)?(?:const|let|var)\s+`+jsName+`\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|(?:<[^>]*>)?\([^)]*\)?\s*(?::\s*[^=]+)?=>|[A-Za-z_$][\w$]*\s*=>)`),
+		memberRule("method", `^\s+(?:(?:public|private|protected|static|async|readonly|override|abstract|get|set)\s+)*\*?`+jsName+`\s*(?:<[^>]*>)?\s*\([^;]*\)\s*(?::\s*[^{;]+)?\{\s*$`),
+	}
+}
+
+// notNames are keywords the member rules would otherwise take for method
+// names or return types, e.g. in "if (ready) {" or "return new Foo() {"
+var notNames = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true,
+	"function": true, "new": true, "else": true, "do": true, "try": true, "throw": true,
+	"typeof": true, "await": true, "yield": true, "super": true, "this": true, "using": true,
+	"lock": true, "foreach": true, "synchronized": true, "when": true, "sizeof": true, "var": true,
+	"let": true, "const": true, "delete": true, "in": true, "is": true, "as": true, "case": true,
+}
+
+// packagePatterns find the package or namespace a file declares
+var packagePatterns = map[string]*regexp.Regexp{
+	"java":   regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`),
+	"kotlin": regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)`),
+	"scala":  regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)`),
+	"csharp": regexp.MustCompile(`(?m)^\s*namespace\s+([\w.]+)`),
+	"php":    regexp.MustCompile(`(?m)^\s*namespace\s+([\w\\]+)\s*[;{]`),
+}
+
+// SupportsSymbols reports whether declarations of a language are extracted
+func SupportsSymbols(language string) bool {
+	_, ok := symbolRules[language]
+	return ok || language == "go" || language == "python"
+}
+
+// ExtractSymbols finds the declarations of a file's functions, methods,
+// types and constants with their line ranges. Go files are parsed; other
+// supported languages are matched line by line, with the end of a
+// declaration found from its braces (or indentation, for Python). Files of
+// other languages have no symbols.
+func ExtractSymbols(language, content string) []storage.CodeSymbol {
+	var symbols []storage.CodeSymbol
+	switch language {
+	case "go":
+		symbols = goSymbols(content)
+	case "python":
+		symbols = pythonSymbols(content)
+	default:
+		rules, ok := symbolRules[language]
+		if !ok {
+			return nil
+		}
+		symbols = braceSymbols(language, content, rules)
+	}
+	for i := range symbols {
+		symbols[i].Language = language
+	}
+	return symbols
+}
+
+// FileSymbols reads an indexed file and extracts its symbols
+func FileSymbols(file *storage.IndexedFile) ([]storage.CodeSymbol, error) {
+	if !SupportsSymbols(file.Language) {
+		return nil, nil
+	}
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractSymbols(file.Language, string(content)), nil
+}
+
+// IndexFileSymbols replaces the stored symbols of an indexed file with the
+// ones it declares now. Call it after the file record is saved.
+func IndexFileSymbols(store *storage.CodeIndexStorage, file *storage.IndexedFile) error {
+	symbols, err := FileSymbols(file)
+	if err != nil {
+		return err
+	}
+	return store.ReplaceFileSymbols(file, symbols)
+}
+
+// goSymbols parses Go source. A file with syntax errors still yields the
+// declarations before the error.
+func goSymbols(content string) []storage.CodeSymbol {
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if file == nil {
+		return nil
+	}
+	lines := strings.Split(content, "\n")
+	pkg := ""
+	if file.Name != nil {
+		pkg = file.Name.Name
+	}
+
+	var symbols []storage.CodeSymbol
+	add := func(name, kind, container string, from, to token.Pos) {
+		if name == "_" {
+			return
+		}
+		start, end := fset.Position(from).Line, fset.Position(to).Line
+		symbols = append(symbols, storage.CodeSymbol{
+			Name:      name,
+			Kind:      kind,
+			Container: container,
+			Package:   pkg,
+			StartLine: start,
+			EndLine:   end,
+			Signature: signatureLine(lines, start),
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				add(decl.Name.Name, "method", receiverType(decl.Recv.List[0].Type), decl.Pos(), decl.End())
+			} else {
+				add(decl.Name.Name, "function", "", decl.Pos(), decl.End())
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				// A declaration without parentheses spans its keyword
+				from, to := spec.Pos(), spec.End()
+				if !decl.Lparen.IsValid() {
+					from, to = decl.Pos(), decl.End()
+				}
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					kind := "type"
+					switch spec.Type.(type) {
+					case *ast.StructType:
+						kind = "struct"
+					case *ast.InterfaceType:
+						kind = "interface"
+					}
+					add(spec.Name.Name, kind, "", from, to)
+				case *ast.ValueSpec:
+					kind := "var"
+					if decl.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, name := range spec.Names {
+						add(name.Name, kind, "", from, to)
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// receiverType returns the type name of a method receiver such as *Cache[K]
+func receiverType(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+var (
+	pythonDef   = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+(\w+)`)
+	pythonClass = regexp.MustCompile(`^(\s*)class\s+(\w+)`)
+)
+
+// pythonSymbols finds classes and functions, which end before the next line
+// indented no deeper than their own. Functions directly in a class are its
+// methods.
+func pythonSymbols(content string) []storage.CodeSymbol {
+	lines := strings.Split(content, "\n")
+	type scope struct {
+		name   string
+		class  bool
+		indent int
+	}
+	var scopes []scope
+	var symbols []storage.CodeSymbol
+
+	for i, line := range lines {
+		match, kind := pythonClass.FindStringSubmatch(line), "class"
+		if match == nil {
+			match, kind = pythonDef.FindStringSubmatch(line), "function"
+		}
+		if match == nil {
+			continue
+		}
+		indent := indentWidth(match[1])
+		for len(scopes) > 0 && scopes[len(scopes)-1].indent >= indent {
+			scopes = scopes[:len(scopes)-1]
+		}
+		container := ""
+		if len(scopes) > 0 && scopes[len(scopes)-1].class {
+			container = scopes[len(scopes)-1].name
+			if kind == "function" {
+				kind = "method"
+			}
+		}
+		scopes = append(scopes, scope{name: match[2], class: kind == "class", indent: indent})
+
+		end := i
+		for j := i + 1; j < len(lines); j++ {
+			trimmed := strings.TrimSpace(lines[j])
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if indentWidth(lines[j]) <= indent && !strings.HasPrefix(trimmed, ")") {
+				break
+			}
+			end = j
+		}
+		symbols = append(symbols, storage.CodeSymbol{
+			Name:      match[2],
+			Kind:      kind,
+			Container: container,
+			StartLine: i + 1,
+			EndLine:   end + 1,
+			Signature: signatureLine(lines, i+1),
+		})
+	}
+	return symbols
+}
+
+// braceSymbols matches declarations line by line and finds where each ends
+// from its braces. Methods get the innermost class-like declaration
+// containing them as container.
+func braceSymbols(language, content string, rules []symbolRule) []storage.CodeSymbol {
+	lines := strings.Split(content, "\n")
+	pkg := ""
+	if pattern := packagePatterns[language]; pattern != nil {
+		if match := pattern.FindStringSubmatch(content); match != nil {
+			pkg = match[1]
+		}
+	}
+
+	type found struct {
+		storage.CodeSymbol
+		member, hidden bool
+	}
+	var all []found
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "/*") || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		for _, r := range rules {
+			match := r.pattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			name := match[r.pattern.SubexpIndex("name")]
+			if r.member && notNames[name] {
+				continue
+			}
+			if t := r.pattern.SubexpIndex("type"); t >= 0 && notNames[match[t]] {
+				continue
+			}
+			all = append(all, found{
+				CodeSymbol: storage.CodeSymbol{
+					Name:      name,
+					Kind:      r.kind,
+					Package:   pkg,
+					StartLine: i + 1,
+					EndLine:   blockEnd(lines, i) + 1,
+					Signature: signatureLine(lines, i+1),
+				},
+				member: r.member,
+				hidden: r.hidden,
+			})
+			break
+		}
+	}
+
+	var symbols []storage.CodeSymbol
+	for i, symbol := range all {
+		// The innermost enclosing container is the last one starting before
+		for j := i - 1; j >= 0; j-- {
+			outer := all[j]
+			if containerKinds[outer.Kind] && outer.EndLine >= symbol.EndLine && outer.StartLine < symbol.StartLine {
+				symbol.Container = outer.Name
+				break
+			}
+		}
+		if symbol.hidden || (symbol.member && symbol.Container == "") {
+			continue
+		}
+		if symbol.Container != "" && symbol.Kind == "function" {
+			symbol.Kind = "method"
+		}
+		symbols = append(symbols, symbol.CodeSymbol)
+	}
+	sort.SliceStable(symbols, func(a, b int) bool { return symbols[a].StartLine < symbols[b].StartLine })
+	return symbols
+}
+
+// blockEnd returns the index of the line closing the braces opened by the
+// declaration starting at line start. A declaration without a body ends at
+// its semicolon, or at its last line when the next one neither opens the
+// body nor continues it. Braces in strings and comments are skipped.
+func blockEnd(lines []string, start int) int {
+	depth, parens := 0, 0
+	opened := false
+	inBlockComment := false
+	var quote byte
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		for j := 0; j < len(line); j++ {
+			c := line[j]
+			switch {
+			case inBlockComment:
+				if c == '*' && j+1 < len(line) && line[j+1] == '/' {
+					inBlockComment = false
+					j++
+				}
+			case quote != 0:
+				if c == '\\' {
+					j++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '/' && j+1 < len(line) && line[j+1] == '/':
+				j = len(line)
+			case c == '/' && j+1 < len(line) && line[j+1] == '*':
+				inBlockComment = true
+				j++
+			case c == '"' || c == '\'' || c == '`':
+				quote = c
+			case c == '(':
+				parens++
+			case c == ')':
+				parens--
+			case c == '{':
+				depth++
+				opened = true
+			case c == '}':
+				depth--
+				if opened && depth <= 0 {
+					return i
+				}
+			case c == ';' && !opened && parens <= 0:
+				return i
+			}
+		}
+		// Only template literals span lines
+		if quote != '`' {
+			quote = 0
+		}
+		if !opened && parens <= 0 && !continues(line, lines[i+1:]) {
+			return i
+		}
+	}
+	if !opened {
+		return start
+	}
+	return len(lines) - 1
+}
+
+// continues reports whether a declaration whose body has not opened goes on
+// after line: it ends with an operator, or the next line opens the body or
+// adds to the signature
+func continues(line string, rest []string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, suffix := range []string{",", "=", "=>", "->", ":", "(", "<"} {
+		if strings.HasSuffix(trimmed, suffix) {
+			return true
+		}
+	}
+	for _, next := range rest {
+		next = strings.TrimSpace(next)
+		if next == "" {
+			continue
+		}
+		for _, prefix := range []string{"{", ":", "=>", "->", "throws", "extends", "implements", "where", "with"} {
+			if strings.HasPrefix(next, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// signatureLine returns a declaration's first line, trimmed
+func signatureLine(lines []string, line int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	signature := strings.TrimSpace(lines[line-1])
+	if len(signature) > 200 {
+		signature = signature[:200]
+	}
+	return signature
+}
+
+func indentWidth(line string) int {
+	width := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
@@ -0,0 +1,160 @@
+package scanner
+
+import (
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// symbolSpan is the part of a symbol the tests compare
+type symbolSpan struct {
+	Name, Kind, Container string
+	StartLine, EndLine    int
+}
+
+func spans(symbols []storage.CodeSymbol) []symbolSpan {
+	var out []symbolSpan
+	for _, s := range symbols {
+		out = append(out, symbolSpan{s.Name, s.Kind, s.Container, s.StartLine, s.EndLine})
+	}
+	return out
+}
+
+func TestExtractSymbolsGo(t *testing.T) {
+	content := `package store
+
+// Client talks to the store
+type Client struct {
+	name string
+}
+
+const MaxItems = 10
+
+func NewClient(name string) *Client {
+	return &Client{name: name}
+}
+
+func (c *Client) Close() error {
+	return nil
+}
+`
+	symbols := ExtractSymbols("go", content)
+	assert.Equal(t, []symbolSpan{
+		{"Client", "struct", "", 4, 6},
+		{"MaxItems", "const", "", 8, 8},
+		{"NewClient", "function", "", 10, 12},
+		{"Close", "method", "Client", 14, 16},
+	}, spans(symbols))
+	assert.Equal(t, "store", symbols[0].Package)
+	assert.Equal(t, "func (c *Client) Close() error {", symbols[3].Signature)
+
+	assert.Empty(t, ExtractSymbols("go", "package broken\nfunc {"))
+}
+
+func TestExtractSymbolsPython(t *testing.T) {
+	content := `import os
+
+class Store:
+    def __init__(self):
+        self.items = []
+
+    def add(self, item):
+        def check(x):
+            return x
+        self.items.append(check(item))
+
+
+def helper(x):
+    return x
+`
+	assert.Equal(t, []symbolSpan{
+		{"Store", "class", "", 3, 10},
+		{"__init__", "method", "Store", 4, 5},
+		{"add", "method", "Store", 7, 10},
+		{"check", "function", "", 8, 9},
+		{"helper", "function", "", 13, 14},
+	}, spans(ExtractSymbols("python", content)))
+}
+
+func TestExtractSymbolsTypeScript(t *testing.T) {
+	content := `export interface Options {
+  name: string;
+}
+
+export class Client {
+  constructor(private opts: Options) {
+  }
+
+  async fetch(id: string): Promise<string> {
+    if (id) {
+      return "}";
+    }
+    return "";
+  }
+}
+
+export const handler = async (req) => {
+  return req;
+};
+`
+	assert.Equal(t, []symbolSpan{
+		{"Options", "interface", "", 1, 3},
+		{"Client", "class", "", 5, 15},
+		{"constructor", "method", "Client", 6, 7},
+		{"fetch", "method", "Client", 9, 14},
+		{"handler", "function", "", 17, 19},
+	}, spans(ExtractSymbols("typescript", content)))
+}
+
+func TestExtractSymbolsJava(t *testing.T) {
+	content := `package com.example.store;
+
+public class Store {
+    private final List<String> items;
+
+    public void add(String item) {
+        if (item != null) {
+            items.add(item);
+        }
+    }
+
+    abstract int size();
+}
+`
+	symbols := ExtractSymbols("java", content)
+	assert.Equal(t, []symbolSpan{
+		{"Store", "class", "", 3, 13},
+		{"add", "method", "Store", 6, 10},
+	}, spans(symbols))
+	assert.Equal(t, "com.example.store", symbols[0].Package)
+}
+
+func TestExtractSymbolsRust(t *testing.T) {
+	content := `pub struct Store {
+    items: Vec<String>,
+}
+
+impl Store {
+    pub fn new() -> Self {
+        Store { items: vec![] }
+    }
+}
+
+fn helper() {}
+`
+	assert.Equal(t, []symbolSpan{
+		{"Store", "struct", "", 1, 3},
+		{"new", "method", "Store", 6, 8},
+		{"helper", "function", "", 11, 11},
+	}, spans(ExtractSymbols("rust", content)))
+}
+
+func TestSupportsSymbols(t *testing.T) {
+	assert.True(t, SupportsSymbols("go"))
+	assert.True(t, SupportsSymbols("python"))
+	assert.True(t, SupportsSymbols("kotlin"))
+	assert.False(t, SupportsSymbols("markdown"))
+	assert.Nil(t, ExtractSymbols("markdown", "# Title\n"))
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MaxPersonaBytes caps a subagent's system prompt and persona document each
+const MaxPersonaBytes = 64 * 1024
+
+// SetAgentPersona updates the system prompt and/or persona document of a
+// registered subagent; nil leaves a field unchanged. It returns the updated
+// subagent.
+func (s *SubchatStorage) SetAgentPersona(name string, systemPrompt, persona *string) (*Subagent, error) {
+	if systemPrompt == nil && persona == nil {
+		return nil, fmt.Errorf("systemPrompt or persona is required")
+	}
+	for _, field := range []*string{systemPrompt, persona} {
+		if field != nil && len(*field) > MaxPersonaBytes {
+			return nil, fmt.Errorf("systemPrompt and persona must be at most %d bytes each", MaxPersonaBytes)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set := bson.M{"personaUpdatedAt": time.Now().UTC()}
+	if systemPrompt != nil {
+		set["systemPrompt"] = *systemPrompt
+	}
+	if persona != nil {
+		set["persona"] = *persona
+	}
+
+	var subagent Subagent
+	err := s.subagentCollection.FindOneAndUpdate(ctx,
+		bson.M{"name": name},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&subagent)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errcode.New(errcode.NotFound, "subagent not found: %s", name)
+		}
+		return nil, fmt.Errorf("failed to update subagent persona: %w", err)
+	}
+	return &subagent, nil
+}
+
+// Bootstrap knowledge limits
+const (
+	DefaultBootstrapLimit  = 5
+	MaxBootstrapLimit      = 20
+	MaxBootstrapCollection = 10
+)
+
+// SetAgentBootstrap replaces the bootstrap collections of a registered
+// subagent: when the agent claims a task, the top entries of these collections
+// for the task are returned inline. No collections detaches the pack.
+func (s *SubchatStorage) SetAgentBootstrap(name string, collections []string, limit int) (*Subagent, error) {
+	if len(collections) > MaxBootstrapCollection {
+		return nil, fmt.Errorf("bootstrap collections must be at most %d", MaxBootstrapCollection)
+	}
+	if limit < 0 || limit > MaxBootstrapLimit {
+		return nil, fmt.Errorf("bootstrap limit must be between 0 and %d", MaxBootstrapLimit)
+	}
+	for _, collection := range collections {
+		if collection == "" {
+			return nil, fmt.Errorf("bootstrap collection names cannot be empty")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"bootstrapCollections": collections, "bootstrapLimit": limit}}
+	if len(collections) == 0 {
+		update = bson.M{"$unset": bson.M{"bootstrapCollections": "", "bootstrapLimit": ""}}
+	}
+
+	var subagent Subagent
+	err := s.subagentCollection.FindOneAndUpdate(ctx,
+		bson.M{"name": name},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&subagent)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errcode.New(errcode.NotFound, "subagent not found: %s", name)
+		}
+		return nil, fmt.Errorf("failed to update subagent bootstrap collections: %w", err)
+	}
+	return &subagent, nil
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAgentPersonaValidation(t *testing.T) {
+	s := &SubchatStorage{}
+
+	_, err := s.SetAgentPersona("go-dev", nil, nil)
+	assert.ErrorContains(t, err, "is required")
+
+	huge := strings.Repeat("x", MaxPersonaBytes+1)
+	_, err = s.SetAgentPersona("go-dev", nil, &huge)
+	assert.ErrorContains(t, err, "must be at most")
+}
+
+func TestSetAgentBootstrapValidation(t *testing.T) {
+	s := &SubchatStorage{}
+
+	_, err := s.SetAgentBootstrap("go-dev", []string{"adr"}, MaxBootstrapLimit+1)
+	assert.ErrorContains(t, err, "must be between")
+
+	_, err = s.SetAgentBootstrap("go-dev", []string{"adr", ""}, 3)
+	assert.ErrorContains(t, err, "cannot be empty")
+
+	_, err = s.SetAgentBootstrap("go-dev", make([]string, MaxBootstrapCollection+1), 3)
+	assert.ErrorContains(t, err, "must be at most")
+}
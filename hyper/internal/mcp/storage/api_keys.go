@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// APIKeyPrefix starts every generated API key secret
+const APIKeyPrefix = "hk_"
+
+// ErrAPIKeyExists is returned when an active key already has the name
+var ErrAPIKeyExists error = errcode.New(errcode.Conflict, "an active API key with this name already exists")
+
+// APIKeyRecord is an API key managed through the admin API. Only the hash of
+// its secret is stored; the secret is returned once, when the key is created.
+type APIKeyRecord struct {
+	Hash       string     `bson:"_id" json:"-"` // Hex SHA-256 of the secret, as middleware.HashAPIKey computes it
+	Name       string     `bson:"name" json:"name"`
+	Hint       string     `bson:"hint" json:"hint"` // Start of the secret, to tell keys apart
+	Scopes     []string   `bson:"scopes" json:"scopes"`
+	CreatedBy  string     `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `bson:"createdAt" json:"createdAt"`
+	LastUsedAt *time.Time `bson:"lastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+}
+
+// APIKeyStorage handles persistence of API keys
+type APIKeyStorage struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+// NewAPIKeyStorage creates a new API key storage
+func NewAPIKeyStorage(db *mongo.Database, logger *zap.Logger) *APIKeyStorage {
+	return &APIKeyStorage{
+		collection: db.Collection(CollectionName("api_keys")),
+		logger:     logger,
+	}
+}
+
+// CreateAPIKey generates a key named name with scopes and returns its secret.
+// Names of active keys are unique.
+func (s *APIKeyStorage) CreateAPIKey(name string, scopes []string, createdBy string) (string, *APIKeyRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := s.collection.CountDocuments(ctx, bson.M{"name": name, "revokedAt": bson.M{"$exists": false}})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to check API key name %s: %w", name, err)
+	}
+	if count > 0 {
+		return "", nil, fmt.Errorf("%w: %s", ErrAPIKeyExists, name)
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret := APIKeyPrefix + hex.EncodeToString(random)
+	sum := sha256.Sum256([]byte(secret))
+
+	record := &APIKeyRecord{
+		Hash:      hex.EncodeToString(sum[:]),
+		Name:      name,
+		Hint:      secret[:len(APIKeyPrefix)+6],
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().UTC(),
+	}
+	if _, err := s.collection.InsertOne(ctx, record); err != nil {
+		return "", nil, fmt.Errorf("failed to create API key %s: %w", name, err)
+	}
+
+	s.logger.Info("API key created",
+		zap.String("name", name),
+		zap.Strings("scopes", scopes),
+		zap.String("createdBy", createdBy))
+	return secret, record, nil
+}
+
+// GetActiveAPIKey returns the unrevoked key whose secret has the given
+// SHA-256 hash, or nil
+func (s *APIKeyStorage) GetActiveAPIKey(hash string) (*APIKeyRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var record APIKeyRecord
+	err := s.collection.FindOne(ctx, bson.M{"_id": hash, "revokedAt": bson.M{"$exists": false}}).Decode(&record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &record, nil
+}
+
+// HasActiveAPIKeys reports whether any unrevoked key exists
+func (s *APIKeyStorage) HasActiveAPIKeys() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := s.collection.CountDocuments(ctx, bson.M{"revokedAt": bson.M{"$exists": false}}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to check for API keys: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ListAPIKeys returns all keys, revoked ones included, newest first
+func (s *APIKeyStorage) ListAPIKeys() ([]*APIKeyRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	records := []*APIKeyRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode API keys: %w", err)
+	}
+	return records, nil
+}
+
+// RevokeAPIKey stops the active key named name from authenticating
+func (s *APIKeyStorage) RevokeAPIKey(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"name": name, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": time.Now().UTC()}})
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key %s: %w", name, err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no active API key found: %s", name)
+	}
+
+	s.logger.Info("API key revoked", zap.String("name", name))
+	return nil
+}
+
+// RecordAPIKeyUse sets the last use time of a key
+func (s *APIKeyStorage) RecordAPIKeyUse(hash string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": hash}, bson.M{"$max": bson.M{"lastUsedAt": at.UTC()}})
+	if err != nil {
+		return fmt.Errorf("failed to record API key use: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Task lifecycle events automation hooks run on
+const (
+	HookEventTaskCreated       = "task_created"        // Human task created or cloned
+	HookEventAgentTaskCreated  = "agent_task_created"  // Agent task created
+	HookEventTodoCompleted     = "todo_completed"      // TODO of an agent task completed
+	HookEventTaskStatusChanged = "task_status_changed" // Human or agent task changed status
+)
+
+// HookEvents lists the supported hook events
+var HookEvents = []string{HookEventTaskCreated, HookEventAgentTaskCreated, HookEventTodoCompleted, HookEventTaskStatusChanged}
+
+// AutomationHook is an operator script run on a task lifecycle event
+type AutomationHook struct {
+	Name      string     `bson:"_id" json:"name"`
+	Event     string     `bson:"event" json:"event"`                             // One of HookEvents
+	Script    string     `bson:"script" json:"script"`                           // Hook script source
+	Enabled   bool       `bson:"enabled" json:"enabled"`                         // Disabled hooks are kept but not run
+	CreatedAt time.Time  `bson:"createdAt" json:"createdAt"`                     // First saved
+	UpdatedAt time.Time  `bson:"updatedAt" json:"updatedAt"`                     // Last configuration change
+	LastRunAt *time.Time `bson:"lastRunAt,omitempty" json:"lastRunAt,omitempty"` // Last run, successful or not
+	LastError string     `bson:"lastError,omitempty" json:"lastError,omitempty"` // Error of the last failed run
+}
+
+// ValidateAutomationHook checks a hook's name and event. Scripts are compiled
+// by the automation package before they are saved.
+func ValidateAutomationHook(hook *AutomationHook) error {
+	hook.Name = strings.TrimSpace(hook.Name)
+	if hook.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	valid := false
+	for _, event := range HookEvents {
+		valid = valid || hook.Event == event
+	}
+	if !valid {
+		return fmt.Errorf("invalid event %q: must be one of %s", hook.Event, strings.Join(HookEvents, ", "))
+	}
+	if strings.TrimSpace(hook.Script) == "" {
+		return fmt.Errorf("script is required")
+	}
+	return nil
+}
+
+// AutomationHookStorage handles persistence of automation hooks
+type AutomationHookStorage struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+// NewAutomationHookStorage creates a new automation hook storage
+func NewAutomationHookStorage(db *mongo.Database, logger *zap.Logger) *AutomationHookStorage {
+	return &AutomationHookStorage{
+		collection: db.Collection(CollectionName("automation_hooks")),
+		logger:     logger,
+	}
+}
+
+// GetHook returns a hook by name, or nil if it does not exist
+func (s *AutomationHookStorage) GetHook(name string) (*AutomationHook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var hook AutomationHook
+	err := s.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&hook)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get automation hook %s: %w", name, err)
+	}
+	return &hook, nil
+}
+
+// SetHook creates or replaces a hook, keeping its creation time. The run
+// history is reset, since it described the previous script.
+func (s *AutomationHookStorage) SetHook(hook *AutomationHook) error {
+	if err := ValidateAutomationHook(hook); err != nil {
+		return err
+	}
+
+	existing, err := s.GetHook(hook.Name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	hook.CreatedAt = now
+	if existing != nil {
+		hook.CreatedAt = existing.CreatedAt
+	}
+	hook.UpdatedAt = now
+	hook.LastRunAt, hook.LastError = nil, ""
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": hook.Name}, hook, options.Replace().SetUpsert(true)); err != nil {
+		s.logger.Error("Failed to save automation hook", zap.String("name", hook.Name), zap.Error(err))
+		return fmt.Errorf("failed to save automation hook %s: %w", hook.Name, err)
+	}
+
+	s.logger.Info("Automation hook saved",
+		zap.String("name", hook.Name),
+		zap.String("event", hook.Event),
+		zap.Bool("enabled", hook.Enabled))
+	return nil
+}
+
+// ListHooks returns all hooks sorted by name
+func (s *AutomationHookStorage) ListHooks() ([]*AutomationHook, error) {
+	return s.find(bson.M{})
+}
+
+// ListEnabledHooks returns the enabled hooks of an event, sorted by name so
+// they run in a predictable order
+func (s *AutomationHookStorage) ListEnabledHooks(event string) ([]*AutomationHook, error) {
+	return s.find(bson.M{"event": event, "enabled": true})
+}
+
+func (s *AutomationHookStorage) find(filter bson.M) ([]*AutomationHook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list automation hooks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	hooks := []*AutomationHook{}
+	if err := cursor.All(ctx, &hooks); err != nil {
+		return nil, fmt.Errorf("failed to decode automation hooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// DeleteHook removes a hook
+func (s *AutomationHookStorage) DeleteHook(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return fmt.Errorf("failed to delete automation hook %s: %w", name, err)
+	}
+	if result.DeletedCount == 0 {
+		return errcode.New(errcode.NotFound, "automation hook not found: %s", name)
+	}
+	return nil
+}
+
+// RecordRun records a hook run; a nil runErr clears the last error
+func (s *AutomationHookStorage) RecordRun(name string, at time.Time, runErr error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"lastRunAt": at}, "$unset": bson.M{"lastError": ""}}
+	if runErr != nil {
+		update = bson.M{"$set": bson.M{"lastRunAt": at, "lastError": runErr.Error()}}
+	}
+
+	if _, err := s.collection.UpdateOne(ctx, bson.M{"_id": name}, update); err != nil {
+		return fmt.Errorf("failed to record automation hook run for %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAutomationHook(t *testing.T) {
+	hook := &AutomationHook{Name: "  tagger ", Event: HookEventTaskCreated, Script: `tag("a")`}
+	require.NoError(t, ValidateAutomationHook(hook))
+	assert.Equal(t, "tagger", hook.Name)
+
+	assert.ErrorContains(t, ValidateAutomationHook(&AutomationHook{Event: HookEventTaskCreated, Script: `tag("a")`}), "name is required")
+	assert.ErrorContains(t, ValidateAutomationHook(&AutomationHook{Name: "x", Event: "task_deleted", Script: `tag("a")`}), "invalid event")
+	assert.ErrorContains(t, ValidateAutomationHook(&AutomationHook{Name: "x", Event: HookEventTodoCompleted, Script: "  "}), "script is required")
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ChunkCompressionEnv selects the codec chunk texts are stored with:
+// "zstd" (default) or "none". Existing chunks stay readable whatever is
+// selected; they are rewritten with the selected codec when re-indexed.
+const ChunkCompressionEnv = "CODE_INDEX_CHUNK_COMPRESSION"
+
+// ChunkCompressionNone stores chunk texts as plain strings
+const ChunkCompressionNone = "none"
+
+// ChunkCodec compresses chunk texts stored in MongoDB. The codec name is
+// stored with each chunk, so a codec must keep decoding what it encoded
+// under that name.
+type ChunkCodec interface {
+	Name() string
+	Encode(src []byte) []byte
+	Decode(src []byte) ([]byte, error)
+}
+
+var (
+	chunkCodecsMu sync.RWMutex
+	chunkCodecs   = map[string]ChunkCodec{}
+)
+
+func init() {
+	RegisterChunkCodec(newZstdCodec())
+}
+
+// RegisterChunkCodec makes a codec available to CODE_INDEX_CHUNK_COMPRESSION
+// and to reading chunks stored with it
+func RegisterChunkCodec(codec ChunkCodec) {
+	chunkCodecsMu.Lock()
+	defer chunkCodecsMu.Unlock()
+	chunkCodecs[codec.Name()] = codec
+}
+
+// ChunkCodecByName returns a registered codec; "none" returns nil
+func ChunkCodecByName(name string) (ChunkCodec, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == ChunkCompressionNone {
+		return nil, nil
+	}
+	chunkCodecsMu.RLock()
+	defer chunkCodecsMu.RUnlock()
+	codec, ok := chunkCodecs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown chunk compression '%s'", name)
+	}
+	return codec, nil
+}
+
+// ChunkCodecFromEnv returns the codec selected by CODE_INDEX_CHUNK_COMPRESSION,
+// zstd when unset
+func ChunkCodecFromEnv() (ChunkCodec, error) {
+	name := os.Getenv(ChunkCompressionEnv)
+	if name == "" {
+		name = "zstd"
+	}
+	codec, err := ChunkCodecByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ChunkCompressionEnv, err)
+	}
+	return codec, nil
+}
+
+// zstdCodec compresses with zstd; its encoder and decoder are safe for
+// concurrent EncodeAll and DecodeAll calls
+type zstdCodec struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func newZstdCodec() *zstdCodec {
+	// Creating an encoder or decoder without a reader or writer cannot fail
+	encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	decoder, _ := zstd.NewReader(nil)
+	return &zstdCodec{encoder: encoder, decoder: decoder}
+}
+
+func (c *zstdCodec) Name() string { return "zstd" }
+
+func (c *zstdCodec) Encode(src []byte) []byte {
+	return c.encoder.EncodeAll(src, nil)
+}
+
+func (c *zstdCodec) Decode(src []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(src, nil)
+}
+
+// compressChunk returns the document stored for chunk: with codec, the
+// content is moved to CompressedContent when that makes it smaller
+func compressChunk(chunk *FileChunk, codec ChunkCodec) *FileChunk {
+	stored := *chunk
+	stored.CompressedContent = nil
+	stored.Compression = ""
+	stored.ContentSize = 0
+	if codec == nil || chunk.Content == "" {
+		return &stored
+	}
+
+	compressed := codec.Encode([]byte(chunk.Content))
+	if len(compressed) >= len(chunk.Content) {
+		return &stored
+	}
+	stored.CompressedContent = compressed
+	stored.Compression = codec.Name()
+	stored.ContentSize = len(chunk.Content)
+	stored.Content = ""
+	return &stored
+}
+
+// decompressChunk restores the content of a chunk read from MongoDB
+func decompressChunk(chunk *FileChunk) error {
+	if chunk.Compression == "" {
+		return nil
+	}
+	codec, err := ChunkCodecByName(chunk.Compression)
+	if err != nil || codec == nil {
+		return fmt.Errorf("chunk %s/%d: unknown compression '%s'", chunk.FileID, chunk.ChunkNum, chunk.Compression)
+	}
+	content, err := codec.Decode(chunk.CompressedContent)
+	if err != nil {
+		return fmt.Errorf("chunk %s/%d: failed to decompress: %w", chunk.FileID, chunk.ChunkNum, err)
+	}
+	chunk.Content = string(content)
+	chunk.CompressedContent = nil
+	chunk.Compression = ""
+	chunk.ContentSize = 0
+	return nil
+}
+
+// decompressChunks restores the content of chunks read from MongoDB
+func decompressChunks(chunks []*FileChunk) error {
+	for _, chunk := range chunks {
+		if err := decompressChunk(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
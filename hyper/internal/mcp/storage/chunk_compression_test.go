@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCompressChunk_RoundTrip(t *testing.T) {
+	codec, err := ChunkCodecByName("zstd")
+	require.NoError(t, err)
+
+	content := strings.Repeat("func handle(ctx context.Context) error {\n\treturn nil\n}\n", 50)
+	chunk := &FileChunk{FileID: "f-1", ChunkNum: 2, Content: content}
+
+	stored := compressChunk(chunk, codec)
+	assert.Equal(t, content, chunk.Content, "the caller's chunk is left as is")
+	assert.Empty(t, stored.Content)
+	assert.Equal(t, "zstd", stored.Compression)
+	assert.Equal(t, len(content), stored.ContentSize)
+	assert.Less(t, len(stored.CompressedContent), len(content)/10)
+
+	// Round-trip through BSON as MongoDB would store it
+	raw, err := bson.Marshal(stored)
+	require.NoError(t, err)
+	var read FileChunk
+	require.NoError(t, bson.Unmarshal(raw, &read))
+	require.NoError(t, decompressChunk(&read))
+	assert.Equal(t, content, read.Content)
+	assert.Empty(t, read.CompressedContent)
+	assert.Empty(t, read.Compression)
+}
+
+func TestCompressChunk_Uncompressed(t *testing.T) {
+	codec, err := ChunkCodecByName("zstd")
+	require.NoError(t, err)
+
+	small := compressChunk(&FileChunk{Content: "x"}, codec)
+	assert.Equal(t, "x", small.Content, "content that does not shrink is stored plain")
+	assert.Empty(t, small.Compression)
+
+	plain := compressChunk(&FileChunk{Content: strings.Repeat("a", 1000)}, nil)
+	assert.Equal(t, strings.Repeat("a", 1000), plain.Content)
+	assert.Empty(t, plain.CompressedContent)
+
+	// Chunks stored before compression read unchanged
+	legacy := &FileChunk{Content: "legacy"}
+	require.NoError(t, decompressChunk(legacy))
+	assert.Equal(t, "legacy", legacy.Content)
+}
+
+func TestDecompressChunk_Errors(t *testing.T) {
+	err := decompressChunk(&FileChunk{FileID: "f-1", Compression: "lz4", CompressedContent: []byte("x")})
+	assert.ErrorContains(t, err, "unknown compression")
+
+	err = decompressChunk(&FileChunk{FileID: "f-1", Compression: "zstd", CompressedContent: []byte("not zstd")})
+	assert.ErrorContains(t, err, "failed to decompress")
+}
+
+func TestChunkCodecFromEnv(t *testing.T) {
+	t.Setenv(ChunkCompressionEnv, "")
+	codec, err := ChunkCodecFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "zstd", codec.Name())
+
+	t.Setenv(ChunkCompressionEnv, "None")
+	codec, err = ChunkCodecFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, codec)
+
+	t.Setenv(ChunkCompressionEnv, "brotli")
+	_, err = ChunkCodecFromEnv()
+	assert.ErrorContains(t, err, ChunkCompressionEnv)
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExportedCodeChunk is one chunk of a code index export, with the file it
+// belongs to and, when available, its stored embedding
+type ExportedCodeChunk struct {
+	FolderPath    string    `json:"folderPath"`
+	FileID        string    `json:"fileId"`
+	FilePath      string    `json:"filePath"`
+	RelativePath  string    `json:"relativePath"`
+	Language      string    `json:"language"`
+	FileUpdatedAt time.Time `json:"fileUpdatedAt"`
+	ChunkNum      int       `json:"chunkNum"`
+	StartLine     int       `json:"startLine"`
+	EndLine       int       `json:"endLine"`
+	Content       string    `json:"content"`
+	Summary       string    `json:"summary,omitempty"`
+	Vector        []float64 `json:"vector,omitempty"`
+}
+
+// ExportCodeChunks streams the chunks of a folder's files updated at or after
+// since (the zero time exports every file) to visit, file by file in update
+// order. When vectors is set, it returns the stored embeddings of the given
+// chunk vector IDs; chunks without one are exported without a vector.
+func (s *CodeIndexStorage) ExportCodeChunks(ctx context.Context, folder *IndexedFolder, since time.Time, vectors func(ids []string) (map[string][]float64, error), visit func(*ExportedCodeChunk) error) error {
+	filter := bson.M{"folderId": folder.ID}
+	if !since.IsZero() {
+		filter["updatedAt"] = bson.M{"$gte": since}
+	}
+	cursor, err := s.filesCol.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "updatedAt", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("failed to export code index files: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var file IndexedFile
+		if err := cursor.Decode(&file); err != nil {
+			return fmt.Errorf("failed to decode indexed file: %w", err)
+		}
+		chunks, err := s.ListChunks(file.ID)
+		if err != nil {
+			return err
+		}
+
+		var chunkVectors map[string][]float64
+		if vectors != nil && len(chunks) > 0 {
+			ids := make([]string, 0, len(chunks))
+			for _, chunk := range chunks {
+				if chunk.VectorID != "" {
+					ids = append(ids, chunk.VectorID)
+				}
+			}
+			if chunkVectors, err = vectors(ids); err != nil {
+				return fmt.Errorf("failed to export vectors of %s: %w", file.RelativePath, err)
+			}
+		}
+
+		for _, chunk := range chunks {
+			exported := &ExportedCodeChunk{
+				FolderPath:    folder.Path,
+				FileID:        file.ID,
+				FilePath:      file.Path,
+				RelativePath:  file.RelativePath,
+				Language:      file.Language,
+				FileUpdatedAt: file.UpdatedAt,
+				ChunkNum:      chunk.ChunkNum,
+				StartLine:     chunk.StartLine,
+				EndLine:       chunk.EndLine,
+				Content:       chunk.Content,
+				Summary:       chunk.Summary,
+				Vector:        chunkVectors[chunk.VectorID],
+			}
+			if err := visit(exported); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to export code index files: %w", err)
+	}
+	return nil
+}
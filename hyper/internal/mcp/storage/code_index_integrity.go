@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kinds of integrity discrepancies between a chunk and its stored vectors
+const (
+	IntegrityMissingPoint  = "missing_point"  // The chunk's point is not in Qdrant, e.g. after a partial upsert
+	IntegrityMissingVector = "missing_vector" // The point has no vector, or one of the wrong size
+	IntegrityLowSimilarity = "low_similarity" // The stored vector no longer matches a fresh embedding of the chunk
+)
+
+// IntegrityDiscrepancy is a sampled chunk whose stored vectors failed verification
+type IntegrityDiscrepancy struct {
+	FileID     string  `bson:"fileId" json:"fileId"`
+	FilePath   string  `bson:"filePath,omitempty" json:"filePath,omitempty"`
+	ChunkNum   int     `bson:"chunkNum" json:"chunkNum"`
+	VectorID   string  `bson:"vectorId" json:"vectorId"`
+	Collection string  `bson:"collection" json:"collection"`
+	Kind       string  `bson:"kind" json:"kind"`                                 // missing_point, missing_vector or low_similarity
+	Vector     string  `bson:"vector,omitempty" json:"vector,omitempty"`         // Named vector that failed, on collections with named vectors
+	Similarity float64 `bson:"similarity,omitempty" json:"similarity,omitempty"` // Cosine similarity to the fresh embedding (low_similarity)
+}
+
+// IntegrityCheck is one run of the index integrity verification: chunks
+// sampled from MongoDB, re-embedded and compared with their Qdrant vectors
+type IntegrityCheck struct {
+	ID            string                 `bson:"_id" json:"id"`
+	StartedAt     time.Time              `bson:"startedAt" json:"startedAt"`
+	FinishedAt    time.Time              `bson:"finishedAt" json:"finishedAt"`
+	Sampled       int                    `bson:"sampled" json:"sampled"`                                 // Chunks sampled
+	Verified      int                    `bson:"verified" json:"verified"`                               // Chunks whose vectors passed
+	Skipped       int                    `bson:"skipped" json:"skipped"`                                 // Chunks that could not be checked (unknown file, embedding error)
+	MinSimilarity float64                `bson:"minSimilarity" json:"minSimilarity"`                     // Similarity a stored vector needed to pass
+	Discrepancies []IntegrityDiscrepancy `bson:"discrepancies,omitempty" json:"discrepancies,omitempty"` // Chunks that failed
+	Error         string                 `bson:"error,omitempty" json:"error,omitempty"`                 // Why the run stopped early
+}
+
+// RecordIntegrityCheck stores the result of an integrity check run
+func (s *CodeIndexStorage) RecordIntegrityCheck(check *IntegrityCheck) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.integrityCol.InsertOne(ctx, check); err != nil {
+		return fmt.Errorf("failed to record integrity check: %w", err)
+	}
+	return nil
+}
+
+// ListIntegrityChecks returns the latest integrity check runs, newest first
+func (s *CodeIndexStorage) ListIntegrityChecks(limit int) ([]*IntegrityCheck, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "startedAt", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := s.integrityCol.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list integrity checks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	checks := []*IntegrityCheck{}
+	if err := cursor.All(ctx, &checks); err != nil {
+		return nil, fmt.Errorf("failed to decode integrity checks: %w", err)
+	}
+	return checks, nil
+}
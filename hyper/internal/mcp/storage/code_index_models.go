@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -14,6 +15,32 @@ type IndexedFolder struct {
 	FileCount   int       `bson:"fileCount" json:"fileCount"`                     // Number of indexed files
 	Status      string    `bson:"status" json:"status"`                           // active, scanning, error
 	Error       string    `bson:"error,omitempty" json:"error,omitempty"`         // Last error if any
+
+	// ScanConcurrency bounds how many files scans of this folder index at once;
+	// nil uses the SCAN_MIN_CONCURRENCY / SCAN_MAX_CONCURRENCY defaults
+	ScanConcurrency *ScanConcurrency `bson:"scanConcurrency,omitempty" json:"scanConcurrency,omitempty"`
+}
+
+// ScanConcurrency bounds the adaptive number of files a scan indexes
+// concurrently. Zero fields fall back to the defaults.
+type ScanConcurrency struct {
+	Min int `bson:"min,omitempty" json:"min,omitempty"`
+	Max int `bson:"max,omitempty" json:"max,omitempty"`
+}
+
+// MaxScanConcurrency caps the per-folder upper bound
+const MaxScanConcurrency = 64
+
+// Validate checks that the bounds are within 0..MaxScanConcurrency and that
+// Min does not exceed a set Max
+func (c ScanConcurrency) Validate() error {
+	if c.Min < 0 || c.Min > MaxScanConcurrency || c.Max < 0 || c.Max > MaxScanConcurrency {
+		return fmt.Errorf("scan concurrency bounds must be between 0 (default) and %d", MaxScanConcurrency)
+	}
+	if c.Max > 0 && c.Min > c.Max {
+		return fmt.Errorf("minimum scan concurrency %d exceeds maximum %d", c.Min, c.Max)
+	}
+	return nil
 }
 
 // IndexedFile represents a single file in the code index
@@ -30,18 +57,27 @@ type IndexedFile struct {
 	UpdatedAt    time.Time `bson:"updatedAt" json:"updatedAt"`                   // Last update time
 	VectorID     string    `bson:"vectorId,omitempty" json:"vectorId,omitempty"` // Qdrant point ID
 	ChunkCount   int       `bson:"chunkCount" json:"chunkCount"`                 // Number of chunks
+	SymbolsAt    time.Time `bson:"symbolsAt,omitempty" json:"symbolsAt,omitempty"` // When its symbols were last extracted
 }
 
 // FileChunk represents a chunk of a file (for large files)
 type FileChunk struct {
-	ID        string    `bson:"_id,omitempty" json:"id"`
-	FileID    string    `bson:"fileId" json:"fileId"`                 // Reference to IndexedFile
-	ChunkNum  int       `bson:"chunkNum" json:"chunkNum"`             // Chunk number (0-based)
-	Content   string    `bson:"content" json:"content"`               // Chunk content
-	StartLine int       `bson:"startLine" json:"startLine"`           // Starting line number
-	EndLine   int       `bson:"endLine" json:"endLine"`               // Ending line number
-	VectorID  string    `bson:"vectorId,omitempty" json:"vectorId"`   // Qdrant point ID
-	IndexedAt time.Time `bson:"indexedAt" json:"indexedAt"`           // When chunk was indexed
+	ID          string    `bson:"_id,omitempty" json:"id"`
+	FileID      string    `bson:"fileId" json:"fileId"`                               // Reference to IndexedFile
+	ChunkNum    int       `bson:"chunkNum" json:"chunkNum"`                           // Chunk number (0-based)
+	Content     string    `bson:"content" json:"content"`                             // Chunk content
+	StartLine   int       `bson:"startLine" json:"startLine"`                         // Starting line number
+	EndLine     int       `bson:"endLine" json:"endLine"`                             // Ending line number
+	VectorID    string    `bson:"vectorId,omitempty" json:"vectorId"`                 // Qdrant point ID
+	IndexedAt   time.Time `bson:"indexedAt" json:"indexedAt"`                         // When chunk was indexed
+	ContentHash string    `bson:"contentHash,omitempty" json:"contentHash,omitempty"` // SHA-256 of chunk content
+	Summary     string    `bson:"summary,omitempty" json:"summary,omitempty"`         // LLM summary embedded with the content (CODE_INDEX_SUMMARIES)
+
+	// Compressed storage of Content (see CODE_INDEX_CHUNK_COMPRESSION); set only
+	// on stored documents, the storage methods return chunks with Content restored
+	CompressedContent []byte `bson:"contentZ,omitempty" json:"-"`
+	Compression       string `bson:"compression,omitempty" json:"-"` // Codec name
+	ContentSize       int    `bson:"contentSize,omitempty" json:"-"` // Bytes of Content before compression
 }
 
 // SearchResult represents a search result from the code index
@@ -50,14 +86,24 @@ type SearchResult struct {
 	FilePath          string  `json:"filePath"`
 	RelativePath      string  `json:"relativePath"`
 	Language          string  `json:"language"`
+	CommentLanguage   string  `json:"commentLanguage,omitempty"` // Detected natural language of the chunk's comments
 	ChunkNum          int     `json:"chunkNum,omitempty"`
 	StartLine         int     `json:"startLine,omitempty"`
 	EndLine           int     `json:"endLine,omitempty"`
 	Content           string  `json:"content"`
+	Summary           string  `json:"summary,omitempty"`  // One-sentence LLM summary, when enabled
 	Score             float32 `json:"score"`              // Similarity score
 	FolderID          string  `json:"folderId"`
 	FolderPath        string  `json:"folderPath"`
 	FullFileRetrieved bool    `json:"fullFileRetrieved"` // True when retrieve="full" mode
+
+	// Multi-folder routing: Score is RawScore multiplied by FolderWeight
+	RawScore     float32        `json:"rawScore,omitempty"`
+	FolderWeight float64        `json:"folderWeight,omitempty"`
+	Folder       *IndexedFolder `json:"folder,omitempty"` // Metadata of the folder the hit came from
+
+	// Agent tasks that declared this file in filesModified, most recent first
+	RecentTasks []TaskReference `json:"recentTasks,omitempty"`
 }
 
 // IndexStatus represents the current status of the code index
@@ -69,4 +115,10 @@ type IndexStatus struct {
 	ActiveFolders  int       `json:"activeFolders"`
 	ScanningFolders int       `json:"scanningFolders"`
 	ErrorFolders   int       `json:"errorFolders"`
+
+	// Chunk text storage: bytes of chunk content, bytes stored after
+	// compression, and the number of compressed chunks
+	ChunkContentBytes int64 `json:"chunkContentBytes"`
+	ChunkStoredBytes  int64 `json:"chunkStoredBytes"`
+	CompressedChunks  int   `json:"compressedChunks"`
 }
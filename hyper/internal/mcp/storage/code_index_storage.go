@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"hyper/internal/errcode"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -18,16 +20,31 @@ type CodeIndexStorage struct {
 	filesCol        *mongo.Collection
 	chunksCol       *mongo.Collection
 	pathMappingsCol *mongo.Collection
+	profilesCol     *mongo.Collection
+	integrityCol    *mongo.Collection
+	usageCol        *mongo.Collection
+	symbolsCol      *mongo.Collection
+	chunkCodec      ChunkCodec // nil stores chunk texts uncompressed
 }
 
 // NewCodeIndexStorage creates a new MongoDB storage instance
 func NewCodeIndexStorage(db *mongo.Database) (*CodeIndexStorage, error) {
+	chunkCodec, err := ChunkCodecFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	storage := &CodeIndexStorage{
 		db:              db,
-		foldersCol:      db.Collection("indexed_folders"),
-		filesCol:        db.Collection("indexed_files"),
-		chunksCol:       db.Collection("file_chunks"),
-		pathMappingsCol: db.Collection("code_index_map"),
+		foldersCol:      db.Collection(CollectionName("indexed_folders")),
+		filesCol:        db.Collection(CollectionName("indexed_files")),
+		chunksCol:       db.Collection(CollectionName("file_chunks")),
+		pathMappingsCol: db.Collection(CollectionName("code_index_map")),
+		profilesCol:     db.Collection(CollectionName("code_search_profiles")),
+		integrityCol:    db.Collection(CollectionName("code_index_integrity_checks")),
+		usageCol:        db.Collection(CollectionName("code_search_usage")),
+		symbolsCol:      db.Collection(CollectionName("code_symbols")),
+		chunkCodec:      chunkCodec,
 	}
 
 	// Create indexes
@@ -80,6 +97,31 @@ func (s *CodeIndexStorage) createIndexes() error {
 		return fmt.Errorf("failed to create chunk indexes: %w", err)
 	}
 
+	// Integrity checks are listed newest first
+	_, err = s.integrityCol.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "startedAt", Value: -1}}})
+	if err != nil {
+		return fmt.Errorf("failed to create integrity check indexes: %w", err)
+	}
+
+	// Search hits are counted per folder directory
+	_, err = s.usageCol.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "folderPath", Value: 1}, {Key: "dir", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create code search usage indexes: %w", err)
+	}
+
+	// Symbols are looked up by name, exactly or by prefix, and replaced per file
+	_, err = s.symbolsCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "nameLower", Value: 1}}},
+		{Keys: bson.D{{Key: "fileId", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create code symbol indexes: %w", err)
+	}
+
 	return nil
 }
 
@@ -94,6 +136,9 @@ func (s *CodeIndexStorage) AddFolder(path, description string) (*IndexedFolder,
 	}
 
 	result, err := s.foldersCol.InsertOne(context.Background(), folder)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errcode.Wrap(errcode.Conflict, err, "folder already indexed: "+path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert folder: %w", err)
 	}
@@ -127,12 +172,16 @@ func (s *CodeIndexStorage) RemoveFolder(folderID string) error {
 		fileIDs = append(fileIDs, file.ID)
 	}
 
-	// Delete all chunks for these files
+	// Delete all chunks and symbols for these files
 	if len(fileIDs) > 0 {
 		_, err = s.chunksCol.DeleteMany(ctx, bson.M{"fileId": bson.M{"$in": fileIDs}})
 		if err != nil {
 			return fmt.Errorf("failed to delete chunks: %w", err)
 		}
+		_, err = s.symbolsCol.DeleteMany(ctx, bson.M{"fileId": bson.M{"$in": fileIDs}})
+		if err != nil {
+			return fmt.Errorf("failed to delete symbols: %w", err)
+		}
 	}
 
 	// Delete all files for this folder
@@ -156,7 +205,7 @@ func (s *CodeIndexStorage) GetFolder(folderID string) (*IndexedFolder, error) {
 	err := s.foldersCol.FindOne(context.Background(), bson.M{"_id": folderID}).Decode(&folder)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("folder not found: %s", folderID)
+			return nil, errcode.New(errcode.NotFound, "folder not found: %s", folderID)
 		}
 		return nil, fmt.Errorf("failed to get folder: %w", err)
 	}
@@ -235,6 +284,23 @@ func (s *CodeIndexStorage) UpdateFolderScanTime(folderID string, fileCount int)
 	return nil
 }
 
+// SetFolderScanConcurrency stores the scan concurrency bounds of a folder;
+// nil clears them
+func (s *CodeIndexStorage) SetFolderScanConcurrency(folderID string, bounds *ScanConcurrency) error {
+	update := bson.M{"$unset": bson.M{"scanConcurrency": ""}}
+	if bounds != nil {
+		if err := bounds.Validate(); err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"scanConcurrency": bounds}}
+	}
+
+	if _, err := s.foldersCol.UpdateOne(context.Background(), bson.M{"_id": folderID}, update); err != nil {
+		return fmt.Errorf("failed to update folder scan concurrency: %w", err)
+	}
+	return nil
+}
+
 // UpsertFile inserts or updates a file in the index
 func (s *CodeIndexStorage) UpsertFile(file *IndexedFile) error {
 	file.UpdatedAt = time.Now()
@@ -285,7 +351,7 @@ func (s *CodeIndexStorage) GetFile(fileID string) (*IndexedFile, error) {
 	err := s.filesCol.FindOne(context.Background(), bson.M{"_id": fileID}).Decode(&file)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("file not found: %s", fileID)
+			return nil, errcode.New(errcode.NotFound, "file not found: %s", fileID)
 		}
 		return nil, fmt.Errorf("failed to get file: %w", err)
 	}
@@ -321,13 +387,62 @@ func (s *CodeIndexStorage) ListFiles(folderID string) ([]*IndexedFile, error) {
 	return files, nil
 }
 
-// UpsertChunk inserts or updates a file chunk
+// FindFilesDeclaredBy returns the indexed files matched by filesModified-style
+// entries (absolute or relative paths, directories, or glob patterns)
+func (s *CodeIndexStorage) FindFilesDeclaredBy(declared []string) ([]*IndexedFile, error) {
+	if len(declared) == 0 {
+		return nil, nil
+	}
+
+	folders, err := s.ListFolders()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*IndexedFile
+	for _, folder := range folders {
+		files, err := s.ListFiles(folder.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if DeclaresPath(declared, file.Path, file.RelativePath) {
+				matched = append(matched, file)
+			}
+		}
+	}
+
+	return matched, nil
+}
+
+// SetChunkCodec replaces the codec new chunk texts are stored with; nil
+// stores them uncompressed
+func (s *CodeIndexStorage) SetChunkCodec(codec ChunkCodec) {
+	s.chunkCodec = codec
+}
+
+// ChunkCompression returns the name of the codec new chunk texts are stored
+// with, or "none"
+func (s *CodeIndexStorage) ChunkCompression() string {
+	if s.chunkCodec == nil {
+		return ChunkCompressionNone
+	}
+	return s.chunkCodec.Name()
+}
+
+// UpsertChunk inserts or updates a file chunk, compressing its content with
+// the storage's chunk codec
 func (s *CodeIndexStorage) UpsertChunk(chunk *FileChunk) error {
 	chunk.IndexedAt = time.Now()
 
 	opts := options.Update().SetUpsert(true)
 	filter := bson.M{"fileId": chunk.FileID, "chunkNum": chunk.ChunkNum}
-	update := bson.M{"$set": chunk}
+	stored := compressChunk(chunk, s.chunkCodec)
+	update := bson.M{"$set": stored}
+	if stored.Compression == "" {
+		// Drop the compressed content of an earlier version of the chunk
+		update["$unset"] = bson.M{"contentZ": "", "compression": "", "contentSize": ""}
+	}
 
 	_, err := s.chunksCol.UpdateOne(context.Background(), filter, update, opts)
 	if err != nil {
@@ -350,6 +465,9 @@ func (s *CodeIndexStorage) GetChunk(fileID string, chunkNum int) (*FileChunk, er
 		}
 		return nil, fmt.Errorf("failed to get chunk: %w", err)
 	}
+	if err := decompressChunk(&chunk); err != nil {
+		return nil, err
+	}
 	return &chunk, nil
 }
 
@@ -369,17 +487,62 @@ func (s *CodeIndexStorage) ListChunks(fileID string) ([]*FileChunk, error) {
 	if err := cursor.All(context.Background(), &chunks); err != nil {
 		return nil, fmt.Errorf("failed to decode chunks: %w", err)
 	}
+	if err := decompressChunks(chunks); err != nil {
+		return nil, err
+	}
 
 	return chunks, nil
 }
 
-// DeleteFile deletes a file and all its associated chunks
+// SampleChunks returns up to n randomly chosen non-empty chunks across all indexed files
+func (s *CodeIndexStorage) SampleChunks(n int) ([]*FileChunk, error) {
+	cursor, err := s.chunksCol.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"content": bson.M{"$ne": ""}},
+			bson.M{"contentZ": bson.M{"$exists": true}},
+		}}}},
+		{{Key: "$sample", Value: bson.M{"size": n}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample chunks: %w", err)
+	}
+	defer cursor.Close(context.Background())
+
+	var chunks []*FileChunk
+	if err := cursor.All(context.Background(), &chunks); err != nil {
+		return nil, fmt.Errorf("failed to decode chunks: %w", err)
+	}
+	if err := decompressChunks(chunks); err != nil {
+		return nil, err
+	}
+
+	return chunks, nil
+}
+
+// DeleteChunksFrom deletes all chunks of a file with chunkNum >= fromChunkNum
+// (used when a re-indexed file shrinks to fewer chunks)
+func (s *CodeIndexStorage) DeleteChunksFrom(fileID string, fromChunkNum int) error {
+	_, err := s.chunksCol.DeleteMany(context.Background(), bson.M{
+		"fileId":   fileID,
+		"chunkNum": bson.M{"$gte": fromChunkNum},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+// DeleteFile deletes a file and all its associated chunks and symbols
 func (s *CodeIndexStorage) DeleteFile(ctx context.Context, fileID string) error {
 	// Delete all chunks for this file
 	_, err := s.chunksCol.DeleteMany(ctx, bson.M{"fileId": fileID})
 	if err != nil {
 		return fmt.Errorf("failed to delete chunks: %w", err)
 	}
+	_, err = s.symbolsCol.DeleteMany(ctx, bson.M{"fileId": fileID})
+	if err != nil {
+		return fmt.Errorf("failed to delete symbols: %w", err)
+	}
 
 	// Delete the file
 	_, err = s.filesCol.DeleteOne(ctx, bson.M{"_id": fileID})
@@ -448,6 +611,10 @@ func (s *CodeIndexStorage) GetIndexStatus() (*IndexStatus, error) {
 	}
 	status.TotalChunks = int(totalChunks)
 
+	if err := s.chunkStorageStats(ctx, status); err != nil {
+		return nil, err
+	}
+
 	// Get last scan time
 	var lastFolder IndexedFolder
 	opts := options.FindOne().SetSort(bson.D{{Key: "lastScanned", Value: -1}})
@@ -459,6 +626,40 @@ func (s *CodeIndexStorage) GetIndexStatus() (*IndexStatus, error) {
 	return status, nil
 }
 
+// chunkStorageStats adds the bytes of chunk texts, before and after
+// compression, to status
+func (s *CodeIndexStorage) chunkStorageStats(ctx context.Context, status *IndexStatus) error {
+	compressed := bson.M{"$eq": bson.A{bson.M{"$type": "$contentZ"}, "binData"}}
+	plainBytes := bson.M{"$strLenBytes": bson.M{"$ifNull": bson.A{"$content", ""}}}
+	cursor, err := s.chunksCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":        nil,
+			"content":    bson.M{"$sum": bson.M{"$cond": bson.A{compressed, "$contentSize", plainBytes}}},
+			"stored":     bson.M{"$sum": bson.M{"$cond": bson.A{compressed, bson.M{"$binarySize": "$contentZ"}, plainBytes}}},
+			"compressed": bson.M{"$sum": bson.M{"$cond": bson.A{compressed, 1, 0}}},
+		}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to aggregate chunk storage: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if cursor.Next(ctx) {
+		var totals struct {
+			Content    int64 `bson:"content"`
+			Stored     int64 `bson:"stored"`
+			Compressed int   `bson:"compressed"`
+		}
+		if err := cursor.Decode(&totals); err != nil {
+			return fmt.Errorf("failed to decode chunk storage: %w", err)
+		}
+		status.ChunkContentBytes = totals.Content
+		status.ChunkStoredBytes = totals.Stored
+		status.CompressedChunks = totals.Compressed
+	}
+	return cursor.Err()
+}
+
 // CodeIndexMapping represents a path-to-Qdrant-collection mapping
 type CodeIndexMapping struct {
 	Path              string    `bson:"path" json:"path"`
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultSearchProfile is the profile applied when a search names no workspace or agent
+const DefaultSearchProfile = "default"
+
+// FolderWeight is a relevance multiplier for hits from an indexed folder
+type FolderWeight struct {
+	Path   string  `bson:"path" json:"path"`     // Indexed folder path (matches the folder and its subfolders)
+	Weight float64 `bson:"weight" json:"weight"` // Score multiplier; 0 excludes the folder
+}
+
+// SearchProfile configures how code_index_search mixes results across indexed
+// folders for a workspace or agent (e.g. boosting a monorepo over its dependency repos)
+type SearchProfile struct {
+	Name           string         `bson:"_id" json:"name"`                                          // Workspace or agent name
+	FolderWeights  []FolderWeight `bson:"folderWeights,omitempty" json:"folderWeights,omitempty"`   // Per-folder relevance weights
+	AllowedFolders []string       `bson:"allowedFolders,omitempty" json:"allowedFolders,omitempty"` // Folder allow-list; empty allows all
+	UpdatedAt      time.Time      `bson:"updatedAt" json:"updatedAt"`
+}
+
+// FolderCovers reports whether path is root itself or lies beneath it
+func FolderCovers(root, path string) bool {
+	root = filepath.Clean(root)
+	path = filepath.Clean(path)
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+// WeightFor returns the weight for a folder, using the most specific configured
+// path that covers it (later entries win ties). Folders without a configured
+// weight default to 1.
+func (p *SearchProfile) WeightFor(folderPath string) float64 {
+	if p == nil {
+		return 1
+	}
+
+	weight := 1.0
+	matchedLen := -1
+	for _, fw := range p.FolderWeights {
+		if FolderCovers(fw.Path, folderPath) && len(fw.Path) >= matchedLen {
+			weight = fw.Weight
+			matchedLen = len(fw.Path)
+		}
+	}
+	return weight
+}
+
+// Allows reports whether a folder passes the profile's allow-list
+func (p *SearchProfile) Allows(folderPath string) bool {
+	if p == nil || len(p.AllowedFolders) == 0 {
+		return true
+	}
+
+	for _, allowed := range p.AllowedFolders {
+		if FolderCovers(allowed, folderPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSearchProfile retrieves a search profile by name, or nil if none is configured
+func (s *CodeIndexStorage) GetSearchProfile(name string) (*SearchProfile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var profile SearchProfile
+	err := s.profilesCol.FindOne(ctx, bson.M{"_id": name}).Decode(&profile)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get search profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// UpsertSearchProfile creates or replaces a search profile
+func (s *CodeIndexStorage) UpsertSearchProfile(profile *SearchProfile) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	profile.UpdatedAt = time.Now().UTC()
+
+	_, err := s.profilesCol.ReplaceOne(ctx, bson.M{"_id": profile.Name}, profile, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save search profile: %w", err)
+	}
+	return nil
+}
+
+// ListSearchProfiles returns all configured search profiles
+func (s *CodeIndexStorage) ListSearchProfiles() ([]*SearchProfile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.profilesCol.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list search profiles: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	profiles := []*SearchProfile{}
+	if err := cursor.All(ctx, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to decode search profiles: %w", err)
+	}
+	return profiles, nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import "testing"
+
+func TestSearchProfileWeightFor(t *testing.T) {
+	profile := &SearchProfile{
+		FolderWeights: []FolderWeight{
+			{Path: "/repo", Weight: 2},
+			{Path: "/repo/vendor", Weight: 0.5},
+			{Path: "/deps", Weight: 0},
+		},
+	}
+
+	tests := []struct {
+		folder string
+		want   float64
+	}{
+		{"/repo", 2},
+		{"/repo/app", 2},
+		{"/repo/vendor/lib", 0.5},
+		{"/repository", 1},
+		{"/deps", 0},
+		{"/other", 1},
+	}
+
+	for _, tt := range tests {
+		if got := profile.WeightFor(tt.folder); got != tt.want {
+			t.Errorf("WeightFor(%q) = %v, want %v", tt.folder, got, tt.want)
+		}
+	}
+
+	var nilProfile *SearchProfile
+	if got := nilProfile.WeightFor("/repo"); got != 1 {
+		t.Errorf("nil profile WeightFor = %v, want 1", got)
+	}
+}
+
+func TestSearchProfileAllows(t *testing.T) {
+	profile := &SearchProfile{AllowedFolders: []string{"/repo", "/deps/shared/"}}
+
+	tests := []struct {
+		folder string
+		want   bool
+	}{
+		{"/repo", true},
+		{"/repo/sub", true},
+		{"/deps/shared", true},
+		{"/deps/other", false},
+		{"/repo2", false},
+	}
+
+	for _, tt := range tests {
+		if got := profile.Allows(tt.folder); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.folder, got, tt.want)
+		}
+	}
+
+	if !(&SearchProfile{}).Allows("/anything") {
+		t.Error("empty allow-list should allow all folders")
+	}
+}
@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"hyper/internal/console"
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultCodeSearchUsageDepth is how many directory levels below an indexed
+// folder a usage report groups areas by
+const DefaultCodeSearchUsageDepth = 2
+
+// codeSearchHits counts the search results that landed in one directory.
+// Directories are recorded in full and grouped to the report depth later.
+type codeSearchHits struct {
+	FolderPath string    `bson:"folderPath"`
+	Dir        string    `bson:"dir"` // Relative to the folder, slash-separated, "." for its root
+	Hits       int64     `bson:"hits"`
+	FirstHitAt time.Time `bson:"firstHitAt"`
+	LastHitAt  time.Time `bson:"lastHitAt"`
+}
+
+// CodeSearchArea is how much code search uses one area of an indexed folder
+type CodeSearchArea struct {
+	Path      string     `json:"path"` // Directory relative to the folder, "." for files at its root
+	Files     int        `json:"files"`
+	Chunks    int        `json:"chunks"`
+	Hits      int64      `json:"hits"`  // Search results returned from the area
+	Share     float64    `json:"share"` // Fraction of the folder's hits
+	LastHitAt *time.Time `json:"lastHitAt,omitempty"`
+}
+
+// CodeSearchFolderUsage reports where searches of one indexed folder land.
+// Hot areas are what searches use most; never-queried areas hold indexed
+// files no search has returned, so they are candidates to exclude.
+type CodeSearchFolderUsage struct {
+	FolderPath         string            `json:"folderPath"`
+	Files              int               `json:"files"`
+	Chunks             int               `json:"chunks"`
+	Hits               int64             `json:"hits"`
+	Areas              int               `json:"areas"`
+	NeverQueriedCount  int               `json:"neverQueriedCount"`
+	NeverQueriedChunks int               `json:"neverQueriedChunks"` // Chunks indexed in never-queried areas
+	Hot                []*CodeSearchArea `json:"hot"`                // Most hits first
+	NeverQueried       []*CodeSearchArea `json:"neverQueried"`       // Most chunks first
+}
+
+// CodeSearchUsageReport is the code search heatmap of every indexed folder
+type CodeSearchUsageReport struct {
+	Depth        int                      `json:"depth"`
+	TrackedSince *time.Time               `json:"trackedSince,omitempty"` // First recorded hit; older searches are not counted
+	Folders      []*CodeSearchFolderUsage `json:"folders"`
+}
+
+// RecordSearchHits counts the results of a code search against the
+// directories they came from. Usage is best-effort: failures are logged,
+// never returned, so they cannot fail the search.
+func (s *CodeIndexStorage) RecordSearchHits(results []SearchResult) {
+	if s == nil || s.usageCol == nil {
+		return
+	}
+
+	type key struct{ folder, dir string }
+	counts := map[key]int64{}
+	for _, result := range results {
+		dir, ok := resultDir(result)
+		if !ok {
+			continue
+		}
+		counts[key{result.FolderPath, dir}]++
+	}
+	if len(counts) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	models := make([]mongo.WriteModel, 0, len(counts))
+	for k, hits := range counts {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"folderPath": k.folder, "dir": k.dir}).
+			SetUpdate(bson.M{
+				"$inc":         bson.M{"hits": hits},
+				"$set":         bson.M{"lastHitAt": now},
+				"$setOnInsert": bson.M{"firstHitAt": now},
+			}).
+			SetUpsert(true))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := s.usageCol.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		console.Printf("Warning: failed to record code search usage: %v\n", err)
+	}
+}
+
+// CodeSearchUsage reports code search hits per area of each indexed folder
+// (or only folderPath), grouped depth directories deep, listing at most limit
+// hot and limit never-queried areas per folder
+func (s *CodeIndexStorage) CodeSearchUsage(folderPath string, depth, limit int) (*CodeSearchUsageReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	folders, err := s.ListFolders()
+	if err != nil {
+		return nil, err
+	}
+	if folderPath != "" {
+		var selected []*IndexedFolder
+		for _, folder := range folders {
+			if folder.Path == folderPath {
+				selected = append(selected, folder)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, errcode.New(errcode.NotFound, "indexed folder not found: %s", folderPath)
+		}
+		folders = selected
+	}
+
+	folderIDs := make([]string, 0, len(folders))
+	folderPaths := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		folderIDs = append(folderIDs, folder.ID)
+		folderPaths = append(folderPaths, folder.Path)
+	}
+
+	cursor, err := s.filesCol.Find(ctx,
+		bson.M{"folderId": bson.M{"$in": folderIDs}},
+		options.Find().SetProjection(bson.M{"folderId": 1, "relativePath": 1, "chunkCount": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	var files []*IndexedFile
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, fmt.Errorf("failed to decode indexed files: %w", err)
+	}
+
+	cursor, err = s.usageCol.Find(ctx, bson.M{"folderPath": bson.M{"$in": folderPaths}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list code search usage: %w", err)
+	}
+	var usage []codeSearchHits
+	if err := cursor.All(ctx, &usage); err != nil {
+		return nil, fmt.Errorf("failed to decode code search usage: %w", err)
+	}
+
+	return buildCodeSearchUsage(folders, files, usage, depth, limit), nil
+}
+
+// buildCodeSearchUsage groups indexed files and recorded hits into areas
+func buildCodeSearchUsage(folders []*IndexedFolder, files []*IndexedFile, usage []codeSearchHits, depth, limit int) *CodeSearchUsageReport {
+	if depth <= 0 {
+		depth = DefaultCodeSearchUsageDepth
+	}
+	report := &CodeSearchUsageReport{Depth: depth, Folders: make([]*CodeSearchFolderUsage, 0, len(folders))}
+
+	byFolder := make(map[string]map[string]*CodeSearchArea, len(folders))
+	folderByID := make(map[string]string, len(folders))
+	for _, folder := range folders {
+		byFolder[folder.Path] = map[string]*CodeSearchArea{}
+		folderByID[folder.ID] = folder.Path
+	}
+	area := func(folderPath, dir string) *CodeSearchArea {
+		areas := byFolder[folderPath]
+		name := areaAt(dir, depth)
+		if areas[name] == nil {
+			areas[name] = &CodeSearchArea{Path: name}
+		}
+		return areas[name]
+	}
+
+	for _, file := range files {
+		folderPath, ok := folderByID[file.FolderID]
+		if !ok {
+			continue
+		}
+		a := area(folderPath, path.Dir(filepath.ToSlash(file.RelativePath)))
+		a.Files++
+		a.Chunks += file.ChunkCount
+	}
+	for _, row := range usage {
+		if _, ok := byFolder[row.FolderPath]; !ok {
+			continue
+		}
+		a := area(row.FolderPath, row.Dir)
+		a.Hits += row.Hits
+		if lastHitAt := row.LastHitAt; a.LastHitAt == nil || lastHitAt.After(*a.LastHitAt) {
+			a.LastHitAt = &lastHitAt
+		}
+		if firstHitAt := row.FirstHitAt; report.TrackedSince == nil || firstHitAt.Before(*report.TrackedSince) {
+			report.TrackedSince = &firstHitAt
+		}
+	}
+
+	for _, folder := range folders {
+		folderUsage := &CodeSearchFolderUsage{FolderPath: folder.Path, Hot: []*CodeSearchArea{}, NeverQueried: []*CodeSearchArea{}}
+		for _, a := range byFolder[folder.Path] {
+			folderUsage.Files += a.Files
+			folderUsage.Chunks += a.Chunks
+			folderUsage.Hits += a.Hits
+		}
+		for _, a := range byFolder[folder.Path] {
+			folderUsage.Areas++
+			if a.Hits > 0 {
+				a.Share = math.Round(float64(a.Hits)/float64(folderUsage.Hits)*1000) / 1000
+				folderUsage.Hot = append(folderUsage.Hot, a)
+			} else if a.Files > 0 {
+				folderUsage.NeverQueriedCount++
+				folderUsage.NeverQueriedChunks += a.Chunks
+				folderUsage.NeverQueried = append(folderUsage.NeverQueried, a)
+			}
+		}
+		sort.Slice(folderUsage.Hot, func(i, j int) bool {
+			if folderUsage.Hot[i].Hits != folderUsage.Hot[j].Hits {
+				return folderUsage.Hot[i].Hits > folderUsage.Hot[j].Hits
+			}
+			return folderUsage.Hot[i].Path < folderUsage.Hot[j].Path
+		})
+		sort.Slice(folderUsage.NeverQueried, func(i, j int) bool {
+			if folderUsage.NeverQueried[i].Chunks != folderUsage.NeverQueried[j].Chunks {
+				return folderUsage.NeverQueried[i].Chunks > folderUsage.NeverQueried[j].Chunks
+			}
+			return folderUsage.NeverQueried[i].Path < folderUsage.NeverQueried[j].Path
+		})
+		if limit > 0 && len(folderUsage.Hot) > limit {
+			folderUsage.Hot = folderUsage.Hot[:limit]
+		}
+		if limit > 0 && len(folderUsage.NeverQueried) > limit {
+			folderUsage.NeverQueried = folderUsage.NeverQueried[:limit]
+		}
+		report.Folders = append(report.Folders, folderUsage)
+	}
+
+	// Folders with the most indexed-but-unused code first
+	sort.SliceStable(report.Folders, func(i, j int) bool {
+		return report.Folders[i].NeverQueriedChunks > report.Folders[j].NeverQueriedChunks
+	})
+	return report
+}
+
+// resultDir returns the directory of a search result relative to its folder
+func resultDir(result SearchResult) (string, bool) {
+	if result.FolderPath == "" {
+		return "", false
+	}
+	relative := result.RelativePath
+	if relative == "" && result.FilePath != "" {
+		rel, err := filepath.Rel(result.FolderPath, result.FilePath)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return "", false
+		}
+		relative = rel
+	}
+	if relative == "" {
+		return "", false
+	}
+	return path.Dir(filepath.ToSlash(relative)), true
+}
+
+// areaAt truncates a slash-separated directory to depth levels
+func areaAt(dir string, depth int) string {
+	if dir == "." || dir == "" {
+		return "."
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
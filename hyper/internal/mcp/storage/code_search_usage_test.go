@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCodeSearchUsage(t *testing.T) {
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := first.Add(48 * time.Hour)
+	folders := []*IndexedFolder{{ID: "f1", Path: "/repo"}, {ID: "f2", Path: "/lib"}}
+	files := []*IndexedFile{
+		{FolderID: "f1", RelativePath: "internal/api/handler.go", ChunkCount: 4},
+		{FolderID: "f1", RelativePath: "internal/api/v2/routes.go", ChunkCount: 2},
+		{FolderID: "f1", RelativePath: "internal/legacy/old.go", ChunkCount: 9},
+		{FolderID: "f1", RelativePath: "main.go", ChunkCount: 1},
+		{FolderID: "f2", RelativePath: "util.go", ChunkCount: 3},
+	}
+	usage := []codeSearchHits{
+		{FolderPath: "/repo", Dir: "internal/api", Hits: 6, FirstHitAt: first, LastHitAt: first},
+		{FolderPath: "/repo", Dir: "internal/api/v2", Hits: 2, FirstHitAt: last, LastHitAt: last},
+		{FolderPath: "/repo", Dir: ".", Hits: 2, FirstHitAt: last, LastHitAt: last},
+		{FolderPath: "/gone", Dir: ".", Hits: 5, FirstHitAt: first, LastHitAt: first},
+	}
+
+	report := buildCodeSearchUsage(folders, files, usage, 0, 10)
+	assert.Equal(t, DefaultCodeSearchUsageDepth, report.Depth)
+	require.NotNil(t, report.TrackedSince)
+	assert.Equal(t, first, *report.TrackedSince)
+	require.Len(t, report.Folders, 2)
+
+	repo := report.Folders[0]
+	assert.Equal(t, "/repo", repo.FolderPath, "folders with the most unused chunks come first")
+	assert.Equal(t, int64(10), repo.Hits, "hits of folders no longer indexed are left out")
+	assert.Equal(t, 16, repo.Chunks)
+	require.Len(t, repo.Hot, 2)
+	api := repo.Hot[0]
+	assert.Equal(t, "internal/api", api.Path, "subdirectories roll up to the report depth")
+	assert.Equal(t, int64(8), api.Hits)
+	assert.Equal(t, 2, api.Files)
+	assert.Equal(t, 0.8, api.Share)
+	assert.Equal(t, last, *api.LastHitAt)
+	assert.Equal(t, ".", repo.Hot[1].Path)
+
+	assert.Equal(t, 1, repo.NeverQueriedCount)
+	assert.Equal(t, 9, repo.NeverQueriedChunks)
+	assert.Equal(t, "internal/legacy", repo.NeverQueried[0].Path)
+
+	lib := report.Folders[1]
+	assert.Empty(t, lib.Hot)
+	assert.Equal(t, 3, lib.NeverQueriedChunks)
+
+	report = buildCodeSearchUsage(folders, files, usage, 1, 1)
+	repo = report.Folders[1]
+	require.Equal(t, "/repo", repo.FolderPath, "every area of /repo has hits at depth 1")
+	require.Len(t, repo.Hot, 1)
+	assert.Equal(t, "internal", repo.Hot[0].Path)
+	assert.Equal(t, 2, repo.Areas)
+}
+
+func TestResultDir(t *testing.T) {
+	dir, ok := resultDir(SearchResult{FolderPath: "/repo", RelativePath: "pkg/a/b.go"})
+	assert.True(t, ok)
+	assert.Equal(t, "pkg/a", dir)
+
+	dir, ok = resultDir(SearchResult{FolderPath: "/repo", FilePath: "/repo/main.go"})
+	assert.True(t, ok)
+	assert.Equal(t, ".", dir)
+
+	_, ok = resultDir(SearchResult{FolderPath: "/repo", FilePath: "/elsewhere/main.go"})
+	assert.False(t, ok)
+	_, ok = resultDir(SearchResult{RelativePath: "main.go"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CodeSymbol is a declaration found in an indexed file: a function, method,
+// type or constant with the lines it spans
+type CodeSymbol struct {
+	FileID       string `bson:"fileId" json:"fileId"`
+	FolderID     string `bson:"folderId" json:"folderId"`
+	FilePath     string `bson:"filePath" json:"filePath"`
+	RelativePath string `bson:"relativePath" json:"relativePath"`
+	Language     string `bson:"language" json:"language"`
+	Name         string `bson:"name" json:"name"`
+	NameLower    string `bson:"nameLower" json:"-"`                             // For case-insensitive prefix lookups
+	Kind         string `bson:"kind" json:"kind"`                               // function, method, struct, class, interface, type, const, var, ...
+	Container    string `bson:"container,omitempty" json:"container,omitempty"` // Receiver type, class or impl the symbol belongs to
+	Package      string `bson:"package,omitempty" json:"package,omitempty"`     // Go package, or the package or namespace the file declares
+	StartLine    int    `bson:"startLine" json:"startLine"`
+	EndLine      int    `bson:"endLine" json:"endLine"`
+	Signature    string `bson:"signature,omitempty" json:"signature,omitempty"` // First line of the declaration
+}
+
+// SymbolQuery selects code symbols. Name may be qualified by the package,
+// directory or container, as in storage.NewClient, mcp/storage.NewClient
+// or CodeIndexStorage.UpsertFile.
+type SymbolQuery struct {
+	Name   string
+	Prefix bool   // Match names starting with Name, ignoring case
+	Kind   string // Optional
+	File   string // Optional: file path, path suffix, or directory of the file
+	Limit  int
+}
+
+// symbolScanLimit caps the candidates read for one query before the
+// qualifier and file filters narrow them
+const symbolScanLimit = 1000
+
+// qualifierSeparator splits qualified names in the notations of the
+// supported languages: pkg.Name, mod::name, Ns\Name and dir/pkg.Name
+var qualifierSeparator = regexp.MustCompile(`::|[.\\/#]`)
+
+// ReplaceFileSymbols replaces the stored symbols of a file and records when
+// they were extracted
+func (s *CodeIndexStorage) ReplaceFileSymbols(file *IndexedFile, symbols []CodeSymbol) error {
+	ctx := context.Background()
+	if _, err := s.symbolsCol.DeleteMany(ctx, bson.M{"fileId": file.ID}); err != nil {
+		return fmt.Errorf("failed to delete symbols: %w", err)
+	}
+
+	if len(symbols) > 0 {
+		docs := make([]interface{}, len(symbols))
+		for i := range symbols {
+			symbol := symbols[i]
+			symbol.FileID = file.ID
+			symbol.FolderID = file.FolderID
+			symbol.FilePath = file.Path
+			symbol.RelativePath = file.RelativePath
+			symbol.NameLower = strings.ToLower(symbol.Name)
+			docs[i] = symbol
+		}
+		if _, err := s.symbolsCol.InsertMany(ctx, docs); err != nil {
+			return fmt.Errorf("failed to insert symbols: %w", err)
+		}
+	}
+
+	file.SymbolsAt = time.Now()
+	_, err := s.filesCol.UpdateOne(ctx, bson.M{"_id": file.ID}, bson.M{"$set": bson.M{"symbolsAt": file.SymbolsAt}})
+	if err != nil {
+		return fmt.Errorf("failed to update file: %w", err)
+	}
+	return nil
+}
+
+// FindSymbols returns the symbols matching a query, declarations in tests
+// last
+func (s *CodeIndexStorage) FindSymbols(query SymbolQuery) ([]*CodeSymbol, error) {
+	qualifier, name := SplitQualifiedName(query.Name)
+	if name == "" {
+		return nil, fmt.Errorf("symbol name is required")
+	}
+
+	filter := bson.M{"name": name}
+	if query.Prefix {
+		filter = bson.M{"nameLower": bson.M{"$regex": "^" + regexp.QuoteMeta(strings.ToLower(name))}}
+	}
+	if query.Kind != "" {
+		filter["kind"] = query.Kind
+	}
+
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "relativePath", Value: 1}, {Key: "startLine", Value: 1}}).SetLimit(symbolScanLimit)
+	cursor, err := s.symbolsCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find symbols: %w", err)
+	}
+	var candidates []*CodeSymbol
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, fmt.Errorf("failed to decode symbols: %w", err)
+	}
+
+	var symbols []*CodeSymbol
+	for _, symbol := range candidates {
+		if symbol.MatchesQualifier(qualifier) && symbol.InFile(query.File) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return !IsTestFile(symbols[i].RelativePath) && IsTestFile(symbols[j].RelativePath)
+	})
+	if query.Limit > 0 && len(symbols) > query.Limit {
+		symbols = symbols[:query.Limit]
+	}
+	return symbols, nil
+}
+
+// CountSymbols returns how many symbols are indexed
+func (s *CodeIndexStorage) CountSymbols() (int64, error) {
+	count, err := s.symbolsCol.EstimatedDocumentCount(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to count symbols: %w", err)
+	}
+	return count, nil
+}
+
+// SplitQualifiedName splits storage.Client.Close into the qualifier
+// storage.Client and the name Close
+func SplitQualifiedName(qualified string) (qualifier, name string) {
+	parts := qualifierSeparator.Split(strings.TrimSpace(qualified), -1)
+	name = parts[len(parts)-1]
+	return strings.Join(parts[:len(parts)-1], "."), name
+}
+
+// MatchesQualifier reports whether a dotted qualifier names the symbol's
+// container, package, directory or module (the file without extension), or
+// a trailing part of them: for UpsertFile in internal/mcp/storage,
+// "CodeIndexStorage", "storage.CodeIndexStorage" and "mcp.storage" all
+// match, and so does "utils" for a function in utils.py.
+func (c *CodeSymbol) MatchesQualifier(qualifier string) bool {
+	if qualifier == "" {
+		return true
+	}
+	want := strings.Split(qualifier, ".")
+
+	var scopes [][]string
+	relative := slashPath(c.RelativePath)
+	module := strings.TrimSuffix(relative, path.Ext(relative))
+	for _, base := range []string{c.Package, path.Dir(relative), module} {
+		var parts []string
+		if base != "" && base != "." {
+			parts = qualifierSeparator.Split(base, -1)
+		}
+		scopes = append(scopes, parts)
+		if c.Container != "" {
+			scopes = append(scopes, append(append([]string{}, parts...), c.Container))
+		}
+	}
+	for _, scope := range scopes {
+		if hasSuffixParts(scope, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// InFile reports whether the symbol is in a file: an absolute or relative
+// path, a trailing part of one, or a directory containing it
+func (c *CodeSymbol) InFile(file string) bool {
+	file = strings.Trim(strings.TrimPrefix(slashPath(strings.TrimSpace(file)), "./"), "/")
+	if file == "" {
+		return true
+	}
+	for _, candidate := range []string{slashPath(c.RelativePath), strings.Trim(slashPath(c.FilePath), "/")} {
+		if candidate == file || strings.HasSuffix(candidate, "/"+file) || strings.HasPrefix(candidate, file+"/") || strings.Contains(candidate, "/"+file+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTestFile reports whether a path looks like a test file
+func IsTestFile(relativePath string) bool {
+	p := strings.ToLower(slashPath(relativePath))
+	base := path.Base(p)
+	return strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.Contains("/"+p, "/test/") || strings.Contains("/"+p, "/tests/") || strings.Contains("/"+p, "/__tests__/")
+}
+
+func hasSuffixParts(parts, suffix []string) bool {
+	if len(suffix) > len(parts) {
+		return false
+	}
+	offset := len(parts) - len(suffix)
+	for i, part := range suffix {
+		if parts[offset+i] != part {
+			return false
+		}
+	}
+	return true
+}
+
+func slashPath(p string) string {
+	return strings.ReplaceAll(p, "\\", "/")
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitQualifiedName(t *testing.T) {
+	for qualified, want := range map[string][2]string{
+		"NewClient":                    {"", "NewClient"},
+		" storage.NewClient ":          {"storage", "NewClient"},
+		"mcp/storage.NewClient":        {"mcp.storage", "NewClient"},
+		"CodeIndexStorage.UpsertFile":  {"CodeIndexStorage", "UpsertFile"},
+		"crate::store::Store::new":     {"crate.store.Store", "new"},
+		`App\Http\Controller`:          {"App.Http", "Controller"},
+		"Store#add":                    {"Store", "add"},
+		"storage.CodeIndexStorage.Get": {"storage.CodeIndexStorage", "Get"},
+	} {
+		qualifier, name := SplitQualifiedName(qualified)
+		assert.Equal(t, want, [2]string{qualifier, name}, qualified)
+	}
+}
+
+func TestCodeSymbolMatchesQualifier(t *testing.T) {
+	method := &CodeSymbol{
+		Name:         "UpsertFile",
+		Container:    "CodeIndexStorage",
+		Package:      "storage",
+		RelativePath: "internal/mcp/storage/code_index_storage.go",
+	}
+	for _, qualifier := range []string{"", "CodeIndexStorage", "storage", "storage.CodeIndexStorage", "mcp.storage", "mcp.storage.CodeIndexStorage", "code_index_storage"} {
+		assert.True(t, method.MatchesQualifier(qualifier), qualifier)
+	}
+	for _, qualifier := range []string{"Storage", "handlers", "internal", "CodeIndexStorage.storage"} {
+		assert.False(t, method.MatchesQualifier(qualifier), qualifier)
+	}
+
+	function := &CodeSymbol{Name: "helper", RelativePath: `pkg\utils.py`}
+	assert.True(t, function.MatchesQualifier("utils"))
+	assert.True(t, function.MatchesQualifier("pkg.utils"))
+	assert.True(t, function.MatchesQualifier("pkg"))
+	assert.False(t, function.MatchesQualifier("other"))
+
+	javaMethod := &CodeSymbol{Name: "add", Container: "Store", Package: "com.example.store", RelativePath: "src/Store.java"}
+	assert.True(t, javaMethod.MatchesQualifier("example.store.Store"))
+	assert.False(t, javaMethod.MatchesQualifier("other.Store"))
+}
+
+func TestCodeSymbolInFile(t *testing.T) {
+	symbol := &CodeSymbol{FilePath: "/repo/internal/mcp/storage/tasks.go", RelativePath: "internal/mcp/storage/tasks.go"}
+	for _, file := range []string{"", "/repo/internal/mcp/storage/tasks.go", "internal/mcp/storage/tasks.go", "./internal/mcp/storage/tasks.go", "storage/tasks.go", "tasks.go", "internal/mcp", "storage/", "mcp"} {
+		assert.True(t, symbol.InFile(file), file)
+	}
+	for _, file := range []string{"asks.go", "storage/tasks", "handlers", "internal/mcp/stor"} {
+		assert.False(t, symbol.InFile(file), file)
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	for _, path := range []string{"internal/store_test.go", "src/app.test.ts", "src/app.spec.js", "tests/test_store.py", "test/Fixture.java", `web\__tests__\app.tsx`, "pkg/test_utils.py"} {
+		assert.True(t, IsTestFile(path), path)
+	}
+	for _, path := range []string{"internal/store.go", "src/testing.ts", "contest/app.js", "attest.py"} {
+		assert.False(t, IsTestFile(path), path)
+	}
+}
@@ -0,0 +1,565 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// minSubjectIdentifierLength keeps an erasure from matching nearly everything
+const minSubjectIdentifierLength = 3
+
+// DataSubject identifies the person a right-to-erasure request is about by
+// one or more identifiers, such as an email address or user ID. Text matches
+// when it contains an identifier, ignoring case.
+type DataSubject struct {
+	Identifiers []string
+	needles     []string
+}
+
+// NewDataSubject validates and normalizes the identifiers of a data subject
+func NewDataSubject(identifiers []string) (*DataSubject, error) {
+	subject := &DataSubject{}
+	seen := map[string]bool{}
+	for _, identifier := range identifiers {
+		identifier = strings.TrimSpace(identifier)
+		if identifier == "" {
+			continue
+		}
+		if len(identifier) < minSubjectIdentifierLength {
+			return nil, fmt.Errorf("identifier %q is too short: at least %d characters are required", identifier, minSubjectIdentifierLength)
+		}
+		needle := strings.ToLower(identifier)
+		if seen[needle] {
+			continue
+		}
+		seen[needle] = true
+		subject.Identifiers = append(subject.Identifiers, identifier)
+		subject.needles = append(subject.needles, needle)
+	}
+	if len(subject.Identifiers) == 0 {
+		return nil, fmt.Errorf("at least one identifier (email address or user ID) is required")
+	}
+	return subject, nil
+}
+
+// Matches reports whether any of texts contains an identifier
+func (d *DataSubject) Matches(texts ...string) bool {
+	for _, text := range texts {
+		lower := strings.ToLower(text)
+		for _, needle := range d.needles {
+			if strings.Contains(lower, needle) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchesValue reports whether a metadata or payload value contains an
+// identifier in any nested string, key included
+func (d *DataSubject) MatchesValue(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return d.Matches(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			if d.Matches(key) || d.MatchesValue(item) {
+				return true
+			}
+		}
+	case bson.M:
+		return d.MatchesValue(map[string]interface{}(v))
+	case []interface{}:
+		for _, item := range v {
+			if d.MatchesValue(item) {
+				return true
+			}
+		}
+	case primitive.A:
+		return d.MatchesValue([]interface{}(v))
+	case []string:
+		return d.Matches(v...)
+	}
+	return false
+}
+
+// pattern matches a field containing an identifier, ignoring case
+func (d *DataSubject) pattern() primitive.Regex {
+	quoted := make([]string, len(d.Identifiers))
+	for i, identifier := range d.Identifiers {
+		quoted[i] = regexp.QuoteMeta(identifier)
+	}
+	return primitive.Regex{Pattern: strings.Join(quoted, "|"), Options: "i"}
+}
+
+// exactPattern matches a field equal to an identifier, ignoring case
+func (d *DataSubject) exactPattern() primitive.Regex {
+	pattern := d.pattern()
+	pattern.Pattern = "^(" + pattern.Pattern + ")$"
+	return pattern
+}
+
+// ErasureSection reports what an erasure found and removed in one store.
+// Matched documents are deleted; Redacted counts entries removed from
+// documents that are otherwise kept.
+type ErasureSection struct {
+	Store    string   `json:"store"`
+	Matched  int      `json:"matched"`
+	Deleted  int      `json:"deleted"`
+	Redacted int      `json:"redacted,omitempty"`
+	IDs      []string `json:"ids,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// ErasureReport is the deletion report of a data subject erasure
+type ErasureReport struct {
+	Identifiers  []string          `json:"identifiers"`
+	DryRun       bool              `json:"dryRun"`
+	StartedAt    time.Time         `json:"startedAt"`
+	CompletedAt  time.Time         `json:"completedAt"`
+	Sections     []*ErasureSection `json:"sections"`
+	TotalMatched int               `json:"totalMatched"`
+	TotalDeleted int               `json:"totalDeleted"`
+	Complete     bool              `json:"complete"` // false when a store failed; run the erasure again
+}
+
+// DataSubjectEraser finds and purges the personal data of a data subject
+// across tasks and their activity log, knowledge entries and their Qdrant
+// vectors, chat history, role assignments and digest subscriptions
+type DataSubjectEraser struct {
+	db        *mongo.Database
+	tasks     *MongoTaskStorage
+	knowledge *MongoKnowledgeStorage
+	logger    *zap.Logger
+}
+
+// NewDataSubjectEraser creates an eraser over the coordinator's storage
+func NewDataSubjectEraser(db *mongo.Database, tasks *MongoTaskStorage, knowledge *MongoKnowledgeStorage, logger *zap.Logger) *DataSubjectEraser {
+	return &DataSubjectEraser{db: db, tasks: tasks, knowledge: knowledge, logger: logger}
+}
+
+// Erase purges everything containing the subject's identifiers, or with
+// dryRun only reports what would be purged. A failing store is reported in
+// its section and does not stop the others.
+func (e *DataSubjectEraser) Erase(ctx context.Context, subject *DataSubject, dryRun bool) *ErasureReport {
+	report := &ErasureReport{
+		Identifiers: subject.Identifiers,
+		DryRun:      dryRun,
+		StartedAt:   time.Now().UTC(),
+		Complete:    true,
+	}
+
+	steps := []struct {
+		store string
+		erase func(context.Context, *DataSubject, bool) ([]*ErasureSection, error)
+	}{
+		{"tasks", e.tasks.EraseDataSubject},
+		{"knowledge", e.knowledge.EraseDataSubject},
+		{"chat", e.eraseChat},
+		{"user_roles", e.eraseRoles},
+		{"digest_subscriptions", e.eraseDigestSubscriptions},
+	}
+	for _, step := range steps {
+		sections, err := step.erase(ctx, subject, dryRun)
+		if err != nil {
+			e.logger.Error("Data subject erasure failed", zap.String("store", step.store), zap.Error(err))
+			sections = append(sections, &ErasureSection{Store: step.store, Error: err.Error()})
+			report.Complete = false
+		}
+		for _, section := range sections {
+			report.TotalMatched += section.Matched
+			report.TotalDeleted += section.Deleted
+		}
+		report.Sections = append(report.Sections, sections...)
+	}
+
+	report.CompletedAt = time.Now().UTC()
+	// Identifiers are personal data, so only counts are logged
+	e.logger.Info("Data subject erasure finished",
+		zap.Bool("dryRun", dryRun),
+		zap.Int("matched", report.TotalMatched),
+		zap.Int("deleted", report.TotalDeleted),
+		zap.Bool("complete", report.Complete))
+	return report
+}
+
+// EraseDataSubject deletes human tasks mentioning the subject together with
+// their agent tasks, deletes agent tasks mentioning the subject, and removes
+// matching activity log entries from the agent tasks that are kept
+func (s *MongoTaskStorage) EraseDataSubject(ctx context.Context, subject *DataSubject, dryRun bool) ([]*ErasureSection, error) {
+	humans := &ErasureSection{Store: "human_tasks"}
+	agents := &ErasureSection{Store: "agent_tasks"}
+	activity := &ErasureSection{Store: "task_activity"}
+	sections := []*ErasureSection{humans, agents, activity}
+
+	humanCursor, err := s.humanTasksCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return sections, fmt.Errorf("failed to query human tasks: %w", err)
+	}
+	defer humanCursor.Close(ctx)
+	erasedHumans := map[string]bool{}
+	for humanCursor.Next(ctx) {
+		var task HumanTask
+		if err := humanCursor.Decode(&task); err != nil {
+			return sections, fmt.Errorf("failed to decode human task: %w", err)
+		}
+		if err := s.cipher.OpenAll(humanTaskSecrets(&task)); err != nil {
+			return sections, fmt.Errorf("human task %s: %w", task.ID, err)
+		}
+		if subject.Matches(task.Prompt, task.Notes) {
+			erasedHumans[task.ID] = true
+			humans.IDs = append(humans.IDs, task.ID)
+		}
+	}
+	if err := humanCursor.Err(); err != nil {
+		return sections, fmt.Errorf("failed to iterate human tasks: %w", err)
+	}
+
+	type redaction struct {
+		task *AgentTask
+		kept []TaskActivity
+	}
+	var redactions []redaction
+	agentCursor, err := s.agentTasksCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return sections, fmt.Errorf("failed to query agent tasks: %w", err)
+	}
+	defer agentCursor.Close(ctx)
+	for agentCursor.Next(ctx) {
+		task := &AgentTask{}
+		if err := agentCursor.Decode(task); err != nil {
+			return sections, fmt.Errorf("failed to decode agent task: %w", err)
+		}
+		if err := s.cipher.OpenAll(agentTaskSecrets(task)); err != nil {
+			return sections, fmt.Errorf("agent task %s: %w", task.ID, err)
+		}
+		if erasedHumans[task.HumanTaskID] || subject.Matches(agentTaskContent(task)...) {
+			agents.IDs = append(agents.IDs, task.ID)
+			continue
+		}
+
+		kept := make([]TaskActivity, 0, len(task.Activity))
+		for _, entry := range task.Activity {
+			if !subject.Matches(entry.Notes) {
+				kept = append(kept, entry)
+			}
+		}
+		if removed := len(task.Activity) - len(kept); removed > 0 {
+			activity.Matched += removed
+			activity.IDs = append(activity.IDs, task.ID)
+			redactions = append(redactions, redaction{task: task, kept: kept})
+		}
+	}
+	if err := agentCursor.Err(); err != nil {
+		return sections, fmt.Errorf("failed to iterate agent tasks: %w", err)
+	}
+	humans.Matched, agents.Matched = len(humans.IDs), len(agents.IDs)
+	if dryRun {
+		return sections, nil
+	}
+
+	for _, r := range redactions {
+		kept := append([]TaskActivity(nil), r.kept...)
+		if err := s.cipher.SealAll(activitySecrets(kept)); err != nil {
+			return sections, err
+		}
+		result, err := s.agentTasksCollection.UpdateOne(ctx,
+			bson.M{"taskId": r.task.ID, "updatedAt": r.task.UpdatedAt},
+			bson.M{"$set": bson.M{"activity": kept}})
+		if err != nil {
+			return sections, fmt.Errorf("failed to redact activity of agent task %s: %w", r.task.ID, err)
+		}
+		if result.MatchedCount == 0 {
+			return sections, fmt.Errorf("agent task %s changed during erasure; run the erasure again", r.task.ID)
+		}
+		activity.Redacted += len(r.task.Activity) - len(r.kept)
+	}
+
+	if len(agents.IDs) > 0 {
+		result, err := s.agentTasksCollection.DeleteMany(ctx, bson.M{"taskId": bson.M{"$in": agents.IDs}})
+		if err != nil {
+			return sections, fmt.Errorf("failed to delete agent tasks: %w", err)
+		}
+		agents.Deleted = int(result.DeletedCount)
+		s.recordDeletions(ctx, "agent", agents.IDs)
+	}
+	if len(humans.IDs) > 0 {
+		result, err := s.humanTasksCollection.DeleteMany(ctx, bson.M{"taskId": bson.M{"$in": humans.IDs}})
+		if err != nil {
+			return sections, fmt.Errorf("failed to delete human tasks: %w", err)
+		}
+		humans.Deleted = int(result.DeletedCount)
+		s.recordDeletions(ctx, "human", humans.IDs)
+	}
+	return sections, nil
+}
+
+// agentTaskContent lists the free text of an agent task apart from its
+// activity log, which is redacted entry by entry
+func agentTaskContent(task *AgentTask) []string {
+	texts := []string{task.Notes, task.ContextSummary, task.PriorWorkSummary, task.HumanPromptNotes}
+	for _, todo := range task.Todos {
+		texts = append(texts, todo.Description, todo.Notes, todo.HumanPromptNotes, todo.ContextHint)
+		for _, item := range todo.Checklist {
+			texts = append(texts, item.Description, item.Notes)
+		}
+	}
+	return texts
+}
+
+// payloadScanner is implemented by Qdrant clients that can page through the
+// payloads of a collection
+type payloadScanner interface {
+	ScanPayloads(collectionName string, visit func(id string, payload map[string]interface{}) error) error
+}
+
+// EraseDataSubject deletes knowledge entries whose text or metadata mentions
+// the subject, and their Qdrant points. Collections known to MongoDB and the
+// default knowledge collection are also scanned in Qdrant, which catches
+// points stored there directly (knowledge_store).
+func (s *MongoKnowledgeStorage) EraseDataSubject(ctx context.Context, subject *DataSubject, dryRun bool) ([]*ErasureSection, error) {
+	entries := &ErasureSection{Store: "knowledge_entries"}
+	vectors := &ErasureSection{Store: "knowledge_vectors"}
+	sections := []*ErasureSection{entries, vectors}
+
+	cursor, err := s.knowledgeCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return sections, fmt.Errorf("failed to query knowledge entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	points := map[string]map[string]bool{} // collection -> point IDs to delete
+	for cursor.Next(ctx) {
+		var entry KnowledgeEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return sections, fmt.Errorf("failed to decode knowledge entry: %w", err)
+		}
+		if points[entry.Collection] == nil {
+			points[entry.Collection] = map[string]bool{}
+		}
+		text, err := s.cipher.Decrypt(entry.Text)
+		if err != nil {
+			return sections, fmt.Errorf("knowledge entry %s: %w", entry.ID, err)
+		}
+		if subject.Matches(text) || subject.MatchesValue(entry.Metadata) {
+			entries.IDs = append(entries.IDs, entry.ID)
+			points[entry.Collection][entry.ID] = true
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return sections, fmt.Errorf("failed to iterate knowledge entries: %w", err)
+	}
+	entries.Matched = len(entries.IDs)
+
+	if s.qdrantClient != nil {
+		if named, ok := s.qdrantClient.(interface{ KnowledgeCollectionName() string }); ok && named.KnowledgeCollectionName() != "" {
+			if points[named.KnowledgeCollectionName()] == nil {
+				points[named.KnowledgeCollectionName()] = map[string]bool{}
+			}
+		}
+		if scanner, ok := s.qdrantClient.(payloadScanner); ok {
+			for collection, ids := range points {
+				err := scanner.ScanPayloads(collection, func(id string, payload map[string]interface{}) error {
+					if subject.MatchesValue(payload) {
+						ids[id] = true
+					}
+					return nil
+				})
+				if err != nil {
+					return sections, fmt.Errorf("failed to scan Qdrant collection %s: %w", collection, err)
+				}
+			}
+		}
+		for collection, ids := range points {
+			for id := range ids {
+				vectors.IDs = append(vectors.IDs, collection+"/"+id)
+			}
+		}
+		vectors.Matched = len(vectors.IDs)
+	}
+	if dryRun {
+		return sections, nil
+	}
+
+	if len(entries.IDs) > 0 {
+		result, err := s.knowledgeCollection.DeleteMany(ctx, bson.M{"entryId": bson.M{"$in": entries.IDs}})
+		if err != nil {
+			return sections, fmt.Errorf("failed to delete knowledge entries: %w", err)
+		}
+		entries.Deleted = int(result.DeletedCount)
+	}
+	if s.qdrantClient != nil {
+		for collection, ids := range points {
+			for id := range ids {
+				if err := s.qdrantClient.DeletePoint(collection, id); err != nil {
+					return sections, fmt.Errorf("failed to delete Qdrant point %s/%s: %w", collection, id, err)
+				}
+				vectors.Deleted++
+			}
+		}
+	}
+	return sections, nil
+}
+
+// eraseChat deletes the subject's chat sessions with their messages, and any
+// other message mentioning the subject
+func (e *DataSubjectEraser) eraseChat(ctx context.Context, subject *DataSubject, dryRun bool) ([]*ErasureSection, error) {
+	sessions := &ErasureSection{Store: "chat_sessions"}
+	messages := &ErasureSection{Store: "chat_messages"}
+	sections := []*ErasureSection{sessions, messages}
+	sessionsCollection := e.db.Collection(CollectionName("chat_sessions"))
+	messagesCollection := e.db.Collection(CollectionName("chat_messages"))
+
+	cursor, err := sessionsCollection.Find(ctx, bson.M{"$or": []bson.M{
+		{"userId": subject.exactPattern()},
+		{"title": subject.pattern()},
+	}})
+	if err != nil {
+		return sections, fmt.Errorf("failed to query chat sessions: %w", err)
+	}
+	var sessionDocs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &sessionDocs); err != nil {
+		return sections, fmt.Errorf("failed to decode chat sessions: %w", err)
+	}
+	sessionIDs := make([]primitive.ObjectID, len(sessionDocs))
+	for i, doc := range sessionDocs {
+		sessionIDs[i] = doc.ID
+		sessions.IDs = append(sessions.IDs, doc.ID.Hex())
+	}
+	sessions.Matched = len(sessionIDs)
+
+	messageFilter := bson.M{"$or": []bson.M{
+		{"sessionId": bson.M{"$in": sessionIDs}},
+		{"content": subject.pattern()},
+	}}
+	count, err := messagesCollection.CountDocuments(ctx, messageFilter)
+	if err != nil {
+		return sections, fmt.Errorf("failed to count chat messages: %w", err)
+	}
+	messages.Matched = int(count)
+	if dryRun {
+		return sections, nil
+	}
+
+	result, err := messagesCollection.DeleteMany(ctx, messageFilter)
+	if err != nil {
+		return sections, fmt.Errorf("failed to delete chat messages: %w", err)
+	}
+	messages.Deleted = int(result.DeletedCount)
+	if len(sessionIDs) > 0 {
+		result, err := sessionsCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": sessionIDs}})
+		if err != nil {
+			return sections, fmt.Errorf("failed to delete chat sessions: %w", err)
+		}
+		sessions.Deleted = int(result.DeletedCount)
+	}
+	return sections, nil
+}
+
+// eraseRoles deletes the subject's role assignment and removes them as the
+// assigner of other users' roles
+func (e *DataSubjectEraser) eraseRoles(ctx context.Context, subject *DataSubject, dryRun bool) ([]*ErasureSection, error) {
+	roles := &ErasureSection{Store: "user_roles"}
+	sections := []*ErasureSection{roles}
+	collection := e.db.Collection(CollectionName("user_roles"))
+
+	cursor, err := collection.Find(ctx, bson.M{"_id": subject.exactPattern()})
+	if err != nil {
+		return sections, fmt.Errorf("failed to query role assignments: %w", err)
+	}
+	var assignments []*RoleAssignment
+	if err := cursor.All(ctx, &assignments); err != nil {
+		return sections, fmt.Errorf("failed to decode role assignments: %w", err)
+	}
+	for _, assignment := range assignments {
+		roles.IDs = append(roles.IDs, assignment.UserID)
+	}
+	roles.Matched = len(roles.IDs)
+
+	assignedBy := bson.M{"assignedBy": subject.exactPattern()}
+	if dryRun {
+		count, err := collection.CountDocuments(ctx, assignedBy)
+		if err != nil {
+			return sections, fmt.Errorf("failed to count role assigners: %w", err)
+		}
+		roles.Redacted = int(count)
+		return sections, nil
+	}
+
+	if len(roles.IDs) > 0 {
+		result, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": roles.IDs}})
+		if err != nil {
+			return sections, fmt.Errorf("failed to delete role assignments: %w", err)
+		}
+		roles.Deleted = int(result.DeletedCount)
+	}
+	result, err := collection.UpdateMany(ctx, assignedBy, bson.M{"$unset": bson.M{"assignedBy": ""}})
+	if err != nil {
+		return sections, fmt.Errorf("failed to redact role assigners: %w", err)
+	}
+	roles.Redacted = int(result.ModifiedCount)
+	return sections, nil
+}
+
+// eraseDigestSubscriptions removes the subject's addresses from email digests,
+// deleting subscriptions left without recipients and webhook subscriptions
+// whose target mentions the subject
+func (e *DataSubjectEraser) eraseDigestSubscriptions(ctx context.Context, subject *DataSubject, dryRun bool) ([]*ErasureSection, error) {
+	digests := &ErasureSection{Store: "digest_subscriptions"}
+	sections := []*ErasureSection{digests}
+	collection := e.db.Collection(CollectionName("digest_subscriptions"))
+
+	cursor, err := collection.Find(ctx, bson.M{"target": subject.pattern()})
+	if err != nil {
+		return sections, fmt.Errorf("failed to query digest subscriptions: %w", err)
+	}
+	var subs []*DigestSubscription
+	if err := cursor.All(ctx, &subs); err != nil {
+		return sections, fmt.Errorf("failed to decode digest subscriptions: %w", err)
+	}
+
+	for _, sub := range subs {
+		var remaining []string
+		if sub.Channel == DigestChannelEmail {
+			for _, address := range strings.Split(sub.Target, ",") {
+				if address = strings.TrimSpace(address); address != "" && !subject.Matches(address) {
+					remaining = append(remaining, address)
+				}
+			}
+		}
+
+		if len(remaining) > 0 {
+			digests.Redacted++
+			if dryRun {
+				continue
+			}
+			if _, err := collection.UpdateOne(ctx, bson.M{"_id": sub.Name},
+				bson.M{"$set": bson.M{"target": strings.Join(remaining, ",")}}); err != nil {
+				return sections, fmt.Errorf("failed to update digest subscription %s: %w", sub.Name, err)
+			}
+			continue
+		}
+
+		digests.Matched++
+		digests.IDs = append(digests.IDs, sub.Name)
+		if dryRun {
+			continue
+		}
+		result, err := collection.DeleteOne(ctx, bson.M{"_id": sub.Name})
+		if err != nil {
+			return sections, fmt.Errorf("failed to delete digest subscription %s: %w", sub.Name, err)
+		}
+		digests.Deleted += int(result.DeletedCount)
+	}
+	return sections, nil
+}
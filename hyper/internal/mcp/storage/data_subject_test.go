@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewDataSubject(t *testing.T) {
+	subject, err := NewDataSubject([]string{" Jane@Example.com ", "jane@example.com", "", "jdoe"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Jane@Example.com", "jdoe"}, subject.Identifiers)
+
+	_, err = NewDataSubject([]string{" "})
+	assert.ErrorContains(t, err, "at least one identifier")
+
+	_, err = NewDataSubject([]string{"jd"})
+	assert.ErrorContains(t, err, "too short")
+}
+
+func TestDataSubjectMatches(t *testing.T) {
+	subject, err := NewDataSubject([]string{"jane@example.com", "jdoe"})
+	require.NoError(t, err)
+
+	assert.True(t, subject.Matches("Ask JANE@example.com about the rollout"))
+	assert.True(t, subject.Matches("", "assigned by jdoe"))
+	assert.False(t, subject.Matches("john@example.com"))
+
+	assert.True(t, subject.MatchesValue(map[string]interface{}{
+		"source": "slack",
+		"people": primitive.A{"bob", map[string]interface{}{"email": "jane@example.com"}},
+	}))
+	assert.True(t, subject.MatchesValue(map[string]interface{}{"jdoe": true}))
+	assert.False(t, subject.MatchesValue(map[string]interface{}{"count": 3, "tags": []string{"ops"}}))
+}
+
+func TestDataSubjectPatterns(t *testing.T) {
+	subject, err := NewDataSubject([]string{"j.doe+x@example.com", "jdoe"})
+	require.NoError(t, err)
+
+	assert.Equal(t, `j\.doe\+x@example\.com|jdoe`, subject.pattern().Pattern)
+	assert.Equal(t, "i", subject.pattern().Options)
+	assert.Equal(t, `^(j\.doe\+x@example\.com|jdoe)$`, subject.exactPattern().Pattern)
+}
+
+func TestAgentTaskContent(t *testing.T) {
+	subject, err := NewDataSubject([]string{"jane@example.com"})
+	require.NoError(t, err)
+
+	task := &AgentTask{
+		Todos:    []TodoItem{{Description: "Email the report", Checklist: []ChecklistItem{{Description: "cc jane@example.com"}}}},
+		Activity: []TaskActivity{{Notes: "jane@example.com approved"}},
+	}
+	assert.True(t, subject.Matches(agentTaskContent(task)...))
+
+	// Activity entries are redacted individually, not matched with the task
+	task.Todos[0].Checklist[0].Description = "cc the team"
+	assert.False(t, subject.Matches(agentTaskContent(task)...))
+}
@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Digest frequencies
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// Digest delivery channels
+const (
+	DigestChannelWebhook = "webhook" // POST of the digest as JSON
+	DigestChannelSlack   = "slack"   // Slack incoming webhook
+	DigestChannelEmail   = "email"   // SMTP, configured with SMTP_* variables
+)
+
+// DigestSubscription configures a scheduled digest for a workspace: what it
+// covers, when it is sent and where it is delivered
+type DigestSubscription struct {
+	Name          string     `bson:"_id" json:"name"`                                        // Workspace name
+	Channel       string     `bson:"channel" json:"channel"`                                 // webhook, slack or email
+	Target        string     `bson:"target" json:"target"`                                   // Webhook URL, or comma-separated email addresses
+	Frequency     string     `bson:"frequency" json:"frequency"`                             // daily or weekly
+	HourUTC       int        `bson:"hourUtc" json:"hourUtc"`                                 // Hour of day the digest is sent
+	Weekday       string     `bson:"weekday,omitempty" json:"weekday,omitempty"`             // Weekly digests only (default monday)
+	Project       string     `bson:"project,omitempty" json:"project,omitempty"`             // Only tasks of this project; empty covers all
+	Collections   []string   `bson:"collections,omitempty" json:"collections,omitempty"`     // Only these knowledge collections; empty covers all
+	Enabled       bool       `bson:"enabled" json:"enabled"`                                 // Disabled subscriptions are kept but not sent
+	CreatedAt     time.Time  `bson:"createdAt" json:"createdAt"`                             // Slots before creation are not sent
+	UpdatedAt     time.Time  `bson:"updatedAt" json:"updatedAt"`                             // Last configuration change
+	LastSentAt    *time.Time `bson:"lastSentAt,omitempty" json:"lastSentAt,omitempty"`       // Last successful delivery
+	LastAttemptAt *time.Time `bson:"lastAttemptAt,omitempty" json:"lastAttemptAt,omitempty"` // Last delivery attempt
+	LastError     string     `bson:"lastError,omitempty" json:"lastError,omitempty"`         // Error of the last failed attempt
+}
+
+// ValidateDigestSubscription checks a subscription and fills in defaults
+func ValidateDigestSubscription(sub *DigestSubscription) error {
+	sub.Name = strings.TrimSpace(sub.Name)
+	if sub.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	switch sub.Frequency {
+	case "":
+		sub.Frequency = DigestDaily
+	case DigestDaily, DigestWeekly:
+	default:
+		return fmt.Errorf("invalid frequency %q: must be daily or weekly", sub.Frequency)
+	}
+
+	if sub.HourUTC < 0 || sub.HourUTC > 23 {
+		return fmt.Errorf("hourUtc must be between 0 and 23")
+	}
+
+	if sub.Frequency == DigestWeekly {
+		if sub.Weekday == "" {
+			sub.Weekday = "monday"
+		}
+		sub.Weekday = strings.ToLower(sub.Weekday)
+		if _, ok := parseWeekday(sub.Weekday); !ok {
+			return fmt.Errorf("invalid weekday %q: must be a day name such as monday", sub.Weekday)
+		}
+	} else {
+		sub.Weekday = ""
+	}
+
+	sub.Target = strings.TrimSpace(sub.Target)
+	if sub.Target == "" {
+		return fmt.Errorf("target is required")
+	}
+	switch sub.Channel {
+	case DigestChannelWebhook, DigestChannelSlack:
+		parsed, err := url.Parse(sub.Target)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("target must be an http(s) URL for %s delivery", sub.Channel)
+		}
+	case DigestChannelEmail:
+		if _, err := ParseEmailRecipients(sub.Target); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid channel %q: must be webhook, slack or email", sub.Channel)
+	}
+
+	return nil
+}
+
+// ParseEmailRecipients parses a comma-separated list of email addresses, as
+// used for email digest targets and NOTIFY_EMAIL_TO
+func ParseEmailRecipients(target string) ([]string, error) {
+	addresses, err := mail.ParseAddressList(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email target: %w", err)
+	}
+	recipients := make([]string, len(addresses))
+	for i, address := range addresses {
+		recipients[i] = address.Address
+	}
+	return recipients, nil
+}
+
+// parseWeekday parses a lower-case English day name
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.ToLower(day.String()) == name {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// Period is the time a digest of this subscription normally covers
+func (s *DigestSubscription) Period() time.Duration {
+	if s.Frequency == DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// LatestSlot returns the most recent scheduled send time at or before now
+func (s *DigestSubscription) LatestSlot(now time.Time) time.Time {
+	now = now.UTC()
+	slot := time.Date(now.Year(), now.Month(), now.Day(), s.HourUTC, 0, 0, 0, time.UTC)
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	if s.Frequency == DigestWeekly {
+		weekday, _ := parseWeekday(s.Weekday)
+		for slot.Weekday() != weekday {
+			slot = slot.AddDate(0, 0, -1)
+		}
+	}
+	return slot
+}
+
+// Due reports whether the subscription has a scheduled send at or before now
+// that has not been delivered yet. Slots before the subscription was created
+// are skipped, so a new subscription waits for its next slot.
+func (s *DigestSubscription) Due(now time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+	slot := s.LatestSlot(now)
+	if slot.Before(s.CreatedAt) {
+		return false
+	}
+	return s.LastSentAt == nil || s.LastSentAt.Before(slot)
+}
+
+// DigestSubscriptionStorage handles persistence of digest subscriptions
+type DigestSubscriptionStorage struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+// NewDigestSubscriptionStorage creates a new digest subscription storage
+func NewDigestSubscriptionStorage(db *mongo.Database, logger *zap.Logger) *DigestSubscriptionStorage {
+	return &DigestSubscriptionStorage{
+		collection: db.Collection(CollectionName("digest_subscriptions")),
+		logger:     logger,
+	}
+}
+
+// GetSubscription returns a subscription by name, or nil if it does not exist
+func (s *DigestSubscriptionStorage) GetSubscription(name string) (*DigestSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var sub DigestSubscription
+	err := s.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&sub)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get digest subscription %s: %w", name, err)
+	}
+	return &sub, nil
+}
+
+// SetSubscription creates or replaces a subscription, keeping its creation
+// time and delivery history
+func (s *DigestSubscriptionStorage) SetSubscription(sub *DigestSubscription) error {
+	if err := ValidateDigestSubscription(sub); err != nil {
+		return err
+	}
+
+	existing, err := s.GetSubscription(sub.Name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	sub.CreatedAt = now
+	sub.LastSentAt, sub.LastAttemptAt, sub.LastError = nil, nil, ""
+	if existing != nil {
+		sub.CreatedAt = existing.CreatedAt
+		sub.LastSentAt = existing.LastSentAt
+		sub.LastAttemptAt = existing.LastAttemptAt
+		sub.LastError = existing.LastError
+	}
+	sub.UpdatedAt = now
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": sub.Name}, sub, options.Replace().SetUpsert(true)); err != nil {
+		s.logger.Error("Failed to save digest subscription", zap.String("name", sub.Name), zap.Error(err))
+		return fmt.Errorf("failed to save digest subscription %s: %w", sub.Name, err)
+	}
+
+	s.logger.Info("Digest subscription saved",
+		zap.String("name", sub.Name),
+		zap.String("channel", sub.Channel),
+		zap.String("frequency", sub.Frequency))
+	return nil
+}
+
+// ListSubscriptions returns all subscriptions sorted by name
+func (s *DigestSubscriptionStorage) ListSubscriptions() ([]*DigestSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list digest subscriptions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	subs := []*DigestSubscription{}
+	if err := cursor.All(ctx, &subs); err != nil {
+		return nil, fmt.Errorf("failed to decode digest subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// DeleteSubscription removes a subscription
+func (s *DigestSubscriptionStorage) DeleteSubscription(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return fmt.Errorf("failed to delete digest subscription %s: %w", name, err)
+	}
+	if result.DeletedCount == 0 {
+		return errcode.New(errcode.NotFound, "digest subscription not found: %s", name)
+	}
+	return nil
+}
+
+// RecordDelivery records a delivery attempt; a nil deliveryErr marks the
+// digest as sent at the given time
+func (s *DigestSubscriptionStorage) RecordDelivery(name string, at time.Time, deliveryErr error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"lastAttemptAt": at, "lastSentAt": at}, "$unset": bson.M{"lastError": ""}}
+	if deliveryErr != nil {
+		update = bson.M{"$set": bson.M{"lastAttemptAt": at, "lastError": deliveryErr.Error()}}
+	}
+
+	if _, err := s.collection.UpdateOne(ctx, bson.M{"_id": name}, update); err != nil {
+		return fmt.Errorf("failed to record digest delivery for %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateDigestSubscription(t *testing.T) {
+	valid := []*DigestSubscription{
+		{Name: "platform", Channel: DigestChannelSlack, Target: "https://hooks.slack.com/services/T/B/x"},
+		{Name: "platform", Channel: DigestChannelWebhook, Target: "http://localhost:9000/digest", Frequency: DigestWeekly, Weekday: "Friday"},
+		{Name: "platform", Channel: DigestChannelEmail, Target: "a@example.com, Bob <b@example.com>", HourUTC: 23},
+	}
+	for _, sub := range valid {
+		if err := ValidateDigestSubscription(sub); err != nil {
+			t.Errorf("valid subscription %+v rejected: %v", sub, err)
+		}
+	}
+
+	if valid[0].Frequency != DigestDaily {
+		t.Errorf("frequency default = %q, want daily", valid[0].Frequency)
+	}
+	if valid[1].Weekday != "friday" {
+		t.Errorf("weekday = %q, want friday", valid[1].Weekday)
+	}
+
+	invalid := []*DigestSubscription{
+		{Channel: DigestChannelSlack, Target: "https://hooks.slack.com/x"},
+		{Name: "p", Channel: "sms", Target: "+123"},
+		{Name: "p", Channel: DigestChannelSlack, Target: "hooks.slack.com/x"},
+		{Name: "p", Channel: DigestChannelEmail, Target: "not an address"},
+		{Name: "p", Channel: DigestChannelEmail},
+		{Name: "p", Channel: DigestChannelSlack, Target: "https://x", Frequency: "hourly"},
+		{Name: "p", Channel: DigestChannelSlack, Target: "https://x", HourUTC: 24},
+		{Name: "p", Channel: DigestChannelSlack, Target: "https://x", Frequency: DigestWeekly, Weekday: "someday"},
+	}
+	for _, sub := range invalid {
+		if err := ValidateDigestSubscription(sub); err == nil {
+			t.Errorf("expected error for %+v", sub)
+		}
+	}
+}
+
+func TestDigestSubscriptionSchedule(t *testing.T) {
+	created := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC) // Monday
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 30, 0, 0, time.UTC) }
+
+	daily := &DigestSubscription{Frequency: DigestDaily, HourUTC: 8, Enabled: true, CreatedAt: created}
+	if got, want := daily.LatestSlot(at(4, 7)), time.Date(2026, 3, 3, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("daily LatestSlot before hour = %v, want %v", got, want)
+	}
+	if got, want := daily.LatestSlot(at(4, 9)), time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("daily LatestSlot after hour = %v, want %v", got, want)
+	}
+
+	weekly := &DigestSubscription{Frequency: DigestWeekly, Weekday: "wednesday", HourUTC: 8, Enabled: true, CreatedAt: created}
+	if got, want := weekly.LatestSlot(at(10, 12)), time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("weekly LatestSlot = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name string
+		sub  DigestSubscription
+		now  time.Time
+		want bool
+	}{
+		{"slot before creation", *daily, at(2, 7), false},
+		{"first slot after creation", *daily, at(2, 8), true},
+		{"already sent", withLastSent(*daily, at(2, 8)), at(2, 20), false},
+		{"next day", withLastSent(*daily, at(2, 8)), at(3, 8), true},
+		{"disabled", DigestSubscription{Frequency: DigestDaily, HourUTC: 8, CreatedAt: created}, at(3, 8), false},
+		{"weekly not yet", *weekly, at(3, 12), false},
+		{"weekly due", *weekly, at(4, 8), true},
+	}
+	for _, tt := range tests {
+		if got := tt.sub.Due(tt.now); got != tt.want {
+			t.Errorf("%s: Due = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func withLastSent(sub DigestSubscription, at time.Time) DigestSubscription {
+	sub.LastSentAt = &at
+	return sub
+}
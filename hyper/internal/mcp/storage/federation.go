@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// peerNamePattern keeps peer names usable in tool arguments and logs
+var peerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// FederationPeer is another coordinator deployment, typically another
+// squad's, that tasks can be delegated to
+type FederationPeer struct {
+	Name        string    `bson:"_id" json:"name"`                                    // e.g. payments-squad
+	URL         string    `bson:"url" json:"url"`                                     // Base URL of the peer's HTTP server
+	Token       string    `bson:"token,omitempty" json:"-"`                           // Bearer token sent to the peer; never returned
+	Description string    `bson:"description,omitempty" json:"description,omitempty"` // Who runs the peer and what it takes
+	CreatedAt   time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// ValidateFederationPeer checks a peer and normalizes its name and URL
+func ValidateFederationPeer(peer *FederationPeer) error {
+	peer.Name = strings.ToLower(strings.TrimSpace(peer.Name))
+	if !peerNamePattern.MatchString(peer.Name) {
+		return fmt.Errorf("invalid peer name %q: must be lowercase letters, digits, - or _", peer.Name)
+	}
+
+	peer.URL = strings.TrimSuffix(strings.TrimSpace(peer.URL), "/")
+	parsed, err := url.Parse(peer.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("peer url must be an http(s) URL, got %q", peer.URL)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("peer url must not have a query or fragment")
+	}
+	return nil
+}
+
+// Delegation records a task created on a peer coordinator and the remote
+// status last seen, which is synced back to the local task
+type Delegation struct {
+	ID           string     `bson:"_id" json:"id"`
+	Peer         string     `bson:"peer" json:"peer"`
+	LocalTaskID  string     `bson:"localTaskId,omitempty" json:"localTaskId,omitempty"` // Human task mirrored from the remote one, if any
+	RemoteTaskID string     `bson:"remoteTaskId" json:"remoteTaskId"`
+	Prompt       string     `bson:"prompt" json:"prompt"`
+	Status       TaskStatus `bson:"status" json:"status"` // Remote status at the last sync
+	CreatedAt    time.Time  `bson:"createdAt" json:"createdAt"`
+	LastSyncedAt *time.Time `bson:"lastSyncedAt,omitempty" json:"lastSyncedAt,omitempty"`
+	LastError    string     `bson:"lastError,omitempty" json:"lastError,omitempty"` // Error of the last failed sync
+}
+
+// FederationStorage handles persistence of federation peers and delegations
+type FederationStorage struct {
+	peers       *mongo.Collection
+	delegations *mongo.Collection
+	cipher      *FieldCipher
+	logger      *zap.Logger
+}
+
+// NewFederationStorage creates a new federation storage
+func NewFederationStorage(db *mongo.Database, logger *zap.Logger) *FederationStorage {
+	return &FederationStorage{
+		peers:       db.Collection(CollectionName("federation_peers")),
+		delegations: db.Collection(CollectionName("federation_delegations")),
+		logger:      logger,
+	}
+}
+
+// SetFieldCipher encrypts peer tokens at rest with the given cipher
+func (s *FederationStorage) SetFieldCipher(cipher *FieldCipher) {
+	s.cipher = cipher
+}
+
+// SetPeer creates or replaces a peer, keeping its creation time. An empty
+// token keeps the stored one.
+func (s *FederationStorage) SetPeer(peer *FederationPeer) error {
+	if err := ValidateFederationPeer(peer); err != nil {
+		return err
+	}
+
+	existing, err := s.GetPeer(peer.Name)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	peer.CreatedAt, peer.UpdatedAt = now, now
+	if existing != nil {
+		peer.CreatedAt = existing.CreatedAt
+		if peer.Token == "" {
+			peer.Token = existing.Token
+		}
+	}
+
+	stored := *peer
+	if err := s.cipher.SealAll([]*string{&stored.Token}); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := s.peers.ReplaceOne(ctx, bson.M{"_id": peer.Name}, stored, options.Replace().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to save federation peer %s: %w", peer.Name, err)
+	}
+
+	s.logger.Info("Federation peer saved", zap.String("peer", peer.Name), zap.String("url", peer.URL))
+	return nil
+}
+
+// GetPeer returns a peer by name, or nil if it does not exist
+func (s *FederationStorage) GetPeer(name string) (*FederationPeer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var peer FederationPeer
+	err := s.peers.FindOne(ctx, bson.M{"_id": strings.ToLower(strings.TrimSpace(name))}).Decode(&peer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get federation peer %s: %w", name, err)
+	}
+	if err := s.cipher.OpenAll([]*string{&peer.Token}); err != nil {
+		return nil, err
+	}
+	return &peer, nil
+}
+
+// ListPeers returns all peers sorted by name, without their tokens
+func (s *FederationStorage) ListPeers() ([]*FederationPeer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.peers.Find(ctx, bson.M{},
+		options.Find().SetSort(bson.M{"_id": 1}).SetProjection(bson.M{"token": 0}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federation peers: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	peers := []*FederationPeer{}
+	if err := cursor.All(ctx, &peers); err != nil {
+		return nil, fmt.Errorf("failed to decode federation peers: %w", err)
+	}
+	return peers, nil
+}
+
+// DeletePeer removes a peer. Its delegations are kept but no longer synced.
+func (s *FederationStorage) DeletePeer(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.peers.DeleteOne(ctx, bson.M{"_id": strings.ToLower(strings.TrimSpace(name))})
+	if err != nil {
+		return fmt.Errorf("failed to delete federation peer %s: %w", name, err)
+	}
+	if result.DeletedCount == 0 {
+		return errcode.New(errcode.NotFound, "federation peer not found: %s", name)
+	}
+	return nil
+}
+
+// CreateDelegation records a task delegated to a peer
+func (s *FederationStorage) CreateDelegation(delegation *Delegation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.delegations.InsertOne(ctx, delegation); err != nil {
+		return fmt.Errorf("failed to save delegation: %w", err)
+	}
+	return nil
+}
+
+// ListDelegations returns delegations, newest first. With openOnly, only
+// delegations whose remote task is not completed are returned.
+func (s *FederationStorage) ListDelegations(peer string, openOnly bool) ([]*Delegation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if peer != "" {
+		filter["peer"] = strings.ToLower(strings.TrimSpace(peer))
+	}
+	if openOnly {
+		filter["status"] = bson.M{"$ne": TaskStatusCompleted}
+	}
+	cursor, err := s.delegations.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	delegations := []*Delegation{}
+	if err := cursor.All(ctx, &delegations); err != nil {
+		return nil, fmt.Errorf("failed to decode delegations: %w", err)
+	}
+	return delegations, nil
+}
+
+// RecordDelegationSync stores the outcome of syncing a delegation: the remote
+// status when the sync succeeded, otherwise the error
+func (s *FederationStorage) RecordDelegationSync(id string, status TaskStatus, syncErr error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	update := bson.M{"$set": bson.M{"status": status, "lastSyncedAt": now}, "$unset": bson.M{"lastError": ""}}
+	if syncErr != nil {
+		update = bson.M{"$set": bson.M{"lastError": syncErr.Error()}}
+	}
+	if _, err := s.delegations.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return fmt.Errorf("failed to update delegation %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// encryptedFieldPrefix marks a field value sealed by FieldCipher. The key ID
+// and the base64 nonce+ciphertext follow: "enc:v1:<keyID>:<data>".
+const encryptedFieldPrefix = "enc:v1:"
+
+// FieldCipher seals sensitive string fields (task prompts and notes, knowledge
+// text) with AES-256-GCM before they reach MongoDB. New values use the active
+// key; every configured key can open values, so keys can be rotated. A nil
+// *FieldCipher leaves values unchanged.
+type FieldCipher struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// LoadFieldCipher returns the cipher configured by FIELD_ENCRYPTION_KEYS, or
+// nil when field encryption is off. Keys are comma-separated id:base64 pairs of
+// 32-byte keys; FIELD_ENCRYPTION_KEYS_FILE reads the same list from a file
+// (e.g. one mounted by a KMS or secret manager). FIELD_ENCRYPTION_ACTIVE_KEY
+// picks the key new values are sealed with (default: the first).
+func LoadFieldCipher() (*FieldCipher, error) {
+	list := os.Getenv("FIELD_ENCRYPTION_KEYS")
+	if path := os.Getenv("FIELD_ENCRYPTION_KEYS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read FIELD_ENCRYPTION_KEYS_FILE: %w", err)
+		}
+		list = string(data)
+	}
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+
+	keys := map[string][]byte{}
+	var first string
+	for _, pair := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == '\n' }) {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(pair, ":")
+		if !ok || strings.TrimSpace(id) == "" {
+			return nil, fmt.Errorf("invalid field encryption key %q: expected id:base64key", truncateKeyID(pair))
+		}
+		id = strings.TrimSpace(id)
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid field encryption key %s: %w", id, err)
+		}
+		if _, dup := keys[id]; dup {
+			return nil, fmt.Errorf("duplicate field encryption key %s", id)
+		}
+		keys[id] = key
+		if first == "" {
+			first = id
+		}
+	}
+
+	active := strings.TrimSpace(os.Getenv("FIELD_ENCRYPTION_ACTIVE_KEY"))
+	if active == "" {
+		active = first
+	}
+	return NewFieldCipher(active, keys)
+}
+
+// NewFieldCipher creates a cipher sealing with the active key. Keys must be
+// 32 bytes (AES-256) and IDs must not contain ':'.
+func NewFieldCipher(active string, keys map[string][]byte) (*FieldCipher, error) {
+	c := &FieldCipher{active: active, keys: map[string]cipher.AEAD{}}
+	for id, key := range keys {
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("field encryption key ID %q must not contain ':'", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("field encryption key %s must be 32 bytes, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field encryption key %s: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field encryption key %s: %w", id, err)
+		}
+		c.keys[id] = aead
+	}
+	if _, ok := c.keys[active]; !ok {
+		return nil, fmt.Errorf("active field encryption key %q is not configured", active)
+	}
+	return c, nil
+}
+
+// ActiveKey returns the ID of the key new values are sealed with
+func (c *FieldCipher) ActiveKey() string {
+	if c == nil {
+		return ""
+	}
+	return c.active
+}
+
+// Encrypt seals a value with the active key. Empty values stay empty so
+// omitempty fields remain absent.
+func (c *FieldCipher) Encrypt(value string) (string, error) {
+	if c == nil || value == "" || IsEncryptedField(value) {
+		return value, nil
+	}
+	aead := c.keys[c.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(c.active))
+	return encryptedFieldPrefix + c.active + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a sealed value. Values written before encryption was enabled
+// are returned as they are.
+func (c *FieldCipher) Decrypt(value string) (string, error) {
+	if !IsEncryptedField(value) {
+		return value, nil
+	}
+	if c == nil {
+		return "", fmt.Errorf("field is encrypted but no FIELD_ENCRYPTION_KEYS are configured")
+	}
+
+	id, encoded, _ := strings.Cut(strings.TrimPrefix(value, encryptedFieldPrefix), ":")
+	aead, ok := c.keys[id]
+	if !ok {
+		return "", fmt.Errorf("field is encrypted with unknown key %q", id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted field")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt field with key %q: %w", id, err)
+	}
+	return string(plain), nil
+}
+
+// NeedsRotation reports whether a value is plaintext or sealed with a key
+// other than the active one
+func (c *FieldCipher) NeedsRotation(value string) bool {
+	if c == nil || value == "" {
+		return false
+	}
+	return !strings.HasPrefix(value, encryptedFieldPrefix+c.active+":")
+}
+
+// IsEncryptedField reports whether a stored value was sealed by FieldCipher
+func IsEncryptedField(value string) bool {
+	return strings.HasPrefix(value, encryptedFieldPrefix)
+}
+
+// SealAll encrypts each field in place
+func (c *FieldCipher) SealAll(fields []*string) error {
+	for _, field := range fields {
+		sealed, err := c.Encrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = sealed
+	}
+	return nil
+}
+
+// OpenAll decrypts each field in place
+func (c *FieldCipher) OpenAll(fields []*string) error {
+	for _, field := range fields {
+		plain, err := c.Decrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = plain
+	}
+	return nil
+}
+
+// Reseal seals fields that are plaintext or under a retired key with the
+// active key, and reports whether any field changed
+func (c *FieldCipher) Reseal(fields []*string) (bool, error) {
+	changed := false
+	for _, field := range fields {
+		if !c.NeedsRotation(*field) {
+			continue
+		}
+		plain, err := c.Decrypt(*field)
+		if err != nil {
+			return false, err
+		}
+		if *field, err = c.Encrypt(plain); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// FieldRotationResult counts the documents visited by a key rotation
+type FieldRotationResult struct {
+	Scanned int `json:"scanned"`
+	Rotated int `json:"rotated"`
+	Skipped int `json:"skipped"` // modified concurrently; rotated by the next run
+}
+
+// record counts the outcome of one optimistic re-seal update
+func (r *FieldRotationResult) record(result *mongo.UpdateResult, err error) error {
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		r.Skipped++
+	} else {
+		r.Rotated++
+	}
+	return nil
+}
+
+// humanTaskSecrets lists the encrypted fields of a human task
+func humanTaskSecrets(task *HumanTask) []*string {
+	return []*string{&task.Prompt, &task.Notes}
+}
+
+// agentTaskSecrets lists the encrypted fields of an agent task, including
+// its TODOs, checklist items and activity log
+func agentTaskSecrets(task *AgentTask) []*string {
+	fields := []*string{&task.Notes, &task.ContextSummary, &task.PriorWorkSummary, &task.HumanPromptNotes}
+	for i := range task.Todos {
+		todo := &task.Todos[i]
+		fields = append(fields, &todo.Notes, &todo.HumanPromptNotes)
+		for j := range todo.Checklist {
+			fields = append(fields, &todo.Checklist[j].Notes)
+		}
+	}
+	return append(fields, activitySecrets(task.Activity)...)
+}
+
+// activitySecrets lists the encrypted fields of activity log entries
+func activitySecrets(activity []TaskActivity) []*string {
+	fields := make([]*string, len(activity))
+	for i := range activity {
+		fields[i] = &activity[i].Notes
+	}
+	return fields
+}
+
+// truncateKeyID keeps key material out of configuration errors
+func truncateKeyID(pair string) string {
+	if len(pair) > 8 {
+		return pair[:8] + "..."
+	}
+	return pair
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestFieldCipherRoundTrip(t *testing.T) {
+	cipher, err := NewFieldCipher("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+
+	sealed, err := cipher.Encrypt("deploy password is hunter2")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, "enc:v1:k1:"))
+	assert.NotContains(t, sealed, "hunter2")
+
+	again, err := cipher.Encrypt(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, sealed, again, "sealed values are not sealed twice")
+
+	plain, err := cipher.Decrypt(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "deploy password is hunter2", plain)
+
+	empty, err := cipher.Encrypt("")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	legacy, err := cipher.Decrypt("written before encryption")
+	require.NoError(t, err)
+	assert.Equal(t, "written before encryption", legacy)
+}
+
+func TestFieldCipherNil(t *testing.T) {
+	var cipher *FieldCipher
+
+	value, err := cipher.Encrypt("plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", value)
+
+	_, err = cipher.Decrypt("enc:v1:k1:AAAA")
+	assert.Error(t, err)
+}
+
+func TestFieldCipherRejectsTampering(t *testing.T) {
+	cipher, err := NewFieldCipher("k1", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	require.NoError(t, err)
+	sealed, err := cipher.Encrypt("secret")
+	require.NoError(t, err)
+
+	// The key ID is authenticated, so relabelling a value fails
+	_, err = cipher.Decrypt(strings.Replace(sealed, ":k1:", ":k2:", 1))
+	assert.Error(t, err)
+
+	_, err = cipher.Decrypt("enc:v1:k3:" + strings.TrimPrefix(sealed, "enc:v1:k1:"))
+	assert.ErrorContains(t, err, "unknown key")
+}
+
+func TestFieldCipherRotation(t *testing.T) {
+	old, err := NewFieldCipher("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+	sealedOld, err := old.Encrypt("notes")
+	require.NoError(t, err)
+
+	rotated, err := NewFieldCipher("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	require.NoError(t, err)
+	assert.True(t, rotated.NeedsRotation(sealedOld))
+	assert.True(t, rotated.NeedsRotation("plaintext"))
+	assert.False(t, rotated.NeedsRotation(""))
+
+	task := &HumanTask{Prompt: sealedOld, Notes: "plain notes"}
+	changed, err := rotated.Reseal(humanTaskSecrets(task))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, strings.HasPrefix(task.Prompt, "enc:v1:k2:"))
+	assert.True(t, strings.HasPrefix(task.Notes, "enc:v1:k2:"))
+
+	changed, err = rotated.Reseal(humanTaskSecrets(task))
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	require.NoError(t, rotated.OpenAll(humanTaskSecrets(task)))
+	assert.Equal(t, "notes", task.Prompt)
+	assert.Equal(t, "plain notes", task.Notes)
+}
+
+func TestSealAgentTaskKeepsCaller(t *testing.T) {
+	cipher, err := NewFieldCipher("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+	s := &MongoTaskStorage{cipher: cipher}
+
+	task := &AgentTask{
+		Notes:    "task notes",
+		Todos:    []TodoItem{{Notes: "todo notes", Checklist: []ChecklistItem{{Notes: "item notes"}}}},
+		Activity: []TaskActivity{{Notes: "activity notes"}},
+	}
+	doc, err := s.sealAgentTask(task)
+	require.NoError(t, err)
+
+	for _, field := range agentTaskSecrets(doc) {
+		assert.True(t, *field == "" || IsEncryptedField(*field), "unsealed field %q", *field)
+	}
+	assert.Equal(t, "todo notes", task.Todos[0].Notes)
+	assert.Equal(t, "item notes", task.Todos[0].Checklist[0].Notes)
+	assert.Equal(t, "activity notes", task.Activity[0].Notes)
+}
+
+func TestLoadFieldCipher(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(testKey(1))
+	k2 := base64.StdEncoding.EncodeToString(testKey(2))
+
+	t.Setenv("FIELD_ENCRYPTION_KEYS", "")
+	cipher, err := LoadFieldCipher()
+	require.NoError(t, err)
+	assert.Nil(t, cipher)
+
+	t.Setenv("FIELD_ENCRYPTION_KEYS", "k1:"+k1+", k2:"+k2)
+	cipher, err = LoadFieldCipher()
+	require.NoError(t, err)
+	assert.Equal(t, "k1", cipher.ActiveKey())
+
+	t.Setenv("FIELD_ENCRYPTION_ACTIVE_KEY", "k2")
+	cipher, err = LoadFieldCipher()
+	require.NoError(t, err)
+	assert.Equal(t, "k2", cipher.ActiveKey())
+
+	t.Setenv("FIELD_ENCRYPTION_ACTIVE_KEY", "k3")
+	_, err = LoadFieldCipher()
+	assert.ErrorContains(t, err, "not configured")
+
+	t.Setenv("FIELD_ENCRYPTION_ACTIVE_KEY", "")
+	t.Setenv("FIELD_ENCRYPTION_KEYS", "k1:"+base64.StdEncoding.EncodeToString([]byte("short")))
+	_, err = LoadFieldCipher()
+	assert.ErrorContains(t, err, "32 bytes")
+
+	path := filepath.Join(t.TempDir(), "keys")
+	require.NoError(t, os.WriteFile(path, []byte("file:"+k1+"\n"), 0o600))
+	t.Setenv("FIELD_ENCRYPTION_KEYS_FILE", path)
+	cipher, err = LoadFieldCipher()
+	require.NoError(t, err)
+	assert.Equal(t, "file", cipher.ActiveKey())
+}
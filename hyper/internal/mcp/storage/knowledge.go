@@ -7,6 +7,9 @@ import (
 	"strings"
 	"time"
 
+	"hyper/internal/console"
+	"hyper/internal/priority"
+
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -20,6 +23,8 @@ type KnowledgeEntry struct {
 	Text       string                 `json:"text" bson:"text"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty" bson:"metadata,omitempty"`
 	CreatedAt  time.Time              `json:"createdAt" bson:"createdAt"`
+	HitCount   int64                  `json:"hitCount,omitempty" bson:"hitCount,omitempty"`   // Times returned by a query
+	LastHitAt  *time.Time             `json:"lastHitAt,omitempty" bson:"lastHitAt,omitempty"` // Last time returned by a query
 }
 
 // QueryResult represents a knowledge query result with similarity score
@@ -51,17 +56,65 @@ type KnowledgeStorage interface {
 	ListKnowledge(collection string, limit int) ([]*KnowledgeEntry, error)
 }
 
+// KnowledgeInput is a knowledge entry to store with UpsertBatch
+type KnowledgeInput struct {
+	Text     string
+	Metadata map[string]interface{}
+	Vector   []float64 // Optional precomputed embedding; reused when its dimension matches
+}
+
+// BatchKnowledgeStorage is implemented by knowledge storages that can store many
+// entries of a collection at once, embedding them in batches
+type BatchKnowledgeStorage interface {
+	UpsertBatch(collection string, inputs []KnowledgeInput) ([]*KnowledgeEntry, error)
+}
+
+// ExportedKnowledge is one entry of a knowledge export, optionally with its
+// stored embedding
+type ExportedKnowledge struct {
+	*KnowledgeEntry
+	Vector []float64 `json:"vector,omitempty"`
+}
+
+// KnowledgeExporter is implemented by knowledge storages that can stream every
+// entry of a collection, oldest first
+type KnowledgeExporter interface {
+	ExportKnowledge(ctx context.Context, collection string, withVectors bool, visit func(*ExportedKnowledge) error) error
+}
+
+// IncrementalKnowledgeExporter is implemented by knowledge storages that can
+// export only the entries created at or after a point in time, so replicas can
+// sync incrementally
+type IncrementalKnowledgeExporter interface {
+	ExportKnowledgeSince(ctx context.Context, collection string, since time.Time, withVectors bool, visit func(*ExportedKnowledge) error) error
+}
+
+// batchPointStore is implemented by Qdrant clients that embed and store many points per request
+type batchPointStore interface {
+	StorePoints(collectionName string, points []KnowledgePoint) error
+}
+
+// pointVectorStore is implemented by Qdrant clients that can return stored vectors
+type pointVectorStore interface {
+	PointVectors(collectionName string, ids []string) (map[string][]float64, error)
+}
+
+// exportVectorBatchSize is how many entries' vectors are fetched from Qdrant per request
+const exportVectorBatchSize = 256
+
 // MongoKnowledgeStorage implements KnowledgeStorage using MongoDB + Qdrant
 type MongoKnowledgeStorage struct {
 	knowledgeCollection *mongo.Collection
 	qdrantClient        QdrantClientInterface
 	vectorDimension     int
+	cipher              *FieldCipher         // optional, seals entry text in MongoDB
+	useObserver         KnowledgeUseObserver // optional, told about queries and writes
 }
 
 // NewMongoKnowledgeStorage creates a new MongoDB + Qdrant knowledge storage
 func NewMongoKnowledgeStorage(db *mongo.Database, qdrantClient QdrantClientInterface) (*MongoKnowledgeStorage, error) {
 	storage := &MongoKnowledgeStorage{
-		knowledgeCollection: db.Collection("knowledge_entries"),
+		knowledgeCollection: db.Collection(CollectionName("knowledge_entries")),
 		qdrantClient:        qdrantClient,
 		vectorDimension:     768, // TEI nomic-embed-text-v1.5 dimension
 	}
@@ -100,17 +153,22 @@ func NewMongoKnowledgeStorage(db *mongo.Database, qdrantClient QdrantClientInter
 // Upsert stores or updates a knowledge entry in both MongoDB and Qdrant
 func (s *MongoKnowledgeStorage) Upsert(collection, text string, metadata map[string]interface{}) (*KnowledgeEntry, error) {
 	ctx := context.Background()
+	s.noteUse(collection)
 
 	entry := &KnowledgeEntry{
 		ID:         uuid.New().String(),
 		Collection: collection,
 		Text:       text,
-		Metadata:   metadata,
+		Metadata:   withLanguage(metadata, text),
 		CreatedAt:  time.Now().UTC(),
 	}
 
 	// Store in MongoDB for metadata and audit trail
-	_, err := s.knowledgeCollection.InsertOne(ctx, entry)
+	doc, err := s.sealEntry(entry)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.knowledgeCollection.InsertOne(ctx, doc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert knowledge entry in MongoDB: %w", err)
 	}
@@ -120,12 +178,12 @@ func (s *MongoKnowledgeStorage) Upsert(collection, text string, metadata map[str
 		// Ensure collection exists
 		if err := s.qdrantClient.EnsureCollection(collection, s.vectorDimension); err != nil {
 			// Log error but don't fail - MongoDB has the data
-			fmt.Printf("Warning: failed to ensure Qdrant collection: %v\n", err)
+			console.Printf("Warning: failed to ensure Qdrant collection: %v\n", err)
 		} else {
 			// Store vector point
-			if err := s.qdrantClient.StorePoint(collection, entry.ID, text, metadata); err != nil {
+			if err := s.qdrantClient.StorePoint(collection, entry.ID, text, entry.Metadata); err != nil {
 				// Log error but don't fail - MongoDB has the data
-				fmt.Printf("Warning: failed to store in Qdrant: %v\n", err)
+				console.Printf("Warning: failed to store in Qdrant: %v\n", err)
 			}
 		}
 	}
@@ -133,13 +191,90 @@ func (s *MongoKnowledgeStorage) Upsert(collection, text string, metadata map[str
 	return entry, nil
 }
 
+// UpsertBatch stores many entries of one collection in MongoDB and Qdrant,
+// embedding them with one request where the Qdrant client supports it
+func (s *MongoKnowledgeStorage) UpsertBatch(collection string, inputs []KnowledgeInput) ([]*KnowledgeEntry, error) {
+	ctx := context.Background()
+
+	entries := make([]*KnowledgeEntry, len(inputs))
+	if len(inputs) == 0 {
+		return entries, nil
+	}
+	s.noteUse(collection)
+
+	now := time.Now().UTC()
+	documents := make([]interface{}, len(inputs))
+	vectors := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		vectors[i] = input.Vector
+		entries[i] = &KnowledgeEntry{
+			ID:         uuid.New().String(),
+			Collection: collection,
+			Text:       input.Text,
+			Metadata:   withLanguage(input.Metadata, input.Text),
+			CreatedAt:  now,
+		}
+		doc, err := s.sealEntry(entries[i])
+		if err != nil {
+			return nil, err
+		}
+		documents[i] = doc
+	}
+
+	// Store in MongoDB for metadata and audit trail
+	if _, err := s.knowledgeCollection.InsertMany(ctx, documents); err != nil {
+		return nil, fmt.Errorf("failed to insert knowledge entries in MongoDB: %w", err)
+	}
+
+	// Store in Qdrant for vector search; as with Upsert, MongoDB has the data
+	// if this fails
+	if s.qdrantClient != nil {
+		if err := s.qdrantClient.EnsureCollection(collection, s.vectorDimension); err != nil {
+			console.Printf("Warning: failed to ensure Qdrant collection: %v\n", err)
+		} else if batchStore, ok := s.qdrantClient.(batchPointStore); ok {
+			points := make([]KnowledgePoint, len(entries))
+			for i, entry := range entries {
+				points[i] = KnowledgePoint{ID: entry.ID, Text: entry.Text, Metadata: entry.Metadata, Vector: vectors[i]}
+			}
+			if err := batchStore.StorePoints(collection, points); err != nil {
+				console.Printf("Warning: failed to store batch in Qdrant: %v\n", err)
+			}
+		} else {
+			for _, entry := range entries {
+				if err := s.qdrantClient.StorePoint(collection, entry.ID, entry.Text, entry.Metadata); err != nil {
+					console.Printf("Warning: failed to store in Qdrant: %v\n", err)
+				}
+			}
+		}
+	}
+
+	return entries, nil
+}
+
 // Query searches for knowledge entries using Qdrant vector search
 func (s *MongoKnowledgeStorage) Query(collection, query string, limit int) ([]*QueryResult, error) {
-	ctx := context.Background()
+	return s.query(context.Background(), collection, query, "", limit)
+}
+
+// QueryContext searches a collection like Query (restricted to language when
+// it is set), bounded by ctx's deadline and admitted at ctx's priority
+func (s *MongoKnowledgeStorage) QueryContext(ctx context.Context, collection, query, language string, limit int) ([]*QueryResult, error) {
+	done, err := priority.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return s.query(ctx, collection, query, strings.ToLower(strings.TrimSpace(language)), limit)
+}
+
+// query searches a collection, restricted to entries tagged with language
+// when it is set
+func (s *MongoKnowledgeStorage) query(ctx context.Context, collection, query, language string, limit int) ([]*QueryResult, error) {
+	s.noteUse(collection)
 
 	// Use Qdrant for semantic vector search if available
-	if s.qdrantClient != nil {
-		results, err := s.qdrantClient.SearchSimilar(collection, query, limit)
+	if searcher := s.similaritySearch(ctx, language); searcher != nil {
+		results, err := searcher(collection, query, limit)
 		if err == nil && len(results) > 0 {
 			// Convert QdrantQueryResult to QueryResult
 			queryResults := make([]*QueryResult, len(results))
@@ -149,19 +284,35 @@ func (s *MongoKnowledgeStorage) Query(collection, query string, limit int) ([]*Q
 					Score: r.Score,
 				}
 			}
+			s.recordHits(ctx, queryResults)
 			return queryResults, nil
 		}
-		// Log error but continue to MongoDB fallback
+		// Log error but continue to MongoDB fallback, unless the caller gave up
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err != nil {
-			fmt.Printf("Warning: Qdrant search failed, falling back to MongoDB: %v\n", err)
+			console.Printf("Warning: Qdrant search failed, falling back to MongoDB: %v\n", err)
 		}
 	}
 
+	// Sealed text cannot be matched by the text index, so match in memory
+	if s.cipher != nil {
+		results, err := s.fallbackQuery(ctx, collection, query, language, limit)
+		if err == nil {
+			s.recordHits(ctx, results)
+		}
+		return results, err
+	}
+
 	// Fallback to MongoDB text search
 	filter := bson.M{
 		"collection": collection,
 		"$text":      bson.M{"$search": query},
 	}
+	if language != "" {
+		filter["metadata."+LanguageKey] = language
+	}
 
 	opts := options.Find().
 		SetProjection(bson.D{{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}}}).
@@ -170,6 +321,9 @@ func (s *MongoKnowledgeStorage) Query(collection, query string, limit int) ([]*Q
 	if limit > 0 {
 		opts.SetLimit(int64(limit))
 	}
+	if maxTime := maxTimeFor(ctx); maxTime != nil {
+		opts.SetMaxTime(*maxTime)
+	}
 
 	cursor, err := s.knowledgeCollection.Find(ctx, filter, opts)
 	if err != nil {
@@ -184,7 +338,11 @@ func (s *MongoKnowledgeStorage) Query(collection, query string, limit int) ([]*Q
 
 	// If MongoDB text search returns no results, fallback to simple similarity
 	if len(entries) == 0 {
-		return s.fallbackQuery(ctx, collection, query, limit)
+		results, err := s.fallbackQuery(ctx, collection, query, language, limit)
+		if err == nil {
+			s.recordHits(ctx, results)
+		}
+		return results, err
 	}
 
 	// Convert to QueryResult format
@@ -196,13 +354,21 @@ func (s *MongoKnowledgeStorage) Query(collection, query string, limit int) ([]*Q
 		}
 	}
 
+	s.recordHits(ctx, results)
 	return results, nil
 }
 
 // fallbackQuery performs simple similarity matching when text search fails
-func (s *MongoKnowledgeStorage) fallbackQuery(ctx context.Context, collection, query string, limit int) ([]*QueryResult, error) {
+func (s *MongoKnowledgeStorage) fallbackQuery(ctx context.Context, collection, query, language string, limit int) ([]*QueryResult, error) {
 	filter := bson.M{"collection": collection}
-	cursor, err := s.knowledgeCollection.Find(ctx, filter)
+	if language != "" {
+		filter["metadata."+LanguageKey] = language
+	}
+	opts := options.Find()
+	if maxTime := maxTimeFor(ctx); maxTime != nil {
+		opts.SetMaxTime(*maxTime)
+	}
+	cursor, err := s.knowledgeCollection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query knowledge: %w", err)
 	}
@@ -212,6 +378,9 @@ func (s *MongoKnowledgeStorage) fallbackQuery(ctx context.Context, collection, q
 	if err := cursor.All(ctx, &entries); err != nil {
 		return nil, fmt.Errorf("failed to decode knowledge entries: %w", err)
 	}
+	if err := s.openEntries(entries); err != nil {
+		return nil, err
+	}
 
 	// Calculate similarity scores
 	results := make([]*QueryResult, 0)
@@ -320,33 +489,37 @@ func (s *MongoKnowledgeStorage) GetPopularCollections(limit int) ([]*CollectionS
 
 // GetCollectionStatsWithMetadata returns all collections with stats and category metadata
 func (s *MongoKnowledgeStorage) GetCollectionStatsWithMetadata() ([]*CollectionWithMetadata, error) {
-	// Hardcoded metadata mapping (from MCP resource handler)
-	categoryMap := map[string]string{
-		"team-coordination":              "Task",
-		"agent-coordination":             "Task",
-		"technical-knowledge":            "Tech",
-		"code-patterns":                  "Tech",
-		"adr":                            "Tech",
-		"data-contracts":                 "Tech",
-		"technical-debt-registry":        "Tech",
-		"ui-component-patterns":          "UI",
-		"ui-test-strategies":             "UI",
-		"ui-accessibility-standards":     "UI",
-		"ui-visual-regression-baseline":  "UI",
-		"mcp-operations":                 "Ops",
-		"code-quality-violations":        "Ops",
-	}
-
 	// Get collection stats from MongoDB
 	stats, err := s.GetPopularCollections(0) // 0 = no limit, get all
 	if err != nil {
 		return nil, err
 	}
+	return CollectionsWithCategories(stats), nil
+}
+
+// collectionCategories maps well-known collections to their UI category
+// (from MCP resource handler)
+var collectionCategories = map[string]string{
+	"team-coordination":             "Task",
+	"agent-coordination":            "Task",
+	"technical-knowledge":           "Tech",
+	"code-patterns":                 "Tech",
+	"adr":                           "Tech",
+	"data-contracts":                "Tech",
+	"technical-debt-registry":       "Tech",
+	"ui-component-patterns":         "UI",
+	"ui-test-strategies":            "UI",
+	"ui-accessibility-standards":    "UI",
+	"ui-visual-regression-baseline": "UI",
+	"mcp-operations":                "Ops",
+	"code-quality-violations":       "Ops",
+}
 
-	// Merge with metadata
+// CollectionsWithCategories adds the UI category to collection stats
+func CollectionsWithCategories(stats []*CollectionStats) []*CollectionWithMetadata {
 	results := make([]*CollectionWithMetadata, 0, len(stats))
 	for _, stat := range stats {
-		category := categoryMap[stat.Collection]
+		category := collectionCategories[stat.Collection]
 
 		// Handle dynamic task collections
 		if category == "" {
@@ -363,8 +536,7 @@ func (s *MongoKnowledgeStorage) GetCollectionStatsWithMetadata() ([]*CollectionW
 			Count:    stat.Count,
 		})
 	}
-
-	return results, nil
+	return results
 }
 
 // ListKnowledge retrieves knowledge entries from a collection without search (browse mode)
@@ -393,6 +565,9 @@ func (s *MongoKnowledgeStorage) ListKnowledge(collection string, limit int) ([]*
 	if err := cursor.All(ctx, &entries); err != nil {
 		return nil, fmt.Errorf("failed to decode knowledge entries: %w", err)
 	}
+	if err := s.openEntries(entries); err != nil {
+		return nil, err
+	}
 
 	// Return empty slice (not nil) if no results
 	if entries == nil {
@@ -402,6 +577,85 @@ func (s *MongoKnowledgeStorage) ListKnowledge(collection string, limit int) ([]*
 	return entries, nil
 }
 
+// ExportKnowledge streams every entry of a collection to visit, oldest first.
+// With withVectors, each entry carries its Qdrant vector; entries missing from
+// Qdrant are exported without one.
+func (s *MongoKnowledgeStorage) ExportKnowledge(ctx context.Context, collection string, withVectors bool, visit func(*ExportedKnowledge) error) error {
+	return s.ExportKnowledgeSince(ctx, collection, time.Time{}, withVectors, visit)
+}
+
+// ExportKnowledgeSince is ExportKnowledge restricted to entries created at or
+// after since; the zero time exports everything
+func (s *MongoKnowledgeStorage) ExportKnowledgeSince(ctx context.Context, collection string, since time.Time, withVectors bool, visit func(*ExportedKnowledge) error) error {
+	var vectorStore pointVectorStore
+	if withVectors {
+		store, ok := s.qdrantClient.(pointVectorStore)
+		if !ok {
+			return fmt.Errorf("vector export unavailable: Qdrant is not configured")
+		}
+		vectorStore = store
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+	filter := bson.M{"collection": collection}
+	if !since.IsZero() {
+		filter["createdAt"] = bson.M{"$gte": since}
+	}
+	cursor, err := s.knowledgeCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return fmt.Errorf("failed to export knowledge entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	// Entries are buffered so vectors can be fetched in batches
+	batch := make([]*KnowledgeEntry, 0, exportVectorBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		var vectors map[string][]float64
+		if vectorStore != nil {
+			ids := make([]string, len(batch))
+			for i, entry := range batch {
+				ids[i] = entry.ID
+			}
+			vectors, err = vectorStore.PointVectors(collection, ids)
+			if err != nil {
+				return fmt.Errorf("failed to export vectors: %w", err)
+			}
+		}
+
+		for _, entry := range batch {
+			if err := visit(&ExportedKnowledge{KnowledgeEntry: entry, Vector: vectors[entry.ID]}); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var entry KnowledgeEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return fmt.Errorf("failed to decode knowledge entry: %w", err)
+		}
+		if entry.Text, err = s.cipher.Decrypt(entry.Text); err != nil {
+			return fmt.Errorf("knowledge entry %s: %w", entry.ID, err)
+		}
+		batch = append(batch, &entry)
+		if len(batch) >= exportVectorBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to export knowledge entries: %w", err)
+	}
+	return flush()
+}
+
 // calculateSimilarity provides simple text similarity scoring
 // Returns a score between 0.0 and 1.0 based on:
 // - Exact match: 1.0
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"hyper/internal/console"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultKnowledgeStaleAfter is how long an entry may go without a query hit
+// before it is reported as stale
+const DefaultKnowledgeStaleAfter = 30 * 24 * time.Hour
+
+// KnowledgeUsageEntry is an entry listed in a usage report
+type KnowledgeUsageEntry struct {
+	ID        string     `json:"id"`
+	Text      string     `json:"text"`
+	HitCount  int64      `json:"hitCount"`
+	LastHitAt *time.Time `json:"lastHitAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// KnowledgeUsageReport summarizes query hits for one collection.
+// Never-hit entries only count entries older than the staleness window, so
+// freshly stored knowledge is not flagged before it had a chance to be used.
+type KnowledgeUsageReport struct {
+	Collection      string                 `json:"collection"`
+	TotalEntries    int                    `json:"totalEntries"`
+	TotalHits       int64                  `json:"totalHits"`
+	LastHitAt       *time.Time             `json:"lastHitAt,omitempty"`
+	NeverHitCount   int                    `json:"neverHitCount"`
+	StaleCount      int                    `json:"staleCount"`
+	NeverHitEntries []*KnowledgeUsageEntry `json:"neverHitEntries"` // Oldest first
+	StaleEntries    []*KnowledgeUsageEntry `json:"staleEntries"`    // Least recently hit first
+}
+
+// KnowledgeAnalytics is implemented by knowledge storages that track query hits
+type KnowledgeAnalytics interface {
+	// GetUsageReports returns a report per collection, listing at most
+	// limit never-hit and limit stale entries each
+	GetUsageReports(staleAfter time.Duration, limit int) ([]*KnowledgeUsageReport, error)
+}
+
+// recordHits bumps the hit count and last-hit time of entries returned by a query
+func (s *MongoKnowledgeStorage) recordHits(ctx context.Context, results []*QueryResult) {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.Entry != nil && result.Entry.ID != "" {
+			ids = append(ids, result.Entry.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	// The results are already found, so record them even if the caller's
+	// deadline runs out meanwhile
+	_, err := s.knowledgeCollection.UpdateMany(context.WithoutCancel(ctx),
+		bson.M{"entryId": bson.M{"$in": ids}},
+		bson.M{
+			"$inc": bson.M{"hitCount": 1},
+			"$set": bson.M{"lastHitAt": time.Now().UTC()},
+		})
+	if err != nil {
+		// Analytics are best-effort; never fail the query over them
+		console.Printf("Warning: failed to record knowledge hits: %v\n", err)
+	}
+}
+
+// GetUsageReports returns hit statistics and never-hit/stale entries per collection
+func (s *MongoKnowledgeStorage) GetUsageReports(staleAfter time.Duration, limit int) ([]*KnowledgeUsageReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if staleAfter <= 0 {
+		staleAfter = DefaultKnowledgeStaleAfter
+	}
+	cutoff := time.Now().UTC().Add(-staleAfter)
+
+	neverHit := bson.M{"$or": bson.A{
+		bson.M{"hitCount": bson.M{"$exists": false}},
+		bson.M{"hitCount": 0},
+	}}
+	stale := bson.M{"lastHitAt": bson.M{"$lt": cutoff}}
+
+	pipeline := []bson.M{
+		{
+			"$group": bson.M{
+				"_id":       "$collection",
+				"total":     bson.M{"$sum": 1},
+				"totalHits": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$hitCount", 0}}},
+				"lastHitAt": bson.M{"$max": "$lastHitAt"},
+				"neverHit": bson.M{"$sum": bson.M{"$cond": bson.A{
+					bson.M{"$and": bson.A{
+						bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$hitCount", 0}}, 0}},
+						bson.M{"$lt": bson.A{"$createdAt", cutoff}},
+					}}, 1, 0,
+				}}},
+				"stale": bson.M{"$sum": bson.M{"$cond": bson.A{
+					bson.M{"$and": bson.A{
+						bson.M{"$gt": bson.A{"$lastHitAt", nil}},
+						bson.M{"$lt": bson.A{"$lastHitAt", cutoff}},
+					}}, 1, 0,
+				}}},
+			},
+		},
+	}
+
+	cursor, err := s.knowledgeCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate knowledge usage: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Collection string     `bson:"_id"`
+		Total      int        `bson:"total"`
+		TotalHits  int64      `bson:"totalHits"`
+		LastHitAt  *time.Time `bson:"lastHitAt"`
+		NeverHit   int        `bson:"neverHit"`
+		Stale      int        `bson:"stale"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode knowledge usage: %w", err)
+	}
+
+	reports := make([]*KnowledgeUsageReport, 0, len(rows))
+	for _, row := range rows {
+		report := &KnowledgeUsageReport{
+			Collection:    row.Collection,
+			TotalEntries:  row.Total,
+			TotalHits:     row.TotalHits,
+			LastHitAt:     row.LastHitAt,
+			NeverHitCount: row.NeverHit,
+			StaleCount:    row.Stale,
+		}
+
+		if report.NeverHitEntries, err = s.findUsageEntries(ctx,
+			bson.M{"$and": bson.A{bson.M{"collection": row.Collection, "createdAt": bson.M{"$lt": cutoff}}, neverHit}},
+			bson.D{{Key: "createdAt", Value: 1}}, limit); err != nil {
+			return nil, err
+		}
+		if report.StaleEntries, err = s.findUsageEntries(ctx,
+			bson.M{"$and": bson.A{bson.M{"collection": row.Collection}, stale}},
+			bson.D{{Key: "lastHitAt", Value: 1}}, limit); err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	// Collections needing the most housekeeping first
+	sort.Slice(reports, func(i, j int) bool {
+		ci := reports[i].NeverHitCount + reports[i].StaleCount
+		cj := reports[j].NeverHitCount + reports[j].StaleCount
+		if ci != cj {
+			return ci > cj
+		}
+		return reports[i].Collection < reports[j].Collection
+	})
+
+	return reports, nil
+}
+
+// findUsageEntries lists up to limit entries matching filter
+func (s *MongoKnowledgeStorage) findUsageEntries(ctx context.Context, filter bson.M, sortBy bson.D, limit int) ([]*KnowledgeUsageEntry, error) {
+	opts := options.Find().SetSort(sortBy)
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := s.knowledgeCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge usage entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*KnowledgeEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode knowledge usage entries: %w", err)
+	}
+	if err := s.openEntries(entries); err != nil {
+		return nil, err
+	}
+
+	usage := make([]*KnowledgeUsageEntry, len(entries))
+	for i, entry := range entries {
+		usage[i] = &KnowledgeUsageEntry{
+			ID:        entry.ID,
+			Text:      entry.Text,
+			HitCount:  entry.HitCount,
+			LastHitAt: entry.LastHitAt,
+			CreatedAt: entry.CreatedAt,
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Vector tiers of a knowledge collection
+const (
+	VectorTierHot         = "hot"         // Vectors are in Qdrant
+	VectorTierArchived    = "archived"    // Vectors are in a snapshot file, the Qdrant collection is deleted
+	VectorTierRehydrating = "rehydrating" // Vectors are being restored from the snapshot
+)
+
+// VectorArchive tracks the vector tier of one knowledge collection or code
+// index folder: when it was last used and, while archived, where its vectors
+// were snapshotted. MongoDB keeps the entries and files in every tier.
+type VectorArchive struct {
+	Collection           string     `bson:"_id" json:"collection"` // Knowledge collection, or folder path in NewFolderVectorArchiveStorage
+	Tier                 string     `bson:"tier" json:"tier"`
+	LastUsedAt           time.Time  `bson:"lastUsedAt" json:"lastUsedAt"` // Last query or write
+	SnapshotPath         string     `bson:"snapshotPath,omitempty" json:"snapshotPath,omitempty"`
+	Points               int        `bson:"points,omitempty" json:"points,omitempty"` // Vectors in the snapshot
+	ArchivedAt           *time.Time `bson:"archivedAt,omitempty" json:"archivedAt,omitempty"`
+	RehydrationStartedAt *time.Time `bson:"rehydrationStartedAt,omitempty" json:"rehydrationStartedAt,omitempty"`
+	RehydratedAt         *time.Time `bson:"rehydratedAt,omitempty" json:"rehydratedAt,omitempty"`
+	Error                string     `bson:"error,omitempty" json:"error,omitempty"` // Last failed rehydration
+}
+
+// VectorArchiveStorage handles persistence of knowledge collection and code
+// index folder vector tiers
+type VectorArchiveStorage struct {
+	collection *mongo.Collection
+}
+
+// NewVectorArchiveStorage creates a new vector archive storage
+func NewVectorArchiveStorage(db *mongo.Database) *VectorArchiveStorage {
+	return &VectorArchiveStorage{collection: db.Collection(CollectionName("vector_archives"))}
+}
+
+// NewFolderVectorArchiveStorage creates a vector archive storage for code
+// index folders, keyed by folder path
+func NewFolderVectorArchiveStorage(db *mongo.Database) *VectorArchiveStorage {
+	return &VectorArchiveStorage{collection: db.Collection(CollectionName("folder_vector_archives"))}
+}
+
+// GetVectorArchive returns the tier record of a collection, or nil if it has
+// none yet
+func (s *VectorArchiveStorage) GetVectorArchive(collection string) (*VectorArchive, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var archive VectorArchive
+	err := s.collection.FindOne(ctx, bson.M{"_id": collection}).Decode(&archive)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get vector archive of %s: %w", collection, err)
+	}
+	return &archive, nil
+}
+
+// ListVectorArchives returns the tier records of all collections, by name
+func (s *VectorArchiveStorage) ListVectorArchives() ([]*VectorArchive, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vector archives: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	archives := []*VectorArchive{}
+	if err := cursor.All(ctx, &archives); err != nil {
+		return nil, fmt.Errorf("failed to decode vector archives: %w", err)
+	}
+	return archives, nil
+}
+
+// TouchVectorArchive records that a collection was used at, creating its
+// record in the hot tier if it has none
+func (s *VectorArchiveStorage) TouchVectorArchive(collection string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": collection},
+		bson.M{
+			"$max":         bson.M{"lastUsedAt": at.UTC()},
+			"$setOnInsert": bson.M{"tier": VectorTierHot},
+		},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to record use of %s: %w", collection, err)
+	}
+	return nil
+}
+
+// SaveVectorArchive stores the tier record of a collection. The last use
+// time is kept if it is newer than archive's.
+func (s *VectorArchiveStorage) SaveVectorArchive(archive *VectorArchive) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set := bson.M{
+		"tier":                 archive.Tier,
+		"snapshotPath":         archive.SnapshotPath,
+		"points":               archive.Points,
+		"archivedAt":           archive.ArchivedAt,
+		"rehydrationStartedAt": archive.RehydrationStartedAt,
+		"rehydratedAt":         archive.RehydratedAt,
+		"error":                archive.Error,
+	}
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": archive.Collection},
+		bson.M{"$set": set, "$max": bson.M{"lastUsedAt": archive.LastUsedAt.UTC()}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save vector archive of %s: %w", archive.Collection, err)
+	}
+	return nil
+}
+
+// BeginRehydration moves an archived collection to the rehydrating tier. It
+// reports false when the collection is not archived, e.g. because another
+// process already started restoring it. A rehydration that started before
+// staleBefore is taken over.
+func (s *VectorArchiveStorage) BeginRehydration(collection string, at, staleBefore time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": collection, "$or": bson.A{
+			bson.M{"tier": VectorTierArchived},
+			bson.M{"tier": VectorTierRehydrating, "rehydrationStartedAt": bson.M{"$lt": staleBefore.UTC()}},
+		}},
+		bson.M{"$set": bson.M{"tier": VectorTierRehydrating, "rehydrationStartedAt": at.UTC(), "error": ""}})
+	if err != nil {
+		return false, fmt.Errorf("failed to start rehydrating %s: %w", collection, err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// KnowledgeUseObserver is told about every query of and write to a
+// knowledge collection. KnowledgeCollectionUsed must not block.
+type KnowledgeUseObserver interface {
+	KnowledgeCollectionUsed(collection string)
+}
+
+// SetUseObserver makes the storage report queries and writes to observer
+func (s *MongoKnowledgeStorage) SetUseObserver(observer KnowledgeUseObserver) {
+	s.useObserver = observer
+}
+
+// noteUse reports a query of or write to collection to the observer, if any
+func (s *MongoKnowledgeStorage) noteUse(collection string) {
+	if s.useObserver != nil {
+		s.useObserver.KnowledgeCollectionUsed(collection)
+	}
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Metadata keys linking the chunks of knowledge text stored in pieces
+const (
+	ChunkGroupKey = "chunkGroupId" // shared by every chunk of one text
+	ChunkIndexKey = "chunkIndex"   // 0-based position of the chunk
+	ChunkCountKey = "chunkCount"   // number of chunks in the group
+)
+
+// chunkSeparators are tried in order when splitting text, so chunks end at
+// paragraph, line, sentence and word boundaries where possible
+var chunkSeparators = []string{"\n\n", "\n", ". ", " "}
+
+// SplitKnowledgeText splits text into chunks of at most maxBytes bytes each.
+// Concatenating the chunks yields text again.
+func SplitKnowledgeText(text string, maxBytes int) []string {
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		return []string{text}
+	}
+
+	var chunks []string
+	for len(text) > maxBytes {
+		cut := chunkCut(text, maxBytes)
+		chunks = append(chunks, text[:cut])
+		text = text[cut:]
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// chunkCut returns where the first chunk of text ends: after the last
+// separator within maxBytes, or on a rune boundary when there is none
+func chunkCut(text string, maxBytes int) int {
+	window := text[:maxBytes]
+	for _, separator := range chunkSeparators {
+		// Ignore separators in the first half so chunks do not get tiny
+		if i := strings.LastIndex(window, separator); i >= maxBytes/2 {
+			return i + len(separator)
+		}
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		// maxBytes is smaller than the first rune; keep the rune whole
+		_, size := utf8.DecodeRuneInString(text)
+		return size
+	}
+	return cut
+}
+
+// KnowledgeChunkMetadata returns a copy of metadata marking a chunk as piece
+// index of count in group
+func KnowledgeChunkMetadata(metadata map[string]interface{}, group string, index, count int) map[string]interface{} {
+	chunk := make(map[string]interface{}, len(metadata)+3)
+	for k, v := range metadata {
+		chunk[k] = v
+	}
+	chunk[ChunkGroupKey] = group
+	chunk[ChunkIndexKey] = index
+	chunk[ChunkCountKey] = count
+	return chunk
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitKnowledgeText(t *testing.T) {
+	assert.Equal(t, []string{"short"}, SplitKnowledgeText("short", 100))
+
+	text := strings.Repeat("First paragraph sentence. ", 4) + "\n\n" + strings.Repeat("Second paragraph sentence. ", 4)
+	chunks := SplitKnowledgeText(text, 120)
+	assert.Equal(t, text, strings.Join(chunks, ""))
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, len(chunk), 120)
+	}
+	assert.True(t, strings.HasSuffix(chunks[0], "\n\n"), "the first chunk ends at the paragraph break")
+
+	// Without separators, multi-byte runes are never split
+	unbroken := strings.Repeat("é", 50)
+	chunks = SplitKnowledgeText(unbroken, 7)
+	assert.Equal(t, unbroken, strings.Join(chunks, ""))
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, len(chunk), 7)
+		assert.Equal(t, 0, len(chunk)%2, "chunk %q splits a rune", chunk)
+	}
+}
+
+func TestKnowledgeChunkMetadata(t *testing.T) {
+	metadata := map[string]interface{}{"source": "adr"}
+	chunk := KnowledgeChunkMetadata(metadata, "group-1", 1, 3)
+	assert.Equal(t, map[string]interface{}{"source": "adr", ChunkGroupKey: "group-1", ChunkIndexKey: 1, ChunkCountKey: 3}, chunk)
+	assert.NotContains(t, metadata, ChunkGroupKey)
+}
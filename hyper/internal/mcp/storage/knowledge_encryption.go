@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SetFieldCipher enables encryption at rest of knowledge text in MongoDB.
+// Qdrant keeps its own payload copy for vector search. Once enabled, MongoDB
+// fallback search matches in memory since the text index only sees ciphertext.
+func (s *MongoKnowledgeStorage) SetFieldCipher(cipher *FieldCipher) {
+	s.cipher = cipher
+}
+
+// sealEntry returns a copy of entry with its text sealed for storage
+func (s *MongoKnowledgeStorage) sealEntry(entry *KnowledgeEntry) (*KnowledgeEntry, error) {
+	doc := *entry
+	text, err := s.cipher.Encrypt(entry.Text)
+	if err != nil {
+		return nil, err
+	}
+	doc.Text = text
+	return &doc, nil
+}
+
+// openEntries decrypts the text of entries read from MongoDB in place
+func (s *MongoKnowledgeStorage) openEntries(entries []*KnowledgeEntry) error {
+	for _, entry := range entries {
+		text, err := s.cipher.Decrypt(entry.Text)
+		if err != nil {
+			return fmt.Errorf("knowledge entry %s: %w", entry.ID, err)
+		}
+		entry.Text = text
+	}
+	return nil
+}
+
+// RotateFieldEncryption re-seals every knowledge entry whose text is plaintext
+// or sealed with a retired key under the active key
+func (s *MongoKnowledgeStorage) RotateFieldEncryption(ctx context.Context) (*FieldRotationResult, error) {
+	if s.cipher == nil {
+		return nil, fmt.Errorf("field encryption is not configured")
+	}
+	result := &FieldRotationResult{}
+
+	cursor, err := s.knowledgeCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query knowledge entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var entry KnowledgeEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("failed to decode knowledge entry: %w", err)
+		}
+		result.Scanned++
+		stored := entry.Text
+		changed, err := s.cipher.Reseal([]*string{&entry.Text})
+		if err != nil {
+			return nil, fmt.Errorf("knowledge entry %s: %w", entry.ID, err)
+		}
+		if !changed {
+			continue
+		}
+		// Matching on the stored text skips entries rewritten during the pass
+		if err := result.record(s.knowledgeCollection.UpdateOne(ctx,
+			bson.M{"entryId": entry.ID, "text": stored}, bson.M{"$set": bson.M{"text": entry.Text}})); err != nil {
+			return nil, fmt.Errorf("failed to rotate knowledge entry %s: %w", entry.ID, err)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate knowledge entries: %w", err)
+	}
+
+	return result, nil
+}
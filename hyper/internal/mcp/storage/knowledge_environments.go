@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// KnowledgeEnvironment holds the template variables substituted into knowledge
+// entry text when it is queried for that environment (e.g. dev/staging/prod endpoints)
+type KnowledgeEnvironment struct {
+	Name      string            `bson:"_id" json:"name"`
+	Variables map[string]string `bson:"variables" json:"variables"`
+	UpdatedAt time.Time         `bson:"updatedAt" json:"updatedAt"`
+}
+
+// templateVariablePattern matches {{NAME}} placeholders, allowing inner whitespace
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// variableNamePattern is the accepted syntax for environment variable names
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateKnowledgeEnvironment checks an environment name and its variable names
+func ValidateKnowledgeEnvironment(env *KnowledgeEnvironment) error {
+	if env.Name == "" {
+		return fmt.Errorf("environment name is required")
+	}
+	for name := range env.Variables {
+		if !variableNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q: must start with a letter or underscore and contain only letters, digits and underscores", name)
+		}
+	}
+	return nil
+}
+
+// InterpolateEnvironment replaces {{NAME}} placeholders in text with the
+// environment's values. Placeholders without a value are left untouched so
+// entries stay readable when queried without (or with a partial) environment.
+func InterpolateEnvironment(text string, variables map[string]string) string {
+	if len(variables) == 0 {
+		return text
+	}
+	return templateVariablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := templateVariablePattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// KnowledgeEnvironmentStorage handles persistence of knowledge environments
+type KnowledgeEnvironmentStorage struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+// NewKnowledgeEnvironmentStorage creates a new knowledge environment storage
+func NewKnowledgeEnvironmentStorage(db *mongo.Database, logger *zap.Logger) *KnowledgeEnvironmentStorage {
+	return &KnowledgeEnvironmentStorage{
+		collection: db.Collection(CollectionName("knowledge_environments")),
+		logger:     logger,
+	}
+}
+
+// GetEnvironment returns an environment by name, or nil if it is not defined
+func (s *KnowledgeEnvironmentStorage) GetEnvironment(name string) (*KnowledgeEnvironment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var env KnowledgeEnvironment
+	err := s.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&env)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get knowledge environment %s: %w", name, err)
+	}
+	return &env, nil
+}
+
+// SetEnvironment creates or replaces an environment and its variables
+func (s *KnowledgeEnvironmentStorage) SetEnvironment(env *KnowledgeEnvironment) error {
+	if err := ValidateKnowledgeEnvironment(env); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if env.Variables == nil {
+		env.Variables = map[string]string{}
+	}
+	env.UpdatedAt = time.Now().UTC()
+
+	_, err := s.collection.ReplaceOne(ctx, bson.M{"_id": env.Name}, env, options.Replace().SetUpsert(true))
+	if err != nil {
+		s.logger.Error("Failed to save knowledge environment", zap.String("environment", env.Name), zap.Error(err))
+		return fmt.Errorf("failed to save knowledge environment %s: %w", env.Name, err)
+	}
+
+	s.logger.Info("Knowledge environment saved",
+		zap.String("environment", env.Name),
+		zap.Int("variables", len(env.Variables)))
+	return nil
+}
+
+// ListEnvironments returns all environments sorted by name
+func (s *KnowledgeEnvironmentStorage) ListEnvironments() ([]*KnowledgeEnvironment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge environments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	envs := []*KnowledgeEnvironment{}
+	if err := cursor.All(ctx, &envs); err != nil {
+		return nil, fmt.Errorf("failed to decode knowledge environments: %w", err)
+	}
+	return envs, nil
+}
+
+// DeleteEnvironment removes an environment
+func (s *KnowledgeEnvironmentStorage) DeleteEnvironment(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return fmt.Errorf("failed to delete knowledge environment %s: %w", name, err)
+	}
+	if result.DeletedCount == 0 {
+		return errcode.New(errcode.NotFound, "knowledge environment not found: %s", name)
+	}
+	return nil
+}
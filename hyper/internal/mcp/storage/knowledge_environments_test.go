@@ -0,0 +1,45 @@
+package storage
+
+import "testing"
+
+func TestInterpolateEnvironment(t *testing.T) {
+	vars := map[string]string{
+		"API_URL":    "https://staging.example.com",
+		"COLLECTION": "staging-docs",
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"single", "Call {{API_URL}}/health", "Call https://staging.example.com/health"},
+		{"whitespace", "Use {{ COLLECTION }}", "Use staging-docs"},
+		{"repeated", "{{API_URL}} and {{API_URL}}", "https://staging.example.com and https://staging.example.com"},
+		{"unknown kept", "Token {{SECRET}} at {{API_URL}}", "Token {{SECRET}} at https://staging.example.com"},
+		{"not a placeholder", "map[{{.Name}}] {{1X}}", "map[{{.Name}}] {{1X}}"},
+		{"no placeholders", "plain text", "plain text"},
+	}
+
+	for _, tt := range tests {
+		if got := InterpolateEnvironment(tt.text, vars); got != tt.want {
+			t.Errorf("%s: InterpolateEnvironment(%q) = %q, want %q", tt.name, tt.text, got, tt.want)
+		}
+	}
+
+	if got := InterpolateEnvironment("{{API_URL}}", nil); got != "{{API_URL}}" {
+		t.Errorf("nil variables changed text: %q", got)
+	}
+}
+
+func TestValidateKnowledgeEnvironment(t *testing.T) {
+	if err := ValidateKnowledgeEnvironment(&KnowledgeEnvironment{Name: "prod", Variables: map[string]string{"API_URL": "x", "_v2": "y"}}); err != nil {
+		t.Errorf("valid environment rejected: %v", err)
+	}
+	if err := ValidateKnowledgeEnvironment(&KnowledgeEnvironment{}); err == nil {
+		t.Error("expected error for missing name")
+	}
+	if err := ValidateKnowledgeEnvironment(&KnowledgeEnvironment{Name: "dev", Variables: map[string]string{"api-url": "x"}}); err == nil {
+		t.Error("expected error for invalid variable name")
+	}
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// KnowledgeEvalCase is a question and the entries a query for it must return.
+// An entry is expected by ID or by a snippet of its text, which survives
+// re-imports and re-chunking that change IDs.
+type KnowledgeEvalCase struct {
+	Question     string   `bson:"question" json:"question"`
+	ExpectedIDs  []string `bson:"expectedIds,omitempty" json:"expectedIds,omitempty"`
+	ExpectedText []string `bson:"expectedText,omitempty" json:"expectedText,omitempty"`
+}
+
+// KnowledgeEvalSuite holds the evaluation cases of one collection and the
+// scores a run must reach
+type KnowledgeEvalSuite struct {
+	Collection   string              `bson:"_id" json:"collection"`
+	K            int                 `bson:"k" json:"k"`                       // Results retrieved per question
+	MinRecall    float64             `bson:"minRecall" json:"minRecall"`       // Mean recall@k a run must reach
+	MinPrecision float64             `bson:"minPrecision" json:"minPrecision"` // Mean precision@k a run must reach
+	Cases        []KnowledgeEvalCase `bson:"cases" json:"cases"`
+	UpdatedBy    string              `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+	UpdatedAt    time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// KnowledgeEvalCaseResult is the outcome of one case in a run
+type KnowledgeEvalCaseResult struct {
+	Question  string   `bson:"question" json:"question"`
+	Recall    float64  `bson:"recall" json:"recall"`
+	Precision float64  `bson:"precision" json:"precision"`
+	Rank      int      `bson:"rank" json:"rank"`                           // Position of the first expected entry, 0 if none was returned
+	Missing   []string `bson:"missing,omitempty" json:"missing,omitempty"` // Expected IDs and texts not returned
+	Error     string   `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// KnowledgeEvalRun is the result of running a suite
+type KnowledgeEvalRun struct {
+	ID             string                    `bson:"_id" json:"id"`
+	Collection     string                    `bson:"collection" json:"collection"`
+	At             time.Time                 `bson:"at" json:"at"`
+	Trigger        string                    `bson:"trigger" json:"trigger"`         // "manual" or "config-change"
+	Fingerprint    string                    `bson:"fingerprint" json:"fingerprint"` // Embedding model and knowledge settings of the run
+	K              int                       `bson:"k" json:"k"`
+	Recall         float64                   `bson:"recall" json:"recall"`
+	Precision      float64                   `bson:"precision" json:"precision"`
+	MRR            float64                   `bson:"mrr" json:"mrr"`
+	RecallDelta    *float64                  `bson:"recallDelta,omitempty" json:"recallDelta,omitempty"` // Change since the previous run
+	PrecisionDelta *float64                  `bson:"precisionDelta,omitempty" json:"precisionDelta,omitempty"`
+	Passed         bool                      `bson:"passed" json:"passed"`
+	Failures       []string                  `bson:"failures,omitempty" json:"failures,omitempty"`
+	Cases          []KnowledgeEvalCaseResult `bson:"cases" json:"cases"`
+}
+
+// KnowledgeEvalStorage handles persistence of knowledge evaluation suites and
+// their runs
+type KnowledgeEvalStorage struct {
+	suites *mongo.Collection
+	runs   *mongo.Collection
+}
+
+// NewKnowledgeEvalStorage creates a new knowledge evaluation storage
+func NewKnowledgeEvalStorage(db *mongo.Database) *KnowledgeEvalStorage {
+	return &KnowledgeEvalStorage{
+		suites: db.Collection(CollectionName("knowledge_eval_suites")),
+		runs:   db.Collection(CollectionName("knowledge_eval_runs")),
+	}
+}
+
+// SaveKnowledgeEvalSuite creates or replaces the suite of a collection
+func (s *KnowledgeEvalStorage) SaveKnowledgeEvalSuite(suite *KnowledgeEvalSuite) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := s.suites.ReplaceOne(ctx, bson.M{"_id": suite.Collection}, suite, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save evaluation suite of %s: %w", suite.Collection, err)
+	}
+	return nil
+}
+
+// GetKnowledgeEvalSuite returns the suite of a collection, or nil if it has none
+func (s *KnowledgeEvalStorage) GetKnowledgeEvalSuite(collection string) (*KnowledgeEvalSuite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var suite KnowledgeEvalSuite
+	err := s.suites.FindOne(ctx, bson.M{"_id": collection}).Decode(&suite)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get evaluation suite of %s: %w", collection, err)
+	}
+	return &suite, nil
+}
+
+// ListKnowledgeEvalSuites returns all suites, by collection
+func (s *KnowledgeEvalStorage) ListKnowledgeEvalSuites() ([]*KnowledgeEvalSuite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.suites.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list evaluation suites: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	suites := []*KnowledgeEvalSuite{}
+	if err := cursor.All(ctx, &suites); err != nil {
+		return nil, fmt.Errorf("failed to decode evaluation suites: %w", err)
+	}
+	return suites, nil
+}
+
+// SaveKnowledgeEvalRun records a run
+func (s *KnowledgeEvalStorage) SaveKnowledgeEvalRun(run *KnowledgeEvalRun) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.runs.InsertOne(ctx, run); err != nil {
+		return fmt.Errorf("failed to save evaluation run of %s: %w", run.Collection, err)
+	}
+	return nil
+}
+
+// LatestKnowledgeEvalRun returns the newest run of a collection, or nil
+func (s *KnowledgeEvalStorage) LatestKnowledgeEvalRun(collection string) (*KnowledgeEvalRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var run KnowledgeEvalRun
+	err := s.runs.FindOne(ctx, bson.M{"collection": collection}, options.FindOne().SetSort(bson.M{"at": -1})).Decode(&run)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest evaluation run of %s: %w", collection, err)
+	}
+	return &run, nil
+}
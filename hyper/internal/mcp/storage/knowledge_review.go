@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"hyper/internal/console"
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Knowledge drafted by the coordinator waits for a human to approve it; its
+// metadata "approval" field holds one of these
+const (
+	ApprovalMetadataKey = "approval"
+	ApprovalPending     = "pending"
+	ApprovalApproved    = "approved"
+	ApprovalRejected    = "rejected"
+)
+
+// KnowledgeMetadataUpdater is implemented by knowledge storages that can
+// change the metadata of a stored entry
+type KnowledgeMetadataUpdater interface {
+	// UpdateKnowledgeMetadata sets metadata fields of the entry in collection
+	// with the given ID, if its metadata also matches every field of match.
+	// It returns the updated entry, or a "not found" error when none matches.
+	UpdateKnowledgeMetadata(ctx context.Context, collection, id string, match, set map[string]interface{}) (*KnowledgeEntry, error)
+}
+
+// pointPayloadSetter is implemented by Qdrant clients that can update a point's payload
+type pointPayloadSetter interface {
+	SetPointPayload(collectionName string, pointID string, payload map[string]interface{}) error
+}
+
+// UpdateKnowledgeMetadata sets metadata fields of a knowledge entry in MongoDB
+// and, best effort, in its Qdrant payload so filtered searches see them
+func (s *MongoKnowledgeStorage) UpdateKnowledgeMetadata(ctx context.Context, collection, id string, match, set map[string]interface{}) (*KnowledgeEntry, error) {
+	if len(set) == 0 {
+		return nil, fmt.Errorf("no metadata fields to set")
+	}
+
+	filter := bson.M{"entryId": id, "collection": collection}
+	for key, value := range match {
+		filter["metadata."+key] = value
+	}
+	update := bson.M{}
+	for key, value := range set {
+		update["metadata."+key] = value
+	}
+
+	var entry KnowledgeEntry
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := s.knowledgeCollection.FindOneAndUpdate(ctx, filter, bson.M{"$set": update}, opts).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errcode.New(errcode.NotFound, "knowledge entry not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update knowledge metadata: %w", err)
+	}
+	if err := s.openEntries([]*KnowledgeEntry{&entry}); err != nil {
+		return nil, err
+	}
+
+	if setter, ok := s.qdrantClient.(pointPayloadSetter); ok {
+		if err := setter.SetPointPayload(collection, id, set); err != nil {
+			// MongoDB has the change; searches filtering on these fields lag
+			console.Printf("Warning: failed to update Qdrant payload of %s: %v\n", id, err)
+		}
+	}
+
+	return &entry, nil
+}
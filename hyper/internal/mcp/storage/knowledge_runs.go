@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// runTaskMetadataKeys are the metadata fields agents name their task in when
+// storing knowledge
+var runTaskMetadataKeys = []string{"taskId", "humanTaskId", "agentTaskId"}
+
+// RunKnowledgeFinder is implemented by knowledge storages that can list the
+// knowledge written during a multi-agent run
+type RunKnowledgeFinder interface {
+	// KnowledgeForRun returns the entries whose metadata names one of taskIDs,
+	// or stored in a task:<id> collection, plus those one of agentNames stored
+	// (metadata agentName) between from and until; oldest first, at most limit
+	KnowledgeForRun(ctx context.Context, taskIDs, agentNames []string, from, until time.Time, limit int) ([]*KnowledgeEntry, error)
+}
+
+// KnowledgeForRun returns the knowledge entries written for a run's tasks
+func (s *MongoKnowledgeStorage) KnowledgeForRun(ctx context.Context, taskIDs, agentNames []string, from, until time.Time, limit int) ([]*KnowledgeEntry, error) {
+	var matches bson.A
+	if len(taskIDs) > 0 {
+		collections := make([]string, len(taskIDs))
+		for i, id := range taskIDs {
+			collections[i] = "task:" + id
+		}
+		matches = append(matches, bson.M{"collection": bson.M{"$in": collections}})
+		for _, key := range runTaskMetadataKeys {
+			matches = append(matches, bson.M{"metadata." + key: bson.M{"$in": taskIDs}})
+		}
+	}
+	if len(agentNames) > 0 {
+		written := bson.M{"$gte": from}
+		if !until.IsZero() {
+			written["$lte"] = until
+		}
+		matches = append(matches, bson.M{"metadata.agentName": bson.M{"$in": agentNames}, "createdAt": written})
+	}
+	if len(matches) == 0 {
+		return []*KnowledgeEntry{}, nil
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := s.knowledgeCollection.Find(ctx, bson.M{"$or": matches}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run knowledge: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := []*KnowledgeEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode run knowledge: %w", err)
+	}
+	if err := s.openEntries(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
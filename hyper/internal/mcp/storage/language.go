@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"hyper/internal/langdetect"
+)
+
+// LanguageKey is the metadata key holding the ISO 639-1 code of a knowledge
+// entry's detected language. It is derived from the text on every write and
+// absent when the language cannot be told.
+const LanguageKey = "lang"
+
+// CommentLanguageKey is the code chunk payload key holding the detected
+// language of the chunk's comments. "language" already names the
+// programming language there.
+const CommentLanguageKey = "commentLang"
+
+// LanguageKnowledgeStorage is implemented by knowledge storages that can
+// restrict a query to entries of one language
+type LanguageKnowledgeStorage interface {
+	QueryLanguage(collection, query, language string, limit int) ([]*QueryResult, error)
+}
+
+// languageSearcher is implemented by Qdrant clients that can restrict a
+// similarity search to points of one language
+type languageSearcher interface {
+	SearchSimilarInLanguage(collectionName, query, language string, limit int) ([]*QdrantQueryResult, error)
+}
+
+// tagLanguage sets LanguageKey in metadata to the language of text
+func tagLanguage(metadata map[string]interface{}, text string) {
+	if lang := langdetect.Detect(text); lang != "" {
+		metadata[LanguageKey] = lang
+	} else {
+		delete(metadata, LanguageKey)
+	}
+}
+
+// TagCommentLanguage sets CommentLanguageKey in a code chunk payload when the
+// language of the chunk's comments can be told
+func TagCommentLanguage(payload map[string]interface{}, content string) {
+	if lang := langdetect.DetectComments(content); lang != "" {
+		payload[CommentLanguageKey] = lang
+	}
+}
+
+// withLanguage returns a copy of metadata tagged with the language of text
+func withLanguage(metadata map[string]interface{}, text string) map[string]interface{} {
+	tagged := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		tagged[k] = v
+	}
+	tagLanguage(tagged, text)
+	if len(tagged) == 0 {
+		return metadata
+	}
+	return tagged
+}
+
+// SearchSimilarInLanguage searches for points similar to query among the
+// points tagged with language
+func (c *QdrantClient) SearchSimilarInLanguage(collectionName, query, language string, limit int) ([]*QdrantQueryResult, error) {
+	return c.SearchSimilarContext(context.Background(), collectionName, query, language, limit)
+}
+
+// embedQuery embeds a search query and returns the payload filter the search
+// needs. With a language router, the query is embedded by the model of its
+// language (or of the requested language) and only points embedded by that
+// same model are compared, since the two models' vectors are unrelated.
+func (c *QdrantClient) embedQuery(query, language string) ([]float64, map[string]interface{}, error) {
+	var filter map[string]interface{}
+	if language != "" {
+		filter = map[string]interface{}{
+			"must": []map[string]interface{}{
+				{"key": LanguageKey, "match": map[string]interface{}{"value": language}},
+			},
+		}
+	}
+
+	if c.languageRouter == nil {
+		vector, err := c.embeddingFunc(query)
+		return vector, filter, err
+	}
+
+	queryLanguage := language
+	if queryLanguage == "" {
+		queryLanguage = langdetect.Detect(query)
+	}
+	vector, err := c.languageRouter.ClientFor(queryLanguage).CreateEmbedding(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if filter == nil {
+		filter = modelFilter(langdetect.IsEnglish(queryLanguage))
+	}
+	return float32sTo64(vector), filter, nil
+}
+
+// modelFilter matches the points embedded by the primary model (English or
+// untagged text) or, with primary false, by the multilingual model
+func modelFilter(primary bool) map[string]interface{} {
+	english := map[string]interface{}{"key": LanguageKey, "match": map[string]interface{}{"value": langdetect.English}}
+	untagged := map[string]interface{}{"is_empty": map[string]interface{}{"key": LanguageKey}}
+	if primary {
+		return map[string]interface{}{"should": []map[string]interface{}{english, untagged}}
+	}
+	return map[string]interface{}{"must_not": []map[string]interface{}{english, untagged}}
+}
+
+// float32sTo64 widens an embedding for Qdrant requests
+func float32sTo64(vector []float32) []float64 {
+	widened := make([]float64, len(vector))
+	for i, v := range vector {
+		widened[i] = float64(v)
+	}
+	return widened
+}
+
+// QueryLanguage searches a collection like Query, returning only entries
+// written in language (an ISO 639-1 code such as "de")
+func (s *MongoKnowledgeStorage) QueryLanguage(collection, query, language string, limit int) ([]*QueryResult, error) {
+	return s.query(context.Background(), collection, query, strings.ToLower(strings.TrimSpace(language)), limit)
+}
+
+// similaritySearch returns the Qdrant search for a query in language, or nil
+// when there is no Qdrant client able to filter by it. Clients implementing
+// contextSearcher are bounded by ctx; others run to completion.
+func (s *MongoKnowledgeStorage) similaritySearch(ctx context.Context, language string) func(collection, query string, limit int) ([]*QdrantQueryResult, error) {
+	if s.qdrantClient == nil {
+		return nil
+	}
+	if searcher, ok := s.qdrantClient.(contextSearcher); ok {
+		return func(collection, query string, limit int) ([]*QdrantQueryResult, error) {
+			return searcher.SearchSimilarContext(ctx, collection, query, language, limit)
+		}
+	}
+	if language == "" {
+		return s.qdrantClient.SearchSimilar
+	}
+	searcher, ok := s.qdrantClient.(languageSearcher)
+	if !ok {
+		return nil
+	}
+	return func(collection, query string, limit int) ([]*QdrantQueryResult, error) {
+		return searcher.SearchSimilarInLanguage(collection, query, language, limit)
+	}
+}
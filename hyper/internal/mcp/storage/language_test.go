@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hyper/internal/mcp/embeddings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// constantEmbedder embeds every text as the same vector
+type constantEmbedder struct {
+	vector []float32
+}
+
+func (e *constantEmbedder) CreateEmbedding(text string) ([]float32, error) {
+	return e.vector, nil
+}
+
+func (e *constantEmbedder) CreateEmbeddings(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = e.vector
+	}
+	return vectors, nil
+}
+
+func (e *constantEmbedder) GetDimensions() int {
+	return len(e.vector)
+}
+
+func TestWithLanguage(t *testing.T) {
+	metadata := map[string]interface{}{"source": "wiki", LanguageKey: "fr"}
+
+	tagged := withLanguage(metadata, "Der Cache wird ungültig, wenn der Benutzer sich abmeldet")
+	assert.Equal(t, "de", tagged[LanguageKey], "the language is derived from the text")
+	assert.Equal(t, "wiki", tagged["source"])
+	assert.Equal(t, "fr", metadata[LanguageKey], "the caller's map is not modified")
+
+	untagged := withLanguage(metadata, "redis")
+	assert.NotContains(t, untagged, LanguageKey)
+	assert.Nil(t, withLanguage(nil, "redis"))
+
+	payload := knowledgePayload("id-1", "The session expires when the token is revoked", nil)
+	assert.Equal(t, "en", payload[LanguageKey])
+}
+
+func TestSearchSimilarFiltersByEmbeddingModel(t *testing.T) {
+	var searches []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		searches = append(searches, body)
+		w.Write([]byte(`{"result": []}`))
+	}))
+	defer server.Close()
+
+	router, err := embeddings.NewLanguageRouter(&constantEmbedder{vector: []float32{1, 0}}, &constantEmbedder{vector: []float32{0, 1}})
+	require.NoError(t, err)
+	client := NewQdrantClientWithEmbeddingClient(server.URL, "knowledge", router)
+
+	_, err = client.SearchSimilar("knowledge", "how does the session expire", 5)
+	require.NoError(t, err)
+	_, err = client.SearchSimilar("knowledge", "wie läuft die Sitzung ab, wenn das Token fehlt", 5)
+	require.NoError(t, err)
+	_, err = client.SearchSimilarInLanguage("knowledge", "session", "de", 5)
+	require.NoError(t, err)
+
+	require.Len(t, searches, 3)
+	assert.Equal(t, []interface{}{1.0, 0.0}, searches[0]["vector"])
+	assert.Contains(t, searches[0]["filter"], "should")
+	assert.Equal(t, []interface{}{0.0, 1.0}, searches[1]["vector"])
+	assert.Contains(t, searches[1]["filter"], "must_not")
+
+	// A requested language picks the model and restricts to that language
+	assert.Equal(t, []interface{}{0.0, 1.0}, searches[2]["vector"])
+	filter, _ := json.Marshal(searches[2]["filter"])
+	assert.JSONEq(t, `{"must": [{"key": "lang", "match": {"value": "de"}}]}`, string(filter))
+}
+
+func TestSearchSimilarWithoutRouterHasNoFilter(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Write([]byte(`{"result": []}`))
+	}))
+	defer server.Close()
+
+	client := NewQdrantClientWithEmbeddingClient(server.URL, "knowledge", &constantEmbedder{vector: []float32{1, 0}})
+	_, err := client.SearchSimilar("knowledge", "wie läuft die Sitzung ab, wenn das Token fehlt", 5)
+	require.NoError(t, err)
+	assert.NotContains(t, body, "filter")
+}
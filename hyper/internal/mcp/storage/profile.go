@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// CollectionPrefix is prepended to every MongoDB and Qdrant collection name so
+// several profiles (dev, prod, ...) can share one database and Qdrant instance
+// without mixing data. It is empty unless a profile is active.
+var CollectionPrefix = ""
+
+var invalidPrefixChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// InitCollectionPrefix sets CollectionPrefix from COLLECTION_PREFIX, falling
+// back to "<profile>_" when only a profile name is given. Must be called before
+// any storage is created.
+func InitCollectionPrefix(profile string) {
+	prefix := os.Getenv("COLLECTION_PREFIX")
+	if prefix == "" && profile != "" {
+		prefix = profile + "_"
+	}
+	CollectionPrefix = invalidPrefixChars.ReplaceAllString(strings.ToLower(prefix), "_")
+}
+
+// CollectionName returns the physical collection name for a logical one
+func CollectionName(name string) string {
+	return CollectionPrefix + name
+}
+
+// Workspace is where one workspace's data lives, from WORKSPACE_DATABASES
+type Workspace struct {
+	Name     string
+	Database string // MongoDB database; empty keeps MONGODB_DATABASE
+	Prefix   string // Collection prefix, after the profile's
+}
+
+// invalidDatabaseChars are the characters MongoDB rejects in database names
+const invalidDatabaseChars = `/\. "$*<>:|?`
+
+// LoadWorkspaces parses WORKSPACE_DATABASES: comma-separated
+// workspace=database entries, where the database may be followed by
+// /prefix to choose the collection prefix (default "<workspace>_") or left
+// empty to share MONGODB_DATABASE under that prefix. Qdrant has no
+// databases, so the prefix also keeps a workspace's vectors apart.
+func LoadWorkspaces() (map[string]Workspace, error) {
+	workspaces := map[string]Workspace{}
+	raw := os.Getenv("WORKSPACE_DATABASES")
+	if strings.TrimSpace(raw) == "" {
+		return workspaces, nil
+	}
+	for _, entry := range strings.Split(raw, ",") {
+		name, target, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid WORKSPACE_DATABASES entry %q: expected workspace=database[/prefix]", entry)
+		}
+		database, prefix, hasPrefix := strings.Cut(strings.TrimSpace(target), "/")
+		if strings.ContainsAny(database, invalidDatabaseChars) {
+			return nil, fmt.Errorf("invalid WORKSPACE_DATABASES entry %q: %q is not a valid database name", entry, database)
+		}
+		if !hasPrefix {
+			prefix = name + "_"
+		}
+		prefix = invalidPrefixChars.ReplaceAllString(strings.ToLower(prefix), "_")
+		if database == "" && prefix == "" {
+			return nil, fmt.Errorf("invalid WORKSPACE_DATABASES entry %q: a workspace sharing the database needs a prefix", entry)
+		}
+		if _, ok := workspaces[name]; ok {
+			return nil, fmt.Errorf("workspace %q appears twice in WORKSPACE_DATABASES", name)
+		}
+		workspaces[name] = Workspace{Name: name, Database: database, Prefix: prefix}
+	}
+	return workspaces, nil
+}
+
+// SelectWorkspace returns the MongoDB database of a workspace, database
+// being MONGODB_DATABASE, and appends its prefix to CollectionPrefix. An
+// empty name keeps both; a workspace missing from WORKSPACE_DATABASES is an
+// error, so a typo cannot mix its data into another workspace. Must be
+// called after InitCollectionPrefix and before any storage is created.
+func SelectWorkspace(name, database string) (string, error) {
+	if name == "" {
+		return database, nil
+	}
+	workspaces, err := LoadWorkspaces()
+	if err != nil {
+		return "", err
+	}
+	workspace, ok := workspaces[name]
+	if !ok {
+		return "", fmt.Errorf("workspace %q is not configured in WORKSPACE_DATABASES", name)
+	}
+	if workspace.Database != "" {
+		database = workspace.Database
+	}
+	CollectionPrefix += workspace.Prefix
+	return database, nil
+}
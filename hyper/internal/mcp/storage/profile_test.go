@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitCollectionPrefix(t *testing.T) {
+	defer func() { CollectionPrefix = "" }()
+
+	tests := []struct {
+		name    string
+		profile string
+		env     string
+		want    string
+	}{
+		{name: "no profile", want: ""},
+		{name: "profile", profile: "dev", want: "dev_"},
+		{name: "explicit prefix wins", profile: "dev", env: "staging_", want: "staging_"},
+		{name: "sanitized", profile: "Prod EU", want: "prod_eu_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COLLECTION_PREFIX", tt.env)
+			InitCollectionPrefix(tt.profile)
+			assert.Equal(t, tt.want, CollectionPrefix)
+			assert.Equal(t, tt.want+"human_tasks", CollectionName("human_tasks"))
+		})
+	}
+}
+
+func TestCollectionURLAppliesPrefix(t *testing.T) {
+	defer func() { CollectionPrefix = "" }()
+	client := &QdrantClient{baseURL: "http://qdrant:6333"}
+
+	assert.Equal(t, "http://qdrant:6333/collections/code_index", client.collectionURL("code_index"))
+
+	CollectionPrefix = "dev_"
+	assert.Equal(t, "http://qdrant:6333/collections/dev_code_index", client.collectionURL("code_index"))
+}
+
+func TestSelectWorkspace(t *testing.T) {
+	defer func() { CollectionPrefix = "" }()
+	t.Setenv("WORKSPACE_DATABASES", "acme=hyper_acme, globex=hyper_globex/gx_, beta=")
+
+	tests := []struct {
+		name         string
+		workspace    string
+		wantDatabase string
+		wantPrefix   string
+		wantErr      string
+	}{
+		{name: "no workspace", wantDatabase: "coordinator_db", wantPrefix: "dev_"},
+		{name: "own database", workspace: "acme", wantDatabase: "hyper_acme", wantPrefix: "dev_acme_"},
+		{name: "explicit prefix", workspace: "globex", wantDatabase: "hyper_globex", wantPrefix: "dev_gx_"},
+		{name: "shared database", workspace: "beta", wantDatabase: "coordinator_db", wantPrefix: "dev_beta_"},
+		{name: "unknown workspace", workspace: "initech", wantErr: "not configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			CollectionPrefix = "dev_"
+			database, err := SelectWorkspace(tt.workspace, "coordinator_db")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDatabase, database)
+			assert.Equal(t, tt.wantPrefix, CollectionPrefix)
+		})
+	}
+}
+
+func TestLoadWorkspaces_Invalid(t *testing.T) {
+	for _, raw := range []string{"acme", "=hyper_acme", "acme=hyper.acme", "acme=/", "acme=a,acme=b"} {
+		t.Setenv("WORKSPACE_DATABASES", raw)
+		_, err := LoadWorkspaces()
+		assert.Error(t, err, raw)
+	}
+}
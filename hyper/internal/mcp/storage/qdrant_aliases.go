@@ -0,0 +1,357 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"hyper/internal/errcode"
+)
+
+// CollectionAlias points a stable collection name, which readers and writers
+// use, at a versioned physical collection
+type CollectionAlias struct {
+	Alias      string `json:"alias"`
+	Collection string `json:"collection"`
+}
+
+// AliasMigration reports a collection alias switched to a new collection
+type AliasMigration struct {
+	Alias         string `json:"alias"`
+	Collection    string `json:"collection"`              // Collection the alias now points to
+	Previous      string `json:"previous,omitempty"`      // Collection it pointed to before, kept for rollback
+	ReplacedPlain bool   `json:"replacedPlain,omitempty"` // A plain collection named like the alias was deleted
+}
+
+// VersionedCollectionName returns the physical collection name of version n
+// of an alias, such as code_index_v3
+func VersionedCollectionName(alias string, version int) string {
+	return fmt.Sprintf("%s_v%d", alias, version)
+}
+
+// collectionVersion returns the version of a collection named by
+// VersionedCollectionName for alias, or 0
+func collectionVersion(alias, collection string) int {
+	suffix, ok := strings.CutPrefix(collection, alias+"_v")
+	if !ok {
+		return 0
+	}
+	version, err := strconv.Atoi(suffix)
+	if err != nil || version <= 0 {
+		return 0
+	}
+	return version
+}
+
+// qdrantJSON sends a JSON request to Qdrant and decodes the response into out
+// (when non-nil)
+func (c *QdrantClient) qdrantJSON(ctx context.Context, method, url string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.addAuthHeader(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ListCollectionAliases returns the aliases of this profile's collections,
+// by logical name
+func (c *QdrantClient) ListCollectionAliases(ctx context.Context) ([]CollectionAlias, error) {
+	var response struct {
+		Result struct {
+			Aliases []struct {
+				AliasName      string `json:"alias_name"`
+				CollectionName string `json:"collection_name"`
+			} `json:"aliases"`
+		} `json:"result"`
+	}
+	if err := c.qdrantJSON(ctx, http.MethodGet, c.baseURL+"/aliases", nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list collection aliases: %w", err)
+	}
+
+	aliases := make([]CollectionAlias, 0, len(response.Result.Aliases))
+	for _, alias := range response.Result.Aliases {
+		name, ok := strings.CutPrefix(alias.AliasName, CollectionPrefix)
+		collection, inProfile := strings.CutPrefix(alias.CollectionName, CollectionPrefix)
+		if !ok || !inProfile {
+			continue
+		}
+		aliases = append(aliases, CollectionAlias{Alias: name, Collection: collection})
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Alias < aliases[j].Alias })
+	return aliases, nil
+}
+
+// listCollections returns the logical names of this profile's collections
+func (c *QdrantClient) listCollections(ctx context.Context) ([]string, error) {
+	var response struct {
+		Result struct {
+			Collections []struct {
+				Name string `json:"name"`
+			} `json:"collections"`
+		} `json:"result"`
+	}
+	if err := c.qdrantJSON(ctx, http.MethodGet, c.baseURL+"/collections", nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	var names []string
+	for _, collection := range response.Result.Collections {
+		if name, ok := strings.CutPrefix(collection.Name, CollectionPrefix); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CollectionVersions returns the versioned collections of alias, oldest first
+func (c *QdrantClient) CollectionVersions(ctx context.Context, alias string) ([]string, error) {
+	collections, err := c.listCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, collection := range collections {
+		if collectionVersion(alias, collection) > 0 {
+			versions = append(versions, collection)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return collectionVersion(alias, versions[i]) < collectionVersion(alias, versions[j])
+	})
+	return versions, nil
+}
+
+// aliasTarget returns the collection alias points to, or "" when it is not
+// an alias
+func (c *QdrantClient) aliasTarget(ctx context.Context, alias string) (string, error) {
+	aliases, err := c.ListCollectionAliases(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, existing := range aliases {
+		if existing.Alias == alias {
+			return existing.Collection, nil
+		}
+	}
+	return "", nil
+}
+
+// collectionExists reports whether a collection (not an alias) exists
+func (c *QdrantClient) collectionExists(ctx context.Context, name string) (bool, error) {
+	collections, err := c.listCollections(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, collection := range collections {
+		if collection == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// createCollection creates a cosine-distance collection, with named code and
+// summary vectors when named is set
+func (c *QdrantClient) createCollection(ctx context.Context, name string, vectorSize int, named bool) error {
+	config := map[string]interface{}{
+		"vectors": map[string]interface{}{
+			"size":     vectorSize,
+			"distance": "Cosine",
+		},
+	}
+	if named {
+		config["vectors"] = codeIndexVectorsConfig(vectorSize)
+	}
+	if err := c.qdrantJSON(ctx, http.MethodPut, c.collectionURL(name), config, nil); err != nil {
+		return fmt.Errorf("failed to create collection %s: %w", name, err)
+	}
+	return nil
+}
+
+// SwitchCollectionAlias points alias at collection in one atomic Qdrant
+// operation, so searches never see a missing collection. It returns the
+// collection the alias pointed to before ("" if it was new).
+func (c *QdrantClient) SwitchCollectionAlias(ctx context.Context, alias, collection string) (string, error) {
+	exists, err := c.collectionExists(ctx, collection)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", errcode.New(errcode.NotFound, "collection %s does not exist", collection)
+	}
+	previous, err := c.aliasTarget(ctx, alias)
+	if err != nil {
+		return "", err
+	}
+
+	var actions []map[string]interface{}
+	if previous != "" {
+		actions = append(actions, map[string]interface{}{
+			"delete_alias": map[string]interface{}{"alias_name": CollectionName(alias)},
+		})
+	}
+	actions = append(actions, map[string]interface{}{
+		"create_alias": map[string]interface{}{
+			"collection_name": CollectionName(collection),
+			"alias_name":      CollectionName(alias),
+		},
+	})
+	if err := c.qdrantJSON(ctx, http.MethodPost, c.baseURL+"/collections/aliases", map[string]interface{}{"actions": actions}, nil); err != nil {
+		return "", fmt.Errorf("failed to switch alias %s to %s: %w", alias, collection, err)
+	}
+	c.forgetVectorLayout(alias)
+	return previous, nil
+}
+
+// MigrateCollection creates the next version of alias with vectorSize
+// dimensions (alias_v1, alias_v2, ...) and switches the alias to it. The
+// previous version is kept, so rolling back is an alias switch. Versions of
+// code index collections are created with named code and summary vectors.
+func (c *QdrantClient) MigrateCollection(ctx context.Context, alias string, vectorSize int) (*AliasMigration, error) {
+	collection, err := c.createNextVersion(ctx, alias, vectorSize)
+	if err != nil {
+		return nil, err
+	}
+	return c.PromoteCollectionVersion(ctx, alias, collection)
+}
+
+// PrepareCollectionVersion returns a version of alias with vectorSize
+// dimensions that the alias does not point to yet, to be filled before
+// PromoteCollectionVersion switches to it. The newest version is reused when
+// a previous preparation left it behind (resumed is then true); otherwise
+// the next version is created.
+func (c *QdrantClient) PrepareCollectionVersion(ctx context.Context, alias string, vectorSize int) (collection string, resumed bool, err error) {
+	versions, err := c.CollectionVersions(ctx, alias)
+	if err != nil {
+		return "", false, err
+	}
+	if len(versions) > 0 {
+		newest := versions[len(versions)-1]
+		target, err := c.aliasTarget(ctx, alias)
+		if err != nil {
+			return "", false, err
+		}
+		if newest != target {
+			size, _, err := c.CollectionVectorSize(ctx, newest)
+			if err != nil {
+				return "", false, err
+			}
+			if size == vectorSize {
+				return newest, true, nil
+			}
+		}
+	}
+	collection, err = c.createNextVersion(ctx, alias, vectorSize)
+	return collection, false, err
+}
+
+// createNextVersion creates the version of alias after its newest one
+func (c *QdrantClient) createNextVersion(ctx context.Context, alias string, vectorSize int) (string, error) {
+	versions, err := c.CollectionVersions(ctx, alias)
+	if err != nil {
+		return "", err
+	}
+	next := 1
+	if len(versions) > 0 {
+		next = collectionVersion(alias, versions[len(versions)-1]) + 1
+	}
+	collection := VersionedCollectionName(alias, next)
+	if err := c.createCollection(ctx, collection, vectorSize, isCodeIndexCollection(alias)); err != nil {
+		return "", err
+	}
+	return collection, nil
+}
+
+// PromoteCollectionVersion switches alias to collection. A plain collection
+// named alias, created before aliases were used, cannot coexist with the
+// alias and is deleted.
+func (c *QdrantClient) PromoteCollectionVersion(ctx context.Context, alias, collection string) (*AliasMigration, error) {
+	migration := &AliasMigration{Alias: alias, Collection: collection}
+	target, err := c.aliasTarget(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	if target == "" {
+		plain, err := c.collectionExists(ctx, alias)
+		if err != nil {
+			return nil, err
+		}
+		if plain {
+			if err := c.DeleteCollection(alias); err != nil {
+				return nil, err
+			}
+			migration.ReplacedPlain = true
+		}
+	}
+
+	if migration.Previous, err = c.SwitchCollectionAlias(ctx, alias, collection); err != nil {
+		return nil, err
+	}
+	return migration, nil
+}
+
+// RollbackCollectionAlias switches alias back to the newest version older
+// than the one it points to
+func (c *QdrantClient) RollbackCollectionAlias(ctx context.Context, alias string) (*AliasMigration, error) {
+	current, err := c.aliasTarget(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	currentVersion := collectionVersion(alias, current)
+	if currentVersion == 0 {
+		return nil, fmt.Errorf("%s is not an alias of a versioned collection: name the collection to switch to", alias)
+	}
+
+	versions, err := c.CollectionVersions(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	target := ""
+	for _, version := range versions {
+		if collectionVersion(alias, version) < currentVersion {
+			target = version
+		}
+	}
+	if target == "" {
+		return nil, fmt.Errorf("no version of %s older than %s to roll back to", alias, current)
+	}
+
+	previous, err := c.SwitchCollectionAlias(ctx, alias, target)
+	if err != nil {
+		return nil, err
+	}
+	return &AliasMigration{Alias: alias, Collection: target, Previous: previous}, nil
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQdrant implements the collection and alias endpoints of Qdrant
+type fakeQdrant struct {
+	mu          sync.Mutex
+	collections map[string]int    // name -> vector size
+	aliases     map[string]string // alias -> collection
+}
+
+func newFakeQdrant(t *testing.T) (*fakeQdrant, *QdrantClient) {
+	fake := &fakeQdrant{collections: map[string]int{}, aliases: map[string]string{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, NewQdrantClientWithEmbedding(server.URL, mockEmbeddingFuncFactory(2), 2)
+}
+
+func (f *fakeQdrant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/collections":
+		var collections []map[string]string
+		for name := range f.collections {
+			collections = append(collections, map[string]string{"name": name})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"collections": collections}})
+	case r.Method == http.MethodGet && r.URL.Path == "/aliases":
+		var aliases []map[string]string
+		for alias, collection := range f.aliases {
+			aliases = append(aliases, map[string]string{"alias_name": alias, "collection_name": collection})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"aliases": aliases}})
+	case r.Method == http.MethodPost && r.URL.Path == "/collections/aliases":
+		var body struct {
+			Actions []struct {
+				Create *struct {
+					Collection string `json:"collection_name"`
+					Alias      string `json:"alias_name"`
+				} `json:"create_alias"`
+				Delete *struct {
+					Alias string `json:"alias_name"`
+				} `json:"delete_alias"`
+			} `json:"actions"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, action := range body.Actions {
+			if action.Delete != nil {
+				delete(f.aliases, action.Delete.Alias)
+			}
+			if action.Create != nil {
+				if _, clash := f.collections[action.Create.Alias]; clash {
+					http.Error(w, "alias clashes with a collection", http.StatusConflict)
+					return
+				}
+				f.aliases[action.Create.Alias] = action.Create.Collection
+			}
+		}
+		w.Write([]byte(`{"result":true}`))
+	case strings.HasPrefix(r.URL.Path, "/collections/"):
+		name := strings.TrimPrefix(r.URL.Path, "/collections/")
+		switch r.Method {
+		case http.MethodGet:
+			size, ok := f.collections[name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{
+				"config": map[string]interface{}{"params": map[string]interface{}{"vectors": codeIndexVectorsConfig(size)}},
+			}})
+			return
+		case http.MethodPut:
+			var body struct {
+				Vectors json.RawMessage `json:"vectors"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			f.collections[name], _ = parseVectorParams(body.Vectors)
+		case http.MethodDelete:
+			delete(f.collections, name)
+		}
+		w.Write([]byte(`{"result":true}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestMigrateCollectionSwitchesAlias(t *testing.T) {
+	fake, client := newFakeQdrant(t)
+	ctx := t.Context()
+
+	migration, err := client.MigrateCollection(ctx, "code_index", 768)
+	require.NoError(t, err)
+	assert.Equal(t, &AliasMigration{Alias: "code_index", Collection: "code_index_v1"}, migration)
+
+	migration, err = client.MigrateCollection(ctx, "code_index", 1024)
+	require.NoError(t, err)
+	assert.Equal(t, "code_index_v2", migration.Collection)
+	assert.Equal(t, "code_index_v1", migration.Previous)
+	assert.Equal(t, map[string]int{"code_index_v1": 768, "code_index_v2": 1024}, fake.collections)
+	assert.Equal(t, map[string]string{"code_index": "code_index_v2"}, fake.aliases)
+
+	versions, err := client.CollectionVersions(ctx, "code_index")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"code_index_v1", "code_index_v2"}, versions)
+
+	rollback, err := client.RollbackCollectionAlias(ctx, "code_index")
+	require.NoError(t, err)
+	assert.Equal(t, &AliasMigration{Alias: "code_index", Collection: "code_index_v1", Previous: "code_index_v2"}, rollback)
+
+	_, err = client.RollbackCollectionAlias(ctx, "code_index")
+	assert.ErrorContains(t, err, "no version")
+
+	previous, err := client.SwitchCollectionAlias(ctx, "code_index", "code_index_v2")
+	require.NoError(t, err)
+	assert.Equal(t, "code_index_v1", previous)
+	_, err = client.SwitchCollectionAlias(ctx, "code_index", "code_index_v9")
+	assert.ErrorContains(t, err, "does not exist")
+}
+
+func TestMigrateCollectionReplacesPlainCollection(t *testing.T) {
+	fake, client := newFakeQdrant(t)
+	fake.collections["code_index"] = 768
+
+	migration, err := client.MigrateCollection(t.Context(), "code_index", 1024)
+	require.NoError(t, err)
+	assert.True(t, migration.ReplacedPlain)
+	assert.Empty(t, migration.Previous)
+	assert.Equal(t, map[string]int{"code_index_v1": 1024}, fake.collections)
+	assert.Equal(t, map[string]string{"code_index": "code_index_v1"}, fake.aliases)
+
+	aliases, err := client.ListCollectionAliases(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []CollectionAlias{{Alias: "code_index", Collection: "code_index_v1"}}, aliases)
+}
+
+func TestPrepareCollectionVersion(t *testing.T) {
+	fake, client := newFakeQdrant(t)
+	ctx := t.Context()
+	_, err := client.MigrateCollection(ctx, "code_index", 768)
+	require.NoError(t, err)
+
+	collection, resumed, err := client.PrepareCollectionVersion(ctx, "code_index", 1024)
+	require.NoError(t, err)
+	assert.Equal(t, "code_index_v2", collection)
+	assert.False(t, resumed)
+	assert.Equal(t, "code_index_v1", fake.aliases["code_index"], "the alias moves only on promotion")
+
+	// An interrupted preparation is picked up again
+	collection, resumed, err = client.PrepareCollectionVersion(ctx, "code_index", 1024)
+	require.NoError(t, err)
+	assert.Equal(t, "code_index_v2", collection)
+	assert.True(t, resumed)
+
+	// ...unless the target dimensions changed again
+	collection, resumed, err = client.PrepareCollectionVersion(ctx, "code_index", 384)
+	require.NoError(t, err)
+	assert.Equal(t, "code_index_v3", collection)
+	assert.False(t, resumed)
+
+	migration, err := client.PromoteCollectionVersion(ctx, "code_index", "code_index_v3")
+	require.NoError(t, err)
+	assert.Equal(t, &AliasMigration{Alias: "code_index", Collection: "code_index_v3", Previous: "code_index_v1"}, migration)
+}
+
+func TestCollectionVersion(t *testing.T) {
+	assert.Equal(t, 3, collectionVersion("code_index", "code_index_v3"))
+	assert.Zero(t, collectionVersion("code_index", "code_index"))
+	assert.Zero(t, collectionVersion("code_index", "code_index_vx"))
+	assert.Zero(t, collectionVersion("code", "code_index_v3"))
+}
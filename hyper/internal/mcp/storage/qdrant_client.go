@@ -12,9 +12,11 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"hyper/internal/mcp/embeddings"
+	"hyper/internal/priority"
 
 	"github.com/google/uuid"
 )
@@ -33,10 +35,14 @@ type QdrantClient struct {
 	baseURL                  string
 	httpClient               *http.Client
 	embeddingFunc            func(string) ([]float64, error)
+	batchEmbeddingFunc       func([]string) ([][]float64, error) // Optional; embeds many texts per request
 	qdrantAPIKey             string
 	teiClient                *embeddings.TEIClient
 	vectorDimension          int
 	knowledgeCollectionName  string // Configurable knowledge collection name
+	languageRouter           *embeddings.LanguageRouter // Set when non-English text has its own model
+	vectorLayouts            sync.Map                   // Collection -> whether it uses named vectors
+	textIndexes              sync.Map                   // Collections whose content has a full-text index
 }
 
 // QdrantPoint represents a point to store in Qdrant
@@ -108,6 +114,7 @@ func NewQdrantClientWithEmbedding(baseURL string, embeddingFunc func(string) ([]
 
 // NewQdrantClientWithEmbeddingClient creates a Qdrant client using an external embedding client
 // This allows using the same embedding client (Ollama, OpenAI, Voyage, etc.) for both code indexing and knowledge/tools storage
+// Custom clients only need to implement embedding.Client (hyper/pkg/embedding)
 func NewQdrantClientWithEmbeddingClient(baseURL string, knowledgeCollectionName string, embeddingClient embeddings.EmbeddingClient) *QdrantClient {
 	qdrantKey := os.Getenv("QDRANT_API_KEY")
 
@@ -141,6 +148,22 @@ func NewQdrantClientWithEmbeddingClient(baseURL string, knowledgeCollectionName
 		}
 		return embedding64, nil
 	}
+	client.batchEmbeddingFunc = func(texts []string) ([][]float64, error) {
+		embeddings32, err := embeddingClient.CreateEmbeddings(texts)
+		if err != nil {
+			return nil, err
+		}
+
+		vectors := make([][]float64, len(embeddings32))
+		for i, embedding32 := range embeddings32 {
+			vectors[i] = make([]float64, len(embedding32))
+			for j, v := range embedding32 {
+				vectors[i][j] = float64(v)
+			}
+		}
+		return vectors, nil
+	}
+	client.languageRouter, _ = embeddingClient.(*embeddings.LanguageRouter)
 
 	return client
 }
@@ -152,10 +175,16 @@ func (c *QdrantClient) addAuthHeader(req *http.Request) {
 	}
 }
 
+// collectionURL returns the REST URL of a collection. Callers pass logical
+// names; the active profile's CollectionPrefix is applied here only.
+func (c *QdrantClient) collectionURL(collectionName string) string {
+	return fmt.Sprintf("%s/collections/%s", c.baseURL, CollectionName(collectionName))
+}
+
 // EnsureCollection ensures a Qdrant collection exists
 func (c *QdrantClient) EnsureCollection(collectionName string, vectorSize int) error {
 	// Check if collection exists
-	checkURL := fmt.Sprintf("%s/collections/%s", c.baseURL, collectionName)
+	checkURL := c.collectionURL(collectionName)
 	req, err := http.NewRequest("GET", checkURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create check request: %w", err)
@@ -186,7 +215,7 @@ func (c *QdrantClient) EnsureCollection(collectionName string, vectorSize int) e
 		return fmt.Errorf("failed to marshal create payload: %w", err)
 	}
 
-	createURL := fmt.Sprintf("%s/collections/%s", c.baseURL, collectionName)
+	createURL := c.collectionURL(collectionName)
 	req, err = http.NewRequest("PUT", createURL, bytes.NewReader(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -216,6 +245,85 @@ func (c *QdrantClient) StorePoint(collectionName string, id string, text string,
 		return fmt.Errorf("failed to generate embedding: %w", err)
 	}
 
+	return c.upsertKnowledgePoints(collectionName, []QdrantPoint{{
+		ID:      id,
+		Vector:  vector,
+		Payload: knowledgePayload(id, text, metadata),
+	}})
+}
+
+// KnowledgePoint is a knowledge entry to embed and store with StorePoints
+type KnowledgePoint struct {
+	ID       string
+	Text     string
+	Metadata map[string]interface{}
+	Vector   []float64 // Optional precomputed embedding, e.g. from an export
+}
+
+// StorePoints embeds and stores many knowledge points with one embedding
+// request (when the embedding client supports batches) and one upsert.
+// Points whose precomputed Vector has the client's dimension are stored as-is
+// without re-embedding; other vectors are discarded and the text is embedded.
+func (c *QdrantClient) StorePoints(collectionName string, points []KnowledgePoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	vectors := make([][]float64, len(points))
+	var texts []string
+	var toEmbed []int
+	for i, point := range points {
+		if len(point.Vector) > 0 && len(point.Vector) == c.vectorDimension {
+			vectors[i] = point.Vector
+			continue
+		}
+		texts = append(texts, point.Text)
+		toEmbed = append(toEmbed, i)
+	}
+
+	if len(texts) > 0 {
+		embedded, err := c.embedTexts(texts)
+		if err != nil {
+			return fmt.Errorf("failed to generate embeddings: %w", err)
+		}
+		if len(embedded) != len(texts) {
+			return fmt.Errorf("failed to generate embeddings: got %d vectors for %d texts", len(embedded), len(texts))
+		}
+		for j, i := range toEmbed {
+			vectors[i] = embedded[j]
+		}
+	}
+
+	qdrantPoints := make([]QdrantPoint, len(points))
+	for i, point := range points {
+		qdrantPoints[i] = QdrantPoint{
+			ID:      point.ID,
+			Vector:  vectors[i],
+			Payload: knowledgePayload(point.ID, point.Text, point.Metadata),
+		}
+	}
+	return c.upsertKnowledgePoints(collectionName, qdrantPoints)
+}
+
+// embedTexts embeds texts in one request when possible, otherwise one at a time
+func (c *QdrantClient) embedTexts(texts []string) ([][]float64, error) {
+	if c.batchEmbeddingFunc != nil {
+		return c.batchEmbeddingFunc(texts)
+	}
+
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vector, err := c.embeddingFunc(text)
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// knowledgePayload builds the Qdrant payload of a knowledge point
+func knowledgePayload(id, text string, metadata map[string]interface{}) map[string]interface{} {
 	// Create payload with text and metadata
 	payload := make(map[string]interface{})
 	payload["text"] = text
@@ -228,17 +336,14 @@ func (c *QdrantClient) StorePoint(collectionName string, id string, text string,
 			payload[k] = v
 		}
 	}
+	tagLanguage(payload, text)
+	return payload
+}
 
-	// Create point
-	point := QdrantPoint{
-		ID:      id,
-		Vector:  vector,
-		Payload: payload,
-	}
-
-	// Upsert point
+// upsertKnowledgePoints upserts embedded knowledge points into a collection
+func (c *QdrantClient) upsertKnowledgePoints(collectionName string, points []QdrantPoint) error {
 	upsertPayload := map[string]interface{}{
-		"points": []QdrantPoint{point},
+		"points": points,
 	}
 
 	payloadBytes, err := json.Marshal(upsertPayload)
@@ -246,7 +351,7 @@ func (c *QdrantClient) StorePoint(collectionName string, id string, text string,
 		return fmt.Errorf("failed to marshal upsert payload: %w", err)
 	}
 
-	upsertURL := fmt.Sprintf("%s/collections/%s/points", c.baseURL, collectionName)
+	upsertURL := c.collectionURL(collectionName) + "/points"
 	req, err := http.NewRequest("PUT", upsertURL, bytes.NewReader(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -268,13 +373,154 @@ func (c *QdrantClient) StorePoint(collectionName string, id string, text string,
 	return nil
 }
 
+// PointVectors returns the stored vectors of knowledge points by ID. Points
+// missing from the collection, or a missing collection, are omitted from the
+// result.
+func (c *QdrantClient) PointVectors(collectionName string, ids []string) (map[string][]float64, error) {
+	vectors := make(map[string][]float64, len(ids))
+	if len(ids) == 0 {
+		return vectors, nil
+	}
+
+	payloadBytes, err := json.Marshal(map[string]interface{}{
+		"ids":          ids,
+		"with_payload": false,
+		"with_vector":  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal retrieve payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.collectionURL(collectionName)+"/points", bytes.NewReader(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.addAuthHeader(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve points: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// A missing collection has no points, e.g. one whose entries were never
+	// stored in Qdrant
+	if resp.StatusCode == http.StatusNotFound {
+		return vectors, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to retrieve points: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var retrieveResp struct {
+		Result []struct {
+			ID     interface{} `json:"id"`
+			Vector []float64   `json:"vector"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&retrieveResp); err != nil {
+		return nil, fmt.Errorf("failed to decode retrieve response: %w", err)
+	}
+
+	for _, point := range retrieveResp.Result {
+		if len(point.Vector) > 0 {
+			vectors[fmt.Sprint(point.ID)] = point.Vector
+		}
+	}
+	return vectors, nil
+}
+
+// ScanPayloads visits the ID and payload of every point in a collection,
+// paging through it with the scroll API. A missing collection has no points.
+func (c *QdrantClient) ScanPayloads(collectionName string, visit func(id string, payload map[string]interface{}) error) error {
+	var offset interface{}
+	for {
+		body := map[string]interface{}{
+			"limit":        256,
+			"with_payload": true,
+			"with_vector":  false,
+		}
+		if offset != nil {
+			body["offset"] = offset
+		}
+		payloadBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal scroll payload: %w", err)
+		}
+
+		req, err := http.NewRequest("POST", c.collectionURL(collectionName)+"/points/scroll", bytes.NewReader(payloadBytes))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		c.addAuthHeader(req)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to scroll points: %w", err)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			respBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return fmt.Errorf("failed to scroll points: status %d, body: %s", resp.StatusCode, string(respBody))
+		}
+
+		var scrollResp struct {
+			Result struct {
+				Points []struct {
+					ID      interface{}            `json:"id"`
+					Payload map[string]interface{} `json:"payload"`
+				} `json:"points"`
+				NextPageOffset interface{} `json:"next_page_offset"`
+			} `json:"result"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&scrollResp)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode scroll response: %w", err)
+		}
+
+		for _, point := range scrollResp.Result.Points {
+			if err := visit(fmt.Sprint(point.ID), point.Payload); err != nil {
+				return err
+			}
+		}
+		if scrollResp.Result.NextPageOffset == nil {
+			return nil
+		}
+		offset = scrollResp.Result.NextPageOffset
+	}
+}
+
 // SearchSimilar searches for similar points in Qdrant
 func (c *QdrantClient) SearchSimilar(collectionName string, query string, limit int) ([]*QdrantQueryResult, error) {
+	return c.SearchSimilarContext(context.Background(), collectionName, query, "", limit)
+}
+
+// SearchSimilarContext searches for points similar to query, restricted to
+// points tagged with language when it is set. The search is bounded by ctx's
+// deadline and admitted at ctx's priority.
+func (c *QdrantClient) SearchSimilarContext(ctx context.Context, collectionName, query, language string, limit int) ([]*QdrantQueryResult, error) {
+	done, err := priority.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
 	// Generate query embedding using configured function
-	queryVector, err := c.embeddingFunc(query)
+	queryVector, filter, err := c.embedQuery(query, language)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Create search request
 	searchPayload := map[string]interface{}{
@@ -282,14 +528,17 @@ func (c *QdrantClient) SearchSimilar(collectionName string, query string, limit
 		"limit":  limit,
 		"with_payload": true,
 	}
+	if filter != nil {
+		searchPayload["filter"] = filter
+	}
 
 	payloadBytes, err := json.Marshal(searchPayload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal search payload: %w", err)
 	}
 
-	searchURL := fmt.Sprintf("%s/collections/%s/points/search", c.baseURL, collectionName)
-	req, err := http.NewRequest("POST", searchURL, bytes.NewReader(payloadBytes))
+	searchURL := c.collectionURL(collectionName) + "/points/search"
+	req, err := http.NewRequestWithContext(ctx, "POST", searchURL, bytes.NewReader(payloadBytes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -347,7 +596,7 @@ func (c *QdrantClient) DeletePoint(collectionName string, pointID string) error
 		return fmt.Errorf("failed to marshal delete request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/collections/%s/points/delete?wait=true", c.baseURL, collectionName)
+	url := c.collectionURL(collectionName) + "/points/delete?wait=true"
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -473,6 +722,9 @@ func (c *QdrantClient) Ping(ctx context.Context) error {
 const (
 	DefaultCodeIndexCollection = "code_index"
 	CodeIndexVectorSize        = 768 // TEI nomic-embed-text-v1.5 dimension (default - may be overridden)
+
+	// RecentChangesCollection holds embedded line diffs of recent file modifications
+	RecentChangesCollection = "code_recent_changes"
 )
 
 var (
@@ -504,16 +756,23 @@ type CodeIndexPoint struct {
 	ID      string                 `json:"id"`
 	Vector  []float32              `json:"vector"`
 	Payload map[string]interface{} `json:"payload"`
+
+	// SummaryVector is the embedding of the chunk's summary, stored as the
+	// summary vector of collections with named vectors
+	SummaryVector []float32 `json:"-"`
 }
 
 // CodeIndexSearchResponse represents a search response for code indexing
 type CodeIndexSearchResponse struct {
-	Result []struct {
-		ID      string                 `json:"id"`
-		Score   float32                `json:"score"`
-		Payload map[string]interface{} `json:"payload"`
-		Vector  []float32              `json:"vector,omitempty"`
-	} `json:"result"`
+	Result []CodeIndexHit `json:"result"`
+}
+
+// CodeIndexHit is one point returned by a code index search
+type CodeIndexHit struct {
+	ID      string                 `json:"id"`
+	Score   float32                `json:"score"`
+	Payload map[string]interface{} `json:"payload"`
+	Vector  []float32              `json:"vector,omitempty"`
 }
 
 // parseDimensionMismatchError parses a Qdrant error response for dimension mismatch
@@ -554,76 +813,88 @@ func parseDimensionMismatchError(statusCode int, body string, collection string)
 	}
 }
 
-// DeleteCollection deletes a Qdrant collection
-func (c *QdrantClient) DeleteCollection(collectionName string) error {
-	url := fmt.Sprintf("%s/collections/%s", c.baseURL, collectionName)
-	req, err := http.NewRequest("DELETE", url, nil)
+// KnowledgeCollectionName returns the name of the default knowledge collection
+func (c *QdrantClient) KnowledgeCollectionName() string {
+	return c.knowledgeCollectionName
+}
+
+// CollectionVectorSize returns the vector size configured for a collection.
+// exists is false when the collection has not been created yet.
+func (c *QdrantClient) CollectionVectorSize(ctx context.Context, collectionName string) (size int, exists bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.collectionURL(collectionName), nil)
 	if err != nil {
-		return fmt.Errorf("failed to create delete request: %w", err)
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
 	}
 	c.addAuthHeader(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to delete collection: %w", err)
+		return 0, false, fmt.Errorf("failed to get collection info: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to delete collection (status %d): %s", resp.StatusCode, string(body))
+		return 0, false, fmt.Errorf("failed to get collection info (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	return nil
-}
-
-// RecreateCodeIndexCollection deletes and recreates the code index collection with new dimensions
-func (c *QdrantClient) RecreateCodeIndexCollection(vectorSize int) error {
-	// Delete existing collection
-	if err := c.DeleteCollection(CodeIndexCollection); err != nil {
-		return fmt.Errorf("failed to delete collection: %w", err)
+	var collectionInfo struct {
+		Result struct {
+			Config struct {
+				Params struct {
+					Vectors json.RawMessage `json:"vectors"`
+				} `json:"params"`
+			} `json:"config"`
+		} `json:"result"`
 	}
-
-	// Create collection with new dimensions
-	collectionConfig := map[string]interface{}{
-		"vectors": map[string]interface{}{
-			"size":     vectorSize,
-			"distance": "Cosine",
-		},
+	if err := json.NewDecoder(resp.Body).Decode(&collectionInfo); err != nil {
+		return 0, true, fmt.Errorf("failed to parse collection info: %w", err)
 	}
 
-	jsonBody, err := json.Marshal(collectionConfig)
-	if err != nil {
-		return fmt.Errorf("failed to marshal collection config: %w", err)
-	}
+	size, _ = parseVectorParams(collectionInfo.Result.Config.Params.Vectors)
+	return size, true, nil
+}
 
-	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/collections/%s", c.baseURL, CodeIndexCollection), bytes.NewBuffer(jsonBody))
+// DeleteCollection deletes a Qdrant collection
+func (c *QdrantClient) DeleteCollection(collectionName string) error {
+	c.forgetVectorLayout(collectionName)
+	url := c.collectionURL(collectionName)
+	req, err := http.NewRequest("DELETE", url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create delete request: %w", err)
 	}
-
-	req.Header.Set("Content-Type", "application/json")
 	c.addAuthHeader(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to create collection: %w", err)
+		return fmt.Errorf("failed to delete collection: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to create collection (status %d): %s", resp.StatusCode, string(body))
+		return fmt.Errorf("failed to delete collection (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	return nil
 }
 
-// EnsureCodeIndexCollection creates the code index collection if it doesn't exist
+// RecreateCodeIndexCollection moves the code index alias to a new, empty
+// collection with new dimensions. The previous collection is kept for
+// rollback (see MigrateCollection).
+func (c *QdrantClient) RecreateCodeIndexCollection(vectorSize int) (*AliasMigration, error) {
+	return c.MigrateCollection(context.Background(), CodeIndexCollection, vectorSize)
+}
+
+// EnsureCodeIndexCollection creates the code index collection if it doesn't
+// exist, as the alias of code_index_v1 (see MigrateCollection)
 // If expectedDimensions > 0, it also verifies the collection has matching dimensions
 func (c *QdrantClient) EnsureCodeIndexCollection(expectedDimensions ...int) error {
 	// Check if collection exists
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/collections/%s", c.baseURL, CodeIndexCollection), nil)
+	req, err := http.NewRequest("GET", c.collectionURL(CodeIndexCollection), nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -649,9 +920,7 @@ func (c *QdrantClient) EnsureCodeIndexCollection(expectedDimensions ...int) erro
 				Result struct {
 					Config struct {
 						Params struct {
-							Vectors struct {
-								Size int `json:"size"`
-							} `json:"vectors"`
+							Vectors json.RawMessage `json:"vectors"`
 						} `json:"params"`
 					} `json:"config"`
 				} `json:"result"`
@@ -661,7 +930,7 @@ func (c *QdrantClient) EnsureCodeIndexCollection(expectedDimensions ...int) erro
 				return fmt.Errorf("failed to parse collection info: %w", err)
 			}
 
-			actualDim := collectionInfo.Result.Config.Params.Vectors.Size
+			actualDim, _ := parseVectorParams(collectionInfo.Result.Config.Params.Vectors)
 			if actualDim != expectedDimensions[0] {
 				return &DimensionMismatchError{
 					ExpectedDim: actualDim,
@@ -673,45 +942,38 @@ func (c *QdrantClient) EnsureCodeIndexCollection(expectedDimensions ...int) erro
 		return nil
 	}
 
-	// Create collection
-	collectionConfig := map[string]interface{}{
-		"vectors": map[string]interface{}{
-			"size":     CodeIndexVectorSize,
-			"distance": "Cosine",
-		},
+	// Create the first version behind the code index alias
+	if _, err := c.MigrateCollection(context.Background(), CodeIndexCollection, CodeIndexVectorSize); err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
 	}
 
-	jsonBody, err := json.Marshal(collectionConfig)
-	if err != nil {
-		return fmt.Errorf("failed to marshal collection config: %w", err)
-	}
+	return nil
+}
+
+// UpsertCodeIndexPoints upserts code indexing points into the specified collection
+func (c *QdrantClient) UpsertCodeIndexPoints(collectionName string, points []CodeIndexPoint) error {
+	return c.UpsertCodeIndexPointsContext(context.Background(), collectionName, points)
+}
 
-	req, err = http.NewRequest("PUT", fmt.Sprintf("%s/collections/%s", c.baseURL, CodeIndexCollection), bytes.NewBuffer(jsonBody))
+// UpsertCodeIndexPointsContext is UpsertCodeIndexPoints bounded by ctx's
+// deadline; bulk callers first yield to interactive searches
+func (c *QdrantClient) UpsertCodeIndexPointsContext(ctx context.Context, collectionName string, points []CodeIndexPoint) error {
+	done, err := priority.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
+	defer done()
 
-	req.Header.Set("Content-Type", "application/json")
-	c.addAuthHeader(req)
-
-	resp, err = c.httpClient.Do(req)
+	named, err := c.UsesNamedVectors(ctx, collectionName)
 	if err != nil {
-		return fmt.Errorf("failed to create collection: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to create collection (status %d): %s", resp.StatusCode, string(body))
+	bodies := make([]map[string]interface{}, len(points))
+	for i, point := range points {
+		bodies[i] = codeIndexPointBody(point, named)
 	}
-
-	return nil
-}
-
-// UpsertCodeIndexPoints upserts code indexing points into the specified collection
-func (c *QdrantClient) UpsertCodeIndexPoints(collectionName string, points []CodeIndexPoint) error {
 	requestBody := map[string]interface{}{
-		"points": points,
+		"points": bodies,
 	}
 
 	jsonBody, err := json.Marshal(requestBody)
@@ -719,8 +981,8 @@ func (c *QdrantClient) UpsertCodeIndexPoints(collectionName string, points []Cod
 		return fmt.Errorf("failed to marshal points: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/collections/%s/points?wait=true", c.baseURL, collectionName)
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonBody))
+	url := c.collectionURL(collectionName) + "/points?wait=true"
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -751,20 +1013,51 @@ func (c *QdrantClient) UpsertCodeIndexPoints(collectionName string, points []Cod
 
 // SearchCodeIndex performs a vector similarity search for code in the specified collection
 func (c *QdrantClient) SearchCodeIndex(collectionName string, vector []float32, limit int) (*CodeIndexSearchResponse, error) {
+	return c.SearchCodeIndexFiltered(collectionName, vector, limit, nil)
+}
+
+// SearchCodeIndexFiltered searches a code index collection, restricted to
+// points matching a Qdrant payload filter (nil searches everything)
+func (c *QdrantClient) SearchCodeIndexFiltered(collectionName string, vector []float32, limit int, filter map[string]interface{}) (*CodeIndexSearchResponse, error) {
+	return c.SearchCodeIndexFilteredContext(context.Background(), collectionName, vector, limit, filter)
+}
+
+// SearchCodeIndexFilteredContext is SearchCodeIndexFiltered bounded by ctx's
+// deadline and admitted at ctx's priority. Collections with named vectors are
+// searched by their code vector (see SearchCodeIndexModeContext).
+func (c *QdrantClient) SearchCodeIndexFilteredContext(ctx context.Context, collectionName string, vector []float32, limit int, filter map[string]interface{}) (*CodeIndexSearchResponse, error) {
+	return c.SearchCodeIndexModeContext(ctx, collectionName, VectorModeCode, vector, limit, filter)
+}
+
+// searchCodeIndexVector searches one vector of a collection; vectorName is
+// empty for collections without named vectors
+func (c *QdrantClient) searchCodeIndexVector(ctx context.Context, collectionName, vectorName string, vector []float32, limit int, filter map[string]interface{}) (*CodeIndexSearchResponse, error) {
+	done, err := priority.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
 	searchReq := map[string]interface{}{
 		"vector":       vector,
 		"limit":        limit,
 		"with_payload": true,
 		"with_vector":  false,
 	}
+	if vectorName != "" {
+		searchReq["vector"] = map[string]interface{}{"name": vectorName, "vector": vector}
+	}
+	if filter != nil {
+		searchReq["filter"] = filter
+	}
 
 	jsonBody, err := json.Marshal(searchReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal search request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/collections/%s/points/search", c.baseURL, collectionName)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	url := c.collectionURL(collectionName) + "/points/search"
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -809,7 +1102,7 @@ func (c *QdrantClient) DeleteCodeIndexByFilter(collectionName string, filter map
 		return fmt.Errorf("failed to marshal delete request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/collections/%s/points/delete?wait=true", c.baseURL, collectionName)
+	url := c.collectionURL(collectionName) + "/points/delete?wait=true"
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -854,7 +1147,7 @@ func (c *QdrantClient) DeleteCodeIndexPoint(pointID string) error {
 		return fmt.Errorf("failed to marshal delete request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/collections/%s/points/delete?wait=true", c.baseURL, CodeIndexCollection)
+	url := c.collectionURL(CodeIndexCollection) + "/points/delete?wait=true"
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -877,6 +1170,48 @@ func (c *QdrantClient) DeleteCodeIndexPoint(pointID string) error {
 	return nil
 }
 
+// SetCodeIndexPointPayload merges payload fields into an existing code index point
+// without touching its vector (used when an unchanged chunk moves within a file)
+func (c *QdrantClient) SetCodeIndexPointPayload(pointID string, payload map[string]interface{}) error {
+	return c.SetPointPayload(CodeIndexCollection, pointID, payload)
+}
+
+// SetPointPayload merges payload fields into an existing point without touching
+// its vector
+func (c *QdrantClient) SetPointPayload(collectionName string, pointID string, payload map[string]interface{}) error {
+	requestBody := map[string]interface{}{
+		"payload": payload,
+		"points":  []string{pointID},
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal set payload request: %w", err)
+	}
+
+	url := c.collectionURL(collectionName) + "/points/payload?wait=true"
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	c.addAuthHeader(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to set point payload: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to set point payload (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
 // EnsureCollectionForPath ensures a Qdrant collection exists for a specific path
 // Checks code_index_map for existing mapping, or creates new collection and mapping
 // Returns the collection name to use for this path
@@ -895,12 +1230,9 @@ func (c *QdrantClient) EnsureCollectionForPath(path string, codeIndexStorage *Co
 	// No mapping exists, create new collection
 	collectionName := GenerateCollectionName(path)
 
-	// Create Qdrant collection with 768 dimensions (default for code indexing)
+	// Create the Qdrant collection with named code and summary vectors
 	collectionConfig := map[string]interface{}{
-		"vectors": map[string]interface{}{
-			"size":     c.vectorDimension,
-			"distance": "Cosine",
-		},
+		"vectors": codeIndexVectorsConfig(c.vectorDimension),
 	}
 
 	jsonBody, err := json.Marshal(collectionConfig)
@@ -908,7 +1240,7 @@ func (c *QdrantClient) EnsureCollectionForPath(path string, codeIndexStorage *Co
 		return "", fmt.Errorf("failed to marshal collection config: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/collections/%s", c.baseURL, collectionName)
+	url := c.collectionURL(collectionName)
 	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
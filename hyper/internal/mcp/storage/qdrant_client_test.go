@@ -2,7 +2,11 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -269,3 +273,91 @@ func TestSearchSimilar_Integration(t *testing.T) {
 	// Results should contain our data
 	assert.Contains(t, results[0].Entry.Text, "kubernetes")
 }
+
+func TestStorePoints_ReusesMatchingVectors(t *testing.T) {
+	var upserted []QdrantPoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Points []QdrantPoint `json:"points"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		upserted = body.Points
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var embedded []string
+	client := NewQdrantClientWithEmbedding(server.URL, func(text string) ([]float64, error) {
+		embedded = append(embedded, text)
+		return []float64{9, 9, 9}, nil
+	}, 3)
+
+	err := client.StorePoints("test_collection", []KnowledgePoint{
+		{ID: "reused", Text: "exported", Vector: []float64{1, 2, 3}},
+		{ID: "wrong-dimension", Text: "other model", Vector: []float64{1, 2}},
+		{ID: "new", Text: "fresh"},
+	})
+	assert.NoError(t, err)
+
+	// Only points without a vector of the client's dimension are embedded
+	assert.Equal(t, []string{"other model", "fresh"}, embedded)
+	if assert.Len(t, upserted, 3) {
+		assert.Equal(t, []float64{1, 2, 3}, upserted[0].Vector)
+		assert.Equal(t, []float64{9, 9, 9}, upserted[1].Vector)
+		assert.Equal(t, []float64{9, 9, 9}, upserted[2].Vector)
+	}
+}
+
+func TestPointVectors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/collections/test_collection/points", r.URL.Path)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, true, body["with_vector"])
+
+		w.Write([]byte(`{"result":[{"id":"a","vector":[0.1,0.2]},{"id":"b","vector":null}]}`))
+	}))
+	defer server.Close()
+
+	client := NewQdrantClientWithEmbedding(server.URL, mockEmbeddingFuncFactory(2), 2)
+	vectors, err := client.PointVectors("test_collection", []string{"a", "b", "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]float64{"a": {0.1, 0.2}}, vectors)
+}
+
+func TestSearchContextHonorsDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewQdrantClientWithEmbedding(server.URL, mockEmbeddingFuncFactory(2), 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.SearchSimilarContext(ctx, "test_collection", "query", "", 5)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = client.SearchCodeIndexFilteredContext(ctx, "code_index", []float32{0.1, 0.2}, 5, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestMaxTimeFor(t *testing.T) {
+	assert.Nil(t, maxTimeFor(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	maxTime := maxTimeFor(ctx)
+	if assert.NotNil(t, maxTime) {
+		assert.InDelta(t, float64(time.Minute), float64(*maxTime), float64(time.Second))
+	}
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	assert.Equal(t, time.Millisecond, *maxTimeFor(expired))
+}
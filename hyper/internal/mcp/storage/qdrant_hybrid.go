@@ -0,0 +1,415 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"hyper/internal/priority"
+)
+
+// Search modes of code searches: rank chunks by embedding similarity, by the
+// query's keywords, or by both rankings fused. Keyword matching finds exact
+// identifiers such as NewHTTPBridge that embeddings tend to miss.
+const (
+	SearchModeVector  = "vector"
+	SearchModeKeyword = "keyword"
+	SearchModeHybrid  = "hybrid"
+)
+
+// Fusion strategies of hybrid searches
+const (
+	FusionRRF      = "rrf"      // Reciprocal rank fusion: the sum of 1/(k+rank) over both rankings
+	FusionWeighted = "weighted" // Each ranking's scores scaled to its best hit, mixed by KeywordWeight
+)
+
+// Environment variables setting the defaults of code searches
+const (
+	SearchModeEnv    = "CODE_INDEX_SEARCH_MODE"
+	SearchFusionEnv  = "CODE_INDEX_SEARCH_FUSION"
+	RRFKEnv          = "CODE_INDEX_RRF_K"
+	KeywordWeightEnv = "CODE_INDEX_KEYWORD_WEIGHT"
+)
+
+const (
+	defaultRRFK          = 60
+	defaultKeywordWeight = 0.3
+
+	// keywordCandidateLimit caps the chunks a keyword search reads and scores
+	keywordCandidateLimit = 256
+
+	// keywordTermLimit caps the query words a keyword search matches
+	keywordTermLimit = 8
+
+	// BM25 term frequency saturation and length normalization
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// contentKey is the payload field holding a chunk's code, full-text indexed
+// for keyword searches
+const contentKey = "content"
+
+// HybridSearch selects how code searches rank chunks
+type HybridSearch struct {
+	Mode          string  // vector, keyword or hybrid
+	Fusion        string  // rrf or weighted; used in hybrid mode
+	RRFK          int     // Rank constant of reciprocal rank fusion
+	KeywordWeight float64 // Share of the keyword ranking in weighted fusion, from 0 to 1
+}
+
+// ParseSearchMode reads a search mode; empty selects vector
+func ParseSearchMode(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "":
+		return SearchModeVector, nil
+	case SearchModeVector, SearchModeKeyword, SearchModeHybrid:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown search mode '%s': use vector, keyword or hybrid", mode)
+}
+
+// ParseFusion reads a fusion strategy; empty selects rrf
+func ParseFusion(fusion string) (string, error) {
+	switch fusion = strings.ToLower(strings.TrimSpace(fusion)); fusion {
+	case "":
+		return FusionRRF, nil
+	case FusionRRF, FusionWeighted:
+		return fusion, nil
+	}
+	return "", fmt.Errorf("unknown fusion '%s': use rrf or weighted", fusion)
+}
+
+// HybridSearchFromEnv returns the search settings of CODE_INDEX_SEARCH_MODE,
+// CODE_INDEX_SEARCH_FUSION, CODE_INDEX_RRF_K and CODE_INDEX_KEYWORD_WEIGHT:
+// vector searches, and reciprocal rank fusion with k=60 for hybrid ones,
+// when unset
+func HybridSearchFromEnv() (HybridSearch, error) {
+	search := HybridSearch{Mode: SearchModeVector, Fusion: FusionRRF, RRFK: defaultRRFK, KeywordWeight: defaultKeywordWeight}
+
+	var err error
+	if search.Mode, err = ParseSearchMode(os.Getenv(SearchModeEnv)); err != nil {
+		return search, fmt.Errorf("invalid %s: %w", SearchModeEnv, err)
+	}
+	if search.Fusion, err = ParseFusion(os.Getenv(SearchFusionEnv)); err != nil {
+		return search, fmt.Errorf("invalid %s: %w", SearchFusionEnv, err)
+	}
+	if v := os.Getenv(RRFKEnv); v != "" {
+		if search.RRFK, err = strconv.Atoi(v); err != nil {
+			return search, fmt.Errorf("invalid %s: %w", RRFKEnv, err)
+		}
+	}
+	if v := os.Getenv(KeywordWeightEnv); v != "" {
+		if search.KeywordWeight, err = strconv.ParseFloat(v, 64); err != nil {
+			return search, fmt.Errorf("invalid %s: %w", KeywordWeightEnv, err)
+		}
+	}
+	return search, search.Validate()
+}
+
+// Validate checks the numeric settings
+func (s HybridSearch) Validate() error {
+	if s.RRFK < 1 {
+		return fmt.Errorf("rrf k must be at least 1, got %d", s.RRFK)
+	}
+	if s.KeywordWeight < 0 || s.KeywordWeight > 1 {
+		return fmt.Errorf("keyword weight must be between 0 and 1, got %g", s.KeywordWeight)
+	}
+	return nil
+}
+
+// SearchCodeIndexHybridContext searches a code index collection in the
+// search's mode: by vector (with vectorMode, see SearchCodeIndexModeContext),
+// by the query's keywords (vector may then be nil), or both. Hybrid searches
+// rank twice limit candidates each way before fusing them, so chunks both
+// rankings place moderately high can overtake chunks only one ranks first.
+func (c *QdrantClient) SearchCodeIndexHybridContext(ctx context.Context, collectionName string, search HybridSearch, vectorMode, query string, vector []float32, limit int, filter map[string]interface{}) (*CodeIndexSearchResponse, error) {
+	switch search.Mode {
+	case SearchModeKeyword:
+		return c.SearchCodeIndexKeywordContext(ctx, collectionName, query, limit, filter)
+	case SearchModeHybrid:
+	default:
+		return c.SearchCodeIndexModeContext(ctx, collectionName, vectorMode, vector, limit, filter)
+	}
+
+	var (
+		wg                    sync.WaitGroup
+		keyword               *CodeIndexSearchResponse
+		keywordErr, vectorErr error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		keyword, keywordErr = c.SearchCodeIndexKeywordContext(ctx, collectionName, query, limit*2, filter)
+	}()
+	vectorResp, vectorErr := c.SearchCodeIndexModeContext(ctx, collectionName, vectorMode, vector, limit*2, filter)
+	wg.Wait()
+	if vectorErr != nil {
+		return nil, vectorErr
+	}
+	if keywordErr != nil {
+		return nil, fmt.Errorf("keyword search failed: %w", keywordErr)
+	}
+	return fuseHybridResults(vectorResp, keyword, search, limit), nil
+}
+
+// fuseHybridResults merges the vector and keyword rankings of a collection.
+// Points keep the payload of their first ranking; ties keep vector order.
+func fuseHybridResults(vector, keyword *CodeIndexSearchResponse, search HybridSearch, limit int) *CodeIndexSearchResponse {
+	fused := &CodeIndexSearchResponse{}
+	scores := make(map[string]float64)
+	weights := []float64{1 - search.KeywordWeight, search.KeywordWeight}
+	for r, resp := range []*CodeIndexSearchResponse{vector, keyword} {
+		var best float32
+		for _, hit := range resp.Result {
+			best = max(best, hit.Score)
+		}
+		for rank, hit := range resp.Result {
+			var score float64
+			switch search.Fusion {
+			case FusionWeighted:
+				if best > 0 {
+					score = weights[r] * float64(hit.Score/best)
+				}
+			default:
+				score = 1 / float64(search.RRFK+rank+1)
+			}
+			if _, ok := scores[hit.ID]; !ok {
+				fused.Result = append(fused.Result, hit)
+			}
+			scores[hit.ID] += score
+		}
+	}
+	for i := range fused.Result {
+		fused.Result[i].Score = float32(scores[fused.Result[i].ID])
+	}
+	sort.SliceStable(fused.Result, func(i, j int) bool {
+		return fused.Result[i].Score > fused.Result[j].Score
+	})
+	if len(fused.Result) > limit {
+		fused.Result = fused.Result[:limit]
+	}
+	return fused
+}
+
+// SearchCodeIndexKeywordContext ranks the chunks of a code index collection
+// containing the query's words by BM25. Candidates come from a full-text
+// index of chunk content, those with the rarest word first, up to
+// keywordCandidateLimit. Document frequencies are counted over the whole
+// collection; the average chunk length is taken from the candidates.
+func (c *QdrantClient) SearchCodeIndexKeywordContext(ctx context.Context, collectionName, query string, limit int, filter map[string]interface{}) (*CodeIndexSearchResponse, error) {
+	terms := KeywordTerms(query)
+	if len(terms) == 0 {
+		return &CodeIndexSearchResponse{}, nil
+	}
+
+	done, err := priority.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	c.ensureTextIndex(ctx, collectionName)
+
+	total, err := c.countCodeIndexPoints(ctx, collectionName, filter)
+	if err != nil || total == 0 {
+		return &CodeIndexSearchResponse{}, err
+	}
+	frequencies := make(map[string]int, len(terms))
+	for _, term := range terms {
+		if frequencies[term], err = c.countCodeIndexPoints(ctx, collectionName, keywordFilter(filter, term, nil)); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(terms, func(i, j int) bool { return frequencies[terms[i]] < frequencies[terms[j]] })
+
+	var candidates []CodeIndexHit
+	var ids []string
+	for _, term := range terms {
+		if frequencies[term] == 0 {
+			continue
+		}
+		remaining := keywordCandidateLimit - len(candidates)
+		if remaining <= 0 {
+			break
+		}
+		hits, err := c.scrollCodeIndexPoints(ctx, collectionName, keywordFilter(filter, term, ids), remaining)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			candidates = append(candidates, hit)
+			ids = append(ids, hit.ID)
+		}
+	}
+	return rankKeywordCandidates(candidates, terms, frequencies, total, limit), nil
+}
+
+// rankKeywordCandidates scores chunks by BM25 over terms, best first,
+// dropping chunks that contain none of them
+func rankKeywordCandidates(candidates []CodeIndexHit, terms []string, frequencies map[string]int, total, limit int) *CodeIndexSearchResponse {
+	resp := &CodeIndexSearchResponse{}
+	if len(candidates) == 0 {
+		return resp
+	}
+
+	counts := make([]map[string]int, len(candidates))
+	lengths := make([]int, len(candidates))
+	sumLength := 0
+	for i, hit := range candidates {
+		content, _ := hit.Payload[contentKey].(string)
+		tokens := keywordTokens(content)
+		counts[i] = make(map[string]int)
+		for _, token := range tokens {
+			counts[i][token]++
+		}
+		lengths[i] = len(tokens)
+		sumLength += len(tokens)
+	}
+	avgLength := math.Max(float64(sumLength)/float64(len(candidates)), 1)
+
+	for i, hit := range candidates {
+		var score float64
+		for _, term := range terms {
+			tf := float64(counts[i][term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(frequencies[term])
+			idf := math.Log(1 + (float64(total)-df+0.5)/(df+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(lengths[i])/avgLength))
+		}
+		if score > 0 {
+			hit.Score = float32(score)
+			resp.Result = append(resp.Result, hit)
+		}
+	}
+	sort.SliceStable(resp.Result, func(i, j int) bool {
+		return resp.Result[i].Score > resp.Result[j].Score
+	})
+	if len(resp.Result) > limit {
+		resp.Result = resp.Result[:limit]
+	}
+	return resp
+}
+
+// KeywordTerms returns the distinct words of a query that keyword searches
+// match, lowercased, in query order
+func KeywordTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, token := range keywordTokens(query) {
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+		terms = append(terms, token)
+		if len(terms) == keywordTermLimit {
+			break
+		}
+	}
+	return terms
+}
+
+// keywordTokens splits text into lowercase words of two or more letters and
+// digits, as the full-text index does: identifiers stay whole, so
+// NewHTTPBridge is one word and request_id two
+func keywordTokens(text string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) >= 2 {
+			tokens = append(tokens, strings.ToLower(word))
+		}
+	}
+	return tokens
+}
+
+// keywordFilter narrows filter to points whose content contains term,
+// excluding the points in exclude
+func keywordFilter(filter map[string]interface{}, term string, exclude []string) map[string]interface{} {
+	must := []interface{}{
+		map[string]interface{}{"key": contentKey, "match": map[string]interface{}{"text": term}},
+	}
+	if filter != nil {
+		must = append(must, filter)
+	}
+	narrowed := map[string]interface{}{"must": must}
+	if len(exclude) > 0 {
+		narrowed["must_not"] = []interface{}{map[string]interface{}{"has_id": exclude}}
+	}
+	return narrowed
+}
+
+// ensureTextIndex creates the full-text index of chunk content once per
+// collection; Qdrant keeps an existing index as it is. Without the index
+// Qdrant still matches text by scanning payloads, so a failure only makes
+// keyword searches slower, and creating the index is tried again next time.
+func (c *QdrantClient) ensureTextIndex(ctx context.Context, collectionName string) {
+	if _, ok := c.textIndexes.Load(collectionName); ok {
+		return
+	}
+	body := map[string]interface{}{
+		"field_name": contentKey,
+		"field_schema": map[string]interface{}{
+			"type":          "text",
+			"tokenizer":     "word",
+			"min_token_len": 2,
+			"lowercase":     true,
+		},
+	}
+	if err := c.qdrantJSON(ctx, http.MethodPut, c.collectionURL(collectionName)+"/index?wait=true", body, nil); err == nil {
+		c.textIndexes.Store(collectionName, true)
+	}
+}
+
+// countCodeIndexPoints counts the points of a collection matching filter
+func (c *QdrantClient) countCodeIndexPoints(ctx context.Context, collectionName string, filter map[string]interface{}) (int, error) {
+	body := map[string]interface{}{"exact": true}
+	if filter != nil {
+		body["filter"] = filter
+	}
+	var response struct {
+		Result struct {
+			Count int `json:"count"`
+		} `json:"result"`
+	}
+	if err := c.qdrantJSON(ctx, http.MethodPost, c.collectionURL(collectionName)+"/points/count", body, &response); err != nil {
+		return 0, fmt.Errorf("failed to count points: %w", err)
+	}
+	return response.Result.Count, nil
+}
+
+// scrollCodeIndexPoints returns up to limit points matching filter, with
+// their payloads
+func (c *QdrantClient) scrollCodeIndexPoints(ctx context.Context, collectionName string, filter map[string]interface{}, limit int) ([]CodeIndexHit, error) {
+	body := map[string]interface{}{
+		"filter":       filter,
+		"limit":        limit,
+		"with_payload": true,
+		"with_vector":  false,
+	}
+	var response struct {
+		Result struct {
+			Points []struct {
+				ID      interface{}            `json:"id"`
+				Payload map[string]interface{} `json:"payload"`
+			} `json:"points"`
+		} `json:"result"`
+	}
+	if err := c.qdrantJSON(ctx, http.MethodPost, c.collectionURL(collectionName)+"/points/scroll", body, &response); err != nil {
+		return nil, fmt.Errorf("failed to scroll points: %w", err)
+	}
+	hits := make([]CodeIndexHit, len(response.Result.Points))
+	for i, point := range response.Result.Points {
+		hits[i] = CodeIndexHit{ID: fmt.Sprint(point.ID), Payload: point.Payload}
+	}
+	return hits, nil
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeywordTerms(t *testing.T) {
+	assert.Equal(t, []string{"where", "is", "newhttpbridge", "request", "id"}, KeywordTerms("Where is NewHTTPBridge? a request_id, newHTTPBridge"))
+	assert.Empty(t, KeywordTerms(" - ? "))
+	assert.Len(t, KeywordTerms("one two three four five six seven eight nine ten"), keywordTermLimit)
+}
+
+func TestHybridSearchFromEnv(t *testing.T) {
+	search, err := HybridSearchFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, HybridSearch{Mode: SearchModeVector, Fusion: FusionRRF, RRFK: 60, KeywordWeight: 0.3}, search)
+
+	t.Setenv(SearchModeEnv, "Hybrid")
+	t.Setenv(SearchFusionEnv, "weighted")
+	t.Setenv(RRFKEnv, "10")
+	t.Setenv(KeywordWeightEnv, "0.5")
+	search, err = HybridSearchFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, HybridSearch{Mode: SearchModeHybrid, Fusion: FusionWeighted, RRFK: 10, KeywordWeight: 0.5}, search)
+
+	t.Setenv(KeywordWeightEnv, "2")
+	_, err = HybridSearchFromEnv()
+	assert.ErrorContains(t, err, "keyword weight")
+
+	t.Setenv(SearchModeEnv, "sparse")
+	_, err = HybridSearchFromEnv()
+	assert.ErrorContains(t, err, SearchModeEnv)
+}
+
+func TestFuseHybridResults(t *testing.T) {
+	var vector, keyword CodeIndexSearchResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"result":[{"id":"a","score":0.9},{"id":"b","score":0.8},{"id":"c","score":0.7}]}`), &vector))
+	require.NoError(t, json.Unmarshal([]byte(`{"result":[{"id":"c","score":12},{"id":"d","score":3}]}`), &keyword))
+
+	ids := func(resp *CodeIndexSearchResponse) []string {
+		var out []string
+		for _, hit := range resp.Result {
+			out = append(out, hit.ID)
+		}
+		return out
+	}
+
+	rrf := fuseHybridResults(&vector, &keyword, HybridSearch{Fusion: FusionRRF, RRFK: 60}, 3)
+	assert.Equal(t, []string{"c", "a", "b"}, ids(rrf), "ranked by both beats ranked first by one")
+	assert.InDelta(t, 1.0/63+1.0/61, rrf.Result[0].Score, 1e-6)
+
+	weighted := fuseHybridResults(&vector, &keyword, HybridSearch{Fusion: FusionWeighted, KeywordWeight: 0.3}, 4)
+	assert.Equal(t, []string{"c", "a", "b", "d"}, ids(weighted))
+	assert.InDelta(t, 0.7*0.7/0.9+0.3, weighted.Result[0].Score, 1e-6)
+
+	vectorOnly := fuseHybridResults(&vector, &keyword, HybridSearch{Fusion: FusionWeighted, KeywordWeight: 0}, 4)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, ids(vectorOnly))
+}
+
+func TestSearchCodeIndexKeywordContext(t *testing.T) {
+	chunks := map[string]string{
+		"1": "func NewHTTPBridge(addr string) *Bridge {\n\treturn &Bridge{addr: addr}\n}",
+		"2": "// The bridge forwards requests\nbridge := NewHTTPBridge(addr)\nbridge.Start()",
+		"3": "func handleRequest(w http.ResponseWriter) {}",
+	}
+	// matches reports whether a chunk passes a keyword filter: it contains the
+	// matched term and is not excluded
+	matches := func(id string, filter map[string]interface{}) bool {
+		if filter == nil {
+			return true
+		}
+		term := filter["must"].([]interface{})[0].(map[string]interface{})["match"].(map[string]interface{})["text"].(string)
+		if notClauses, ok := filter["must_not"].([]interface{}); ok {
+			for _, excluded := range notClauses[0].(map[string]interface{})["has_id"].([]interface{}) {
+				if excluded == id {
+					return false
+				}
+			}
+		}
+		for _, token := range keywordTokens(chunks[id]) {
+			if token == term {
+				return true
+			}
+		}
+		return false
+	}
+
+	var indexed int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Filter map[string]interface{} `json:"filter"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		var matched []string
+		for _, id := range []string{"1", "2", "3"} {
+			if matches(id, body.Filter) {
+				matched = append(matched, id)
+			}
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/index"):
+			indexed++
+			w.Write([]byte(`{"result":{}}`))
+		case strings.HasSuffix(r.URL.Path, "/points/count"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]int{"count": len(matched)}})
+		case strings.HasSuffix(r.URL.Path, "/points/scroll"):
+			var points []map[string]interface{}
+			for _, id := range matched {
+				points = append(points, map[string]interface{}{"id": id, "payload": map[string]string{"content": chunks[id]}})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"points": points}})
+		}
+	}))
+	defer server.Close()
+	client := NewQdrantClientWithEmbedding(server.URL, mockEmbeddingFuncFactory(2), 2)
+
+	resp, err := client.SearchCodeIndexKeywordContext(t.Context(), "code_index_repo", "NewHTTPBridge forwards", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, resp.Result, 2)
+	assert.Equal(t, "2", resp.Result[0].ID, "the chunk with both words ranks first")
+	assert.Equal(t, "1", resp.Result[1].ID)
+	assert.Greater(t, resp.Result[1].Score, float32(0))
+
+	resp, err = client.SearchCodeIndexKeywordContext(t.Context(), "code_index_repo", "unknownIdentifier", 10, nil)
+	require.NoError(t, err)
+	assert.Empty(t, resp.Result)
+	assert.Equal(t, 1, indexed, "the text index is created once per collection")
+}
@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Named vectors of code index points: the embedding of the chunk's code and,
+// when CODE_INDEX_SUMMARIES is on, the embedding of its natural-language summary
+const (
+	CodeVectorName    = "code"
+	SummaryVectorName = "summary"
+)
+
+// Vector modes of code searches: search the code vector, the summary vector,
+// or both, fusing the hits
+const (
+	VectorModeCode    = "code"
+	VectorModeSummary = "summary"
+	VectorModeFused   = "fused"
+)
+
+// ParseVectorMode reads a vector mode; empty selects fused
+func ParseVectorMode(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "":
+		return VectorModeFused, nil
+	case VectorModeCode, VectorModeSummary, VectorModeFused:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown vector mode '%s': use code, summary or fused", mode)
+}
+
+// isCodeIndexCollection reports whether name is the code index or a
+// per-folder code collection (see GenerateCollectionName); these are created
+// with named vectors
+func isCodeIndexCollection(name string) bool {
+	return name == CodeIndexCollection || strings.HasPrefix(name, DefaultCodeIndexCollection+"_")
+}
+
+// codeIndexVectorsConfig is the vectors config of a code index collection
+func codeIndexVectorsConfig(vectorSize int) map[string]interface{} {
+	params := map[string]interface{}{"size": vectorSize, "distance": "Cosine"}
+	return map[string]interface{}{
+		CodeVectorName:    params,
+		SummaryVectorName: params,
+	}
+}
+
+// parseVectorParams reads the vector size of a collection's vectors config,
+// and whether the collection uses named vectors (the code vector's size)
+func parseVectorParams(raw json.RawMessage) (size int, named bool) {
+	var single struct {
+		Size int `json:"size"`
+	}
+	if err := json.Unmarshal(raw, &single); err == nil && single.Size > 0 {
+		return single.Size, false
+	}
+	var multiple map[string]struct {
+		Size int `json:"size"`
+	}
+	if err := json.Unmarshal(raw, &multiple); err == nil {
+		if code, ok := multiple[CodeVectorName]; ok {
+			return code.Size, true
+		}
+	}
+	return 0, false
+}
+
+// UsesNamedVectors reports whether a collection stores named code and
+// summary vectors. Collections created before named vectors hold one
+// unnamed vector of summary and code embedded together.
+func (c *QdrantClient) UsesNamedVectors(ctx context.Context, collectionName string) (bool, error) {
+	if named, ok := c.vectorLayouts.Load(collectionName); ok {
+		return named.(bool), nil
+	}
+
+	var info struct {
+		Result struct {
+			Config struct {
+				Params struct {
+					Vectors json.RawMessage `json:"vectors"`
+				} `json:"params"`
+			} `json:"config"`
+		} `json:"result"`
+	}
+	if err := c.qdrantJSON(ctx, http.MethodGet, c.collectionURL(collectionName), nil, &info); err != nil {
+		return false, fmt.Errorf("failed to get collection info: %w", err)
+	}
+	_, named := parseVectorParams(info.Result.Config.Params.Vectors)
+	c.vectorLayouts.Store(collectionName, named)
+	return named, nil
+}
+
+// forgetVectorLayout drops the cached layout and text index state of a
+// collection or alias whose target changed
+func (c *QdrantClient) forgetVectorLayout(collectionName string) {
+	c.vectorLayouts.Delete(collectionName)
+	c.textIndexes.Delete(collectionName)
+}
+
+// codeIndexPointBody is the JSON of a point upserted into a collection with
+// or without named vectors
+func codeIndexPointBody(point CodeIndexPoint, named bool) map[string]interface{} {
+	body := map[string]interface{}{
+		"id":      point.ID,
+		"payload": point.Payload,
+	}
+	if !named {
+		body["vector"] = point.Vector
+		return body
+	}
+	vectors := map[string][]float32{CodeVectorName: point.Vector}
+	if len(point.SummaryVector) > 0 {
+		vectors[SummaryVectorName] = point.SummaryVector
+	}
+	body["vector"] = vectors
+	return body
+}
+
+// SearchCodeIndexModeContext searches a code index collection with mode.
+// Fused searches run against both named vectors and keep each point once,
+// with its better score. Collections without named vectors are searched as
+// they are, whatever the mode.
+func (c *QdrantClient) SearchCodeIndexModeContext(ctx context.Context, collectionName, mode string, vector []float32, limit int, filter map[string]interface{}) (*CodeIndexSearchResponse, error) {
+	named, err := c.UsesNamedVectors(ctx, collectionName)
+	if err != nil {
+		return nil, err
+	}
+	if !named {
+		return c.searchCodeIndexVector(ctx, collectionName, "", vector, limit, filter)
+	}
+
+	switch mode {
+	case VectorModeSummary:
+		return c.searchCodeIndexVector(ctx, collectionName, SummaryVectorName, vector, limit, filter)
+	case VectorModeFused:
+		code, err := c.searchCodeIndexVector(ctx, collectionName, CodeVectorName, vector, limit, filter)
+		if err != nil {
+			return nil, err
+		}
+		summary, err := c.searchCodeIndexVector(ctx, collectionName, SummaryVectorName, vector, limit, filter)
+		if err != nil {
+			return nil, err
+		}
+		return fuseCodeIndexResults(code, summary, limit), nil
+	default:
+		return c.searchCodeIndexVector(ctx, collectionName, CodeVectorName, vector, limit, filter)
+	}
+}
+
+// fuseCodeIndexResults merges two searches of the same collection, keeping
+// each point once with its higher score, best first
+func fuseCodeIndexResults(a, b *CodeIndexSearchResponse, limit int) *CodeIndexSearchResponse {
+	fused := &CodeIndexSearchResponse{}
+	index := make(map[string]int)
+	for _, resp := range []*CodeIndexSearchResponse{a, b} {
+		for _, hit := range resp.Result {
+			if i, ok := index[hit.ID]; ok {
+				if hit.Score > fused.Result[i].Score {
+					fused.Result[i].Score = hit.Score
+				}
+				continue
+			}
+			index[hit.ID] = len(fused.Result)
+			fused.Result = append(fused.Result, hit)
+		}
+	}
+	sort.SliceStable(fused.Result, func(i, j int) bool {
+		return fused.Result[i].Score > fused.Result[j].Score
+	})
+	if len(fused.Result) > limit {
+		fused.Result = fused.Result[:limit]
+	}
+	return fused
+}
+
+// StoredVectors are the vectors stored for a code index point. Collections
+// without named vectors only have Code, holding summary and code embedded
+// together.
+type StoredVectors struct {
+	Code    []float32
+	Summary []float32
+}
+
+// CodeIndexPointVectors retrieves the stored vectors of code index points by
+// ID. Points missing from the collection are omitted from the result.
+func (c *QdrantClient) CodeIndexPointVectors(ctx context.Context, collectionName string, ids []string) (map[string]StoredVectors, error) {
+	vectors := make(map[string]StoredVectors, len(ids))
+	if len(ids) == 0 {
+		return vectors, nil
+	}
+
+	var response struct {
+		Result []struct {
+			ID     interface{}     `json:"id"`
+			Vector json.RawMessage `json:"vector"`
+		} `json:"result"`
+	}
+	request := map[string]interface{}{"ids": ids, "with_payload": false, "with_vector": true}
+	if err := c.qdrantJSON(ctx, http.MethodPost, c.collectionURL(collectionName)+"/points", request, &response); err != nil {
+		return nil, fmt.Errorf("failed to retrieve points: %w", err)
+	}
+
+	for _, point := range response.Result {
+		vectors[fmt.Sprint(point.ID)] = decodeStoredVectors(point.Vector)
+	}
+	return vectors, nil
+}
+
+// decodeStoredVectors reads the vector of a point, named or not
+func decodeStoredVectors(raw json.RawMessage) StoredVectors {
+	var stored StoredVectors
+	if err := json.Unmarshal(raw, &stored.Code); err != nil {
+		var named map[string][]float32
+		if err := json.Unmarshal(raw, &named); err == nil {
+			stored.Code = named[CodeVectorName]
+			stored.Summary = named[SummaryVectorName]
+		}
+	}
+	return stored
+}
+
+// ScanCodeIndexPoints visits every point of a code index collection matching
+// filter, with its payload and vectors, paging through the collection with
+// the scroll API. A missing collection has no points.
+func (c *QdrantClient) ScanCodeIndexPoints(ctx context.Context, collectionName string, filter map[string]interface{}, visit func(CodeIndexPoint) error) error {
+	var offset interface{}
+	for {
+		body := map[string]interface{}{
+			"limit":        256,
+			"with_payload": true,
+			"with_vector":  true,
+		}
+		if filter != nil {
+			body["filter"] = filter
+		}
+		if offset != nil {
+			body["offset"] = offset
+		}
+
+		payloadBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal scroll payload: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.collectionURL(collectionName)+"/points/scroll", bytes.NewReader(payloadBytes))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		c.addAuthHeader(req)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to scroll points: %w", err)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			respBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return fmt.Errorf("failed to scroll points: status %d, body: %s", resp.StatusCode, string(respBody))
+		}
+
+		var scrollResp struct {
+			Result struct {
+				Points []struct {
+					ID      interface{}            `json:"id"`
+					Vector  json.RawMessage        `json:"vector"`
+					Payload map[string]interface{} `json:"payload"`
+				} `json:"points"`
+				NextPageOffset interface{} `json:"next_page_offset"`
+			} `json:"result"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&scrollResp)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode scroll response: %w", err)
+		}
+
+		for _, raw := range scrollResp.Result.Points {
+			vectors := decodeStoredVectors(raw.Vector)
+			point := CodeIndexPoint{ID: fmt.Sprint(raw.ID), Vector: vectors.Code, SummaryVector: vectors.Summary, Payload: raw.Payload}
+			if err := visit(point); err != nil {
+				return err
+			}
+		}
+		if scrollResp.Result.NextPageOffset == nil {
+			return nil
+		}
+		offset = scrollResp.Result.NextPageOffset
+	}
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVectorParams(t *testing.T) {
+	size, named := parseVectorParams(json.RawMessage(`{"size":768,"distance":"Cosine"}`))
+	assert.Equal(t, 768, size)
+	assert.False(t, named)
+
+	raw, err := json.Marshal(codeIndexVectorsConfig(384))
+	require.NoError(t, err)
+	size, named = parseVectorParams(raw)
+	assert.Equal(t, 384, size)
+	assert.True(t, named)
+
+	size, _ = parseVectorParams(nil)
+	assert.Zero(t, size)
+}
+
+func TestParseVectorMode(t *testing.T) {
+	mode, err := ParseVectorMode("")
+	require.NoError(t, err)
+	assert.Equal(t, VectorModeFused, mode)
+
+	mode, err = ParseVectorMode(" Summary ")
+	require.NoError(t, err)
+	assert.Equal(t, VectorModeSummary, mode)
+
+	_, err = ParseVectorMode("sparse")
+	assert.Error(t, err)
+}
+
+func TestCodeIndexPointBody(t *testing.T) {
+	point := CodeIndexPoint{ID: "p-1", Vector: []float32{1, 0}, SummaryVector: []float32{0, 1}}
+
+	assert.Equal(t, []float32{1, 0}, codeIndexPointBody(point, false)["vector"])
+	assert.Equal(t, map[string][]float32{
+		CodeVectorName:    {1, 0},
+		SummaryVectorName: {0, 1},
+	}, codeIndexPointBody(point, true)["vector"])
+
+	point.SummaryVector = nil
+	assert.Equal(t, map[string][]float32{CodeVectorName: {1, 0}}, codeIndexPointBody(point, true)["vector"])
+}
+
+func TestFuseCodeIndexResults(t *testing.T) {
+	var code, summary CodeIndexSearchResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"result":[{"id":"a","score":0.9},{"id":"b","score":0.5}]}`), &code))
+	require.NoError(t, json.Unmarshal([]byte(`{"result":[{"id":"b","score":0.95},{"id":"c","score":0.4}]}`), &summary))
+
+	fused := fuseCodeIndexResults(&code, &summary, 2)
+	require.Len(t, fused.Result, 2)
+	assert.Equal(t, "b", fused.Result[0].ID)
+	assert.Equal(t, float32(0.95), fused.Result[0].Score)
+	assert.Equal(t, "a", fused.Result[1].ID)
+}
+
+func TestSearchCodeIndexModeContext(t *testing.T) {
+	vectors := map[string]string{
+		"code_index_named": `{"code":{"size":2,"distance":"Cosine"},"summary":{"size":2,"distance":"Cosine"}}`,
+		"code_index_plain": `{"size":2,"distance":"Cosine"}`,
+	}
+	var searched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.Split(strings.TrimPrefix(r.URL.Path, "/collections/"), "/")[0]
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"result":{"config":{"params":{"vectors":` + vectors[name] + `}}}}`))
+			return
+		}
+		var body struct {
+			Vector json.RawMessage `json:"vector"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		var named struct {
+			Name string `json:"name"`
+		}
+		json.Unmarshal(body.Vector, &named)
+		searched = append(searched, name+":"+named.Name)
+		w.Write([]byte(`{"result":[{"id":"` + named.Name + `","score":0.5,"payload":{}}]}`))
+	}))
+	defer server.Close()
+	client := NewQdrantClientWithEmbedding(server.URL, mockEmbeddingFuncFactory(2), 2)
+
+	resp, err := client.SearchCodeIndexModeContext(t.Context(), "code_index_named", VectorModeFused, []float32{1, 0}, 5, nil)
+	require.NoError(t, err)
+	assert.Len(t, resp.Result, 2)
+
+	_, err = client.SearchCodeIndexModeContext(t.Context(), "code_index_named", VectorModeSummary, []float32{1, 0}, 5, nil)
+	require.NoError(t, err)
+
+	_, err = client.SearchCodeIndexModeContext(t.Context(), "code_index_plain", VectorModeSummary, []float32{1, 0}, 5, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"code_index_named:code", "code_index_named:summary",
+		"code_index_named:summary",
+		"code_index_plain:",
+	}, searched, "legacy collections are searched without a vector name, and layouts are cached")
+}
+
+func TestCodeIndexPointVectors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":[
+			{"id":"named","vector":{"code":[1,0],"summary":[0,1]}},
+			{"id":"plain","vector":[0.5,0.5]}
+		]}`))
+	}))
+	defer server.Close()
+	client := NewQdrantClientWithEmbedding(server.URL, mockEmbeddingFuncFactory(2), 2)
+
+	vectors, err := client.CodeIndexPointVectors(t.Context(), "code_index", []string{"named", "plain", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]StoredVectors{
+		"named": {Code: []float32{1, 0}, Summary: []float32{0, 1}},
+		"plain": {Code: []float32{0.5, 0.5}},
+	}, vectors)
+}
+
+func TestScanCodeIndexPoints(t *testing.T) {
+	var filters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/collections/missing/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body struct {
+			Filter json.RawMessage `json:"filter"`
+			Offset interface{}     `json:"offset"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		filters = append(filters, string(body.Filter))
+		if body.Offset == nil {
+			w.Write([]byte(`{"result":{"points":[{"id":"named","vector":{"code":[1,0],"summary":[0,1]},"payload":{"folderId":"f"}}],"next_page_offset":"plain"}}`))
+			return
+		}
+		w.Write([]byte(`{"result":{"points":[{"id":"plain","vector":[0.5,0.5],"payload":{"folderId":"f"}}],"next_page_offset":null}}`))
+	}))
+	defer server.Close()
+	client := NewQdrantClientWithEmbedding(server.URL, mockEmbeddingFuncFactory(2), 2)
+
+	filter := map[string]interface{}{"must": []interface{}{map[string]interface{}{"key": "folderId", "match": map[string]interface{}{"value": "f"}}}}
+	var points []CodeIndexPoint
+	err := client.ScanCodeIndexPoints(t.Context(), "code_index", filter, func(point CodeIndexPoint) error {
+		points = append(points, point)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.Equal(t, CodeIndexPoint{ID: "named", Vector: []float32{1, 0}, SummaryVector: []float32{0, 1}, Payload: map[string]interface{}{"folderId": "f"}}, points[0])
+	assert.Equal(t, []float32{0.5, 0.5}, points[1].Vector)
+	assert.Len(t, filters, 2)
+	assert.Contains(t, filters[1], "folderId", "every page is filtered")
+
+	assert.NoError(t, client.ScanCodeIndexPoints(t.Context(), "missing", nil, func(CodeIndexPoint) error {
+		t.Fatal("a missing collection has no points")
+		return nil
+	}))
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ContextKnowledgeStorage is implemented by knowledge storages whose queries
+// honor the caller's deadline and priority (see package priority)
+type ContextKnowledgeStorage interface {
+	// QueryContext searches a collection like Query, restricted to entries
+	// of language when it is set
+	QueryContext(ctx context.Context, collection, query, language string, limit int) ([]*QueryResult, error)
+}
+
+// contextSearcher is implemented by Qdrant clients whose similarity searches
+// honor the caller's deadline and priority
+type contextSearcher interface {
+	SearchSimilarContext(ctx context.Context, collectionName, query, language string, limit int) ([]*QdrantQueryResult, error)
+}
+
+// QueryKnowledgeContext queries store with ctx when it supports it, and
+// falls back to Query otherwise
+func QueryKnowledgeContext(ctx context.Context, store KnowledgeStorage, collection, query string, limit int) ([]*QueryResult, error) {
+	if contextStore, ok := store.(ContextKnowledgeStorage); ok {
+		return contextStore.QueryContext(ctx, collection, query, "", limit)
+	}
+	return store.Query(collection, query, limit)
+}
+
+// maxTimeFor returns the server-side time limit (maxTimeMS) matching ctx's
+// deadline, so MongoDB stops work nobody waits for anymore; nil without a
+// deadline. An expired deadline yields 1ms, failing the operation at once.
+func maxTimeFor(ctx context.Context) *time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	remaining := time.Until(deadline)
+	if remaining < time.Millisecond {
+		remaining = time.Millisecond
+	}
+	return &remaining
+}
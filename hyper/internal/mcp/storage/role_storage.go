@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// RoleAssignment binds a user to an RBAC role
+type RoleAssignment struct {
+	UserID     string    `bson:"_id" json:"userId"`
+	Role       string    `bson:"role" json:"role"`
+	AssignedBy string    `bson:"assignedBy,omitempty" json:"assignedBy,omitempty"`
+	UpdatedAt  time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// RoleStorage handles persistence of user role assignments
+type RoleStorage struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+// NewRoleStorage creates a new role storage
+func NewRoleStorage(db *mongo.Database, logger *zap.Logger) *RoleStorage {
+	return &RoleStorage{
+		collection: db.Collection(CollectionName("user_roles")),
+		logger:     logger,
+	}
+}
+
+// GetUserRole returns the role assigned to a user, or an empty string if none
+func (s *RoleStorage) GetUserRole(userID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var assignment RoleAssignment
+	err := s.collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&assignment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get role for user %s: %w", userID, err)
+	}
+
+	return assignment.Role, nil
+}
+
+// SetUserRole creates or replaces the role assignment for a user
+func (s *RoleStorage) SetUserRole(userID, role, assignedBy string) (*RoleAssignment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assignment := &RoleAssignment{
+		UserID:     userID,
+		Role:       role,
+		AssignedBy: assignedBy,
+		UpdatedAt:  time.Now().UTC(),
+	}
+
+	_, err := s.collection.ReplaceOne(ctx, bson.M{"_id": userID}, assignment, options.Replace().SetUpsert(true))
+	if err != nil {
+		s.logger.Error("Failed to set user role", zap.String("userId", userID), zap.Error(err))
+		return nil, fmt.Errorf("failed to set role for user %s: %w", userID, err)
+	}
+
+	s.logger.Info("User role assigned",
+		zap.String("userId", userID),
+		zap.String("role", role),
+		zap.String("assignedBy", assignedBy))
+
+	return assignment, nil
+}
+
+// DeleteUserRole removes the role assignment for a user
+func (s *RoleStorage) DeleteUserRole(userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": userID})
+	if err != nil {
+		return fmt.Errorf("failed to delete role for user %s: %w", userID, err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("no role assignment found for user: %s", userID)
+	}
+
+	return nil
+}
+
+// ListRoleAssignments returns all stored role assignments
+func (s *RoleStorage) ListRoleAssignments() ([]*RoleAssignment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	assignments := []*RoleAssignment{}
+	if err := cursor.All(ctx, &assignments); err != nil {
+		return nil, fmt.Errorf("failed to decode role assignments: %w", err)
+	}
+
+	return assignments, nil
+}
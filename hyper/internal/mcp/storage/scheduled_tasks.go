@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// ScheduledTaskTemplate is the agent task a schedule creates on each run
+type ScheduledTaskTemplate struct {
+	HumanTaskID       string          `bson:"humanTaskId,omitempty" json:"humanTaskId,omitempty"`             // Parent of every run's agent task; empty creates a human task per run
+	Prompt            string          `bson:"prompt,omitempty" json:"prompt,omitempty"`                       // Prompt of the human task created per run
+	AgentName         string          `bson:"agentName" json:"agentName"`                                     // Agent the task is assigned to
+	Role              string          `bson:"role" json:"role"`                                               // What the agent does
+	Todos             []TodoItemInput `bson:"todos" json:"todos"`                                             // TODOs of every run
+	ContextSummary    string          `bson:"contextSummary,omitempty" json:"contextSummary,omitempty"`       // Context given to the agent
+	QdrantCollections []string        `bson:"qdrantCollections,omitempty" json:"qdrantCollections,omitempty"` // Knowledge collections to consult
+	PriorWorkSummary  string          `bson:"priorWorkSummary,omitempty" json:"priorWorkSummary,omitempty"`   // Work the agent builds on
+}
+
+// ScheduledTask creates an agent task from its template whenever its cron
+// expression matches
+type ScheduledTask struct {
+	Name       string                `bson:"_id" json:"name"`
+	Cron       string                `bson:"cron" json:"cron"`                                 // Five-field cron expression or @daily style shorthand
+	Timezone   string                `bson:"timezone" json:"timezone"`                         // IANA zone the expression is read in (default UTC)
+	Template   ScheduledTaskTemplate `bson:"template" json:"template"`                         // Agent task created on each run
+	Enabled    bool                  `bson:"enabled" json:"enabled"`                           // Disabled schedules are kept but not run
+	NextRunAt  time.Time             `bson:"nextRunAt" json:"nextRunAt"`                       // When the next run is due
+	CreatedAt  time.Time             `bson:"createdAt" json:"createdAt"`                       // First saved
+	UpdatedAt  time.Time             `bson:"updatedAt" json:"updatedAt"`                       // Last configuration change
+	RunCount   int                   `bson:"runCount" json:"runCount"`                         // Runs that created a task
+	LastRunAt  *time.Time            `bson:"lastRunAt,omitempty" json:"lastRunAt,omitempty"`   // Last run, successful or not
+	LastTaskID string                `bson:"lastTaskId,omitempty" json:"lastTaskId,omitempty"` // Agent task created by the last successful run
+	LastError  string                `bson:"lastError,omitempty" json:"lastError,omitempty"`   // Error of the last failed run
+}
+
+// ValidateScheduledTask checks a schedule's name and template and fills in
+// defaults. Cron expressions are parsed by the schedule package before
+// schedules are saved.
+func ValidateScheduledTask(task *ScheduledTask) error {
+	task.Name = strings.TrimSpace(task.Name)
+	if task.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(task.Cron) == "" {
+		return fmt.Errorf("cron is required")
+	}
+	if task.Timezone == "" {
+		task.Timezone = "UTC"
+	}
+
+	template := &task.Template
+	if strings.TrimSpace(template.AgentName) == "" {
+		return fmt.Errorf("template.agentName is required")
+	}
+	if strings.TrimSpace(template.Role) == "" {
+		return fmt.Errorf("template.role is required")
+	}
+	if len(template.Todos) == 0 {
+		return fmt.Errorf("template.todos must have at least one TODO")
+	}
+	for i, todo := range template.Todos {
+		if strings.TrimSpace(todo.Description) == "" {
+			return fmt.Errorf("template.todos[%d].description is required", i)
+		}
+	}
+	if template.HumanTaskID == "" && strings.TrimSpace(template.Prompt) == "" {
+		template.Prompt = "Scheduled task: " + task.Name
+	}
+	return nil
+}
+
+// ScheduledTaskStorage handles persistence of scheduled tasks
+type ScheduledTaskStorage struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+// NewScheduledTaskStorage creates a new scheduled task storage
+func NewScheduledTaskStorage(db *mongo.Database, logger *zap.Logger) *ScheduledTaskStorage {
+	return &ScheduledTaskStorage{
+		collection: db.Collection(CollectionName("scheduled_tasks")),
+		logger:     logger,
+	}
+}
+
+// GetScheduledTask returns a schedule by name, or nil if it does not exist
+func (s *ScheduledTaskStorage) GetScheduledTask(name string) (*ScheduledTask, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var task ScheduledTask
+	err := s.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&task)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get scheduled task %s: %w", name, err)
+	}
+	return &task, nil
+}
+
+// SetScheduledTask creates or replaces a schedule, keeping its creation
+// time and run count. NextRunAt must already be computed.
+func (s *ScheduledTaskStorage) SetScheduledTask(task *ScheduledTask) error {
+	if err := ValidateScheduledTask(task); err != nil {
+		return err
+	}
+
+	existing, err := s.GetScheduledTask(task.Name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	task.CreatedAt = now
+	if existing != nil {
+		task.CreatedAt = existing.CreatedAt
+		task.RunCount = existing.RunCount
+		task.LastRunAt, task.LastTaskID, task.LastError = existing.LastRunAt, existing.LastTaskID, existing.LastError
+	}
+	task.UpdatedAt = now
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": task.Name}, task, options.Replace().SetUpsert(true)); err != nil {
+		s.logger.Error("Failed to save scheduled task", zap.String("name", task.Name), zap.Error(err))
+		return fmt.Errorf("failed to save scheduled task %s: %w", task.Name, err)
+	}
+
+	s.logger.Info("Scheduled task saved",
+		zap.String("name", task.Name),
+		zap.String("cron", task.Cron),
+		zap.Time("nextRunAt", task.NextRunAt),
+		zap.Bool("enabled", task.Enabled))
+	return nil
+}
+
+// ListScheduledTasks returns all schedules sorted by name
+func (s *ScheduledTaskStorage) ListScheduledTasks() ([]*ScheduledTask, error) {
+	return s.find(bson.M{})
+}
+
+// DueScheduledTasks returns the enabled schedules whose next run is at or
+// before now
+func (s *ScheduledTaskStorage) DueScheduledTasks(now time.Time) ([]*ScheduledTask, error) {
+	return s.find(bson.M{"enabled": true, "nextRunAt": bson.M{"$lte": now}})
+}
+
+func (s *ScheduledTaskStorage) find(filter bson.M) ([]*ScheduledTask, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled tasks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	tasks := []*ScheduledTask{}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to decode scheduled tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// DeleteScheduledTask removes a schedule
+func (s *ScheduledTaskStorage) DeleteScheduledTask(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return fmt.Errorf("failed to delete scheduled task %s: %w", name, err)
+	}
+	if result.DeletedCount == 0 {
+		return errcode.New(errcode.NotFound, "scheduled task not found: %s", name)
+	}
+	return nil
+}
+
+// ClaimScheduledRun moves a schedule's next run from due to next. It
+// returns false when another coordinator already claimed the run, or the
+// schedule changed since it was read, so each run creates one task.
+func (s *ScheduledTaskStorage) ClaimScheduledRun(name string, due, next time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": name, "enabled": true, "nextRunAt": due},
+		bson.M{"$set": bson.M{"nextRunAt": next}})
+	if err != nil {
+		return false, fmt.Errorf("failed to claim run of scheduled task %s: %w", name, err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// RecordScheduledRun records a run; a nil runErr counts the run, stores the
+// created task and clears the last error
+func (s *ScheduledTaskStorage) RecordScheduledRun(name string, at time.Time, taskID string, runErr error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set":   bson.M{"lastRunAt": at, "lastTaskId": taskID},
+		"$inc":   bson.M{"runCount": 1},
+		"$unset": bson.M{"lastError": ""},
+	}
+	if runErr != nil {
+		update = bson.M{"$set": bson.M{"lastRunAt": at, "lastError": runErr.Error()}}
+	}
+
+	if _, err := s.collection.UpdateOne(ctx, bson.M{"_id": name}, update); err != nil {
+		return fmt.Errorf("failed to record run of scheduled task %s: %w", name, err)
+	}
+	return nil
+}
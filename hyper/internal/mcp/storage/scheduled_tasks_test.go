@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateScheduledTask(t *testing.T) {
+	template := ScheduledTaskTemplate{AgentName: "indexer", Role: "Re-index", Todos: []TodoItemInput{{Description: "Scan folders"}}}
+
+	task := &ScheduledTask{Name: " nightly-reindex ", Cron: "0 2 * * *", Template: template}
+	require.NoError(t, ValidateScheduledTask(task))
+	assert.Equal(t, "nightly-reindex", task.Name)
+	assert.Equal(t, "UTC", task.Timezone)
+	assert.Equal(t, "Scheduled task: nightly-reindex", task.Template.Prompt)
+
+	withParent := template
+	withParent.HumanTaskID = "h1"
+	task = &ScheduledTask{Name: "x", Cron: "@daily", Template: withParent}
+	require.NoError(t, ValidateScheduledTask(task))
+	assert.Empty(t, task.Template.Prompt, "runs under an existing human task need no prompt")
+
+	assert.ErrorContains(t, ValidateScheduledTask(&ScheduledTask{Cron: "@daily", Template: template}), "name is required")
+	assert.ErrorContains(t, ValidateScheduledTask(&ScheduledTask{Name: "x", Template: template}), "cron is required")
+	assert.ErrorContains(t, ValidateScheduledTask(&ScheduledTask{Name: "x", Cron: "@daily", Template: ScheduledTaskTemplate{AgentName: "a", Role: "r"}}), "template.todos")
+	assert.ErrorContains(t, ValidateScheduledTask(&ScheduledTask{Name: "x", Cron: "@daily", Template: ScheduledTaskTemplate{AgentName: "a", Role: "r", Todos: []TodoItemInput{{}}}}), "template.todos[0].description")
+}
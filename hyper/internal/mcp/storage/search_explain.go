@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"hyper/internal/priority"
+)
+
+// explainRankDepth is how many better-scoring points are counted to rank an
+// explained point; points ranked deeper report Rank 0
+const explainRankDepth = 100
+
+// PointExplanation reports how one point scores against a search, to tell why
+// it was or wasn't returned
+type PointExplanation struct {
+	ID           string                 `json:"id"`
+	Found        bool                   `json:"found"`                 // The point is stored in the collection
+	Similarity   float64                `json:"similarity"`            // Cosine similarity to the query, ignoring filters
+	Filter       map[string]interface{} `json:"filter,omitempty"`      // Payload filter the search applies
+	PassesFilter bool                   `json:"passesFilter"`          // The point matches Filter (true without one)
+	Rank         int                    `json:"rank"`                  // 1-based position among filtered hits; 0 if deeper than RankDepth
+	RankDepth    int                    `json:"rankDepth"`             // How deep Rank was looked for
+	CutoffScore  float64                `json:"cutoffScore,omitempty"` // Score of the last hit within the limit, when the limit is full
+	Vector       string                 `json:"vector,omitempty"`      // Named vector the point was scored on, for collections with named vectors
+	Payload      map[string]interface{} `json:"payload,omitempty"`     // Stored metadata of the point
+}
+
+// Returned reports whether a search with limit results returns the point
+func (e *PointExplanation) Returned(limit int) bool {
+	return e.Found && e.PassesFilter && e.Rank > 0 && e.Rank <= limit
+}
+
+// searchPoints runs a raw Qdrant search and returns its hits
+func (c *QdrantClient) searchPoints(ctx context.Context, collectionName string, request map[string]interface{}) ([]QdrantSearchResult, error) {
+	var response struct {
+		Result []QdrantSearchResult `json:"result"`
+	}
+	if err := c.qdrantJSON(ctx, http.MethodPost, c.collectionURL(collectionName)+"/points/search", request, &response); err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	return response.Result, nil
+}
+
+// withCondition returns a filter requiring condition and, when set, filter
+func withCondition(filter map[string]interface{}, condition map[string]interface{}) map[string]interface{} {
+	must := []map[string]interface{}{condition}
+	if filter != nil {
+		must = append(must, filter)
+	}
+	return map[string]interface{}{"must": must}
+}
+
+// explainPoint scores point id against vector: its unfiltered similarity,
+// whether it passes filter, its rank among the filtered hits, and the score a
+// point needs to make the top limit
+func (c *QdrantClient) explainPoint(ctx context.Context, collectionName string, vector interface{}, filter map[string]interface{}, id string, limit int) (*PointExplanation, error) {
+	done, err := priority.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	explanation := &PointExplanation{ID: id, Filter: filter, RankDepth: explainRankDepth}
+	byID := map[string]interface{}{"has_id": []string{id}}
+
+	hits, err := c.searchPoints(ctx, collectionName, map[string]interface{}{
+		"vector":       vector,
+		"limit":        1,
+		"with_payload": true,
+		"filter":       withCondition(nil, byID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return explanation, nil
+	}
+	explanation.Found = true
+	explanation.Similarity = hits[0].Score
+	explanation.Payload = hits[0].Payload
+
+	explanation.PassesFilter = true
+	if filter != nil {
+		hits, err = c.searchPoints(ctx, collectionName, map[string]interface{}{
+			"vector": vector,
+			"limit":  1,
+			"filter": withCondition(filter, byID),
+		})
+		if err != nil {
+			return nil, err
+		}
+		explanation.PassesFilter = len(hits) > 0
+	}
+
+	// Every filtered hit scoring at least as well ranks ahead of the point
+	rankRequest := map[string]interface{}{
+		"vector":          vector,
+		"limit":           explainRankDepth,
+		"score_threshold": explanation.Similarity,
+	}
+	if filter != nil {
+		rankRequest["filter"] = filter
+	}
+	if hits, err = c.searchPoints(ctx, collectionName, rankRequest); err != nil {
+		return nil, err
+	}
+	ahead, counted := 0, len(hits) < explainRankDepth
+	for _, hit := range hits {
+		if hit.ID == id {
+			counted = true
+		} else {
+			ahead++
+		}
+	}
+	if counted {
+		explanation.Rank = ahead + 1
+	}
+
+	if limit > 0 {
+		cutoffRequest := map[string]interface{}{"vector": vector, "limit": limit}
+		if filter != nil {
+			cutoffRequest["filter"] = filter
+		}
+		if hits, err = c.searchPoints(ctx, collectionName, cutoffRequest); err != nil {
+			return nil, err
+		}
+		if len(hits) == limit {
+			explanation.CutoffScore = hits[len(hits)-1].Score
+		}
+	}
+
+	return explanation, nil
+}
+
+// ExplainKnowledgePoint explains how knowledge point id scores for query in a
+// knowledge_find search restricted to language, returning limit results
+func (c *QdrantClient) ExplainKnowledgePoint(ctx context.Context, collectionName, query, language, id string, limit int) (*PointExplanation, error) {
+	vector, filter, err := c.embedQuery(query, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+	return c.explainPoint(ctx, collectionName, vector, filter, id, limit)
+}
+
+// ExplainCodeIndexPoint explains how code chunk point id scores for a query
+// vector in a code search with mode (see SearchCodeIndexModeContext) applying
+// filter and returning limit results. A fused search keeps each point's
+// better score, so the point is explained on the vector it scores higher on.
+func (c *QdrantClient) ExplainCodeIndexPoint(ctx context.Context, collectionName, mode string, vector []float32, filter map[string]interface{}, id string, limit int) (*PointExplanation, error) {
+	named, err := c.UsesNamedVectors(ctx, collectionName)
+	if err != nil {
+		return nil, err
+	}
+	if !named {
+		return c.explainPoint(ctx, collectionName, vector, filter, id, limit)
+	}
+
+	names := []string{CodeVectorName}
+	switch mode {
+	case VectorModeSummary:
+		names = []string{SummaryVectorName}
+	case VectorModeFused:
+		names = []string{CodeVectorName, SummaryVectorName}
+	}
+	var best *PointExplanation
+	for _, name := range names {
+		explanation, err := c.explainPoint(ctx, collectionName, map[string]interface{}{"name": name, "vector": vector}, filter, id, limit)
+		if err != nil {
+			return nil, err
+		}
+		explanation.Vector = name
+		if best == nil || (explanation.Found && explanation.Similarity > best.Similarity) {
+			best = explanation
+		}
+	}
+	return best, nil
+}
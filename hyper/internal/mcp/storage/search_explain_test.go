@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSearchPoint is a point of fakeSearchQdrant
+type fakeSearchPoint struct {
+	id      string
+	vector  []float64
+	payload map[string]interface{}
+}
+
+// fakeSearchQdrant scores points by dot product and understands has_id,
+// match and nested must filters
+type fakeSearchQdrant struct {
+	points []fakeSearchPoint
+}
+
+func (f *fakeSearchQdrant) matches(point fakeSearchPoint, filter map[string]interface{}) bool {
+	if filter == nil {
+		return true
+	}
+	if ids, ok := filter["has_id"].([]interface{}); ok {
+		for _, id := range ids {
+			if id == point.id {
+				return true
+			}
+		}
+		return false
+	}
+	if key, ok := filter["key"].(string); ok {
+		return point.payload[key] == filter["match"].(map[string]interface{})["value"]
+	}
+	for _, condition := range filter["must"].([]interface{}) {
+		if !f.matches(point, condition.(map[string]interface{})) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *fakeSearchQdrant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		w.Write([]byte(`{"result":{"config":{"params":{"vectors":{"size":2,"distance":"Cosine"}}}}}`))
+		return
+	}
+	var request struct {
+		Vector         []float64              `json:"vector"`
+		Limit          int                    `json:"limit"`
+		ScoreThreshold *float64               `json:"score_threshold"`
+		Filter         map[string]interface{} `json:"filter"`
+	}
+	json.NewDecoder(r.Body).Decode(&request)
+
+	hits := []QdrantSearchResult{}
+	for _, point := range f.points {
+		if !f.matches(point, request.Filter) {
+			continue
+		}
+		score := 0.0
+		for i := range point.vector {
+			score += point.vector[i] * request.Vector[i]
+		}
+		if request.ScoreThreshold != nil && score < *request.ScoreThreshold {
+			continue
+		}
+		hits = append(hits, QdrantSearchResult{ID: point.id, Score: score, Payload: point.payload})
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > request.Limit {
+		hits = hits[:request.Limit]
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"result": hits})
+}
+
+func TestExplainCodeIndexPoint(t *testing.T) {
+	fake := &fakeSearchQdrant{points: []fakeSearchPoint{
+		{id: "a", vector: []float64{0.9, 0.1}, payload: map[string]interface{}{"filePath": "/a.go", CommentLanguageKey: "en"}},
+		{id: "b", vector: []float64{0.8, 0.2}, payload: map[string]interface{}{"filePath": "/b.go", CommentLanguageKey: "de"}},
+		{id: "c", vector: []float64{0.5, 0.5}, payload: map[string]interface{}{"filePath": "/c.go", CommentLanguageKey: "en"}},
+	}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client := NewQdrantClientWithEmbedding(server.URL, mockEmbeddingFuncFactory(2), 2)
+	query := []float32{1, 0}
+
+	explanation, err := client.ExplainCodeIndexPoint(t.Context(), "code_index", VectorModeFused, query, nil, "c", 2)
+	require.NoError(t, err)
+	assert.True(t, explanation.Found)
+	assert.InDelta(t, 0.5, explanation.Similarity, 1e-6)
+	assert.True(t, explanation.PassesFilter)
+	assert.Equal(t, 3, explanation.Rank)
+	assert.InDelta(t, 0.8, explanation.CutoffScore, 1e-6)
+	assert.Equal(t, "/c.go", explanation.Payload["filePath"])
+	assert.False(t, explanation.Returned(2))
+
+	english := map[string]interface{}{
+		"must": []map[string]interface{}{{"key": CommentLanguageKey, "match": map[string]interface{}{"value": "en"}}},
+	}
+	explanation, err = client.ExplainCodeIndexPoint(t.Context(), "code_index", VectorModeFused, query, english, "c", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, explanation.Rank, "filtered-out points do not rank ahead")
+	assert.True(t, explanation.Returned(2))
+
+	explanation, err = client.ExplainCodeIndexPoint(t.Context(), "code_index", VectorModeFused, query, english, "b", 2)
+	require.NoError(t, err)
+	assert.True(t, explanation.Found)
+	assert.False(t, explanation.PassesFilter)
+	assert.False(t, explanation.Returned(2))
+
+	explanation, err = client.ExplainCodeIndexPoint(t.Context(), "code_index", VectorModeFused, query, nil, "missing", 2)
+	require.NoError(t, err)
+	assert.False(t, explanation.Found)
+}
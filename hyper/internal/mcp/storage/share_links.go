@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// ShareLink grants read-only access to a human task and its progress to
+// anyone holding its signed URL, until it expires or is revoked
+type ShareLink struct {
+	ID           string     `bson:"_id" json:"id"`
+	HumanTaskID  string     `bson:"humanTaskId" json:"humanTaskId"`
+	CreatedBy    string     `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	CreatedAt    time.Time  `bson:"createdAt" json:"createdAt"`
+	ExpiresAt    time.Time  `bson:"expiresAt" json:"expiresAt"`
+	RevokedAt    *time.Time `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	Views        int        `bson:"views" json:"views"`
+	LastViewedAt *time.Time `bson:"lastViewedAt,omitempty" json:"lastViewedAt,omitempty"`
+}
+
+// Active reports whether the link can still be opened at now
+func (l *ShareLink) Active(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// ShareLinkStorage handles persistence of task share links
+type ShareLinkStorage struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+// NewShareLinkStorage creates a new share link storage
+func NewShareLinkStorage(db *mongo.Database, logger *zap.Logger) *ShareLinkStorage {
+	return &ShareLinkStorage{
+		collection: db.Collection(CollectionName("share_links")),
+		logger:     logger,
+	}
+}
+
+// CreateShareLink creates a link to a human task that expires at expiresAt
+func (s *ShareLinkStorage) CreateShareLink(humanTaskID, createdBy string, expiresAt time.Time) (*ShareLink, error) {
+	link := &ShareLink{
+		ID:          uuid.New().String(),
+		HumanTaskID: humanTaskID,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   expiresAt.UTC(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.collection.InsertOne(ctx, link); err != nil {
+		return nil, fmt.Errorf("failed to create share link for task %s: %w", humanTaskID, err)
+	}
+
+	s.logger.Info("Share link created",
+		zap.String("id", link.ID),
+		zap.String("humanTaskId", humanTaskID),
+		zap.Time("expiresAt", link.ExpiresAt))
+	return link, nil
+}
+
+// GetShareLink returns a link by ID, or nil if it does not exist
+func (s *ShareLinkStorage) GetShareLink(id string) (*ShareLink, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var link ShareLink
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get share link %s: %w", id, err)
+	}
+	return &link, nil
+}
+
+// ListShareLinks returns the links of a human task, newest first
+func (s *ShareLinkStorage) ListShareLinks(humanTaskID string) ([]*ShareLink, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{"humanTaskId": humanTaskID}, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links for task %s: %w", humanTaskID, err)
+	}
+	defer cursor.Close(ctx)
+
+	links := []*ShareLink{}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, fmt.Errorf("failed to decode share links: %w", err)
+	}
+	return links, nil
+}
+
+// RevokeShareLink stops a link from opening. Revoking a revoked link keeps
+// its original revocation time. It returns nil if the link does not exist.
+func (s *ShareLinkStorage) RevokeShareLink(id string) (*ShareLink, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": now}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke share link %s: %w", id, err)
+	}
+
+	s.logger.Info("Share link revoked", zap.String("id", id))
+	return s.GetShareLink(id)
+}
+
+// RecordShareLinkView counts an opening of a link
+func (s *ShareLinkStorage) RecordShareLinkView(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$inc": bson.M{"views": 1}, "$set": bson.M{"lastViewedAt": time.Now().UTC()}})
+	if err != nil {
+		return fmt.Errorf("failed to record view of share link %s: %w", id, err)
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"hyper/internal/errcode"
+
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -34,12 +36,16 @@ type Subchat struct {
 
 // Subagent represents an available specialist agent
 type Subagent struct {
-	ID           string   `bson:"_id" json:"id"`
-	Name         string   `bson:"name" json:"name"`
-	Description  string   `bson:"description" json:"description"`
-	SystemPrompt string   `bson:"systemPrompt" json:"systemPrompt"`
-	Tools        []string `bson:"tools,omitempty" json:"tools,omitempty"`
-	Category     string   `bson:"category,omitempty" json:"category,omitempty"`
+	ID                   string     `bson:"_id" json:"id"`
+	Name                 string     `bson:"name" json:"name"`
+	Description          string     `bson:"description" json:"description"`
+	SystemPrompt         string     `bson:"systemPrompt" json:"systemPrompt"`
+	Persona              string     `bson:"persona,omitempty" json:"persona,omitempty"` // Markdown persona document injected alongside the system prompt
+	Tools                []string   `bson:"tools,omitempty" json:"tools,omitempty"`
+	Category             string     `bson:"category,omitempty" json:"category,omitempty"`
+	PersonaUpdatedAt     *time.Time `bson:"personaUpdatedAt,omitempty" json:"personaUpdatedAt,omitempty"`
+	BootstrapCollections []string   `bson:"bootstrapCollections,omitempty" json:"bootstrapCollections,omitempty"` // Knowledge returned inline when the agent claims a task
+	BootstrapLimit       int        `bson:"bootstrapLimit,omitempty" json:"bootstrapLimit,omitempty"`             // Entries returned; 0 means DefaultBootstrapLimit
 }
 
 // SubchatStorage handles subchat persistence
@@ -52,8 +58,8 @@ type SubchatStorage struct {
 // NewSubchatStorage creates a new subchat storage
 func NewSubchatStorage(db *mongo.Database, logger *zap.Logger) *SubchatStorage {
 	return &SubchatStorage{
-		collection:        db.Collection("subchats"),
-		subagentCollection: db.Collection("subagents"),
+		collection:        db.Collection(CollectionName("subchats")),
+		subagentCollection: db.Collection(CollectionName("subagents")),
 		logger:            logger,
 	}
 }
@@ -97,7 +103,7 @@ func (s *SubchatStorage) GetSubchat(id string) (*Subchat, error) {
 	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&subchat)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("subchat not found: %s", id)
+			return nil, errcode.New(errcode.NotFound, "subchat not found: %s", id)
 		}
 		s.logger.Error("Failed to get subchat", zap.String("id", id), zap.Error(err))
 		return nil, fmt.Errorf("failed to get subchat: %w", err)
@@ -148,7 +154,7 @@ func (s *SubchatStorage) UpdateSubchatStatus(id string, status SubchatStatus) er
 	}
 
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("subchat not found: %s", id)
+		return errcode.New(errcode.NotFound, "subchat not found: %s", id)
 	}
 
 	s.logger.Info("Updated subchat status",
@@ -188,7 +194,7 @@ func (s *SubchatStorage) GetSubagent(name string) (*Subagent, error) {
 	err := s.subagentCollection.FindOne(ctx, bson.M{"name": name}).Decode(&subagent)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("subagent not found: %s", name)
+			return nil, errcode.New(errcode.NotFound, "subagent not found: %s", name)
 		}
 		s.logger.Error("Failed to get subagent", zap.String("name", name), zap.Error(err))
 		return nil, fmt.Errorf("failed to get subagent: %w", err)
@@ -218,7 +224,7 @@ func (s *SubchatStorage) UpdateSubchatAgentTask(subchatID string, agentTaskID *s
 	}
 
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("subchat not found: %s", subchatID)
+		return errcode.New(errcode.NotFound, "subchat not found: %s", subchatID)
 	}
 
 	s.logger.Info("Updated subchat with agent task",
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxTaskActivityEntries bounds the activity log kept per agent task; older entries are dropped
+const maxTaskActivityEntries = 1000
+
+// ActivityAction identifies the kind of change recorded in an agent task's activity log
+type ActivityAction string
+
+const (
+	ActivityCreated                ActivityAction = "created"
+	ActivityStatusChanged          ActivityAction = "status_changed"
+	ActivityTodoStatusChanged      ActivityAction = "todo_status_changed"
+	ActivityChecklistStatusChanged ActivityAction = "checklist_status_changed"
+	ActivityTodoTimeUpdated        ActivityAction = "todo_time_updated"
+	ActivityPromptNotesAdded       ActivityAction = "prompt_notes_added"
+	ActivityPromptNotesUpdated     ActivityAction = "prompt_notes_updated"
+	ActivityPromptNotesCleared     ActivityAction = "prompt_notes_cleared"
+	ActivityTodoPromptNotesAdded   ActivityAction = "todo_prompt_notes_added"
+	ActivityTodoPromptNotesUpdated ActivityAction = "todo_prompt_notes_updated"
+	ActivityTodoPromptNotesCleared ActivityAction = "todo_prompt_notes_cleared"
+)
+
+// TaskActivity is one entry of an agent task's activity log. Entries are
+// appended in the same write as the change they describe, so the log never
+// misses a change that was applied.
+type TaskActivity struct {
+	At               time.Time      `json:"at" bson:"at"`
+	Action           ActivityAction `json:"action" bson:"action"`
+	TodoID           string         `json:"todoId,omitempty" bson:"todoId,omitempty"`
+	ChecklistItemID  string         `json:"checklistItemId,omitempty" bson:"checklistItemId,omitempty"`
+	PreviousStatus   string         `json:"previousStatus,omitempty" bson:"previousStatus,omitempty"`
+	Status           string         `json:"status,omitempty" bson:"status,omitempty"`
+	Notes            string         `json:"notes,omitempty" bson:"notes,omitempty"`
+	EstimatedMinutes *int           `json:"estimatedMinutes,omitempty" bson:"estimatedMinutes,omitempty"`
+	ActualMinutes    *int           `json:"actualMinutes,omitempty" bson:"actualMinutes,omitempty"`
+}
+
+// pushActivity returns the $push clause that appends entry to the activity log,
+// keeping only the most recent maxTaskActivityEntries entries
+func pushActivity(entry TaskActivity) bson.M {
+	return bson.M{
+		"activity": bson.M{
+			"$each":  []TaskActivity{entry},
+			"$slice": -maxTaskActivityEntries,
+		},
+	}
+}
+
+// GetAgentTaskActivity returns the activity log of an agent task, oldest first
+func (s *MongoTaskStorage) GetAgentTaskActivity(agentTaskID string) ([]TaskActivity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var doc struct {
+		Activity []TaskActivity `bson:"activity"`
+	}
+	opts := options.FindOne().SetProjection(bson.M{"activity": 1})
+	err := s.agentTasksCollection.FindOne(ctx, bson.M{"taskId": agentTaskID}, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errcode.New(errcode.NotFound, "agent task with ID %s not found", agentTaskID)
+		}
+		return nil, fmt.Errorf("failed to retrieve agent task activity: %w", err)
+	}
+
+	if doc.Activity == nil {
+		return []TaskActivity{}, nil
+	}
+	if err := s.cipher.OpenAll(activitySecrets(doc.Activity)); err != nil {
+		return nil, err
+	}
+	return doc.Activity, nil
+}
@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPushActivity(t *testing.T) {
+	entry := TaskActivity{At: time.Now().UTC(), Action: ActivityStatusChanged, Status: string(TaskStatusCompleted)}
+
+	push := pushActivity(entry)
+
+	clause, ok := push["activity"].(bson.M)
+	if !ok {
+		t.Fatalf("expected activity clause, got %#v", push)
+	}
+	entries, ok := clause["$each"].([]TaskActivity)
+	if !ok || len(entries) != 1 || entries[0] != entry {
+		t.Errorf("expected $each to hold the single entry, got %#v", clause["$each"])
+	}
+	if clause["$slice"] != -maxTaskActivityEntries {
+		t.Errorf("expected $slice %d, got %v", -maxTaskActivityEntries, clause["$slice"])
+	}
+}
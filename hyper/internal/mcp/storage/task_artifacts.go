@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+	"hyper/internal/objectstore"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// ArtifactMaxBytesEnv sets the largest artifact accepted, in bytes
+const ArtifactMaxBytesEnv = "ARTIFACT_MAX_BYTES"
+
+// defaultArtifactMaxBytes fits build logs, coverage reports and screenshots
+const defaultArtifactMaxBytes = 50 << 20
+
+// maxArtifactNameLength bounds artifact file names
+const maxArtifactNameLength = 255
+
+// TaskArtifact describes a file attached to an agent task, such as a build
+// log, coverage report or screenshot. The content is stored separately in an
+// object store and read with TaskArtifactStorage.Open.
+type TaskArtifact struct {
+	ID             string    `bson:"_id" json:"id"`
+	AgentTaskID    string    `bson:"agentTaskId" json:"agentTaskId"`
+	Name           string    `bson:"name" json:"name"`                                   // File name, e.g. coverage.html
+	ContentType    string    `bson:"contentType" json:"contentType"`                     // MIME type, detected when not given
+	Size           int64     `bson:"size" json:"size"`                                   // Bytes
+	SHA256         string    `bson:"sha256" json:"sha256"`                               // Hex digest of the content
+	Description    string    `bson:"description,omitempty" json:"description,omitempty"` // What the artifact shows
+	CreatedAt      time.Time `bson:"createdAt" json:"createdAt"`
+	StorageBackend string    `bson:"storageBackend,omitempty" json:"-"` // Object store holding the content; empty is GridFS
+	StorageKey     string    `bson:"storageKey,omitempty" json:"-"`     // Object key of the content; empty is the artifact ID
+}
+
+// ArtifactMaxBytes returns the largest artifact accepted: ARTIFACT_MAX_BYTES
+// when set to a positive number, otherwise 50 MiB
+func ArtifactMaxBytes() int64 {
+	if raw := os.Getenv(ArtifactMaxBytesEnv); raw != "" {
+		if value, err := strconv.ParseInt(raw, 10, 64); err == nil && value > 0 {
+			return value
+		}
+	}
+	return defaultArtifactMaxBytes
+}
+
+// ValidateArtifactName checks an artifact file name and returns it trimmed.
+// Names are plain file names so downloads cannot write outside a directory.
+func ValidateArtifactName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("artifact name is required")
+	}
+	if len(name) > maxArtifactNameLength {
+		return "", fmt.Errorf("artifact name must be at most %d bytes", maxArtifactNameLength)
+	}
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("artifact name must be a file name without directories, got %q", name)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return "", fmt.Errorf("artifact name must not contain control characters")
+		}
+	}
+	return name, nil
+}
+
+// artifactContentType returns the declared content type, else the type of the
+// name's extension, else the type sniffed from the first bytes of content
+func artifactContentType(declared, name string, head []byte) string {
+	if declared = strings.TrimSpace(declared); declared != "" {
+		return declared
+	}
+	if byExtension := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); byExtension != "" {
+		return byExtension
+	}
+	return http.DetectContentType(head)
+}
+
+// TaskArtifactStorage stores agent task artifacts: metadata in a collection
+// and content in the object store selected by OBJECT_STORAGE (GridFS by
+// default), so files of any size stay out of task documents
+type TaskArtifactStorage struct {
+	collection *mongo.Collection
+	store      objectstore.Store            // Receives new artifacts
+	stores     map[string]objectstore.Store // Serves artifacts by the backend they were stored in
+	logger     *zap.Logger
+}
+
+// NewTaskArtifactStorage creates a new task artifact storage. Artifacts
+// stored in GridFS stay readable after switching OBJECT_STORAGE to s3.
+func NewTaskArtifactStorage(db *mongo.Database, logger *zap.Logger) (*TaskArtifactStorage, error) {
+	gridFS, err := objectstore.NewGridFSStore(db, CollectionName("task_artifact_files"))
+	if err != nil {
+		return nil, err
+	}
+	s := &TaskArtifactStorage{
+		collection: db.Collection(CollectionName("task_artifacts")),
+		store:      gridFS,
+		stores:     map[string]objectstore.Store{objectstore.BackendGridFS: gridFS},
+		logger:     logger,
+	}
+
+	backend, err := objectstore.BackendFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if backend == objectstore.BackendS3 {
+		cfg, err := objectstore.S3ConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		s3, err := objectstore.NewS3Store(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.store = s3
+		s.stores[objectstore.BackendS3] = s3
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "agentTaskId", Value: 1}, {Key: "createdAt", Value: 1}},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create artifact index: %w", err)
+	}
+
+	logger.Info("Task artifact storage initialized", zap.String("backend", s.store.Backend()))
+	return s, nil
+}
+
+// Save stores content as a new artifact of an agent task. The artifact's ID,
+// size, digest and creation time are filled in, and its content type is
+// detected when empty. Content above ArtifactMaxBytes is rejected.
+func (s *TaskArtifactStorage) Save(ctx context.Context, artifact *TaskArtifact, content io.Reader) error {
+	if artifact.AgentTaskID == "" {
+		return fmt.Errorf("agentTaskId is required")
+	}
+	name, err := ValidateArtifactName(artifact.Name)
+	if err != nil {
+		return err
+	}
+	artifact.Name = name
+
+	reader := bufio.NewReader(content)
+	head, _ := reader.Peek(512)
+	artifact.ContentType = artifactContentType(artifact.ContentType, name, head)
+	artifact.ID = uuid.New().String()
+	artifact.CreatedAt = time.Now().UTC()
+	artifact.StorageBackend = s.store.Backend()
+	artifact.StorageKey = CollectionName("task_artifacts") + "/" + artifact.AgentTaskID + "/" + artifact.ID
+
+	maxBytes := ArtifactMaxBytes()
+	hash := sha256.New()
+	var size byteCounter
+	limited := io.TeeReader(io.LimitReader(reader, maxBytes+1), io.MultiWriter(hash, &size))
+
+	if err := s.store.Put(ctx, artifact.StorageKey, limited, -1, artifact.ContentType); err != nil {
+		return fmt.Errorf("failed to store artifact content: %w", err)
+	}
+	if int64(size) > maxBytes {
+		s.deleteContent(ctx, artifact)
+		return fmt.Errorf("artifact exceeds the %d byte limit (%s)", maxBytes, ArtifactMaxBytesEnv)
+	}
+
+	artifact.Size = int64(size)
+	artifact.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	if _, err := s.collection.InsertOne(ctx, artifact); err != nil {
+		// Do not leave content behind that no artifact refers to
+		s.deleteContent(ctx, artifact)
+		return fmt.Errorf("failed to save artifact: %w", err)
+	}
+
+	s.logger.Info("Task artifact stored",
+		zap.String("artifactId", artifact.ID),
+		zap.String("agentTaskId", artifact.AgentTaskID),
+		zap.String("name", name),
+		zap.Int64("size", artifact.Size),
+		zap.String("backend", artifact.StorageBackend))
+	return nil
+}
+
+// deleteContent removes the content of an artifact that is not kept
+func (s *TaskArtifactStorage) deleteContent(ctx context.Context, artifact *TaskArtifact) {
+	if err := s.store.Delete(ctx, artifact.StorageKey); err != nil {
+		s.logger.Warn("Failed to remove content of unsaved artifact", zap.String("artifactId", artifact.ID), zap.Error(err))
+	}
+}
+
+// byteCounter counts the bytes written to it
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// List returns the artifacts of an agent task, oldest first
+func (s *TaskArtifactStorage) List(ctx context.Context, agentTaskID string) ([]*TaskArtifact, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{"agentTaskId": agentTaskID},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifacts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	artifacts := []*TaskArtifact{}
+	if err := cursor.All(ctx, &artifacts); err != nil {
+		return nil, fmt.Errorf("failed to decode artifacts: %w", err)
+	}
+	return artifacts, nil
+}
+
+// Get returns an artifact of an agent task
+func (s *TaskArtifactStorage) Get(ctx context.Context, agentTaskID, artifactID string) (*TaskArtifact, error) {
+	var artifact TaskArtifact
+	err := s.collection.FindOne(ctx, bson.M{"_id": artifactID, "agentTaskId": agentTaskID}).Decode(&artifact)
+	if err == mongo.ErrNoDocuments {
+		return nil, errcode.New(errcode.NotFound, "artifact %s not found for agent task %s", artifactID, agentTaskID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artifact: %w", err)
+	}
+	return &artifact, nil
+}
+
+// Open returns an artifact of an agent task and a reader of its content,
+// which the caller must close
+func (s *TaskArtifactStorage) Open(ctx context.Context, agentTaskID, artifactID string) (*TaskArtifact, io.ReadCloser, error) {
+	artifact, err := s.Get(ctx, agentTaskID, artifactID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Artifacts from before the object store abstraction have no storage fields
+	backend, key := artifact.StorageBackend, artifact.StorageKey
+	if backend == "" {
+		backend = objectstore.BackendGridFS
+	}
+	if key == "" {
+		key = artifact.ID
+	}
+	store, ok := s.stores[backend]
+	if !ok {
+		return nil, nil, fmt.Errorf("artifact %s is stored in %s, which is unavailable with %s=%s", artifact.ID, backend, objectstore.BackendEnv, s.store.Backend())
+	}
+
+	content, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open artifact content: %w", err)
+	}
+	return artifact, content, nil
+}
+
+// DownloadPath returns the REST path serving the artifact's content
+func (a *TaskArtifact) DownloadPath() string {
+	return fmt.Sprintf("/api/v1/agent-tasks/%s/artifacts/%s", a.AgentTaskID, a.ID)
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateArtifactName(t *testing.T) {
+	name, err := ValidateArtifactName("  coverage.html ")
+	if err != nil || name != "coverage.html" {
+		t.Errorf("ValidateArtifactName = %q, %v; want coverage.html", name, err)
+	}
+
+	for _, invalid := range []string{"", "  ", "../etc/passwd", `logs\build.log`, "..", "build\n.log", strings.Repeat("a", 256)} {
+		if _, err := ValidateArtifactName(invalid); err == nil {
+			t.Errorf("ValidateArtifactName(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestArtifactContentType(t *testing.T) {
+	tests := []struct {
+		declared, name string
+		head           []byte
+		want           string
+	}{
+		{"text/x-log", "build.log", nil, "text/x-log"},
+		{"", "coverage.html", nil, "text/html; charset=utf-8"},
+		{"", "screenshot", []byte("\x89PNG\r\n\x1a\n"), "image/png"},
+		{"", "output", []byte("plain text"), "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		if got := artifactContentType(tt.declared, tt.name, tt.head); got != tt.want {
+			t.Errorf("artifactContentType(%q, %q) = %q, want %q", tt.declared, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestArtifactMaxBytes(t *testing.T) {
+	t.Setenv(ArtifactMaxBytesEnv, "")
+	if got := ArtifactMaxBytes(); got != defaultArtifactMaxBytes {
+		t.Errorf("default limit = %d, want %d", got, defaultArtifactMaxBytes)
+	}
+
+	t.Setenv(ArtifactMaxBytesEnv, "1048576")
+	if got := ArtifactMaxBytes(); got != 1<<20 {
+		t.Errorf("limit = %d, want %d", got, 1<<20)
+	}
+
+	t.Setenv(ArtifactMaxBytesEnv, "-1")
+	if got := ArtifactMaxBytes(); got != defaultArtifactMaxBytes {
+		t.Errorf("negative limit = %d, want default", got)
+	}
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TaskAutomationStorage is implemented by task storages that support the
+// changes automation hooks make
+type TaskAutomationStorage interface {
+	AddTaskTags(taskID string, tags []string) error
+	AssignAgentTask(taskID, agentName string) error
+}
+
+// AddTaskTags adds tags to any task (human or agent); existing tags are kept
+func (s *MongoTaskStorage) AddTaskTags(taskID string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$addToSet": bson.M{"tags": bson.M{"$each": tags}},
+		"$set":      bson.M{"updatedAt": time.Now().UTC()},
+	}
+	for _, collection := range []*mongo.Collection{s.humanTasksCollection, s.agentTasksCollection} {
+		result, err := collection.UpdateOne(ctx, bson.M{"taskId": taskID}, update)
+		if err != nil {
+			return fmt.Errorf("failed to tag task: %w", err)
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+	}
+	return errcode.New(errcode.NotFound, "task with ID %s not found", taskID)
+}
+
+// RemoveTaskTags removes tags from any task (human or agent); tags it does
+// not have are ignored
+func (s *MongoTaskStorage) RemoveTaskTags(taskID string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$pull": bson.M{"tags": bson.M{"$in": tags}},
+		"$set":  bson.M{"updatedAt": time.Now().UTC()},
+	}
+	for _, collection := range []*mongo.Collection{s.humanTasksCollection, s.agentTasksCollection} {
+		result, err := collection.UpdateOne(ctx, bson.M{"taskId": taskID}, update)
+		if err != nil {
+			return fmt.Errorf("failed to untag task: %w", err)
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+	}
+	return errcode.New(errcode.NotFound, "task with ID %s not found", taskID)
+}
+
+// AssignAgentTask hands an agent task to another agent
+func (s *MongoTaskStorage) AssignAgentTask(taskID, agentName string) error {
+	agentName = strings.TrimSpace(agentName)
+	if agentName == "" {
+		return fmt.Errorf("agent name is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	var previous AgentTask
+	err := s.agentTasksCollection.FindOneAndUpdate(ctx,
+		bson.M{"taskId": taskID},
+		bson.M{"$set": bson.M{"agentName": agentName, "updatedAt": now}},
+		options.FindOneAndUpdate().SetProjection(bson.M{"humanTaskId": 1, "agentName": 1, "status": 1})).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return errcode.New(errcode.NotFound, "agent task with ID %s not found", taskID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to assign agent task: %w", err)
+	}
+	if previous.AgentName != agentName {
+		s.publish(TaskEvent{
+			Type:              TaskEventAgentTaskAssigned,
+			At:                now,
+			TaskID:            taskID,
+			Kind:              "agent",
+			HumanTaskID:       previous.HumanTaskID,
+			AgentName:         agentName,
+			PreviousAgentName: previous.AgentName,
+			Status:            string(previous.Status),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TaskDeletionRetention is how long deleted task IDs are kept for board
+// deltas; a client syncing from further back reloads the whole board
+const TaskDeletionRetention = 7 * 24 * time.Hour
+
+// BoardClearedID is the TaskDeletion.TaskID recorded when the whole board is cleared
+const BoardClearedID = "*"
+
+// TaskDeletion records a deleted task, so board deltas can report removals
+type TaskDeletion struct {
+	TaskID    string    `json:"id" bson:"taskId"`
+	Kind      string    `json:"kind" bson:"kind"` // human, agent, or board for ClearAllTasks
+	DeletedAt time.Time `json:"deletedAt" bson:"deletedAt"`
+}
+
+// TaskBoardStorage is implemented by task storages that serve the task board
+// without loading whole tasks
+type TaskBoardStorage interface {
+	// BoardTasks returns the tasks updated at or after since (every task
+	// when since is zero), with only the fields board cards show
+	BoardTasks(ctx context.Context, since time.Time) ([]*HumanTask, []*AgentTask, error)
+	// TaskDeletionsSince returns the tasks deleted at or after since
+	TaskDeletionsSince(ctx context.Context, since time.Time) ([]TaskDeletion, error)
+}
+
+// BoardStorageOf returns the TaskBoardStorage of tasks. Storages that wrap
+// another to add side effects to writes (hooks, issue trackers,
+// notifications) expose it through Unwrap, so the board still reads from the
+// underlying storage.
+func BoardStorageOf(tasks TaskStorage) (TaskBoardStorage, bool) {
+	for tasks != nil {
+		if board, ok := tasks.(TaskBoardStorage); ok {
+			return board, true
+		}
+		wrapper, ok := tasks.(interface{ Unwrap() TaskStorage })
+		if !ok {
+			return nil, false
+		}
+		tasks = wrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// boardHumanFields and boardAgentFields are the fields board cards show
+var (
+	boardHumanFields = bson.M{"taskId": 1, "prompt": 1, "status": 1, "project": 1, "createdAt": 1, "updatedAt": 1, "dueAt": 1, "tags": 1, "priority": 1}
+	boardAgentFields = bson.M{"taskId": 1, "humanTaskId": 1, "agentName": 1, "role": 1, "status": 1, "todos.status": 1, "createdAt": 1, "updatedAt": 1, "dueAt": 1, "tags": 1, "priority": 1, "inheritedPriority": 1}
+)
+
+// ensureBoardIndexes indexes task updates for board deltas, and expires
+// deletion records after TaskDeletionRetention
+func (s *MongoTaskStorage) ensureBoardIndexes(ctx context.Context) error {
+	for _, collection := range []*mongo.Collection{s.humanTasksCollection, s.agentTasksCollection} {
+		if _, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "updatedAt", Value: 1}},
+		}); err != nil {
+			return fmt.Errorf("failed to create updatedAt index: %w", err)
+		}
+	}
+	_, err := s.deletionsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "deletedAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(TaskDeletionRetention / time.Second)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create task deletions index: %w", err)
+	}
+	return nil
+}
+
+// BoardTasks returns the tasks updated at or after since, with only the
+// fields board cards show
+func (s *MongoTaskStorage) BoardTasks(ctx context.Context, since time.Time) ([]*HumanTask, []*AgentTask, error) {
+	filter := bson.M{}
+	if !since.IsZero() {
+		filter["updatedAt"] = bson.M{"$gte": since.UTC()}
+	}
+
+	cursor, err := s.humanTasksCollection.Find(ctx, filter, options.Find().SetProjection(boardHumanFields))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list human tasks: %w", err)
+	}
+	var humans []*HumanTask
+	if err := cursor.All(ctx, &humans); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode human tasks: %w", err)
+	}
+	for _, task := range humans {
+		// A field that fails to decrypt stays sealed rather than hiding the task
+		s.cipher.OpenAll(humanTaskSecrets(task))
+	}
+
+	cursor, err = s.agentTasksCollection.Find(ctx, filter, options.Find().SetProjection(boardAgentFields))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list agent tasks: %w", err)
+	}
+	var agents []*AgentTask
+	if err := cursor.All(ctx, &agents); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode agent tasks: %w", err)
+	}
+	return humans, agents, nil
+}
+
+// TaskDeletionsSince returns the tasks deleted at or after since
+func (s *MongoTaskStorage) TaskDeletionsSince(ctx context.Context, since time.Time) ([]TaskDeletion, error) {
+	cursor, err := s.deletionsCollection.Find(ctx,
+		bson.M{"deletedAt": bson.M{"$gte": since.UTC()}},
+		options.Find().SetSort(bson.D{{Key: "deletedAt", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task deletions: %w", err)
+	}
+	var deletions []TaskDeletion
+	if err := cursor.All(ctx, &deletions); err != nil {
+		return nil, fmt.Errorf("failed to decode task deletions: %w", err)
+	}
+	return deletions, nil
+}
+
+// recordDeletions notes deleted tasks for board deltas. Failing to record
+// them is not an error of the deletion: the tasks are gone either way.
+func (s *MongoTaskStorage) recordDeletions(ctx context.Context, kind string, ids []string) {
+	if s.deletionsCollection == nil || len(ids) == 0 {
+		return
+	}
+	now := time.Now().UTC()
+	docs := make([]interface{}, len(ids))
+	for i, id := range ids {
+		docs[i] = TaskDeletion{TaskID: id, Kind: kind, DeletedAt: now}
+	}
+	_, _ = s.deletionsCollection.InsertMany(ctx, docs)
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RecentTasksPerFile caps the agent tasks listed on each code search hit
+const RecentTasksPerFile = 3
+
+// TaskReference is a compact view of an agent task attached to code search
+// results, linking a file to the tasks that declared it in filesModified
+type TaskReference struct {
+	AgentTaskID string     `json:"agentTaskId"`
+	HumanTaskID string     `json:"humanTaskId"`
+	AgentName   string     `json:"agentName"`
+	Role        string     `json:"role"`
+	Status      TaskStatus `json:"status"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// DeclaresPath reports whether any declared entry matches the given file.
+// Entries may be absolute paths, paths relative to the indexed folder (matched
+// as a path suffix), directories (trailing slash or prefix), or glob patterns.
+func DeclaresPath(declared []string, path, relativePath string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	relativePath = filepath.ToSlash(relativePath)
+
+	for _, entry := range declared {
+		entry = strings.TrimSpace(filepath.ToSlash(entry))
+		if entry == "" {
+			continue
+		}
+
+		cleaned := strings.TrimSuffix(filepath.ToSlash(filepath.Clean(entry)), "/")
+		cleaned = strings.TrimPrefix(cleaned, "./")
+
+		if path == cleaned || relativePath == cleaned || strings.HasSuffix(path, "/"+cleaned) {
+			return true
+		}
+
+		// Directory entries cover all files beneath them
+		if strings.HasPrefix(path, cleaned+"/") || strings.Contains(path, "/"+cleaned+"/") {
+			return true
+		}
+
+		if strings.ContainsAny(entry, "*?[") {
+			if matched, _ := filepath.Match(cleaned, relativePath); matched {
+				return true
+			}
+			if matched, _ := filepath.Match(cleaned, path); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// RecentTasksForFile returns up to limit agent tasks that declared the file in
+// filesModified, most recently updated first
+func RecentTasksForFile(tasks []*AgentTask, path, relativePath string, limit int) []TaskReference {
+	var refs []TaskReference
+	for _, task := range tasks {
+		if !DeclaresPath(task.FilesModified, path, relativePath) {
+			continue
+		}
+		refs = append(refs, TaskReference{
+			AgentTaskID: task.ID,
+			HumanTaskID: task.HumanTaskID,
+			AgentName:   task.AgentName,
+			Role:        task.Role,
+			Status:      task.Status,
+			UpdatedAt:   task.UpdatedAt,
+		})
+	}
+
+	sort.SliceStable(refs, func(i, j int) bool {
+		return refs[i].UpdatedAt.After(refs[j].UpdatedAt)
+	})
+	if limit > 0 && len(refs) > limit {
+		refs = refs[:limit]
+	}
+	return refs
+}
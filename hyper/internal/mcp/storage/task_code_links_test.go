@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeclaresPath(t *testing.T) {
+	path := "/workspace/repo/hyper/internal/mcp/watcher/file_watcher.go"
+	rel := "hyper/internal/mcp/watcher/file_watcher.go"
+
+	tests := []struct {
+		name     string
+		declared []string
+		want     bool
+	}{
+		{"absolute path", []string{path}, true},
+		{"relative path", []string{rel}, true},
+		{"path suffix", []string{"internal/mcp/watcher/file_watcher.go"}, true},
+		{"directory with trailing slash", []string{"hyper/internal/mcp/watcher/"}, true},
+		{"directory prefix", []string{"/workspace/repo/hyper"}, true},
+		{"glob pattern", []string{"hyper/internal/mcp/watcher/*.go"}, true},
+		{"different file", []string{"hyper/internal/mcp/watcher/path_mapper.go"}, false},
+		{"partial name is not a match", []string{"watcher.go"}, false},
+		{"empty entries", []string{"", "  "}, false},
+		{"no declarations", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeclaresPath(tt.declared, path, rel); got != tt.want {
+				t.Fatalf("DeclaresPath(%v) = %v, want %v", tt.declared, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecentTasksForFile(t *testing.T) {
+	path := "/repo/internal/api/rest_handler.go"
+	rel := "internal/api/rest_handler.go"
+	now := time.Now()
+
+	tasks := []*AgentTask{
+		{ID: "old", AgentName: "go-dev", FilesModified: []string{rel}, UpdatedAt: now.Add(-2 * time.Hour)},
+		{ID: "unrelated", AgentName: "go-dev", FilesModified: []string{"internal/mcp/"}, UpdatedAt: now},
+		{ID: "new", AgentName: "api-dev", FilesModified: []string{"internal/api/"}, UpdatedAt: now.Add(-time.Minute)},
+		{ID: "middle", AgentName: "go-dev", FilesModified: []string{"internal/api/*.go"}, UpdatedAt: now.Add(-time.Hour)},
+	}
+
+	refs := RecentTasksForFile(tasks, path, rel, 2)
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(refs))
+	}
+	if refs[0].AgentTaskID != "new" || refs[1].AgentTaskID != "middle" {
+		t.Errorf("unexpected order: %s, %s", refs[0].AgentTaskID, refs[1].AgentTaskID)
+	}
+
+	if refs := RecentTasksForFile(tasks, "/repo/README.md", "README.md", 3); len(refs) != 0 {
+		t.Errorf("expected no tasks for undeclared file, got %d", len(refs))
+	}
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SetFieldCipher enables encryption at rest of task prompts and notes. Tasks
+// written before encryption was enabled stay readable and are sealed by
+// RotateFieldEncryption.
+func (s *MongoTaskStorage) SetFieldCipher(cipher *FieldCipher) {
+	s.cipher = cipher
+}
+
+// sealHumanTask returns a copy of task with its secret fields sealed for storage
+func (s *MongoTaskStorage) sealHumanTask(task *HumanTask) (*HumanTask, error) {
+	doc := *task
+	if err := s.cipher.SealAll(humanTaskSecrets(&doc)); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// sealAgentTask returns a copy of task with its secret fields sealed for
+// storage, leaving the caller's TODOs and activity untouched
+func (s *MongoTaskStorage) sealAgentTask(task *AgentTask) (*AgentTask, error) {
+	doc := *task
+	doc.Todos = make([]TodoItem, len(task.Todos))
+	for i, todo := range task.Todos {
+		doc.Todos[i] = todo
+		doc.Todos[i].Checklist = append([]ChecklistItem(nil), todo.Checklist...)
+	}
+	doc.Activity = append([]TaskActivity(nil), task.Activity...)
+	if err := s.cipher.SealAll(agentTaskSecrets(&doc)); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// RotateFieldEncryption re-seals every task field that is plaintext or sealed
+// with a retired key under the active key. A task modified during the pass is
+// skipped and picked up by the next run.
+func (s *MongoTaskStorage) RotateFieldEncryption(ctx context.Context) (*FieldRotationResult, error) {
+	if s.cipher == nil {
+		return nil, fmt.Errorf("field encryption is not configured")
+	}
+	result := &FieldRotationResult{}
+
+	humanCursor, err := s.humanTasksCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query human tasks: %w", err)
+	}
+	defer humanCursor.Close(ctx)
+	for humanCursor.Next(ctx) {
+		var task HumanTask
+		if err := humanCursor.Decode(&task); err != nil {
+			return nil, fmt.Errorf("failed to decode human task: %w", err)
+		}
+		result.Scanned++
+		changed, err := s.cipher.Reseal(humanTaskSecrets(&task))
+		if err != nil {
+			return nil, fmt.Errorf("human task %s: %w", task.ID, err)
+		}
+		if !changed {
+			continue
+		}
+		set := bson.M{"prompt": task.Prompt}
+		if task.Notes != "" {
+			set["notes"] = task.Notes
+		}
+		if err := result.record(s.humanTasksCollection.UpdateOne(ctx,
+			bson.M{"taskId": task.ID, "updatedAt": task.UpdatedAt}, bson.M{"$set": set})); err != nil {
+			return nil, fmt.Errorf("failed to rotate human task %s: %w", task.ID, err)
+		}
+	}
+	if err := humanCursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate human tasks: %w", err)
+	}
+
+	agentCursor, err := s.agentTasksCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent tasks: %w", err)
+	}
+	defer agentCursor.Close(ctx)
+	for agentCursor.Next(ctx) {
+		var task AgentTask
+		if err := agentCursor.Decode(&task); err != nil {
+			return nil, fmt.Errorf("failed to decode agent task: %w", err)
+		}
+		result.Scanned++
+		changed, err := s.cipher.Reseal(agentTaskSecrets(&task))
+		if err != nil {
+			return nil, fmt.Errorf("agent task %s: %w", task.ID, err)
+		}
+		if !changed {
+			continue
+		}
+		set := bson.M{"todos": task.Todos, "activity": task.Activity}
+		for field, value := range map[string]string{
+			"notes":            task.Notes,
+			"contextSummary":   task.ContextSummary,
+			"priorWorkSummary": task.PriorWorkSummary,
+			"humanPromptNotes": task.HumanPromptNotes,
+		} {
+			if value != "" {
+				set[field] = value
+			}
+		}
+		if err := result.record(s.agentTasksCollection.UpdateOne(ctx,
+			bson.M{"taskId": task.ID, "updatedAt": task.UpdatedAt}, bson.M{"$set": set})); err != nil {
+			return nil, fmt.Errorf("failed to rotate agent task %s: %w", task.ID, err)
+		}
+	}
+	if err := agentCursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate agent tasks: %w", err)
+	}
+
+	return result, nil
+}
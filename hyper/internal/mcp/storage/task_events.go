@@ -0,0 +1,83 @@
+package storage
+
+import "time"
+
+// TaskEventType names a change to the task board
+type TaskEventType string
+
+const (
+	TaskEventHumanTaskCreated      TaskEventType = "human_task_created"
+	TaskEventAgentTaskCreated      TaskEventType = "agent_task_created"
+	TaskEventStatusChanged         TaskEventType = "task_status_changed"
+	TaskEventTodoStatusChanged     TaskEventType = "todo_status_changed"
+	TaskEventChecklistStatusChange TaskEventType = "checklist_item_status_changed"
+	TaskEventAgentTaskAssigned     TaskEventType = "agent_task_assigned"
+	TaskEventBoardCleared          TaskEventType = "board_cleared"
+)
+
+// TaskEventTypes lists every task event type
+var TaskEventTypes = []TaskEventType{
+	TaskEventHumanTaskCreated,
+	TaskEventAgentTaskCreated,
+	TaskEventStatusChanged,
+	TaskEventTodoStatusChanged,
+	TaskEventChecklistStatusChange,
+	TaskEventAgentTaskAssigned,
+	TaskEventBoardCleared,
+}
+
+// TaskEvent is a change MongoTaskStorage made. It identifies the task and the
+// new state, without prompts or notes: clients fetch the task for those.
+type TaskEvent struct {
+	Type              TaskEventType `json:"type"`
+	At                time.Time     `json:"at"`
+	TaskID            string        `json:"taskId,omitempty"`
+	Kind              string        `json:"kind,omitempty"`        // human or agent
+	HumanTaskID       string        `json:"humanTaskId,omitempty"` // Of agent tasks
+	AgentName         string        `json:"agentName,omitempty"`
+	PreviousAgentName string        `json:"previousAgentName,omitempty"` // agent_task_assigned
+	TodoID            string        `json:"todoId,omitempty"`
+	ChecklistItemID   string        `json:"checklistItemId,omitempty"`
+	Status            string        `json:"status,omitempty"`
+	PreviousStatus    string        `json:"previousStatus,omitempty"` // Equals status when only the notes changed
+}
+
+// TaskEventPublisher receives the changes MongoTaskStorage makes, after they
+// are stored. PublishTaskEvent must not block.
+type TaskEventPublisher interface {
+	PublishTaskEvent(event TaskEvent)
+}
+
+// SetEventPublisher makes the storage publish task creations, status and TODO
+// changes and assignments to publisher
+func (s *MongoTaskStorage) SetEventPublisher(publisher TaskEventPublisher) {
+	s.events = publisher
+}
+
+// publish hands event to the publisher, if any
+func (s *MongoTaskStorage) publish(event TaskEvent) {
+	if s.events == nil {
+		return
+	}
+	if event.At.IsZero() {
+		event.At = time.Now().UTC()
+	}
+	s.events.PublishTaskEvent(event)
+}
+
+// humanTaskCreatedEvent and agentTaskCreatedEvent describe new tasks
+func humanTaskCreatedEvent(task *HumanTask) TaskEvent {
+	return TaskEvent{Type: TaskEventHumanTaskCreated, At: task.CreatedAt, TaskID: task.ID, Kind: "human", Status: string(task.Status)}
+}
+
+func agentTaskCreatedEvent(task *AgentTask) TaskEvent {
+	return TaskEvent{
+		Type:        TaskEventAgentTaskCreated,
+		At:          task.CreatedAt,
+		TaskID:      task.ID,
+		Kind:        "agent",
+		HumanTaskID: task.HumanTaskID,
+		AgentName:   task.AgentName,
+		Status:      string(task.Status),
+	}
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// JiraLinkStorage is implemented by task storages that can link human tasks
+// to Jira issues
+type JiraLinkStorage interface {
+	SetJiraIssueKey(taskID, issueKey string) error
+	GetHumanTaskByJiraKey(issueKey string) (*HumanTask, error)
+}
+
+// SetJiraIssueKey stores the Jira issue linked to a human task
+func (s *MongoTaskStorage) SetJiraIssueKey(taskID, issueKey string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.humanTasksCollection.UpdateOne(ctx,
+		bson.M{"taskId": taskID},
+		bson.M{"$set": bson.M{"jiraIssueKey": issueKey}})
+	if err != nil {
+		return fmt.Errorf("failed to link human task %s to Jira: %w", taskID, err)
+	}
+	if result.MatchedCount == 0 {
+		return errcode.New(errcode.NotFound, "human task with ID %s not found", taskID)
+	}
+	return nil
+}
+
+// GetHumanTaskByJiraKey returns the human task linked to a Jira issue, or nil
+// if no task is linked
+func (s *MongoTaskStorage) GetHumanTaskByJiraKey(issueKey string) (*HumanTask, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var task HumanTask
+	err := s.humanTasksCollection.FindOne(ctx, bson.M{"jiraIssueKey": issueKey}).Decode(&task)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find human task for Jira issue %s: %w", issueKey, err)
+	}
+	if err := s.cipher.OpenAll(humanTaskSecrets(&task)); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
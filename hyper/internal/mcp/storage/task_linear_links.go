@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// LinearLinkStorage is implemented by task storages that can link human tasks
+// to Linear issues
+type LinearLinkStorage interface {
+	SetLinearIssue(taskID, issueID, issueKey string) error
+	GetHumanTaskByLinearIssue(issueID string) (*HumanTask, error)
+}
+
+// SetLinearIssue stores the Linear issue linked to a human task
+func (s *MongoTaskStorage) SetLinearIssue(taskID, issueID, issueKey string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.humanTasksCollection.UpdateOne(ctx,
+		bson.M{"taskId": taskID},
+		bson.M{"$set": bson.M{"linearIssueId": issueID, "linearIssueKey": issueKey}})
+	if err != nil {
+		return fmt.Errorf("failed to link human task %s to Linear: %w", taskID, err)
+	}
+	if result.MatchedCount == 0 {
+		return errcode.New(errcode.NotFound, "human task with ID %s not found", taskID)
+	}
+	return nil
+}
+
+// GetHumanTaskByLinearIssue returns the human task linked to a Linear issue,
+// or nil if no task is linked
+func (s *MongoTaskStorage) GetHumanTaskByLinearIssue(issueID string) (*HumanTask, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var task HumanTask
+	err := s.humanTasksCollection.FindOne(ctx, bson.M{"linearIssueId": issueID}).Decode(&task)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find human task for Linear issue %s: %w", issueID, err)
+	}
+	if err := s.cipher.OpenAll(humanTaskSecrets(&task)); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
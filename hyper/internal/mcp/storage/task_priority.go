@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TaskPriority is how urgent a task is
+type TaskPriority string
+
+const (
+	PriorityUnset    TaskPriority = ""
+	PriorityLow      TaskPriority = "low"
+	PriorityMedium   TaskPriority = "medium"
+	PriorityHigh     TaskPriority = "high"
+	PriorityCritical TaskPriority = "critical"
+)
+
+// priorityTagPrefix marks priority labels among task tags, e.g. "priority:high"
+const priorityTagPrefix = "priority:"
+
+// priorityRanks orders priorities; tasks without one rank as medium
+var priorityRanks = map[TaskPriority]int{
+	PriorityLow:      1,
+	PriorityUnset:    2,
+	PriorityMedium:   2,
+	PriorityHigh:     3,
+	PriorityCritical: 4,
+}
+
+// ParseTaskPriority returns the priority named by s (case-insensitive); an
+// empty s is PriorityUnset
+func ParseTaskPriority(s string) (TaskPriority, error) {
+	priority := TaskPriority(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := priorityRanks[priority]; !ok {
+		return PriorityUnset, fmt.Errorf("invalid priority %q: must be low, medium, high or critical", s)
+	}
+	return priority, nil
+}
+
+// Rank orders priorities from low (1) to critical (4)
+func (p TaskPriority) Rank() int {
+	return priorityRanks[p]
+}
+
+// Higher returns the more urgent of p and other; an unset other never raises p
+func (p TaskPriority) Higher(other TaskPriority) TaskPriority {
+	if other != PriorityUnset && other.Rank() > p.Rank() {
+		return other
+	}
+	return p
+}
+
+// labelPriority returns the priority set explicitly, or else the one labelled
+// by a "priority:<level>" tag
+func labelPriority(priority TaskPriority, tags []string) TaskPriority {
+	if priority != PriorityUnset {
+		return priority
+	}
+	for _, tag := range tags {
+		if level, ok := strings.CutPrefix(strings.ToLower(tag), priorityTagPrefix); ok {
+			if parsed, err := ParseTaskPriority(level); err == nil {
+				return parsed
+			}
+		}
+	}
+	return PriorityUnset
+}
+
+// HumanTaskPriority returns a human task's priority, from its priority field
+// or a priority tag
+func HumanTaskPriority(task *HumanTask) TaskPriority {
+	return labelPriority(task.Priority, task.Tags)
+}
+
+// OwnAgentTaskPriority returns an agent task's own priority, or its human
+// task's when it has none. parent may be nil.
+func OwnAgentTaskPriority(task *AgentTask, parent *HumanTask) TaskPriority {
+	priority := labelPriority(task.Priority, task.Tags)
+	if priority == PriorityUnset && parent != nil {
+		priority = HumanTaskPriority(parent)
+	}
+	return priority
+}
+
+// AgentTaskPriority returns the priority an agent task is worked at: its own
+// raised to any inherited priority. parent may be nil.
+func AgentTaskPriority(task *AgentTask, parent *HumanTask) TaskPriority {
+	return OwnAgentTaskPriority(task, parent).Higher(task.InheritedPriority)
+}
+
+// TaskPriorityStorage is implemented by task storages that store priorities
+// and the state of priority rules
+type TaskPriorityStorage interface {
+	// SetTaskPriority sets the priority of any task (human or agent);
+	// PriorityUnset clears it
+	SetTaskPriority(taskID string, priority TaskPriority) error
+	// SetInheritedPriority raises an agent task to the priority of the human
+	// task from, whose blocked work waits on it; PriorityUnset restores its
+	// own priority
+	SetInheritedPriority(taskID string, priority TaskPriority, from string) error
+	// MarkBlockedEscalated records when a blocked agent task was escalated;
+	// nil clears the mark once it is no longer blocked
+	MarkBlockedEscalated(taskID string, at *time.Time) error
+}
+
+// SetTaskPriority sets the priority of any task (human or agent)
+func (s *MongoTaskStorage) SetTaskPriority(taskID string, priority TaskPriority) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"updatedAt": time.Now().UTC()}}
+	if priority != PriorityUnset {
+		update["$set"].(bson.M)["priority"] = priority
+	} else {
+		update["$unset"] = bson.M{"priority": ""}
+	}
+
+	for _, collection := range []*mongo.Collection{s.humanTasksCollection, s.agentTasksCollection} {
+		result, err := collection.UpdateOne(ctx, bson.M{"taskId": taskID}, update)
+		if err != nil {
+			return fmt.Errorf("failed to set priority: %w", err)
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+	}
+	return errcode.New(errcode.NotFound, "task with ID %s not found", taskID)
+}
+
+// SetInheritedPriority sets or clears the priority an agent task inherits
+func (s *MongoTaskStorage) SetInheritedPriority(taskID string, priority TaskPriority, from string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"updatedAt": time.Now().UTC()}}
+	if priority != PriorityUnset {
+		update["$set"].(bson.M)["inheritedPriority"] = priority
+		update["$set"].(bson.M)["priorityInheritedFrom"] = from
+	} else {
+		update["$unset"] = bson.M{"inheritedPriority": "", "priorityInheritedFrom": ""}
+	}
+
+	result, err := s.agentTasksCollection.UpdateOne(ctx, bson.M{"taskId": taskID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to set inherited priority: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return errcode.New(errcode.NotFound, "agent task with ID %s not found", taskID)
+	}
+	return nil
+}
+
+// MarkBlockedEscalated sets or clears when a blocked agent task was escalated.
+// It is bookkeeping, so updatedAt is left alone.
+func (s *MongoTaskStorage) MarkBlockedEscalated(taskID string, at *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$unset": bson.M{"escalatedAt": ""}}
+	if at != nil {
+		update = bson.M{"$set": bson.M{"escalatedAt": at.UTC()}}
+	}
+
+	result, err := s.agentTasksCollection.UpdateOne(ctx, bson.M{"taskId": taskID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to mark escalation: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return errcode.New(errcode.NotFound, "agent task with ID %s not found", taskID)
+	}
+	return nil
+}
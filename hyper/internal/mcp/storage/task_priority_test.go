@@ -0,0 +1,55 @@
+package storage
+
+import "testing"
+
+func TestParseTaskPriority(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    TaskPriority
+		wantErr bool
+	}{
+		{"high", PriorityHigh, false},
+		{" Critical ", PriorityCritical, false},
+		{"", PriorityUnset, false},
+		{"urgent", PriorityUnset, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTaskPriority(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Fatalf("ParseTaskPriority(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestAgentTaskPriority(t *testing.T) {
+	parent := &HumanTask{Tags: []string{"frontend", "Priority:High"}}
+	if got := HumanTaskPriority(parent); got != PriorityHigh {
+		t.Fatalf("priority tag: got %q, want high", got)
+	}
+
+	task := &AgentTask{}
+	if got := AgentTaskPriority(task, parent); got != PriorityHigh {
+		t.Fatalf("human task's priority: got %q, want high", got)
+	}
+	if got := AgentTaskPriority(task, nil); got != PriorityUnset {
+		t.Fatalf("no priority: got %q, want unset", got)
+	}
+
+	task.Priority = PriorityLow
+	if got := AgentTaskPriority(task, parent); got != PriorityLow {
+		t.Fatalf("own priority: got %q, want low", got)
+	}
+
+	task.InheritedPriority = PriorityCritical
+	if got := AgentTaskPriority(task, parent); got != PriorityCritical {
+		t.Fatalf("inherited priority: got %q, want critical", got)
+	}
+	if got := OwnAgentTaskPriority(task, parent); got != PriorityLow {
+		t.Fatalf("own priority ignores inheritance: got %q, want low", got)
+	}
+
+	if PriorityUnset.Rank() != PriorityMedium.Rank() {
+		t.Fatal("tasks without a priority should rank as medium")
+	}
+}
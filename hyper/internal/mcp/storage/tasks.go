@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"hyper/internal/errcode"
+
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -40,57 +42,99 @@ const (
 
 // TodoItem represents a single trackable subtask within an agent task
 type TodoItem struct {
-	ID           string     `json:"id" bson:"id"`
-	Description  string     `json:"description" bson:"description"`
-	Status       TodoStatus `json:"status" bson:"status"`
-	CreatedAt    time.Time  `json:"createdAt" bson:"createdAt"`
-	CompletedAt  *time.Time `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
-	Notes                     string     `json:"notes,omitempty" bson:"notes,omitempty"`
-	FilePath                  string     `json:"filePath,omitempty" bson:"filePath,omitempty"`
-	FunctionName              string     `json:"functionName,omitempty" bson:"functionName,omitempty"`
-	ContextHint               string     `json:"contextHint,omitempty" bson:"contextHint,omitempty"`
-	HumanPromptNotes          string     `json:"humanPromptNotes,omitempty" bson:"humanPromptNotes,omitempty"`
-	HumanPromptNotesAddedAt   *time.Time `json:"humanPromptNotesAddedAt,omitempty" bson:"humanPromptNotesAddedAt,omitempty"`
-	HumanPromptNotesUpdatedAt *time.Time `json:"humanPromptNotesUpdatedAt,omitempty" bson:"humanPromptNotesUpdatedAt,omitempty"`
+	ID                        string          `json:"id" bson:"id"`
+	Description               string          `json:"description" bson:"description"`
+	Status                    TodoStatus      `json:"status" bson:"status"`
+	CreatedAt                 time.Time       `json:"createdAt" bson:"createdAt"`
+	CompletedAt               *time.Time      `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
+	Notes                     string          `json:"notes,omitempty" bson:"notes,omitempty"`
+	FilePath                  string          `json:"filePath,omitempty" bson:"filePath,omitempty"`
+	FunctionName              string          `json:"functionName,omitempty" bson:"functionName,omitempty"`
+	ContextHint               string          `json:"contextHint,omitempty" bson:"contextHint,omitempty"`
+	HumanPromptNotes          string          `json:"humanPromptNotes,omitempty" bson:"humanPromptNotes,omitempty"`
+	HumanPromptNotesAddedAt   *time.Time      `json:"humanPromptNotesAddedAt,omitempty" bson:"humanPromptNotesAddedAt,omitempty"`
+	HumanPromptNotesUpdatedAt *time.Time      `json:"humanPromptNotesUpdatedAt,omitempty" bson:"humanPromptNotesUpdatedAt,omitempty"`
+	Checklist                 []ChecklistItem `json:"checklist,omitempty" bson:"checklist,omitempty"`               // Nested sub-TODOs; the TODO completes when all are done
+	EstimatedMinutes          int             `json:"estimatedMinutes,omitempty" bson:"estimatedMinutes,omitempty"` // Planned effort; 0 means not estimated
+	ActualMinutes             int             `json:"actualMinutes,omitempty" bson:"actualMinutes,omitempty"`       // Reported effort; 0 means not reported
+}
+
+// ChecklistItem is a nested entry of a TODO with its own status
+type ChecklistItem struct {
+	ID          string     `json:"id" bson:"id"`
+	Description string     `json:"description" bson:"description"`
+	Status      TodoStatus `json:"status" bson:"status"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
+	Notes       string     `json:"notes,omitempty" bson:"notes,omitempty"`
 }
 
 // TodoItemInput represents the input format for creating a TODO item
 type TodoItemInput struct {
-	Description  string `json:"description"`
-	FilePath     string `json:"filePath,omitempty"`
-	FunctionName string `json:"functionName,omitempty"`
-	ContextHint  string `json:"contextHint,omitempty"`
-	Notes        string `json:"notes,omitempty"`
+	Description      string   `json:"description"`
+	FilePath         string   `json:"filePath,omitempty"`
+	FunctionName     string   `json:"functionName,omitempty"`
+	ContextHint      string   `json:"contextHint,omitempty"`
+	Notes            string   `json:"notes,omitempty"`
+	Checklist        []string `json:"checklist,omitempty"` // Descriptions of nested checklist items
+	EstimatedMinutes int      `json:"estimatedMinutes,omitempty"`
 }
 
 // HumanTask represents a task created by a human user
 type HumanTask struct {
-	ID        string     `json:"id" bson:"taskId"`
-	Prompt    string     `json:"prompt" bson:"prompt"`
-	CreatedAt time.Time  `json:"createdAt" bson:"createdAt"`
-	UpdatedAt time.Time  `json:"updatedAt" bson:"updatedAt"`
-	Status    TaskStatus `json:"status" bson:"status"`
-	Notes     string     `json:"notes,omitempty" bson:"notes,omitempty"`
+	ID             string       `json:"id" bson:"taskId"`
+	Prompt         string       `json:"prompt" bson:"prompt"`
+	CreatedAt      time.Time    `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time    `json:"updatedAt" bson:"updatedAt"`
+	Status         TaskStatus   `json:"status" bson:"status"`
+	Notes          string       `json:"notes,omitempty" bson:"notes,omitempty"`
+	Project        string       `json:"project,omitempty" bson:"project,omitempty"`
+	ClonedFrom     string       `json:"clonedFrom,omitempty" bson:"clonedFrom,omitempty"`         // Source human task ID for clones
+	JiraIssueKey   string       `json:"jiraIssueKey,omitempty" bson:"jiraIssueKey,omitempty"`     // Linked Jira issue, e.g. "PROJ-123"
+	LinearIssueID  string       `json:"linearIssueId,omitempty" bson:"linearIssueId,omitempty"`   // Linked Linear issue UUID
+	LinearIssueKey string       `json:"linearIssueKey,omitempty" bson:"linearIssueKey,omitempty"` // Its identifier, e.g. "ENG-42"
+	DueAt          *time.Time   `json:"dueAt,omitempty" bson:"dueAt,omitempty"`
+	Tags           []string     `json:"tags,omitempty" bson:"tags,omitempty"` // Added by automation hooks
+	Priority       TaskPriority `json:"priority,omitempty" bson:"priority,omitempty"`
 }
 
 // AgentTask represents a task assigned to an agent
 type AgentTask struct {
-	ID                string     `json:"id" bson:"taskId"`
-	HumanTaskID       string     `json:"humanTaskId" bson:"humanTaskId"`
-	AgentName         string     `json:"agentName" bson:"agentName"`
-	Role              string     `json:"role" bson:"role"`
-	Todos             []TodoItem `json:"todos" bson:"todos"`
-	CreatedAt         time.Time  `json:"createdAt" bson:"createdAt"`
-	UpdatedAt         time.Time  `json:"updatedAt" bson:"updatedAt"`
-	Status            TaskStatus `json:"status" bson:"status"`
-	Notes             string     `json:"notes,omitempty" bson:"notes,omitempty"`
-	ContextSummary    string     `json:"contextSummary,omitempty" bson:"contextSummary,omitempty"`
-	FilesModified             []string   `json:"filesModified,omitempty" bson:"filesModified,omitempty"`
-	QdrantCollections         []string   `json:"qdrantCollections,omitempty" bson:"qdrantCollections,omitempty"`
-	PriorWorkSummary          string     `json:"priorWorkSummary,omitempty" bson:"priorWorkSummary,omitempty"`
-	HumanPromptNotes          string     `json:"humanPromptNotes,omitempty" bson:"humanPromptNotes,omitempty"`
-	HumanPromptNotesAddedAt   *time.Time `json:"humanPromptNotesAddedAt,omitempty" bson:"humanPromptNotesAddedAt,omitempty"`
-	HumanPromptNotesUpdatedAt *time.Time `json:"humanPromptNotesUpdatedAt,omitempty" bson:"humanPromptNotesUpdatedAt,omitempty"`
+	ID                        string            `json:"id" bson:"taskId"`
+	HumanTaskID               string            `json:"humanTaskId" bson:"humanTaskId"`
+	AgentName                 string            `json:"agentName" bson:"agentName"`
+	Role                      string            `json:"role" bson:"role"`
+	Todos                     []TodoItem        `json:"todos" bson:"todos"`
+	CreatedAt                 time.Time         `json:"createdAt" bson:"createdAt"`
+	UpdatedAt                 time.Time         `json:"updatedAt" bson:"updatedAt"`
+	Status                    TaskStatus        `json:"status" bson:"status"`
+	Notes                     string            `json:"notes,omitempty" bson:"notes,omitempty"`
+	ContextSummary            string            `json:"contextSummary,omitempty" bson:"contextSummary,omitempty"`
+	FilesModified             []string          `json:"filesModified,omitempty" bson:"filesModified,omitempty"`
+	QdrantCollections         []string          `json:"qdrantCollections,omitempty" bson:"qdrantCollections,omitempty"`
+	PriorWorkSummary          string            `json:"priorWorkSummary,omitempty" bson:"priorWorkSummary,omitempty"`
+	HumanPromptNotes          string            `json:"humanPromptNotes,omitempty" bson:"humanPromptNotes,omitempty"`
+	HumanPromptNotesAddedAt   *time.Time        `json:"humanPromptNotesAddedAt,omitempty" bson:"humanPromptNotesAddedAt,omitempty"`
+	HumanPromptNotesUpdatedAt *time.Time        `json:"humanPromptNotesUpdatedAt,omitempty" bson:"humanPromptNotesUpdatedAt,omitempty"`
+	ChangeLog                 []FileChangeEntry `json:"changeLog,omitempty" bson:"changeLog,omitempty"`
+	ClonedFrom                string            `json:"clonedFrom,omitempty" bson:"clonedFrom,omitempty"` // Source agent task ID for clones
+	DueAt                     *time.Time        `json:"dueAt,omitempty" bson:"dueAt,omitempty"`
+	Tags                      []string          `json:"tags,omitempty" bson:"tags,omitempty"` // Added by automation hooks
+	Priority                  TaskPriority      `json:"priority,omitempty" bson:"priority,omitempty"`
+	InheritedPriority         TaskPriority      `json:"inheritedPriority,omitempty" bson:"inheritedPriority,omitempty"`         // Raised by priority rules while blocking higher-priority work
+	PriorityInheritedFrom     string            `json:"priorityInheritedFrom,omitempty" bson:"priorityInheritedFrom,omitempty"` // Human task it inherited from
+	EscalatedAt               *time.Time        `json:"escalatedAt,omitempty" bson:"escalatedAt,omitempty"`                     // When priority rules escalated it as blocked too long
+	Activity                  []TaskActivity    `json:"-" bson:"activity,omitempty"`                                            // Served separately via GetAgentTaskActivity
+}
+
+// FileChangeEntry records a file system change observed while an agent task was active
+type FileChangeEntry struct {
+	Path          string    `json:"path" bson:"path"`
+	Operation     string    `json:"operation" bson:"operation"`
+	ObservedAt    time.Time `json:"observedAt" bson:"observedAt"`
+	ChunksIndexed int       `json:"chunksIndexed" bson:"chunksIndexed"`
+	ChunksRemoved int       `json:"chunksRemoved" bson:"chunksRemoved"`
+	LinesAdded    int       `json:"linesAdded" bson:"linesAdded"`
+	LinesRemoved  int       `json:"linesRemoved" bson:"linesRemoved"`
 }
 
 // ClearResult contains statistics about cleared tasks
@@ -109,8 +153,13 @@ type TaskStorage interface {
 	GetAgentTasksByName(agentName string) ([]*AgentTask, error)
 	ListAllHumanTasks() []*HumanTask
 	ListAllAgentTasks() []*AgentTask
+	ListAgentTasksByStatus(status TaskStatus) ([]*AgentTask, error)
+	ListAgentTasksByHumanTask(humanTaskID string) ([]*AgentTask, error)
 	UpdateTaskStatus(taskID string, status TaskStatus, notes string) error
 	UpdateTodoStatus(agentTaskID, todoID string, status TodoStatus, notes string) error
+	UpdateChecklistItemStatus(agentTaskID, todoID, itemID string, status TodoStatus, notes string) error
+	UpdateTodoTime(agentTaskID, todoID string, estimatedMinutes, actualMinutes *int) error
+	GetAgentTaskActivity(agentTaskID string) ([]TaskActivity, error)
 	AddTaskPromptNotes(agentTaskID string, notes string) error
 	UpdateTaskPromptNotes(agentTaskID string, notes string) error
 	ClearTaskPromptNotes(agentTaskID string) error
@@ -118,19 +167,26 @@ type TaskStorage interface {
 	UpdateTodoPromptNotes(agentTaskID string, todoID string, notes string) error
 	ClearTodoPromptNotes(agentTaskID string, todoID string) error
 	ClearAllTasks() (*ClearResult, error)
+	AppendTaskChangeLog(agentTaskID string, entry FileChangeEntry) error
+	CloneHumanTask(sourceTaskID, project, prompt string) (*HumanTask, []*AgentTask, error)
+	SetTaskDueDate(taskID string, dueAt *time.Time) error
 }
 
 // MongoTaskStorage implements TaskStorage using MongoDB
 type MongoTaskStorage struct {
 	humanTasksCollection *mongo.Collection
 	agentTasksCollection *mongo.Collection
+	deletionsCollection  *mongo.Collection  // deleted task IDs, for board deltas
+	cipher               *FieldCipher       // optional, seals prompts and notes at rest
+	events               TaskEventPublisher // optional, told about task changes
 }
 
 // NewMongoTaskStorage creates a new MongoDB-backed task storage
 func NewMongoTaskStorage(db *mongo.Database) (*MongoTaskStorage, error) {
 	storage := &MongoTaskStorage{
-		humanTasksCollection: db.Collection("human_tasks"),
-		agentTasksCollection: db.Collection("agent_tasks"),
+		humanTasksCollection: db.Collection(CollectionName("human_tasks")),
+		agentTasksCollection: db.Collection(CollectionName("agent_tasks")),
+		deletionsCollection:  db.Collection(CollectionName("task_deletions")),
 	}
 
 	// Create indexes
@@ -170,6 +226,36 @@ func NewMongoTaskStorage(db *mongo.Database) (*MongoTaskStorage, error) {
 		return nil, fmt.Errorf("failed to create human task ID index: %w", err)
 	}
 
+	// Index on agentTasks.status for the watcher's in-progress lookups
+	_, err = storage.agentTasksCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent task status index: %w", err)
+	}
+
+	// Sparse index on humanTasks.jiraIssueKey for Jira sync lookups
+	_, err = storage.humanTasksCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "jiraIssueKey", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Jira issue key index: %w", err)
+	}
+
+	// Sparse index on humanTasks.linearIssueId for Linear sync lookups
+	_, err = storage.humanTasksCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "linearIssueId", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Linear issue ID index: %w", err)
+	}
+
+	if err := storage.ensureBoardIndexes(ctx); err != nil {
+		return nil, err
+	}
+
 	return storage, nil
 }
 
@@ -186,10 +272,15 @@ func (s *MongoTaskStorage) CreateHumanTask(prompt string) (*HumanTask, error) {
 		Status:    TaskStatusPending,
 	}
 
-	_, err := s.humanTasksCollection.InsertOne(ctx, task)
+	doc, err := s.sealHumanTask(task)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.humanTasksCollection.InsertOne(ctx, doc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert human task: %w", err)
 	}
+	s.publish(humanTaskCreatedEvent(task))
 
 	return task, nil
 }
@@ -203,7 +294,7 @@ func (s *MongoTaskStorage) CreateAgentTask(humanTaskID, agentName, role string,
 	err := s.humanTasksCollection.FindOne(ctx, bson.M{"taskId": humanTaskID}).Decode(&humanTask)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("human task with ID %s not found", humanTaskID)
+			return nil, errcode.New(errcode.NotFound, "human task with ID %s not found", humanTaskID)
 		}
 		return nil, fmt.Errorf("failed to validate human task: %w", err)
 	}
@@ -214,14 +305,16 @@ func (s *MongoTaskStorage) CreateAgentTask(humanTaskID, agentName, role string,
 	todoItems := make([]TodoItem, len(todos))
 	for i, input := range todos {
 		todoItems[i] = TodoItem{
-			ID:           uuid.New().String(),
-			Description:  input.Description,
-			Status:       TodoStatusPending,
-			CreatedAt:    now,
-			FilePath:     input.FilePath,
-			FunctionName: input.FunctionName,
-			ContextHint:  input.ContextHint,
-			Notes:        input.Notes,
+			ID:               uuid.New().String(),
+			Description:      input.Description,
+			Status:           TodoStatusPending,
+			CreatedAt:        now,
+			FilePath:         input.FilePath,
+			FunctionName:     input.FunctionName,
+			ContextHint:      input.ContextHint,
+			Notes:            input.Notes,
+			Checklist:        newChecklistItems(input.Checklist),
+			EstimatedMinutes: input.EstimatedMinutes,
 		}
 	}
 
@@ -238,12 +331,18 @@ func (s *MongoTaskStorage) CreateAgentTask(humanTaskID, agentName, role string,
 		FilesModified:     filesModified,
 		QdrantCollections: qdrantCollections,
 		PriorWorkSummary:  priorWorkSummary,
+		Activity:          []TaskActivity{{At: now, Action: ActivityCreated, Status: string(TaskStatusPending)}},
 	}
 
-	_, err = s.agentTasksCollection.InsertOne(ctx, task)
+	doc, err := s.sealAgentTask(task)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.agentTasksCollection.InsertOne(ctx, doc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert agent task: %w", err)
 	}
+	s.publish(agentTaskCreatedEvent(task))
 
 	return task, nil
 }
@@ -256,10 +355,13 @@ func (s *MongoTaskStorage) GetHumanTask(taskID string) (*HumanTask, error) {
 	err := s.humanTasksCollection.FindOne(ctx, bson.M{"taskId": taskID}).Decode(&task)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("human task with ID %s not found", taskID)
+			return nil, errcode.New(errcode.NotFound, "human task with ID %s not found", taskID)
 		}
 		return nil, fmt.Errorf("failed to retrieve human task: %w", err)
 	}
+	if err := s.cipher.OpenAll(humanTaskSecrets(&task)); err != nil {
+		return nil, err
+	}
 
 	return &task, nil
 }
@@ -272,10 +374,13 @@ func (s *MongoTaskStorage) GetAgentTask(taskID string) (*AgentTask, error) {
 	err := s.agentTasksCollection.FindOne(ctx, bson.M{"taskId": taskID}).Decode(&task)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("agent task with ID %s not found", taskID)
+			return nil, errcode.New(errcode.NotFound, "agent task with ID %s not found", taskID)
 		}
 		return nil, fmt.Errorf("failed to retrieve agent task: %w", err)
 	}
+	if err := s.cipher.OpenAll(agentTaskSecrets(&task)); err != nil {
+		return nil, err
+	}
 
 	return &task, nil
 }
@@ -294,6 +399,11 @@ func (s *MongoTaskStorage) GetAgentTasksByName(agentName string) ([]*AgentTask,
 	if err := cursor.All(ctx, &tasks); err != nil {
 		return nil, fmt.Errorf("failed to decode agent tasks: %w", err)
 	}
+	for _, task := range tasks {
+		if err := s.cipher.OpenAll(agentTaskSecrets(task)); err != nil {
+			return nil, err
+		}
+	}
 
 	return tasks, nil
 }
@@ -312,6 +422,10 @@ func (s *MongoTaskStorage) ListAllHumanTasks() []*HumanTask {
 	if err := cursor.All(ctx, &tasks); err != nil {
 		return []*HumanTask{}
 	}
+	for _, task := range tasks {
+		// A field that fails to decrypt stays sealed rather than hiding the task
+		s.cipher.OpenAll(humanTaskSecrets(task))
+	}
 
 	return tasks
 }
@@ -330,18 +444,68 @@ func (s *MongoTaskStorage) ListAllAgentTasks() []*AgentTask {
 	if err := cursor.All(ctx, &tasks); err != nil {
 		return []*AgentTask{}
 	}
+	for _, task := range tasks {
+		// A field that fails to decrypt stays sealed rather than hiding the task
+		s.cipher.OpenAll(agentTaskSecrets(task))
+	}
 
 	return tasks
 }
 
+// ListAgentTasksByStatus returns the agent tasks with a status
+func (s *MongoTaskStorage) ListAgentTasksByStatus(status TaskStatus) ([]*AgentTask, error) {
+	ctx := context.Background()
+
+	cursor, err := s.agentTasksCollection.Find(ctx, bson.M{"status": status})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent tasks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []*AgentTask
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to decode agent tasks: %w", err)
+	}
+	for _, task := range tasks {
+		s.cipher.OpenAll(agentTaskSecrets(task))
+	}
+	return tasks, nil
+}
+
+// ListAgentTasksByHumanTask returns the agent tasks of a human task
+func (s *MongoTaskStorage) ListAgentTasksByHumanTask(humanTaskID string) ([]*AgentTask, error) {
+	ctx := context.Background()
+
+	cursor, err := s.agentTasksCollection.Find(ctx, bson.M{"humanTaskId": humanTaskID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent tasks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []*AgentTask
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to decode agent tasks: %w", err)
+	}
+	for _, task := range tasks {
+		s.cipher.OpenAll(agentTaskSecrets(task))
+	}
+	return tasks, nil
+}
+
 // UpdateTaskStatus updates the status and notes of any task (human or agent)
 func (s *MongoTaskStorage) UpdateTaskStatus(taskID string, status TaskStatus, notes string) error {
 	ctx := context.Background()
+	now := time.Now().UTC()
+
+	notes, err := s.cipher.Encrypt(notes)
+	if err != nil {
+		return err
+	}
 
 	update := bson.M{
 		"$set": bson.M{
 			"status":    status,
-			"updatedAt": time.Now().UTC(),
+			"updatedAt": now,
 		},
 	}
 
@@ -356,10 +520,15 @@ func (s *MongoTaskStorage) UpdateTaskStatus(taskID string, status TaskStatus, no
 		update,
 	)
 	if result.Err() == nil {
+		var previous HumanTask
+		if result.Decode(&previous) == nil {
+			s.publish(TaskEvent{Type: TaskEventStatusChanged, At: now, TaskID: taskID, Kind: "human", Status: string(status), PreviousStatus: string(previous.Status)})
+		}
 		return nil
 	}
 
-	// If not found in human tasks, try agent tasks
+	// If not found in human tasks, try agent tasks, recording the change in the activity log
+	update["$push"] = pushActivity(TaskActivity{At: now, Action: ActivityStatusChanged, Status: string(status), Notes: notes})
 	result = s.agentTasksCollection.FindOneAndUpdate(
 		ctx,
 		bson.M{"taskId": taskID},
@@ -367,10 +536,23 @@ func (s *MongoTaskStorage) UpdateTaskStatus(taskID string, status TaskStatus, no
 	)
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
-			return fmt.Errorf("task with ID %s not found", taskID)
+			return errcode.New(errcode.NotFound, "task with ID %s not found", taskID)
 		}
 		return fmt.Errorf("failed to update task status: %w", result.Err())
 	}
+	var previous AgentTask
+	if result.Decode(&previous) == nil {
+		s.publish(TaskEvent{
+			Type:           TaskEventStatusChanged,
+			At:             now,
+			TaskID:         taskID,
+			Kind:           "agent",
+			HumanTaskID:    previous.HumanTaskID,
+			AgentName:      previous.AgentName,
+			Status:         string(status),
+			PreviousStatus: string(previous.Status),
+		})
+	}
 
 	return nil
 }
@@ -384,7 +566,7 @@ func (s *MongoTaskStorage) UpdateTodoStatus(agentTaskID, todoID string, status T
 	err := s.agentTasksCollection.FindOne(ctx, bson.M{"taskId": agentTaskID}).Decode(&agentTask)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return fmt.Errorf("agent task with ID %s not found", agentTaskID)
+			return errcode.New(errcode.NotFound, "agent task with ID %s not found", agentTaskID)
 		}
 		return fmt.Errorf("failed to retrieve agent task: %w", err)
 	}
@@ -399,14 +581,19 @@ func (s *MongoTaskStorage) UpdateTodoStatus(agentTaskID, todoID string, status T
 	}
 
 	if todoIndex == -1 {
-		return fmt.Errorf("todo item with ID %s not found in agent task %s", todoID, agentTaskID)
+		return errcode.New(errcode.NotFound, "todo item with ID %s not found in agent task %s", todoID, agentTaskID)
+	}
+
+	notes, err = s.cipher.Encrypt(notes)
+	if err != nil {
+		return err
 	}
 
 	// Prepare the update for the specific todo item
 	now := time.Now().UTC()
 	updateFields := bson.M{
-		fmt.Sprintf("todos.%d.status", todoIndex):    status,
-		"updatedAt":                                   now,
+		fmt.Sprintf("todos.%d.status", todoIndex): status,
+		"updatedAt": now,
 	}
 
 	// Add completion timestamp if status is completed
@@ -419,7 +606,17 @@ func (s *MongoTaskStorage) UpdateTodoStatus(agentTaskID, todoID string, status T
 		updateFields[fmt.Sprintf("todos.%d.notes", todoIndex)] = notes
 	}
 
-	update := bson.M{"$set": updateFields}
+	update := bson.M{
+		"$set": updateFields,
+		"$push": pushActivity(TaskActivity{
+			At:             now,
+			Action:         ActivityTodoStatusChanged,
+			TodoID:         todoID,
+			PreviousStatus: string(agentTask.Todos[todoIndex].Status),
+			Status:         string(status),
+			Notes:          notes,
+		}),
+	}
 
 	// Update the agent task
 	result := s.agentTasksCollection.FindOneAndUpdate(
@@ -431,33 +628,276 @@ func (s *MongoTaskStorage) UpdateTodoStatus(agentTaskID, todoID string, status T
 	if result.Err() != nil {
 		return fmt.Errorf("failed to update todo status: %w", result.Err())
 	}
+	s.publish(TaskEvent{
+		Type:           TaskEventTodoStatusChanged,
+		At:             now,
+		TaskID:         agentTaskID,
+		Kind:           "agent",
+		HumanTaskID:    agentTask.HumanTaskID,
+		AgentName:      agentTask.AgentName,
+		TodoID:         todoID,
+		Status:         string(status),
+		PreviousStatus: string(agentTask.Todos[todoIndex].Status),
+	})
+
+	s.autoCompleteAgentTask(ctx, agentTaskID)
+
+	return nil
+}
+
+// UpdateTodoTime records the estimated and/or actual minutes of a TODO item.
+// Nil values leave the stored value unchanged.
+func (s *MongoTaskStorage) UpdateTodoTime(agentTaskID, todoID string, estimatedMinutes, actualMinutes *int) error {
+	if estimatedMinutes == nil && actualMinutes == nil {
+		return nil
+	}
+	if (estimatedMinutes != nil && *estimatedMinutes < 0) || (actualMinutes != nil && *actualMinutes < 0) {
+		return fmt.Errorf("estimatedMinutes and actualMinutes must not be negative")
+	}
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	set := bson.M{
+		"updatedAt": now,
+	}
+	if estimatedMinutes != nil {
+		set["todos.$[elem].estimatedMinutes"] = *estimatedMinutes
+	}
+	if actualMinutes != nil {
+		set["todos.$[elem].actualMinutes"] = *actualMinutes
+	}
+
+	arrayFilters := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{
+			bson.M{"elem.id": todoID},
+		},
+	})
 
-	// Check if all todos are completed, and if so, auto-complete the agent task
+	result, err := s.agentTasksCollection.UpdateOne(
+		ctx,
+		bson.M{"taskId": agentTaskID, "todos.id": todoID},
+		bson.M{
+			"$set": set,
+			"$push": pushActivity(TaskActivity{
+				At:               now,
+				Action:           ActivityTodoTimeUpdated,
+				TodoID:           todoID,
+				EstimatedMinutes: estimatedMinutes,
+				ActualMinutes:    actualMinutes,
+			}),
+		},
+		arrayFilters,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update todo time: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return errcode.New(errcode.NotFound, "todo item with ID %s not found in agent task %s", todoID, agentTaskID)
+	}
+
+	return nil
+}
+
+// autoCompleteAgentTask marks an agent task completed once all of its TODOs are
+func (s *MongoTaskStorage) autoCompleteAgentTask(ctx context.Context, agentTaskID string) {
 	var updatedTask AgentTask
-	err = s.agentTasksCollection.FindOne(ctx, bson.M{"taskId": agentTaskID}).Decode(&updatedTask)
-	if err == nil {
-		allCompleted := true
-		for _, todo := range updatedTask.Todos {
-			if todo.Status != TodoStatusCompleted {
-				allCompleted = false
-				break
-			}
+	err := s.agentTasksCollection.FindOne(ctx, bson.M{"taskId": agentTaskID}).Decode(&updatedTask)
+	if err != nil {
+		return
+	}
+
+	allCompleted := true
+	for _, todo := range updatedTask.Todos {
+		if todo.Status != TodoStatusCompleted {
+			allCompleted = false
+			break
 		}
+	}
 
-		// Auto-complete the agent task if all todos are done
-		if allCompleted && updatedTask.Status != TaskStatusCompleted {
-			s.UpdateTaskStatus(agentTaskID, TaskStatusCompleted, "All TODO items completed")
+	// Auto-complete the agent task if all todos are done
+	if allCompleted && updatedTask.Status != TaskStatusCompleted {
+		s.UpdateTaskStatus(agentTaskID, TaskStatusCompleted, "All TODO items completed")
+	}
+}
+
+// UpdateChecklistItemStatus updates a checklist item nested in a TODO and rolls
+// the result up to the TODO: it completes when every item is completed, and a
+// completed TODO is reopened when one of its items is reopened.
+func (s *MongoTaskStorage) UpdateChecklistItemStatus(agentTaskID, todoID, itemID string, status TodoStatus, notes string) error {
+	ctx := context.Background()
+
+	var agentTask AgentTask
+	err := s.agentTasksCollection.FindOne(ctx, bson.M{"taskId": agentTaskID}).Decode(&agentTask)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errcode.New(errcode.NotFound, "agent task with ID %s not found", agentTaskID)
 		}
+		return fmt.Errorf("failed to retrieve agent task: %w", err)
 	}
 
+	todoIndex := -1
+	for i, todo := range agentTask.Todos {
+		if todo.ID == todoID {
+			todoIndex = i
+			break
+		}
+	}
+	if todoIndex == -1 {
+		return errcode.New(errcode.NotFound, "todo item with ID %s not found in agent task %s", todoID, agentTaskID)
+	}
+
+	todo := agentTask.Todos[todoIndex]
+	previousStatus := ""
+	for _, item := range todo.Checklist {
+		if item.ID == itemID {
+			previousStatus = string(item.Status)
+			break
+		}
+	}
+	// Other items' notes are still sealed as read, so only the new notes need sealing
+	notes, err = s.cipher.Encrypt(notes)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if !applyChecklistItemStatus(todo.Checklist, itemID, status, notes, now) {
+		return errcode.New(errcode.NotFound, "checklist item with ID %s not found in todo %s", itemID, todoID)
+	}
+
+	todoStatus := rollupTodoStatus(todo.Status, todo.Checklist)
+	set := bson.M{
+		fmt.Sprintf("todos.%d.checklist", todoIndex): todo.Checklist,
+		fmt.Sprintf("todos.%d.status", todoIndex):    todoStatus,
+		"updatedAt": now,
+	}
+	update := bson.M{
+		"$set": set,
+		"$push": pushActivity(TaskActivity{
+			At:              now,
+			Action:          ActivityChecklistStatusChanged,
+			TodoID:          todoID,
+			ChecklistItemID: itemID,
+			PreviousStatus:  previousStatus,
+			Status:          string(status),
+			Notes:           notes,
+		}),
+	}
+	if todoStatus == TodoStatusCompleted {
+		if todo.Status != TodoStatusCompleted {
+			set[fmt.Sprintf("todos.%d.completedAt", todoIndex)] = now
+		}
+	} else {
+		update["$unset"] = bson.M{fmt.Sprintf("todos.%d.completedAt", todoIndex): ""}
+	}
+
+	_, err = s.agentTasksCollection.UpdateOne(ctx, bson.M{"taskId": agentTaskID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update checklist item status: %w", err)
+	}
+	event := TaskEvent{
+		Type:            TaskEventChecklistStatusChange,
+		At:              now,
+		TaskID:          agentTaskID,
+		Kind:            "agent",
+		HumanTaskID:     agentTask.HumanTaskID,
+		AgentName:       agentTask.AgentName,
+		TodoID:          todoID,
+		ChecklistItemID: itemID,
+		Status:          string(status),
+		PreviousStatus:  previousStatus,
+	}
+	s.publish(event)
+	if todoStatus != todo.Status {
+		event.Type = TaskEventTodoStatusChanged
+		event.ChecklistItemID = ""
+		event.Status = string(todoStatus)
+		event.PreviousStatus = string(todo.Status)
+		s.publish(event)
+	}
+
+	s.autoCompleteAgentTask(ctx, agentTaskID)
+
 	return nil
 }
 
+// newChecklistItems builds pending checklist items from their descriptions, skipping blanks
+func newChecklistItems(descriptions []string) []ChecklistItem {
+	var items []ChecklistItem
+	for _, description := range descriptions {
+		if description == "" {
+			continue
+		}
+		items = append(items, ChecklistItem{
+			ID:          uuid.New().String(),
+			Description: description,
+			Status:      TodoStatusPending,
+		})
+	}
+	return items
+}
+
+// applyChecklistItemStatus updates the item with itemID in place and reports whether it was found
+func applyChecklistItemStatus(items []ChecklistItem, itemID string, status TodoStatus, notes string, now time.Time) bool {
+	for i := range items {
+		if items[i].ID != itemID {
+			continue
+		}
+		items[i].Status = status
+		if status == TodoStatusCompleted {
+			items[i].CompletedAt = &now
+		} else {
+			items[i].CompletedAt = nil
+		}
+		if notes != "" {
+			items[i].Notes = notes
+		}
+		return true
+	}
+	return false
+}
+
+// rollupTodoStatus derives a TODO's status after one of its checklist items
+// changed. All items completed completes the TODO; otherwise a completed TODO
+// is reopened and a pending TODO with started items moves to in_progress.
+func rollupTodoStatus(current TodoStatus, items []ChecklistItem) TodoStatus {
+	if len(items) == 0 {
+		return current
+	}
+
+	completed, started := 0, 0
+	for _, item := range items {
+		switch item.Status {
+		case TodoStatusCompleted:
+			completed++
+			started++
+		case TodoStatusInProgress:
+			started++
+		}
+	}
+
+	switch {
+	case completed == len(items):
+		return TodoStatusCompleted
+	case current == TodoStatusCompleted:
+		return TodoStatusInProgress
+	case current == TodoStatusPending && started > 0:
+		return TodoStatusInProgress
+	default:
+		return current
+	}
+}
+
 // AddTaskPromptNotes adds human prompt notes to an agent task
 func (s *MongoTaskStorage) AddTaskPromptNotes(agentTaskID string, notes string) error {
 	ctx := context.Background()
 	now := time.Now().UTC()
 
+	notes, err := s.cipher.Encrypt(notes)
+	if err != nil {
+		return err
+	}
+
 	update := bson.M{
 		"$set": bson.M{
 			"humanPromptNotes":          notes,
@@ -466,6 +906,7 @@ func (s *MongoTaskStorage) AddTaskPromptNotes(agentTaskID string, notes string)
 			"updatedAt":                 now,
 		},
 	}
+	update["$push"] = pushActivity(TaskActivity{At: now, Action: ActivityPromptNotesAdded, Notes: notes})
 
 	result := s.agentTasksCollection.FindOneAndUpdate(
 		ctx,
@@ -475,7 +916,7 @@ func (s *MongoTaskStorage) AddTaskPromptNotes(agentTaskID string, notes string)
 
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
-			return fmt.Errorf("agent task with ID %s not found", agentTaskID)
+			return errcode.New(errcode.NotFound, "agent task with ID %s not found", agentTaskID)
 		}
 		return fmt.Errorf("failed to add task prompt notes: %w", result.Err())
 	}
@@ -488,6 +929,11 @@ func (s *MongoTaskStorage) UpdateTaskPromptNotes(agentTaskID string, notes strin
 	ctx := context.Background()
 	now := time.Now().UTC()
 
+	notes, err := s.cipher.Encrypt(notes)
+	if err != nil {
+		return err
+	}
+
 	update := bson.M{
 		"$set": bson.M{
 			"humanPromptNotes":          notes,
@@ -495,6 +941,7 @@ func (s *MongoTaskStorage) UpdateTaskPromptNotes(agentTaskID string, notes strin
 			"updatedAt":                 now,
 		},
 	}
+	update["$push"] = pushActivity(TaskActivity{At: now, Action: ActivityPromptNotesUpdated, Notes: notes})
 
 	result := s.agentTasksCollection.FindOneAndUpdate(
 		ctx,
@@ -504,7 +951,7 @@ func (s *MongoTaskStorage) UpdateTaskPromptNotes(agentTaskID string, notes strin
 
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
-			return fmt.Errorf("agent task with ID %s not found", agentTaskID)
+			return errcode.New(errcode.NotFound, "agent task with ID %s not found", agentTaskID)
 		}
 		return fmt.Errorf("failed to update task prompt notes: %w", result.Err())
 	}
@@ -527,6 +974,7 @@ func (s *MongoTaskStorage) ClearTaskPromptNotes(agentTaskID string) error {
 			"updatedAt": now,
 		},
 	}
+	update["$push"] = pushActivity(TaskActivity{At: now, Action: ActivityPromptNotesCleared})
 
 	result := s.agentTasksCollection.FindOneAndUpdate(
 		ctx,
@@ -536,7 +984,7 @@ func (s *MongoTaskStorage) ClearTaskPromptNotes(agentTaskID string) error {
 
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
-			return fmt.Errorf("agent task with ID %s not found", agentTaskID)
+			return errcode.New(errcode.NotFound, "agent task with ID %s not found", agentTaskID)
 		}
 		return fmt.Errorf("failed to clear task prompt notes: %w", result.Err())
 	}
@@ -549,6 +997,11 @@ func (s *MongoTaskStorage) AddTodoPromptNotes(agentTaskID string, todoID string,
 	ctx := context.Background()
 	now := time.Now().UTC()
 
+	notes, err := s.cipher.Encrypt(notes)
+	if err != nil {
+		return err
+	}
+
 	update := bson.M{
 		"$set": bson.M{
 			"todos.$[elem].humanPromptNotes":          notes,
@@ -557,6 +1010,7 @@ func (s *MongoTaskStorage) AddTodoPromptNotes(agentTaskID string, todoID string,
 			"updatedAt": now,
 		},
 	}
+	update["$push"] = pushActivity(TaskActivity{At: now, Action: ActivityTodoPromptNotesAdded, TodoID: todoID, Notes: notes})
 
 	arrayFilters := options.Update().SetArrayFilters(options.ArrayFilters{
 		Filters: []interface{}{
@@ -576,11 +1030,11 @@ func (s *MongoTaskStorage) AddTodoPromptNotes(agentTaskID string, todoID string,
 	}
 
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("agent task with ID %s not found", agentTaskID)
+		return errcode.New(errcode.NotFound, "agent task with ID %s not found", agentTaskID)
 	}
 
 	if result.ModifiedCount == 0 {
-		return fmt.Errorf("todo item with ID %s not found in agent task %s", todoID, agentTaskID)
+		return errcode.New(errcode.NotFound, "todo item with ID %s not found in agent task %s", todoID, agentTaskID)
 	}
 
 	return nil
@@ -591,6 +1045,11 @@ func (s *MongoTaskStorage) UpdateTodoPromptNotes(agentTaskID string, todoID stri
 	ctx := context.Background()
 	now := time.Now().UTC()
 
+	notes, err := s.cipher.Encrypt(notes)
+	if err != nil {
+		return err
+	}
+
 	update := bson.M{
 		"$set": bson.M{
 			"todos.$[elem].humanPromptNotes":          notes,
@@ -598,6 +1057,7 @@ func (s *MongoTaskStorage) UpdateTodoPromptNotes(agentTaskID string, todoID stri
 			"updatedAt": now,
 		},
 	}
+	update["$push"] = pushActivity(TaskActivity{At: now, Action: ActivityTodoPromptNotesUpdated, TodoID: todoID, Notes: notes})
 
 	arrayFilters := options.Update().SetArrayFilters(options.ArrayFilters{
 		Filters: []interface{}{
@@ -617,11 +1077,11 @@ func (s *MongoTaskStorage) UpdateTodoPromptNotes(agentTaskID string, todoID stri
 	}
 
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("agent task with ID %s not found", agentTaskID)
+		return errcode.New(errcode.NotFound, "agent task with ID %s not found", agentTaskID)
 	}
 
 	if result.ModifiedCount == 0 {
-		return fmt.Errorf("todo item with ID %s not found in agent task %s", todoID, agentTaskID)
+		return errcode.New(errcode.NotFound, "todo item with ID %s not found in agent task %s", todoID, agentTaskID)
 	}
 
 	return nil
@@ -642,6 +1102,7 @@ func (s *MongoTaskStorage) ClearTodoPromptNotes(agentTaskID string, todoID strin
 			"updatedAt": now,
 		},
 	}
+	update["$push"] = pushActivity(TaskActivity{At: now, Action: ActivityTodoPromptNotesCleared, TodoID: todoID})
 
 	arrayFilters := options.Update().SetArrayFilters(options.ArrayFilters{
 		Filters: []interface{}{
@@ -661,11 +1122,11 @@ func (s *MongoTaskStorage) ClearTodoPromptNotes(agentTaskID string, todoID strin
 	}
 
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("agent task with ID %s not found", agentTaskID)
+		return errcode.New(errcode.NotFound, "agent task with ID %s not found", agentTaskID)
 	}
 
 	if result.ModifiedCount == 0 {
-		return fmt.Errorf("todo item with ID %s not found in agent task %s", todoID, agentTaskID)
+		return errcode.New(errcode.NotFound, "todo item with ID %s not found in agent task %s", todoID, agentTaskID)
 	}
 
 	return nil
@@ -691,6 +1152,180 @@ func (s *MongoTaskStorage) ClearAllTasks() (*ClearResult, error) {
 		return nil, fmt.Errorf("failed to delete agent tasks: %w", err)
 	}
 	result.AgentTasksDeleted = agentResult.DeletedCount
+	s.recordDeletions(ctx, "board", []string{BoardClearedID})
+	s.publish(TaskEvent{Type: TaskEventBoardCleared})
 
 	return result, nil
-}
\ No newline at end of file
+}
+
+// AppendTaskChangeLog appends an observed file change to an agent task's change log
+func (s *MongoTaskStorage) AppendTaskChangeLog(agentTaskID string, entry FileChangeEntry) error {
+	ctx := context.Background()
+
+	update := bson.M{
+		"$push": bson.M{"changeLog": entry},
+		"$set":  bson.M{"updatedAt": time.Now().UTC()},
+	}
+
+	result, err := s.agentTasksCollection.UpdateOne(ctx, bson.M{"taskId": agentTaskID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to append change log: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return errcode.New(errcode.NotFound, "agent task with ID %s not found", agentTaskID)
+	}
+
+	return nil
+}
+
+// CloneHumanTask deep-copies a human task and all of its agent tasks and TODOs
+// into a new task tree with fresh IDs and statuses reset to pending.
+// An empty project keeps the source's project; an empty prompt keeps its prompt.
+func (s *MongoTaskStorage) CloneHumanTask(sourceTaskID, project, prompt string) (*HumanTask, []*AgentTask, error) {
+	ctx := context.Background()
+
+	source, err := s.GetHumanTask(sourceTaskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cursor, err := s.agentTasksCollection.Find(ctx, bson.M{"humanTaskId": sourceTaskID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query agent tasks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var sourceAgentTasks []*AgentTask
+	if err := cursor.All(ctx, &sourceAgentTasks); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode agent tasks: %w", err)
+	}
+
+	for _, task := range sourceAgentTasks {
+		if err := s.cipher.OpenAll(agentTaskSecrets(task)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	clone, agentClones := cloneTaskTree(source, sourceAgentTasks, project, prompt, time.Now().UTC())
+
+	cloneDoc, err := s.sealHumanTask(clone)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := s.humanTasksCollection.InsertOne(ctx, cloneDoc); err != nil {
+		return nil, nil, fmt.Errorf("failed to insert cloned human task: %w", err)
+	}
+
+	if len(agentClones) > 0 {
+		docs := make([]interface{}, len(agentClones))
+		for i, task := range agentClones {
+			doc, err := s.sealAgentTask(task)
+			if err != nil {
+				s.humanTasksCollection.DeleteOne(ctx, bson.M{"taskId": clone.ID})
+				return nil, nil, err
+			}
+			docs[i] = doc
+		}
+		if _, err := s.agentTasksCollection.InsertMany(ctx, docs); err != nil {
+			// Roll back so a failed clone does not leave a partial tree behind
+			s.agentTasksCollection.DeleteMany(ctx, bson.M{"humanTaskId": clone.ID})
+			s.humanTasksCollection.DeleteOne(ctx, bson.M{"taskId": clone.ID})
+			return nil, nil, fmt.Errorf("failed to insert cloned agent tasks: %w", err)
+		}
+	}
+	s.publish(humanTaskCreatedEvent(clone))
+	for _, task := range agentClones {
+		s.publish(agentTaskCreatedEvent(task))
+	}
+
+	return clone, agentClones, nil
+}
+
+// SetTaskDueDate sets the due date of any task (human or agent); nil clears it
+func (s *MongoTaskStorage) SetTaskDueDate(taskID string, dueAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"updatedAt": time.Now().UTC()}}
+	if dueAt != nil {
+		update["$set"].(bson.M)["dueAt"] = dueAt.UTC()
+	} else {
+		update["$unset"] = bson.M{"dueAt": ""}
+	}
+
+	for _, collection := range []*mongo.Collection{s.humanTasksCollection, s.agentTasksCollection} {
+		result, err := collection.UpdateOne(ctx, bson.M{"taskId": taskID}, update)
+		if err != nil {
+			return fmt.Errorf("failed to set due date: %w", err)
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+	}
+	return errcode.New(errcode.NotFound, "task with ID %s not found", taskID)
+}
+
+// cloneTaskTree builds the copies for CloneHumanTask. Planning content (prompts,
+// TODO descriptions, context, prompt notes) is kept; progress (statuses, status
+// notes, completion times, change logs) is reset.
+func cloneTaskTree(source *HumanTask, agentTasks []*AgentTask, project, prompt string, now time.Time) (*HumanTask, []*AgentTask) {
+	if project == "" {
+		project = source.Project
+	}
+	if prompt == "" {
+		prompt = source.Prompt
+	}
+
+	clone := &HumanTask{
+		ID:         uuid.New().String(),
+		Prompt:     prompt,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Status:     TaskStatusPending,
+		Project:    project,
+		ClonedFrom: source.ID,
+	}
+
+	agentClones := make([]*AgentTask, 0, len(agentTasks))
+	for _, task := range agentTasks {
+		todos := make([]TodoItem, len(task.Todos))
+		for i, todo := range task.Todos {
+			todos[i] = todo
+			todos[i].ID = uuid.New().String()
+			todos[i].Status = TodoStatusPending
+			todos[i].CreatedAt = now
+			todos[i].CompletedAt = nil
+			todos[i].ActualMinutes = 0
+			todos[i].Checklist = nil
+			for _, item := range todo.Checklist {
+				todos[i].Checklist = append(todos[i].Checklist, ChecklistItem{
+					ID:          uuid.New().String(),
+					Description: item.Description,
+					Status:      TodoStatusPending,
+				})
+			}
+		}
+
+		agentClones = append(agentClones, &AgentTask{
+			ID:                        uuid.New().String(),
+			HumanTaskID:               clone.ID,
+			AgentName:                 task.AgentName,
+			Role:                      task.Role,
+			Todos:                     todos,
+			CreatedAt:                 now,
+			UpdatedAt:                 now,
+			Status:                    TaskStatusPending,
+			ContextSummary:            task.ContextSummary,
+			FilesModified:             append([]string(nil), task.FilesModified...),
+			QdrantCollections:         append([]string(nil), task.QdrantCollections...),
+			PriorWorkSummary:          task.PriorWorkSummary,
+			HumanPromptNotes:          task.HumanPromptNotes,
+			HumanPromptNotesAddedAt:   task.HumanPromptNotesAddedAt,
+			HumanPromptNotesUpdatedAt: task.HumanPromptNotesUpdatedAt,
+			ClonedFrom:                task.ID,
+		})
+	}
+
+	return clone, agentClones
+}
@@ -454,3 +454,109 @@ func TestTodoArrayFilterUpdate(t *testing.T) {
 		t.Error("Second TODO should have UpdatedAt timestamp")
 	}
 }
+
+func TestCloneTaskTree(t *testing.T) {
+	completedAt := time.Now().Add(-time.Hour)
+	source := &HumanTask{
+		ID:      "human-1",
+		Prompt:  "Build the feature",
+		Status:  TaskStatusCompleted,
+		Notes:   "done",
+		Project: "alpha",
+	}
+	agentTasks := []*AgentTask{
+		{
+			ID:               "agent-1",
+			HumanTaskID:      "human-1",
+			AgentName:        "go-dev",
+			Role:             "backend",
+			Status:           TaskStatusCompleted,
+			Notes:            "finished",
+			FilesModified:    []string{"main.go"},
+			HumanPromptNotes: "keep it small",
+			ChangeLog:        []FileChangeEntry{{Path: "main.go", Operation: "update"}},
+			Todos: []TodoItem{
+				{ID: "todo-1", Description: "write code", Status: TodoStatusCompleted, CompletedAt: &completedAt},
+			},
+		},
+	}
+
+	clone, clones := cloneTaskTree(source, agentTasks, "", "", time.Now().UTC())
+
+	if clone.ID == source.ID || clone.ClonedFrom != "human-1" {
+		t.Errorf("clone ID = %s, clonedFrom = %s", clone.ID, clone.ClonedFrom)
+	}
+	if clone.Prompt != source.Prompt || clone.Project != "alpha" {
+		t.Errorf("clone should inherit prompt and project, got %q / %q", clone.Prompt, clone.Project)
+	}
+	if clone.Status != TaskStatusPending || clone.Notes != "" {
+		t.Errorf("clone progress not reset: status=%s notes=%q", clone.Status, clone.Notes)
+	}
+
+	if len(clones) != 1 {
+		t.Fatalf("expected 1 cloned agent task, got %d", len(clones))
+	}
+	agent := clones[0]
+	if agent.ID == "agent-1" || agent.ClonedFrom != "agent-1" || agent.HumanTaskID != clone.ID {
+		t.Errorf("agent clone IDs wrong: id=%s clonedFrom=%s humanTaskId=%s", agent.ID, agent.ClonedFrom, agent.HumanTaskID)
+	}
+	if agent.Status != TaskStatusPending || agent.Notes != "" || len(agent.ChangeLog) != 0 {
+		t.Errorf("agent clone progress not reset: status=%s notes=%q changeLog=%d", agent.Status, agent.Notes, len(agent.ChangeLog))
+	}
+	if agent.HumanPromptNotes != "keep it small" || len(agent.FilesModified) != 1 {
+		t.Error("agent clone should keep planning content")
+	}
+
+	if len(agent.Todos) != 1 {
+		t.Fatalf("expected 1 cloned TODO, got %d", len(agent.Todos))
+	}
+	todo := agent.Todos[0]
+	if todo.ID == "todo-1" || todo.Status != TodoStatusPending || todo.CompletedAt != nil {
+		t.Errorf("TODO clone not reset: id=%s status=%s completedAt=%v", todo.ID, todo.Status, todo.CompletedAt)
+	}
+	if agentTasks[0].Todos[0].Status != TodoStatusCompleted {
+		t.Error("cloning must not modify the source TODOs")
+	}
+
+	moved, _ := cloneTaskTree(source, nil, "beta", "Try another approach", time.Now().UTC())
+	if moved.Project != "beta" || moved.Prompt != "Try another approach" {
+		t.Errorf("overrides not applied: project=%q prompt=%q", moved.Project, moved.Prompt)
+	}
+}
+
+func TestChecklistRollup(t *testing.T) {
+	items := newChecklistItems([]string{"write migration", "", "backfill data"})
+	if len(items) != 2 || items[0].Status != TodoStatusPending || items[0].ID == "" {
+		t.Fatalf("unexpected checklist items: %+v", items)
+	}
+
+	now := time.Now().UTC()
+	if applyChecklistItemStatus(items, "missing", TodoStatusCompleted, "", now) {
+		t.Error("unknown item ID should not be found")
+	}
+
+	applyChecklistItemStatus(items, items[0].ID, TodoStatusInProgress, "started", now)
+	if got := rollupTodoStatus(TodoStatusPending, items); got != TodoStatusInProgress {
+		t.Errorf("started item should move pending TODO to in_progress, got %s", got)
+	}
+
+	applyChecklistItemStatus(items, items[0].ID, TodoStatusCompleted, "", now)
+	applyChecklistItemStatus(items, items[1].ID, TodoStatusCompleted, "", now)
+	if items[0].Notes != "started" || items[0].CompletedAt == nil {
+		t.Errorf("item not updated: %+v", items[0])
+	}
+	if got := rollupTodoStatus(TodoStatusInProgress, items); got != TodoStatusCompleted {
+		t.Errorf("all items completed should complete TODO, got %s", got)
+	}
+
+	applyChecklistItemStatus(items, items[1].ID, TodoStatusPending, "", now)
+	if items[1].CompletedAt != nil {
+		t.Error("reopened item should clear completedAt")
+	}
+	if got := rollupTodoStatus(TodoStatusCompleted, items); got != TodoStatusInProgress {
+		t.Errorf("reopened item should reopen completed TODO, got %s", got)
+	}
+	if got := rollupTodoStatus(TodoStatusPending, nil); got != TodoStatusPending {
+		t.Errorf("TODO without checklist keeps its status, got %s", got)
+	}
+}
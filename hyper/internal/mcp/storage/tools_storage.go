@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"time"
 
+	"hyper/internal/console"
+	"hyper/internal/errcode"
+
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -64,8 +67,8 @@ type ToolsStorage struct {
 // NewToolsStorage creates a new tools storage instance
 func NewToolsStorage(db *mongo.Database, qdrantClient QdrantClientInterface) (*ToolsStorage, error) {
 	storage := &ToolsStorage{
-		toolsCollection:   db.Collection("tools"),
-		serversCollection: db.Collection("mcp_servers"),
+		toolsCollection:   db.Collection(CollectionName("tools")),
+		serversCollection: db.Collection(CollectionName("mcp_servers")),
 		qdrantClient:      qdrantClient,
 	}
 
@@ -164,7 +167,7 @@ func (s *ToolsStorage) StoreToolMetadata(ctx context.Context, toolName, descript
 		// Ensure collection exists with correct dimensions
 		if err := s.qdrantClient.EnsureCollection("mcp-tools", vectorDim); err != nil {
 			// Log error but don't fail - MongoDB has the data
-			fmt.Printf("Warning: failed to ensure Qdrant collection 'mcp-tools': %v\n", err)
+			console.Printf("Warning: failed to ensure Qdrant collection 'mcp-tools': %v\n", err)
 		} else {
 			// Create searchable text combining tool name and description
 			searchableText := fmt.Sprintf("%s: %s", toolName, description)
@@ -177,7 +180,7 @@ func (s *ToolsStorage) StoreToolMetadata(ctx context.Context, toolName, descript
 
 			if err := s.qdrantClient.StorePoint("mcp-tools", metadata.ID, searchableText, pointMetadata); err != nil {
 				// Log error but don't fail - MongoDB has the data
-				fmt.Printf("Warning: failed to store tool in Qdrant: %v\n", err)
+				console.Printf("Warning: failed to store tool in Qdrant: %v\n", err)
 			}
 		}
 	}
@@ -224,7 +227,7 @@ func (s *ToolsStorage) SearchTools(ctx context.Context, query string, limit int)
 		}
 		// Log error but continue to MongoDB fallback
 		if err != nil {
-			fmt.Printf("Warning: Qdrant search failed, falling back to MongoDB: %v\n", err)
+			console.Printf("Warning: Qdrant search failed, falling back to MongoDB: %v\n", err)
 		}
 	}
 
@@ -280,7 +283,7 @@ func (s *ToolsStorage) GetToolSchema(ctx context.Context, toolName string) (*Too
 	err := s.toolsCollection.FindOne(ctx, filter).Decode(&metadata)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("tool not found: %s", toolName)
+			return nil, errcode.New(errcode.NotFound, "tool not found: %s", toolName)
 		}
 		return nil, fmt.Errorf("failed to get tool schema: %w", err)
 	}
@@ -331,7 +334,7 @@ func (s *ToolsStorage) RemoveServer(ctx context.Context, serverName string) erro
 	}
 
 	if result.DeletedCount == 0 {
-		return fmt.Errorf("server not found: %s", serverName)
+		return errcode.New(errcode.NotFound, "server not found: %s", serverName)
 	}
 
 	return nil
@@ -345,7 +348,7 @@ func (s *ToolsStorage) GetServer(ctx context.Context, serverName string) (*Serve
 	err := s.serversCollection.FindOne(ctx, filter).Decode(&server)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("server not found: %s", serverName)
+			return nil, errcode.New(errcode.NotFound, "server not found: %s", serverName)
 		}
 		return nil, fmt.Errorf("failed to get server: %w", err)
 	}
@@ -408,7 +411,7 @@ func (s *ToolsStorage) RemoveServerTools(ctx context.Context, serverName string)
 		for _, toolID := range toolIDs {
 			if err := s.qdrantClient.DeletePoint("mcp-tools", toolID); err != nil {
 				// Log error but don't fail
-				fmt.Printf("Warning: failed to delete tool %s from Qdrant: %v\n", toolID, err)
+				console.Printf("Warning: failed to delete tool %s from Qdrant: %v\n", toolID, err)
 			}
 		}
 	}
@@ -423,9 +426,9 @@ func (s *ToolsStorage) RemoveServerTools(ctx context.Context, serverName string)
 	_, err = s.serversCollection.UpdateOne(ctx, bson.M{"serverName": serverName}, update)
 	if err != nil {
 		// Log error but don't fail - tools are deleted
-		fmt.Printf("Warning: failed to update server tool count: %v\n", err)
+		console.Printf("Warning: failed to update server tool count: %v\n", err)
 	}
 
-	fmt.Printf("Removed %d tools for server %s\n", result.DeletedCount, serverName)
+	console.Printf("Removed %d tools for server %s\n", result.DeletedCount, serverName)
 	return nil
 }
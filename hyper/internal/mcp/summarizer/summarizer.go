@@ -0,0 +1,170 @@
+// Package summarizer generates one-sentence natural-language summaries of code
+// chunks with a local LLM. Summaries are embedded together with the code so
+// intent-level queries ("where do we validate JWTs") match code that never
+// spells out its purpose.
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+)
+
+const (
+	// DefaultModel is a small local code model that summarizes quickly
+	DefaultModel = "qwen2.5-coder:1.5b"
+
+	// maxSummaryLength caps stored summaries in case the model ignores the prompt
+	maxSummaryLength = 300
+)
+
+// Summarizer describes what a code chunk does
+type Summarizer interface {
+	Summarize(ctx context.Context, language, code string) (string, error)
+}
+
+// FromEnv returns an Ollama summarizer when CODE_INDEX_SUMMARIES=true, or nil
+// when summaries are disabled (the default). The model is CODE_SUMMARY_MODEL
+// and the server OLLAMA_URL.
+func FromEnv() Summarizer {
+	if os.Getenv("CODE_INDEX_SUMMARIES") != "true" {
+		return nil
+	}
+	return NewOllamaSummarizer(os.Getenv("OLLAMA_URL"), os.Getenv("CODE_SUMMARY_MODEL"))
+}
+
+// OllamaSummarizer summarizes code with an Ollama generation model
+type OllamaSummarizer struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOllamaSummarizer creates a summarizer; empty arguments select the defaults
+func NewOllamaSummarizer(baseURL, model string) *OllamaSummarizer {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	if model == "" {
+		model = DefaultModel
+	}
+	return &OllamaSummarizer{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+type generateRequest struct {
+	Model   string                 `json:"model"`
+	Prompt  string                 `json:"prompt"`
+	Stream  bool                   `json:"stream"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+type generateResponse struct {
+	Response string `json:"response"`
+}
+
+// Summarize asks the model for a single sentence describing the code's purpose
+func (s *OllamaSummarizer) Summarize(ctx context.Context, language, code string) (string, error) {
+	prompt := fmt.Sprintf("Describe in one sentence what the following %s code does. Mention what it validates, computes or returns. Reply with the sentence only.\n\n%s", language, code)
+
+	body, err := json.Marshal(generateRequest{
+		Model:   s.model,
+		Prompt:  prompt,
+		Stream:  false,
+		Options: map[string]interface{}{"temperature": 0, "num_predict": 80},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ollama returned HTTP %d (is %s pulled?)", resp.StatusCode, s.model)
+	}
+
+	var result generateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return cleanSummary(result.Response), nil
+}
+
+// cleanSummary keeps the first line of a response and bounds its length
+func cleanSummary(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = strings.TrimSpace(text[:i])
+	}
+	if len(text) > maxSummaryLength {
+		text = strings.TrimSpace(text[:maxSummaryLength]) + "…"
+	}
+	return text
+}
+
+// Enrich summarizes a chunk and returns the summary together with the text to
+// embed. With a nil summarizer, blank code or a failed call the code is
+// embedded alone; the error is returned for logging only.
+func Enrich(ctx context.Context, s Summarizer, language, code string) (string, string, error) {
+	if s == nil || strings.TrimSpace(code) == "" {
+		return "", code, nil
+	}
+	summary, err := s.Summarize(ctx, language, code)
+	if err != nil || summary == "" {
+		return "", code, err
+	}
+	return summary, EmbeddingText(summary, code), nil
+}
+
+// EmbeddingText combines a summary and its code into the text that is embedded
+func EmbeddingText(summary, code string) string {
+	if summary == "" {
+		return code
+	}
+	return summary + "\n\n" + code
+}
+
+// EmbedChunk embeds a chunk for its collection. With named vectors the code
+// and the summary are embedded separately (the summary only when there is
+// one); otherwise they are embedded together as one vector. tokens estimates
+// the embedded text.
+func EmbedChunk(ctx context.Context, embedder embeddings.EmbeddingClient, summary, code string, named bool) (vector, summaryVector []float32, tokens int64, err error) {
+	text := code
+	if !named {
+		text = EmbeddingText(summary, code)
+	}
+	if vector, err = embeddings.CreateEmbeddingContext(ctx, embedder, text); err != nil {
+		return nil, nil, 0, err
+	}
+	tokens = embeddings.EstimateTokens(text)
+
+	if named && summary != "" {
+		if summaryVector, err = embeddings.CreateEmbeddingContext(ctx, embedder, summary); err != nil {
+			return nil, nil, 0, fmt.Errorf("failed to embed summary: %w", err)
+		}
+		tokens += embeddings.EstimateTokens(summary)
+	}
+	return vector, summaryVector, tokens, nil
+}
@@ -0,0 +1,100 @@
+package summarizer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOllamaSummarizer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/generate", r.URL.Path)
+		var req generateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "tiny", req.Model)
+		assert.False(t, req.Stream)
+		assert.Contains(t, req.Prompt, "func ValidateJWT")
+
+		json.NewEncoder(w).Encode(generateResponse{Response: "  Validates a JWT signature and expiry.\nExtra chatter"})
+	}))
+	defer srv.Close()
+
+	s := NewOllamaSummarizer(srv.URL, "tiny")
+	summary, err := s.Summarize(context.Background(), "go", "func ValidateJWT(token string) error { ... }")
+	require.NoError(t, err)
+	assert.Equal(t, "Validates a JWT signature and expiry.", summary)
+}
+
+func TestEnrich(t *testing.T) {
+	summary, text, err := Enrich(context.Background(), nil, "go", "x := 1")
+	require.NoError(t, err)
+	assert.Empty(t, summary)
+	assert.Equal(t, "x := 1", text)
+
+	fake := summarizeFunc(func(ctx context.Context, language, code string) (string, error) {
+		return "Sets x.", nil
+	})
+	summary, text, err = Enrich(context.Background(), fake, "go", "x := 1")
+	require.NoError(t, err)
+	assert.Equal(t, "Sets x.", summary)
+	assert.Equal(t, "Sets x.\n\nx := 1", text)
+}
+
+func TestEmbedChunk(t *testing.T) {
+	embedder := &recordingEmbedder{}
+
+	vector, summaryVector, tokens, err := EmbedChunk(context.Background(), embedder, "Sets x.", "x := 1", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x := 1", "Sets x."}, embedder.texts)
+	assert.NotEmpty(t, vector)
+	assert.NotEmpty(t, summaryVector)
+	assert.Equal(t, int64(4), tokens)
+
+	embedder.texts = nil
+	_, summaryVector, _, err = EmbedChunk(context.Background(), embedder, "Sets x.", "x := 1", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Sets x.\n\nx := 1"}, embedder.texts, "collections without named vectors embed summary and code together")
+	assert.Nil(t, summaryVector)
+
+	embedder.texts = nil
+	_, summaryVector, _, err = EmbedChunk(context.Background(), embedder, "", "x := 1", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x := 1"}, embedder.texts)
+	assert.Nil(t, summaryVector)
+}
+
+func TestCleanSummaryBoundsLength(t *testing.T) {
+	summary := cleanSummary(strings.Repeat("a", 500))
+	assert.LessOrEqual(t, len(summary), maxSummaryLength+len("…"))
+}
+
+type summarizeFunc func(ctx context.Context, language, code string) (string, error)
+
+func (f summarizeFunc) Summarize(ctx context.Context, language, code string) (string, error) {
+	return f(ctx, language, code)
+}
+
+type recordingEmbedder struct {
+	texts []string
+}
+
+func (e *recordingEmbedder) CreateEmbedding(text string) ([]float32, error) {
+	e.texts = append(e.texts, text)
+	return []float32{float32(len(text)), 1}, nil
+}
+
+func (e *recordingEmbedder) CreateEmbeddings(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = e.CreateEmbedding(text)
+	}
+	return vectors, nil
+}
+
+func (e *recordingEmbedder) GetDimensions() int { return 2 }
@@ -0,0 +1,60 @@
+package watcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"hyper/internal/mcp/scanner"
+	"hyper/internal/mcp/storage"
+)
+
+// chunkPlan describes how to bring a file's stored chunks in line with its new content
+type chunkPlan struct {
+	// reuse maps a new chunk index to the stored chunk with identical content,
+	// whose vector can be kept instead of re-embedding
+	reuse map[int]*storage.FileChunk
+	// stale lists stored chunks with no identical new chunk; their points must be deleted
+	stale []*storage.FileChunk
+}
+
+// hashChunkContent returns the SHA-256 hex digest of a chunk's content
+func hashChunkContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// planChunkUpdates matches new chunks against stored chunks by content hash.
+// Identical content is matched even if it moved to a different chunk position,
+// so a one-line edit only re-embeds the chunk(s) that actually changed.
+func planChunkUpdates(oldChunks []*storage.FileChunk, newChunks []scanner.ChunkContent) chunkPlan {
+	plan := chunkPlan{reuse: make(map[int]*storage.FileChunk)}
+
+	// Queue stored chunks by hash; duplicates are consumed in order
+	byHash := make(map[string][]*storage.FileChunk)
+	for _, chunk := range oldChunks {
+		if chunk.VectorID == "" {
+			plan.stale = append(plan.stale, chunk)
+			continue
+		}
+		hash := chunk.ContentHash
+		if hash == "" {
+			// Chunks indexed before hashes were stored
+			hash = hashChunkContent(chunk.Content)
+		}
+		byHash[hash] = append(byHash[hash], chunk)
+	}
+
+	for i, chunk := range newChunks {
+		hash := hashChunkContent(chunk.Content)
+		if queue := byHash[hash]; len(queue) > 0 {
+			plan.reuse[i] = queue[0]
+			byHash[hash] = queue[1:]
+		}
+	}
+
+	for _, queue := range byHash {
+		plan.stale = append(plan.stale, queue...)
+	}
+
+	return plan
+}
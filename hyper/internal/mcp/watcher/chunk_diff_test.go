@@ -0,0 +1,68 @@
+package watcher
+
+import (
+	"testing"
+
+	"hyper/internal/mcp/scanner"
+	"hyper/internal/mcp/storage"
+)
+
+func TestPlanChunkUpdates_OnlyChangedChunksReembedded(t *testing.T) {
+	oldChunks := []*storage.FileChunk{
+		{ChunkNum: 0, Content: "package main\n", VectorID: "v0"},
+		{ChunkNum: 1, Content: "func a() {}\n", VectorID: "v1", ContentHash: hashChunkContent("func a() {}\n")},
+		{ChunkNum: 2, Content: "func b() {}\n", VectorID: "v2"},
+	}
+	newChunks := []scanner.ChunkContent{
+		{Content: "package main\n"},
+		{Content: "func a() { return }\n"},
+		{Content: "func b() {}\n"},
+	}
+
+	plan := planChunkUpdates(oldChunks, newChunks)
+
+	if len(plan.reuse) != 2 {
+		t.Fatalf("expected 2 reused chunks, got %d", len(plan.reuse))
+	}
+	if plan.reuse[0].VectorID != "v0" || plan.reuse[2].VectorID != "v2" {
+		t.Fatalf("unexpected reuse mapping: %+v", plan.reuse)
+	}
+	if _, ok := plan.reuse[1]; ok {
+		t.Fatal("modified chunk must not be reused")
+	}
+	if len(plan.stale) != 1 || plan.stale[0].VectorID != "v1" {
+		t.Fatalf("expected v1 to be stale, got %+v", plan.stale)
+	}
+}
+
+func TestPlanChunkUpdates_MovedAndDuplicateChunks(t *testing.T) {
+	oldChunks := []*storage.FileChunk{
+		{ChunkNum: 0, Content: "dup\n", VectorID: "v0"},
+		{ChunkNum: 1, Content: "dup\n", VectorID: "v1"},
+		{ChunkNum: 2, Content: "tail\n", VectorID: "v2"},
+	}
+	newChunks := []scanner.ChunkContent{
+		{Content: "tail\n"},
+		{Content: "dup\n"},
+	}
+
+	plan := planChunkUpdates(oldChunks, newChunks)
+
+	if plan.reuse[0].VectorID != "v2" {
+		t.Fatalf("expected moved chunk to reuse v2, got %s", plan.reuse[0].VectorID)
+	}
+	if plan.reuse[1].VectorID != "v0" {
+		t.Fatalf("expected first duplicate to be reused, got %s", plan.reuse[1].VectorID)
+	}
+	if len(plan.stale) != 1 || plan.stale[0].VectorID != "v1" {
+		t.Fatalf("expected surplus duplicate to be stale, got %+v", plan.stale)
+	}
+}
+
+func TestPlanChunkUpdates_NoStoredChunks(t *testing.T) {
+	plan := planChunkUpdates(nil, []scanner.ChunkContent{{Content: "new\n"}})
+
+	if len(plan.reuse) != 0 || len(plan.stale) != 0 {
+		t.Fatalf("expected empty plan, got %+v", plan)
+	}
+}
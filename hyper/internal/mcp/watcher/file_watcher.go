@@ -7,11 +7,14 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"hyper/internal/mcp/embeddings"
 	"hyper/internal/mcp/scanner"
 	"hyper/internal/mcp/storage"
+	"hyper/internal/mcp/summarizer"
+	"hyper/internal/priority"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/google/uuid"
@@ -25,6 +28,7 @@ type FileWatcher struct {
 	qdrantClient    *storage.QdrantClient
 	embeddingClient embeddings.EmbeddingClient
 	pathMapper      *PathMapper
+	summarizer      summarizer.Summarizer // nil unless CODE_INDEX_SUMMARIES=true
 	logger          *zap.Logger
 
 	// Debouncing
@@ -36,6 +40,15 @@ type FileWatcher struct {
 	watchedFolders  map[string]*storage.IndexedFolder
 	foldersMutex    sync.RWMutex
 
+	// Optional correlation of file events with in-progress agent tasks
+	changeTracker   *TaskChangeTracker
+
+	// Optional embedding of modification diffs for recent change search
+	recentChanges   *RecentChangeIndexer
+
+	// Unix nanoseconds of the event loop's last sign of life; 0 until started
+	heartbeat       atomic.Int64
+
 	// Control
 	ctx             context.Context
 	cancel          context.CancelFunc
@@ -55,7 +68,8 @@ func NewFileWatcher(
 		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	// Re-indexing is bulk work: it yields to interactive searches
+	ctx, cancel := context.WithCancel(priority.WithLevel(context.Background(), priority.Bulk))
 
 	fw := &FileWatcher{
 		watcher:         watcher,
@@ -63,6 +77,7 @@ func NewFileWatcher(
 		qdrantClient:    qdrantClient,
 		embeddingClient: embeddingClient,
 		pathMapper:      pathMapper,
+		summarizer:      summarizer.FromEnv(),
 		logger:          logger,
 		debounceTime:    500 * time.Millisecond,
 		debounceTimers:  make(map[string]*time.Timer),
@@ -74,6 +89,33 @@ func NewFileWatcher(
 	return fw, nil
 }
 
+// SetChangeTracker enables per-task change logging for observed file events
+func (fw *FileWatcher) SetChangeTracker(tracker *TaskChangeTracker) {
+	fw.changeTracker = tracker
+}
+
+// SetRecentChangeIndexer enables embedding of added/removed lines on file
+// modifications; a nil indexer leaves it disabled
+func (fw *FileWatcher) SetRecentChangeIndexer(indexer *RecentChangeIndexer) {
+	fw.recentChanges = indexer
+}
+
+// recordChange forwards a processed file event to the change tracker, if configured
+func (fw *FileWatcher) recordChange(path string, folder *storage.IndexedFolder, operation string, change storage.FileChangeEntry) {
+	if fw.changeTracker == nil {
+		return
+	}
+
+	relativePath, err := filepath.Rel(folder.Path, path)
+	if err != nil {
+		relativePath = path
+	}
+
+	change.Path = path
+	change.Operation = operation
+	fw.changeTracker.RecordChange(relativePath, change)
+}
+
 // Start begins watching all indexed folders
 func (fw *FileWatcher) Start() error {
 	// Check if file watcher is disabled via ENV
@@ -294,20 +336,49 @@ func (fw *FileWatcher) RemoveFolder(folderPath string) error {
 	return nil
 }
 
+// HeartbeatInterval is how often an idle event loop refreshes its heartbeat
+const HeartbeatInterval = 30 * time.Second
+
+// Heartbeat returns when the event loop last showed it was alive, or the zero
+// time if the watcher is not running
+func (fw *FileWatcher) Heartbeat() time.Time {
+	nanos := fw.heartbeat.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// WatchedFolderCount returns the number of folders being watched
+func (fw *FileWatcher) WatchedFolderCount() int {
+	fw.foldersMutex.RLock()
+	defer fw.foldersMutex.RUnlock()
+	return len(fw.watchedFolders)
+}
+
 // processEvents processes file system events
 func (fw *FileWatcher) processEvents() {
 	defer fw.wg.Done()
+	defer fw.heartbeat.Store(0)
+
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	fw.heartbeat.Store(time.Now().UnixNano())
 
 	for {
 		select {
 		case <-fw.ctx.Done():
 			return
 
+		case <-ticker.C:
+			fw.heartbeat.Store(time.Now().UnixNano())
+
 		case event, ok := <-fw.watcher.Events:
 			if !ok {
 				return
 			}
 
+			fw.heartbeat.Store(time.Now().UnixNano())
 			fw.handleEvent(event)
 
 		case err, ok := <-fw.watcher.Errors:
@@ -407,7 +478,7 @@ func (fw *FileWatcher) handleCreate(path string, folder *storage.IndexedFolder)
 
 	// If it's a code file, index it
 	if scanner.IsCodeFile(path) {
-		fw.indexFile(path, folder)
+		fw.recordChange(path, folder, "create", fw.indexFile(path, folder))
 	}
 }
 
@@ -419,7 +490,7 @@ func (fw *FileWatcher) handleUpdate(path string, folder *storage.IndexedFolder)
 	}
 
 	// Re-index the file
-	fw.indexFile(path, folder)
+	fw.recordChange(path, folder, "update", fw.indexFile(path, folder))
 }
 
 // handleDelete handles file deletion events
@@ -439,6 +510,12 @@ func (fw *FileWatcher) handleDelete(path string, folder *storage.IndexedFolder)
 	if file == nil {
 		return
 	}
+	defer func() {
+		fw.recordChange(path, folder, "delete", storage.FileChangeEntry{
+			ChunksRemoved: file.ChunkCount,
+			LinesRemoved:  file.LineCount,
+		})
+	}()
 
 	// Get all chunks for this file
 	chunks, err := fw.mongoStorage.ListChunks(file.ID)
@@ -477,7 +554,13 @@ func (fw *FileWatcher) handleDelete(path string, folder *storage.IndexedFolder)
 }
 
 // indexFile indexes or re-indexes a single file
-func (fw *FileWatcher) indexFile(path string, folder *storage.IndexedFolder) {
+// Returns the chunks embedded and removed and, when a change tracker is set, the lines added and removed
+func (fw *FileWatcher) indexFile(path string, folder *storage.IndexedFolder) storage.FileChangeEntry {
+	return fw.indexFileWith(path, folder, fw.embeddingClient)
+}
+
+// indexFileWith indexes a file, embedding changed chunks with embedder
+func (fw *FileWatcher) indexFileWith(path string, folder *storage.IndexedFolder, embedder embeddings.EmbeddingClient) storage.FileChangeEntry {
 	fw.logger.Info("Indexing file",
 		zap.String("path", path),
 		zap.String("folderId", folder.ID))
@@ -488,7 +571,7 @@ func (fw *FileWatcher) indexFile(path string, folder *storage.IndexedFolder) {
 		fw.logger.Error("Failed to scan file",
 			zap.String("path", path),
 			zap.Error(err))
-		return
+		return storage.FileChangeEntry{}
 	}
 
 	// Check if file already exists
@@ -497,21 +580,49 @@ func (fw *FileWatcher) indexFile(path string, folder *storage.IndexedFolder) {
 		fw.logger.Error("Failed to check existing file",
 			zap.String("path", path),
 			zap.Error(err))
-		return
+		return storage.FileChangeEntry{}
 	}
 
-	// Skip if file hasn't changed
+	// Skip if file hasn't changed, extracting symbols if it predates them
 	if existingFile != nil && existingFile.SHA256 == fileInfo.SHA256 {
 		fw.logger.Debug("File unchanged, skipping",
 			zap.String("path", path))
-		return
+		if existingFile.SymbolsAt.IsZero() {
+			fw.indexSymbols(existingFile, scannedContent(fileInfo.Chunks))
+		}
+		return storage.FileChangeEntry{}
+	}
+
+	// Embed for the vector layout of the collection points are stored in
+	namedVectors, err := fw.qdrantClient.UsesNamedVectors(fw.ctx, storage.CodeIndexCollection)
+	if err != nil {
+		fw.logger.Error("Failed to read code index collection",
+			zap.String("path", path),
+			zap.Error(err))
+		return storage.FileChangeEntry{}
 	}
 
-	// Delete old chunks and vectors if file exists
+	// Diff against stored chunks so only changed chunks are re-embedded
+	var plan chunkPlan
+	var oldContent string
+	chunksRemoved := 0
 	if existingFile != nil {
-		chunks, _ := fw.mongoStorage.ListChunks(existingFile.ID)
-		for _, chunk := range chunks {
-			fw.qdrantClient.DeleteCodeIndexPoint(chunk.VectorID)
+		oldChunks, _ := fw.mongoStorage.ListChunks(existingFile.ID)
+		plan = planChunkUpdates(oldChunks, fileInfo.Chunks)
+		oldContent = chunksContent(oldChunks)
+
+		// Delete vectors for chunks whose content no longer exists in the file
+		for _, chunk := range plan.stale {
+			if chunk.VectorID == "" {
+				continue
+			}
+			if err := fw.qdrantClient.DeleteCodeIndexPoint(chunk.VectorID); err != nil {
+				fw.logger.Warn("Failed to delete stale vector",
+					zap.String("vectorId", chunk.VectorID),
+					zap.Error(err))
+				continue
+			}
+			chunksRemoved++
 		}
 	}
 
@@ -543,13 +654,62 @@ func (fw *FileWatcher) indexFile(path string, folder *storage.IndexedFolder) {
 		fw.logger.Error("Failed to upsert file",
 			zap.String("path", path),
 			zap.Error(err))
-		return
+		return storage.FileChangeEntry{}
 	}
 
 	// Index chunks
+	chunksIndexed := 0
+	chunksReused := 0
 	for i, chunkContent := range fileInfo.Chunks {
-		// Generate embedding
-		embedding, err := fw.embeddingClient.CreateEmbedding(chunkContent.Content)
+		contentHash := hashChunkContent(chunkContent.Content)
+
+		// Unchanged chunk: keep its vector, only refresh position metadata if it moved
+		if reused, ok := plan.reuse[i]; ok {
+			if reused.ChunkNum != i || reused.StartLine != chunkContent.StartLine || reused.EndLine != chunkContent.EndLine {
+				positionPayload := map[string]interface{}{
+					"chunkNum":  i,
+					"startLine": chunkContent.StartLine,
+					"endLine":   chunkContent.EndLine,
+				}
+				if err := fw.qdrantClient.SetCodeIndexPointPayload(reused.VectorID, positionPayload); err != nil {
+					fw.logger.Warn("Failed to update moved chunk payload",
+						zap.String("vectorId", reused.VectorID),
+						zap.Error(err))
+				}
+			}
+
+			chunk := &storage.FileChunk{
+				FileID:      file.ID,
+				ChunkNum:    i,
+				Content:     chunkContent.Content,
+				StartLine:   chunkContent.StartLine,
+				EndLine:     chunkContent.EndLine,
+				VectorID:    reused.VectorID,
+				ContentHash: contentHash,
+				Summary:     reused.Summary,
+			}
+			if err := fw.mongoStorage.UpsertChunk(chunk); err != nil {
+				fw.logger.Error("Failed to upsert chunk",
+					zap.String("path", path),
+					zap.Int("chunk", i),
+					zap.Error(err))
+				continue
+			}
+			chunksReused++
+			continue
+		}
+
+		// Optionally summarize, then embed code and summary for the collection's vector layout
+		summary, _, err := summarizer.Enrich(fw.ctx, fw.summarizer, file.Language, chunkContent.Content)
+		if err != nil {
+			fw.logger.Warn("Failed to summarize chunk",
+				zap.String("path", path),
+				zap.Int("chunk", i),
+				zap.Error(err))
+		}
+
+		// Generate embeddings
+		embedding, summaryEmbedding, _, err := summarizer.EmbedChunk(fw.ctx, embedder, summary, chunkContent.Content, namedVectors)
 		if err != nil {
 			fw.logger.Error("Failed to create embedding",
 				zap.String("path", path),
@@ -576,8 +736,13 @@ func (fw *FileWatcher) indexFile(path string, folder *storage.IndexedFolder) {
 			"endLine":      chunkContent.EndLine,
 			"content":      chunkContent.Content,
 		}
+		if summary != "" {
+			payload["summary"] = summary
+		}
+		storage.TagCommentLanguage(payload, chunkContent.Content)
 
-		if err := fw.qdrantClient.UpsertCodeIndexPoint(vectorID, embedding, payload); err != nil {
+		point := storage.CodeIndexPoint{ID: vectorID, Vector: embedding, SummaryVector: summaryEmbedding, Payload: payload}
+		if err := fw.qdrantClient.UpsertCodeIndexPoints(storage.CodeIndexCollection, []storage.CodeIndexPoint{point}); err != nil {
 			fw.logger.Error("Failed to upsert vector",
 				zap.String("vectorId", vectorID),
 				zap.Error(err))
@@ -586,12 +751,14 @@ func (fw *FileWatcher) indexFile(path string, folder *storage.IndexedFolder) {
 
 		// Store chunk in MongoDB
 		chunk := &storage.FileChunk{
-			FileID:    file.ID,
-			ChunkNum:  i,
-			Content:   chunkContent.Content,
-			StartLine: chunkContent.StartLine,
-			EndLine:   chunkContent.EndLine,
-			VectorID:  vectorID,
+			FileID:      file.ID,
+			ChunkNum:    i,
+			Content:     chunkContent.Content,
+			StartLine:   chunkContent.StartLine,
+			EndLine:     chunkContent.EndLine,
+			VectorID:    vectorID,
+			ContentHash: contentHash,
+			Summary:     summary,
 		}
 
 		if err := fw.mongoStorage.UpsertChunk(chunk); err != nil {
@@ -599,16 +766,54 @@ func (fw *FileWatcher) indexFile(path string, folder *storage.IndexedFolder) {
 				zap.String("path", path),
 				zap.Int("chunk", i),
 				zap.Error(err))
+			continue
+		}
+		chunksIndexed++
+	}
+
+	// Drop chunk records beyond the new chunk count (file got shorter)
+	if existingFile != nil {
+		if err := fw.mongoStorage.DeleteChunksFrom(file.ID, len(fileInfo.Chunks)); err != nil {
+			fw.logger.Warn("Failed to delete trailing chunks",
+				zap.String("path", path),
+				zap.Error(err))
 		}
 	}
 
+	fw.indexSymbols(file, scannedContent(fileInfo.Chunks))
+
+	// Embed what changed for recent change search
+	if existingFile != nil && fw.recentChanges != nil {
+		fw.recentChanges.Record(folder, file, oldContent, scannedContent(fileInfo.Chunks))
+	}
+
 	// Update folder file count
 	files, _ := fw.mongoStorage.ListFiles(folder.ID)
 	fw.mongoStorage.UpdateFolderScanTime(folder.ID, len(files))
 
 	fw.logger.Info("File indexed successfully",
 		zap.String("path", path),
-		zap.Int("chunks", len(fileInfo.Chunks)))
+		zap.Int("chunks", len(fileInfo.Chunks)),
+		zap.Int("chunksEmbedded", chunksIndexed),
+		zap.Int("chunksReused", chunksReused),
+		zap.Int("chunksRemoved", chunksRemoved))
+
+	change := storage.FileChangeEntry{ChunksIndexed: chunksIndexed, ChunksRemoved: chunksRemoved}
+	if fw.changeTracker != nil {
+		change.LinesAdded, change.LinesRemoved = lineStats(oldContent, scannedContent(fileInfo.Chunks))
+	}
+	return change
+}
+
+// indexSymbols replaces the stored symbols of a file with those its content
+// declares
+func (fw *FileWatcher) indexSymbols(file *storage.IndexedFile, content string) {
+	symbols := scanner.ExtractSymbols(file.Language, content)
+	if err := fw.mongoStorage.ReplaceFileSymbols(file, symbols); err != nil {
+		fw.logger.Warn("Failed to index symbols",
+			zap.String("path", file.Path),
+			zap.Error(err))
+	}
 }
 
 // findFolder finds which indexed folder a file belongs to
@@ -713,32 +918,38 @@ func (fw *FileWatcher) ScanFolder(folder *storage.IndexedFolder) error {
 		return fmt.Errorf("failed to scan directory: %w", err)
 	}
 
+	var mu sync.Mutex
 	filesIndexed := 0
 	filesUpdated := 0
 	filesSkipped := 0
 
-	// Process each file
-	for _, scannedFile := range scannedFiles {
+	// Index files concurrently, as many at once as system load allows
+	limiter := scanner.NewFolderLimiter(folder)
+	embedder := limiter.Embedder(fw.embeddingClient)
+	limiter.Run(fw.ctx, len(scannedFiles), func(i int) {
+		scannedFile := scannedFiles[i]
 		scannedFile.FolderID = folder.ID
 
 		// Check if file already exists
 		existingFile, _ := fw.mongoStorage.GetFileByPath(scannedFile.Path)
 
+		mu.Lock()
 		if existingFile != nil {
 			// Check if file has changed
 			if existingFile.SHA256 == scannedFile.SHA256 {
 				filesSkipped++
-				continue
+				mu.Unlock()
+				return
 			}
 			filesUpdated++
 			scannedFile.ID = existingFile.ID
 		} else {
 			filesIndexed++
 		}
+		mu.Unlock()
 
-		// Index the file using the file watcher's indexFile method
-		fw.indexFile(scannedFile.Path, folder)
-	}
+		fw.indexFileWith(scannedFile.Path, folder, embedder)
+	})
 
 	// Update folder status and scan time
 	if err := fw.mongoStorage.UpdateFolderStatus(folder.ID, "active", ""); err != nil {
@@ -755,7 +966,8 @@ func (fw *FileWatcher) ScanFolder(folder *storage.IndexedFolder) error {
 		zap.Int("filesIndexed", filesIndexed),
 		zap.Int("filesUpdated", filesUpdated),
 		zap.Int("filesSkipped", filesSkipped),
-		zap.Int("totalFiles", len(scannedFiles)))
+		zap.Int("totalFiles", len(scannedFiles)),
+		zap.Int("peakConcurrency", limiter.Stats().Peak))
 
 	return nil
 }
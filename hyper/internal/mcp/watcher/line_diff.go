@@ -0,0 +1,106 @@
+package watcher
+
+import "strings"
+
+// maxDiffCells bounds the LCS table; larger changed regions are reported as one hunk
+const maxDiffCells = 1 << 20
+
+// diffHunk is a contiguous run of removed and added lines
+type diffHunk struct {
+	OldStart int // 1-based line in the old content where the hunk starts
+	NewStart int // 1-based line in the new content where the hunk starts
+	Removed  []string
+	Added    []string
+}
+
+// splitLines splits content into lines without their trailing newline
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// diffLines computes the hunks that turn oldLines into newLines. The common
+// prefix and suffix are trimmed first, so typical local edits stay cheap.
+func diffLines(oldLines, newLines []string) []diffHunk {
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	oldMid := oldLines[prefix : len(oldLines)-suffix]
+	newMid := newLines[prefix : len(newLines)-suffix]
+	if len(oldMid) == 0 && len(newMid) == 0 {
+		return nil
+	}
+
+	if (len(oldMid)+1)*(len(newMid)+1) > maxDiffCells {
+		return []diffHunk{{OldStart: prefix + 1, NewStart: prefix + 1, Removed: oldMid, Added: newMid}}
+	}
+
+	// lcs[i][j] is the LCS length of oldMid[i:] and newMid[j:]
+	lcs := make([][]int, len(oldMid)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newMid)+1)
+	}
+	for i := len(oldMid) - 1; i >= 0; i-- {
+		for j := len(newMid) - 1; j >= 0; j-- {
+			if oldMid[i] == newMid[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var hunks []diffHunk
+	var current *diffHunk
+	flush := func() {
+		if current != nil {
+			hunks = append(hunks, *current)
+			current = nil
+		}
+	}
+	start := func(i, j int) *diffHunk {
+		if current == nil {
+			current = &diffHunk{OldStart: prefix + i + 1, NewStart: prefix + j + 1}
+		}
+		return current
+	}
+
+	i, j := 0, 0
+	for i < len(oldMid) || j < len(newMid) {
+		switch {
+		case i < len(oldMid) && j < len(newMid) && oldMid[i] == newMid[j]:
+			flush()
+			i++
+			j++
+		case j < len(newMid) && (i == len(oldMid) || lcs[i][j+1] >= lcs[i+1][j]):
+			h := start(i, j)
+			h.Added = append(h.Added, newMid[j])
+			j++
+		default:
+			h := start(i, j)
+			h.Removed = append(h.Removed, oldMid[i])
+			i++
+		}
+	}
+	flush()
+
+	return hunks
+}
+
+// lineStats counts the lines added and removed between two versions of a file
+func lineStats(oldContent, newContent string) (added, removed int) {
+	for _, hunk := range diffLines(splitLines(oldContent), splitLines(newContent)) {
+		added += len(hunk.Added)
+		removed += len(hunk.Removed)
+	}
+	return added, removed
+}
@@ -0,0 +1,43 @@
+package watcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	old := splitLines("package auth\n\nfunc Login() {\n\treturn nil\n}\n")
+	updated := splitLines("package auth\n\nfunc Login() {\n\tcheckToken()\n\treturn nil\n}\n\nfunc Logout() {}\n")
+
+	hunks := diffLines(old, updated)
+	want := []diffHunk{
+		{OldStart: 4, NewStart: 4, Added: []string{"\tcheckToken()"}},
+		{OldStart: 6, NewStart: 7, Added: []string{"", "func Logout() {}"}},
+	}
+	if !reflect.DeepEqual(hunks, want) {
+		t.Fatalf("diffLines() = %+v, want %+v", hunks, want)
+	}
+}
+
+func TestDiffLinesReplace(t *testing.T) {
+	hunks := diffLines([]string{"a", "b", "c"}, []string{"a", "x", "c"})
+	want := []diffHunk{{OldStart: 2, NewStart: 2, Removed: []string{"b"}, Added: []string{"x"}}}
+	if !reflect.DeepEqual(hunks, want) {
+		t.Fatalf("diffLines() = %+v, want %+v", hunks, want)
+	}
+
+	if hunks := diffLines([]string{"a"}, []string{"a"}); hunks != nil {
+		t.Errorf("expected no hunks for identical content, got %+v", hunks)
+	}
+}
+
+func TestLineStats(t *testing.T) {
+	added, removed := lineStats("a\nb\nc\n", "a\nx\nc\nd\n")
+	if added != 2 || removed != 1 {
+		t.Errorf("lineStats() = %d added, %d removed, want 2 and 1", added, removed)
+	}
+
+	if added, removed := lineStats("", "a\nb\n"); added != 2 || removed != 0 {
+		t.Errorf("lineStats() for a new file = %d added, %d removed, want 2 and 0", added, removed)
+	}
+}
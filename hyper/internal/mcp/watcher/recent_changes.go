@@ -0,0 +1,232 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/scanner"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/priority"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRecentChangesRetention is how long embedded diffs are kept
+	DefaultRecentChangesRetention = 14 * 24 * time.Hour
+
+	// maxHunksPerChange bounds the embeddings created for a single file event
+	maxHunksPerChange = 20
+	// maxHunkTextBytes truncates very large hunks before embedding
+	maxHunkTextBytes = 4000
+	// recentChangesPruneInterval throttles deletion of expired diffs
+	recentChangesPruneInterval = time.Hour
+)
+
+// RecentChangeIndexer embeds the added and removed lines of file modifications
+// into a rolling Qdrant collection, so agents can search what changed recently
+type RecentChangeIndexer struct {
+	qdrantClient    *storage.QdrantClient
+	embeddingClient embeddings.EmbeddingClient
+	retention       time.Duration
+	logger          *zap.Logger
+
+	mu         sync.Mutex
+	ensured    bool
+	lastPruned time.Time
+}
+
+// NewRecentChangeIndexer creates a recent change indexer. Retention comes from
+// RECENT_CHANGES_RETENTION_DAYS (default 14); 0 disables it and returns nil.
+func NewRecentChangeIndexer(qdrantClient *storage.QdrantClient, embeddingClient embeddings.EmbeddingClient, logger *zap.Logger) *RecentChangeIndexer {
+	retention := DefaultRecentChangesRetention
+	if raw := os.Getenv("RECENT_CHANGES_RETENTION_DAYS"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 {
+			logger.Warn("Invalid RECENT_CHANGES_RETENTION_DAYS, using default",
+				zap.String("value", raw),
+				zap.Duration("retention", retention))
+		} else {
+			retention = time.Duration(days) * 24 * time.Hour
+		}
+	}
+	if retention == 0 {
+		logger.Info("Recent change indexing disabled (RECENT_CHANGES_RETENTION_DAYS=0)")
+		return nil
+	}
+
+	return &RecentChangeIndexer{
+		qdrantClient:    qdrantClient,
+		embeddingClient: embeddingClient,
+		retention:       retention,
+		logger:          logger,
+	}
+}
+
+// Record embeds the hunks between the old and new content of a modified file
+func (r *RecentChangeIndexer) Record(folder *storage.IndexedFolder, file *storage.IndexedFile, oldContent, newContent string) {
+	hunks := diffLines(splitLines(oldContent), splitLines(newContent))
+	if len(hunks) == 0 {
+		return
+	}
+	if len(hunks) > maxHunksPerChange {
+		r.logger.Debug("Truncating recent change hunks",
+			zap.String("path", file.Path),
+			zap.Int("hunks", len(hunks)))
+		hunks = hunks[:maxHunksPerChange]
+	}
+
+	if err := r.ensureCollection(); err != nil {
+		r.logger.Warn("Failed to ensure recent changes collection", zap.Error(err))
+		return
+	}
+
+	// Recording changes is bulk work: it yields to interactive searches
+	ctx := priority.WithLevel(context.Background(), priority.Bulk)
+	changedAt := time.Now().UTC()
+	points := make([]storage.CodeIndexPoint, 0, len(hunks))
+	for _, hunk := range hunks {
+		text := hunkText(file.RelativePath, file.Language, hunk)
+		embedding, err := embeddings.CreateEmbeddingContext(ctx, r.embeddingClient, text)
+		if err != nil {
+			r.logger.Warn("Failed to embed recent change",
+				zap.String("path", file.Path),
+				zap.Int("line", hunk.NewStart),
+				zap.Error(err))
+			continue
+		}
+
+		points = append(points, storage.CodeIndexPoint{
+			ID:     uuid.New().String(),
+			Vector: embedding,
+			Payload: map[string]interface{}{
+				"fileId":        file.ID,
+				"folderId":      folder.ID,
+				"folderPath":    folder.Path,
+				"filePath":      file.Path,
+				"relativePath":  file.RelativePath,
+				"language":      file.Language,
+				"changedAt":     changedAt.Format(time.RFC3339),
+				"changedAtUnix": changedAt.Unix(),
+				"startLine":     hunk.NewStart,
+				"oldStartLine":  hunk.OldStart,
+				"added":         strings.Join(hunk.Added, "\n"),
+				"removed":       strings.Join(hunk.Removed, "\n"),
+				"linesAdded":    len(hunk.Added),
+				"linesRemoved":  len(hunk.Removed),
+			},
+		})
+	}
+	if len(points) == 0 {
+		return
+	}
+
+	if err := r.qdrantClient.UpsertCodeIndexPointsContext(ctx, storage.RecentChangesCollection, points); err != nil {
+		var dimErr *storage.DimensionMismatchError
+		if errors.As(err, &dimErr) {
+			// The embedding provider changed; the rolling history is disposable
+			r.logger.Warn("Recreating recent changes collection after embedding dimension change", zap.Error(err))
+			r.mu.Lock()
+			r.ensured = false
+			r.mu.Unlock()
+			if err := r.qdrantClient.DeleteCollection(storage.RecentChangesCollection); err != nil {
+				r.logger.Warn("Failed to delete recent changes collection", zap.Error(err))
+			}
+			return
+		}
+		r.logger.Warn("Failed to store recent changes",
+			zap.String("path", file.Path),
+			zap.Error(err))
+		return
+	}
+
+	r.logger.Debug("Recorded recent change",
+		zap.String("path", file.Path),
+		zap.Int("hunks", len(points)))
+
+	r.pruneExpired()
+}
+
+// ensureCollection creates the recent changes collection on first use
+func (r *RecentChangeIndexer) ensureCollection() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ensured {
+		return nil
+	}
+	if err := r.qdrantClient.EnsureCollection(storage.RecentChangesCollection, r.embeddingClient.GetDimensions()); err != nil {
+		return err
+	}
+	r.ensured = true
+	return nil
+}
+
+// pruneExpired deletes diffs older than the retention window, at most hourly
+func (r *RecentChangeIndexer) pruneExpired() {
+	r.mu.Lock()
+	if time.Since(r.lastPruned) < recentChangesPruneInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastPruned = time.Now()
+	r.mu.Unlock()
+
+	cutoff := time.Now().Add(-r.retention).Unix()
+	filter := map[string]interface{}{
+		"must": []map[string]interface{}{
+			{"key": "changedAtUnix", "range": map[string]interface{}{"lt": cutoff}},
+		},
+	}
+	if err := r.qdrantClient.DeleteCodeIndexByFilter(storage.RecentChangesCollection, filter); err != nil {
+		r.logger.Warn("Failed to prune expired recent changes", zap.Error(err))
+	}
+}
+
+// hunkText renders a hunk as unified-diff style text for embedding
+func hunkText(relativePath, language string, hunk diffHunk) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Change in %s (%s) at line %d\n", relativePath, language, hunk.NewStart)
+	for _, line := range hunk.Removed {
+		b.WriteString("- ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	for _, line := range hunk.Added {
+		b.WriteString("+ ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	text := b.String()
+	if len(text) > maxHunkTextBytes {
+		text = strings.ToValidUTF8(text[:maxHunkTextBytes], "")
+	}
+	return text
+}
+
+// chunksContent reassembles file content from its stored chunks, which are
+// contiguous and listed in chunk order
+func chunksContent(chunks []*storage.FileChunk) string {
+	var b strings.Builder
+	for _, chunk := range chunks {
+		b.WriteString(chunk.Content)
+	}
+	return b.String()
+}
+
+// scannedContent reassembles file content from freshly scanned chunks
+func scannedContent(chunks []scanner.ChunkContent) string {
+	var b strings.Builder
+	for _, chunk := range chunks {
+		b.WriteString(chunk.Content)
+	}
+	return b.String()
+}
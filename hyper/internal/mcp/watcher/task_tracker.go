@@ -0,0 +1,59 @@
+package watcher
+
+import (
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// TaskChangeTracker correlates observed file events with in-progress agent tasks
+// that declared the file in filesModified, and appends them to the task's change log.
+// This provides evidence of what an agent actually changed versus what it claimed.
+type TaskChangeTracker struct {
+	taskStorage storage.TaskStorage
+	logger      *zap.Logger
+}
+
+// NewTaskChangeTracker creates a new task change tracker
+func NewTaskChangeTracker(taskStorage storage.TaskStorage, logger *zap.Logger) *TaskChangeTracker {
+	return &TaskChangeTracker{
+		taskStorage: taskStorage,
+		logger:      logger,
+	}
+}
+
+// RecordChange appends a change entry to every in-progress agent task whose
+// declared filesModified covers the entry's path
+func (t *TaskChangeTracker) RecordChange(relativePath string, entry storage.FileChangeEntry) {
+	entry.ObservedAt = time.Now().UTC()
+
+	tasks, err := t.taskStorage.ListAgentTasksByStatus(storage.TaskStatusInProgress)
+	if err != nil {
+		t.logger.Warn("Failed to list in-progress agent tasks",
+			zap.String("path", entry.Path),
+			zap.Error(err))
+		return
+	}
+
+	for _, task := range tasks {
+		if !storage.DeclaresPath(task.FilesModified, entry.Path, relativePath) {
+			continue
+		}
+
+		if err := t.taskStorage.AppendTaskChangeLog(task.ID, entry); err != nil {
+			t.logger.Warn("Failed to append task change log",
+				zap.String("agentTaskId", task.ID),
+				zap.String("path", entry.Path),
+				zap.Error(err))
+			continue
+		}
+
+		t.logger.Debug("Recorded file change for agent task",
+			zap.String("agentTaskId", task.ID),
+			zap.String("agentName", task.AgentName),
+			zap.String("path", entry.Path),
+			zap.String("operation", entry.Operation))
+	}
+}
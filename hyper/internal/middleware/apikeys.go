@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// APIKeyHeader carries an API key. Keys are also accepted as bearer tokens.
+const APIKeyHeader = "X-API-Key"
+
+// minAPIKeyLength is the shortest secret accepted in API_KEYS
+const minAPIKeyLength = 16
+
+// APIKey is a credential for scripts and services. Requests made with it act
+// as user "apikey:<name>" and are limited to its scopes.
+type APIKey struct {
+	Name   string
+	Scopes Scopes
+}
+
+// UserID returns the user ID requests made with the key act as
+func (k *APIKey) UserID() string {
+	return "apikey:" + k.Name
+}
+
+// APIKeyStore looks up API keys by the SHA-256 hash of their secret
+type APIKeyStore interface {
+	// LookupAPIKey returns nil when no active key has the hash
+	LookupAPIKey(hash string) (*APIKey, error)
+	// HasAPIKeys reports whether any active key exists
+	HasAPIKeys() (bool, error)
+}
+
+// HashAPIKey returns the hex SHA-256 hash API keys are looked up by
+func HashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// StaticAPIKeys are API keys configured in the environment, by hash
+type StaticAPIKeys map[string]*APIKey
+
+// LookupAPIKey implements APIKeyStore
+func (k StaticAPIKeys) LookupAPIKey(hash string) (*APIKey, error) {
+	return k[hash], nil
+}
+
+// HasAPIKeys implements APIKeyStore
+func (k StaticAPIKeys) HasAPIKeys() (bool, error) {
+	return len(k) > 0, nil
+}
+
+// ParseAPIKeys parses comma-separated name:secret:scopes entries, where
+// scopes are joined with "+", e.g. "ci:<secret>:read+task-write"
+func ParseAPIKeys(value string) (StaticAPIKeys, error) {
+	keys := StaticAPIKeys{}
+	names := map[string]bool{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid API key entry %q: expected name:secret:scope+scope", strings.SplitN(entry, ":", 2)[0])
+		}
+		name, secret := parts[0], parts[1]
+		if names[name] {
+			return nil, fmt.Errorf("duplicate API key name %q", name)
+		}
+		if len(secret) < minAPIKeyLength || strings.Contains(secret, ".") {
+			return nil, fmt.Errorf("API key %q: secret must be at least %d characters without dots", name, minAPIKeyLength)
+		}
+		scopes, err := ParseScopeList(strings.Split(parts[2], "+"))
+		if err != nil {
+			return nil, fmt.Errorf("API key %q: %w", name, err)
+		}
+		names[name] = true
+		keys[HashAPIKey(secret)] = &APIKey{Name: name, Scopes: scopes}
+	}
+	return keys, nil
+}
+
+// ParseScopeList parses a non-empty list of scopes
+func ParseScopeList(values []string) (Scopes, error) {
+	scopes := Scopes{}
+	for _, value := range values {
+		scope, ok := ParseScope(value)
+		if !ok {
+			return nil, fmt.Errorf("unknown scope %q: must be read, task-write, knowledge-write or admin", value)
+		}
+		scopes = append(scopes, scope)
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return scopes, nil
+}
+
+// LoadAPIKeys reads API keys from API_KEYS
+func LoadAPIKeys() (StaticAPIKeys, error) {
+	keys, err := ParseAPIKeys(os.Getenv("API_KEYS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API_KEYS: %w", err)
+	}
+	return keys, nil
+}
+
+// AuthRequired reports whether requests without credentials are rejected:
+// with ENABLE_JWT or AUTH_REQUIRED set. Otherwise they run as the dev user
+// until an API key exists.
+func AuthRequired() bool {
+	for _, name := range []string{"ENABLE_JWT", "AUTH_REQUIRED"} {
+		if value := os.Getenv(name); value == "true" || value == "1" {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupAPIKey finds the key of secret in the configured keys first, then
+// in store
+func lookupAPIKey(static StaticAPIKeys, store APIKeyStore, secret string) (*APIKey, error) {
+	hash := HashAPIKey(secret)
+	if key := static[hash]; key != nil {
+		return key, nil
+	}
+	if store == nil {
+		return nil, nil
+	}
+	return store.LookupAPIKey(hash)
+}
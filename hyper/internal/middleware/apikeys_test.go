@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	readSecret  = "read-secret-0123456789"
+	writeSecret = "write-secret-0123456789"
+)
+
+// mockKeyStore holds keys by hash and counts lookups
+type mockKeyStore struct {
+	keys    map[string]*APIKey
+	lookups int
+}
+
+func (m *mockKeyStore) LookupAPIKey(hash string) (*APIKey, error) {
+	m.lookups++
+	return m.keys[hash], nil
+}
+
+func (m *mockKeyStore) HasAPIKeys() (bool, error) {
+	return len(m.keys) > 0, nil
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys(" ci:" + readSecret + ":read , bot:" + writeSecret + ":read+hyper:task-write")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key := keys[HashAPIKey(writeSecret)]
+	if key == nil || key.Name != "bot" || key.Scopes.String() != "read task-write" {
+		t.Fatalf("unexpected key %+v", key)
+	}
+
+	for _, value := range []string{
+		"ci:" + readSecret,
+		"ci:short:read",
+		"ci:" + readSecret + ".x:read",
+		"ci:" + readSecret + ":write",
+		"ci:" + readSecret + ":read,ci:" + writeSecret + ":read",
+	} {
+		if _, err := ParseAPIKeys(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		} else if strings.Contains(err.Error(), "secret-0123") {
+			t.Errorf("error leaks the secret: %v", err)
+		}
+	}
+}
+
+func newAuthRouter(t *testing.T, store APIKeyStore) *gin.Engine {
+	t.Helper()
+	auth, err := AuthMiddleware(store, zap.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(auth)
+	r.Use(RBACMiddleware(nil, nil, zap.NewNop()))
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"userId": c.GetString("userId"), "role": GetRole(c), "scopes": c.GetHeader(ScopesHeader)})
+	}
+	r.GET("/api/v1/tasks", handler)
+	r.POST("/api/v1/tasks", handler)
+	r.POST("/api/v1/knowledge", handler)
+	r.GET("/health", handler)
+	return r
+}
+
+func serve(r *gin.Engine, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for name := range header {
+		req.Header.Set(name, header[name][0])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthMiddleware_APIKeys(t *testing.T) {
+	t.Setenv("ENABLE_JWT", "false")
+	t.Setenv("AUTH_REQUIRED", "true")
+	t.Setenv("RBAC_DEFAULT_ROLE", "")
+	t.Setenv("API_KEYS", "ci:"+readSecret+":read")
+	store := &mockKeyStore{keys: map[string]*APIKey{
+		HashAPIKey(writeSecret): {Name: "bot", Scopes: Scopes{ScopeTaskWrite}},
+	}}
+	r := newAuthRouter(t, store)
+
+	// Credentials are required
+	if w := serve(r, http.MethodGet, "/api/v1/tasks", nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", w.Code)
+	}
+	if w := serve(r, http.MethodGet, "/health", nil); w.Code != http.StatusOK {
+		t.Fatalf("expected health probes to stay open, got %d", w.Code)
+	}
+	if w := serve(r, http.MethodGet, "/api/v1/tasks", http.Header{APIKeyHeader: {"unknown-secret-0123456789"}}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an unknown key, got %d", w.Code)
+	}
+	store.lookups = 0
+
+	// A read key reads but does not write; client-supplied scopes are replaced
+	w := serve(r, http.MethodGet, "/api/v1/tasks", http.Header{APIKeyHeader: {readSecret}, ScopesHeader: {"admin"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected read key to read, got %d", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"userId":"apikey:ci"`) || !strings.Contains(body, `"role":"viewer"`) || !strings.Contains(body, `"scopes":"read"`) {
+		t.Fatalf("unexpected response %s", body)
+	}
+	if store.lookups != 0 {
+		t.Errorf("expected configured keys to be found without the store, got %d lookups", store.lookups)
+	}
+	if w := serve(r, http.MethodPost, "/api/v1/tasks", http.Header{APIKeyHeader: {readSecret}}); w.Code != http.StatusForbidden {
+		t.Fatalf("expected read key to be forbidden from writing, got %d", w.Code)
+	}
+
+	// Stored keys work as bearer tokens and are limited to their write scope
+	bearer := http.Header{"Authorization": {"Bearer " + writeSecret}}
+	if w := serve(r, http.MethodPost, "/api/v1/tasks", bearer); w.Code != http.StatusOK {
+		t.Fatalf("expected task-write key to create tasks, got %d: %s", w.Code, w.Body.String())
+	}
+	w = serve(r, http.MethodPost, "/api/v1/knowledge", bearer)
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "knowledge-write") {
+		t.Fatalf("expected task-write key to be forbidden from writing knowledge, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthMiddleware_DevModeUntilKeysExist(t *testing.T) {
+	t.Setenv("ENABLE_JWT", "false")
+	t.Setenv("AUTH_REQUIRED", "false")
+	t.Setenv("RBAC_DEFAULT_ROLE", "")
+	t.Setenv("API_KEYS", "")
+	store := &mockKeyStore{keys: map[string]*APIKey{}}
+	r := newAuthRouter(t, store)
+
+	w := serve(r, http.MethodPost, "/api/v1/tasks", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"userId":"dev-user"`) {
+		t.Fatalf("expected requests without credentials to run as the dev user, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Once a key exists, its scopes cannot be bypassed by omitting it
+	store.keys[HashAPIKey(readSecret)] = &APIKey{Name: "ci", Scopes: Scopes{ScopeRead}}
+	if w := serve(r, http.MethodPost, "/api/v1/tasks", nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials once a key exists, got %d", w.Code)
+	}
+	if w := serve(r, http.MethodGet, "/health", nil); w.Code != http.StatusOK {
+		t.Fatalf("expected health probes to stay open, got %d", w.Code)
+	}
+	if w := serve(r, http.MethodPost, "/api/v1/tasks", http.Header{APIKeyHeader: {readSecret}}); w.Code != http.StatusForbidden {
+		t.Fatalf("expected read key to be forbidden from writing, got %d", w.Code)
+	}
+	if w := serve(r, http.MethodGet, "/api/v1/tasks", http.Header{APIKeyHeader: {readSecret}}); w.Code != http.StatusOK {
+		t.Fatalf("expected read key to read, got %d", w.Code)
+	}
+}
+
+func TestAuthMiddleware_ConfiguredKeysRequireCredentials(t *testing.T) {
+	t.Setenv("ENABLE_JWT", "false")
+	t.Setenv("AUTH_REQUIRED", "false")
+	t.Setenv("RBAC_DEFAULT_ROLE", "")
+	t.Setenv("API_KEYS", "ci:"+readSecret+":read")
+	r := newAuthRouter(t, nil)
+
+	if w := serve(r, http.MethodPost, "/api/v1/tasks", nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials when API_KEYS is set, got %d", w.Code)
+	}
+	if w := serve(r, http.MethodGet, "/api/v1/tasks", http.Header{APIKeyHeader: {writeSecret}}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected an unknown key to be rejected, got %d", w.Code)
+	}
+}
+
+func TestAuthMiddleware_InvalidConfiguration(t *testing.T) {
+	t.Setenv("API_KEYS", "ci:short:read")
+	if _, err := AuthMiddleware(nil, zap.NewNop()); err == nil {
+		t.Fatal("expected invalid API_KEYS to be rejected")
+	}
+}
@@ -1,52 +1,133 @@
 package middleware
 
 import (
-	"net/http"
 	"os"
 	"strings"
 
+	"hyper/internal/errcode"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
+// WebhookPathPrefix is the route prefix of inbound webhooks. Senders such as
+// Jira cannot present a JWT, so each webhook checks its own shared secret.
+const WebhookPathPrefix = "/api/v1/webhooks/"
+
+// CalendarFeedPath serves the task calendar. Calendar apps subscribe with a
+// token in the URL instead of a JWT.
+const CalendarFeedPath = "/api/calendar.ics"
+
+// SharePathPrefix serves shared tasks to people without an account. The
+// signed link itself grants read-only access to one task.
+const SharePathPrefix = "/api/share/"
+
+// tokenAuthenticated reports whether a route checks its own shared token
+// instead of a JWT
+func tokenAuthenticated(path string) bool {
+	return strings.HasPrefix(path, WebhookPathPrefix) || path == CalendarFeedPath || strings.HasPrefix(path, SharePathPrefix)
+}
+
+// probePath reports whether a route is a health probe, which load balancers
+// and orchestrators call without credentials
+func probePath(path string) bool {
+	return path == "/health" || path == "/readyz"
+}
+
 // OptionalJWTMiddleware provides optional JWT authentication
 // If ENABLE_JWT is not set or set to "false" (default), it injects dev mock values
 // If ENABLE_JWT is "true", it validates JWT tokens and extracts claims
 func OptionalJWTMiddleware() gin.HandlerFunc {
-	enableJWT := os.Getenv("ENABLE_JWT")
-	jwtEnabled := enableJWT == "true" || enableJWT == "1"
-
 	// Get logger (optional, for debugging)
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
 
-	if !jwtEnabled {
-		logger.Info("JWT authentication DISABLED - using dev mock values")
-		// Return middleware that injects mock values for development
-		return func(c *gin.Context) {
-			c.Set("userId", "dev-user")
-			c.Set("companyId", "dev-company")
-			c.Next()
+	return newAuthenticator(nil, nil, logger).handle
+}
+
+// AuthMiddleware authenticates requests with API keys or JWTs. Keys come
+// from API_KEYS and, when store is non-nil, from store; they are sent in the
+// X-API-Key header or as bearer tokens. JWTs are validated as by
+// OptionalJWTMiddleware. With AuthRequired, or once any API key exists,
+// requests without credentials are rejected; otherwise they run as the dev
+// user, since key scopes would restrict nobody. Requests are checked against
+// the scopes of their credential, and the scopes are forwarded to the MCP
+// layer in ScopesHeader.
+func AuthMiddleware(store APIKeyStore, logger *zap.Logger) (gin.HandlerFunc, error) {
+	static, err := LoadAPIKeys()
+	if err != nil {
+		return nil, err
+	}
+	return newAuthenticator(static, store, logger).handle, nil
+}
+
+// authenticator resolves the caller of a request from its credentials
+type authenticator struct {
+	static     StaticAPIKeys
+	store      APIKeyStore
+	required   bool
+	jwtEnabled bool
+	jwtSecret  string
+	logger     *zap.Logger
+}
+
+func newAuthenticator(static StaticAPIKeys, store APIKeyStore, logger *zap.Logger) *authenticator {
+	enableJWT := os.Getenv("ENABLE_JWT")
+	a := &authenticator{
+		static:     static,
+		store:      store,
+		required:   AuthRequired(),
+		jwtEnabled: enableJWT == "true" || enableJWT == "1",
+		logger:     logger,
+	}
+
+	if !a.jwtEnabled {
+		logger.Info("JWT authentication DISABLED")
+	} else {
+		logger.Info("JWT authentication ENABLED - validating tokens")
+
+		// JWT secret from environment
+		a.jwtSecret = os.Getenv("JWT_SECRET")
+		if a.jwtSecret == "" {
+			logger.Warn("JWT_SECRET not set, using default (INSECURE for production)")
+			a.jwtSecret = "hyperion-default-secret-change-in-production"
 		}
 	}
+	if len(static) > 0 || store != nil {
+		logger.Info("API key authentication enabled", zap.Int("configuredKeys", len(static)), zap.Bool("keyStore", store != nil))
+	}
+	if !a.required && len(static) > 0 {
+		logger.Info("API_KEYS is set: requests without credentials are rejected")
+	} else if !a.required {
+		logger.Info("Requests without credentials use dev mock values until an API key exists")
+	}
+	return a
+}
 
-	logger.Info("JWT authentication ENABLED - validating tokens")
+// handle authenticates one request
+func (a *authenticator) handle(c *gin.Context) {
+	c.Request.Header.Del(ScopesHeader)
 
-	// JWT secret from environment
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		logger.Warn("JWT_SECRET not set, using default (INSECURE for production)")
-		jwtSecret = "hyperion-default-secret-change-in-production"
+	required := a.credentialsRequired()
+	if tokenAuthenticated(c.Request.URL.Path) || probePath(c.Request.URL.Path) || (!required && !a.hasCredentials(c)) {
+		if !required {
+			// Inject mock values for development
+			c.Set("userId", "dev-user")
+			c.Set("companyId", "dev-company")
+		}
+		c.Next()
+		return
 	}
 
-	// Return middleware that validates JWT tokens
-	return func(c *gin.Context) {
+	if secret := c.GetHeader(APIKeyHeader); secret != "" {
+		if !a.authenticateKey(c, secret) {
+			return
+		}
+	} else {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Missing Authorization header",
-			})
+			errcode.RespondCode(c, errcode.Unauthenticated, "Missing Authorization header")
 			c.Abort()
 			return
 		}
@@ -54,106 +135,174 @@ func OptionalJWTMiddleware() gin.HandlerFunc {
 		// Extract Bearer token
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid Authorization header format. Expected: Bearer <token>",
-			})
+			errcode.RespondCode(c, errcode.Unauthenticated, "Invalid Authorization header format. Expected: Bearer <token>")
 			c.Abort()
 			return
 		}
 
-		tokenString := parts[1]
-
-		// Parse and validate JWT token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		// JWTs have three dot-separated parts; API keys have no dots
+		if a.jwtEnabled && strings.Count(parts[1], ".") == 2 {
+			if !a.authenticateJWT(c, parts[1]) {
+				return
 			}
-			return []byte(jwtSecret), nil
-		})
-
-		if err != nil {
-			logger.Error("JWT validation failed", zap.Error(err))
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid token: " + err.Error(),
-			})
-			c.Abort()
+		} else if !a.authenticateKey(c, parts[1]) {
 			return
 		}
+	}
 
-		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid token",
-			})
-			c.Abort()
-			return
-		}
+	scopes := GetScopes(c)
+	if scopes != nil {
+		c.Request.Header.Set(ScopesHeader, scopes.String())
+	}
+	if required := RequiredScopeForRoute(c.Request.Method, c.Request.URL.Path); !scopes.Allows(required) {
+		errcode.RespondDetails(c, errcode.PermissionDenied, "Credential lacks the scope for this operation", gin.H{
+			"scopes":        scopes,
+			"requiredScope": required,
+		})
+		c.Abort()
+		return
+	}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid token claims",
-			})
-			c.Abort()
-			return
+	c.Next()
+}
+
+// credentialsRequired reports whether requests must carry credentials: with
+// AuthRequired, or once any API key exists. If the key store cannot be
+// checked, credentials are required.
+func (a *authenticator) credentialsRequired() bool {
+	if a.required || len(a.static) > 0 {
+		return true
+	}
+	if a.store == nil {
+		return false
+	}
+	exists, err := a.store.HasAPIKeys()
+	if err != nil {
+		a.logger.Error("Failed to check for API keys", zap.Error(err))
+		return true
+	}
+	return exists
+}
+
+// hasCredentials reports whether a request presents an API key or a token.
+// Without JWT validation, bearer tokens can only be API keys.
+func (a *authenticator) hasCredentials(c *gin.Context) bool {
+	if c.GetHeader(APIKeyHeader) != "" {
+		return true
+	}
+	if !strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ") {
+		return false
+	}
+	return a.jwtEnabled || len(a.static) > 0 || a.store != nil
+}
+
+// authenticateKey sets the caller from an API key, or rejects the request
+func (a *authenticator) authenticateKey(c *gin.Context, secret string) bool {
+	key, err := lookupAPIKey(a.static, a.store, secret)
+	if err != nil {
+		a.logger.Error("API key lookup failed", zap.Error(err))
+		errcode.RespondCode(c, errcode.Internal, "Failed to verify API key")
+		c.Abort()
+		return false
+	}
+	if key == nil {
+		errcode.RespondCode(c, errcode.Unauthenticated, "Invalid API key")
+		c.Abort()
+		return false
+	}
+
+	c.Set("userId", key.UserID())
+	c.Set("companyId", key.UserID())
+	c.Set("scopes", key.Scopes)
+	c.Set(apiKeyRoleKey, key.Scopes.Role())
+	return true
+}
+
+// authenticateJWT sets the caller from a JWT, or rejects the request
+func (a *authenticator) authenticateJWT(c *gin.Context, tokenString string) bool {
+	// Parse and validate JWT token
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Validate signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
 		}
+		return []byte(a.jwtSecret), nil
+	})
 
-		// Extract userId and companyId from claims
-		// Try different claim formats to be flexible
-		var userId, companyId string
+	if err != nil {
+		a.logger.Error("JWT validation failed", zap.Error(err))
+		errcode.RespondCode(c, errcode.Unauthenticated, "Invalid token: "+err.Error())
+		c.Abort()
+		return false
+	}
 
-		// Try to get userId
-		if id, ok := claims["userId"].(string); ok {
-			userId = id
-		} else if id, ok := claims["user_id"].(string); ok {
-			userId = id
-		} else if id, ok := claims["sub"].(string); ok {
+	if !token.Valid {
+		errcode.RespondCode(c, errcode.Unauthenticated, "Invalid token")
+		c.Abort()
+		return false
+	}
+
+	// Extract claims
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		errcode.RespondCode(c, errcode.Unauthenticated, "Invalid token claims")
+		c.Abort()
+		return false
+	}
+
+	// Extract userId and companyId from claims
+	// Try different claim formats to be flexible
+	var userId, companyId string
+
+	// Try to get userId
+	if id, ok := claims["userId"].(string); ok {
+		userId = id
+	} else if id, ok := claims["user_id"].(string); ok {
+		userId = id
+	} else if id, ok := claims["sub"].(string); ok {
+		userId = id
+	} else if identity, ok := claims["identity"].(map[string]interface{}); ok {
+		if id, ok := identity["id"].(string); ok {
 			userId = id
-		} else if identity, ok := claims["identity"].(map[string]interface{}); ok {
-			if id, ok := identity["id"].(string); ok {
-				userId = id
-			}
 		}
+	}
 
-		// Try to get companyId
-		if id, ok := claims["companyId"].(string); ok {
-			companyId = id
-		} else if id, ok := claims["company_id"].(string); ok {
+	// Try to get companyId
+	if id, ok := claims["companyId"].(string); ok {
+		companyId = id
+	} else if id, ok := claims["company_id"].(string); ok {
+		companyId = id
+	} else if identity, ok := claims["identity"].(map[string]interface{}); ok {
+		if id, ok := identity["companyId"].(string); ok {
 			companyId = id
-		} else if identity, ok := claims["identity"].(map[string]interface{}); ok {
-			if id, ok := identity["companyId"].(string); ok {
-				companyId = id
-			}
 		}
+	}
 
-		// Validate required claims
-		if userId == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Token missing userId claim",
-			})
-			c.Abort()
-			return
-		}
+	// Validate required claims
+	if userId == "" {
+		errcode.RespondCode(c, errcode.Unauthenticated, "Token missing userId claim")
+		c.Abort()
+		return false
+	}
 
-		if companyId == "" {
-			// If no companyId in token, use userId as default (for backward compatibility)
-			companyId = userId
-			logger.Warn("Token missing companyId claim, using userId as default",
-				zap.String("userId", userId))
-		}
+	if companyId == "" {
+		// If no companyId in token, use userId as default (for backward compatibility)
+		companyId = userId
+		a.logger.Warn("Token missing companyId claim, using userId as default",
+			zap.String("userId", userId))
+	}
 
-		// Set claims in context
-		c.Set("userId", userId)
-		c.Set("companyId", companyId)
+	// Set claims in context
+	c.Set("userId", userId)
+	c.Set("companyId", companyId)
 
-		// Store full claims for additional context if needed
-		c.Set("jwtClaims", claims)
+	// Store full claims for additional context if needed
+	c.Set("jwtClaims", claims)
+	c.Set("scopes", ScopesFromClaims(claims))
 
-		logger.Debug("JWT validated successfully",
-			zap.String("userId", userId),
-			zap.String("companyId", companyId))
+	a.logger.Debug("JWT validated successfully",
+		zap.String("userId", userId),
+		zap.String("companyId", companyId))
 
-		c.Next()
-	}
+	return true
 }
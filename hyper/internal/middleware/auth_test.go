@@ -95,3 +95,22 @@ func TestOptionalJWTMiddleware_EnabledMissingHeader(t *testing.T) {
         t.Fatalf("expected status 401, got %d", w.Code)
     }
 }
+
+func TestOptionalJWTMiddleware_EnabledSkipsWebhooks(t *testing.T) {
+    os.Setenv("ENABLE_JWT", "true")
+    os.Setenv("JWT_SECRET", "any")
+
+    r := gin.New()
+    r.Use(OptionalJWTMiddleware())
+    r.POST("/api/v1/webhooks/jira", func(c *gin.Context) {
+        c.JSON(http.StatusOK, gin.H{"ok": true})
+    })
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/jira", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+
+    if w.Code != http.StatusOK {
+        t.Fatalf("expected status 200, got %d", w.Code)
+    }
+}
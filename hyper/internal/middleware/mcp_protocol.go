@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ProtocolVersionHeader carries the negotiated MCP revision on streamable HTTP requests
+const ProtocolVersionHeader = "Mcp-Protocol-Version"
+
+// MinProtocolVersion is the oldest MCP revision the server accepts
+const MinProtocolVersion = "2024-11-05"
+
+// SupportedProtocolVersions lists the MCP revisions the go-sdk server speaks, newest first.
+// Keep in sync with the SDK version in go.mod.
+var SupportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// LatestProtocolVersion is the revision offered to clients requesting a newer, unknown one
+var LatestProtocolVersion = SupportedProtocolVersions[0]
+
+// MCPMaxBodyBytesEnv sets the largest request body accepted on /mcp, in bytes
+const MCPMaxBodyBytesEnv = "MCP_MAX_BODY_BYTES"
+
+// defaultMCPMaxBodyBytes fits tool calls carrying small artifacts and
+// knowledge imports
+const defaultMCPMaxBodyBytes = 10 << 20
+
+// MCPMaxBodyBytes returns the largest /mcp request body accepted:
+// MCP_MAX_BODY_BYTES when set to a positive number, otherwise 10 MiB
+func MCPMaxBodyBytes() int64 {
+	if raw := os.Getenv(MCPMaxBodyBytesEnv); raw != "" {
+		if value, err := strconv.ParseInt(raw, 10, 64); err == nil && value > 0 {
+			return value
+		}
+	}
+	return defaultMCPMaxBodyBytes
+}
+
+// JSON-RPC error codes used for protocol version failures
+const (
+	jsonRPCInvalidRequest = -32600
+	jsonRPCInvalidParams  = -32602
+)
+
+var protocolVersionPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// IsSupportedProtocolVersion reports whether version is an MCP revision the server speaks exactly
+func IsSupportedProtocolVersion(version string) bool {
+	return slices.Contains(SupportedProtocolVersions, version)
+}
+
+// IsNegotiableProtocolVersion reports whether a client's initialize request can be
+// answered. Known revisions are used as-is; well-formed revisions newer than
+// MinProtocolVersion are negotiated down to LatestProtocolVersion, as the spec
+// requires. Malformed or older versions cannot be negotiated.
+func IsNegotiableProtocolVersion(version string) bool {
+	if IsSupportedProtocolVersion(version) {
+		return true
+	}
+	// Revisions are ISO dates, so they order lexically
+	return protocolVersionPattern.MatchString(version) && version > MinProtocolVersion
+}
+
+// jsonRPCMessage is the subset of a JSON-RPC message inspected for version negotiation
+type jsonRPCMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	} `json:"params"`
+}
+
+// MCPProtocolVersionMiddleware validates MCP protocol versions on the streamable
+// HTTP endpoint before requests reach the SDK handler:
+//   - an Mcp-Protocol-Version header naming an unsupported revision is rejected
+//     with 400 and a JSON-RPC error listing the supported revisions
+//   - an initialize request whose protocolVersion cannot be negotiated is rejected
+//     with the spec's "Unsupported protocol version" error (-32602)
+//   - a body larger than MCPMaxBodyBytes is rejected with 413
+//
+// Requests without the header are passed through; the SDK treats them as 2025-03-26.
+func MCPProtocolVersionMiddleware(logger *zap.Logger) gin.HandlerFunc {
+	maxBodyBytes := MCPMaxBodyBytes()
+	return func(c *gin.Context) {
+		if version := c.GetHeader(ProtocolVersionHeader); version != "" && !IsSupportedProtocolVersion(version) {
+			logger.Warn("Rejected MCP request with unsupported protocol version header",
+				zap.String("protocolVersion", version),
+				zap.String("remoteAddr", c.ClientIP()))
+			abortWithProtocolError(c, http.StatusBadRequest, nil, jsonRPCInvalidRequest, version)
+			return
+		}
+
+		if c.Request.Method != http.MethodPost || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				logger.Warn("Rejected MCP request with oversized body",
+					zap.Int64("limit", maxBodyBytes),
+					zap.String("remoteAddr", c.ClientIP()))
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+					"jsonrpc": "2.0",
+					"id":      nil,
+					"error": gin.H{
+						"code":    jsonRPCInvalidRequest,
+						"message": "Request body too large",
+						"data":    gin.H{"limit": maxBodyBytes},
+					},
+				})
+				c.Abort()
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		// Restore the body for the MCP handler
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		for _, msg := range decodeJSONRPCMessages(body) {
+			if msg.Method != "initialize" || IsNegotiableProtocolVersion(msg.Params.ProtocolVersion) {
+				continue
+			}
+			logger.Warn("Rejected MCP initialize with unsupported protocol version",
+				zap.String("protocolVersion", msg.Params.ProtocolVersion),
+				zap.String("remoteAddr", c.ClientIP()))
+			abortWithProtocolError(c, http.StatusOK, msg.ID, jsonRPCInvalidParams, msg.Params.ProtocolVersion)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// decodeJSONRPCMessages parses a single JSON-RPC message or a batch.
+// Unparseable bodies yield no messages and are left for the SDK to reject.
+func decodeJSONRPCMessages(body []byte) []jsonRPCMessage {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil
+	}
+
+	if trimmed[0] == '[' {
+		var batch []jsonRPCMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			return nil
+		}
+		return batch
+	}
+
+	var msg jsonRPCMessage
+	if err := json.Unmarshal(trimmed, &msg); err != nil {
+		return nil
+	}
+	return []jsonRPCMessage{msg}
+}
+
+// abortWithProtocolError writes a JSON-RPC error describing a protocol version mismatch
+func abortWithProtocolError(c *gin.Context, status int, id json.RawMessage, code int, requested string) {
+	var responseID interface{}
+	if len(id) > 0 {
+		responseID = id
+	}
+
+	c.JSON(status, gin.H{
+		"jsonrpc": "2.0",
+		"id":      responseID,
+		"error": gin.H{
+			"code":    code,
+			"message": "Unsupported protocol version",
+			"data": gin.H{
+				"supported": SupportedProtocolVersions,
+				"requested": requested,
+			},
+		},
+	})
+	c.Abort()
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestIsNegotiableProtocolVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"2024-11-05", true},
+		{"2025-03-26", true},
+		{"2025-06-18", true},
+		{"2026-01-01", true}, // newer revision, negotiated down
+		{"2024-10-07", false},
+		{"1.0.0", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsNegotiableProtocolVersion(tt.version); got != tt.want {
+			t.Errorf("IsNegotiableProtocolVersion(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func newProtocolTestRouter(reached *string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Any("/mcp", MCPProtocolVersionMiddleware(zap.NewNop()), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		*reached = string(body)
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestMCPProtocolVersionMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		body        string
+		wantStatus  int
+		wantReached bool
+		wantCode    int
+	}{
+		{
+			name:        "supported initialize passes through",
+			body:        `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+			wantStatus:  http.StatusOK,
+			wantReached: true,
+		},
+		{
+			name:        "newer initialize is left to negotiation",
+			body:        `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2026-03-01"}}`,
+			wantStatus:  http.StatusOK,
+			wantReached: true,
+		},
+		{
+			name:       "unknown initialize version is rejected",
+			body:       `{"jsonrpc":"2.0","id":7,"method":"initialize","params":{"protocolVersion":"1.0.0"}}`,
+			wantStatus: http.StatusOK,
+			wantCode:   jsonRPCInvalidParams,
+		},
+		{
+			name:       "unsupported header is rejected",
+			header:     "2023-01-01",
+			body:       `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   jsonRPCInvalidRequest,
+		},
+		{
+			name:        "supported header passes through",
+			header:      "2025-06-18",
+			body:        `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+			wantStatus:  http.StatusOK,
+			wantReached: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reached string
+			r := newProtocolTestRouter(&reached)
+
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set(ProtocolVersionHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantReached {
+				if reached != tt.body {
+					t.Fatalf("handler did not receive the original body: %q", reached)
+				}
+				return
+			}
+
+			var resp struct {
+				Error struct {
+					Code int `json:"code"`
+					Data struct {
+						Supported []string `json:"supported"`
+					} `json:"data"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid JSON-RPC error body: %v", err)
+			}
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("error code = %d, want %d", resp.Error.Code, tt.wantCode)
+			}
+			if len(resp.Error.Data.Supported) != len(SupportedProtocolVersions) {
+				t.Errorf("supported versions = %v", resp.Error.Data.Supported)
+			}
+		})
+	}
+}
+
+func TestMCPProtocolVersionMiddleware_BodyLimit(t *testing.T) {
+	t.Setenv(MCPMaxBodyBytesEnv, "64")
+	var reached string
+	r := newProtocolTestRouter(&reached)
+
+	small := `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(small)))
+	if w.Code != http.StatusOK || reached != small {
+		t.Fatalf("body within the limit: status = %d, reached %q", w.Code, reached)
+	}
+
+	reached = ""
+	large := `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"arguments":{"text":"` + strings.Repeat("x", 100) + `"}}}`
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(large)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+	if reached != "" {
+		t.Fatal("an oversized body must not reach the MCP handler")
+	}
+	var resp struct {
+		Error struct {
+			Code int `json:"code"`
+			Data struct {
+				Limit int64 `json:"limit"`
+			} `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON-RPC error body: %v", err)
+	}
+	if resp.Error.Code != jsonRPCInvalidRequest || resp.Error.Data.Limit != 64 {
+		t.Errorf("error = %+v", resp.Error)
+	}
+}
+
+func TestMCPMaxBodyBytes(t *testing.T) {
+	t.Setenv(MCPMaxBodyBytesEnv, "")
+	if got := MCPMaxBodyBytes(); got != defaultMCPMaxBodyBytes {
+		t.Errorf("default = %d", got)
+	}
+	t.Setenv(MCPMaxBodyBytesEnv, "-1")
+	if got := MCPMaxBodyBytes(); got != defaultMCPMaxBodyBytes {
+		t.Errorf("invalid values keep the default, got %d", got)
+	}
+	t.Setenv(MCPMaxBodyBytesEnv, "1048576")
+	if got := MCPMaxBodyBytes(); got != 1<<20 {
+		t.Errorf("MCP_MAX_BODY_BYTES = %d", got)
+	}
+}
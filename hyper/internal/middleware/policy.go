@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// UserHeader carries the authenticated user ID from the HTTP layer to the MCP
+// layer so tool policies see the principal. Like RoleHeader, any
+// client-supplied value is overwritten by RBACMiddleware.
+const UserHeader = "X-Hyper-User"
+
+// defaultPolicyTimeout bounds one policy query
+const defaultPolicyTimeout = 2 * time.Second
+
+// Principal is the caller an authorization decision is made for
+type Principal struct {
+	UserID string `json:"userId,omitempty"`
+	Role   Role   `json:"role"`
+}
+
+// PolicyInput is the input document sent to the policy engine. Kind is
+// "route" for REST requests (Method, Path) and "tool" for MCP and proxied
+// tool calls (Tool, Arguments). RequiredRole and RoleAllowed carry the
+// built-in RBAC verdict so policies can extend rather than restate it.
+type PolicyInput struct {
+	Kind         string                 `json:"kind"`
+	Principal    Principal              `json:"principal"`
+	Method       string                 `json:"method,omitempty"`
+	Path         string                 `json:"path,omitempty"`
+	Tool         string                 `json:"tool,omitempty"`
+	Arguments    map[string]interface{} `json:"arguments,omitempty"`
+	RequiredRole Role                   `json:"requiredRole"`
+	RoleAllowed  bool                   `json:"roleAllowed"`
+}
+
+// Policy input kinds
+const (
+	PolicyKindRoute = "route"
+	PolicyKindTool  = "tool"
+)
+
+// PolicyDecision is the verdict of a policy engine
+type PolicyDecision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Policy makes authorization decisions in place of the built-in role checks
+type Policy interface {
+	Decide(ctx context.Context, input PolicyInput) (*PolicyDecision, error)
+}
+
+// OPAPolicy queries an Open Policy Agent server over its data API
+type OPAPolicy struct {
+	url      string // e.g. http://localhost:8181/v1/data/hyper/authz
+	token    string
+	client   *http.Client
+	failOpen bool
+	logger   *zap.Logger
+}
+
+// LoadPolicy returns the policy configured by POLICY_OPA_URL, or nil when
+// authorization uses roles only. POLICY_OPA_TOKEN is sent as a bearer token,
+// POLICY_TIMEOUT bounds each query, and POLICY_FAIL_OPEN=true falls back to
+// the role check when OPA cannot be reached (the default denies).
+func LoadPolicy(logger *zap.Logger) (Policy, error) {
+	rawURL := strings.TrimSpace(os.Getenv("POLICY_OPA_URL"))
+	if rawURL == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		return nil, fmt.Errorf("POLICY_OPA_URL must be an http(s) URL, got %q", rawURL)
+	}
+
+	timeout := defaultPolicyTimeout
+	if raw := os.Getenv("POLICY_TIMEOUT"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid POLICY_TIMEOUT %q: must be a positive duration such as 2s", raw)
+		}
+		timeout = parsed
+	}
+
+	failOpen := os.Getenv("POLICY_FAIL_OPEN")
+	policy := NewOPAPolicy(rawURL, os.Getenv("POLICY_OPA_TOKEN"), timeout, failOpen == "true" || failOpen == "1", logger)
+	logger.Info("Authorization delegated to OPA",
+		zap.String("url", policy.url),
+		zap.Duration("timeout", timeout),
+		zap.Bool("failOpen", policy.failOpen))
+	return policy, nil
+}
+
+// NewOPAPolicy creates a policy backed by the OPA decision at url
+func NewOPAPolicy(url, token string, timeout time.Duration, failOpen bool, logger *zap.Logger) *OPAPolicy {
+	return &OPAPolicy{
+		url:      strings.TrimRight(url, "/"),
+		token:    token,
+		client:   &http.Client{Timeout: timeout},
+		failOpen: failOpen,
+		logger:   logger,
+	}
+}
+
+// Decide posts {"input": ...} to OPA. The decision may be a boolean or an
+// object with "allow" and an optional "reason"; an undefined decision denies.
+// When OPA fails and fail-open is set, the role check decides.
+func (p *OPAPolicy) Decide(ctx context.Context, input PolicyInput) (*PolicyDecision, error) {
+	decision, err := p.query(ctx, input)
+	if err != nil {
+		if p.failOpen {
+			p.logger.Warn("Policy engine unavailable, falling back to role check", zap.String("url", p.url), zap.Error(err))
+			return &PolicyDecision{Allow: input.RoleAllowed, Reason: "policy engine unavailable"}, nil
+		}
+		return nil, err
+	}
+	return decision, nil
+}
+
+func (p *OPAPolicy) query(ctx context.Context, input PolicyInput) (*PolicyDecision, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode policy input: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("policy request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy engine returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode policy response: %w", err)
+	}
+	return parseDecision(result.Result)
+}
+
+// parseDecision reads a boolean or {"allow": bool, "reason": string} result
+func parseDecision(raw json.RawMessage) (*PolicyDecision, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return &PolicyDecision{Allow: false, Reason: "policy decision is undefined"}, nil
+	}
+
+	var allow bool
+	if err := json.Unmarshal(raw, &allow); err == nil {
+		return &PolicyDecision{Allow: allow}, nil
+	}
+
+	var decision PolicyDecision
+	if err := json.Unmarshal(raw, &decision); err != nil {
+		return nil, fmt.Errorf("policy result must be a boolean or an object with \"allow\": %w", err)
+	}
+	return &decision, nil
+}
+
+// Authorize decides whether a principal may proceed. Without a policy the
+// role check decides; with one, the policy does, and errors deny.
+func Authorize(ctx context.Context, policy Policy, input PolicyInput) *PolicyDecision {
+	input.RoleAllowed = input.Principal.Role.Allows(input.RequiredRole)
+	if policy == nil {
+		return &PolicyDecision{Allow: input.RoleAllowed}
+	}
+
+	decision, err := policy.Decide(ctx, input)
+	if err != nil {
+		return &PolicyDecision{Allow: false, Reason: err.Error()}
+	}
+	return decision
+}
+
+// ToolDeniedMessage explains a tool call denied by the authorization policy
+func ToolDeniedMessage(tool string, decision *PolicyDecision) string {
+	if decision.Reason == "" {
+		return fmt.Sprintf("permission denied: tool %s denied by authorization policy", tool)
+	}
+	return fmt.Sprintf("permission denied: tool %s denied by authorization policy: %s", tool, decision.Reason)
+}
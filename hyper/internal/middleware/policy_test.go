@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// fakeOPA answers decision queries with a fixed result and records inputs
+func fakeOPA(t *testing.T, status int, result string) (*httptest.Server, *[]PolicyInput) {
+	t.Helper()
+	var inputs []PolicyInput
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("expected bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		var body struct {
+			Input PolicyInput `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode policy input: %v", err)
+		}
+		inputs = append(inputs, body.Input)
+		w.WriteHeader(status)
+		w.Write([]byte(result))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &inputs
+}
+
+func TestOPAPolicyDecisions(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		allow  bool
+		reason string
+	}{
+		{"boolean", `{"result": true}`, true, ""},
+		{"object", `{"result": {"allow": false, "reason": "outside change window"}}`, false, "outside change window"},
+		{"undefined", `{}`, false, "policy decision is undefined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, inputs := fakeOPA(t, http.StatusOK, tt.result)
+			policy := NewOPAPolicy(srv.URL+"/v1/data/hyper/authz/", "secret", time.Second, false, zap.NewNop())
+
+			decision := Authorize(context.Background(), policy, PolicyInput{
+				Kind:         PolicyKindTool,
+				Principal:    Principal{UserID: "alice", Role: RoleViewer},
+				Tool:         "bash",
+				Arguments:    map[string]interface{}{"command": "ls"},
+				RequiredRole: RoleOperator,
+			})
+			if decision.Allow != tt.allow || decision.Reason != tt.reason {
+				t.Fatalf("got %+v, want allow=%v reason=%q", decision, tt.allow, tt.reason)
+			}
+
+			input := (*inputs)[0]
+			if input.Tool != "bash" || input.Principal.UserID != "alice" || input.Arguments["command"] != "ls" || input.RoleAllowed {
+				t.Fatalf("unexpected policy input: %+v", input)
+			}
+		})
+	}
+}
+
+func TestOPAPolicyFailure(t *testing.T) {
+	srv, _ := fakeOPA(t, http.StatusInternalServerError, `{}`)
+	input := PolicyInput{Kind: PolicyKindRoute, Principal: Principal{Role: RoleAdmin}, RequiredRole: RoleViewer}
+
+	closed := NewOPAPolicy(srv.URL, "secret", time.Second, false, zap.NewNop())
+	if decision := Authorize(context.Background(), closed, input); decision.Allow {
+		t.Fatal("expected fail-closed policy to deny when OPA fails")
+	}
+
+	open := NewOPAPolicy(srv.URL, "secret", time.Second, true, zap.NewNop())
+	if decision := Authorize(context.Background(), open, input); !decision.Allow {
+		t.Fatal("expected fail-open policy to fall back to the role check")
+	}
+	input.Principal.Role = RoleViewer
+	input.RequiredRole = RoleAdmin
+	if decision := Authorize(context.Background(), open, input); decision.Allow {
+		t.Fatal("expected fail-open fallback to deny an insufficient role")
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	defer os.Unsetenv("POLICY_OPA_URL")
+	defer os.Unsetenv("POLICY_TIMEOUT")
+
+	os.Unsetenv("POLICY_OPA_URL")
+	if policy, err := LoadPolicy(zap.NewNop()); err != nil || policy != nil {
+		t.Fatalf("expected no policy without POLICY_OPA_URL, got %v, %v", policy, err)
+	}
+
+	os.Setenv("POLICY_OPA_URL", "localhost:8181")
+	if _, err := LoadPolicy(zap.NewNop()); err == nil {
+		t.Fatal("expected an error for a URL without scheme")
+	}
+
+	os.Setenv("POLICY_OPA_URL", "http://localhost:8181/v1/data/hyper/authz")
+	os.Setenv("POLICY_TIMEOUT", "soon")
+	if _, err := LoadPolicy(zap.NewNop()); err == nil || !strings.Contains(err.Error(), "POLICY_TIMEOUT") {
+		t.Fatalf("expected POLICY_TIMEOUT error, got %v", err)
+	}
+}
+
+func TestRBACMiddleware_PolicyDecides(t *testing.T) {
+	os.Unsetenv("ENABLE_JWT")
+	os.Setenv("RBAC_DEFAULT_ROLE", "viewer")
+	defer os.Unsetenv("RBAC_DEFAULT_ROLE")
+
+	// The policy allows what the role alone would not, and sees the principal
+	srv, inputs := fakeOPA(t, http.StatusOK, `{"result": true}`)
+	r := gin.New()
+	r.Use(OptionalJWTMiddleware())
+	r.Use(RBACMiddleware(nil, NewOPAPolicy(srv.URL, "secret", time.Second, false, zap.NewNop()), zap.NewNop()))
+	r.POST("/api/v1/tasks", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user": c.GetHeader(UserHeader)})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", nil)
+	req.Header.Set(UserHeader, "spoofed")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected policy to allow the request, got %d", w.Code)
+	}
+	if body := w.Body.String(); body != `{"user":"dev-user"}` {
+		t.Fatalf("unexpected response body: %s", body)
+	}
+	input := (*inputs)[0]
+	if input.Kind != PolicyKindRoute || input.Method != http.MethodPost || input.Path != "/api/v1/tasks" ||
+		input.RequiredRole != RoleContributor || input.RoleAllowed {
+		t.Fatalf("unexpected policy input: %+v", input)
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+
+	"hyper/internal/errcode"
+	"hyper/internal/priority"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestPriorityMiddleware attaches the priority and deadline hints of
+// X-Hyper-Priority and X-Hyper-Timeout-Ms to the request context, so storage
+// and vector operations stop at the deadline and bulk work yields to
+// interactive requests. Requests are interactive unless marked bulk.
+func RequestPriorityMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		level, timeout, err := priority.FromHeader(c.Request.Header)
+		if err != nil {
+			errcode.RespondCode(c, errcode.Validation, err.Error())
+			c.Abort()
+			return
+		}
+		if level == priority.Unset {
+			level = priority.Interactive
+		}
+
+		ctx := priority.WithLevel(c.Request.Context(), level)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hyper/internal/priority"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestPriorityMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var level priority.Level
+	var hasDeadline bool
+	r := gin.New()
+	r.Use(RequestPriorityMiddleware())
+	r.GET("/", func(c *gin.Context) {
+		level = priority.FromContext(c.Request.Context())
+		_, hasDeadline = c.Request.Context().Deadline()
+		c.Status(http.StatusNoContent)
+	})
+
+	serve := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve(nil); w.Code != http.StatusNoContent || level != priority.Interactive || hasDeadline {
+		t.Fatalf("default request: status %d, level %q, deadline %v", w.Code, level, hasDeadline)
+	}
+
+	w := serve(map[string]string{priority.PriorityHeader: "bulk", priority.TimeoutHeader: "2000"})
+	if w.Code != http.StatusNoContent || level != priority.Bulk || !hasDeadline {
+		t.Fatalf("bulk request: status %d, level %q, deadline %v", w.Code, level, hasDeadline)
+	}
+
+	if w := serve(map[string]string{priority.TimeoutHeader: "soon"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid timeout: status %d, want 400", w.Code)
+	}
+}
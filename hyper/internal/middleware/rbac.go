@@ -0,0 +1,331 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"hyper/internal/errcode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// Role represents an access level granted to a caller
+type Role string
+
+const (
+	RoleViewer      Role = "viewer"
+	RoleContributor Role = "contributor"
+	RoleOperator    Role = "operator"
+	RoleAdmin       Role = "admin"
+)
+
+// RoleHeader carries the resolved role from the HTTP layer to the MCP layer.
+// Any client-supplied value is overwritten by RBACMiddleware.
+const RoleHeader = "X-Hyper-Role"
+
+// roleRanks orders roles from least to most privileged
+var roleRanks = map[Role]int{
+	RoleViewer:      1,
+	RoleContributor: 2,
+	RoleOperator:    3,
+	RoleAdmin:       4,
+}
+
+// AllRoles lists the supported roles in ascending privilege order
+var AllRoles = []Role{RoleViewer, RoleContributor, RoleOperator, RoleAdmin}
+
+// ParseRole converts a string into a Role, returning false if it is unknown
+func ParseRole(value string) (Role, bool) {
+	role := Role(strings.ToLower(strings.TrimSpace(value)))
+	_, ok := roleRanks[role]
+	return role, ok
+}
+
+// Allows reports whether the role satisfies the required role
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// RoleStore resolves explicit role assignments for users
+type RoleStore interface {
+	GetUserRole(userID string) (string, error)
+}
+
+// DefaultRole returns the role used when neither a stored assignment nor a
+// claim grants one. RBAC_DEFAULT_ROLE overrides it; otherwise dev mode (JWT
+// disabled) keeps full access and authenticated callers get contributor.
+func DefaultRole() Role {
+	if role, ok := ParseRole(os.Getenv("RBAC_DEFAULT_ROLE")); ok {
+		return role
+	}
+	enableJWT := os.Getenv("ENABLE_JWT")
+	if enableJWT == "true" || enableJWT == "1" {
+		return RoleContributor
+	}
+	return RoleAdmin
+}
+
+// RoleFromClaims extracts the highest role granted by JWT claims.
+// Supports "role", "roles", and OAuth-style "scope"/"scopes" (e.g. "hyper:operator").
+func RoleFromClaims(claims jwt.MapClaims) (Role, bool) {
+	var candidates []string
+
+	collect := func(value interface{}) {
+		switch v := value.(type) {
+		case string:
+			candidates = append(candidates, strings.Fields(v)...)
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					candidates = append(candidates, s)
+				}
+			}
+		case []string:
+			candidates = append(candidates, v...)
+		}
+	}
+
+	for _, key := range []string{"role", "roles", "scope", "scopes"} {
+		if value, ok := claims[key]; ok {
+			collect(value)
+		}
+	}
+
+	var best Role
+	for _, candidate := range candidates {
+		candidate = strings.TrimPrefix(candidate, "hyper:")
+		if role, ok := ParseRole(candidate); ok && roleRanks[role] > roleRanks[best] {
+			best = role
+		}
+	}
+
+	return best, best != ""
+}
+
+// RequiredRoleForRoute returns the minimum role needed for an HTTP request.
+// Reads are open to viewers, writes need contributor, and destructive or
+// administrative routes are elevated explicitly.
+func RequiredRoleForRoute(method, path string) Role {
+	switch {
+	case strings.HasPrefix(path, "/api/v1/admin"):
+		return RoleAdmin
+	case strings.HasPrefix(path, "/api/v1/staged-operations"):
+		// Like coordinator_undo and coordinator_confirm_operation
+		return RoleAdmin
+	case method == http.MethodDelete && strings.HasPrefix(path, "/api/v1/code-index"):
+		return RoleOperator
+	case method == http.MethodDelete && strings.HasPrefix(path, "/api/v1/tools"):
+		return RoleOperator
+	case method == http.MethodPut && path == "/api/v1/ai/system-prompt":
+		return RoleOperator
+	case method == http.MethodPut && strings.HasPrefix(path, "/api/v1/subagents/") && strings.HasSuffix(path, "/persona"):
+		return RoleOperator
+	case path == "/mcp":
+		// Individual tool calls are authorized by the MCP middleware
+		return RoleViewer
+	case strings.HasPrefix(path, "/api/tools/"):
+		// Proxied tool calls are authorized per tool by the REST tool proxy
+		return RoleViewer
+	case tokenAuthenticated(path):
+		// Webhooks, the calendar feed and share links authenticate with their own token
+		return RoleViewer
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return RoleViewer
+	case http.MethodPost:
+		// Search and query endpoints are read-only despite using POST
+		if strings.HasSuffix(path, "/search") || strings.HasSuffix(path, "/query") {
+			return RoleViewer
+		}
+	}
+
+	return RoleContributor
+}
+
+// toolRoles overrides the default role required for specific MCP tools
+var toolRoles = map[string]Role{
+	"coordinator_clear_task_board":        RoleAdmin,
+	"coordinator_undo":                    RoleAdmin,
+	"coordinator_confirm_operation":       RoleAdmin,
+	"coordinator_diagnose":                RoleOperator,
+	"coordinator_set_digest_subscription": RoleAdmin,
+	"coordinator_send_digest":             RoleOperator,
+	"coordinator_review_adr":              RoleOperator,
+	"coordinator_set_agent_persona":       RoleOperator,
+	"coordinator_set_agent_bootstrap":     RoleOperator,
+	"coordinator_set_automation_hook":     RoleAdmin,
+	"coordinator_erase_data_subject":      RoleAdmin,
+	"coordinator_set_federation_peer":     RoleAdmin,
+	"coordinator_migrate_collection":      RoleAdmin,
+	"coordinator_switch_collection_alias": RoleAdmin,
+	"coordinator_export_knowledge":        RoleOperator,
+	"coordinator_import_knowledge":        RoleOperator,
+	"coordinator_create_scheduled_task":   RoleOperator,
+	"coordinator_delete_scheduled_task":   RoleOperator,
+	"mcp_add_server":                      RoleAdmin,
+	"mcp_remove_server":                   RoleAdmin,
+	"mcp_rediscover_server":               RoleOperator,
+	"bash":                                RoleOperator,
+	"file_write":                          RoleOperator,
+	"apply_patch":                         RoleOperator,
+	"execute_tool":                        RoleOperator,
+	"code_index_add_folder":               RoleOperator,
+	"code_index_remove_folder":            RoleOperator,
+	"code_index_scan":                     RoleOperator,
+	"code_index_workspace_roots":          RoleOperator,
+}
+
+// readOnlyToolPrefixes identify tools that only read state
+var readOnlyToolPrefixes = []string{"coordinator_list_", "coordinator_get_", "coordinator_query_", "list_", "get_", "discover_"}
+
+// readOnlyTools are read-only tools not covered by readOnlyToolPrefixes
+var readOnlyTools = map[string]bool{
+	"code_index_search":                true,
+	"code_index_search_by_snippet":     true,
+	"code_index_recent_changes":        true,
+	"code_index_find_symbol":           true,
+	"code_index_get_definition":        true,
+	"code_index_status":                true,
+	"code_index_usage":                 true,
+	"code_index_explain":               true,
+	"knowledge_find":                   true,
+	"knowledge_explain":                true,
+	"coordinator_answer":               true,
+	"coordinator_search":               true,
+	"coordinator_export_run":           true,
+	"coordinator_read_resources":       true,
+	"coordinator_build_context_bundle": true,
+	"coordinator_test_automation_hook": true,
+	"coordinator_run_knowledge_eval":   true,
+	"file_read":                        true,
+}
+
+// RequiredRoleForTool returns the minimum role needed to call an MCP tool
+func RequiredRoleForTool(name string) Role {
+	if role, ok := toolRoles[name]; ok {
+		return role
+	}
+	if readOnlyTools[name] {
+		return RoleViewer
+	}
+	for _, prefix := range readOnlyToolPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return RoleViewer
+		}
+	}
+	return RoleContributor
+}
+
+// RoleFromHeader reads the role set by RBACMiddleware from forwarded request headers
+func RoleFromHeader(header http.Header) (Role, bool) {
+	if header == nil {
+		return "", false
+	}
+	return ParseRole(header.Get(RoleHeader))
+}
+
+// GetRole returns the role resolved for the current request
+func GetRole(c *gin.Context) Role {
+	if value, exists := c.Get("role"); exists {
+		if role, ok := value.(Role); ok {
+			return role
+		}
+	}
+	return ""
+}
+
+// apiKeyRoleKey holds the role of the API key a request authenticated with
+const apiKeyRoleKey = "apiKeyRole"
+
+// RBACMiddleware resolves the caller's role and enforces route policies.
+// Must be registered after AuthMiddleware so userId and claims are set.
+// Resolution order: stored assignment, JWT claims or API key scopes,
+// DefaultRole. A non-nil policy decides access in place of the per-route
+// minimum roles.
+func RBACMiddleware(store RoleStore, policy Policy, logger *zap.Logger) gin.HandlerFunc {
+	defaultRole := DefaultRole()
+	logger.Info("RBAC enforcement enabled", zap.String("defaultRole", string(defaultRole)))
+
+	return func(c *gin.Context) {
+		role := defaultRole
+
+		if claimsVal, exists := c.Get("jwtClaims"); exists {
+			if claims, ok := claimsVal.(jwt.MapClaims); ok {
+				if claimRole, ok := RoleFromClaims(claims); ok {
+					role = claimRole
+				}
+			}
+		}
+
+		if keyRole, ok := c.Get(apiKeyRoleKey); ok {
+			role = keyRole.(Role)
+		}
+
+		userID := c.GetString("userId")
+		if store != nil && userID != "" {
+			assigned, err := store.GetUserRole(userID)
+			if err != nil {
+				logger.Warn("Failed to look up role assignment", zap.String("userId", userID), zap.Error(err))
+			} else if assignedRole, ok := ParseRole(assigned); ok {
+				role = assignedRole
+			}
+		}
+
+		c.Set("role", role)
+		c.Request.Header.Set(RoleHeader, string(role))
+		c.Request.Header.Set(UserHeader, userID)
+
+		required := RequiredRoleForRoute(c.Request.Method, c.Request.URL.Path)
+		decision := Authorize(c.Request.Context(), policy, PolicyInput{
+			Kind:         PolicyKindRoute,
+			Principal:    Principal{UserID: userID, Role: role},
+			Method:       c.Request.Method,
+			Path:         c.Request.URL.Path,
+			RequiredRole: required,
+		})
+		if !decision.Allow {
+			if policy != nil {
+				logger.Warn("Request denied by policy",
+					zap.String("method", c.Request.Method),
+					zap.String("path", c.Request.URL.Path),
+					zap.String("userId", userID),
+					zap.String("reason", decision.Reason))
+				errcode.RespondDetails(c, errcode.PermissionDenied, "Denied by authorization policy", gin.H{
+					"role":   role,
+					"reason": decision.Reason,
+				})
+				c.Abort()
+				return
+			}
+			errcode.RespondDetails(c, errcode.PermissionDenied, "Insufficient role for this operation", gin.H{
+				"role":         role,
+				"requiredRole": required,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose resolved role is below the required role
+func RequireRole(required Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := GetRole(c)
+		if !role.Allows(required) {
+			errcode.RespondDetails(c, errcode.PermissionDenied, "Insufficient role for this operation", gin.H{
+				"role":         role,
+				"requiredRole": required,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+type mockRoleStore struct {
+	roles map[string]string
+	err   error
+}
+
+func (m *mockRoleStore) GetUserRole(userID string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.roles[userID], nil
+}
+
+func TestRoleAllows(t *testing.T) {
+	if !RoleAdmin.Allows(RoleOperator) {
+		t.Fatal("admin should satisfy operator")
+	}
+	if RoleViewer.Allows(RoleContributor) {
+		t.Fatal("viewer should not satisfy contributor")
+	}
+	if Role("").Allows(RoleViewer) {
+		t.Fatal("empty role should not satisfy viewer")
+	}
+}
+
+func TestRoleFromClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   Role
+		ok     bool
+	}{
+		{"role claim", jwt.MapClaims{"role": "Operator"}, RoleOperator, true},
+		{"roles array picks highest", jwt.MapClaims{"roles": []interface{}{"viewer", "admin"}}, RoleAdmin, true},
+		{"scope string", jwt.MapClaims{"scope": "openid hyper:contributor"}, RoleContributor, true},
+		{"unknown values", jwt.MapClaims{"role": "superuser"}, "", false},
+		{"no claims", jwt.MapClaims{}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RoleFromClaims(tt.claims)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("RoleFromClaims() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRequiredRoleForRoute(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         Role
+	}{
+		{http.MethodGet, "/api/v1/tasks", RoleViewer},
+		{http.MethodPost, "/api/v1/tasks", RoleContributor},
+		{http.MethodPost, "/api/v1/code-index/search", RoleViewer},
+		{http.MethodDelete, "/api/v1/code-index/remove-folder/abc", RoleOperator},
+		{http.MethodGet, "/api/v1/admin/roles", RoleAdmin},
+		{http.MethodPost, "/api/v1/staged-operations/token/undo", RoleAdmin},
+		{http.MethodPost, "/mcp", RoleViewer},
+		{http.MethodPost, "/api/tools/coordinator_clear_task_board", RoleViewer},
+		{http.MethodPost, "/api/v1/webhooks/jira", RoleViewer},
+		{http.MethodGet, "/api/calendar.ics", RoleViewer},
+		{http.MethodGet, "/api/share/token", RoleViewer},
+		{http.MethodDelete, "/api/v1/share-links/id", RoleContributor},
+		{http.MethodPut, "/api/v1/subagents/go-dev/persona", RoleOperator},
+		{http.MethodGet, "/api/v1/subagents/go-dev/persona", RoleViewer},
+	}
+
+	for _, tt := range tests {
+		if got := RequiredRoleForRoute(tt.method, tt.path); got != tt.want {
+			t.Errorf("RequiredRoleForRoute(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRequiredRoleForTool(t *testing.T) {
+	tests := map[string]Role{
+		"coordinator_clear_task_board":        RoleAdmin,
+		"coordinator_confirm_operation":       RoleAdmin,
+		"coordinator_list_agent_tasks":        RoleViewer,
+		"coordinator_update_task_status":      RoleContributor,
+		"code_index_search":                   RoleViewer,
+		"coordinator_set_automation_hook":     RoleAdmin,
+		"coordinator_test_automation_hook":    RoleViewer,
+		"coordinator_erase_data_subject":      RoleAdmin,
+		"coordinator_set_federation_peer":     RoleAdmin,
+		"coordinator_delegate_task":           RoleContributor,
+		"coordinator_switch_collection_alias": RoleAdmin,
+		"coordinator_list_collection_aliases": RoleViewer,
+		"coordinator_import_knowledge":        RoleOperator,
+		"bash":                                RoleOperator,
+		"knowledge_store":                     RoleContributor,
+	}
+
+	for tool, want := range tests {
+		if got := RequiredRoleForTool(tool); got != want {
+			t.Errorf("RequiredRoleForTool(%s) = %q, want %q", tool, got, want)
+		}
+	}
+}
+
+func newRBACRouter(store RoleStore) *gin.Engine {
+	r := gin.New()
+	r.Use(OptionalJWTMiddleware())
+	r.Use(RBACMiddleware(store, nil, zap.NewNop()))
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"role": GetRole(c), "header": c.GetHeader(RoleHeader)})
+	}
+	r.GET("/api/v1/tasks", handler)
+	r.POST("/api/v1/tasks", handler)
+	r.GET("/api/v1/admin/roles", handler)
+	return r
+}
+
+func TestRBACMiddleware_DefaultRoleInDevMode(t *testing.T) {
+	os.Unsetenv("ENABLE_JWT")
+	os.Unsetenv("RBAC_DEFAULT_ROLE")
+
+	r := newRBACRouter(nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/roles", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for dev admin, got %d", w.Code)
+	}
+}
+
+func TestRBACMiddleware_StoredAssignmentDenies(t *testing.T) {
+	os.Unsetenv("ENABLE_JWT")
+	os.Unsetenv("RBAC_DEFAULT_ROLE")
+
+	r := newRBACRouter(&mockRoleStore{roles: map[string]string{"dev-user": "viewer"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected viewer to read tasks, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/tasks", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected viewer to be forbidden from creating tasks, got %d", w.Code)
+	}
+}
+
+func TestRBACMiddleware_OverwritesSpoofedHeader(t *testing.T) {
+	os.Unsetenv("ENABLE_JWT")
+	os.Setenv("RBAC_DEFAULT_ROLE", "viewer")
+	defer os.Unsetenv("RBAC_DEFAULT_ROLE")
+
+	r := newRBACRouter(&mockRoleStore{err: errors.New("store unavailable")})
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+	req.Header.Set(RoleHeader, "admin")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if body != "{\"header\":\"viewer\",\"role\":\"viewer\"}" {
+		t.Fatalf("unexpected response body: %s", body)
+	}
+}
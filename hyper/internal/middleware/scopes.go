@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Scope limits what a credential may do, on top of the caller's role
+type Scope string
+
+const (
+	ScopeRead           Scope = "read"            // Reads, searches and queries
+	ScopeTaskWrite      Scope = "task-write"      // Writes other than knowledge
+	ScopeKnowledgeWrite Scope = "knowledge-write" // Knowledge writes
+	ScopeAdmin          Scope = "admin"           // Everything, including operator and admin routes and tools
+)
+
+// AllScopes lists the supported scopes
+var AllScopes = []Scope{ScopeRead, ScopeTaskWrite, ScopeKnowledgeWrite, ScopeAdmin}
+
+// ScopesHeader carries the scopes of the authenticated credential from the
+// HTTP layer to the MCP layer as a space-separated list. It is absent when
+// the credential is unrestricted; any client-supplied value is removed by
+// the auth middleware.
+const ScopesHeader = "X-Hyper-Scopes"
+
+// ParseScope converts a string into a Scope, returning false if it is
+// unknown. A "hyper:" prefix is accepted, as in OAuth scope claims.
+func ParseScope(value string) (Scope, bool) {
+	scope := Scope(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), "hyper:"))
+	switch scope {
+	case ScopeRead, ScopeTaskWrite, ScopeKnowledgeWrite, ScopeAdmin:
+		return scope, true
+	}
+	return "", false
+}
+
+// Scopes are the scopes granted to a credential. Nil means unrestricted:
+// requests without a credential (dev mode) and JWTs without scope claims.
+type Scopes []Scope
+
+// Allows reports whether the scopes permit a request needing required. Every
+// scope includes read, and admin includes everything.
+func (s Scopes) Allows(required Scope) bool {
+	if s == nil {
+		return true
+	}
+	for _, scope := range s {
+		if scope == ScopeAdmin || scope == required || required == ScopeRead {
+			return true
+		}
+	}
+	return false
+}
+
+// Role returns the role a credential with these scopes acts as: admin with
+// the admin scope, contributor with a write scope, viewer otherwise
+func (s Scopes) Role() Role {
+	role := RoleViewer
+	for _, scope := range s {
+		switch scope {
+		case ScopeAdmin:
+			return RoleAdmin
+		case ScopeTaskWrite, ScopeKnowledgeWrite:
+			role = RoleContributor
+		}
+	}
+	return role
+}
+
+// String formats the scopes for ScopesHeader
+func (s Scopes) String() string {
+	names := make([]string, len(s))
+	for i, scope := range s {
+		names[i] = string(scope)
+	}
+	return strings.Join(names, " ")
+}
+
+// ScopesFromClaims extracts the scopes granted by the "scope"/"scopes"
+// claims of a JWT. Values that are not scopes, such as "hyper:operator"
+// roles, are ignored; a token without any scope is unrestricted.
+func ScopesFromClaims(claims jwt.MapClaims) Scopes {
+	var scopes Scopes
+	for _, key := range []string{"scope", "scopes"} {
+		var values []string
+		switch v := claims[key].(type) {
+		case string:
+			values = strings.Fields(v)
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+		for _, value := range values {
+			if scope, ok := ParseScope(value); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// ScopesFromHeader reads the scopes forwarded by the auth middleware; nil
+// when the header is absent
+func ScopesFromHeader(header http.Header) Scopes {
+	if header == nil || header.Get(ScopesHeader) == "" {
+		return nil
+	}
+	scopes := Scopes{}
+	for _, value := range strings.Fields(header.Get(ScopesHeader)) {
+		if scope, ok := ParseScope(value); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// GetScopes returns the scopes of the credential of the current request
+func GetScopes(c *gin.Context) Scopes {
+	if value, exists := c.Get("scopes"); exists {
+		if scopes, ok := value.(Scopes); ok {
+			return scopes
+		}
+	}
+	return nil
+}
+
+// requiredScope maps the role a route or tool requires to a scope: viewer
+// work needs read, operator and admin work needs admin, and other writes
+// need knowledge-write or task-write
+func requiredScope(role Role, knowledge bool) Scope {
+	switch {
+	case role == RoleViewer:
+		return ScopeRead
+	case role.Allows(RoleOperator):
+		return ScopeAdmin
+	case knowledge:
+		return ScopeKnowledgeWrite
+	}
+	return ScopeTaskWrite
+}
+
+// RequiredScopeForRoute returns the scope needed for an HTTP request
+func RequiredScopeForRoute(method, path string) Scope {
+	return requiredScope(RequiredRoleForRoute(method, path), strings.HasPrefix(path, "/api/v1/knowledge"))
+}
+
+// knowledgeWriteTools write knowledge without "knowledge" in their name
+var knowledgeWriteTools = map[string]bool{
+	"coordinator_draft_adr": true,
+}
+
+// RequiredScopeForTool returns the scope needed to call an MCP tool
+func RequiredScopeForTool(name string) Scope {
+	return requiredScope(RequiredRoleForTool(name), strings.Contains(name, "knowledge") || knowledgeWriteTools[name])
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestScopesAllows(t *testing.T) {
+	tests := []struct {
+		scopes   Scopes
+		required Scope
+		want     bool
+	}{
+		{nil, ScopeAdmin, true},
+		{Scopes{ScopeRead}, ScopeRead, true},
+		{Scopes{ScopeRead}, ScopeTaskWrite, false},
+		{Scopes{ScopeKnowledgeWrite}, ScopeRead, true},
+		{Scopes{ScopeKnowledgeWrite}, ScopeTaskWrite, false},
+		{Scopes{ScopeTaskWrite}, ScopeTaskWrite, true},
+		{Scopes{ScopeTaskWrite}, ScopeAdmin, false},
+		{Scopes{ScopeAdmin}, ScopeKnowledgeWrite, true},
+		{Scopes{}, ScopeRead, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.scopes.Allows(tt.required); got != tt.want {
+			t.Errorf("%v.Allows(%s) = %v, want %v", tt.scopes, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestScopesRole(t *testing.T) {
+	tests := map[string]struct {
+		scopes Scopes
+		want   Role
+	}{
+		"read":       {Scopes{ScopeRead}, RoleViewer},
+		"task write": {Scopes{ScopeRead, ScopeTaskWrite}, RoleContributor},
+		"admin":      {Scopes{ScopeKnowledgeWrite, ScopeAdmin}, RoleAdmin},
+	}
+
+	for name, tt := range tests {
+		if got := tt.scopes.Role(); got != tt.want {
+			t.Errorf("%s: Role() = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestRequiredScopeForRoute(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   Scope
+	}{
+		{http.MethodGet, "/api/v1/tasks", ScopeRead},
+		{http.MethodPost, "/api/v1/knowledge/query", ScopeRead},
+		{http.MethodPost, "/api/v1/tasks", ScopeTaskWrite},
+		{http.MethodPost, "/api/v1/knowledge", ScopeKnowledgeWrite},
+		{http.MethodDelete, "/api/v1/code-index/folders/1", ScopeAdmin},
+		{http.MethodGet, "/api/v1/admin/roles", ScopeAdmin},
+		{http.MethodPost, "/mcp", ScopeRead},
+	}
+
+	for _, tt := range tests {
+		if got := RequiredScopeForRoute(tt.method, tt.path); got != tt.want {
+			t.Errorf("RequiredScopeForRoute(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRequiredScopeForTool(t *testing.T) {
+	tests := map[string]Scope{
+		"coordinator_list_human_tasks":   ScopeRead,
+		"coordinator_query_knowledge":    ScopeRead,
+		"coordinator_update_task_status": ScopeTaskWrite,
+		"coordinator_upsert_knowledge":   ScopeKnowledgeWrite,
+		"coordinator_draft_adr":          ScopeKnowledgeWrite,
+		"coordinator_import_knowledge":   ScopeAdmin,
+		"bash":                           ScopeAdmin,
+	}
+
+	for tool, want := range tests {
+		if got := RequiredScopeForTool(tool); got != want {
+			t.Errorf("RequiredScopeForTool(%s) = %q, want %q", tool, got, want)
+		}
+	}
+}
+
+func TestScopesFromClaims(t *testing.T) {
+	if scopes := ScopesFromClaims(jwt.MapClaims{"scope": "hyper:operator"}); scopes != nil {
+		t.Errorf("expected roles to leave a token unrestricted, got %v", scopes)
+	}
+
+	scopes := ScopesFromClaims(jwt.MapClaims{"scope": "openid hyper:read", "scopes": []interface{}{"knowledge-write"}})
+	if scopes.String() != "read knowledge-write" {
+		t.Errorf("unexpected scopes %q", scopes.String())
+	}
+
+	header := http.Header{}
+	header.Set(ScopesHeader, scopes.String())
+	if got := ScopesFromHeader(header); got.String() != scopes.String() {
+		t.Errorf("ScopesFromHeader = %q, want %q", got.String(), scopes.String())
+	}
+	if ScopesFromHeader(http.Header{}) != nil {
+		t.Error("expected no header to be unrestricted")
+	}
+}
@@ -61,11 +61,8 @@ type CreateHTTPToolRequest struct {
 	AuthTokenField string              `json:"authTokenField"`
 }
 
-// HTTPToolListResponse represents paginated list of HTTP tools
+// HTTPToolListResponse represents a page of HTTP tools; paging is reported in
+// the response envelope's pagination metadata
 type HTTPToolListResponse struct {
-	Tools      []HTTPToolDefinition `json:"tools"`
-	Total      int64                `json:"total"`
-	Page       int                  `json:"page"`
-	PageSize   int                  `json:"pageSize"`
-	TotalPages int                  `json:"totalPages"`
+	Tools []HTTPToolDefinition `json:"tools"`
 }
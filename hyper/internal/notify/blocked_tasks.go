@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// RecipientsEnv lists the addresses emailed about blocked tasks
+const RecipientsEnv = "NOTIFY_EMAIL_TO"
+
+// blockedTaskStorage emails recipients whenever a task moves to blocked
+type blockedTaskStorage struct {
+	storage.TaskStorage
+	email      EmailSender
+	recipients []string
+	logger     *zap.Logger
+}
+
+// Unwrap returns the wrapped storage, for reads that bypass the wrapper
+func (s *blockedTaskStorage) Unwrap() storage.TaskStorage {
+	return s.TaskStorage
+}
+
+// WatchBlockedTasks wraps a task storage so that status changes to blocked are
+// emailed to NOTIFY_EMAIL_TO. Without SMTP or recipients it returns tasks unchanged.
+func WatchBlockedTasks(tasks storage.TaskStorage, mailer *Mailer, logger *zap.Logger) storage.TaskStorage {
+	list := os.Getenv(RecipientsEnv)
+	if list == "" || !mailer.Configured() {
+		return tasks
+	}
+
+	recipients, err := storage.ParseEmailRecipients(list)
+	if err != nil {
+		logger.Warn("Blocked task emails disabled", zap.String("env", RecipientsEnv), zap.Error(err))
+		return tasks
+	}
+
+	logger.Info("Blocked task emails enabled", zap.Int("recipients", len(recipients)))
+	return &blockedTaskStorage{TaskStorage: tasks, email: mailer, recipients: recipients, logger: logger}
+}
+
+// UpdateTaskStatus updates the status and emails recipients when a task that
+// was not blocked becomes blocked. Delivery happens in the background.
+func (s *blockedTaskStorage) UpdateTaskStatus(taskID string, status storage.TaskStatus, notes string) error {
+	if status != storage.TaskStatusBlocked {
+		return s.TaskStorage.UpdateTaskStatus(taskID, status, notes)
+	}
+
+	msg := s.blockedTaskEmail(taskID, notes)
+	if err := s.TaskStorage.UpdateTaskStatus(taskID, status, notes); err != nil {
+		return err
+	}
+	if msg == nil {
+		return nil
+	}
+
+	go func() {
+		if err := s.email.SendEmail(msg); err != nil {
+			s.logger.Warn("Failed to email blocked task", zap.String("taskId", taskID), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// blockedTask is the data of the task_blocked.html template
+type blockedTask struct {
+	Title   string
+	Kind    string
+	TaskID  string
+	Agent   string
+	Project string
+	Summary string
+	Notes   string
+	At      time.Time
+}
+
+// blockedTaskEmail builds the notification for a task about to be blocked, or
+// returns nil when it is already blocked or unknown
+func (s *blockedTaskStorage) blockedTaskEmail(taskID, notes string) *Email {
+	task := &blockedTask{Kind: "human", TaskID: taskID, Notes: notes, At: time.Now().UTC()}
+
+	if human, err := s.GetHumanTask(taskID); err == nil {
+		if human.Status == storage.TaskStatusBlocked {
+			return nil
+		}
+		task.Project = human.Project
+		task.Summary = human.Prompt
+	} else if agent, err := s.GetAgentTask(taskID); err == nil {
+		if agent.Status == storage.TaskStatusBlocked {
+			return nil
+		}
+		task.Kind = "agent"
+		task.Agent = agent.AgentName
+		task.Summary = agent.Role
+		if parent, err := s.GetHumanTask(agent.HumanTaskID); err == nil {
+			task.Project = parent.Project
+		}
+	} else {
+		return nil
+	}
+
+	task.Title = fmt.Sprintf("Task blocked: %s", truncate(task.Summary, 80))
+	text := fmt.Sprintf("A %s task is now blocked and may need attention.\n\nTask: %s\n", task.Kind, task.TaskID)
+	if task.Agent != "" {
+		text += fmt.Sprintf("Agent: %s\n", task.Agent)
+	}
+	if task.Project != "" {
+		text += fmt.Sprintf("Project: %s\n", task.Project)
+	}
+	text += fmt.Sprintf("Summary: %s\n", task.Summary)
+	if task.Notes != "" {
+		text += fmt.Sprintf("Notes: %s\n", task.Notes)
+	}
+
+	html, err := RenderHTML("task_blocked.html", task)
+	if err != nil {
+		// Plain text still carries everything
+		s.logger.Warn("Failed to render blocked task email", zap.Error(err))
+	}
+
+	return &Email{To: s.recipients, Subject: task.Title, Text: text, HTML: html}
+}
+
+// truncate cuts s to max runes
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
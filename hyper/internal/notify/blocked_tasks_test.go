@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryTasks keeps task statuses in memory
+type memoryTasks struct {
+	storage.TaskStorage
+	human map[string]*storage.HumanTask
+	agent map[string]*storage.AgentTask
+}
+
+func (m *memoryTasks) GetHumanTask(id string) (*storage.HumanTask, error) {
+	if task, ok := m.human[id]; ok {
+		return task, nil
+	}
+	return nil, fmt.Errorf("human task with ID %s not found", id)
+}
+
+func (m *memoryTasks) GetAgentTask(id string) (*storage.AgentTask, error) {
+	if task, ok := m.agent[id]; ok {
+		return task, nil
+	}
+	return nil, fmt.Errorf("agent task with ID %s not found", id)
+}
+
+func (m *memoryTasks) UpdateTaskStatus(id string, status storage.TaskStatus, notes string) error {
+	if task, ok := m.human[id]; ok {
+		task.Status = status
+		return nil
+	}
+	if task, ok := m.agent[id]; ok {
+		task.Status = status
+		return nil
+	}
+	return fmt.Errorf("task with ID %s not found", id)
+}
+
+// channelEmail hands sent emails to the test
+type channelEmail chan *Email
+
+func (c channelEmail) SendEmail(msg *Email) error {
+	c <- msg
+	return nil
+}
+
+func TestBlockedTaskStorage(t *testing.T) {
+	tasks := &memoryTasks{
+		human: map[string]*storage.HumanTask{
+			"h-1": {ID: "h-1", Prompt: "Ship digests", Project: "platform", Status: storage.TaskStatusInProgress},
+		},
+		agent: map[string]*storage.AgentTask{
+			"a-1": {ID: "a-1", HumanTaskID: "h-1", AgentName: "go-dev", Role: "Build scheduler", Status: storage.TaskStatusInProgress},
+		},
+	}
+	sent := make(channelEmail, 4)
+	watched := &blockedTaskStorage{TaskStorage: tasks, email: sent, recipients: []string{"lead@example.com"}, logger: zap.NewNop()}
+
+	require.NoError(t, watched.UpdateTaskStatus("a-1", storage.TaskStatusBlocked, "waiting for credentials"))
+	msg := receive(t, sent)
+	assert.Equal(t, []string{"lead@example.com"}, msg.To)
+	assert.Equal(t, "Task blocked: Build scheduler", msg.Subject)
+	assert.Contains(t, msg.Text, "Agent: go-dev\nProject: platform\n")
+	assert.Contains(t, msg.HTML, "waiting for credentials")
+	assert.Equal(t, storage.TaskStatusBlocked, tasks.agent["a-1"].Status)
+
+	// Already blocked, other statuses and unknown tasks send nothing
+	require.NoError(t, watched.UpdateTaskStatus("a-1", storage.TaskStatusBlocked, "still waiting"))
+	require.NoError(t, watched.UpdateTaskStatus("h-1", storage.TaskStatusCompleted, ""))
+	assert.Error(t, watched.UpdateTaskStatus("missing", storage.TaskStatusBlocked, ""))
+
+	select {
+	case msg := <-sent:
+		t.Fatalf("unexpected email %q", msg.Subject)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWatchBlockedTasks_Disabled(t *testing.T) {
+	tasks := &memoryTasks{}
+	t.Setenv(RecipientsEnv, "lead@example.com")
+	assert.Same(t, tasks, WatchBlockedTasks(tasks, NewMailer(SMTPConfig{}), zap.NewNop()))
+
+	t.Setenv(RecipientsEnv, "")
+	assert.Same(t, tasks, WatchBlockedTasks(tasks, NewMailer(SMTPConfig{Host: "smtp", From: "a@example.com"}), zap.NewNop()))
+}
+
+func receive(t *testing.T, sent channelEmail) *Email {
+	t.Helper()
+	select {
+	case msg := <-sent:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no email sent")
+		return nil
+	}
+}
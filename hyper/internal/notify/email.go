@@ -0,0 +1,133 @@
+// Package notify delivers notifications (digests, blocked tasks) by email.
+package notify
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// SMTPConfig configures email delivery
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// LoadSMTPConfig reads SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME,
+// SMTP_PASSWORD and NOTIFY_EMAIL_FROM (DIGEST_EMAIL_FROM is still accepted,
+// defaulting to SMTP_USERNAME)
+func LoadSMTPConfig() SMTPConfig {
+	cfg := SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("NOTIFY_EMAIL_FROM"),
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	if cfg.From == "" {
+		cfg.From = os.Getenv("DIGEST_EMAIL_FROM")
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	return cfg
+}
+
+// Email is a notification email with plain text and HTML bodies
+type Email struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string // Optional; sent as the preferred alternative to Text
+}
+
+// EmailSender sends notification emails (implemented by Mailer)
+type EmailSender interface {
+	SendEmail(msg *Email) error
+}
+
+// Mailer sends email through an SMTP server
+type Mailer struct {
+	cfg      SMTPConfig
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewMailer creates a mailer for the given SMTP settings
+func NewMailer(cfg SMTPConfig) *Mailer {
+	return &Mailer{cfg: cfg, sendMail: smtp.SendMail}
+}
+
+// Configured reports whether an SMTP host and sender are set
+func (m *Mailer) Configured() bool {
+	return m.cfg.Host != "" && m.cfg.From != ""
+}
+
+// SendEmail sends a message, as multipart/alternative when it has an HTML body
+func (m *Mailer) SendEmail(msg *Email) error {
+	if !m.Configured() {
+		return fmt.Errorf("email delivery unavailable: set SMTP_HOST and NOTIFY_EMAIL_FROM")
+	}
+	if len(msg.To) == 0 {
+		return fmt.Errorf("email recipients are required")
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+	if err := m.sendMail(addr, auth, m.cfg.From, msg.To, buildMessage(m.cfg.From, msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// buildMessage renders the MIME message
+func buildMessage(from string, msg *Email) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	text := crlf(msg.Text)
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		b.WriteString(text)
+		return []byte(b.String())
+	}
+
+	boundary := newBoundary()
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n", boundary, text)
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s\r\n", boundary, crlf(msg.HTML))
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return []byte(b.String())
+}
+
+// crlf normalizes line endings for SMTP
+func crlf(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
+
+func newBoundary() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("hyper-%d", time.Now().UnixNano())
+	}
+	return "hyper-" + hex.EncodeToString(buf)
+}
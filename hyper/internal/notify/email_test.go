@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMailer_SendEmail(t *testing.T) {
+	var gotAddr, gotFrom, gotMsg string
+	var gotTo []string
+	mailer := NewMailer(SMTPConfig{Host: "smtp.example.com", Port: "2525", From: "hyper@example.com"})
+	mailer.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, string(msg)
+		return nil
+	}
+
+	err := mailer.SendEmail(&Email{
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: "Task blocked: déploiement",
+		Text:    "line one\nline two",
+		HTML:    "<p>line one</p>",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "smtp.example.com:2525", gotAddr)
+	assert.Equal(t, "hyper@example.com", gotFrom)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, gotTo)
+	assert.Contains(t, gotMsg, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, gotMsg, "Subject: =?utf-8?q?")
+	assert.Contains(t, gotMsg, "Content-Type: multipart/alternative; boundary=")
+	assert.Contains(t, gotMsg, "Content-Type: text/plain; charset=UTF-8\r\n\r\nline one\r\nline two\r\n")
+	assert.Contains(t, gotMsg, "Content-Type: text/html; charset=UTF-8\r\n\r\n<p>line one</p>\r\n")
+	assert.True(t, strings.HasSuffix(gotMsg, "--\r\n"))
+}
+
+func TestMailer_PlainTextOnly(t *testing.T) {
+	msg := string(buildMessage("hyper@example.com", &Email{To: []string{"a@example.com"}, Subject: "Hi", Text: "body"}))
+	assert.Contains(t, msg, "Content-Type: text/plain; charset=UTF-8\r\n\r\nbody")
+	assert.NotContains(t, msg, "multipart")
+}
+
+func TestMailer_NotConfigured(t *testing.T) {
+	err := NewMailer(SMTPConfig{}).SendEmail(&Email{To: []string{"a@example.com"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unavailable")
+}
+
+func TestRenderHTML_EscapesContent(t *testing.T) {
+	html, err := RenderHTML("task_blocked.html", blockedTask{
+		Title:   "Task blocked",
+		Kind:    "agent",
+		TaskID:  "t-1",
+		Summary: "<script>alert(1)</script>",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, html, "<title>Task blocked</title>")
+	assert.Contains(t, html, "&lt;script&gt;")
+	assert.NotContains(t, html, "<script>")
+}
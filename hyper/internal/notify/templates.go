@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// templateFuncs are available to every email template
+var templateFuncs = template.FuncMap{
+	"datetime": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") },
+	"sub":      func(a, b int) int { return a - b },
+	"dict": func(pairs ...interface{}) map[string]interface{} {
+		m := make(map[string]interface{}, len(pairs)/2)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			key, _ := pairs[i].(string)
+			m[key] = pairs[i+1]
+		}
+		return m
+	},
+}
+
+// RenderHTML renders an email body from templates/<name>, wrapped in the
+// shared layout. Data must have a Title field or key, shown as the heading.
+func RenderHTML(name string, data interface{}) (string, error) {
+	tmpl, err := template.New("layout.html").Funcs(templateFuncs).ParseFS(templateFS, "templates/layout.html", "templates/"+name)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse email template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "layout.html", data); err != nil {
+		return "", fmt.Errorf("failed to render email template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
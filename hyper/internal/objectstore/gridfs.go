@@ -0,0 +1,79 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GridFSStore stores objects in a MongoDB GridFS bucket, using the key as
+// the file ID
+type GridFSStore struct {
+	bucket *gridfs.Bucket
+}
+
+// NewGridFSStore creates a store backed by the named GridFS bucket
+func NewGridFSStore(db *mongo.Database, bucketName string) (*GridFSStore, error) {
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(bucketName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GridFS bucket %s: %w", bucketName, err)
+	}
+	return &GridFSStore{bucket: bucket}, nil
+}
+
+// Backend returns "gridfs"
+func (s *GridFSStore) Backend() string {
+	return BackendGridFS
+}
+
+// Put stores content as a GridFS file, replacing any file with the same key
+func (s *GridFSStore) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	if err := s.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	upload, err := s.bucket.OpenUploadStreamWithID(key, path.Base(key),
+		options.GridFSUpload().SetMetadata(bson.M{"contentType": contentType}))
+	if err != nil {
+		return fmt.Errorf("failed to open GridFS upload: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		upload.SetWriteDeadline(deadline)
+	}
+	if _, err := io.Copy(upload, content); err != nil {
+		upload.Abort()
+		return err
+	}
+	if err := upload.Close(); err != nil {
+		return fmt.Errorf("failed to store GridFS file: %w", err)
+	}
+	return nil
+}
+
+// Get opens a GridFS file for reading
+func (s *GridFSStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	download, err := s.bucket.OpenDownloadStream(key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GridFS file: %w", err)
+	}
+	return download, nil
+}
+
+// Delete removes a GridFS file and its chunks
+func (s *GridFSStore) Delete(ctx context.Context, key string) error {
+	err := s.bucket.DeleteContext(ctx, key)
+	if err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return fmt.Errorf("failed to delete GridFS file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package objectstore stores binary objects such as task artifacts outside
+// the documents that reference them, in MongoDB GridFS or an S3-compatible
+// service (AWS S3, MinIO).
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Backend names, as set in OBJECT_STORAGE
+const (
+	BackendGridFS = "gridfs"
+	BackendS3     = "s3"
+)
+
+// BackendEnv selects where new objects are written
+const BackendEnv = "OBJECT_STORAGE"
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Store reads and writes objects by key. Keys are slash-separated paths such
+// as "task-artifacts/<task>/<artifact>".
+type Store interface {
+	// Backend returns the backend name recorded with stored objects
+	Backend() string
+	// Put stores content under key, replacing any object there. size is the
+	// content length in bytes, or -1 when unknown.
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	// Get returns a reader of the object's content, which the caller must close
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// BackendFromEnv returns the backend configured in OBJECT_STORAGE, defaulting
+// to GridFS
+func BackendFromEnv() (string, error) {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv(BackendEnv)))
+	switch backend {
+	case "", BackendGridFS:
+		return BackendGridFS, nil
+	case BackendS3, "minio":
+		return BackendS3, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q: must be gridfs or s3", BackendEnv, backend)
+	}
+}
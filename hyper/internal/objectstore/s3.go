@@ -0,0 +1,328 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables configuring the S3 backend
+const (
+	S3EndpointEnv       = "S3_ENDPOINT"          // e.g. http://minio:9000; empty uses AWS
+	S3RegionEnv         = "S3_REGION"            // default us-east-1
+	S3BucketEnv         = "S3_BUCKET"            // required
+	S3AccessKeyIDEnv    = "S3_ACCESS_KEY_ID"     // falls back to AWS_ACCESS_KEY_ID
+	S3SecretKeyEnv      = "S3_SECRET_ACCESS_KEY" // falls back to AWS_SECRET_ACCESS_KEY
+	S3SessionTokenEnv   = "S3_SESSION_TOKEN"     // optional, falls back to AWS_SESSION_TOKEN
+	S3PrefixEnv         = "S3_PREFIX"            // optional key prefix shared by all objects
+	S3ForcePathStyleEnv = "S3_FORCE_PATH_STYLE"  // default true with S3_ENDPOINT, false for AWS
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Config configures an S3-compatible store
+type S3Config struct {
+	Endpoint        string // Base URL; empty uses https://s3.<region>.amazonaws.com
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Prefix          string // Prepended to every key
+	ForcePathStyle  bool   // Address the bucket in the path instead of the host name (MinIO)
+}
+
+// S3ConfigFromEnv reads the S3 configuration from S3_* variables, falling
+// back to the standard AWS_* credentials
+func S3ConfigFromEnv() (S3Config, error) {
+	cfg := S3Config{
+		Endpoint:        strings.TrimSuffix(os.Getenv(S3EndpointEnv), "/"),
+		Region:          firstEnv(S3RegionEnv, "AWS_REGION"),
+		Bucket:          os.Getenv(S3BucketEnv),
+		AccessKeyID:     firstEnv(S3AccessKeyIDEnv, "AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: firstEnv(S3SecretKeyEnv, "AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    firstEnv(S3SessionTokenEnv, "AWS_SESSION_TOKEN"),
+		Prefix:          os.Getenv(S3PrefixEnv),
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	cfg.ForcePathStyle = cfg.Endpoint != ""
+	if raw := os.Getenv(S3ForcePathStyleEnv); raw != "" {
+		forcePathStyle, err := strconv.ParseBool(raw)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s %q: must be true or false", S3ForcePathStyleEnv, raw)
+		}
+		cfg.ForcePathStyle = forcePathStyle
+	}
+
+	if cfg.Bucket == "" {
+		return cfg, fmt.Errorf("%s is required with %s=s3", S3BucketEnv, BackendEnv)
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return cfg, fmt.Errorf("%s and %s are required with %s=s3", S3AccessKeyIDEnv, S3SecretKeyEnv, BackendEnv)
+	}
+	return cfg, nil
+}
+
+// firstEnv returns the first non-empty variable among names
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// S3Store stores objects in an S3-compatible bucket, signing requests with
+// AWS Signature Version 4
+type S3Store struct {
+	cfg    S3Config
+	base   *url.URL
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Store creates a store for the configured bucket
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	base, err := url.Parse(endpoint)
+	if err != nil || base.Host == "" || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("invalid %s %q: must be an http(s) URL", S3EndpointEnv, cfg.Endpoint)
+	}
+	if !cfg.ForcePathStyle {
+		base.Host = cfg.Bucket + "." + base.Host
+	}
+	return &S3Store{
+		cfg:    cfg,
+		base:   base,
+		client: &http.Client{Timeout: 10 * time.Minute},
+		now:    time.Now,
+	}, nil
+}
+
+// Backend returns "s3"
+func (s *S3Store) Backend() string {
+	return BackendS3
+}
+
+// Put uploads content with a single PUT. Content of unknown size is spooled
+// to a temporary file first, since S3 needs the length up front.
+func (s *S3Store) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		spool, err := os.CreateTemp("", "hyper-object-*")
+		if err != nil {
+			return fmt.Errorf("failed to spool object: %w", err)
+		}
+		defer os.Remove(spool.Name())
+		defer spool.Close()
+
+		if size, err = io.Copy(spool, content); err != nil {
+			return err
+		}
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to spool object: %w", err)
+		}
+		content = spool
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req, "UNSIGNED-PAYLOAD")
+	if err != nil {
+		return fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get downloads an object
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes an object; S3 reports success for missing objects
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// newRequest builds a request for the object at key
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	objectPath := "/" + strings.TrimPrefix(s.cfg.Prefix+key, "/")
+	if s.cfg.ForcePathStyle {
+		objectPath = "/" + s.cfg.Bucket + objectPath
+	}
+	target := *s.base
+	target.Path = strings.TrimSuffix(s.base.Path, "/") + objectPath
+	target.RawPath = uriEncode(target.Path, false)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build S3 request: %w", err)
+	}
+	if body == nil {
+		req.Body = http.NoBody
+	}
+	return req, nil
+}
+
+// do signs and sends a request, turning error responses into errors
+func (s *S3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash, s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = xml.Unmarshal(body, &s3Err)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if s3Err.Code != "" {
+		return nil, fmt.Errorf("S3 returned %d %s: %s", resp.StatusCode, s3Err.Code, s3Err.Message)
+	}
+	return nil, fmt.Errorf("S3 returned %d", resp.StatusCode)
+}
+
+// sign adds AWS Signature Version 4 headers to req, signing the host and
+// every header already set on it
+func (s *S3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery sorts and encodes query parameters for signing
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, as SigV4
+// requires; slashes are kept unless encodeSlash is set
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hexSHA256(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,217 @@
+// Package priority carries per-request deadline and priority hints to the
+// storage layer.
+//
+// Interactive work (an agent waiting on a knowledge query or code search) is
+// counted while it runs against shared backends; bulk work (indexing) yields
+// to it between units of work, so agent queries no longer queue behind
+// indexing traffic. Work without a priority neither counts nor yields.
+package priority
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the priority of a unit of work
+type Level string
+
+const (
+	// Unset work neither counts as interactive nor yields
+	Unset Level = ""
+	// Interactive work is waited on by an agent or user
+	Interactive Level = "interactive"
+	// Bulk work, such as indexing, yields while interactive work is active
+	Bulk Level = "bulk"
+)
+
+// PriorityHeader lets HTTP clients mark a request as bulk (or interactive)
+const PriorityHeader = "X-Hyper-Priority"
+
+// TimeoutHeader sets a request deadline in milliseconds
+const TimeoutHeader = "X-Hyper-Timeout-Ms"
+
+// MaxBulkDelayEnv bounds how long bulk work waits for interactive work to
+// finish before it proceeds anyway, so indexing cannot starve
+const MaxBulkDelayEnv = "PRIORITY_BULK_MAX_DELAY"
+
+// DefaultMaxBulkDelay is used when MaxBulkDelayEnv is unset or invalid
+const DefaultMaxBulkDelay = 10 * time.Second
+
+// MaxTimeout caps the deadline a caller can request
+const MaxTimeout = 5 * time.Minute
+
+type contextKey struct{}
+
+// Parse returns the level named by s (case-insensitive)
+func Parse(s string) (Level, error) {
+	switch level := Level(strings.ToLower(strings.TrimSpace(s))); level {
+	case Interactive, Bulk:
+		return level, nil
+	default:
+		return Unset, fmt.Errorf("invalid priority %q: must be %q or %q", s, Interactive, Bulk)
+	}
+}
+
+// ParseTimeoutMs parses a timeout in milliseconds, capped at MaxTimeout
+func ParseTimeoutMs(raw string) (time.Duration, error) {
+	ms, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || ms <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must be a positive number of milliseconds", raw)
+	}
+	if ms > MaxTimeout.Milliseconds() {
+		return MaxTimeout, nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// FromHeader reads PriorityHeader and TimeoutHeader. Absent headers yield
+// Unset and 0.
+func FromHeader(h http.Header) (Level, time.Duration, error) {
+	level := Unset
+	if raw := h.Get(PriorityHeader); raw != "" {
+		parsed, err := Parse(raw)
+		if err != nil {
+			return Unset, 0, fmt.Errorf("%s: %w", PriorityHeader, err)
+		}
+		level = parsed
+	}
+
+	var timeout time.Duration
+	if raw := h.Get(TimeoutHeader); raw != "" {
+		parsed, err := ParseTimeoutMs(raw)
+		if err != nil {
+			return Unset, 0, fmt.Errorf("%s: %w", TimeoutHeader, err)
+		}
+		timeout = parsed
+	}
+	return level, timeout, nil
+}
+
+// WithLevel returns a context carrying level
+func WithLevel(ctx context.Context, level Level) context.Context {
+	return context.WithValue(ctx, contextKey{}, level)
+}
+
+// FromContext returns the level carried by ctx, or Unset
+func FromContext(ctx context.Context) Level {
+	if ctx != nil {
+		if level, ok := ctx.Value(contextKey{}).(Level); ok {
+			return level
+		}
+	}
+	return Unset
+}
+
+// MaxBulkDelayFromEnv returns MaxBulkDelayEnv, or DefaultMaxBulkDelay
+func MaxBulkDelayFromEnv() time.Duration {
+	if raw := os.Getenv(MaxBulkDelayEnv); raw != "" {
+		if delay, err := time.ParseDuration(raw); err == nil && delay >= 0 {
+			return delay
+		}
+	}
+	return DefaultMaxBulkDelay
+}
+
+// Scheduler tracks active interactive work and holds bulk work back while
+// there is any
+type Scheduler struct {
+	maxDelay func() time.Duration
+
+	mu     sync.Mutex
+	active int
+	idle   chan struct{} // Closed when active drops to zero; nil while idle
+}
+
+// NewScheduler creates a scheduler whose bulk work waits at most maxDelay
+// (read on every wait) for interactive work to finish
+func NewScheduler(maxDelay func() time.Duration) *Scheduler {
+	return &Scheduler{maxDelay: maxDelay}
+}
+
+// defaultScheduler is shared by the whole process, since all requests compete
+// for the same MongoDB, Qdrant and embedding backends
+var defaultScheduler = NewScheduler(MaxBulkDelayFromEnv)
+
+// Begin admits work at ctx's level. Interactive work counts as active until
+// done is called; bulk work first waits like Yield. err is ctx's error when
+// ctx ends first.
+func (s *Scheduler) Begin(ctx context.Context) (done func(), err error) {
+	switch FromContext(ctx) {
+	case Interactive:
+		s.mu.Lock()
+		s.active++
+		if s.idle == nil {
+			s.idle = make(chan struct{})
+		}
+		s.mu.Unlock()
+
+		var once sync.Once
+		return func() { once.Do(s.end) }, nil
+	case Bulk:
+		return func() {}, s.Yield(ctx)
+	default:
+		return func() {}, ctx.Err()
+	}
+}
+
+// end marks one unit of interactive work finished
+func (s *Scheduler) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+	if s.active == 0 && s.idle != nil {
+		close(s.idle)
+		s.idle = nil
+	}
+}
+
+// Yield waits while interactive work is active, at most the maximum bulk
+// delay. It returns ctx's error when ctx ends first.
+func (s *Scheduler) Yield(ctx context.Context) error {
+	s.mu.Lock()
+	idle := s.idle
+	s.mu.Unlock()
+	if idle == nil {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(s.maxDelay())
+	defer timer.Stop()
+	select {
+	case <-idle:
+		return nil
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Active returns the number of interactive units of work in progress
+func (s *Scheduler) Active() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
+
+// Begin admits work at ctx's level on the process-wide scheduler
+func Begin(ctx context.Context) (done func(), err error) {
+	return defaultScheduler.Begin(ctx)
+}
+
+// Yield holds bulk work back while interactive work is active on the
+// process-wide scheduler
+func Yield(ctx context.Context) error {
+	return defaultScheduler.Yield(ctx)
+}
+
+// Active returns the interactive work in progress on the process-wide scheduler
+func Active() int {
+	return defaultScheduler.Active()
+}
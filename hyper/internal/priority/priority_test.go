@@ -0,0 +1,116 @@
+package priority
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func fixedDelay(d time.Duration) func() time.Duration {
+	return func() time.Duration { return d }
+}
+
+func TestFromHeader(t *testing.T) {
+	h := http.Header{}
+	level, timeout, err := FromHeader(h)
+	if err != nil || level != Unset || timeout != 0 {
+		t.Fatalf("empty headers: got %q %s %v", level, timeout, err)
+	}
+
+	h.Set(PriorityHeader, "Bulk")
+	h.Set(TimeoutHeader, "1500")
+	level, timeout, err = FromHeader(h)
+	if err != nil || level != Bulk || timeout != 1500*time.Millisecond {
+		t.Fatalf("got %q %s %v", level, timeout, err)
+	}
+
+	h.Set(TimeoutHeader, "999999999999")
+	if _, timeout, _ = FromHeader(h); timeout != MaxTimeout {
+		t.Fatalf("timeout not capped: %s", timeout)
+	}
+
+	for header, value := range map[string]string{PriorityHeader: "urgent", TimeoutHeader: "-5"} {
+		bad := http.Header{}
+		bad.Set(header, value)
+		if _, _, err := FromHeader(bad); err == nil {
+			t.Errorf("%s=%s: expected an error", header, value)
+		}
+	}
+}
+
+func TestYieldWaitsForInteractiveWork(t *testing.T) {
+	s := NewScheduler(fixedDelay(time.Minute))
+	bulk := WithLevel(context.Background(), Bulk)
+
+	if err := s.Yield(bulk); err != nil {
+		t.Fatalf("idle scheduler: %v", err)
+	}
+
+	done, err := s.Begin(WithLevel(context.Background(), Interactive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Active() != 1 {
+		t.Fatalf("active = %d, want 1", s.Active())
+	}
+
+	yielded := make(chan error, 1)
+	go func() { yielded <- s.Yield(bulk) }()
+
+	select {
+	case <-yielded:
+		t.Fatal("bulk work did not wait for interactive work")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	done()
+	done() // idempotent
+	select {
+	case err := <-yielded:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("bulk work still waiting after interactive work finished")
+	}
+	if s.Active() != 0 {
+		t.Fatalf("active = %d, want 0", s.Active())
+	}
+}
+
+func TestYieldIsBounded(t *testing.T) {
+	s := NewScheduler(fixedDelay(20 * time.Millisecond))
+	done, _ := s.Begin(WithLevel(context.Background(), Interactive))
+	defer done()
+
+	start := time.Now()
+	if err := s.Yield(WithLevel(context.Background(), Bulk)); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Fatalf("bulk work waited %s, past the maximum delay", waited)
+	}
+}
+
+func TestBeginHonorsContext(t *testing.T) {
+	s := NewScheduler(fixedDelay(time.Minute))
+	done, _ := s.Begin(WithLevel(context.Background(), Interactive))
+	defer done()
+
+	ctx, cancel := context.WithTimeout(WithLevel(context.Background(), Bulk), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.Begin(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+
+	// Work without a priority neither waits nor counts
+	release, err := s.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if s.Active() != 1 {
+		t.Fatalf("active = %d, want 1", s.Active())
+	}
+}
@@ -0,0 +1,361 @@
+// Package reembed moves the code index to a new embedding model without
+// losing it. A migration fills a new version of the code index collection
+// (see storage.QdrantClient.PrepareCollectionVersion) by re-embedding the
+// chunks stored in MongoDB in batches, and switches the collection alias to
+// it once every chunk is in. Until then searches keep the old collection.
+package reembed
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+	"hyper/internal/mcp/summarizer"
+	"hyper/internal/priority"
+
+	"go.uber.org/zap"
+)
+
+// DefaultBatchSize is the number of chunks embedded per request
+const DefaultBatchSize = 32
+
+// Migration states
+const (
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+)
+
+// Chunks lists the code index sources (implemented by
+// storage.CodeIndexStorage)
+type Chunks interface {
+	ListFolders() ([]*storage.IndexedFolder, error)
+	ListFiles(folderID string) ([]*storage.IndexedFile, error)
+	ListChunks(fileID string) ([]*storage.FileChunk, error)
+}
+
+// Collections creates, fills and promotes collection versions (implemented
+// by storage.QdrantClient)
+type Collections interface {
+	PrepareCollectionVersion(ctx context.Context, alias string, vectorSize int) (string, bool, error)
+	PromoteCollectionVersion(ctx context.Context, alias, collection string) (*storage.AliasMigration, error)
+	UsesNamedVectors(ctx context.Context, collectionName string) (bool, error)
+	CodeIndexPointVectors(ctx context.Context, collectionName string, ids []string) (map[string]storage.StoredVectors, error)
+	UpsertCodeIndexPointsContext(ctx context.Context, collectionName string, points []storage.CodeIndexPoint) error
+}
+
+// Progress reports a migration, running or finished
+type Progress struct {
+	State       string                  `json:"state"`
+	Alias       string                  `json:"alias"`
+	Collection  string                  `json:"collection"` // Version being filled
+	Dimensions  int                     `json:"dimensions"`
+	Resumed     bool                    `json:"resumed,omitempty"` // Continues a version an interrupted migration left behind
+	TotalChunks int                     `json:"totalChunks"`       // Chunks recorded on the indexed files
+	Embedded    int                     `json:"embedded"`
+	Skipped     int                     `json:"skipped"` // Already in the new version
+	Failed      int                     `json:"failed"`
+	StartedAt   time.Time               `json:"startedAt"`
+	FinishedAt  *time.Time              `json:"finishedAt,omitempty"`
+	Error       string                  `json:"error,omitempty"`
+	Migration   *storage.AliasMigration `json:"migration,omitempty"` // Set once the alias is switched
+}
+
+// Migrator re-embeds the code index into a new collection version
+type Migrator struct {
+	chunks      Chunks
+	collections Collections
+	embedder    embeddings.EmbeddingClient
+	logger      *zap.Logger
+	BatchSize   int
+
+	mu       sync.Mutex
+	progress *Progress
+}
+
+// NewMigrator creates a migrator that embeds with embedder
+func NewMigrator(chunks Chunks, collections Collections, embedder embeddings.EmbeddingClient, logger *zap.Logger) *Migrator {
+	return &Migrator{chunks: chunks, collections: collections, embedder: embedder, logger: logger, BatchSize: DefaultBatchSize}
+}
+
+// Progress returns a copy of the progress of the latest migration, or nil
+// when none has run
+func (m *Migrator) Progress() *Progress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.progress == nil {
+		return nil
+	}
+	progress := *m.progress
+	return &progress
+}
+
+// update changes the progress under the lock
+func (m *Migrator) update(change func(p *Progress)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	change(m.progress)
+}
+
+// Run migrates alias to the embedder's dimensions. Chunks already in the new
+// version, from an interrupted run, are skipped. The alias is only switched
+// when every chunk made it in; otherwise Run fails and running it again
+// retries the rest.
+func (m *Migrator) Run(ctx context.Context, alias string) error {
+	m.mu.Lock()
+	if m.progress != nil && m.progress.State == StateRunning {
+		m.mu.Unlock()
+		return fmt.Errorf("a migration of %s is already running", m.progress.Alias)
+	}
+	dimensions := m.embedder.GetDimensions()
+	m.progress = &Progress{State: StateRunning, Alias: alias, Dimensions: dimensions, StartedAt: time.Now()}
+	m.mu.Unlock()
+
+	migration, err := m.run(priority.WithLevel(ctx, priority.Bulk), alias, dimensions)
+	finished := time.Now()
+	m.update(func(p *Progress) {
+		p.FinishedAt = &finished
+		p.Migration = migration
+		p.State = StateCompleted
+		if err != nil {
+			p.State = StateFailed
+			p.Error = err.Error()
+		}
+	})
+
+	progress := m.Progress()
+	fields := []zap.Field{
+		zap.String("alias", alias),
+		zap.String("collection", progress.Collection),
+		zap.Int("embedded", progress.Embedded),
+		zap.Int("skipped", progress.Skipped),
+		zap.Int("failed", progress.Failed),
+		zap.Duration("duration", finished.Sub(progress.StartedAt)),
+	}
+	if err != nil {
+		m.logger.Error("Code index re-embedding failed", append(fields, zap.Error(err))...)
+		return err
+	}
+	m.logger.Info("Code index re-embedded", append(fields, zap.String("previous", migration.Previous))...)
+	return nil
+}
+
+func (m *Migrator) run(ctx context.Context, alias string, dimensions int) (*storage.AliasMigration, error) {
+	collection, resumed, err := m.collections.PrepareCollectionVersion(ctx, alias, dimensions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the new collection: %w", err)
+	}
+	named, err := m.collections.UsesNamedVectors(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := m.chunks.ListFolders()
+	if err != nil {
+		return nil, err
+	}
+	filesByFolder := make(map[string][]*storage.IndexedFile, len(folders))
+	total := 0
+	for _, folder := range folders {
+		files, err := m.chunks.ListFiles(folder.ID)
+		if err != nil {
+			return nil, err
+		}
+		filesByFolder[folder.ID] = files
+		for _, file := range files {
+			total += file.ChunkCount
+		}
+	}
+	m.update(func(p *Progress) {
+		p.Collection = collection
+		p.Resumed = resumed
+		p.TotalChunks = total
+	})
+	m.logger.Info("Re-embedding code index",
+		zap.String("alias", alias),
+		zap.String("collection", collection),
+		zap.Bool("resumed", resumed),
+		zap.Int("dimensions", dimensions),
+		zap.Int("chunks", total))
+
+	batch := &batch{collection: collection, named: named}
+	for _, folder := range folders {
+		for _, file := range filesByFolder[folder.ID] {
+			chunks, err := m.chunks.ListChunks(file.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, chunk := range chunks {
+				if chunk.VectorID == "" {
+					continue
+				}
+				batch.add(folder, file, chunk)
+				if len(batch.chunks) >= m.batchSize() {
+					if err := m.flush(ctx, batch); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	if err := m.flush(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	if failed := m.Progress().Failed; failed > 0 {
+		return nil, fmt.Errorf("%d chunks could not be re-embedded; %s still points to its previous collection; restart to retry them", failed, alias)
+	}
+	migration, err := m.collections.PromoteCollectionVersion(ctx, alias, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to switch %s to %s: %w", alias, collection, err)
+	}
+	return migration, nil
+}
+
+func (m *Migrator) batchSize() int {
+	if m.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	return m.BatchSize
+}
+
+// batch collects chunks to embed together
+type batch struct {
+	collection string
+	named      bool
+	chunks     []*storage.FileChunk
+	payloads   []map[string]interface{}
+}
+
+func (b *batch) add(folder *storage.IndexedFolder, file *storage.IndexedFile, chunk *storage.FileChunk) {
+	b.chunks = append(b.chunks, chunk)
+	b.payloads = append(b.payloads, chunkPayload(folder, file, chunk))
+}
+
+func (b *batch) reset() {
+	b.chunks = b.chunks[:0]
+	b.payloads = b.payloads[:0]
+}
+
+// flush embeds the batch's chunks that are not in the new collection yet and
+// stores them under their existing point IDs, so MongoDB keeps pointing at
+// them. Embedding failures are counted and the migration goes on; storage
+// failures stop it.
+func (m *Migrator) flush(ctx context.Context, b *batch) error {
+	if len(b.chunks) == 0 {
+		return nil
+	}
+	defer b.reset()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ids := make([]string, len(b.chunks))
+	for i, chunk := range b.chunks {
+		ids[i] = chunk.VectorID
+	}
+	existing, err := m.collections.CodeIndexPointVectors(ctx, b.collection, ids)
+	if err != nil {
+		return err
+	}
+
+	var points []storage.CodeIndexPoint
+	var texts, summaries []string
+	var summarized []int // Indexes into points of chunks with a summary
+	for i, chunk := range b.chunks {
+		if _, ok := existing[chunk.VectorID]; ok {
+			continue
+		}
+		text := chunk.Content
+		if !b.named {
+			text = summarizer.EmbeddingText(chunk.Summary, chunk.Content)
+		} else if chunk.Summary != "" {
+			summarized = append(summarized, len(points))
+			summaries = append(summaries, chunk.Summary)
+		}
+		texts = append(texts, text)
+		points = append(points, storage.CodeIndexPoint{ID: chunk.VectorID, Payload: b.payloads[i]})
+	}
+	skipped := len(b.chunks) - len(points)
+	if len(points) == 0 {
+		m.update(func(p *Progress) { p.Skipped += skipped })
+		return nil
+	}
+
+	vectors, summaryVectors, err := m.embed(ctx, texts, summaries)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		m.logger.Warn("Failed to re-embed code index batch", zap.Int("chunks", len(points)), zap.Error(err))
+		m.update(func(p *Progress) {
+			p.Skipped += skipped
+			p.Failed += len(points)
+		})
+		return nil
+	}
+	for i := range points {
+		points[i].Vector = vectors[i]
+	}
+	for i, index := range summarized {
+		points[index].SummaryVector = summaryVectors[i]
+	}
+
+	if err := m.collections.UpsertCodeIndexPointsContext(ctx, b.collection, points); err != nil {
+		return fmt.Errorf("failed to store re-embedded chunks: %w", err)
+	}
+	m.update(func(p *Progress) {
+		p.Skipped += skipped
+		p.Embedded += len(points)
+	})
+	return nil
+}
+
+// embed embeds texts, and summaries when there are any, at bulk priority
+func (m *Migrator) embed(ctx context.Context, texts, summaries []string) (vectors, summaryVectors [][]float32, err error) {
+	done, err := priority.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer done()
+
+	if vectors, err = m.embedder.CreateEmbeddings(texts); err != nil {
+		return nil, nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, nil, fmt.Errorf("embedding model returned %d vectors for %d chunks", len(vectors), len(texts))
+	}
+	if len(summaries) > 0 {
+		if summaryVectors, err = m.embedder.CreateEmbeddings(summaries); err != nil {
+			return nil, nil, fmt.Errorf("failed to embed summaries: %w", err)
+		}
+		if len(summaryVectors) != len(summaries) {
+			return nil, nil, fmt.Errorf("embedding model returned %d vectors for %d summaries", len(summaryVectors), len(summaries))
+		}
+	}
+	return vectors, summaryVectors, nil
+}
+
+// chunkPayload rebuilds the payload the file watcher stores with a chunk
+func chunkPayload(folder *storage.IndexedFolder, file *storage.IndexedFile, chunk *storage.FileChunk) map[string]interface{} {
+	payload := map[string]interface{}{
+		"fileId":       file.ID,
+		"folderId":     folder.ID,
+		"folderPath":   folder.Path,
+		"filePath":     file.Path,
+		"relativePath": file.RelativePath,
+		"language":     file.Language,
+		"chunkNum":     chunk.ChunkNum,
+		"startLine":    chunk.StartLine,
+		"endLine":      chunk.EndLine,
+		"content":      chunk.Content,
+	}
+	if chunk.Summary != "" {
+		payload["summary"] = chunk.Summary
+	}
+	storage.TagCommentLanguage(payload, chunk.Content)
+	return payload
+}
@@ -0,0 +1,155 @@
+package reembed
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryIndex serves one folder of chunks and keeps collections in memory
+type memoryIndex struct {
+	files    []*storage.IndexedFile
+	chunks   map[string][]*storage.FileChunk
+	points   map[string]storage.CodeIndexPoint // Points of the new version, by ID
+	promoted string
+	resumed  bool
+}
+
+func (m *memoryIndex) ListFolders() ([]*storage.IndexedFolder, error) {
+	return []*storage.IndexedFolder{{ID: "folder", Path: "/src"}}, nil
+}
+
+func (m *memoryIndex) ListFiles(folderID string) ([]*storage.IndexedFile, error) {
+	return m.files, nil
+}
+
+func (m *memoryIndex) ListChunks(fileID string) ([]*storage.FileChunk, error) {
+	return m.chunks[fileID], nil
+}
+
+func (m *memoryIndex) PrepareCollectionVersion(ctx context.Context, alias string, vectorSize int) (string, bool, error) {
+	return "code_index_v2", m.resumed, nil
+}
+
+func (m *memoryIndex) PromoteCollectionVersion(ctx context.Context, alias, collection string) (*storage.AliasMigration, error) {
+	m.promoted = collection
+	return &storage.AliasMigration{Alias: alias, Collection: collection, Previous: "code_index_v1"}, nil
+}
+
+func (m *memoryIndex) UsesNamedVectors(ctx context.Context, collectionName string) (bool, error) {
+	return true, nil
+}
+
+func (m *memoryIndex) CodeIndexPointVectors(ctx context.Context, collectionName string, ids []string) (map[string]storage.StoredVectors, error) {
+	found := map[string]storage.StoredVectors{}
+	for _, id := range ids {
+		if point, ok := m.points[id]; ok {
+			found[id] = storage.StoredVectors{Code: point.Vector}
+		}
+	}
+	return found, nil
+}
+
+func (m *memoryIndex) UpsertCodeIndexPointsContext(ctx context.Context, collectionName string, points []storage.CodeIndexPoint) error {
+	for _, point := range points {
+		m.points[point.ID] = point
+	}
+	return nil
+}
+
+// embedder returns 3-dimensional vectors and fails on "broken" code
+type embedder struct {
+	calls int
+}
+
+func (e *embedder) CreateEmbedding(text string) ([]float32, error) {
+	return []float32{float32(len(text)), 0, 1}, nil
+}
+
+func (e *embedder) CreateEmbeddings(texts []string) ([][]float32, error) {
+	e.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if text == "broken" {
+			return nil, errors.New("model unavailable")
+		}
+		vectors[i], _ = e.CreateEmbedding(text)
+	}
+	return vectors, nil
+}
+
+func (e *embedder) GetDimensions() int { return 3 }
+
+func newIndex() *memoryIndex {
+	return &memoryIndex{
+		files: []*storage.IndexedFile{
+			{ID: "f1", Path: "/src/a.go", RelativePath: "a.go", Language: "go", ChunkCount: 2},
+			{ID: "f2", Path: "/src/b.go", RelativePath: "b.go", Language: "go", ChunkCount: 1},
+		},
+		chunks: map[string][]*storage.FileChunk{
+			"f1": {
+				{FileID: "f1", ChunkNum: 0, Content: "package a", VectorID: "p1", Summary: "Declares package a"},
+				{FileID: "f1", ChunkNum: 1, Content: "func A() {}", VectorID: "p2"},
+			},
+			"f2": {{FileID: "f2", ChunkNum: 0, Content: "package b", VectorID: "p3"}},
+		},
+		points: map[string]storage.CodeIndexPoint{},
+	}
+}
+
+func TestRun(t *testing.T) {
+	index := newIndex()
+	migrator := NewMigrator(index, index, &embedder{}, zap.NewNop())
+	migrator.BatchSize = 2
+
+	require.NoError(t, migrator.Run(context.Background(), "code_index"))
+	assert.Equal(t, "code_index_v2", index.promoted)
+	require.Len(t, index.points, 3)
+
+	point := index.points["p1"]
+	assert.Equal(t, []float32{9, 0, 1}, point.Vector)
+	assert.NotNil(t, point.SummaryVector, "summaries get their own vector")
+	assert.Equal(t, "a.go", point.Payload["relativePath"])
+	assert.Equal(t, "/src", point.Payload["folderPath"])
+	assert.Equal(t, "Declares package a", point.Payload["summary"])
+	assert.Nil(t, index.points["p2"].SummaryVector)
+
+	progress := migrator.Progress()
+	assert.Equal(t, StateCompleted, progress.State)
+	assert.Equal(t, 3, progress.TotalChunks)
+	assert.Equal(t, 3, progress.Embedded)
+	assert.Equal(t, "code_index_v1", progress.Migration.Previous)
+	assert.NotNil(t, progress.FinishedAt)
+}
+
+func TestRun_ResumesAndRetriesFailures(t *testing.T) {
+	index := newIndex()
+	index.chunks["f2"][0].Content = "broken"
+	models := &embedder{}
+	migrator := NewMigrator(index, index, models, zap.NewNop())
+	migrator.BatchSize = 2
+
+	err := migrator.Run(context.Background(), "code_index")
+	assert.ErrorContains(t, err, "1 chunks could not be re-embedded")
+	assert.Empty(t, index.promoted, "the alias stays until every chunk is in")
+	assert.Equal(t, StateFailed, migrator.Progress().State)
+	assert.Len(t, index.points, 2)
+
+	// The next run only embeds what is missing
+	index.chunks["f2"][0].Content = "package b"
+	index.resumed = true
+	models.calls = 0
+	require.NoError(t, migrator.Run(context.Background(), "code_index"))
+	progress := migrator.Progress()
+	assert.True(t, progress.Resumed)
+	assert.Equal(t, 2, progress.Skipped)
+	assert.Equal(t, 1, progress.Embedded)
+	assert.Equal(t, 1, models.calls)
+	assert.Equal(t, "code_index_v2", index.promoted)
+}
@@ -0,0 +1,182 @@
+package replica
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"hyper/internal/vectortier"
+)
+
+// nearLimitRatio is the share of the memory budget above which a cache
+// reports, and logs once, that it is near its limit
+const nearLimitRatio = 0.9
+
+// itemOverhead approximates the map, pointer and struct bookkeeping of one
+// cached entry or chunk
+const itemOverhead = 256
+
+// Usage reports the memory a cache's copy takes against its budget
+type Usage struct {
+	BudgetBytes    int64 `json:"budgetBytes"` // 0 means unlimited
+	UsedBytes      int64 `json:"usedBytes"`
+	NearLimit      bool  `json:"nearLimit"`
+	EvictedEntries int64 `json:"evictedEntries"` // Knowledge entries evicted since start
+	EvictedFiles   int64 `json:"evictedFiles"`   // Code files evicted since start
+}
+
+// DiskUsage reports the disk the vector snapshots in VECTOR_ARCHIVE_DIR take
+// against their budget. Snapshots hold the only copy of archived vectors, so
+// they are never evicted; archiving that would exceed the budget fails
+// instead.
+type DiskUsage struct {
+	Dir         string `json:"dir,omitempty"`
+	BudgetBytes int64  `json:"budgetBytes"` // 0 means unlimited
+	UsedBytes   int64  `json:"usedBytes"`
+	NearLimit   bool   `json:"nearLimit"`
+	Error       string `json:"error,omitempty"` // Why the last measurement failed
+}
+
+// entrySize estimates the memory of a cached knowledge entry
+func entrySize(cached *cachedEntry) int64 {
+	size := itemOverhead + 4*len(cached.vector)
+	if entry := cached.entry; entry != nil {
+		size += len(entry.ID) + len(entry.Collection) + len(entry.Text)
+		if len(entry.Metadata) > 0 {
+			metadata, _ := json.Marshal(entry.Metadata)
+			size += len(metadata)
+		}
+	}
+	return int64(size)
+}
+
+// chunkSize estimates the memory of a cached code chunk
+func chunkSize(cached *cachedChunk) int64 {
+	size := itemOverhead + 4*len(cached.vector)
+	if chunk := cached.chunk; chunk != nil {
+		size += len(chunk.FolderPath) + len(chunk.FileID) + len(chunk.FilePath) + len(chunk.RelativePath) +
+			len(chunk.Language) + len(chunk.Content) + len(chunk.Summary)
+	}
+	return int64(size)
+}
+
+// SetMemoryBudget caps the estimated memory of the cached copy; 0 removes
+// the cap. The budget is enforced after every sync.
+func (s *Store) SetMemoryBudget(bytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage.BudgetBytes = max(0, bytes)
+}
+
+// Usage returns the memory use measured by the last enforceBudget
+func (s *Store) Usage() Usage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.usage
+}
+
+// SetDiskBudget measures the vector snapshots in dir against bytes after
+// every sync; 0 removes the cap. An empty dir measures nothing.
+func (s *Store) SetDiskBudget(dir string, bytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disk.Dir = dir
+	s.disk.BudgetBytes = max(0, bytes)
+}
+
+// DiskUsage returns the disk use measured by the last measureDisk
+func (s *Store) DiskUsage() DiskUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.disk
+}
+
+// measureDisk measures the snapshot directory against the disk budget
+func (s *Store) measureDisk() DiskUsage {
+	s.mu.RLock()
+	dir := s.disk.Dir
+	s.mu.RUnlock()
+	if dir == "" {
+		return s.DiskUsage()
+	}
+
+	used, err := vectortier.DirSize(dir)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disk.Error = ""
+	if err != nil {
+		s.disk.Error = err.Error()
+		return s.disk
+	}
+	s.disk.UsedBytes = used
+	s.disk.NearLimit = s.disk.BudgetBytes > 0 && float64(used) >= nearLimitRatio*float64(s.disk.BudgetBytes)
+	return s.disk
+}
+
+// evictable is a knowledge entry or code file that can be dropped
+type evictable struct {
+	lastUsed int64     // Unix nanoseconds of the last query returning it; 0 if never
+	age      time.Time // Creation or update time, breaking ties oldest first
+	size     int64
+	drop     func()
+}
+
+// enforceBudget measures the cached copy and, while it exceeds the budget,
+// evicts the least recently used knowledge entries and code files. Content no
+// query has returned goes first, oldest first. It returns how many entries
+// and files it evicted.
+func (s *Store) enforceBudget() (entries, files int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var used int64
+	var candidates []evictable
+	for _, collectionEntries := range s.knowledge {
+		for id, cached := range collectionEntries {
+			size := entrySize(cached)
+			used += size
+			candidates = append(candidates, evictable{
+				lastUsed: cached.lastUsed.Load(),
+				age:      cached.entry.CreatedAt,
+				size:     size,
+				drop:     func() { delete(collectionEntries, id); entries++ },
+			})
+		}
+	}
+	for _, folderFiles := range s.code {
+		for fileID, chunks := range folderFiles {
+			candidate := evictable{}
+			for _, cached := range chunks {
+				candidate.size += chunkSize(cached)
+				candidate.lastUsed = max(candidate.lastUsed, cached.lastUsed.Load())
+				candidate.age = cached.chunk.FileUpdatedAt
+			}
+			used += candidate.size
+			candidate.drop = func() { delete(folderFiles, fileID); files++ }
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	budget := s.usage.BudgetBytes
+	if budget > 0 && used > budget {
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].lastUsed != candidates[j].lastUsed {
+				return candidates[i].lastUsed < candidates[j].lastUsed
+			}
+			return candidates[i].age.Before(candidates[j].age)
+		})
+		for _, candidate := range candidates {
+			if used <= budget {
+				break
+			}
+			candidate.drop()
+			used -= candidate.size
+		}
+	}
+
+	s.usage.UsedBytes = used
+	s.usage.NearLimit = budget > 0 && float64(used) >= nearLimitRatio*float64(budget)
+	s.usage.EvictedEntries += int64(entries)
+	s.usage.EvictedFiles += int64(files)
+	return entries, files
+}
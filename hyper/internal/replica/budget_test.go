@@ -0,0 +1,99 @@
+package replica
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforceBudgetEvictsLeastRecentlyUsed(t *testing.T) {
+	embedder := newTestEmbedder()
+	store := NewStore(embedder, embedder)
+	now := time.Now()
+	text := strings.Repeat("x", 1000)
+	store.replaceCollection("adr", map[string]*cachedEntry{
+		"old":     {entry: &storage.KnowledgeEntry{ID: "old", Collection: "adr", Text: "mongo " + text, CreatedAt: now}, vector: []float32{1, 0, 0}},
+		"newer":   {entry: &storage.KnowledgeEntry{ID: "newer", Collection: "adr", Text: "qdrant " + text, CreatedAt: now.Add(time.Second)}, vector: []float32{0, 1, 0}},
+		"queried": {entry: &storage.KnowledgeEntry{ID: "queried", Collection: "adr", Text: "auth " + text, CreatedAt: now.Add(-time.Hour)}, vector: []float32{0, 0, 1}},
+	})
+	store.updateFiles(map[string]map[string][]*cachedChunk{
+		"/repo": {"f1": {{chunk: &storage.ExportedCodeChunk{FolderPath: "/repo", FileID: "f1", Content: "func mongo() " + text, FileUpdatedAt: now.Add(-time.Minute)}, vector: []float32{1, 0, 0}}}},
+	})
+
+	// Without a budget nothing is evicted, but usage is measured
+	entries, files := store.enforceBudget()
+	assert.Zero(t, entries+files)
+	usage := store.Usage()
+	assert.Greater(t, usage.UsedBytes, int64(4000))
+	assert.False(t, usage.NearLimit)
+
+	// The queried entry is the most recently used; of the rest, the oldest go first
+	_, err := store.Query("adr", "auth", 1)
+	require.NoError(t, err)
+	store.SetMemoryBudget(usage.UsedBytes - 1)
+	entries, files = store.enforceBudget()
+	assert.Equal(t, 0, entries)
+	assert.Equal(t, 1, files, "the file is older than every unqueried entry")
+
+	store.SetMemoryBudget(usage.UsedBytes / 3)
+	entries, files = store.enforceBudget()
+	assert.Equal(t, 2, entries)
+	assert.Zero(t, files)
+	assert.Equal(t, map[string]int{"adr": 1}, store.CollectionCounts())
+	results, err := store.Query("adr", "auth", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "queried", results[0].Entry.ID)
+
+	usage = store.Usage()
+	assert.LessOrEqual(t, usage.UsedBytes, usage.BudgetBytes)
+	assert.Equal(t, int64(2), usage.EvictedEntries)
+	assert.Equal(t, int64(1), usage.EvictedFiles)
+}
+
+func TestResyncKeepsLastUse(t *testing.T) {
+	embedder := newTestEmbedder()
+	store := NewStore(embedder, embedder)
+	entry := func() map[string]*cachedEntry {
+		return map[string]*cachedEntry{"1": {entry: &storage.KnowledgeEntry{ID: "1", Collection: "adr", Text: "Use MongoDB"}, vector: []float32{1, 0, 0}}}
+	}
+	store.replaceCollection("adr", entry())
+	_, err := store.Query("adr", "mongo", 1)
+	require.NoError(t, err)
+	used := store.knowledge["adr"]["1"].lastUsed.Load()
+	require.NotZero(t, used)
+
+	store.replaceCollection("adr", entry())
+	assert.Equal(t, used, store.knowledge["adr"]["1"].lastUsed.Load())
+}
+
+func TestMeasureDisk(t *testing.T) {
+	embedder := newTestEmbedder()
+	store := NewStore(embedder, embedder)
+	assert.Zero(t, store.measureDisk().UsedBytes, "no directory, nothing measured")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "adr.jsonl"), make([]byte, 600), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "folders"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "folders", "repo.jsonl"), make([]byte, 400), 0o644))
+
+	store.SetDiskBudget(dir, 2000)
+	disk := store.measureDisk()
+	assert.Equal(t, int64(1000), disk.UsedBytes, "folder snapshots count too")
+	assert.False(t, disk.NearLimit)
+
+	store.SetDiskBudget(dir, 1050)
+	disk = store.measureDisk()
+	assert.True(t, disk.NearLimit)
+	assert.Equal(t, disk, store.DiskUsage())
+
+	store.SetDiskBudget(filepath.Join(dir, "missing"), 1050)
+	assert.Zero(t, store.measureDisk().UsedBytes)
+}
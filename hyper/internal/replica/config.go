@@ -0,0 +1,82 @@
+// Package replica keeps a read-only, in-memory copy of selected knowledge
+// collections and code indexes of a primary coordinator, so a lightweight
+// cache instance (hyper --mode cache) can answer queries close to the agents
+// that send them.
+package replica
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AllFolders in CACHE_CODE_FOLDERS syncs every indexed folder of the primary
+const AllFolders = "*"
+
+// Config configures a cache instance
+type Config struct {
+	PrimaryURL       string        // CACHE_PRIMARY_URL: base URL of the primary's HTTP server
+	PrimaryToken     string        // CACHE_PRIMARY_TOKEN: Bearer token for the primary's API (optional)
+	Collections      []string      // CACHE_COLLECTIONS: knowledge collections to sync
+	CodeFolders      []string      // CACHE_CODE_FOLDERS: indexed folder paths to sync, or * for all
+	SyncInterval     time.Duration // CACHE_SYNC_INTERVAL: incremental sync (default 1m)
+	FullSyncInterval time.Duration // CACHE_FULL_SYNC_INTERVAL: full resync that also drops deleted entries (default 1h)
+	APIToken         string        // CACHE_API_TOKEN: Bearer token clients of the cache must send (optional)
+	MemoryBudget     int64         // CACHE_MEMORY_BUDGET_BYTES: cap on the cached copy, evicting least recently used content (0 = unlimited)
+}
+
+// LoadConfig reads the CACHE_* environment variables
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		PrimaryURL:       strings.TrimSuffix(strings.TrimSpace(os.Getenv("CACHE_PRIMARY_URL")), "/"),
+		PrimaryToken:     os.Getenv("CACHE_PRIMARY_TOKEN"),
+		Collections:      splitList(os.Getenv("CACHE_COLLECTIONS")),
+		CodeFolders:      splitList(os.Getenv("CACHE_CODE_FOLDERS")),
+		SyncInterval:     time.Minute,
+		FullSyncInterval: time.Hour,
+		APIToken:         os.Getenv("CACHE_API_TOKEN"),
+	}
+
+	parsed, err := url.Parse(cfg.PrimaryURL)
+	if cfg.PrimaryURL == "" || err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return cfg, fmt.Errorf("CACHE_PRIMARY_URL must be the http(s) URL of the primary coordinator, got %q", cfg.PrimaryURL)
+	}
+	if len(cfg.Collections) == 0 && len(cfg.CodeFolders) == 0 {
+		return cfg, fmt.Errorf("nothing to cache: set CACHE_COLLECTIONS and/or CACHE_CODE_FOLDERS")
+	}
+
+	for name, interval := range map[string]*time.Duration{
+		"CACHE_SYNC_INTERVAL":      &cfg.SyncInterval,
+		"CACHE_FULL_SYNC_INTERVAL": &cfg.FullSyncInterval,
+	} {
+		if raw := os.Getenv(name); raw != "" {
+			parsed, err := time.ParseDuration(raw)
+			if err != nil || parsed <= 0 {
+				return cfg, fmt.Errorf("invalid %s %q: must be a positive duration such as 5m", name, raw)
+			}
+			*interval = parsed
+		}
+	}
+	if raw := os.Getenv("CACHE_MEMORY_BUDGET_BYTES"); raw != "" {
+		budget, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil || budget < 0 {
+			return cfg, fmt.Errorf("invalid CACHE_MEMORY_BUDGET_BYTES %q: must be a number of bytes, 0 for unlimited", raw)
+		}
+		cfg.MemoryBudget = budget
+	}
+	return cfg, nil
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
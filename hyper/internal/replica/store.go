@@ -0,0 +1,342 @@
+package replica
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+)
+
+// ErrReadOnly is returned for writes: a cache only serves what it synced
+var ErrReadOnly = errors.New("knowledge cache is read-only: write to the primary coordinator")
+
+// cachedEntry is a knowledge entry with its embedding
+type cachedEntry struct {
+	entry    *storage.KnowledgeEntry
+	vector   []float32
+	lastUsed atomic.Int64 // Unix nanoseconds of the last query returning it
+}
+
+// cachedChunk is a code chunk with its embedding
+type cachedChunk struct {
+	chunk    *storage.ExportedCodeChunk
+	vector   []float32
+	lastUsed atomic.Int64 // Unix nanoseconds of the last search returning it
+}
+
+// Store is an in-memory, read-only knowledge and code index. It implements
+// storage.KnowledgeStorage, ranking entries by cosine similarity as Qdrant
+// does on the primary.
+type Store struct {
+	knowledgeEmbedder embeddings.EmbeddingClient
+	codeEmbedder      embeddings.EmbeddingClient
+
+	mu        sync.RWMutex
+	knowledge map[string]map[string]*cachedEntry   // collection -> entry ID
+	code      map[string]map[string][]*cachedChunk // folder path -> file ID -> chunks
+	usage     Usage
+	disk      DiskUsage
+}
+
+// NewStore creates an empty store. Queries are embedded with the same models
+// the primary uses: knowledgeEmbedder for knowledge, codeEmbedder for code.
+func NewStore(knowledgeEmbedder, codeEmbedder embeddings.EmbeddingClient) *Store {
+	return &Store{
+		knowledgeEmbedder: knowledgeEmbedder,
+		codeEmbedder:      codeEmbedder,
+		knowledge:         map[string]map[string]*cachedEntry{},
+		code:              map[string]map[string][]*cachedChunk{},
+	}
+}
+
+// replaceCollection swaps in a complete copy of a collection
+func (s *Store) replaceCollection(collection string, entries map[string]*cachedEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keepEntryUse(s.knowledge[collection], entries)
+	s.knowledge[collection] = entries
+}
+
+// addEntries adds or replaces entries of a collection
+func (s *Store) addEntries(collection string, entries map[string]*cachedEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing := s.knowledge[collection]
+	if existing == nil {
+		existing = map[string]*cachedEntry{}
+		s.knowledge[collection] = existing
+	}
+	keepEntryUse(existing, entries)
+	for id, entry := range entries {
+		existing[id] = entry
+	}
+}
+
+// replaceFolders swaps in a complete copy of the given folders' code. With
+// all, folders missing from files are dropped as well.
+func (s *Store) replaceFolders(files map[string]map[string][]*cachedChunk, folders []string, all bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for folder, folderFiles := range files {
+		keepFileUse(s.code[folder], folderFiles)
+	}
+	if all {
+		s.code = files
+		return
+	}
+	for _, folder := range folders {
+		if folderFiles, ok := files[folder]; ok {
+			s.code[folder] = folderFiles
+		} else {
+			delete(s.code, folder)
+		}
+	}
+}
+
+// updateFiles replaces the chunks of the given files
+func (s *Store) updateFiles(files map[string]map[string][]*cachedChunk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for folder, folderFiles := range files {
+		existing := s.code[folder]
+		if existing == nil {
+			existing = map[string][]*cachedChunk{}
+			s.code[folder] = existing
+		}
+		keepFileUse(existing, folderFiles)
+		for fileID, chunks := range folderFiles {
+			existing[fileID] = chunks
+		}
+	}
+}
+
+// keepEntryUse carries the last use of entries over to their resynced copies,
+// so a full sync does not reset the eviction order
+func keepEntryUse(previous, entries map[string]*cachedEntry) {
+	for id, cached := range entries {
+		if old, ok := previous[id]; ok && old != cached {
+			cached.lastUsed.Store(old.lastUsed.Load())
+		}
+	}
+}
+
+// keepFileUse carries the last use of files over to their re-indexed chunks
+func keepFileUse(previous, files map[string][]*cachedChunk) {
+	for fileID, chunks := range files {
+		var lastUsed int64
+		for _, old := range previous[fileID] {
+			lastUsed = max(lastUsed, old.lastUsed.Load())
+		}
+		if lastUsed == 0 {
+			continue
+		}
+		for _, cached := range chunks {
+			cached.lastUsed.Store(lastUsed)
+		}
+	}
+}
+
+// Upsert is not supported: a cache only serves what it synced
+func (s *Store) Upsert(collection, text string, metadata map[string]interface{}) (*storage.KnowledgeEntry, error) {
+	return nil, ErrReadOnly
+}
+
+// Query returns the entries of a collection most similar to query
+func (s *Store) Query(collection, query string, limit int) ([]*storage.QueryResult, error) {
+	vector, err := s.knowledgeEmbedder.CreateEmbedding(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	s.mu.RLock()
+	results := make([]*storage.QueryResult, 0, len(s.knowledge[collection]))
+	used := make(map[*storage.KnowledgeEntry]*cachedEntry, len(s.knowledge[collection]))
+	for _, cached := range s.knowledge[collection] {
+		results = append(results, &storage.QueryResult{Entry: cached.entry, Score: cosine(vector, cached.vector)})
+		used[cached.entry] = cached
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Entry.ID < results[j].Entry.ID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	now := time.Now().UnixNano()
+	for _, result := range results {
+		used[result.Entry].lastUsed.Store(now)
+	}
+	return results, nil
+}
+
+// ListCollections returns the cached collections
+func (s *Store) ListCollections() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	collections := make([]string, 0, len(s.knowledge))
+	for collection := range s.knowledge {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	return collections
+}
+
+// GetPopularCollections returns the cached collections by entry count
+func (s *Store) GetPopularCollections(limit int) ([]*storage.CollectionStats, error) {
+	s.mu.RLock()
+	stats := make([]*storage.CollectionStats, 0, len(s.knowledge))
+	for collection, entries := range s.knowledge {
+		stats = append(stats, &storage.CollectionStats{Collection: collection, Count: len(entries)})
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Collection < stats[j].Collection
+	})
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+// GetCollectionStatsWithMetadata returns the cached collections with their categories
+func (s *Store) GetCollectionStatsWithMetadata() ([]*storage.CollectionWithMetadata, error) {
+	stats, err := s.GetPopularCollections(0)
+	if err != nil {
+		return nil, err
+	}
+	return storage.CollectionsWithCategories(stats), nil
+}
+
+// ListKnowledge returns the entries of a collection, newest first
+func (s *Store) ListKnowledge(collection string, limit int) ([]*storage.KnowledgeEntry, error) {
+	s.mu.RLock()
+	entries := make([]*storage.KnowledgeEntry, 0, len(s.knowledge[collection]))
+	for _, cached := range s.knowledge[collection] {
+		entries = append(entries, cached.entry)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// SearchCode returns the cached code chunks most similar to query, from one
+// folder when folderPath is set
+func (s *Store) SearchCode(query, folderPath string, limit int) ([]*storage.SearchResult, error) {
+	vector, err := s.codeEmbedder.CreateEmbedding(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	var results []*storage.SearchResult
+	used := map[*storage.SearchResult]*cachedChunk{}
+	s.mu.RLock()
+	for folder, files := range s.code {
+		if folderPath != "" && folder != folderPath {
+			continue
+		}
+		for _, chunks := range files {
+			for _, cached := range chunks {
+				chunk := cached.chunk
+				result := &storage.SearchResult{
+					FileID:       chunk.FileID,
+					FilePath:     chunk.FilePath,
+					RelativePath: chunk.RelativePath,
+					Language:     chunk.Language,
+					ChunkNum:     chunk.ChunkNum,
+					StartLine:    chunk.StartLine,
+					EndLine:      chunk.EndLine,
+					Content:      chunk.Content,
+					Summary:      chunk.Summary,
+					Score:        float32(cosine(vector, cached.vector)),
+					FolderPath:   chunk.FolderPath,
+				}
+				results = append(results, result)
+				used[result] = cached
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].FilePath != results[j].FilePath {
+			return results[i].FilePath < results[j].FilePath
+		}
+		return results[i].ChunkNum < results[j].ChunkNum
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	now := time.Now().UnixNano()
+	for _, result := range results {
+		used[result].lastUsed.Store(now)
+	}
+	return results, nil
+}
+
+// CollectionCounts returns the number of cached entries per collection
+func (s *Store) CollectionCounts() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	counts := make(map[string]int, len(s.knowledge))
+	for collection, entries := range s.knowledge {
+		counts[collection] = len(entries)
+	}
+	return counts
+}
+
+// FolderCounts returns the number of cached chunks per code folder
+func (s *Store) FolderCounts() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	counts := make(map[string]int, len(s.code))
+	for folder, files := range s.code {
+		for _, chunks := range files {
+			counts[folder] += len(chunks)
+		}
+	}
+	return counts
+}
+
+// cosine returns the cosine similarity of two vectors, 0 when their
+// dimensions differ or either is zero
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
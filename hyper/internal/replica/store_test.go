@@ -0,0 +1,86 @@
+package replica
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keywordEmbedder embeds texts as keyword counts, so similarity follows
+// shared keywords
+type keywordEmbedder struct {
+	keywords []string
+	calls    int
+}
+
+func (e *keywordEmbedder) CreateEmbedding(text string) ([]float32, error) {
+	e.calls++
+	vector := make([]float32, len(e.keywords))
+	for i, keyword := range e.keywords {
+		vector[i] = float32(strings.Count(strings.ToLower(text), keyword))
+	}
+	return vector, nil
+}
+
+func (e *keywordEmbedder) CreateEmbeddings(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = e.CreateEmbedding(text)
+	}
+	return vectors, nil
+}
+
+func (e *keywordEmbedder) GetDimensions() int {
+	return len(e.keywords)
+}
+
+func newTestEmbedder() *keywordEmbedder {
+	return &keywordEmbedder{keywords: []string{"mongo", "qdrant", "auth"}}
+}
+
+func TestStoreQueryRanksBySimilarity(t *testing.T) {
+	embedder := newTestEmbedder()
+	store := NewStore(embedder, embedder)
+	now := time.Now()
+	store.replaceCollection("adr", map[string]*cachedEntry{
+		"1": {entry: &storage.KnowledgeEntry{ID: "1", Collection: "adr", Text: "Use MongoDB", CreatedAt: now}, vector: []float32{1, 0, 0}},
+		"2": {entry: &storage.KnowledgeEntry{ID: "2", Collection: "adr", Text: "Use Qdrant", CreatedAt: now.Add(time.Second)}, vector: []float32{0, 1, 0}},
+	})
+
+	results, err := store.Query("adr", "why qdrant?", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "2", results[0].Entry.ID)
+	assert.InDelta(t, 1.0, results[0].Score, 1e-9)
+
+	entries, err := store.ListKnowledge("adr", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "2", entries[0].ID)
+
+	_, err = store.Upsert("adr", "Use Postgres", nil)
+	assert.ErrorIs(t, err, ErrReadOnly)
+}
+
+func TestStoreSearchCodeFiltersFolder(t *testing.T) {
+	embedder := newTestEmbedder()
+	store := NewStore(embedder, embedder)
+	store.updateFiles(map[string]map[string][]*cachedChunk{
+		"/repo/app": {"f1": {{chunk: &storage.ExportedCodeChunk{FolderPath: "/repo/app", FileID: "f1", FilePath: "/repo/app/auth.go", Content: "func auth()"}, vector: []float32{0, 0, 1}}}},
+		"/repo/lib": {"f2": {{chunk: &storage.ExportedCodeChunk{FolderPath: "/repo/lib", FileID: "f2", FilePath: "/repo/lib/auth.go", Content: "func auth()"}, vector: []float32{0, 0, 1}}}},
+	})
+
+	results, err := store.SearchCode("auth middleware", "", 10)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	results, err = store.SearchCode("auth middleware", "/repo/lib", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "/repo/lib/auth.go", results[0].FilePath)
+	assert.Equal(t, map[string]int{"/repo/app": 1, "/repo/lib": 1}, store.FolderCounts())
+}
@@ -0,0 +1,452 @@
+package replica
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// maxLineBytes bounds one NDJSON line of an export: an entry or chunk with its vector
+const maxLineBytes = 16 << 20
+
+// Status reports what a cache holds and how its last sync went
+type Status struct {
+	Primary        string         `json:"primary"`
+	LastSyncAt     *time.Time     `json:"lastSyncAt,omitempty"`
+	LastFullSyncAt *time.Time     `json:"lastFullSyncAt,omitempty"`
+	LastError      string         `json:"lastError,omitempty"`
+	Collections    map[string]int `json:"collections"` // Cached entries per collection
+	CodeFolders    map[string]int `json:"codeFolders"` // Cached chunks per folder
+	Memory         Usage          `json:"memory"`
+	Disk           DiskUsage      `json:"disk"`
+}
+
+// Syncer copies the configured knowledge collections and code folders from
+// the primary into a Store: fully on the first sync and every
+// FullSyncInterval, otherwise only what was created or re-indexed since the
+// previous sync
+type Syncer struct {
+	cfg    Config
+	store  *Store
+	client *http.Client
+	logger *zap.Logger
+
+	mu             sync.Mutex           // Serializes syncs
+	knowledgeMarks map[string]time.Time // Newest entry synced per collection
+	codeMarks      map[string]time.Time // Newest file update synced per folder (or AllFolders)
+
+	statusMu       sync.RWMutex
+	lastSyncAt     time.Time
+	lastFullSyncAt time.Time
+	lastError      string
+
+	nearLimitLogged     bool // Whether the current near-limit episode was logged
+	diskNearLimitLogged bool // Likewise for the disk budget
+}
+
+// NewSyncer creates a syncer filling store from the primary
+func NewSyncer(cfg Config, store *Store, logger *zap.Logger) *Syncer {
+	return &Syncer{
+		cfg:            cfg,
+		store:          store,
+		client:         &http.Client{Timeout: 10 * time.Minute},
+		logger:         logger,
+		knowledgeMarks: map[string]time.Time{},
+		codeMarks:      map[string]time.Time{},
+	}
+}
+
+// Sync pulls changes from the primary; full re-copies everything, dropping
+// entries and files deleted on the primary. A failed collection or folder is
+// retried from the same point on the next sync.
+func (s *Syncer) Sync(ctx context.Context, full bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	record := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, collection := range s.cfg.Collections {
+		record(s.syncCollection(ctx, collection, full))
+	}
+	for _, folder := range s.cfg.CodeFolders {
+		record(s.syncFolder(ctx, folder, full))
+	}
+	s.enforceBudget()
+
+	now := time.Now().UTC()
+	s.statusMu.Lock()
+	s.lastError = ""
+	if firstErr != nil {
+		s.lastError = firstErr.Error()
+	} else {
+		s.lastSyncAt = now
+		if full {
+			s.lastFullSyncAt = now
+		}
+	}
+	s.statusMu.Unlock()
+	return firstErr
+}
+
+// syncCollection copies the entries of a collection created since its mark
+func (s *Syncer) syncCollection(ctx context.Context, collection string, full bool) error {
+	since := s.knowledgeMarks[collection]
+	if full {
+		since = time.Time{}
+	}
+
+	query := url.Values{"collection": {collection}, "vectors": {"true"}}
+	if !since.IsZero() {
+		query.Set("since", since.Format(time.RFC3339Nano))
+	}
+	entries := map[string]*cachedEntry{}
+	var missing []*cachedEntry
+	err := s.fetch(ctx, "/api/v1/knowledge/export", query, func(line []byte) error {
+		var exported storage.ExportedKnowledge
+		if err := json.Unmarshal(line, &exported); err != nil || exported.KnowledgeEntry == nil {
+			return fmt.Errorf("invalid export line: %s", truncate(line))
+		}
+		cached := &cachedEntry{entry: exported.KnowledgeEntry, vector: toFloat32(exported.Vector)}
+		if cached.vector == nil {
+			missing = append(missing, cached)
+		}
+		entries[cached.entry.ID] = cached
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sync collection %s: %w", collection, err)
+	}
+
+	texts := make([]string, len(missing))
+	for i, cached := range missing {
+		texts[i] = cached.entry.Text
+	}
+	vectors, err := embedMissing(s.store.knowledgeEmbedder, texts)
+	if err != nil {
+		return fmt.Errorf("failed to sync collection %s: %w", collection, err)
+	}
+	for i, cached := range missing {
+		cached.vector = vectors[i]
+	}
+	if err := checkDimensions(s.store.knowledgeEmbedder, entryVectors(entries)); err != nil {
+		return fmt.Errorf("failed to sync collection %s: %w", collection, err)
+	}
+
+	if full {
+		s.store.replaceCollection(collection, entries)
+	} else {
+		s.store.addEntries(collection, entries)
+	}
+	for _, cached := range entries {
+		if cached.entry.CreatedAt.After(since) {
+			since = cached.entry.CreatedAt
+		}
+	}
+	s.knowledgeMarks[collection] = since
+
+	if len(entries) > 0 {
+		s.logger.Info("Synced knowledge collection",
+			zap.String("collection", collection),
+			zap.Int("entries", len(entries)),
+			zap.Bool("full", full),
+			zap.Int("embeddedLocally", len(missing)))
+	}
+	return nil
+}
+
+// syncFolder copies the chunks of a folder's files re-indexed since its mark
+func (s *Syncer) syncFolder(ctx context.Context, folder string, full bool) error {
+	since := s.codeMarks[folder]
+	if full {
+		since = time.Time{}
+	}
+
+	query := url.Values{}
+	if folder != AllFolders {
+		query.Set("folder", folder)
+	}
+	if !since.IsZero() {
+		query.Set("since", since.Format(time.RFC3339Nano))
+	}
+	files := map[string]map[string][]*cachedChunk{}
+	var all, missing []*cachedChunk
+	err := s.fetch(ctx, "/api/v1/code-index/export", query, func(line []byte) error {
+		var chunk storage.ExportedCodeChunk
+		if err := json.Unmarshal(line, &chunk); err != nil || chunk.FileID == "" {
+			return fmt.Errorf("invalid export line: %s", truncate(line))
+		}
+		cached := &cachedChunk{chunk: &chunk, vector: toFloat32(chunk.Vector)}
+		chunk.Vector = nil
+		if cached.vector == nil {
+			missing = append(missing, cached)
+		}
+		if files[chunk.FolderPath] == nil {
+			files[chunk.FolderPath] = map[string][]*cachedChunk{}
+		}
+		files[chunk.FolderPath][chunk.FileID] = append(files[chunk.FolderPath][chunk.FileID], cached)
+		all = append(all, cached)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sync code folder %s: %w", folder, err)
+	}
+
+	texts := make([]string, len(missing))
+	for i, cached := range missing {
+		texts[i] = cached.chunk.Content
+	}
+	vectors, err := embedMissing(s.store.codeEmbedder, texts)
+	if err != nil {
+		return fmt.Errorf("failed to sync code folder %s: %w", folder, err)
+	}
+	for i, cached := range missing {
+		cached.vector = vectors[i]
+	}
+	chunkVectors := make([][]float32, len(all))
+	for i, cached := range all {
+		chunkVectors[i] = cached.vector
+	}
+	if err := checkDimensions(s.store.codeEmbedder, chunkVectors); err != nil {
+		return fmt.Errorf("failed to sync code folder %s: %w", folder, err)
+	}
+
+	if full {
+		s.store.replaceFolders(files, []string{folder}, folder == AllFolders)
+	} else {
+		s.store.updateFiles(files)
+	}
+	for _, cached := range all {
+		if cached.chunk.FileUpdatedAt.After(since) {
+			since = cached.chunk.FileUpdatedAt
+		}
+	}
+	s.codeMarks[folder] = since
+
+	if len(all) > 0 {
+		s.logger.Info("Synced code folder",
+			zap.String("folder", folder),
+			zap.Int("chunks", len(all)),
+			zap.Bool("full", full),
+			zap.Int("embeddedLocally", len(missing)))
+	}
+	return nil
+}
+
+// enforceBudget evicts content over the memory budget and warns when the
+// cache nears or exceeds it, or the vector snapshots near their disk budget
+func (s *Syncer) enforceBudget() {
+	entries, files := s.store.enforceBudget()
+	usage := s.store.Usage()
+	if entries > 0 || files > 0 {
+		s.logger.Warn("Cache exceeded its memory budget; evicted least recently used content",
+			zap.Int("entries", entries),
+			zap.Int("files", files),
+			zap.Int64("usedBytes", usage.UsedBytes),
+			zap.Int64("budgetBytes", usage.BudgetBytes))
+	}
+	if usage.NearLimit && !s.nearLimitLogged {
+		s.logger.Warn("Cache memory use is near its budget",
+			zap.Int64("usedBytes", usage.UsedBytes),
+			zap.Int64("budgetBytes", usage.BudgetBytes))
+	}
+	s.nearLimitLogged = usage.NearLimit
+
+	disk := s.store.measureDisk()
+	if disk.Error != "" {
+		s.logger.Warn("Failed to measure vector snapshot disk use", zap.String("dir", disk.Dir), zap.String("error", disk.Error))
+	}
+	if disk.NearLimit && !s.diskNearLimitLogged {
+		s.logger.Warn("Vector snapshot disk use is near its budget; archiving stops at the budget",
+			zap.String("dir", disk.Dir),
+			zap.Int64("usedBytes", disk.UsedBytes),
+			zap.Int64("budgetBytes", disk.BudgetBytes))
+	}
+	s.diskNearLimitLogged = disk.NearLimit
+}
+
+// fetch streams an NDJSON export of the primary, calling visit per line. An
+// error envelope, as the primary ends a failed stream with, fails the fetch.
+func (s *Syncer) fetch(ctx context.Context, path string, query url.Values, visit func(line []byte) error) error {
+	target := s.cfg.PrimaryURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	if s.cfg.PrimaryToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.PrimaryToken)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if err := envelopeError(body); err != nil {
+			return err
+		}
+		return fmt.Errorf("primary returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := envelopeError(line); err != nil {
+			return err
+		}
+		if err := visit(line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read export: %w", err)
+	}
+	return nil
+}
+
+// envelopeError returns the error of a JSON error envelope, or nil
+func envelopeError(body []byte) error {
+	var envelope struct {
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) != nil || envelope.Error == nil {
+		return nil
+	}
+	return fmt.Errorf("primary returned %s: %s", envelope.Error.Code, envelope.Error.Message)
+}
+
+// Status reports the cached content and the outcome of the last sync
+func (s *Syncer) Status() Status {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+
+	status := Status{
+		Primary:     s.cfg.PrimaryURL,
+		LastError:   s.lastError,
+		Collections: s.store.CollectionCounts(),
+		CodeFolders: s.store.FolderCounts(),
+		Memory:      s.store.Usage(),
+		Disk:        s.store.DiskUsage(),
+	}
+	if !s.lastSyncAt.IsZero() {
+		lastSyncAt := s.lastSyncAt
+		status.LastSyncAt = &lastSyncAt
+	}
+	if !s.lastFullSyncAt.IsZero() {
+		lastFullSyncAt := s.lastFullSyncAt
+		status.LastFullSyncAt = &lastFullSyncAt
+	}
+	return status
+}
+
+// Start syncs fully right away, then incrementally every SyncInterval and
+// fully every FullSyncInterval, until ctx is cancelled
+func (s *Syncer) Start(ctx context.Context) {
+	go func() {
+		lastFull := time.Now()
+		if err := s.Sync(ctx, true); err != nil {
+			s.logger.Warn("Initial cache sync failed; retrying on the next sync", zap.Error(err))
+			lastFull = time.Time{}
+		}
+
+		ticker := time.NewTicker(s.cfg.SyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				full := time.Since(lastFull) >= s.cfg.FullSyncInterval
+				if err := s.Sync(ctx, full); err != nil {
+					s.logger.Warn("Cache sync failed", zap.Bool("full", full), zap.Error(err))
+					continue
+				}
+				if full {
+					lastFull = time.Now()
+				}
+			}
+		}
+	}()
+}
+
+// embedMissing embeds texts whose vectors the primary did not export
+func embedMissing(embedder embeddings.EmbeddingClient, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	vectors, err := embedder.CreateEmbeddings(texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed %d entries without vectors: %w", len(texts), err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedding model returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+// checkDimensions rejects vectors that queries embedded locally cannot be
+// compared with
+func checkDimensions(embedder embeddings.EmbeddingClient, vectors [][]float32) error {
+	want := embedder.GetDimensions()
+	for _, vector := range vectors {
+		if len(vector) != want {
+			return fmt.Errorf("primary embeddings have %d dimensions but the local model has %d: configure the cache with the primary's EMBEDDING settings", len(vector), want)
+		}
+	}
+	return nil
+}
+
+// entryVectors returns the vectors of entries
+func entryVectors(entries map[string]*cachedEntry) [][]float32 {
+	vectors := make([][]float32, 0, len(entries))
+	for _, cached := range entries {
+		vectors = append(vectors, cached.vector)
+	}
+	return vectors
+}
+
+// toFloat32 converts an exported vector, keeping nil for missing ones
+func toFloat32(vector []float64) []float32 {
+	if len(vector) == 0 {
+		return nil
+	}
+	converted := make([]float32, len(vector))
+	for i, value := range vector {
+		converted[i] = float32(value)
+	}
+	return converted
+}
+
+// truncate shortens a line for error messages
+func truncate(line []byte) string {
+	if len(line) > 200 {
+		return string(line[:200]) + "..."
+	}
+	return string(line)
+}
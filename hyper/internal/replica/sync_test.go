@@ -0,0 +1,182 @@
+package replica
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakePrimary serves knowledge and code index exports from memory
+type fakePrimary struct {
+	entries []*storage.ExportedKnowledge
+	chunks  []*storage.ExportedCodeChunk
+	since   []string
+	fail    bool
+}
+
+func (p *fakePrimary) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer primary-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"data":null,"error":{"code":"UNAUTHENTICATED","message":"missing token"}}`))
+		return
+	}
+	p.since = append(p.since, r.URL.Query().Get("since"))
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		since, _ = time.Parse(time.RFC3339Nano, raw)
+	}
+
+	encoder := json.NewEncoder(w)
+	switch r.URL.Path {
+	case "/api/v1/knowledge/export":
+		for _, entry := range p.entries {
+			if entry.Collection == r.URL.Query().Get("collection") && !entry.CreatedAt.Before(since) {
+				encoder.Encode(entry)
+			}
+		}
+	case "/api/v1/code-index/export":
+		for _, chunk := range p.chunks {
+			if !chunk.FileUpdatedAt.Before(since) {
+				encoder.Encode(chunk)
+			}
+		}
+	}
+	if p.fail {
+		w.Write([]byte(`{"data":null,"error":{"code":"DEPENDENCY_UNAVAILABLE","message":"qdrant unavailable"}}` + "\n"))
+	}
+}
+
+func newTestSyncer(t *testing.T, primary *fakePrimary) (*Syncer, *Store) {
+	t.Helper()
+	server := httptest.NewServer(primary)
+	t.Cleanup(server.Close)
+
+	embedder := newTestEmbedder()
+	store := NewStore(embedder, embedder)
+	cfg := Config{
+		PrimaryURL:   server.URL,
+		PrimaryToken: "primary-token",
+		Collections:  []string{"adr"},
+		CodeFolders:  []string{AllFolders},
+	}
+	return NewSyncer(cfg, store, zap.NewNop()), store
+}
+
+func TestSyncCopiesAndUpdatesIncrementally(t *testing.T) {
+	start := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	primary := &fakePrimary{
+		entries: []*storage.ExportedKnowledge{
+			{KnowledgeEntry: &storage.KnowledgeEntry{ID: "1", Collection: "adr", Text: "Use MongoDB", CreatedAt: start}, Vector: []float64{1, 0, 0}},
+			{KnowledgeEntry: &storage.KnowledgeEntry{ID: "x", Collection: "other", Text: "Not cached", CreatedAt: start}},
+		},
+		chunks: []*storage.ExportedCodeChunk{
+			{FolderPath: "/repo", FileID: "f1", FilePath: "/repo/auth.go", Content: "func auth()", FileUpdatedAt: start},
+		},
+	}
+	syncer, store := newTestSyncer(t, primary)
+	ctx := context.Background()
+
+	require.NoError(t, syncer.Sync(ctx, true))
+	assert.Equal(t, map[string]int{"adr": 1}, store.CollectionCounts())
+	assert.Equal(t, map[string]int{"/repo": 1}, store.FolderCounts())
+
+	// The chunk had no vector on the primary and was embedded locally
+	results, err := store.SearchCode("auth", "", 1)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, results[0].Score, 1e-6)
+
+	// Later entries and re-indexed files arrive with the next incremental sync
+	primary.entries = append(primary.entries, &storage.ExportedKnowledge{
+		KnowledgeEntry: &storage.KnowledgeEntry{ID: "2", Collection: "adr", Text: "Use Qdrant", CreatedAt: start.Add(time.Minute)},
+		Vector:         []float64{0, 1, 0},
+	})
+	primary.chunks = []*storage.ExportedCodeChunk{
+		{FolderPath: "/repo", FileID: "f1", FilePath: "/repo/auth.go", ChunkNum: 0, Content: "func auth() // mongo", FileUpdatedAt: start.Add(time.Minute)},
+		{FolderPath: "/repo", FileID: "f1", FilePath: "/repo/auth.go", ChunkNum: 1, Content: "func qdrant()", FileUpdatedAt: start.Add(time.Minute)},
+	}
+	primary.since = nil
+	require.NoError(t, syncer.Sync(ctx, false))
+	assert.Equal(t, []string{start.Format(time.RFC3339Nano), start.Format(time.RFC3339Nano)}, primary.since)
+	assert.Equal(t, map[string]int{"adr": 2}, store.CollectionCounts())
+	assert.Equal(t, map[string]int{"/repo": 2}, store.FolderCounts())
+
+	results2, err := store.Query("adr", "qdrant", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "2", results2[0].Entry.ID)
+
+	// A full sync drops what was deleted on the primary
+	primary.entries = primary.entries[1:]
+	require.NoError(t, syncer.Sync(ctx, true))
+	assert.Equal(t, map[string]int{"adr": 1}, store.CollectionCounts())
+
+	status := syncer.Status()
+	assert.NotNil(t, status.LastFullSyncAt)
+	assert.Empty(t, status.LastError)
+}
+
+func TestSyncReportsErrors(t *testing.T) {
+	primary := &fakePrimary{
+		entries: []*storage.ExportedKnowledge{
+			{KnowledgeEntry: &storage.KnowledgeEntry{ID: "1", Collection: "adr", Text: "Use MongoDB", CreatedAt: time.Now()}, Vector: []float64{1, 0, 0}},
+		},
+		fail: true,
+	}
+	syncer, store := newTestSyncer(t, primary)
+
+	err := syncer.Sync(context.Background(), true)
+	assert.ErrorContains(t, err, "qdrant unavailable")
+	assert.Empty(t, store.CollectionCounts(), "a truncated export must not be applied")
+	assert.Contains(t, syncer.Status().LastError, "DEPENDENCY_UNAVAILABLE")
+
+	// Vectors from a different embedding model are rejected
+	primary.fail = false
+	primary.entries[0].Vector = []float64{1, 0}
+	assert.ErrorContains(t, syncer.Sync(context.Background(), true), "2 dimensions")
+
+	syncer.cfg.PrimaryToken = ""
+	assert.ErrorContains(t, syncer.Sync(context.Background(), true), "UNAUTHENTICATED")
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("CACHE_PRIMARY_URL", "https://coordinator.example.com/")
+	t.Setenv("CACHE_COLLECTIONS", "adr, code-patterns,")
+	t.Setenv("CACHE_CODE_FOLDERS", "")
+	t.Setenv("CACHE_SYNC_INTERVAL", "30s")
+	t.Setenv("CACHE_FULL_SYNC_INTERVAL", "")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://coordinator.example.com", cfg.PrimaryURL)
+	assert.Equal(t, []string{"adr", "code-patterns"}, cfg.Collections)
+	assert.Equal(t, 30*time.Second, cfg.SyncInterval)
+	assert.Equal(t, time.Hour, cfg.FullSyncInterval)
+	assert.Zero(t, cfg.MemoryBudget)
+
+	t.Setenv("CACHE_MEMORY_BUDGET_BYTES", "1048576")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<20), cfg.MemoryBudget)
+
+	t.Setenv("CACHE_MEMORY_BUDGET_BYTES", "1GB")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "CACHE_MEMORY_BUDGET_BYTES")
+	t.Setenv("CACHE_MEMORY_BUDGET_BYTES", "")
+
+	t.Setenv("CACHE_COLLECTIONS", "")
+	_, err = LoadConfig()
+	assert.ErrorContains(t, err, "nothing to cache")
+
+	t.Setenv("CACHE_COLLECTIONS", "adr")
+	t.Setenv("CACHE_PRIMARY_URL", "")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		require.NoError(t, err)
+		return v
+	}
+
+	tests := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"0 2 * * *", "2026-10-16 01:30", "2026-10-16 02:00"},
+		{"0 2 * * *", "2026-10-16 02:00", "2026-10-17 02:00"},
+		{"*/15 * * * *", "2026-10-16 10:07", "2026-10-16 10:15"},
+		{"30 9 * * mon-fri", "2026-10-16 10:00", "2026-10-19 09:30"}, // Friday to Monday
+		{"0 0 1 jan *", "2026-10-16 00:00", "2027-01-01 00:00"},
+		{"0 12 13 * 5", "2026-10-16 13:00", "2026-10-23 12:00"}, // 13th or a Friday
+		{"0 0 * * 7", "2026-10-16 00:00", "2026-10-18 00:00"},   // 7 is Sunday
+		{"@hourly", "2026-10-16 10:59", "2026-10-16 11:00"},
+		{"0 0 29 2 *", "2026-10-16 00:00", "2028-02-29 00:00"},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, at(tt.want), cron.Next(at(tt.after)), tt.expr)
+	}
+
+	never, err := ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(at("2026-10-16 00:00")).IsZero())
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "0 0 * * funday"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
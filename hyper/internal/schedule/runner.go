@@ -0,0 +1,156 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+	_ "time/tzdata" // Timezones resolve in containers without zoneinfo
+
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// checkInterval is how often schedules are checked for due runs
+const checkInterval = 30 * time.Second
+
+// Store is the schedule storage the runner needs (implemented by
+// storage.ScheduledTaskStorage)
+type Store interface {
+	DueScheduledTasks(now time.Time) ([]*storage.ScheduledTask, error)
+	ClaimScheduledRun(name string, due, next time.Time) (bool, error)
+	RecordScheduledRun(name string, at time.Time, taskID string, runErr error) error
+}
+
+// TaskCreator creates the tasks of a run (implemented by storage.TaskStorage)
+type TaskCreator interface {
+	CreateHumanTask(prompt string) (*storage.HumanTask, error)
+	CreateAgentTask(humanTaskID, agentName, role string, todos []storage.TodoItemInput, contextSummary string, filesModified []string, qdrantCollections []string, priorWorkSummary string) (*storage.AgentTask, error)
+}
+
+// NextRun returns the first run of a schedule after t, in UTC. It fails for
+// invalid cron expressions and timezones, so it also validates schedules
+// before they are saved.
+func NextRun(task *storage.ScheduledTask, t time.Time) (time.Time, error) {
+	cron, err := ParseCron(task.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	zone := task.Timezone
+	if zone == "" {
+		zone = "UTC"
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q: %w", zone, err)
+	}
+	next := cron.Next(t.In(location))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression %q never matches", task.Cron)
+	}
+	return next.UTC(), nil
+}
+
+// Runner creates agent tasks when their schedules come up. A run missed
+// while no coordinator was running happens once, at the next check, rather
+// than once per missed slot.
+type Runner struct {
+	store  Store
+	tasks  TaskCreator
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewRunner creates a schedule runner
+func NewRunner(store Store, tasks TaskCreator, logger *zap.Logger) *Runner {
+	return &Runner{
+		store:  store,
+		tasks:  tasks,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Start runs due schedules every 30 seconds until ctx is cancelled
+func (r *Runner) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		r.logger.Info("Task scheduler started")
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.RunDue()
+			}
+		}
+	}()
+}
+
+// RunDue creates the tasks of every due schedule. Each run is claimed
+// first, so coordinators sharing a database create it once.
+func (r *Runner) RunDue() {
+	now := r.now().UTC()
+	due, err := r.store.DueScheduledTasks(now)
+	if err != nil {
+		r.logger.Warn("Failed to list due scheduled tasks", zap.Error(err))
+		return
+	}
+
+	for _, task := range due {
+		next, err := NextRun(task, now)
+		if err != nil {
+			r.logger.Warn("Scheduled task has an invalid schedule", zap.String("name", task.Name), zap.Error(err))
+			r.record(task.Name, now, "", err)
+			continue
+		}
+		claimed, err := r.store.ClaimScheduledRun(task.Name, task.NextRunAt, next)
+		if err != nil {
+			r.logger.Warn("Failed to claim scheduled task run", zap.String("name", task.Name), zap.Error(err))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		agentTask, err := r.Create(task.Template)
+		if err != nil {
+			r.logger.Warn("Scheduled task run failed", zap.String("name", task.Name), zap.Error(err))
+			r.record(task.Name, now, "", err)
+			continue
+		}
+		r.record(task.Name, now, agentTask.ID, nil)
+		r.logger.Info("Scheduled task created",
+			zap.String("name", task.Name),
+			zap.String("agentTaskId", agentTask.ID),
+			zap.Time("nextRunAt", next))
+	}
+}
+
+// Create creates the agent task of a template, under a new human task when
+// the template has no parent
+func (r *Runner) Create(template storage.ScheduledTaskTemplate) (*storage.AgentTask, error) {
+	humanTaskID := template.HumanTaskID
+	if humanTaskID == "" {
+		humanTask, err := r.tasks.CreateHumanTask(template.Prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create human task: %w", err)
+		}
+		humanTaskID = humanTask.ID
+	}
+
+	agentTask, err := r.tasks.CreateAgentTask(humanTaskID, template.AgentName, template.Role, template.Todos,
+		template.ContextSummary, nil, template.QdrantCollections, template.PriorWorkSummary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent task: %w", err)
+	}
+	return agentTask, nil
+}
+
+func (r *Runner) record(name string, at time.Time, taskID string, runErr error) {
+	if err := r.store.RecordScheduledRun(name, at, taskID, runErr); err != nil {
+		r.logger.Warn("Failed to record scheduled task run", zap.String("name", name), zap.Error(err))
+	}
+}
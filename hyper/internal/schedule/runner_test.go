@@ -0,0 +1,118 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeStore keeps schedules in memory
+type fakeStore struct {
+	tasks map[string]*storage.ScheduledTask
+	runs  []string // Task IDs recorded, "error: ..." for failures
+}
+
+func (f *fakeStore) DueScheduledTasks(now time.Time) ([]*storage.ScheduledTask, error) {
+	var due []*storage.ScheduledTask
+	for _, task := range f.tasks {
+		if task.Enabled && !task.NextRunAt.After(now) {
+			copied := *task
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeStore) ClaimScheduledRun(name string, due, next time.Time) (bool, error) {
+	task := f.tasks[name]
+	if task == nil || !task.NextRunAt.Equal(due) {
+		return false, nil
+	}
+	task.NextRunAt = next
+	return true, nil
+}
+
+func (f *fakeStore) RecordScheduledRun(name string, at time.Time, taskID string, runErr error) error {
+	if runErr != nil {
+		f.runs = append(f.runs, "error: "+runErr.Error())
+		return nil
+	}
+	f.runs = append(f.runs, taskID)
+	return nil
+}
+
+// fakeTasks records created tasks
+type fakeTasks struct {
+	human []string // Prompts
+	agent []*storage.AgentTask
+	fail  bool
+}
+
+func (f *fakeTasks) CreateHumanTask(prompt string) (*storage.HumanTask, error) {
+	f.human = append(f.human, prompt)
+	return &storage.HumanTask{ID: fmt.Sprintf("human-%d", len(f.human)), Prompt: prompt}, nil
+}
+
+func (f *fakeTasks) CreateAgentTask(humanTaskID, agentName, role string, todos []storage.TodoItemInput, contextSummary string, filesModified []string, qdrantCollections []string, priorWorkSummary string) (*storage.AgentTask, error) {
+	if f.fail {
+		return nil, fmt.Errorf("human task not found")
+	}
+	task := &storage.AgentTask{ID: fmt.Sprintf("agent-%d", len(f.agent)+1), HumanTaskID: humanTaskID, AgentName: agentName, Role: role}
+	f.agent = append(f.agent, task)
+	return task, nil
+}
+
+func TestRunner_RunDue(t *testing.T) {
+	now := time.Date(2026, 10, 16, 2, 0, 30, 0, time.UTC)
+	store := &fakeStore{tasks: map[string]*storage.ScheduledTask{
+		"reindex": {
+			Name: "reindex", Cron: "0 2 * * *", Enabled: true,
+			NextRunAt: time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC), // Missed twice while down
+			Template: storage.ScheduledTaskTemplate{
+				Prompt: "Nightly re-index", AgentName: "indexer", Role: "Re-index the monorepo",
+				Todos: []storage.TodoItemInput{{Description: "Scan folders"}},
+			},
+		},
+		"cleanup": {
+			Name: "cleanup", Cron: "0 3 * * *", Enabled: true,
+			NextRunAt: time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC),
+			Template:  storage.ScheduledTaskTemplate{HumanTaskID: "human-x", AgentName: "librarian", Role: "Clean up"},
+		},
+	}}
+	tasks := &fakeTasks{}
+	runner := NewRunner(store, tasks, zap.NewNop())
+	runner.now = func() time.Time { return now }
+
+	runner.RunDue()
+	require.Len(t, tasks.agent, 1, "only the due schedule runs, once")
+	assert.Equal(t, []string{"Nightly re-index"}, tasks.human)
+	assert.Equal(t, "human-1", tasks.agent[0].HumanTaskID)
+	assert.Equal(t, []string{"agent-1"}, store.runs)
+	assert.Equal(t, time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC), store.tasks["reindex"].NextRunAt)
+
+	// Nothing is due until the next slot
+	runner.RunDue()
+	assert.Len(t, tasks.agent, 1)
+
+	now = time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+	tasks.fail = true
+	runner.RunDue()
+	assert.Equal(t, []string{"agent-1", "error: failed to create agent task: human task not found"}, store.runs)
+	assert.Equal(t, time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC), store.tasks["cleanup"].NextRunAt, "a failed run still moves on")
+}
+
+func TestNextRun_Timezone(t *testing.T) {
+	task := &storage.ScheduledTask{Cron: "0 2 * * *", Timezone: "Europe/Paris"}
+	next, err := NextRun(task, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), next) // 02:00 CEST
+
+	_, err = NextRun(&storage.ScheduledTask{Cron: "0 2 * * *", Timezone: "Mars/Olympus"}, time.Now())
+	assert.ErrorContains(t, err, "invalid timezone")
+}
@@ -17,13 +17,20 @@ import (
 	"hyper/internal/ai-service/tools"
 	mcptools "hyper/internal/ai-service/tools/mcp"
 	"hyper/internal/api"
+	"hyper/internal/bulktasks"
+	"hyper/internal/console"
+	"hyper/internal/errcode"
 	"hyper/internal/handlers"
+	"hyper/internal/jira"
 	"hyper/internal/middleware"
 	"hyper/internal/services"
 	"hyper/internal/mcp/embeddings"
 	mcphandlers "hyper/internal/mcp/handlers"
 	"hyper/internal/mcp/storage"
 	"hyper/internal/mcp/watcher"
+	"hyper/internal/priority"
+	"hyper/internal/taskevents"
+	"hyper/internal/undo"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -78,8 +85,8 @@ func promptKillProcess(port string, pid int, logger *zap.Logger) (bool, error) {
 		return false, nil
 	}
 
-	fmt.Printf("\n⚠️  Port %s is already in use by process %d\n", port, pid)
-	fmt.Print("Kill the process and retry? [y/N]: ")
+	console.Printf("\n⚠️  Port %s is already in use by process %d\n", port, pid)
+	console.Printf("Kill the process and retry? [y/N]: ")
 
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
@@ -110,6 +117,15 @@ func killProcess(pid int, logger *zap.Logger) error {
 	return nil
 }
 
+// Lifecycle controls probes and shutdown of the HTTP server. The zero value
+// suits a workstation: no probe listener, no drain and a 5s shutdown.
+type Lifecycle struct {
+	ProbePort       string        // Serves /healthz, /readyz and /drain apart from the API
+	DrainDelay      time.Duration // How long /readyz fails before the listener closes
+	ShutdownTimeout time.Duration // Bound for in-flight requests to finish
+	JSONLogs        bool          // Skip gin's text access log, which would break JSON logs
+}
+
 // StartHTTPServer starts the HTTP server with REST API + UI static serving + MCP HTTP endpoint
 func StartHTTPServer(
 	ctx context.Context,
@@ -121,10 +137,16 @@ func StartHTTPServer(
 	embeddingClient embeddings.EmbeddingClient,
 	fileWatcher *watcher.FileWatcher,
 	mcpServer *mcp.Server,
+	toolInvoker api.ToolInvoker,
 	embeddedUI http.FileSystem,
 	hasEmbeddedUI bool,
 	logger *zap.Logger,
 	mongoDatabase *mongo.Database,
+	jiraSync *jira.Sync,
+	taskEvents *taskevents.Hub,
+	bulkEditor *bulktasks.Editor,
+	undoManager *undo.Manager,
+	lifecycle Lifecycle,
 ) error {
 	// Create REST API handler
 	restHandler := api.NewRESTAPIHandler(
@@ -137,6 +159,19 @@ func StartHTTPServer(
 		logger,
 	)
 
+	// Serve uploads and downloads of agent task artifacts
+	if artifactStorage, err := storage.NewTaskArtifactStorage(mongoDatabase, logger); err != nil {
+		logger.Warn("Agent task artifacts disabled", zap.Error(err))
+	} else {
+		restHandler.SetArtifactStorage(artifactStorage)
+	}
+
+	// Change many agent tasks at once
+	restHandler.SetBulkEditor(bulkEditor)
+
+	// Stage folder removals for the undo window shared with the MCP tools
+	restHandler.SetUndoManager(undoManager)
+
 	// Initialize chat service
 	chatService, err := services.NewChatService(mongoDatabase, logger)
 	if err != nil {
@@ -277,7 +312,12 @@ func StartHTTPServer(
 
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode)
+	gin.DefaultWriter = console.Writer() // Request logs must stay off stdout in stdio mode
 	r := gin.Default()
+	if lifecycle.JSONLogs {
+		r = gin.New()
+		r.Use(gin.Recovery())
+	}
 
 	// Configure CORS for frontend
 	corsConfig := cors.DefaultConfig()
@@ -295,14 +335,34 @@ func StartHTTPServer(
 		"http://hyperion-ui:80",  // Docker internal network with port
 	}
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "X-Request-ID", "Authorization"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "X-Request-ID", "Authorization", middleware.APIKeyHeader, priority.PriorityHeader, priority.TimeoutHeader}
 	corsConfig.AllowCredentials = true
 	r.Use(cors.New(corsConfig))
 
-	// Register optional JWT authentication middleware
-	// Disabled by default (injects dev mock values)
-	// Enable with ENABLE_JWT=true environment variable
-	r.Use(middleware.OptionalJWTMiddleware())
+	// Carry request deadlines and priority hints (X-Hyper-Priority,
+	// X-Hyper-Timeout-Ms) down to storage, so indexing yields to queries
+	r.Use(middleware.RequestPriorityMiddleware())
+
+	// Register authentication middleware: API keys (API_KEYS or created
+	// through /api/v1/admin/api-keys) and JWTs (ENABLE_JWT=true). Requests
+	// without credentials get dev mock values unless ENABLE_JWT or
+	// AUTH_REQUIRED is set or an API key exists
+	apiKeysHandler := handlers.NewAPIKeysHandler(storage.NewAPIKeyStorage(mongoDatabase, logger), logger)
+	authMiddleware, err := middleware.AuthMiddleware(apiKeysHandler, logger)
+	if err != nil {
+		return fmt.Errorf("invalid authentication configuration: %w", err)
+	}
+	r.Use(authMiddleware)
+
+	// Register RBAC middleware (resolves role from stored assignments, JWT claims,
+	// or RBAC_DEFAULT_ROLE and enforces per-route minimum roles)
+	// POLICY_OPA_URL delegates these decisions to an OPA server
+	roleStorage := storage.NewRoleStorage(mongoDatabase, logger)
+	policy, err := middleware.LoadPolicy(logger)
+	if err != nil {
+		return fmt.Errorf("invalid authorization policy configuration: %w", err)
+	}
+	r.Use(middleware.RBACMiddleware(roleStorage, policy, logger))
 
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
@@ -313,9 +373,72 @@ func StartHTTPServer(
 		})
 	})
 
+	// Readiness: MongoDB, Qdrant and the embedding provider answer, and the
+	// server is not draining
+	readiness := NewReadiness(mongoDatabase, qdrantClient, embeddingClient)
+	r.GET("/readyz", readiness.ServeReadyz)
+
 	// Register REST API routes
 	restHandler.RegisterRESTRoutes(r)
 
+	// Expose every registered MCP tool at POST /api/tools/:toolName, calling
+	// the tool handlers in-process
+	toolProxy := api.NewToolProxy(toolInvoker, logger)
+	toolProxy.SetPolicy(policy)
+	toolProxy.RegisterRoutes(r)
+
+	logger.Info("REST tool proxy routes registered",
+		zap.String("listPath", "/api/tools"),
+		zap.String("callPath", "/api/tools/:toolName"))
+
+	// Register RBAC role administration routes
+	rolesHandler := handlers.NewRolesHandler(roleStorage, logger)
+	rolesHandler.RegisterRolesRoutes(r)
+
+	logger.Info("Roles API routes registered",
+		zap.String("adminPath", "/api/v1/admin/roles"),
+		zap.String("currentRolePath", "/api/v1/roles/me"))
+
+	// Register API key administration routes
+	apiKeysHandler.RegisterRoutes(r)
+
+	logger.Info("API keys routes registered",
+		zap.String("adminPath", "/api/v1/admin/api-keys"))
+
+	// Register the Jira webhook when the Jira sync is enabled
+	if jiraSync != nil {
+		jiraWebhookHandler := handlers.NewJiraWebhookHandler(jiraSync, logger)
+		jiraWebhookHandler.RegisterRoutes(r)
+
+		logger.Info("Jira webhook route registered",
+			zap.String("webhookPath", handlers.JiraWebhookPath))
+	}
+
+	// Stream task changes over WebSocket
+	taskEventsHandler := handlers.NewTaskEventsHandler(taskEvents, corsConfig.AllowOrigins, logger)
+	taskEventsHandler.RegisterRoutes(r)
+
+	logger.Info("Task event stream route registered",
+		zap.String("websocketPath", handlers.TaskEventsPath))
+
+	// Register the calendar feed when CALENDAR_FEED_TOKEN is set
+	if calendarHandler := handlers.NewCalendarHandler(taskStorage, logger); calendarHandler != nil {
+		calendarHandler.RegisterRoutes(r)
+
+		logger.Info("Calendar feed route registered",
+			zap.String("feedPath", middleware.CalendarFeedPath))
+	}
+
+	// Register task share links when SHARE_LINK_SECRET is set
+	shareLinkStorage := storage.NewShareLinkStorage(mongoDatabase, logger)
+	if shareLinksHandler := handlers.NewShareLinksHandler(shareLinkStorage, taskStorage, logger); shareLinksHandler != nil {
+		shareLinksHandler.RegisterRoutes(r)
+
+		logger.Info("Share link routes registered",
+			zap.String("sharePath", middleware.SharePathPrefix+":token"),
+			zap.String("managePath", "/api/v1/tasks/:id/share-links"))
+	}
+
 	// Register chat routes
 	chatGroup := r.Group("/api/v1/chat")
 	{
@@ -348,7 +471,10 @@ func StartHTTPServer(
 	}
 
 	logger.Info("Knowledge API routes registered",
-		zap.String("popularCollectionsPath", "/api/v1/knowledge/popular-collections"))
+		zap.String("popularCollectionsPath", "/api/v1/knowledge/popular-collections"),
+		zap.String("importPath", "/api/v1/knowledge/import"),
+		zap.String("exportPath", "/api/v1/knowledge/export"),
+		zap.String("analyticsPath", "/api/v1/knowledge/analytics"))
 
 	// Initialize subchat storage and handlers
 	subchatStorage := storage.NewSubchatStorage(mongoDatabase, logger)
@@ -374,12 +500,15 @@ func StartHTTPServer(
 	{
 		subagentGroup.GET("", subagentHandler.ListSubagents)
 		subagentGroup.GET("/:name", subagentHandler.GetSubagent)
+		subagentGroup.GET("/:name/persona", subagentHandler.GetPersona)
+		subagentGroup.PUT("/:name/persona", subagentHandler.UpdatePersona)
 	}
 
 	logger.Info("Subchat and Subagent API routes registered",
 		zap.String("subchatsPath", "/api/v1/subchats"),
 		zap.String("chatSubchatsPath", "/api/v1/chats/:chatId/subchats"),
-		zap.String("subagentsPath", "/api/v1/subagents"))
+		zap.String("subagentsPath", "/api/v1/subagents"),
+		zap.String("personaPath", "/api/v1/subagents/:name/persona"))
 
 	// Register HTTP tools routes
 	httpToolsGroup := r.Group("/api/v1/tools/http")
@@ -412,12 +541,13 @@ func StartHTTPServer(
 	// Mount MCP handler at /mcp endpoint
 	// This handles both GET (session info) and POST (JSON-RPC requests)
 	// The StreamableHTTPHandler implements http.Handler interface
-	r.Any("/mcp", gin.WrapH(mcpHandler))
+	// Protocol versions are validated first so unsupported clients get a spec-compliant error
+	r.Any("/mcp", middleware.MCPProtocolVersionMiddleware(logger), gin.WrapH(mcpHandler))
 
 	logger.Info("MCP HTTP transport initialized",
 		zap.String("endpoint", "/mcp"),
 		zap.String("transport", "StreamableHTTP"),
-		zap.String("protocol", "2024-11-05"))
+		zap.Strings("protocolVersions", middleware.SupportedProtocolVersions))
 
 	// Serve UI static files
 	// Priority: embedded UI (single binary) > filesystem (development)
@@ -437,7 +567,7 @@ func StartHTTPServer(
 					return
 				}
 			}
-			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			errcode.RespondCode(c, errcode.NotFound, "Not found")
 		})
 	} else {
 		// Development mode: proxy to Vite dev server for hot reload
@@ -477,7 +607,7 @@ func StartHTTPServer(
 
 		// Fallback for other routes
 		r.NoRoute(func(c *gin.Context) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			errcode.RespondCode(c, errcode.NotFound, "Not found")
 		})
 	}
 
@@ -554,7 +684,7 @@ func StartHTTPServer(
 				return fmt.Errorf("failed to kill process %d: %w", pid, killErr)
 			}
 
-			fmt.Printf("✓ Killed process %d, retrying... (attempt %d/%d)\n", pid, attempt, maxRetries)
+			console.Printf("✓ Killed process %d, retrying... (attempt %d/%d)\n", pid, attempt, maxRetries)
 			logger.Info("Process killed, retrying server start",
 				zap.Int("pid", pid),
 				zap.Int("attempt", attempt),
@@ -590,14 +720,32 @@ func StartHTTPServer(
 		return fmt.Errorf("failed to start HTTP server after %d attempts: %w", maxRetries, startErr)
 	}
 
+	var probeSrv *http.Server
+	if lifecycle.ProbePort != "" {
+		probeSrv = startProbeServer(lifecycle, readiness, logger)
+	}
+
 	// Wait for context cancellation (shutdown signal)
 	<-ctx.Done()
 	logger.Info("HTTP server shutting down...")
 
-	// Graceful shutdown with 5 second timeout
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Keep serving while load balancers see /readyz fail and stop routing here
+	if lifecycle.DrainDelay > 0 {
+		logger.Info("Draining before the listener closes", zap.Duration("delay", lifecycle.DrainDelay))
+		readiness.WaitDrained(context.Background(), lifecycle.DrainDelay)
+	}
+
+	// Graceful shutdown, 5 seconds unless configured
+	shutdownTimeout := lifecycle.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = 5 * time.Second
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
+	if probeSrv != nil {
+		probeSrv.Shutdown(shutdownCtx)
+	}
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("HTTP server forced to shutdown", zap.Error(err))
 		return err
@@ -606,3 +754,30 @@ func StartHTTPServer(
 	logger.Info("HTTP server stopped")
 	return nil
 }
+
+// startProbeServer serves the Kubernetes probes on their own port, kept out
+// of the Service so only the kubelet reaches /drain: /healthz (liveness),
+// /readyz (readiness) and /drain (a preStop hook that marks the pod not ready
+// and returns once the drain delay has passed)
+func startProbeServer(lifecycle Lifecycle, readiness *Readiness, logger *zap.Logger) *http.Server {
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "alive"})
+	})
+	r.GET("/readyz", readiness.ServeReadyz)
+	r.GET("/drain", func(c *gin.Context) {
+		logger.Info("Drain requested by preStop hook", zap.Duration("delay", lifecycle.DrainDelay))
+		readiness.WaitDrained(c.Request.Context(), lifecycle.DrainDelay)
+		c.JSON(http.StatusOK, gin.H{"status": "drained"})
+	})
+
+	srv := &http.Server{Addr: ":" + lifecycle.ProbePort, Handler: r}
+	go func() {
+		logger.Info("Probe server listening", zap.String("port", lifecycle.ProbePort))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Probe server error", zap.Error(err))
+		}
+	}()
+	return srv
+}
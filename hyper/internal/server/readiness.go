@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"hyper/internal/mcp/embeddings"
+	"hyper/internal/mcp/storage"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// readinessTimeout bounds each dependency check
+const readinessTimeout = 3 * time.Second
+
+// readinessCheck is one dependency /readyz is gated on. Results are reused
+// for ttl, so frequent probes don't turn into load on the dependency (or
+// paid embedding calls).
+type readinessCheck struct {
+	name  string
+	ttl   time.Duration
+	check func(ctx context.Context) error
+
+	mu        sync.Mutex
+	err       error
+	checkedAt time.Time
+}
+
+func (c *readinessCheck) run(ctx context.Context, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.checkedAt.IsZero() && now.Sub(c.checkedAt) < c.ttl {
+		return c.err
+	}
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	c.err = c.check(ctx)
+	c.checkedAt = now
+	return c.err
+}
+
+// Readiness answers /readyz: ready while MongoDB, Qdrant and the embedding
+// provider answer, and not ready once the server starts draining
+type Readiness struct {
+	checks   []*readinessCheck
+	draining atomic.Bool
+	drainAt  atomic.Int64 // Unix nanoseconds the drain started
+	now      func() time.Time
+}
+
+// NewReadiness returns readiness checks of the coordinator's dependencies
+func NewReadiness(db *mongo.Database, qdrant *storage.QdrantClient, embedder embeddings.EmbeddingClient) *Readiness {
+	r := &Readiness{now: time.Now}
+	if db != nil {
+		r.add("mongodb", 5*time.Second, func(ctx context.Context) error {
+			return db.Client().Ping(ctx, nil)
+		})
+	}
+	if qdrant != nil {
+		r.add("qdrant", 5*time.Second, qdrant.Ping)
+	}
+	if embedder != nil {
+		r.add("embedding", time.Minute, func(ctx context.Context) error {
+			done := make(chan error, 1)
+			go func() {
+				_, err := embedder.CreateEmbedding("readiness probe")
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
+	return r
+}
+
+func (r *Readiness) add(name string, ttl time.Duration, check func(ctx context.Context) error) {
+	r.checks = append(r.checks, &readinessCheck{name: name, ttl: ttl, check: check})
+}
+
+// Drain marks the server not ready, so load balancers stop routing to it
+func (r *Readiness) Drain() {
+	if r.draining.CompareAndSwap(false, true) {
+		r.drainAt.Store(r.now().UnixNano())
+	}
+}
+
+// Draining reports whether the server is draining
+func (r *Readiness) Draining() bool {
+	return r.draining.Load()
+}
+
+// WaitDrained drains and returns once it has been draining for delay, or
+// when ctx is done
+func (r *Readiness) WaitDrained(ctx context.Context, delay time.Duration) {
+	r.Drain()
+	remaining := delay - r.now().Sub(time.Unix(0, r.drainAt.Load()))
+	if remaining <= 0 {
+		return
+	}
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// Check runs the checks in parallel and returns each failure by name
+func (r *Readiness) Check(ctx context.Context) map[string]string {
+	now := r.now()
+	failures := map[string]string{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range r.checks {
+		wg.Add(1)
+		go func(check *readinessCheck) {
+			defer wg.Done()
+			if err := check.run(ctx, now); err != nil {
+				mu.Lock()
+				failures[check.name] = err.Error()
+				mu.Unlock()
+			}
+		}(check)
+	}
+	wg.Wait()
+	return failures
+}
+
+// ServeReadyz handles GET /readyz
+func (r *Readiness) ServeReadyz(c *gin.Context) {
+	if r.Draining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+
+	checks := make(map[string]string, len(r.checks))
+	failures := r.Check(c.Request.Context())
+	for _, check := range r.checks {
+		checks[check.name] = "ok"
+		if failure, failed := failures[check.name]; failed {
+			checks[check.name] = failure
+		}
+	}
+	if len(failures) > 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveReadyz(t *testing.T, readiness *Readiness) (int, map[string]interface{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/readyz", readiness.ServeReadyz)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func TestReadiness(t *testing.T) {
+	now := time.Now()
+	readiness := &Readiness{now: func() time.Time { return now }}
+	var embedCalls int
+	var qdrantErr error
+	readiness.add("qdrant", 5*time.Second, func(ctx context.Context) error { return qdrantErr })
+	readiness.add("embedding", time.Minute, func(ctx context.Context) error {
+		embedCalls++
+		return nil
+	})
+
+	code, body := serveReadyz(t, readiness)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body["status"])
+	assert.Equal(t, map[string]interface{}{"qdrant": "ok", "embedding": "ok"}, body["checks"])
+
+	qdrantErr = errors.New("connection refused")
+	code, _ = serveReadyz(t, readiness)
+	assert.Equal(t, http.StatusOK, code, "results are reused within their ttl")
+
+	now = now.Add(10 * time.Second)
+	code, body = serveReadyz(t, readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", body["status"])
+	assert.Equal(t, "connection refused", body["checks"].(map[string]interface{})["qdrant"])
+	assert.Equal(t, 1, embedCalls, "the embedding provider is called once a minute at most")
+
+	qdrantErr = nil
+	readiness.Drain()
+	code, body = serveReadyz(t, readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "draining", body["status"])
+}
+
+func TestReadinessWaitDrained(t *testing.T) {
+	readiness := &Readiness{now: time.Now}
+
+	start := time.Now()
+	readiness.WaitDrained(context.Background(), 50*time.Millisecond)
+	assert.True(t, readiness.Draining())
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	start = time.Now()
+	readiness.WaitDrained(context.Background(), 50*time.Millisecond)
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "a drain started by the preStop hook is not repeated on SIGTERM")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	other := &Readiness{now: time.Now}
+	start = time.Now()
+	other.WaitDrained(ctx, time.Minute)
+	assert.Less(t, time.Since(start), time.Second, "stops waiting when the request is cancelled")
+}
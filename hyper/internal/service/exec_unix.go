@@ -0,0 +1,18 @@
+//go:build darwin || linux
+
+package service
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// run runs a service manager command, returning its output in the error
+func run(name string, args ...string) error {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Package service installs the coordinator as an OS-managed background
+// service so it keeps running across logins and reboots: a launchd agent on
+// macOS, a systemd user unit on Linux and a Windows service. Each is set up
+// to restart the coordinator when it crashes and to append its output to a
+// log file.
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Name is the systemd unit and Windows service name
+	Name = "hyper"
+	// Label is the launchd job label
+	Label = "ai.hyperion.hyper"
+	// DisplayName is shown in service managers
+	DisplayName = "Hyperion Coordinator"
+	// Description describes the service in service managers
+	Description = "Hyperion coordinator: MCP tools, REST API and UI"
+	// restartDelaySeconds is how long a crashed coordinator is given before
+	// it is restarted
+	restartDelaySeconds = 5
+)
+
+// Config describes how the service runs the coordinator
+type Config struct {
+	Executable string   // Absolute path of the hyper binary
+	Args       []string // Command-line arguments, e.g. --mode=http
+	WorkingDir string   // Directory the coordinator runs in
+	LogFile    string   // File stdout and stderr are appended to
+}
+
+// Validate checks that a config can be installed
+func (c Config) Validate() error {
+	if !filepath.IsAbs(c.Executable) {
+		return fmt.Errorf("executable must be an absolute path: %q", c.Executable)
+	}
+	if c.LogFile == "" {
+		return fmt.Errorf("log file is required")
+	}
+	if c.WorkingDir == "" {
+		return fmt.Errorf("working directory is required")
+	}
+	return nil
+}
+
+// commandLine quotes the executable and arguments for a systemd ExecStart
+// line, which splits on whitespace unless quoted
+func (c Config) commandLine() string {
+	parts := make([]string, 0, len(c.Args)+1)
+	for _, part := range append([]string{c.Executable}, c.Args...) {
+		if strings.ContainsAny(part, " \t\"\\") {
+			part = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(part) + `"`
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Install registers the coordinator with the OS service manager and enables
+// it to start at login or boot. The log directory is created if needed.
+func Install(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.LogFile), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	return install(cfg)
+}
+
+// Uninstall stops the service and removes it from the OS service manager
+func Uninstall() error { return uninstall() }
+
+// Start starts the installed service
+func Start() error { return start() }
+
+// Stop stops the installed service; it is not restarted until started again
+// or until the next login or boot
+func Stop() error { return stop() }
+
+// DefaultLogFile is where the service's output goes on this platform
+func DefaultLogFile() (string, error) { return defaultLogFile() }
+
+// Hint is a platform note shown after installation, or empty
+func Hint() string { return hint }
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const hint = "The agent starts at login and is restarted if it crashes."
+
+// plistPath is the launchd agent file of the current user
+func plistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", Label+".plist"), nil
+}
+
+func defaultLogFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Logs", "Hyperion", "hyper.log"), nil
+}
+
+// domain is the launchd GUI domain of the current user
+func domain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+func install(cfg Config) error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Replace an earlier installation; bootout fails when none is loaded
+	_ = run("launchctl", "bootout", domain()+"/"+Label)
+	if err := os.WriteFile(path, []byte(LaunchdPlist(cfg)), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return run("launchctl", "bootstrap", domain(), path)
+}
+
+func uninstall() error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	_ = run("launchctl", "bootout", domain()+"/"+Label)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func start() error {
+	return run("launchctl", "kickstart", domain()+"/"+Label)
+}
+
+func stop() error {
+	// A clean exit is not restarted by KeepAlive
+	return run("launchctl", "kill", "SIGTERM", domain()+"/"+Label)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const hint = "The unit starts at login; run `loginctl enable-linger` to start it at boot without logging in."
+
+// unitPath is the systemd user unit file of the current user
+func unitPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "systemd", "user", Name+".service"), nil
+}
+
+func defaultLogFile() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "hyper", "hyper.log"), nil
+}
+
+func systemctl(args ...string) error {
+	return run("systemctl", append([]string{"--user"}, args...)...)
+}
+
+func install(cfg Config) error {
+	path, err := unitPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(SystemdUnit(cfg)), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", Name+".service")
+}
+
+func uninstall() error {
+	path, err := unitPath()
+	if err != nil {
+		return err
+	}
+	_ = systemctl("disable", "--now", Name+".service")
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return systemctl("daemon-reload")
+}
+
+func start() error {
+	return systemctl("start", Name+".service")
+}
+
+func stop() error {
+	return systemctl("stop", Name+".service")
+}
@@ -0,0 +1,20 @@
+//go:build !darwin && !linux && !windows
+
+package service
+
+import (
+	"fmt"
+	"runtime"
+)
+
+const hint = ""
+
+func unsupported() error {
+	return fmt.Errorf("service installation is not supported on %s", runtime.GOOS)
+}
+
+func defaultLogFile() (string, error) { return "", unsupported() }
+func install(Config) error            { return unsupported() }
+func uninstall() error                { return unsupported() }
+func start() error                    { return unsupported() }
+func stop() error                     { return unsupported() }
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const hint = "The service starts at boot and is restarted if it crashes. Installing and controlling it requires an administrator prompt."
+
+// stopTimeout bounds how long Stop waits for the coordinator to shut down
+const stopTimeout = 30 * time.Second
+
+// defaultLogFile is under ProgramData, since the service runs as LocalSystem
+func defaultLogFile() (string, error) {
+	dir := os.Getenv("ProgramData")
+	if dir == "" {
+		dir = `C:\ProgramData`
+	}
+	return filepath.Join(dir, "Hyperion", "logs", "hyper.log"), nil
+}
+
+// openService connects to the service control manager and opens the service
+func openService() (*mgr.Mgr, *mgr.Service, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to the service control manager (run as administrator): %w", err)
+	}
+	s, err := m.OpenService(Name)
+	if err != nil {
+		m.Disconnect()
+		return nil, nil, fmt.Errorf("service %s is not installed: %w", Name, err)
+	}
+	return m, s, nil
+}
+
+// install creates an automatic-start service that restarts on failure.
+// Services have no console and start in System32, so the coordinator is
+// passed --log-file and changes to its own directory (cfg.WorkingDir is not
+// used).
+func install(cfg Config) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service control manager (run as administrator): %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(Name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is already installed; run `hyper service uninstall` first", Name)
+	}
+
+	s, err := m.CreateService(Name, cfg.Executable, mgr.Config{
+		DisplayName: DisplayName,
+		Description: Description,
+		StartType:   mgr.StartAutomatic,
+	}, append(cfg.Args, "--log-file="+cfg.LogFile)...)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+	defer s.Close()
+
+	restart := mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: restartDelaySeconds * time.Second}
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{restart, restart, restart}, uint32((24 * time.Hour).Seconds())); err != nil {
+		return fmt.Errorf("failed to set restart policy: %w", err)
+	}
+	return nil
+}
+
+func uninstall() error {
+	m, s, err := openService()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+
+	if status, err := s.Query(); err == nil && status.State != svc.Stopped {
+		if err := stopAndWait(s); err != nil {
+			return err
+		}
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+	return nil
+}
+
+func start() error {
+	m, s, err := openService()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("failed to start service: %w", err)
+	}
+	return nil
+}
+
+func stop() error {
+	m, s, err := openService()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+	return stopAndWait(s)
+}
+
+// stopAndWait asks the service to stop and waits until it has
+func stopAndWait(s *mgr.Service) error {
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return fmt.Errorf("failed to stop service: %w", err)
+	}
+	deadline := time.Now().Add(stopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("service did not stop within %s", stopTimeout)
+		}
+		time.Sleep(300 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return fmt.Errorf("failed to query service: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// LaunchdPlist renders the launchd agent for cfg. KeepAlive restarts the
+// coordinator when it exits with an error, but not after a clean stop.
+func LaunchdPlist(cfg Config) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	writeKey(&b, "Label", Label)
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{cfg.Executable}, cfg.Args...) {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString("\t</array>\n")
+	writeKey(&b, "WorkingDirectory", cfg.WorkingDir)
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	fmt.Fprintf(&b, "\t<key>ThrottleInterval</key>\n\t<integer>%d</integer>\n", restartDelaySeconds)
+	writeKey(&b, "StandardOutPath", cfg.LogFile)
+	writeKey(&b, "StandardErrorPath", cfg.LogFile)
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+func writeKey(b *strings.Builder, key, value string) {
+	fmt.Fprintf(b, "\t<key>%s</key>\n\t<string>%s</string>\n", key, xmlEscape(value))
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// SystemdUnit renders the systemd user unit for cfg. The coordinator is
+// restarted when it fails and its output is appended to the log file.
+func SystemdUnit(cfg Config) string {
+	return fmt.Sprintf(`[Unit]
+Description=%s
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=simple
+ExecStart=%s
+WorkingDirectory=%s
+Restart=on-failure
+RestartSec=%d
+StandardOutput=append:%s
+StandardError=append:%s
+
+[Install]
+WantedBy=default.target
+`, DisplayName, cfg.commandLine(), cfg.WorkingDir, restartDelaySeconds, cfg.LogFile, cfg.LogFile)
+}
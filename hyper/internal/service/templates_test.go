@@ -0,0 +1,91 @@
+package service
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func testConfig() Config {
+	return Config{
+		Executable: "/Users/dev/Hyper Apps/hyper",
+		Args:       []string{"--mode=http", "--profile=work"},
+		WorkingDir: "/Users/dev/Hyper Apps",
+		LogFile:    "/Users/dev/Library/Logs/Hyperion/hyper.log",
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	plist := LaunchdPlist(testConfig())
+
+	// Must be well-formed XML
+	decoder := xml.NewDecoder(strings.NewReader(plist))
+	decoder.Strict = false
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("invalid plist XML: %v", err)
+			}
+			break
+		}
+	}
+
+	for _, want := range []string{
+		"<string>" + Label + "</string>",
+		"<string>/Users/dev/Hyper Apps/hyper</string>\n\t\t<string>--mode=http</string>\n\t\t<string>--profile=work</string>",
+		"<key>RunAtLoad</key>\n\t<true/>",
+		"<key>SuccessfulExit</key>\n\t\t<false/>",
+		"<key>StandardOutPath</key>\n\t<string>/Users/dev/Library/Logs/Hyperion/hyper.log</string>",
+		"<key>StandardErrorPath</key>\n\t<string>/Users/dev/Library/Logs/Hyperion/hyper.log</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist missing %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestLaunchdPlistEscapesXML(t *testing.T) {
+	cfg := testConfig()
+	cfg.Args = []string{"--config=/tmp/a&b<c>.env"}
+	plist := LaunchdPlist(cfg)
+	if !strings.Contains(plist, "<string>--config=/tmp/a&amp;b&lt;c&gt;.env</string>") {
+		t.Errorf("expected escaped argument:\n%s", plist)
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	unit := SystemdUnit(testConfig())
+	for _, want := range []string{
+		`ExecStart="/Users/dev/Hyper Apps/hyper" --mode=http --profile=work`,
+		"WorkingDirectory=/Users/dev/Hyper Apps",
+		"Restart=on-failure",
+		"RestartSec=5",
+		"StandardOutput=append:/Users/dev/Library/Logs/Hyperion/hyper.log",
+		"StandardError=append:/Users/dev/Library/Logs/Hyperion/hyper.log",
+		"WantedBy=default.target",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit missing %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := testConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+
+	relative := cfg
+	relative.Executable = "hyper"
+	if err := relative.Validate(); err == nil {
+		t.Error("expected relative executable to be rejected")
+	}
+
+	noLog := cfg
+	noLog.LogFile = ""
+	if err := noLog.Validate(); err == nil {
+		t.Error("expected missing log file to be rejected")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"hyper/internal/mcp/storage"
 	"hyper/internal/models"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -24,8 +25,8 @@ type AISettingsService struct {
 // NewAISettingsService creates a new AI settings service instance
 func NewAISettingsService(db *mongo.Database, logger *zap.Logger) (*AISettingsService, error) {
 	service := &AISettingsService{
-		systemPromptsCollection: db.Collection("system_prompts"),
-		subagentsCollection:     db.Collection("subagents"),
+		systemPromptsCollection: db.Collection(storage.CollectionName("system_prompts")),
+		subagentsCollection:     db.Collection(storage.CollectionName("subagents")),
 		logger:                  logger,
 	}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"hyper/internal/mcp/storage"
 	"hyper/internal/models"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -24,8 +25,8 @@ type ChatService struct {
 // NewChatService creates a new chat service instance
 func NewChatService(db *mongo.Database, logger *zap.Logger) (*ChatService, error) {
 	service := &ChatService{
-		sessionsCollection: db.Collection("chat_sessions"),
-		messagesCollection: db.Collection("chat_messages"),
+		sessionsCollection: db.Collection(storage.CollectionName("chat_sessions")),
+		messagesCollection: db.Collection(storage.CollectionName("chat_messages")),
 		logger:             logger,
 	}
 
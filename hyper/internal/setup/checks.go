@@ -0,0 +1,154 @@
+package setup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// checkTimeout bounds each connectivity check
+const checkTimeout = 5 * time.Second
+
+// Check is the outcome of one connectivity check
+type Check struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// Checker verifies that the services named in a configuration are reachable
+type Checker struct {
+	httpClient *http.Client
+	pingMongo  func(ctx context.Context, uri string) error
+}
+
+// NewChecker creates a checker that contacts the real services
+func NewChecker() *Checker {
+	return &Checker{
+		httpClient: &http.Client{Timeout: checkTimeout},
+		pingMongo:  pingMongo,
+	}
+}
+
+// CheckAll runs every connectivity check for cfg
+func (c *Checker) CheckAll(ctx context.Context, cfg *Config) []Check {
+	return []Check{
+		c.checkMongo(ctx, cfg),
+		c.checkQdrant(ctx, cfg),
+		c.checkEmbedding(ctx, cfg),
+	}
+}
+
+// AllOK reports whether every check passed
+func AllOK(checks []Check) bool {
+	for _, check := range checks {
+		if !check.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// pingMongo connects to MongoDB and pings the server
+func pingMongo(ctx context.Context, uri string) error {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetServerSelectionTimeout(checkTimeout))
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	return client.Ping(ctx, nil)
+}
+
+func (c *Checker) checkMongo(ctx context.Context, cfg *Config) Check {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	if err := c.pingMongo(ctx, cfg.MongoURI); err != nil {
+		return Check{Name: "mongodb", Message: fmt.Sprintf("cannot reach MongoDB: %v", err)}
+	}
+	return Check{Name: "mongodb", OK: true, Message: fmt.Sprintf("connected (database %s)", cfg.MongoDatabase)}
+}
+
+func (c *Checker) checkQdrant(ctx context.Context, cfg *Config) Check {
+	headers := map[string]string{}
+	if cfg.QdrantAPIKey != "" {
+		headers["api-key"] = cfg.QdrantAPIKey
+	}
+	if _, err := c.get(ctx, strings.TrimRight(cfg.QdrantURL, "/")+"/", headers); err != nil {
+		return Check{Name: "qdrant", Message: fmt.Sprintf("cannot reach Qdrant: %v", err)}
+	}
+	return Check{Name: "qdrant", OK: true, Message: "connected"}
+}
+
+func (c *Checker) checkEmbedding(ctx context.Context, cfg *Config) Check {
+	switch cfg.Embedding {
+	case EmbeddingOllama:
+		body, err := c.get(ctx, strings.TrimRight(cfg.OllamaURL, "/")+"/api/tags", nil)
+		if err != nil {
+			return Check{Name: "embedding", Message: fmt.Sprintf("cannot reach Ollama: %v", err)}
+		}
+		var tags struct {
+			Models []struct {
+				Name string `json:"name"`
+			} `json:"models"`
+		}
+		if err := json.Unmarshal(body, &tags); err != nil {
+			return Check{Name: "embedding", Message: fmt.Sprintf("unexpected Ollama response: %v", err)}
+		}
+		for _, model := range tags.Models {
+			if model.Name == cfg.OllamaModel || strings.HasPrefix(model.Name, cfg.OllamaModel+":") {
+				return Check{Name: "embedding", OK: true, Message: fmt.Sprintf("Ollama model %s available", cfg.OllamaModel)}
+			}
+		}
+		return Check{Name: "embedding", Message: fmt.Sprintf("Ollama is running but model %s is not pulled: run `ollama pull %s`", cfg.OllamaModel, cfg.OllamaModel)}
+
+	case EmbeddingLocal:
+		if _, err := c.get(ctx, strings.TrimRight(cfg.TEIURL, "/")+"/health", nil); err != nil {
+			return Check{Name: "embedding", Message: fmt.Sprintf("cannot reach TEI embedding service: %v", err)}
+		}
+		return Check{Name: "embedding", OK: true, Message: "TEI embedding service healthy"}
+
+	case EmbeddingOpenAI:
+		if _, err := c.get(ctx, "https://api.openai.com/v1/models", map[string]string{"Authorization": "Bearer " + cfg.OpenAIAPIKey}); err != nil {
+			return Check{Name: "embedding", Message: fmt.Sprintf("OpenAI API key rejected or API unreachable: %v", err)}
+		}
+		return Check{Name: "embedding", OK: true, Message: "OpenAI API key accepted"}
+
+	case EmbeddingVoyage:
+		// Voyage has no free endpoint to verify a key; it is checked on first use
+		return Check{Name: "embedding", OK: true, Message: "Voyage API key set (verified on first embedding request)"}
+	}
+	return Check{Name: "embedding", Message: fmt.Sprintf("unsupported embedding provider %q", cfg.Embedding)}
+}
+
+// get performs a GET request and returns the body of a 200 response
+func (c *Checker) get(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
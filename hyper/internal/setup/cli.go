@@ -0,0 +1,122 @@
+package setup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// prompter reads answers to interactive questions
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints a question with its default and returns the answer or the default
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// confirm asks a yes/no question, defaulting to no
+func (p *prompter) confirm(question string) (bool, error) {
+	answer, err := p.ask(question+" (yes/no)", "no")
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "yes" || answer == "y", nil
+}
+
+// RunInit interactively collects the configuration, validates connectivity
+// and writes it to envPath. It implements the `hyper init` command.
+func RunInit(ctx context.Context, in io.Reader, out io.Writer, envPath string, checker *Checker) error {
+	p := &prompter{in: bufio.NewReader(in), out: out}
+
+	fmt.Fprintf(out, "Hyperion coordinator setup\n━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Fprintf(out, "Writes %s. Press Enter to accept the default shown in brackets.\n\n", envPath)
+
+	if _, err := os.Stat(envPath); err == nil {
+		overwrite, err := p.confirm(fmt.Sprintf("%s already exists. Overwrite it?", envPath))
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			return fmt.Errorf("setup cancelled: existing configuration kept")
+		}
+	}
+
+	cfg := Defaults()
+	questions := []struct {
+		label string
+		field *string
+		when  func() bool
+	}{
+		{"MongoDB URI", &cfg.MongoURI, nil},
+		{"MongoDB database", &cfg.MongoDatabase, nil},
+		{"Qdrant URL", &cfg.QdrantURL, nil},
+		{"Qdrant API key (empty for none)", &cfg.QdrantAPIKey, nil},
+		{"Embedding provider (ollama, local, openai, voyage)", &cfg.Embedding, nil},
+		{"Ollama URL", &cfg.OllamaURL, func() bool { return cfg.Embedding == EmbeddingOllama }},
+		{"Ollama model", &cfg.OllamaModel, func() bool { return cfg.Embedding == EmbeddingOllama }},
+		{"TEI URL", &cfg.TEIURL, func() bool { return cfg.Embedding == EmbeddingLocal }},
+		{"OpenAI API key", &cfg.OpenAIAPIKey, func() bool { return cfg.Embedding == EmbeddingOpenAI }},
+		{"Voyage API key", &cfg.VoyageAPIKey, func() bool { return cfg.Embedding == EmbeddingVoyage }},
+		{"HTTP port", &cfg.HTTPPort, nil},
+	}
+	for _, q := range questions {
+		if q.when != nil && !q.when() {
+			continue
+		}
+		answer, err := p.ask(q.label, *q.field)
+		if err != nil {
+			return err
+		}
+		*q.field = answer
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	fmt.Fprintf(out, "\nChecking connectivity...\n")
+	checks := checker.CheckAll(ctx, &cfg)
+	for _, check := range checks {
+		mark := "✓"
+		if !check.OK {
+			mark = "✗"
+		}
+		fmt.Fprintf(out, "  %s %-10s %s\n", mark, check.Name, check.Message)
+	}
+
+	if !AllOK(checks) {
+		write, err := p.confirm("\nSome checks failed. Write the configuration anyway?")
+		if err != nil {
+			return err
+		}
+		if !write {
+			return fmt.Errorf("setup cancelled: fix the failing services and run `hyper init` again")
+		}
+	}
+
+	if err := WriteEnvFile(envPath, &cfg); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\n✅ Configuration written to %s\nStart the coordinator with: hyper\n", envPath)
+	return nil
+}
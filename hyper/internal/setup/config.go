@@ -0,0 +1,234 @@
+// Package setup implements the first-run configuration flow: it collects the
+// MongoDB, Qdrant and embedding settings, validates connectivity and writes
+// .env.hyper. It is driven either over HTTP (while no configuration exists) or
+// by the `hyper init` command.
+package setup
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// EnvFileName is the configuration file loaded at startup
+const EnvFileName = ".env.hyper"
+
+// EnvFileNameFor returns the configuration file of a profile, for example
+// .env.hyper.dev. An empty profile selects the default EnvFileName.
+func EnvFileNameFor(profile string) string {
+	if profile == "" {
+		return EnvFileName
+	}
+	return EnvFileName + "." + profile
+}
+
+// Supported embedding providers
+const (
+	EmbeddingOllama = "ollama"
+	EmbeddingLocal  = "local"
+	EmbeddingOpenAI = "openai"
+	EmbeddingVoyage = "voyage"
+)
+
+// Config holds the settings collected by the setup flow
+type Config struct {
+	MongoURI      string `json:"mongoUri"`
+	MongoDatabase string `json:"mongoDatabase"`
+	QdrantURL     string `json:"qdrantUrl"`
+	QdrantAPIKey  string `json:"qdrantApiKey,omitempty"`
+	Embedding     string `json:"embedding"`
+	OllamaURL     string `json:"ollamaUrl,omitempty"`
+	OllamaModel   string `json:"ollamaModel,omitempty"`
+	TEIURL        string `json:"teiUrl,omitempty"`
+	OpenAIAPIKey  string `json:"openaiApiKey,omitempty"`
+	VoyageAPIKey  string `json:"voyageApiKey,omitempty"`
+	HTTPPort      string `json:"httpPort,omitempty"`
+}
+
+// Defaults returns a configuration pre-filled with the values the coordinator
+// assumes when a variable is unset
+func Defaults() Config {
+	return Config{
+		MongoURI:      "mongodb://localhost:27017",
+		MongoDatabase: "coordinator_db1",
+		QdrantURL:     "http://localhost:6333",
+		Embedding:     EmbeddingOllama,
+		OllamaURL:     "http://localhost:11434",
+		OllamaModel:   "nomic-embed-text",
+		TEIURL:        "http://localhost:8080",
+		HTTPPort:      "7095",
+	}
+}
+
+// applyDefaults fills provider settings left empty with their defaults
+func (c *Config) applyDefaults() {
+	d := Defaults()
+	c.Embedding = strings.ToLower(strings.TrimSpace(c.Embedding))
+	if c.MongoDatabase == "" {
+		c.MongoDatabase = d.MongoDatabase
+	}
+	if c.Embedding == "" {
+		c.Embedding = d.Embedding
+	}
+	if c.Embedding == EmbeddingOllama && c.OllamaURL == "" {
+		c.OllamaURL = d.OllamaURL
+	}
+	if c.Embedding == EmbeddingOllama && c.OllamaModel == "" {
+		c.OllamaModel = d.OllamaModel
+	}
+	if c.Embedding == EmbeddingLocal && c.TEIURL == "" {
+		c.TEIURL = d.TEIURL
+	}
+	if c.HTTPPort == "" {
+		c.HTTPPort = d.HTTPPort
+	}
+}
+
+// fields returns the settings by their JSON name
+func (c *Config) fields() [][2]string {
+	return [][2]string{
+		{"mongoUri", c.MongoURI},
+		{"mongoDatabase", c.MongoDatabase},
+		{"qdrantUrl", c.QdrantURL},
+		{"qdrantApiKey", c.QdrantAPIKey},
+		{"embedding", c.Embedding},
+		{"ollamaUrl", c.OllamaURL},
+		{"ollamaModel", c.OllamaModel},
+		{"teiUrl", c.TEIURL},
+		{"openaiApiKey", c.OpenAIAPIKey},
+		{"voyageApiKey", c.VoyageAPIKey},
+		{"httpPort", c.HTTPPort},
+	}
+}
+
+// Validate fills defaults and checks that the configuration is complete.
+// Control characters are rejected in every setting: a newline would start a
+// new line in .env.hyper and let a value set other variables.
+func (c *Config) Validate() error {
+	c.applyDefaults()
+
+	for _, field := range c.fields() {
+		if strings.IndexFunc(field[1], unicode.IsControl) >= 0 {
+			return fmt.Errorf("%s must not contain control characters", field[0])
+		}
+	}
+
+	if !strings.HasPrefix(c.MongoURI, "mongodb://") && !strings.HasPrefix(c.MongoURI, "mongodb+srv://") {
+		return fmt.Errorf("mongoUri must start with mongodb:// or mongodb+srv://")
+	}
+	if err := validateHTTPURL("qdrantUrl", c.QdrantURL); err != nil {
+		return err
+	}
+
+	switch c.Embedding {
+	case EmbeddingOllama:
+		if err := validateHTTPURL("ollamaUrl", c.OllamaURL); err != nil {
+			return err
+		}
+	case EmbeddingLocal:
+		if err := validateHTTPURL("teiUrl", c.TEIURL); err != nil {
+			return err
+		}
+	case EmbeddingOpenAI:
+		if c.OpenAIAPIKey == "" {
+			return fmt.Errorf("openaiApiKey is required when embedding is openai")
+		}
+	case EmbeddingVoyage:
+		if c.VoyageAPIKey == "" {
+			return fmt.Errorf("voyageApiKey is required when embedding is voyage")
+		}
+	default:
+		return fmt.Errorf("embedding must be one of ollama, local, openai or voyage")
+	}
+
+	for _, r := range c.HTTPPort {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("httpPort must be a number")
+		}
+	}
+	return nil
+}
+
+// validateHTTPURL checks that value is an absolute http(s) URL
+func validateHTTPURL(field, value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s must be an http:// or https:// URL", field)
+	}
+	return nil
+}
+
+// Render returns the .env.hyper contents for the configuration. Only the
+// settings of the selected embedding provider are written.
+func (c *Config) Render() string {
+	var b strings.Builder
+	b.WriteString("# Hyperion coordinator configuration (generated by setup)\n\n")
+
+	b.WriteString("# MongoDB\n")
+	writeEnv(&b, "MONGODB_URI", c.MongoURI)
+	writeEnv(&b, "MONGODB_DATABASE", c.MongoDatabase)
+
+	b.WriteString("\n# Qdrant\n")
+	writeEnv(&b, "QDRANT_URL", c.QdrantURL)
+	if c.QdrantAPIKey != "" {
+		writeEnv(&b, "QDRANT_API_KEY", c.QdrantAPIKey)
+	}
+
+	b.WriteString("\n# Embeddings\n")
+	writeEnv(&b, "EMBEDDING", c.Embedding)
+	switch c.Embedding {
+	case EmbeddingOllama:
+		writeEnv(&b, "OLLAMA_URL", c.OllamaURL)
+		writeEnv(&b, "OLLAMA_MODEL", c.OllamaModel)
+	case EmbeddingLocal:
+		writeEnv(&b, "TEI_URL", c.TEIURL)
+	case EmbeddingOpenAI:
+		writeEnv(&b, "OPENAI_API_KEY", c.OpenAIAPIKey)
+	case EmbeddingVoyage:
+		writeEnv(&b, "VOYAGE_API_KEY", c.VoyageAPIKey)
+	}
+
+	b.WriteString("\n# HTTP server\n")
+	writeEnv(&b, "HTTP_PORT", c.HTTPPort)
+	return b.String()
+}
+
+// writeEnv writes one KEY=value line, quoting values that need it. Line
+// breaks are escaped so that a value always stays on its own line, even if it
+// bypassed Validate.
+func writeEnv(b *strings.Builder, key, value string) {
+	if strings.ContainsAny(value, " #\"'\\$\t\r\n") {
+		value = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\r", `\r`, "\n", `\n`).Replace(value) + `"`
+	}
+	fmt.Fprintf(b, "%s=%s\n", key, value)
+}
+
+// WriteEnvFile atomically writes the configuration to path, readable only by
+// the current user since it contains credentials
+func WriteEnvFile(path string, cfg *Config) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, EnvFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(cfg.Render()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set config file permissions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save config file: %w", err)
+	}
+	return nil
+}
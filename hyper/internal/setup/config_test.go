@@ -0,0 +1,113 @@
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string
+	}{
+		{name: "defaults are valid", modify: func(c *Config) {}},
+		{name: "bad mongo scheme", modify: func(c *Config) { c.MongoURI = "http://localhost:27017" }, wantErr: "mongoUri"},
+		{name: "bad qdrant url", modify: func(c *Config) { c.QdrantURL = "localhost:6333" }, wantErr: "qdrantUrl"},
+		{name: "unknown provider", modify: func(c *Config) { c.Embedding = "bert" }, wantErr: "embedding must be"},
+		{name: "openai needs key", modify: func(c *Config) { c.Embedding = EmbeddingOpenAI }, wantErr: "openaiApiKey"},
+		{name: "voyage needs key", modify: func(c *Config) { c.Embedding = EmbeddingVoyage }, wantErr: "voyageApiKey"},
+		{name: "non-numeric port", modify: func(c *Config) { c.HTTPPort = "80a" }, wantErr: "httpPort"},
+		{name: "newline in key", modify: func(c *Config) {
+			c.Embedding = EmbeddingOpenAI
+			c.OpenAIAPIKey = "sk\nAUTH_REQUIRED=false\nMCP_STDIO_ROLE=admin"
+		}, wantErr: "openaiApiKey must not contain control characters"},
+		{name: "carriage return in database", modify: func(c *Config) { c.MongoDatabase = "db\rX=1" }, wantErr: "mongoDatabase"},
+		{name: "control character in unused setting", modify: func(c *Config) { c.VoyageAPIKey = "k\x00" }, wantErr: "voyageApiKey"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestConfigValidateFillsDefaults(t *testing.T) {
+	cfg := Config{MongoURI: "mongodb://db:27017", QdrantURL: "http://qdrant:6333", Embedding: " Ollama "}
+	require.NoError(t, cfg.Validate())
+
+	assert.Equal(t, EmbeddingOllama, cfg.Embedding)
+	assert.Equal(t, "coordinator_db1", cfg.MongoDatabase)
+	assert.Equal(t, "http://localhost:11434", cfg.OllamaURL)
+	assert.Equal(t, "nomic-embed-text", cfg.OllamaModel)
+	assert.Equal(t, "7095", cfg.HTTPPort)
+}
+
+func TestConfigRender(t *testing.T) {
+	cfg := Defaults()
+	cfg.Embedding = EmbeddingOpenAI
+	cfg.OpenAIAPIKey = `sk-"quoted" $key`
+	require.NoError(t, cfg.Validate())
+
+	out := cfg.Render()
+	assert.Contains(t, out, "MONGODB_URI=mongodb://localhost:27017\n")
+	assert.Contains(t, out, "EMBEDDING=openai\n")
+	assert.Contains(t, out, `OPENAI_API_KEY="sk-\"quoted\" \$key"`)
+	assert.NotContains(t, out, "OLLAMA_URL", "only the selected provider is written")
+	assert.NotContains(t, out, "QDRANT_API_KEY", "empty optional settings are omitted")
+}
+
+func TestConfigRender_EscapesLineBreaks(t *testing.T) {
+	cfg := Defaults()
+	cfg.Embedding = EmbeddingOpenAI
+	cfg.OpenAIAPIKey = "sk\nAUTH_REQUIRED=false\r\nMCP_STDIO_ROLE=admin"
+
+	path := filepath.Join(t.TempDir(), EnvFileName)
+	require.NoError(t, os.WriteFile(path, []byte(cfg.Render()), 0o600))
+
+	env, _, err := ReadEnvFiles(path)
+	require.NoError(t, err)
+	assert.NotContains(t, env, "AUTH_REQUIRED")
+	assert.NotContains(t, env, "MCP_STDIO_ROLE")
+	assert.Equal(t, "openai", env["EMBEDDING"])
+	assert.Contains(t, env["OPENAI_API_KEY"], "AUTH_REQUIRED=false")
+}
+
+func TestWriteEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), EnvFileName)
+	cfg := Defaults()
+	require.NoError(t, cfg.Validate())
+
+	require.NoError(t, WriteEnvFile(path, &cfg))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Render(), string(data))
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.False(t, strings.HasSuffix(entry.Name(), ".tmp"), "temp file left behind: %s", entry.Name())
+	}
+}
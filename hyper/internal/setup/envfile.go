@@ -0,0 +1,181 @@
+package setup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// LocalEnvFile returns the overrides layered on a configuration file, for
+// example .env.hyper.local for .env.hyper. It is meant to stay out of version
+// control, so a shared base file can be committed while tokens stay local.
+func LocalEnvFile(path string) string {
+	return path + ".local"
+}
+
+// refMarker stands in for "${" while godotenv parses a file, so references
+// survive parsing and are expanded once every layer is merged
+const refMarker = "\uE000{"
+
+// ReadEnvFiles reads a configuration file and its local overrides, the
+// overrides winning, and expands ${VAR} references in the values. A
+// reference resolves to a variable of either file, then to the environment,
+// and to an empty string otherwise; $${VAR} keeps a literal ${VAR}. It
+// returns the files read and fails when neither exists.
+func ReadEnvFiles(path string) (map[string]string, []string, error) {
+	env, files, err := readEnvLayers(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	expanded, err := expandEnv(env, func(name string) (string, bool) { return "", false })
+	if err != nil {
+		return nil, nil, err
+	}
+	return expanded, files, nil
+}
+
+// LoadEnvFiles reads a configuration file and its local overrides like
+// ReadEnvFiles and sets the result in the environment. With override, the
+// files replace variables already set (as godotenv.Overload does); without
+// it, exported variables are kept and also win in references.
+func LoadEnvFiles(path string, override bool) ([]string, error) {
+	env, files, err := readEnvLayers(path)
+	if err != nil {
+		return nil, err
+	}
+	exported := func(name string) (string, bool) {
+		if override {
+			return "", false
+		}
+		return os.LookupEnv(name)
+	}
+	expanded, err := expandEnv(env, exported)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range expanded {
+		if err := os.Setenv(key, value); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	return files, nil
+}
+
+// readEnvLayers merges the unexpanded variables of path and its local
+// overrides
+func readEnvLayers(path string) (map[string]string, []string, error) {
+	env := map[string]string{}
+	var files []string
+	var firstErr error
+	for _, file := range []string{path, LocalEnvFile(path)} {
+		layer, err := readEnvFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for key, value := range layer {
+			env[key] = value
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, nil, firstErr
+	}
+	return env, files, nil
+}
+
+// readEnvFile parses one file, leaving ${VAR} references unexpanded
+func readEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	env, err := godotenv.UnmarshalBytes(bytes.ReplaceAll(data, []byte("${"), []byte(refMarker)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for key, value := range env {
+		env[key] = strings.ReplaceAll(value, refMarker, "${")
+	}
+	return env, nil
+}
+
+// expandEnv expands the ${VAR} references of env. exported returns the
+// variables that take precedence over env, which are left out of the result;
+// names found in neither resolve to the environment.
+func expandEnv(env map[string]string, exported func(string) (string, bool)) (map[string]string, error) {
+	expanded := make(map[string]string, len(env))
+	resolving := map[string]bool{}
+
+	var resolve func(name string) (string, error)
+	resolve = func(name string) (string, error) {
+		if value, ok := exported(name); ok {
+			return value, nil
+		}
+		if value, ok := expanded[name]; ok {
+			return value, nil
+		}
+		raw, ok := env[name]
+		if !ok {
+			return os.Getenv(name), nil
+		}
+		if resolving[name] {
+			return "", fmt.Errorf("${%s} refers to itself", name)
+		}
+		resolving[name] = true
+		value, err := expandRefs(raw, resolve)
+		if err != nil {
+			return "", err
+		}
+		delete(resolving, name)
+		expanded[name] = value
+		return value, nil
+	}
+
+	for name := range env {
+		if _, err := resolve(name); err != nil {
+			return nil, err
+		}
+	}
+	return expanded, nil
+}
+
+// expandRefs replaces the ${VAR} references of value. $${ is a literal ${
+// and a reference without a closing brace is kept as written.
+func expandRefs(value string, resolve func(string) (string, error)) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			b.WriteString(value)
+			return b.String(), nil
+		}
+		if start > 0 && value[start-1] == '$' {
+			b.WriteString(value[:start-1] + "${")
+			value = value[start+2:]
+			continue
+		}
+		end := strings.IndexByte(value[start:], '}')
+		if end < 0 {
+			b.WriteString(value)
+			return b.String(), nil
+		}
+		b.WriteString(value[:start])
+		resolved, err := resolve(value[start+2 : start+end])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(resolved)
+		value = value[start+end+1:]
+	}
+}
@@ -0,0 +1,70 @@
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, EnvFileName)
+	require.NoError(t, os.WriteFile(base, []byte(`MONGODB_URI=mongodb://${MONGO_USER}:${MONGO_PASSWORD}@db:27017
+MONGO_USER=hyper
+MONGO_PASSWORD=change-me
+QDRANT_URL="http://${QDRANT_HOST}:6333"
+CACHE_DIR=${HYPER_TEST_HOME}/cache
+TEMPLATE='$${NAME}'
+`), 0o600))
+	require.NoError(t, os.WriteFile(LocalEnvFile(base), []byte(`MONGO_PASSWORD=s3cret
+QDRANT_HOST=localhost
+`), 0o600))
+	t.Setenv("HYPER_TEST_HOME", "/home/dev")
+
+	env, files, err := ReadEnvFiles(base)
+	require.NoError(t, err)
+	assert.Equal(t, []string{base, base + ".local"}, files)
+	assert.Equal(t, "mongodb://hyper:s3cret@db:27017", env["MONGODB_URI"], "references see the overrides")
+	assert.Equal(t, "http://localhost:6333", env["QDRANT_URL"])
+	assert.Equal(t, "/home/dev/cache", env["CACHE_DIR"])
+	assert.Equal(t, "${NAME}", env["TEMPLATE"])
+
+	// The local file alone is enough
+	require.NoError(t, os.Remove(base))
+	env, files, err = ReadEnvFiles(base)
+	require.NoError(t, err)
+	assert.Equal(t, []string{base + ".local"}, files)
+	assert.Equal(t, "s3cret", env["MONGO_PASSWORD"])
+
+	_, _, err = ReadEnvFiles(filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestReadEnvFiles_Cycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), EnvFileName)
+	require.NoError(t, os.WriteFile(path, []byte("A=${B}\nB=x${A}\n"), 0o600))
+
+	_, _, err := ReadEnvFiles(path)
+	assert.ErrorContains(t, err, "refers to itself")
+}
+
+func TestLoadEnvFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), EnvFileName)
+	require.NoError(t, os.WriteFile(path, []byte("HYPER_TEST_HOST=file-host\nHYPER_TEST_URL=http://${HYPER_TEST_HOST}\n"), 0o600))
+
+	t.Setenv("HYPER_TEST_HOST", "exported-host")
+	t.Setenv("HYPER_TEST_URL", "")
+	os.Unsetenv("HYPER_TEST_URL")
+	_, err := LoadEnvFiles(path, false)
+	require.NoError(t, err)
+	assert.Equal(t, "exported-host", os.Getenv("HYPER_TEST_HOST"), "exported variables win")
+	assert.Equal(t, "http://exported-host", os.Getenv("HYPER_TEST_URL"))
+
+	_, err = LoadEnvFiles(path, true)
+	require.NoError(t, err)
+	assert.Equal(t, "file-host", os.Getenv("HYPER_TEST_HOST"))
+	assert.Equal(t, "http://file-host", os.Getenv("HYPER_TEST_URL"))
+}
@@ -0,0 +1,192 @@
+package setup
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"sync"
+
+	"hyper/internal/envelope"
+	"hyper/internal/errcode"
+	"hyper/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TokenHeader carries the setup token printed to the console. Without it, any
+// web page the user opens could post a configuration to the setup server.
+const TokenHeader = "X-Setup-Token"
+
+// Handler serves the setup REST endpoints. It is only mounted while the
+// coordinator runs without configuration.
+type Handler struct {
+	envPath   string
+	origin    string // Origin of the setup server, the only one browsers may post from
+	token     string
+	checker   *Checker
+	onApplied func()
+	logger    *zap.Logger
+
+	mu      sync.Mutex
+	applied bool
+}
+
+// NewHandler creates a setup handler served at origin (e.g.
+// http://127.0.0.1:7095) that writes envPath and then calls onApplied
+// (typically a process restart). It generates the token that POST requests
+// must carry; see Token.
+func NewHandler(envPath, origin string, checker *Checker, onApplied func(), logger *zap.Logger) *Handler {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		panic("setup: failed to generate token: " + err.Error())
+	}
+	return &Handler{
+		envPath:   envPath,
+		origin:    origin,
+		token:     hex.EncodeToString(random),
+		checker:   checker,
+		onApplied: onApplied,
+		logger:    logger,
+	}
+}
+
+// Token returns the token POST requests must send in TokenHeader. It is
+// generated per run and meant to be printed to the console only.
+func (h *Handler) Token() string {
+	return h.token
+}
+
+// ApplyRequest is the body of POST /api/v1/setup/apply
+type ApplyRequest struct {
+	Config
+	Force bool `json:"force,omitempty"` // Write the config even if connectivity checks fail
+}
+
+// StatusResponse is the body of GET /api/v1/setup/status
+type StatusResponse struct {
+	SetupRequired bool     `json:"setupRequired"`
+	EnvPath       string   `json:"envPath"`
+	Defaults      Config   `json:"defaults"`
+	Embeddings    []string `json:"embeddings"`
+}
+
+// ValidateResponse is the body of POST /api/v1/setup/validate
+type ValidateResponse struct {
+	Valid  bool    `json:"valid"`
+	Checks []Check `json:"checks"`
+}
+
+// ApplyResponse is the body of POST /api/v1/setup/apply
+type ApplyResponse struct {
+	EnvPath    string  `json:"envPath"`
+	Checks     []Check `json:"checks"`
+	Restarting bool    `json:"restarting"`
+}
+
+// RegisterRoutes mounts the setup endpoints
+func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	setup := r.Group("/api/v1/setup")
+	{
+		setup.GET("/status", h.Status)
+		setup.POST("/validate", h.guard, h.Validate)
+		setup.POST("/apply", h.guard, h.Apply)
+	}
+}
+
+// guard rejects cross-site requests: the body must be JSON, which browsers
+// cannot send cross-origin without a preflight, a browser Origin must be the
+// setup server's own, and the setup token must match
+func (h *Handler) guard(c *gin.Context) {
+	if c.ContentType() != "application/json" {
+		errcode.RespondCode(c, errcode.Validation, "Content-Type must be application/json")
+		c.Abort()
+		return
+	}
+	if origin := c.GetHeader("Origin"); origin != "" && origin != h.origin {
+		errcode.RespondCode(c, errcode.PermissionDenied, "Cross-origin setup requests are not allowed")
+		c.Abort()
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader(TokenHeader)), []byte(h.token)) != 1 {
+		errcode.RespondCode(c, errcode.Unauthenticated, "Missing or invalid "+TokenHeader+" header; the token is printed in the coordinator console")
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// Status reports whether setup is still required and the default values
+// GET /api/v1/setup/status
+func (h *Handler) Status(c *gin.Context) {
+	h.mu.Lock()
+	applied := h.applied
+	h.mu.Unlock()
+
+	envelope.OK(c, StatusResponse{
+		SetupRequired: !applied,
+		EnvPath:       h.envPath,
+		Defaults:      Defaults(),
+		Embeddings:    []string{EmbeddingOllama, EmbeddingLocal, EmbeddingOpenAI, EmbeddingVoyage},
+	})
+}
+
+// Validate checks a configuration and the connectivity of its services without saving it
+// POST /api/v1/setup/validate
+func (h *Handler) Validate(c *gin.Context) {
+	var cfg Config
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		validation.RespondBinding(c, err, &cfg)
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		errcode.RespondCode(c, errcode.Validation, err.Error())
+		return
+	}
+
+	checks := h.checker.CheckAll(c.Request.Context(), &cfg)
+	envelope.OK(c, ValidateResponse{Valid: AllOK(checks), Checks: checks})
+}
+
+// Apply validates a configuration, writes it to the env file and restarts the coordinator
+// POST /api/v1/setup/apply
+func (h *Handler) Apply(c *gin.Context) {
+	var req ApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondBinding(c, err, &req)
+		return
+	}
+	cfg := req.Config
+	if err := cfg.Validate(); err != nil {
+		errcode.RespondCode(c, errcode.Validation, err.Error())
+		return
+	}
+
+	checks := h.checker.CheckAll(c.Request.Context(), &cfg)
+	if !AllOK(checks) && !req.Force {
+		errcode.RespondDetails(c, errcode.DependencyUnavailable, "Connectivity checks failed; fix the configuration or pass force=true", gin.H{
+			"checks": checks,
+		})
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.applied {
+		errcode.RespondCode(c, errcode.Conflict, "Configuration was already written; the coordinator is restarting")
+		return
+	}
+	if err := WriteEnvFile(h.envPath, &cfg); err != nil {
+		errcode.RespondCode(c, errcode.Internal, err.Error())
+		return
+	}
+	h.applied = true
+
+	h.logger.Info("Setup wrote configuration", zap.String("path", h.envPath), zap.Bool("forced", req.Force))
+	envelope.JSON(c, http.StatusAccepted, ApplyResponse{EnvPath: h.envPath, Checks: checks, Restarting: h.onApplied != nil})
+
+	if h.onApplied != nil {
+		go h.onApplied()
+	}
+}
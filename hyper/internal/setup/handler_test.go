@@ -0,0 +1,158 @@
+package setup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestChecker returns a checker backed by a fake Qdrant/Ollama server
+func newTestChecker(t *testing.T, mongoErr error) (*Checker, string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			w.Write([]byte(`{"models":[{"name":"nomic-embed-text:latest"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	return &Checker{
+		httpClient: srv.Client(),
+		pingMongo:  func(ctx context.Context, uri string) error { return mongoErr },
+	}, srv.URL
+}
+
+func setupRouter(h *Handler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h.RegisterRoutes(r)
+	return r
+}
+
+const testOrigin = "http://127.0.0.1:7095"
+
+func postJSON(r *gin.Engine, h *Handler, path string, body interface{}) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TokenHeader, h.Token())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandlerValidate(t *testing.T) {
+	checker, url := newTestChecker(t, nil)
+	h := NewHandler(filepath.Join(t.TempDir(), EnvFileName), testOrigin, checker, nil, zap.NewNop())
+	r := setupRouter(h)
+
+	t.Run("reachable services", func(t *testing.T) {
+		w := postJSON(r, h, "/api/v1/setup/validate", Config{MongoURI: "mongodb://db", QdrantURL: url, OllamaURL: url})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data ValidateResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.Data.Valid)
+		assert.Len(t, resp.Data.Checks, 3)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		w := postJSON(r, h, "/api/v1/setup/validate", Config{MongoURI: "localhost", QdrantURL: url})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "mongoUri")
+	})
+}
+
+func TestHandlerApply(t *testing.T) {
+	t.Run("writes config and restarts", func(t *testing.T) {
+		checker, url := newTestChecker(t, nil)
+		envPath := filepath.Join(t.TempDir(), EnvFileName)
+		restarted := make(chan struct{})
+		h := NewHandler(envPath, testOrigin, checker, func() { close(restarted) }, zap.NewNop())
+		r := setupRouter(h)
+
+		w := postJSON(r, h, "/api/v1/setup/apply", ApplyRequest{Config: Config{MongoURI: "mongodb://db", QdrantURL: url, OllamaURL: url}})
+		require.Equal(t, http.StatusAccepted, w.Code)
+		<-restarted
+
+		data, err := os.ReadFile(envPath)
+		require.NoError(t, err)
+		assert.True(t, strings.Contains(string(data), "MONGODB_URI=mongodb://db\n"))
+
+		// A second apply is rejected while the coordinator restarts
+		w = postJSON(r, h, "/api/v1/setup/apply", ApplyRequest{Config: Config{MongoURI: "mongodb://db", QdrantURL: url, OllamaURL: url}})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("failing checks block apply unless forced", func(t *testing.T) {
+		checker, url := newTestChecker(t, errors.New("connection refused"))
+		envPath := filepath.Join(t.TempDir(), EnvFileName)
+		h := NewHandler(envPath, testOrigin, checker, nil, zap.NewNop())
+		r := setupRouter(h)
+		cfg := Config{MongoURI: "mongodb://db", QdrantURL: url, OllamaURL: url}
+
+		w := postJSON(r, h, "/api/v1/setup/apply", ApplyRequest{Config: cfg})
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "connection refused")
+		_, err := os.Stat(envPath)
+		assert.True(t, os.IsNotExist(err))
+
+		w = postJSON(r, h, "/api/v1/setup/apply", ApplyRequest{Config: cfg, Force: true})
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		_, err = os.Stat(envPath)
+		assert.NoError(t, err)
+	})
+}
+
+func TestHandlerRejectsCrossSiteRequests(t *testing.T) {
+	checker, url := newTestChecker(t, nil)
+	envPath := filepath.Join(t.TempDir(), EnvFileName)
+	h := NewHandler(envPath, testOrigin, checker, nil, zap.NewNop())
+	r := setupRouter(h)
+	body := `{"mongoUri":"mongodb://db","qdrantUrl":"` + url + `","ollamaUrl":"` + url + `"}`
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{name: "form content type", header: http.Header{"Content-Type": {"text/plain"}, TokenHeader: {h.Token()}}, want: http.StatusBadRequest},
+		{name: "foreign origin", header: http.Header{"Content-Type": {"application/json"}, "Origin": {"https://evil.example"}, TokenHeader: {h.Token()}}, want: http.StatusForbidden},
+		{name: "missing token", header: http.Header{"Content-Type": {"application/json"}}, want: http.StatusUnauthorized},
+		{name: "wrong token", header: http.Header{"Content-Type": {"application/json"}, TokenHeader: {"guess"}}, want: http.StatusUnauthorized},
+		{name: "own origin with token", header: http.Header{"Content-Type": {"application/json"}, "Origin": {testOrigin}, TokenHeader: {h.Token()}}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/setup/validate", strings.NewReader(body))
+			req.Header = tt.header
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/setup/apply", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	_, err := os.Stat(envPath)
+	assert.True(t, os.IsNotExist(err), "apply without the token must not write the config")
+}
@@ -0,0 +1,54 @@
+package share
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows each key, such as a client IP, a fixed number of requests
+// per window
+type Limiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	counts    map[string]*windowCount
+	nextSweep time.Time
+}
+
+type windowCount struct {
+	start time.Time
+	count int
+}
+
+// NewLimiter creates a limiter allowing limit requests per window and key
+func NewLimiter(limit int, window time.Duration) *Limiter {
+	return &Limiter{limit: limit, window: window, counts: map[string]*windowCount{}}
+}
+
+// Allow counts a request for key at now. When the key is over its limit it
+// returns false and how long until its window resets.
+func (l *Limiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Forget keys whose window has passed, so one-off clients do not pile up
+	if !now.Before(l.nextSweep) {
+		for k, w := range l.counts {
+			if !now.Before(w.start.Add(l.window)) {
+				delete(l.counts, k)
+			}
+		}
+		l.nextSweep = now.Add(l.window)
+	}
+
+	w, ok := l.counts[key]
+	if !ok || !now.Before(w.start.Add(l.window)) {
+		w = &windowCount{start: now}
+		l.counts[key] = w
+	}
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
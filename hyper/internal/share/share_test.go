@@ -0,0 +1,81 @@
+package share
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	token := signer.Token("link-1", now.Add(time.Hour))
+
+	id, err := signer.Verify(token, now)
+	require.NoError(t, err)
+	assert.Equal(t, "link-1", id)
+
+	_, err = signer.Verify(token, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrExpiredToken)
+
+	_, err = NewSigner([]byte("other")).Verify(token, now)
+	assert.ErrorIs(t, err, ErrInvalidToken, "signed with another secret")
+
+	// Extending the expiry invalidates the signature
+	forged := strings.Replace(token, ".", ".9", 1)
+	_, err = signer.Verify(forged, now)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	for _, bad := range []string{"", "link-1", "link-1.x." + signer.sign("link-1.x")} {
+		_, err = signer.Verify(bad, now)
+		assert.ErrorIs(t, err, ErrInvalidToken, bad)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(2, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		ok, _ := limiter.Allow("1.2.3.4", now)
+		assert.True(t, ok)
+	}
+	ok, retryAfter := limiter.Allow("1.2.3.4", now.Add(15*time.Second))
+	assert.False(t, ok)
+	assert.Equal(t, 45*time.Second, retryAfter)
+
+	ok, _ = limiter.Allow("5.6.7.8", now)
+	assert.True(t, ok, "keys are limited separately")
+
+	ok, _ = limiter.Allow("1.2.3.4", now.Add(time.Minute))
+	assert.True(t, ok, "the window resets")
+	assert.Len(t, limiter.counts, 1, "expired keys are swept")
+}
+
+func TestNewTaskView(t *testing.T) {
+	human := &storage.HumanTask{ID: "h-1", Prompt: "Ship <checkout>", Project: "web", Status: storage.TaskStatusInProgress, Notes: "internal"}
+	agents := []*storage.AgentTask{
+		{ID: "a-2", HumanTaskID: "h-1", AgentName: "frontend", Status: storage.TaskStatusPending, CreatedAt: now,
+			Todos: []storage.TodoItem{{Description: "Form", Status: storage.TodoStatusPending}}},
+		{ID: "a-1", HumanTaskID: "h-1", AgentName: "backend", Status: storage.TaskStatusCompleted, CreatedAt: now.Add(-time.Hour), Notes: "secret notes",
+			Todos: []storage.TodoItem{{Description: "API", Status: storage.TodoStatusCompleted}, {Description: "Tests", Status: storage.TodoStatusCompleted}}},
+		{ID: "a-3", HumanTaskID: "h-2", AgentName: "docs"},
+	}
+
+	view := NewTaskView(human, agents, now.Add(time.Hour), now)
+	require.Len(t, view.AgentTasks, 2)
+	assert.Equal(t, "backend", view.AgentTasks[0].AgentName, "oldest first")
+	assert.Equal(t, Progress{AgentTasks: 2, AgentTasksCompleted: 1, Todos: 3, TodosCompleted: 2, Percent: 66}, view.Progress)
+
+	page, err := RenderHTML(view)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "Ship &lt;checkout&gt;")
+	assert.Contains(t, string(page), "width:66%")
+	assert.NotContains(t, string(page), "secret notes")
+	assert.NotContains(t, string(page), "internal")
+}
@@ -0,0 +1,120 @@
+package share
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"sort"
+	"time"
+
+	"hyper/internal/mcp/storage"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var taskTemplate = template.Must(template.New("task.html").Funcs(template.FuncMap{
+	"datetime": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") },
+}).ParseFS(templateFS, "templates/task.html"))
+
+// TaskView is what a share link shows of a human task. Notes, context and
+// file paths stay private; only the request and its progress are shared.
+type TaskView struct {
+	Prompt      string          `json:"prompt"`
+	Project     string          `json:"project,omitempty"`
+	Status      string          `json:"status"`
+	Priority    string          `json:"priority,omitempty"`
+	DueAt       *time.Time      `json:"dueAt,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	Progress    Progress        `json:"progress"`
+	AgentTasks  []AgentTaskView `json:"agentTasks"`
+	ExpiresAt   time.Time       `json:"expiresAt"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+}
+
+// Progress counts completed work across the task's agent tasks
+type Progress struct {
+	AgentTasks          int `json:"agentTasks"`
+	AgentTasksCompleted int `json:"agentTasksCompleted"`
+	Todos               int `json:"todos"`
+	TodosCompleted      int `json:"todosCompleted"`
+	Percent             int `json:"percent"` // Share of TODOs completed
+}
+
+// AgentTaskView is the shared part of an agent task
+type AgentTaskView struct {
+	AgentName string     `json:"agentName"`
+	Role      string     `json:"role"`
+	Status    string     `json:"status"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Todos     []TodoView `json:"todos"`
+}
+
+// TodoView is the shared part of a TODO
+type TodoView struct {
+	Description string `json:"description"`
+	Status      string `json:"status"`
+}
+
+// NewTaskView builds the shared view of human and those of agents that belong
+// to it, for a link expiring at expiresAt
+func NewTaskView(human *storage.HumanTask, agents []*storage.AgentTask, expiresAt, now time.Time) *TaskView {
+	view := &TaskView{
+		Prompt:      human.Prompt,
+		Project:     human.Project,
+		Status:      string(human.Status),
+		Priority:    string(human.Priority),
+		DueAt:       human.DueAt,
+		CreatedAt:   human.CreatedAt,
+		UpdatedAt:   human.UpdatedAt,
+		AgentTasks:  []AgentTaskView{},
+		ExpiresAt:   expiresAt,
+		GeneratedAt: now,
+	}
+
+	own := []*storage.AgentTask{}
+	for _, agent := range agents {
+		if agent.HumanTaskID == human.ID {
+			own = append(own, agent)
+		}
+	}
+	sort.SliceStable(own, func(i, j int) bool { return own[i].CreatedAt.Before(own[j].CreatedAt) })
+
+	for _, agent := range own {
+		item := AgentTaskView{
+			AgentName: agent.AgentName,
+			Role:      agent.Role,
+			Status:    string(agent.Status),
+			UpdatedAt: agent.UpdatedAt,
+			Todos:     make([]TodoView, len(agent.Todos)),
+		}
+		for i, todo := range agent.Todos {
+			item.Todos[i] = TodoView{Description: todo.Description, Status: string(todo.Status)}
+			view.Progress.Todos++
+			if todo.Status == storage.TodoStatusCompleted {
+				view.Progress.TodosCompleted++
+			}
+		}
+		view.Progress.AgentTasks++
+		if agent.Status == storage.TaskStatusCompleted {
+			view.Progress.AgentTasksCompleted++
+		}
+		view.AgentTasks = append(view.AgentTasks, item)
+	}
+
+	if view.Progress.Todos > 0 {
+		view.Progress.Percent = view.Progress.TodosCompleted * 100 / view.Progress.Todos
+	}
+	return view
+}
+
+// RenderHTML renders the view as a standalone page
+func RenderHTML(view *TaskView) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := taskTemplate.Execute(&buf, view); err != nil {
+		return nil, fmt.Errorf("failed to render shared task: %w", err)
+	}
+	return buf.Bytes(), nil
+}
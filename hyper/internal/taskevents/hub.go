@@ -0,0 +1,143 @@
+// Package taskevents fans task changes out to live subscribers, such as the
+// dashboard and agents watching for assignments over /api/ws.
+package taskevents
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it
+// is dropped
+const subscriberBuffer = 256
+
+// Filter selects the events a subscriber receives. Empty fields match
+// everything; board_cleared always matches.
+type Filter struct {
+	Types       map[storage.TaskEventType]bool
+	TaskID      string
+	HumanTaskID string // The human task and its agent tasks
+	AgentName   string // Tasks of the agent, including ones assigned away from it
+}
+
+// ParseTypes reads a comma-separated list of event types; an empty list is nil
+func ParseTypes(list string) (map[storage.TaskEventType]bool, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	types := make(map[storage.TaskEventType]bool)
+	for _, name := range strings.Split(list, ",") {
+		eventType := storage.TaskEventType(strings.TrimSpace(name))
+		known := false
+		for _, t := range storage.TaskEventTypes {
+			known = known || t == eventType
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown event type %q", name)
+		}
+		types[eventType] = true
+	}
+	return types, nil
+}
+
+// Matches reports whether the filter selects event
+func (f Filter) Matches(event storage.TaskEvent) bool {
+	if event.Type == storage.TaskEventBoardCleared {
+		return true
+	}
+	if f.Types != nil && !f.Types[event.Type] {
+		return false
+	}
+	if f.TaskID != "" && event.TaskID != f.TaskID {
+		return false
+	}
+	if f.HumanTaskID != "" && event.TaskID != f.HumanTaskID && event.HumanTaskID != f.HumanTaskID {
+		return false
+	}
+	if f.AgentName != "" && event.AgentName != f.AgentName && event.PreviousAgentName != f.AgentName {
+		return false
+	}
+	return true
+}
+
+// Subscription receives the events matching its filter
+type Subscription struct {
+	filter Filter
+	events chan storage.TaskEvent
+}
+
+// Events is closed when the subscriber is dropped for falling behind, or
+// unsubscribed
+func (s *Subscription) Events() <-chan storage.TaskEvent {
+	return s.events
+}
+
+// Hub delivers published task events to subscribers. It implements
+// storage.TaskEventPublisher.
+type Hub struct {
+	logger *zap.Logger
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]bool
+}
+
+// NewHub creates a hub without subscribers
+func NewHub(logger *zap.Logger) *Hub {
+	return &Hub{logger: logger, subscribers: make(map[*Subscription]bool)}
+}
+
+// Subscribe starts delivering events matching filter
+func (h *Hub) Subscribe(filter Filter) *Subscription {
+	sub := &Subscription{filter: filter, events: make(chan storage.TaskEvent, subscriberBuffer)}
+	h.mu.Lock()
+	h.subscribers[sub] = true
+	h.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe stops delivery and closes the subscription's channel
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(sub)
+}
+
+// drop removes a subscriber; h.mu must be held
+func (h *Hub) drop(sub *Subscription) {
+	if h.subscribers[sub] {
+		delete(h.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// PublishTaskEvent delivers event to matching subscribers without waiting.
+// A subscriber whose buffer is full is dropped rather than missing events
+// silently; it reloads the board and subscribes again.
+func (h *Hub) PublishTaskEvent(event storage.TaskEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			h.logger.Warn("Dropped task event subscriber that fell behind", zap.Int("buffer", subscriberBuffer))
+			h.drop(sub)
+		}
+	}
+}
+
+// Subscribers returns how many subscribers are connected
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
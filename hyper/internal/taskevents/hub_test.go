@@ -0,0 +1,94 @@
+package taskevents
+
+import (
+	"testing"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFilterMatches(t *testing.T) {
+	created := storage.TaskEvent{Type: storage.TaskEventAgentTaskCreated, TaskID: "a-1", HumanTaskID: "h-1", AgentName: "backend"}
+	reassigned := storage.TaskEvent{Type: storage.TaskEventAgentTaskAssigned, TaskID: "a-1", HumanTaskID: "h-1", AgentName: "frontend", PreviousAgentName: "backend"}
+	human := storage.TaskEvent{Type: storage.TaskEventStatusChanged, TaskID: "h-1", Kind: "human"}
+	cleared := storage.TaskEvent{Type: storage.TaskEventBoardCleared}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		event  storage.TaskEvent
+		want   bool
+	}{
+		{"no filter", Filter{}, created, true},
+		{"type", Filter{Types: map[storage.TaskEventType]bool{storage.TaskEventAgentTaskAssigned: true}}, created, false},
+		{"agent", Filter{AgentName: "backend"}, created, true},
+		{"other agent", Filter{AgentName: "frontend"}, created, false},
+		{"assigned away", Filter{AgentName: "backend"}, reassigned, true},
+		{"human task's agent task", Filter{HumanTaskID: "h-1"}, created, true},
+		{"human task itself", Filter{HumanTaskID: "h-1"}, human, true},
+		{"other human task", Filter{HumanTaskID: "h-2"}, human, false},
+		{"task", Filter{TaskID: "h-1"}, created, false},
+		{"board cleared always", Filter{AgentName: "frontend", Types: map[storage.TaskEventType]bool{}}, cleared, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(tt.event))
+		})
+	}
+}
+
+func TestParseTypes(t *testing.T) {
+	types, err := ParseTypes("")
+	require.NoError(t, err)
+	assert.Nil(t, types)
+
+	types, err = ParseTypes("task_status_changed, todo_status_changed")
+	require.NoError(t, err)
+	assert.Equal(t, map[storage.TaskEventType]bool{storage.TaskEventStatusChanged: true, storage.TaskEventTodoStatusChanged: true}, types)
+
+	_, err = ParseTypes("task_deleted")
+	assert.Error(t, err)
+}
+
+func TestHub(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	backend := hub.Subscribe(Filter{AgentName: "backend"})
+	everyone := hub.Subscribe(Filter{})
+	assert.Equal(t, 2, hub.Subscribers())
+
+	hub.PublishTaskEvent(storage.TaskEvent{Type: storage.TaskEventHumanTaskCreated, TaskID: "h-1"})
+	hub.PublishTaskEvent(storage.TaskEvent{Type: storage.TaskEventAgentTaskCreated, TaskID: "a-1", AgentName: "backend"})
+
+	assert.Equal(t, "a-1", (<-backend.Events()).TaskID)
+	assert.Empty(t, backend.Events())
+	assert.Equal(t, "h-1", (<-everyone.Events()).TaskID)
+	assert.Equal(t, "a-1", (<-everyone.Events()).TaskID)
+
+	hub.Unsubscribe(backend)
+	hub.Unsubscribe(backend)
+	_, open := <-backend.Events()
+	assert.False(t, open)
+	assert.Equal(t, 1, hub.Subscribers())
+}
+
+func TestHub_DropsLaggingSubscriber(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	sub := hub.Subscribe(Filter{})
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		hub.PublishTaskEvent(storage.TaskEvent{Type: storage.TaskEventStatusChanged, TaskID: "h-1"})
+	}
+	assert.Equal(t, 0, hub.Subscribers())
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received, "buffered events are still delivered before the channel closes")
+
+	hub.Unsubscribe(sub)
+}
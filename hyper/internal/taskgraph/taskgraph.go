@@ -0,0 +1,277 @@
+// Package taskgraph renders multi-agent plans as diagrams: each human task
+// with the agent tasks it was split into, the dependencies between those,
+// and optionally their TODOs, as Mermaid or Graphviz DOT text for docs and
+// PR descriptions.
+package taskgraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"hyper/internal/mcp/storage"
+)
+
+// Format is a diagram language
+type Format string
+
+const (
+	Mermaid Format = "mermaid"
+	DOT     Format = "dot"
+)
+
+// ParseFormat reads a format name; empty means Mermaid
+func ParseFormat(raw string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "mermaid":
+		return Mermaid, nil
+	case "dot", "graphviz":
+		return DOT, nil
+	}
+	return "", fmt.Errorf("format must be mermaid or dot, not %q", raw)
+}
+
+// labelRunes bounds node labels, so prompts don't swamp the diagram
+const labelRunes = 60
+
+// Node kinds
+const (
+	KindHuman = "human"
+	KindAgent = "agent"
+	KindTodo  = "todo"
+)
+
+// Edge kinds: a human task assigns agent tasks, an agent task has TODOs, and
+// one agent task blocks another that depends on it
+const (
+	EdgeAssigns = "assigns"
+	EdgeTodo    = "todo"
+	EdgeBlocks  = "blocks"
+)
+
+// Node is a task or TODO in the graph
+type Node struct {
+	ID     string `json:"id"`     // Diagram identifier, e.g. h1 or a2
+	TaskID string `json:"taskId"` // Task or TODO UUID
+	Kind   string `json:"kind"`
+	Label  string `json:"label"`
+	Status string `json:"status"`
+}
+
+// Edge connects two nodes by diagram identifier
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Graph is a set of plans ready to render
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Options selects what Build includes
+type Options struct {
+	HumanTaskID  string // Only this human task's plan; empty includes every plan
+	IncludeTodos bool   // Add each agent task's TODOs
+}
+
+// Build lays out human tasks with their agent tasks, oldest first. An agent
+// task depends on another when its notes or prior work summary reference the
+// other's ID, as the hyperion://workflow/dependencies resource reads them.
+func Build(humans []*storage.HumanTask, agents []*storage.AgentTask, opts Options) (*Graph, error) {
+	humans = append([]*storage.HumanTask(nil), humans...)
+	sort.SliceStable(humans, func(i, j int) bool { return humans[i].CreatedAt.Before(humans[j].CreatedAt) })
+	agents = append([]*storage.AgentTask(nil), agents...)
+	sort.SliceStable(agents, func(i, j int) bool { return agents[i].CreatedAt.Before(agents[j].CreatedAt) })
+
+	byHuman := map[string][]*storage.AgentTask{}
+	for _, task := range agents {
+		byHuman[task.HumanTaskID] = append(byHuman[task.HumanTaskID], task)
+	}
+
+	graph := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	found := opts.HumanTaskID == ""
+	agentNodes := map[string]string{} // agent task ID to node ID
+	var included []*storage.AgentTask
+	humanCount, todoCount := 0, 0
+
+	for _, human := range humans {
+		if opts.HumanTaskID != "" && human.ID != opts.HumanTaskID {
+			continue
+		}
+		found = true
+		humanCount++
+		humanNode := fmt.Sprintf("h%d", humanCount)
+		graph.Nodes = append(graph.Nodes, Node{ID: humanNode, TaskID: human.ID, Kind: KindHuman, Label: shorten(human.Prompt), Status: string(human.Status)})
+
+		for _, agent := range byHuman[human.ID] {
+			agentNode := fmt.Sprintf("a%d", len(included)+1)
+			label := agent.Role
+			if agent.AgentName != "" {
+				label = agent.AgentName + ": " + agent.Role
+			}
+			graph.Nodes = append(graph.Nodes, Node{ID: agentNode, TaskID: agent.ID, Kind: KindAgent, Label: shorten(label), Status: string(agent.Status)})
+			graph.Edges = append(graph.Edges, Edge{From: humanNode, To: agentNode, Kind: EdgeAssigns})
+			agentNodes[agent.ID] = agentNode
+			included = append(included, agent)
+
+			if !opts.IncludeTodos {
+				continue
+			}
+			for _, todo := range agent.Todos {
+				todoCount++
+				todoNode := fmt.Sprintf("t%d", todoCount)
+				graph.Nodes = append(graph.Nodes, Node{ID: todoNode, TaskID: todo.ID, Kind: KindTodo, Label: shorten(todo.Description), Status: string(todo.Status)})
+				graph.Edges = append(graph.Edges, Edge{From: agentNode, To: todoNode, Kind: EdgeTodo})
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("human task with ID %s not found", opts.HumanTaskID)
+	}
+
+	for _, dependent := range included {
+		for _, dependency := range included {
+			if dependency.ID == dependent.ID {
+				continue
+			}
+			if DependsOn(dependent, dependency) {
+				graph.Edges = append(graph.Edges, Edge{From: agentNodes[dependency.ID], To: agentNodes[dependent.ID], Kind: EdgeBlocks})
+			}
+		}
+	}
+	return graph, nil
+}
+
+// Render writes the graph in a format
+func (g *Graph) Render(format Format) string {
+	if format == DOT {
+		return g.DOT()
+	}
+	return g.Mermaid()
+}
+
+// statusColors fill nodes by status in both formats
+var statusColors = map[string]string{
+	string(storage.TaskStatusPending):    "#eeeeee",
+	string(storage.TaskStatusInProgress): "#cfe2ff",
+	string(storage.TaskStatusCompleted):  "#d1e7dd",
+	string(storage.TaskStatusBlocked):    "#f8d7da",
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for _, node := range g.Nodes {
+		label := strings.ReplaceAll(node.Label, `"`, "#quot;")
+		if node.Status != "" {
+			label += "<br/><i>" + strings.ReplaceAll(node.Status, "_", " ") + "</i>"
+		}
+		left, right := "[", "]"
+		switch node.Kind {
+		case KindHuman:
+			left, right = "[[", "]]"
+		case KindTodo:
+			left, right = "(", ")"
+		}
+		fmt.Fprintf(&b, "    %s%s\"%s\"%s", node.ID, left, label, right)
+		if _, ok := statusColors[node.Status]; ok {
+			b.WriteString(":::" + node.Status)
+		}
+		b.WriteString("\n")
+	}
+	for _, edge := range g.Edges {
+		switch edge.Kind {
+		case EdgeBlocks:
+			fmt.Fprintf(&b, "    %s -. blocks .-> %s\n", edge.From, edge.To)
+		case EdgeTodo:
+			fmt.Fprintf(&b, "    %s --- %s\n", edge.From, edge.To)
+		default:
+			fmt.Fprintf(&b, "    %s --> %s\n", edge.From, edge.To)
+		}
+	}
+	for _, status := range sortedStatuses() {
+		fmt.Fprintf(&b, "    classDef %s fill:%s\n", status, statusColors[status])
+	}
+	return b.String()
+}
+
+// DOT renders the graph as a Graphviz digraph
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph tasks {\n")
+	b.WriteString("    node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\", fontname=\"Helvetica\"];\n")
+	for _, node := range g.Nodes {
+		label := dotEscape(node.Label)
+		if node.Status != "" {
+			label += `\n(` + strings.ReplaceAll(node.Status, "_", " ") + ")"
+		}
+		attrs := []string{fmt.Sprintf("label=\"%s\"", label)}
+		if color, ok := statusColors[node.Status]; ok {
+			attrs = append(attrs, fmt.Sprintf("fillcolor=\"%s\"", color))
+		}
+		switch node.Kind {
+		case KindHuman:
+			attrs = append(attrs, "penwidth=2")
+		case KindTodo:
+			attrs = append(attrs, "shape=note", "fontsize=10")
+		}
+		fmt.Fprintf(&b, "    %s [%s];\n", node.ID, strings.Join(attrs, ", "))
+	}
+	for _, edge := range g.Edges {
+		switch edge.Kind {
+		case EdgeBlocks:
+			fmt.Fprintf(&b, "    %s -> %s [style=dashed, label=\"blocks\"];\n", edge.From, edge.To)
+		case EdgeTodo:
+			fmt.Fprintf(&b, "    %s -> %s [arrowhead=none];\n", edge.From, edge.To)
+		default:
+			fmt.Fprintf(&b, "    %s -> %s;\n", edge.From, edge.To)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// DependsOn reports whether dependent's notes or prior work summary reference
+// dependency's ID, so that dependency blocks it
+func DependsOn(dependent, dependency *storage.AgentTask) bool {
+	return references(dependent.Notes, dependency.ID) || references(dependent.PriorWorkSummary, dependency.ID)
+}
+
+// references reports whether text mentions a task by ID, or as "task" and the
+// first 8 characters of the ID
+func references(text, taskID string) bool {
+	if text == "" || taskID == "" {
+		return false
+	}
+	if strings.Contains(text, taskID) {
+		return true
+	}
+	return len(taskID) >= 8 && strings.Contains(text, "task "+taskID[:8])
+}
+
+func sortedStatuses() []string {
+	statuses := make([]string, 0, len(statusColors))
+	for status := range statusColors {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// shorten collapses whitespace and truncates text to a label
+func shorten(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= labelRunes {
+		return text
+	}
+	return string([]rune(text)[:labelRunes-1]) + "…"
+}
+
+func dotEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text)
+}
@@ -0,0 +1,89 @@
+package taskgraph
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func plan() ([]*storage.HumanTask, []*storage.AgentTask) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	humans := []*storage.HumanTask{
+		{ID: "human-2", Prompt: "Second plan", Status: storage.TaskStatusPending, CreatedAt: base.Add(time.Hour)},
+		{ID: "human-1", Prompt: `Ship the "export" feature`, Status: storage.TaskStatusInProgress, CreatedAt: base},
+	}
+	agents := []*storage.AgentTask{
+		{
+			ID: "bbbbbbbb-0000", HumanTaskID: "human-1", AgentName: "ui-dev", Role: "Build the page",
+			Status: storage.TaskStatusBlocked, Notes: "Waiting on task aaaaaaaa", CreatedAt: base.Add(2 * time.Minute),
+			Todos: []storage.TodoItem{{ID: "todo-1", Description: "Add route", Status: storage.TodoStatusPending}},
+		},
+		{ID: "aaaaaaaa-0000", HumanTaskID: "human-1", AgentName: "go-dev", Role: "Add the API", Status: storage.TaskStatusCompleted, CreatedAt: base.Add(time.Minute)},
+		{ID: "cccccccc-0000", HumanTaskID: "human-2", Role: "Review", Status: storage.TaskStatusPending, PriorWorkSummary: "Built on bbbbbbbb-0000", CreatedAt: base.Add(3 * time.Minute)},
+	}
+	return humans, agents
+}
+
+func TestBuild(t *testing.T) {
+	humans, agents := plan()
+
+	graph, err := Build(humans, agents, Options{})
+	require.NoError(t, err)
+	ids := make([]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		ids[i] = node.ID + "=" + node.TaskID
+	}
+	assert.Equal(t, []string{"h1=human-1", "a1=aaaaaaaa-0000", "a2=bbbbbbbb-0000", "h2=human-2", "a3=cccccccc-0000"}, ids)
+	assert.Contains(t, graph.Edges, Edge{From: "a1", To: "a2", Kind: EdgeBlocks})
+	assert.Contains(t, graph.Edges, Edge{From: "a2", To: "a3", Kind: EdgeBlocks}, "dependencies cross plans")
+	assert.Equal(t, "go-dev: Add the API", graph.Nodes[1].Label)
+
+	graph, err = Build(humans, agents, Options{HumanTaskID: "human-1", IncludeTodos: true})
+	require.NoError(t, err)
+	assert.Len(t, graph.Nodes, 4)
+	assert.Contains(t, graph.Edges, Edge{From: "a2", To: "t1", Kind: EdgeTodo})
+
+	_, err = Build(humans, agents, Options{HumanTaskID: "missing"})
+	assert.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	humans, agents := plan()
+	graph, err := Build(humans, agents, Options{HumanTaskID: "human-1", IncludeTodos: true})
+	require.NoError(t, err)
+
+	mermaid := graph.Render(Mermaid)
+	assert.True(t, strings.HasPrefix(mermaid, "flowchart TD\n"))
+	assert.Contains(t, mermaid, `h1[["Ship the #quot;export#quot; feature<br/><i>in progress</i>"]]:::in_progress`)
+	assert.Contains(t, mermaid, "a1 -. blocks .-> a2")
+	assert.Contains(t, mermaid, "a2 --- t1")
+	assert.Contains(t, mermaid, "classDef blocked fill:#f8d7da")
+
+	dot := graph.Render(DOT)
+	assert.True(t, strings.HasPrefix(dot, "digraph tasks {\n"))
+	assert.Contains(t, dot, `h1 [label="Ship the \"export\" feature\n(in progress)", fillcolor="#cfe2ff", penwidth=2];`)
+	assert.Contains(t, dot, `a1 -> a2 [style=dashed, label="blocks"];`)
+	assert.True(t, strings.HasSuffix(dot, "}\n"))
+}
+
+func TestParseFormat(t *testing.T) {
+	for raw, want := range map[string]Format{"": Mermaid, "Mermaid": Mermaid, "dot": DOT, "graphviz": DOT} {
+		format, err := ParseFormat(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, want, format, raw)
+	}
+	_, err := ParseFormat("svg")
+	assert.Error(t, err)
+}
+
+func TestShorten(t *testing.T) {
+	assert.Equal(t, "a b", shorten("  a\n\tb "))
+	long := shorten(strings.Repeat("é", 100))
+	assert.Equal(t, labelRunes, len([]rune(long)))
+	assert.True(t, strings.HasSuffix(long, "…"))
+}
@@ -0,0 +1,76 @@
+// Package toolprogress carries progress reports of long-running tool calls
+// from their handlers to whoever made the call.
+//
+// Handlers call Report as work advances; without a reporter in the context
+// that is a no-op. MCP calls that carry a progress token forward reports as
+// notifications/progress, and REST calls through the tool proxy that accept
+// text/event-stream receive them as server-sent events, so clients see scans
+// and imports advance instead of waiting on a silent request.
+package toolprogress
+
+import (
+	"context"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Update is one progress report
+type Update struct {
+	Progress float64       `json:"progress"`          // Work done so far; increases with every report
+	Total    float64       `json:"total,omitempty"`   // Total work, 0 if unknown
+	Message  string        `json:"message,omitempty"` // What was just done
+	Content  []mcp.Content `json:"content,omitempty"` // Partial result completed since the last report
+}
+
+// Reporter receives the updates of one call. It may be called from several
+// goroutines at once.
+type Reporter func(Update)
+
+type contextKey struct{}
+
+// WithReporter returns a context whose tool progress goes to reporter
+func WithReporter(ctx context.Context, reporter Reporter) context.Context {
+	return context.WithValue(ctx, contextKey{}, reporter)
+}
+
+// Enabled reports whether anyone receives the progress of ctx, so handlers
+// can skip building costly updates
+func Enabled(ctx context.Context) bool {
+	reporter, _ := ctx.Value(contextKey{}).(Reporter)
+	return reporter != nil
+}
+
+// Report sends update to the reporter of ctx, if any
+func Report(ctx context.Context, update Update) {
+	if reporter, _ := ctx.Value(contextKey{}).(Reporter); reporter != nil {
+		reporter(update)
+	}
+}
+
+// Counter reports progress through a known number of items from concurrent
+// workers
+type Counter struct {
+	ctx   context.Context
+	total int
+
+	mu   sync.Mutex
+	done int
+}
+
+// NewCounter creates a counter for total items
+func NewCounter(ctx context.Context, total int) *Counter {
+	return &Counter{ctx: ctx, total: total}
+}
+
+// Done counts one finished item and reports it with message
+func (c *Counter) Done(message string) {
+	if !Enabled(c.ctx) {
+		return
+	}
+	// Reports are sent under the lock so progress never goes backwards
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.done++
+	Report(c.ctx, Update{Progress: float64(c.done), Total: float64(c.total), Message: message})
+}
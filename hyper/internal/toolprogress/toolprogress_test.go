@@ -0,0 +1,48 @@
+package toolprogress
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	Report(context.Background(), Update{Progress: 1}) // No reporter: no-op
+	assert.False(t, Enabled(context.Background()))
+
+	var updates []Update
+	ctx := WithReporter(context.Background(), func(update Update) { updates = append(updates, update) })
+	assert.True(t, Enabled(ctx))
+
+	Report(ctx, Update{Progress: 1, Message: "first"})
+	assert.Equal(t, []Update{{Progress: 1, Message: "first"}}, updates)
+}
+
+func TestCounter_ReportsInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var progress []float64
+	ctx := WithReporter(context.Background(), func(update Update) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 20.0, update.Total)
+		progress = append(progress, update.Progress)
+	})
+
+	counter := NewCounter(ctx, 20)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counter.Done("item")
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, progress, 20)
+	for i, p := range progress {
+		assert.Equal(t, float64(i+1), p)
+	}
+}
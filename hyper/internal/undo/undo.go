@@ -0,0 +1,159 @@
+// Package undo stages destructive operations for a fixed window, so that MCP
+// tools and REST routes can offer one shared undo and confirm flow.
+package undo
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"hyper/internal/errcode"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// WindowEnv sets how many seconds destructive operations stay staged
+// before they are committed. 0 disables staging.
+const WindowEnv = "UNDO_WINDOW_SECONDS"
+
+// defaultWindow is the staging window when UNDO_WINDOW_SECONDS is unset
+const defaultWindow = 60 * time.Second
+
+// WindowFromEnv returns the configured undo window
+func WindowFromEnv() time.Duration {
+	if raw := os.Getenv(WindowEnv); raw != "" {
+		if seconds, err := strconv.Atoi(raw); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultWindow
+}
+
+// StagedOperation describes a destructive operation waiting to be committed
+type StagedOperation struct {
+	UndoToken   string    `json:"undoToken"`
+	Tool        string    `json:"tool"`
+	Description string    `json:"description"`
+	StagedAt    time.Time `json:"stagedAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// CommitFunc performs a staged operation and returns its result
+type CommitFunc func(ctx context.Context) (interface{}, error)
+
+type pendingOperation struct {
+	StagedOperation
+	commit CommitFunc
+	timer  *time.Timer
+}
+
+// Manager stages destructive operations for a fixed window. A staged
+// operation is committed by Confirm or when the window expires, and discarded
+// by Undo. Staged operations live in memory only: operations still staged when
+// the server stops are never committed.
+type Manager struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending map[string]*pendingOperation
+	logger  *zap.Logger
+}
+
+// NewManager creates an undo manager with the given window. A zero window
+// disables staging and destructive operations run immediately.
+func NewManager(window time.Duration, logger *zap.Logger) *Manager {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Manager{
+		window:  window,
+		pending: make(map[string]*pendingOperation),
+		logger:  logger,
+	}
+}
+
+// Enabled reports whether destructive operations are staged
+func (m *Manager) Enabled() bool {
+	return m != nil && m.window > 0
+}
+
+// Stage registers an operation to be committed when the undo window expires
+func (m *Manager) Stage(tool, description string, commit CommitFunc) StagedOperation {
+	now := time.Now().UTC()
+	op := &pendingOperation{
+		StagedOperation: StagedOperation{
+			UndoToken:   uuid.New().String(),
+			Tool:        tool,
+			Description: description,
+			StagedAt:    now,
+			ExpiresAt:   now.Add(m.window),
+		},
+		commit: commit,
+	}
+
+	m.mu.Lock()
+	m.pending[op.UndoToken] = op
+	op.timer = time.AfterFunc(m.window, func() {
+		if _, err := m.Confirm(context.Background(), op.UndoToken); err != nil {
+			m.logger.Error("Failed to commit staged operation",
+				zap.String("tool", op.Tool), zap.String("undoToken", op.UndoToken), zap.Error(err))
+			return
+		}
+		m.logger.Info("Committed staged operation after undo window",
+			zap.String("tool", op.Tool), zap.String("undoToken", op.UndoToken))
+	})
+	m.mu.Unlock()
+
+	return op.StagedOperation
+}
+
+// Confirm commits a staged operation immediately
+func (m *Manager) Confirm(ctx context.Context, token string) (interface{}, error) {
+	op, err := m.take(token)
+	if err != nil {
+		return nil, err
+	}
+	return op.commit(ctx)
+}
+
+// Undo discards a staged operation before it is committed
+func (m *Manager) Undo(token string) (StagedOperation, error) {
+	op, err := m.take(token)
+	if err != nil {
+		return StagedOperation{}, err
+	}
+	return op.StagedOperation, nil
+}
+
+// List returns the staged operations, oldest first
+func (m *Manager) List() []StagedOperation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ops := make([]StagedOperation, 0, len(m.pending))
+	for _, op := range m.pending {
+		ops = append(ops, op.StagedOperation)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].StagedAt.Before(ops[j].StagedAt)
+	})
+	return ops
+}
+
+// take removes a staged operation so that exactly one of confirm, undo or
+// expiry acts on it
+func (m *Manager) take(token string) (*pendingOperation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	op, ok := m.pending[token]
+	if !ok {
+		return nil, errcode.New(errcode.NotFound, "staged operation with undo token %s not found: it was already committed, undone, or never existed", token)
+	}
+	op.timer.Stop()
+	delete(m.pending, token)
+	return op, nil
+}
@@ -0,0 +1,87 @@
+package undo
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countingCommit(calls *int32) CommitFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(calls, 1)
+		return "done", nil
+	}
+}
+
+func TestManager_UndoCancelsCommit(t *testing.T) {
+	var calls int32
+	m := NewManager(50*time.Millisecond, nil)
+
+	op := m.Stage("coordinator_clear_task_board", "Clear all tasks", countingCommit(&calls))
+	require.Len(t, m.List(), 1)
+
+	undone, err := m.Undo(op.UndoToken)
+	require.NoError(t, err)
+	assert.Equal(t, op, undone)
+	assert.Empty(t, m.List())
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+	_, err = m.Undo(op.UndoToken)
+	assert.Error(t, err)
+}
+
+func TestManager_ConfirmCommitsOnce(t *testing.T) {
+	var calls int32
+	m := NewManager(50*time.Millisecond, nil)
+
+	op := m.Stage("mcp_remove_server", "Remove server", countingCommit(&calls))
+
+	result, err := m.Confirm(context.Background(), op.UndoToken)
+	require.NoError(t, err)
+	assert.Equal(t, "done", result)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	_, err = m.Undo(op.UndoToken)
+	assert.Error(t, err, "committed operations can no longer be undone")
+}
+
+func TestManager_ExpiryCommits(t *testing.T) {
+	var calls int32
+	m := NewManager(20*time.Millisecond, nil)
+
+	m.Stage("coordinator_clear_task_board", "Clear all tasks", countingCommit(&calls))
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, m.List())
+}
+
+func TestManager_Enabled(t *testing.T) {
+	var m *Manager
+	assert.False(t, m.Enabled())
+	assert.False(t, NewManager(0, nil).Enabled())
+	assert.True(t, NewManager(time.Second, nil).Enabled())
+}
+
+func TestWindowFromEnv(t *testing.T) {
+	t.Setenv(WindowEnv, "")
+	assert.Equal(t, defaultWindow, WindowFromEnv())
+
+	t.Setenv(WindowEnv, "0")
+	assert.Equal(t, time.Duration(0), WindowFromEnv())
+
+	t.Setenv(WindowEnv, "15")
+	assert.Equal(t, 15*time.Second, WindowFromEnv())
+
+	t.Setenv(WindowEnv, "-3")
+	assert.Equal(t, defaultWindow, WindowFromEnv())
+}
@@ -0,0 +1,304 @@
+// Package update checks the release endpoint for a newer coordinator,
+// verifies the downloaded binary against the release signing key and swaps
+// it in for the running executable.
+//
+// The endpoint serves a JSON manifest listing one binary per platform:
+//
+//	{"version": "2.1.0", "notes": "...",
+//	 "assets": {"darwin-arm64": {"url": "...", "sha256": "<hex>", "signature": "<base64>"}}}
+//
+// The signature is an Ed25519 signature, made with the release key, of the
+// asset's manifest entry: the version, platform and SHA-256 (see
+// SignedMessage). A binary is only installed when its hash and signature
+// check out and its version is newer than the running one, so a manifest
+// cannot relabel a signed binary or roll back to an older release.
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultURL is the manifest of the latest release
+	DefaultURL = "https://github.com/HyperionWave-AI/dev-ex-mcp/releases/latest/download/hyper-release.json"
+	// maxManifestBytes caps the manifest download
+	maxManifestBytes = 1 << 20
+	// maxBinaryBytes caps the binary download
+	maxBinaryBytes = 512 << 20
+)
+
+// PublicKey is the base64 Ed25519 release signing key, set at build time with
+// -ldflags "-X hyper/internal/update.PublicKey=...". HYPER_UPDATE_PUBLIC_KEY
+// overrides it.
+var PublicKey = ""
+
+// Asset is the binary of one platform
+type Asset struct {
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
+// Release is the release manifest
+type Release struct {
+	Version string           `json:"version"`
+	Notes   string           `json:"notes,omitempty"`
+	Assets  map[string]Asset `json:"assets"`
+}
+
+// Config configures update checks
+type Config struct {
+	URL            string // HYPER_UPDATE_URL (default DefaultURL)
+	PublicKey      string // Base64 Ed25519 key (HYPER_UPDATE_PUBLIC_KEY or the build-time key)
+	CheckOnStartup bool   // HYPER_UPDATE_CHECK=true: look for a new version when the coordinator starts
+}
+
+// LoadConfig reads the HYPER_UPDATE_* environment variables
+func LoadConfig() Config {
+	cfg := Config{
+		URL:            os.Getenv("HYPER_UPDATE_URL"),
+		PublicKey:      os.Getenv("HYPER_UPDATE_PUBLIC_KEY"),
+		CheckOnStartup: os.Getenv("HYPER_UPDATE_CHECK") == "true",
+	}
+	if cfg.URL == "" {
+		cfg.URL = DefaultURL
+	}
+	if cfg.PublicKey == "" {
+		cfg.PublicKey = PublicKey
+	}
+	return cfg
+}
+
+// Updater checks for and installs releases
+type Updater struct {
+	cfg        Config
+	httpClient *http.Client
+	platform   string
+}
+
+// NewUpdater creates an updater for the running platform
+func NewUpdater(cfg Config) *Updater {
+	return &Updater{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Minute},
+		platform:   Platform(),
+	}
+}
+
+// Platform is the manifest asset key of the running binary, e.g. darwin-arm64
+func Platform() string {
+	return runtime.GOOS + "-" + runtime.GOARCH
+}
+
+// Latest fetches the release manifest
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	body, err := u.get(ctx, u.cfg.URL, maxManifestBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release manifest: %w", err)
+	}
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("invalid release manifest: %w", err)
+	}
+	if release.Version == "" {
+		return nil, fmt.Errorf("invalid release manifest: version is required")
+	}
+	return &release, nil
+}
+
+// Check returns the latest release and whether it is newer than current.
+// Development builds (version "dev" or empty) are never offered updates.
+func (u *Updater) Check(ctx context.Context, current string) (*Release, bool, error) {
+	release, err := u.Latest(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return release, IsNewer(release.Version, current), nil
+}
+
+// Download fetches the release's binary for this platform and verifies its
+// hash and signature. Releases not newer than current are rejected;
+// development builds (version "dev" or empty) accept any release.
+func (u *Updater) Download(ctx context.Context, release *Release, current string) ([]byte, error) {
+	if current != "" && current != "dev" && !IsNewer(release.Version, current) {
+		return nil, fmt.Errorf("release %s is not newer than the running version %s", release.Version, current)
+	}
+	key, err := u.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	asset, ok := release.Assets[u.platform]
+	if !ok {
+		return nil, fmt.Errorf("release %s has no binary for %s", release.Version, u.platform)
+	}
+
+	binary, err := u.get(ctx, asset.URL, maxBinaryBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download release %s: %w", release.Version, err)
+	}
+	if err := Verify(binary, release.Version, u.platform, asset, key); err != nil {
+		return nil, err
+	}
+	return binary, nil
+}
+
+// SignedMessage is what the release key signs for one asset: its version,
+// platform and lowercase hex SHA-256, one per line
+func SignedMessage(version, platform, sha256Hex string) []byte {
+	return []byte(fmt.Sprintf("hyper-release\nversion=%s\nplatform=%s\nsha256=%s\n", version, platform, strings.ToLower(sha256Hex)))
+}
+
+// Verify checks a downloaded binary against its manifest hash, and the
+// signature against the manifest entry of version and platform
+func Verify(binary []byte, version, platform string, asset Asset, key ed25519.PublicKey) error {
+	sum := sha256.Sum256(binary)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), asset.SHA256) {
+		return fmt.Errorf("invalid release binary: sha256 does not match the manifest")
+	}
+	signature, err := base64.StdEncoding.DecodeString(asset.Signature)
+	if err != nil || !ed25519.Verify(key, SignedMessage(version, platform, asset.SHA256), signature) {
+		return fmt.Errorf("invalid release binary: signature verification failed for %s %s", version, platform)
+	}
+	return nil
+}
+
+// publicKey decodes the configured signing key
+func (u *Updater) publicKey() (ed25519.PublicKey, error) {
+	if u.cfg.PublicKey == "" {
+		return nil, fmt.Errorf("no release signing key is configured: set HYPER_UPDATE_PUBLIC_KEY")
+	}
+	key, err := base64.StdEncoding.DecodeString(u.cfg.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release signing key: must be a base64 Ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// get downloads a URL, failing when the body exceeds limit
+func (u *Updater) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%s exceeds %d bytes", url, limit)
+	}
+	return body, nil
+}
+
+// IsNewer reports whether version a is newer than b. Versions are compared
+// by their dot-separated numbers, ignoring a leading "v"; a pre-release
+// (suffix after "-") is older than its release. Development builds are
+// never older than anything.
+func IsNewer(a, b string) bool {
+	if b == "" || b == "dev" {
+		return false
+	}
+	aNums, aPre := parseVersion(a)
+	bNums, bPre := parseVersion(b)
+	for i := 0; i < len(aNums) || i < len(bNums); i++ {
+		var x, y int
+		if i < len(aNums) {
+			x = aNums[i]
+		}
+		if i < len(bNums) {
+			y = bNums[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	// Same numbers: a release beats a pre-release
+	return aPre == "" && bPre != ""
+}
+
+func parseVersion(v string) ([]int, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, pre, _ := strings.Cut(v, "-")
+	var nums []int
+	for _, part := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		nums = append(nums, n)
+	}
+	return nums, pre
+}
+
+// Apply replaces the executable at path with binary. The new file is written
+// next to it and renamed over it, so the swap is atomic and an interrupted
+// update leaves the old binary in place.
+func Apply(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".new"
+	if err := os.WriteFile(tmp, binary, info.Mode().Perm()|0o100); err != nil {
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := replace(path, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace binary: %w", err)
+	}
+	return nil
+}
+
+// replace renames src over dst. Windows cannot overwrite a running
+// executable but can rename it, so the old binary is moved aside first.
+func replace(dst, src string) error {
+	if runtime.GOOS != "windows" {
+		return os.Rename(src, dst)
+	}
+	old := dst + ".old"
+	os.Remove(old) // Left by the previous update
+	if err := os.Rename(dst, old); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		os.Rename(old, dst)
+		return err
+	}
+	return nil
+}
+
+// Sign signs the manifest entry of a binary of version for platform with a
+// release private key, producing the manifest asset for it (used by release
+// tooling and tests)
+func Sign(binary []byte, url, version, platform string, key ed25519.PrivateKey) Asset {
+	sum := sha256.Sum256(binary)
+	digest := hex.EncodeToString(sum[:])
+	return Asset{
+		URL:       url,
+		SHA256:    digest,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, SignedMessage(version, platform, digest))),
+	}
+}
@@ -0,0 +1,213 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// releaseServer serves a manifest for the running platform and its binary
+func releaseServer(t *testing.T, version string, binary []byte, served []byte, key ed25519.PrivateKey) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/hyper-release.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Release{
+			Version: version,
+			Assets:  map[string]Asset{Platform(): Sign(binary, server.URL+"/hyper", version, Platform(), key)},
+		})
+	})
+	mux.HandleFunc("/hyper", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(served)
+	})
+	return server
+}
+
+func newKey(t *testing.T) (string, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(public), private
+}
+
+func TestCheckAndDownload(t *testing.T) {
+	publicKey, privateKey := newKey(t)
+	binary := []byte("new hyper binary")
+	server := releaseServer(t, "2.1.0", binary, binary, privateKey)
+	updater := NewUpdater(Config{URL: server.URL + "/hyper-release.json", PublicKey: publicKey})
+
+	release, newer, err := updater.Check(context.Background(), "2.0.3")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if !newer || release.Version != "2.1.0" {
+		t.Fatalf("expected 2.1.0 to be newer, got %s newer=%v", release.Version, newer)
+	}
+
+	got, err := updater.Download(context.Background(), release, "2.0.3")
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if string(got) != string(binary) {
+		t.Errorf("downloaded %q", got)
+	}
+
+	if _, newer, _ := updater.Check(context.Background(), "2.1.0"); newer {
+		t.Error("same version should not be newer")
+	}
+}
+
+func TestDownloadRejectsTamperedBinary(t *testing.T) {
+	publicKey, privateKey := newKey(t)
+	server := releaseServer(t, "2.1.0", []byte("signed binary"), []byte("tampered binary"), privateKey)
+	updater := NewUpdater(Config{URL: server.URL + "/hyper-release.json", PublicKey: publicKey})
+
+	release, _, err := updater.Check(context.Background(), "2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := updater.Download(context.Background(), release, "2.0.0"); err == nil || !strings.Contains(err.Error(), "sha256") {
+		t.Errorf("expected hash mismatch, got %v", err)
+	}
+}
+
+func TestVerifyRejectsWrongKey(t *testing.T) {
+	_, releaseKey := newKey(t)
+	otherPublic, _ := newKey(t)
+	binary := []byte("binary")
+	asset := Sign(binary, "", "2.1.0", "linux-amd64", releaseKey)
+
+	key, _ := base64.StdEncoding.DecodeString(otherPublic)
+	if err := Verify(binary, "2.1.0", "linux-amd64", asset, ed25519.PublicKey(key)); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("expected signature failure, got %v", err)
+	}
+}
+
+func TestVerifyRejectsRelabeledAsset(t *testing.T) {
+	publicKey, privateKey := newKey(t)
+	key, _ := base64.StdEncoding.DecodeString(publicKey)
+	binary := []byte("hyper 2.0.0 for linux")
+	asset := Sign(binary, "", "2.0.0", "linux-amd64", privateKey)
+
+	if err := Verify(binary, "2.0.0", "linux-amd64", asset, ed25519.PublicKey(key)); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	// The same signed binary listed under another version or platform
+	if err := Verify(binary, "2.1.0", "linux-amd64", asset, ed25519.PublicKey(key)); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("expected a relabeled version to be rejected, got %v", err)
+	}
+	if err := Verify(binary, "2.0.0", "darwin-arm64", asset, ed25519.PublicKey(key)); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("expected a relabeled platform to be rejected, got %v", err)
+	}
+}
+
+func TestDownloadRejectsOlderRelease(t *testing.T) {
+	publicKey, privateKey := newKey(t)
+	server := releaseServer(t, "2.0.0", []byte("b"), []byte("b"), privateKey)
+	updater := NewUpdater(Config{URL: server.URL + "/hyper-release.json", PublicKey: publicKey})
+	release, err := updater.Latest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, current := range []string{"2.0.0", "2.1.0"} {
+		if _, err := updater.Download(context.Background(), release, current); err == nil || !strings.Contains(err.Error(), "not newer") {
+			t.Errorf("expected 2.0.0 to be rejected on %s, got %v", current, err)
+		}
+	}
+	if _, err := updater.Download(context.Background(), release, "dev"); err != nil {
+		t.Errorf("development builds accept any release, got %v", err)
+	}
+}
+
+func TestDownloadRequiresKey(t *testing.T) {
+	_, privateKey := newKey(t)
+	server := releaseServer(t, "2.1.0", []byte("b"), []byte("b"), privateKey)
+
+	for _, key := range []string{"", "not-a-key"} {
+		updater := NewUpdater(Config{URL: server.URL + "/hyper-release.json", PublicKey: key})
+		release, err := updater.Latest(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := updater.Download(context.Background(), release, "2.0.0"); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
+
+func TestDownloadMissingPlatform(t *testing.T) {
+	publicKey, _ := newKey(t)
+	updater := NewUpdater(Config{PublicKey: publicKey})
+	release := &Release{Version: "2.1.0", Assets: map[string]Asset{"plan9-mips": {}}}
+	if _, err := updater.Download(context.Background(), release, "2.0.0"); err == nil || !strings.Contains(err.Error(), "no binary") {
+		t.Errorf("expected missing platform error, got %v", err)
+	}
+}
+
+func TestIsNewer(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"2.1.0", "2.0.9", true},
+		{"v2.10.0", "2.9.1", true},
+		{"2.0.0", "2.0.0", false},
+		{"2.0.0", "2.0.1", false},
+		{"2.0.1", "2.0", true},
+		{"2.0.0", "2.0.0-native", true},
+		{"2.0.0-rc1", "2.0.0", false},
+		{"9.9.9", "dev", false},
+		{"9.9.9", "", false},
+	}
+	for _, c := range cases {
+		if got := IsNewer(c.a, c.b); got != c.want {
+			t.Errorf("IsNewer(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestApplyReplacesExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hyper")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Apply(path, []byte("new")); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil || string(got) != "new" {
+		t.Fatalf("expected new binary, got %q (%v)", got, err)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("expected executable permissions, got %v", info.Mode())
+	}
+	if _, err := os.Stat(path + ".new"); !os.IsNotExist(err) {
+		t.Error("expected no temporary file left behind")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("HYPER_UPDATE_URL", "")
+	t.Setenv("HYPER_UPDATE_CHECK", "true")
+	t.Setenv("HYPER_UPDATE_PUBLIC_KEY", "")
+
+	cfg := LoadConfig()
+	if cfg.URL != DefaultURL || !cfg.CheckOnStartup {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
@@ -0,0 +1,236 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FromBinding describes an error from binding a JSON body to obj (a pointer
+// to a struct): malformed JSON, a value of the wrong type, or failed
+// `binding` rules, with field names as the client sends them
+func FromBinding(err error, obj interface{}) []FieldError {
+	var (
+		rules     validator.ValidationErrors
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+	)
+	switch {
+	case errors.As(err, &rules):
+		errs := make([]FieldError, 0, len(rules))
+		for _, rule := range rules {
+			errs = append(errs, FieldError{
+				Pointer:    structPointer(reflect.TypeOf(obj), rule.StructNamespace()),
+				Constraint: rule.Tag(),
+				Message:    ruleMessage(rule),
+				Example:    ruleExample(rule),
+			})
+		}
+		return errs
+	case errors.As(err, &typeErr):
+		var tokens []string
+		if typeErr.Field != "" {
+			tokens = strings.Split(typeErr.Field, ".")
+		}
+		return []FieldError{{
+			Pointer:    pointer("", tokens...),
+			Constraint: "type",
+			Message:    fmt.Sprintf("must be %s, not %s", jsonKind(typeErr.Type), typeErr.Value),
+			Example:    goExample(typeErr.Type),
+		}}
+	case errors.As(err, &syntaxErr):
+		return []FieldError{{Constraint: "syntax", Message: fmt.Sprintf("body is not valid JSON at byte %d: %s", syntaxErr.Offset, syntaxErr.Error())}}
+	case errors.Is(err, io.EOF):
+		return []FieldError{{Constraint: "required", Message: "request body is empty", Example: map[string]interface{}{}}}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return []FieldError{{Constraint: "syntax", Message: "body is not valid JSON: unexpected end of input"}}
+	default:
+		return []FieldError{{Constraint: "invalid", Message: err.Error()}}
+	}
+}
+
+// structPointer converts a validator namespace such as
+// "CreateAgentTaskRequest.Todos[0].Description" into the JSON Pointer of the
+// field ("/todos/0/description"), following json tags through t
+func structPointer(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	if len(segments) > 0 {
+		segments = segments[1:] // the struct's own name
+	}
+
+	result := ""
+	for _, segment := range segments {
+		name, keys := segment, []string(nil)
+		if i := strings.Index(segment, "["); i >= 0 {
+			name = segment[:i]
+			keys = strings.Split(strings.TrimSuffix(segment[i+1:], "]"), "][")
+		}
+
+		t = indirect(t)
+		if t == nil || t.Kind() != reflect.Struct {
+			result = pointer(result, name)
+			t = nil
+		} else if field, ok := t.FieldByName(name); ok {
+			result = pointer(result, jsonName(field))
+			t = field.Type
+		} else {
+			result = pointer(result, name)
+			t = nil
+		}
+
+		for _, key := range keys {
+			result = pointer(result, key)
+			if t = indirect(t); t != nil {
+				switch t.Kind() {
+				case reflect.Slice, reflect.Array, reflect.Map:
+					t = t.Elem()
+				default:
+					t = nil
+				}
+			}
+		}
+	}
+	return result
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonName returns the name a struct field is encoded under
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// ruleMessage describes a failed validator rule
+func ruleMessage(rule validator.FieldError) string {
+	param := rule.Param()
+	counted := ""
+	switch rule.Kind() {
+	case reflect.String:
+		counted = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		counted = " items"
+	}
+
+	switch rule.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s%s", param, counted)
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s%s", param, counted)
+	case "gt":
+		return fmt.Sprintf("must be greater than %s%s", param, counted)
+	case "lt":
+		return fmt.Sprintf("must be less than %s%s", param, counted)
+	case "len":
+		return fmt.Sprintf("must be exactly %s%s", param, counted)
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "email":
+		return "must be an email address"
+	case "url", "uri":
+		return "must be a URL"
+	default:
+		if param != "" {
+			return fmt.Sprintf("fails the %s=%s rule", rule.Tag(), param)
+		}
+		return fmt.Sprintf("fails the %s rule", rule.Tag())
+	}
+}
+
+// ruleExample returns a value satisfying a failed validator rule, or nil
+func ruleExample(rule validator.FieldError) interface{} {
+	param := rule.Param()
+	switch rule.Tag() {
+	case "oneof":
+		if options := strings.Fields(param); len(options) > 0 {
+			return options[0]
+		}
+	case "email":
+		return "user@example.com"
+	case "url", "uri":
+		return "https://example.com"
+	case "min", "gte", "max", "lte", "len":
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil
+		}
+		switch rule.Kind() {
+		case reflect.String:
+			return strings.Repeat("x", int(n))
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return nil
+		}
+		return n
+	}
+	return goExample(rule.Type())
+}
+
+// goExample returns a JSON value decoding into t, or nil
+func goExample(t reflect.Type) interface{} {
+	t = indirect(t)
+	if t == nil {
+		return nil
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return "2025-01-01T00:00:00Z"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "example"
+	case reflect.Bool:
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return 1
+	case reflect.Slice, reflect.Array:
+		return []interface{}{}
+	case reflect.Map, reflect.Struct:
+		return map[string]interface{}{}
+	}
+	return nil
+}
+
+// jsonKind names the JSON type a Go type decodes from
+func jsonKind(t reflect.Type) string {
+	t = indirect(t)
+	if t == nil {
+		return "a value"
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return "an RFC 3339 timestamp"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return "a " + t.String()
+}
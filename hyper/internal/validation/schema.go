@@ -0,0 +1,301 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// FromSchema validates a decoded JSON instance against a JSON schema (a
+// *jsonschema.Schema or anything marshalling to one) and returns every
+// failing field. Keywords it does not walk itself, such as anyOf, are still
+// checked, and reported against the whole instance.
+func FromSchema(schema interface{}, instance interface{}) []FieldError {
+	if schema == nil {
+		return nil
+	}
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	var s jsonschema.Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil
+	}
+
+	var errs []FieldError
+	walk(&s, normalize(instance), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	resolved, err := s.Resolve(nil)
+	if err != nil {
+		return nil
+	}
+	if err := resolved.Validate(instance); err != nil {
+		return []FieldError{{Constraint: "schema", Message: err.Error()}}
+	}
+	return nil
+}
+
+// normalize converts json.Number and other numbers to float64, as
+// json.Unmarshal decodes them, so they compare with enum values
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = normalize(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+		return out
+	}
+	return value
+}
+
+func walk(s *jsonschema.Schema, value interface{}, at string, errs *[]FieldError) {
+	if s == nil {
+		return
+	}
+	fail := func(constraint, message string) {
+		*errs = append(*errs, FieldError{Pointer: at, Constraint: constraint, Message: message, Example: schemaExample(s)})
+	}
+
+	if types := schemaTypes(s); len(types) > 0 && !matchesType(types, value) {
+		fail("type", fmt.Sprintf("must be %s, not %s", strings.Join(types, " or "), jsonType(value)))
+		return
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) {
+		fail("enum", "must be one of: "+formatValues(s.Enum))
+		return
+	}
+	if s.Const != nil && !reflect.DeepEqual(normalize(*s.Const), value) {
+		fail("const", "must be "+formatValues([]interface{}{*s.Const}))
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("minLength", fmt.Sprintf("must be at least %d characters", *s.MinLength))
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("maxLength", fmt.Sprintf("must be at most %d characters", *s.MaxLength))
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(v) {
+				fail("pattern", fmt.Sprintf("must match %s", s.Pattern))
+			}
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("minimum", fmt.Sprintf("must be at least %s", formatNumber(*s.Minimum)))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("maximum", fmt.Sprintf("must be at most %s", formatNumber(*s.Maximum)))
+		}
+		if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+			fail("exclusiveMinimum", fmt.Sprintf("must be greater than %s", formatNumber(*s.ExclusiveMinimum)))
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("minItems", fmt.Sprintf("must have at least %d items", *s.MinItems))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("maxItems", fmt.Sprintf("must have at most %d items", *s.MaxItems))
+		}
+		for i, item := range v {
+			walk(s.Items, item, pointer(at, fmt.Sprint(i)), errs)
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, FieldError{
+					Pointer:    pointer(at, name),
+					Constraint: "required",
+					Message:    "is required",
+					Example:    schemaExample(s.Properties[name]),
+				})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				walk(property, v[name], pointer(at, name), errs)
+			} else if s.AdditionalProperties != nil {
+				if isFalse(s.AdditionalProperties) {
+					*errs = append(*errs, FieldError{Pointer: pointer(at, name), Constraint: "additionalProperties", Message: "is not a known field"})
+				} else {
+					walk(s.AdditionalProperties, v[name], pointer(at, name), errs)
+				}
+			}
+		}
+	}
+}
+
+// isFalse reports whether s is the schema `false`, which matches nothing
+func isFalse(s *jsonschema.Schema) bool {
+	return s.Not != nil && reflect.ValueOf(*s.Not).IsZero()
+}
+
+func schemaTypes(s *jsonschema.Schema) []string {
+	if s.Type != "" {
+		return []string{s.Type}
+	}
+	return s.Types
+}
+
+func matchesType(types []string, value interface{}) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		switch {
+		case t == actual:
+			return true
+		case t == "number" && actual == "integer":
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON type of a decoded value; whole numbers are integers
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(normalize(candidate), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		raw, _ := json.Marshal(value)
+		parts[i] = string(raw)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatNumber(n float64) string {
+	raw, _ := json.Marshal(n)
+	return string(raw)
+}
+
+// schemaExample returns a value valid against s: its first example, default,
+// enum value or const, otherwise one built from its type and bounds. Returns
+// nil for a pattern, since no value can be built to match one.
+func schemaExample(s *jsonschema.Schema) interface{} {
+	if s == nil {
+		return nil
+	}
+	switch {
+	case len(s.Examples) > 0:
+		return s.Examples[0]
+	case len(s.Default) > 0:
+		var value interface{}
+		if json.Unmarshal(s.Default, &value) == nil {
+			return value
+		}
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case s.Const != nil:
+		return *s.Const
+	}
+
+	types := schemaTypes(s)
+	if len(types) == 0 {
+		return nil
+	}
+	switch types[0] {
+	case "string":
+		if s.Pattern != "" {
+			return nil
+		}
+		switch s.Format {
+		case "date-time":
+			return "2025-01-01T00:00:00Z"
+		case "date":
+			return "2025-01-01"
+		case "email":
+			return "user@example.com"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		if s.MinLength != nil && *s.MinLength > len("example") {
+			return strings.Repeat("x", *s.MinLength)
+		}
+		return "example"
+	case "integer", "number":
+		switch {
+		case s.Minimum != nil:
+			return math.Ceil(*s.Minimum)
+		case s.ExclusiveMinimum != nil:
+			return math.Floor(*s.ExclusiveMinimum) + 1
+		case s.Maximum != nil && *s.Maximum < 1:
+			return math.Floor(*s.Maximum)
+		}
+		return 1
+	case "boolean":
+		return true
+	case "array":
+		if item := schemaExample(s.Items); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "object":
+		example := map[string]interface{}{}
+		for _, name := range s.Required {
+			example[name] = schemaExample(s.Properties[name])
+		}
+		return example
+	}
+	return nil
+}
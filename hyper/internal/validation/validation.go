@@ -0,0 +1,73 @@
+// Package validation reports invalid input field by field. REST request
+// binding and MCP tool arguments both describe each failing field with a
+// JSON Pointer (RFC 6901), the constraint it violates and a value that would
+// satisfy it, so clients can fix a request without trial and error.
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"hyper/internal/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldError is one field failing validation
+type FieldError struct {
+	Pointer    string      `json:"pointer"`           // JSON Pointer to the field; "" is the whole body
+	Constraint string      `json:"constraint"`        // Rule violated, e.g. required, type, enum, minimum
+	Message    string      `json:"message"`           // What is wrong, e.g. "is required"
+	Example    interface{} `json:"example,omitempty"` // A value that satisfies the constraint, when one is known
+}
+
+func (e FieldError) String() string {
+	if e.Pointer == "" {
+		return e.Message
+	}
+	return e.Pointer + " " + e.Message
+}
+
+// Details is the error.details of a REST validation error
+type Details struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// maxSummarized bounds how many fields Summary names
+const maxSummarized = 3
+
+// Summary describes errs in one line, e.g. "/collection is required"
+func Summary(errs []FieldError) string {
+	parts := make([]string, 0, maxSummarized+1)
+	for i, e := range errs {
+		if i == maxSummarized {
+			parts = append(parts, fmt.Sprintf("and %d more", len(errs)-maxSummarized))
+			break
+		}
+		parts = append(parts, e.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Respond writes a VALIDATION error listing errs in details
+func Respond(c *gin.Context, message string, errs []FieldError) {
+	if len(errs) > 0 {
+		message += ": " + Summary(errs)
+	}
+	errcode.RespondDetails(c, errcode.Validation, message, Details{Errors: errs})
+}
+
+// RespondBinding writes the validation error for a request body that failed
+// to bind to obj
+func RespondBinding(c *gin.Context, err error, obj interface{}) {
+	Respond(c, "Invalid request", FromBinding(err, obj))
+}
+
+// pointer appends escaped reference tokens to a JSON Pointer
+func pointer(base string, tokens ...string) string {
+	for _, token := range tokens {
+		token = strings.ReplaceAll(token, "~", "~0")
+		base += "/" + strings.ReplaceAll(token, "/", "~1")
+	}
+	return base
+}
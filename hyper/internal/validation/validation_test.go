@@ -0,0 +1,118 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type todoRequest struct {
+	Description string `json:"description" binding:"required"`
+}
+
+type createTaskRequest struct {
+	HumanTaskID string        `json:"humanTaskId" binding:"required"`
+	Role        string        `json:"role" binding:"oneof=frontend backend"`
+	Limit       int           `json:"limit" binding:"min=1"`
+	Todos       []todoRequest `json:"todos" binding:"dive"`
+}
+
+func bindError(t *testing.T, body string) error {
+	t.Helper()
+	var req createTaskRequest
+	err := binding.JSON.BindBody([]byte(body), &req)
+	require.Error(t, err)
+	return err
+}
+
+func TestFromBinding_Rules(t *testing.T) {
+	err := bindError(t, `{"role": "qa", "limit": 0, "todos": [{"description": "ok"}, {}]}`)
+	assert.Equal(t, []FieldError{
+		{Pointer: "/humanTaskId", Constraint: "required", Message: "is required", Example: "example"},
+		{Pointer: "/role", Constraint: "oneof", Message: "must be one of: frontend, backend", Example: "frontend"},
+		{Pointer: "/limit", Constraint: "min", Message: "must be at least 1", Example: float64(1)},
+		{Pointer: "/todos/1/description", Constraint: "required", Message: "is required", Example: "example"},
+	}, FromBinding(err, &createTaskRequest{}))
+}
+
+func TestFromBinding_Decoding(t *testing.T) {
+	errs := FromBinding(bindError(t, `{"humanTaskId": 42}`), &createTaskRequest{})
+	assert.Equal(t, []FieldError{{Pointer: "/humanTaskId", Constraint: "type", Message: "must be a string, not number", Example: "example"}}, errs)
+
+	errs = FromBinding(bindError(t, `{"humanTaskId": "t-1",`), &createTaskRequest{})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "syntax", errs[0].Constraint)
+	assert.Empty(t, errs[0].Pointer)
+
+	errs = FromBinding(bindError(t, ``), &createTaskRequest{})
+	assert.Equal(t, "request body is empty", errs[0].Message)
+}
+
+func TestFromSchema(t *testing.T) {
+	minimum := 1.0
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"collectionName": {Type: "string"},
+			"limit":          {Type: "integer", Minimum: &minimum},
+			"status":         {Type: "string", Enum: []any{"pending", "completed"}},
+			"tags":           {Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+			"meta/data":      {Type: "object"},
+		},
+		Required: []string{"collectionName", "information"},
+	}
+	var args map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"limit": 0.5, "status": "done", "tags": ["a", 2], "meta/data": []}`), &args))
+
+	assert.Equal(t, []FieldError{
+		{Pointer: "/collectionName", Constraint: "required", Message: "is required", Example: "example"},
+		{Pointer: "/information", Constraint: "required", Message: "is required"},
+		{Pointer: "/limit", Constraint: "type", Message: "must be integer, not number", Example: float64(1)},
+		{Pointer: "/meta~1data", Constraint: "type", Message: "must be object, not array", Example: map[string]interface{}{}},
+		{Pointer: "/status", Constraint: "enum", Message: `must be one of: "pending", "completed"`, Example: "pending"},
+		{Pointer: "/tags/1", Constraint: "type", Message: "must be string, not integer", Example: "example"},
+	}, FromSchema(schema, args))
+
+	args = nil
+	require.NoError(t, json.Unmarshal([]byte(`{"collectionName": "docs", "information": "x", "limit": 3}`), &args))
+	assert.Empty(t, FromSchema(schema, args))
+}
+
+func TestRespondBinding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"role": "backend", "limit": 2}`))
+
+	var req createTaskRequest
+	err := c.ShouldBindJSON(&req)
+	require.Error(t, err)
+	RespondBinding(c, err, &req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp struct {
+		Error struct {
+			Code    string  `json:"code"`
+			Message string  `json:"message"`
+			Details Details `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "VALIDATION", resp.Error.Code)
+	assert.Equal(t, "Invalid request: /humanTaskId is required", resp.Error.Message)
+	assert.Equal(t, []FieldError{{Pointer: "/humanTaskId", Constraint: "required", Message: "is required", Example: "example"}}, resp.Error.Details.Errors)
+}
+
+func TestSummary(t *testing.T) {
+	errs := []FieldError{{Pointer: "/a", Message: "is required"}, {Pointer: "/b", Message: "is required"}, {Pointer: "/c", Message: "is required"}, {Pointer: "/d", Message: "is required"}}
+	assert.Equal(t, "/a is required; /b is required; /c is required; and 1 more", Summary(errs))
+	assert.Equal(t, "request body is empty", Summary([]FieldError{{Message: "request body is empty"}}))
+}
@@ -0,0 +1,374 @@
+package vectortier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// Folders lists the code index (implemented by storage.CodeIndexStorage)
+type Folders interface {
+	ListFolders() ([]*storage.IndexedFolder, error)
+	ListFiles(folderID string) ([]*storage.IndexedFile, error)
+	ListChunks(fileID string) ([]*storage.FileChunk, error)
+	GetPathMapping(path string) (*storage.CodeIndexMapping, error)
+}
+
+// CodeVectors reads, writes and deletes code index points (implemented by
+// storage.QdrantClient)
+type CodeVectors interface {
+	ScanCodeIndexPoints(ctx context.Context, collectionName string, filter map[string]interface{}, visit func(storage.CodeIndexPoint) error) error
+	UpsertCodeIndexPointsContext(ctx context.Context, collectionName string, points []storage.CodeIndexPoint) error
+	DeleteCodeIndexByFilter(collectionName string, filter map[string]interface{}) error
+}
+
+// folderSnapshotLine is one point of a folder snapshot. Folders share
+// collections, so the point is kept whole, with the collection it came from.
+type folderSnapshotLine struct {
+	Collection    string                 `json:"collection"`
+	ID            string                 `json:"id"`
+	Vector        []float32              `json:"vector"`
+	SummaryVector []float32              `json:"summaryVector,omitempty"`
+	Payload       map[string]interface{} `json:"payload"`
+}
+
+// SetCodeFolders makes the tier archive idle code index folders too. Their
+// tiers are recorded in archives, keyed by folder path.
+func (t *Tier) SetCodeFolders(folders Folders, vectors CodeVectors, archives Archives) {
+	t.folders = folders
+	t.codeVectors = vectors
+	t.folderArchives = archives
+}
+
+// CodeFolderUsed records a search of folderPath and, if it is archived,
+// starts restoring its vectors. It does not block.
+func (t *Tier) CodeFolderUsed(folderPath string) {
+	if t.folders == nil {
+		return
+	}
+	now := t.now()
+	t.mu.Lock()
+	if last, ok := t.folderUse[folderPath]; ok && now.Sub(last) < useInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.folderUse[folderPath] = now
+	t.mu.Unlock()
+
+	go func() {
+		if err := t.folderArchives.TouchVectorArchive(folderPath, now); err != nil {
+			t.logger.Warn("Failed to record code folder use", zap.String("folder", folderPath), zap.Error(err))
+			return
+		}
+		archive, err := t.folderArchives.GetVectorArchive(folderPath)
+		if err != nil {
+			t.logger.Warn("Failed to read code folder tier", zap.String("folder", folderPath), zap.Error(err))
+			return
+		}
+		if archive != nil && archive.Tier == storage.VectorTierArchived {
+			if err := t.RehydrateFolder(t.ctx, folderPath); err != nil {
+				t.logger.Error("Code folder rehydration failed", zap.String("folder", folderPath), zap.Error(err))
+			}
+		}
+	}()
+}
+
+// FolderStatus returns the tier record of a code index folder, or nil when
+// it is hot, unknown or folders are not archived
+func (t *Tier) FolderStatus(folderPath string) *storage.VectorArchive {
+	if t.folders == nil {
+		return nil
+	}
+	archive, err := t.folderArchives.GetVectorArchive(folderPath)
+	if err != nil {
+		t.logger.Warn("Failed to read code folder tier", zap.String("folder", folderPath), zap.Error(err))
+		return nil
+	}
+	if archive == nil || archive.Tier == storage.VectorTierHot {
+		return nil
+	}
+	return archive
+}
+
+// runFolders is the code index part of Run. Folders being scanned are left
+// alone.
+func (t *Tier) runFolders(ctx context.Context, now time.Time, report *Report) {
+	folders, err := t.folders.ListFolders()
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return
+	}
+	archives, err := t.folderArchives.ListVectorArchives()
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return
+	}
+	byPath := make(map[string]*storage.VectorArchive, len(archives))
+	for _, archive := range archives {
+		byPath[archive.Collection] = archive
+	}
+
+	for _, folder := range folders {
+		if ctx.Err() != nil {
+			return
+		}
+		archive := byPath[folder.Path]
+		switch {
+		case archive == nil:
+			if err := t.folderArchives.TouchVectorArchive(folder.Path, now); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			}
+		case archive.Tier == storage.VectorTierHot && folder.Status != "scanning" && now.Sub(archive.LastUsedAt) > t.cfg.ArchiveAfter:
+			if _, err := t.ArchiveFolder(ctx, folder); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", folder.Path, err.Error()))
+				continue
+			}
+			report.ArchivedFolders = append(report.ArchivedFolders, folder.Path)
+		case archive.Tier == storage.VectorTierRehydrating && archive.RehydrationStartedAt != nil && now.Sub(*archive.RehydrationStartedAt) > staleRehydration:
+			if err := t.RehydrateFolder(ctx, folder.Path); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", folder.Path, err.Error()))
+				continue
+			}
+			report.RestoredFolders = append(report.RestoredFolders, folder.Path)
+		}
+	}
+}
+
+// folderSnapshotPath returns the snapshot file of a code index folder
+func (t *Tier) folderSnapshotPath(folderPath string) string {
+	return filepath.Join(t.cfg.Dir, "folders", url.PathEscape(folderPath)+".jsonl")
+}
+
+// folderCollections returns the collections holding the points of folder:
+// its own collection if it has one, and the shared code index, where the
+// file watcher writes re-indexed files
+func (t *Tier) folderCollections(folder *storage.IndexedFolder) ([]string, error) {
+	mapping, err := t.folders.GetPathMapping(folder.Path)
+	if err != nil {
+		return nil, err
+	}
+	if mapping == nil || mapping.QdrantCollection == "" || mapping.QdrantCollection == storage.CodeIndexCollection {
+		return []string{storage.CodeIndexCollection}, nil
+	}
+	return []string{mapping.QdrantCollection, storage.CodeIndexCollection}, nil
+}
+
+// folderFilter matches the points of folder
+func folderFilter(folder *storage.IndexedFolder) map[string]interface{} {
+	return map[string]interface{}{
+		"must": []map[string]interface{}{
+			{"key": "folderId", "match": map[string]interface{}{"value": folder.ID}},
+		},
+	}
+}
+
+// ArchiveFolder snapshots the points of a code index folder and deletes them
+// from Qdrant. MongoDB keeps its files and chunks; searches find nothing in
+// the folder until a search of it restores the points. As with Archive, the
+// tier is recorded before the points are deleted.
+func (t *Tier) ArchiveFolder(ctx context.Context, folder *storage.IndexedFolder) (*storage.VectorArchive, error) {
+	collections, err := t.folderCollections(folder)
+	if err != nil {
+		return nil, err
+	}
+	path := t.folderSnapshotPath(folder.Path)
+	points := 0
+	scanned := map[string]bool{} // Collections the folder has points in
+	err = t.writeSnapshot(path, func(encoder *json.Encoder) error {
+		for _, collection := range collections {
+			err := t.codeVectors.ScanCodeIndexPoints(ctx, collection, folderFilter(folder), func(point storage.CodeIndexPoint) error {
+				points++
+				scanned[collection] = true
+				return encoder.Encode(folderSnapshotLine{
+					Collection:    collection,
+					ID:            point.ID,
+					Vector:        point.Vector,
+					SummaryVector: point.SummaryVector,
+					Payload:       point.Payload,
+				})
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+	archive := &storage.VectorArchive{
+		Collection:   folder.Path,
+		Tier:         storage.VectorTierArchived,
+		SnapshotPath: path,
+		Points:       points,
+		ArchivedAt:   &now,
+	}
+	if err := t.folderArchives.SaveVectorArchive(archive); err != nil {
+		return nil, err
+	}
+	for _, collection := range collections {
+		if !scanned[collection] {
+			continue
+		}
+		if err := t.codeVectors.DeleteCodeIndexByFilter(collection, folderFilter(folder)); err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	delete(t.folderUse, folder.Path)
+	t.mu.Unlock()
+
+	t.logger.Info("Code folder archived",
+		zap.String("folder", folder.Path),
+		zap.String("snapshot", path),
+		zap.Int("points", points))
+	return archive, nil
+}
+
+// RehydrateFolder restores the points of an archived code index folder from
+// its snapshot. Points of chunks re-indexed while it was archived are
+// skipped: the file watcher already wrote their replacements. It does
+// nothing when the folder is not archived or another rehydration is under
+// way.
+func (t *Tier) RehydrateFolder(ctx context.Context, folderPath string) error {
+	started := t.now()
+	claimed, err := t.folderArchives.BeginRehydration(folderPath, started, started.Add(-staleRehydration))
+	if err != nil || !claimed {
+		return err
+	}
+	archive, err := t.folderArchives.GetVectorArchive(folderPath)
+	if err != nil {
+		return err
+	}
+	t.logger.Info("Rehydrating code folder", zap.String("folder", folderPath))
+
+	restored, err := t.restoreFolder(ctx, folderPath, archive.SnapshotPath)
+	if err != nil {
+		// Back to archived, so the next use tries again
+		archive.Tier = storage.VectorTierArchived
+		archive.Error = err.Error()
+		if saveErr := t.folderArchives.SaveVectorArchive(archive); saveErr != nil {
+			t.logger.Warn("Failed to record failed rehydration", zap.String("folder", folderPath), zap.Error(saveErr))
+		}
+		return err
+	}
+
+	finished := t.now()
+	snapshot := archive.SnapshotPath
+	archive.Tier = storage.VectorTierHot
+	archive.SnapshotPath = ""
+	archive.Points = 0
+	archive.RehydratedAt = &finished
+	archive.Error = ""
+	archive.LastUsedAt = finished
+	if err := t.folderArchives.SaveVectorArchive(archive); err != nil {
+		return err
+	}
+	if snapshot != "" {
+		os.Remove(snapshot)
+	}
+
+	t.logger.Info("Code folder rehydrated",
+		zap.String("folder", folderPath),
+		zap.Int("points", restored),
+		zap.Duration("duration", finished.Sub(started)))
+	return nil
+}
+
+// restoreFolder stores the snapshot points of folderPath whose chunks are
+// still indexed, and returns how many it stored. A folder removed while
+// archived has nothing to restore.
+func (t *Tier) restoreFolder(ctx context.Context, folderPath, snapshot string) (int, error) {
+	folders, err := t.folders.ListFolders()
+	if err != nil {
+		return 0, err
+	}
+	var folder *storage.IndexedFolder
+	for _, candidate := range folders {
+		if candidate.Path == folderPath {
+			folder = candidate
+			break
+		}
+	}
+	if folder == nil || snapshot == "" {
+		return 0, nil
+	}
+
+	current := map[string]bool{}
+	files, err := t.folders.ListFiles(folder.ID)
+	if err != nil {
+		return 0, err
+	}
+	for _, file := range files {
+		chunks, err := t.folders.ListChunks(file.ID)
+		if err != nil {
+			return 0, err
+		}
+		for _, chunk := range chunks {
+			if chunk.VectorID != "" {
+				current[chunk.VectorID] = true
+			}
+		}
+	}
+
+	file, err := os.Open(snapshot)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer file.Close()
+
+	restored := 0
+	batches := map[string][]storage.CodeIndexPoint{}
+	flush := func(collection string) error {
+		batch := batches[collection]
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := t.codeVectors.UpsertCodeIndexPointsContext(ctx, collection, batch); err != nil {
+			return fmt.Errorf("failed to restore vectors: %w", err)
+		}
+		restored += len(batch)
+		batches[collection] = batch[:0]
+		return nil
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for decoder.More() {
+		var line folderSnapshotLine
+		if err := decoder.Decode(&line); err != nil {
+			return restored, fmt.Errorf("failed to read snapshot %s: %w", snapshot, err)
+		}
+		if !current[line.ID] {
+			continue
+		}
+		batches[line.Collection] = append(batches[line.Collection], storage.CodeIndexPoint{
+			ID:            line.ID,
+			Vector:        line.Vector,
+			SummaryVector: line.SummaryVector,
+			Payload:       line.Payload,
+		})
+		if len(batches[line.Collection]) >= restoreBatchSize {
+			if err := flush(line.Collection); err != nil {
+				return restored, err
+			}
+		}
+	}
+	for collection := range batches {
+		if err := flush(collection); err != nil {
+			return restored, err
+		}
+	}
+	return restored, nil
+}
@@ -0,0 +1,200 @@
+package vectortier
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCodeIndex keeps folders, files and chunks in "MongoDB" and points in
+// "Qdrant" collections
+type memoryCodeIndex struct {
+	mu       sync.Mutex
+	folders  []*storage.IndexedFolder
+	files    map[string][]*storage.IndexedFile // folder ID -> files
+	chunks   map[string][]*storage.FileChunk   // file ID -> chunks
+	mappings map[string]string                 // folder path -> collection
+	qdrant   map[string]map[string]storage.CodeIndexPoint
+}
+
+func (m *memoryCodeIndex) ListFolders() ([]*storage.IndexedFolder, error) { return m.folders, nil }
+
+func (m *memoryCodeIndex) ListFiles(folderID string) ([]*storage.IndexedFile, error) {
+	return m.files[folderID], nil
+}
+
+func (m *memoryCodeIndex) ListChunks(fileID string) ([]*storage.FileChunk, error) {
+	return m.chunks[fileID], nil
+}
+
+func (m *memoryCodeIndex) GetPathMapping(path string) (*storage.CodeIndexMapping, error) {
+	collection, ok := m.mappings[path]
+	if !ok {
+		return nil, nil
+	}
+	return &storage.CodeIndexMapping{Path: path, QdrantCollection: collection}, nil
+}
+
+// folderOf returns the folderId a filter built by folderFilter matches
+func folderOf(filter map[string]interface{}) string {
+	must := filter["must"].([]map[string]interface{})
+	return must[0]["match"].(map[string]interface{})["value"].(string)
+}
+
+func (m *memoryCodeIndex) ScanCodeIndexPoints(ctx context.Context, collectionName string, filter map[string]interface{}, visit func(storage.CodeIndexPoint) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, point := range m.qdrant[collectionName] {
+		if point.Payload["folderId"] != folderOf(filter) {
+			continue
+		}
+		if err := visit(point); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryCodeIndex) UpsertCodeIndexPointsContext(ctx context.Context, collectionName string, points []storage.CodeIndexPoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, point := range points {
+		m.qdrant[collectionName][point.ID] = point
+	}
+	return nil
+}
+
+func (m *memoryCodeIndex) DeleteCodeIndexByFilter(collectionName string, filter map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, point := range m.qdrant[collectionName] {
+		if point.Payload["folderId"] == folderOf(filter) {
+			delete(m.qdrant[collectionName], id)
+		}
+	}
+	return nil
+}
+
+func codePoint(id, folderID string) storage.CodeIndexPoint {
+	return storage.CodeIndexPoint{ID: id, Vector: []float32{0.1, 0.2}, Payload: map[string]interface{}{"folderId": folderID}}
+}
+
+func newTestFolderTier(t *testing.T) (*Tier, *memoryCodeIndex, *memoryArchives, *time.Time) {
+	tier, _, _, now := newTestTier(t)
+	index := &memoryCodeIndex{
+		folders: []*storage.IndexedFolder{
+			{ID: "f1", Path: "/repos/api", Status: "active"},
+			{ID: "f2", Path: "/repos/web", Status: "active"},
+		},
+		files: map[string][]*storage.IndexedFile{
+			"f1": {{ID: "a.go", FolderID: "f1"}},
+			"f2": {{ID: "b.ts", FolderID: "f2"}},
+		},
+		chunks: map[string][]*storage.FileChunk{
+			"a.go": {{ID: "c1", VectorID: "p1"}, {ID: "c2", VectorID: "p2"}},
+			"b.ts": {{ID: "c3", VectorID: "p3"}},
+		},
+		mappings: map[string]string{"/repos/api": "api_index"},
+		qdrant: map[string]map[string]storage.CodeIndexPoint{
+			"api_index":                 {"p1": codePoint("p1", "f1")},
+			storage.CodeIndexCollection: {"p2": codePoint("p2", "f1"), "p3": codePoint("p3", "f2")},
+		},
+	}
+	archives := &memoryArchives{records: map[string]*storage.VectorArchive{}}
+	tier.SetCodeFolders(index, index, archives)
+	return tier, index, archives, now
+}
+
+func TestRun_ArchivesIdleFolders(t *testing.T) {
+	tier, index, archives, now := newTestFolderTier(t)
+	ctx := context.Background()
+
+	report := tier.Run(ctx)
+	assert.Empty(t, report.ArchivedFolders)
+	require.Len(t, archives.records, 2)
+
+	*now = now.Add(20 * 24 * time.Hour)
+	tier.CodeFolderUsed("/repos/web")
+	assert.Eventually(t, func() bool {
+		archive, _ := archives.GetVectorArchive("/repos/web")
+		return archive.LastUsedAt.Equal(*now)
+	}, time.Second, 5*time.Millisecond)
+	*now = now.Add(15 * 24 * time.Hour)
+
+	report = tier.Run(ctx)
+	assert.Equal(t, []string{"/repos/api"}, report.ArchivedFolders)
+	assert.Empty(t, index.qdrant["api_index"], "points in the folder's collection are deleted")
+	assert.Equal(t, []string{"p3"}, keys(index.qdrant[storage.CodeIndexCollection]), "other folders keep their points")
+
+	archive := archives.records["/repos/api"]
+	assert.Equal(t, storage.VectorTierArchived, archive.Tier)
+	assert.Equal(t, 2, archive.Points)
+	assert.Equal(t, storage.VectorTierArchived, tier.FolderStatus("/repos/api").Tier)
+	assert.Nil(t, tier.FolderStatus("/repos/web"))
+}
+
+func TestRun_SkipsScanningFolders(t *testing.T) {
+	tier, index, _, now := newTestFolderTier(t)
+	tier.Run(context.Background())
+	index.folders[0].Status = "scanning"
+	*now = now.Add(40 * 24 * time.Hour)
+
+	report := tier.Run(context.Background())
+	assert.Equal(t, []string{"/repos/web"}, report.ArchivedFolders)
+}
+
+func TestRehydrateFolder(t *testing.T) {
+	tier, index, archives, now := newTestFolderTier(t)
+	ctx := context.Background()
+
+	_, err := tier.ArchiveFolder(ctx, index.folders[0])
+	require.NoError(t, err)
+	snapshot := archives.records["/repos/api"].SnapshotPath
+
+	// a.go was re-indexed while archived: chunk c2 got a new point
+	index.chunks["a.go"][1].VectorID = "p4"
+	index.qdrant[storage.CodeIndexCollection]["p4"] = codePoint("p4", "f1")
+
+	*now = now.Add(time.Hour)
+	require.NoError(t, tier.RehydrateFolder(ctx, "/repos/api"))
+	assert.Equal(t, []string{"p1"}, keys(index.qdrant["api_index"]), "points go back to their collection")
+	assert.Equal(t, []string{"p3", "p4"}, keys(index.qdrant[storage.CodeIndexCollection]), "replaced points are not restored")
+	assert.Equal(t, []float32{0.1, 0.2}, index.qdrant["api_index"]["p1"].Vector)
+
+	archive := archives.records["/repos/api"]
+	assert.Equal(t, storage.VectorTierHot, archive.Tier)
+	assert.Equal(t, *now, *archive.RehydratedAt)
+	assert.NoFileExists(t, snapshot)
+}
+
+func TestCodeFolderUsed_Rehydrates(t *testing.T) {
+	tier, index, archives, _ := newTestFolderTier(t)
+	_, err := tier.ArchiveFolder(context.Background(), index.folders[0])
+	require.NoError(t, err)
+
+	tier.CodeFolderUsed("/repos/api")
+	assert.Eventually(t, func() bool {
+		archive, _ := archives.GetVectorArchive("/repos/api")
+		return archive.Tier == storage.VectorTierHot
+	}, time.Second, 5*time.Millisecond)
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	assert.Len(t, index.qdrant["api_index"], 1)
+}
+
+func keys(points map[string]storage.CodeIndexPoint) []string {
+	var ids []string
+	for id := range points {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
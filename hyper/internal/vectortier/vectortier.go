@@ -0,0 +1,539 @@
+// Package vectortier keeps Qdrant memory proportional to active work. The
+// vectors of knowledge collections nobody queried or wrote to for a while are
+// snapshotted to a file and their Qdrant collection is deleted; MongoDB keeps
+// the entries, so queries still answer from its text search. The next query
+// of or write to an archived collection restores its vectors in the
+// background. Code index folders nobody searched for a while are archived the
+// same way, point by point, since folders share collections.
+package vectortier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"go.uber.org/zap"
+)
+
+// Config controls archiving
+type Config struct {
+	ArchiveAfter time.Duration // Idle time after which a collection is archived; 0 disables archiving
+	Interval     time.Duration // Time between sweeps for idle collections
+	Dir          string        // Directory of the vector snapshots
+	MaxDiskBytes int64         // Size Dir may reach; archiving that would exceed it fails. 0 means no limit
+}
+
+// defaultMaxDiskMB is the default VECTOR_ARCHIVE_MAX_MB
+const defaultMaxDiskMB = 10240
+
+// LoadConfig reads VECTOR_ARCHIVE_AFTER_DAYS (default 0, archiving
+// disabled), VECTOR_ARCHIVE_INTERVAL (default 1h), VECTOR_ARCHIVE_DIR
+// (default vector-archives) and VECTOR_ARCHIVE_MAX_MB (default 10240, 0 for
+// no limit)
+func LoadConfig() (Config, error) {
+	cfg := Config{Interval: time.Hour, Dir: "vector-archives", MaxDiskBytes: defaultMaxDiskMB << 20}
+
+	if raw := os.Getenv("VECTOR_ARCHIVE_AFTER_DAYS"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 {
+			return cfg, fmt.Errorf("invalid VECTOR_ARCHIVE_AFTER_DAYS %q: must be a number of days, 0 to disable archiving", raw)
+		}
+		cfg.ArchiveAfter = time.Duration(days) * 24 * time.Hour
+	}
+	if raw := os.Getenv("VECTOR_ARCHIVE_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval <= 0 {
+			return cfg, fmt.Errorf("invalid VECTOR_ARCHIVE_INTERVAL %q: must be a positive duration such as 1h", raw)
+		}
+		cfg.Interval = interval
+	}
+	if raw := os.Getenv("VECTOR_ARCHIVE_DIR"); raw != "" {
+		cfg.Dir = raw
+	}
+	if raw := os.Getenv("VECTOR_ARCHIVE_MAX_MB"); raw != "" {
+		mb, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || mb < 0 {
+			return cfg, fmt.Errorf("invalid VECTOR_ARCHIVE_MAX_MB %q: must be a number of megabytes, 0 for no limit", raw)
+		}
+		cfg.MaxDiskBytes = mb << 20
+	}
+	return cfg, nil
+}
+
+// Knowledge lists and exports knowledge entries (implemented by
+// storage.MongoKnowledgeStorage)
+type Knowledge interface {
+	ListCollections() []string
+	ExportKnowledge(ctx context.Context, collection string, withVectors bool, visit func(*storage.ExportedKnowledge) error) error
+}
+
+// Vectors manages Qdrant collections (implemented by storage.QdrantClient)
+type Vectors interface {
+	EnsureCollection(collectionName string, vectorSize int) error
+	StorePoints(collectionName string, points []storage.KnowledgePoint) error
+	DeleteCollection(collectionName string) error
+}
+
+// Archives stores vector tiers (implemented by storage.VectorArchiveStorage)
+type Archives interface {
+	GetVectorArchive(collection string) (*storage.VectorArchive, error)
+	ListVectorArchives() ([]*storage.VectorArchive, error)
+	TouchVectorArchive(collection string, at time.Time) error
+	SaveVectorArchive(archive *storage.VectorArchive) error
+	BeginRehydration(collection string, at, staleBefore time.Time) (bool, error)
+}
+
+const (
+	// useInterval is how often a collection's use is recorded at most
+	useInterval = time.Minute
+	// staleRehydration is when a rehydration is assumed to have died with its
+	// process and may be taken over
+	staleRehydration = 30 * time.Minute
+	// restoreBatchSize is how many points a rehydration stores per request
+	restoreBatchSize = 256
+)
+
+// snapshotLine is one vector of a snapshot. Only IDs and vectors are kept:
+// text and metadata are restored from MongoDB, so sealed text never reaches
+// the snapshot.
+type snapshotLine struct {
+	ID     string    `json:"id"`
+	Vector []float64 `json:"vector"`
+}
+
+// Report is what one sweep archived
+type Report struct {
+	At              time.Time `json:"at"`
+	Archived        []string  `json:"archived"`
+	Restored        []string  `json:"restored"` // Stale rehydrations taken over
+	ArchivedFolders []string  `json:"archivedFolders,omitempty"`
+	RestoredFolders []string  `json:"restoredFolders,omitempty"`
+	Errors          []string  `json:"errors,omitempty"`
+}
+
+// Tier archives idle knowledge collections and code index folders and
+// rehydrates them on use
+type Tier struct {
+	cfg        Config
+	knowledge  Knowledge
+	vectors    Vectors
+	archives   Archives
+	dimensions int
+	logger     *zap.Logger
+	now        func() time.Time
+	ctx        context.Context // Background work stops when it is cancelled
+
+	// Optional: code index folders, see SetCodeFolders
+	folders        Folders
+	codeVectors    CodeVectors
+	folderArchives Archives
+
+	mu        sync.Mutex
+	lastUse   map[string]time.Time // Last recorded use, by collection
+	folderUse map[string]time.Time // Last recorded use, by folder path
+	sweeping  sync.Mutex           // One sweep at a time
+}
+
+// NewTier creates a vector tier. Restored collections are created with
+// dimensions, the embedding model's.
+func NewTier(cfg Config, knowledge Knowledge, vectors Vectors, archives Archives, dimensions int, logger *zap.Logger) *Tier {
+	return &Tier{
+		cfg:        cfg,
+		knowledge:  knowledge,
+		vectors:    vectors,
+		archives:   archives,
+		dimensions: dimensions,
+		logger:     logger,
+		now:        time.Now,
+		ctx:        context.Background(),
+		lastUse:    map[string]time.Time{},
+		folderUse:  map[string]time.Time{},
+	}
+}
+
+// Start sweeps for idle collections every interval until ctx is cancelled.
+// Rehydrations run whether or not archiving is enabled, so collections
+// archived before it was disabled still come back.
+func (t *Tier) Start(ctx context.Context) {
+	t.ctx = ctx
+	if t.cfg.ArchiveAfter <= 0 {
+		t.logger.Info("Vector archiving disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(t.cfg.Interval)
+		defer ticker.Stop()
+
+		t.logger.Info("Vector archiving started",
+			zap.Duration("archiveAfter", t.cfg.ArchiveAfter),
+			zap.Duration("interval", t.cfg.Interval),
+			zap.String("dir", t.cfg.Dir),
+			zap.Int64("maxDiskBytes", t.cfg.MaxDiskBytes),
+			zap.Bool("codeFolders", t.folders != nil))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.Run(ctx)
+			}
+		}
+	}()
+}
+
+// KnowledgeCollectionUsed records a query of or write to collection and, if
+// it is archived, starts restoring its vectors. It implements
+// storage.KnowledgeUseObserver and does not block.
+func (t *Tier) KnowledgeCollectionUsed(collection string) {
+	now := t.now()
+	t.mu.Lock()
+	if last, ok := t.lastUse[collection]; ok && now.Sub(last) < useInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.lastUse[collection] = now
+	t.mu.Unlock()
+
+	go func() {
+		if err := t.archives.TouchVectorArchive(collection, now); err != nil {
+			t.logger.Warn("Failed to record knowledge collection use", zap.String("collection", collection), zap.Error(err))
+			return
+		}
+		archive, err := t.archives.GetVectorArchive(collection)
+		if err != nil {
+			t.logger.Warn("Failed to read knowledge collection tier", zap.String("collection", collection), zap.Error(err))
+			return
+		}
+		if archive != nil && archive.Tier == storage.VectorTierArchived {
+			if err := t.Rehydrate(t.ctx, collection); err != nil {
+				t.logger.Error("Knowledge collection rehydration failed", zap.String("collection", collection), zap.Error(err))
+			}
+		}
+	}()
+}
+
+// Status returns the tier record of collection, or nil when it is hot or
+// unknown
+func (t *Tier) Status(collection string) *storage.VectorArchive {
+	archive, err := t.archives.GetVectorArchive(collection)
+	if err != nil {
+		t.logger.Warn("Failed to read knowledge collection tier", zap.String("collection", collection), zap.Error(err))
+		return nil
+	}
+	if archive == nil || archive.Tier == storage.VectorTierHot {
+		return nil
+	}
+	return archive
+}
+
+// List returns the tier records of all collections
+func (t *Tier) List() ([]*storage.VectorArchive, error) {
+	return t.archives.ListVectorArchives()
+}
+
+// Run archives every hot collection and code index folder unused for longer
+// than ArchiveAfter and takes over stale rehydrations. Collections and
+// folders without a record get one, so their idle time counts from their
+// first sweep.
+func (t *Tier) Run(ctx context.Context) *Report {
+	t.sweeping.Lock()
+	defer t.sweeping.Unlock()
+
+	now := t.now()
+	report := &Report{At: now, Archived: []string{}, Restored: []string{}}
+	archives, err := t.archives.ListVectorArchives()
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return report
+	}
+	byCollection := make(map[string]*storage.VectorArchive, len(archives))
+	for _, archive := range archives {
+		byCollection[archive.Collection] = archive
+	}
+
+	for _, collection := range t.knowledge.ListCollections() {
+		if ctx.Err() != nil {
+			break
+		}
+		archive := byCollection[collection]
+		switch {
+		case archive == nil:
+			if err := t.archives.TouchVectorArchive(collection, now); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			}
+		case archive.Tier == storage.VectorTierHot && now.Sub(archive.LastUsedAt) > t.cfg.ArchiveAfter:
+			if _, err := t.Archive(ctx, collection); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", collection, err.Error()))
+				continue
+			}
+			report.Archived = append(report.Archived, collection)
+		case archive.Tier == storage.VectorTierRehydrating && archive.RehydrationStartedAt != nil && now.Sub(*archive.RehydrationStartedAt) > staleRehydration:
+			if err := t.Rehydrate(ctx, collection); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", collection, err.Error()))
+				continue
+			}
+			report.Restored = append(report.Restored, collection)
+		}
+	}
+
+	if t.folders != nil && ctx.Err() == nil {
+		t.runFolders(ctx, now, report)
+	}
+
+	if len(report.Archived) > 0 || len(report.Restored) > 0 || len(report.ArchivedFolders) > 0 || len(report.RestoredFolders) > 0 || len(report.Errors) > 0 {
+		t.logger.Info("Vector archiving sweep",
+			zap.Strings("archived", report.Archived),
+			zap.Strings("restored", report.Restored),
+			zap.Strings("archivedFolders", report.ArchivedFolders),
+			zap.Strings("restoredFolders", report.RestoredFolders),
+			zap.Strings("errors", report.Errors))
+	}
+	return report
+}
+
+// snapshotPath returns the snapshot file of a collection
+func (t *Tier) snapshotPath(collection string) string {
+	return filepath.Join(t.cfg.Dir, url.PathEscape(collection)+".jsonl")
+}
+
+// Archive snapshots the vectors of collection and deletes its Qdrant
+// collection. The tier is recorded before the collection is deleted, so a
+// use in between rehydrates it rather than leaving it without vectors.
+func (t *Tier) Archive(ctx context.Context, collection string) (*storage.VectorArchive, error) {
+	path := t.snapshotPath(collection)
+	points := 0
+	err := t.writeSnapshot(path, func(encoder *json.Encoder) error {
+		return t.knowledge.ExportKnowledge(ctx, collection, true, func(entry *storage.ExportedKnowledge) error {
+			if len(entry.Vector) == 0 {
+				return nil // Not in Qdrant; it is embedded again on rehydration
+			}
+			points++
+			return encoder.Encode(snapshotLine{ID: entry.ID, Vector: entry.Vector})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+	archive := &storage.VectorArchive{
+		Collection:   collection,
+		Tier:         storage.VectorTierArchived,
+		SnapshotPath: path,
+		Points:       points,
+		ArchivedAt:   &now,
+	}
+	if err := t.archives.SaveVectorArchive(archive); err != nil {
+		return nil, err
+	}
+	if err := t.vectors.DeleteCollection(collection); err != nil {
+		return nil, err
+	}
+
+	// The next use must look the tier up again
+	t.mu.Lock()
+	delete(t.lastUse, collection)
+	t.mu.Unlock()
+
+	t.logger.Info("Knowledge collection archived",
+		zap.String("collection", collection),
+		zap.String("snapshot", path),
+		zap.Int("points", points))
+	return archive, nil
+}
+
+// writeSnapshot writes a snapshot to path with write. It writes next to path
+// and renames, so a failed snapshot never replaces a complete one, and it
+// discards the snapshot if it would take Dir over MaxDiskBytes.
+func (t *Tier) writeSnapshot(path string, write func(encoder *json.Encoder) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	writer := bufio.NewWriter(file)
+	err = write(json.NewEncoder(writer))
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to snapshot vectors: %w", err)
+	}
+
+	if t.cfg.MaxDiskBytes > 0 {
+		used, err := t.DiskUsage()
+		if err != nil {
+			return err
+		}
+		if replaced, err := os.Stat(path); err == nil {
+			used -= replaced.Size()
+		}
+		if used > t.cfg.MaxDiskBytes {
+			return fmt.Errorf("snapshot would take %s to %d MB, over VECTOR_ARCHIVE_MAX_MB (%d MB)", t.cfg.Dir, used>>20, t.cfg.MaxDiskBytes>>20)
+		}
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// DiskUsage returns the bytes the snapshot directory takes
+func (t *Tier) DiskUsage() (int64, error) {
+	return DirSize(t.cfg.Dir)
+}
+
+// DirSize returns the bytes the files under dir take. A missing directory
+// takes none.
+func DirSize(dir string) (int64, error) {
+	var used int64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			used += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure snapshot directory: %w", err)
+	}
+	return used, nil
+}
+
+// Rehydrate restores the vectors of an archived collection from its snapshot
+// and MongoDB. Entries without a snapshot vector of the current dimensions,
+// such as ones written while it was archived, are embedded again. It does
+// nothing when the collection is not archived or another rehydration is
+// under way.
+func (t *Tier) Rehydrate(ctx context.Context, collection string) error {
+	started := t.now()
+	claimed, err := t.archives.BeginRehydration(collection, started, started.Add(-staleRehydration))
+	if err != nil || !claimed {
+		return err
+	}
+	archive, err := t.archives.GetVectorArchive(collection)
+	if err != nil {
+		return err
+	}
+	t.logger.Info("Rehydrating knowledge collection", zap.String("collection", collection))
+
+	restored, err := t.restore(ctx, collection, archive.SnapshotPath)
+	if err != nil {
+		// Back to archived, so the next use tries again
+		archive.Tier = storage.VectorTierArchived
+		archive.Error = err.Error()
+		if saveErr := t.archives.SaveVectorArchive(archive); saveErr != nil {
+			t.logger.Warn("Failed to record failed rehydration", zap.String("collection", collection), zap.Error(saveErr))
+		}
+		return err
+	}
+
+	finished := t.now()
+	snapshot := archive.SnapshotPath
+	archive.Tier = storage.VectorTierHot
+	archive.SnapshotPath = ""
+	archive.Points = 0
+	archive.RehydratedAt = &finished
+	archive.Error = ""
+	archive.LastUsedAt = finished
+	if err := t.archives.SaveVectorArchive(archive); err != nil {
+		return err
+	}
+	if snapshot != "" {
+		os.Remove(snapshot)
+	}
+
+	t.logger.Info("Knowledge collection rehydrated",
+		zap.String("collection", collection),
+		zap.Int("points", restored),
+		zap.Duration("duration", finished.Sub(started)))
+	return nil
+}
+
+// restore stores every MongoDB entry of collection in Qdrant with its
+// snapshot vector, and returns how many it stored
+func (t *Tier) restore(ctx context.Context, collection, snapshot string) (int, error) {
+	vectors, err := readSnapshot(snapshot)
+	if err != nil {
+		return 0, err
+	}
+	if err := t.vectors.EnsureCollection(collection, t.dimensions); err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	var batch []storage.KnowledgePoint
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := t.vectors.StorePoints(collection, batch); err != nil {
+			return fmt.Errorf("failed to restore vectors: %w", err)
+		}
+		restored += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	err = t.knowledge.ExportKnowledge(ctx, collection, false, func(entry *storage.ExportedKnowledge) error {
+		batch = append(batch, storage.KnowledgePoint{ID: entry.ID, Text: entry.Text, Metadata: entry.Metadata, Vector: vectors[entry.ID]})
+		if len(batch) < restoreBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	return restored, err
+}
+
+// readSnapshot loads the vectors of a snapshot by entry ID. A missing path
+// yields no vectors, so every entry is embedded again.
+func readSnapshot(path string) (map[string][]float64, error) {
+	vectors := map[string][]float64{}
+	if path == "" {
+		return vectors, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for decoder.More() {
+		var line snapshotLine
+		if err := decoder.Decode(&line); err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+		}
+		vectors[line.ID] = line.Vector
+	}
+	return vectors, nil
+}
@@ -0,0 +1,281 @@
+package vectortier
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"hyper/internal/mcp/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryKnowledge keeps entries in "MongoDB" and vectors in "Qdrant" collections
+type memoryKnowledge struct {
+	mu      sync.Mutex
+	entries map[string][]*storage.KnowledgeEntry
+	qdrant  map[string]map[string][]float64 // collection -> point ID -> vector
+	stored  []storage.KnowledgePoint
+}
+
+func (m *memoryKnowledge) ListCollections() []string {
+	var names []string
+	for name := range m.entries {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (m *memoryKnowledge) ExportKnowledge(ctx context.Context, collection string, withVectors bool, visit func(*storage.ExportedKnowledge) error) error {
+	for _, entry := range m.entries[collection] {
+		exported := &storage.ExportedKnowledge{KnowledgeEntry: entry}
+		if withVectors {
+			exported.Vector = m.qdrant[collection][entry.ID]
+		}
+		if err := visit(exported); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryKnowledge) EnsureCollection(collectionName string, vectorSize int) error {
+	if m.qdrant[collectionName] == nil {
+		m.qdrant[collectionName] = map[string][]float64{}
+	}
+	return nil
+}
+
+func (m *memoryKnowledge) StorePoints(collectionName string, points []storage.KnowledgePoint) error {
+	for _, point := range points {
+		vector := point.Vector
+		if len(vector) != 2 {
+			vector = []float64{9, 9} // Embedded again
+		}
+		m.qdrant[collectionName][point.ID] = vector
+		m.stored = append(m.stored, point)
+	}
+	return nil
+}
+
+func (m *memoryKnowledge) DeleteCollection(collectionName string) error {
+	delete(m.qdrant, collectionName)
+	return nil
+}
+
+// memoryArchives stores tier records in memory
+type memoryArchives struct {
+	mu      sync.Mutex
+	records map[string]*storage.VectorArchive
+}
+
+func (m *memoryArchives) GetVectorArchive(collection string) (*storage.VectorArchive, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if record, ok := m.records[collection]; ok {
+		copy := *record
+		return &copy, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryArchives) ListVectorArchives() ([]*storage.VectorArchive, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var records []*storage.VectorArchive
+	for _, record := range m.records {
+		copy := *record
+		records = append(records, &copy)
+	}
+	return records, nil
+}
+
+func (m *memoryArchives) TouchVectorArchive(collection string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.records[collection]
+	if !ok {
+		m.records[collection] = &storage.VectorArchive{Collection: collection, Tier: storage.VectorTierHot, LastUsedAt: at}
+		return nil
+	}
+	if at.After(record.LastUsedAt) {
+		record.LastUsedAt = at
+	}
+	return nil
+}
+
+func (m *memoryArchives) SaveVectorArchive(archive *storage.VectorArchive) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copy := *archive
+	if existing, ok := m.records[archive.Collection]; ok && existing.LastUsedAt.After(copy.LastUsedAt) {
+		copy.LastUsedAt = existing.LastUsedAt
+	}
+	m.records[archive.Collection] = &copy
+	return nil
+}
+
+func (m *memoryArchives) BeginRehydration(collection string, at, staleBefore time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.records[collection]
+	if !ok || !(record.Tier == storage.VectorTierArchived ||
+		(record.Tier == storage.VectorTierRehydrating && record.RehydrationStartedAt.Before(staleBefore))) {
+		return false, nil
+	}
+	record.Tier = storage.VectorTierRehydrating
+	record.RehydrationStartedAt = &at
+	record.Error = ""
+	return true, nil
+}
+
+func newTestTier(t *testing.T) (*Tier, *memoryKnowledge, *memoryArchives, *time.Time) {
+	knowledge := &memoryKnowledge{
+		entries: map[string][]*storage.KnowledgeEntry{
+			"adr": {
+				{ID: "1", Collection: "adr", Text: "Use MongoDB"},
+				{ID: "2", Collection: "adr", Text: "Use Qdrant"},
+			},
+			"runbooks": {{ID: "3", Collection: "runbooks", Text: "Restart the pod"}},
+		},
+		qdrant: map[string]map[string][]float64{
+			"adr":      {"1": {0.1, 0.2}, "2": {0.3, 0.4}},
+			"runbooks": {"3": {0.5, 0.6}},
+		},
+	}
+	archives := &memoryArchives{records: map[string]*storage.VectorArchive{}}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cfg := Config{ArchiveAfter: 30 * 24 * time.Hour, Interval: time.Hour, Dir: t.TempDir()}
+	tier := NewTier(cfg, knowledge, knowledge, archives, 2, zap.NewNop())
+	tier.now = func() time.Time { return now }
+	return tier, knowledge, archives, &now
+}
+
+func TestRun_ArchivesIdleCollections(t *testing.T) {
+	tier, knowledge, archives, now := newTestTier(t)
+	ctx := context.Background()
+
+	// The first sweep starts the idle clock
+	report := tier.Run(ctx)
+	assert.Empty(t, report.Archived)
+	require.Len(t, archives.records, 2)
+
+	*now = now.Add(20 * 24 * time.Hour)
+	require.NoError(t, archives.TouchVectorArchive("runbooks", *now))
+	*now = now.Add(15 * 24 * time.Hour)
+
+	report = tier.Run(ctx)
+	assert.Equal(t, []string{"adr"}, report.Archived)
+	assert.NotContains(t, knowledge.qdrant, "adr", "the Qdrant collection is deleted")
+	assert.Contains(t, knowledge.qdrant, "runbooks")
+
+	archive := archives.records["adr"]
+	assert.Equal(t, storage.VectorTierArchived, archive.Tier)
+	assert.Equal(t, 2, archive.Points)
+	data, err := os.ReadFile(archive.SnapshotPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Use MongoDB", "snapshots hold vectors only")
+
+	assert.Equal(t, archive.Collection, tier.Status("adr").Collection)
+	assert.Nil(t, tier.Status("runbooks"), "hot collections have no status")
+}
+
+func TestRehydrate(t *testing.T) {
+	tier, knowledge, archives, now := newTestTier(t)
+	ctx := context.Background()
+
+	_, err := tier.Archive(ctx, "adr")
+	require.NoError(t, err)
+	snapshot := archives.records["adr"].SnapshotPath
+
+	// An entry written while archived has no snapshot vector
+	knowledge.entries["adr"] = append(knowledge.entries["adr"], &storage.KnowledgeEntry{ID: "4", Collection: "adr", Text: "Use NATS"})
+
+	*now = now.Add(time.Hour)
+	require.NoError(t, tier.Rehydrate(ctx, "adr"))
+	assert.Equal(t, map[string][]float64{"1": {0.1, 0.2}, "2": {0.3, 0.4}, "4": {9, 9}}, knowledge.qdrant["adr"])
+	assert.Equal(t, "Use MongoDB", knowledge.stored[0].Text, "text comes from MongoDB")
+
+	archive := archives.records["adr"]
+	assert.Equal(t, storage.VectorTierHot, archive.Tier)
+	assert.Equal(t, *now, *archive.RehydratedAt)
+	assert.Empty(t, archive.SnapshotPath)
+	assert.NoFileExists(t, snapshot)
+
+	// Hot collections are left alone
+	knowledge.stored = nil
+	require.NoError(t, tier.Rehydrate(ctx, "adr"))
+	assert.Empty(t, knowledge.stored)
+}
+
+func TestKnowledgeCollectionUsed_Rehydrates(t *testing.T) {
+	tier, knowledge, archives, _ := newTestTier(t)
+	_, err := tier.Archive(context.Background(), "adr")
+	require.NoError(t, err)
+
+	tier.KnowledgeCollectionUsed("adr")
+	assert.Eventually(t, func() bool {
+		archive, _ := archives.GetVectorArchive("adr")
+		return archive.Tier == storage.VectorTierHot
+	}, time.Second, 5*time.Millisecond)
+
+	knowledge.mu.Lock()
+	defer knowledge.mu.Unlock()
+	assert.Len(t, knowledge.qdrant["adr"], 2)
+}
+
+func TestArchive_DiskLimit(t *testing.T) {
+	tier, knowledge, archives, _ := newTestTier(t)
+	ctx := context.Background()
+
+	_, err := tier.Archive(ctx, "adr")
+	require.NoError(t, err)
+	used, err := tier.DiskUsage()
+	require.NoError(t, err)
+	require.Positive(t, used)
+
+	// Archiving adr again replaces its snapshot, which fits; another one does not
+	tier.cfg.MaxDiskBytes = used
+	archives.records["adr"].Tier = storage.VectorTierHot
+	knowledge.qdrant["adr"] = map[string][]float64{"1": {0.1, 0.2}, "2": {0.3, 0.4}}
+	_, err = tier.Archive(ctx, "adr")
+	require.NoError(t, err)
+
+	_, err = tier.Archive(ctx, "runbooks")
+	require.ErrorContains(t, err, "VECTOR_ARCHIVE_MAX_MB")
+	assert.Contains(t, knowledge.qdrant, "runbooks", "the collection is kept")
+	assert.NotContains(t, archives.records, "runbooks")
+	after, err := tier.DiskUsage()
+	require.NoError(t, err)
+	assert.Equal(t, used, after, "the rejected snapshot is removed")
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("VECTOR_ARCHIVE_AFTER_DAYS", "30")
+	t.Setenv("VECTOR_ARCHIVE_INTERVAL", "15m")
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, cfg.ArchiveAfter)
+	assert.Equal(t, 15*time.Minute, cfg.Interval)
+	assert.Equal(t, "vector-archives", cfg.Dir)
+	assert.Equal(t, int64(10240<<20), cfg.MaxDiskBytes)
+
+	t.Setenv("VECTOR_ARCHIVE_MAX_MB", "0")
+	cfg, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.MaxDiskBytes, "0 means no limit")
+
+	t.Setenv("VECTOR_ARCHIVE_MAX_MB", "-5")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+	t.Setenv("VECTOR_ARCHIVE_MAX_MB", "")
+
+	t.Setenv("VECTOR_ARCHIVE_AFTER_DAYS", "-1")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
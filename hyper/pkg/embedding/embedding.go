@@ -0,0 +1,22 @@
+// Package embedding defines the embedding client interface the coordinator
+// stores and searches vectors with.
+//
+// It lives outside internal so code that builds its own coordinator, such as
+// a fork's main package or an in-house provider behind a proxy, can implement
+// Client without importing coordinator internals. Any Client can be passed
+// where the coordinator takes an embeddings.EmbeddingClient, for example to
+// storage.NewQdrantClientWithEmbeddingClient.
+package embedding
+
+// Client generates embedding vectors. Implementations must be safe for
+// concurrent use.
+type Client interface {
+	// CreateEmbedding generates a single embedding vector for the given text
+	CreateEmbedding(text string) ([]float32, error)
+
+	// CreateEmbeddings generates embedding vectors for multiple texts, in order
+	CreateEmbeddings(texts []string) ([][]float32, error)
+
+	// GetDimensions returns the number of dimensions of the vectors
+	GetDimensions() int
+}